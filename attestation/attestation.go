@@ -0,0 +1,67 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package attestation binds the evidence that emmy server (the verifier) runs in a trusted
+// execution environment (TEE, for example Intel SGX enclave) to the protocol session.
+//
+// The client sends a random nonce at the beginning of the session and the server responds
+// with the evidence (for example SGX quote) whose report data is ReportData(nonce, transcript),
+// where the transcript is the hybrid key exchange of the session (if any). Thus the evidence
+// is fresh and, when the session is encrypted, it proves that the session key is held by
+// the attested code - the client checks it before any protocol message (which might reveal
+// presentation metadata) is sent.
+//
+// The evidence itself is produced and checked by Attestor and Verifier implementations which
+// wrap the TEE-specific libraries (SGX DCAP, SEV-SNP, ...). Simulated attestor and verifier
+// are provided for development and testing without TEE.
+package attestation
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+)
+
+// ReportDataLen is the length of the report data (it fits into the report data of SGX quote).
+const ReportDataLen = sha512.Size
+
+// Attestor produces the evidence that the code runs in a TEE (server side).
+type Attestor interface {
+	// Format identifies the type of the evidence, so that the client can choose the verifier.
+	Format() string
+	// Attest returns the evidence which contains the given report data.
+	Attest(reportData []byte) ([]byte, error)
+}
+
+// Verifier checks the evidence (client side): it needs to check that the evidence is genuine,
+// that the measurement of the code is the expected one and that it contains the report data.
+type Verifier interface {
+	Verify(format string, evidence, reportData []byte) error
+}
+
+// ReportData returns the hash of the client's nonce and the transcript of the key exchange
+// which is to be included in the evidence.
+func ReportData(nonce, transcript []byte) []byte {
+	h := sha512.New()
+	h.Write([]byte("emmy/attestation"))
+	for _, b := range [][]byte{nonce, transcript} {
+		l := make([]byte, 8)
+		binary.BigEndian.PutUint64(l, uint64(len(b)))
+		h.Write(l)
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package attestation
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+)
+
+// SimulatedFormat is the format of the evidence produced by SimulatedAttestor.
+const SimulatedFormat = "emmy-simulated"
+
+// simulatedEvidence is the measurement and report data signed with the attestation key.
+type simulatedEvidence struct {
+	Measurement []byte
+	ReportData  []byte
+	R, S        *big.Int
+}
+
+// SimulatedAttestor signs the measurement (hash of the code) and report data with a key
+// which stands for the TEE's attestation key. It does not prove anything about the code
+// and is meant only for development and testing of the clients.
+type SimulatedAttestor struct {
+	key         *ecdsa.PrivateKey
+	measurement []byte
+}
+
+func NewSimulatedAttestor(key *ecdsa.PrivateKey, measurement []byte) *SimulatedAttestor {
+	return &SimulatedAttestor{
+		key:         key,
+		measurement: measurement,
+	}
+}
+
+func (a *SimulatedAttestor) Format() string {
+	return SimulatedFormat
+}
+
+func (a *SimulatedAttestor) Attest(reportData []byte) ([]byte, error) {
+	digest := simulatedDigest(a.measurement, reportData)
+	r, s, err := ecdsa.Sign(rand.Reader, a.key, digest)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(simulatedEvidence{
+		Measurement: a.measurement,
+		ReportData:  reportData,
+		R:           r,
+		S:           s,
+	})
+}
+
+// SimulatedVerifier checks the evidence produced by SimulatedAttestor.
+type SimulatedVerifier struct {
+	key         *ecdsa.PublicKey
+	measurement []byte
+}
+
+func NewSimulatedVerifier(key *ecdsa.PublicKey, measurement []byte) *SimulatedVerifier {
+	return &SimulatedVerifier{
+		key:         key,
+		measurement: measurement,
+	}
+}
+
+func (v *SimulatedVerifier) Verify(format string, evidence, reportData []byte) error {
+	if format != SimulatedFormat {
+		return fmt.Errorf("unsupported evidence format %q", format)
+	}
+	var e simulatedEvidence
+	rest, err := asn1.Unmarshal(evidence, &e)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("trailing data after evidence")
+	}
+	if !ecdsa.Verify(v.key, simulatedDigest(e.Measurement, e.ReportData), e.R, e.S) {
+		return fmt.Errorf("invalid evidence signature")
+	}
+	if !bytes.Equal(e.Measurement, v.measurement) {
+		return fmt.Errorf("unexpected measurement %x", e.Measurement)
+	}
+	if !bytes.Equal(e.ReportData, reportData) {
+		return fmt.Errorf("evidence is not bound to this session")
+	}
+	return nil
+}
+
+func simulatedDigest(measurement, reportData []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(SimulatedFormat))
+	h.Write(ReportData(measurement, reportData))
+	return h.Sum(nil)
+}
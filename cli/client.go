@@ -55,7 +55,8 @@ var clientSubcommands = []cli.Command{
 				}
 				group := config.LoadGroup("pedersen")
 				secret := big.NewInt(ctx.Int64("secret"))
-				client, err := client.NewPedersenClient(conn, pbVariant, group, secret)
+				client, err := client.NewPedersenClient(conn, group, secret,
+					client.WithProtocolVariant(pbVariant))
 				if err != nil {
 					return fmt.Errorf("Error creating client: %v", err)
 				}
@@ -72,7 +73,7 @@ var clientSubcommands = []cli.Command{
 			return run(ctx.Parent(), ctx, func(ctx *cli.Context, conn *grpc.ClientConn) error {
 				secret := big.NewInt(ctx.Int64("secret"))
 				curve := dlog.P256
-				client, err := client.NewPedersenECClient(conn, secret, curve)
+				client, err := client.NewPedersenECClient(conn, secret, client.WithCurve(curve))
 				if err != nil {
 					return fmt.Errorf("Error creating client: %v", err)
 				}
@@ -93,7 +94,8 @@ var clientSubcommands = []cli.Command{
 				}
 				group := config.LoadGroup("schnorr")
 				secret := big.NewInt(ctx.Int64("secret"))
-				client, err := client.NewSchnorrClient(conn, pbVariant, group, secret)
+				client, err := client.NewSchnorrClient(conn, group, secret,
+					client.WithProtocolVariant(pbVariant))
 				if err != nil {
 					return fmt.Errorf("Error creating client: %v", err)
 				}
@@ -114,7 +116,8 @@ var clientSubcommands = []cli.Command{
 					return err
 				}
 				secret := big.NewInt(ctx.Int64("secret"))
-				client, err := client.NewSchnorrClient(conn, pbVariant, group, secret)
+				client, err := client.NewSchnorrClient(conn, group, secret,
+					client.WithProtocolVariant(pbVariant))
 				if err != nil {
 					return fmt.Errorf("Error creating client: %v", err)
 				}
@@ -129,8 +132,14 @@ var clientSubcommands = []cli.Command{
 		Flags:    []cli.Flag{protocolSecretFlag, protocolLabelFlag, protocolPubKeyFlag},
 		Action: func(ctx *cli.Context) error {
 			return run(ctx.Parent(), ctx, func(ctx *cli.Context, conn *grpc.ClientConn) error {
-				secret := common.GetRandomInt(big.NewInt(ctx.Int64("secret")))
-				label := common.GetRandomInt(big.NewInt(ctx.Int64("label")))
+				secret, err := common.GetRandomInt(big.NewInt(ctx.Int64("secret")))
+				if err != nil {
+					return err
+				}
+				label, err := common.GetRandomInt(big.NewInt(ctx.Int64("label")))
+				if err != nil {
+					return err
+				}
 				pubKey := ctx.String("pubkey")
 				client, err := client.NewCSPaillierClient(conn, pubKey, secret, label)
 				if err != nil {
@@ -0,0 +1,260 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cli
+
+import (
+	"fmt"
+	"github.com/urfave/cli"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/log"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"github.com/xlab-si/emmy/server"
+	"google.golang.org/grpc"
+	"io"
+	"math/big"
+	"net"
+	"os"
+)
+
+var DemoCmd = cli.Command{
+	Name: "demo",
+	Usage: `Runs an in-process CA, issuer and verifier and executes scripted
+		end-to-end scenarios, printing the transcript of each protocol`,
+	Flags: demoFlags,
+	Action: func(ctx *cli.Context) error {
+		err := RunDemo(os.Stdout, ctx.Int("port"), ctx.String("cert"), ctx.String("key"),
+			ctx.String("loglevel"), ctx.String("scenario"))
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		return nil
+	},
+}
+
+// demoScenario is a step of the demo - each scenario builds on the state left by the
+// scenarios before it.
+type demoScenario struct {
+	name        string
+	description string
+	run         func(d *demo) error
+}
+
+// demoScenarios are run in the given order.
+var demoScenarios = []demoScenario{
+	{
+		name: "register",
+		description: "User obtains a CA certificate for its master pseudonym and " +
+			"registers a new pseudonym with the organization",
+		run: (*demo).register,
+	},
+	{
+		name:        "issue",
+		description: "Organization issues a credential for the registered pseudonym",
+		run:         (*demo).issue,
+	},
+	{
+		name: "present",
+		description: "User presents the credential - the organization accepts it, " +
+			"but not when the user does not know the secret",
+		run: (*demo).present,
+	},
+	{
+		name:        "revoke",
+		description: "CA revokes the certificate, which is reported by its status",
+		run:         (*demo).revoke,
+	},
+}
+
+// demo holds the state which is passed between the scenarios.
+type demo struct {
+	out        io.Writer
+	srv        *server.Server
+	conn       *grpc.ClientConn
+	opts       []client.ClientOption
+	params     *pseudonymsys.Params
+	secret     *big.Int
+	caCert     *pseudonymsys.CACertificate
+	nym        *pseudonymsys.Pseudonym
+	credential *pseudonymsys.Credential
+}
+
+// RunDemo starts emmy server at the given port (a free port is chosen if it is 0) and runs the demo scenarios against it, up
+// to and including the scenario with the given name (all the scenarios if it is empty).
+// Transcript of the protocols is written to out.
+func RunDemo(out io.Writer, port int, certPath, keyPath, logLevel, until string) error {
+	scenarios, err := demoScenariosUntil(until)
+	if err != nil {
+		return err
+	}
+
+	logger, err := log.NewStdoutLogger("demo", logLevel, log.FORMAT_SHORT)
+	if err != nil {
+		return err
+	}
+	srv, err := server.NewProtocolServer(certPath, keyPath, logger)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return err
+	}
+	go srv.Serve(listener)
+	defer srv.Teardown()
+
+	address := fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port)
+	conn, err := client.GetConnection(address, certPath, false)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	d := &demo{
+		out:    out,
+		srv:    srv,
+		conn:   conn,
+		params: config.LoadPseudonymsysParams(),
+	}
+	d.opts = []client.ClientOption{
+		client.WithLogger(logger),
+		client.WithSendHook(d.printer("->")),
+		client.WithReceiveHook(d.printer("<-")),
+	}
+
+	for i, s := range scenarios {
+		fmt.Fprintf(out, "=== Scenario %d: %s\n=== %s\n", i+1, s.name, s.description)
+		if err := s.run(d); err != nil {
+			fmt.Fprintf(out, "=== FAIL: %s (%v)\n\n", s.name, err)
+			return fmt.Errorf("Scenario %s failed: %v", s.name, err)
+		}
+		fmt.Fprintf(out, "=== OK: %s\n\n", s.name)
+	}
+	return nil
+}
+
+// demoScenariosUntil returns the scenarios up to and including the one with the given name.
+func demoScenariosUntil(name string) ([]demoScenario, error) {
+	if name == "" {
+		return demoScenarios, nil
+	}
+	for i, s := range demoScenarios {
+		if s.name == name {
+			return demoScenarios[:i+1], nil
+		}
+	}
+	return nil, fmt.Errorf("Unknown scenario %s", name)
+}
+
+// printer returns a hook which prints the messages of the clients.
+func (d *demo) printer(direction string) client.MessageHook {
+	return func(clientId int32, msg *pb.Message) (*pb.Message, error) {
+		fmt.Fprintf(d.out, "  [%d] %s %v\n", clientId, direction, msg)
+		return msg, nil
+	}
+}
+
+func (d *demo) step(format string, args ...interface{}) {
+	fmt.Fprintf(d.out, "--- "+format+"\n", args...)
+}
+
+func (d *demo) register() error {
+	caClient, err := client.NewPseudonymsysCAClient(d.conn, d.params, d.opts...)
+	if err != nil {
+		return err
+	}
+	c, err := client.NewPseudonymsysClient(d.conn, d.params, d.opts...)
+	if err != nil {
+		return err
+	}
+
+	group := d.params.Group
+	d.secret, err = c.GenerateMasterKey()
+	if err != nil {
+		return err
+	}
+	masterNym := pseudonymsys.NewPseudonym(group.G, group.Exp(group.G, d.secret))
+	d.step("Obtaining CA certificate for the master pseudonym")
+	if d.caCert, err = caClient.ObtainCertificate(d.secret, masterNym); err != nil {
+		return err
+	}
+
+	d.step("Registering a pseudonym with the organization")
+	d.nym, err = c.GenerateNym(d.secret, d.caCert)
+	return err
+}
+
+func (d *demo) issue() error {
+	c, err := client.NewPseudonymsysClient(d.conn, d.params, d.opts...)
+	if err != nil {
+		return err
+	}
+
+	h1, h2 := config.LoadPseudonymsysOrgPubKeys("org1")
+	d.step("Obtaining credential from org1")
+	d.credential, err = c.ObtainCredential(d.secret, d.nym, pseudonymsys.NewOrgPubKeys(h1, h2))
+	return err
+}
+
+func (d *demo) present() error {
+	c, err := client.NewPseudonymsysClient(d.conn, d.params, d.opts...)
+	if err != nil {
+		return err
+	}
+
+	d.step("Presenting the credential to org1")
+	sessionKey, err := c.TransferCredential("org1", d.secret, d.nym, d.credential)
+	if err != nil {
+		return err
+	}
+	d.step("Credential accepted, session key: %s", sessionKey.Value)
+
+	d.step("Presenting the credential with a wrong secret")
+	wrongSecret := new(big.Int).Add(d.secret, big.NewInt(1))
+	if _, err := c.TransferCredential("org1", wrongSecret, d.nym, d.credential); err == nil {
+		return fmt.Errorf("Credential with a wrong secret was accepted")
+	}
+	d.step("Credential refused")
+	return nil
+}
+
+func (d *demo) revoke() error {
+	caClient, err := client.NewPseudonymsysCAClient(d.conn, d.params, d.opts...)
+	if err != nil {
+		return err
+	}
+
+	d.step("CA revokes the certificate")
+	d.srv.GetCAStatusResponder().Revoke(d.caCert)
+
+	d.step("Querying the certificate status")
+	status, err := caClient.GetCertificateStatus(d.caCert)
+	if err != nil {
+		return err
+	}
+	caX, caY := config.LoadPseudonymsysCAPubKey()
+	if !pseudonymsys.VerifyCAStatusResponse(caX, caY, d.caCert, status) {
+		return fmt.Errorf("Status response is not signed by the CA")
+	}
+	if status.Status != pseudonymsys.Revoked {
+		return fmt.Errorf("Certificate status is %v", status.Status)
+	}
+	d.step("Certificate status: %v", status.Status)
+	return nil
+}
@@ -125,6 +125,27 @@ var protocolPubKeyFlag = cli.StringFlag{
 	Usage: "`PATH` to the verifier's public key file",
 }
 
+// pluginFlag keeps the paths to Go plugins with extensions to be loaded by emmy server.
+var pluginFlag = cli.StringSliceFlag{
+	Name:  "plugin",
+	Usage: "`PATH` to the plugin with an extension scheme (can be repeated)",
+}
+
+// demoLogLevelFlag indicates the log level of the demo - logs are mostly hidden by
+// default, so that they do not clutter the transcript.
+var demoLogLevelFlag = cli.StringFlag{
+	Name:  "loglevel, l",
+	Value: "error",
+	Usage: "debug|info|notice|error|critical",
+}
+
+// demoScenarioFlag indicates the last demo scenario to run.
+var demoScenarioFlag = cli.StringFlag{
+	Name:  "scenario, s",
+	Value: "",
+	Usage: "register|issue|present|revoke - the scenario is run after the ones it builds on (default: all)",
+}
+
 // serverFlags are the flags used by the server CLI commands.
 var serverFlags = []cli.Flag{
 	portFlag,
@@ -132,6 +153,7 @@ var serverFlags = []cli.Flag{
 	keyFlag,
 	logFilePathFlag,
 	logLevelFlag,
+	pluginFlag,
 }
 
 // clientFlags are flags common to all client CLI subcommands, regardless of the protocol.
@@ -143,3 +165,12 @@ var clientFlags = []cli.Flag{
 	insecureFlag,
 	logLevelFlag,
 }
+
+// demoFlags are the flags used by the demo CLI command.
+var demoFlags = []cli.Flag{
+	portFlag,
+	certFlag,
+	keyFlag,
+	demoLogLevelFlag,
+	demoScenarioFlag,
+}
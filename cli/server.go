@@ -37,7 +37,8 @@ var ServerCmd = cli.Command{
 					ctx.String("cert"),
 					ctx.String("key"),
 					ctx.String("logfile"),
-					ctx.String("loglevel"))
+					ctx.String("loglevel"),
+					ctx.StringSlice("plugin"))
 				if err != nil {
 					return cli.NewExitError(err, 1)
 				}
@@ -48,7 +49,8 @@ var ServerCmd = cli.Command{
 }
 
 // startEmmyServer configures and starts the gRPC server at the desired port
-func startEmmyServer(port int, certPath, keyPath, logFilePath, logLevel string) error {
+func startEmmyServer(port int, certPath, keyPath, logFilePath, logLevel string,
+	pluginPaths []string) error {
 	var err error
 	var logger log.Logger
 
@@ -62,6 +64,13 @@ func startEmmyServer(port int, certPath, keyPath, logFilePath, logLevel string)
 		return err
 	}
 
+	for _, path := range pluginPaths {
+		if err := server.LoadExtensionPlugin(path); err != nil {
+			return err
+		}
+		logger.Noticef("Loaded extension plugin %s", path)
+	}
+
 	srv, err := server.NewProtocolServer(certPath, keyPath, logger)
 	if err != nil {
 		return err
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"fmt"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"github.com/xlab-si/emmy/revocation"
+	"google.golang.org/grpc"
+	"math/big"
+)
+
+// AbuseReportClient is used by verifiers to report misbehaving sessions to the issuer.
+type AbuseReportClient struct {
+	genericClient
+	reporter string
+	d        *big.Int
+	x        *big.Int
+	y        *big.Int
+}
+
+// NewAbuseReportClient returns a client which signs the reports in the name of reporter with
+// ECDSA (P256) key d (the issuer needs to know the reporter's public key (x, y), see
+// revocation.AbuseDesk).
+func NewAbuseReportClient(conn *grpc.ClientConn, reporter string, d, x, y *big.Int,
+	opts ...ClientOption) (*AbuseReportClient, error) {
+	genericClient, err := newGenericClient(conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &AbuseReportClient{
+		genericClient: *genericClient,
+		reporter:      reporter,
+		d:             d,
+		x:             x,
+		y:             y,
+	}, nil
+}
+
+// Report signs the report and submits it to the issuer.
+func (c *AbuseReportClient) Report(report *revocation.AbuseReport) error {
+	if err := report.Sign(c.reporter, c.d, c.x, c.y); err != nil {
+		return err
+	}
+
+	c.openStream()
+	defer c.closeStream()
+
+	data := &pb.AbuseReport{
+		Ticket:         &pb.Pair{A: report.Ticket.H.Bytes(), B: report.Ticket.Tag.Bytes()},
+		TranscriptHash: report.TranscriptHash,
+		Reason:         report.Reason,
+		Reporter:       report.Reporter,
+		Timestamp:      report.Timestamp,
+		R:              report.R.Bytes(),
+		S:              report.S.Bytes(),
+	}
+	if report.Nym != nil {
+		data.Nym = &pb.Pair{A: report.Nym.A.Bytes(), B: report.Nym.B.Bytes()}
+	}
+	msg := &pb.Message{
+		ClientId:      c.id,
+		Schema:        pb.SchemaType_ABUSE_REPORT,
+		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Content:       &pb.Message_AbuseReport{data},
+	}
+	resp, err := c.getResponseTo(msg)
+	if err != nil {
+		return err
+	}
+	if !resp.GetStatus().Success {
+		return fmt.Errorf("Abuse report was not accepted")
+	}
+	return nil
+}
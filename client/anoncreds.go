@@ -0,0 +1,243 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/anoncreds"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"google.golang.org/grpc"
+	"math/big"
+)
+
+// AnonCredsClient obtains anonymous credentials from emmy server (see
+// server.SetAnonCredsIssuer) and shows them to emmy server (see server.SetAnonCredsVerifier).
+type AnonCredsClient struct {
+	genericClient
+	pubKey        *anoncreds.PublicKey
+	holder        *anoncreds.Holder
+	requestPolicy func(*anoncreds.PresentationRequest) error
+}
+
+// NewAnonCredsClient returns the client for the credentials of the issuer with pubKey, which
+// are issued on the holder's master secret.
+func NewAnonCredsClient(conn *grpc.ClientConn, pubKey *anoncreds.PublicKey,
+	holder *anoncreds.Holder, opts ...ClientOption) (*AnonCredsClient, error) {
+	genericClient, err := newGenericClient(conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &AnonCredsClient{
+		genericClient: *genericClient,
+		pubKey:        pubKey,
+		holder:        holder,
+	}, nil
+}
+
+// SetRequestPolicy sets the policy which decides whether the presentation request of
+// the server is fulfilled (for example whether the user agrees to disclose the requested
+// attributes). ProveCredential returns the error of the policy without proving anything.
+// By default, all requests are fulfilled.
+func (c *AnonCredsClient) SetRequestPolicy(policy func(*anoncreds.PresentationRequest) error) {
+	c.requestPolicy = policy
+}
+
+// ObtainCredential runs the issuance protocol with the server and returns the credential.
+// The server can set other values than the requested attributes, thus Attributes of
+// the returned credential need to be checked.
+func (c *AnonCredsClient) ObtainCredential(attributes map[string]*big.Int) (
+	*anoncreds.Credential, error) {
+	receiver, err := anoncreds.NewCredentialReceiver(c.pubKey, c.holder)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.openStream(); err != nil {
+		return nil, err
+	}
+	defer c.closeStream()
+
+	U, t, err := receiver.GetProofRandomData()
+	if err != nil {
+		return nil, err
+	}
+	request := &pb.AnonCredsIssueRequest{
+		U: U.Bytes(),
+		T: t.Bytes(),
+	}
+	for name, value := range attributes {
+		request.Names = append(request.Names, name)
+		request.Values = append(request.Values, value.Bytes())
+	}
+	msg := &pb.Message{
+		ClientId:      c.id,
+		Schema:        pb.SchemaType_ANONCREDS_ISSUE,
+		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Content:       &pb.Message_AnonCredsIssueRequest{request},
+	}
+	resp, err := c.getResponseTo(msg)
+	if err != nil {
+		return nil, err
+	}
+	challenge := resp.GetBigint()
+	if challenge == nil {
+		return nil, fmt.Errorf("challenge expected")
+	}
+
+	zS1, zM := receiver.GetProofData(new(big.Int).SetBytes(challenge.X1))
+	msg = &pb.Message{
+		Content: &pb.Message_DoubleBigint{&pb.DoubleBigInt{X1: zS1.Bytes(), X2: zM.Bytes()}},
+	}
+	resp, err = c.getResponseTo(msg)
+	if err != nil {
+		return nil, err
+	}
+	credential := resp.GetAnonCredsCredential()
+	if credential == nil || len(credential.Names) != len(credential.Values) {
+		return nil, fmt.Errorf("credential expected")
+	}
+	signed := make(map[string]*big.Int, len(credential.Names))
+	for i, name := range credential.Names {
+		signed[name] = new(big.Int).SetBytes(credential.Values[i])
+	}
+	return receiver.GetCredential(new(big.Int).SetBytes(credential.V),
+		new(big.Int).SetBytes(credential.E), new(big.Int).SetBytes(credential.S), signed)
+}
+
+// ProveCredential shows the credential to the server - it discloses the attributes and
+// proves the predicates of the server's presentation request - and returns whether
+// the server accepted it.
+func (c *AnonCredsClient) ProveCredential(credential *anoncreds.Credential) (bool, error) {
+	if err := c.openStream(); err != nil {
+		return false, err
+	}
+	defer c.closeStream()
+
+	msg := &pb.Message{
+		ClientId:      c.id,
+		Schema:        pb.SchemaType_ANONCREDS_SHOW,
+		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Content:       &pb.Message_Empty{&pb.EmptyMsg{}},
+	}
+	resp, err := c.getResponseTo(msg)
+	if err != nil {
+		return false, err
+	}
+	request, err := toPresentationRequest(resp.GetAnonCredsPresentationRequest())
+	if err != nil {
+		return false, err
+	}
+	if c.requestPolicy != nil {
+		if err := c.requestPolicy(request); err != nil {
+			return false, err
+		}
+	}
+	prover, err := anoncreds.NewPresentationProver(c.pubKey, credential, request)
+	if err != nil {
+		return false, err
+	}
+
+	proofRandomData, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	data := &pb.AnonCredsProofRandomData{
+		V: proofRandomData.V.Bytes(),
+		T: proofRandomData.T.Bytes(),
+	}
+	for _, name := range request.Disclosed {
+		data.DisclosedValues = append(data.DisclosedValues, credential.Attributes[name].Bytes())
+	}
+	for _, p := range proofRandomData.Predicates {
+		values := []*big.Int{p.C, p.T}
+		values = append(values, p.NonNegative.C...)
+		for _, square := range p.NonNegative.Squares {
+			values = append(values, square.C1, square.T1, square.T2)
+		}
+		data.Predicates = append(data.Predicates, toBytes(values)...)
+	}
+	msg = &pb.Message{
+		Content: &pb.Message_AnonCredsProofRandomData{data},
+	}
+	resp, err = c.getResponseTo(msg)
+	if err != nil {
+		return false, err
+	}
+	challenge := resp.GetBigint()
+	if challenge == nil {
+		return false, fmt.Errorf("challenge expected")
+	}
+
+	proofData := prover.GetProofData(new(big.Int).SetBytes(challenge.X1))
+	pData := &pb.AnonCredsProofData{
+		ZE: proofData.ZE.Bytes(),
+		ZS: proofData.ZS.Bytes(),
+	}
+	// responses for the hidden blocks in the order of the blocks
+	for i := 0; i <= len(c.pubKey.Attributes); i++ {
+		if z, ok := proofData.ZM[i]; ok {
+			pData.ZM = append(pData.ZM, z.Bytes())
+		}
+	}
+	for _, p := range proofData.Predicates {
+		values := []*big.Int{p.ZR}
+		for _, square := range p.NonNegative {
+			values = append(values, square.Z, square.W1, square.W2)
+		}
+		pData.Predicates = append(pData.Predicates, toBytes(values)...)
+	}
+	msg = &pb.Message{
+		Content: &pb.Message_AnonCredsProofData{pData},
+	}
+	resp, err = c.getResponseTo(msg)
+	if err != nil {
+		return false, err
+	}
+	return resp.GetStatus().Success, nil
+}
+
+func toPresentationRequest(request *pb.AnonCredsPresentationRequest) (
+	*anoncreds.PresentationRequest, error) {
+	if request == nil || len(request.PredicateAttributes) != len(request.PredicateTypes) ||
+		len(request.PredicateAttributes) != len(request.PredicateBounds) {
+		return nil, fmt.Errorf("presentation request expected")
+	}
+	presentationRequest := &anoncreds.PresentationRequest{
+		Disclosed: request.Disclosed,
+	}
+	for i, name := range request.PredicateAttributes {
+		t, err := anoncreds.ParsePredicateType(request.PredicateTypes[i])
+		if err != nil {
+			return nil, err
+		}
+		presentationRequest.Predicates = append(presentationRequest.Predicates,
+			&anoncreds.Predicate{
+				Attribute: name,
+				Type:      t,
+				Bound:     new(big.Int).SetBytes(request.PredicateBounds[i]),
+			})
+	}
+	return presentationRequest, nil
+}
+
+func toBytes(values []*big.Int) [][]byte {
+	b := make([][]byte, len(values))
+	for i, v := range values {
+		b[i] = v.Bytes()
+	}
+	return b
+}
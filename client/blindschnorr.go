@@ -0,0 +1,164 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/signatures/blindschnorr"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"google.golang.org/grpc"
+	"math/big"
+)
+
+// BlindSchnorrClient obtains blind Schnorr signatures from emmy server (see
+// server.SetBlindSchnorrSigner). The server does not learn the signed messages and cannot
+// link the signatures to the sessions in which they were issued.
+type BlindSchnorrClient struct {
+	genericClient
+	publicKey *blindschnorr.PublicKey
+}
+
+// NewBlindSchnorrClient returns the client which obtains signatures that are valid under
+// publicKey of the server.
+func NewBlindSchnorrClient(conn *grpc.ClientConn, publicKey *blindschnorr.PublicKey,
+	opts ...ClientOption) (*BlindSchnorrClient, error) {
+	genericClient, err := newGenericClient(conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &BlindSchnorrClient{
+		genericClient: *genericClient,
+		publicKey:     publicKey,
+	}, nil
+}
+
+// ObtainSignature runs the issuance protocol with the server and returns the signature
+// of message.
+func (c *BlindSchnorrClient) ObtainSignature(message []byte) (*blindschnorr.Signature, error) {
+	if err := c.openStream(); err != nil {
+		return nil, err
+	}
+	defer c.closeStream()
+
+	user := blindschnorr.NewUser(c.publicKey, message)
+	msg := &pb.Message{
+		ClientId:      c.id,
+		Schema:        pb.SchemaType_BLIND_SCHNORR,
+		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Content:       &pb.Message_Empty{&pb.EmptyMsg{}},
+	}
+	resp, err := c.getResponseTo(msg)
+	if err != nil {
+		return nil, err
+	}
+	commitment := resp.GetBigint()
+	if commitment == nil {
+		return nil, fmt.Errorf("commitment expected")
+	}
+	challenge, err := user.Blind(new(big.Int).SetBytes(commitment.X1))
+	if err != nil {
+		return nil, err
+	}
+
+	msg = &pb.Message{
+		Content: &pb.Message_Bigint{&pb.BigInt{X1: challenge.Bytes()}},
+	}
+	resp, err = c.getResponseTo(msg)
+	if err != nil {
+		return nil, err
+	}
+	response := resp.GetBigint()
+	if response == nil {
+		return nil, fmt.Errorf("response expected")
+	}
+	return user.Unblind(new(big.Int).SetBytes(response.X1))
+}
+
+// PartiallyBlindSchnorrClient obtains partially blind Schnorr signatures from emmy server
+// (see server.SetPartiallyBlindSchnorrSigner), which embed the public information decided
+// by the server.
+type PartiallyBlindSchnorrClient struct {
+	genericClient
+	publicKey *blindschnorr.PublicKey
+}
+
+// NewPartiallyBlindSchnorrClient returns the client which obtains signatures that are valid
+// under publicKey of the server.
+func NewPartiallyBlindSchnorrClient(conn *grpc.ClientConn, publicKey *blindschnorr.PublicKey,
+	opts ...ClientOption) (*PartiallyBlindSchnorrClient, error) {
+	genericClient, err := newGenericClient(conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &PartiallyBlindSchnorrClient{
+		genericClient: *genericClient,
+		publicKey:     publicKey,
+	}, nil
+}
+
+// ObtainSignature runs the issuance protocol with the server and returns the signature of
+// message. The server can embed other public information than the requested info, thus
+// Info of the returned signature needs to be checked.
+func (c *PartiallyBlindSchnorrClient) ObtainSignature(info, message []byte) (
+	*blindschnorr.PartiallyBlindSignature, error) {
+	if err := c.openStream(); err != nil {
+		return nil, err
+	}
+	defer c.closeStream()
+
+	msg := &pb.Message{
+		ClientId:      c.id,
+		Schema:        pb.SchemaType_PARTIALLY_BLIND_SCHNORR,
+		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Content: &pb.Message_PartiallyBlindSchnorrRequest{
+			&pb.PartiallyBlindSchnorrRequest{Info: info},
+		},
+	}
+	resp, err := c.getResponseTo(msg)
+	if err != nil {
+		return nil, err
+	}
+	commitment := resp.GetPartiallyBlindSchnorrCommitment()
+	if commitment == nil {
+		return nil, fmt.Errorf("commitment expected")
+	}
+	user := blindschnorr.NewPartiallyBlindUser(c.publicKey, commitment.Info, message)
+	challenge, err := user.Blind(new(big.Int).SetBytes(commitment.A),
+		new(big.Int).SetBytes(commitment.B))
+	if err != nil {
+		return nil, err
+	}
+
+	msg = &pb.Message{
+		Content: &pb.Message_Bigint{&pb.BigInt{X1: challenge.Bytes()}},
+	}
+	resp, err = c.getResponseTo(msg)
+	if err != nil {
+		return nil, err
+	}
+	data := resp.GetPartiallyBlindSchnorrResponse()
+	if data == nil {
+		return nil, fmt.Errorf("response expected")
+	}
+	return user.Unblind(&blindschnorr.PartiallyBlindResponse{
+		R: new(big.Int).SetBytes(data.R),
+		C: new(big.Int).SetBytes(data.C),
+		S: new(big.Int).SetBytes(data.S),
+		D: new(big.Int).SetBytes(data.D),
+	})
+}
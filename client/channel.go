@@ -0,0 +1,145 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"github.com/xlab-si/emmy/attestation"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	pb "github.com/xlab-si/emmy/protobuf"
+)
+
+// hybridKEMHandshake runs the hybrid key exchange at the beginning of the stream and
+// sets the cipher which encrypts the rest of the messages.
+func (c *genericClient) hybridKEMHandshake() error {
+	key, err := encryption.NewHybridKEMPrivateKey()
+	if err != nil {
+		return err
+	}
+	pubKey := key.PublicKey()
+	initMsg := &pb.Message{
+		ClientId: c.id,
+		Content: &pb.Message_HybridKemInit{
+			&pb.HybridKEMInit{
+				X25519: pubKey.X25519,
+				MLKEM:  pubKey.MLKEM,
+			},
+		},
+	}
+	if err := c.stream.Send(initMsg); err != nil {
+		return err
+	}
+
+	resp, err := c.stream.Recv()
+	if err != nil {
+		return err
+	}
+	if resp.ProtocolError != "" {
+		return errors.New(resp.ProtocolError)
+	}
+	kemResp := resp.GetHybridKemResponse()
+	if kemResp == nil {
+		return fmt.Errorf("server did not respond to the key exchange")
+	}
+
+	sharedKey, err := key.Decapsulate(&encryption.HybridKEMCiphertext{
+		X25519: kemResp.X25519,
+		MLKEM:  kemResp.MLKEM,
+	})
+	if err != nil {
+		return err
+	}
+	c.channel, err = encryption.NewChannelCipher(sharedKey, true)
+	c.kemTranscript = kemTranscript(initMsg.GetHybridKemInit(), kemResp)
+	return err
+}
+
+// kemTranscript returns the messages of the hybrid key exchange, to which the attestation
+// evidence is bound.
+func kemTranscript(init *pb.HybridKEMInit, resp *pb.HybridKEMResponse) []byte {
+	var t []byte
+	for _, b := range [][]byte{init.X25519, init.MLKEM, resp.X25519, resp.MLKEM} {
+		t = append(t, b...)
+	}
+	return t
+}
+
+// attest requests the evidence that the server runs in a TEE and checks it with
+// the client's verifier.
+func (c *genericClient) attest() error {
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	resp, err := c.getResponseTo(&pb.Message{
+		ClientId: c.id,
+		Content: &pb.Message_AttestationRequest{
+			&pb.AttestationRequest{
+				Nonce: nonce,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	evidence := resp.GetAttestationEvidence()
+	if evidence == nil {
+		return fmt.Errorf("server did not respond with attestation evidence")
+	}
+
+	reportData := attestation.ReportData(nonce, c.kemTranscript)
+	return c.attestation.Verify(evidence.Format, evidence.Evidence, reportData)
+}
+
+// encrypt wraps the message into EncryptedMsg. It fails if the key exchange was not
+// completed, so that the message is never sent in the clear.
+func (c *genericClient) encrypt(msg *pb.Message) (*pb.Message, error) {
+	if c.channel == nil {
+		return nil, fmt.Errorf("the stream is not encrypted")
+	}
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Message{
+		Content: &pb.Message_Encrypted{
+			&pb.EncryptedMsg{
+				Ciphertext: c.channel.Seal(data),
+			},
+		},
+	}, nil
+}
+
+func (c *genericClient) decrypt(msg *pb.Message) (*pb.Message, error) {
+	encrypted := msg.GetEncrypted()
+	if c.channel == nil || encrypted == nil {
+		return nil, fmt.Errorf("received unencrypted message")
+	}
+	data, err := c.channel.Open(encrypted.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	msg = &pb.Message{}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
@@ -19,11 +19,16 @@ package client
 
 import (
 	"fmt"
+	"github.com/xlab-si/emmy/attestation"
 	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/zkp/security"
 	"github.com/xlab-si/emmy/log"
 	pb "github.com/xlab-si/emmy/protobuf"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"io"
 	"math/rand"
 	"time"
@@ -31,6 +36,10 @@ import (
 
 var logger log.Logger
 
+// tenantMetadataKey is the gRPC metadata key with the tenant of the client (see WithTenant
+// and server.TenantMetadataKey).
+const tenantMetadataKey = "emmy-tenant"
+
 // init instantiates and configures client logger with default log level.
 func init() {
 	clientLogger, err := log.NewStdoutLogger("client", log.INFO, log.FORMAT_SHORT)
@@ -88,29 +97,63 @@ type genericClient struct {
 	id             int32
 	protocolClient pb.ProtocolClient
 	stream         pb.Protocol_RunClient
+	cancel         context.CancelFunc
+	logger         log.Logger
+	curve          dlog.Curve
+	variant        pb.SchemaVariant
+	timeout        time.Duration
+	rand           *rand.Rand
+	sendHooks      []MessageHook
+	receiveHooks   []MessageHook
+	hybridKEM      bool
+	channel        *encryption.ChannelCipher
+	kemTranscript  []byte
+	attestation    attestation.Verifier
+	security       security.Params
+	tenant         string
+	streamErr      error // set when the stream could not be prepared, no message is sent then
 }
 
-func newGenericClient(conn *grpc.ClientConn) (*genericClient, error) {
-	logger.Debug("Creating the client")
-	client := pb.NewProtocolClient(conn)
-
-	rand.Seed(time.Now().UTC().UnixNano())
-
+func newGenericClient(conn *grpc.ClientConn, opts ...ClientOption) (*genericClient, error) {
 	genClient := genericClient{
-		id:             rand.Int31(),
-		protocolClient: client,
+		protocolClient: pb.NewProtocolClient(conn),
+		logger:         logger,
+		curve:          dlog.P256,
+		variant:        pb.SchemaVariant_SIGMA,
+	}
+	for _, opt := range opts {
+		opt(&genClient)
+	}
+
+	genClient.logger.Debug("Creating the client")
+	if genClient.rand == nil {
+		genClient.rand = rand.New(rand.NewSource(time.Now().UTC().UnixNano()))
 	}
+	genClient.id = genClient.rand.Int31()
 
-	logger.Debugf("New GenericClient spawned (%v)", genClient.id)
+	genClient.logger.Debugf("New GenericClient spawned (%v)", genClient.id)
 	return &genClient, nil
 }
 
 func (c *genericClient) send(msg *pb.Message) error {
-	if err := c.stream.Send(msg); err != nil {
+	if c.streamErr != nil {
+		return c.streamErr
+	}
+	msg, err := runHooks(c.sendHooks, c.id, msg)
+	if err != nil {
+		return fmt.Errorf("[Client %v] Send hook failed: %v", c.id, err)
+	}
+	wireMsg := msg
+	if c.hybridKEM {
+		if wireMsg, err = c.encrypt(msg); err != nil {
+			return fmt.Errorf("[Client %v] Error encrypting message: %v", c.id, err)
+		}
+	}
+	if err := c.stream.Send(wireMsg); err != nil {
 		return fmt.Errorf("[Client %v] Error sending message: %v", c.id, err)
 	}
-	logger.Infof("[Client %v] Successfully sent request of type %T", c.id, msg.Content)
-	logger.Debugf("%+v", msg)
+	c.logger.Infof("[Client %v] Successfully sent request of type %T", c.id, msg.Content)
+	c.logger.Debugf("%+v", msg)
 
 	return nil
 }
@@ -122,15 +165,35 @@ func (c *genericClient) receive() (*pb.Message, error) {
 	} else if err != nil {
 		return nil, fmt.Errorf("[Client %v] An error ocurred: %v", c.id, err)
 	}
+	if c.hybridKEM && resp.ProtocolError == "" {
+		if resp, err = c.decrypt(resp); err != nil {
+			return nil, fmt.Errorf("[Client %v] Error decrypting message: %v", c.id, err)
+		}
+	}
+	resp, err = runHooks(c.receiveHooks, c.id, resp)
+	if err != nil {
+		return nil, fmt.Errorf("[Client %v] Receive hook failed: %v", c.id, err)
+	}
 	if resp.ProtocolError != "" {
 		return nil, fmt.Errorf(resp.ProtocolError)
 	}
-	logger.Infof("[Client %v] Received response of type %T from the stream", c.id, resp.Content)
-	logger.Debugf("%+v", resp)
+	c.logger.Infof("[Client %v] Received response of type %T from the stream", c.id, resp.Content)
+	c.logger.Debugf("%+v", resp)
 
 	return resp, nil
 }
 
+// runHooks passes the message through all the hooks and returns the resulting message.
+func runHooks(hooks []MessageHook, clientId int32, msg *pb.Message) (*pb.Message, error) {
+	var err error
+	for _, hook := range hooks {
+		if msg, err = hook(clientId, msg); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
 // getResponseTo sends a message msg to emmy server and retrieves the server's response.
 func (c *genericClient) getResponseTo(msg *pb.Message) (*pb.Message, error) {
 	if err := c.send(msg); err != nil {
@@ -143,12 +206,40 @@ func (c *genericClient) getResponseTo(msg *pb.Message) (*pb.Message, error) {
 // the protocol client.
 // This function has to be called explicitly at the beginning of the protocol execution function.
 func (c *genericClient) openStream() error {
-	stream, err := c.protocolClient.Run(context.Background())
+	return c.openStreamContext(context.Background())
+}
+
+// openStreamContext opens the stream like openStream, but the stream is also closed when
+// ctx is done.
+func (c *genericClient) openStreamContext(ctx context.Context) error {
+	c.cancel = nil
+	if c.timeout > 0 {
+		ctx, c.cancel = context.WithTimeout(ctx, c.timeout)
+	}
+	if c.tenant != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, tenantMetadataKey, c.tenant)
+	}
+	stream, err := c.protocolClient.Run(ctx)
 	if err != nil {
 		return fmt.Errorf("[Client %v] Error opening stream: %v", c.id, err)
 	}
 
 	c.stream = stream
+	c.channel = nil
+	c.kemTranscript = nil
+	c.streamErr = nil
+	if c.hybridKEM {
+		if err := c.hybridKEMHandshake(); err != nil {
+			c.streamErr = fmt.Errorf("[Client %v] Hybrid key exchange failed: %v", c.id, err)
+			return c.streamErr
+		}
+	}
+	if c.attestation != nil {
+		if err := c.attest(); err != nil {
+			c.streamErr = fmt.Errorf("[Client %v] Attestation failed: %v", c.id, err)
+			return c.streamErr
+		}
+	}
 	return nil
 }
 
@@ -158,6 +249,9 @@ func (c *genericClient) openStream() error {
 // Note that closing the stream does not close the corresponding connection to the server,
 // as it should be done externally.
 func (c *genericClient) closeStream() error {
+	if c.cancel != nil {
+		defer c.cancel()
+	}
 	if err := c.stream.CloseSend(); err != nil {
 		return fmt.Errorf("[Client %v] Error closing stream: %v", c.id, err)
 	}
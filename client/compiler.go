@@ -0,0 +1,93 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/zkp/compiler"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"math/big"
+)
+
+// challengeVariant returns the variant of the protocol set with WithProtocolVariant - the
+// protobuf value is decoded once here and the clients only deal with compiler.Variant.
+func (c *genericClient) challengeVariant() compiler.Variant {
+	switch c.variant {
+	case pb.SchemaVariant_ZKP:
+		return compiler.ZKP{}
+	case pb.SchemaVariant_ZKPOK:
+		return compiler.ZKPOK{}
+	default:
+		return compiler.Sigma{}
+	}
+}
+
+// openChallengeCommitment runs the opening phase of ZKP and ZKPOK (see package compiler)
+// for any sigma protocol: h is sent in initMsg and the server's commitment to the challenge
+// is stored. It returns the message which is to be filled with the first message of the
+// sigma protocol - in sigma protocol this is initMsg itself.
+func (c *genericClient) openChallengeCommitment(initMsg *pb.Message,
+	receiver compiler.ChallengeReceiver) (*pb.Message, error) {
+	h := receiver.GetOpeningMsg()
+	if h == nil { // sigma protocol
+		return initMsg, nil
+	}
+
+	initMsg.Content = &pb.Message_PedersenFirst{
+		&pb.PedersenFirst{H: h.Bytes()},
+	}
+	resp, err := c.getResponseTo(initMsg)
+	if err != nil {
+		return nil, err
+	}
+	receiver.SetCommitment(new(big.Int).SetBytes(resp.GetBigint().GetX1()))
+	return &pb.Message{}, nil
+}
+
+// getChallenge returns the challenge from the server's response and checks that it is
+// the one the server committed to.
+func (c *genericClient) getChallenge(resp *pb.Message,
+	receiver compiler.ChallengeReceiver) (*big.Int, error) {
+	decommitment := resp.GetPedersenDecommitment()
+	challenge := new(big.Int).SetBytes(decommitment.GetX())
+	r := new(big.Int).SetBytes(decommitment.GetR())
+	if !receiver.CheckChallenge(challenge, r) {
+		return nil, fmt.Errorf("Decommitment failed")
+	}
+	return challenge, nil
+}
+
+// sendProofData sends the last message of the sigma protocol and in ZKPOK reveals
+// the trapdoor. It returns the server's response.
+func (c *genericClient) sendProofData(msg *pb.Message,
+	receiver compiler.ChallengeReceiver) (*pb.Message, error) {
+	resp, err := c.getResponseTo(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if trapdoor := receiver.GetTrapdoor(); trapdoor != nil { // only in ZKPOK
+		msg = &pb.Message{
+			Content: &pb.Message_Trapdoor{
+				&pb.Trapdoor{Trapdoor: trapdoor.Bytes()},
+			},
+		}
+		return c.getResponseTo(msg)
+	}
+	return resp, nil
+}
@@ -31,8 +31,9 @@ type CSPaillierClient struct {
 }
 
 // NewCSPaillierClient returns an initialized struct of type CSPaillierClient.
-func NewCSPaillierClient(conn *grpc.ClientConn, pubKeyPath string, m, l *big.Int) (*CSPaillierClient, error) {
-	genericClient, err := newGenericClient(conn)
+func NewCSPaillierClient(conn *grpc.ClientConn, pubKeyPath string, m, l *big.Int,
+	opts ...ClientOption) (*CSPaillierClient, error) {
+	genericClient, err := newGenericClient(conn, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -75,7 +76,10 @@ func (c *CSPaillierClient) Run() error {
 }
 
 func (c *CSPaillierClient) open(u, e, v *big.Int) error {
-	l, delta := c.encryptor.GetOpeningMsg(c.m)
+	l, delta, err := c.encryptor.GetOpeningMsg(c.m)
+	if err != nil {
+		return err
+	}
 
 	opening := pb.CSPaillierOpening{
 		U:     u.Bytes(),
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package client
+
+import (
+	"fmt"
+	"github.com/golang/protobuf/ptypes/any"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"google.golang.org/grpc"
+)
+
+// ExtensionClient communicates with a scheme which is registered with emmy server
+// as an extension (see server.Extension). The messages of the scheme are protobuf
+// Any payloads which are interpreted by the extension.
+type ExtensionClient struct {
+	genericClient
+	scheme  string
+	started bool
+}
+
+func NewExtensionClient(conn *grpc.ClientConn, scheme string,
+	opts ...ClientOption) (*ExtensionClient, error) {
+	genericClient, err := newGenericClient(conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExtensionClient{
+		genericClient: *genericClient,
+		scheme:        scheme,
+	}, nil
+}
+
+// Exchange sends the payload to the extension and returns the payload of its response.
+// The first call opens the stream, which needs to be closed with Close once the scheme
+// is finished.
+func (c *ExtensionClient) Exchange(payload *any.Any) (*any.Any, error) {
+	msg := &pb.Message{
+		Content: &pb.Message_Extension{
+			&pb.ExtensionMsg{
+				Scheme:  c.scheme,
+				Payload: payload,
+			},
+		},
+	}
+
+	if !c.started {
+		if err := c.openStream(); err != nil {
+			return nil, err
+		}
+		c.started = true
+		msg.ClientId = c.id
+		msg.Schema = pb.SchemaType_EXTENSION
+	}
+
+	resp, err := c.getResponseTo(msg)
+	if err != nil {
+		return nil, err
+	}
+	data := resp.GetExtension()
+	if data == nil {
+		return nil, fmt.Errorf("[Client %v] Expected extension message, got %T", c.id,
+			resp.Content)
+	}
+	return data.Payload, nil
+}
+
+// Close closes the stream with the server, ending the scheme.
+func (c *ExtensionClient) Close() error {
+	if !c.started {
+		return nil
+	}
+	c.started = false
+	return c.closeStream()
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"google.golang.org/grpc"
+	"math/big"
+)
+
+type GPSClient struct {
+	genericClient
+	prover    *dlogproofs.GPSProver
+	publicKey *big.Int
+}
+
+// NewGPSClient returns a client which identifies itself with the GPS scheme, where
+// secret is from [0, 2^dlogproofs.GPSSecretBitLen) (see GPSParams.GenerateKey).
+func NewGPSClient(conn *grpc.ClientConn, params *dlogproofs.GPSParams, secret *big.Int,
+	opts ...ClientOption) (*GPSClient, error) {
+	prover, err := dlogproofs.NewGPSProver(params, secret)
+	if err != nil {
+		return nil, err
+	}
+	genericClient, err := newGenericClient(conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := prover.SetSecurity(genericClient.security); err != nil {
+		return nil, err
+	}
+
+	return &GPSClient{
+		genericClient: *genericClient,
+		prover:        prover,
+		publicKey:     params.PublicKey(secret),
+	}, nil
+}
+
+// Run executes the identification and returns whether the server accepted it.
+func (c *GPSClient) Run() (bool, error) {
+	c.openStream()
+	defer c.closeStream()
+
+	x, err := c.prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	params := c.prover.Params
+	msg := &pb.Message{
+		ClientId:      c.id,
+		Schema:        pb.SchemaType_GPS,
+		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Content: &pb.Message_GpsProofRandomData{
+			&pb.GPSProofRandomData{
+				N: params.N.Bytes(),
+				G: params.G.Bytes(),
+				V: c.publicKey.Bytes(),
+				X: x.Bytes(),
+			},
+		},
+	}
+	resp, err := c.getResponseTo(msg)
+	if err != nil {
+		return false, err
+	}
+
+	y, err := c.prover.GetProofData(new(big.Int).SetBytes(resp.GetBigint().X1))
+	if err != nil {
+		return false, err
+	}
+	msg = &pb.Message{
+		Content: &pb.Message_Bigint{&pb.BigInt{X1: y.Bytes()}},
+	}
+	resp, err = c.getResponseTo(msg)
+	if err != nil {
+		return false, err
+	}
+	return resp.GetStatus().Success, nil
+}
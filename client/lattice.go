@@ -0,0 +1,89 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"github.com/xlab-si/emmy/crypto/lattice"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"google.golang.org/grpc"
+)
+
+type LatticeShortVectorClient struct {
+	genericClient
+	statement *lattice.ShortVectorStatement
+	secret    lattice.PolyVec
+}
+
+// NewLatticeShortVectorClient returns a client which proves the knowledge of short secret
+// such that statement.A * secret = statement.T.
+func NewLatticeShortVectorClient(conn *grpc.ClientConn, statement *lattice.ShortVectorStatement,
+	secret lattice.PolyVec, opts ...ClientOption) (*LatticeShortVectorClient, error) {
+	genericClient, err := newGenericClient(conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &LatticeShortVectorClient{
+		genericClient: *genericClient,
+		statement:     statement,
+		secret:        secret,
+	}, nil
+}
+
+// Run sends the statement together with the non-interactive proof and returns whether
+// the server accepted it.
+func (c *LatticeShortVectorClient) Run() (bool, error) {
+	proof, err := lattice.ProveShortVector(c.statement, c.secret)
+	if err != nil {
+		return false, err
+	}
+
+	c.openStream()
+	defer c.closeStream()
+
+	encode := func(v lattice.PolyVec) [][]byte {
+		encoded := make([][]byte, len(v))
+		for i, p := range v {
+			encoded[i] = p.Bytes()
+		}
+		return encoded
+	}
+	var a [][]byte
+	for _, row := range c.statement.A {
+		a = append(a, encode(row)...)
+	}
+	msg := &pb.Message{
+		ClientId:      c.id,
+		Schema:        pb.SchemaType_LATTICE_SHORT_VECTOR,
+		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Content: &pb.Message_LatticeShortVectorProof{
+			&pb.LatticeShortVectorProof{
+				Width:     int32(len(c.secret)),
+				A:         a,
+				T:         encode(c.statement.T),
+				Bound:     c.statement.Bound,
+				Challenge: proof.Challenge.Bytes(),
+				Z:         encode(proof.Z),
+			},
+		},
+	}
+	resp, err := c.getResponseTo(msg)
+	if err != nil {
+		return false, err
+	}
+	return resp.GetStatus().Success, nil
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package client
+
+import (
+	"github.com/xlab-si/emmy/attestation"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/zkp/security"
+	"github.com/xlab-si/emmy/log"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"math/rand"
+	"time"
+)
+
+// ClientOption configures optional behavior of the clients. Options are passed as the last
+// arguments of client constructors, for example:
+//
+//	NewSchnorrECClient(conn, params, secret, WithCurve(dlog.P384),
+//		WithProtocolVariant(pb.SchemaVariant_ZKP))
+//
+// Options which are not relevant for the client are ignored.
+type ClientOption func(*genericClient)
+
+// WithCurve sets the elliptic curve used by the clients of EC based protocols (default is P256).
+// The curve is proposed to the server at the beginning of each session - the session fails
+// if the server does not support it.
+func WithCurve(curve dlog.Curve) ClientOption {
+	return func(c *genericClient) {
+		c.curve = curve
+	}
+}
+
+// WithProtocolVariant sets the variant (sigma, ZKP or ZKPOK) of the protocol to be run
+// (default is sigma).
+func WithProtocolVariant(variant pb.SchemaVariant) ClientOption {
+	return func(c *genericClient) {
+		c.variant = variant
+	}
+}
+
+// WithTimeout limits the duration of each protocol run. By default protocols are not limited.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *genericClient) {
+		c.timeout = timeout
+	}
+}
+
+// WithHybridKEM protects each protocol run with a hybrid X25519 + ML-KEM (Kyber) key
+// exchange, independent of TLS - all the messages are encrypted with the derived key, so the
+// recorded transcripts cannot be decrypted even when X25519 is broken by a quantum computer.
+func WithHybridKEM() ClientOption {
+	return func(c *genericClient) {
+		c.hybridKEM = true
+	}
+}
+
+// WithAttestation requires the evidence that the server runs in a trusted execution
+// environment at the beginning of each protocol run - the evidence is checked by the verifier
+// before any protocol message is sent, and the run fails if it is not accepted. When combined
+// with WithHybridKEM, the evidence is bound to the key exchange, which proves that the session
+// key is held by the attested code (and not for example by a TLS terminating proxy).
+func WithAttestation(verifier attestation.Verifier) ClientOption {
+	return func(c *genericClient) {
+		c.attestation = verifier
+	}
+}
+
+// WithSecurityParams sets the security parameters of the proofs (see package
+// crypto/zkp/security), which need to match the parameters of the server. Currently they
+// apply to GPS and Stern clients.
+func WithSecurityParams(params security.Params) ClientOption {
+	return func(c *genericClient) {
+		c.security = params
+	}
+}
+
+// WithTenant sends the tenant (for example the organization or the application) with each
+// protocol run, so that the server can apply the limits of the tenant to the sessions (see
+// server.Tenant).
+func WithTenant(tenant string) ClientOption {
+	return func(c *genericClient) {
+		c.tenant = tenant
+	}
+}
+
+// WithRand sets the source of randomness for generating client IDs, which is useful
+// for reproducible logs in tests. Note that it is not used for any cryptographic purpose.
+func WithRand(source rand.Source) ClientOption {
+	return func(c *genericClient) {
+		c.rand = rand.New(source)
+	}
+}
+
+// WithLogger sets the logger of the client (default is the logger of this package,
+// see SetLogger).
+func WithLogger(lgr log.Logger) ClientOption {
+	return func(c *genericClient) {
+		c.logger = lgr
+	}
+}
+
+// MessageHook is called with each message that the client sends to or receives from
+// the server. It can be used for logging, metrics or for transformation of the message - the
+// message returned by the hook is passed on instead of the original one. If the hook returns
+// an error, the protocol is stopped.
+type MessageHook func(clientId int32, msg *pb.Message) (*pb.Message, error)
+
+// WithSendHook adds a hook which is called before each message is sent to the server.
+func WithSendHook(hook MessageHook) ClientOption {
+	return func(c *genericClient) {
+		c.sendHooks = append(c.sendHooks, hook)
+	}
+}
+
+// WithReceiveHook adds a hook which is called after each message is received from the server
+// (before the message is checked for protocol errors).
+func WithReceiveHook(hook MessageHook) ClientOption {
+	return func(c *genericClient) {
+		c.receiveHooks = append(c.receiveHooks, hook)
+	}
+}
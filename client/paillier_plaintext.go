@@ -0,0 +1,163 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/encproofs"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"google.golang.org/grpc"
+	"math/big"
+)
+
+type PaillierPlaintextClient struct {
+	genericClient
+	pubKey *encryption.PaillierPubKey
+	group  *groups.SchnorrGroup // nil if the plaintext is not committed
+	m      *big.Int
+	r      *big.Int
+	c      *big.Int
+}
+
+// NewPaillierPlaintextClient returns a client which encrypts m with Paillier public key
+// and proves to the server that it knows the plaintext of the ciphertext.
+func NewPaillierPlaintextClient(conn *grpc.ClientConn, pubKey *encryption.PaillierPubKey,
+	m *big.Int, opts ...ClientOption) (*PaillierPlaintextClient, error) {
+	genericClient, err := newGenericClient(conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := common.GetRandomZnInvertibleElement(pubKey.GetN())
+	if err != nil {
+		return nil, err
+	}
+	c, err := encryption.NewPubPaillier(pubKey).EncryptWithR(m, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaillierPlaintextClient{
+		genericClient: *genericClient,
+		pubKey:        pubKey,
+		m:             m,
+		r:             r,
+		c:             c,
+	}, nil
+}
+
+// NewPaillierCommittedPlaintextClient returns a client which encrypts m with Paillier public
+// key, commits to m with Pedersen commitment in the group and proves to the server that
+// the ciphertext encrypts the committed value. This way the value (for example the secret
+// of a pseudonym) can be escrowed - the holder of the Paillier secret key can recover it.
+func NewPaillierCommittedPlaintextClient(conn *grpc.ClientConn, group *groups.SchnorrGroup,
+	pubKey *encryption.PaillierPubKey, m *big.Int,
+	opts ...ClientOption) (*PaillierPlaintextClient, error) {
+	c, err := NewPaillierPlaintextClient(conn, pubKey, m, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.group = group
+	return c, nil
+}
+
+// GetCiphertext returns the ciphertext of which the plaintext knowledge is proved.
+func (c *PaillierPlaintextClient) GetCiphertext() *big.Int {
+	return c.c
+}
+
+// Run runs the proof of plaintext knowledge. It returns whether the server accepted the proof.
+func (c *PaillierPlaintextClient) Run() (bool, error) {
+	c.openStream()
+	defer c.closeStream()
+
+	initMsg := &pb.Message{
+		ClientId: c.id,
+		Schema:   pb.SchemaType_PAILLIER_PLAINTEXT,
+		Content:  &pb.Message_Empty{&pb.EmptyMsg{}},
+	}
+	resp, err := c.getResponseTo(initMsg)
+	if err != nil {
+		return false, err
+	}
+	h := new(big.Int).SetBytes(resp.GetPedersenFirst().H)
+
+	randomData := &pb.PaillierPlaintextProofRandomData{
+		N: c.pubKey.GetN().Bytes(),
+		G: c.pubKey.GetG().Bytes(),
+		C: c.c.Bytes(),
+	}
+	var getProofData func(challenge *big.Int) *pb.PaillierPlaintextProofData
+	if c.group == nil {
+		prover, err := encproofs.NewPaillierPlaintextProver(c.pubKey, c.m, c.r)
+		if err != nil {
+			return false, err
+		}
+		a, err := prover.GetProofRandomData()
+		if err != nil {
+			return false, err
+		}
+		randomData.A = a.Bytes()
+		getProofData = func(challenge *big.Int) *pb.PaillierPlaintextProofData {
+			z, w := prover.GetProofData(challenge)
+			return &pb.PaillierPlaintextProofData{Z: z.Bytes(), W: w.Bytes()}
+		}
+	} else {
+		t, err := common.GetRandomInt(c.group.Q)
+		if err != nil {
+			return false, err
+		}
+		prover, err := encproofs.NewPaillierCommittedPlaintextProver(c.pubKey, c.group, h,
+			c.m, c.r, t)
+		if err != nil {
+			return false, err
+		}
+		a, commitmentA, err := prover.GetProofRandomData()
+		if err != nil {
+			return false, err
+		}
+		commitment := c.group.Mul(c.group.Exp(c.group.G, c.m), c.group.Exp(h, t))
+		randomData.A = a.Bytes()
+		randomData.Commitment = commitment.Bytes()
+		randomData.CommitmentA = commitmentA.Bytes()
+		getProofData = func(challenge *big.Int) *pb.PaillierPlaintextProofData {
+			z, w, v := prover.GetProofData(challenge)
+			return &pb.PaillierPlaintextProofData{Z: z.Bytes(), W: w.Bytes(), V: v.Bytes()}
+		}
+	}
+
+	msg := &pb.Message{
+		Content: &pb.Message_PaillierPlaintextProofRandomData{randomData},
+	}
+	resp, err = c.getResponseTo(msg)
+	if err != nil {
+		return false, err
+	}
+	challenge := new(big.Int).SetBytes(resp.GetBigint().X1)
+
+	msg = &pb.Message{
+		Content: &pb.Message_PaillierPlaintextProofData{getProofData(challenge)},
+	}
+	resp, err = c.getResponseTo(msg)
+	if err != nil {
+		return false, err
+	}
+	return resp.GetStatus().Success, nil
+}
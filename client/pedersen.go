@@ -32,14 +32,14 @@ type PedersenClient struct {
 }
 
 // NewPedersenClient returns an initialized struct of type PedersenClient.
-func NewPedersenClient(conn *grpc.ClientConn, variant pb.SchemaVariant, dlog *groups.SchnorrGroup,
-	val *big.Int) (*PedersenClient, error) {
-	genericClient, err := newGenericClient(conn)
+func NewPedersenClient(conn *grpc.ClientConn, dlog *groups.SchnorrGroup, val *big.Int,
+	opts ...ClientOption) (*PedersenClient, error) {
+	genericClient, err := newGenericClient(conn, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	validateVariant(variant)
+	validateVariant(genericClient.variant)
 
 	return &PedersenClient{
 		pedersenCommonClient: pedersenCommonClient{genericClient: *genericClient},
@@ -63,7 +63,7 @@ func (c *PedersenClient) Run() error {
 
 	commitment, err := c.committer.GetCommitMsg(c.val)
 	if err != nil {
-		logger.Criticalf("could not generate committment message: %v", err)
+		c.logger.Criticalf("could not generate committment message: %v", err)
 		return err
 	}
 
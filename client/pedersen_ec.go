@@ -19,7 +19,6 @@ package client
 
 import (
 	"github.com/xlab-si/emmy/crypto/commitments"
-	"github.com/xlab-si/emmy/crypto/dlog"
 	pb "github.com/xlab-si/emmy/protobuf"
 	"github.com/xlab-si/emmy/types"
 	"google.golang.org/grpc"
@@ -33,15 +32,16 @@ type PedersenECClient struct {
 }
 
 // NewPedersenECClient returns an initialized struct of type PedersenECClient.
-func NewPedersenECClient(conn *grpc.ClientConn, v *big.Int, curveType dlog.Curve) (*PedersenECClient, error) {
-	genericClient, err := newGenericClient(conn)
+func NewPedersenECClient(conn *grpc.ClientConn, v *big.Int,
+	opts ...ClientOption) (*PedersenECClient, error) {
+	genericClient, err := newGenericClient(conn, opts...)
 	if err != nil {
 		return nil, err
 	}
 
 	return &PedersenECClient{
 		pedersenCommonClient: pedersenCommonClient{genericClient: *genericClient},
-		committer:            commitments.NewPedersenECCommitter(curveType),
+		committer:            commitments.NewPedersenECCommitter(genericClient.curve),
 		val:                  v,
 	}, nil
 }
@@ -60,7 +60,7 @@ func (c *PedersenECClient) Run() error {
 
 	commitment, err := c.committer.GetCommitMsg(c.val)
 	if err != nil {
-		logger.Criticalf("could not generate committment message: %v", err)
+		c.logger.Criticalf("could not generate committment message: %v", err)
 		return nil
 	}
 
@@ -80,6 +80,7 @@ func (c *PedersenECClient) getH() (*pb.ECGroupElement, error) {
 	initMsg := &pb.Message{
 		ClientId: c.id,
 		Schema:   pb.SchemaType_PEDERSEN_EC,
+		Curve:    int32(c.curve),
 		Content:  &pb.Message_Empty{&pb.EmptyMsg{}},
 	}
 
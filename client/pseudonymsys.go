@@ -20,38 +20,51 @@ package client
 import (
 	"errors"
 	"fmt"
-	"github.com/xlab-si/emmy/config"
 	"github.com/xlab-si/emmy/crypto/common"
 	"github.com/xlab-si/emmy/crypto/groups"
 	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/anoncreds"
 	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
 	pb "github.com/xlab-si/emmy/protobuf"
-	"github.com/xlab-si/emmy/types"
 	"google.golang.org/grpc"
 	"math/big"
 )
 
 type PseudonymsysClient struct {
 	genericClient
-	group *groups.SchnorrGroup
+	group     *groups.SchnorrGroup
+	wallet    *pseudonymsys.ConsentWallet
+	recipient string
 }
 
-func NewPseudonymsysClient(conn *grpc.ClientConn) (*PseudonymsysClient, error) {
-	genericClient, err := newGenericClient(conn)
+// NewPseudonymsysClient returns a client of the pseudonym system with the given parameters
+// (see config.LoadPseudonymsysParams).
+func NewPseudonymsysClient(conn *grpc.ClientConn, params *pseudonymsys.Params,
+	opts ...ClientOption) (*PseudonymsysClient, error) {
+	if err := params.Validate(); err != nil {
+		return nil, fmt.Errorf("Invalid pseudonymsys parameters: %v", err)
+	}
+	genericClient, err := newGenericClient(conn, opts...)
 	if err != nil {
 		return nil, err
 	}
-	group := config.LoadGroup("pseudonymsys")
 
 	return &PseudonymsysClient{
-		group:         group,
+		group:         params.Group,
 		genericClient: *genericClient,
 	}, nil
 }
 
+// SetConsentWallet sets the wallet where consent receipts for the credentials presented
+// to the recipient (the organization behind the connection) are stored.
+func (c *PseudonymsysClient) SetConsentWallet(wallet *pseudonymsys.ConsentWallet, recipient string) {
+	c.wallet = wallet
+	c.recipient = recipient
+}
+
 // GenerateMasterKey generates a master secret key, representing a random integer betweeen
 // 0 and order of the group. This key will be used subsequently by all the protocols in the scheme.
-func (c *PseudonymsysClient) GenerateMasterKey() *big.Int {
+func (c *PseudonymsysClient) GenerateMasterKey() (*big.Int, error) {
 	return common.GetRandomInt(c.group.Q)
 }
 
@@ -71,22 +84,29 @@ func (c *PseudonymsysClient) GenerateNym(userSecret *big.Int,
 
 	// Note that as there is very little logic needed (besides what is in DLog equality
 	// prover), everything is implemented here (no pseudoynymsys nym gen client).
-	gamma := common.GetRandomInt(prover.Group.Q)
+	gamma, err := common.GetRandomInt(prover.Group.Q)
+	if err != nil {
+		return nil, err
+	}
 	nymA := c.group.Exp(c.group.G, gamma)
 	nymB := c.group.Exp(nymA, userSecret)
 
 	// Prove now that log_nymA(nymB) = log_blindedA(blindedB):
 	// g1 = nymA, g2 = blindedA
-	x1, x2 := prover.GetProofRandomData(userSecret, nymA, caCertificate.BlindedA)
+	x1, x2, err := prover.GetProofRandomData(userSecret, nymA, caCertificate.BlindedA)
+	if err != nil {
+		return nil, err
+	}
 	pRandomData := pb.PseudonymsysNymGenProofRandomData{
-		X1: x1.Bytes(),
-		A1: nymA.Bytes(),
-		B1: nymB.Bytes(),
-		X2: x2.Bytes(),
-		A2: caCertificate.BlindedA.Bytes(),
-		B2: caCertificate.BlindedB.Bytes(),
-		R:  caCertificate.R.Bytes(),
-		S:  caCertificate.S.Bytes(),
+		X1:        x1.Bytes(),
+		A1:        nymA.Bytes(),
+		B1:        nymB.Bytes(),
+		X2:        x2.Bytes(),
+		A2:        caCertificate.BlindedA.Bytes(),
+		B2:        caCertificate.BlindedB.Bytes(),
+		R:         caCertificate.R.Bytes(),
+		S:         caCertificate.S.Bytes(),
+		Algorithm: int32(caCertificate.Algorithm),
 	}
 
 	initMsg := &pb.Message{
@@ -108,13 +128,15 @@ func (c *PseudonymsysClient) GenerateNym(userSecret *big.Int,
 	}
 	challenge := new(big.Int).SetBytes(pedersenDecommitment.X)
 
-	z := prover.GetProofData(challenge)
+	z, err := prover.GetProofData(challenge)
+	if err != nil {
+		return nil, err
+	}
 
 	msg := &pb.Message{
 		Content: &pb.Message_SchnorrProofData{
 			&pb.SchnorrProofData{
 				Z: z.Bytes(),
-				//Trapdoor: trapdoor.Bytes(),
 			},
 		},
 	}
@@ -140,14 +162,29 @@ func (c *PseudonymsysClient) ObtainCredential(userSecret *big.Int,
 	c.openStream()
 	defer c.closeStream()
 
-	gamma := common.GetRandomInt(c.group.Q)
-	equalityVerifier1 := dlogproofs.NewDLogEqualityBTranscriptVerifier(c.group, gamma)
-	equalityVerifier2 := dlogproofs.NewDLogEqualityBTranscriptVerifier(c.group, gamma)
+	gamma, err := common.GetRandomInt(c.group.Q)
+	if err != nil {
+		return nil, err
+	}
+	equalityVerifier1, err := dlogproofs.NewDLogEqualityBTranscriptVerifier(c.group, gamma)
+	if err != nil {
+		return nil, err
+	}
+	equalityVerifier2, err := dlogproofs.NewDLogEqualityBTranscriptVerifier(c.group, gamma)
+	if err != nil {
+		return nil, err
+	}
 
 	// First we need to authenticate - prove that we know dlog_a(b) where (a, b) is a nym registered
 	// with this organization. Authentication is done via Schnorr.
-	schnorrProver := dlogproofs.NewSchnorrProver(c.group, types.Sigma)
-	x := schnorrProver.GetProofRandomData(userSecret, nym.A)
+	schnorrProver, err := dlogproofs.NewSchnorrProver(c.group)
+	if err != nil {
+		return nil, err
+	}
+	x, err := schnorrProver.GetProofRandomData(userSecret, nym.A)
+	if err != nil {
+		return nil, err
+	}
 
 	pRandomData := pb.SchnorrProofRandomData{
 		X: x.Bytes(),
@@ -171,7 +208,10 @@ func (c *PseudonymsysClient) ObtainCredential(userSecret *big.Int,
 	ch := resp.GetBigint()
 	challenge := new(big.Int).SetBytes(ch.X1)
 
-	z, _ := schnorrProver.GetProofData(challenge)
+	z, _, err := schnorrProver.GetProofData(challenge)
+	if err != nil {
+		return nil, err
+	}
 	msg := &pb.Message{
 		Content: &pb.Message_Bigint{
 			&pb.BigInt{
@@ -197,17 +237,43 @@ func (c *PseudonymsysClient) ObtainCredential(userSecret *big.Int,
 	A := new(big.Int).SetBytes(randomData.A)
 	B := new(big.Int).SetBytes(randomData.B)
 
-	challenge1 := equalityVerifier1.GetChallenge(c.group.G, nym.B, orgPubKeys.H2, A, x11, x12)
+	challenge1, err := equalityVerifier1.GetChallenge(c.group.G, nym.B, orgPubKeys.H2, A, x11, x12)
+	if err != nil {
+		return nil, err
+	}
 	aA := c.group.Mul(nym.A, A)
-	challenge2 := equalityVerifier2.GetChallenge(c.group.G, aA, orgPubKeys.H1, B, x21, x22)
+	attributes, err := c.receiveAttributes(randomData.Attributes, gamma, nym.A, A, orgPubKeys)
+	if err != nil {
+		return nil, err
+	}
+	for _, attr := range attributes {
+		aA = c.group.Mul(aA, attr.d)
+	}
+	challenge2, err := equalityVerifier2.GetChallenge(c.group.G, aA, orgPubKeys.H1, B, x21, x22)
+	if err != nil {
+		return nil, err
+	}
 
-	msg = &pb.Message{
-		Content: &pb.Message_DoubleBigint{
-			&pb.DoubleBigInt{
-				X1: challenge1.Bytes(),
-				X2: challenge2.Bytes(),
+	// with attributes the challenges of their equality proofs follow the two challenges
+	if len(attributes) > 0 {
+		challenges := [][]byte{challenge1.Bytes(), challenge2.Bytes()}
+		for _, attr := range attributes {
+			challenges = append(challenges, attr.challenge.Bytes())
+		}
+		msg = &pb.Message{
+			Content: &pb.Message_RepeatedBigint{
+				&pb.RepeatedBigInt{X: challenges},
 			},
-		},
+		}
+	} else {
+		msg = &pb.Message{
+			Content: &pb.Message_DoubleBigint{
+				&pb.DoubleBigInt{
+					X1: challenge1.Bytes(),
+					X2: challenge2.Bytes(),
+				},
+			},
+		}
 	}
 
 	resp, err = c.getResponseTo(msg)
@@ -215,9 +281,21 @@ func (c *PseudonymsysClient) ObtainCredential(userSecret *big.Int,
 		return nil, err
 	}
 
-	proofData := resp.GetDoubleBigint()
-	z1 := new(big.Int).SetBytes(proofData.X1)
-	z2 := new(big.Int).SetBytes(proofData.X2)
+	var z1, z2 *big.Int
+	var zAttributes [][]byte
+	if len(attributes) > 0 {
+		proofData := resp.GetRepeatedBigint()
+		if proofData == nil || len(proofData.X) != 2+len(attributes) {
+			return nil, errors.New("Responses of the equality proofs expected.")
+		}
+		z1 = new(big.Int).SetBytes(proofData.X[0])
+		z2 = new(big.Int).SetBytes(proofData.X[1])
+		zAttributes = proofData.X[2:]
+	} else {
+		proofData := resp.GetDoubleBigint()
+		z1 = new(big.Int).SetBytes(proofData.X1)
+		z2 = new(big.Int).SetBytes(proofData.X2)
+	}
 
 	verified1, transcript1, bToGamma, AToGamma := equalityVerifier1.Verify(z1)
 	verified2, transcript2, aAToGamma, BToGamma := equalityVerifier2.Verify(z2)
@@ -228,9 +306,17 @@ func (c *PseudonymsysClient) ObtainCredential(userSecret *big.Int,
 			bToGamma, AToGamma)
 		valid2 := dlogproofs.VerifyBlindedTranscript(transcript2, c.group, c.group.G, orgPubKeys.H1,
 			aAToGamma, BToGamma)
+		credentialAttributes := make([]*pseudonymsys.CredentialAttribute, len(attributes))
+		for i, attr := range attributes {
+			credentialAttributes[i] = attr.verify(new(big.Int).SetBytes(zAttributes[i]))
+			if credentialAttributes[i] == nil {
+				valid1 = false
+			}
+		}
 		if valid1 && valid2 {
 			credential := pseudonymsys.NewCredential(aToGamma, bToGamma, AToGamma, BToGamma,
 				transcript1, transcript2)
+			credential.Attributes = credentialAttributes
 			return credential, nil
 		}
 	}
@@ -241,9 +327,25 @@ func (c *PseudonymsysClient) ObtainCredential(userSecret *big.Int,
 
 // TransferCredential transfers orgName's credential to organization where the
 // authentication should happen (the organization takes credential issued by
-// another organization).
+// another organization). The attributes of the credential are not disclosed.
 func (c *PseudonymsysClient) TransferCredential(orgName string, userSecret *big.Int,
 	nym *pseudonymsys.Pseudonym, credential *pseudonymsys.Credential) (*pb.SessionKey, error) {
+	return c.TransferCredentialWithAttributes(orgName, userSecret, nym, credential,
+		&anoncreds.PresentationRequest{})
+}
+
+// TransferCredentialWithAttributes transfers the credential like TransferCredential,
+// disclosing the attributes of the credential and proving the predicates about the hidden
+// ones as given by request.
+func (c *PseudonymsysClient) TransferCredentialWithAttributes(orgName string,
+	userSecret *big.Int, nym *pseudonymsys.Pseudonym, credential *pseudonymsys.Credential,
+	request *anoncreds.PresentationRequest) (*pb.SessionKey, error) {
+	predicateProver, err := pseudonymsys.NewPredicateProver(c.group, credential,
+		request.Predicates)
+	if err != nil {
+		return nil, err
+	}
+
 	c.openStream()
 	defer c.closeStream()
 
@@ -252,28 +354,23 @@ func (c *PseudonymsysClient) TransferCredential(orgName string, userSecret *big.
 	// a2, b2 are a1, b1 exponentiated to gamma, and (a1, b1) is a nym for organization that
 	// issued a credential. So we can do both proofs at the same time using DLogEqualityProver.
 	equalityProver := dlogproofs.NewDLogEqualityProver(c.group)
-	x1, x2 := equalityProver.GetProofRandomData(userSecret, nym.A, credential.SmallAToGamma)
-
-	transcript1 := &pb.PseudonymsysTranscript{
-		A:      credential.T1.A.Bytes(),
-		B:      credential.T1.B.Bytes(),
-		Hash:   credential.T1.Hash.Bytes(),
-		ZAlpha: credential.T1.ZAlpha.Bytes(),
+	x1, x2, err := equalityProver.GetProofRandomData(userSecret, nym.A, credential.SmallAToGamma)
+	if err != nil {
+		return nil, err
 	}
-	transcript2 := &pb.PseudonymsysTranscript{
-		A:      credential.T2.A.Bytes(),
-		B:      credential.T2.B.Bytes(),
-		Hash:   credential.T2.Hash.Bytes(),
-		ZAlpha: credential.T2.ZAlpha.Bytes(),
+
+	// the predicates are proved with the same challenge as the equality
+	predicateData, err := predicateProver.GetProofRandomData()
+	if err != nil {
+		return nil, err
 	}
-	pbCredential := &pb.PseudonymsysCredential{
-		SmallAToGamma: credential.SmallAToGamma.Bytes(),
-		SmallBToGamma: credential.SmallBToGamma.Bytes(),
-		AToGamma:      credential.AToGamma.Bytes(),
-		BToGamma:      credential.BToGamma.Bytes(),
-		T1:            transcript1,
-		T2:            transcript2,
+	var predicates [][]byte
+	for _, bits := range predicateData {
+		for _, bit := range bits {
+			predicates = append(predicates, bit.C.Bytes(), bit.T0.Bytes(), bit.T1.Bytes())
+		}
 	}
+
 	initMsg := &pb.Message{
 		ClientId:      c.id,
 		Schema:        pb.SchemaType_PSEUDONYMSYS_TRANSFER_CREDENTIAL,
@@ -285,7 +382,9 @@ func (c *PseudonymsysClient) TransferCredential(orgName string, userSecret *big.
 				X2:         x2.Bytes(),
 				NymA:       nym.A.Bytes(),
 				NymB:       nym.B.Bytes(),
-				Credential: pbCredential,
+				Credential: toPbCredential(credential.Present(request.Disclosed)),
+				Request:    toPbPresentationRequest(request),
+				Predicates: predicates,
 			},
 		},
 	}
@@ -297,7 +396,10 @@ func (c *PseudonymsysClient) TransferCredential(orgName string, userSecret *big.
 	ch := resp.GetBigint()
 	challenge := new(big.Int).SetBytes(ch.X1)
 
-	z := equalityProver.GetProofData(challenge)
+	z, err := equalityProver.GetProofData(challenge)
+	if err != nil {
+		return nil, err
+	}
 	msg := &pb.Message{
 		Content: &pb.Message_Bigint{
 			&pb.BigInt{
@@ -305,6 +407,20 @@ func (c *PseudonymsysClient) TransferCredential(orgName string, userSecret *big.
 			},
 		},
 	}
+	if len(request.Predicates) > 0 {
+		proofData := [][]byte{z.Bytes()}
+		for _, bits := range predicateProver.GetProofData(challenge) {
+			for _, bit := range bits {
+				proofData = append(proofData, bit.E0.Bytes(), bit.E1.Bytes(), bit.Z0.Bytes(),
+					bit.Z1.Bytes())
+			}
+		}
+		msg = &pb.Message{
+			Content: &pb.Message_RepeatedBigint{
+				&pb.RepeatedBigInt{X: proofData},
+			},
+		}
+	}
 
 	resp, err = c.getResponseTo(msg)
 	if err != nil {
@@ -319,5 +435,48 @@ func (c *PseudonymsysClient) TransferCredential(orgName string, userSecret *big.
 		return nil, err
 	}
 
+	if c.wallet != nil {
+		disclosed := []string{fmt.Sprintf("credential issued by %s", orgName)}
+		for _, name := range request.Disclosed {
+			disclosed = append(disclosed, fmt.Sprintf("%s = %v", name,
+				credential.Attribute(name).Value))
+		}
+		for _, predicate := range request.Predicates {
+			disclosed = append(disclosed, fmt.Sprintf("%s %v %v", predicate.Attribute,
+				predicate.Type, predicate.Bound))
+		}
+		_, err := c.wallet.AddReceipt(c.recipient, disclosed, nym.A, nym.B,
+			credential.SmallAToGamma, credential.SmallBToGamma, credential.AToGamma,
+			credential.BToGamma, x1, x2, challenge, z)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return sessionKey, nil
 }
+
+// toPbCredential converts the credential to its protobuf representation.
+func toPbCredential(credential *pseudonymsys.Credential) *pb.PseudonymsysCredential {
+	transcript1 := &pb.PseudonymsysTranscript{
+		A:      credential.T1.A.Bytes(),
+		B:      credential.T1.B.Bytes(),
+		Hash:   credential.T1.Hash.Bytes(),
+		ZAlpha: credential.T1.ZAlpha.Bytes(),
+	}
+	transcript2 := &pb.PseudonymsysTranscript{
+		A:      credential.T2.A.Bytes(),
+		B:      credential.T2.B.Bytes(),
+		Hash:   credential.T2.Hash.Bytes(),
+		ZAlpha: credential.T2.ZAlpha.Bytes(),
+	}
+	return &pb.PseudonymsysCredential{
+		SmallAToGamma: credential.SmallAToGamma.Bytes(),
+		SmallBToGamma: credential.SmallBToGamma.Bytes(),
+		AToGamma:      credential.AToGamma.Bytes(),
+		BToGamma:      credential.BToGamma.Bytes(),
+		T1:            transcript1,
+		T2:            transcript2,
+		Attributes:    toPbCredentialAttributes(credential.Attributes),
+	}
+}
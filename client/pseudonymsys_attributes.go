@@ -0,0 +1,150 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/anoncreds"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"math/big"
+)
+
+// issuedAttribute is an attribute which is being embedded into the credential, together with
+// the verifier of the organization's proof that it is signed.
+type issuedAttribute struct {
+	name      string
+	value     *big.Int
+	r         *big.Int
+	d         *big.Int
+	challenge *big.Int
+	verifier  *dlogproofs.DLogEqualityBTranscriptVerifier
+	pubKey    *big.Int
+}
+
+// receiveAttributes checks the attributes which the organization embeds into the credential
+// (they need to be exactly those of orgPubKeys) and computes the challenges of their proofs.
+// a is the nym and A the part of the credential, gamma blinds the credential.
+func (c *PseudonymsysClient) receiveAttributes(data []*pb.PseudonymsysIssuedAttribute,
+	gamma, a, A *big.Int, orgPubKeys *pseudonymsys.OrgPubKeys) ([]*issuedAttribute, error) {
+	if len(data) == 0 {
+		if orgPubKeys.Attributes != nil && len(orgPubKeys.Attributes.Names) > 0 {
+			return nil, errors.New("Organization did not embed the attributes into the credential.")
+		}
+		return nil, nil
+	}
+	pubKeys := orgPubKeys.Attributes
+	if pubKeys == nil || len(pubKeys.Names) != len(data) || len(pubKeys.H) != len(data) {
+		return nil, errors.New("Public keys of the attributes of the credential are not known.")
+	}
+
+	attributes := make([]*issuedAttribute, len(data))
+	for i, d := range data {
+		if d.Name != pubKeys.Names[i] {
+			return nil, fmt.Errorf("Unexpected attribute %s of the credential.", d.Name)
+		}
+		verifier, err := dlogproofs.NewDLogEqualityBTranscriptVerifier(c.group, gamma)
+		if err != nil {
+			return nil, err
+		}
+		attr := &issuedAttribute{
+			name:     d.Name,
+			value:    new(big.Int).SetBytes(d.Value),
+			r:        new(big.Int).SetBytes(d.R),
+			d:        new(big.Int).SetBytes(d.D),
+			verifier: verifier,
+			pubKey:   pubKeys.H[i],
+		}
+		if attr.value.BitLen() > pseudonymsys.AttributeBitLen ||
+			!c.group.IsElementInGroup(attr.d) {
+			return nil, fmt.Errorf("Invalid attribute %s of the credential.", d.Name)
+		}
+		v := c.group.Mul(c.group.Exp(a, attr.value), c.group.Exp(A, attr.r))
+		attr.challenge, err = attr.verifier.GetChallenge(c.group.G, v, attr.pubKey, attr.d,
+			new(big.Int).SetBytes(d.X1), new(big.Int).SetBytes(d.X2))
+		if err != nil {
+			return nil, err
+		}
+		attributes[i] = attr
+	}
+	return attributes, nil
+}
+
+// verify checks the organization's proof for the attribute and returns the attribute of
+// the blinded credential, or nil if the proof is not valid.
+func (attr *issuedAttribute) verify(z *big.Int) *pseudonymsys.CredentialAttribute {
+	verified, transcript, vToGamma, dToGamma := attr.verifier.Verify(z)
+	if !verified {
+		return nil
+	}
+	group := attr.verifier.Group
+	if !dlogproofs.VerifyBlindedTranscript(transcript, group, group.G, attr.pubKey, vToGamma,
+		dToGamma) {
+		return nil
+	}
+	return &pseudonymsys.CredentialAttribute{
+		Name:  attr.name,
+		Value: attr.value,
+		R:     attr.r,
+		V:     vToGamma,
+		D:     dToGamma,
+		T:     transcript,
+	}
+}
+
+// toPbCredentialAttributes converts the attributes of the credential to their protobuf
+// representation - the values are sent only for the disclosed attributes.
+func toPbCredentialAttributes(
+	attributes []*pseudonymsys.CredentialAttribute) []*pb.PseudonymsysCredentialAttribute {
+	data := make([]*pb.PseudonymsysCredentialAttribute, len(attributes))
+	for i, attr := range attributes {
+		data[i] = &pb.PseudonymsysCredentialAttribute{
+			Name: attr.Name,
+			V:    attr.V.Bytes(),
+			D:    attr.D.Bytes(),
+			T: &pb.PseudonymsysTranscript{
+				A:      attr.T.A.Bytes(),
+				B:      attr.T.B.Bytes(),
+				Hash:   attr.T.Hash.Bytes(),
+				ZAlpha: attr.T.ZAlpha.Bytes(),
+			},
+		}
+		if attr.Value != nil {
+			data[i].Disclosed = true
+			data[i].Value = attr.Value.Bytes()
+			data[i].R = attr.R.Bytes()
+		}
+	}
+	return data
+}
+
+func toPbPresentationRequest(
+	request *anoncreds.PresentationRequest) *pb.AnonCredsPresentationRequest {
+	pbRequest := &pb.AnonCredsPresentationRequest{
+		Disclosed: request.Disclosed,
+	}
+	for _, predicate := range request.Predicates {
+		pbRequest.PredicateAttributes = append(pbRequest.PredicateAttributes,
+			predicate.Attribute)
+		pbRequest.PredicateTypes = append(pbRequest.PredicateTypes, predicate.Type.String())
+		pbRequest.PredicateBounds = append(pbRequest.PredicateBounds, predicate.Bound.Bytes())
+	}
+	return pbRequest
+}
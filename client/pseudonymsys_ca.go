@@ -18,33 +18,64 @@
 package client
 
 import (
-	"github.com/xlab-si/emmy/config"
+	"fmt"
 	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
 	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
 	pb "github.com/xlab-si/emmy/protobuf"
-	"github.com/xlab-si/emmy/types"
 	"google.golang.org/grpc"
 	"math/big"
 )
 
 type PseudonymsysCAClient struct {
 	genericClient
-	prover *dlogproofs.SchnorrProver
+	prover              *dlogproofs.SchnorrProver
+	signatureAlgorithms []pseudonymsys.SignatureAlgorithm
+	logX                *big.Int
+	logY                *big.Int
 }
 
-func NewPseudonymsysCAClient(conn *grpc.ClientConn) (*PseudonymsysCAClient, error) {
-	group := config.LoadGroup("pseudonymsys")
-	genericClient, err := newGenericClient(conn)
+// NewPseudonymsysCAClient returns a client of the pseudonym system CA with the given parameters
+// (see config.LoadPseudonymsysParams).
+func NewPseudonymsysCAClient(conn *grpc.ClientConn, params *pseudonymsys.Params,
+	opts ...ClientOption) (*PseudonymsysCAClient, error) {
+	if err := params.Validate(); err != nil {
+		return nil, fmt.Errorf("Invalid pseudonymsys parameters: %v", err)
+	}
+	genericClient, err := newGenericClient(conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	prover, err := dlogproofs.NewSchnorrProver(params.Group)
 	if err != nil {
 		return nil, err
 	}
 
 	return &PseudonymsysCAClient{
 		genericClient: *genericClient,
-		prover:        dlogproofs.NewSchnorrProver(group, types.Sigma),
+		prover:        prover,
+		signatureAlgorithms: []pseudonymsys.SignatureAlgorithm{
+			pseudonymsys.Ed25519,
+			pseudonymsys.Schnorr,
+			pseudonymsys.ECDSA,
+		},
 	}, nil
 }
 
+// SetCALogPubKey sets the public key of the log where CA needs to submit the issued
+// certificates. If the key is set, ObtainCertificate fails when the certificate does not
+// come with a valid SignedCertificateTimestamp of the log.
+func (c *PseudonymsysCAClient) SetCALogPubKey(x, y *big.Int) {
+	c.logX = x
+	c.logY = y
+}
+
+// SetSignatureAlgorithms sets the signature algorithms which are acceptable for
+// the certificate (by default all the algorithms are). CA chooses among them.
+func (c *PseudonymsysCAClient) SetSignatureAlgorithms(algs ...pseudonymsys.SignatureAlgorithm) {
+	c.signatureAlgorithms = algs
+}
+
 // ObtainCertificate provides a certificate from trusted CA to the user. Note that CA
 // needs to know the user. The certificate is then used for registering pseudonym (nym).
 // The certificate contains blinded user's master key pair and a signature of it.
@@ -53,7 +84,10 @@ func (c *PseudonymsysCAClient) ObtainCertificate(userSecret *big.Int, nym *pseud
 	c.openStream()
 	defer c.closeStream()
 
-	x := c.prover.GetProofRandomData(userSecret, nym.A)
+	x, err := c.prover.GetProofRandomData(userSecret, nym.A)
+	if err != nil {
+		return nil, err
+	}
 	b := c.prover.Group.Exp(nym.A, userSecret)
 	pRandomData := pb.SchnorrProofRandomData{
 		X: x.Bytes(),
@@ -61,12 +95,20 @@ func (c *PseudonymsysCAClient) ObtainCertificate(userSecret *big.Int, nym *pseud
 		B: b.Bytes(),
 	}
 
+	algs := make([]int32, len(c.signatureAlgorithms))
+	for i, alg := range c.signatureAlgorithms {
+		algs[i] = int32(alg)
+	}
+
 	initMsg := &pb.Message{
 		ClientId:      c.id,
 		Schema:        pb.SchemaType_PSEUDONYMSYS_CA,
 		SchemaVariant: pb.SchemaVariant_SIGMA,
-		Content: &pb.Message_SchnorrProofRandomData{
-			&pRandomData,
+		Content: &pb.Message_PseudonymsysCaRequest{
+			&pb.PseudonymsysCARequest{
+				ProofRandomData:     &pRandomData,
+				SignatureAlgorithms: algs,
+			},
 		},
 	}
 	resp, err := c.getResponseTo(initMsg)
@@ -77,13 +119,14 @@ func (c *PseudonymsysCAClient) ObtainCertificate(userSecret *big.Int, nym *pseud
 	ch := resp.GetBigint()
 	challenge := new(big.Int).SetBytes(ch.X1)
 
-	z, _ := c.prover.GetProofData(challenge)
-	trapdoor := new(big.Int)
+	z, _, err := c.prover.GetProofData(challenge)
+	if err != nil {
+		return nil, err
+	}
 	msg := &pb.Message{
 		Content: &pb.Message_SchnorrProofData{
 			&pb.SchnorrProofData{
-				Z:        z.Bytes(),
-				Trapdoor: trapdoor.Bytes(),
+				Z: z.Bytes(),
 			},
 		},
 	}
@@ -95,7 +138,51 @@ func (c *PseudonymsysCAClient) ObtainCertificate(userSecret *big.Int, nym *pseud
 	cert := resp.GetPseudonymsysCaCertificate()
 	certificate := pseudonymsys.NewCACertificate(
 		new(big.Int).SetBytes(cert.BlindedA), new(big.Int).SetBytes(cert.BlindedB),
-		new(big.Int).SetBytes(cert.R), new(big.Int).SetBytes(cert.S))
+		new(big.Int).SetBytes(cert.R), new(big.Int).SetBytes(cert.S),
+		pseudonymsys.SignatureAlgorithm(cert.Algorithm))
+	if sct := cert.SCT; sct != nil {
+		certificate.SCT = pseudonymsys.NewSignedCertificateTimestamp(sct.Timestamp,
+			new(big.Int).SetBytes(sct.R), new(big.Int).SetBytes(sct.S))
+	}
+
+	if c.logX != nil {
+		if certificate.SCT == nil || !pseudonymsys.VerifySignedCertificateTimestamp(c.logX,
+			c.logY, certificate, certificate.SCT) {
+			return nil, fmt.Errorf("Certificate was not submitted to the CA log")
+		}
+	}
 
 	return certificate, nil
 }
+
+// GetCertificateStatus obtains the CA signed status of the certificate. The user presents the
+// status together with the certificate to the organization (which verifies it with
+// pseudonymsys.VerifyCAStatusResponse), so that CA does not learn where the certificate is used.
+func (c *PseudonymsysCAClient) GetCertificateStatus(cert *pseudonymsys.CACertificate) (
+	*pseudonymsys.CAStatusResponse, error) {
+	c.openStream()
+	defer c.closeStream()
+
+	msg := &pb.Message{
+		ClientId:      c.id,
+		Schema:        pb.SchemaType_PSEUDONYMSYS_CA_STATUS,
+		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Content: &pb.Message_PseudonymsysCaCertificate{
+			&pb.PseudonymsysCACertificate{
+				BlindedA:  cert.BlindedA.Bytes(),
+				BlindedB:  cert.BlindedB.Bytes(),
+				R:         cert.R.Bytes(),
+				S:         cert.S.Bytes(),
+				Algorithm: int32(cert.Algorithm),
+			},
+		},
+	}
+	resp, err := c.getResponseTo(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	status := resp.GetPseudonymsysCaStatus()
+	return pseudonymsys.NewCAStatusResponse(pseudonymsys.CertificateStatus(status.Status),
+		status.Timestamp, new(big.Int).SetBytes(status.R), new(big.Int).SetBytes(status.S)), nil
+}
@@ -18,7 +18,6 @@
 package client
 
 import (
-	"github.com/xlab-si/emmy/crypto/dlog"
 	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
 	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
 	pb "github.com/xlab-si/emmy/protobuf"
@@ -32,13 +31,14 @@ type PseudonymsysCAClientEC struct {
 	prover *dlogproofs.SchnorrECProver
 }
 
-func NewPseudonymsysCAClientEC(conn *grpc.ClientConn, curve dlog.Curve) (*PseudonymsysCAClientEC, error) {
-	genericClient, err := newGenericClient(conn)
+func NewPseudonymsysCAClientEC(conn *grpc.ClientConn,
+	opts ...ClientOption) (*PseudonymsysCAClientEC, error) {
+	genericClient, err := newGenericClient(conn, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	prover, err := dlogproofs.NewSchnorrECProver(curve, types.Sigma)
+	prover, err := dlogproofs.NewSchnorrECProver(dlogproofs.WithCurve(genericClient.curve))
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +57,10 @@ func (c *PseudonymsysCAClientEC) ObtainCertificate(userSecret *big.Int, nym *pse
 	c.openStream()
 	defer c.closeStream()
 
-	x := c.prover.GetProofRandomData(userSecret, nym.A)
+	x, err := c.prover.GetProofRandomData(userSecret, nym.A)
+	if err != nil {
+		return nil, err
+	}
 	pRandomData := pb.SchnorrECProofRandomData{
 		X: types.ToPbECGroupElement(x),
 		A: types.ToPbECGroupElement(nym.A),
@@ -68,6 +71,7 @@ func (c *PseudonymsysCAClientEC) ObtainCertificate(userSecret *big.Int, nym *pse
 		ClientId:      c.id,
 		Schema:        pb.SchemaType_PSEUDONYMSYS_CA_EC,
 		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Curve:         int32(c.curve),
 		Content: &pb.Message_SchnorrEcProofRandomData{
 			&pRandomData,
 		},
@@ -80,13 +84,14 @@ func (c *PseudonymsysCAClientEC) ObtainCertificate(userSecret *big.Int, nym *pse
 	ch := resp.GetBigint()
 	challenge := new(big.Int).SetBytes(ch.X1)
 
-	z, _ := c.prover.GetProofData(challenge)
-	trapdoor := new(big.Int)
+	z, _, err := c.prover.GetProofData(challenge)
+	if err != nil {
+		return nil, err
+	}
 	msg := &pb.Message{
 		Content: &pb.Message_SchnorrProofData{
 			&pb.SchnorrProofData{
-				Z:        z.Bytes(),
-				Trapdoor: trapdoor.Bytes(),
+				Z: z.Bytes(),
 			},
 		},
 	}
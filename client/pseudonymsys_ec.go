@@ -32,23 +32,22 @@ import (
 
 type PseudonymsysClientEC struct {
 	genericClient
-	curve dlog.Curve
 }
 
-func NewPseudonymsysClientEC(conn *grpc.ClientConn, curve dlog.Curve) (*PseudonymsysClientEC, error) {
-	genericClient, err := newGenericClient(conn)
+func NewPseudonymsysClientEC(conn *grpc.ClientConn,
+	opts ...ClientOption) (*PseudonymsysClientEC, error) {
+	genericClient, err := newGenericClient(conn, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return &PseudonymsysClientEC{
 		genericClient: *genericClient,
-		curve:         curve,
 	}, nil
 }
 
 // GenerateMasterKey generates a master secret key to be used subsequently by all the
 // protocols in the scheme.
-func (c *PseudonymsysClientEC) GenerateMasterKey() *big.Int {
+func (c *PseudonymsysClientEC) GenerateMasterKey() (*big.Int, error) {
 	discreteLog := dlog.NewECDLog(c.curve)
 	return common.GetRandomInt(discreteLog.OrderOfSubgroup)
 }
@@ -75,7 +74,10 @@ func (c *PseudonymsysClientEC) GenerateNym(userSecret *big.Int,
 	nymB1, nymB2 := prover.DLog.Exponentiate(masterNymA.X, masterNymA.Y, userSecret)
 	masterNymB := types.NewECGroupElement(nymB1, nymB2)
 
-	gamma := common.GetRandomInt(prover.DLog.GetOrderOfSubgroup())
+	gamma, err := common.GetRandomInt(prover.DLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, err
+	}
 	nymAX, nymAY := prover.DLog.Exponentiate(masterNymA.X, masterNymA.Y, gamma)
 	nymBX, nymBY := prover.DLog.Exponentiate(masterNymB.X, masterNymB.Y, gamma)
 
@@ -84,7 +86,10 @@ func (c *PseudonymsysClientEC) GenerateNym(userSecret *big.Int,
 
 	// Prove now that log_nymA(nymB) = log_blindedA(blindedB):
 	// g1 = nymA, g2 = blindedA
-	x1, x2 := prover.GetProofRandomData(userSecret, nymA, caCertificate.BlindedA)
+	x1, x2, err := prover.GetProofRandomData(userSecret, nymA, caCertificate.BlindedA)
+	if err != nil {
+		return nil, err
+	}
 	pRandomData := pb.PseudonymsysNymGenProofRandomDataEC{
 		X1: types.ToPbECGroupElement(x1),
 		A1: types.ToPbECGroupElement(nymA),
@@ -100,6 +105,7 @@ func (c *PseudonymsysClientEC) GenerateNym(userSecret *big.Int,
 		ClientId:      c.id,
 		Schema:        pb.SchemaType_PSEUDONYMSYS_NYM_GEN_EC,
 		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Curve:         int32(c.curve),
 		Content: &pb.Message_PseudonymsysNymGenProofRandomDataEc{
 			&pRandomData,
 		},
@@ -115,13 +121,15 @@ func (c *PseudonymsysClientEC) GenerateNym(userSecret *big.Int,
 	}
 	challenge := new(big.Int).SetBytes(pedersenDecommitment.X)
 
-	z := prover.GetProofData(challenge)
+	z, err := prover.GetProofData(challenge)
+	if err != nil {
+		return nil, err
+	}
 
 	msg := &pb.Message{
 		Content: &pb.Message_SchnorrProofData{
 			&pb.SchnorrProofData{
 				Z: z.Bytes(),
-				//Trapdoor: trapdoor.Bytes(),
 			},
 		},
 	}
@@ -154,12 +162,15 @@ func (c *PseudonymsysClientEC) ObtainCredential(userSecret *big.Int,
 
 	// First we need to authenticate - prove that we know dlog_a(b) where (a, b) is a nym registered
 	// with this organization. Authentication is done via Schnorr.
-	schnorrProver, err := dlogproofs.NewSchnorrECProver(c.curve, types.Sigma)
+	schnorrProver, err := dlogproofs.NewSchnorrECProver(dlogproofs.WithCurve(c.curve))
 	if err != nil {
 		return nil, err
 	}
 
-	x := schnorrProver.GetProofRandomData(userSecret, nym.A)
+	x, err := schnorrProver.GetProofRandomData(userSecret, nym.A)
+	if err != nil {
+		return nil, err
+	}
 
 	pRandomData := pb.SchnorrECProofRandomData{
 		X: types.ToPbECGroupElement(x),
@@ -171,6 +182,7 @@ func (c *PseudonymsysClientEC) ObtainCredential(userSecret *big.Int,
 		ClientId:      c.id,
 		Schema:        pb.SchemaType_PSEUDONYMSYS_ISSUE_CREDENTIAL_EC,
 		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Curve:         int32(c.curve),
 		Content: &pb.Message_SchnorrEcProofRandomData{
 			&pRandomData,
 		},
@@ -183,7 +195,10 @@ func (c *PseudonymsysClientEC) ObtainCredential(userSecret *big.Int,
 	ch := resp.GetBigint()
 	challenge := new(big.Int).SetBytes(ch.X1)
 
-	z, _ := schnorrProver.GetProofData(challenge)
+	z, _, err := schnorrProver.GetProofData(challenge)
+	if err != nil {
+		return nil, err
+	}
 	msg := &pb.Message{
 		Content: &pb.Message_Bigint{
 			&pb.BigInt{
@@ -209,17 +224,32 @@ func (c *PseudonymsysClientEC) ObtainCredential(userSecret *big.Int,
 	A := types.ToECGroupElement(randomData.A)
 	B := types.ToECGroupElement(randomData.B)
 
-	gamma := common.GetRandomInt(schnorrProver.DLog.OrderOfSubgroup)
-	equalityVerifier1 := dlogproofs.NewECDLogEqualityBTranscriptVerifier(c.curve, gamma)
-	equalityVerifier2 := dlogproofs.NewECDLogEqualityBTranscriptVerifier(c.curve, gamma)
+	gamma, err := common.GetRandomInt(schnorrProver.DLog.OrderOfSubgroup)
+	if err != nil {
+		return nil, err
+	}
+	equalityVerifier1, err := dlogproofs.NewECDLogEqualityBTranscriptVerifier(c.curve, gamma)
+	if err != nil {
+		return nil, err
+	}
+	equalityVerifier2, err := dlogproofs.NewECDLogEqualityBTranscriptVerifier(c.curve, gamma)
+	if err != nil {
+		return nil, err
+	}
 
 	g := types.NewECGroupElement(equalityVerifier1.DLog.Curve.Params().Gx,
 		equalityVerifier1.DLog.Curve.Params().Gy)
 
-	challenge1 := equalityVerifier1.GetChallenge(g, nym.B, orgPubKeys.H2, A, x11, x12)
+	challenge1, err := equalityVerifier1.GetChallenge(g, nym.B, orgPubKeys.H2, A, x11, x12)
+	if err != nil {
+		return nil, err
+	}
 	aA1, aA2 := equalityVerifier1.DLog.Multiply(nym.A.X, nym.A.Y, A.X, A.Y)
 	aA := types.NewECGroupElement(aA1, aA2)
-	challenge2 := equalityVerifier2.GetChallenge(g, aA, orgPubKeys.H1, B, x21, x22)
+	challenge2, err := equalityVerifier2.GetChallenge(g, aA, orgPubKeys.H1, B, x21, x22)
+	if err != nil {
+		return nil, err
+	}
 
 	msg = &pb.Message{
 		Content: &pb.Message_DoubleBigint{
@@ -277,7 +307,10 @@ func (c *PseudonymsysClientEC) TransferCredential(orgName string, userSecret *bi
 	// a2, b2 are a1, b1 exponentiated to gamma, and (a1, b1) is a nym for organization that
 	// issued a credential. So we can do both proofs at the same time using DLogEqualityProver.
 	equalityProver := dlogproofs.NewECDLogEqualityProver(c.curve)
-	x1, x2 := equalityProver.GetProofRandomData(userSecret, nym.A, credential.SmallAToGamma)
+	x1, x2, err := equalityProver.GetProofRandomData(userSecret, nym.A, credential.SmallAToGamma)
+	if err != nil {
+		return nil, err
+	}
 
 	transcript1 := &pb.PseudonymsysTranscriptEC{
 		A: types.ToPbECGroupElement(types.NewECGroupElement(credential.T1.Alpha_1,
@@ -307,6 +340,7 @@ func (c *PseudonymsysClientEC) TransferCredential(orgName string, userSecret *bi
 		ClientId:      c.id,
 		Schema:        pb.SchemaType_PSEUDONYMSYS_TRANSFER_CREDENTIAL_EC,
 		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Curve:         int32(c.curve),
 		Content: &pb.Message_PseudonymsysTransferCredentialDataEc{
 			&pb.PseudonymsysTransferCredentialDataEC{
 				OrgName:    orgName,
@@ -326,7 +360,10 @@ func (c *PseudonymsysClientEC) TransferCredential(orgName string, userSecret *bi
 	ch := resp.GetBigint()
 	challenge := new(big.Int).SetBytes(ch.X1)
 
-	z := equalityProver.GetProofData(challenge)
+	z, err := equalityProver.GetProofData(challenge)
+	if err != nil {
+		return nil, err
+	}
 	msg := &pb.Message{
 		Content: &pb.Message_Bigint{
 			&pb.BigInt{
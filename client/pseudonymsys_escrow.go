@@ -0,0 +1,96 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"math/big"
+)
+
+// EscrowNym encrypts the master secret under the auditor's key (the organization needs to
+// use the same key, see server.SetNymEscrowKey) and proves to the organization that
+// the ciphertext contains the master secret of the registered nym. The auditor can then
+// recover the master public key of the user behind the nym.
+func (c *PseudonymsysClient) EscrowNym(nym *pseudonymsys.Pseudonym, userSecret *big.Int,
+	escrowKey *encryption.CSPaillierPubKey) error {
+	prover, err := pseudonymsys.NewNymEscrowProver(c.group, escrowKey, nym, userSecret)
+	if err != nil {
+		return err
+	}
+	randomData, err := prover.GetProofRandomData()
+	if err != nil {
+		return err
+	}
+
+	c.openStream()
+	defer c.closeStream()
+
+	enc := prover.GetEncryption()
+	initMsg := &pb.Message{
+		ClientId:      c.id,
+		Schema:        pb.SchemaType_PSEUDONYMSYS_NYM_ESCROW,
+		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Content: &pb.Message_PseudonymsysNymEscrowData{
+			&pb.PseudonymsysNymEscrowData{
+				NymA: nym.A.Bytes(),
+				NymB: nym.B.Bytes(),
+				U:    enc.U.Bytes(),
+				E:    enc.E.Bytes(),
+				V:    enc.V.Bytes(),
+				L:    enc.L.Bytes(),
+				ProofRandomData: &pb.CSPaillierProofRandomData{
+					U1:     randomData.U1.Bytes(),
+					E1:     randomData.E1.Bytes(),
+					V1:     randomData.V1.Bytes(),
+					Delta1: randomData.Delta1.Bytes(),
+					L1:     randomData.L1.Bytes(),
+				},
+			},
+		},
+	}
+	resp, err := c.getResponseTo(initMsg)
+	if err != nil {
+		return err
+	}
+	challenge := new(big.Int).SetBytes(resp.GetBigint().X1)
+
+	proofData := prover.GetProofData(challenge)
+	msg := &pb.Message{
+		Content: &pb.Message_CsPaillierProofData{
+			&pb.CSPaillierProofData{
+				RTilde:      proofData.RTilde.Bytes(),
+				RTildeIsNeg: proofData.RTilde.Sign() < 0,
+				STilde:      proofData.STilde.Bytes(),
+				STildeIsNeg: proofData.STilde.Sign() < 0,
+				MTilde:      proofData.MTilde.Bytes(),
+				MTildeIsNeg: proofData.MTilde.Sign() < 0,
+			},
+		},
+	}
+	resp, err = c.getResponseTo(msg)
+	if err != nil {
+		return err
+	}
+	if !resp.GetStatus().Success {
+		return fmt.Errorf("Organization did not accept the escrow.")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"math/big"
+)
+
+// GetNymInclusionProof obtains the proof that the nym is included in the last published
+// root of the organization's nym registry. The proof is checked against the nym, but
+// the signature of the root needs to be verified with the registry's public key
+// (see pseudonymsys.SignedNymRegistryRoot.Verify).
+func (c *PseudonymsysClient) GetNymInclusionProof(nym *pseudonymsys.Pseudonym) (
+	*pseudonymsys.NymInclusionProof, *pseudonymsys.SignedNymRegistryRoot, error) {
+	c.openStream()
+	defer c.closeStream()
+
+	msg := &pb.Message{
+		ClientId:      c.id,
+		Schema:        pb.SchemaType_PSEUDONYMSYS_NYM_REGISTRY,
+		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Content: &pb.Message_DoubleBigint{
+			&pb.DoubleBigInt{
+				X1: nym.A.Bytes(),
+				X2: nym.B.Bytes(),
+			},
+		},
+	}
+	resp, err := c.getResponseTo(msg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := resp.GetNymInclusionProof()
+	if data == nil || data.Root == nil {
+		return nil, nil, fmt.Errorf("Nym inclusion proof expected")
+	}
+	root := &pseudonymsys.SignedNymRegistryRoot{
+		Epoch:     data.Root.Epoch,
+		Size:      int(data.Root.Size),
+		Root:      data.Root.Root,
+		Previous:  data.Root.Previous,
+		Timestamp: data.Root.Timestamp,
+		R:         new(big.Int).SetBytes(data.Root.R),
+		S:         new(big.Int).SetBytes(data.Root.S),
+	}
+	proof := &pseudonymsys.NymInclusionProof{
+		Bitmap:   data.Bitmap,
+		Siblings: data.Siblings,
+	}
+	if !proof.Verify(root, nym) {
+		return nil, nil, fmt.Errorf("Nym inclusion proof is not valid")
+	}
+	return proof, root, nil
+}
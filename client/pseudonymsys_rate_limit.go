@@ -0,0 +1,88 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package client
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/anoncreds"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"math/big"
+	"time"
+)
+
+// AuthorizeAction asks the server to authorize an action in the given scope, the tickets are
+// computed for the period given by params (see config.LoadRateLimitParams). The user holds
+// an anonymous credential (for example a proof-of-personhood credential) of the issuer with
+// pubKey, but it reveals only the ticket for the scope and the current period and the proof
+// that the ticket is computed with the master secret of the credential - the server
+// authorizes only a limited number of actions per credential per scope per period, but it
+// cannot link the actions from different periods or scopes, nor learn who performed them.
+func (c *PseudonymsysClient) AuthorizeAction(scope string, params *pseudonymsys.RateLimitParams,
+	pubKey *anoncreds.PublicKey, credential *anoncreds.Credential) error {
+	if err := params.Validate(); err != nil {
+		return fmt.Errorf("Invalid rate limit parameters: %v", err)
+	}
+	prover, err := pseudonymsys.NewEpochTicketProver(c.group, pubKey, credential)
+	if err != nil {
+		return err
+	}
+	if err := c.openStream(); err != nil {
+		return err
+	}
+	defer c.closeStream()
+
+	epoch := pseudonymsys.GetEpoch(time.Now(), params.Period)
+	ticket := prover.GetTicket(scope, epoch)
+	v, t, x := prover.GetProofRandomData()
+
+	initMsg := &pb.Message{
+		ClientId:      c.id,
+		Schema:        pb.SchemaType_PSEUDONYMSYS_RATE_LIMIT,
+		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Content: &pb.Message_PseudonymsysRateLimitData{
+			&pb.PseudonymsysRateLimitData{
+				Scope:  scope,
+				Epoch:  epoch,
+				Ticket: ticket.Bytes(),
+				V:      v.Bytes(),
+				T:      t.Bytes(),
+				X:      x.Bytes(),
+			},
+		},
+	}
+	resp, err := c.getResponseTo(initMsg)
+	if err != nil {
+		return err
+	}
+
+	zE, zS, zM := prover.GetProofData(new(big.Int).SetBytes(resp.GetBigint().X1))
+	proofData := &pb.AnonCredsProofData{
+		ZE: zE.Bytes(),
+		ZS: zS.Bytes(),
+	}
+	// responses for all the blocks in the order of the blocks
+	for i := 0; i < len(zM); i++ {
+		proofData.ZM = append(proofData.ZM, zM[i].Bytes())
+	}
+	msg := &pb.Message{
+		Content: &pb.Message_AnonCredsProofData{proofData},
+	}
+
+	_, err = c.getResponseTo(msg)
+	return err
+}
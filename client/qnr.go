@@ -34,8 +34,9 @@ type QNRClient struct {
 	variant pb.SchemaVariant
 }
 
-func NewQNRClient(conn *grpc.ClientConn, qr *dlog.QR, y *big.Int) (*QNRClient, error) {
-	genericClient, err := newGenericClient(conn)
+func NewQNRClient(conn *grpc.ClientConn, qr *dlog.QR, y *big.Int,
+	opts ...ClientOption) (*QNRClient, error) {
+	genericClient, err := newGenericClient(conn, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -137,7 +138,10 @@ func (c *QNRClient) getVerifierChallenge() (*big.Int, []*types.Pair, error) {
 
 func (c *QNRClient) sendProverChallenge() error {
 	// get challenge from prover for proving that verifier is not cheating
-	randVector := c.prover.GetChallenge()
+	randVector, err := c.prover.GetChallenge()
+	if err != nil {
+		return err
+	}
 	var ints []int32
 	for _, i := range randVector {
 		ints = append(ints, int32(i))
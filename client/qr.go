@@ -32,8 +32,9 @@ type QRClient struct {
 }
 
 // NewQRClient returns an initialized struct of type QRClient.
-func NewQRClient(conn *grpc.ClientConn, group *groups.SchnorrGroup, y1 *big.Int) (*QRClient, error) {
-	genericClient, err := newGenericClient(conn)
+func NewQRClient(conn *grpc.ClientConn, group *groups.SchnorrGroup, y1 *big.Int,
+	opts ...ClientOption) (*QRClient, error) {
+	genericClient, err := newGenericClient(conn, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -90,14 +91,16 @@ func (c *QRClient) Run() (bool, error) {
 }
 
 func (c *QRClient) sendProofRandomData() error {
-	x := c.prover.GetProofRandomData()
+	x, err := c.prover.GetProofRandomData()
+	if err != nil {
+		return err
+	}
 	msg := &pb.Message{
 		Content: &pb.Message_Bigint{
 			&pb.BigInt{X1: x.Bytes()},
 		},
 	}
-	err := c.send(msg)
-	return err
+	return c.send(msg)
 }
 
 func (c *QRClient) getChallenge() (*big.Int, error) {
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/rangeproofs"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"google.golang.org/grpc"
+	"math/big"
+)
+
+type RangeProofClient struct {
+	genericClient
+	group     *groups.SchnorrGroup
+	committer *commitments.PedersenCommitter
+	val       *big.Int
+	a         *big.Int
+	b         *big.Int
+}
+
+// NewRangeProofClient returns a client which commits to val (using Pedersen commitment) and
+// proves to the server that val lies in [a, b] without revealing it. Note that a and b
+// need to be non-negative.
+func NewRangeProofClient(conn *grpc.ClientConn, group *groups.SchnorrGroup, val, a, b *big.Int,
+	opts ...ClientOption) (*RangeProofClient, error) {
+	genericClient, err := newGenericClient(conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RangeProofClient{
+		genericClient: *genericClient,
+		group:         group,
+		committer:     commitments.NewPedersenCommitter(group),
+		val:           val,
+		a:             a,
+		b:             b,
+	}, nil
+}
+
+// Run runs the range proof protocol. It returns whether the server accepted the proof.
+func (c *RangeProofClient) Run() (bool, error) {
+	c.openStream()
+	defer c.closeStream()
+
+	initMsg := &pb.Message{
+		ClientId: c.id,
+		Schema:   pb.SchemaType_RANGE_PROOF,
+		Content:  &pb.Message_Empty{&pb.EmptyMsg{}},
+	}
+	resp, err := c.getResponseTo(initMsg)
+	if err != nil {
+		return false, err
+	}
+	h := new(big.Int).SetBytes(resp.GetPedersenFirst().H)
+	c.committer.SetH(h)
+
+	commitment, err := c.committer.GetCommitMsg(c.val)
+	if err != nil {
+		return false, err
+	}
+	_, r := c.committer.GetDecommitMsg()
+
+	prover, err := rangeproofs.NewRangeProver(c.group, h, c.val, r, c.a, c.b)
+	if err != nil {
+		return false, err
+	}
+	randomData, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	msg := &pb.Message{
+		Content: &pb.Message_RangeProofRandomData{
+			&pb.RangeProofRandomData{
+				C:     commitment.Bytes(),
+				A:     c.a.Bytes(),
+				B:     c.b.Bytes(),
+				Lower: fromBitProofRandomData(randomData.Lower),
+				Upper: fromBitProofRandomData(randomData.Upper),
+			},
+		},
+	}
+	resp, err = c.getResponseTo(msg)
+	if err != nil {
+		return false, err
+	}
+	challenge := new(big.Int).SetBytes(resp.GetBigint().X1)
+
+	proofData := prover.GetProofData(challenge)
+	msg = &pb.Message{
+		Content: &pb.Message_RangeProofData{
+			&pb.RangeProofData{
+				Lower: fromBitProofData(proofData.Lower),
+				Upper: fromBitProofData(proofData.Upper),
+			},
+		},
+	}
+	resp, err = c.getResponseTo(msg)
+	if err != nil {
+		return false, err
+	}
+	return resp.GetStatus().Success, nil
+}
+
+func fromBitProofRandomData(data []*rangeproofs.BitProofRandomData) []*pb.BitProofRandomData {
+	result := make([]*pb.BitProofRandomData, len(data))
+	for i, d := range data {
+		result[i] = &pb.BitProofRandomData{
+			C:  d.C.Bytes(),
+			T0: d.T0.Bytes(),
+			T1: d.T1.Bytes(),
+		}
+	}
+	return result
+}
+
+func fromBitProofData(data []*rangeproofs.BitProofData) []*pb.BitProofData {
+	result := make([]*pb.BitProofData, len(data))
+	for i, d := range data {
+		result[i] = &pb.BitProofData{
+			E0: d.E0.Bytes(),
+			E1: d.E1.Bytes(),
+			Z0: d.Z0.Bytes(),
+			Z1: d.Z1.Bytes(),
+		}
+	}
+	return result
+}
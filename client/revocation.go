@@ -0,0 +1,123 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"github.com/xlab-si/emmy/revocation"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"math/big"
+)
+
+// RevocationClient keeps the local replica of the revocation registry in sync with
+// the server - it only receives the tickets revoked since the version it already has.
+type RevocationClient struct {
+	genericClient
+	replica *revocation.Replica
+}
+
+// NewRevocationClient returns a client which applies the updates to replica.
+func NewRevocationClient(conn *grpc.ClientConn, replica *revocation.Replica,
+	opts ...ClientOption) (*RevocationClient, error) {
+	genericClient, err := newGenericClient(conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &RevocationClient{
+		genericClient: *genericClient,
+		replica:       replica,
+	}, nil
+}
+
+// Sync brings the replica up to date (and refreshes its signature) with a single update.
+func (c *RevocationClient) Sync() error {
+	if err := c.openStream(); err != nil {
+		return err
+	}
+	defer c.closeStream()
+
+	if err := c.subscribe(); err != nil {
+		return err
+	}
+	return c.applyNext()
+}
+
+// Subscribe applies the updates as they are pushed by the server until ctx is done
+// (then it returns nil) or an error occurs. After each applied update, onUpdate
+// (if not nil) is called with the new snapshot of the replica.
+func (c *RevocationClient) Subscribe(ctx context.Context,
+	onUpdate func(*revocation.Snapshot)) error {
+	if err := c.openStreamContext(ctx); err != nil {
+		return err
+	}
+	defer c.closeStream()
+
+	if err := c.subscribe(); err != nil {
+		return err
+	}
+	for {
+		if err := c.applyNext(); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if onUpdate != nil {
+			onUpdate(c.replica.Snapshot())
+		}
+	}
+}
+
+func (c *RevocationClient) subscribe() error {
+	return c.send(&pb.Message{
+		ClientId:      c.id,
+		Schema:        pb.SchemaType_REVOCATION_UPDATES,
+		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Content: &pb.Message_RevocationSubscription{
+			&pb.RevocationSubscription{Version: c.replica.Version()},
+		},
+	})
+}
+
+func (c *RevocationClient) applyNext() error {
+	resp, err := c.receive()
+	if err != nil {
+		return err
+	}
+	msg := resp.GetRevocationUpdate()
+	if msg == nil {
+		return fmt.Errorf("[Client %v] Revocation update expected", c.id)
+	}
+
+	update := &revocation.Update{
+		From:      msg.From,
+		Version:   msg.Version,
+		Timestamp: msg.Timestamp,
+		Tickets:   make([]*pseudonymsys.BlacklistTicket, len(msg.Tickets)),
+		R:         new(big.Int).SetBytes(msg.R),
+		S:         new(big.Int).SetBytes(msg.S),
+	}
+	for i, t := range msg.Tickets {
+		update.Tickets[i] = pseudonymsys.NewBlacklistTicket(new(big.Int).SetBytes(t.A),
+			new(big.Int).SetBytes(t.B))
+	}
+	return c.replica.Apply(update)
+}
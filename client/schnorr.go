@@ -18,36 +18,58 @@
 package client
 
 import (
-	"fmt"
 	"github.com/xlab-si/emmy/crypto/groups"
-	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/compiler"
 	pb "github.com/xlab-si/emmy/protobuf"
-	"github.com/xlab-si/emmy/types"
+	"github.com/xlab-si/emmy/secretholder"
 	"google.golang.org/grpc"
 	"math/big"
 )
 
+// SchnorrSecretHolder performs the computations of SchnorrClient which depend on the secret
+// (for example in a separate process, see package secretholder).
+type SchnorrSecretHolder interface {
+	// GetPublicKey returns a^secret.
+	GetPublicKey(a *big.Int) (*big.Int, error)
+	// GetProofRandomData returns a^r where r is random.
+	GetProofRandomData(a *big.Int) (*big.Int, error)
+	// GetProofData returns r + challenge * secret.
+	GetProofData(challenge *big.Int) (*big.Int, error)
+}
+
 type SchnorrClient struct {
 	genericClient
-	prover  *dlogproofs.SchnorrProver
-	secret  *big.Int
-	a       *big.Int
-	variant pb.SchemaVariant
+	holder   SchnorrSecretHolder
+	receiver compiler.ChallengeReceiver
+	a        *big.Int
 }
 
 // NewSchnorrClient returns an initialized struct of type SchnorrClient.
-func NewSchnorrClient(conn *grpc.ClientConn, variant pb.SchemaVariant, group *groups.SchnorrGroup,
-	s *big.Int) (*SchnorrClient, error) {
-	genericClient, err := newGenericClient(conn)
+func NewSchnorrClient(conn *grpc.ClientConn, group *groups.SchnorrGroup, s *big.Int,
+	opts ...ClientOption) (*SchnorrClient, error) {
+	return NewSchnorrClientFromSecretHolder(conn, group, secretholder.NewSchnorr(group, s),
+		opts...)
+}
+
+// NewSchnorrClientFromSecretHolder returns an initialized struct of type SchnorrClient
+// which does not know the secret - the computations which depend on it are delegated
+// to the holder.
+func NewSchnorrClientFromSecretHolder(conn *grpc.ClientConn, group *groups.SchnorrGroup,
+	holder SchnorrSecretHolder, opts ...ClientOption) (*SchnorrClient, error) {
+	genericClient, err := newGenericClient(conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	receiver, err := genericClient.challengeVariant().NewChallengeReceiver(group)
 	if err != nil {
 		return nil, err
 	}
 
 	return &SchnorrClient{
 		genericClient: *genericClient,
-		variant:       variant,
-		prover:        dlogproofs.NewSchnorrProver(group, types.ToProtocolType(variant)),
-		secret:        s,
+		holder:        holder,
+		receiver:      receiver,
 		a:             group.G,
 	}, nil
 }
@@ -56,126 +78,60 @@ func NewSchnorrClient(conn *grpc.ClientConn, variant pb.SchemaVariant, group *gr
 // group of integers modulo p. It executes either sigma protocol or Zero Knowledge Proof(of
 // knowledge)
 func (c *SchnorrClient) Run() error {
-	if c.variant == pb.SchemaVariant_SIGMA {
-		return c.runSigma()
-	}
-	return c.runZeroKnowledge()
-}
-
-// runSigma runs the sigma version of the Schnorr protocol
-func (c *SchnorrClient) runSigma() error {
 	c.openStream()
 	defer c.closeStream()
 
 	initMsg := &pb.Message{
 		ClientId:      c.id,
 		Schema:        pb.SchemaType_SCHNORR,
-		SchemaVariant: pb.SchemaVariant_SIGMA,
-	}
-	pedersenDecommitment, err := c.getProofRandomData(true, initMsg)
-	if err != nil {
-		return err
+		SchemaVariant: c.variant,
 	}
-
-	challenge := new(big.Int).SetBytes(pedersenDecommitment.X)
-	proved, err := c.getProofData(challenge)
+	msg, err := c.openChallengeCommitment(initMsg, c.receiver)
 	if err != nil {
 		return err
 	}
-	logger.Noticef("Decommitment successful, proved: %v", proved)
-
-	return nil
-}
-
-// runZeroKnowledge runs the ZKP or ZKPOK version of Schnorr protocol, depending on the value
-// of SchnorrClient's variant field.
-func (c *SchnorrClient) runZeroKnowledge() error {
-	c.openStream()
-	defer c.closeStream()
 
-	commitment, err := c.open() // sends pedersen's h=g^trapdoor
+	x, err := c.holder.GetProofRandomData(c.a)
 	if err != nil {
 		return err
 	}
-	c.prover.PedersenReceiver.SetCommitment(commitment)
-
-	pedersenDecommitment, err := c.getProofRandomData(false, &pb.Message{})
+	b, err := c.holder.GetPublicKey(c.a)
 	if err != nil {
 		return err
 	}
-
-	challenge := new(big.Int).SetBytes(pedersenDecommitment.X)
-	r := new(big.Int).SetBytes(pedersenDecommitment.R)
-
-	if success := c.prover.PedersenReceiver.CheckDecommitment(r, challenge); success {
-		proved, err := c.getProofData(challenge)
-		logger.Noticef("Decommitment successful, proved: %v", proved)
-		if err != nil {
-			return err
-		}
-	} else {
-		return fmt.Errorf("Decommitment failed")
-	}
-
-	return nil
-}
-
-func (c *SchnorrClient) open() (*big.Int, error) {
-	h := c.prover.GetOpeningMsg()
-	openMsg := &pb.Message{
-		ClientId:      c.id,
-		Schema:        pb.SchemaType_SCHNORR,
-		SchemaVariant: c.variant,
-		Content: &pb.Message_PedersenFirst{
-			&pb.PedersenFirst{H: h.Bytes()},
+	msg.Content = &pb.Message_SchnorrProofRandomData{
+		&pb.SchnorrProofRandomData{
+			X: x.Bytes(),
+			A: c.a.Bytes(),
+			B: b.Bytes(),
 		},
 	}
-
-	resp, err := c.getResponseTo(openMsg)
+	resp, err := c.getResponseTo(msg)
 	if err != nil {
-		return nil, err
-	}
-	bigint := resp.GetBigint()
-	return new(big.Int).SetBytes(bigint.X1), nil
-}
-
-func (c *SchnorrClient) getProofRandomData(isFirstMsg bool, msg *pb.Message) (*pb.PedersenDecommitment, error) {
-	x := c.prover.GetProofRandomData(c.secret, c.a)
-	b := c.prover.Group.Exp(c.a, c.secret)
-	pRandomData := pb.SchnorrProofRandomData{
-		X: x.Bytes(),
-		A: c.a.Bytes(),
-		B: b.Bytes(),
+		return err
 	}
 
-	msg.Content = &pb.Message_SchnorrProofRandomData{
-		&pRandomData,
-	}
-	resp, err := c.getResponseTo(msg)
+	challenge, err := c.getChallenge(resp, c.receiver)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return resp.GetPedersenDecommitment(), nil
-}
-
-func (c *SchnorrClient) getProofData(challenge *big.Int) (bool, error) {
-	z, trapdoor := c.prover.GetProofData(challenge)
-	if trapdoor == nil { // sigma protocol and ZKP
-		trapdoor = new(big.Int)
+	z, err := c.holder.GetProofData(challenge)
+	if err != nil {
+		return err
 	}
-	msg := &pb.Message{
+	msg = &pb.Message{
 		Content: &pb.Message_SchnorrProofData{
 			&pb.SchnorrProofData{
-				Z:        z.Bytes(),
-				Trapdoor: trapdoor.Bytes(),
+				Z: z.Bytes(),
 			},
 		},
 	}
-
-	resp, err := c.getResponseTo(msg)
+	resp, err = c.sendProofData(msg, c.receiver)
 	if err != nil {
-		return false, err
+		return err
 	}
-	return resp.GetStatus().Success, nil
+	c.logger.Noticef("Decommitment successful, proved: %v", resp.GetStatus().Success)
+
+	return nil
 }
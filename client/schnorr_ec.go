@@ -20,175 +20,129 @@ package client
 import (
 	"fmt"
 	"github.com/xlab-si/emmy/crypto/dlog"
-	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/compiler"
 	pb "github.com/xlab-si/emmy/protobuf"
+	"github.com/xlab-si/emmy/secretholder"
 	"github.com/xlab-si/emmy/types"
 	"google.golang.org/grpc"
 	"math/big"
 )
 
+// SchnorrECSecretHolder performs the computations of SchnorrECClient which depend on
+// the secret (for example in a separate process, see package secretholder).
+type SchnorrECSecretHolder interface {
+	// GetPublicKey returns a^secret.
+	GetPublicKey(a *types.ECGroupElement) (*types.ECGroupElement, error)
+	// GetProofRandomData returns a^r where r is random.
+	GetProofRandomData(a *types.ECGroupElement) (*types.ECGroupElement, error)
+	// GetProofData returns r + challenge * secret.
+	GetProofData(challenge *big.Int) (*big.Int, error)
+}
+
 type SchnorrECClient struct {
 	genericClient
-	prover  *dlogproofs.SchnorrECProver
-	secret  *big.Int
-	a       *types.ECGroupElement
-	variant pb.SchemaVariant
+	holder   SchnorrECSecretHolder
+	receiver compiler.ChallengeReceiver
+	a        *types.ECGroupElement
 }
 
-// NewSchnorrECClient returns an initialized struct of type SchnorrECClient.
-func NewSchnorrECClient(conn *grpc.ClientConn, variant pb.SchemaVariant, curve dlog.Curve,
-	s *big.Int) (*SchnorrECClient, error) {
-	genericClient, err := newGenericClient(conn)
+// NewSchnorrECClient returns an initialized struct of type SchnorrECClient. The challenge
+// is committed in the group given by params (see config.LoadCompilerParams).
+func NewSchnorrECClient(conn *grpc.ClientConn, params *compiler.Params, s *big.Int,
+	opts ...ClientOption) (*SchnorrECClient, error) {
+	c, err := NewSchnorrECClientFromSecretHolder(conn, params, nil, opts...)
 	if err != nil {
 		return nil, err
 	}
+	c.holder = secretholder.NewSchnorrEC(c.curve, s)
+	return c, nil
+}
 
-	prover, err := dlogproofs.NewSchnorrECProver(curve, types.ToProtocolType(variant))
+// NewSchnorrECClientFromSecretHolder returns an initialized struct of type SchnorrECClient
+// which does not know the secret - the computations which depend on it are delegated
+// to the holder. The holder needs to use the same curve as the client (see WithCurve).
+func NewSchnorrECClientFromSecretHolder(conn *grpc.ClientConn, params *compiler.Params,
+	holder SchnorrECSecretHolder, opts ...ClientOption) (*SchnorrECClient, error) {
+	if err := params.Validate(); err != nil {
+		return nil, fmt.Errorf("Invalid compiler parameters: %v", err)
+	}
+	genericClient, err := newGenericClient(conn, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("Could not create schnorr EC prover: %v", err)
+		return nil, err
 	}
 
+	receiver, err := genericClient.challengeVariant().NewChallengeReceiver(params.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	curveParams := dlog.GetEllipticCurve(genericClient.curve).Params()
 	return &SchnorrECClient{
 		genericClient: *genericClient,
-		prover:        prover,
-		variant:       variant,
-		secret:        s,
-		a: &types.ECGroupElement{
-			X: prover.DLog.Curve.Params().Gx,
-			Y: prover.DLog.Curve.Params().Gy,
-		},
+		holder:        holder,
+		receiver:      receiver,
+		a:             types.NewECGroupElement(curveParams.Gx, curveParams.Gy),
 	}, nil
 }
 
 // Run starts the Schnorr protocol for proving knowledge of a discrete logarithm in elliptic curve
 // group. It executes either sigma protocol or Zero Knowledge Proof (of knowledge)
 func (c *SchnorrECClient) Run() error {
-	if c.variant == pb.SchemaVariant_SIGMA {
-		return c.runSigma()
-	}
-	return c.runZeroKnowledge()
-}
-
-// RunSigma runs the sigma version of the Schnorr protocol in the elliptic curve group
-func (c *SchnorrECClient) runSigma() error {
 	c.openStream()
 	defer c.closeStream()
 
-	pedersenDecommitment, err := c.getProofRandomData(true)
-	if err != nil {
-		return err
+	initMsg := &pb.Message{
+		ClientId:      c.id,
+		Schema:        pb.SchemaType_SCHNORR_EC,
+		SchemaVariant: c.variant,
+		Curve:         int32(c.curve),
 	}
-	challenge := new(big.Int).SetBytes(pedersenDecommitment.X)
-	proved, err := c.getProofData(challenge)
+	msg, err := c.openChallengeCommitment(initMsg, c.receiver)
 	if err != nil {
 		return err
 	}
-	logger.Noticef("Decommitment successful, proved: %v", proved)
-
-	return nil
-}
 
-// runZeroKnowledge runs the ZKP or ZKPOK version of Schnorr protocol in the elliptic curve group,
-// depending on the value of SchnorrClient's variant field.
-func (c *SchnorrECClient) runZeroKnowledge() error {
-	c.openStream()
-	defer c.closeStream()
-
-	commitment, err := c.open()
+	x, err := c.holder.GetProofRandomData(c.a) // x = a^r, b = a^secret is "public key"
 	if err != nil {
 		return err
 	}
-
-	c.prover.PedersenReceiver.SetCommitment(commitment)
-	pedersenDecommitment, err := c.getProofRandomData(false)
+	b, err := c.holder.GetPublicKey(c.a)
 	if err != nil {
 		return err
 	}
-
-	challenge := new(big.Int).SetBytes(pedersenDecommitment.X)
-	r := new(big.Int).SetBytes(pedersenDecommitment.R)
-
-	success := c.prover.PedersenReceiver.CheckDecommitment(r, challenge)
-	if success {
-		proved, err := c.getProofData(challenge)
-		logger.Noticef("Decommitment successful, proved: %v", proved)
-		if err != nil {
-			return err
-		}
-	} else {
-		return fmt.Errorf("Decommitment failed")
-	}
-
-	return nil
-}
-
-func (c *SchnorrECClient) open() (*types.ECGroupElement, error) {
-	h := c.prover.GetOpeningMsg()
-	ecge := types.ToPbECGroupElement(h)
-	openMsg := &pb.Message{
-		ClientId:      c.id,
-		Schema:        pb.SchemaType_SCHNORR_EC,
-		SchemaVariant: c.variant,
-		Content:       &pb.Message_EcGroupElement{ecge},
+	msg.Content = &pb.Message_SchnorrEcProofRandomData{
+		&pb.SchnorrECProofRandomData{
+			X: types.ToPbECGroupElement(x),
+			A: types.ToPbECGroupElement(c.a),
+			B: types.ToPbECGroupElement(b),
+		},
 	}
-
-	resp, err := c.getResponseTo(openMsg)
+	resp, err := c.getResponseTo(msg)
 	if err != nil {
-		return nil, err
-	}
-
-	ecge = resp.GetEcGroupElement()
-	return types.ToECGroupElement(ecge), nil
-}
-
-func (c *SchnorrECClient) getProofRandomData(isFirstMsg bool) (*pb.PedersenDecommitment, error) {
-	x := c.prover.GetProofRandomData(c.secret, c.a) // x = a^r, b = a^secret is "public key"
-	b1, b2 := c.prover.DLog.Exponentiate(c.a.X, c.a.Y, c.secret)
-	b := &types.ECGroupElement{X: b1, Y: b2}
-
-	pRandomData := pb.SchnorrECProofRandomData{
-		X: types.ToPbECGroupElement(x),
-		A: types.ToPbECGroupElement(c.a),
-		B: types.ToPbECGroupElement(b),
-	}
-
-	req := &pb.Message{}
-	if isFirstMsg {
-		req = &pb.Message{
-			ClientId:      c.id,
-			Schema:        pb.SchemaType_SCHNORR_EC,
-			SchemaVariant: c.variant,
-		}
-	}
-	req.Content = &pb.Message_SchnorrEcProofRandomData{
-		&pRandomData,
+		return err
 	}
 
-	resp, err := c.getResponseTo(req)
+	challenge, err := c.getChallenge(resp, c.receiver)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return resp.GetPedersenDecommitment(), nil
-}
-
-func (c *SchnorrECClient) getProofData(challenge *big.Int) (bool, error) {
-	z, trapdoor := c.prover.GetProofData(challenge)
-	if trapdoor == nil { // sigma protocol and ZKP
-		trapdoor = new(big.Int)
+	z, err := c.holder.GetProofData(challenge)
+	if err != nil {
+		return err
 	}
-	msg := &pb.Message{
+	msg = &pb.Message{
 		Content: &pb.Message_SchnorrProofData{
 			&pb.SchnorrProofData{
-				Z:        z.Bytes(),
-				Trapdoor: trapdoor.Bytes(),
+				Z: z.Bytes(),
 			},
 		},
 	}
-
-	resp, err := c.getResponseTo(msg)
+	resp, err = c.sendProofData(msg, c.receiver)
 	if err != nil {
-		return false, err
+		return err
 	}
-	return resp.GetStatus().Success, nil
+	c.logger.Noticef("Decommitment successful, proved: %v", resp.GetStatus().Success)
+
+	return nil
 }
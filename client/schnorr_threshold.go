@@ -0,0 +1,237 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"google.golang.org/grpc"
+	"math/big"
+)
+
+// ThresholdSchnorrParticipant computes the part of the threshold Schnorr proof of the holder
+// of one share of the secret. It is implemented by dlogproofs.ThresholdSchnorrParticipant
+// (the share is held locally) and ThresholdSchnorrShareClient (the share is held by emmy
+// server).
+type ThresholdSchnorrParticipant interface {
+	// Index returns the index of the share.
+	Index() int
+	// GetProofRandomData starts the proof by the signers and returns g^r_i.
+	GetProofRandomData(signers []int) (*big.Int, error)
+	// GetProofData returns r_i + challenge * lambda_i * x_i.
+	GetProofData(challenge *big.Int) (*big.Int, error)
+	// Reset discards an unfinished proof.
+	Reset()
+}
+
+// ThresholdSchnorrHolder is the coordinator of the threshold Schnorr proof - it performs
+// the computations of SchnorrClient which depend on the secret by combining the parts
+// of the participants, any threshold of which together hold the secret. The verifier
+// sees an ordinary Schnorr proof of knowledge of log_g(publicKey.Y):
+//
+//	holder, err := NewThresholdSchnorrHolder(group, publicKey, participants...)
+//	client, err := NewSchnorrClientFromSecretHolder(conn, group, holder)
+//	err = client.Run()
+type ThresholdSchnorrHolder struct {
+	group        *groups.SchnorrGroup
+	publicKey    *dlogproofs.ThresholdSchnorrPublicKey
+	participants []ThresholdSchnorrParticipant
+	signers      []int
+	coordinator  *dlogproofs.ThresholdSchnorrCoordinator
+}
+
+// NewThresholdSchnorrHolder returns the holder which produces the proofs with the given
+// participants - at least publicKey.Threshold of them are needed.
+func NewThresholdSchnorrHolder(group *groups.SchnorrGroup,
+	publicKey *dlogproofs.ThresholdSchnorrPublicKey,
+	participants ...ThresholdSchnorrParticipant) (*ThresholdSchnorrHolder, error) {
+	signers := make([]int, len(participants))
+	for i, p := range participants {
+		signers[i] = p.Index()
+	}
+	// check the signers before the proof is started
+	if _, err := dlogproofs.NewThresholdSchnorrCoordinator(group, publicKey, signers); err != nil {
+		return nil, err
+	}
+	return &ThresholdSchnorrHolder{
+		group:        group,
+		publicKey:    publicKey,
+		participants: participants,
+		signers:      signers,
+	}, nil
+}
+
+func (h *ThresholdSchnorrHolder) GetPublicKey(a *big.Int) (*big.Int, error) {
+	if err := h.checkBase(a); err != nil {
+		return nil, err
+	}
+	return h.publicKey.Y, nil
+}
+
+func (h *ThresholdSchnorrHolder) GetProofRandomData(a *big.Int) (*big.Int, error) {
+	if err := h.checkBase(a); err != nil {
+		return nil, err
+	}
+	coordinator, err := dlogproofs.NewThresholdSchnorrCoordinator(h.group, h.publicKey,
+		h.signers)
+	if err != nil {
+		return nil, err
+	}
+	h.coordinator = coordinator
+	for _, p := range h.participants {
+		x, err := p.GetProofRandomData(h.signers)
+		if err == nil {
+			err = coordinator.SetProofRandomData(p.Index(), x)
+		}
+		if err != nil {
+			h.reset()
+			return nil, fmt.Errorf("participant %d: %v", p.Index(), err)
+		}
+	}
+	return coordinator.GetProofRandomData()
+}
+
+// GetProofData returns the proof data of the Schnorr proof. It returns an error naming
+// the participant which provided invalid proof data - the proof can then be repeated
+// with other participants.
+func (h *ThresholdSchnorrHolder) GetProofData(challenge *big.Int) (*big.Int, error) {
+	if h.coordinator == nil {
+		return nil, fmt.Errorf("proof random data needs to be obtained first")
+	}
+	defer func() { h.coordinator = nil }()
+	h.coordinator.SetChallenge(challenge)
+	for i, p := range h.participants {
+		z, err := p.GetProofData(challenge)
+		if err != nil {
+			for _, q := range h.participants[i+1:] {
+				q.Reset()
+			}
+			return nil, fmt.Errorf("participant %d: %v", p.Index(), err)
+		}
+		if err := h.coordinator.SetProofData(p.Index(), z); err != nil {
+			for _, q := range h.participants[i+1:] {
+				q.Reset()
+			}
+			return nil, err
+		}
+	}
+	return h.coordinator.GetProofData()
+}
+
+func (h *ThresholdSchnorrHolder) checkBase(a *big.Int) error {
+	if a.Cmp(h.group.G) != 0 {
+		return fmt.Errorf("threshold Schnorr proofs are supported only for the generator")
+	}
+	return nil
+}
+
+func (h *ThresholdSchnorrHolder) reset() {
+	for _, p := range h.participants {
+		p.Reset()
+	}
+	h.coordinator = nil
+}
+
+// ThresholdSchnorrShareClient is the participant of threshold Schnorr proofs whose share
+// is held by emmy server (see server.SetThresholdSchnorrShare).
+type ThresholdSchnorrShareClient struct {
+	genericClient
+	index  int
+	active bool
+}
+
+// NewThresholdSchnorrShareClient returns the participant whose share with the given index
+// is held by the server at the other end of conn.
+func NewThresholdSchnorrShareClient(conn *grpc.ClientConn, index int,
+	opts ...ClientOption) (*ThresholdSchnorrShareClient, error) {
+	genericClient, err := newGenericClient(conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ThresholdSchnorrShareClient{
+		genericClient: *genericClient,
+		index:         index,
+	}, nil
+}
+
+func (c *ThresholdSchnorrShareClient) Index() int {
+	return c.index
+}
+
+// GetProofRandomData opens the stream to the server, which is kept open until GetProofData
+// or Reset.
+func (c *ThresholdSchnorrShareClient) GetProofRandomData(signers []int) (*big.Int, error) {
+	c.Reset()
+	if err := c.openStream(); err != nil {
+		return nil, err
+	}
+	c.active = true
+
+	indices := make([]int32, len(signers))
+	for i, j := range signers {
+		indices[i] = int32(j)
+	}
+	msg := &pb.Message{
+		ClientId:      c.id,
+		Schema:        pb.SchemaType_THRESHOLD_SCHNORR,
+		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Content: &pb.Message_ThresholdSchnorrRequest{
+			&pb.ThresholdSchnorrRequest{
+				Index:   int32(c.index),
+				Signers: indices,
+			},
+		},
+	}
+	resp, err := c.getResponseTo(msg)
+	if err == nil && resp.GetBigint() == nil {
+		err = fmt.Errorf("proof random data expected")
+	}
+	if err != nil {
+		c.Reset()
+		return nil, err
+	}
+	return new(big.Int).SetBytes(resp.GetBigint().X1), nil
+}
+
+func (c *ThresholdSchnorrShareClient) GetProofData(challenge *big.Int) (*big.Int, error) {
+	if !c.active {
+		return nil, fmt.Errorf("proof random data needs to be obtained first")
+	}
+	defer c.Reset()
+	msg := &pb.Message{
+		Content: &pb.Message_Bigint{&pb.BigInt{X1: challenge.Bytes()}},
+	}
+	resp, err := c.getResponseTo(msg)
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetBigint() == nil {
+		return nil, fmt.Errorf("proof data expected")
+	}
+	return new(big.Int).SetBytes(resp.GetBigint().X1), nil
+}
+
+// Reset closes the stream of an unfinished proof.
+func (c *ThresholdSchnorrShareClient) Reset() {
+	if c.active {
+		c.closeStream()
+		c.active = false
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/stern"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"google.golang.org/grpc"
+)
+
+type SternClient struct {
+	genericClient
+	prover    *stern.SternProver
+	publicKey []byte
+}
+
+// NewSternClient returns a client which identifies itself with Stern's protocol, where secret
+// is a vector of weight stern.W (see stern.Params.GenerateKey).
+func NewSternClient(conn *grpc.ClientConn, params *stern.Params, secret []byte,
+	opts ...ClientOption) (*SternClient, error) {
+	prover, err := stern.NewSternProver(params, secret)
+	if err != nil {
+		return nil, err
+	}
+	genericClient, err := newGenericClient(conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := prover.SetSecurity(genericClient.security); err != nil {
+		return nil, err
+	}
+
+	return &SternClient{
+		genericClient: *genericClient,
+		prover:        prover,
+		publicKey:     params.Syndrome(secret),
+	}, nil
+}
+
+// Run executes the identification and returns whether the server accepted it.
+func (c *SternClient) Run() (bool, error) {
+	c.openStream()
+	defer c.closeStream()
+
+	commitments, err := c.prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	pbCommitments := make([]*pb.SternCommitment, len(commitments))
+	for i, commitment := range commitments {
+		pbCommitments[i] = &pb.SternCommitment{
+			C1: commitment.C1,
+			C2: commitment.C2,
+			C3: commitment.C3,
+		}
+	}
+	msg := &pb.Message{
+		ClientId:      c.id,
+		Schema:        pb.SchemaType_STERN,
+		SchemaVariant: pb.SchemaVariant_SIGMA,
+		Content: &pb.Message_SternProofRandomData{
+			&pb.SternProofRandomData{
+				ParamsSeed:  c.prover.Params.Seed,
+				PublicKey:   c.publicKey,
+				Commitments: pbCommitments,
+			},
+		},
+	}
+	resp, err := c.getResponseTo(msg)
+	if err != nil {
+		return false, err
+	}
+
+	ints := resp.GetRepeatedInt()
+	if ints == nil {
+		return false, fmt.Errorf("Challenges expected")
+	}
+	challenges := make([]int, len(ints.Ints))
+	for i, b := range ints.Ints {
+		challenges[i] = int(b)
+	}
+	responses, err := c.prover.GetProofData(challenges)
+	if err != nil {
+		return false, err
+	}
+	pbResponses := make([]*pb.SternResponse, len(responses))
+	for i, r := range responses {
+		pbResponses[i] = &pb.SternResponse{
+			SigmaSeed: r.SigmaSeed,
+			YSeed:     r.YSeed,
+			Y:         r.Y,
+			E:         r.E,
+			Salts:     r.Salts,
+		}
+	}
+	msg = &pb.Message{
+		Content: &pb.Message_SternProofData{
+			&pb.SternProofData{Responses: pbResponses},
+		},
+	}
+	resp, err = c.getResponseTo(msg)
+	if err != nil {
+		return false, err
+	}
+	return resp.GetStatus().Success, nil
+}
@@ -18,12 +18,20 @@
 package config
 
 import (
+	"encoding/hex"
 	"fmt"
 	"github.com/spf13/viper"
 	"github.com/xlab-si/emmy/crypto/dlog"
 	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/compiler"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/crypto/zkp/security"
+	pb "github.com/xlab-si/emmy/protobuf"
 	"github.com/xlab-si/emmy/types"
 	"math/big"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // init loads the default config file
@@ -84,6 +92,19 @@ func LoadGroup(scheme string) *groups.SchnorrGroup {
 	return groups.NewSchnorrGroupFromParams(p, g, q)
 }
 
+// LoadPseudonymsysParams returns the parameters of the pseudonym system. Note that
+// the parameters are not validated.
+func LoadPseudonymsysParams() *pseudonymsys.Params {
+	return pseudonymsys.NewParams(LoadGroup("pseudonymsys"))
+}
+
+// LoadCompilerParams returns the parameters for committing to the challenges in ZKP
+// and ZKPOK variants of the EC protocols (the group of Pedersen commitments). Note that
+// the parameters are not validated.
+func LoadCompilerParams() *compiler.Params {
+	return compiler.NewParams(LoadGroup("pedersen"))
+}
+
 func LoadQR(name string) *dlog.QR {
 	x := viper.GetStringMap(name)
 	p, _ := new(big.Int).SetString(x["p"].(string), 10)
@@ -128,6 +149,56 @@ func LoadPseudonymsysCAPubKey() (*big.Int, *big.Int) {
 	return x, y
 }
 
+// LoadPseudonymsysCASigners returns the signers of the pseudonymsys CA in the order of
+// CA's preference.
+func LoadPseudonymsysCASigners() []pseudonymsys.CASigner {
+	signers := []pseudonymsys.CASigner{}
+
+	ed := viper.GetStringMap("pseudonymsys.ca.ed25519")
+	seed, _ := hex.DecodeString(ed["seed"].(string))
+	if signer, err := pseudonymsys.NewEd25519Signer(seed); err == nil {
+		signers = append(signers, signer)
+	}
+
+	schnorr := viper.GetStringMap("pseudonymsys.ca.schnorr")
+	d, _ := new(big.Int).SetString(schnorr["d"].(string), 10)
+	signers = append(signers, pseudonymsys.NewSchnorrSigner(d))
+
+	x, y := LoadPseudonymsysCAPubKey()
+	signers = append(signers, pseudonymsys.NewECDSASigner(LoadPseudonymsysCASecret(), x, y))
+	return signers
+}
+
+// LoadPseudonymsysCAPubKeys returns the public keys of the pseudonymsys CA for all the
+// supported signature algorithms.
+func LoadPseudonymsysCAPubKeys() []pseudonymsys.CAPubKey {
+	pubKeys := []pseudonymsys.CAPubKey{}
+
+	ed := viper.GetStringMap("pseudonymsys.ca.ed25519")
+	edPubKey, _ := hex.DecodeString(ed["pubkey"].(string))
+	if pubKey, err := pseudonymsys.NewEd25519PubKey(edPubKey); err == nil {
+		pubKeys = append(pubKeys, pubKey)
+	}
+
+	schnorr := viper.GetStringMap("pseudonymsys.ca.schnorr")
+	schnorrX, _ := new(big.Int).SetString(schnorr["x"].(string), 10)
+	schnorrY, _ := new(big.Int).SetString(schnorr["y"].(string), 10)
+	pubKeys = append(pubKeys, pseudonymsys.NewSchnorrPubKey(schnorrX, schnorrY))
+
+	pubKeys = append(pubKeys, pseudonymsys.NewECDSAPubKey(LoadPseudonymsysCAPubKey()))
+	return pubKeys
+}
+
+// LoadPseudonymsysCALogKey returns the secret key d and public key (x, y) of the log
+// where pseudonymsys CA submits the issued certificates.
+func LoadPseudonymsysCALogKey() (*big.Int, *big.Int, *big.Int) {
+	caLog := viper.GetStringMap("pseudonymsys.ca_log")
+	d, _ := new(big.Int).SetString(caLog["d"].(string), 10)
+	x, _ := new(big.Int).SetString(caLog["x"].(string), 10)
+	y, _ := new(big.Int).SetString(caLog["y"].(string), 10)
+	return d, x, y
+}
+
 func LoadServiceInfo() *types.ServiceInfo {
 	serviceName := viper.GetString("service_info.name")
 	serviceProvider := viper.GetString("service_info.provider")
@@ -138,3 +209,136 @@ func LoadServiceInfo() *types.ServiceInfo {
 func LoadSessionKeyMinByteLen() int {
 	return viper.GetInt("session_key_bytelen")
 }
+
+// LoadPedersenReceiverRotation returns the period after which the server regenerates
+// the shared Pedersen receiver parameters.
+func LoadPedersenReceiverRotation() time.Duration {
+	return time.Duration(viper.GetInt("pedersen_receiver_rotation")) * time.Second
+}
+
+// LoadCurves returns the elliptic curves which are supported by the server, the default
+// one first. Unknown curve names are ignored.
+func LoadCurves() []dlog.Curve {
+	curves := []dlog.Curve{}
+	for _, name := range viper.GetStringSlice("ec_curves") {
+		if c, err := dlog.ParseCurve(name); err == nil {
+			curves = append(curves, c)
+		}
+	}
+	return curves
+}
+
+// LoadRoundTimeouts returns the default deadline for each message of the client in
+// server's sessions and the deadlines for particular schemas. Unknown schema names
+// are ignored.
+func LoadRoundTimeouts() (time.Duration, map[pb.SchemaType]time.Duration) {
+	timeouts := make(map[pb.SchemaType]time.Duration)
+	for name := range viper.GetStringMap("round_timeouts") {
+		if schema, ok := pb.SchemaType_value[strings.ToUpper(name)]; ok {
+			timeouts[pb.SchemaType(schema)] = roundTimeout(name)
+		}
+	}
+	return roundTimeout("default"), timeouts
+}
+
+func roundTimeout(name string) time.Duration {
+	return time.Duration(viper.GetInt("round_timeouts."+name)) * time.Second
+}
+
+// LoadSecurityParams returns the default security parameters of the proofs and
+// the parameters for particular schemas, where the parameters which are not set for
+// a schema are taken from the default ones. Unknown schema names are ignored.
+func LoadSecurityParams() (security.Params, map[pb.SchemaType]security.Params) {
+	def := securityParams("default", security.Params{})
+	params := make(map[pb.SchemaType]security.Params)
+	for name := range viper.GetStringMap("security") {
+		if schema, ok := pb.SchemaType_value[strings.ToUpper(name)]; ok {
+			params[pb.SchemaType(schema)] = securityParams(name, def)
+		}
+	}
+	return def, params
+}
+
+// securityParams returns the parameters from the given section, with the ones which are
+// not set there taken from base.
+func securityParams(name string, base security.Params) security.Params {
+	for key, value := range map[string]*int{
+		"level":                  &base.Level,
+		"challenge_bit_length":   &base.ChallengeBitLength,
+		"statistical_bit_length": &base.StatisticalBitLength,
+		"repetitions":            &base.Repetitions,
+	} {
+		if key = "security." + name + "." + key; viper.IsSet(key) {
+			*value = viper.GetInt(key)
+		}
+	}
+	return base
+}
+
+// LoadAdmission returns the budget of the server for the estimated cost of the running
+// sessions (0 means no admission control), the maximum number of sessions waiting
+// to be admitted and the maximum waiting time.
+func LoadAdmission() (float64, int, time.Duration) {
+	budget := viper.GetFloat64("admission.budget")
+	queueLength := viper.GetInt("admission.queue_length")
+	queueTimeout := time.Duration(viper.GetInt("admission.queue_timeout")) * time.Second
+	return budget, queueLength, queueTimeout
+}
+
+// LoadConcurrency returns the limits of the sessions which are open at the same time in total
+// and per client (0 means no limit), the number of sessions of a client which can wait
+// to be admitted and the duration of the wait.
+func LoadConcurrency() (int, int, int, time.Duration) {
+	total := viper.GetInt("concurrency.total")
+	perClient := viper.GetInt("concurrency.per_client")
+	queueLength := viper.GetInt("concurrency.queue_length")
+	queueTimeout := time.Duration(viper.GetInt("concurrency.queue_timeout")) * time.Second
+	return total, perClient, queueLength, queueTimeout
+}
+
+// LoadClientIdentity returns the name of the identity by which the clients are distinguished
+// for the concurrency limits (peer is the default).
+func LoadClientIdentity() string {
+	if identity := viper.GetString("concurrency.identity"); identity != "" {
+		return identity
+	}
+	return "peer"
+}
+
+// LoadClientConcurrencyLimits returns the limits of concurrent sessions of particular
+// clients, which override the limit per client. Invalid limits are ignored.
+func LoadClientConcurrencyLimits() map[string]int {
+	limits := make(map[string]int)
+	for client, limit := range viper.GetStringMap("concurrency.clients") {
+		if l, err := strconv.Atoi(fmt.Sprint(limit)); err == nil && l >= 0 {
+			limits[client] = l
+		}
+	}
+	return limits
+}
+
+// LoadSessionCosts returns the default estimated cost of a session and the costs of
+// the sessions of particular schemas. Unknown schema names are ignored.
+func LoadSessionCosts() (float64, map[pb.SchemaType]float64) {
+	costs := make(map[pb.SchemaType]float64)
+	for name := range viper.GetStringMap("admission.costs") {
+		if schema, ok := pb.SchemaType_value[strings.ToUpper(name)]; ok {
+			costs[pb.SchemaType(schema)] = viper.GetFloat64("admission.costs." + name)
+		}
+	}
+	return viper.GetFloat64("admission.costs.default"), costs
+}
+
+// LoadUsageStatsEpsilon returns the privacy parameter of the usage statistics
+// (0 means the statistics are disabled).
+func LoadUsageStatsEpsilon() float64 {
+	return viper.GetFloat64("usage_stats.epsilon")
+}
+
+// LoadRateLimitParams returns the number of actions allowed per human per scope per period
+// and the duration of the period. Note that the parameters are not validated.
+func LoadRateLimitParams() *pseudonymsys.RateLimitParams {
+	limit := viper.GetInt("rate_limit.limit")
+	period := time.Duration(viper.GetInt("rate_limit.period")) * time.Second
+	return pseudonymsys.NewRateLimitParams(limit, period)
+}
@@ -0,0 +1,322 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package accumulators
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/common"
+	"math/big"
+)
+
+// ChallengeBitLength is the bit length of the challenges in the non-membership proof (the
+// challenges need to be smaller than the smallest prime factor of the order of QR_N).
+const ChallengeBitLength = 128
+
+// ProveNonMembership demonstrates how prover can prove that the value x committed in
+// cx = g^x * h^rx is not accumulated in v.
+func ProveNonMembership(params *commitments.DamgardFujisakiParams, v, x, rx *big.Int,
+	witness *NonMembershipWitness) (bool, error) {
+	cx := params.Commit(x, rx)
+	prover := NewNonMembershipProver(params, v, cx, x, rx, witness)
+	verifier := NewNonMembershipVerifier(params, v, cx)
+
+	proofRandomData, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(proofRandomData); err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+// NonMembershipProver proves in zero knowledge that the value x committed in
+// a Damgard-Fujisaki commitment cx = g^x * h^rx is not accumulated in v, without revealing
+// x or the non-membership witness (a, d). Prover commits to d as cd = d * h^w and to w as
+// cw = g^w * h^rw, and proves the knowledge of x, rx, w, rw, y = x*w, t = x*rw and a such that:
+//
+//	cx = g^x * h^rx
+//	cw = g^w * h^rw
+//	1 = cw^x * g^-y * h^-t (which implies y = x*w)
+//	g = v^a * cd^-x * h^y (which implies v^a = (cd * h^-w)^x * g)
+//
+// All the responses are integers which statistically hide the secrets. Note that the proof
+// does not show that x is a prime - the commitment cx needs to be bound to the element (for
+// example issued or computed by the verifier), otherwise the prover could use x = 1.
+type NonMembershipProver struct {
+	params  *commitments.DamgardFujisakiParams
+	v       *big.Int
+	cx      *big.Int
+	x       *big.Int
+	rx      *big.Int
+	witness *NonMembershipWitness
+	secrets *nonMembershipSecrets
+	masks   *nonMembershipSecrets
+}
+
+// NonMembershipProofRandomData holds the commitments to d and w and the first messages for
+// all four equations.
+type NonMembershipProofRandomData struct {
+	Cd *big.Int
+	Cw *big.Int
+	T1 *big.Int
+	T2 *big.Int
+	T3 *big.Int
+	T4 *big.Int
+}
+
+// NonMembershipProofData holds the responses for x, rx, w, rw, y, t and a.
+type NonMembershipProofData struct {
+	Zx  *big.Int
+	Zrx *big.Int
+	Zw  *big.Int
+	Zrw *big.Int
+	Zy  *big.Int
+	Zt  *big.Int
+	Za  *big.Int
+}
+
+type nonMembershipSecrets struct {
+	x, rx, w, rw, y, t, a *big.Int
+}
+
+// NewNonMembershipProver returns a prover for the commitment cx = g^x * h^rx, where rx is
+// smaller than 2^K * N. The witness needs to be valid for the accumulator value v.
+func NewNonMembershipProver(params *commitments.DamgardFujisakiParams, v, cx, x, rx *big.Int,
+	witness *NonMembershipWitness) *NonMembershipProver {
+	return &NonMembershipProver{
+		params:  params,
+		v:       v,
+		cx:      cx,
+		x:       x,
+		rx:      rx,
+		witness: witness,
+	}
+}
+
+func (prover *NonMembershipProver) GetProofRandomData() (*NonMembershipProofRandomData, error) {
+	params := prover.params
+	bounds := newNonMembershipBounds(params)
+	w, err := common.GetRandomInt(bounds.w)
+	if err != nil {
+		return nil, err
+	}
+	rw, err := common.GetRandomInt(bounds.rw)
+	if err != nil {
+		return nil, err
+	}
+	secrets := &nonMembershipSecrets{
+		x:  prover.x,
+		rx: prover.rx,
+		w:  w,
+		rw: rw,
+		y:  new(big.Int).Mul(prover.x, w),
+		t:  new(big.Int).Mul(prover.x, rw),
+		a:  prover.witness.A,
+	}
+
+	// the masks hide challenge * secret for secrets smaller than the bounds
+	shift := uint(ChallengeBitLength + commitments.DamgardFujisakiK)
+	masks := new(nonMembershipSecrets)
+	for _, m := range []struct {
+		mask  **big.Int
+		bound *big.Int
+	}{
+		{&masks.x, bounds.x}, {&masks.rx, bounds.rx}, {&masks.w, bounds.w},
+		{&masks.rw, bounds.rw}, {&masks.y, bounds.y}, {&masks.t, bounds.t},
+		{&masks.a, bounds.a},
+	} {
+		mask, err := common.GetRandomInt(new(big.Int).Lsh(m.bound, shift))
+		if err != nil {
+			return nil, err
+		}
+		*m.mask = mask
+	}
+	prover.secrets, prover.masks = secrets, masks
+
+	g, h, v := params.G, params.H, prover.v
+	cd := multiExp(params.N, []*big.Int{prover.witness.D, h}, []*big.Int{big.NewInt(1), w})
+	cw := params.Commit(w, rw)
+	if cd == nil || cw == nil {
+		return nil, fmt.Errorf("g and h need to be invertible modulo N")
+	}
+
+	return &NonMembershipProofRandomData{
+		Cd: cd,
+		Cw: cw,
+		T1: params.Commit(masks.x, masks.rx),
+		T2: params.Commit(masks.w, masks.rw),
+		T3: multiExp(params.N, []*big.Int{cw, g, h},
+			[]*big.Int{masks.x, neg(masks.y), neg(masks.t)}),
+		T4: multiExp(params.N, []*big.Int{v, cd, h},
+			[]*big.Int{masks.a, neg(masks.x), masks.y}),
+	}, nil
+}
+
+func (prover *NonMembershipProver) GetProofData(challenge *big.Int) *NonMembershipProofData {
+	s, m := prover.secrets, prover.masks
+	return &NonMembershipProofData{
+		Zx:  response(m.x, challenge, s.x),
+		Zrx: response(m.rx, challenge, s.rx),
+		Zw:  response(m.w, challenge, s.w),
+		Zrw: response(m.rw, challenge, s.rw),
+		Zy:  response(m.y, challenge, s.y),
+		Zt:  response(m.t, challenge, s.t),
+		Za:  response(m.a, challenge, s.a),
+	}
+}
+
+type NonMembershipVerifier struct {
+	params     *commitments.DamgardFujisakiParams
+	v          *big.Int
+	cx         *big.Int
+	randomData *NonMembershipProofRandomData
+	challenge  *big.Int
+}
+
+func NewNonMembershipVerifier(params *commitments.DamgardFujisakiParams,
+	v, cx *big.Int) *NonMembershipVerifier {
+	return &NonMembershipVerifier{
+		params: params,
+		v:      v,
+		cx:     cx,
+	}
+}
+
+func (verifier *NonMembershipVerifier) SetProofRandomData(
+	data *NonMembershipProofRandomData) error {
+	if data == nil {
+		return fmt.Errorf("non-membership proof random data is missing")
+	}
+	for _, e := range []*big.Int{data.Cd, data.Cw, data.T1, data.T2, data.T3, data.T4} {
+		if !isElement(verifier.params.N, e) {
+			return fmt.Errorf("non-membership proof random data is not from the group")
+		}
+	}
+	verifier.randomData = data
+	return nil
+}
+
+func (verifier *NonMembershipVerifier) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(new(big.Int).Lsh(big.NewInt(1), ChallengeBitLength))
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return verifier.challenge, nil
+}
+
+// SetChallenge sets the challenge which was not generated by the verifier (for example
+// the one derived by Fiat-Shamir heuristic).
+func (verifier *NonMembershipVerifier) SetChallenge(challenge *big.Int) {
+	verifier.challenge = challenge
+}
+
+// Verify checks:
+//
+//	g^zx * h^zrx = T1 * cx^challenge
+//	g^zw * h^zrw = T2 * cw^challenge
+//	cw^zx * g^-zy * h^-zt = T3
+//	v^za * cd^-zx * h^zy = T4 * g^challenge
+func (verifier *NonMembershipVerifier) Verify(data *NonMembershipProofData) bool {
+	params := verifier.params
+	rd := verifier.randomData
+	if rd == nil || verifier.challenge == nil || data == nil || data.Zx == nil ||
+		data.Zrx == nil || data.Zw == nil || data.Zrw == nil || data.Zy == nil ||
+		data.Zt == nil || data.Za == nil || !isElement(params.N, verifier.v) ||
+		!isElement(params.N, verifier.cx) {
+		return false
+	}
+	n, g, h, c := params.N, params.G, params.H, verifier.challenge
+	one := big.NewInt(1)
+
+	checks := []struct {
+		bases, exps []*big.Int
+		t, y        *big.Int
+	}{
+		{[]*big.Int{g, h}, []*big.Int{data.Zx, data.Zrx}, rd.T1, verifier.cx},
+		{[]*big.Int{g, h}, []*big.Int{data.Zw, data.Zrw}, rd.T2, rd.Cw},
+		{[]*big.Int{rd.Cw, g, h}, []*big.Int{data.Zx, neg(data.Zy), neg(data.Zt)}, rd.T3, one},
+		{[]*big.Int{verifier.v, rd.Cd, h}, []*big.Int{data.Za, neg(data.Zx), data.Zy}, rd.T4, g},
+	}
+	for _, check := range checks {
+		left := multiExp(n, check.bases, check.exps)
+		right := multiExp(n, []*big.Int{check.t, check.y}, []*big.Int{one, c})
+		if left == nil || right == nil || left.Cmp(right) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// nonMembershipBounds are the bounds for the secrets of the non-membership proof.
+type nonMembershipBounds struct {
+	x, rx, w, rw, y, t, a *big.Int
+}
+
+func newNonMembershipBounds(params *commitments.DamgardFujisakiParams) *nonMembershipBounds {
+	r := params.RandomnessBound()
+	xr := new(big.Int).Mul(params.N, r)
+	return &nonMembershipBounds{
+		x:  params.N,
+		rx: r,
+		w:  r,
+		rw: r,
+		y:  xr,
+		t:  xr,
+		a:  params.N, // a < x
+	}
+}
+
+// response returns mask + challenge * secret (as an integer).
+func response(mask, challenge, secret *big.Int) *big.Int {
+	z := new(big.Int).Mul(challenge, secret)
+	return z.Add(z, mask)
+}
+
+func neg(x *big.Int) *big.Int {
+	return new(big.Int).Neg(x)
+}
+
+// multiExp returns bases[0]^exps[0] * ... * bases[k]^exps[k] mod n (negative exponents are
+// computed with the inverses of the bases) or nil if some base is not invertible.
+func multiExp(n *big.Int, bases, exps []*big.Int) *big.Int {
+	r := big.NewInt(1)
+	for i, base := range bases {
+		e := new(big.Int).Exp(base, exps[i], n)
+		if e == nil {
+			return nil
+		}
+		r.Mul(r, e)
+		r.Mod(r, n)
+	}
+	return r
+}
+
+// isElement checks that x is an invertible element modulo n.
+func isElement(n, x *big.Int) bool {
+	if x == nil || x.Sign() <= 0 || x.Cmp(n) >= 0 {
+		return false
+	}
+	return new(big.Int).GCD(nil, nil, x, n).Cmp(big.NewInt(1)) == 0
+}
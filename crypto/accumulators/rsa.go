@@ -0,0 +1,209 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package accumulators
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/common"
+	"math/big"
+)
+
+// RSA dynamic accumulator (Camenisch, Lysyanskaya: Dynamic Accumulators and Application to
+// Efficient Revocation of Anonymous Credentials) with non-membership witnesses (Li, Li, Xue:
+// Universal Accumulators with Efficient Nonmembership Proofs). The accumulator value for
+// the set of primes {x_1,...,x_k} is v = g^u mod N where u = x_1 * ... * x_k and N is
+// a product of two safe primes.
+//
+// The manager of the accumulator (for example an organization maintaining a revocation list)
+// knows the factorization of N, which allows it to delete elements (v = v^(1/x)) and to
+// issue non-membership witnesses. A non-membership witness for x is a pair (a, d) such that
+// v^a = d^x * g. It exists only when gcd(x, u) = 1: a = u^-1 mod x and d = g^((a*u - 1)/x).
+// Under the strong RSA assumption nobody but the manager can compute it for accumulated x.
+//
+// The public parameters are the Damgard-Fujisaki parameters (N, g, h): g is the base of
+// the accumulator and (g, h) are used to commit to the elements in the non-membership
+// proofs (see NonMembershipProver).
+type RSAAccumulator struct {
+	Params   *commitments.DamgardFujisakiParams
+	Value    *big.Int
+	order    *big.Int // p' * q', the order of QR_N
+	elements map[string]*big.Int
+}
+
+// NewRSAAccumulator returns an empty accumulator (v = g) with the modulus being the product
+// of two safe primes of the given bit length.
+func NewRSAAccumulator(safePrimeBitLength int) (*RSAAccumulator, error) {
+	p, err := common.GetSafePrime(safePrimeBitLength)
+	if err != nil {
+		return nil, err
+	}
+	q, err := common.GetSafePrime(safePrimeBitLength)
+	if err != nil {
+		return nil, err
+	}
+	gen, err := common.GetGeneratorOfCompositeQR(p, q)
+	if err != nil {
+		return nil, err
+	}
+
+	n := new(big.Int).Mul(p, q)
+	p1 := new(big.Int).Rsh(p, 1)
+	q1 := new(big.Int).Rsh(q, 1)
+	order := new(big.Int).Mul(p1, q1)
+
+	g := new(big.Int).Exp(gen, big.NewInt(2), n) // generator of QR_N
+	alpha, err := common.GetRandomInt(order)
+	if err != nil {
+		return nil, err
+	}
+	h := new(big.Int).Exp(g, alpha, n)
+
+	return &RSAAccumulator{
+		Params:   &commitments.DamgardFujisakiParams{N: n, G: g, H: h},
+		Value:    new(big.Int).Set(g),
+		order:    order,
+		elements: make(map[string]*big.Int),
+	}, nil
+}
+
+// Add accumulates a prime x: v = v^x.
+func (acc *RSAAccumulator) Add(x *big.Int) error {
+	if err := acc.checkElement(x); err != nil {
+		return err
+	}
+	if acc.Contains(x) {
+		return fmt.Errorf("element is already accumulated")
+	}
+	acc.Value.Exp(acc.Value, x, acc.Params.N)
+	acc.elements[x.String()] = new(big.Int).Set(x)
+	return nil
+}
+
+// Delete removes x from the accumulator: v = v^(x^-1 mod p'q'). All the non-membership
+// witnesses issued before are invalidated by both Add and Delete, so the users need to
+// obtain the fresh ones for the new accumulator value.
+func (acc *RSAAccumulator) Delete(x *big.Int) error {
+	if !acc.Contains(x) {
+		return fmt.Errorf("element is not accumulated")
+	}
+	xInv := new(big.Int).ModInverse(x, acc.order)
+	if xInv == nil {
+		return fmt.Errorf("element is not invertible modulo the order of the group")
+	}
+	acc.Value.Exp(acc.Value, xInv, acc.Params.N)
+	delete(acc.elements, x.String())
+	return nil
+}
+
+func (acc *RSAAccumulator) Contains(x *big.Int) bool {
+	_, ok := acc.elements[x.String()]
+	return ok
+}
+
+// NonMembershipWitness holds (a, d) such that v^a = d^x * g.
+type NonMembershipWitness struct {
+	A *big.Int
+	D *big.Int
+}
+
+// GetNonMembershipWitness returns the witness that x is not accumulated in the current
+// accumulator value.
+func (acc *RSAAccumulator) GetNonMembershipWitness(x *big.Int) (*NonMembershipWitness, error) {
+	if err := acc.checkElement(x); err != nil {
+		return nil, err
+	}
+	if acc.Contains(x) {
+		return nil, fmt.Errorf("element is accumulated")
+	}
+
+	// u mod x and u mod p'q'
+	uModX := big.NewInt(1)
+	uModOrder := big.NewInt(1)
+	for _, e := range acc.elements {
+		uModX.Mul(uModX, e)
+		uModX.Mod(uModX, x)
+		uModOrder.Mul(uModOrder, e)
+		uModOrder.Mod(uModOrder, acc.order)
+	}
+	a := new(big.Int).ModInverse(uModX, x)
+	if a == nil {
+		return nil, fmt.Errorf("element is not coprime with the accumulated elements")
+	}
+
+	// d = g^((a*u - 1) / x), the exponent is computed modulo p'q'
+	xInv := new(big.Int).ModInverse(x, acc.order)
+	if xInv == nil {
+		return nil, fmt.Errorf("element is not invertible modulo the order of the group")
+	}
+	e := new(big.Int).Mul(a, uModOrder)
+	e.Sub(e, big.NewInt(1))
+	e.Mul(e, xInv)
+	e.Mod(e, acc.order)
+
+	return &NonMembershipWitness{
+		A: a,
+		D: new(big.Int).Exp(acc.Params.G, e, acc.Params.N),
+	}, nil
+}
+
+func (acc *RSAAccumulator) checkElement(x *big.Int) error {
+	if x == nil || x.Cmp(big.NewInt(2)) <= 0 || !x.ProbablyPrime(20) {
+		return fmt.Errorf("accumulated elements need to be odd primes")
+	}
+	return nil
+}
+
+// VerifyNonMembershipWitness checks v^a = d^x * g, which proves that x is not accumulated
+// in v. It reveals x and the witness - to hide them, use the non-membership proof.
+func VerifyNonMembershipWitness(params *commitments.DamgardFujisakiParams, v, x *big.Int,
+	witness *NonMembershipWitness) bool {
+	if witness == nil || witness.A == nil || witness.D == nil || witness.A.Sign() == 0 {
+		return false
+	}
+	left := new(big.Int).Exp(v, witness.A, params.N)
+	right := new(big.Int).Exp(witness.D, x, params.N)
+	if left == nil || right == nil {
+		return false
+	}
+	right.Mul(right, params.G)
+	right.Mod(right, params.N)
+	return left.Cmp(right) == 0
+}
+
+// HashToPrime maps data to a prime of the given bit length (at most 512): it hashes data
+// together with a counter until the hash (with the highest and the lowest bit set) is
+// a prime.
+func HashToPrime(data []byte, bitLength int) (*big.Int, error) {
+	if bitLength < 3 || bitLength > 8*sha512.Size {
+		return nil, fmt.Errorf("bit length needs to be between 3 and %d", 8*sha512.Size)
+	}
+	for counter := 0; ; counter++ {
+		h := sha512.New()
+		h.Write(data)
+		h.Write([]byte(fmt.Sprintf("%d", counter)))
+		x := new(big.Int).SetBytes(h.Sum(nil))
+		x.Rsh(x, uint(8*sha512.Size-bitLength))
+		x.SetBit(x, bitLength-1, 1)
+		x.SetBit(x, 0, 1)
+		if x.ProbablyPrime(20) {
+			return x, nil
+		}
+	}
+}
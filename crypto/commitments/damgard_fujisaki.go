@@ -0,0 +1,145 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package commitments
+
+import (
+	"errors"
+	"github.com/xlab-si/emmy/crypto/common"
+	"math/big"
+)
+
+// DamgardFujisakiK is the statistical hiding parameter of Damgard-Fujisaki commitments:
+// the randomness is chosen from [0, 2^K * N).
+const DamgardFujisakiK = 80
+
+// Damgard-Fujisaki (also Fujisaki-Okamoto) integer commitment scheme: c = g^x * h^r mod N,
+// where N is a product of two safe primes and g, h are generators of the group of quadratic
+// residues modulo N. As the order of the group is hidden, the committed value x is bound as
+// an integer (not only modulo the order) and can be negative. The committer must not know
+// the factorization of N nor log_h(g) - the parameters are generated by the receiver.
+type DamgardFujisakiParams struct {
+	N *big.Int
+	G *big.Int
+	H *big.Int
+}
+
+// NewDamgardFujisakiParams generates N as a product of two safe primes of the given bit
+// length, h as a random generator of QR_N and g = h^alpha for a random alpha.
+func NewDamgardFujisakiParams(safePrimeBitLength int) (*DamgardFujisakiParams, error) {
+	p, err := common.GetSafePrime(safePrimeBitLength)
+	if err != nil {
+		return nil, err
+	}
+	q, err := common.GetSafePrime(safePrimeBitLength)
+	if err != nil {
+		return nil, err
+	}
+	gen, err := common.GetGeneratorOfCompositeQR(p, q)
+	if err != nil {
+		return nil, err
+	}
+
+	n := new(big.Int).Mul(p, q)
+	h := new(big.Int).Exp(gen, big.NewInt(2), n) // generator of QR_N
+	alpha, err := common.GetRandomInt(n)
+	if err != nil {
+		return nil, err
+	}
+	g := new(big.Int).Exp(h, alpha, n)
+	return &DamgardFujisakiParams{N: n, G: g, H: h}, nil
+}
+
+// Commit returns g^x * h^r mod N (x and r can be negative).
+func (params *DamgardFujisakiParams) Commit(x, r *big.Int) *big.Int {
+	gToX := new(big.Int).Exp(params.G, x, params.N)
+	hToR := new(big.Int).Exp(params.H, r, params.N)
+	if gToX == nil || hToR == nil { // not invertible, the parameters are broken
+		return nil
+	}
+	c := new(big.Int).Mul(gToX, hToR)
+	return c.Mod(c, params.N)
+}
+
+// RandomnessBound returns 2^K * N, the bound for the randomness of the commitments.
+func (params *DamgardFujisakiParams) RandomnessBound() *big.Int {
+	return new(big.Int).Lsh(params.N, DamgardFujisakiK)
+}
+
+// Committer commits to an integer x - it sends to receiver c = g^x * h^r mod N.
+// When decommitting, committer sends to receiver r, x; receiver checks whether c = g^x * h^r.
+type DamgardFujisakiCommitter struct {
+	Params         *DamgardFujisakiParams
+	committedValue *big.Int
+	r              *big.Int
+}
+
+func NewDamgardFujisakiCommitter(params *DamgardFujisakiParams) *DamgardFujisakiCommitter {
+	return &DamgardFujisakiCommitter{
+		Params: params,
+	}
+}
+
+// GetCommitMsg chooses a random r from [0, 2^K * N) and returns c = g^x * h^r mod N.
+func (committer *DamgardFujisakiCommitter) GetCommitMsg(x *big.Int) (*big.Int, error) {
+	r, err := common.GetRandomInt(committer.Params.RandomnessBound())
+	if err != nil {
+		return nil, err
+	}
+	c := committer.Params.Commit(x, r)
+	if c == nil {
+		return nil, errors.New("g and h need to be invertible modulo N")
+	}
+
+	committer.committedValue = x
+	committer.r = r
+	return c, nil
+}
+
+// It returns values x and r (commitment was c = g^x * h^r).
+func (committer *DamgardFujisakiCommitter) GetDecommitMsg() (*big.Int, *big.Int) {
+	return committer.committedValue, committer.r
+}
+
+type DamgardFujisakiReceiver struct {
+	Params     *DamgardFujisakiParams
+	commitment *big.Int
+}
+
+// NewDamgardFujisakiReceiver generates new parameters (see NewDamgardFujisakiParams) which
+// need to be sent to the committer.
+func NewDamgardFujisakiReceiver(safePrimeBitLength int) (*DamgardFujisakiReceiver, error) {
+	params, err := NewDamgardFujisakiParams(safePrimeBitLength)
+	if err != nil {
+		return nil, err
+	}
+	return &DamgardFujisakiReceiver{
+		Params: params,
+	}, nil
+}
+
+// When receiver receives a commitment, it stores the value using SetCommitment method.
+func (receiver *DamgardFujisakiReceiver) SetCommitment(c *big.Int) {
+	receiver.commitment = c
+}
+
+// CheckDecommitment verifies the decommitment against the stored value (stored by
+// SetCommitment).
+func (receiver *DamgardFujisakiReceiver) CheckDecommitment(r, x *big.Int) bool {
+	c := receiver.Params.Commit(x, r)
+	return c != nil && receiver.commitment != nil && c.Cmp(receiver.commitment) == 0
+}
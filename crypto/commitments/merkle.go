@@ -0,0 +1,174 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package commitments
+
+import (
+	"crypto/sha256"
+	"errors"
+	"github.com/xlab-si/emmy/crypto/common"
+	"math/big"
+)
+
+// MerkleCommitter commits to a (possibly large) list of attributes with a single value -
+// the root of a Merkle tree whose leaves are salted hashes of attributes. Any of the
+// attributes can later be decommitted separately by revealing the attribute, its salt and
+// the hashes of siblings on the path to the root (log(n) hashes), while the other attributes
+// remain hidden (salts prevent guessing the attributes from the hashes of siblings).
+// The number of leaves is padded to a power of two.
+type MerkleCommitter struct {
+	attributes []*big.Int
+	salts      []*big.Int
+	levels     [][][]byte // levels[0] are leaves, the last level contains only the root
+}
+
+// MerkleDecommitment reveals the attribute at Index. Path contains the hashes
+// of siblings from the leaf level up to (but not including) the root.
+type MerkleDecommitment struct {
+	Index     int
+	Attribute *big.Int
+	Salt      *big.Int
+	Path      [][]byte
+}
+
+func NewMerkleCommitter() *MerkleCommitter {
+	return &MerkleCommitter{}
+}
+
+// GetCommitMsg builds a Merkle tree from the attributes and returns its root.
+func (committer *MerkleCommitter) GetCommitMsg(attributes []*big.Int) (*big.Int, error) {
+	if len(attributes) == 0 {
+		return nil, errors.New("at least one attribute is needed")
+	}
+
+	saltBound := new(big.Int).Lsh(big.NewInt(1), 8*sha256.Size)
+	salts := make([]*big.Int, len(attributes))
+	size := 1
+	for size < len(attributes) {
+		size *= 2
+	}
+	leaves := make([][]byte, size)
+	for i := range leaves {
+		if i < len(attributes) {
+			salt, err := common.GetRandomInt(saltBound)
+			if err != nil {
+				return nil, err
+			}
+			salts[i] = salt
+			leaves[i] = hashMerkleLeaf(attributes[i], salts[i])
+		} else {
+			leaves[i] = hashMerklePadding()
+		}
+	}
+
+	levels := [][][]byte{leaves}
+	for level := leaves; len(level) > 1; {
+		parents := make([][]byte, len(level)/2)
+		for i := range parents {
+			parents[i] = hashMerkleNode(level[2*i], level[2*i+1])
+		}
+		levels = append(levels, parents)
+		level = parents
+	}
+
+	committer.attributes = attributes
+	committer.salts = salts
+	committer.levels = levels
+	return new(big.Int).SetBytes(levels[len(levels)-1][0]), nil
+}
+
+// GetDecommitMsg returns the decommitment of the attribute at index.
+func (committer *MerkleCommitter) GetDecommitMsg(index int) (*MerkleDecommitment, error) {
+	if index < 0 || index >= len(committer.attributes) {
+		return nil, errors.New("attribute index is out of range")
+	}
+
+	var path [][]byte
+	pos := index
+	for _, level := range committer.levels[:len(committer.levels)-1] {
+		path = append(path, level[pos^1])
+		pos /= 2
+	}
+
+	return &MerkleDecommitment{
+		Index:     index,
+		Attribute: committer.attributes[index],
+		Salt:      committer.salts[index],
+		Path:      path,
+	}, nil
+}
+
+type MerkleReceiver struct {
+	commitment *big.Int
+}
+
+func NewMerkleReceiver() *MerkleReceiver {
+	return &MerkleReceiver{}
+}
+
+// When receiver receives a commitment (the root of Merkle tree), it stores it.
+func (receiver *MerkleReceiver) SetCommitment(root *big.Int) {
+	receiver.commitment = root
+}
+
+// CheckDecommitment recomputes the root from the revealed attribute and the path
+// and checks whether it matches the commitment.
+func (receiver *MerkleReceiver) CheckDecommitment(decommitment *MerkleDecommitment) bool {
+	if decommitment.Index < 0 || decommitment.Index >= 1<<uint(len(decommitment.Path)) ||
+		decommitment.Salt.BitLen() > 8*sha256.Size {
+		return false
+	}
+
+	hash := hashMerkleLeaf(decommitment.Attribute, decommitment.Salt)
+	pos := decommitment.Index
+	for _, sibling := range decommitment.Path {
+		if pos%2 == 0 {
+			hash = hashMerkleNode(hash, sibling)
+		} else {
+			hash = hashMerkleNode(sibling, hash)
+		}
+		pos /= 2
+	}
+
+	return new(big.Int).SetBytes(hash).Cmp(receiver.commitment) == 0
+}
+
+// Leaves, padding and inner nodes are hashed with different prefixes, so that
+// an inner node cannot be presented as a leaf.
+func hashMerkleLeaf(attribute, salt *big.Int) []byte {
+	// salt is padded to a fixed length, so that (salt, attribute) encoding is unambiguous
+	saltBytes := salt.Bytes()
+	h := sha256.New()
+	h.Write([]byte{0})
+	h.Write(make([]byte, sha256.Size-len(saltBytes)))
+	h.Write(saltBytes)
+	h.Write(attribute.Bytes())
+	return h.Sum(nil)
+}
+
+func hashMerkleNode(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+func hashMerklePadding() []byte {
+	h := sha256.Sum256([]byte{2})
+	return h[:]
+}
@@ -0,0 +1,212 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package commitments
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+)
+
+// MerkleLog is an append-only Merkle tree as used by certificate transparency logs
+// (RFC 6962). Unlike MerkleCommitter, the tree is not padded - the root of the first
+// n entries is defined for every n, so the log can prove that an entry is included in
+// the tree of a given size (inclusion proof) and that the tree of a given size is
+// an extension of a smaller tree (consistency proof), both with log(n) hashes.
+// Leaves and inner nodes are hashed with different prefixes (as in MerkleCommitter).
+type MerkleLog struct {
+	leaves [][]byte // hashes of the leaves
+}
+
+func NewMerkleLog() *MerkleLog {
+	return &MerkleLog{}
+}
+
+// Append adds the entry to the log and returns its index.
+func (log *MerkleLog) Append(entry []byte) int {
+	log.leaves = append(log.leaves, hashMerkleLogLeaf(entry))
+	return len(log.leaves) - 1
+}
+
+// Size returns the number of the entries in the log.
+func (log *MerkleLog) Size() int {
+	return len(log.leaves)
+}
+
+// GetRoot returns the root of the tree of the first size entries.
+func (log *MerkleLog) GetRoot(size int) ([]byte, error) {
+	if size < 0 || size > len(log.leaves) {
+		return nil, errors.New("tree size is out of range")
+	}
+	return merkleLogRoot(log.leaves[:size]), nil
+}
+
+// GetInclusionProof returns the hashes which are needed to compute the root of the tree of
+// the first size entries from the entry at index (see VerifyMerkleLogInclusion).
+func (log *MerkleLog) GetInclusionProof(index, size int) ([][]byte, error) {
+	if size < 0 || size > len(log.leaves) || index < 0 || index >= size {
+		return nil, errors.New("entry index or tree size is out of range")
+	}
+	return merkleLogPath(index, log.leaves[:size]), nil
+}
+
+// GetConsistencyProof returns the hashes which are needed to check that the tree of
+// the first newSize entries extends the tree of the first oldSize entries (see
+// VerifyMerkleLogConsistency).
+func (log *MerkleLog) GetConsistencyProof(oldSize, newSize int) ([][]byte, error) {
+	if oldSize < 0 || oldSize > newSize || newSize > len(log.leaves) {
+		return nil, errors.New("tree sizes are out of range")
+	}
+	if oldSize == 0 || oldSize == newSize {
+		return nil, nil
+	}
+	return merkleLogSubproof(oldSize, log.leaves[:newSize], true), nil
+}
+
+// VerifyMerkleLogInclusion checks that the entry is at index in the tree of the given size
+// and root (RFC 9162, section 2.1.3.2).
+func VerifyMerkleLogInclusion(entry []byte, index, size int, proof [][]byte,
+	root []byte) bool {
+	if index < 0 || index >= size {
+		return false
+	}
+	fn, sn := index, size-1
+	hash := hashMerkleLogLeaf(entry)
+	for _, p := range proof {
+		if sn == 0 {
+			return false
+		}
+		if fn%2 == 1 || fn == sn {
+			hash = hashMerkleNode(p, hash)
+			for fn%2 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			hash = hashMerkleNode(hash, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(hash, root)
+}
+
+// VerifyMerkleLogConsistency checks that the tree of newSize entries with root newRoot
+// extends the tree of oldSize entries with root oldRoot (RFC 9162, section 2.1.4.2).
+func VerifyMerkleLogConsistency(oldSize, newSize int, oldRoot, newRoot []byte,
+	proof [][]byte) bool {
+	if oldSize < 0 || oldSize > newSize {
+		return false
+	}
+	if oldSize == newSize {
+		return len(proof) == 0 && bytes.Equal(oldRoot, newRoot)
+	}
+	if oldSize == 0 {
+		return len(proof) == 0 && bytes.Equal(oldRoot, merkleLogRoot(nil))
+	}
+	if len(proof) == 0 {
+		return false
+	}
+
+	// if the old tree is a complete subtree, its root is the first node of the path
+	if oldSize&(oldSize-1) == 0 {
+		proof = append([][]byte{oldRoot}, proof...)
+	}
+	fn, sn := oldSize-1, newSize-1
+	for fn%2 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	oldHash, newHash := proof[0], proof[0]
+	for _, p := range proof[1:] {
+		if sn == 0 {
+			return false
+		}
+		if fn%2 == 1 || fn == sn {
+			oldHash = hashMerkleNode(p, oldHash)
+			newHash = hashMerkleNode(p, newHash)
+			for fn%2 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			newHash = hashMerkleNode(newHash, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(oldHash, oldRoot) && bytes.Equal(newHash, newRoot)
+}
+
+// merkleLogRoot returns the root of the tree with the given leaves: the left subtree is
+// the largest complete tree with less than len(leaves) leaves.
+func merkleLogRoot(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		h := sha256.Sum256(nil)
+		return h[:]
+	case 1:
+		return leaves[0]
+	}
+	k := merkleLogSplit(len(leaves))
+	return hashMerkleNode(merkleLogRoot(leaves[:k]), merkleLogRoot(leaves[k:]))
+}
+
+func merkleLogPath(index int, leaves [][]byte) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := merkleLogSplit(len(leaves))
+	if index < k {
+		return append(merkleLogPath(index, leaves[:k]), merkleLogRoot(leaves[k:]))
+	}
+	return append(merkleLogPath(index-k, leaves[k:]), merkleLogRoot(leaves[:k]))
+}
+
+// merkleLogSubproof is SUBPROOF of RFC 6962 (section 2.1.2), complete tells whether
+// the subtree of the first oldSize leaves is the old tree itself.
+func merkleLogSubproof(oldSize int, leaves [][]byte, complete bool) [][]byte {
+	if oldSize == len(leaves) {
+		if complete {
+			return nil
+		}
+		return [][]byte{merkleLogRoot(leaves)}
+	}
+	k := merkleLogSplit(len(leaves))
+	if oldSize <= k {
+		return append(merkleLogSubproof(oldSize, leaves[:k], complete),
+			merkleLogRoot(leaves[k:]))
+	}
+	return append(merkleLogSubproof(oldSize-k, leaves[k:], false), merkleLogRoot(leaves[:k]))
+}
+
+// merkleLogSplit returns the largest power of two smaller than n (n > 1).
+func merkleLogSplit(n int) int {
+	k := 1
+	for 2*k < n {
+		k *= 2
+	}
+	return k
+}
+
+func hashMerkleLogLeaf(entry []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0})
+	h.Write(entry)
+	return h.Sum(nil)
+}
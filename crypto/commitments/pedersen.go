@@ -51,6 +51,10 @@ func (committer *PedersenCommitter) SetH(h *big.Int) {
 	committer.h = h
 }
 
+func (committer *PedersenCommitter) GetH() *big.Int {
+	return committer.h
+}
+
 // It receives a value x (to this value a commitment is made), chooses a random x, outputs c = g^x * g^r.
 func (committer *PedersenCommitter) GetCommitMsg(val *big.Int) (*big.Int, error) {
 	if val.Cmp(committer.group.Q) == 1 || val.Cmp(big.NewInt(0)) == -1 {
@@ -59,7 +63,10 @@ func (committer *PedersenCommitter) GetCommitMsg(val *big.Int) (*big.Int, error)
 	}
 
 	// c = g^x * h^r
-	r := common.GetRandomInt(committer.group.Q)
+	r, err := common.GetRandomInt(committer.group.Q)
+	if err != nil {
+		return nil, err
+	}
 
 	committer.r = r
 	committer.committedValue = val
@@ -77,6 +84,13 @@ func (committer *PedersenCommitter) GetDecommitMsg() (*big.Int, *big.Int) {
 	return val, r
 }
 
+// SetDecommitMsg sets the committed value x and randomness r of the commitment which was
+// computed earlier (for example when the committer's state is restored).
+func (committer *PedersenCommitter) SetDecommitMsg(val, r *big.Int) {
+	committer.committedValue = val
+	committer.r = r
+}
+
 func (committer *PedersenCommitter) VerifyTrapdoor(trapdoor *big.Int) bool {
 	h := committer.group.Exp(committer.group.G, trapdoor)
 	if h.Cmp(committer.h) == 0 {
@@ -91,10 +105,51 @@ type PedersenReceiver struct {
 	a          *big.Int
 	h          *big.Int
 	commitment *big.Int
+	params     *PedersenReceiverParams // nil if receiver has its own trapdoor
+}
+
+// PedersenReceiverParams are receiver's trapdoor a and h = g^a together with the precomputed
+// tables for exponentiation of g and h. Generating h and the tables is costly, thus
+// the parameters can be shared by many receivers (for example by the server's sessions) -
+// note that they need to be replaced from time to time, as the trapdoor is kept in memory.
+type PedersenReceiverParams struct {
+	Group  *groups.SchnorrGroup
+	a      *big.Int
+	h      *big.Int
+	gTable *groups.ExpTable
+	hTable *groups.ExpTable
 }
 
-func NewPedersenReceiver(group *groups.SchnorrGroup) *PedersenReceiver {
-	a := common.GetRandomInt(group.Q)
+func NewPedersenReceiverParams(group *groups.SchnorrGroup) (*PedersenReceiverParams, error) {
+	a, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, err
+	}
+	h := group.Exp(group.G, a)
+	return &PedersenReceiverParams{
+		Group:  group,
+		a:      a,
+		h:      h,
+		gTable: groups.NewExpTable(group, group.G),
+		hTable: groups.NewExpTable(group, h),
+	}, nil
+}
+
+// NewPedersenReceiverFromParams returns a receiver which uses the shared parameters.
+func NewPedersenReceiverFromParams(params *PedersenReceiverParams) *PedersenReceiver {
+	return &PedersenReceiver{
+		group:  params.Group,
+		a:      params.a,
+		h:      params.h,
+		params: params,
+	}
+}
+
+func NewPedersenReceiver(group *groups.SchnorrGroup) (*PedersenReceiver, error) {
+	a, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, err
+	}
 	h := group.Exp(group.G, a)
 
 	receiver := new(PedersenReceiver)
@@ -102,11 +157,14 @@ func NewPedersenReceiver(group *groups.SchnorrGroup) *PedersenReceiver {
 	receiver.a = a
 	receiver.h = h
 
-	return receiver
+	return receiver, nil
 }
 
-func NewPedersenReceiverFromExistingDLog(group *groups.SchnorrGroup) *PedersenReceiver {
-	a := common.GetRandomInt(group.Q)
+func NewPedersenReceiverFromExistingDLog(group *groups.SchnorrGroup) (*PedersenReceiver, error) {
+	a, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, err
+	}
 	h := group.Exp(group.G, a)
 
 	receiver := new(PedersenReceiver)
@@ -114,7 +172,17 @@ func NewPedersenReceiverFromExistingDLog(group *groups.SchnorrGroup) *PedersenRe
 	receiver.a = a
 	receiver.h = h
 
-	return receiver
+	return receiver, nil
+}
+
+// NewPedersenReceiverFromH returns a receiver which uses h of somebody else (for example
+// the public key of the committer) - the receiver does not know the trapdoor, thus
+// the commitments are binding only if the committer does not know it either.
+func NewPedersenReceiverFromH(group *groups.SchnorrGroup, h *big.Int) *PedersenReceiver {
+	return &PedersenReceiver{
+		group: group,
+		h:     h,
+	}
 }
 
 func (s *PedersenReceiver) GetH() *big.Int {
@@ -125,17 +193,57 @@ func (s *PedersenReceiver) GetTrapdoor() *big.Int {
 	return s.a
 }
 
+// SetTrapdoor replaces the trapdoor a (and h = g^a) of the receiver, for example when
+// the receiver's state is restored. The precomputed tables are dropped, as they are
+// for the old h.
+func (s *PedersenReceiver) SetTrapdoor(a *big.Int) {
+	s.a = a
+	s.h = s.group.Exp(s.group.G, a)
+	s.params = nil
+}
+
+// Precompute builds the tables for exponentiation of g and h with windows of
+// the given size (see groups.NewExpTableWithWindow), which makes CheckDecommitment faster.
+func (s *PedersenReceiver) Precompute(window int) error {
+	gTable, err := groups.NewExpTableWithWindow(s.group, s.group.G, window)
+	if err != nil {
+		return err
+	}
+	hTable, err := groups.NewExpTableWithWindow(s.group, s.h, window)
+	if err != nil {
+		return err
+	}
+	s.params = &PedersenReceiverParams{
+		Group:  s.group,
+		a:      s.a,
+		h:      s.h,
+		gTable: gTable,
+		hTable: hTable,
+	}
+	return nil
+}
+
 // When receiver receives a commitment, it stores the value using SetCommitment method.
 func (s *PedersenReceiver) SetCommitment(el *big.Int) {
 	s.commitment = el
 }
 
+func (s *PedersenReceiver) GetCommitment() *big.Int {
+	return s.commitment
+}
+
 // When receiver receives a decommitment, CheckDecommitment verifies it against the stored value
 // (stored by SetCommitment).
 func (s *PedersenReceiver) CheckDecommitment(r, val *big.Int) bool {
-	t1 := s.group.Exp(s.group.G, val) // g^x
-	t2 := s.group.Exp(s.h, r)         // h^r
-	c := s.group.Mul(t1, t2)          // g^x * h^r
+	var t1, t2 *big.Int
+	if s.params != nil {
+		t1 = s.params.gTable.Exp(val) // g^x
+		t2 = s.params.hTable.Exp(r)   // h^r
+	} else {
+		t1 = s.group.Exp(s.group.G, val) // g^x
+		t2 = s.group.Exp(s.h, r)         // h^r
+	}
+	c := s.group.Mul(t1, t2) // g^x * h^r
 
 	var success bool
 	if c.Cmp(s.commitment) == 0 {
@@ -48,6 +48,10 @@ func (committer *PedersenECCommitter) SetH(h *types.ECGroupElement) {
 	committer.h = h
 }
 
+func (committer *PedersenECCommitter) GetH() *types.ECGroupElement {
+	return committer.h
+}
+
 // It receives a value x (to this value a commitment is made), chooses a random x, outputs c = g^x * g^r.
 func (committer *PedersenECCommitter) GetCommitMsg(val *big.Int) (*types.ECGroupElement, error) {
 	if val.Cmp(committer.dLog.OrderOfSubgroup) == 1 || val.Cmp(big.NewInt(0)) == -1 {
@@ -55,16 +59,23 @@ func (committer *PedersenECCommitter) GetCommitMsg(val *big.Int) (*types.ECGroup
 		return nil, err
 	}
 
+	// h = g^a needs to be a point from the subgroup different from the point at infinity,
+	// otherwise the commitment would not hide the value
+	if !committer.dLog.IsInSubgroup(committer.h) || committer.h.IsInfinity() {
+		return nil, errors.New("h needs to be a point from the subgroup (not the point at infinity)")
+	}
+
 	// c = g^x * h^r
-	r := common.GetRandomInt(committer.dLog.OrderOfSubgroup)
+	r, err := common.GetRandomInt(committer.dLog.OrderOfSubgroup)
+	if err != nil {
+		return nil, err
+	}
 
 	committer.r = r
 	committer.committedValue = val
-	x1, y1 := committer.dLog.ExponentiateBaseG(val)
-	x2, y2 := committer.dLog.Exponentiate(committer.h.X, committer.h.Y, r)
-	c1, c2 := committer.dLog.Multiply(x1, y1, x2, y2)
+	c := committer.dLog.Mul(committer.dLog.ExpBaseG(val), committer.dLog.Exp(committer.h, r))
 
-	return &types.ECGroupElement{X: c1, Y: c2}, nil
+	return c, nil
 }
 
 // It returns values x and r (commitment was c = g^x * g^r).
@@ -75,13 +86,15 @@ func (committer *PedersenECCommitter) GetDecommitMsg() (*big.Int, *big.Int) {
 	return val, r
 }
 
+// SetDecommitMsg sets the committed value x and randomness r of the commitment which was
+// computed earlier (for example when the committer's state is restored).
+func (committer *PedersenECCommitter) SetDecommitMsg(val, r *big.Int) {
+	committer.committedValue = val
+	committer.r = r
+}
+
 func (committer *PedersenECCommitter) VerifyTrapdoor(trapdoor *big.Int) bool {
-	hx, hy := committer.dLog.ExponentiateBaseG(trapdoor)
-	if hx.Cmp(committer.h.X) == 0 && hy.Cmp(committer.h.Y) == 0 {
-		return true
-	} else {
-		return false
-	}
+	return committer.dLog.ExpBaseG(trapdoor).Equals(committer.h)
 }
 
 type PedersenECReceiver struct {
@@ -91,18 +104,20 @@ type PedersenECReceiver struct {
 	commitment *types.ECGroupElement
 }
 
-func NewPedersenECReceiver(curve dlog.Curve) *PedersenECReceiver {
+func NewPedersenECReceiver(curve dlog.Curve) (*PedersenECReceiver, error) {
 	dLog := dlog.NewECDLog(curve)
 
-	a := common.GetRandomInt(dLog.OrderOfSubgroup)
-	x, y := dLog.ExponentiateBaseG(a)
+	a, err := common.GetRandomInt(dLog.OrderOfSubgroup)
+	if err != nil {
+		return nil, err
+	}
 
 	receiver := new(PedersenECReceiver)
 	receiver.dLog = dLog
 	receiver.a = a
-	receiver.h = &types.ECGroupElement{X: x, Y: y}
+	receiver.h = dLog.ExpBaseG(a)
 
-	return receiver
+	return receiver, nil
 }
 
 func (s *PedersenECReceiver) GetH() *types.ECGroupElement {
@@ -113,24 +128,26 @@ func (s *PedersenECReceiver) GetTrapdoor() *big.Int {
 	return s.a
 }
 
+// SetTrapdoor replaces the trapdoor a (and h = g^a) of the receiver, for example when
+// the receiver's state is restored.
+func (s *PedersenECReceiver) SetTrapdoor(a *big.Int) {
+	s.a = a
+	s.h = s.dLog.ExpBaseG(a)
+}
+
 // When receiver receives a commitment, it stores the value using SetCommitment method.
 func (s *PedersenECReceiver) SetCommitment(el *types.ECGroupElement) {
 	s.commitment = el
 }
 
+func (s *PedersenECReceiver) GetCommitment() *types.ECGroupElement {
+	return s.commitment
+}
+
 // When receiver receives a decommitment, CheckDecommitment verifies it against the stored value
 // (stored by SetCommitment).
 func (s *PedersenECReceiver) CheckDecommitment(r, val *big.Int) bool {
-	x1, y1 := s.dLog.ExponentiateBaseG(val)        // g^x
-	x2, y2 := s.dLog.Exponentiate(s.h.X, s.h.Y, r) // h^r
-	c1, c2 := s.dLog.Multiply(x1, y1, x2, y2)      // g^x * h^r
-
-	var success bool
-	if c1.Cmp(s.commitment.X) == 0 && c2.Cmp(s.commitment.Y) == 0 {
-		success = true
-	} else {
-		success = false
-	}
+	c := s.dLog.Mul(s.dLog.ExpBaseG(val), s.dLog.Exp(s.h, r)) // g^x * h^r
 
-	return success
+	return c.Equals(s.commitment)
 }
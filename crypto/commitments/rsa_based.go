@@ -93,19 +93,25 @@ func (committer *RSABasedCommitter) GetCommitMsg(a *big.Int) (*big.Int, error) {
 		err := errors.New("the committed value needs to be < Q")
 		return nil, err
 	}
-	c, r := committer.computeCommitment(a)
+	c, r, err := committer.computeCommitment(a)
+	if err != nil {
+		return nil, err
+	}
 	committer.committedValue = a
 	committer.r = r
 	return c, nil
 }
 
-func (committer *RSABasedCommitter) computeCommitment(a *big.Int) (*big.Int, *big.Int) {
+func (committer *RSABasedCommitter) computeCommitment(a *big.Int) (*big.Int, *big.Int, error) {
 	// Y^a * r^Q mod N, where r is random from Z_N*
-	r := committer.H.GetRandomElement()
+	r, err := committer.H.GetRandomElement()
+	if err != nil {
+		return nil, nil, err
+	}
 	t1 := committer.H.Exp(committer.Y, a)
 	t2 := committer.Homomorphism(r)
 	c := committer.H.Mul(t1, t2)
-	return c, r
+	return c, r, nil
 }
 
 func (committer *RSABasedCommitter) GetDecommitMsg() (*big.Int, *big.Int) {
@@ -117,10 +123,13 @@ func (committer *RSABasedCommitter) GetDecommitMsg() (*big.Int, *big.Int) {
 // random integer o where C = y^(a*b) * QOneWayHomomorphism(o), and integer t such that
 // C = B^a * QOneWayHomomorphism(t).
 func (committer *RSABasedCommitter) GetCommitmentToMultiplication(a, b, u *big.Int) (*big.Int,
-	*big.Int, *big.Int) {
+	*big.Int, *big.Int, error) {
 	c := new(big.Int).Mul(a, b)
 	cMod := new(big.Int).Mod(c, committer.Q) // c = a * b mod Q
-	C, o := committer.computeCommitment(cMod)
+	C, o, err := committer.computeCommitment(cMod)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
 	j := new(big.Int).Sub(c, cMod)
 	j.Div(j, committer.Q)
@@ -135,7 +144,7 @@ func (committer *RSABasedCommitter) GetCommitmentToMultiplication(a, b, u *big.I
 	yTojInv := committer.H.Inv(yToj)
 	t1 := committer.HomomorphismInv(yTojInv)
 	t = committer.H.Mul(t, t1)
-	return C, o, t
+	return C, o, t, nil
 }
 
 type RSABasedCommitReceiver struct {
@@ -157,7 +166,10 @@ func NewRSABasedCommitReceiver(nBitLength int) (*RSABasedCommitReceiver, error)
 
 	// gcd(q, phi(N)) is prime because q is prime and q > N > phi(N)
 	// let's choose some x from Z_n*
-	x := H.GetRandomElement()
+	x, err := H.GetRandomElement()
+	if err != nil {
+		return nil, err
+	}
 	y := homomorphism(x)
 
 	return &RSABasedCommitReceiver{
@@ -31,7 +31,10 @@ func GetGeneratorOfZnSubgroup(n, groupOrder, subgroupOrder *big.Int) (*big.Int,
 	}
 	r := new(big.Int).Div(groupOrder, subgroupOrder)
 	for {
-		h := GetRandomInt(n)
+		h, err := GetRandomInt(n)
+		if err != nil {
+			return nil, err
+		}
 		g := new(big.Int)
 		g.Exp(h, r, n)
 		if g.Cmp(big.NewInt(1)) != 0 {
@@ -66,7 +69,10 @@ func GetGeneratorOfCompositeQR(p, q *big.Int) (g *big.Int, err error) {
 	// We need to make sure that all elements of orders smaller than 2 * p1 * q1 are ruled out.
 
 	for {
-		a := GetRandomInt(n)
+		a, err := GetRandomInt(n)
+		if err != nil {
+			return nil, err
+		}
 		a_plus := new(big.Int).Add(a, one)
 		a_min := new(big.Int).Sub(a, one)
 		tmp.GCD(nil, nil, a, p)
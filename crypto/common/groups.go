@@ -26,7 +26,7 @@ import (
 // I would say for EC we should introduce ECGroup interface. All these should be moved
 // into dlog package which should be renamed as groups.
 type Group interface {
-	GetRandomElement() *big.Int
+	GetRandomElement() (*big.Int, error)
 	Mul(*big.Int, *big.Int) *big.Int
 	Exp(*big.Int, *big.Int) *big.Int
 	Inv(*big.Int) *big.Int
@@ -43,7 +43,7 @@ func NewZnGroup(n *big.Int) *ZnGroup {
 	}
 }
 
-func (znGroup *ZnGroup) GetRandomElement() *big.Int {
+func (znGroup *ZnGroup) GetRandomElement() (*big.Int, error) {
 	return GetRandomZnInvertibleElement(znGroup.N)
 }
 
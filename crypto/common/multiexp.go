@@ -0,0 +1,280 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package common
+
+import (
+	"crypto/elliptic"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// strausWindow is the window size (in bits) of Straus' simultaneous exponentiation.
+const strausWindow = 4
+
+// MultiExp returns bases[0]^exponents[0] * ... * bases[k-1]^exponents[k-1] mod modulus.
+// Computing the product at once is much faster than exponentiating each base separately,
+// as the squarings are shared among all bases:
+//   - for a few bases Straus' simultaneous exponentiation is used (each base needs one
+//     multiplication per window of the exponents),
+//   - for many bases Pippenger's bucket method is used (bases with the same digit of
+//     the exponent are multiplied together first, so the cost per base does not depend on
+//     the window size).
+//
+// For two or three bases it is faster to exponentiate each base separately with big.Int.Exp,
+// which uses Montgomery multiplication (more than twice faster than the multiplication
+// followed by the reduction used here). The method with the smallest estimated cost is
+// chosen. A negative
+// exponent means the exponentiation of the inverse of the base - nil is returned when such
+// base is not invertible.
+func MultiExp(bases, exponents []*big.Int, modulus *big.Int) *big.Int {
+	if len(bases) == 1 {
+		return exp(bases[0], exponents[0], modulus)
+	}
+
+	bs := make([]*big.Int, 0, len(bases))
+	es := make([]*big.Int, 0, len(bases))
+	bitLen := 0
+	for i, e := range exponents {
+		b := bases[i]
+		if e.Sign() == 0 {
+			continue
+		}
+		if e.Sign() < 0 {
+			b = new(big.Int).ModInverse(b, modulus)
+			if b == nil {
+				return nil
+			}
+			e = new(big.Int).Neg(e)
+		}
+		bs = append(bs, b)
+		es = append(es, e)
+		if e.BitLen() > bitLen {
+			bitLen = e.BitLen()
+		}
+	}
+	if len(bs) == 0 {
+		return new(big.Int).Mod(big.NewInt(1), modulus)
+	}
+
+	// the costs are in the number of multiplications (with the reduction)
+	separate := len(bs) * (bitLen + bitLen/strausWindow + (1 << strausWindow)) * 10 / 22
+	straus := bitLen + len(bs)*((1<<strausWindow)+bitLen/strausWindow)
+	window, pippenger := pippengerWindow(len(bs), bitLen)
+	switch {
+	case separate <= straus && separate <= pippenger:
+		result := big.NewInt(1)
+		for i, b := range bs {
+			result = mulMod(result, new(big.Int).Exp(b, es[i], modulus), modulus)
+		}
+		return result
+	case pippenger < straus:
+		return pippengerMultiExp(bs, es, modulus, bitLen, window)
+	}
+	return strausMultiExp(bs, es, modulus, bitLen)
+}
+
+// exp returns base^exponent mod modulus, or nil if the exponent is negative and the base
+// is not invertible.
+func exp(base, exponent, modulus *big.Int) *big.Int {
+	if exponent.Sign() < 0 {
+		base = new(big.Int).ModInverse(base, modulus)
+		if base == nil {
+			return nil
+		}
+		exponent = new(big.Int).Neg(exponent)
+	}
+	return new(big.Int).Exp(base, exponent, modulus)
+}
+
+func mulMod(x, y, modulus *big.Int) *big.Int {
+	r := new(big.Int).Mul(x, y)
+	return r.Mod(r, modulus)
+}
+
+// strausMultiExp computes the product with simultaneous exponentiation: for each base
+// the powers base^j (j < 2^strausWindow) are precomputed, then for each window the result
+// is squared strausWindow times and multiplied by the power of each base given by the digit
+// of its exponent.
+func strausMultiExp(bases, exponents []*big.Int, modulus *big.Int, bitLen int) *big.Int {
+	tables := make([][]*big.Int, len(bases))
+	for i, base := range bases {
+		tables[i] = make([]*big.Int, 1<<strausWindow)
+		tables[i][1] = new(big.Int).Mod(base, modulus)
+		for j := 2; j < len(tables[i]); j++ {
+			tables[i][j] = mulMod(tables[i][j-1], base, modulus)
+		}
+	}
+
+	var result *big.Int // nil stands for 1, which saves the first squarings
+	for w := (bitLen+strausWindow-1)/strausWindow - 1; w >= 0; w-- {
+		if result != nil {
+			for j := 0; j < strausWindow; j++ {
+				result = mulMod(result, result, modulus)
+			}
+		}
+		for i, e := range exponents {
+			if digit := windowDigit(e, w, strausWindow); digit != 0 {
+				if result == nil {
+					result = new(big.Int).Set(tables[i][digit])
+				} else {
+					result = mulMod(result, tables[i][digit], modulus)
+				}
+			}
+		}
+	}
+	if result == nil {
+		return new(big.Int).Mod(big.NewInt(1), modulus)
+	}
+	return result
+}
+
+// pippengerWindow returns the window size which minimizes the estimated number of
+// multiplications of Pippenger's method for k bases and exponents of bitLen bits,
+// together with the estimate.
+func pippengerWindow(k, bitLen int) (int, int) {
+	best, bestCost := 1, -1
+	for c := 1; c <= 16; c++ {
+		windows := (bitLen + c - 1) / c
+		// per window: k multiplications into buckets and 2 * 2^c to combine them
+		cost := bitLen + windows*(k+(2<<uint(c)))
+		if bestCost < 0 || cost < bestCost {
+			best, bestCost = c, cost
+		}
+	}
+	return best, bestCost
+}
+
+// pippengerMultiExp computes the product with Pippenger's bucket method: for each window
+// the bases are multiplied into the bucket given by the digit of their exponent and
+// the buckets are then combined as prod_d bucket_d^d using running products.
+func pippengerMultiExp(bases, exponents []*big.Int, modulus *big.Int, bitLen,
+	window int) *big.Int {
+	buckets := make([]*big.Int, 1<<uint(window))
+	var result *big.Int // nil stands for 1
+	for w := (bitLen+window-1)/window - 1; w >= 0; w-- {
+		if result != nil {
+			for j := 0; j < window; j++ {
+				result = mulMod(result, result, modulus)
+			}
+		}
+
+		for d := range buckets {
+			buckets[d] = nil
+		}
+		for i, e := range exponents {
+			if d := windowDigit(e, w, window); d != 0 {
+				if buckets[d] == nil {
+					buckets[d] = new(big.Int).Set(bases[i])
+				} else {
+					buckets[d] = mulMod(buckets[d], bases[i], modulus)
+				}
+			}
+		}
+
+		// prod_d bucket_d^d = prod_d (prod_{j >= d} bucket_j)
+		var running, acc *big.Int
+		for d := len(buckets) - 1; d > 0; d-- {
+			if buckets[d] != nil {
+				if running == nil {
+					running = buckets[d]
+				} else {
+					running = mulMod(running, buckets[d], modulus)
+				}
+			}
+			if running != nil {
+				if acc == nil {
+					acc = running
+				} else {
+					acc = mulMod(acc, running, modulus)
+				}
+			}
+		}
+		if acc != nil {
+			if result == nil {
+				result = acc
+			} else {
+				result = mulMod(result, acc, modulus)
+			}
+		}
+	}
+	if result == nil {
+		return new(big.Int).Mod(big.NewInt(1), modulus)
+	}
+	return new(big.Int).Mod(result, modulus)
+}
+
+// windowDigit returns the w-th digit (of size bits) of non-negative e.
+func windowDigit(e *big.Int, w, size int) int {
+	digit := 0
+	for j := 0; j < size; j++ {
+		digit |= int(e.Bit(w*size+j)) << uint(j)
+	}
+	return digit
+}
+
+// MultiExpEC returns bases[0]^exponents[0] * ... * bases[k-1]^exponents[k-1] (that is
+// the sum of exponents[i] * bases[i] in the additive notation) on the curve. The exponents
+// are reduced modulo the order of the base point, so they can be negative.
+//
+// Unlike in MultiExp, the methods which save exponentiations by adding more points (Straus,
+// Pippenger) do not pay off here: the points are added through crypto/elliptic, where
+// each addition converts the coordinates and costs about a tenth of a scalar
+// multiplication. Instead, the exponents of the same points are merged, so that each
+// distinct point is exponentiated once, and the base point of the curve is exponentiated
+// with the precomputed tables of crypto/elliptic.
+func MultiExpEC(curve elliptic.Curve, bases []*types.ECGroupElement,
+	exponents []*big.Int) *types.ECGroupElement {
+	params := curve.Params()
+	index := make(map[string]int)
+	var points []*types.ECGroupElement
+	var exps []*big.Int
+	for i, p := range bases {
+		if p.IsInfinity() {
+			continue
+		}
+		key := p.X.String() + "," + p.Y.String()
+		j, ok := index[key]
+		if !ok {
+			j = len(points)
+			index[key] = j
+			points = append(points, p)
+			exps = append(exps, new(big.Int))
+		}
+		exps[j].Add(exps[j], exponents[i])
+	}
+
+	result := types.NewECGroupElementInfinity()
+	for i, p := range points {
+		e := exps[i].Mod(exps[i], params.N)
+		if e.Sign() == 0 {
+			continue
+		}
+		var x, y *big.Int
+		if p.X.Cmp(params.Gx) == 0 && p.Y.Cmp(params.Gy) == 0 {
+			x, y = curve.ScalarBaseMult(e.Bytes())
+		} else {
+			x, y = curve.ScalarMult(p.X, p.Y, e.Bytes())
+		}
+		if result.IsInfinity() {
+			result = types.NewECGroupElement(x, y)
+		} else if x.Sign() != 0 || y.Sign() != 0 {
+			result = types.NewECGroupElement(curve.Add(result.X, result.Y, x, y))
+		}
+	}
+	return result
+}
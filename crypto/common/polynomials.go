@@ -32,7 +32,10 @@ type Polynomial struct {
 func NewRandomPolynomial(degree int, prime *big.Int) (*Polynomial, error) {
 	var coefficients []*big.Int
 	for i := 0; i <= degree; i++ {
-		coef := GetRandomInt(prime) // coeff has to be < prime
+		coef, err := GetRandomInt(prime) // coeff has to be < prime
+		if err != nil {
+			return nil, err
+		}
 		coefficients = append(coefficients, coef)
 	}
 	polynomial := Polynomial{
@@ -26,7 +26,10 @@ import (
 
 // GetSafePrime returns a safe prime p (p = 2*p1 + 2 where p1 is prime too).
 func GetSafePrime(bits int) (p *big.Int, err error) {
-	p1 := GetGermainPrime(bits - 1)
+	p1, err := GetGermainPrime(bits - 1)
+	if err != nil {
+		return nil, err
+	}
 	p = big.NewInt(0)
 	p.Mul(p1, big.NewInt(2))
 	p.Add(p, big.NewInt(1))
@@ -39,20 +42,34 @@ func GetSafePrime(bits int) (p *big.Int, err error) {
 	}
 }
 
+// germainPrimeWorkers is the number of goroutines which search for a germain prime.
+const germainPrimeWorkers = 8
+
 // GetGermainPrime returns a prime number p for which 2*p + 1 is also prime. Note that conversely p
-// is called safe prime.
-func GetGermainPrime(bits int) (p *big.Int) {
-	// multiple germainPrime goroutines are called and we assume at least one will compute a
-	// safe prime and send it to the channel, thus we do not handle errors in germainPrime
-	var c chan *big.Int = make(chan *big.Int)
-	var quit chan int = make(chan int)
-	for j := int(0); j < 8; j++ {
-		go germainPrime(bits, c, quit)
+// is called safe prime. It returns an error only if all the goroutines which search for
+// the prime fail (for example because randomness cannot be obtained).
+func GetGermainPrime(bits int) (*big.Int, error) {
+	c := make(chan *big.Int)
+	errs := make(chan error, germainPrimeWorkers)
+	quit := make(chan int)
+	defer close(quit)
+	for j := 0; j < germainPrimeWorkers; j++ {
+		go func() {
+			if _, err := germainPrime(bits, c, quit); err != nil {
+				errs <- err
+			}
+		}()
 	}
-	msg := <-c
-	close(c)
-	close(quit)
-	return msg
+
+	var err error
+	for j := 0; j < germainPrimeWorkers; j++ {
+		select {
+		case p := <-c:
+			return p, nil
+		case err = <-errs:
+		}
+	}
+	return nil, err
 }
 
 var smallPrimes = []uint8{
@@ -160,19 +177,12 @@ func germainPrime(bits int, c chan *big.Int, quit chan int) (p *big.Int, err err
 		// here.
 		if p.ProbablyPrime(20) && p.BitLen() == bits {
 			if p1.ProbablyPrime(20) {
-				// waiting for a message about channel being closed is repeated here,
-				// because it might happen that channel is closed after waiting at the
-				// beginning of for loop above (but we want to have it there also,
-				// otherwise it this goroutine might be searching for a germain
-				// prime for some time after one was found by another goroutine
+				// the prime is sent only if no other goroutine has found one (otherwise
+				// GetGermainPrime is not receiving anymore and quit is closed)
 				select {
 				case <-quit:
-					return
-				default:
-					// this is to make it non-blocking
+				case c <- p:
 				}
-
-				c <- p
 				return
 			}
 		}
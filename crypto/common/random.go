@@ -20,69 +20,78 @@ package common
 import (
 	"crypto/rand"
 	"errors"
-	"log"
+	"fmt"
 	"math/big"
 )
 
-// Returns random integer from [0, max).
-func GetRandomInt(max *big.Int) *big.Int {
+// The functions in this file are safe for concurrent use (crypto/rand.Reader is). They
+// return the errors of the source of randomness (or of invalid arguments), which need to be
+// passed on to the caller.
+
+// GetRandomInt returns random integer from [0, max).
+func GetRandomInt(max *big.Int) (*big.Int, error) {
+	if max == nil || max.Sign() <= 0 {
+		return nil, fmt.Errorf("GetRandomInt: max needs to be positive")
+	}
 	n, err := rand.Int(rand.Reader, max)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("GetRandomInt: %v", err)
 	}
-	return n
+	return n, nil
 }
 
-// Returns random integer from [min, max).
+// GetRandomIntFromRange returns random integer from [min, max).
 func GetRandomIntFromRange(min, max *big.Int) (*big.Int, error) {
 	if min.Cmp(max) >= 0 {
 		err := errors.New("GetRandomIntFromRange: max has to be bigger than min")
 		return nil, err
 	}
-	if min.Cmp(big.NewInt(0)) < 0 && max.Cmp(big.NewInt(0)) < 0 {
-		d := new(big.Int).Sub(min, max)
-		dAbs := new(big.Int).Abs(d)
-		i := GetRandomInt(dAbs)
-		ic := new(big.Int).Add(min, i)
-		return ic, nil
-	} else if min.Cmp(big.NewInt(0)) < 0 && max.Cmp(big.NewInt(0)) >= 0 {
-		nMin := new(big.Int).Abs(min)
-		d := new(big.Int).Add(nMin, max)
-		i := GetRandomInt(d)
-		ic := new(big.Int).Add(min, i)
-		return ic, nil
-	} else {
-		d := new(big.Int).Sub(max, min)
-		i := GetRandomInt(d)
-		ic := new(big.Int).Add(min, i)
-		return ic, nil
+	i, err := GetRandomInt(new(big.Int).Sub(max, min))
+	if err != nil {
+		return nil, err
 	}
+	return i.Add(i, min), nil
 }
 
-// GetRandomIntOfLength returns random *big.Int exactly of length bitLengh.
-func GetRandomIntOfLength(bitLength int) *big.Int {
-	// choose a random number a of length bitLength
-	// that means: 2^(bitLength-1) < a < 2^(bitLength)
-	// choose a random from [0, 2^(bitLength-1)) and add it to 2^(bitLength-1)
-	max := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(bitLength-1)), nil)
-	o := GetRandomInt(max)
-	r := new(big.Int).Add(max, o)
-
-	b1 := r.Cmp(new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(bitLength-1)), nil))
-	b2 := r.Cmp(new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(bitLength)), nil))
-	if (b1 != 1) || (b2 != -1) {
-		log.Panic("parameter not properly chosen")
+// GetRandomInts returns n random integers from [0, max).
+func GetRandomInts(n int, max *big.Int) ([]*big.Int, error) {
+	values := make([]*big.Int, n)
+	for i := range values {
+		r, err := GetRandomInt(max)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = r
 	}
+	return values, nil
+}
 
-	return r
+// GetRandomIntOfLength returns random integer exactly of length bitLength.
+func GetRandomIntOfLength(bitLength int) (*big.Int, error) {
+	if bitLength < 1 {
+		return nil, fmt.Errorf("GetRandomIntOfLength: bit length needs to be positive")
+	}
+	// choose a random from [0, 2^(bitLength-1)) and add it to 2^(bitLength-1)
+	max := new(big.Int).Lsh(big.NewInt(1), uint(bitLength-1))
+	r, err := GetRandomInt(max)
+	if err != nil {
+		return nil, err
+	}
+	return r.Add(r, max), nil
 }
 
-// GetZnInvertibleElement returns random element from Z_n*.
-func GetRandomZnInvertibleElement(n *big.Int) *big.Int {
+// GetRandomZnInvertibleElement returns random element from Z_n*.
+func GetRandomZnInvertibleElement(n *big.Int) (*big.Int, error) {
+	if n == nil || n.Cmp(big.NewInt(1)) <= 0 {
+		return nil, fmt.Errorf("GetRandomZnInvertibleElement: n needs to be bigger than 1")
+	}
 	for {
-		r := GetRandomInt(n)
+		r, err := GetRandomInt(n)
+		if err != nil {
+			return nil, err
+		}
 		if new(big.Int).GCD(nil, nil, r, n).Cmp(big.NewInt(1)) == 0 {
-			return r
+			return r, nil
 		}
 	}
 }
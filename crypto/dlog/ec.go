@@ -19,6 +19,8 @@ package dlog
 
 import (
 	"crypto/elliptic"
+	"fmt"
+	"github.com/xlab-si/emmy/types"
 	"math/big"
 )
 
@@ -31,9 +33,37 @@ const (
 	P521
 )
 
+var curveNames = map[Curve]string{
+	P224: "P224",
+	P256: "P256",
+	P384: "P384",
+	P521: "P521",
+}
+
+func (c Curve) String() string {
+	if name, ok := curveNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Curve(%d)", int(c))
+}
+
+// ParseCurve returns the curve with the given name (for example "P256").
+func ParseCurve(name string) (Curve, error) {
+	for c, n := range curveNames {
+		if n == name {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown elliptic curve %s", name)
+}
+
+// ECDLog is the group of points of order OrderOfSubgroup (generated by the base point G of the
+// curve). The number of points on the curve is Cofactor * OrderOfSubgroup - for curves with
+// Cofactor > 1 a point on the curve is not necessarily in the subgroup (see IsInSubgroup).
 type ECDLog struct {
 	Curve           elliptic.Curve
 	OrderOfSubgroup *big.Int
+	Cofactor        *big.Int
 }
 
 func GetEllipticCurve(curveType Curve) elliptic.Curve {
@@ -55,42 +85,33 @@ func NewECDLog(curveType Curve) *ECDLog {
 	c := GetEllipticCurve(curveType)
 	ecdlog := ECDLog{
 		Curve:           c,
-		OrderOfSubgroup: c.Params().N, // order of G
+		OrderOfSubgroup: c.Params().N,  // order of G
+		Cofactor:        big.NewInt(1), // all supported (NIST) curves have prime order
 	}
 	return &ecdlog
 }
 
+// Multiply, Exponentiate, ExponentiateBaseG and Inverse work with coordinates, see Mul, Exp,
+// ExpBaseG and Inv for the details.
 func (dlog *ECDLog) Multiply(params ...*big.Int) (*big.Int, *big.Int) {
-	x1 := params[0]
-	y1 := params[1]
-	x2 := params[2]
-	y2 := params[3]
-
-	// calculates (x1, y1) + (x2, y2) as we use elliptic curves
-	x, y := dlog.Curve.Add(x1, y1, x2, y2)
-	return x, y
+	p := dlog.Mul(types.NewECGroupElement(params[0], params[1]),
+		types.NewECGroupElement(params[2], params[3]))
+	return p.X, p.Y
 }
 
 func (dlog *ECDLog) Exponentiate(params ...*big.Int) (*big.Int, *big.Int) {
-	x := params[0]
-	y := params[1]
-	exponent := params[2]
-
-	// calculates (x, y) * exponent
-	hx, hy := dlog.Curve.ScalarMult(x, y, exponent.Bytes())
-	return hx, hy
+	p := dlog.Exp(types.NewECGroupElement(params[0], params[1]), params[2])
+	return p.X, p.Y
 }
 
 func (dlog *ECDLog) ExponentiateBaseG(exponent *big.Int) (*big.Int, *big.Int) {
-	// calculates g ^^ exponent or better to say g * exponent as this is elliptic ((gx, gy) * exponent)
-	hx, hy := dlog.Curve.ScalarBaseMult(exponent.Bytes())
-	return hx, hy
+	p := dlog.ExpBaseG(exponent)
+	return p.X, p.Y
 }
 
 func (dlog *ECDLog) Inverse(x, y *big.Int) (*big.Int, *big.Int) {
-	orderMin := new(big.Int).Sub(dlog.OrderOfSubgroup, big.NewInt(1))
-	invX, invY := dlog.Exponentiate(x, y, orderMin)
-	return invX, invY
+	p := dlog.Inv(types.NewECGroupElement(x, y))
+	return p.X, p.Y
 }
 
 func (dlog *ECDLog) GetOrderOfSubgroup() *big.Int {
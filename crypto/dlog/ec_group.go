@@ -0,0 +1,121 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlog
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// ecBackend is ECDLog as groups.PrimeOrderGroup.
+type ecBackend struct {
+	dLog *ECDLog
+}
+
+// NewECBackend returns the subgroup of the curve as groups.PrimeOrderGroup. Elements are
+// encoded as the coordinates of the points.
+func NewECBackend(curve Curve) groups.PrimeOrderGroup {
+	return &ecBackend{
+		dLog: NewECDLog(curve),
+	}
+}
+
+func (b *ecBackend) Name() string {
+	return b.dLog.Curve.Params().Name
+}
+
+func (b *ecBackend) Params() []*big.Int {
+	params := b.dLog.Curve.Params()
+	return []*big.Int{params.P, params.B, params.Gx, params.Gy}
+}
+
+func (b *ecBackend) Order() *big.Int {
+	return b.dLog.OrderOfSubgroup
+}
+
+func (b *ecBackend) Generator() groups.Element {
+	params := b.dLog.Curve.Params()
+	return b.element(types.NewECGroupElement(params.Gx, params.Gy))
+}
+
+func (b *ecBackend) Identity() groups.Element {
+	return b.element(types.NewECGroupElementInfinity())
+}
+
+func (b *ecBackend) Scalar(x *big.Int) groups.Scalar {
+	return groups.NewBigScalar(b.dLog.OrderOfSubgroup, x)
+}
+
+func (b *ecBackend) RandomScalar() (groups.Scalar, error) {
+	return groups.RandomBigScalar(b.dLog.OrderOfSubgroup)
+}
+
+func (b *ecBackend) Element(ints []*big.Int) (groups.Element, error) {
+	if len(ints) != 2 {
+		return nil, fmt.Errorf("not an element of the EC group")
+	}
+	p := types.NewECGroupElement(ints[0], ints[1])
+	if !b.dLog.IsInSubgroup(p) {
+		return nil, fmt.Errorf("not an element of the EC group")
+	}
+	return b.element(p), nil
+}
+
+func (b *ecBackend) element(p *types.ECGroupElement) *ecElement {
+	return &ecElement{
+		dLog: b.dLog,
+		p:    copyECGroupElement(p),
+	}
+}
+
+type ecElement struct {
+	dLog *ECDLog
+	p    *types.ECGroupElement
+}
+
+func (e *ecElement) Mul(other groups.Element) groups.Element {
+	return &ecElement{
+		dLog: e.dLog,
+		p:    e.dLog.Mul(e.p, other.(*ecElement).p),
+	}
+}
+
+func (e *ecElement) Exp(exponent groups.Scalar) groups.Element {
+	return &ecElement{
+		dLog: e.dLog,
+		p:    e.dLog.Exp(e.p, exponent.BigInt()),
+	}
+}
+
+func (e *ecElement) Inv() groups.Element {
+	return &ecElement{
+		dLog: e.dLog,
+		p:    e.dLog.Inv(e.p),
+	}
+}
+
+func (e *ecElement) Equals(other groups.Element) bool {
+	o, ok := other.(*ecElement)
+	return ok && e.p.Equals(o.p)
+}
+
+func (e *ecElement) Ints() []*big.Int {
+	return []*big.Int{new(big.Int).Set(e.p.X), new(big.Int).Set(e.p.Y)}
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlog
+
+import (
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// The group operation on the curve is written multiplicatively (as in the rest of emmy),
+// so Mul adds two points and Exp multiplies a point by a scalar.
+//
+// crypto/elliptic represents the point at infinity by (0, 0), but it does not consider it to
+// be on the curve, it panics when it is given a point which is not on the curve, and it
+// ignores the sign of scalars. The methods below handle the point at infinity and negative
+// (or too big) exponents explicitly. Points which are not valid (see IsValid) still make them
+// panic, thus points received from other parties need to be checked with IsValid first.
+// Exponents are reduced modulo the order of the subgroup, which is correct only for points
+// from the subgroup - points received from other parties thus need to be checked with
+// IsInSubgroup instead when the curve has a cofactor.
+
+// IsValid returns true if p is the point at infinity or a point on the curve.
+func (dlog *ECDLog) IsValid(p *types.ECGroupElement) bool {
+	if p == nil || p.X == nil || p.Y == nil {
+		return false
+	}
+	return p.IsInfinity() || dlog.Curve.IsOnCurve(p.X, p.Y)
+}
+
+// Mul returns a * b (that is a + b in the additive notation).
+func (dlog *ECDLog) Mul(a, b *types.ECGroupElement) *types.ECGroupElement {
+	if a.IsInfinity() {
+		return copyECGroupElement(b)
+	}
+	if b.IsInfinity() {
+		return copyECGroupElement(a)
+	}
+	// Add returns (0, 0) when b is the inverse of a, and doubles a when a = b
+	return types.NewECGroupElement(dlog.Curve.Add(a.X, a.Y, b.X, b.Y))
+}
+
+// Exp returns p^exponent (that is exponent * p in the additive notation). The exponent
+// is reduced modulo the order of the subgroup, so it can be negative.
+func (dlog *ECDLog) Exp(p *types.ECGroupElement, exponent *big.Int) *types.ECGroupElement {
+	e := dlog.reduce(exponent)
+	if p.IsInfinity() || e.Sign() == 0 {
+		return types.NewECGroupElementInfinity()
+	}
+	return types.NewECGroupElement(dlog.Curve.ScalarMult(p.X, p.Y, e.Bytes()))
+}
+
+// ExpBaseG returns g^exponent where g is the base point of the curve.
+func (dlog *ECDLog) ExpBaseG(exponent *big.Int) *types.ECGroupElement {
+	e := dlog.reduce(exponent)
+	if e.Sign() == 0 {
+		return types.NewECGroupElementInfinity()
+	}
+	return types.NewECGroupElement(dlog.Curve.ScalarBaseMult(e.Bytes()))
+}
+
+// Inv returns p^-1 (that is -p in the additive notation).
+func (dlog *ECDLog) Inv(p *types.ECGroupElement) *types.ECGroupElement {
+	if p.IsInfinity() {
+		return types.NewECGroupElementInfinity()
+	}
+	y := new(big.Int).Sub(dlog.Curve.Params().P, p.Y)
+	return types.NewECGroupElement(new(big.Int).Set(p.X), y.Mod(y, dlog.Curve.Params().P))
+}
+
+// IsInSubgroup returns true if p is a valid point (see IsValid) from the subgroup of order
+// OrderOfSubgroup. For curves with cofactor 1 all valid points are in the subgroup.
+func (dlog *ECDLog) IsInSubgroup(p *types.ECGroupElement) bool {
+	if !dlog.IsValid(p) {
+		return false
+	}
+	if p.IsInfinity() || !dlog.hasCofactor() {
+		return true
+	}
+	x, y := dlog.Curve.ScalarMult(p.X, p.Y, dlog.OrderOfSubgroup.Bytes())
+	return types.NewECGroupElement(x, y).IsInfinity()
+}
+
+// ClearCofactor maps a valid point into the subgroup by computing p^Cofactor. Note that the
+// result can be the point at infinity (for the points of small order).
+func (dlog *ECDLog) ClearCofactor(p *types.ECGroupElement) *types.ECGroupElement {
+	if p.IsInfinity() || !dlog.hasCofactor() {
+		return copyECGroupElement(p)
+	}
+	return types.NewECGroupElement(dlog.Curve.ScalarMult(p.X, p.Y, dlog.Cofactor.Bytes()))
+}
+
+func (dlog *ECDLog) hasCofactor() bool {
+	return dlog.Cofactor != nil && dlog.Cofactor.Cmp(big.NewInt(1)) > 0
+}
+
+func (dlog *ECDLog) reduce(exponent *big.Int) *big.Int {
+	return new(big.Int).Mod(exponent, dlog.OrderOfSubgroup)
+}
+
+func copyECGroupElement(p *types.ECGroupElement) *types.ECGroupElement {
+	return types.NewECGroupElement(new(big.Int).Set(p.X), new(big.Int).Set(p.Y))
+}
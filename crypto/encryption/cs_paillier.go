@@ -19,6 +19,7 @@ package encryption
 
 import (
 	"errors"
+	"fmt"
 	"github.com/golang/protobuf/proto"
 	"github.com/xlab-si/emmy/crypto/common"
 	"github.com/xlab-si/emmy/crypto/groups"
@@ -115,15 +116,17 @@ type CSPaillierVerifierEncData struct {
 	Delta *big.Int
 }
 
-func NewCSPaillier(secParams *CSPaillierSecParams) *CSPaillier {
+func NewCSPaillier(secParams *CSPaillierSecParams) (*CSPaillier, error) {
 	var cspaillier CSPaillier
 
 	cspaillier = CSPaillier{
 		SecParams: secParams,
 	}
-	cspaillier.generateKey()
+	if err := cspaillier.generateKey(); err != nil {
+		return nil, err
+	}
 
-	return &cspaillier
+	return &cspaillier, nil
 }
 
 func NewCSPaillierFromSecKey(path string) (*CSPaillier, error) {
@@ -268,14 +271,23 @@ func (cspaillier *CSPaillier) StorePubKey(path string) error {
 
 // Returns (u, e, v).
 func (cspaillier *CSPaillier) Encrypt(m, label *big.Int) (*big.Int, *big.Int, *big.Int, error) {
+	b := new(big.Int).Div(cspaillier.PubKey.N, big.NewInt(4))
+	r, err := common.GetRandomInt(b)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return cspaillier.EncryptWithR(m, label, r)
+}
+
+// EncryptWithR returns (u, e, v) computed with the given randomness r from [0, n/4). It is
+// needed when the encryptor proves something about the ciphertext (see package encproofs).
+func (cspaillier *CSPaillier) EncryptWithR(m, label, r *big.Int) (*big.Int, *big.Int,
+	*big.Int, error) {
 	if m.Cmp(cspaillier.PubKey.N) >= 0 {
 		err := errors.New("msg is too big")
 		return nil, nil, nil, err
 	}
 
-	b := new(big.Int).Div(cspaillier.PubKey.N, big.NewInt(4))
-	r := common.GetRandomInt(b)
-
 	n2 := new(big.Int).Mul(cspaillier.PubKey.N, cspaillier.PubKey.N)
 	// u = g^r
 	u := new(big.Int).Exp(cspaillier.PubKey.G, r, n2)
@@ -371,9 +383,15 @@ func (cspaillier *CSPaillier) Abs(a *big.Int) (*big.Int, error) {
 	}
 }
 
-func (cspaillier *CSPaillier) generateKey() {
-	p1 := common.GetGermainPrime(cspaillier.SecParams.L)
-	q1 := common.GetGermainPrime(cspaillier.SecParams.L)
+func (cspaillier *CSPaillier) generateKey() error {
+	p1, err := common.GetGermainPrime(cspaillier.SecParams.L)
+	if err != nil {
+		return err
+	}
+	q1, err := common.GetGermainPrime(cspaillier.SecParams.L)
+	if err != nil {
+		return err
+	}
 
 	p := new(big.Int).Add(p1, p1)
 	p.Add(p, big.NewInt(1))
@@ -393,7 +411,7 @@ func (cspaillier *CSPaillier) generateKey() {
 	// for verifiable encryption:
 	Gamma, err := groups.NewSchnorrGroup(cspaillier.SecParams.RoLength)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	pubKey.Gamma = Gamma
 
@@ -403,7 +421,7 @@ func (cspaillier *CSPaillier) generateKey() {
 
 	check1 := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(cspaillier.SecParams.K)), nil)
 	if check1.Cmp(p1) >= 0 || check1.Cmp(q1) >= 0 || check1.Cmp(Gamma.Q) >= 0 {
-		log.Fatal(err)
+		return fmt.Errorf("2^K needs to be smaller than p1, q1 and ro")
 	}
 
 	tmp := cspaillier.SecParams.K + cspaillier.SecParams.K1 + 3
@@ -411,7 +429,7 @@ func (cspaillier *CSPaillier) generateKey() {
 	check2.Mul(check2, Gamma.Q)
 
 	if check2.Cmp(n) >= 0 {
-		log.Fatal(err)
+		return fmt.Errorf("ro * 2^(K + K1 + 3) needs to be smaller than n")
 	}
 
 	pubKey.K = cspaillier.SecParams.K
@@ -423,7 +441,7 @@ func (cspaillier *CSPaillier) generateKey() {
 	orderOfZn := new(big.Int).Mul(big.NewInt(4), cspaillier.n1)
 	verifiableEncGroup, err := NewVerifiableEncGroup(n, orderOfZn, cspaillier.n1)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	pubKey.VerifiableEncGroupN = verifiableEncGroup.N
@@ -442,33 +460,38 @@ func (cspaillier *CSPaillier) generateKey() {
 
 	// choose x1, x2, x3 which are < n^2/4
 	b := new(big.Int).Div(n2, big.NewInt(4))
-	secretKey.X1 = common.GetRandomInt(b)
-	secretKey.X2 = common.GetRandomInt(b)
-	secretKey.X3 = common.GetRandomInt(b)
-
-	for { // choose g1 from Z_n^2*
-		g1 := common.GetRandomInt(n2)
-		gcd := new(big.Int).GCD(nil, nil, g1, n2) // negligible probability that gcd != 1
-		if gcd.Cmp(big.NewInt(1)) == 0 {
-			t := new(big.Int).Mul(big.NewInt(2), n)
-			g := new(big.Int).Exp(g1, t, n2)
-			pubKey.G = g
-			secretKey.G = g
-			break
+	xs := make([]*big.Int, 3)
+	for i := range xs {
+		if xs[i], err = common.GetRandomInt(b); err != nil {
+			return err
 		}
 	}
+	secretKey.X1, secretKey.X2, secretKey.X3 = xs[0], xs[1], xs[2]
+
+	// choose g1 from Z_n^2*
+	g1, err := common.GetRandomZnInvertibleElement(n2)
+	if err != nil {
+		return err
+	}
+	g := new(big.Int).Exp(g1, new(big.Int).Mul(big.NewInt(2), n), n2)
+	pubKey.G = g
+	secretKey.G = g
 
 	pubKey.Y1 = new(big.Int).Exp(pubKey.G, secretKey.X1, n2)
 	pubKey.Y2 = new(big.Int).Exp(pubKey.G, secretKey.X2, n2)
 	pubKey.Y3 = new(big.Int).Exp(pubKey.G, secretKey.X3, n2)
 	cspaillier.PubKey = &pubKey
 	cspaillier.SecretKey = &secretKey
+	return nil
 }
 
 // Returns l = g1^m * h1^s where s is a random integer smaller than n/4.
-func (cspaillier *CSPaillier) GetOpeningMsg(m *big.Int) (*big.Int, *big.Int) {
+func (cspaillier *CSPaillier) GetOpeningMsg(m *big.Int) (*big.Int, *big.Int, error) {
 	b := new(big.Int).Div(cspaillier.PubKey.VerifiableEncGroupN, big.NewInt(4))
-	s := common.GetRandomInt(b)
+	s, err := common.GetRandomInt(b)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	t1 := new(big.Int).Exp(cspaillier.PubKey.VerifiableEncGroupG1, m,
 		cspaillier.PubKey.VerifiableEncGroupN)
@@ -482,7 +505,7 @@ func (cspaillier *CSPaillier) GetOpeningMsg(m *big.Int) (*big.Int, *big.Int) {
 	}
 
 	delta := new(big.Int).Exp(cspaillier.PubKey.Gamma.G, m, cspaillier.PubKey.Gamma.P)
-	return l, delta
+	return l, delta, nil
 }
 
 // Prover (encryptor) should use this function to generate values for the first sigma protocol message.
@@ -661,10 +684,13 @@ func (cspaillier *CSPaillier) Verify(rTilde, sTilde, mTilde *big.Int) bool {
 	return true
 }
 
-func (cspaillier *CSPaillier) GetChallenge() *big.Int {
+func (cspaillier *CSPaillier) GetChallenge() (*big.Int, error) {
 	b := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(cspaillier.SecretKey.K)), nil)
-	c := common.GetRandomInt(b)
-	return c
+	c, err := common.GetRandomInt(b)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
 }
 
 // Verifier should call this function when it receives proof random data as the second protocol message.
@@ -687,9 +713,11 @@ type VerifiableEncGroup struct {
 
 func NewVerifiableEncGroup(n, orderOfZn, n1 *big.Int) (*VerifiableEncGroup, error) {
 	g1, err := common.GetGeneratorOfZnSubgroup(n, orderOfZn, n1)
+	if err != nil {
+		return nil, err
+	}
 	h1, err := common.GetGeneratorOfZnSubgroup(n, orderOfZn, n1)
 	if err != nil {
-		log.Fatal(err)
 		return nil, err
 	}
 
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encryption
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+)
+
+// ElGamal encryption in a Schnorr group: a message m (an element of the group) is encrypted
+// as (g^r, m * y^r) where y = g^x is the public key. The scheme is homomorphic (the product
+// of two ciphertexts encrypts the product of messages), thus ciphertexts can be re-encrypted
+// (multiplied by an encryption of 1) without knowing the secret key - this is what mixnets
+// (see package shuffle) build upon.
+type ElGamal struct {
+	pubKey *ElGamalPubKey
+	secret *big.Int
+}
+
+type ElGamalPubKey struct {
+	Group *groups.SchnorrGroup
+	Y     *big.Int
+}
+
+type ElGamalCiphertext struct {
+	A *big.Int // g^r
+	B *big.Int // m * y^r
+}
+
+// NewElGamal generates a new key pair in the given group.
+func NewElGamal(group *groups.SchnorrGroup) (*ElGamal, error) {
+	x, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, err
+	}
+	return NewElGamalFromSecret(group, x), nil
+}
+
+// NewElGamalFromSecret returns the key pair with the given secret key x.
+func NewElGamalFromSecret(group *groups.SchnorrGroup, x *big.Int) *ElGamal {
+	return &ElGamal{
+		pubKey: &ElGamalPubKey{
+			Group: group,
+			Y:     group.Exp(group.G, x),
+		},
+		secret: x,
+	}
+}
+
+func (elgamal *ElGamal) GetPubKey() *ElGamalPubKey {
+	return elgamal.pubKey
+}
+
+func (elgamal *ElGamal) GetSecretKey() *big.Int {
+	return elgamal.secret
+}
+
+// Decrypt returns m = B / A^x.
+func (elgamal *ElGamal) Decrypt(c *ElGamalCiphertext) (*big.Int, error) {
+	group := elgamal.pubKey.Group
+	if !elgamal.pubKey.IsCiphertext(c) {
+		return nil, fmt.Errorf("ciphertext is not from the group")
+	}
+	return group.Mul(c.B, group.Inv(group.Exp(c.A, elgamal.secret))), nil
+}
+
+// Encrypt encrypts m, which needs to be an element of the group.
+func (pubKey *ElGamalPubKey) Encrypt(m *big.Int) (*ElGamalCiphertext, error) {
+	r, err := common.GetRandomInt(pubKey.Group.Q)
+	if err != nil {
+		return nil, err
+	}
+	return pubKey.EncryptWithR(m, r)
+}
+
+// EncryptWithR encrypts m using the given randomness r from Z_q.
+func (pubKey *ElGamalPubKey) EncryptWithR(m, r *big.Int) (*ElGamalCiphertext, error) {
+	if !pubKey.Group.IsElementInGroup(m) {
+		return nil, fmt.Errorf("message is not an element of the group")
+	}
+	group := pubKey.Group
+	return &ElGamalCiphertext{
+		A: group.Exp(group.G, r),
+		B: group.Mul(m, group.Exp(pubKey.Y, r)),
+	}, nil
+}
+
+// ReEncrypt returns c * (g^r, y^r), which is a ciphertext of the same message.
+func (pubKey *ElGamalPubKey) ReEncrypt(c *ElGamalCiphertext, r *big.Int) *ElGamalCiphertext {
+	return pubKey.Mul(c, pubKey.EncryptOne(r))
+}
+
+// EncryptOne returns the encryption of 1 with randomness r, that is (g^r, y^r).
+func (pubKey *ElGamalPubKey) EncryptOne(r *big.Int) *ElGamalCiphertext {
+	group := pubKey.Group
+	e := new(big.Int).Mod(r, group.Q)
+	return &ElGamalCiphertext{
+		A: group.Exp(group.G, e),
+		B: group.Exp(pubKey.Y, e),
+	}
+}
+
+// Mul returns the componentwise product of the ciphertexts.
+func (pubKey *ElGamalPubKey) Mul(c1, c2 *ElGamalCiphertext) *ElGamalCiphertext {
+	group := pubKey.Group
+	return &ElGamalCiphertext{
+		A: group.Mul(c1.A, c2.A),
+		B: group.Mul(c1.B, c2.B),
+	}
+}
+
+// Exp returns the componentwise exponentiation of the ciphertext.
+func (pubKey *ElGamalPubKey) Exp(c *ElGamalCiphertext, exponent *big.Int) *ElGamalCiphertext {
+	group := pubKey.Group
+	return &ElGamalCiphertext{
+		A: group.Exp(c.A, exponent),
+		B: group.Exp(c.B, exponent),
+	}
+}
+
+// IsCiphertext checks that both components of c are elements of the group.
+func (pubKey *ElGamalPubKey) IsCiphertext(c *ElGamalCiphertext) bool {
+	return c != nil && pubKey.Group.IsElementInGroup(c.A) && pubKey.Group.IsElementInGroup(c.B)
+}
+
+// Equals returns true if both components of the ciphertexts are equal.
+func (c *ElGamalCiphertext) Equals(other *ElGamalCiphertext) bool {
+	return c != nil && other != nil && c.A != nil && c.B != nil && other.A != nil &&
+		other.B != nil && c.A.Cmp(other.A) == 0 && c.B.Cmp(other.B) == 0
+}
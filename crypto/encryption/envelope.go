@@ -0,0 +1,277 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Envelope encryption of the data which services need to store (for example attribute
+// values of registration records). Each value is encrypted (AES-256-GCM) with a fresh data
+// key which is wrapped with a key encryption key (KEK) of a KeyEncrypter - LocalKeyRing or
+// an external KMS or HSM. This allows:
+//
+//   - key rotation: Envelope.Rewrap re-encrypts only the data key with the current KEK,
+//     the ciphertext does not change,
+//   - crypto-erasure: Envelope.Erase removes the wrapped data key of a single value, and
+//     destroying a KEK (LocalKeyRing.DestroyKey) erases all the values wrapped with it -
+//     the remaining ciphertexts (also in backups and append-only logs) cannot be decrypted.
+
+// DataKeyLen is the length (in bytes) of the data keys and of the keys of LocalKeyRing.
+const DataKeyLen = 32
+
+// ErrErased is returned when opening an envelope whose data key was erased or wrapped
+// with a destroyed KEK.
+var ErrErased = errors.New("the data has been erased")
+
+// KeyEncrypter wraps data keys with the key encryption keys identified by their IDs.
+type KeyEncrypter interface {
+	// CurrentKeyID returns the ID of the KEK which is used for new envelopes.
+	CurrentKeyID() string
+	WrapKey(keyID string, dataKey []byte) ([]byte, error)
+	// UnwrapKey returns ErrErased if the KEK has been destroyed.
+	UnwrapKey(keyID string, wrapped []byte) ([]byte, error)
+}
+
+type Envelope struct {
+	KeyID      string
+	WrappedKey []byte // nil when the data has been erased
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+// Seal encrypts plaintext with a fresh data key wrapped with the current KEK. Associated
+// data (for example the identifier of the record) is authenticated but not stored - it
+// needs to be given again when the envelope is opened.
+func Seal(kek KeyEncrypter, plaintext, associatedData []byte) (*Envelope, error) {
+	dataKey := make([]byte, DataKeyLen)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	aead, err := newAESGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	keyID := kek.CurrentKeyID()
+	wrapped, err := kek.WrapKey(keyID, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		KeyID:      keyID,
+		WrappedKey: wrapped,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, associatedData),
+	}, nil
+}
+
+// Open decrypts the envelope. It returns ErrErased if the data has been erased.
+func (env *Envelope) Open(kek KeyEncrypter, associatedData []byte) ([]byte, error) {
+	if env.Erased() {
+		return nil, ErrErased
+	}
+	dataKey, err := kek.UnwrapKey(env.KeyID, env.WrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAESGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, env.Nonce, env.Ciphertext, associatedData)
+	if err != nil {
+		return nil, errors.New("envelope authentication failed")
+	}
+	return plaintext, nil
+}
+
+// Rewrap wraps the data key with the current KEK (if it is not wrapped with it already),
+// so that the previous KEKs can be retired. Erased envelopes are left as they are.
+func (env *Envelope) Rewrap(kek KeyEncrypter) error {
+	keyID := kek.CurrentKeyID()
+	if env.Erased() || env.KeyID == keyID {
+		return nil
+	}
+	dataKey, err := kek.UnwrapKey(env.KeyID, env.WrappedKey)
+	if err != nil {
+		return err
+	}
+	wrapped, err := kek.WrapKey(keyID, dataKey)
+	if err != nil {
+		return err
+	}
+	env.KeyID = keyID
+	env.WrappedKey = wrapped
+	return nil
+}
+
+// Erase removes the wrapped data key, thus the ciphertext can no longer be decrypted. Note
+// that the copies of the envelope (for example in backups) need to be erased as well,
+// unless the KEK which wrapped the data key is destroyed.
+func (env *Envelope) Erase() {
+	env.WrappedKey = nil
+}
+
+func (env *Envelope) Erased() bool {
+	return env.WrappedKey == nil
+}
+
+// LocalKeyRing keeps the KEKs in memory. Keys can be imported with AddKey (for example
+// from a file which is protected by the operating system) and exported with Key. It is safe
+// for concurrent use.
+type LocalKeyRing struct {
+	keys      map[string][]byte
+	destroyed map[string]bool
+	current   string
+	mutex     sync.Mutex
+}
+
+// NewLocalKeyRing returns the key ring with a new random KEK.
+func NewLocalKeyRing() (*LocalKeyRing, error) {
+	ring := &LocalKeyRing{
+		keys:      make(map[string][]byte),
+		destroyed: make(map[string]bool),
+	}
+	if _, err := ring.Rotate(); err != nil {
+		return nil, err
+	}
+	return ring, nil
+}
+
+// AddKey adds the KEK with the given ID and makes it the current one.
+func (ring *LocalKeyRing) AddKey(id string, key []byte) error {
+	if len(key) != DataKeyLen {
+		return fmt.Errorf("key needs to be %d bytes long", DataKeyLen)
+	}
+	ring.mutex.Lock()
+	defer ring.mutex.Unlock()
+	if ring.destroyed[id] {
+		return fmt.Errorf("key %s has been destroyed", id)
+	}
+	ring.keys[id] = append([]byte(nil), key...)
+	ring.current = id
+	return nil
+}
+
+// Rotate generates a new random KEK, makes it the current one and returns its ID.
+// The previous KEKs are kept for unwrapping until they are destroyed.
+func (ring *LocalKeyRing) Rotate() (string, error) {
+	key := make([]byte, DataKeyLen)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	keyID := hex.EncodeToString(id)
+	return keyID, ring.AddKey(keyID, key)
+}
+
+// Key returns the KEK with the given ID (nil if it does not exist).
+func (ring *LocalKeyRing) Key(id string) []byte {
+	ring.mutex.Lock()
+	defer ring.mutex.Unlock()
+	return append([]byte(nil), ring.keys[id]...)
+}
+
+// DestroyKey removes the KEK - all the data keys wrapped with it (which were not rewrapped)
+// are erased. The current KEK cannot be destroyed.
+func (ring *LocalKeyRing) DestroyKey(id string) error {
+	ring.mutex.Lock()
+	defer ring.mutex.Unlock()
+	if id == ring.current {
+		return errors.New("the current key cannot be destroyed")
+	}
+	if key, ok := ring.keys[id]; ok {
+		for i := range key {
+			key[i] = 0
+		}
+		delete(ring.keys, id)
+	}
+	ring.destroyed[id] = true
+	return nil
+}
+
+func (ring *LocalKeyRing) CurrentKeyID() string {
+	ring.mutex.Lock()
+	defer ring.mutex.Unlock()
+	return ring.current
+}
+
+// WrapKey encrypts the data key with AES-256-GCM under the KEK (the nonce is prepended).
+func (ring *LocalKeyRing) WrapKey(keyID string, dataKey []byte) ([]byte, error) {
+	aead, err := ring.aead(keyID)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, dataKey, []byte(keyID)), nil
+}
+
+func (ring *LocalKeyRing) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	aead, err := ring.aead(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, errors.New("wrapped key is too short")
+	}
+	nonceSize := aead.NonceSize()
+	dataKey, err := aead.Open(nil, wrapped[:nonceSize], wrapped[nonceSize:], []byte(keyID))
+	if err != nil {
+		return nil, errors.New("wrapped key authentication failed")
+	}
+	return dataKey, nil
+}
+
+func (ring *LocalKeyRing) aead(keyID string) (cipher.AEAD, error) {
+	ring.mutex.Lock()
+	key, ok := ring.keys[keyID]
+	destroyed := ring.destroyed[keyID]
+	ring.mutex.Unlock()
+	if destroyed {
+		return nil, ErrErased
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key %s", keyID)
+	}
+	return newAESGCM(key)
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
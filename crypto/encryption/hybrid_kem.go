@@ -0,0 +1,203 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/mlkem"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+// Hybrid key exchange which combines X25519 with ML-KEM-768 (Kyber). The derived key
+// is secure as long as either of the two is not broken, so the traffic which is recorded
+// today cannot be decrypted once a quantum computer breaks X25519.
+//
+// The initiator generates HybridKEMPrivateKey and sends its public part to the responder.
+// The responder calls HybridEncapsulate and sends back the ciphertext. Both obtain
+// the same shared key which is then used for ChannelCipher.
+
+const HybridKEMKeyLen = 32
+
+const hybridKEMInfo = "emmy hybrid X25519+ML-KEM-768"
+
+type HybridKEMPublicKey struct {
+	X25519 []byte
+	MLKEM  []byte
+}
+
+type HybridKEMCiphertext struct {
+	X25519 []byte
+	MLKEM  []byte
+}
+
+type HybridKEMPrivateKey struct {
+	x25519 *ecdh.PrivateKey
+	mlkem  *mlkem.DecapsulationKey768
+}
+
+// NewHybridKEMPrivateKey generates a fresh (ephemeral) key pair.
+func NewHybridKEMPrivateKey() (*HybridKEMPrivateKey, error) {
+	x, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	k, err := mlkem.GenerateKey768()
+	if err != nil {
+		return nil, err
+	}
+	return &HybridKEMPrivateKey{
+		x25519: x,
+		mlkem:  k,
+	}, nil
+}
+
+func (key *HybridKEMPrivateKey) PublicKey() *HybridKEMPublicKey {
+	return &HybridKEMPublicKey{
+		X25519: key.x25519.PublicKey().Bytes(),
+		MLKEM:  key.mlkem.EncapsulationKey().Bytes(),
+	}
+}
+
+// Decapsulate returns the shared key for the ciphertext from HybridEncapsulate.
+func (key *HybridKEMPrivateKey) Decapsulate(ct *HybridKEMCiphertext) ([]byte, error) {
+	peer, err := ecdh.X25519().NewPublicKey(ct.X25519)
+	if err != nil {
+		return nil, err
+	}
+	ecSecret, err := key.x25519.ECDH(peer)
+	if err != nil {
+		return nil, err
+	}
+	kemSecret, err := key.mlkem.Decapsulate(ct.MLKEM)
+	if err != nil {
+		return nil, err
+	}
+	return deriveHybridKEMKey(ecSecret, kemSecret, key.PublicKey(), ct)
+}
+
+// HybridEncapsulate returns a shared key and the ciphertext from which the holder of
+// the private key for pubKey obtains the same key.
+func HybridEncapsulate(pubKey *HybridKEMPublicKey) ([]byte, *HybridKEMCiphertext, error) {
+	peer, err := ecdh.X25519().NewPublicKey(pubKey.X25519)
+	if err != nil {
+		return nil, nil, err
+	}
+	ek, err := mlkem.NewEncapsulationKey768(pubKey.MLKEM)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	x, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	ecSecret, err := x.ECDH(peer)
+	if err != nil {
+		return nil, nil, err
+	}
+	kemSecret, kemCt := ek.Encapsulate()
+
+	ct := &HybridKEMCiphertext{
+		X25519: x.PublicKey().Bytes(),
+		MLKEM:  kemCt,
+	}
+	key, err := deriveHybridKEMKey(ecSecret, kemSecret, pubKey, ct)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, ct, nil
+}
+
+// deriveHybridKEMKey combines both secrets with the whole transcript of the key exchange.
+func deriveHybridKEMKey(ecSecret, kemSecret []byte, pubKey *HybridKEMPublicKey,
+	ct *HybridKEMCiphertext) ([]byte, error) {
+	secret := append(append([]byte{}, ecSecret...), kemSecret...)
+	var salt []byte
+	for _, b := range [][]byte{pubKey.X25519, pubKey.MLKEM, ct.X25519, ct.MLKEM} {
+		salt = append(salt, b...)
+	}
+	return hkdf.Key(sha256.New, secret, salt, hybridKEMInfo, HybridKEMKeyLen)
+}
+
+// ChannelCipher encrypts the messages of one stream with AES-GCM. Each side uses its own
+// key (derived from the shared key) for sending and a message counter as a nonce, thus
+// the messages cannot be replayed, reordered or reflected.
+type ChannelCipher struct {
+	sendAEAD cipher.AEAD
+	recvAEAD cipher.AEAD
+	sendSeq  uint64
+	recvSeq  uint64
+}
+
+// NewChannelCipher returns a cipher for one side of the channel - initiator needs to be
+// true on the side which generated HybridKEMPrivateKey and false on the other one.
+func NewChannelCipher(key []byte, initiator bool) (*ChannelCipher, error) {
+	initKey, err := newChannelAEAD(key, "initiator")
+	if err != nil {
+		return nil, err
+	}
+	respKey, err := newChannelAEAD(key, "responder")
+	if err != nil {
+		return nil, err
+	}
+
+	if initiator {
+		return &ChannelCipher{sendAEAD: initKey, recvAEAD: respKey}, nil
+	}
+	return &ChannelCipher{sendAEAD: respKey, recvAEAD: initKey}, nil
+}
+
+func (c *ChannelCipher) Seal(plaintext []byte) []byte {
+	nonce := channelNonce(c.sendAEAD, c.sendSeq)
+	c.sendSeq++
+	return c.sendAEAD.Seal(nil, nonce, plaintext, nil)
+}
+
+func (c *ChannelCipher) Open(ciphertext []byte) ([]byte, error) {
+	nonce := channelNonce(c.recvAEAD, c.recvSeq)
+	plaintext, err := c.recvAEAD.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("message authentication failed")
+	}
+	c.recvSeq++
+	return plaintext, nil
+}
+
+func newChannelAEAD(key []byte, direction string) (cipher.AEAD, error) {
+	k, err := hkdf.Expand(sha256.New, key, hybridKEMInfo+" "+direction, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func channelNonce(aead cipher.AEAD, seq uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], seq)
+	return nonce
+}
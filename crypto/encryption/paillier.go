@@ -48,6 +48,23 @@ func NewPaillier(primeLength int) *Paillier {
 	return &paillier
 }
 
+// NewPaillierPubKey returns the public key with modulus n and generator g.
+func NewPaillierPubKey(n, g *big.Int) *PaillierPubKey {
+	return &PaillierPubKey{
+		n:  n,
+		n2: new(big.Int).Mul(n, n),
+		g:  g,
+	}
+}
+
+func (pubKey *PaillierPubKey) GetN() *big.Int {
+	return pubKey.n
+}
+
+func (pubKey *PaillierPubKey) GetG() *big.Int {
+	return pubKey.g
+}
+
 func NewPubPaillier(pubKey *PaillierPubKey) *Paillier {
 	var paillier Paillier
 
@@ -59,15 +76,24 @@ func NewPubPaillier(pubKey *PaillierPubKey) *Paillier {
 }
 
 func (paillier *Paillier) Encrypt(m *big.Int) (*big.Int, error) {
+	// r should be from Z_n*, but as it is very unlikely that we get an element which is not
+	// invertible, we don't check
+	r, err := common.GetRandomInt(paillier.pubKey.n)
+	if err != nil {
+		return nil, err
+	}
+	return paillier.EncryptWithR(m, r)
+}
+
+// EncryptWithR encrypts m using the given randomness r from Z_n*. It is needed when
+// the encryptor proves something about the ciphertext (see package encproofs).
+func (paillier *Paillier) EncryptWithR(m, r *big.Int) (*big.Int, error) {
 	if m.Cmp(paillier.pubKey.n) >= 0 {
 		err := errors.New("msg is too big")
 		return nil, err
 	}
 
 	// c = g^m * r^n mod n^2
-	// r should be from Z_n*, but as it is very unlikely that we get an element which is not
-	// invertible, we don't check
-	r := common.GetRandomInt(paillier.pubKey.n)
 	t1 := new(big.Int).Exp(paillier.pubKey.g, m, paillier.pubKey.n2) // g^m
 	t2 := new(big.Int).Exp(r, paillier.pubKey.n, paillier.pubKey.n2) // r^n
 	c := new(big.Int).Mul(t1, t2)
@@ -102,7 +128,7 @@ func (paillier *Paillier) GetPubKey() *PaillierPubKey {
 	return paillier.pubKey
 }
 
-func (paillier *Paillier) generateKey() {
+func (paillier *Paillier) generateKey() error {
 	p, _ := rand.Prime(rand.Reader, paillier.primeLength)
 	q, _ := rand.Prime(rand.Reader, paillier.primeLength)
 	p_min := new(big.Int).Sub(p, big.NewInt(1)) // p-1
@@ -118,7 +144,10 @@ func (paillier *Paillier) generateKey() {
 	}
 
 	for {
-		g := common.GetRandomInt(n2)
+		g, err := common.GetRandomInt(n2)
+		if err != nil {
+			return err
+		}
 		// check whether it is of order k * n
 		// g = (1+n)^x * y^n mod n^2
 		// g^lambda = (1+n)^(lambda * x) * y^(n * lambda) mod n^2
@@ -140,4 +169,5 @@ func (paillier *Paillier) generateKey() {
 		}
 	}
 
+	return nil
 }
@@ -0,0 +1,213 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package groups
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"math/big"
+)
+
+// PrimeOrderGroup abstracts the arithmetic of a group of prime order q, so that the protocols
+// written against it (see for example sigma.NewGroupRepresentation) work with any backend:
+// Schnorr groups (NewSchnorrBackend), elliptic curves (dlog.NewECBackend) or optimized
+// implementations with fixed-size field elements, which can be plugged in without touching
+// the provers and verifiers.
+//
+// Elements and scalars of one group are not to be mixed with the ones of another group
+// (the implementations may panic).
+type PrimeOrderGroup interface {
+	// Name identifies the backend (for example "Schnorr" or "P256").
+	Name() string
+	// Params returns the public values which define the group.
+	Params() []*big.Int
+	// Order returns q.
+	Order() *big.Int
+	Generator() Element
+	Identity() Element
+	// Scalar returns x mod q.
+	Scalar(x *big.Int) Scalar
+	// RandomScalar returns a random scalar from Z_q.
+	RandomScalar() (Scalar, error)
+	// Element decodes the element encoded by Element.Ints. It returns an error if the
+	// integers do not encode an element of the group, thus it is to be used for all the values
+	// received from other parties.
+	Element(ints []*big.Int) (Element, error)
+}
+
+// Scalar is an integer modulo the order of the group. Scalars are immutable, operations
+// return new values.
+type Scalar interface {
+	Add(other Scalar) Scalar
+	Sub(other Scalar) Scalar
+	Mul(other Scalar) Scalar
+	Neg() Scalar
+	Equals(other Scalar) bool
+	// BigInt returns the value from [0, q).
+	BigInt() *big.Int
+}
+
+// Element is an element of a prime order group, written multiplicatively. Elements are
+// immutable, operations return new values.
+type Element interface {
+	Mul(other Element) Element
+	Exp(exponent Scalar) Element
+	Inv() Element
+	Equals(other Element) bool
+	// Ints returns the encoding of the element as integers (for example the coordinates
+	// of a point), which is how elements are hashed and sent in messages.
+	Ints() []*big.Int
+}
+
+// bigScalar is a Scalar backed by big.Int.
+type bigScalar struct {
+	q *big.Int
+	v *big.Int
+}
+
+// NewBigScalar returns x mod q as a Scalar backed by big.Int. Backends which do not provide
+// their own field arithmetic can use it for their scalars.
+func NewBigScalar(q, x *big.Int) Scalar {
+	return &bigScalar{
+		q: q,
+		v: new(big.Int).Mod(x, q),
+	}
+}
+
+// RandomBigScalar returns a random Scalar from Z_q backed by big.Int.
+func RandomBigScalar(q *big.Int) (Scalar, error) {
+	x, err := common.GetRandomInt(q)
+	if err != nil {
+		return nil, err
+	}
+	return &bigScalar{q: q, v: x}, nil
+}
+
+func (s *bigScalar) Add(other Scalar) Scalar {
+	return NewBigScalar(s.q, new(big.Int).Add(s.v, other.BigInt()))
+}
+
+func (s *bigScalar) Sub(other Scalar) Scalar {
+	return NewBigScalar(s.q, new(big.Int).Sub(s.v, other.BigInt()))
+}
+
+func (s *bigScalar) Mul(other Scalar) Scalar {
+	return NewBigScalar(s.q, new(big.Int).Mul(s.v, other.BigInt()))
+}
+
+func (s *bigScalar) Neg() Scalar {
+	return NewBigScalar(s.q, new(big.Int).Neg(s.v))
+}
+
+func (s *bigScalar) Equals(other Scalar) bool {
+	return other != nil && s.v.Cmp(other.BigInt()) == 0
+}
+
+func (s *bigScalar) BigInt() *big.Int {
+	return new(big.Int).Set(s.v)
+}
+
+// schnorrBackend is SchnorrGroup as PrimeOrderGroup.
+type schnorrBackend struct {
+	group *SchnorrGroup
+}
+
+// NewSchnorrBackend returns the Schnorr group as PrimeOrderGroup. Elements are encoded
+// as a single integer from [1, P).
+func NewSchnorrBackend(group *SchnorrGroup) PrimeOrderGroup {
+	return &schnorrBackend{
+		group: group,
+	}
+}
+
+func (b *schnorrBackend) Name() string {
+	return "Schnorr"
+}
+
+func (b *schnorrBackend) Params() []*big.Int {
+	return []*big.Int{b.group.P}
+}
+
+func (b *schnorrBackend) Order() *big.Int {
+	return b.group.Q
+}
+
+func (b *schnorrBackend) Generator() Element {
+	return b.element(b.group.G)
+}
+
+func (b *schnorrBackend) Identity() Element {
+	return b.element(big.NewInt(1))
+}
+
+func (b *schnorrBackend) Scalar(x *big.Int) Scalar {
+	return NewBigScalar(b.group.Q, x)
+}
+
+func (b *schnorrBackend) RandomScalar() (Scalar, error) {
+	return RandomBigScalar(b.group.Q)
+}
+
+func (b *schnorrBackend) Element(ints []*big.Int) (Element, error) {
+	if len(ints) != 1 || !b.group.IsElementInGroup(ints[0]) {
+		return nil, fmt.Errorf("not an element of the Schnorr group")
+	}
+	return b.element(ints[0]), nil
+}
+
+func (b *schnorrBackend) element(x *big.Int) *schnorrElement {
+	return &schnorrElement{
+		group: b.group,
+		v:     new(big.Int).Set(x),
+	}
+}
+
+type schnorrElement struct {
+	group *SchnorrGroup
+	v     *big.Int
+}
+
+func (e *schnorrElement) Mul(other Element) Element {
+	return &schnorrElement{
+		group: e.group,
+		v:     e.group.Mul(e.v, other.(*schnorrElement).v),
+	}
+}
+
+func (e *schnorrElement) Exp(exponent Scalar) Element {
+	return &schnorrElement{
+		group: e.group,
+		v:     e.group.Exp(e.v, exponent.BigInt()),
+	}
+}
+
+func (e *schnorrElement) Inv() Element {
+	return &schnorrElement{
+		group: e.group,
+		v:     e.group.Inv(e.v),
+	}
+}
+
+func (e *schnorrElement) Equals(other Element) bool {
+	o, ok := other.(*schnorrElement)
+	return ok && e.v.Cmp(o.v) == 0
+}
+
+func (e *schnorrElement) Ints() []*big.Int {
+	return []*big.Int{new(big.Int).Set(e.v)}
+}
@@ -0,0 +1,169 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package groups
+
+import (
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+)
+
+// Encodings of the Schnorr group parameters which are used by other libraries, so that
+// the groups generated by OpenSSL (openssl dsaparam, openssl genpkey -genparam) or Java
+// (AlgorithmParameters.getEncoded) can be used in emmy and vice versa. Supported are:
+//  - DSA parameters (RFC 3279): SEQUENCE {p, q, g}, PEM type "DSA PARAMETERS",
+//  - X9.42 DH parameters (RFC 3279): SEQUENCE {p, g, q, j, validationParms}, where j and
+//    validationParms are optional, PEM type "X9.42 DH PARAMETERS",
+//  - PKCS #3 DH parameters: SEQUENCE {p, g, privateValueLength}, PEM type "DH PARAMETERS".
+//    They do not contain Q, thus P needs to be a safe prime (Q = (P-1)/2), as for example
+//    the groups from RFC 7919 or the ones generated by openssl dhparam.
+//
+// All the parsed groups are validated (see Validate).
+
+const (
+	PEMTypeDSA   = "DSA PARAMETERS"
+	PEMTypeX942  = "X9.42 DH PARAMETERS"
+	PEMTypePKCS3 = "DH PARAMETERS"
+)
+
+type dsaParameters struct {
+	P, Q, G *big.Int
+}
+
+type x942Parameters struct {
+	P, G, Q         *big.Int
+	J               *big.Int      `asn1:"optional"`
+	ValidationParms asn1.RawValue `asn1:"optional"`
+}
+
+type pkcs3Parameters struct {
+	P, G               *big.Int
+	PrivateValueLength int `asn1:"optional"`
+}
+
+// ParseDSAParameters parses the DER encoded DSA parameters.
+func ParseDSAParameters(der []byte) (*SchnorrGroup, error) {
+	var params dsaParameters
+	if err := unmarshalParameters(der, &params); err != nil {
+		return nil, err
+	}
+	return validated(NewSchnorrGroupFromParams(params.P, params.G, params.Q))
+}
+
+// ParseX942Parameters parses the DER encoded X9.42 DH parameters. J and the validation
+// parameters are ignored.
+func ParseX942Parameters(der []byte) (*SchnorrGroup, error) {
+	var params x942Parameters
+	if err := unmarshalParameters(der, &params); err != nil {
+		return nil, err
+	}
+	return validated(NewSchnorrGroupFromParams(params.P, params.G, params.Q))
+}
+
+// ParsePKCS3Parameters parses the DER encoded PKCS #3 DH parameters. It returns an error
+// if P is not a safe prime or G is not of order (P-1)/2.
+func ParsePKCS3Parameters(der []byte) (*SchnorrGroup, error) {
+	var params pkcs3Parameters
+	if err := unmarshalParameters(der, &params); err != nil {
+		return nil, err
+	}
+	if params.P.Sign() <= 0 {
+		return nil, fmt.Errorf("Modulus P is not positive")
+	}
+	q := new(big.Int).Sub(params.P, big.NewInt(1))
+	q.Rsh(q, 1)
+	return validated(NewSchnorrGroupFromParams(params.P, params.G, q))
+}
+
+// ParsePEMParameters parses the first PEM block of data, which can be any of
+// the supported encodings.
+func ParsePEMParameters(data []byte) (*SchnorrGroup, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("No PEM block found")
+	}
+	switch block.Type {
+	case PEMTypeDSA:
+		return ParseDSAParameters(block.Bytes)
+	case PEMTypeX942:
+		return ParseX942Parameters(block.Bytes)
+	case PEMTypePKCS3:
+		return ParsePKCS3Parameters(block.Bytes)
+	}
+	return nil, fmt.Errorf("PEM block of type %s is not supported", block.Type)
+}
+
+// MarshalDSAParameters returns the DER encoding of the group as DSA parameters.
+func (group *SchnorrGroup) MarshalDSAParameters() ([]byte, error) {
+	return asn1.Marshal(dsaParameters{
+		P: group.P,
+		Q: group.Q,
+		G: group.G,
+	})
+}
+
+// MarshalX942Parameters returns the DER encoding of the group as X9.42 DH parameters
+// (without the optional values).
+func (group *SchnorrGroup) MarshalX942Parameters() ([]byte, error) {
+	return asn1.Marshal(struct{ P, G, Q *big.Int }{
+		P: group.P,
+		G: group.G,
+		Q: group.Q,
+	})
+}
+
+// MarshalPEM returns the group encoded as the PEM block of the given type (PEMTypeDSA
+// or PEMTypeX942). PKCS #3 is not supported as it does not contain Q.
+func (group *SchnorrGroup) MarshalPEM(pemType string) ([]byte, error) {
+	var der []byte
+	var err error
+	switch pemType {
+	case PEMTypeDSA:
+		der, err = group.MarshalDSAParameters()
+	case PEMTypeX942:
+		der, err = group.MarshalX942Parameters()
+	default:
+		return nil, fmt.Errorf("PEM block of type %s is not supported", pemType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  pemType,
+		Bytes: der,
+	}), nil
+}
+
+func unmarshalParameters(der []byte, params interface{}) error {
+	rest, err := asn1.Unmarshal(der, params)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return fmt.Errorf("Trailing data after the parameters")
+	}
+	return nil
+}
+
+func validated(group *SchnorrGroup) (*SchnorrGroup, error) {
+	if err := group.Validate(); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package groups
+
+import (
+	"fmt"
+	"math/big"
+)
+
+const expTableWindow = 4
+
+// MaxExpTableWindow is the largest supported window size - the table holds 2^window
+// elements per window, so larger windows need too much memory.
+const MaxExpTableWindow = 8
+
+// ExpTable holds precomputed powers of a fixed base from SchnorrGroup, which makes
+// exponentiation of the base several times faster (fixed-base windowing): for each window
+// i of w bits it stores base^(j * 2^(i * w)) for all window values j, thus exponentiation
+// requires only one multiplication per window and no squaring.
+// It pays off when the same base is exponentiated many times (for example Pedersen bases
+// which are reused across sessions).
+type ExpTable struct {
+	group  *SchnorrGroup
+	window int
+	table  [][]*big.Int
+}
+
+// NewExpTable precomputes the table for base, which needs to be an element of the group
+// (of order group.Q).
+func NewExpTable(group *SchnorrGroup, base *big.Int) *ExpTable {
+	table, _ := NewExpTableWithWindow(group, base, expTableWindow)
+	return table
+}
+
+// NewExpTableWithWindow precomputes the table for base with windows of the given size
+// (from 1 to MaxExpTableWindow bits). Larger windows mean fewer multiplications per
+// exponentiation, but the table grows exponentially with the window size.
+func NewExpTableWithWindow(group *SchnorrGroup, base *big.Int, window int) (*ExpTable, error) {
+	if window < 1 || window > MaxExpTableWindow {
+		return nil, fmt.Errorf("window size needs to be between 1 and %d", MaxExpTableWindow)
+	}
+	windows := (group.Q.BitLen() + window - 1) / window
+	table := make([][]*big.Int, windows)
+	b := new(big.Int).Set(base)
+	for i := range table {
+		table[i] = make([]*big.Int, 1<<uint(window))
+		table[i][0] = big.NewInt(1)
+		for j := 1; j < len(table[i]); j++ {
+			table[i][j] = group.Mul(table[i][j-1], b)
+		}
+		// the base for the next window is b^(2^window)
+		b = group.Mul(table[i][len(table[i])-1], b)
+	}
+
+	return &ExpTable{
+		group:  group,
+		window: window,
+		table:  table,
+	}, nil
+}
+
+// Exp computes base^exponent mod group.P.
+func (t *ExpTable) Exp(exponent *big.Int) *big.Int {
+	e := new(big.Int).Mod(exponent, t.group.Q)
+	result := big.NewInt(1)
+	for i, window := range t.table {
+		digit := 0
+		for j := 0; j < t.window; j++ {
+			digit |= int(e.Bit(i*t.window+j)) << uint(j)
+		}
+		if digit != 0 {
+			result = t.group.Mul(result, window[digit])
+		}
+	}
+	return result
+}
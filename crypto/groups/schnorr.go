@@ -98,13 +98,68 @@ func NewSchnorrSafePrimeGroup(modulusBitLength int) (*SchnorrGroup, error) {
 	}, nil
 }
 
+// Validate checks that P and Q are (probable) primes, Q divides P-1 and G is an element
+// of order Q. It is meant for parameters which are not generated by this package, for example
+// loaded from a configuration file.
+func (group *SchnorrGroup) Validate() error {
+	if group.P == nil || group.G == nil || group.Q == nil {
+		return fmt.Errorf("Schnorr group parameters are not set")
+	}
+	if !group.P.ProbablyPrime(20) {
+		return fmt.Errorf("Modulus P is not prime")
+	}
+	if !group.Q.ProbablyPrime(20) {
+		return fmt.Errorf("Order Q is not prime")
+	}
+	pMin := new(big.Int).Sub(group.P, big.NewInt(1))
+	if new(big.Int).Mod(pMin, group.Q).Sign() != 0 {
+		return fmt.Errorf("Order Q does not divide P-1")
+	}
+	if group.G.Cmp(big.NewInt(1)) <= 0 || group.G.Cmp(group.P) >= 0 {
+		return fmt.Errorf("Generator G is not in the group")
+	}
+	if group.Exp(group.G, group.Q).Cmp(big.NewInt(1)) != 0 {
+		return fmt.Errorf("Generator G is not of order Q")
+	}
+	return nil
+}
+
 // GetRandomElement returns a random element from this group. Note that elements from this group
 // are integers smaller than group.P, but not all - only Q of them. GetRandomElement returns
 // one (random) of these Q elements.
-func (group *SchnorrGroup) GetRandomElement() *big.Int {
-	r := common.GetRandomInt(group.Q)
+func (group *SchnorrGroup) GetRandomElement() (*big.Int, error) {
+	r, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, err
+	}
 	el := group.Exp(group.G, r)
-	return el
+	return el, nil
+}
+
+// HashIntoElement deterministically maps numbers to an element of this group (the element
+// is not 1, thus it is a generator). Nobody knows the dlog of the returned element with
+// respect to G, so it can be used as a base which is bound to some context (for example
+// a domain name).
+func (group *SchnorrGroup) HashIntoElement(numbers ...*big.Int) *big.Int {
+	for counter := int64(0); ; counter++ {
+		h := common.Hash(append([]*big.Int{big.NewInt(counter)}, numbers...)...)
+		el := group.ClearCofactor(h.Mod(h, group.P))
+		if el.Cmp(big.NewInt(1)) > 0 {
+			return el
+		}
+	}
+}
+
+// Cofactor returns R = (P-1) / Q.
+func (group *SchnorrGroup) Cofactor() *big.Int {
+	cofactor := new(big.Int).Sub(group.P, big.NewInt(1))
+	return cofactor.Div(cofactor, group.Q)
+}
+
+// ClearCofactor maps an element x of Z_p* into the group by computing x^R. Note that the
+// result can be 1 (for the elements of order dividing R).
+func (group *SchnorrGroup) ClearCofactor(x *big.Int) *big.Int {
+	return group.Exp(x, group.Cofactor())
 }
 
 // Add computes x + y in SchnorrGroup. This means x + y mod group.P.
@@ -133,8 +188,12 @@ func (group *SchnorrGroup) Inv(x *big.Int) *big.Int {
 }
 
 // IsElementInGroup returns true if x is in the group and false otherwise. Note that
-// an element x is in Schnorr group when x^group.Q = 1 mod group.P.
+// an element x is in Schnorr group when x^group.Q = 1 mod group.P. Only the canonical
+// representations (from [1, P)) are accepted, otherwise x + P would be accepted too.
 func (group *SchnorrGroup) IsElementInGroup(x *big.Int) bool {
+	if x == nil || x.Sign() <= 0 || x.Cmp(group.P) >= 0 {
+		return false
+	}
 	check := group.Exp(x, group.Q) // should be 1
 	return check.Cmp(big.NewInt(1)) == 0
 }
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package lattice
+
+// BDLOP commitment scheme (Baum, Damgard, Lyubashevsky, Oechsner, Peikert:
+// More Efficient Commitments from Structured Lattice Assumptions, https://eprint.iacr.org/2016/997).
+//
+// Public parameters are A1 = [I_n | A1'] (n x k matrix over R_q) and a2 = [0^n, 1, a2']
+// (vector of length k). Committer chooses a short r (coefficients from {-1, 0, 1}) and commits
+// to m from R_q with c1 = A1 * r, c2 = <a2, r> + m. The scheme is computationally hiding
+// (Module-LWE) and binding (Module-SIS).
+const (
+	BDLOPHeight = 1 // n
+	BDLOPWidth  = 3 // k
+)
+
+type BDLOPParams struct {
+	A1 []PolyVec
+	A2 PolyVec
+}
+
+// NewBDLOPParams returns random public parameters. They need to be generated by a party
+// trusted by both the committer and the receiver (or from a public random seed).
+func NewBDLOPParams() *BDLOPParams {
+	a1 := make([]PolyVec, BDLOPHeight)
+	for i := range a1 {
+		a1[i] = make(PolyVec, BDLOPWidth)
+		for j := range a1[i] {
+			if j < BDLOPHeight {
+				a1[i][j] = NewPoly()
+				if i == j {
+					a1[i][j][0] = 1
+				}
+			} else {
+				a1[i][j] = GetRandomPoly()
+			}
+		}
+	}
+
+	a2 := make(PolyVec, BDLOPWidth)
+	for j := range a2 {
+		switch {
+		case j < BDLOPHeight:
+			a2[j] = NewPoly()
+		case j == BDLOPHeight:
+			a2[j] = NewPolyFromCoefficients(1)
+		default:
+			a2[j] = GetRandomPoly()
+		}
+	}
+
+	return &BDLOPParams{
+		A1: a1,
+		A2: a2,
+	}
+}
+
+type BDLOPCommitment struct {
+	C1 PolyVec
+	C2 Poly
+}
+
+// commit returns c1 = A1 * r, c2 = <a2, r> + m.
+func (params *BDLOPParams) commit(m Poly, r PolyVec) *BDLOPCommitment {
+	return &BDLOPCommitment{
+		C1: MatVecMul(params.A1, r),
+		C2: params.A2.InnerProduct(r).Add(m),
+	}
+}
+
+type BDLOPCommitter struct {
+	params         *BDLOPParams
+	committedValue Poly
+	r              PolyVec
+}
+
+func NewBDLOPCommitter(params *BDLOPParams) *BDLOPCommitter {
+	return &BDLOPCommitter{
+		params: params,
+	}
+}
+
+// GetCommitMsg chooses a random short r and returns the commitment to m.
+func (committer *BDLOPCommitter) GetCommitMsg(m Poly) *BDLOPCommitment {
+	committer.committedValue = m
+	committer.r = GetRandomBoundedPolyVec(BDLOPWidth, 1)
+	return committer.params.commit(m, committer.r)
+}
+
+// GetDecommitMsg returns the committed value m and the randomness r.
+func (committer *BDLOPCommitter) GetDecommitMsg() (Poly, PolyVec) {
+	return committer.committedValue, committer.r
+}
+
+type BDLOPReceiver struct {
+	params     *BDLOPParams
+	commitment *BDLOPCommitment
+}
+
+func NewBDLOPReceiver(params *BDLOPParams) *BDLOPReceiver {
+	return &BDLOPReceiver{
+		params: params,
+	}
+}
+
+// When receiver receives a commitment, it stores the value using SetCommitment method.
+func (receiver *BDLOPReceiver) SetCommitment(c *BDLOPCommitment) {
+	receiver.commitment = c
+}
+
+// CheckDecommitment verifies that r is short and that the stored commitment is a commitment
+// to m with randomness r.
+func (receiver *BDLOPReceiver) CheckDecommitment(m Poly, r PolyVec) bool {
+	if len(r) != BDLOPWidth || r.InfNorm() > 1 {
+		return false
+	}
+	c := receiver.params.commit(m, r)
+	return c.C1.Equals(receiver.commitment.C1) && c.C2.Equals(receiver.commitment.C2)
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package lattice
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+// Proof of knowledge of the opening of BDLOP commitment (Lyubashevsky's Fiat-Shamir with
+// aborts): prover knows short r such that c1 = A1 * r. Prover sends t = A1 * y for y with
+// coefficients from [-BDLOPProofBound, BDLOPProofBound], verifier sends a challenge d with
+// BDLOPChallengeWeight coefficients from {-1, 1} (the rest are 0), and prover responds with
+// z = y + d * r. To make z independent of r, prover aborts if z has a coefficient larger than
+// BDLOPProofBound - BDLOPChallengeWeight - in this case the proof needs to be restarted.
+// Verifier checks A1 * z = t + d * c1 and that z is short.
+//
+// Note that the extracted opening is a "weak" one (as described in the paper), it binds
+// the committer to the committed value nevertheless.
+const (
+	BDLOPChallengeWeight = 36
+	BDLOPProofBound      = 1 << 15
+	bdlopMaxAttempts     = 100
+)
+
+// GetBDLOPChallenge returns a random polynomial with BDLOPChallengeWeight coefficients
+// from {-1, 1} and all the others 0.
+func GetBDLOPChallenge() Poly {
+	d := NewPoly()
+	positions := make([]int, N)
+	for i := range positions {
+		positions[i] = i
+	}
+	for i := 0; i < BDLOPChallengeWeight; i++ {
+		j, _ := rand.Int(rand.Reader, big.NewInt(int64(N-i)))
+		k := i + int(j.Int64())
+		positions[i], positions[k] = positions[k], positions[i]
+		sign, _ := rand.Int(rand.Reader, big.NewInt(2))
+		d[positions[i]] = mod(2*sign.Int64() - 1)
+	}
+	return d
+}
+
+type BDLOPOpeningProver struct {
+	params *BDLOPParams
+	r      PolyVec
+	y      PolyVec
+}
+
+func NewBDLOPOpeningProver(params *BDLOPParams, r PolyVec) *BDLOPOpeningProver {
+	return &BDLOPOpeningProver{
+		params: params,
+		r:      r,
+	}
+}
+
+// GetProofRandomData returns t = A1 * y.
+func (prover *BDLOPOpeningProver) GetProofRandomData() PolyVec {
+	prover.y = GetRandomBoundedPolyVec(BDLOPWidth, BDLOPProofBound)
+	return MatVecMul(prover.params.A1, prover.y)
+}
+
+// GetProofData returns z = y + d * r. It returns an error if z would leak information about r
+// - the proof needs to be restarted in this case (with new GetProofRandomData).
+func (prover *BDLOPOpeningProver) GetProofData(challenge Poly) (PolyVec, error) {
+	z := prover.y.Add(prover.r.ScalarMul(challenge))
+	if z.InfNorm() > BDLOPProofBound-BDLOPChallengeWeight {
+		return nil, errors.New("proof aborted, it needs to be restarted")
+	}
+	return z, nil
+}
+
+type BDLOPOpeningVerifier struct {
+	params     *BDLOPParams
+	commitment *BDLOPCommitment
+	t          PolyVec
+	challenge  Poly
+}
+
+func NewBDLOPOpeningVerifier(params *BDLOPParams,
+	commitment *BDLOPCommitment) *BDLOPOpeningVerifier {
+	return &BDLOPOpeningVerifier{
+		params:     params,
+		commitment: commitment,
+	}
+}
+
+func (verifier *BDLOPOpeningVerifier) SetProofRandomData(t PolyVec) {
+	verifier.t = t
+}
+
+func (verifier *BDLOPOpeningVerifier) GetChallenge() Poly {
+	verifier.challenge = GetBDLOPChallenge()
+	return verifier.challenge
+}
+
+// Verify checks that z is short and A1 * z = t + d * c1.
+func (verifier *BDLOPOpeningVerifier) Verify(z PolyVec) bool {
+	if len(z) != BDLOPWidth || z.InfNorm() > BDLOPProofBound-BDLOPChallengeWeight {
+		return false
+	}
+	left := MatVecMul(verifier.params.A1, z)
+	right := verifier.t.Add(verifier.commitment.C1.ScalarMul(verifier.challenge))
+	return left.Equals(right)
+}
+
+// ProveBDLOPOpening demonstrates the proof of the opening (restarting it when aborted).
+func ProveBDLOPOpening(params *BDLOPParams, commitment *BDLOPCommitment, r PolyVec) bool {
+	prover := NewBDLOPOpeningProver(params, r)
+	verifier := NewBDLOPOpeningVerifier(params, commitment)
+
+	for i := 0; i < bdlopMaxAttempts; i++ {
+		t := prover.GetProofRandomData()
+		verifier.SetProofRandomData(t)
+		challenge := verifier.GetChallenge()
+		z, err := prover.GetProofData(challenge)
+		if err != nil {
+			continue
+		}
+		return verifier.Verify(z)
+	}
+	return false
+}
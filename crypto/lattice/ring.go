@@ -0,0 +1,241 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package lattice is an EXPERIMENTAL module for prototyping post-quantum schemes. It is not
+// optimized (polynomials are multiplied naively) and its parameters were not vetted - do not
+// use it to protect anything.
+package lattice
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// Polynomials are elements of the ring R_q = Z_q[X]/(X^N + 1).
+const (
+	N = 256
+	Q = 2147483647 // 2^31 - 1, thus products of two coefficients fit into int64
+)
+
+// Poly holds the coefficients of a polynomial from R_q in [0, Q).
+type Poly []int64
+
+// PolyVec is a vector of polynomials from R_q.
+type PolyVec []Poly
+
+func NewPoly() Poly {
+	return make(Poly, N)
+}
+
+// NewPolyFromCoefficients returns a polynomial with the given coefficients (reduced modulo Q),
+// the missing coefficients are 0.
+func NewPolyFromCoefficients(coeffs ...int64) Poly {
+	p := NewPoly()
+	for i, c := range coeffs {
+		p[i] = mod(c)
+	}
+	return p
+}
+
+func (p Poly) Add(other Poly) Poly {
+	res := NewPoly()
+	for i := range p {
+		res[i] = mod(p[i] + other[i])
+	}
+	return res
+}
+
+func (p Poly) Sub(other Poly) Poly {
+	res := NewPoly()
+	for i := range p {
+		res[i] = mod(p[i] - other[i])
+	}
+	return res
+}
+
+// Mul returns p * other in R_q (X^N = -1).
+func (p Poly) Mul(other Poly) Poly {
+	res := NewPoly()
+	for i, a := range p {
+		if a == 0 {
+			continue
+		}
+		for j, b := range other {
+			prod := a * b % Q
+			if k := i + j; k < N {
+				res[k] = mod(res[k] + prod)
+			} else {
+				res[k-N] = mod(res[k-N] - prod)
+			}
+		}
+	}
+	return res
+}
+
+func (p Poly) Equals(other Poly) bool {
+	for i := range p {
+		if p[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// InfNorm returns the largest absolute value of the coefficients in the centered
+// representation (-Q/2, Q/2].
+func (p Poly) InfNorm() int64 {
+	var norm int64
+	for _, c := range p {
+		if c > Q/2 {
+			c = Q - c
+		}
+		if c > norm {
+			norm = c
+		}
+	}
+	return norm
+}
+
+func (p Poly) Bytes() []byte {
+	b := make([]byte, 4*N)
+	for i, c := range p {
+		binary.BigEndian.PutUint32(b[4*i:], uint32(c))
+	}
+	return b
+}
+
+// NewPolyFromBytes returns the polynomial encoded with Bytes.
+func NewPolyFromBytes(b []byte) (Poly, error) {
+	if len(b) != 4*N {
+		return nil, fmt.Errorf("polynomial needs to be encoded with %d bytes", 4*N)
+	}
+	p := NewPoly()
+	for i := range p {
+		p[i] = int64(binary.BigEndian.Uint32(b[4*i:]))
+		if p[i] >= Q {
+			return nil, fmt.Errorf("coefficient is not reduced modulo Q")
+		}
+	}
+	return p, nil
+}
+
+func (v PolyVec) Add(other PolyVec) PolyVec {
+	res := make(PolyVec, len(v))
+	for i := range v {
+		res[i] = v[i].Add(other[i])
+	}
+	return res
+}
+
+func (v PolyVec) Sub(other PolyVec) PolyVec {
+	res := make(PolyVec, len(v))
+	for i := range v {
+		res[i] = v[i].Sub(other[i])
+	}
+	return res
+}
+
+// ScalarMul returns the vector with each polynomial multiplied by p.
+func (v PolyVec) ScalarMul(p Poly) PolyVec {
+	res := make(PolyVec, len(v))
+	for i := range v {
+		res[i] = v[i].Mul(p)
+	}
+	return res
+}
+
+// InnerProduct returns the sum of v[i] * other[i].
+func (v PolyVec) InnerProduct(other PolyVec) Poly {
+	res := NewPoly()
+	for i := range v {
+		res = res.Add(v[i].Mul(other[i]))
+	}
+	return res
+}
+
+func (v PolyVec) InfNorm() int64 {
+	var norm int64
+	for _, p := range v {
+		if n := p.InfNorm(); n > norm {
+			norm = n
+		}
+	}
+	return norm
+}
+
+func (v PolyVec) Equals(other PolyVec) bool {
+	if len(v) != len(other) {
+		return false
+	}
+	for i := range v {
+		if !v[i].Equals(other[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatVecMul returns the product of matrix m (given by rows) and vector v.
+func MatVecMul(m []PolyVec, v PolyVec) PolyVec {
+	res := make(PolyVec, len(m))
+	for i, row := range m {
+		res[i] = row.InnerProduct(v)
+	}
+	return res
+}
+
+// GetRandomPoly returns a uniformly random polynomial from R_q.
+func GetRandomPoly() Poly {
+	p := NewPoly()
+	bound := big.NewInt(Q)
+	for i := range p {
+		c, _ := rand.Int(rand.Reader, bound)
+		p[i] = c.Int64()
+	}
+	return p
+}
+
+// GetRandomBoundedPoly returns a polynomial with coefficients chosen uniformly
+// from [-bound, bound].
+func GetRandomBoundedPoly(bound int64) Poly {
+	p := NewPoly()
+	size := big.NewInt(2*bound + 1)
+	for i := range p {
+		c, _ := rand.Int(rand.Reader, size)
+		p[i] = mod(c.Int64() - bound)
+	}
+	return p
+}
+
+// GetRandomBoundedPolyVec returns a vector of k polynomials from GetRandomBoundedPoly.
+func GetRandomBoundedPolyVec(k int, bound int64) PolyVec {
+	v := make(PolyVec, k)
+	for i := range v {
+		v[i] = GetRandomBoundedPoly(bound)
+	}
+	return v
+}
+
+func mod(c int64) int64 {
+	c %= Q
+	if c < 0 {
+		c += Q
+	}
+	return c
+}
@@ -0,0 +1,231 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package lattice
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+// Proof of knowledge of a short solution of a Module-SIS relation: prover knows s with
+// coefficients from [-Bound, Bound] such that A * s = t (BDLOP opening proof is a special case
+// with A = A1). It is Lyubashevsky's Fiat-Shamir with aborts: prover sends w = A * y for
+// a masking vector y with coefficients from [-gamma, gamma], verifier sends a challenge d
+// (see GetBDLOPChallenge) and prover responds with z = y + d * s, unless z has a coefficient
+// larger than gamma - beta, where beta = Bound * BDLOPChallengeWeight bounds the coefficients
+// of d * s - in this case it aborts, as z would leak s, and the proof is restarted. Verifier
+// checks A * z = w + d * t and that z is short.
+//
+// gamma is chosen so that the prover aborts with probability about 1 - 1/e. In the
+// non-interactive variant (ProveShortVector) the challenge is the hash of the statement and w,
+// so that the aborted attempts are never seen by the verifier.
+type ShortVectorStatement struct {
+	A     []PolyVec
+	T     PolyVec
+	Bound int64
+}
+
+// NewShortVectorStatement returns the statement A * s = t for the given short s.
+func NewShortVectorStatement(a []PolyVec, s PolyVec, bound int64) *ShortVectorStatement {
+	return &ShortVectorStatement{
+		A:     a,
+		T:     MatVecMul(a, s),
+		Bound: bound,
+	}
+}
+
+// gamma returns the bound of the masking vectors.
+func (statement *ShortVectorStatement) gamma() int64 {
+	return statement.beta() * N * int64(statement.width())
+}
+
+func (statement *ShortVectorStatement) beta() int64 {
+	return statement.Bound * BDLOPChallengeWeight
+}
+
+func (statement *ShortVectorStatement) width() int {
+	if len(statement.A) == 0 {
+		return 0
+	}
+	return len(statement.A[0])
+}
+
+// isShortResponse checks that z has the right length and coefficients from
+// [-(gamma - beta), gamma - beta].
+func (statement *ShortVectorStatement) isShortResponse(z PolyVec) bool {
+	return len(z) == statement.width() && z.InfNorm() <= statement.gamma()-statement.beta()
+}
+
+type ShortVectorProver struct {
+	statement *ShortVectorStatement
+	s         PolyVec
+	y         PolyVec
+}
+
+func NewShortVectorProver(statement *ShortVectorStatement, s PolyVec) (*ShortVectorProver,
+	error) {
+	if len(s) != statement.width() || s.InfNorm() > statement.Bound {
+		return nil, errors.New("secret is not a short vector of the right length")
+	}
+	return &ShortVectorProver{
+		statement: statement,
+		s:         s,
+	}, nil
+}
+
+// GetProofRandomData returns w = A * y.
+func (prover *ShortVectorProver) GetProofRandomData() PolyVec {
+	prover.y = GetRandomBoundedPolyVec(prover.statement.width(), prover.statement.gamma())
+	return MatVecMul(prover.statement.A, prover.y)
+}
+
+// GetProofData returns z = y + d * s. It returns an error if z would leak information about s
+// - the proof needs to be restarted in this case (with new GetProofRandomData).
+func (prover *ShortVectorProver) GetProofData(challenge Poly) (PolyVec, error) {
+	z := prover.y.Add(prover.s.ScalarMul(challenge))
+	if !prover.statement.isShortResponse(z) {
+		return nil, errors.New("proof aborted, it needs to be restarted")
+	}
+	return z, nil
+}
+
+type ShortVectorVerifier struct {
+	statement *ShortVectorStatement
+	w         PolyVec
+	challenge Poly
+}
+
+func NewShortVectorVerifier(statement *ShortVectorStatement) *ShortVectorVerifier {
+	return &ShortVectorVerifier{
+		statement: statement,
+	}
+}
+
+func (verifier *ShortVectorVerifier) SetProofRandomData(w PolyVec) {
+	verifier.w = w
+}
+
+func (verifier *ShortVectorVerifier) GetChallenge() Poly {
+	verifier.challenge = GetBDLOPChallenge()
+	return verifier.challenge
+}
+
+// Verify checks that z is short and A * z = w + d * t.
+func (verifier *ShortVectorVerifier) Verify(z PolyVec) bool {
+	statement := verifier.statement
+	if !statement.isShortResponse(z) || len(verifier.w) != len(statement.T) {
+		return false
+	}
+	left := MatVecMul(statement.A, z)
+	right := verifier.w.Add(statement.T.ScalarMul(verifier.challenge))
+	return left.Equals(right)
+}
+
+// ShortVectorProof is the non-interactive proof of knowledge of a short solution.
+type ShortVectorProof struct {
+	Challenge Poly
+	Z         PolyVec
+}
+
+// ProveShortVector returns the non-interactive proof of knowledge of short s such that
+// A * s = t.
+func ProveShortVector(statement *ShortVectorStatement, s PolyVec) (*ShortVectorProof, error) {
+	prover, err := NewShortVectorProver(statement, s)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < bdlopMaxAttempts; i++ {
+		w := prover.GetProofRandomData()
+		challenge := statement.challenge(w)
+		z, err := prover.GetProofData(challenge)
+		if err != nil {
+			continue
+		}
+		return &ShortVectorProof{
+			Challenge: challenge,
+			Z:         z,
+		}, nil
+	}
+	return nil, errors.New("proof aborted too many times")
+}
+
+// Verify recomputes w = A * z - d * t and checks that d is the hash of the statement and w.
+func (proof *ShortVectorProof) Verify(statement *ShortVectorStatement) bool {
+	if len(proof.Challenge) != N || !statement.isShortResponse(proof.Z) {
+		return false
+	}
+	w := MatVecMul(statement.A, proof.Z).Sub(statement.T.ScalarMul(proof.Challenge))
+	return statement.challenge(w).Equals(proof.Challenge)
+}
+
+// challenge derives the challenge from the statement and w (with the same distribution
+// as GetBDLOPChallenge).
+func (statement *ShortVectorStatement) challenge(w PolyVec) Poly {
+	h := sha256.New()
+	h.Write([]byte("emmy/lattice/short-vector"))
+	header := make([]byte, 16)
+	binary.BigEndian.PutUint64(header, uint64(len(statement.A)))
+	binary.BigEndian.PutUint64(header[8:], uint64(statement.Bound))
+	h.Write(header)
+	for _, row := range statement.A {
+		for _, p := range row {
+			h.Write(p.Bytes())
+		}
+	}
+	for _, v := range []PolyVec{statement.T, w} {
+		for _, p := range v {
+			h.Write(p.Bytes())
+		}
+	}
+	seed := h.Sum(nil)
+
+	// positions are chosen with the Fisher-Yates shuffle from the stream expanded from seed
+	var counter uint32
+	var stream []byte
+	next := func() uint16 {
+		if len(stream) < 2 {
+			block := make([]byte, 4)
+			binary.BigEndian.PutUint32(block, counter)
+			counter++
+			digest := sha256.Sum256(append(append([]byte{}, seed...), block...))
+			stream = digest[:]
+		}
+		r := binary.BigEndian.Uint16(stream)
+		stream = stream[2:]
+		return r
+	}
+
+	d := NewPoly()
+	positions := make([]int, N)
+	for i := range positions {
+		positions[i] = i
+	}
+	for i := 0; i < BDLOPChallengeWeight; i++ {
+		bound := uint16(N - i)
+		limit := 65535 - 65535%bound
+		r := next()
+		for r >= limit {
+			r = next()
+		}
+		k := i + int(r%bound)
+		positions[i], positions[k] = positions[k], positions[i]
+		d[positions[i]] = mod(2*int64(next()&1) - 1)
+	}
+	return d
+}
@@ -0,0 +1,227 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package bbsplus implements BBS+ signatures (Au, Susilo and Mu 2006) over the BN256
+// pairing-friendly curve. A single signature is issued on a list of messages (attributes of
+// a credential) and its holder can later prove the possession of the signature while
+// disclosing only some of the messages - the proof does not reveal the signature or
+// the hidden messages and two proofs of the same signature cannot be linked
+// (Camenisch, Drijvers and Lehmann 2016).
+//
+// With generators g1 of G1, g2 of G2 and h_0, h_1, ..., h_L of G1, the secret key is x and
+// the public key is w = g2^x. The signature of messages m_1, ..., m_L is (A, e, s) where
+// e and s are random and
+//
+//	A = (g1 * h_0^s * h_1^m_1 * ... * h_L^m_L)^(1/(x+e))
+//
+// which is verified as e(A, w * g2^e) = e(g1 * h_0^s * h_1^m_1 * ... * h_L^m_L, g2).
+//
+// Note that BN256 offers about 100 bits of security due to the recent advances in
+// the computation of discrete logarithms in the target group.
+package bbsplus
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"golang.org/x/crypto/bn256"
+	"math/big"
+)
+
+var (
+	g1 = new(bn256.G1).ScalarBaseMult(big.NewInt(1))
+	g2 = new(bn256.G2).ScalarBaseMult(big.NewInt(1))
+
+	// fieldPrime is the prime of the field over which BN256 is defined (G1 is the curve
+	// y^2 = x^3 + 3 over it).
+	fieldPrime, _ = new(big.Int).SetString(
+		"65000549695646603732796438742359905742825358107623003571877145026864184071783", 10)
+)
+
+// Params are the generators h_0, h_1, ..., h_L which are shared by the signers of messages
+// of length L. They are derived by hashing, thus nobody knows the discrete logarithms
+// between them.
+type Params struct {
+	H0 *bn256.G1
+	H  []*bn256.G1
+}
+
+// NewParams returns the generators for signatures of numOfMessages messages. The same
+// generators are returned on each call.
+func NewParams(numOfMessages int) *Params {
+	h := make([]*bn256.G1, numOfMessages)
+	for i := range h {
+		h[i] = hashToG1([]byte(fmt.Sprintf("emmy BBS+ h_%d", i+1)))
+	}
+	return &Params{
+		H0: hashToG1([]byte("emmy BBS+ h_0")),
+		H:  h,
+	}
+}
+
+type PubKey struct {
+	W      *bn256.G2 // g2^x
+	Params *Params
+}
+
+type Signature struct {
+	A *bn256.G1
+	E *big.Int
+	S *big.Int
+}
+
+type Signer struct {
+	secKey *big.Int
+	pubKey *PubKey
+}
+
+// NewSigner returns the signer of numOfMessages messages with a new random key.
+func NewSigner(numOfMessages int) (*Signer, error) {
+	x, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{
+		secKey: x,
+		pubKey: &PubKey{
+			W:      new(bn256.G2).ScalarBaseMult(x),
+			Params: NewParams(numOfMessages),
+		},
+	}, nil
+}
+
+func (signer *Signer) GetPubKey() *PubKey {
+	return signer.pubKey
+}
+
+// Sign returns the signature (A, e, s) of messages. The messages are integers modulo
+// the order of the group (see MessageFromBytes).
+func (signer *Signer) Sign(messages []*big.Int) (*Signature, error) {
+	if len(messages) != len(signer.pubKey.Params.H) {
+		return nil, fmt.Errorf("expected %d messages, got %d", len(signer.pubKey.Params.H),
+			len(messages))
+	}
+	for {
+		e, err := randomScalar()
+		if err != nil {
+			return nil, err
+		}
+		s, err := randomScalar()
+		if err != nil {
+			return nil, err
+		}
+		inv := new(big.Int).Add(signer.secKey, e)
+		if inv.ModInverse(inv.Mod(inv, bn256.Order), bn256.Order) == nil {
+			continue // x + e = 0, which happens with negligible probability
+		}
+		b := signer.pubKey.commitment(messages, s)
+		return &Signature{
+			A: new(bn256.G1).ScalarMult(b, inv),
+			E: e,
+			S: s,
+		}, nil
+	}
+}
+
+// Verify returns true if signature is a valid signature of messages, which means that
+// e(A, w * g2^e) = e(g1 * h_0^s * h_1^m_1 * ... * h_L^m_L, g2).
+func Verify(pubKey *PubKey, messages []*big.Int, signature *Signature) bool {
+	if signature == nil || signature.A == nil || signature.E == nil || signature.S == nil ||
+		len(messages) != len(pubKey.Params.H) || isIdentity(signature.A) {
+		return false
+	}
+	w := new(bn256.G2).Add(pubKey.W, new(bn256.G2).ScalarBaseMult(mod(signature.E)))
+	b := pubKey.commitment(messages, signature.S)
+	return equalGT(bn256.Pair(signature.A, w), bn256.Pair(b, g2))
+}
+
+// MessageFromBytes maps an arbitrary message (for example an attribute value) to
+// an integer which can be signed.
+func MessageFromBytes(message []byte) *big.Int {
+	h := sha256.Sum256(message)
+	return mod(new(big.Int).SetBytes(h[:]))
+}
+
+// commitment returns g1 * h_0^s * h_1^m_1 * ... * h_L^m_L.
+func (pubKey *PubKey) commitment(messages []*big.Int, s *big.Int) *bn256.G1 {
+	b := new(bn256.G1).Add(g1, new(bn256.G1).ScalarMult(pubKey.Params.H0, mod(s)))
+	for i, m := range messages {
+		b.Add(b, new(bn256.G1).ScalarMult(pubKey.Params.H[i], mod(m)))
+	}
+	return b
+}
+
+// hashToG1 maps label to a point of G1 by hashing it (together with a counter) to
+// the x coordinate until x^3 + 3 is a square. Because G1 has a prime order, any point on
+// the curve is a generator.
+func hashToG1(label []byte) *bn256.G1 {
+	// fieldPrime = 3 mod 4, thus the square root of a is a^((p+1)/4)
+	sqrtExp := new(big.Int).Add(fieldPrime, big.NewInt(1))
+	sqrtExp.Rsh(sqrtExp, 2)
+	counter := make([]byte, 4)
+	for i := uint32(0); ; i++ {
+		binary.BigEndian.PutUint32(counter, i)
+		h := sha256.Sum256(append(counter, label...))
+		x := new(big.Int).SetBytes(h[:])
+		x.Mod(x, fieldPrime)
+		rhs := new(big.Int).Exp(x, big.NewInt(3), fieldPrime)
+		rhs.Add(rhs, big.NewInt(3))
+		rhs.Mod(rhs, fieldPrime)
+		y := new(big.Int).Exp(rhs, sqrtExp, fieldPrime)
+		if new(big.Int).Exp(y, big.NewInt(2), fieldPrime).Cmp(rhs) != 0 {
+			continue
+		}
+		point := make([]byte, 64)
+		copy(point[32-len(x.Bytes()):32], x.Bytes())
+		copy(point[64-len(y.Bytes()):], y.Bytes())
+		if p, ok := new(bn256.G1).Unmarshal(point); ok && !isIdentity(p) {
+			return p
+		}
+	}
+}
+
+func randomScalar() (*big.Int, error) {
+	for {
+		r, err := common.GetRandomInt(bn256.Order)
+		if err != nil {
+			return nil, err
+		}
+		if r.Sign() != 0 {
+			return r, nil
+		}
+	}
+}
+
+// mod returns x modulo the order of the groups, which is also needed for the negative
+// exponents.
+func mod(x *big.Int) *big.Int {
+	return new(big.Int).Mod(x, bn256.Order)
+}
+
+func isIdentity(p *bn256.G1) bool {
+	return equalG1(p, new(bn256.G1).ScalarBaseMult(big.NewInt(0)))
+}
+
+func equalG1(p1, p2 *bn256.G1) bool {
+	return bytes.Equal(p1.Marshal(), p2.Marshal())
+}
+
+func equalGT(p1, p2 *bn256.GT) bool {
+	return bytes.Equal(p1.Marshal(), p2.Marshal())
+}
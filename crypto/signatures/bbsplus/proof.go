@@ -0,0 +1,396 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package bbsplus
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"golang.org/x/crypto/bn256"
+	"math/big"
+	"sort"
+)
+
+// ProofRandomData is the first message of the proof of possession of a signature (A, e, s).
+// The prover randomizes the signature as A' = A^r1, Abar = A'^(-e) * b^r1 (which equals
+// A'^x) and d = b^r1 * h_0^(-r2), where b = g1 * h_0^s * h_1^m_1 * ... * h_L^m_L, and
+// proves the knowledge of e, r2, r3 = 1/r1, s' = s - r2 * r3 and the hidden messages such
+// that:
+//
+//	Abar / d = A'^(-e) * h_0^r2
+//	g1 * prod_{i disclosed} h_i^m_i = d^r3 * h_0^(-s') * prod_{i hidden} h_i^(-m_i)
+//
+// T1 and T2 are the commitments of this proof.
+type ProofRandomData struct {
+	APrime *bn256.G1
+	ABar   *bn256.G1
+	D      *bn256.G1
+	T1     *bn256.G1
+	T2     *bn256.G1
+}
+
+// ProofData holds the responses of the proof, M maps the indices of the hidden messages
+// to the corresponding responses.
+type ProofData struct {
+	E  *big.Int
+	R2 *big.Int
+	R3 *big.Int
+	S  *big.Int
+	M  map[int]*big.Int
+}
+
+// Proof is the non-interactive proof of possession of a signature (see
+// ProveSelectiveDisclosure).
+type Proof struct {
+	APrime    *bn256.G1
+	ABar      *bn256.G1
+	D         *bn256.G1
+	Challenge *big.Int
+	Responses *ProofData
+}
+
+// ProveSelectiveDisclosure returns the proof that the holder possesses a signature of
+// messages in which the messages at indices disclosed have the values known to
+// the verifier. The challenge is computed with the Fiat-Shamir heuristic - nonce should be
+// chosen by the verifier (or include a context such as the time) so that the proof cannot
+// be replayed.
+func ProveSelectiveDisclosure(pubKey *PubKey, messages []*big.Int, signature *Signature,
+	disclosed []int, nonce []byte) (*Proof, error) {
+	prover, err := NewProver(pubKey, messages, signature, disclosed)
+	if err != nil {
+		return nil, err
+	}
+	data, err := prover.GetProofRandomData()
+	if err != nil {
+		return nil, err
+	}
+	c := fiatShamirChallenge(pubKey, data, prover.disclosedMessages(), nonce)
+	return &Proof{
+		APrime:    data.APrime,
+		ABar:      data.ABar,
+		D:         data.D,
+		Challenge: c,
+		Responses: prover.GetProofData(c),
+	}, nil
+}
+
+// VerifySelectiveDisclosure verifies the proof produced by ProveSelectiveDisclosure, where
+// disclosed maps the indices of the disclosed messages to their values.
+func VerifySelectiveDisclosure(pubKey *PubKey, disclosed map[int]*big.Int, nonce []byte,
+	proof *Proof) bool {
+	verifier, err := NewVerifier(pubKey, disclosed)
+	if err != nil || proof == nil || proof.Challenge == nil {
+		return false
+	}
+	data := &ProofRandomData{
+		APrime: proof.APrime,
+		ABar:   proof.ABar,
+		D:      proof.D,
+	}
+	if !verifier.checkRandomizedSignature(data) {
+		return false
+	}
+	data.T1, data.T2 = verifier.commitments(data, proof.Challenge, proof.Responses)
+	if data.T1 == nil {
+		return false
+	}
+	return fiatShamirChallenge(pubKey, data, disclosed, nonce).Cmp(proof.Challenge) == 0
+}
+
+type Prover struct {
+	pubKey    *PubKey
+	messages  []*big.Int
+	signature *Signature
+	disclosed []int
+	hidden    []int
+	// secrets of the proof and the corresponding randomness
+	e, r2, r3, s                 *big.Int
+	randE, randR2, randR3, randS *big.Int
+	randM                        map[int]*big.Int
+}
+
+// NewProver returns the prover of the possession of signature of messages which discloses
+// the messages at indices disclosed.
+func NewProver(pubKey *PubKey, messages []*big.Int, signature *Signature,
+	disclosed []int) (*Prover, error) {
+	if !Verify(pubKey, messages, signature) {
+		return nil, fmt.Errorf("the signature is not valid")
+	}
+	hidden, err := hiddenIndices(len(messages), disclosed)
+	if err != nil {
+		return nil, err
+	}
+	return &Prover{
+		pubKey:    pubKey,
+		messages:  messages,
+		signature: signature,
+		disclosed: disclosed,
+		hidden:    hidden,
+	}, nil
+}
+
+// GetProofRandomData randomizes the signature and returns it together with the commitments
+// of the proof.
+func (prover *Prover) GetProofRandomData() (*ProofRandomData, error) {
+	params := prover.pubKey.Params
+	r1, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+	prover.r2, err = randomScalar()
+	if err != nil {
+		return nil, err
+	}
+	prover.r3 = new(big.Int).ModInverse(r1, bn256.Order)
+	prover.e = prover.signature.E
+	// s' = s - r2 * r3
+	prover.s = new(big.Int).Mul(prover.r2, prover.r3)
+	prover.s = mod(prover.s.Sub(prover.signature.S, prover.s))
+
+	b := prover.pubKey.commitment(prover.messages, prover.signature.S)
+	aPrime := new(bn256.G1).ScalarMult(prover.signature.A, r1)
+	bR1 := new(bn256.G1).ScalarMult(b, r1)
+	aBar := new(bn256.G1).ScalarMult(aPrime, mod(new(big.Int).Neg(prover.e)))
+	aBar.Add(aBar, bR1)
+	d := new(bn256.G1).ScalarMult(params.H0, mod(new(big.Int).Neg(prover.r2)))
+	d.Add(d, bR1)
+
+	prover.randE, err = randomScalar()
+	if err != nil {
+		return nil, err
+	}
+	prover.randR2, err = randomScalar()
+	if err != nil {
+		return nil, err
+	}
+	prover.randR3, err = randomScalar()
+	if err != nil {
+		return nil, err
+	}
+	prover.randS, err = randomScalar()
+	if err != nil {
+		return nil, err
+	}
+	prover.randM = make(map[int]*big.Int, len(prover.hidden))
+	for _, i := range prover.hidden {
+		prover.randM[i], err = randomScalar()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data := &ProofRandomData{
+		APrime: aPrime,
+		ABar:   aBar,
+		D:      d,
+	}
+	data.T1, data.T2 = exponents(data, params, prover.randE, prover.randR2, prover.randR3,
+		prover.randS, prover.randM)
+	return data, nil
+}
+
+// GetProofData returns the responses z = r + challenge * secret for each secret of
+// the proof.
+func (prover *Prover) GetProofData(challenge *big.Int) *ProofData {
+	response := func(r, secret *big.Int) *big.Int {
+		z := new(big.Int).Mul(challenge, secret)
+		return mod(z.Add(z, r))
+	}
+	m := make(map[int]*big.Int, len(prover.hidden))
+	for _, i := range prover.hidden {
+		m[i] = response(prover.randM[i], prover.messages[i])
+	}
+	return &ProofData{
+		E:  response(prover.randE, prover.e),
+		R2: response(prover.randR2, prover.r2),
+		R3: response(prover.randR3, prover.r3),
+		S:  response(prover.randS, prover.s),
+		M:  m,
+	}
+}
+
+func (prover *Prover) disclosedMessages() map[int]*big.Int {
+	disclosed := make(map[int]*big.Int, len(prover.disclosed))
+	for _, i := range prover.disclosed {
+		disclosed[i] = prover.messages[i]
+	}
+	return disclosed
+}
+
+type Verifier struct {
+	pubKey    *PubKey
+	disclosed map[int]*big.Int
+	hidden    []int
+	data      *ProofRandomData
+	challenge *big.Int
+}
+
+// NewVerifier returns the verifier of the possession of a signature in which the messages
+// at the indices of disclosed have the given values.
+func NewVerifier(pubKey *PubKey, disclosed map[int]*big.Int) (*Verifier, error) {
+	indices := make([]int, 0, len(disclosed))
+	for i, m := range disclosed {
+		if m == nil {
+			return nil, fmt.Errorf("message %d is missing", i)
+		}
+		indices = append(indices, i)
+	}
+	hidden, err := hiddenIndices(len(pubKey.Params.H), indices)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{
+		pubKey:    pubKey,
+		disclosed: disclosed,
+		hidden:    hidden,
+	}, nil
+}
+
+// GetChallenge stores the randomized signature and the commitments and returns a random
+// challenge.
+func (verifier *Verifier) GetChallenge(data *ProofRandomData) (*big.Int, error) {
+	verifier.data = data
+	challenge, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return verifier.challenge, nil
+}
+
+// Verify returns true if the randomized signature is valid and proofData are correct
+// responses to the challenge.
+func (verifier *Verifier) Verify(proofData *ProofData) bool {
+	data := verifier.data
+	if data == nil || data.T1 == nil || data.T2 == nil ||
+		!verifier.checkRandomizedSignature(data) {
+		return false
+	}
+	t1, t2 := verifier.commitments(data, verifier.challenge, proofData)
+	return t1 != nil && equalG1(t1, data.T1) && equalG1(t2, data.T2)
+}
+
+// checkRandomizedSignature checks that A' is not the identity and that e(A', w) =
+// e(Abar, g2), which means that Abar = A'^x.
+func (verifier *Verifier) checkRandomizedSignature(data *ProofRandomData) bool {
+	if data.APrime == nil || data.ABar == nil || data.D == nil || isIdentity(data.APrime) {
+		return false
+	}
+	return equalGT(bn256.Pair(data.APrime, verifier.pubKey.W), bn256.Pair(data.ABar, g2))
+}
+
+// commitments returns the commitments which are determined by the responses and
+// the challenge:
+//
+//	T1 = A'^(-z_e) * h_0^z_r2 * (Abar / d)^(-c)
+//	T2 = d^z_r3 * h_0^(-z_s) * prod_{i hidden} h_i^(-z_m_i) *
+//	  (g1 * prod_{i disclosed} h_i^m_i)^(-c)
+//
+// It returns nils if the responses are not well formed.
+func (verifier *Verifier) commitments(data *ProofRandomData, challenge *big.Int,
+	proofData *ProofData) (*bn256.G1, *bn256.G1) {
+	if proofData == nil || proofData.E == nil || proofData.R2 == nil ||
+		proofData.R3 == nil || proofData.S == nil || len(proofData.M) != len(verifier.hidden) {
+		return nil, nil
+	}
+	for _, i := range verifier.hidden {
+		if proofData.M[i] == nil {
+			return nil, nil
+		}
+	}
+	params := verifier.pubKey.Params
+	t1, t2 := exponents(data, params, proofData.E, proofData.R2, proofData.R3, proofData.S,
+		proofData.M)
+
+	negC := mod(new(big.Int).Neg(challenge))
+	x1 := new(bn256.G1).Neg(data.D)
+	x1.Add(x1, data.ABar)
+	t1.Add(t1, x1.ScalarMult(x1, negC))
+
+	x2 := new(bn256.G1).ScalarBaseMult(big.NewInt(1))
+	for i, m := range verifier.disclosed {
+		x2.Add(x2, new(bn256.G1).ScalarMult(params.H[i], mod(m)))
+	}
+	t2.Add(t2, x2.ScalarMult(x2, negC))
+	return t1, t2
+}
+
+// exponents returns A'^(-e) * h_0^r2 and d^r3 * h_0^(-s) * prod_{i in m} h_i^(-m_i).
+func exponents(data *ProofRandomData, params *Params, e, r2, r3, s *big.Int,
+	m map[int]*big.Int) (*bn256.G1, *bn256.G1) {
+	t1 := new(bn256.G1).ScalarMult(data.APrime, mod(new(big.Int).Neg(e)))
+	t1.Add(t1, new(bn256.G1).ScalarMult(params.H0, mod(r2)))
+
+	t2 := new(bn256.G1).ScalarMult(data.D, mod(r3))
+	t2.Add(t2, new(bn256.G1).ScalarMult(params.H0, mod(new(big.Int).Neg(s))))
+	for i, mi := range m {
+		t2.Add(t2, new(bn256.G1).ScalarMult(params.H[i], mod(new(big.Int).Neg(mi))))
+	}
+	return t1, t2
+}
+
+// hiddenIndices checks that the disclosed indices are distinct indices of the messages and
+// returns the remaining indices.
+func hiddenIndices(numOfMessages int, disclosed []int) ([]int, error) {
+	isDisclosed := make([]bool, numOfMessages)
+	for _, i := range disclosed {
+		if i < 0 || i >= numOfMessages {
+			return nil, fmt.Errorf("index %d out of range [0, %d)", i, numOfMessages)
+		}
+		if isDisclosed[i] {
+			return nil, fmt.Errorf("index %d disclosed twice", i)
+		}
+		isDisclosed[i] = true
+	}
+	var hidden []int
+	for i, d := range isDisclosed {
+		if !d {
+			hidden = append(hidden, i)
+		}
+	}
+	return hidden, nil
+}
+
+// fiatShamirChallenge hashes the public key, the proof random data, the disclosed messages
+// (ordered by their indices) and nonce. Each value is prefixed with its length.
+func fiatShamirChallenge(pubKey *PubKey, data *ProofRandomData, disclosed map[int]*big.Int,
+	nonce []byte) *big.Int {
+	indices := make([]int, 0, len(disclosed))
+	for i := range disclosed {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+
+	values := [][]byte{pubKey.W.Marshal(), data.APrime.Marshal(), data.ABar.Marshal(),
+		data.D.Marshal(), data.T1.Marshal(), data.T2.Marshal()}
+	for _, i := range indices {
+		values = append(values, big.NewInt(int64(i)).Bytes(), mod(disclosed[i]).Bytes())
+	}
+	values = append(values, nonce)
+
+	h := sha512.New()
+	length := make([]byte, 8)
+	binary.BigEndian.PutUint64(length, uint64(len(pubKey.Params.H)))
+	h.Write(length)
+	for _, v := range values {
+		binary.BigEndian.PutUint64(length, uint64(len(v)))
+		h.Write(length)
+		h.Write(v)
+	}
+	return mod(new(big.Int).SetBytes(h.Sum(nil)))
+}
@@ -0,0 +1,237 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package blindschnorr implements blind Schnorr signatures in Schnorr groups. The signer
+// signs a message without seeing it, and the signature cannot be linked to the issuance
+// session in which it was obtained - the signed messages can thus be used as unlinkable
+// tokens (for example a random serial number which is signed once and spent once).
+//
+// Issuance is a three-move protocol:
+//
+//	signer -> user: R = g^k (Signer.NewSession, SignerSession.GetCommitment)
+//	user -> signer: c = H(R * g^alpha * y^beta, y, m) + beta (User.Blind)
+//	signer -> user: s = k + c * x (SignerSession.GetResponse)
+//
+// and the user obtains the signature (c - beta, s + alpha) of m with User.Unblind.
+//
+// Note that blind Schnorr signatures are only secure when the number of concurrent issuance
+// sessions is small - with many open sessions the user can obtain more signatures than
+// sessions (ROS attack, Benhamouda et al. 2021). The signer should thus limit the number
+// of open sessions (for example by the admission control of the server).
+package blindschnorr
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+	"sync"
+)
+
+type PublicKey struct {
+	Group *groups.SchnorrGroup
+	Y     *big.Int // g^x
+}
+
+type Signature struct {
+	C *big.Int
+	S *big.Int
+}
+
+// Verify returns true if signature is a valid signature of message, which means that
+// C = H(g^S * y^(-C), y, message).
+func Verify(publicKey *PublicKey, message []byte, signature *Signature) bool {
+	if signature == nil || signature.C == nil || signature.S == nil {
+		return false
+	}
+	group := publicKey.Group
+	r := common.MultiExp([]*big.Int{group.G, publicKey.Y},
+		[]*big.Int{signature.S, new(big.Int).Neg(signature.C)}, group.P)
+	if r == nil {
+		return false
+	}
+	return challenge(publicKey, message, r).Cmp(signature.C) == 0
+}
+
+// challenge returns H(elements, y, message) reduced modulo the order of the group. Each
+// value is prefixed with its length, thus different inputs cannot produce the same hashed
+// string.
+func challenge(publicKey *PublicKey, message []byte, elements ...*big.Int) *big.Int {
+	values := [][]byte{publicKey.Group.G.Bytes(), publicKey.Y.Bytes()}
+	for _, el := range elements {
+		values = append(values, el.Bytes())
+	}
+	h := sha512.New()
+	for _, b := range append(values, message) {
+		l := make([]byte, 8)
+		binary.BigEndian.PutUint64(l, uint64(len(b)))
+		h.Write(l)
+		h.Write(b)
+	}
+	c := new(big.Int).SetBytes(h.Sum(nil))
+	return c.Mod(c, publicKey.Group.Q)
+}
+
+var errNoCommitment = errors.New("blindschnorr: the response needs to be preceded by " +
+	"the commitment, each commitment can be used only once")
+
+type Signer struct {
+	publicKey *PublicKey
+	secretKey *big.Int
+}
+
+// NewSigner returns the signer with a new random key.
+func NewSigner(group *groups.SchnorrGroup) (*Signer, error) {
+	secretKey, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, err
+	}
+	return NewSignerFromSecretKey(group, secretKey), nil
+}
+
+func NewSignerFromSecretKey(group *groups.SchnorrGroup, secretKey *big.Int) *Signer {
+	return &Signer{
+		publicKey: &PublicKey{
+			Group: group,
+			Y:     group.Exp(group.G, secretKey),
+		},
+		secretKey: secretKey,
+	}
+}
+
+func (signer *Signer) GetPublicKey() *PublicKey {
+	return signer.publicKey
+}
+
+// NewSession starts the issuance of a signature.
+func (signer *Signer) NewSession() *SignerSession {
+	return &SignerSession{
+		signer: signer,
+	}
+}
+
+// SignerSession is the signer's side of one issuance.
+type SignerSession struct {
+	signer *Signer
+	k      *big.Int
+	mutex  sync.Mutex
+}
+
+// GetCommitment returns R = g^k where k is random.
+func (session *SignerSession) GetCommitment() (*big.Int, error) {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	group := session.signer.publicKey.Group
+	k, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, err
+	}
+	session.k = k
+	return group.Exp(group.G, session.k), nil
+}
+
+// GetResponse returns s = k + c * x for the blinded challenge c. Each commitment can be used
+// for a single response only, as two responses for the same k reveal the secret key.
+func (session *SignerSession) GetResponse(c *big.Int) (*big.Int, error) {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	if session.k == nil {
+		return nil, errNoCommitment
+	}
+	group := session.signer.publicKey.Group
+	s := new(big.Int).Mod(c, group.Q)
+	s.Mul(s, session.signer.secretKey)
+	s.Add(s, session.k)
+	s.Mod(s, group.Q)
+	session.k = nil
+	return s, nil
+}
+
+// User obtains a blind signature of the message.
+type User struct {
+	publicKey *PublicKey
+	message   []byte
+	alpha     *big.Int
+	beta      *big.Int
+	r         *big.Int // commitment of the signer
+	c         *big.Int // blinded challenge
+	mutex     sync.Mutex
+}
+
+func NewUser(publicKey *PublicKey, message []byte) *User {
+	return &User{
+		publicKey: publicKey,
+		message:   message,
+	}
+}
+
+// Blind returns the blinded challenge for the signer's commitment r.
+func (user *User) Blind(r *big.Int) (*big.Int, error) {
+	user.mutex.Lock()
+	defer user.mutex.Unlock()
+	group := user.publicKey.Group
+	if !group.IsElementInGroup(r) {
+		return nil, errors.New("blindschnorr: commitment is not in the group")
+	}
+
+	alpha, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, err
+	}
+	user.alpha = alpha
+	beta, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, err
+	}
+	user.beta = beta
+	// r' = r * g^alpha * y^beta
+	blinded := common.MultiExp([]*big.Int{r, group.G, user.publicKey.Y},
+		[]*big.Int{big.NewInt(1), user.alpha, user.beta}, group.P)
+	c := challenge(user.publicKey, user.message, blinded)
+	user.r = r
+	user.c = new(big.Int).Add(c, user.beta)
+	user.c.Mod(user.c, group.Q)
+	return user.c, nil
+}
+
+// Unblind checks the signer's response s (g^s = r * y^c) and returns the signature
+// of the message.
+func (user *User) Unblind(s *big.Int) (*Signature, error) {
+	user.mutex.Lock()
+	defer user.mutex.Unlock()
+	if user.c == nil {
+		return nil, errors.New("blindschnorr: the challenge needs to be blinded first")
+	}
+	group := user.publicKey.Group
+	r := common.MultiExp([]*big.Int{group.G, user.publicKey.Y},
+		[]*big.Int{s, new(big.Int).Neg(user.c)}, group.P)
+	if r == nil || r.Cmp(user.r) != 0 {
+		return nil, errors.New("blindschnorr: response of the signer is not valid")
+	}
+
+	c := new(big.Int).Sub(user.c, user.beta)
+	c.Mod(c, group.Q)
+	signature := &Signature{
+		C: c,
+		S: new(big.Int).Add(s, user.alpha),
+	}
+	signature.S.Mod(signature.S, group.Q)
+	user.alpha, user.beta, user.r, user.c = nil, nil, nil, nil
+	return signature, nil
+}
@@ -0,0 +1,244 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package blindschnorr
+
+import (
+	"crypto/sha512"
+	"errors"
+	"github.com/xlab-si/emmy/crypto/common"
+	"math/big"
+	"sync"
+)
+
+// Partially blind signatures (Abe, Okamoto: Provably secure partially blind signatures,
+// CRYPTO 2000) embed the public information (for example the expiry date or the type of
+// the credential) which is agreed by the signer and the user, while the message remains
+// hidden. The information is bound to the signature through z = F(y, info) - the user
+// cannot remove it or replace it with other information. Issuance is a three-move protocol:
+//
+//	signer -> user: a = g^u, b = g^s * z^d (PartiallyBlindSession.GetCommitment)
+//	user -> signer: e = H(a * g^t1 * y^t2, b * g^t3 * z^t4, z, y, m) - t2 - t4
+//	                (PartiallyBlindUser.Blind)
+//	signer -> user: c = e - d, r = u - c * x, s, d (PartiallyBlindSession.GetResponse)
+//
+// and the user obtains the signature (r + t1, c + t2, s + t3, d + t4) with
+// PartiallyBlindUser.Unblind. The note about concurrent sessions of blind signatures
+// applies to partially blind signatures as well.
+
+type PartiallyBlindSignature struct {
+	Info  []byte
+	Rho   *big.Int
+	Omega *big.Int
+	Sigma *big.Int
+	Delta *big.Int
+}
+
+// PartiallyBlindResponse is the signer's response to the blinded challenge.
+type PartiallyBlindResponse struct {
+	R *big.Int
+	C *big.Int
+	S *big.Int
+	D *big.Int
+}
+
+// VerifyPartiallyBlind returns true if signature is a valid partially blind signature of
+// message with the public information signature.Info, which means that
+// Omega + Delta = H(g^Rho * y^Omega, g^Sigma * z^Delta, z, y, message) where z = F(y, Info).
+func VerifyPartiallyBlind(publicKey *PublicKey, message []byte,
+	signature *PartiallyBlindSignature) bool {
+	if signature == nil || signature.Rho == nil || signature.Omega == nil ||
+		signature.Sigma == nil || signature.Delta == nil {
+		return false
+	}
+	group := publicKey.Group
+	z := infoElement(publicKey, signature.Info)
+	alpha := common.MultiExp([]*big.Int{group.G, publicKey.Y},
+		[]*big.Int{signature.Rho, signature.Omega}, group.P)
+	beta := common.MultiExp([]*big.Int{group.G, z},
+		[]*big.Int{signature.Sigma, signature.Delta}, group.P)
+	if alpha == nil || beta == nil {
+		return false
+	}
+	sum := new(big.Int).Add(signature.Omega, signature.Delta)
+	sum.Mod(sum, group.Q)
+	return challenge(publicKey, message, alpha, beta, z).Cmp(sum) == 0
+}
+
+// infoElement returns z = F(y, info), an element of the group whose dlog is not known.
+func infoElement(publicKey *PublicKey, info []byte) *big.Int {
+	h := sha512.Sum512(info)
+	return publicKey.Group.HashIntoElement(publicKey.Y, new(big.Int).SetBytes(h[:]))
+}
+
+// NewPartiallyBlindSession starts the issuance of a partially blind signature with
+// the public information info.
+func (signer *Signer) NewPartiallyBlindSession(info []byte) *PartiallyBlindSession {
+	return &PartiallyBlindSession{
+		signer: signer,
+		z:      infoElement(signer.publicKey, info),
+	}
+}
+
+// PartiallyBlindSession is the signer's side of one issuance of a partially blind signature.
+type PartiallyBlindSession struct {
+	signer *Signer
+	z      *big.Int
+	u      *big.Int
+	s      *big.Int
+	d      *big.Int
+	mutex  sync.Mutex
+}
+
+// GetCommitment returns a = g^u and b = g^s * z^d where u, s and d are random.
+func (session *PartiallyBlindSession) GetCommitment() (*big.Int, *big.Int, error) {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	group := session.signer.publicKey.Group
+	u, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, nil, err
+	}
+	session.u = u
+	s, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, nil, err
+	}
+	session.s = s
+	d, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, nil, err
+	}
+	session.d = d
+	a := group.Exp(group.G, session.u)
+	b := common.MultiExp([]*big.Int{group.G, session.z},
+		[]*big.Int{session.s, session.d}, group.P)
+	return a, b, nil
+}
+
+// GetResponse returns c = e - d, r = u - c * x, s and d for the blinded challenge e.
+// Each commitment can be used for a single response only.
+func (session *PartiallyBlindSession) GetResponse(e *big.Int) (*PartiallyBlindResponse,
+	error) {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	if session.u == nil {
+		return nil, errNoCommitment
+	}
+	group := session.signer.publicKey.Group
+	c := new(big.Int).Sub(e, session.d)
+	c.Mod(c, group.Q)
+	r := new(big.Int).Mul(c, session.signer.secretKey)
+	r.Sub(session.u, r)
+	r.Mod(r, group.Q)
+	resp := &PartiallyBlindResponse{
+		R: r,
+		C: c,
+		S: session.s,
+		D: session.d,
+	}
+	session.u, session.s, session.d = nil, nil, nil
+	return resp, nil
+}
+
+// PartiallyBlindUser obtains a partially blind signature of the message with the public
+// information agreed with the signer.
+type PartiallyBlindUser struct {
+	publicKey *PublicKey
+	info      []byte
+	message   []byte
+	z         *big.Int
+	t         []*big.Int // t1, t2, t3, t4
+	a         *big.Int
+	b         *big.Int
+	e         *big.Int
+	mutex     sync.Mutex
+}
+
+func NewPartiallyBlindUser(publicKey *PublicKey, info, message []byte) *PartiallyBlindUser {
+	return &PartiallyBlindUser{
+		publicKey: publicKey,
+		info:      info,
+		message:   message,
+		z:         infoElement(publicKey, info),
+	}
+}
+
+// Blind returns the blinded challenge for the signer's commitment (a, b).
+func (user *PartiallyBlindUser) Blind(a, b *big.Int) (*big.Int, error) {
+	user.mutex.Lock()
+	defer user.mutex.Unlock()
+	group := user.publicKey.Group
+	if !group.IsElementInGroup(a) || !group.IsElementInGroup(b) {
+		return nil, errors.New("blindschnorr: commitment is not in the group")
+	}
+
+	t, err := common.GetRandomInts(4, group.Q)
+	if err != nil {
+		return nil, err
+	}
+	// alpha = a * g^t1 * y^t2, beta = b * g^t3 * z^t4
+	alpha := common.MultiExp([]*big.Int{a, group.G, user.publicKey.Y},
+		[]*big.Int{big.NewInt(1), t[0], t[1]}, group.P)
+	beta := common.MultiExp([]*big.Int{b, group.G, user.z},
+		[]*big.Int{big.NewInt(1), t[2], t[3]}, group.P)
+	epsilon := challenge(user.publicKey, user.message, alpha, beta, user.z)
+
+	e := new(big.Int).Sub(epsilon, t[1])
+	e.Sub(e, t[3])
+	e.Mod(e, group.Q)
+	user.t, user.a, user.b, user.e = t, a, b, e
+	return e, nil
+}
+
+// Unblind checks the signer's response (c + d = e, a = g^r * y^c and b = g^s * z^d) and
+// returns the signature of the message.
+func (user *PartiallyBlindUser) Unblind(resp *PartiallyBlindResponse) (
+	*PartiallyBlindSignature, error) {
+	user.mutex.Lock()
+	defer user.mutex.Unlock()
+	if user.e == nil {
+		return nil, errors.New("blindschnorr: the challenge needs to be blinded first")
+	}
+	group := user.publicKey.Group
+	if resp == nil || resp.R == nil || resp.C == nil || resp.S == nil || resp.D == nil {
+		return nil, errors.New("blindschnorr: response of the signer is not complete")
+	}
+	sum := new(big.Int).Add(resp.C, resp.D)
+	a := common.MultiExp([]*big.Int{group.G, user.publicKey.Y},
+		[]*big.Int{resp.R, resp.C}, group.P)
+	b := common.MultiExp([]*big.Int{group.G, user.z}, []*big.Int{resp.S, resp.D}, group.P)
+	if sum.Mod(sum, group.Q).Cmp(user.e) != 0 || a == nil || a.Cmp(user.a) != 0 ||
+		b == nil || b.Cmp(user.b) != 0 {
+		return nil, errors.New("blindschnorr: response of the signer is not valid")
+	}
+
+	t := user.t
+	signature := &PartiallyBlindSignature{
+		Info:  user.info,
+		Rho:   new(big.Int).Add(resp.R, t[0]),
+		Omega: new(big.Int).Add(resp.C, t[1]),
+		Sigma: new(big.Int).Add(resp.S, t[2]),
+		Delta: new(big.Int).Add(resp.D, t[3]),
+	}
+	for _, v := range []*big.Int{signature.Rho, signature.Omega, signature.Sigma,
+		signature.Delta} {
+		v.Mod(v, group.Q)
+	}
+	user.t, user.a, user.b, user.e = nil, nil, nil, nil
+	return signature, nil
+}
@@ -18,10 +18,8 @@
 package signatures
 
 import (
-	"crypto/rand"
 	"errors"
 	"github.com/xlab-si/emmy/crypto/common"
-	"log"
 	"math/big"
 )
 
@@ -40,10 +38,26 @@ type CLSignature struct {
 	v *big.Int
 }
 
+// NewCLSignature returns the signature (e, s, v), for example one which was received from
+// the issuer. It needs to be checked with CL.Verify.
+func NewCLSignature(e, s, v *big.Int) *CLSignature {
+	return &CLSignature{
+		e: e,
+		s: s,
+		v: v,
+	}
+}
+
 type CLConfig struct {
 	l_n int
 	l_m int
 	l   int
+	// e is a prime from [2^(l_e-1), 2^(l_e-1) + 2^(l_e_prime-1)) - the interval is narrow,
+	// so that the proofs of signature possession can bound e' = e - 2^(l_e-1) (as in Idemix).
+	// l_e is larger than the bound of e' in the proofs (l_e_prime + 2*l + 1), thus
+	// the verifier knows that the prover uses e > 2^(l_m+1).
+	l_e       int
+	l_e_prime int
 }
 
 type CLPubKey struct {
@@ -53,11 +67,33 @@ type CLPubKey struct {
 	c   *big.Int
 }
 
+// GetN returns the modulus n.
+func (pubKey *CLPubKey) GetN() *big.Int {
+	return pubKey.n
+}
+
+// GetA returns the bases a_1, ..., a_L of the message blocks.
+func (pubKey *CLPubKey) GetA() []*big.Int {
+	return pubKey.a_L
+}
+
+// GetB returns the base b of s.
+func (pubKey *CLPubKey) GetB() *big.Int {
+	return pubKey.b
+}
+
+// GetC returns c (v^e = a_1^m_1 * ... * a_L^m_L * b^s * c for a valid signature).
+func (pubKey *CLPubKey) GetC() *big.Int {
+	return pubKey.c
+}
+
 func NewCL(numOfBlocks int) *CL {
 	config := CLConfig{
-		l_n: 1024,
-		l_m: 160,
-		l:   160,
+		l_n:       1024,
+		l_m:       160,
+		l:         160,
+		l_e:       597,
+		l_e_prime: 120,
 	}
 
 	cl := CL{
@@ -70,9 +106,11 @@ func NewCL(numOfBlocks int) *CL {
 
 func NewPubCL(pubKey *CLPubKey) *CL {
 	config := CLConfig{
-		l_n: 1024,
-		l_m: 160,
-		l:   160,
+		l_n:       1024,
+		l_m:       160,
+		l:         160,
+		l_e:       597,
+		l_e_prime: 120,
 	}
 
 	cl := CL{
@@ -83,23 +121,32 @@ func NewPubCL(pubKey *CLPubKey) *CL {
 	return &cl
 }
 
-func (cl *CL) getQuadraticResidues(n *big.Int) ([]*big.Int, *big.Int, *big.Int) {
+func (cl *CL) getQuadraticResidues(n *big.Int) ([]*big.Int, *big.Int, *big.Int, error) {
 	var a_L []*big.Int
 	for i := 0; i < cl.numOfBlocks; i++ {
-		aRoot := common.GetRandomInt(n)
+		aRoot, err := common.GetRandomInt(n)
+		if err != nil {
+			return nil, nil, nil, err
+		}
 		a := new(big.Int).Mul(aRoot, aRoot)
 		a.Mod(a, n)
 		a_L = append(a_L, a)
 	}
 
-	bRoot := common.GetRandomInt(n)
-	cRoot := common.GetRandomInt(n)
+	bRoot, err := common.GetRandomInt(n)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cRoot, err := common.GetRandomInt(n)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	b := new(big.Int).Mul(bRoot, bRoot)
 	b.Mod(b, n)
 	c := new(big.Int).Mul(cRoot, cRoot)
 	c.Mod(c, n)
 
-	return a_L, b, c
+	return a_L, b, c, nil
 }
 
 func (cl *CL) Sign(m_Ls []*big.Int) (*CLSignature, error) {
@@ -116,23 +163,25 @@ func (cl *CL) Sign(m_Ls []*big.Int) (*CLSignature, error) {
 		}
 	}
 
-	// choose a random prime number e > 2^(l_m+1) of length l_e = l_m + 2
-	// that means: 2^(l_m+1) < e < 2^(l_m+2)
-	e, _ := rand.Prime(rand.Reader, cl.config.l_m+2)
-
-	b1 := e.Cmp(new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(cl.config.l_m+1)), nil))
-	b2 := e.Cmp(new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(cl.config.l_m+2)), nil))
-	if (b1 != 1) || (b2 != -1) {
-		log.Panic("parameter not properly chosen")
+	// choose a random prime number e > 2^(l_m+1) from the interval
+	// [2^(l_e-1), 2^(l_e-1) + 2^(l_e_prime-1))
+	e, err := cl.getRandomE()
+	if err != nil {
+		return nil, err
 	}
 
-	s := common.GetRandomIntOfLength(cl.config.l_n + cl.config.l_m + cl.config.l)
+	s, err := common.GetRandomIntOfLength(cl.config.l_n + cl.config.l_m + cl.config.l)
+	if err != nil {
+		return nil, err
+	}
 
 	// v^e = a_1^m_1 * ... * a_L^m_L * b^s * c % n
 
-	a := new(big.Int)
+	a := big.NewInt(1)
 	for i := 0; i < cl.numOfBlocks; i++ {
-		a = new(big.Int).Exp(cl.pubKey.a_L[i], m_Ls[i], cl.pubKey.n)
+		t := new(big.Int).Exp(cl.pubKey.a_L[i], m_Ls[i], cl.pubKey.n)
+		a.Mul(a, t)
+		a.Mod(a, cl.pubKey.n)
 	}
 
 	t2 := new(big.Int).Exp(cl.pubKey.b, s, cl.pubKey.n) // b^s (mod n)
@@ -172,20 +221,18 @@ func (cl *CL) Verify(m_Ls []*big.Int, signature *CLSignature) (bool, error) {
 	}
 
 	// check v^e = a^m*b^s*c (mod n)
-	// and check: 2^l_e - 1 < e < 2^l_e, where l_e = l_m + 2
-
-	/*
-		b1 := signature.e.Cmp(new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(cl.config.l_m+1)), nil))
-		b2 := signature.e.Cmp(new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(cl.config.l_m+2)), nil))
-		if (b1 != 1) || (b2 != -1) {
-			return false, nil
-		}
-	*/
+	// and check: 2^(l_e-1) <= e < 2^(l_e-1) + 2^(l_e_prime-1) - without the bounds
+	// anybody could sign with e = 1
+	if signature.e == nil || !cl.config.isValidE(signature.e) {
+		return false, nil
+	}
 
 	numOfBlocks := len(m_Ls)
-	a := new(big.Int)
+	a := big.NewInt(1)
 	for i := 0; i < numOfBlocks; i++ {
-		a = new(big.Int).Exp(cl.pubKey.a_L[i], m_Ls[i], cl.pubKey.n)
+		t := new(big.Int).Exp(cl.pubKey.a_L[i], m_Ls[i], cl.pubKey.n)
+		a.Mul(a, t)
+		a.Mod(a, cl.pubKey.n)
 	}
 
 	t2 := new(big.Int).Exp(cl.pubKey.b, signature.s, cl.pubKey.n) // b^s
@@ -203,6 +250,33 @@ func (cl *CL) Verify(m_Ls []*big.Int, signature *CLSignature) (bool, error) {
 	}
 }
 
+// getRandomE returns a random prime from [2^(l_e-1), 2^(l_e-1) + 2^(l_e_prime-1)).
+func (cl *CL) getRandomE() (*big.Int, error) {
+	offset := cl.config.getEOffset()
+	for {
+		e, err := common.GetRandomInt(new(big.Int).Lsh(big.NewInt(1),
+			uint(cl.config.l_e_prime-1)))
+		if err != nil {
+			return nil, err
+		}
+		e.Add(e, offset)
+		if e.ProbablyPrime(20) {
+			return e, nil
+		}
+	}
+}
+
+// getEOffset returns 2^(l_e-1), the lower bound of e.
+func (config *CLConfig) getEOffset() *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(config.l_e-1))
+}
+
+// isValidE checks that 2^(l_e-1) <= e < 2^(l_e-1) + 2^(l_e_prime-1).
+func (config *CLConfig) isValidE(e *big.Int) bool {
+	ePrime := new(big.Int).Sub(e, config.getEOffset())
+	return ePrime.Sign() >= 0 && ePrime.BitLen() < config.l_e_prime
+}
+
 func (cl *CL) GetPubKey() *CLPubKey {
 	return cl.pubKey
 }
@@ -215,7 +289,10 @@ func (cl *CL) generateKey() (err error) {
 		return err
 	}
 	n := new(big.Int).Mul(p, q)
-	a_L, b, c := cl.getQuadraticResidues(n)
+	a_L, b, c, err := cl.getQuadraticResidues(n)
+	if err != nil {
+		return err
+	}
 	cl.p = p
 	cl.q = q
 	cl.pubKey = &CLPubKey{
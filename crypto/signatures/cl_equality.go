@@ -0,0 +1,275 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package signatures
+
+import (
+	"errors"
+	"github.com/xlab-si/emmy/crypto/common"
+	"math/big"
+)
+
+// ProveCLAttributeEquality demonstrates how the holder of two CL signatures (possibly issued
+// by different issuers) proves that the block at index1 of the first signed message is the same
+// as the block at index2 of the second one, without revealing the value, the other blocks or
+// the signatures.
+func ProveCLAttributeEquality(pubKey1 *CLPubKey, m_Ls1 []*big.Int, signature1 *CLSignature,
+	index1 int, pubKey2 *CLPubKey, m_Ls2 []*big.Int, signature2 *CLSignature,
+	index2 int) (bool, error) {
+	prover, err := NewCLEqualityProver(pubKey1, m_Ls1, signature1, index1, pubKey2, m_Ls2,
+		signature2, index2)
+	if err != nil {
+		return false, err
+	}
+	verifier := NewCLEqualityVerifier(pubKey1, index1, pubKey2, index2)
+
+	v1, t1, v2, t2 := prover.GetProofRandomData()
+	challenge, err := verifier.GetChallenge(v1, t1, v2, t2)
+	if err != nil {
+		return false, err
+	}
+	zE1, zS1, zM1, zE2, zS2, zM2 := prover.GetProofData(challenge)
+	verified := verifier.Verify(zE1, zS1, zM1, zE2, zS2, zM2)
+	return verified, nil
+}
+
+// CLEqualityProver proves the possession of two CL signatures in the same way as
+// CLUpdateHolder does - for each signature it sends the randomized signature v' = v * b^r
+// and proves the knowledge of e' = e - 2^(l_e-1), s' = s + r * e, m_1, ..., m_L such that
+// c * v'^(-2^(l_e-1)) = v'^e' * a_1^(-m_1) * ... * a_L^(-m_L) * b^(-s'). Both proofs are answered with the
+// same challenge and the same randomness is used for the two blocks which are claimed to
+// be equal - thus the responses for these two blocks are equal as well.
+type CLEqualityProver struct {
+	prover1 *clPossessionProver
+	prover2 *clPossessionProver
+	index1  int
+	index2  int
+}
+
+func NewCLEqualityProver(pubKey1 *CLPubKey, m_Ls1 []*big.Int, signature1 *CLSignature,
+	index1 int, pubKey2 *CLPubKey, m_Ls2 []*big.Int, signature2 *CLSignature,
+	index2 int) (*CLEqualityProver, error) {
+	if len(m_Ls1) != len(pubKey1.a_L) || len(m_Ls2) != len(pubKey2.a_L) {
+		return nil, errors.New("the number of message blocks is not correct")
+	}
+	if index1 < 0 || index1 >= len(m_Ls1) || index2 < 0 || index2 >= len(m_Ls2) {
+		return nil, errors.New("block index is out of range")
+	}
+	if m_Ls1[index1].Cmp(m_Ls2[index2]) != 0 {
+		return nil, errors.New("blocks are not equal")
+	}
+
+	return &CLEqualityProver{
+		prover1: newCLPossessionProver(pubKey1, m_Ls1, signature1),
+		prover2: newCLPossessionProver(pubKey2, m_Ls2, signature2),
+		index1:  index1,
+		index2:  index2,
+	}, nil
+}
+
+// GetProofRandomData returns randomized signatures v1, v2 and the first messages of both
+// proofs t1, t2.
+func (prover *CLEqualityProver) GetProofRandomData() (*big.Int, *big.Int, *big.Int, *big.Int) {
+	prover.prover1.setRandomValues()
+	prover.prover2.setRandomValues()
+	prover.prover2.rM[prover.index2] = prover.prover1.rM[prover.index1]
+
+	v1, t1 := prover.prover1.getProofRandomData()
+	v2, t2 := prover.prover2.getProofRandomData()
+	return v1, t1, v2, t2
+}
+
+// GetProofData returns responses zE, zS, zM for both proofs.
+func (prover *CLEqualityProver) GetProofData(challenge *big.Int) (*big.Int, *big.Int,
+	[]*big.Int, *big.Int, *big.Int, []*big.Int) {
+	zE1, zS1, zM1 := prover.prover1.getProofData(challenge)
+	zE2, zS2, zM2 := prover.prover2.getProofData(challenge)
+	return zE1, zS1, zM1, zE2, zS2, zM2
+}
+
+type CLEqualityVerifier struct {
+	pubKey1   *CLPubKey
+	pubKey2   *CLPubKey
+	index1    int
+	index2    int
+	v1        *big.Int
+	t1        *big.Int
+	v2        *big.Int
+	t2        *big.Int
+	challenge *big.Int
+}
+
+func NewCLEqualityVerifier(pubKey1 *CLPubKey, index1 int, pubKey2 *CLPubKey,
+	index2 int) *CLEqualityVerifier {
+	return &CLEqualityVerifier{
+		pubKey1: pubKey1,
+		pubKey2: pubKey2,
+		index1:  index1,
+		index2:  index2,
+	}
+}
+
+func (verifier *CLEqualityVerifier) GetChallenge(v1, t1, v2, t2 *big.Int) (*big.Int, error) {
+	verifier.v1 = v1
+	verifier.t1 = t1
+	verifier.v2 = v2
+	verifier.t2 = t2
+
+	challenge, err := common.GetRandomIntOfLength(NewPubCL(verifier.pubKey1).config.l)
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return challenge, nil
+}
+
+// Verify checks both proofs of signature possession (including the bounds of e and of
+// the blocks, see verifyCLPossession) and that the responses for the two blocks are equal.
+func (verifier *CLEqualityVerifier) Verify(zE1, zS1 *big.Int, zM1 []*big.Int, zE2,
+	zS2 *big.Int, zM2 []*big.Int) bool {
+	if len(zM1) != len(verifier.pubKey1.a_L) || len(zM2) != len(verifier.pubKey2.a_L) {
+		return false
+	}
+	if zM1[verifier.index1] == nil || zM1[verifier.index1].Cmp(zM2[verifier.index2]) != 0 {
+		return false
+	}
+
+	return verifyCLPossession(verifier.pubKey1, verifier.v1, verifier.t1, verifier.challenge,
+		zE1, zS1, zM1) && verifyCLPossession(verifier.pubKey2, verifier.v2, verifier.t2,
+		verifier.challenge, zE2, zS2, zM2)
+}
+
+// clPossessionProver proves the knowledge of a CL signature and the signed blocks.
+type clPossessionProver struct {
+	pubKey    *CLPubKey
+	config    *CLConfig
+	m_Ls      []*big.Int
+	signature *CLSignature
+	s         *big.Int // s' = s + r * e
+	rE        *big.Int
+	rS        *big.Int
+	rM        []*big.Int
+}
+
+func newCLPossessionProver(pubKey *CLPubKey, m_Ls []*big.Int,
+	signature *CLSignature) *clPossessionProver {
+	return &clPossessionProver{
+		pubKey:    pubKey,
+		config:    NewPubCL(pubKey).config,
+		m_Ls:      m_Ls,
+		signature: signature,
+	}
+}
+
+// setRandomValues randomizes the signature and chooses random values for the proof.
+func (prover *clPossessionProver) setRandomValues() error {
+	cfg := prover.config
+	r, err := common.GetRandomIntOfLength(cfg.l_n + cfg.l)
+	if err != nil {
+		return err
+	}
+	prover.s = new(big.Int).Mul(r, prover.signature.e)
+	prover.s.Add(prover.s, prover.signature.s)
+	prover.signature = &CLSignature{
+		e: prover.signature.e,
+		s: prover.s,
+		v: new(big.Int).Mul(prover.signature.v,
+			new(big.Int).Exp(prover.pubKey.b, r, prover.pubKey.n)),
+	}
+	prover.signature.v.Mod(prover.signature.v, prover.pubKey.n)
+
+	prover.rE, err = clUpdateRandomValue(cfg.l_e_prime, cfg)
+	if err != nil {
+		return err
+	}
+	prover.rS, err = clUpdateRandomValue(prover.s.BitLen(), cfg)
+	if err != nil {
+		return err
+	}
+	prover.rM = make([]*big.Int, len(prover.m_Ls))
+	for i := range prover.m_Ls {
+		prover.rM[i], err = clUpdateRandomValue(cfg.l_m, cfg)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getProofRandomData returns the randomized signature v' and
+// t = v'^rE * a_1^(-rM_1) * ... * a_L^(-rM_L) * b^(-rS).
+func (prover *clPossessionProver) getProofRandomData() (*big.Int, *big.Int) {
+	n := prover.pubKey.n
+	t := new(big.Int).Exp(prover.signature.v, prover.rE, n)
+	for i, rM := range prover.rM {
+		t.Mul(t, common.Exponentiate(prover.pubKey.a_L[i], new(big.Int).Neg(rM), n))
+		t.Mod(t, n)
+	}
+	t.Mul(t, common.Exponentiate(prover.pubKey.b, new(big.Int).Neg(prover.rS), n))
+	t.Mod(t, n)
+
+	return new(big.Int).Set(prover.signature.v), t
+}
+
+func (prover *clPossessionProver) getProofData(challenge *big.Int) (*big.Int, *big.Int,
+	[]*big.Int) {
+	zE := clUpdateResponse(prover.rE, challenge,
+		new(big.Int).Sub(prover.signature.e, prover.config.getEOffset()))
+	zS := clUpdateResponse(prover.rS, challenge, prover.s)
+	zM := make([]*big.Int, len(prover.m_Ls))
+	for i, m := range prover.m_Ls {
+		zM[i] = clUpdateResponse(prover.rM[i], challenge, m)
+	}
+	return zE, zS, zM
+}
+
+// verifyCLPossession checks that the responses are not longer than the responses of
+// an honest prover and that
+// v^zE * a_1^(-zM_1) * ... * a_L^(-zM_L) * b^(-zS) = t * (c * v^(-2^(l_e-1)))^challenge.
+// As zE is the response for e' = e - 2^(l_e-1) and its length is bounded, the verifier
+// knows that e is in the interval in which the issuer chooses it - otherwise the prover
+// could use a forged signature with e = 1, v = c * a_1^m_1 * ... * a_L^m_L * b^s.
+func verifyCLPossession(pubKey *CLPubKey, v, t, challenge, zE, zS *big.Int,
+	zM []*big.Int) bool {
+	cfg := NewPubCL(pubKey).config
+	if v == nil || t == nil || challenge == nil || zE == nil || zS == nil ||
+		len(zM) != len(pubKey.a_L) || challenge.BitLen() > cfg.l ||
+		zE.BitLen() > cfg.l_e_prime+2*cfg.l+1 {
+		return false
+	}
+	for _, z := range zM {
+		if z == nil || z.BitLen() > cfg.l_m+2*cfg.l+1 {
+			return false
+		}
+	}
+
+	n := pubKey.n
+	left := common.Exponentiate(v, zE, n)
+	for i, z := range zM {
+		left.Mul(left, common.Exponentiate(pubKey.a_L[i], new(big.Int).Neg(z), n))
+		left.Mod(left, n)
+	}
+	left.Mul(left, common.Exponentiate(pubKey.b, new(big.Int).Neg(zS), n))
+	left.Mod(left, n)
+
+	right := common.Exponentiate(v, new(big.Int).Neg(cfg.getEOffset()), n)
+	right.Mul(right, pubKey.c)
+	right.Exp(right, challenge, n)
+	right.Mul(right, t)
+	right.Mod(right, n)
+	return left.Cmp(right) == 0
+}
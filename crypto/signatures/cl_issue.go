@@ -0,0 +1,241 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package signatures
+
+import (
+	"errors"
+	"github.com/xlab-si/emmy/crypto/common"
+	"math/big"
+)
+
+// IssueCLSignature demonstrates how the receiver obtains CL signature on blocks of which
+// the issuer knows only the blocks in known - the hidden blocks (for example the master
+// secret of an anonymous credential system) are only committed to. It returns
+// the signature and all the signed blocks.
+func IssueCLSignature(cl *CL, hidden, known map[int]*big.Int) (*CLSignature, []*big.Int,
+	error) {
+	receiver, err := NewCLIssueReceiver(cl.GetPubKey(), hidden)
+	if err != nil {
+		return nil, nil, err
+	}
+	issuer := NewCLIssuer(cl, known)
+
+	U, t, err := receiver.GetProofRandomData()
+	if err != nil {
+		return nil, nil, err
+	}
+	challenge, err := issuer.GetChallenge(U, t)
+	if err != nil {
+		return nil, nil, err
+	}
+	zS, zM := receiver.GetProofData(challenge)
+	v, e, s, err := issuer.Verify(zS, zM)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return receiver.GetSignature(v, e, s, known)
+}
+
+// CLIssueReceiver is the receiver's side of the issuance of CL signature on hidden blocks
+// (as in CL02, section 4.2). The receiver sends the commitment
+// U = prod_{i hidden} a_i^m_i * b^s1 and proves the knowledge of s1 and the hidden blocks.
+// The issuer then signs U together with the blocks it knows, and the receiver obtains
+// the signature by adding s1 to the issuer's s.
+type CLIssueReceiver struct {
+	pubKey *CLPubKey
+	config *CLConfig
+	hidden map[int]*big.Int
+	s1     *big.Int
+	rS1    *big.Int
+	rM     map[int]*big.Int
+}
+
+// NewCLIssueReceiver returns an error if the hidden blocks are out of range.
+func NewCLIssueReceiver(pubKey *CLPubKey, hidden map[int]*big.Int) (*CLIssueReceiver, error) {
+	config := NewPubCL(pubKey).config
+	for i, m := range hidden {
+		if i < 0 || i >= len(pubKey.a_L) {
+			return nil, errors.New("block index is out of range")
+		}
+		if m.Sign() < 0 || m.BitLen() > config.l_m {
+			return nil, errors.New("msg is too big")
+		}
+	}
+
+	return &CLIssueReceiver{
+		pubKey: pubKey,
+		config: config,
+		hidden: hidden,
+	}, nil
+}
+
+// GetProofRandomData returns the commitment U to the hidden blocks and
+// t = prod_{i hidden} a_i^rM_i * b^rS1.
+func (receiver *CLIssueReceiver) GetProofRandomData() (*big.Int, *big.Int, error) {
+	n := receiver.pubKey.n
+	cfg := receiver.config
+
+	s1, err := common.GetRandomIntOfLength(cfg.l_n + cfg.l)
+	if err != nil {
+		return nil, nil, err
+	}
+	receiver.s1 = s1
+	receiver.rS1, err = clUpdateRandomValue(receiver.s1.BitLen(), cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	U := new(big.Int).Exp(receiver.pubKey.b, receiver.s1, n)
+	t := new(big.Int).Exp(receiver.pubKey.b, receiver.rS1, n)
+	receiver.rM = make(map[int]*big.Int, len(receiver.hidden))
+	for i, m := range receiver.hidden {
+		receiver.rM[i], err = clUpdateRandomValue(cfg.l_m, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		U.Mul(U, new(big.Int).Exp(receiver.pubKey.a_L[i], m, n))
+		U.Mod(U, n)
+		t.Mul(t, new(big.Int).Exp(receiver.pubKey.a_L[i], receiver.rM[i], n))
+		t.Mod(t, n)
+	}
+
+	return U, t, nil
+}
+
+// GetProofData returns zS1 = rS1 + challenge * s1 and zM_i = rM_i + challenge * m_i for
+// the hidden blocks.
+func (receiver *CLIssueReceiver) GetProofData(challenge *big.Int) (*big.Int,
+	map[int]*big.Int) {
+	zM := make(map[int]*big.Int, len(receiver.hidden))
+	for i, m := range receiver.hidden {
+		zM[i] = clUpdateResponse(receiver.rM[i], challenge, m)
+	}
+	return clUpdateResponse(receiver.rS1, challenge, receiver.s1), zM
+}
+
+// GetSignature takes the issuer's (v, e, s) and the blocks known to the issuer and returns
+// the signature together with all the signed blocks.
+func (receiver *CLIssueReceiver) GetSignature(v, e, s *big.Int,
+	known map[int]*big.Int) (*CLSignature, []*big.Int, error) {
+	m_Ls := make([]*big.Int, len(receiver.pubKey.a_L))
+	for _, blocks := range []map[int]*big.Int{receiver.hidden, known} {
+		for i, m := range blocks {
+			if i < 0 || i >= len(m_Ls) || m_Ls[i] != nil {
+				return nil, nil, errors.New("blocks are not properly specified")
+			}
+			m_Ls[i] = m
+		}
+	}
+	for _, m := range m_Ls {
+		if m == nil {
+			return nil, nil, errors.New("the number of message blocks is not correct")
+		}
+	}
+
+	signature := &CLSignature{
+		e: e,
+		s: new(big.Int).Add(receiver.s1, s),
+		v: v,
+	}
+	verified, err := NewPubCL(receiver.pubKey).Verify(m_Ls, signature)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !verified {
+		return nil, nil, errors.New("issued signature is not valid")
+	}
+	return signature, m_Ls, nil
+}
+
+// CLIssuer is the issuer's side of the issuance of CL signature on hidden blocks (see
+// CLIssueReceiver). Known are the blocks which are set by the issuer, all the others need
+// to be committed by the receiver.
+type CLIssuer struct {
+	cl        *CL
+	known     map[int]*big.Int
+	U         *big.Int
+	t         *big.Int
+	challenge *big.Int
+}
+
+func NewCLIssuer(cl *CL, known map[int]*big.Int) *CLIssuer {
+	return &CLIssuer{
+		cl:    cl,
+		known: known,
+	}
+}
+
+func (issuer *CLIssuer) GetChallenge(U, t *big.Int) (*big.Int, error) {
+	issuer.U = U
+	issuer.t = t
+	challenge, err := common.GetRandomIntOfLength(issuer.cl.config.l)
+	if err != nil {
+		return nil, err
+	}
+	issuer.challenge = challenge
+	return issuer.challenge, nil
+}
+
+// Verify checks the proof of knowledge of the blocks committed in U - the responses need to
+// be given for exactly the blocks which are not known to the issuer and they must not be
+// longer than the responses of an honest receiver (thus the hidden blocks are bounded).
+// If the proof is valid, it returns v, e, s such that
+// v^e = U * prod_{i known} a_i^m_i * b^s * c.
+func (issuer *CLIssuer) Verify(zS1 *big.Int, zM map[int]*big.Int) (*big.Int, *big.Int,
+	*big.Int, error) {
+	pubKey := issuer.cl.pubKey
+	n := pubKey.n
+	cfg := issuer.cl.config
+	if issuer.U == nil || issuer.t == nil || zS1 == nil {
+		return nil, nil, nil, errors.New("the proof is not complete")
+	}
+	if len(zM)+len(issuer.known) != len(pubKey.a_L) {
+		return nil, nil, nil, errors.New("the number of message blocks is not correct")
+	}
+	for i, m := range issuer.known {
+		if i < 0 || i >= len(pubKey.a_L) || zM[i] != nil {
+			return nil, nil, nil, errors.New("blocks are not properly specified")
+		}
+		if m.Sign() < 0 || m.BitLen() > cfg.l_m {
+			return nil, nil, nil, errors.New("msg is too big")
+		}
+	}
+
+	// prod_{i hidden} a_i^zM_i * b^zS1 = t * U^challenge
+	left := common.Exponentiate(pubKey.b, zS1, n)
+	for i, z := range zM {
+		if i < 0 || i >= len(pubKey.a_L) || z == nil || z.BitLen() > cfg.l_m+2*cfg.l+1 {
+			return nil, nil, nil, errors.New("blocks are not properly specified")
+		}
+		left.Mul(left, common.Exponentiate(pubKey.a_L[i], z, n))
+		left.Mod(left, n)
+	}
+	right := new(big.Int).Exp(issuer.U, issuer.challenge, n)
+	right.Mul(right, issuer.t)
+	right.Mod(right, n)
+	if left.Cmp(right) != 0 {
+		return nil, nil, nil, errors.New("the proof of knowledge of hidden blocks is not valid")
+	}
+
+	t := new(big.Int).Set(issuer.U)
+	for i, m := range issuer.known {
+		t.Mul(t, new(big.Int).Exp(pubKey.a_L[i], m, n))
+		t.Mod(t, n)
+	}
+	return issuer.cl.signCommitment(t)
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package signatures
+
+import (
+	"errors"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"math/big"
+)
+
+// Merkle-ized credential: attributes are the leaves of a Merkle tree (see
+// commitments.MerkleCommitter) and the issuer signs only the root of the tree, thus
+// a credential can contain hundreds of attributes, while the holder reveals only a few of them
+// (each together with a path to the root). Note that the root and the signature are revealed
+// at each presentation, so presentations of the same credential are linkable.
+//
+// The root (256 bits) is bigger than the allowed size of a block (l_m bits), so it is split
+// into two blocks - a CL instance with two blocks needs to be used (see NewMerkleCL).
+
+func NewMerkleCL() *CL {
+	return NewCL(2)
+}
+
+// SignMerkleRoot signs the root of the Merkle tree of attributes.
+func (cl *CL) SignMerkleRoot(root *big.Int) (*CLSignature, error) {
+	return cl.Sign(merkleRootBlocks(root))
+}
+
+// VerifyMerkleDisclosure checks the signature on the root and that each of the revealed
+// attributes is a leaf of the tree with this root.
+func (cl *CL) VerifyMerkleDisclosure(root *big.Int, signature *CLSignature,
+	decommitments []*commitments.MerkleDecommitment) (bool, error) {
+	verified, err := cl.Verify(merkleRootBlocks(root), signature)
+	if err != nil {
+		return false, err
+	}
+	if !verified {
+		return false, errors.New("signature on Merkle root is not valid")
+	}
+
+	receiver := commitments.NewMerkleReceiver()
+	receiver.SetCommitment(root)
+	for _, decommitment := range decommitments {
+		if !receiver.CheckDecommitment(decommitment) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// merkleRootBlocks splits the root into two 128-bit blocks.
+func merkleRootBlocks(root *big.Int) []*big.Int {
+	mask := new(big.Int).Lsh(big.NewInt(1), 128)
+	mask.Sub(mask, big.NewInt(1))
+	low := new(big.Int).And(root, mask)
+	high := new(big.Int).Rsh(root, 128)
+	return []*big.Int{high, low}
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package signatures
+
+import (
+	"errors"
+	"github.com/xlab-si/emmy/crypto/common"
+	"math/big"
+)
+
+// ProveCLPossession demonstrates how the holder of CL signature on m_Ls proves that it
+// possesses a signature in which the blocks at indices disclosed have the given values,
+// without revealing the signature or the other blocks.
+func ProveCLPossession(pubKey *CLPubKey, m_Ls []*big.Int, signature *CLSignature,
+	disclosed []int) (bool, error) {
+	prover, err := NewCLPossessionProver(pubKey, m_Ls, signature, disclosed)
+	if err != nil {
+		return false, err
+	}
+	disclosedBlocks := make(map[int]*big.Int, len(disclosed))
+	for _, i := range disclosed {
+		disclosedBlocks[i] = m_Ls[i]
+	}
+	verifier := NewCLPossessionVerifier(pubKey, disclosedBlocks)
+
+	v, t := prover.GetProofRandomData()
+	challenge, err := verifier.GetChallenge(v, t)
+	if err != nil {
+		return false, err
+	}
+	zE, zS, zM := prover.GetProofData(challenge)
+	return verifier.Verify(zE, zS, zM), nil
+}
+
+// CLPossessionProver proves the possession of CL signature as CLEqualityProver does for
+// each of its signatures - it sends the randomized signature v' = v * b^r and proves
+// the knowledge of e' = e - 2^(l_e-1), s' = s + r * e and the hidden blocks such that
+// c * v'^(-2^(l_e-1)) * prod_{i disclosed} a_i^m_i =
+// v'^e' * prod_{i hidden} a_i^(-m_i) * b^(-s').
+// The responses for the disclosed blocks are not sent, as the verifier computes them
+// (the random values for these blocks are 0, thus the responses are challenge * m_i).
+type CLPossessionProver struct {
+	prover    *clPossessionProver
+	disclosed map[int]bool
+}
+
+func NewCLPossessionProver(pubKey *CLPubKey, m_Ls []*big.Int, signature *CLSignature,
+	disclosed []int) (*CLPossessionProver, error) {
+	if len(m_Ls) != len(pubKey.a_L) {
+		return nil, errors.New("the number of message blocks is not correct")
+	}
+	isDisclosed := make(map[int]bool, len(disclosed))
+	for _, i := range disclosed {
+		if i < 0 || i >= len(m_Ls) {
+			return nil, errors.New("block index is out of range")
+		}
+		isDisclosed[i] = true
+	}
+
+	return &CLPossessionProver{
+		prover:    newCLPossessionProver(pubKey, m_Ls, signature),
+		disclosed: isDisclosed,
+	}, nil
+}
+
+// GetProofRandomData returns the randomized signature v' and
+// t = v'^rE * prod_{i hidden} a_i^(-rM_i) * b^(-rS).
+func (prover *CLPossessionProver) GetProofRandomData() (*big.Int, *big.Int) {
+	prover.prover.setRandomValues()
+	for i := range prover.disclosed {
+		prover.prover.rM[i] = big.NewInt(0)
+	}
+	return prover.prover.getProofRandomData()
+}
+
+// GetBlockRandomValue returns the random value rM_i of the hidden block i (after
+// GetProofRandomData), so that the block can be linked to other proofs with the same
+// challenge - the response for the block is then rM_i + challenge * m_i in all of them.
+func (prover *CLPossessionProver) GetBlockRandomValue(i int) *big.Int {
+	if prover.disclosed[i] || prover.prover.rM == nil || i < 0 || i >= len(prover.prover.rM) {
+		return nil
+	}
+	return prover.prover.rM[i]
+}
+
+// GetProofData returns zE, zS and the responses for the hidden blocks (indexed as
+// the blocks).
+func (prover *CLPossessionProver) GetProofData(challenge *big.Int) (*big.Int, *big.Int,
+	map[int]*big.Int) {
+	zE, zS, zMs := prover.prover.getProofData(challenge)
+	zM := make(map[int]*big.Int, len(zMs)-len(prover.disclosed))
+	for i, z := range zMs {
+		if !prover.disclosed[i] {
+			zM[i] = z
+		}
+	}
+	return zE, zS, zM
+}
+
+type CLPossessionVerifier struct {
+	pubKey    *CLPubKey
+	config    *CLConfig
+	disclosed map[int]*big.Int
+	v         *big.Int
+	t         *big.Int
+	challenge *big.Int
+}
+
+// NewCLPossessionVerifier returns the verifier of the possession of CL signature in which
+// the blocks at the indices of disclosed have the given values.
+func NewCLPossessionVerifier(pubKey *CLPubKey, disclosed map[int]*big.Int) *CLPossessionVerifier {
+	return &CLPossessionVerifier{
+		pubKey:    pubKey,
+		config:    NewPubCL(pubKey).config,
+		disclosed: disclosed,
+	}
+}
+
+func (verifier *CLPossessionVerifier) GetChallenge(v, t *big.Int) (*big.Int, error) {
+	challenge, err := common.GetRandomIntOfLength(verifier.config.l)
+	if err != nil {
+		return nil, err
+	}
+	verifier.SetChallenge(v, t, challenge)
+	return challenge, nil
+}
+
+// SetChallenge sets the proof random data and the challenge which was not generated by
+// the verifier (for example the challenge of a proof composed of several proofs). Challenges
+// longer than the ones of the verifier (160 bits) are rejected by Verify.
+func (verifier *CLPossessionVerifier) SetChallenge(v, t, challenge *big.Int) {
+	verifier.v = v
+	verifier.t = t
+	verifier.challenge = challenge
+}
+
+// Verify checks that the responses are given for exactly the hidden blocks, and then checks
+// the proof with the responses challenge * m_i for the disclosed blocks. The responses must
+// not be longer than the responses of an honest prover (see verifyCLPossession), thus
+// the hidden blocks are bounded and e is in the interval in which the issuer chooses it.
+func (verifier *CLPossessionVerifier) Verify(zE, zS *big.Int, zM map[int]*big.Int) bool {
+	numOfBlocks := len(verifier.pubKey.a_L)
+	if verifier.challenge == nil || len(zM)+len(verifier.disclosed) != numOfBlocks {
+		return false
+	}
+
+	zMs := make([]*big.Int, numOfBlocks)
+	for i, m := range verifier.disclosed {
+		if i < 0 || i >= numOfBlocks || m == nil {
+			return false
+		}
+		zMs[i] = new(big.Int).Mul(verifier.challenge, m)
+	}
+	for i, z := range zM {
+		if i < 0 || i >= numOfBlocks || zMs[i] != nil {
+			return false
+		}
+		zMs[i] = z
+	}
+
+	return verifyCLPossession(verifier.pubKey, verifier.v, verifier.t, verifier.challenge,
+		zE, zS, zMs)
+}
+
+// SimulateCLPossession returns the proof random data (v, t) and the proof data (zE, zS and
+// the responses for the hidden blocks) of the proof of possession of CL signature for
+// the given challenge, without knowing a signature (the blocks at the indices of disclosed
+// have the given values). The responses are chosen as random values of an honest prover and
+// t is computed from them, thus the simulated proof is statistically indistinguishable from
+// a real one - it is used for the branches of OR proofs which are not actually proved.
+func SimulateCLPossession(pubKey *CLPubKey, disclosed map[int]*big.Int,
+	challenge *big.Int) (*big.Int, *big.Int, *big.Int, *big.Int, map[int]*big.Int, error) {
+	cfg := NewPubCL(pubKey).config
+	n := pubKey.n
+	r, err := common.GetRandomIntOfLength(cfg.l_n + cfg.l)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+	v := new(big.Int).Exp(pubKey.b, r, n)
+	zE, err := clUpdateRandomValue(cfg.l_e_prime, cfg)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+	// s' = s + r * e is about as long as r * e
+	zS, err := clUpdateRandomValue(cfg.l_n+cfg.l+cfg.l_e, cfg)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+
+	zM := make(map[int]*big.Int, len(pubKey.a_L)-len(disclosed))
+	zMs := make([]*big.Int, len(pubKey.a_L))
+	for i := range zMs {
+		if m, ok := disclosed[i]; ok {
+			zMs[i] = new(big.Int).Mul(challenge, m)
+			continue
+		}
+		zMs[i], err = clUpdateRandomValue(cfg.l_m, cfg)
+		if err != nil {
+			return nil, nil, nil, nil, nil, err
+		}
+		zM[i] = zMs[i]
+	}
+
+	// t = v^zE * a_1^(-zM_1) * ... * a_L^(-zM_L) * b^(-zS) * (c * v^(-2^(l_e-1)))^(-challenge)
+	t := new(big.Int).Exp(v, zE, n)
+	for i, z := range zMs {
+		t.Mul(t, common.Exponentiate(pubKey.a_L[i], new(big.Int).Neg(z), n))
+		t.Mod(t, n)
+	}
+	t.Mul(t, common.Exponentiate(pubKey.b, new(big.Int).Neg(zS), n))
+	t.Mod(t, n)
+	x := common.Exponentiate(v, new(big.Int).Neg(cfg.getEOffset()), n)
+	x.Mul(x, pubKey.c)
+	t.Mul(t, common.Exponentiate(x, new(big.Int).Neg(challenge), n))
+	t.Mod(t, n)
+
+	return v, t, zE, zS, zM, nil
+}
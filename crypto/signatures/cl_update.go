@@ -0,0 +1,322 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package signatures
+
+import (
+	"errors"
+	"github.com/xlab-si/emmy/crypto/common"
+	"math/big"
+)
+
+// UpdateCLSignature demonstrates how the holder of CL signature on m_Ls obtains
+// a signature where the block at index is increased by delta, without
+// revealing the signature or the (other) blocks to the issuer.
+func UpdateCLSignature(cl *CL, m_Ls []*big.Int, signature *CLSignature, index int,
+	delta *big.Int) (*CLSignature, []*big.Int, error) {
+	holder, err := NewCLUpdateHolder(cl.GetPubKey(), m_Ls, signature, index, delta)
+	if err != nil {
+		return nil, nil, err
+	}
+	issuer := NewCLUpdateIssuer(cl, index, delta)
+
+	v1, U, t1, t2, err := holder.GetProofRandomData()
+	if err != nil {
+		return nil, nil, err
+	}
+	challenge, err := issuer.GetChallenge(v1, U, t1, t2)
+	if err != nil {
+		return nil, nil, err
+	}
+	zE, zS, zS1, zM := holder.GetProofData(challenge)
+	v2, e2, s2, err := issuer.Verify(zE, zS, zS1, zM)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return holder.GetUpdatedSignature(v2, e2, s2)
+}
+
+// CLUpdateHolder is the holder's side of the protocol for updating a single block
+// of CL signature (for example incrementing a counter or renewing expiry date).
+// The holder randomizes the signature (v1 = v * b^r, s' = s + r * e) and sends v1 together
+// with a commitment U = a_1^m_1' * ... * a_L^m_L' * b^s1 where m' are blocks
+// before the update. It then proves the knowledge of e' = e - 2^(l_e-1), s', s1,
+// m_1', ..., m_L' such that: c * v1^(-2^(l_e-1)) = v1^e' * a_1^(-m_1') * ... * a_L^(-m_L') *
+// b^(-s') and U = a_1^m_1' * ... * a_L^m_L' * b^s1.
+// The issuer then signs U * a_index^delta, so the holder obtains a signature on
+// updated blocks, while the issuer cannot link the update to the issuance.
+type CLUpdateHolder struct {
+	pubKey    *CLPubKey
+	config    *CLConfig
+	m_Ls      []*big.Int
+	signature *CLSignature
+	index     int
+	delta     *big.Int
+	s         *big.Int // s' = s + r * e
+	s1        *big.Int
+	rE        *big.Int
+	rS        *big.Int
+	rS1       *big.Int
+	rM        []*big.Int
+}
+
+func NewCLUpdateHolder(pubKey *CLPubKey, m_Ls []*big.Int, signature *CLSignature, index int,
+	delta *big.Int) (*CLUpdateHolder, error) {
+	if len(m_Ls) != len(pubKey.a_L) {
+		return nil, errors.New("the number of message blocks is not correct")
+	}
+	if index < 0 || index >= len(m_Ls) {
+		return nil, errors.New("block index is out of range")
+	}
+
+	return &CLUpdateHolder{
+		pubKey:    pubKey,
+		config:    NewPubCL(pubKey).config,
+		m_Ls:      m_Ls,
+		signature: signature,
+		index:     index,
+		delta:     delta,
+	}, nil
+}
+
+// GetProofRandomData returns randomized signature v1, commitment U to the blocks and
+// values t1, t2 (first message of the proof that v1 and U are properly formed).
+func (holder *CLUpdateHolder) GetProofRandomData() (*big.Int, *big.Int, *big.Int, *big.Int, error) {
+	n := holder.pubKey.n
+	cfg := holder.config
+
+	// v1 = v * b^r, s' = s + r * e
+	r, err := common.GetRandomIntOfLength(cfg.l_n + cfg.l)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	v1 := new(big.Int).Exp(holder.pubKey.b, r, n)
+	v1.Mul(v1, holder.signature.v)
+	v1.Mod(v1, n)
+	holder.s = new(big.Int).Mul(r, holder.signature.e)
+	holder.s.Add(holder.s, holder.signature.s)
+
+	// U = a_1^m_1 * ... * a_L^m_L * b^s1
+	holder.s1, err = common.GetRandomIntOfLength(cfg.l_n + cfg.l)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	U := new(big.Int).Exp(holder.pubKey.b, holder.s1, n)
+	for i, m := range holder.m_Ls {
+		U.Mul(U, new(big.Int).Exp(holder.pubKey.a_L[i], m, n))
+		U.Mod(U, n)
+	}
+
+	// random values need to be longer than the secrets (by the challenge length and
+	// the security parameter) to statistically hide the secrets
+	holder.rE, err = clUpdateRandomValue(cfg.l_e_prime, cfg)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	holder.rS, err = clUpdateRandomValue(holder.s.BitLen(), cfg)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	holder.rS1, err = clUpdateRandomValue(holder.s1.BitLen(), cfg)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	holder.rM = make([]*big.Int, len(holder.m_Ls))
+	for i := range holder.m_Ls {
+		holder.rM[i], err = clUpdateRandomValue(cfg.l_m, cfg)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+	}
+
+	// t1 = v1^rE * a_1^(-rM_1) * ... * a_L^(-rM_L) * b^(-rS)
+	// t2 = a_1^rM_1 * ... * a_L^rM_L * b^rS1
+	aToRM := big.NewInt(1)
+	for i, rM := range holder.rM {
+		aToRM.Mul(aToRM, new(big.Int).Exp(holder.pubKey.a_L[i], rM, n))
+		aToRM.Mod(aToRM, n)
+	}
+	t1 := new(big.Int).Exp(v1, holder.rE, n)
+	t1.Mul(t1, new(big.Int).ModInverse(aToRM, n))
+	t1.Mul(t1, common.Exponentiate(holder.pubKey.b, new(big.Int).Neg(holder.rS), n))
+	t1.Mod(t1, n)
+
+	t2 := new(big.Int).Exp(holder.pubKey.b, holder.rS1, n)
+	t2.Mul(t2, aToRM)
+	t2.Mod(t2, n)
+
+	return v1, U, t1, t2, nil
+}
+
+// GetProofData returns zE = rE + challenge * e', zS = rS + challenge * s',
+// zS1 = rS1 + challenge * s1 and zM_i = rM_i + challenge * m_i (computed in integers).
+func (holder *CLUpdateHolder) GetProofData(challenge *big.Int) (*big.Int, *big.Int,
+	*big.Int, []*big.Int) {
+	zE := clUpdateResponse(holder.rE, challenge,
+		new(big.Int).Sub(holder.signature.e, holder.config.getEOffset()))
+	zS := clUpdateResponse(holder.rS, challenge, holder.s)
+	zS1 := clUpdateResponse(holder.rS1, challenge, holder.s1)
+	zM := make([]*big.Int, len(holder.m_Ls))
+	for i, m := range holder.m_Ls {
+		zM[i] = clUpdateResponse(holder.rM[i], challenge, m)
+	}
+	return zE, zS, zS1, zM
+}
+
+// GetUpdatedSignature takes the issuer's (v2, e2, s2) and returns the signature on updated
+// blocks together with the updated blocks.
+func (holder *CLUpdateHolder) GetUpdatedSignature(v2, e2, s2 *big.Int) (*CLSignature,
+	[]*big.Int, error) {
+	m_Ls := make([]*big.Int, len(holder.m_Ls))
+	copy(m_Ls, holder.m_Ls)
+	m_Ls[holder.index] = new(big.Int).Add(m_Ls[holder.index], holder.delta)
+
+	signature := &CLSignature{
+		e: e2,
+		s: new(big.Int).Add(holder.s1, s2),
+		v: v2,
+	}
+
+	verified, err := NewPubCL(holder.pubKey).Verify(m_Ls, signature)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !verified {
+		return nil, nil, errors.New("updated signature is not valid")
+	}
+	return signature, m_Ls, nil
+}
+
+// CLUpdateIssuer is the issuer's side of the protocol for updating a single block
+// of CL signature (see CLUpdateHolder).
+type CLUpdateIssuer struct {
+	cl        *CL
+	index     int
+	delta     *big.Int
+	v1        *big.Int
+	U         *big.Int
+	t1        *big.Int
+	t2        *big.Int
+	challenge *big.Int
+}
+
+func NewCLUpdateIssuer(cl *CL, index int, delta *big.Int) *CLUpdateIssuer {
+	return &CLUpdateIssuer{
+		cl:    cl,
+		index: index,
+		delta: delta,
+	}
+}
+
+func (issuer *CLUpdateIssuer) GetChallenge(v1, U, t1, t2 *big.Int) (*big.Int, error) {
+	issuer.v1 = v1
+	issuer.U = U
+	issuer.t1 = t1
+	issuer.t2 = t2
+
+	challenge, err := common.GetRandomIntOfLength(issuer.cl.config.l)
+	if err != nil {
+		return nil, err
+	}
+	issuer.challenge = challenge
+	return challenge, nil
+}
+
+// Verify checks the proof that v1 is a valid (randomized) signature on blocks committed in U.
+// The responses must not be longer than the responses of an honest holder (see
+// verifyCLPossession), otherwise a forged signature with e = 1 would be accepted and
+// signed. If the proof is valid, it returns v2, e2, s2 such that
+// v2^e2 = U * a_index^delta * b^s2 * c.
+func (issuer *CLUpdateIssuer) Verify(zE, zS, zS1 *big.Int, zM []*big.Int) (*big.Int,
+	*big.Int, *big.Int, error) {
+	pubKey := issuer.cl.pubKey
+	n := pubKey.n
+	if len(zM) != len(pubKey.a_L) {
+		return nil, nil, nil, errors.New("the number of message blocks is not correct")
+	}
+
+	// v1^zE * a_1^(-zM_1) * ... * a_L^(-zM_L) * b^(-zS) = t1 * (c * v1^(-2^(l_e-1)))^challenge
+	if !verifyCLPossession(pubKey, issuer.v1, issuer.t1, issuer.challenge, zE, zS, zM) {
+		return nil, nil, nil, errors.New("the proof of signature possession is not valid")
+	}
+
+	// a_1^zM_1 * ... * a_L^zM_L * b^zS1 = t2 * U^challenge
+	if issuer.U == nil || issuer.t2 == nil || zS1 == nil {
+		return nil, nil, nil, errors.New("the proof is not complete")
+	}
+	aToZM := big.NewInt(1)
+	for i, z := range zM {
+		aToZM.Mul(aToZM, common.Exponentiate(pubKey.a_L[i], z, n))
+		aToZM.Mod(aToZM, n)
+	}
+	left2 := common.Exponentiate(pubKey.b, zS1, n)
+	left2.Mul(left2, aToZM)
+	left2.Mod(left2, n)
+	right2 := new(big.Int).Exp(issuer.U, issuer.challenge, n)
+	right2.Mul(right2, issuer.t2)
+	right2.Mod(right2, n)
+
+	if left2.Cmp(right2) != 0 {
+		return nil, nil, nil, errors.New("the proof of signature possession is not valid")
+	}
+
+	// v2^e2 = U * a_index^delta * b^s2 * c
+	t := new(big.Int).Exp(pubKey.a_L[issuer.index], issuer.delta, n)
+	t.Mul(t, issuer.U)
+	t.Mod(t, n)
+	return issuer.cl.signCommitment(t)
+}
+
+// signCommitment returns v, e, s such that v^e = U * b^s * c, which is a signature on
+// the blocks committed in U (the receiver adds its randomness of U to s).
+func (cl *CL) signCommitment(U *big.Int) (*big.Int, *big.Int, *big.Int, error) {
+	pubKey := cl.pubKey
+	n := pubKey.n
+	e, err := cl.getRandomE()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	s, err := common.GetRandomIntOfLength(cl.config.l_n + cl.config.l_m + cl.config.l)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	t := new(big.Int).Exp(pubKey.b, s, n)
+	t.Mul(t, U)
+	t.Mul(t, pubKey.c)
+	t.Mod(t, n)
+
+	pMin1 := new(big.Int).Sub(cl.p, big.NewInt(1))
+	qMin1 := new(big.Int).Sub(cl.q, big.NewInt(1))
+	phi_n := new(big.Int).Mul(pMin1, qMin1)
+	eInv := new(big.Int).ModInverse(e, phi_n)
+	v := new(big.Int).Exp(t, eInv, n)
+
+	return v, e, s, nil
+}
+
+// clUpdateRandomValue returns a random value which is by challenge length and security
+// parameter longer than a secret of length bitLen.
+func clUpdateRandomValue(bitLen int, config *CLConfig) (*big.Int, error) {
+	return common.GetRandomIntOfLength(bitLen + 2*config.l)
+}
+
+// clUpdateResponse returns r + challenge * secret.
+func clUpdateResponse(r, challenge, secret *big.Int) *big.Int {
+	z := new(big.Int).Mul(challenge, secret)
+	return z.Add(z, r)
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package signatures
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/binary"
+	"math/big"
+)
+
+// RSA is the full-domain-hash RSA signature scheme (Bellare, Rogaway): the signature of
+// msg is H(msg)^d mod N, where H maps messages to Z_N. Unlike ECDSA or Schnorr
+// signatures, the possession of RSA signature can be proved without revealing it
+// (see RSASignatureProver).
+type RSA struct {
+	pubKey *RSAPubKey
+	d      *big.Int
+	primes []*big.Int
+}
+
+type RSAPubKey struct {
+	N *big.Int
+	E *big.Int
+}
+
+// NewRSA generates a new key with the modulus of the given bit length.
+func NewRSA(bits int) (*RSA, error) {
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, err
+	}
+	return NewRSAFromKey(key), nil
+}
+
+// NewRSAFromKey returns the scheme with an existing RSA key.
+func NewRSAFromKey(key *rsa.PrivateKey) *RSA {
+	return &RSA{
+		pubKey: &RSAPubKey{
+			N: key.N,
+			E: big.NewInt(int64(key.E)),
+		},
+		d:      key.D,
+		primes: key.Primes,
+	}
+}
+
+func (r *RSA) GetPubKey() *RSAPubKey {
+	return r.pubKey
+}
+
+// GetPrimes returns the prime factors of N (needed to prove that N is well-formed).
+func (r *RSA) GetPrimes() []*big.Int {
+	return r.primes
+}
+
+// Sign returns H(msg)^d mod N.
+func (r *RSA) Sign(msg []byte) *big.Int {
+	return new(big.Int).Exp(r.pubKey.Hash(msg), r.d, r.pubKey.N)
+}
+
+// Verify checks that signature^e = H(msg) mod N.
+func (pubKey *RSAPubKey) Verify(msg []byte, signature *big.Int) bool {
+	if signature == nil || signature.Sign() <= 0 || signature.Cmp(pubKey.N) >= 0 {
+		return false
+	}
+	y := new(big.Int).Exp(signature, pubKey.E, pubKey.N)
+	return y.Cmp(pubKey.Hash(msg)) == 0
+}
+
+// Hash maps msg to Z_N: SHA-512 is applied to msg prefixed by a counter until
+// |N| + 128 bits are obtained, which are then reduced modulo N.
+func (pubKey *RSAPubKey) Hash(msg []byte) *big.Int {
+	var out []byte
+	counter := make([]byte, 4)
+	for i := uint32(0); len(out)*8 < pubKey.N.BitLen()+128; i++ {
+		binary.BigEndian.PutUint32(counter, i)
+		h := sha512.New()
+		h.Write(counter)
+		h.Write(msg)
+		out = h.Sum(out)
+	}
+	y := new(big.Int).SetBytes(out)
+	return y.Mod(y, pubKey.N)
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package signatures
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"math/big"
+)
+
+// RSAProofSecurityBits is the bit length of the challenge space (summed over all rounds)
+// of the proof of knowledge of RSA signature.
+const RSAProofSecurityBits = 80
+
+// ProveRSASignatureKnowledge demonstrates how prover can prove that it knows RSA signature
+// of msg without revealing it.
+func ProveRSASignatureKnowledge(pubKey *RSAPubKey, msg []byte, signature *big.Int) (bool,
+	error) {
+	prover, err := NewRSASignatureProver(pubKey, msg, signature)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewRSASignatureVerifier(pubKey, msg)
+	if err != nil {
+		return false, err
+	}
+
+	proofRandomData, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	challenges, err := verifier.GetChallenge(proofRandomData)
+	if err != nil {
+		return false, err
+	}
+	proofData, err := prover.GetProofData(challenges)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(proofData), nil
+}
+
+// RSASignatureProver proves the knowledge of the signature s such that s^e = y mod N
+// where y = H(msg) (Guillou, Quisquater: A practical zero-knowledge protocol fitted to
+// security microprocessor minimizing both transmission and memory):
+//   - prover chooses r from Z_N* and sends t = r^e mod N,
+//   - verifier sends challenge c from [0, e),
+//   - prover sends z = r * s^c mod N,
+//   - verifier checks z^e = t * y^c mod N.
+//
+// As e is prime, two accepting responses for different challenges reveal s, while
+// the transcripts can be simulated without s (the verifier does not learn the signature and
+// cannot show it to anybody else). For small e (like 65537) the soundness error 1/e is too
+// big, thus the protocol is run in parallel in as many rounds as needed for the challenges
+// to have at least RSAProofSecurityBits bits.
+type RSASignatureProver struct {
+	pubKey    *RSAPubKey
+	signature *big.Int
+	r         []*big.Int
+}
+
+func NewRSASignatureProver(pubKey *RSAPubKey, msg []byte,
+	signature *big.Int) (*RSASignatureProver, error) {
+	if !pubKey.Verify(msg, signature) {
+		return nil, fmt.Errorf("signature is not valid")
+	}
+	return &RSASignatureProver{
+		pubKey:    pubKey,
+		signature: signature,
+	}, nil
+}
+
+// GetProofRandomData returns t_i = r_i^e mod N for each round.
+func (prover *RSASignatureProver) GetProofRandomData() ([]*big.Int, error) {
+	n := prover.pubKey.N
+	rounds := rsaProofRounds(prover.pubKey.E)
+	prover.r = make([]*big.Int, rounds)
+	t := make([]*big.Int, rounds)
+	for i := 0; i < rounds; i++ {
+		r, err := common.GetRandomZnInvertibleElement(n)
+		if err != nil {
+			return nil, err
+		}
+		prover.r[i] = r
+		t[i] = new(big.Int).Exp(r, prover.pubKey.E, n)
+	}
+	return t, nil
+}
+
+// GetProofData returns z_i = r_i * s^c_i mod N for each round.
+func (prover *RSASignatureProver) GetProofData(challenges []*big.Int) ([]*big.Int, error) {
+	if prover.r == nil || len(challenges) != len(prover.r) {
+		return nil, fmt.Errorf("the number of challenges needs to match the proof random data")
+	}
+	n := prover.pubKey.N
+	z := make([]*big.Int, len(challenges))
+	for i, c := range challenges {
+		z[i] = new(big.Int).Exp(prover.signature, c, n)
+		z[i].Mul(z[i], prover.r[i])
+		z[i].Mod(z[i], n)
+	}
+	prover.r = nil
+	return z, nil
+}
+
+type RSASignatureVerifier struct {
+	pubKey          *RSAPubKey
+	y               *big.Int
+	proofRandomData []*big.Int
+	challenges      []*big.Int
+}
+
+// NewRSASignatureVerifier returns a verifier of the proof that the prover knows RSA signature
+// of msg. The public exponent needs to be an odd prime, otherwise the proof is not sound.
+func NewRSASignatureVerifier(pubKey *RSAPubKey, msg []byte) (*RSASignatureVerifier, error) {
+	if pubKey.N == nil || pubKey.E == nil || pubKey.E.Cmp(big.NewInt(2)) <= 0 ||
+		!pubKey.E.ProbablyPrime(20) || pubKey.E.Cmp(pubKey.N) >= 0 {
+		return nil, fmt.Errorf("public exponent needs to be an odd prime smaller than N")
+	}
+	return &RSASignatureVerifier{
+		pubKey: pubKey,
+		y:      pubKey.Hash(msg),
+	}, nil
+}
+
+// GetChallenge receives the proof random data and returns a challenge from [0, e)
+// for each round.
+func (verifier *RSASignatureVerifier) GetChallenge(proofRandomData []*big.Int) ([]*big.Int,
+	error) {
+	rounds := rsaProofRounds(verifier.pubKey.E)
+	if len(proofRandomData) != rounds {
+		return nil, fmt.Errorf("proof random data needs to contain %d values", rounds)
+	}
+	challenges := make([]*big.Int, rounds)
+	for i := 0; i < rounds; i++ {
+		c, err := common.GetRandomInt(verifier.pubKey.E)
+		if err != nil {
+			return nil, err
+		}
+		challenges[i] = c
+	}
+	verifier.proofRandomData = proofRandomData
+	verifier.challenges = challenges
+	return challenges, nil
+}
+
+// Verify checks z_i^e = t_i * y^c_i mod N for each round.
+func (verifier *RSASignatureVerifier) Verify(proofData []*big.Int) bool {
+	if verifier.challenges == nil || len(proofData) != len(verifier.challenges) {
+		return false
+	}
+	n := verifier.pubKey.N
+	for i, z := range proofData {
+		t := verifier.proofRandomData[i]
+		if !isInvertibleModN(z, n) || !isInvertibleModN(t, n) {
+			return false
+		}
+		left := new(big.Int).Exp(z, verifier.pubKey.E, n)
+		right := new(big.Int).Exp(verifier.y, verifier.challenges[i], n)
+		right.Mul(right, t)
+		right.Mod(right, n)
+		if left.Cmp(right) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// rsaProofRounds returns the number of rounds needed for the challenges from [0, e)
+// to have at least RSAProofSecurityBits bits.
+func rsaProofRounds(e *big.Int) int {
+	bits := e.BitLen() - 1
+	return (RSAProofSecurityBits + bits - 1) / bits
+}
+
+func isInvertibleModN(x, n *big.Int) bool {
+	if x == nil || x.Sign() <= 0 || x.Cmp(n) >= 0 {
+		return false
+	}
+	return new(big.Int).GCD(nil, nil, x, n).Cmp(big.NewInt(1)) == 0
+}
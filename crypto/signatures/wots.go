@@ -0,0 +1,131 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package signatures
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// WOTS+ one-time signatures (Hulsing: W-OTS+ - Shorter Signatures for Hash-Based Signature
+// Schemes, https://eprint.iacr.org/2017/965) with Winternitz parameter w = 16 over SHA-256.
+// The security relies only on the hash function, so the signatures remain secure against
+// quantum adversaries. As in SPHINCS+ "simple" variant, each hash call is tweaked with
+// the public seed and the address of the call (instead of XOR-ing with bitmasks).
+//
+// A WOTS+ key must never sign two different messages - see XMSS for a many-time scheme.
+const (
+	wotsN    = 32 // length of hashes
+	wotsW    = 16
+	wotsLen1 = 64 // 2 * wotsN digits of base w
+	wotsLen2 = 3  // digits of the checksum (at most wotsLen1 * (wotsW - 1))
+	wotsLen  = wotsLen1 + wotsLen2
+)
+
+const (
+	addrWOTSChain = iota
+	addrWOTSPubKey
+	addrTreeNode
+	addrWOTSSecret
+	addrRandomizer
+)
+
+// hashAddress is a unique position of a hash call in the whole XMSS structure.
+type hashAddress struct {
+	typ    uint32
+	keyIdx uint32 // index of the WOTS+ key (leaf), or tree level for tree nodes
+	chain  uint32 // index of the chain, or index of the node in the level
+	step   uint32
+}
+
+func (a hashAddress) bytes() []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint32(b[0:], a.typ)
+	binary.BigEndian.PutUint32(b[4:], a.keyIdx)
+	binary.BigEndian.PutUint32(b[8:], a.chain)
+	binary.BigEndian.PutUint32(b[12:], a.step)
+	return b
+}
+
+// tweakedHash returns H(seed || address || inputs...).
+func tweakedHash(seed []byte, addr hashAddress, inputs ...[]byte) []byte {
+	h := sha256.New()
+	h.Write(seed)
+	h.Write(addr.bytes())
+	for _, in := range inputs {
+		h.Write(in)
+	}
+	return h.Sum(nil)
+}
+
+// wotsChain applies steps iterations of the chaining function to x, starting at start.
+func wotsChain(x []byte, start, steps int, pubSeed []byte, keyIdx, chain uint32) []byte {
+	for i := start; i < start+steps; i++ {
+		x = tweakedHash(pubSeed, hashAddress{addrWOTSChain, keyIdx, chain, uint32(i)}, x)
+	}
+	return x
+}
+
+// wotsDigits returns the message digest in base w followed by its checksum.
+func wotsDigits(msgHash []byte) []int {
+	digits := make([]int, 0, wotsLen)
+	for _, b := range msgHash {
+		digits = append(digits, int(b>>4), int(b&0x0f))
+	}
+	checksum := 0
+	for _, d := range digits {
+		checksum += wotsW - 1 - d
+	}
+	for i := wotsLen2 - 1; i >= 0; i-- {
+		digits = append(digits, (checksum>>(4*uint(i)))&0x0f)
+	}
+	return digits
+}
+
+func wotsSecretKey(skSeed []byte, keyIdx uint32, chain int) []byte {
+	return tweakedHash(skSeed, hashAddress{addrWOTSSecret, keyIdx, uint32(chain), 0})
+}
+
+// wotsPubKey returns the compressed public key of the keyIdx-th WOTS+ key.
+func wotsPubKey(skSeed, pubSeed []byte, keyIdx uint32) []byte {
+	ends := make([][]byte, wotsLen)
+	for i := range ends {
+		ends[i] = wotsChain(wotsSecretKey(skSeed, keyIdx, i), 0, wotsW-1, pubSeed, keyIdx,
+			uint32(i))
+	}
+	return tweakedHash(pubSeed, hashAddress{addrWOTSPubKey, keyIdx, 0, 0}, ends...)
+}
+
+// wotsSign signs the 32-byte message hash with the keyIdx-th WOTS+ key.
+func wotsSign(msgHash, skSeed, pubSeed []byte, keyIdx uint32) [][]byte {
+	sig := make([][]byte, wotsLen)
+	for i, d := range wotsDigits(msgHash) {
+		sig[i] = wotsChain(wotsSecretKey(skSeed, keyIdx, i), 0, d, pubSeed, keyIdx, uint32(i))
+	}
+	return sig
+}
+
+// wotsPubKeyFromSig returns the compressed public key which corresponds to the signature
+// (it equals the real public key only if the signature is valid).
+func wotsPubKeyFromSig(msgHash []byte, sig [][]byte, pubSeed []byte, keyIdx uint32) []byte {
+	ends := make([][]byte, wotsLen)
+	for i, d := range wotsDigits(msgHash) {
+		ends[i] = wotsChain(sig[i], d, wotsW-1-d, pubSeed, keyIdx, uint32(i))
+	}
+	return tweakedHash(pubSeed, hashAddress{addrWOTSPubKey, keyIdx, 0, 0}, ends...)
+}
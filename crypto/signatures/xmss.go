@@ -0,0 +1,188 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package signatures
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"sync"
+)
+
+// XMSS (Buchmann, Dahmen, Hulsing: XMSS - A Practical Forward Secure Signature Scheme
+// based on Minimal Security Assumptions, see also RFC 8391) combines 2^height WOTS+ keys
+// into a Merkle tree - the public key is the root. It is a post-quantum option for
+// the data which needs long-term authenticity but no blind or zero-knowledge properties
+// (revocation registries, epoch metadata, ...).
+//
+// XMSS is stateful: each WOTS+ key can be used only once, thus the index of the next unused
+// key needs to be stored reliably (see Index and NewXMSSFromSeeds) - reusing an index
+// compromises the security.
+type XMSS struct {
+	height  int
+	skSeed  []byte
+	pubSeed []byte
+	index   uint32
+	tree    [][][]byte // tree[level][node]
+	sync.Mutex
+}
+
+type XMSSPubKey struct {
+	Height  int
+	Root    []byte
+	PubSeed []byte
+}
+
+type XMSSSignature struct {
+	Index    uint32
+	R        []byte
+	WOTS     [][]byte
+	AuthPath [][]byte
+}
+
+// NewXMSS generates a key which can sign 2^height messages.
+func NewXMSS(height int) (*XMSS, error) {
+	skSeed := make([]byte, wotsN)
+	pubSeed := make([]byte, wotsN)
+	if _, err := rand.Read(skSeed); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(pubSeed); err != nil {
+		return nil, err
+	}
+	return NewXMSSFromSeeds(height, skSeed, pubSeed, 0)
+}
+
+// NewXMSSFromSeeds restores the key from its seeds and the index of the next unused WOTS+ key.
+func NewXMSSFromSeeds(height int, skSeed, pubSeed []byte, index uint32) (*XMSS, error) {
+	if height < 1 || height > 20 {
+		return nil, errors.New("height of XMSS tree needs to be between 1 and 20")
+	}
+
+	leaves := make([][]byte, 1<<uint(height))
+	for i := range leaves {
+		leaves[i] = wotsPubKey(skSeed, pubSeed, uint32(i))
+	}
+	tree := [][][]byte{leaves}
+	for level := 0; level < height; level++ {
+		nodes := make([][]byte, len(tree[level])/2)
+		for i := range nodes {
+			nodes[i] = tweakedHash(pubSeed, hashAddress{addrTreeNode, uint32(level + 1),
+				uint32(i), 0}, tree[level][2*i], tree[level][2*i+1])
+		}
+		tree = append(tree, nodes)
+	}
+
+	return &XMSS{
+		height:  height,
+		skSeed:  skSeed,
+		pubSeed: pubSeed,
+		index:   index,
+		tree:    tree,
+	}, nil
+}
+
+func (xmss *XMSS) GetPubKey() *XMSSPubKey {
+	return &XMSSPubKey{
+		Height:  xmss.height,
+		Root:    xmss.tree[xmss.height][0],
+		PubSeed: xmss.pubSeed,
+	}
+}
+
+// Index returns the index of the next unused WOTS+ key.
+func (xmss *XMSS) Index() uint32 {
+	xmss.Lock()
+	defer xmss.Unlock()
+	return xmss.index
+}
+
+// Remaining returns the number of messages which can still be signed.
+func (xmss *XMSS) Remaining() int {
+	xmss.Lock()
+	defer xmss.Unlock()
+	return (1 << uint(xmss.height)) - int(xmss.index)
+}
+
+// Sign signs the message with the next unused WOTS+ key.
+func (xmss *XMSS) Sign(msg []byte) (*XMSSSignature, error) {
+	xmss.Lock()
+	idx := xmss.index
+	if int(idx) >= 1<<uint(xmss.height) {
+		xmss.Unlock()
+		return nil, errors.New("all XMSS one-time keys were used")
+	}
+	xmss.index++
+	xmss.Unlock()
+
+	r := tweakedHash(xmss.skSeed, hashAddress{addrRandomizer, idx, 0, 0}, msg)
+	msgHash := xmssMsgHash(r, xmss.tree[xmss.height][0], idx, msg)
+
+	authPath := make([][]byte, xmss.height)
+	for level := range authPath {
+		authPath[level] = xmss.tree[level][(idx>>uint(level))^1]
+	}
+
+	return &XMSSSignature{
+		Index:    idx,
+		R:        r,
+		WOTS:     wotsSign(msgHash, xmss.skSeed, xmss.pubSeed, idx),
+		AuthPath: authPath,
+	}, nil
+}
+
+// VerifyXMSS checks the signature of the message.
+func VerifyXMSS(pubKey *XMSSPubKey, msg []byte, sig *XMSSSignature) bool {
+	if len(sig.WOTS) != wotsLen || len(sig.AuthPath) != pubKey.Height ||
+		int(sig.Index) >= 1<<uint(pubKey.Height) {
+		return false
+	}
+	for _, s := range sig.WOTS {
+		if len(s) != wotsN {
+			return false
+		}
+	}
+
+	msgHash := xmssMsgHash(sig.R, pubKey.Root, sig.Index, msg)
+	node := wotsPubKeyFromSig(msgHash, sig.WOTS, pubKey.PubSeed, sig.Index)
+	idx := sig.Index
+	for level, sibling := range sig.AuthPath {
+		addr := hashAddress{addrTreeNode, uint32(level + 1), idx >> 1, 0}
+		if idx&1 == 0 {
+			node = tweakedHash(pubKey.PubSeed, addr, node, sibling)
+		} else {
+			node = tweakedHash(pubKey.PubSeed, addr, sibling, node)
+		}
+		idx >>= 1
+	}
+	return bytes.Equal(node, pubKey.Root)
+}
+
+// xmssMsgHash returns the randomized hash of the message which is signed by WOTS+.
+func xmssMsgHash(r, root []byte, idx uint32, msg []byte) []byte {
+	idxBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(idxBytes, idx)
+	h := sha256.New()
+	h.Write(r)
+	h.Write(root)
+	h.Write(idxBytes)
+	h.Write(msg)
+	return h.Sum(nil)
+}
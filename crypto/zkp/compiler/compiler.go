@@ -0,0 +1,79 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package compiler turns any sigma protocol into a zero-knowledge proof (ZKP) or a
+// zero-knowledge proof of knowledge (ZKPOK) - see Damgard: On Sigma-protocols.
+// Before the sigma protocol starts, the prover sends h = g^trapdoor (opening message) and
+// the verifier commits to the challenge with a Pedersen commitment using h. Instead of
+// generating the challenge, the verifier of the sigma protocol then uses the decommitted
+// one (the prover checks the decommitment). In ZKPOK, the prover reveals the trapdoor at
+// the end of the protocol and the verifier checks it.
+//
+// The commitment group does not need to be the group of the sigma protocol - the challenges
+// are taken from [0, min(challenge space, order of the commitment group)).
+package compiler
+
+import (
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// Variant is the strategy which implements the differences between the sigma protocol,
+// ZKP and ZKPOK. Each variant is a separate type (Sigma, ZKP, ZKPOK) which provides its own
+// ChallengeReceiver and ChallengeCommitter, thus the code which runs the protocols does not
+// need to check the protocol type.
+type Variant interface {
+	ProtocolType() types.ProtocolType
+	// NewChallengeReceiver returns the prover's part of the variant.
+	NewChallengeReceiver(group *groups.SchnorrGroup) (ChallengeReceiver, error)
+	// NewChallengeCommitter returns the verifier's part of the variant for challenges
+	// from [0, challengeSpace).
+	NewChallengeCommitter(group *groups.SchnorrGroup, challengeSpace *big.Int) ChallengeCommitter
+}
+
+// ChallengeReceiver is the prover's part of the opening phase.
+type ChallengeReceiver interface {
+	// GetOpeningMsg returns h which the verifier uses for the commitment to the challenge
+	// (nil if there is no opening phase).
+	GetOpeningMsg() *big.Int
+	// SetCommitment stores the verifier's commitment to the challenge.
+	SetCommitment(commitment *big.Int)
+	// CheckChallenge checks that the challenge is the one the verifier committed to.
+	CheckChallenge(challenge, decommitment *big.Int) bool
+	// GetTrapdoor returns the trapdoor which needs to be revealed at the end of the
+	// protocol (nil if it is not revealed).
+	GetTrapdoor() *big.Int
+}
+
+// ChallengeCommitter is the verifier's part of the opening phase - it generates
+// the challenge and commits to it.
+type ChallengeCommitter interface {
+	// HasOpening returns true if the prover starts the protocol with the opening message.
+	HasOpening() bool
+	// GetOpeningMsgReply generates the challenge and returns the commitment to it.
+	GetOpeningMsgReply(h *big.Int) (*big.Int, error)
+	// GetChallenge returns the challenge and the decommitment (nil if the challenge
+	// was not committed).
+	GetChallenge() (*big.Int, *big.Int, error)
+	// RequiresTrapdoor returns true if the prover needs to reveal the trapdoor.
+	RequiresTrapdoor() bool
+	// VerifyTrapdoor checks the trapdoor which the prover reveals at the end of the protocol.
+	VerifyTrapdoor(trapdoor *big.Int) bool
+	// Verified combines the result of the sigma protocol with the checks of the variant.
+	Verified(proved bool) bool
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package compiler
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/groups"
+)
+
+// Params are the parameters of the compiler which need to be shared by the prover and
+// the verifier - the group in which the verifier commits to the challenge (in ZKP and ZKPOK).
+type Params struct {
+	Group *groups.SchnorrGroup
+}
+
+func NewParams(group *groups.SchnorrGroup) *Params {
+	return &Params{
+		Group: group,
+	}
+}
+
+// Validate checks that the commitment group is a valid Schnorr group.
+func (params *Params) Validate() error {
+	if params.Group == nil {
+		return fmt.Errorf("Commitment group is not set")
+	}
+	return params.Group.Validate()
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package compiler
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// Sigma is the sigma protocol: there is no opening phase, the verifier simply generates
+// the challenge.
+type Sigma struct{}
+
+func (Sigma) ProtocolType() types.ProtocolType {
+	return types.Sigma
+}
+
+func (Sigma) NewChallengeReceiver(group *groups.SchnorrGroup) (ChallengeReceiver, error) {
+	return sigmaReceiver{}, nil
+}
+
+func (Sigma) NewChallengeCommitter(group *groups.SchnorrGroup,
+	challengeSpace *big.Int) ChallengeCommitter {
+	return sigmaCommitter{challengeSpace: challengeSpace}
+}
+
+type sigmaReceiver struct{}
+
+func (sigmaReceiver) GetOpeningMsg() *big.Int {
+	return nil
+}
+
+func (sigmaReceiver) SetCommitment(commitment *big.Int) {}
+
+func (sigmaReceiver) CheckChallenge(challenge, decommitment *big.Int) bool {
+	return true
+}
+
+func (sigmaReceiver) GetTrapdoor() *big.Int {
+	return nil
+}
+
+type sigmaCommitter struct {
+	challengeSpace *big.Int
+}
+
+func (sigmaCommitter) HasOpening() bool {
+	return false
+}
+
+func (sigmaCommitter) GetOpeningMsgReply(h *big.Int) (*big.Int, error) {
+	return nil, fmt.Errorf("Sigma protocol has no opening phase.")
+}
+
+func (c sigmaCommitter) GetChallenge() (*big.Int, *big.Int, error) {
+	challenge, err := common.GetRandomInt(c.challengeSpace)
+	return challenge, nil, err
+}
+
+func (sigmaCommitter) RequiresTrapdoor() bool {
+	return false
+}
+
+func (sigmaCommitter) VerifyTrapdoor(trapdoor *big.Int) bool {
+	return false
+}
+
+func (sigmaCommitter) Verified(proved bool) bool {
+	return proved
+}
+
+// ZKP is the zero knowledge proof: the verifier commits to the challenge before the sigma
+// protocol starts.
+type ZKP struct{}
+
+func (ZKP) ProtocolType() types.ProtocolType {
+	return types.ZKP
+}
+
+func (ZKP) NewChallengeReceiver(group *groups.SchnorrGroup) (ChallengeReceiver, error) {
+	receiver, err := commitments.NewPedersenReceiver(group)
+	if err != nil {
+		return nil, err
+	}
+	return &zkpReceiver{receiver: receiver}, nil
+}
+
+func (ZKP) NewChallengeCommitter(group *groups.SchnorrGroup,
+	challengeSpace *big.Int) ChallengeCommitter {
+	return newZKPCommitter(group, challengeSpace)
+}
+
+func newZKPCommitter(group *groups.SchnorrGroup, challengeSpace *big.Int) *zkpCommitter {
+	if challengeSpace.Cmp(group.Q) > 0 {
+		challengeSpace = group.Q
+	}
+	return &zkpCommitter{
+		group:          group,
+		challengeSpace: challengeSpace,
+		committer:      commitments.NewPedersenCommitter(group),
+	}
+}
+
+type zkpReceiver struct {
+	receiver *commitments.PedersenReceiver
+}
+
+func (r *zkpReceiver) GetOpeningMsg() *big.Int {
+	return r.receiver.GetH()
+}
+
+func (r *zkpReceiver) SetCommitment(commitment *big.Int) {
+	r.receiver.SetCommitment(commitment)
+}
+
+func (r *zkpReceiver) CheckChallenge(challenge, decommitment *big.Int) bool {
+	return decommitment != nil && r.receiver.CheckDecommitment(decommitment, challenge)
+}
+
+func (r *zkpReceiver) GetTrapdoor() *big.Int {
+	return nil
+}
+
+type zkpCommitter struct {
+	group          *groups.SchnorrGroup
+	challengeSpace *big.Int
+	committer      *commitments.PedersenCommitter
+}
+
+func (c *zkpCommitter) HasOpening() bool {
+	return true
+}
+
+func (c *zkpCommitter) GetOpeningMsgReply(h *big.Int) (*big.Int, error) {
+	if h.Cmp(big.NewInt(1)) <= 0 || h.Cmp(c.group.P) >= 0 || !c.group.IsElementInGroup(h) {
+		return nil, fmt.Errorf("Opening message is not a valid group element.")
+	}
+	c.committer.SetH(h)
+	challenge, err := common.GetRandomInt(c.challengeSpace)
+	if err != nil {
+		return nil, err
+	}
+	return c.committer.GetCommitMsg(challenge)
+}
+
+func (c *zkpCommitter) GetChallenge() (*big.Int, *big.Int, error) {
+	challenge, decommitment := c.committer.GetDecommitMsg()
+	return challenge, decommitment, nil
+}
+
+func (c *zkpCommitter) RequiresTrapdoor() bool {
+	return false
+}
+
+func (c *zkpCommitter) VerifyTrapdoor(trapdoor *big.Int) bool {
+	return false
+}
+
+func (c *zkpCommitter) Verified(proved bool) bool {
+	return proved
+}
+
+// ZKPOK is the zero knowledge proof of knowledge: as ZKP, but the prover reveals the trapdoor at the end of the protocol.
+type ZKPOK struct{}
+
+func (ZKPOK) ProtocolType() types.ProtocolType {
+	return types.ZKPOK
+}
+
+func (ZKPOK) NewChallengeReceiver(group *groups.SchnorrGroup) (ChallengeReceiver, error) {
+	receiver, err := commitments.NewPedersenReceiver(group)
+	if err != nil {
+		return nil, err
+	}
+	return &zkpokReceiver{zkpReceiver{receiver: receiver}}, nil
+}
+
+func (ZKPOK) NewChallengeCommitter(group *groups.SchnorrGroup,
+	challengeSpace *big.Int) ChallengeCommitter {
+	return &zkpokCommitter{zkpCommitter: *newZKPCommitter(group, challengeSpace)}
+}
+
+type zkpokReceiver struct {
+	zkpReceiver
+}
+
+func (r *zkpokReceiver) GetTrapdoor() *big.Int {
+	return r.receiver.GetTrapdoor()
+}
+
+type zkpokCommitter struct {
+	zkpCommitter
+	trapdoorVerified bool
+}
+
+func (c *zkpokCommitter) RequiresTrapdoor() bool {
+	return true
+}
+
+func (c *zkpokCommitter) VerifyTrapdoor(trapdoor *big.Int) bool {
+	c.trapdoorVerified = trapdoor != nil && c.committer.VerifyTrapdoor(trapdoor)
+	return c.trapdoorVerified
+}
+
+// Verified accepts the proof only if the trapdoor was verified.
+func (c *zkpokCommitter) Verified(proved bool) bool {
+	return proved && c.trapdoorVerified
+}
+
+var (
+	_ Variant = Sigma{}
+	_ Variant = ZKP{}
+	_ Variant = ZKPOK{}
+)
@@ -0,0 +1,198 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package der encodes the non-interactive proofs and credentials in ASN.1 DER with object
+// identifiers, so that they can be carried in CMS and X.509 structures (for example as
+// the content of a SignedData or as the value of a certificate extension).
+//
+// Each object is encoded in the same way as CMS ContentInfo:
+//
+//	EncodedObject ::= SEQUENCE {
+//		type     OBJECT IDENTIFIER,
+//		content  [0] EXPLICIT ANY DEFINED BY type
+//	}
+//
+// The object identifiers are under the arc 2.25.102764937083622588717371394607346474509
+// (UUID based arc, see ITU-T X.667, which does not need a registration):
+//
+//	.1.1  Fiat-Shamir proof (fiatshamir.Proof)
+//	.1.2  Bulletproofs range proof (bulletproofs.RangeProof)
+//	.2.1  pseudonym system credential (pseudonymsys.Credential)
+//	.2.2  pseudonym system credential on elliptic curve (pseudonymsys.CredentialEC)
+//	.2.3  pseudonym system CA certificate (pseudonymsys.CACertificate)
+//	.2.4  pseudonym system CA certificate on elliptic curve (pseudonymsys.CACertificateEC)
+//	.3.1  Schnorr group (in the statement descriptors of proofs, fiatshamir.Descriptor)
+//	.3.2  group of quadratic residues modulo RSA modulus (fiatshamir.Descriptor)
+//
+// Note that the arc does not fit into asn1.ObjectIdentifier (its elements are of type int),
+// thus the identifiers are handled as strings.
+package der
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const arc = "2.25.102764937083622588717371394607346474509"
+
+const (
+	OIDFiatShamirProof           = arc + ".1.1"
+	OIDBulletproofsRangeProof    = arc + ".1.2"
+	OIDPseudonymsysCredential    = arc + ".2.1"
+	OIDPseudonymsysCredentialEC  = arc + ".2.2"
+	OIDPseudonymsysCertificate   = arc + ".2.3"
+	OIDPseudonymsysCertificateEC = arc + ".2.4"
+)
+
+type encodedObject struct {
+	Type    asn1.RawValue
+	Content asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+// explicit returns the DER encoding of [0] EXPLICIT with the DER encoded content (Marshal
+// ignores the explicit tag of RawValue with FullBytes).
+func explicit(content []byte) asn1.RawValue {
+	return asn1.RawValue{
+		Class:      asn1.ClassContextSpecific,
+		Tag:        0,
+		IsCompound: true,
+		Bytes:      content,
+	}
+}
+
+// wrap returns the DER encoding of the object of the given type with the DER
+// encoded content.
+func wrap(oid string, content []byte) ([]byte, error) {
+	oidBytes, err := marshalOID(oid)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(struct {
+		Type    asn1.RawValue
+		Content asn1.RawValue
+	}{
+		Type:    asn1.RawValue{FullBytes: oidBytes},
+		Content: explicit(content),
+	})
+}
+
+// unwrap returns the type and the DER encoded content of the object.
+func unwrap(data []byte) (string, []byte, error) {
+	var obj encodedObject
+	rest, err := asn1.Unmarshal(data, &obj)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(rest) > 0 {
+		return "", nil, fmt.Errorf("trailing data after the object")
+	}
+	if obj.Type.Class != asn1.ClassUniversal || obj.Type.Tag != asn1.TagOID {
+		return "", nil, fmt.Errorf("object type is not an object identifier")
+	}
+	oid, err := parseOID(obj.Type.Bytes)
+	if err != nil {
+		return "", nil, err
+	}
+	return oid, obj.Content.Bytes, nil
+}
+
+// TypeOf returns the object identifier of the encoded object.
+func TypeOf(data []byte) (string, error) {
+	oid, _, err := unwrap(data)
+	return oid, err
+}
+
+// marshalOID returns the DER encoding of the object identifier given in the dotted form.
+func marshalOID(oid string) ([]byte, error) {
+	parts := strings.Split(oid, ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid object identifier %s", oid)
+	}
+	arcs := make([]*big.Int, len(parts))
+	for i, p := range parts {
+		a, ok := new(big.Int).SetString(p, 10)
+		if !ok || a.Sign() < 0 {
+			return nil, fmt.Errorf("invalid object identifier %s", oid)
+		}
+		arcs[i] = a
+	}
+	if arcs[0].Cmp(big.NewInt(2)) > 0 ||
+		(arcs[0].Cmp(big.NewInt(2)) < 0 && arcs[1].Cmp(big.NewInt(40)) >= 0) {
+		return nil, fmt.Errorf("invalid object identifier %s", oid)
+	}
+
+	// the first two arcs are encoded as 40 * arc0 + arc1
+	first := new(big.Int).Mul(arcs[0], big.NewInt(40))
+	first.Add(first, arcs[1])
+	var content []byte
+	for _, a := range append([]*big.Int{first}, arcs[2:]...) {
+		content = append(content, base128(a)...)
+	}
+	return asn1.Marshal(asn1.RawValue{
+		Class: asn1.ClassUniversal,
+		Tag:   asn1.TagOID,
+		Bytes: content,
+	})
+}
+
+// parseOID returns the dotted form of the object identifier with the given DER content.
+func parseOID(content []byte) (string, error) {
+	var arcs []string
+	a := new(big.Int)
+	for i, b := range content {
+		if a.Sign() == 0 && b == 0x80 {
+			return "", fmt.Errorf("object identifier is not minimally encoded")
+		}
+		a.Lsh(a, 7)
+		a.Or(a, big.NewInt(int64(b&0x7f)))
+		if b&0x80 != 0 {
+			if i == len(content)-1 {
+				return "", fmt.Errorf("object identifier is truncated")
+			}
+			continue
+		}
+		if arcs == nil {
+			// the first two arcs are encoded as 40 * arc0 + arc1
+			arc0 := int64(2)
+			if a.Cmp(big.NewInt(80)) < 0 {
+				arc0 = a.Int64() / 40
+			}
+			arc1 := new(big.Int).Sub(a, big.NewInt(40*arc0))
+			arcs = append(arcs, fmt.Sprint(arc0), arc1.String())
+		} else {
+			arcs = append(arcs, a.String())
+		}
+		a = new(big.Int)
+	}
+	if len(arcs) == 0 {
+		return "", fmt.Errorf("object identifier is empty")
+	}
+	return strings.Join(arcs, "."), nil
+}
+
+// base128 returns x in base 128, most significant digit first, with the highest bit set
+// in all the digits but the last one.
+func base128(x *big.Int) []byte {
+	digits := []byte{byte(new(big.Int).And(x, big.NewInt(0x7f)).Int64())}
+	for y := new(big.Int).Rsh(x, 7); y.Sign() > 0; y.Rsh(y, 7) {
+		digit := byte(new(big.Int).And(y, big.NewInt(0x7f)).Int64()) | 0x80
+		digits = append([]byte{digit}, digits...)
+	}
+	return digits
+}
@@ -0,0 +1,310 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package der
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/bulletproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/fiatshamir"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// Marshal returns the DER encoding of the proof or credential, which is one of
+// *fiatshamir.Proof, *bulletproofs.RangeProof, *pseudonymsys.Credential,
+// *pseudonymsys.CredentialEC, *pseudonymsys.CACertificate and *pseudonymsys.CACertificateEC.
+func Marshal(obj interface{}) ([]byte, error) {
+	var oid string
+	var content []byte
+	var err error
+	switch o := obj.(type) {
+	case *fiatshamir.Proof:
+		oid = OIDFiatShamirProof
+		content, err = o.Marshal()
+	case *bulletproofs.RangeProof:
+		oid = OIDBulletproofsRangeProof
+		content, err = o.Marshal()
+	case *pseudonymsys.Credential:
+		oid = OIDPseudonymsysCredential
+		content, err = marshalCredential(o)
+	case *pseudonymsys.CredentialEC:
+		oid = OIDPseudonymsysCredentialEC
+		content, err = marshalCredentialEC(o)
+	case *pseudonymsys.CACertificate:
+		oid = OIDPseudonymsysCertificate
+		content, err = marshalCertificate(o)
+	case *pseudonymsys.CACertificateEC:
+		oid = OIDPseudonymsysCertificateEC
+		content, err = marshalCertificateEC(o)
+	default:
+		return nil, fmt.Errorf("encoding of %T is not supported", obj)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return wrap(oid, content)
+}
+
+// Unmarshal decodes the object encoded by Marshal. The type of the returned object is
+// determined by the object identifier (see TypeOf). Note that the values are not validated
+// (for example whether the points are on the curve) - this is done when the proofs and
+// credentials are verified.
+func Unmarshal(data []byte) (interface{}, error) {
+	oid, content, err := unwrap(data)
+	if err != nil {
+		return nil, err
+	}
+	switch oid {
+	case OIDFiatShamirProof:
+		var proof fiatshamir.Proof
+		if err := unmarshal(content, &proof); err != nil {
+			return nil, err
+		}
+		return &proof, nil
+	case OIDBulletproofsRangeProof:
+		return bulletproofs.UnmarshalRangeProof(content)
+	case OIDPseudonymsysCredential:
+		return unmarshalCredential(content)
+	case OIDPseudonymsysCredentialEC:
+		return unmarshalCredentialEC(content)
+	case OIDPseudonymsysCertificate:
+		return unmarshalCertificate(content)
+	case OIDPseudonymsysCertificateEC:
+		return unmarshalCertificateEC(content)
+	}
+	return nil, fmt.Errorf("object of type %s is not supported", oid)
+}
+
+// unmarshal decodes the content which must not be followed by any data.
+func unmarshal(content []byte, v interface{}) error {
+	rest, err := asn1.Unmarshal(content, v)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("trailing data after the content")
+	}
+	return nil
+}
+
+type encodedTranscript struct {
+	A, B, Hash, ZAlpha *big.Int
+}
+
+//	Credential ::= SEQUENCE {
+//		smallAToGamma, smallBToGamma, aToGamma, bToGamma INTEGER,
+//		t1, t2 SEQUENCE { a, b, hash, zAlpha INTEGER }
+//	}
+type encodedCredential struct {
+	SmallAToGamma, SmallBToGamma *big.Int
+	AToGamma, BToGamma           *big.Int
+	T1, T2                       encodedTranscript
+}
+
+func marshalCredential(c *pseudonymsys.Credential) ([]byte, error) {
+	if c.T1 == nil || c.T2 == nil {
+		return nil, fmt.Errorf("credential transcript is missing")
+	}
+	return asn1.Marshal(encodedCredential{
+		SmallAToGamma: c.SmallAToGamma,
+		SmallBToGamma: c.SmallBToGamma,
+		AToGamma:      c.AToGamma,
+		BToGamma:      c.BToGamma,
+		T1:            encodedTranscript(*c.T1),
+		T2:            encodedTranscript(*c.T2),
+	})
+}
+
+func unmarshalCredential(content []byte) (*pseudonymsys.Credential, error) {
+	var enc encodedCredential
+	if err := unmarshal(content, &enc); err != nil {
+		return nil, err
+	}
+	t1, t2 := dlogproofs.Transcript(enc.T1), dlogproofs.Transcript(enc.T2)
+	return pseudonymsys.NewCredential(enc.SmallAToGamma, enc.SmallBToGamma, enc.AToGamma,
+		enc.BToGamma, &t1, &t2), nil
+}
+
+// Point ::= SEQUENCE { x, y INTEGER }
+type encodedPoint struct {
+	X, Y *big.Int
+}
+
+func point(p *types.ECGroupElement) (encodedPoint, error) {
+	if p == nil {
+		return encodedPoint{}, fmt.Errorf("point is missing")
+	}
+	return encodedPoint{X: p.X, Y: p.Y}, nil
+}
+
+func points(ps ...*types.ECGroupElement) ([]encodedPoint, error) {
+	encoded := make([]encodedPoint, len(ps))
+	for i, p := range ps {
+		e, err := point(p)
+		if err != nil {
+			return nil, err
+		}
+		encoded[i] = e
+	}
+	return encoded, nil
+}
+
+func (p encodedPoint) element() *types.ECGroupElement {
+	return types.NewECGroupElement(p.X, p.Y)
+}
+
+type encodedTranscriptEC struct {
+	Alpha_1, Alpha_2, Beta_1, Beta_2, Hash, ZAlpha *big.Int
+}
+
+//	CredentialEC ::= SEQUENCE {
+//		smallAToGamma, smallBToGamma, aToGamma, bToGamma Point,
+//		t1, t2 SEQUENCE { alpha1, alpha2, beta1, beta2, hash, zAlpha INTEGER }
+//	}
+type encodedCredentialEC struct {
+	SmallAToGamma, SmallBToGamma encodedPoint
+	AToGamma, BToGamma           encodedPoint
+	T1, T2                       encodedTranscriptEC
+}
+
+func marshalCredentialEC(c *pseudonymsys.CredentialEC) ([]byte, error) {
+	if c.T1 == nil || c.T2 == nil {
+		return nil, fmt.Errorf("credential transcript is missing")
+	}
+	ps, err := points(c.SmallAToGamma, c.SmallBToGamma, c.AToGamma, c.BToGamma)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(encodedCredentialEC{
+		SmallAToGamma: ps[0],
+		SmallBToGamma: ps[1],
+		AToGamma:      ps[2],
+		BToGamma:      ps[3],
+		T1:            encodedTranscriptEC(*c.T1),
+		T2:            encodedTranscriptEC(*c.T2),
+	})
+}
+
+func unmarshalCredentialEC(content []byte) (*pseudonymsys.CredentialEC, error) {
+	var enc encodedCredentialEC
+	if err := unmarshal(content, &enc); err != nil {
+		return nil, err
+	}
+	t1, t2 := dlogproofs.TranscriptEC(enc.T1), dlogproofs.TranscriptEC(enc.T2)
+	return pseudonymsys.NewCredentialEC(enc.SmallAToGamma.element(),
+		enc.SmallBToGamma.element(), enc.AToGamma.element(), enc.BToGamma.element(),
+		&t1, &t2), nil
+}
+
+type encodedSCT struct {
+	Timestamp int64
+	R, S      *big.Int
+}
+
+//	CACertificate ::= SEQUENCE {
+//		blindedA, blindedB, r, s INTEGER,
+//		algorithm INTEGER,
+//		sct [0] EXPLICIT SEQUENCE { timestamp, r, s INTEGER } OPTIONAL
+//	}
+type encodedCertificate struct {
+	BlindedA, BlindedB *big.Int
+	R, S               *big.Int
+	Algorithm          int
+	SCT                asn1.RawValue `asn1:"optional,explicit,tag:0"`
+}
+
+// certificateWithSCT is encodedCertificate for marshalling (see explicit).
+type certificateWithSCT struct {
+	BlindedA, BlindedB *big.Int
+	R, S               *big.Int
+	Algorithm          int
+	SCT                asn1.RawValue
+}
+
+func marshalCertificate(c *pseudonymsys.CACertificate) ([]byte, error) {
+	enc := encodedCertificate{
+		BlindedA:  c.BlindedA,
+		BlindedB:  c.BlindedB,
+		R:         c.R,
+		S:         c.S,
+		Algorithm: int(c.Algorithm),
+	}
+	if c.SCT == nil {
+		return asn1.Marshal(enc)
+	}
+	sct, err := asn1.Marshal(encodedSCT(*c.SCT))
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(certificateWithSCT{
+		BlindedA:  enc.BlindedA,
+		BlindedB:  enc.BlindedB,
+		R:         enc.R,
+		S:         enc.S,
+		Algorithm: enc.Algorithm,
+		SCT:       explicit(sct),
+	})
+}
+
+func unmarshalCertificate(content []byte) (*pseudonymsys.CACertificate, error) {
+	var enc encodedCertificate
+	if err := unmarshal(content, &enc); err != nil {
+		return nil, err
+	}
+	cert := pseudonymsys.NewCACertificate(enc.BlindedA, enc.BlindedB, enc.R, enc.S,
+		pseudonymsys.SignatureAlgorithm(enc.Algorithm))
+	if len(enc.SCT.FullBytes) > 0 {
+		var sct encodedSCT
+		if err := unmarshal(enc.SCT.Bytes, &sct); err != nil {
+			return nil, err
+		}
+		cert.SCT = pseudonymsys.NewSignedCertificateTimestamp(sct.Timestamp, sct.R, sct.S)
+	}
+	return cert, nil
+}
+
+// CACertificateEC ::= SEQUENCE { blindedA, blindedB Point, r, s INTEGER }
+type encodedCertificateEC struct {
+	BlindedA, BlindedB encodedPoint
+	R, S               *big.Int
+}
+
+func marshalCertificateEC(c *pseudonymsys.CACertificateEC) ([]byte, error) {
+	ps, err := points(c.BlindedA, c.BlindedB)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(encodedCertificateEC{
+		BlindedA: ps[0],
+		BlindedB: ps[1],
+		R:        c.R,
+		S:        c.S,
+	})
+}
+
+func unmarshalCertificateEC(content []byte) (*pseudonymsys.CACertificateEC, error) {
+	var enc encodedCertificateEC
+	if err := unmarshal(content, &enc); err != nil {
+		return nil, err
+	}
+	return pseudonymsys.NewCACertificateEC(enc.BlindedA.element(), enc.BlindedB.element(),
+		enc.R, enc.S), nil
+}
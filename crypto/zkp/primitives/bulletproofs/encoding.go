@@ -0,0 +1,111 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package bulletproofs
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// encodedRangeProof is the ASN.1 form of RangeProof. Points holds the coordinates of
+// A, S, T1, T2, L_1, ..., L_k, R_1, ..., R_k and Scalars holds TauX, Mu, THat, a, b.
+type encodedRangeProof struct {
+	Points  []*big.Int
+	Scalars []*big.Int
+}
+
+// Marshal encodes the proof (ASN.1 DER), so that it can be stored or sent as a blob.
+func (proof *RangeProof) Marshal() ([]byte, error) {
+	if proof.IPP == nil || len(proof.IPP.L) != len(proof.IPP.R) {
+		return nil, fmt.Errorf("inner-product proof is missing or incomplete")
+	}
+	points := []*types.ECGroupElement{proof.A, proof.S, proof.T1, proof.T2}
+	points = append(points, proof.IPP.L...)
+	points = append(points, proof.IPP.R...)
+	enc := encodedRangeProof{
+		Scalars: []*big.Int{proof.TauX, proof.Mu, proof.THat, proof.IPP.A, proof.IPP.B},
+	}
+	for _, p := range points {
+		if p == nil {
+			return nil, fmt.Errorf("point is missing")
+		}
+		enc.Points = append(enc.Points, p.X, p.Y)
+	}
+	return asn1.Marshal(enc)
+}
+
+// UnmarshalRangeProof decodes the proof encoded by Marshal. It does not check whether the
+// points are on the curve - this is done in VerifyRange.
+func UnmarshalRangeProof(data []byte) (*RangeProof, error) {
+	var enc encodedRangeProof
+	rest, err := asn1.Unmarshal(data, &enc)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("trailing data after range proof")
+	}
+	if len(enc.Scalars) != 5 || len(enc.Points) < 8 || len(enc.Points)%4 != 0 {
+		return nil, fmt.Errorf("malformed range proof")
+	}
+
+	points := make([]*types.ECGroupElement, len(enc.Points)/2)
+	for i := range points {
+		points[i] = types.NewECGroupElement(enc.Points[2*i], enc.Points[2*i+1])
+	}
+	k := (len(points) - 4) / 2
+	return &RangeProof{
+		A:    points[0],
+		S:    points[1],
+		T1:   points[2],
+		T2:   points[3],
+		TauX: enc.Scalars[0],
+		Mu:   enc.Scalars[1],
+		THat: enc.Scalars[2],
+		IPP: &InnerProductProof{
+			L: points[4 : 4+k],
+			R: points[4+k:],
+			A: enc.Scalars[3],
+			B: enc.Scalars[4],
+		},
+	}, nil
+}
+
+// VerifyRangeProof checks the encoded proof that the values committed in commitments are
+// from [0, 2^n). It needs only the bytes and the public parameters, for example:
+//
+//	ok := bulletproofs.VerifyRangeProof(proof, dlog.P256, 64, commitments)
+//
+// Generating the parameters is not cheap, thus when many proofs are to be verified,
+// Params should be created once and UnmarshalRangeProof with VerifyRange used instead.
+// Proofs which cannot be decoded are invalid.
+func VerifyRangeProof(data []byte, curve dlog.Curve, n int,
+	commitments []*types.ECGroupElement) bool {
+	proof, err := UnmarshalRangeProof(data)
+	if err != nil {
+		return false
+	}
+	params, err := NewParams(curve, n, len(commitments))
+	if err != nil {
+		return false
+	}
+	return params.VerifyRange(commitments, proof)
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package bulletproofs
+
+import (
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// InnerProductProof proves the knowledge of vectors a, b such that
+// P = Gs^a * Hs^b * U^<a, b> (Protocol 2 from the Bulletproofs paper). The proof consists of
+// log(n) pairs (L, R) and the final a, b.
+type InnerProductProof struct {
+	L []*types.ECGroupElement
+	R []*types.ECGroupElement
+	A *big.Int
+	B *big.Int
+}
+
+// proveInnerProduct returns a proof of knowledge of a, b such that
+// P = gs^a * hs^b * u^<a, b>. The length of vectors needs to be a power of 2.
+// The challenges are derived from the transcript t, which needs to contain P.
+func (params *Params) proveInnerProduct(t *transcript, gs, hs []*types.ECGroupElement,
+	u *types.ECGroupElement, a, b []*big.Int) *InnerProductProof {
+	q := params.DLog.OrderOfSubgroup
+	proof := &InnerProductProof{}
+	for n := len(a); n > 1; n /= 2 {
+		n1 := n / 2
+		cL := innerProduct(a[:n1], b[n1:], q)
+		cR := innerProduct(a[n1:], b[:n1], q)
+		L := params.add(params.multiExp(append(gs[n1:n:n], hs[:n1]...), append(a[:n1:n1], b[n1:]...)),
+			params.mul(u, cL))
+		R := params.add(params.multiExp(append(gs[:n1:n1], hs[n1:]...), append(a[n1:n:n], b[:n1]...)),
+			params.mul(u, cR))
+		proof.L = append(proof.L, L)
+		proof.R = append(proof.R, R)
+
+		t.appendPoints(L, R)
+		x := t.challenge()
+		xInv := new(big.Int).ModInverse(x, q)
+
+		gs, hs = params.foldGenerators(gs, hs, x, xInv)
+		aNew := make([]*big.Int, n1)
+		bNew := make([]*big.Int, n1)
+		for i := 0; i < n1; i++ {
+			// a' = a_L * x + a_R * x^-1, b' = b_L * x^-1 + b_R * x
+			aNew[i] = linComb(a[i], x, a[n1+i], xInv, q)
+			bNew[i] = linComb(b[i], xInv, b[n1+i], x, q)
+		}
+		a, b = aNew, bNew
+	}
+	proof.A = a[0]
+	proof.B = b[0]
+	return proof
+}
+
+// verifyInnerProduct checks the proof for P (P needs to be already in the transcript t).
+func (params *Params) verifyInnerProduct(t *transcript, gs, hs []*types.ECGroupElement,
+	u, P *types.ECGroupElement, proof *InnerProductProof) bool {
+	q := params.DLog.OrderOfSubgroup
+	rounds := 0
+	for n := len(gs); n > 1; n /= 2 {
+		rounds++
+	}
+	if len(proof.L) != rounds || len(proof.R) != rounds || proof.A == nil || proof.B == nil ||
+		!params.isValid(proof.L...) || !params.isValid(proof.R...) {
+		return false
+	}
+
+	for i := 0; i < rounds; i++ {
+		t.appendPoints(proof.L[i], proof.R[i])
+		x := t.challenge()
+		xInv := new(big.Int).ModInverse(x, q)
+		x2 := new(big.Int).Mul(x, x)
+		x2Inv := new(big.Int).Mul(xInv, xInv)
+
+		gs, hs = params.foldGenerators(gs, hs, x, xInv)
+		// P' = L^(x^2) * P * R^(x^-2)
+		P = params.add(params.add(params.mul(proof.L[i], x2), P), params.mul(proof.R[i], x2Inv))
+	}
+
+	ab := new(big.Int).Mul(proof.A, proof.B)
+	expected := params.add(params.add(params.mul(gs[0], proof.A), params.mul(hs[0], proof.B)),
+		params.mul(u, ab))
+	return expected.X.Cmp(P.X) == 0 && expected.Y.Cmp(P.Y) == 0
+}
+
+// foldGenerators returns gs' = gs_L^(x^-1) * gs_R^x and hs' = hs_L^x * hs_R^(x^-1).
+func (params *Params) foldGenerators(gs, hs []*types.ECGroupElement, x, xInv *big.Int) (
+	[]*types.ECGroupElement, []*types.ECGroupElement) {
+	n1 := len(gs) / 2
+	gsNew := make([]*types.ECGroupElement, n1)
+	hsNew := make([]*types.ECGroupElement, n1)
+	for i := 0; i < n1; i++ {
+		gsNew[i] = params.add(params.mul(gs[i], xInv), params.mul(gs[n1+i], x))
+		hsNew[i] = params.add(params.mul(hs[i], x), params.mul(hs[n1+i], xInv))
+	}
+	return gsNew, hsNew
+}
+
+// innerProduct returns <a, b> mod q.
+func innerProduct(a, b []*big.Int, q *big.Int) *big.Int {
+	result := new(big.Int)
+	for i := range a {
+		result.Add(result, new(big.Int).Mul(a[i], b[i]))
+	}
+	return result.Mod(result, q)
+}
+
+// linComb returns a * x + b * y mod q.
+func linComb(a, x, b, y, q *big.Int) *big.Int {
+	result := new(big.Int).Mul(a, x)
+	result.Add(result, new(big.Int).Mul(b, y))
+	return result.Mod(result, q)
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package bulletproofs implements Bulletproofs (B. Bünz, J. Bootle, D. Boneh, A. Poelstra,
+// P. Wuille, G. Maxwell: Bulletproofs: Short Proofs for Confidential Transactions and More)
+// over elliptic curves: the inner-product argument and the (aggregated) range proof for
+// Pedersen commitments V = g^v * h^gamma. The size of the proof is logarithmic in the number
+// of bits and committed values. The proofs are non-interactive (Fiat-Shamir).
+package bulletproofs
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// Params holds the generators of the Bulletproofs. Nobody knows the dlogs between them
+// (except G, all of them are obtained by hashing into the curve).
+type Params struct {
+	DLog *dlog.ECDLog
+	G    *types.ECGroupElement // base for committed values (base point of the curve)
+	H    *types.ECGroupElement // base for randomness
+	Gs   []*types.ECGroupElement
+	Hs   []*types.ECGroupElement
+	N    int // bit length of the values in range proofs - values are from [0, 2^N)
+	M    int // maximum number of values in the aggregated range proof
+}
+
+// NewParams returns the parameters for proving that up to m values are from [0, 2^n).
+// Both n and m need to be powers of 2.
+func NewParams(curve dlog.Curve, n, m int) (*Params, error) {
+	dLog := dlog.NewECDLog(curve)
+	if !isPowerOfTwo(n) || !isPowerOfTwo(m) {
+		return nil, fmt.Errorf("bit length and number of values need to be powers of 2")
+	}
+	if n >= dLog.OrderOfSubgroup.BitLen() {
+		return nil, fmt.Errorf("bit length %d is too large for the curve", n)
+	}
+
+	curveParams := dLog.Curve.Params()
+	params := &Params{
+		DLog: dLog,
+		G:    types.NewECGroupElement(curveParams.Gx, curveParams.Gy),
+		H:    hashIntoCurve(dLog, "H", 0),
+		Gs:   make([]*types.ECGroupElement, n*m),
+		Hs:   make([]*types.ECGroupElement, n*m),
+		N:    n,
+		M:    m,
+	}
+	for i := 0; i < n*m; i++ {
+		params.Gs[i] = hashIntoCurve(dLog, "G", i)
+		params.Hs[i] = hashIntoCurve(dLog, "H", i+1)
+	}
+	return params, nil
+}
+
+// Commit returns the Pedersen commitment g^v * h^gamma.
+func (params *Params) Commit(v, gamma *big.Int) *types.ECGroupElement {
+	return params.add(params.mul(params.G, v), params.mul(params.H, gamma))
+}
+
+// hashIntoCurve deterministically maps the label and index into a point on the curve
+// (try-and-increment). NIST curves have cofactor 1, thus each point is in the group.
+func hashIntoCurve(dLog *dlog.ECDLog, label string, index int) *types.ECGroupElement {
+	curveParams := dLog.Curve.Params()
+	p := curveParams.P
+	three := big.NewInt(3)
+	for counter := uint32(0); ; counter++ {
+		h := sha512.New()
+		h.Write([]byte("emmy/bulletproofs/" + curveParams.Name + "/" + label))
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint32(buf, uint32(index))
+		binary.BigEndian.PutUint32(buf[4:], counter)
+		h.Write(buf)
+
+		x := new(big.Int).SetBytes(h.Sum(nil))
+		x.Mod(x, p)
+		// y^2 = x^3 - 3x + b
+		y2 := new(big.Int).Exp(x, three, p)
+		y2.Sub(y2, new(big.Int).Mul(three, x))
+		y2.Add(y2, curveParams.B)
+		y2.Mod(y2, p)
+		if y := new(big.Int).ModSqrt(y2, p); y != nil && dLog.Curve.IsOnCurve(x, y) {
+			return types.NewECGroupElement(x, y)
+		}
+	}
+}
+
+// add returns a + b.
+func (params *Params) add(a, b *types.ECGroupElement) *types.ECGroupElement {
+	x, y := params.DLog.Multiply(a.X, a.Y, b.X, b.Y)
+	return types.NewECGroupElement(x, y)
+}
+
+// mul returns a^e (e is reduced modulo the group order, thus it can be negative).
+func (params *Params) mul(a *types.ECGroupElement, e *big.Int) *types.ECGroupElement {
+	e = new(big.Int).Mod(e, params.DLog.OrderOfSubgroup)
+	x, y := params.DLog.Exponentiate(a.X, a.Y, e)
+	return types.NewECGroupElement(x, y)
+}
+
+// multiExp returns prod(bases[i]^exps[i]).
+func (params *Params) multiExp(bases []*types.ECGroupElement, exps []*big.Int) *types.ECGroupElement {
+	result := types.NewECGroupElement(new(big.Int), new(big.Int)) // point at infinity
+	for i, base := range bases {
+		result = params.add(result, params.mul(base, exps[i]))
+	}
+	return result
+}
+
+// isValid checks that the point received in the proof is on the curve (operations with
+// the points which are not on the curve might panic).
+func (params *Params) isValid(points ...*types.ECGroupElement) bool {
+	for _, p := range points {
+		if p == nil || p.X == nil || p.Y == nil || !params.DLog.Curve.IsOnCurve(p.X, p.Y) {
+			return false
+		}
+	}
+	return true
+}
+
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
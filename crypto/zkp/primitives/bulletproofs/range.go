@@ -0,0 +1,270 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package bulletproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// RangeProof is an (aggregated) proof that the values committed in V_1, ..., V_m are
+// from [0, 2^N). Its size is 2 * log(N * m) + 4 group elements and 5 integers.
+type RangeProof struct {
+	A    *types.ECGroupElement
+	S    *types.ECGroupElement
+	T1   *types.ECGroupElement
+	T2   *types.ECGroupElement
+	TauX *big.Int
+	Mu   *big.Int
+	THat *big.Int
+	IPP  *InnerProductProof
+}
+
+// ProveRange returns the proof that values (committed as g^v_j * h^gamma_j, see Commit)
+// are from [0, 2^N). The number of values needs to be a power of 2 and at most M.
+func (params *Params) ProveRange(values, gammas []*big.Int) (*RangeProof, error) {
+	m := len(values)
+	if !isPowerOfTwo(m) || m > params.M || len(gammas) != m {
+		return nil, fmt.Errorf("number of values needs to be a power of 2 and at most %d", params.M)
+	}
+	n := params.N
+	nm := n * m
+	q := params.DLog.OrderOfSubgroup
+	for _, v := range values {
+		if v.Sign() < 0 || v.BitLen() > n {
+			return nil, fmt.Errorf("value %v is not from [0, 2^%d)", v, n)
+		}
+	}
+
+	t := newTranscript("emmy/bulletproofs/range", q)
+	t.appendInts(big.NewInt(int64(n)), big.NewInt(int64(m)))
+	for j := range values {
+		t.appendPoints(params.Commit(values[j], gammas[j]))
+	}
+
+	// aL holds the bits of the values, aR = aL - 1
+	aL := make([]*big.Int, nm)
+	aR := make([]*big.Int, nm)
+	for j, v := range values {
+		for i := 0; i < n; i++ {
+			aL[j*n+i] = big.NewInt(int64(v.Bit(i)))
+			aR[j*n+i] = new(big.Int).Sub(aL[j*n+i], big.NewInt(1))
+		}
+	}
+	blinding, err := randomScalars(2*nm+4, q)
+	if err != nil {
+		return nil, err
+	}
+	sL, sR := blinding[:nm], blinding[nm:2*nm]
+	alpha, rho := blinding[2*nm], blinding[2*nm+1]
+	tau1, tau2 := blinding[2*nm+2], blinding[2*nm+3]
+	gs, hs := params.Gs[:nm], params.Hs[:nm]
+
+	A := params.add(params.mul(params.H, alpha), params.multiExp(append(gs[:nm:nm], hs...),
+		append(aL[:nm:nm], aR...)))
+	S := params.add(params.mul(params.H, rho), params.multiExp(append(gs[:nm:nm], hs...),
+		append(sL[:nm:nm], sR...)))
+	t.appendPoints(A, S)
+	y := t.challenge()
+	z := t.challenge()
+
+	// l(X) = (aL - z) + sL * X
+	// r(X) = y^nm o (aR + z + sR * X) + sum_j z^(1+j) * (0^((j-1)n) || 2^n || 0^((m-j)n))
+	yPows := powers(y, nm, q)
+	zs2 := zPowersOfTwo(z, n, m, q)
+	l0 := make([]*big.Int, nm)
+	r0 := make([]*big.Int, nm)
+	r1 := make([]*big.Int, nm)
+	for i := 0; i < nm; i++ {
+		l0[i] = new(big.Int).Sub(aL[i], z)
+		r0[i] = new(big.Int).Add(aR[i], z)
+		r0[i].Mul(r0[i], yPows[i])
+		r0[i].Add(r0[i], zs2[i])
+		r0[i].Mod(r0[i], q)
+		r1[i] = new(big.Int).Mul(yPows[i], sR[i])
+		r1[i].Mod(r1[i], q)
+	}
+	// t(X) = <l(X), r(X)> = t0 + t1 * X + t2 * X^2
+	t1 := new(big.Int).Add(innerProduct(l0, r1, q), innerProduct(sL, r0, q))
+	t1.Mod(t1, q)
+	t2 := innerProduct(sL, r1, q)
+
+	T1 := params.Commit(t1, tau1)
+	T2 := params.Commit(t2, tau2)
+	t.appendPoints(T1, T2)
+	x := t.challenge()
+
+	// tauX = tau2 * x^2 + tau1 * x + sum_j z^(1+j) * gamma_j
+	tauX := linComb(tau2, new(big.Int).Mul(x, x), tau1, x, q)
+	zPow := new(big.Int).Set(z)
+	for _, gamma := range gammas {
+		zPow.Mul(zPow, z)
+		tauX.Add(tauX, new(big.Int).Mul(zPow, gamma))
+	}
+	tauX.Mod(tauX, q)
+	mu := linComb(alpha, big.NewInt(1), rho, x, q)
+
+	l := make([]*big.Int, nm)
+	r := make([]*big.Int, nm)
+	for i := 0; i < nm; i++ {
+		l[i] = linComb(l0[i], big.NewInt(1), sL[i], x, q)
+		r[i] = linComb(r0[i], big.NewInt(1), r1[i], x, q)
+	}
+	tHat := innerProduct(l, r, q)
+
+	t.appendInts(tauX, mu, tHat)
+	w := t.challenge()
+	u := params.mul(params.G, w)
+
+	return &RangeProof{
+		A:    A,
+		S:    S,
+		T1:   T1,
+		T2:   T2,
+		TauX: tauX,
+		Mu:   mu,
+		THat: tHat,
+		IPP:  params.proveInnerProduct(t, gs, params.scaledHs(hs, y), u, l, r),
+	}, nil
+}
+
+// VerifyRange checks that the values committed in commitments are from [0, 2^N).
+func (params *Params) VerifyRange(commitments []*types.ECGroupElement, proof *RangeProof) bool {
+	m := len(commitments)
+	if !isPowerOfTwo(m) || m > params.M || proof == nil || proof.IPP == nil ||
+		proof.TauX == nil || proof.Mu == nil || proof.THat == nil ||
+		!params.isValid(commitments...) || !params.isValid(proof.A, proof.S, proof.T1, proof.T2) {
+		return false
+	}
+	n := params.N
+	nm := n * m
+	q := params.DLog.OrderOfSubgroup
+
+	t := newTranscript("emmy/bulletproofs/range", q)
+	t.appendInts(big.NewInt(int64(n)), big.NewInt(int64(m)))
+	t.appendPoints(commitments...)
+	t.appendPoints(proof.A, proof.S)
+	y := t.challenge()
+	z := t.challenge()
+	t.appendPoints(proof.T1, proof.T2)
+	x := t.challenge()
+	t.appendInts(proof.TauX, proof.Mu, proof.THat)
+	w := t.challenge()
+
+	// g^tHat * h^tauX = V^(z^2 * z^m) * g^delta(y, z) * T1^x * T2^(x^2) where
+	// delta(y, z) = (z - z^2) * <1, y^nm> - sum_j z^(j+2) * <1, 2^n>
+	yPows := powers(y, nm, q)
+	zSq := new(big.Int).Mul(z, z)
+	delta := new(big.Int).Sub(z, zSq)
+	delta.Mul(delta, sum(yPows))
+	twoN := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(n)), big.NewInt(1))
+	zPow := new(big.Int).Set(zSq)
+	zExps := make([]*big.Int, m)
+	for j := 0; j < m; j++ {
+		zExps[j] = new(big.Int).Set(zPow)
+		zPow.Mul(zPow, z)
+		delta.Sub(delta, new(big.Int).Mul(zPow, twoN))
+	}
+	delta.Mod(delta, q)
+
+	left := params.Commit(proof.THat, proof.TauX)
+	right := params.add(params.multiExp(commitments, zExps), params.mul(params.G, delta))
+	right = params.add(right, params.mul(proof.T1, x))
+	right = params.add(right, params.mul(proof.T2, new(big.Int).Mul(x, x)))
+	if left.X.Cmp(right.X) != 0 || left.Y.Cmp(right.Y) != 0 {
+		return false
+	}
+
+	// P = A * S^x * gs^(-z) * hs'^(z * y^nm + zs2) * h^(-mu), where hs'_i = hs_i^(y^-i)
+	gs, hs := params.Gs[:nm], params.scaledHs(params.Hs[:nm], y)
+	zs2 := zPowersOfTwo(z, n, m, q)
+	gExps := make([]*big.Int, nm)
+	hExps := make([]*big.Int, nm)
+	for i := 0; i < nm; i++ {
+		gExps[i] = new(big.Int).Neg(z)
+		hExps[i] = linComb(z, yPows[i], zs2[i], big.NewInt(1), q)
+	}
+	P := params.add(proof.A, params.mul(proof.S, x))
+	P = params.add(P, params.multiExp(append(gs[:nm:nm], hs...), append(gExps, hExps...)))
+	P = params.add(P, params.mul(params.H, new(big.Int).Neg(proof.Mu)))
+	// the inner-product argument is for P * u^tHat
+	u := params.mul(params.G, w)
+	P = params.add(P, params.mul(u, proof.THat))
+
+	return params.verifyInnerProduct(t, gs, hs, u, P, proof.IPP)
+}
+
+// scaledHs returns hs_i^(y^-i).
+func (params *Params) scaledHs(hs []*types.ECGroupElement, y *big.Int) []*types.ECGroupElement {
+	q := params.DLog.OrderOfSubgroup
+	yInvPows := powers(new(big.Int).ModInverse(y, q), len(hs), q)
+	result := make([]*types.ECGroupElement, len(hs))
+	for i, h := range hs {
+		result[i] = params.mul(h, yInvPows[i])
+	}
+	return result
+}
+
+// powers returns 1, x, x^2, ..., x^(n-1) modulo q.
+func powers(x *big.Int, n int, q *big.Int) []*big.Int {
+	result := make([]*big.Int, n)
+	p := big.NewInt(1)
+	for i := 0; i < n; i++ {
+		result[i] = new(big.Int).Set(p)
+		p.Mul(p, x)
+		p.Mod(p, q)
+	}
+	return result
+}
+
+// zPowersOfTwo returns the vector z^(1+j) * 2^i at position (j-1) * n + i (for j = 1, ..., m).
+func zPowersOfTwo(z *big.Int, n, m int, q *big.Int) []*big.Int {
+	result := make([]*big.Int, n*m)
+	zPow := new(big.Int).Set(z)
+	for j := 0; j < m; j++ {
+		zPow.Mul(zPow, z)
+		for i := 0; i < n; i++ {
+			result[j*n+i] = new(big.Int).Lsh(zPow, uint(i))
+			result[j*n+i].Mod(result[j*n+i], q)
+		}
+	}
+	return result
+}
+
+func sum(values []*big.Int) *big.Int {
+	result := new(big.Int)
+	for _, v := range values {
+		result.Add(result, v)
+	}
+	return result
+}
+
+// randomScalars returns k random elements of Z_q.
+func randomScalars(k int, q *big.Int) ([]*big.Int, error) {
+	result := make([]*big.Int, k)
+	for i := range result {
+		r, err := common.GetRandomInt(q)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = r
+	}
+	return result, nil
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package bulletproofs
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// transcript derives the challenges of the non-interactive proof from all the messages
+// which were exchanged so far (Fiat-Shamir). Each value is prefixed with its length.
+type transcript struct {
+	state []byte
+	order *big.Int
+}
+
+func newTranscript(label string, order *big.Int) *transcript {
+	t := &transcript{order: order}
+	t.append([]byte(label))
+	return t
+}
+
+func (t *transcript) append(b []byte) {
+	l := make([]byte, 8)
+	binary.BigEndian.PutUint64(l, uint64(len(b)))
+	t.state = append(t.state, l...)
+	t.state = append(t.state, b...)
+}
+
+func (t *transcript) appendInts(values ...*big.Int) {
+	for _, v := range values {
+		t.append(v.Bytes())
+	}
+}
+
+func (t *transcript) appendPoints(points ...*types.ECGroupElement) {
+	for _, p := range points {
+		t.appendInts(p.X, p.Y)
+	}
+}
+
+// challenge returns a non-zero challenge and appends it to the transcript.
+func (t *transcript) challenge() *big.Int {
+	for {
+		h := sha512.Sum512(t.state)
+		c := new(big.Int).SetBytes(h[:])
+		c.Mod(c, t.order)
+		t.append(h[:])
+		if c.Sign() != 0 {
+			return c
+		}
+	}
+}
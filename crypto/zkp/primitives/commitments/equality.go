@@ -0,0 +1,357 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package commitmentzkp
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// EqualityCommitment is a commitment C = g^x * h^r in some group, as needed by the proof
+// that several commitments (possibly in different groups) contain the same value.
+// Group elements are encoded as vectors of integers (coordinates for EC points).
+type EqualityCommitment interface {
+	// Commit returns g^x * h^r.
+	Commit(x, r *big.Int) []*big.Int
+	// MulCommitment returns t * C^c.
+	MulCommitment(t []*big.Int, c *big.Int) []*big.Int
+	// Order returns the order of the group or nil if it is hidden.
+	Order() *big.Int
+	// RandomnessBound returns the bound for the randomness r when the order is hidden.
+	RandomnessBound() *big.Int
+}
+
+// PedersenEqualityCommitment is Pedersen commitment C = g^x * h^r in SchnorrGroup.
+type PedersenEqualityCommitment struct {
+	Group *groups.SchnorrGroup
+	H     *big.Int
+	C     *big.Int
+}
+
+func NewPedersenEqualityCommitment(group *groups.SchnorrGroup,
+	h, c *big.Int) *PedersenEqualityCommitment {
+	return &PedersenEqualityCommitment{
+		Group: group,
+		H:     h,
+		C:     c,
+	}
+}
+
+func (com *PedersenEqualityCommitment) Commit(x, r *big.Int) []*big.Int {
+	return []*big.Int{common.MultiExp([]*big.Int{com.Group.G, com.H}, []*big.Int{x, r},
+		com.Group.P)}
+}
+
+func (com *PedersenEqualityCommitment) MulCommitment(t []*big.Int, c *big.Int) []*big.Int {
+	return []*big.Int{com.Group.Mul(t[0], com.Group.Exp(com.C, c))}
+}
+
+func (com *PedersenEqualityCommitment) Order() *big.Int {
+	return com.Group.Q
+}
+
+func (com *PedersenEqualityCommitment) RandomnessBound() *big.Int {
+	return com.Group.Q
+}
+
+// PedersenECEqualityCommitment is Pedersen commitment C = g^x * h^r on elliptic curve.
+type PedersenECEqualityCommitment struct {
+	DLog *dlog.ECDLog
+	H    *types.ECGroupElement
+	C    *types.ECGroupElement
+}
+
+func NewPedersenECEqualityCommitment(curve dlog.Curve,
+	h, c *types.ECGroupElement) *PedersenECEqualityCommitment {
+	return &PedersenECEqualityCommitment{
+		DLog: dlog.NewECDLog(curve),
+		H:    h,
+		C:    c,
+	}
+}
+
+func (com *PedersenECEqualityCommitment) Commit(x, r *big.Int) []*big.Int {
+	g := types.NewECGroupElement(com.DLog.Curve.Params().Gx, com.DLog.Curve.Params().Gy)
+	p := common.MultiExpEC(com.DLog.Curve, []*types.ECGroupElement{g, com.H},
+		[]*big.Int{x, r})
+	return []*big.Int{p.X, p.Y}
+}
+
+func (com *PedersenECEqualityCommitment) MulCommitment(t []*big.Int, c *big.Int) []*big.Int {
+	p := com.DLog.Mul(types.NewECGroupElement(t[0], t[1]), com.DLog.Exp(com.C, c))
+	return []*big.Int{p.X, p.Y}
+}
+
+func (com *PedersenECEqualityCommitment) Order() *big.Int {
+	return com.DLog.OrderOfSubgroup
+}
+
+func (com *PedersenECEqualityCommitment) RandomnessBound() *big.Int {
+	return com.DLog.OrderOfSubgroup
+}
+
+// DamgardFujisakiEqualityCommitment is Damgard-Fujisaki commitment C = g^x * h^r mod N.
+type DamgardFujisakiEqualityCommitment struct {
+	Params *commitments.DamgardFujisakiParams
+	C      *big.Int
+}
+
+func NewDamgardFujisakiEqualityCommitment(params *commitments.DamgardFujisakiParams,
+	c *big.Int) *DamgardFujisakiEqualityCommitment {
+	return &DamgardFujisakiEqualityCommitment{
+		Params: params,
+		C:      c,
+	}
+}
+
+func (com *DamgardFujisakiEqualityCommitment) Commit(x, r *big.Int) []*big.Int {
+	return []*big.Int{com.Params.Commit(x, r)}
+}
+
+func (com *DamgardFujisakiEqualityCommitment) MulCommitment(t []*big.Int,
+	c *big.Int) []*big.Int {
+	n := com.Params.N
+	res := new(big.Int).Exp(com.C, c, n)
+	res.Mul(res, t[0])
+	return []*big.Int{res.Mod(res, n)}
+}
+
+func (com *DamgardFujisakiEqualityCommitment) Order() *big.Int {
+	return nil
+}
+
+func (com *DamgardFujisakiEqualityCommitment) RandomnessBound() *big.Int {
+	return com.Params.RandomnessBound()
+}
+
+// ProveCommitmentEquality demonstrates how prover can prove that the commitments contain
+// the same value x from [0, 2^l), where rs are the randomnesses of the commitments.
+func ProveCommitmentEquality(coms []EqualityCommitment, x *big.Int, rs []*big.Int,
+	l int) (bool, error) {
+	prover, err := NewCommitmentEqualityProver(coms, x, rs, l)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewCommitmentEqualityVerifier(coms, l)
+	if err != nil {
+		return false, err
+	}
+
+	proofRandomData, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge(proofRandomData)
+	if err != nil {
+		return false, err
+	}
+	zX, zs := prover.GetProofData(challenge)
+	return verifier.Verify(zX, zs), nil
+}
+
+// CommitmentEqualityProver proves that the commitments C_i = g_i^x * h_i^r_i contain
+// the same x (Camenisch, Michels: Separability and Efficiency for Generic Group Signature
+// Schemes):
+//   - prover chooses rX from [0, 2^(l+K+K1)), rR_i (modulo the order or, for hidden
+//     order, from [0, 2^(K+K1) * B_i) where B_i is the randomness bound) and sends
+//     t_i = g_i^rX * h_i^rR_i,
+//   - verifier sends challenge c from [0, 2^K),
+//   - prover sends zX = rX + c * x (in integers) and zR_i = rR_i + c * r_i,
+//   - verifier checks zX < 2^(l+K+K1+1) and g_i^zX * h_i^zR_i = t_i * C_i^c.
+//
+// As zX is computed in integers, the value extracted from each commitment is the same
+// integer as long as it is smaller than the half of each known order - thus l + K + K1 + 3
+// must not exceed the bit length of the smallest order. When the orders of the groups
+// differ, one of the commitments should be Damgard-Fujisaki commitment: as its group order
+// is hidden, the extracted x is an integer and the bound on zX limits it to the range where
+// the equality modulo the other orders implies the equality of integers.
+//
+// The proof is a glue for composing other proofs, for example to show that a value which is
+// committed for a range proof is the attribute committed in a credential.
+type CommitmentEqualityProver struct {
+	coms []EqualityCommitment
+	x    *big.Int
+	rs   []*big.Int
+	L    int
+	K    int
+	K1   int
+	rX   *big.Int
+	rRs  []*big.Int
+}
+
+func NewCommitmentEqualityProver(coms []EqualityCommitment, x *big.Int, rs []*big.Int,
+	l int) (*CommitmentEqualityProver, error) {
+	if len(rs) != len(coms) {
+		return nil, fmt.Errorf("each commitment needs its randomness")
+	}
+	if x.Sign() < 0 || x.BitLen() > l {
+		return nil, fmt.Errorf("committed value needs to be from [0, 2^%d)", l)
+	}
+	prover := &CommitmentEqualityProver{
+		coms: coms,
+		x:    x,
+		rs:   rs,
+		L:    l,
+		K:    80,
+		K1:   80,
+	}
+	if err := checkEqualityBound(coms, l, prover.K, prover.K1); err != nil {
+		return nil, err
+	}
+	return prover, nil
+}
+
+// GetProofRandomData returns t_i = g_i^rX * h_i^rR_i for each commitment.
+func (prover *CommitmentEqualityProver) GetProofRandomData() ([][]*big.Int, error) {
+	rX, err := common.GetRandomInt(pow2(prover.L + prover.K + prover.K1))
+	if err != nil {
+		return nil, err
+	}
+	prover.rX = rX
+	prover.rRs = make([]*big.Int, len(prover.coms))
+	ts := make([][]*big.Int, len(prover.coms))
+	for i, com := range prover.coms {
+		if order := com.Order(); order != nil {
+			rR, err := common.GetRandomInt(order)
+			if err != nil {
+				return nil, err
+			}
+			prover.rRs[i] = rR
+		} else {
+			bound := new(big.Int).Lsh(com.RandomnessBound(), uint(prover.K+prover.K1))
+			rR, err := common.GetRandomInt(bound)
+			if err != nil {
+				return nil, err
+			}
+			prover.rRs[i] = rR
+		}
+		ts[i] = com.Commit(prover.rX, prover.rRs[i])
+	}
+	return ts, nil
+}
+
+// GetProofData returns zX = rX + challenge * x and zR_i = rR_i + challenge * r_i
+// (modulo the order when it is known).
+func (prover *CommitmentEqualityProver) GetProofData(challenge *big.Int) (*big.Int,
+	[]*big.Int) {
+	zX := new(big.Int).Mul(challenge, prover.x)
+	zX.Add(zX, prover.rX)
+	zs := make([]*big.Int, len(prover.coms))
+	for i, com := range prover.coms {
+		zs[i] = new(big.Int).Mul(challenge, prover.rs[i])
+		zs[i].Add(zs[i], prover.rRs[i])
+		if order := com.Order(); order != nil {
+			zs[i].Mod(zs[i], order)
+		}
+	}
+	return zX, zs
+}
+
+type CommitmentEqualityVerifier struct {
+	coms            []EqualityCommitment
+	L               int
+	K               int
+	K1              int
+	proofRandomData [][]*big.Int
+	challenge       *big.Int
+}
+
+func NewCommitmentEqualityVerifier(coms []EqualityCommitment,
+	l int) (*CommitmentEqualityVerifier, error) {
+	verifier := &CommitmentEqualityVerifier{
+		coms: coms,
+		L:    l,
+		K:    80,
+		K1:   80,
+	}
+	if err := checkEqualityBound(coms, l, verifier.K, verifier.K1); err != nil {
+		return nil, err
+	}
+	return verifier, nil
+}
+
+func (verifier *CommitmentEqualityVerifier) GetChallenge(
+	proofRandomData [][]*big.Int) (*big.Int, error) {
+	verifier.proofRandomData = proofRandomData
+	challenge, err := common.GetRandomInt(pow2(verifier.K))
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return verifier.challenge, nil
+}
+
+// Verify checks zX < 2^(l+K+K1+1) and g_i^zX * h_i^zR_i = t_i * C_i^c for each commitment.
+func (verifier *CommitmentEqualityVerifier) Verify(zX *big.Int, zs []*big.Int) bool {
+	if zX == nil || len(zs) != len(verifier.coms) ||
+		len(verifier.proofRandomData) != len(verifier.coms) {
+		return false
+	}
+	if zX.Sign() < 0 || zX.BitLen() > verifier.L+verifier.K+verifier.K1+1 {
+		return false
+	}
+	for i, com := range verifier.coms {
+		if zs[i] == nil || zs[i].Sign() < 0 {
+			return false
+		}
+		left := com.Commit(zX, zs[i])
+		t := verifier.proofRandomData[i]
+		if len(t) != len(left) {
+			return false
+		}
+		for _, x := range t {
+			if x == nil {
+				return false
+			}
+		}
+		right := com.MulCommitment(t, verifier.challenge)
+		for j := range left {
+			if left[j] == nil || right[j] == nil || left[j].Cmp(right[j]) != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// checkEqualityBound checks that integers from (-2^(l+K+K1+1), 2^(l+K+K1+1)) are smaller
+// than the half of all the known orders.
+func checkEqualityBound(coms []EqualityCommitment, l, k, k1 int) error {
+	if len(coms) < 2 {
+		return fmt.Errorf("at least two commitments are needed")
+	}
+	for _, com := range coms {
+		if order := com.Order(); order != nil && l+k+k1+3 > order.BitLen() {
+			return fmt.Errorf("bit length %d is too large for the order of the group", l)
+		}
+	}
+	if l < 1 {
+		return fmt.Errorf("bit length needs to be positive")
+	}
+	return nil
+}
+
+// pow2 returns 2^n.
+func pow2(n int) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(n))
+}
@@ -46,17 +46,26 @@ func ProveBitCommitment() (bool, error) {
 	_, v1 := committer.GetDecommitMsg() // v1 is a random r used in commitment: c = Y^a * r^q mod N
 
 	// receiver.RSA.E is Q
-	u2 := committer.H.GetRandomElement()
+	u2, err := committer.H.GetRandomElement()
+	if err != nil {
+		return false, err
+	}
 
 	prover := preimage.NewPartialPreimageProver(committer.Homomorphism, committer.H,
 		committer.Q, v1, u1, u2)
 	verifier := preimage.NewPartialPreimageVerifier(receiver.Homomorphism, receiver.H,
 		receiver.Q)
 
-	pair1, pair2 := prover.GetProofRandomData()
+	pair1, pair2, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
 
 	verifier.SetProofRandomData(pair1, pair2)
-	challenge := verifier.GetChallenge()
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
 
 	c1, z1, c2, z2 := prover.GetProofData(challenge)
 	verified := verifier.Verify(c1, z1, c2, z2)
@@ -79,8 +88,14 @@ func ProveCommitmentMultiplication() (bool, error) {
 		return false, err
 	}
 
-	a := common.GetRandomInt(committer.Q)
-	b := common.GetRandomInt(committer.Q)
+	a, err := common.GetRandomInt(committer.Q)
+	if err != nil {
+		return false, err
+	}
+	b, err := common.GetRandomInt(committer.Q)
+	if err != nil {
+		return false, err
+	}
 	A, err1 := committer.GetCommitMsg(a)
 	_, r := committer.GetDecommitMsg()
 	B, err2 := committer.GetCommitMsg(b)
@@ -90,7 +105,10 @@ func ProveCommitmentMultiplication() (bool, error) {
 
 	c := new(big.Int).Mul(a, b)
 	c.Mod(c, committer.Q) // c = a * b mod Q
-	C, o, t := committer.GetCommitmentToMultiplication(a, b, u)
+	C, o, t, err := committer.GetCommitmentToMultiplication(a, b, u)
+	if err != nil {
+		return false, err
+	}
 	if err1 != nil || err2 != nil {
 		return false, err
 	}
@@ -108,10 +126,16 @@ func ProveCommitmentMultiplication() (bool, error) {
 		commitments, committedValues, randomValues, t)
 	verifier := NewQOneWayMultiplicationVerifier(homomorphism, H, Q, Y, commitments)
 
-	m1, m2, m3 := prover.GetProofRandomData()
+	m1, m2, m3, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
 	verifier.SetProofRandomData(m1, m2, m3)
 
-	challenge := verifier.GetChallenge()
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
 	z1, w1, w2, z2, w3 := prover.GetProofData(challenge)
 	proved := verifier.Verify(z1, w1, w2, z2, w3)
 
@@ -164,11 +188,20 @@ func NewQOneWayMultiplicationProver(homomorphism func(*big.Int) *big.Int,
 	}
 }
 
-func (prover *QOneWayMultiplicationProver) GetProofRandomData() (*big.Int, *big.Int, *big.Int) {
+func (prover *QOneWayMultiplicationProver) GetProofRandomData() (*big.Int, *big.Int, *big.Int, error) {
 	// m1 = Y^x * f(s1) where x random from Z_q and s1 random from H
-	x := common.GetRandomInt(prover.Q)
-	s1 := prover.H.GetRandomElement()
-	s2 := prover.H.GetRandomElement()
+	x, err := common.GetRandomInt(prover.Q)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	s1, err := prover.H.GetRandomElement()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	s2, err := prover.H.GetRandomElement()
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	prover.x = x
 	prover.s1 = s1
 	prover.s2 = s2
@@ -177,13 +210,19 @@ func (prover *QOneWayMultiplicationProver) GetProofRandomData() (*big.Int, *big.
 	m2 := helper(prover.QOneWayHomomorphism, prover.H, prover.B, x, s2)
 
 	// m3 = Y^d * f(s)
-	d := common.GetRandomInt(prover.Q)
-	s := prover.H.GetRandomElement()
+	d, err := common.GetRandomInt(prover.Q)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	s, err := prover.H.GetRandomElement()
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	prover.d = d
 	prover.s = s
 	m3 := helper(prover.QOneWayHomomorphism, prover.H, prover.Y, d, s)
 
-	return m1, m2, m3
+	return m1, m2, m3, nil
 }
 
 func (prover *QOneWayMultiplicationProver) GetProofData(challenge *big.Int) (*big.Int, *big.Int,
@@ -266,10 +305,13 @@ func (verifier *QOneWayMultiplicationVerifier) SetProofRandomData(m1, m2, m3 *bi
 	verifier.m3 = m3
 }
 
-func (verifier *QOneWayMultiplicationVerifier) GetChallenge() *big.Int {
-	challenge := common.GetRandomInt(verifier.Q)
+func (verifier *QOneWayMultiplicationVerifier) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(verifier.Q)
+	if err != nil {
+		return nil, err
+	}
 	verifier.challenge = challenge
-	return challenge
+	return challenge, nil
 }
 
 func (verifier *QOneWayMultiplicationVerifier) Verify(z1, w1, w2, z2, w3 *big.Int) bool {
@@ -0,0 +1,164 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlogproofs
+
+import (
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+	"runtime"
+	"sync"
+)
+
+// BatchBackend computes the multi-exponentiations of the batch verifiers (see
+// SchnorrBatchVerifier and SchnorrECBatchVerifier), which is where almost all the time
+// of the batch verification is spent. The default backend computes them sequentially in
+// the calling goroutine. Operators verifying large batches can use ParallelBatchBackend
+// (which is the default when built with emmy_parallel tag), or plug in their own backend,
+// for example one which offloads multi-scalar multiplications to a GPU.
+type BatchBackend interface {
+	// MultiExp returns prod bases[i]^exponents[i] mod group.P, exponents are non-negative.
+	MultiExp(group *groups.SchnorrGroup, bases, exponents []*big.Int) *big.Int
+	// MultiExpEC returns prod bases[i]^exponents[i] in the EC group.
+	MultiExpEC(dLog *dlog.ECDLog, bases []*types.ECGroupElement,
+		exponents []*big.Int) *types.ECGroupElement
+}
+
+var (
+	batchBackend      BatchBackend = cpuBatchBackend{}
+	batchBackendMutex sync.RWMutex
+)
+
+// SetBatchBackend sets the backend which is used by all batch verifiers. If backend is nil,
+// the default (sequential) backend is used.
+func SetBatchBackend(backend BatchBackend) {
+	batchBackendMutex.Lock()
+	defer batchBackendMutex.Unlock()
+	if backend == nil {
+		backend = cpuBatchBackend{}
+	}
+	batchBackend = backend
+}
+
+// GetBatchBackend returns the backend which is used by batch verifiers.
+func GetBatchBackend() BatchBackend {
+	batchBackendMutex.RLock()
+	defer batchBackendMutex.RUnlock()
+	return batchBackend
+}
+
+// cpuBatchBackend computes multi-exponentiations sequentially.
+type cpuBatchBackend struct{}
+
+func (cpuBatchBackend) MultiExp(group *groups.SchnorrGroup, bases, exponents []*big.Int) *big.Int {
+	return common.MultiExp(bases, exponents, group.P)
+}
+
+func (cpuBatchBackend) MultiExpEC(dLog *dlog.ECDLog, bases []*types.ECGroupElement,
+	exponents []*big.Int) *types.ECGroupElement {
+	return common.MultiExpEC(dLog.Curve, bases, exponents)
+}
+
+// parallelMinChunk is the smallest number of bases which is handed to a worker of
+// ParallelBatchBackend - for smaller chunks the goroutines cost more than they save.
+const parallelMinChunk = 32
+
+// ParallelBatchBackend splits multi-exponentiations into chunks which are computed
+// concurrently by the given number of workers (all CPU cores by default), the partial
+// products are then multiplied. The squarings are shared only within a chunk, thus it pays
+// off for large batches (thousands of proofs) on machines with many cores.
+type ParallelBatchBackend struct {
+	Workers int
+}
+
+// NewParallelBatchBackend returns a backend with the given number of workers. If workers
+// is not positive, the number of CPUs is used.
+func NewParallelBatchBackend(workers int) *ParallelBatchBackend {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &ParallelBatchBackend{
+		Workers: workers,
+	}
+}
+
+func (backend *ParallelBatchBackend) MultiExp(group *groups.SchnorrGroup,
+	bases, exponents []*big.Int) *big.Int {
+	chunks := backend.chunks(len(bases))
+	results := make([]*big.Int, len(chunks))
+	backend.run(chunks, func(i, from, to int) {
+		results[i] = common.MultiExp(bases[from:to], exponents[from:to], group.P)
+	})
+
+	result := big.NewInt(1)
+	for _, r := range results {
+		result = group.Mul(result, r)
+	}
+	return result
+}
+
+func (backend *ParallelBatchBackend) MultiExpEC(dLog *dlog.ECDLog, bases []*types.ECGroupElement,
+	exponents []*big.Int) *types.ECGroupElement {
+	chunks := backend.chunks(len(bases))
+	results := make([]*types.ECGroupElement, len(chunks))
+	backend.run(chunks, func(i, from, to int) {
+		results[i] = common.MultiExpEC(dLog.Curve, bases[from:to], exponents[from:to])
+	})
+
+	result := types.NewECGroupElementInfinity()
+	for _, r := range results {
+		result = dLog.Mul(result, r)
+	}
+	return result
+}
+
+// chunks splits n bases into at most Workers chunks of at least parallelMinChunk bases
+// and returns the boundaries of the chunks.
+func (backend *ParallelBatchBackend) chunks(n int) [][2]int {
+	workers := backend.Workers
+	if max := (n + parallelMinChunk - 1) / parallelMinChunk; workers > max {
+		workers = max
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	size := (n + workers - 1) / workers
+	var chunks [][2]int
+	for from := 0; from < n; from += size {
+		to := from + size
+		if to > n {
+			to = n
+		}
+		chunks = append(chunks, [2]int{from, to})
+	}
+	return chunks
+}
+
+func (backend *ParallelBatchBackend) run(chunks [][2]int, f func(i, from, to int)) {
+	var wg sync.WaitGroup
+	for i, c := range chunks {
+		wg.Add(1)
+		go func(i, from, to int) {
+			defer wg.Done()
+			f(i, from, to)
+		}(i, c[0], c[1])
+	}
+	wg.Wait()
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlogproofs
+
+import (
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+	"sync"
+)
+
+// ProveDHTuple demonstrates how prover can prove that (g, ga, gb, gab) is a Diffie-Hellman
+// tuple, which means ga = g^a and gab = gb^a for a (the secret) known to the prover.
+func ProveDHTuple(a, g, ga, gb, gab *big.Int, group *groups.SchnorrGroup) (bool, error) {
+	prover := NewDHTupleProver(group)
+	verifier := NewDHTupleVerifier(group)
+
+	x1, x2, err := prover.GetProofRandomData(a, g, gb)
+	if err != nil {
+		return false, err
+	}
+
+	challenge, err := verifier.GetChallenge(g, ga, gb, gab, x1, x2)
+	if err != nil {
+		return false, err
+	}
+	z, err := prover.GetProofData(challenge)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(z), nil
+}
+
+// DHTupleProver proves that (g, g^a, g^b, g^(ab)) is a Diffie-Hellman tuple. It is
+// the proof of dlog equality log_g(g^a) = log_(g^b)(g^(ab)), where only a needs to be
+// known to the prover.
+type DHTupleProver struct {
+	Group  *groups.SchnorrGroup
+	prover *DLogEqualityProver
+}
+
+func NewDHTupleProver(group *groups.SchnorrGroup) *DHTupleProver {
+	return &DHTupleProver{
+		Group:  group,
+		prover: NewDLogEqualityProver(group),
+	}
+}
+
+// GetProofRandomData returns x1 = g^r and x2 = gb^r where r is random.
+func (prover *DHTupleProver) GetProofRandomData(a, g, gb *big.Int) (*big.Int, *big.Int, error) {
+	return prover.prover.GetProofRandomData(a, g, gb)
+}
+
+// GetProofData returns z = r + challenge * a. It returns an error if the proof random
+// data has not been generated or has been already used (see Reset).
+func (prover *DHTupleProver) GetProofData(challenge *big.Int) (*big.Int, error) {
+	return prover.prover.GetProofData(challenge)
+}
+
+// Reset discards the randomness of an unfinished proof.
+func (prover *DHTupleProver) Reset() {
+	prover.prover.Reset()
+}
+
+type DHTupleVerifier struct {
+	Group     *groups.SchnorrGroup
+	g         *big.Int
+	ga        *big.Int
+	gb        *big.Int
+	gab       *big.Int
+	x1        *big.Int
+	x2        *big.Int
+	challenge *big.Int
+	mutex     sync.Mutex
+}
+
+func NewDHTupleVerifier(group *groups.SchnorrGroup) *DHTupleVerifier {
+	return &DHTupleVerifier{
+		Group: group,
+	}
+}
+
+// GetChallenge sets the tuple and the proof random data, and returns a random challenge.
+func (verifier *DHTupleVerifier) GetChallenge(g, ga, gb, gab, x1, x2 *big.Int) (*big.Int, error) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.setProofRandomData(g, ga, gb, gab, x1, x2)
+	challenge, err := common.GetRandomInt(verifier.Group.Q)
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return verifier.challenge, nil
+}
+
+// SetProofRandomData sets the tuple (g, ga, gb, gab) and the proof random data x1 = g^r
+// and x2 = gb^r.
+func (verifier *DHTupleVerifier) SetProofRandomData(g, ga, gb, gab, x1, x2 *big.Int) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.setProofRandomData(g, ga, gb, gab, x1, x2)
+}
+
+func (verifier *DHTupleVerifier) setProofRandomData(g, ga, gb, gab, x1, x2 *big.Int) {
+	verifier.g, verifier.ga, verifier.gb, verifier.gab = g, ga, gb, gab
+	verifier.x1, verifier.x2 = x1, x2
+}
+
+// SetChallenge sets the challenge which was not generated by the verifier (for example
+// the one derived by Fiat-Shamir heuristic).
+func (verifier *DHTupleVerifier) SetChallenge(challenge *big.Int) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.challenge = challenge
+}
+
+// Verify receives z = r + challenge * a. It returns true if all the elements are in the group,
+// g is not 1, g^z = x1 * ga^challenge and gb^z = x2 * gab^challenge.
+func (verifier *DHTupleVerifier) Verify(z *big.Int) bool {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	if !isSet(verifier.g, verifier.ga, verifier.gb, verifier.gab, verifier.x1, verifier.x2,
+		verifier.challenge, z) {
+		return false
+	}
+	for _, e := range []*big.Int{verifier.g, verifier.ga, verifier.gb, verifier.gab,
+		verifier.x1, verifier.x2} {
+		if !verifier.Group.IsElementInGroup(e) {
+			return false
+		}
+	}
+	if verifier.g.Cmp(big.NewInt(1)) == 0 {
+		return false
+	}
+
+	return verifyDLogEquation(verifier.Group, verifier.g, verifier.ga, verifier.x1,
+		verifier.challenge, z) &&
+		verifyDLogEquation(verifier.Group, verifier.gb, verifier.gab, verifier.x2,
+			verifier.challenge, z)
+}
+
+// Reset discards the tuple, proof random data and challenge of the last proof.
+func (verifier *DHTupleVerifier) Reset() {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.g, verifier.ga, verifier.gb, verifier.gab = nil, nil, nil, nil
+	verifier.x1, verifier.x2, verifier.challenge = nil, nil, nil
+}
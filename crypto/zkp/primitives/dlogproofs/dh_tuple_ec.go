@@ -0,0 +1,166 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlogproofs
+
+import (
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+	"sync"
+)
+
+// ProveECDHTuple demonstrates how prover can prove that (g, ga, gb, gab) is a Diffie-Hellman
+// tuple in EC group, which means ga = a * g and gab = a * gb for a (the secret) known to
+// the prover.
+func ProveECDHTuple(a *big.Int, g, ga, gb, gab *types.ECGroupElement, curve dlog.Curve) (bool,
+	error) {
+	prover := NewECDHTupleProver(curve)
+	verifier := NewECDHTupleVerifier(curve)
+
+	x1, x2, err := prover.GetProofRandomData(a, g, gb)
+	if err != nil {
+		return false, err
+	}
+
+	challenge, err := verifier.GetChallenge(g, ga, gb, gab, x1, x2)
+	if err != nil {
+		return false, err
+	}
+	z, err := prover.GetProofData(challenge)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(z), nil
+}
+
+// ECDHTupleProver proves that (g, g^a, g^b, g^(ab)) is a Diffie-Hellman tuple in EC group
+// (see DHTupleProver).
+type ECDHTupleProver struct {
+	DLog   *dlog.ECDLog
+	prover *ECDLogEqualityProver
+}
+
+func NewECDHTupleProver(curve dlog.Curve) *ECDHTupleProver {
+	prover := NewECDLogEqualityProver(curve)
+	return &ECDHTupleProver{
+		DLog:   prover.DLog,
+		prover: prover,
+	}
+}
+
+// GetProofRandomData returns x1 = g^r and x2 = gb^r where r is random.
+func (prover *ECDHTupleProver) GetProofRandomData(a *big.Int,
+	g, gb *types.ECGroupElement) (*types.ECGroupElement, *types.ECGroupElement, error) {
+	return prover.prover.GetProofRandomData(a, g, gb)
+}
+
+// GetProofData returns z = r + challenge * a. It returns an error if the proof random
+// data has not been generated or has been already used (see Reset).
+func (prover *ECDHTupleProver) GetProofData(challenge *big.Int) (*big.Int, error) {
+	return prover.prover.GetProofData(challenge)
+}
+
+// Reset discards the randomness of an unfinished proof.
+func (prover *ECDHTupleProver) Reset() {
+	prover.prover.Reset()
+}
+
+type ECDHTupleVerifier struct {
+	DLog      *dlog.ECDLog
+	g         *types.ECGroupElement
+	ga        *types.ECGroupElement
+	gb        *types.ECGroupElement
+	gab       *types.ECGroupElement
+	x1        *types.ECGroupElement
+	x2        *types.ECGroupElement
+	challenge *big.Int
+	mutex     sync.Mutex
+}
+
+func NewECDHTupleVerifier(curve dlog.Curve) *ECDHTupleVerifier {
+	return &ECDHTupleVerifier{
+		DLog: dlog.NewECDLog(curve),
+	}
+}
+
+// GetChallenge sets the tuple and the proof random data, and returns a random challenge.
+func (verifier *ECDHTupleVerifier) GetChallenge(g, ga, gb, gab, x1,
+	x2 *types.ECGroupElement) (*big.Int, error) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.setProofRandomData(g, ga, gb, gab, x1, x2)
+	challenge, err := common.GetRandomInt(verifier.DLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return verifier.challenge, nil
+}
+
+// SetProofRandomData sets the tuple (g, ga, gb, gab) and the proof random data x1 = g^r
+// and x2 = gb^r.
+func (verifier *ECDHTupleVerifier) SetProofRandomData(g, ga, gb, gab, x1,
+	x2 *types.ECGroupElement) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.setProofRandomData(g, ga, gb, gab, x1, x2)
+}
+
+func (verifier *ECDHTupleVerifier) setProofRandomData(g, ga, gb, gab, x1,
+	x2 *types.ECGroupElement) {
+	verifier.g, verifier.ga, verifier.gb, verifier.gab = g, ga, gb, gab
+	verifier.x1, verifier.x2 = x1, x2
+}
+
+// SetChallenge sets the challenge which was not generated by the verifier (for example
+// the one derived by Fiat-Shamir heuristic).
+func (verifier *ECDHTupleVerifier) SetChallenge(challenge *big.Int) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.challenge = challenge
+}
+
+// Verify receives z = r + challenge * a. It returns true if all the points are in the group,
+// g is not the point at infinity, g^z = x1 * ga^challenge and gb^z = x2 * gab^challenge.
+func (verifier *ECDHTupleVerifier) Verify(z *big.Int) bool {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	for _, p := range []*types.ECGroupElement{verifier.g, verifier.ga, verifier.gb,
+		verifier.gab, verifier.x1, verifier.x2} {
+		if p == nil || !verifier.DLog.IsInSubgroup(p) {
+			return false
+		}
+	}
+	if verifier.g.IsInfinity() || !isSet(verifier.challenge, z) {
+		return false
+	}
+
+	return verifyECDLogEquation(verifier.DLog, verifier.g, verifier.ga, verifier.x1,
+		verifier.challenge, z) &&
+		verifyECDLogEquation(verifier.DLog, verifier.gb, verifier.gab, verifier.x2,
+			verifier.challenge, z)
+}
+
+// Reset discards the tuple, proof random data and challenge of the last proof.
+func (verifier *ECDHTupleVerifier) Reset() {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.g, verifier.ga, verifier.gb, verifier.gab = nil, nil, nil, nil
+	verifier.x1, verifier.x2, verifier.challenge = nil, nil, nil
+}
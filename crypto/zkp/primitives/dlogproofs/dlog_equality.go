@@ -21,20 +21,30 @@ import (
 	"github.com/xlab-si/emmy/crypto/common"
 	"github.com/xlab-si/emmy/crypto/groups"
 	"math/big"
+	"sync"
 )
 
 // ProveDLogEquality demonstrates how prover can prove the knowledge of log_g1(t1), log_g2(t2) and
 // that log_g1(t1) = log_g2(t2).
-func ProveDLogEquality(secret, g1, g2, t1, t2 *big.Int, group *groups.SchnorrGroup) bool {
+func ProveDLogEquality(secret, g1, g2, t1, t2 *big.Int, group *groups.SchnorrGroup) (bool,
+	error) {
 	eProver := NewDLogEqualityProver(group)
 	eVerifier := NewDLogEqualityVerifier(group)
 
-	x1, x2 := eProver.GetProofRandomData(secret, g1, g2)
+	x1, x2, err := eProver.GetProofRandomData(secret, g1, g2)
+	if err != nil {
+		return false, err
+	}
 
-	challenge := eVerifier.GetChallenge(g1, g2, t1, t2, x1, x2)
-	z := eProver.GetProofData(challenge)
-	verified := eVerifier.Verify(z)
-	return verified
+	challenge, err := eVerifier.GetChallenge(g1, g2, t1, t2, x1, x2)
+	if err != nil {
+		return false, err
+	}
+	z, err := eProver.GetProofData(challenge)
+	if err != nil {
+		return false, err
+	}
+	return eVerifier.Verify(z), nil
 }
 
 type DLogEqualityProver struct {
@@ -43,6 +53,7 @@ type DLogEqualityProver struct {
 	secret *big.Int
 	g1     *big.Int
 	g2     *big.Int
+	mutex  sync.Mutex
 }
 
 func NewDLogEqualityProver(group *groups.SchnorrGroup) *DLogEqualityProver {
@@ -53,28 +64,49 @@ func NewDLogEqualityProver(group *groups.SchnorrGroup) *DLogEqualityProver {
 	return &prover
 }
 
-func (prover *DLogEqualityProver) GetProofRandomData(secret, g1, g2 *big.Int) (*big.Int, *big.Int) {
+func (prover *DLogEqualityProver) GetProofRandomData(secret, g1, g2 *big.Int) (*big.Int, *big.Int, error) {
 	// Sets the values that are needed before the protocol can be run.
 	// The protocol proves the knowledge of log_g1(t1), log_g2(t2) and
 	// that log_g1(t1) = log_g2(t2).
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
 	prover.secret = secret
 	prover.g1 = g1
 	prover.g2 = g2
 
-	r := common.GetRandomInt(prover.Group.Q)
+	r, err := common.GetRandomInt(prover.Group.Q)
+	if err != nil {
+		return nil, nil, err
+	}
 	prover.r = r
 	x1 := prover.Group.Exp(prover.g1, r)
 	x2 := prover.Group.Exp(prover.g2, r)
-	return x1, x2
+	return x1, x2, nil
 }
 
-func (prover *DLogEqualityProver) GetProofData(challenge *big.Int) *big.Int {
+// GetProofData returns z = r + challenge * secret. It returns an error if the proof random
+// data has not been generated or has been already used (see Reset).
+func (prover *DLogEqualityProver) GetProofData(challenge *big.Int) (*big.Int, error) {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	if err := checkProofRandomData(prover.r); err != nil {
+		return nil, err
+	}
+
 	// z = r + challenge * secret
 	z := new(big.Int)
 	z.Mul(challenge, prover.secret)
 	z.Add(z, prover.r)
 	z.Mod(z, prover.Group.Q)
-	return z
+	prover.r = nil
+	return z, nil
+}
+
+// Reset discards the randomness of an unfinished proof.
+func (prover *DLogEqualityProver) Reset() {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	prover.r, prover.secret, prover.g1, prover.g2 = nil, nil, nil, nil
 }
 
 type DLogEqualityVerifier struct {
@@ -86,6 +118,7 @@ type DLogEqualityVerifier struct {
 	x2        *big.Int
 	t1        *big.Int
 	t2        *big.Int
+	mutex     sync.Mutex
 }
 
 func NewDLogEqualityVerifier(group *groups.SchnorrGroup) *DLogEqualityVerifier {
@@ -96,38 +129,63 @@ func NewDLogEqualityVerifier(group *groups.SchnorrGroup) *DLogEqualityVerifier {
 	return &verifier
 }
 
-func (verifier *DLogEqualityVerifier) GetChallenge(g1, g2, t1, t2, x1, x2 *big.Int) *big.Int {
-	// Set the values that are needed before the protocol can be run.
-	// The protocol proves the knowledge of log_g1(t1), log_g2(t2) and
-	// that log_g1(t1) = log_g2(t2).
+func (verifier *DLogEqualityVerifier) GetChallenge(g1, g2, t1, t2, x1, x2 *big.Int) (*big.Int, error) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.setProofRandomData(g1, g2, t1, t2, x1, x2)
+	challenge, err := common.GetRandomInt(verifier.Group.Q)
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return challenge, nil
+}
+
+// SetProofRandomData sets the values that are needed before the protocol can be run.
+// The protocol proves the knowledge of log_g1(t1), log_g2(t2) and that log_g1(t1) = log_g2(t2),
+// x1 = g1^r and x2 = g2^r.
+func (verifier *DLogEqualityVerifier) SetProofRandomData(g1, g2, t1, t2, x1, x2 *big.Int) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.setProofRandomData(g1, g2, t1, t2, x1, x2)
+}
+
+func (verifier *DLogEqualityVerifier) setProofRandomData(g1, g2, t1, t2, x1, x2 *big.Int) {
 	verifier.g1 = g1
 	verifier.g2 = g2
 	verifier.t1 = t1
 	verifier.t2 = t2
-
-	// Sets the values g1^r1 and g2^r2.
 	verifier.x1 = x1
 	verifier.x2 = x2
+}
 
-	challenge := common.GetRandomInt(verifier.Group.Q)
+// SetChallenge sets the challenge which was not generated by the verifier (for example
+// the one derived by Fiat-Shamir heuristic).
+func (verifier *DLogEqualityVerifier) SetChallenge(challenge *big.Int) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
 	verifier.challenge = challenge
-	return challenge
 }
 
 // It receives z = r + secret * challenge.
-//It returns true if g1^z = g1^r * (g1^secret) ^ challenge and g2^z = g2^r * (g2^secret) ^ challenge.
+// It returns true if g1^z = g1^r * (g1^secret) ^ challenge and g2^z = g2^r * (g2^secret) ^ challenge.
 func (verifier *DLogEqualityVerifier) Verify(z *big.Int) bool {
-	left1 := verifier.Group.Exp(verifier.g1, z)
-	left2 := verifier.Group.Exp(verifier.g2, z)
-
-	r11 := verifier.Group.Exp(verifier.t1, verifier.challenge)
-	r12 := verifier.Group.Exp(verifier.t2, verifier.challenge)
-	right1 := verifier.Group.Mul(r11, verifier.x1)
-	right2 := verifier.Group.Mul(r12, verifier.x2)
-
-	if left1.Cmp(right1) == 0 && left2.Cmp(right2) == 0 {
-		return true
-	} else {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	if !isSet(verifier.g1, verifier.g2, verifier.t1, verifier.t2, verifier.x1, verifier.x2,
+		verifier.challenge, z) {
 		return false
 	}
+	return verifyDLogEquation(verifier.Group, verifier.g1, verifier.t1, verifier.x1,
+		verifier.challenge, z) &&
+		verifyDLogEquation(verifier.Group, verifier.g2, verifier.t2, verifier.x2,
+			verifier.challenge, z)
+}
+
+// Reset discards the proof random data and challenge of the last proof.
+func (verifier *DLogEqualityVerifier) Reset() {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.g1, verifier.g2, verifier.t1, verifier.t2 = nil, nil, nil, nil
+	verifier.x1, verifier.x2, verifier.challenge = nil, nil, nil
 }
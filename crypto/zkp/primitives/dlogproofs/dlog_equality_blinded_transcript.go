@@ -86,7 +86,7 @@ func NewDLogEqualityBTranscriptProver(group *groups.SchnorrGroup) *DLogEqualityB
 
 // Prove that you know dlog_g1(h1), dlog_g2(h2) and that dlog_g1(h1) = dlog_g2(h2).
 func (prover *DLogEqualityBTranscriptProver) GetProofRandomData(secret, g1, g2 *big.Int) (*big.Int,
-	*big.Int) {
+	*big.Int, error) {
 	// Set the values that are needed before the protocol can be run.
 	// The protocol proves the knowledge of log_g1(t1), log_g2(t2) and
 	// that log_g1(t1) = log_g2(t2).
@@ -94,20 +94,29 @@ func (prover *DLogEqualityBTranscriptProver) GetProofRandomData(secret, g1, g2 *
 	prover.g1 = g1
 	prover.g2 = g2
 
-	r := common.GetRandomInt(prover.Group.Q)
+	r, err := common.GetRandomInt(prover.Group.Q)
+	if err != nil {
+		return nil, nil, err
+	}
 	prover.r = r
 	x1 := prover.Group.Exp(prover.g1, r)
 	x2 := prover.Group.Exp(prover.g2, r)
-	return x1, x2
+	return x1, x2, nil
 }
 
-func (prover *DLogEqualityBTranscriptProver) GetProofData(challenge *big.Int) *big.Int {
+// GetProofData returns z = r + challenge * secret. It returns an error if the proof random
+// data has not been generated or has been already used.
+func (prover *DLogEqualityBTranscriptProver) GetProofData(challenge *big.Int) (*big.Int, error) {
+	if err := checkProofRandomData(prover.r); err != nil {
+		return nil, err
+	}
 	// z = r + challenge * secret
 	z := new(big.Int)
 	z.Mul(challenge, prover.secret)
 	z.Add(z, prover.r)
 	z.Mod(z, prover.Group.Q)
-	return z
+	prover.r = nil
+	return z, nil
 }
 
 type DLogEqualityBTranscriptVerifier struct {
@@ -125,19 +134,22 @@ type DLogEqualityBTranscriptVerifier struct {
 }
 
 func NewDLogEqualityBTranscriptVerifier(group *groups.SchnorrGroup,
-	gamma *big.Int) *DLogEqualityBTranscriptVerifier {
+	gamma *big.Int) (*DLogEqualityBTranscriptVerifier, error) {
 	if gamma == nil {
-		gamma = common.GetRandomInt(group.Q)
+		var err error
+		if gamma, err = common.GetRandomInt(group.Q); err != nil {
+			return nil, err
+		}
 	}
 	verifier := DLogEqualityBTranscriptVerifier{
 		Group: group,
 		gamma: gamma,
 	}
 
-	return &verifier
+	return &verifier, nil
 }
 
-func (verifier *DLogEqualityBTranscriptVerifier) GetChallenge(g1, g2, t1, t2, x1, x2 *big.Int) *big.Int {
+func (verifier *DLogEqualityBTranscriptVerifier) GetChallenge(g1, g2, t1, t2, x1, x2 *big.Int) (*big.Int, error) {
 	// Set the values that are needed before the protocol can be run.
 	// The protocol proves the knowledge of log_g1(t1), log_g2(t2) and
 	// that log_g1(t1) = log_g2(t2).
@@ -150,8 +162,14 @@ func (verifier *DLogEqualityBTranscriptVerifier) GetChallenge(g1, g2, t1, t2, x1
 	verifier.x1 = x1
 	verifier.x2 = x2
 
-	alpha := common.GetRandomInt(verifier.Group.Q)
-	beta := common.GetRandomInt(verifier.Group.Q)
+	alpha, err := common.GetRandomInt(verifier.Group.Q)
+	if err != nil {
+		return nil, err
+	}
+	beta, err := common.GetRandomInt(verifier.Group.Q)
+	if err != nil {
+		return nil, err
+	}
 
 	// alpha1 = g1^r * g1^alpha * t1^beta
 	// beta1 = (g2^r * g2^alpha * t2^beta)^gamma
@@ -175,11 +193,11 @@ func (verifier *DLogEqualityBTranscriptVerifier) GetChallenge(g1, g2, t1, t2, x1
 	verifier.transcript = NewTranscript(alpha1, beta1, hashNum, nil)
 	verifier.alpha = alpha
 
-	return challenge
+	return challenge, nil
 }
 
 // It receives z = r + secret * challenge.
-//It returns true if g1^z = g1^r * (g1^secret) ^ challenge and g2^z = g2^r * (g2^secret) ^ challenge.
+// It returns true if g1^z = g1^r * (g1^secret) ^ challenge and g2^z = g2^r * (g2^secret) ^ challenge.
 func (verifier *DLogEqualityBTranscriptVerifier) Verify(z *big.Int) (bool, *Transcript,
 	*big.Int, *big.Int) {
 	left1 := verifier.Group.Exp(verifier.g1, z)
@@ -95,7 +95,7 @@ func NewECDLogEqualityBTranscriptProver(curve dlog.Curve) *ECDLogEqualityBTransc
 
 // Prove that you know dlog_g1(h1), dlog_g2(h2) and that dlog_g1(h1) = dlog_g2(h2).
 func (prover *ECDLogEqualityBTranscriptProver) GetProofRandomData(secret *big.Int,
-	g1, g2 *types.ECGroupElement) (*types.ECGroupElement, *types.ECGroupElement) {
+	g1, g2 *types.ECGroupElement) (*types.ECGroupElement, *types.ECGroupElement, error) {
 	// Set the values that are needed before the protocol can be run.
 	// The protocol proves the knowledge of log_g1(t1), log_g2(t2) and
 	// that log_g1(t1) = log_g2(t2).
@@ -103,20 +103,29 @@ func (prover *ECDLogEqualityBTranscriptProver) GetProofRandomData(secret *big.In
 	prover.g1 = g1
 	prover.g2 = g2
 
-	r := common.GetRandomInt(prover.DLog.GetOrderOfSubgroup())
+	r, err := common.GetRandomInt(prover.DLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, nil, err
+	}
 	prover.r = r
 	x1, y1 := prover.DLog.Exponentiate(prover.g1.X, prover.g1.Y, r)
 	x2, y2 := prover.DLog.Exponentiate(prover.g2.X, prover.g2.Y, r)
-	return types.NewECGroupElement(x1, y1), types.NewECGroupElement(x2, y2)
+	return types.NewECGroupElement(x1, y1), types.NewECGroupElement(x2, y2), nil
 }
 
-func (prover *ECDLogEqualityBTranscriptProver) GetProofData(challenge *big.Int) *big.Int {
+// GetProofData returns z = r + challenge * secret. It returns an error if the proof random
+// data has not been generated or has been already used.
+func (prover *ECDLogEqualityBTranscriptProver) GetProofData(challenge *big.Int) (*big.Int, error) {
+	if err := checkProofRandomData(prover.r); err != nil {
+		return nil, err
+	}
 	// z = r + challenge * secret
 	z := new(big.Int)
 	z.Mul(challenge, prover.secret)
 	z.Add(z, prover.r)
 	z.Mod(z, prover.DLog.GetOrderOfSubgroup())
-	return z
+	prover.r = nil
+	return z, nil
 }
 
 type ECDLogEqualityBTranscriptVerifier struct {
@@ -134,21 +143,24 @@ type ECDLogEqualityBTranscriptVerifier struct {
 }
 
 func NewECDLogEqualityBTranscriptVerifier(curve dlog.Curve,
-	gamma *big.Int) *ECDLogEqualityBTranscriptVerifier {
+	gamma *big.Int) (*ECDLogEqualityBTranscriptVerifier, error) {
 	dlog := dlog.NewECDLog(curve)
 	if gamma == nil {
-		gamma = common.GetRandomInt(dlog.GetOrderOfSubgroup())
+		var err error
+		if gamma, err = common.GetRandomInt(dlog.GetOrderOfSubgroup()); err != nil {
+			return nil, err
+		}
 	}
 	verifier := ECDLogEqualityBTranscriptVerifier{
 		DLog:  dlog,
 		gamma: gamma,
 	}
 
-	return &verifier
+	return &verifier, nil
 }
 
 func (verifier *ECDLogEqualityBTranscriptVerifier) GetChallenge(g1, g2, t1, t2, x1,
-	x2 *types.ECGroupElement) *big.Int {
+	x2 *types.ECGroupElement) (*big.Int, error) {
 	// Set the values that are needed before the protocol can be run.
 	// The protocol proves the knowledge of log_g1(t1), log_g2(t2) and
 	// that log_g1(t1) = log_g2(t2).
@@ -161,8 +173,14 @@ func (verifier *ECDLogEqualityBTranscriptVerifier) GetChallenge(g1, g2, t1, t2,
 	verifier.x1 = x1
 	verifier.x2 = x2
 
-	alpha := common.GetRandomInt(verifier.DLog.GetOrderOfSubgroup())
-	beta := common.GetRandomInt(verifier.DLog.GetOrderOfSubgroup())
+	alpha, err := common.GetRandomInt(verifier.DLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, err
+	}
+	beta, err := common.GetRandomInt(verifier.DLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, err
+	}
 
 	// alpha1 = g1^r * g1^alpha * t1^beta
 	// beta1 = (g2^r * g2^alpha * t2^beta)^gamma
@@ -186,11 +204,11 @@ func (verifier *ECDLogEqualityBTranscriptVerifier) GetChallenge(g1, g2, t1, t2,
 	verifier.transcript = NewTranscriptEC(alpha11, alpha12, beta11, beta12, hashNum, nil)
 	verifier.alpha = alpha
 
-	return challenge
+	return challenge, nil
 }
 
 // It receives z = r + secret * challenge.
-//It returns true if g1^z = g1^r * (g1^secret) ^ challenge and g2^z = g2^r * (g2^secret) ^ challenge.
+// It returns true if g1^z = g1^r * (g1^secret) ^ challenge and g2^z = g2^r * (g2^secret) ^ challenge.
 func (verifier *ECDLogEqualityBTranscriptVerifier) Verify(z *big.Int) (bool, *TranscriptEC,
 	*types.ECGroupElement, *types.ECGroupElement) {
 	left11, left12 := verifier.DLog.Exponentiate(verifier.g1.X, verifier.g1.Y, z)
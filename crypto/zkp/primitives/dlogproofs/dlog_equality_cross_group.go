@@ -0,0 +1,310 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlogproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// ProveCrossGroupDLogEquality demonstrates how prover can prove that the same secret x from
+// [0, 2^l) is log_g(t) in SchnorrGroup and log_gEC(tEC) in EC group. The secret is committed
+// in RSA group of unknown order as well, so that it can be bound to RSA-group credentials.
+func ProveCrossGroupDLogEquality(secret, g, t *big.Int, gEC, tEC *types.ECGroupElement,
+	group *groups.SchnorrGroup, curve dlog.Curve, params *CrossGroupParams, l int) (bool, error) {
+	prover, err := NewCrossGroupDLogEqualityProver(group, curve, params, l)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewCrossGroupDLogEqualityVerifier(group, curve, params, l)
+	if err != nil {
+		return false, err
+	}
+
+	commitment, proofRandomData, err := prover.GetProofRandomData(secret, g, gEC)
+	if err != nil {
+		return false, err
+	}
+
+	challenge, err := verifier.GetChallenge(g, t, gEC, tEC, commitment, proofRandomData)
+	if err != nil {
+		return false, err
+	}
+	z, zS := prover.GetProofData(challenge)
+	return verifier.Verify(z, zS), nil
+}
+
+// CrossGroupParams are the parameters of RSA group in which the secret is committed:
+// N is a product of two safe primes and H1, H2 are generators of the group of quadratic
+// residues modulo N. The prover must not know the factorization of N nor log_H2(H1) - they
+// are usually generated by the verifier (see NewCrossGroupParams).
+type CrossGroupParams struct {
+	N  *big.Int
+	H1 *big.Int
+	H2 *big.Int
+}
+
+// NewCrossGroupParams generates N as a product of two safe primes of the given bit length.
+func NewCrossGroupParams(safePrimeBitLength int) (*CrossGroupParams, error) {
+	p, err := common.GetSafePrime(safePrimeBitLength)
+	if err != nil {
+		return nil, err
+	}
+	q, err := common.GetSafePrime(safePrimeBitLength)
+	if err != nil {
+		return nil, err
+	}
+	g, err := common.GetGeneratorOfCompositeQR(p, q)
+	if err != nil {
+		return nil, err
+	}
+
+	n := new(big.Int).Mul(p, q)
+	h2 := new(big.Int).Exp(g, big.NewInt(2), n) // generator of QR_N
+	e, err := common.GetRandomInt(n)
+	if err != nil {
+		return nil, err
+	}
+	h1 := new(big.Int).Exp(h2, e, n)
+	return &CrossGroupParams{N: n, H1: h1, H2: h2}, nil
+}
+
+// Cross-group dlog equality proof (Camenisch, Michels: Separability and Efficiency for
+// Generic Group Signature Schemes) proves that g^x = t in SchnorrGroup and gEC^x = tEC in EC
+// group for the same x. As the orders of the two groups differ, the response z = r + c * x is
+// computed in integers. The prover also commits to x as C = H1^x * H2^s mod N and proves the
+// knowledge of its opening with the same z. As the order of RSA group is unknown, the
+// extracted x is an integer and the bound on z implies |x| < 2^(l+K+K1+1), which is smaller
+// than the half of both orders - thus the equality holds for the integer x itself.
+//
+// The secret needs to be from [0, 2^l) where l + K + K1 + 3 is at most the bit length of the
+// smaller of both orders (for example l <= 93 for 256-bit orders and K = K1 = 80).
+type CrossGroupDLogEqualityProver struct {
+	Group  *groups.SchnorrGroup
+	DLog   *dlog.ECDLog
+	Params *CrossGroupParams
+	L      int
+	K      int
+	K1     int
+	secret *big.Int
+	s      *big.Int // randomness of the commitment
+	r      *big.Int
+	rS     *big.Int
+}
+
+// CrossGroupProofRandomData contains the first messages of the proof in all three groups.
+type CrossGroupProofRandomData struct {
+	X   *big.Int              // g^r in SchnorrGroup
+	XEC *types.ECGroupElement // gEC^r in EC group
+	XN  *big.Int              // H1^r * H2^rS mod N
+}
+
+func NewCrossGroupDLogEqualityProver(group *groups.SchnorrGroup, curve dlog.Curve,
+	params *CrossGroupParams, l int) (*CrossGroupDLogEqualityProver, error) {
+	prover := &CrossGroupDLogEqualityProver{
+		Group:  group,
+		DLog:   dlog.NewECDLog(curve),
+		Params: params,
+		L:      l,
+		K:      80,
+		K1:     80,
+	}
+	if err := checkCrossGroupBound(group, prover.DLog, l, prover.K, prover.K1); err != nil {
+		return nil, err
+	}
+	return prover, nil
+}
+
+// GetProofRandomData returns the commitment to the secret and the first messages of the proof.
+func (prover *CrossGroupDLogEqualityProver) GetProofRandomData(secret, g *big.Int,
+	gEC *types.ECGroupElement) (*big.Int, *CrossGroupProofRandomData, error) {
+	if secret.Sign() < 0 || secret.BitLen() > prover.L {
+		return nil, nil, fmt.Errorf("Secret needs to be from [0, 2^%d)", prover.L)
+	}
+	prover.secret = secret
+
+	params := prover.Params
+	nBound := pow2(params.N.BitLen() + prover.K1)
+	s, err := common.GetRandomInt(nBound)
+	if err != nil {
+		return nil, nil, err
+	}
+	prover.s = s
+	commitment := prover.commit(secret, prover.s)
+
+	// r from [0, 2^(l + K + K1)), rS from [0, 2^(|N| + K + 2*K1))
+	r, err := common.GetRandomInt(pow2(prover.L + prover.K + prover.K1))
+	if err != nil {
+		return nil, nil, err
+	}
+	prover.r = r
+	rS, err := common.GetRandomInt(pow2(params.N.BitLen() + prover.K + 2*prover.K1))
+	if err != nil {
+		return nil, nil, err
+	}
+	prover.rS = rS
+
+	xEC1, xEC2 := prover.DLog.Exponentiate(gEC.X, gEC.Y,
+		new(big.Int).Mod(prover.r, prover.DLog.OrderOfSubgroup))
+	return commitment, &CrossGroupProofRandomData{
+		X:   prover.Group.Exp(g, prover.r),
+		XEC: types.NewECGroupElement(xEC1, xEC2),
+		XN:  prover.commit(prover.r, prover.rS),
+	}, nil
+}
+
+// GetProofData returns z = r + challenge * secret and zS = rS + challenge * s (computed
+// in integers).
+func (prover *CrossGroupDLogEqualityProver) GetProofData(challenge *big.Int) (*big.Int, *big.Int) {
+	z := new(big.Int).Mul(challenge, prover.secret)
+	z.Add(z, prover.r)
+	zS := new(big.Int).Mul(challenge, prover.s)
+	zS.Add(zS, prover.rS)
+	return z, zS
+}
+
+// GetCommitmentOpening returns the randomness s of the commitment C = H1^x * H2^s mod N, so
+// that the commitment can be used in further proofs.
+func (prover *CrossGroupDLogEqualityProver) GetCommitmentOpening() *big.Int {
+	return prover.s
+}
+
+func (prover *CrossGroupDLogEqualityProver) commit(x, s *big.Int) *big.Int {
+	return commitCrossGroup(prover.Params, x, s)
+}
+
+type CrossGroupDLogEqualityVerifier struct {
+	Group           *groups.SchnorrGroup
+	DLog            *dlog.ECDLog
+	Params          *CrossGroupParams
+	L               int
+	K               int
+	K1              int
+	challenge       *big.Int
+	g               *big.Int
+	t               *big.Int
+	gEC             *types.ECGroupElement
+	tEC             *types.ECGroupElement
+	commitment      *big.Int
+	proofRandomData *CrossGroupProofRandomData
+}
+
+func NewCrossGroupDLogEqualityVerifier(group *groups.SchnorrGroup, curve dlog.Curve,
+	params *CrossGroupParams, l int) (*CrossGroupDLogEqualityVerifier, error) {
+	verifier := &CrossGroupDLogEqualityVerifier{
+		Group:  group,
+		DLog:   dlog.NewECDLog(curve),
+		Params: params,
+		L:      l,
+		K:      80,
+		K1:     80,
+	}
+	if err := checkCrossGroupBound(group, verifier.DLog, l, verifier.K, verifier.K1); err != nil {
+		return nil, err
+	}
+	return verifier, nil
+}
+
+func (verifier *CrossGroupDLogEqualityVerifier) GetChallenge(g, t *big.Int,
+	gEC, tEC *types.ECGroupElement, commitment *big.Int,
+	proofRandomData *CrossGroupProofRandomData) (*big.Int, error) {
+	verifier.g = g
+	verifier.t = t
+	verifier.gEC = gEC
+	verifier.tEC = tEC
+	verifier.commitment = commitment
+	verifier.proofRandomData = proofRandomData
+
+	// challenge from [0, 2^K)
+	challenge, err := common.GetRandomInt(pow2(verifier.K))
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return challenge, nil
+}
+
+// Verify checks that z is not too big, g^z = X * t^challenge in SchnorrGroup,
+// gEC^z = XEC * tEC^challenge in EC group and H1^z * H2^zS = XN * C^challenge mod N.
+func (verifier *CrossGroupDLogEqualityVerifier) Verify(z, zS *big.Int) bool {
+	data := verifier.proofRandomData
+	if z == nil || zS == nil || data == nil || verifier.commitment == nil ||
+		data.X == nil || data.XEC == nil || data.XN == nil {
+		return false
+	}
+	if z.Sign() < 0 || z.BitLen() > verifier.L+verifier.K+verifier.K1+1 || zS.Sign() < 0 {
+		return false
+	}
+
+	left := verifier.Group.Exp(verifier.g, z)
+	right := verifier.Group.Mul(verifier.Group.Exp(verifier.t, verifier.challenge), data.X)
+	if left.Cmp(right) != 0 {
+		return false
+	}
+
+	zEC := new(big.Int).Mod(z, verifier.DLog.OrderOfSubgroup)
+	left1, left2 := verifier.DLog.Exponentiate(verifier.gEC.X, verifier.gEC.Y, zEC)
+	r1, r2 := verifier.DLog.Exponentiate(verifier.tEC.X, verifier.tEC.Y, verifier.challenge)
+	right1, right2 := verifier.DLog.Multiply(r1, r2, data.XEC.X, data.XEC.Y)
+	if left1.Cmp(right1) != 0 || left2.Cmp(right2) != 0 {
+		return false
+	}
+
+	n := verifier.Params.N
+	leftN := commitCrossGroup(verifier.Params, z, zS)
+	rightN := new(big.Int).Exp(verifier.commitment, verifier.challenge, n)
+	rightN.Mul(rightN, data.XN)
+	rightN.Mod(rightN, n)
+	return leftN.Cmp(rightN) == 0
+}
+
+// damgardFujisaki returns the parameters as Damgard-Fujisaki parameters (G = H1, H = H2), so
+// that the commitment to the secret can be used in the proofs about committed integers.
+func (params *CrossGroupParams) damgardFujisaki() *commitments.DamgardFujisakiParams {
+	return &commitments.DamgardFujisakiParams{
+		N: params.N,
+		G: params.H1,
+		H: params.H2,
+	}
+}
+
+// commitCrossGroup returns H1^x * H2^s mod N.
+func commitCrossGroup(params *CrossGroupParams, x, s *big.Int) *big.Int {
+	c := new(big.Int).Exp(params.H1, x, params.N)
+	c.Mul(c, new(big.Int).Exp(params.H2, s, params.N))
+	return c.Mod(c, params.N)
+}
+
+// checkCrossGroupBound checks that integers from (-2^(l+K+K1+1), 2^(l+K+K1+1)) are smaller
+// than the half of the orders of both groups.
+func checkCrossGroupBound(group *groups.SchnorrGroup, dLog *dlog.ECDLog, l, k, k1 int) error {
+	if l < 1 || l+k+k1+3 > keyCorrespondenceSecretBitLen(group, dLog) {
+		return fmt.Errorf("Secret bit length %d is too large for the orders of the groups", l)
+	}
+	return nil
+}
+
+// pow2 returns 2^n.
+func pow2(n int) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(n))
+}
@@ -22,21 +22,30 @@ import (
 	"github.com/xlab-si/emmy/crypto/dlog"
 	"github.com/xlab-si/emmy/types"
 	"math/big"
+	"sync"
 )
 
 // ProveECDLogEquality demonstrates how prover can prove the knowledge of log_g1(t1), log_g2(t2) and
 // that log_g1(t1) = log_g2(t2) in EC group.
 func ProveECDLogEquality(secret *big.Int, g1, g2, t1, t2 *types.ECGroupElement,
-	curve dlog.Curve) bool {
+	curve dlog.Curve) (bool, error) {
 	eProver := NewECDLogEqualityProver(curve)
 	eVerifier := NewECDLogEqualityVerifier(curve)
 
-	x1, x2 := eProver.GetProofRandomData(secret, g1, g2)
+	x1, x2, err := eProver.GetProofRandomData(secret, g1, g2)
+	if err != nil {
+		return false, err
+	}
 
-	challenge := eVerifier.GetChallenge(g1, g2, t1, t2, x1, x2)
-	z := eProver.GetProofData(challenge)
-	verified := eVerifier.Verify(z)
-	return verified
+	challenge, err := eVerifier.GetChallenge(g1, g2, t1, t2, x1, x2)
+	if err != nil {
+		return false, err
+	}
+	z, err := eProver.GetProofData(challenge)
+	if err != nil {
+		return false, err
+	}
+	return eVerifier.Verify(z), nil
 }
 
 type ECDLogEqualityProver struct {
@@ -45,6 +54,7 @@ type ECDLogEqualityProver struct {
 	secret *big.Int
 	g1     *types.ECGroupElement
 	g2     *types.ECGroupElement
+	mutex  sync.Mutex
 }
 
 func NewECDLogEqualityProver(curve dlog.Curve) *ECDLogEqualityProver {
@@ -57,28 +67,47 @@ func NewECDLogEqualityProver(curve dlog.Curve) *ECDLogEqualityProver {
 }
 
 func (prover *ECDLogEqualityProver) GetProofRandomData(secret *big.Int,
-	g1, g2 *types.ECGroupElement) (*types.ECGroupElement, *types.ECGroupElement) {
+	g1, g2 *types.ECGroupElement) (*types.ECGroupElement, *types.ECGroupElement, error) {
 	// Sets the values that are needed before the protocol can be run.
 	// The protocol proves the knowledge of log_g1(t1), log_g2(t2) and
 	// that log_g1(t1) = log_g2(t2).
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
 	prover.secret = secret
 	prover.g1 = g1
 	prover.g2 = g2
 
-	r := common.GetRandomInt(prover.DLog.GetOrderOfSubgroup())
+	r, err := common.GetRandomInt(prover.DLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, nil, err
+	}
 	prover.r = r
-	x1, y1 := prover.DLog.Exponentiate(prover.g1.X, prover.g1.Y, r)
-	x2, y2 := prover.DLog.Exponentiate(prover.g2.X, prover.g2.Y, r)
-	return types.NewECGroupElement(x1, y1), types.NewECGroupElement(x2, y2)
+	return prover.DLog.Exp(prover.g1, r), prover.DLog.Exp(prover.g2, r), nil
 }
 
-func (prover *ECDLogEqualityProver) GetProofData(challenge *big.Int) *big.Int {
+// GetProofData returns z = r + challenge * secret. It returns an error if the proof random
+// data has not been generated or has been already used (see Reset).
+func (prover *ECDLogEqualityProver) GetProofData(challenge *big.Int) (*big.Int, error) {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	if err := checkProofRandomData(prover.r); err != nil {
+		return nil, err
+	}
+
 	// z = r + challenge * secret
 	z := new(big.Int)
 	z.Mul(challenge, prover.secret)
 	z.Add(z, prover.r)
 	z.Mod(z, prover.DLog.GetOrderOfSubgroup())
-	return z
+	prover.r = nil
+	return z, nil
+}
+
+// Reset discards the randomness of an unfinished proof.
+func (prover *ECDLogEqualityProver) Reset() {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	prover.r, prover.secret, prover.g1, prover.g2 = nil, nil, nil, nil
 }
 
 type ECDLogEqualityVerifier struct {
@@ -90,6 +119,7 @@ type ECDLogEqualityVerifier struct {
 	x2        *types.ECGroupElement
 	t1        *types.ECGroupElement
 	t2        *types.ECGroupElement
+	mutex     sync.Mutex
 }
 
 func NewECDLogEqualityVerifier(curve dlog.Curve) *ECDLogEqualityVerifier {
@@ -102,7 +132,9 @@ func NewECDLogEqualityVerifier(curve dlog.Curve) *ECDLogEqualityVerifier {
 }
 
 func (verifier *ECDLogEqualityVerifier) GetChallenge(g1, g2, t1, t2, x1,
-	x2 *types.ECGroupElement) *big.Int {
+	x2 *types.ECGroupElement) (*big.Int, error) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
 	// Set the values that are needed before the protocol can be run.
 	// The protocol proves the knowledge of log_g1(t1), log_g2(t2) and
 	// that log_g1(t1) = log_g2(t2).
@@ -115,26 +147,39 @@ func (verifier *ECDLogEqualityVerifier) GetChallenge(g1, g2, t1, t2, x1,
 	verifier.x1 = x1
 	verifier.x2 = x2
 
-	challenge := common.GetRandomInt(verifier.DLog.GetOrderOfSubgroup())
+	challenge, err := common.GetRandomInt(verifier.DLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, err
+	}
 	verifier.challenge = challenge
-	return challenge
+	return challenge, nil
 }
 
 // It receives z = r + secret * challenge.
-//It returns true if g1^z = g1^r * (g1^secret) ^ challenge and g2^z = g2^r * (g2^secret) ^ challenge.
+// It returns true if g1^z = g1^r * (g1^secret) ^ challenge and g2^z = g2^r * (g2^secret) ^ challenge.
 func (verifier *ECDLogEqualityVerifier) Verify(z *big.Int) bool {
-	left11, left12 := verifier.DLog.Exponentiate(verifier.g1.X, verifier.g1.Y, z)
-	left21, left22 := verifier.DLog.Exponentiate(verifier.g2.X, verifier.g2.Y, z)
-
-	r11, r12 := verifier.DLog.Exponentiate(verifier.t1.X, verifier.t1.Y, verifier.challenge)
-	r21, r22 := verifier.DLog.Exponentiate(verifier.t2.X, verifier.t2.Y, verifier.challenge)
-	right11, right12 := verifier.DLog.Multiply(r11, r12, verifier.x1.X, verifier.x1.Y)
-	right21, right22 := verifier.DLog.Multiply(r21, r22, verifier.x2.X, verifier.x2.Y)
-
-	if left11.Cmp(right11) == 0 && left12.Cmp(right12) == 0 &&
-		left21.Cmp(right21) == 0 && left22.Cmp(right22) == 0 {
-		return true
-	} else {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	for _, p := range []*types.ECGroupElement{verifier.g1, verifier.g2, verifier.t1,
+		verifier.t2, verifier.x1, verifier.x2} {
+		if !verifier.DLog.IsInSubgroup(p) {
+			return false
+		}
+	}
+	if verifier.g1.IsInfinity() || verifier.g2.IsInfinity() || !isSet(verifier.challenge, z) {
 		return false
 	}
+
+	return verifyECDLogEquation(verifier.DLog, verifier.g1, verifier.t1, verifier.x1,
+		verifier.challenge, z) &&
+		verifyECDLogEquation(verifier.DLog, verifier.g2, verifier.t2, verifier.x2,
+			verifier.challenge, z)
+}
+
+// Reset discards the proof random data and challenge of the last proof.
+func (verifier *ECDLogEqualityVerifier) Reset() {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.g1, verifier.g2, verifier.t1, verifier.t2 = nil, nil, nil, nil
+	verifier.x1, verifier.x2, verifier.challenge = nil, nil, nil
 }
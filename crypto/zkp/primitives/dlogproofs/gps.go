@@ -0,0 +1,235 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlogproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/zkp/security"
+	"math/big"
+)
+
+// GPSSecretBitLen is the bit length of the secret keys of the GPS identification scheme.
+const GPSSecretBitLen = 160
+
+// GPSChallengeBitLen is the default bit length of the challenges (soundness error
+// is 2^-80).
+const GPSChallengeBitLen = 80
+
+// GPSStatisticalBitLen is the default statistical parameter which determines how well
+// the response hides the secret.
+const GPSStatisticalBitLen = 80
+
+// ProveGPS demonstrates how prover can identify itself with the GPS scheme.
+func ProveGPS(params *GPSParams, secret *big.Int) (bool, error) {
+	prover, err := NewGPSProver(params, secret)
+	if err != nil {
+		return false, err
+	}
+	verifier := NewGPSVerifier(params, params.PublicKey(secret))
+
+	x, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge(x)
+	if err != nil {
+		return false, err
+	}
+	y, err := prover.GetProofData(challenge)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(y), nil
+}
+
+// GPSParams are the public parameters of the GPS identification scheme: a modulus N whose
+// factorization is unknown to the verifier (for example an existing RSA modulus) and
+// a base G of large order in Z_N*.
+type GPSParams struct {
+	N *big.Int
+	G *big.Int
+}
+
+// NewGPSParams returns the parameters with modulus n and base 2 (like in ISO/IEC 9798-5).
+func NewGPSParams(n *big.Int) *GPSParams {
+	return &GPSParams{
+		N: n,
+		G: big.NewInt(2),
+	}
+}
+
+// GenerateKey returns a random secret s from [0, 2^GPSSecretBitLen) and the public key
+// G^(-s) mod N.
+func (params *GPSParams) GenerateKey() (*big.Int, *big.Int, error) {
+	s, err := common.GetRandomInt(pow2(GPSSecretBitLen))
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, params.PublicKey(s), nil
+}
+
+// PublicKey returns G^(-s) mod N.
+func (params *GPSParams) PublicKey(s *big.Int) *big.Int {
+	return common.Exponentiate(params.G, new(big.Int).Neg(s), params.N)
+}
+
+// gpsSecurity are the security parameters of the GPS prover and verifier, which need
+// to be the same on both sides.
+type gpsSecurity struct {
+	challengeBitLen   int
+	statisticalBitLen int
+}
+
+func defaultGPSSecurity() gpsSecurity {
+	return gpsSecurity{
+		challengeBitLen:   GPSChallengeBitLen,
+		statisticalBitLen: GPSStatisticalBitLen,
+	}
+}
+
+// set changes the bit length of the challenges and the statistical parameter to those
+// given by params.
+func (s *gpsSecurity) set(params security.Params) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	s.challengeBitLen = params.ChallengeBits(s.challengeBitLen)
+	s.statisticalBitLen = params.StatisticalBits(s.statisticalBitLen)
+	return nil
+}
+
+// bound returns A = 2^(GPSSecretBitLen + challenge bit length + statistical bit length),
+// the bound for the randomness of the prover.
+func (s *gpsSecurity) bound() *big.Int {
+	return pow2(GPSSecretBitLen + s.challengeBitLen + s.statisticalBitLen)
+}
+
+// GPSProver identifies itself with the GPS scheme (Girault, Poupard, Stern: On the fly
+// authentication and signature schemes based on groups of unknown order). It is Schnorr
+// protocol in Z_N* where the order of the group is unknown, thus the response is
+// computed in integers:
+//   - prover chooses r from [0, A) and sends x = G^r mod N,
+//   - verifier sends challenge c from [0, 2^GPSChallengeBitLen),
+//   - prover sends y = r + s * c,
+//   - verifier checks y < A + 2^(GPSSecretBitLen + GPSChallengeBitLen) and G^y * v^c = x
+//     where v = G^(-s) is the public key.
+//
+// As no modular reduction is needed, the response is cheap to compute (the secret is short
+// and exponentiations can be precomputed), which suits constrained devices. The modulus
+// of an existing RSA key can be used.
+//
+// The bit length of the challenges and the statistical parameter can be changed with
+// SetSecurity (on both sides).
+type GPSProver struct {
+	Params   *GPSParams
+	secret   *big.Int
+	r        *big.Int
+	security gpsSecurity
+}
+
+func NewGPSProver(params *GPSParams, secret *big.Int) (*GPSProver, error) {
+	if secret.Sign() < 0 || secret.BitLen() > GPSSecretBitLen {
+		return nil, fmt.Errorf("secret needs to be from [0, 2^%d)", GPSSecretBitLen)
+	}
+	return &GPSProver{
+		Params:   params,
+		secret:   secret,
+		security: defaultGPSSecurity(),
+	}, nil
+}
+
+// SetSecurity sets the bit length of the challenges and the statistical parameter
+// (the defaults are GPSChallengeBitLen and GPSStatisticalBitLen).
+func (prover *GPSProver) SetSecurity(params security.Params) error {
+	return prover.security.set(params)
+}
+
+// GetProofRandomData returns x = G^r mod N.
+func (prover *GPSProver) GetProofRandomData() (*big.Int, error) {
+	r, err := common.GetRandomInt(prover.security.bound())
+	if err != nil {
+		return nil, err
+	}
+	prover.r = r
+	return new(big.Int).Exp(prover.Params.G, r, prover.Params.N), nil
+}
+
+// GetProofData returns y = r + s * challenge.
+func (prover *GPSProver) GetProofData(challenge *big.Int) (*big.Int, error) {
+	if prover.r == nil {
+		return nil, fmt.Errorf("proof random data has not been generated")
+	}
+	if challenge.Sign() < 0 || challenge.BitLen() > prover.security.challengeBitLen {
+		return nil, fmt.Errorf("challenge is out of range")
+	}
+	y := new(big.Int).Mul(prover.secret, challenge)
+	y.Add(y, prover.r)
+	prover.r = nil
+	return y, nil
+}
+
+type GPSVerifier struct {
+	Params    *GPSParams
+	publicKey *big.Int
+	x         *big.Int
+	challenge *big.Int
+	security  gpsSecurity
+}
+
+func NewGPSVerifier(params *GPSParams, publicKey *big.Int) *GPSVerifier {
+	return &GPSVerifier{
+		Params:    params,
+		publicKey: publicKey,
+		security:  defaultGPSSecurity(),
+	}
+}
+
+// SetSecurity sets the bit length of the challenges and the statistical parameter
+// (the defaults are GPSChallengeBitLen and GPSStatisticalBitLen).
+func (verifier *GPSVerifier) SetSecurity(params security.Params) error {
+	return verifier.security.set(params)
+}
+
+// GetChallenge stores x and returns a random challenge of the configured bit length
+// (GPSChallengeBitLen by default).
+func (verifier *GPSVerifier) GetChallenge(x *big.Int) (*big.Int, error) {
+	verifier.x = x
+	challenge, err := common.GetRandomInt(pow2(verifier.security.challengeBitLen))
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return verifier.challenge, nil
+}
+
+// Verify checks the bound on y and G^y * v^challenge = x mod N.
+func (verifier *GPSVerifier) Verify(y *big.Int) bool {
+	if y == nil || verifier.x == nil || verifier.challenge == nil {
+		return false
+	}
+	bound := new(big.Int).Add(verifier.security.bound(),
+		pow2(GPSSecretBitLen+verifier.security.challengeBitLen))
+	if y.Sign() < 0 || y.Cmp(bound) >= 0 {
+		return false
+	}
+	params := verifier.Params
+	left := common.MultiExp([]*big.Int{params.G, verifier.publicKey},
+		[]*big.Int{y, verifier.challenge}, params.N)
+	return left != nil && left.Cmp(verifier.x) == 0
+}
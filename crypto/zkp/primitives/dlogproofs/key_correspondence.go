@@ -0,0 +1,235 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlogproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/rangeproofs"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// ProveKeyCorrespondence demonstrates how prover can prove that the same secret is
+// log_g(t) in SchnorrGroup and log_gEC(tEC) in EC group. This way one master key can
+// be used for both modular and EC nyms. The parameters of RSA group in which the secret
+// is committed need to be generated by the verifier (see NewCrossGroupParams).
+func ProveKeyCorrespondence(secret, g, t *big.Int, gEC, tEC *types.ECGroupElement,
+	group *groups.SchnorrGroup, curve dlog.Curve, params *CrossGroupParams) (bool, error) {
+	prover := NewKeyCorrespondenceProver(group, curve, params)
+	verifier := NewKeyCorrespondenceVerifier(group, curve, params)
+
+	commitment, proofRandomData, err := prover.GetProofRandomData(secret, g, gEC)
+	if err != nil {
+		return false, err
+	}
+
+	challenge, err := verifier.GetChallenge(g, t, gEC, tEC, commitment, proofRandomData)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+// Key correspondence proof is a cross-group dlog equality proof (see
+// CrossGroupDLogEqualityProver) for secrets of full length, that is smaller than both orders.
+// As the orders of the two groups differ, the response z = r + challenge * secret is computed
+// in integers and the secret is committed in RSA group of unknown order, so that the value
+// extracted from the prover is an integer x (not only a pair of residues modulo both orders,
+// which would always exist) with g^x = t and gEC^x = tEC.
+//
+// Note that l + K + K1 + 3 exceeds the bit length of the orders here, thus the bound on
+// the response only implies |x| < 2^(l+K+K1+1) where l is the bit length of the smaller
+// order m - a dishonest prover could use x larger than the orders and log_g(t) and
+// log_gEC(tEC) would then be x reduced modulo each of the orders. Thus the prover also
+// proves that x and m - 1 - x committed in C = H1^x * H2^s and H1^(m-1) / C are
+// non-negative (see rangeproofs.NonNegativeProver, the parameters of RSA group are used as
+// Damgard-Fujisaki parameters), which bounds x to [0, m) and the reduced values are equal.
+type KeyCorrespondenceProver struct {
+	*CrossGroupDLogEqualityProver
+	lower *rangeproofs.NonNegativeProver
+	upper *rangeproofs.NonNegativeProver
+}
+
+// KeyCorrespondenceProofRandomData contains the first messages of the cross-group proof and
+// of the proofs that x >= 0 (Lower) and m - 1 - x >= 0 (Upper).
+type KeyCorrespondenceProofRandomData struct {
+	CrossGroup *CrossGroupProofRandomData
+	Lower      *rangeproofs.NonNegativeProofRandomData
+	Upper      *rangeproofs.NonNegativeProofRandomData
+}
+
+// KeyCorrespondenceProofData contains the responses of the cross-group proof and of
+// the non-negativity proofs.
+type KeyCorrespondenceProofData struct {
+	Z     *big.Int
+	ZS    *big.Int
+	Lower []*rangeproofs.SquareProofData
+	Upper []*rangeproofs.SquareProofData
+}
+
+func NewKeyCorrespondenceProver(group *groups.SchnorrGroup, curve dlog.Curve,
+	params *CrossGroupParams) *KeyCorrespondenceProver {
+	dLog := dlog.NewECDLog(curve)
+	return &KeyCorrespondenceProver{
+		CrossGroupDLogEqualityProver: &CrossGroupDLogEqualityProver{
+			Group:  group,
+			DLog:   dLog,
+			Params: params,
+			L:      keyCorrespondenceSecretBitLen(group, dLog),
+			K:      80,
+			K1:     80,
+		},
+	}
+}
+
+// GetProofRandomData returns the commitment to the secret and the first messages of the proof.
+func (prover *KeyCorrespondenceProver) GetProofRandomData(secret, g *big.Int,
+	gEC *types.ECGroupElement) (*big.Int, *KeyCorrespondenceProofRandomData, error) {
+	m := keyCorrespondenceOrder(prover.Group, prover.DLog)
+	if secret.Sign() < 0 || secret.Cmp(m) >= 0 {
+		return nil, nil, fmt.Errorf("Secret needs to be smaller than the orders of both groups")
+	}
+	commitment, crossGroup, err := prover.CrossGroupDLogEqualityProver.GetProofRandomData(
+		secret, g, gEC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	params := prover.Params.damgardFujisaki()
+	s := prover.GetCommitmentOpening()
+	if prover.lower, err = rangeproofs.NewNonNegativeProver(params, secret, s); err != nil {
+		return nil, nil, err
+	}
+	upperValue := new(big.Int).Sub(m, big.NewInt(1))
+	upperValue.Sub(upperValue, secret)
+	if prover.upper, err = rangeproofs.NewNonNegativeProver(params, upperValue,
+		new(big.Int).Neg(s)); err != nil {
+		return nil, nil, err
+	}
+	lower, err := prover.lower.GetProofRandomData()
+	if err != nil {
+		return nil, nil, err
+	}
+	upper, err := prover.upper.GetProofRandomData()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return commitment, &KeyCorrespondenceProofRandomData{
+		CrossGroup: crossGroup,
+		Lower:      lower,
+		Upper:      upper,
+	}, nil
+}
+
+// GetProofData returns the responses of all the proofs to the same challenge.
+func (prover *KeyCorrespondenceProver) GetProofData(
+	challenge *big.Int) *KeyCorrespondenceProofData {
+	z, zS := prover.CrossGroupDLogEqualityProver.GetProofData(challenge)
+	return &KeyCorrespondenceProofData{
+		Z:     z,
+		ZS:    zS,
+		Lower: prover.lower.GetProofData(challenge),
+		Upper: prover.upper.GetProofData(challenge),
+	}
+}
+
+type KeyCorrespondenceVerifier struct {
+	*CrossGroupDLogEqualityVerifier
+	lower *rangeproofs.NonNegativeVerifier
+	upper *rangeproofs.NonNegativeVerifier
+}
+
+func NewKeyCorrespondenceVerifier(group *groups.SchnorrGroup, curve dlog.Curve,
+	params *CrossGroupParams) *KeyCorrespondenceVerifier {
+	dLog := dlog.NewECDLog(curve)
+	return &KeyCorrespondenceVerifier{
+		CrossGroupDLogEqualityVerifier: &CrossGroupDLogEqualityVerifier{
+			Group:  group,
+			DLog:   dLog,
+			Params: params,
+			L:      keyCorrespondenceSecretBitLen(group, dLog),
+			K:      80,
+			K1:     80,
+		},
+	}
+}
+
+// GetChallenge sets the proof random data and returns the challenge for all the proofs.
+// The commitment to m - 1 - x is computed by the verifier as H1^(m-1) / C.
+func (verifier *KeyCorrespondenceVerifier) GetChallenge(g, t *big.Int,
+	gEC, tEC *types.ECGroupElement, commitment *big.Int,
+	proofRandomData *KeyCorrespondenceProofRandomData) (*big.Int, error) {
+	if commitment == nil || proofRandomData == nil {
+		return nil, fmt.Errorf("Proof random data is not complete")
+	}
+	params := verifier.Params.damgardFujisaki()
+	m := keyCorrespondenceOrder(verifier.Group, verifier.DLog)
+	inv := new(big.Int).ModInverse(commitment, params.N)
+	if inv == nil {
+		return nil, fmt.Errorf("Commitment is not invertible")
+	}
+	upperCommitment := new(big.Int).Exp(params.G, new(big.Int).Sub(m, big.NewInt(1)),
+		params.N)
+	upperCommitment.Mul(upperCommitment, inv)
+	upperCommitment.Mod(upperCommitment, params.N)
+
+	lower := rangeproofs.NewNonNegativeVerifier(params, commitment)
+	if err := lower.SetProofRandomData(proofRandomData.Lower); err != nil {
+		return nil, err
+	}
+	upper := rangeproofs.NewNonNegativeVerifier(params, upperCommitment)
+	if err := upper.SetProofRandomData(proofRandomData.Upper); err != nil {
+		return nil, err
+	}
+
+	challenge, err := verifier.CrossGroupDLogEqualityVerifier.GetChallenge(g, t, gEC, tEC,
+		commitment, proofRandomData.CrossGroup)
+	if err != nil {
+		return nil, err
+	}
+	lower.SetChallenge(challenge)
+	upper.SetChallenge(challenge)
+	verifier.lower = lower
+	verifier.upper = upper
+	return challenge, nil
+}
+
+// Verify checks the cross-group proof and that the secret is from [0, m).
+func (verifier *KeyCorrespondenceVerifier) Verify(data *KeyCorrespondenceProofData) bool {
+	if data == nil || verifier.lower == nil || verifier.upper == nil {
+		return false
+	}
+	return verifier.CrossGroupDLogEqualityVerifier.Verify(data.Z, data.ZS) &&
+		verifier.lower.Verify(data.Lower) && verifier.upper.Verify(data.Upper)
+}
+
+// keyCorrespondenceOrder returns the smaller of both group orders.
+func keyCorrespondenceOrder(group *groups.SchnorrGroup, dLog *dlog.ECDLog) *big.Int {
+	if group.Q.Cmp(dLog.OrderOfSubgroup) < 0 {
+		return group.Q
+	}
+	return dLog.OrderOfSubgroup
+}
+
+// keyCorrespondenceSecretBitLen returns the bit length of the smaller of both group orders.
+func keyCorrespondenceSecretBitLen(group *groups.SchnorrGroup, dLog *dlog.ECDLog) int {
+	return keyCorrespondenceOrder(group, dLog).BitLen()
+}
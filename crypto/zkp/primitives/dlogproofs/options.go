@@ -0,0 +1,71 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package dlogproofs
+
+import (
+	"errors"
+	"github.com/xlab-si/emmy/crypto/dlog"
+)
+
+// SchnorrOption configures SchnorrProver, SchnorrVerifier, SchnorrECProver and
+// SchnorrECVerifier. Options are passed as the last arguments of their constructors, as
+// the options of the clients (see client.ClientOption), for example:
+//
+//	NewSchnorrECProver(WithCurve(dlog.P384), WithProtocolVariant(ZKPOK{}))
+//
+// Options which are not relevant for the constructor are ignored.
+type SchnorrOption func(*schnorrOptions)
+
+type schnorrOptions struct {
+	curve   dlog.Curve
+	variant SchnorrVariant
+}
+
+func newSchnorrOptions(opts []SchnorrOption) *schnorrOptions {
+	options := &schnorrOptions{
+		curve:   dlog.P256,
+		variant: Sigma{},
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// ecVariant returns the variant of EC provers and verifiers, DesignatedVerifier is not
+// accepted.
+func (options *schnorrOptions) ecVariant() (SchnorrECVariant, error) {
+	variant, ok := options.variant.(SchnorrECVariant)
+	if !ok {
+		return nil, errors.New("protocol variant is not supported in EC")
+	}
+	return variant, nil
+}
+
+// WithCurve sets the elliptic curve of EC provers and verifiers (default is P256).
+func WithCurve(curve dlog.Curve) SchnorrOption {
+	return func(options *schnorrOptions) {
+		options.curve = curve
+	}
+}
+
+// WithProtocolVariant sets the variant of the protocol (default is Sigma).
+func WithProtocolVariant(variant SchnorrVariant) SchnorrOption {
+	return func(options *schnorrOptions) {
+		options.variant = variant
+	}
+}
@@ -22,27 +22,37 @@ import (
 	"github.com/xlab-si/emmy/crypto/groups"
 	"github.com/xlab-si/emmy/types"
 	"math/big"
+	"sync"
 )
 
 // ProvePartialDLogKnowledge demonstrates how prover can prove that he knows dlog_a2(b2) and
 // the verifier does not know whether knowledge of dlog_a1(b1) or knowledge of dlog_a2(b2) was proved.
-func ProvePartialDLogKnowledge(group *groups.SchnorrGroup, secret1, a1, a2, b2 *big.Int) bool {
+func ProvePartialDLogKnowledge(group *groups.SchnorrGroup, secret1, a1, a2, b2 *big.Int) (bool, error) {
 	prover := NewPartialDLogProver(group)
 	verifier := NewPartialDLogVerifier(group)
 
 	b1 := prover.Group.Exp(a1, secret1)
-	triple1, triple2 := prover.GetProofRandomData(secret1, a1, b1, a2, b2)
+	triple1, triple2, err := prover.GetProofRandomData(secret1, a1, b1, a2, b2)
+	if err != nil {
+		return false, err
+	}
 
 	verifier.SetProofRandomData(triple1, triple2)
-	challenge := verifier.GetChallenge()
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
 
-	c1, z1, c2, z2 := prover.GetProofData(challenge)
-	verified := verifier.Verify(c1, z1, c2, z2)
-	return verified
+	c1, z1, c2, z2, err := prover.GetProofData(challenge)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(c1, z1, c2, z2), nil
 }
 
 // Proving that it knows either secret1 such that a1^secret1 = b1 (mod p1) or
-//  secret2 such that a2^secret2 = b2 (mod p2).
+//
+//	secret2 such that a2^secret2 = b2 (mod p2).
 type PartialDLogProver struct {
 	Group   *groups.SchnorrGroup
 	secret1 *big.Int
@@ -52,6 +62,7 @@ type PartialDLogProver struct {
 	c2      *big.Int
 	z2      *big.Int
 	ord     int
+	mutex   sync.Mutex
 }
 
 func NewPartialDLogProver(group *groups.SchnorrGroup) *PartialDLogProver {
@@ -61,13 +72,24 @@ func NewPartialDLogProver(group *groups.SchnorrGroup) *PartialDLogProver {
 }
 
 func (prover *PartialDLogProver) GetProofRandomData(secret1, a1, b1, a2,
-	b2 *big.Int) (*types.Triple, *types.Triple) {
+	b2 *big.Int) (*types.Triple, *types.Triple, error) {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
 	prover.a1 = a1
 	prover.a2 = a2
 	prover.secret1 = secret1
-	r1 := common.GetRandomInt(prover.Group.Q)
-	c2 := common.GetRandomInt(prover.Group.Q)
-	z2 := common.GetRandomInt(prover.Group.Q)
+	r1, err := common.GetRandomInt(prover.Group.Q)
+	if err != nil {
+		return nil, nil, err
+	}
+	c2, err := common.GetRandomInt(prover.Group.Q)
+	if err != nil {
+		return nil, nil, err
+	}
+	z2, err := common.GetRandomInt(prover.Group.Q)
+	if err != nil {
+		return nil, nil, err
+	}
 	prover.r1 = r1
 	prover.c2 = c2
 	prover.z2 = z2
@@ -78,22 +100,35 @@ func (prover *PartialDLogProver) GetProofRandomData(secret1, a1, b1, a2,
 	x2 = prover.Group.Mul(x2, b2ToC2Inv)
 
 	// we need to make sure that the order does not reveal which secret we do know:
-	ord := common.GetRandomInt(big.NewInt(2))
+	ord, err := common.GetRandomInt(big.NewInt(2))
+	if err != nil {
+		return nil, nil, err
+	}
 	triple1 := types.NewTriple(x1, a1, b1)
 	triple2 := types.NewTriple(x2, a2, b2)
 
 	if ord.Cmp(big.NewInt(0)) == 0 {
 		prover.ord = 0
-		return triple1, triple2
+		return triple1, triple2, nil
 	} else {
 		prover.ord = 1
-		return triple2, triple1
+		return triple2, triple1, nil
 	}
 }
 
+// GetProofData returns an error if the proof random data has not been generated or has been
+// already used (see Reset).
 func (prover *PartialDLogProver) GetProofData(challenge *big.Int) (*big.Int, *big.Int,
-	*big.Int, *big.Int) {
-	c1 := new(big.Int).Xor(prover.c2, challenge)
+	*big.Int, *big.Int, error) {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	if err := checkProofRandomData(prover.r1, prover.c2, prover.z2); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	c2, z2 := prover.c2, prover.z2
+	defer prover.reset()
+
+	c1 := new(big.Int).Xor(c2, challenge)
 
 	z1 := new(big.Int)
 	z1.Mul(c1, prover.secret1)
@@ -101,17 +136,30 @@ func (prover *PartialDLogProver) GetProofData(challenge *big.Int) (*big.Int, *bi
 	z1.Mod(z1, prover.Group.Q)
 
 	if prover.ord == 0 {
-		return c1, z1, prover.c2, prover.z2
+		return c1, z1, c2, z2, nil
 	} else {
-		return prover.c2, prover.z2, c1, z1
+		return c2, z2, c1, z1, nil
 	}
 }
 
+// Reset discards the randomness of an unfinished proof.
+func (prover *PartialDLogProver) Reset() {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	prover.reset()
+}
+
+func (prover *PartialDLogProver) reset() {
+	prover.secret1, prover.a1, prover.a2 = nil, nil, nil
+	prover.r1, prover.c2, prover.z2 = nil, nil, nil
+}
+
 type PartialDLogVerifier struct {
 	Group     *groups.SchnorrGroup
 	triple1   *types.Triple // contains x1, a1, b1
 	triple2   *types.Triple // contains x2, a2, b2
 	challenge *big.Int
+	mutex     sync.Mutex
 }
 
 func NewPartialDLogVerifier(group *groups.SchnorrGroup) *PartialDLogVerifier {
@@ -121,26 +169,44 @@ func NewPartialDLogVerifier(group *groups.SchnorrGroup) *PartialDLogVerifier {
 }
 
 func (verifier *PartialDLogVerifier) SetProofRandomData(triple1, triple2 *types.Triple) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
 	verifier.triple1 = triple1
 	verifier.triple2 = triple2
 }
 
-func (verifier *PartialDLogVerifier) GetChallenge() *big.Int {
-	challenge := common.GetRandomInt(verifier.Group.Q)
+func (verifier *PartialDLogVerifier) GetChallenge() (*big.Int, error) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	challenge, err := common.GetRandomInt(verifier.Group.Q)
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return challenge, nil
+}
+
+// SetChallenge sets the challenge which was not generated by the verifier (for example
+// the one derived by Fiat-Shamir heuristic).
+func (verifier *PartialDLogVerifier) SetChallenge(challenge *big.Int) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
 	verifier.challenge = challenge
-	return challenge
 }
 
 func (verifier *PartialDLogVerifier) verifyTriple(triple *types.Triple,
 	challenge, z *big.Int) bool {
-	left := verifier.Group.Exp(triple.B, z)       // (a, z)
-	r1 := verifier.Group.Exp(triple.C, challenge) // (b, challenge)
-	right := verifier.Group.Mul(r1, triple.A)     // (r1, x1)
-
-	return left.Cmp(right) == 0
+	// a^z = b^challenge * x
+	return verifyDLogEquation(verifier.Group, triple.B, triple.C, triple.A, challenge, z)
 }
 
 func (verifier *PartialDLogVerifier) Verify(c1, z1, c2, z2 *big.Int) bool {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	if verifier.triple1 == nil || verifier.triple2 == nil ||
+		!isSet(verifier.challenge, c1, z1, c2, z2) {
+		return false
+	}
 	c := new(big.Int).Xor(c1, c2)
 	if c.Cmp(verifier.challenge) != 0 {
 		return false
@@ -150,3 +216,10 @@ func (verifier *PartialDLogVerifier) Verify(c1, z1, c2, z2 *big.Int) bool {
 	verified2 := verifier.verifyTriple(verifier.triple2, c2, z2)
 	return verified1 && verified2
 }
+
+// Reset discards the proof random data and challenge of the last proof.
+func (verifier *PartialDLogVerifier) Reset() {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.triple1, verifier.triple2, verifier.challenge = nil, nil, nil
+}
@@ -22,30 +22,40 @@ import (
 	"github.com/xlab-si/emmy/crypto/dlog"
 	"github.com/xlab-si/emmy/types"
 	"math/big"
+	"sync"
 )
 
 // ProvePartialECDLogKnowledge demonstrates how prover can prove that he knows dlog_a2(b2) and
 // the verifier does not know whether knowledge of dlog_a1(b1) or knowledge of dlog_a2(b2) was proved.
 func ProvePartialECDLogKnowledge(dlog *dlog.ECDLog, secret1 *big.Int,
-	a1, a2, b2 *types.ECGroupElement) bool {
+	a1, a2, b2 *types.ECGroupElement) (bool, error) {
 	prover := NewPartialECDLogProver(dlog)
 	verifier := NewPartialECDLogVerifier(dlog)
 
-	b1X, b1Y := prover.DLog.Exponentiate(a1.X, a1.Y, secret1)
-	b1 := types.NewECGroupElement(b1X, b1Y)
-	triple1, triple2 := prover.GetProofRandomData(secret1, a1, b1, a2, b2)
+	b1 := prover.DLog.Exp(a1, secret1)
+	triple1, triple2, err := prover.GetProofRandomData(secret1, a1, b1, a2, b2)
+	if err != nil {
+		return false, err
+	}
 
 	verifier.SetProofRandomData(triple1, triple2)
-	challenge := verifier.GetChallenge()
-
-	c1, z1, c2, z2 := prover.GetProofData(challenge)
-	verified := verifier.Verify(c1, z1, c2, z2)
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
 
-	return verified
+	c1, z1, c2, z2, err := prover.GetProofData(challenge)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(c1, z1, c2, z2), nil
 }
 
 // Proving that it knows either secret1 such that a1^secret1 = b1 or
-//  secret2 such that a2^secret2 = b2.
+//
+//	secret2 such that a2^secret2 = b2.
+//
+// For OR (and AND) compositions of more (or other) statements see package sigma.
 type PartialECDLogProver struct {
 	DLog    *dlog.ECDLog
 	secret1 *big.Int
@@ -55,6 +65,7 @@ type PartialECDLogProver struct {
 	c2      *big.Int
 	z2      *big.Int
 	ord     int
+	mutex   sync.Mutex
 }
 
 func NewPartialECDLogProver(dlog *dlog.ECDLog) *PartialECDLogProver {
@@ -64,42 +75,61 @@ func NewPartialECDLogProver(dlog *dlog.ECDLog) *PartialECDLogProver {
 }
 
 func (prover *PartialECDLogProver) GetProofRandomData(secret1 *big.Int, a1, b1, a2,
-	b2 *types.ECGroupElement) (*types.ECTriple, *types.ECTriple) {
+	b2 *types.ECGroupElement) (*types.ECTriple, *types.ECTriple, error) {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
 	prover.a1 = a1
 	prover.a2 = a2
 	prover.secret1 = secret1
-	r1 := common.GetRandomInt(prover.DLog.GetOrderOfSubgroup())
-	c2 := common.GetRandomInt(prover.DLog.GetOrderOfSubgroup())
-	z2 := common.GetRandomInt(prover.DLog.GetOrderOfSubgroup())
+	r1, err := common.GetRandomInt(prover.DLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, nil, err
+	}
+	c2, err := common.GetRandomInt(prover.DLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, nil, err
+	}
+	z2, err := common.GetRandomInt(prover.DLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, nil, err
+	}
 	prover.r1 = r1
 	prover.c2 = c2
 	prover.z2 = z2
-	x1X, x1Y := prover.DLog.Exponentiate(a1.X, a1.Y, r1)
-	x2X, x2Y := prover.DLog.Exponentiate(a2.X, a2.Y, z2)
-	b2ToC2X, b2ToC2Y := prover.DLog.Exponentiate(b2.X, b2.Y, c2)
-	b2ToC2InvX, b2ToC2InvY := prover.DLog.Inverse(b2ToC2X, b2ToC2Y)
-	x2X, x2Y = prover.DLog.Multiply(x2X, x2Y, b2ToC2InvX, b2ToC2InvY)
-
-	x1 := types.NewECGroupElement(x1X, x1Y)
-	x2 := types.NewECGroupElement(x2X, x2Y)
+	x1 := prover.DLog.Exp(a1, r1)
+	// x2 = a2^z2 * b2^(-c2)
+	x2 := prover.DLog.Mul(prover.DLog.Exp(a2, z2), prover.DLog.Inv(prover.DLog.Exp(b2, c2)))
 
 	// we need to make sure that the order does not reveal which secret we do know:
-	ord := common.GetRandomInt(big.NewInt(2))
+	ord, err := common.GetRandomInt(big.NewInt(2))
+	if err != nil {
+		return nil, nil, err
+	}
 	triple1 := types.NewECTriple(x1, a1, b1)
 	triple2 := types.NewECTriple(x2, a2, b2)
 
 	if ord.Cmp(big.NewInt(0)) == 0 {
 		prover.ord = 0
-		return triple1, triple2
+		return triple1, triple2, nil
 	} else {
 		prover.ord = 1
-		return triple2, triple1
+		return triple2, triple1, nil
 	}
 }
 
+// GetProofData returns an error if the proof random data has not been generated or has been
+// already used (see Reset).
 func (prover *PartialECDLogProver) GetProofData(challenge *big.Int) (*big.Int, *big.Int,
-	*big.Int, *big.Int) {
-	c1 := new(big.Int).Xor(prover.c2, challenge)
+	*big.Int, *big.Int, error) {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	if err := checkProofRandomData(prover.r1, prover.c2, prover.z2); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	c2, z2 := prover.c2, prover.z2
+	defer prover.reset()
+
+	c1 := new(big.Int).Xor(c2, challenge)
 
 	z1 := new(big.Int)
 	z1.Mul(c1, prover.secret1)
@@ -107,17 +137,30 @@ func (prover *PartialECDLogProver) GetProofData(challenge *big.Int) (*big.Int, *
 	z1.Mod(z1, prover.DLog.GetOrderOfSubgroup())
 
 	if prover.ord == 0 {
-		return c1, z1, prover.c2, prover.z2
+		return c1, z1, c2, z2, nil
 	} else {
-		return prover.c2, prover.z2, c1, z1
+		return c2, z2, c1, z1, nil
 	}
 }
 
+// Reset discards the randomness of an unfinished proof.
+func (prover *PartialECDLogProver) Reset() {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	prover.reset()
+}
+
+func (prover *PartialECDLogProver) reset() {
+	prover.secret1, prover.a1, prover.a2 = nil, nil, nil
+	prover.r1, prover.c2, prover.z2 = nil, nil, nil
+}
+
 type PartialECDLogVerifier struct {
 	DLog      *dlog.ECDLog
 	triple1   *types.ECTriple // contains x1, a1, b1
 	triple2   *types.ECTriple // contains x2, a2, b2
 	challenge *big.Int
+	mutex     sync.Mutex
 }
 
 func NewPartialECDLogVerifier(dlog *dlog.ECDLog) *PartialECDLogVerifier {
@@ -127,26 +170,48 @@ func NewPartialECDLogVerifier(dlog *dlog.ECDLog) *PartialECDLogVerifier {
 }
 
 func (verifier *PartialECDLogVerifier) SetProofRandomData(triple1, triple2 *types.ECTriple) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
 	verifier.triple1 = triple1
 	verifier.triple2 = triple2
 }
 
-func (verifier *PartialECDLogVerifier) GetChallenge() *big.Int {
-	challenge := common.GetRandomInt(verifier.DLog.GetOrderOfSubgroup())
+func (verifier *PartialECDLogVerifier) GetChallenge() (*big.Int, error) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	challenge, err := common.GetRandomInt(verifier.DLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return challenge, nil
+}
+
+// SetChallenge sets the challenge which was not generated by the verifier (for example
+// the one derived by Fiat-Shamir heuristic).
+func (verifier *PartialECDLogVerifier) SetChallenge(challenge *big.Int) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
 	verifier.challenge = challenge
-	return challenge
 }
 
 func (verifier *PartialECDLogVerifier) verifyTriple(triple *types.ECTriple,
 	challenge, z *big.Int) bool {
-	left1, left2 := verifier.DLog.Exponentiate(triple.B.X, triple.B.Y, z)    // a.X, a.Y, z
-	r1, r2 := verifier.DLog.Exponentiate(triple.C.X, triple.C.Y, challenge)  // b.X, b.Y, challenge
-	right1, right2 := verifier.DLog.Multiply(r1, r2, triple.A.X, triple.A.Y) // r1, r2, x.X, x.Y
-
-	return left1.Cmp(right1) == 0 && left2.Cmp(right2) == 0
+	if !verifier.DLog.IsInSubgroup(triple.A) || !verifier.DLog.IsInSubgroup(triple.B) ||
+		!verifier.DLog.IsInSubgroup(triple.C) || triple.B.IsInfinity() {
+		return false
+	}
+	// a^z = b^challenge * x
+	return verifyECDLogEquation(verifier.DLog, triple.B, triple.C, triple.A, challenge, z)
 }
 
 func (verifier *PartialECDLogVerifier) Verify(c1, z1, c2, z2 *big.Int) bool {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	if verifier.triple1 == nil || verifier.triple2 == nil ||
+		!isSet(verifier.challenge, c1, z1, c2, z2) {
+		return false
+	}
 	c := new(big.Int).Xor(c1, c2)
 	if c.Cmp(verifier.challenge) != 0 {
 		return false
@@ -156,3 +221,10 @@ func (verifier *PartialECDLogVerifier) Verify(c1, z1, c2, z2 *big.Int) bool {
 	verified2 := verifier.verifyTriple(verifier.triple2, c2, z2)
 	return verified1 && verified2
 }
+
+// Reset discards the proof random data and challenge of the last proof.
+func (verifier *PartialECDLogVerifier) Reset() {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.triple1, verifier.triple2, verifier.challenge = nil, nil, nil
+}
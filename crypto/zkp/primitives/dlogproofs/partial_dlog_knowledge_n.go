@@ -0,0 +1,273 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlogproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+)
+
+// ProvePartialDLogKnowledgeN demonstrates how prover can prove that he knows at least k
+// out of n discrete logarithms log_a[i](b[i]), without revealing which ones. Secrets which
+// the prover does not know are nil.
+func ProvePartialDLogKnowledgeN(group *groups.SchnorrGroup, k int, a, b,
+	secrets []*big.Int) (bool, error) {
+	prover, err := NewPartialDLogProverN(group, k, a, b, secrets)
+	if err != nil {
+		return false, err
+	}
+	verifier := NewPartialDLogVerifierN(group, k, a, b)
+
+	x, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	verifier.SetProofRandomData(x)
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+
+	challenges, z := prover.GetProofData(challenge)
+	return verifier.Verify(challenges, z), nil
+}
+
+// PartialDLogProverN proves the knowledge of k out of n discrete logarithms, it generalizes
+// PartialDLogProver (which is 1 out of 2) as proposed in Cramer, Damgard, Schoenmakers:
+// Proofs of Partial Knowledge and Simplified Design of Witness Hiding Protocols.
+//
+// The challenge c is shared by Shamir's secret sharing with threshold n-k+1 - the challenges
+// c_i of the n proofs are the shares (c_i = f(i) for a polynomial f of degree n-k with
+// f(0) = c). For each of the n-k statements for which the prover does not know the secret,
+// the proof is simulated with a randomly chosen c_i. These shares determine f, and thus
+// the challenges of the k proofs for which the prover knows the secrets.
+type PartialDLogProverN struct {
+	Group   *groups.SchnorrGroup
+	k       int
+	a       []*big.Int
+	b       []*big.Int
+	secrets []*big.Int // nil for secrets which are not known (or not used)
+	r       []*big.Int
+	c       []*big.Int // challenges of the simulated proofs
+	z       []*big.Int
+}
+
+// NewPartialDLogProverN returns a prover for the statement that it knows at least k out of n
+// secrets such that a[i]^secrets[i] = b[i], where n = len(a). Secrets which are not known
+// are nil - it returns an error if less than k secrets are given.
+func NewPartialDLogProverN(group *groups.SchnorrGroup, k int, a, b,
+	secrets []*big.Int) (*PartialDLogProverN, error) {
+	known, err := knownSecrets(k, len(a), len(b), secrets)
+	if err != nil {
+		return nil, err
+	}
+	return &PartialDLogProverN{
+		Group:   group,
+		k:       k,
+		a:       a,
+		b:       b,
+		secrets: known,
+	}, nil
+}
+
+// GetProofRandomData returns x_i for each of the n statements - a[i]^r_i for the proofs
+// with known secrets and the simulated a[i]^z_i * b[i]^(-c_i) for the others.
+func (prover *PartialDLogProverN) GetProofRandomData() ([]*big.Int, error) {
+	n := len(prover.a)
+	prover.r = make([]*big.Int, n)
+	prover.c = make([]*big.Int, n)
+	prover.z = make([]*big.Int, n)
+	x := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		if prover.secrets[i] != nil {
+			r, err := common.GetRandomInt(prover.Group.Q)
+			if err != nil {
+				return nil, err
+			}
+			prover.r[i] = r
+			x[i] = prover.Group.Exp(prover.a[i], prover.r[i])
+			continue
+		}
+		c, err := common.GetRandomInt(prover.Group.Q)
+		if err != nil {
+			return nil, err
+		}
+		prover.c[i] = c
+		z, err := common.GetRandomInt(prover.Group.Q)
+		if err != nil {
+			return nil, err
+		}
+		prover.z[i] = z
+		bToC := prover.Group.Exp(prover.b[i], prover.c[i])
+		x[i] = prover.Group.Mul(prover.Group.Exp(prover.a[i], prover.z[i]),
+			prover.Group.Inv(bToC))
+	}
+	return x, nil
+}
+
+// GetProofData returns the challenges and z_i of all n proofs.
+func (prover *PartialDLogProverN) GetProofData(challenge *big.Int) ([]*big.Int, []*big.Int) {
+	challenges := shareChallenge(challenge, prover.c, prover.Group.Q)
+	z := make([]*big.Int, len(prover.a))
+	for i := range z {
+		if prover.secrets[i] == nil {
+			z[i] = prover.z[i]
+			continue
+		}
+		// z_i = r_i + c_i * secret_i
+		z[i] = new(big.Int).Mul(challenges[i], prover.secrets[i])
+		z[i].Add(z[i], prover.r[i])
+		z[i].Mod(z[i], prover.Group.Q)
+	}
+	return challenges, z
+}
+
+type PartialDLogVerifierN struct {
+	Group     *groups.SchnorrGroup
+	k         int
+	a         []*big.Int
+	b         []*big.Int
+	x         []*big.Int
+	challenge *big.Int
+}
+
+// NewPartialDLogVerifierN returns a verifier for the statement that the prover knows at
+// least k out of n secrets such that a[i]^secrets[i] = b[i].
+func NewPartialDLogVerifierN(group *groups.SchnorrGroup, k int, a,
+	b []*big.Int) *PartialDLogVerifierN {
+	return &PartialDLogVerifierN{
+		Group: group,
+		k:     k,
+		a:     a,
+		b:     b,
+	}
+}
+
+func (verifier *PartialDLogVerifierN) SetProofRandomData(x []*big.Int) {
+	verifier.x = x
+}
+
+func (verifier *PartialDLogVerifierN) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(verifier.Group.Q)
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return challenge, nil
+}
+
+// SetChallenge sets the challenge which was not generated by the verifier (for example
+// the one derived by Fiat-Shamir heuristic).
+func (verifier *PartialDLogVerifierN) SetChallenge(challenge *big.Int) {
+	verifier.challenge = challenge
+}
+
+// Verify checks that the challenges are shares of the challenge (that is, at most n-k of
+// them were chosen by the prover) and that a[i]^z_i = x_i * b[i]^c_i for each i.
+func (verifier *PartialDLogVerifierN) Verify(challenges, z []*big.Int) bool {
+	n := len(verifier.a)
+	if verifier.k < 1 || verifier.k > n ||
+		len(verifier.b) != n || len(verifier.x) != n || len(z) != n ||
+		!validShares(verifier.challenge, challenges, verifier.k, n, verifier.Group.Q) {
+		return false
+	}
+
+	for i := 0; i < n; i++ {
+		if z[i] == nil || verifier.x[i] == nil {
+			return false
+		}
+		if !verifyDLogEquation(verifier.Group, verifier.a[i], verifier.b[i], verifier.x[i],
+			challenges[i], z[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// knownSecrets checks the parameters of k out of n proof and returns secrets where only
+// the first k known secrets are kept - the others are treated as unknown.
+func knownSecrets(k, n, nB int, secrets []*big.Int) ([]*big.Int, error) {
+	if n == 0 || nB != n || len(secrets) != n {
+		return nil, fmt.Errorf("Each of the n statements needs to have a base, a value and a (nil) secret.")
+	}
+	if k < 1 || k > n {
+		return nil, fmt.Errorf("Number of known secrets needs to be in [1, %d].", n)
+	}
+
+	known := make([]*big.Int, n)
+	count := 0
+	for i, s := range secrets {
+		if s != nil && count < k {
+			known[i] = s
+			count++
+		}
+	}
+	if count < k {
+		return nil, fmt.Errorf("Only %d out of %d needed secrets are known.", count, k)
+	}
+	return known, nil
+}
+
+// shareChallenge returns the shares c_i = f(i+1) of the challenge, where f is the polynomial
+// of degree n-k determined by f(0) = challenge and the n-k given shares (those which are
+// nil in fixed are computed).
+func shareChallenge(challenge *big.Int, fixed []*big.Int, q *big.Int) []*big.Int {
+	points := map[*big.Int]*big.Int{big.NewInt(0): challenge}
+	for i, c := range fixed {
+		if c != nil {
+			points[big.NewInt(int64(i+1))] = c
+		}
+	}
+
+	shares := make([]*big.Int, len(fixed))
+	for i, c := range fixed {
+		if c != nil {
+			shares[i] = c
+		} else {
+			shares[i] = common.LagrangeInterpolation(big.NewInt(int64(i+1)), points, q)
+		}
+	}
+	return shares
+}
+
+// validShares checks that the n shares and f(0) = challenge lie on a polynomial of degree
+// at most n-k - the first n-k shares (and the challenge) determine the polynomial, which
+// needs to go through the remaining k shares.
+func validShares(challenge *big.Int, shares []*big.Int, k, n int, q *big.Int) bool {
+	if challenge == nil || len(shares) != n {
+		return false
+	}
+	for _, c := range shares {
+		if c == nil || c.Sign() < 0 || c.Cmp(q) >= 0 {
+			return false
+		}
+	}
+
+	points := map[*big.Int]*big.Int{big.NewInt(0): challenge}
+	for i := 0; i < n-k; i++ {
+		points[big.NewInt(int64(i+1))] = shares[i]
+	}
+	for i := n - k; i < n; i++ {
+		if common.LagrangeInterpolation(big.NewInt(int64(i+1)), points, q).Cmp(shares[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
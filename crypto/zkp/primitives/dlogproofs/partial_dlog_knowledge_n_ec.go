@@ -0,0 +1,199 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlogproofs
+
+import (
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// ProvePartialECDLogKnowledgeN demonstrates how prover can prove that he knows at least k
+// out of n discrete logarithms log_a[i](b[i]) in EC group, without revealing which ones.
+// Secrets which the prover does not know are nil.
+func ProvePartialECDLogKnowledgeN(dlog *dlog.ECDLog, k int, a, b []*types.ECGroupElement,
+	secrets []*big.Int) (bool, error) {
+	prover, err := NewPartialECDLogProverN(dlog, k, a, b, secrets)
+	if err != nil {
+		return false, err
+	}
+	verifier := NewPartialECDLogVerifierN(dlog, k, a, b)
+
+	x, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	verifier.SetProofRandomData(x)
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+
+	challenges, z := prover.GetProofData(challenge)
+	return verifier.Verify(challenges, z), nil
+}
+
+// PartialECDLogProverN is PartialDLogProverN in EC group.
+type PartialECDLogProverN struct {
+	DLog    *dlog.ECDLog
+	k       int
+	a       []*types.ECGroupElement
+	b       []*types.ECGroupElement
+	secrets []*big.Int // nil for secrets which are not known (or not used)
+	r       []*big.Int
+	c       []*big.Int // challenges of the simulated proofs
+	z       []*big.Int
+}
+
+// NewPartialECDLogProverN returns a prover for the statement that it knows at least k out of
+// n secrets such that a[i]^secrets[i] = b[i], where n = len(a). Secrets which are not known
+// are nil - it returns an error if less than k secrets are given.
+func NewPartialECDLogProverN(dlog *dlog.ECDLog, k int, a, b []*types.ECGroupElement,
+	secrets []*big.Int) (*PartialECDLogProverN, error) {
+	known, err := knownSecrets(k, len(a), len(b), secrets)
+	if err != nil {
+		return nil, err
+	}
+	return &PartialECDLogProverN{
+		DLog:    dlog,
+		k:       k,
+		a:       a,
+		b:       b,
+		secrets: known,
+	}, nil
+}
+
+// GetProofRandomData returns x_i for each of the n statements - a[i]^r_i for the proofs
+// with known secrets and the simulated a[i]^z_i * b[i]^(-c_i) for the others.
+func (prover *PartialECDLogProverN) GetProofRandomData() ([]*types.ECGroupElement, error) {
+	n := len(prover.a)
+	order := prover.DLog.GetOrderOfSubgroup()
+	prover.r = make([]*big.Int, n)
+	prover.c = make([]*big.Int, n)
+	prover.z = make([]*big.Int, n)
+	x := make([]*types.ECGroupElement, n)
+	for i := 0; i < n; i++ {
+		a, b := prover.a[i], prover.b[i]
+		if prover.secrets[i] != nil {
+			r, err := common.GetRandomInt(order)
+			if err != nil {
+				return nil, err
+			}
+			prover.r[i] = r
+			x[i] = prover.DLog.Exp(a, prover.r[i])
+			continue
+		}
+		c, err := common.GetRandomInt(order)
+		if err != nil {
+			return nil, err
+		}
+		prover.c[i] = c
+		z, err := common.GetRandomInt(order)
+		if err != nil {
+			return nil, err
+		}
+		prover.z[i] = z
+		// x = a^z * b^(-c)
+		x[i] = prover.DLog.Mul(prover.DLog.Exp(a, prover.z[i]),
+			prover.DLog.Inv(prover.DLog.Exp(b, prover.c[i])))
+	}
+	return x, nil
+}
+
+// GetProofData returns the challenges and z_i of all n proofs.
+func (prover *PartialECDLogProverN) GetProofData(challenge *big.Int) ([]*big.Int, []*big.Int) {
+	order := prover.DLog.GetOrderOfSubgroup()
+	challenges := shareChallenge(challenge, prover.c, order)
+	z := make([]*big.Int, len(prover.a))
+	for i := range z {
+		if prover.secrets[i] == nil {
+			z[i] = prover.z[i]
+			continue
+		}
+		// z_i = r_i + c_i * secret_i
+		z[i] = new(big.Int).Mul(challenges[i], prover.secrets[i])
+		z[i].Add(z[i], prover.r[i])
+		z[i].Mod(z[i], order)
+	}
+	return challenges, z
+}
+
+type PartialECDLogVerifierN struct {
+	DLog      *dlog.ECDLog
+	k         int
+	a         []*types.ECGroupElement
+	b         []*types.ECGroupElement
+	x         []*types.ECGroupElement
+	challenge *big.Int
+}
+
+// NewPartialECDLogVerifierN returns a verifier for the statement that the prover knows at
+// least k out of n secrets such that a[i]^secrets[i] = b[i].
+func NewPartialECDLogVerifierN(dlog *dlog.ECDLog, k int,
+	a, b []*types.ECGroupElement) *PartialECDLogVerifierN {
+	return &PartialECDLogVerifierN{
+		DLog: dlog,
+		k:    k,
+		a:    a,
+		b:    b,
+	}
+}
+
+func (verifier *PartialECDLogVerifierN) SetProofRandomData(x []*types.ECGroupElement) {
+	verifier.x = x
+}
+
+func (verifier *PartialECDLogVerifierN) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(verifier.DLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return challenge, nil
+}
+
+// SetChallenge sets the challenge which was not generated by the verifier (for example
+// the one derived by Fiat-Shamir heuristic).
+func (verifier *PartialECDLogVerifierN) SetChallenge(challenge *big.Int) {
+	verifier.challenge = challenge
+}
+
+// Verify checks that the challenges are shares of the challenge (that is, at most n-k of
+// them were chosen by the prover) and that a[i]^z_i = x_i * b[i]^c_i for each i.
+func (verifier *PartialECDLogVerifierN) Verify(challenges, z []*big.Int) bool {
+	n := len(verifier.a)
+	if verifier.k < 1 || verifier.k > n ||
+		len(verifier.b) != n || len(verifier.x) != n || len(z) != n ||
+		!validShares(verifier.challenge, challenges, verifier.k, n,
+			verifier.DLog.GetOrderOfSubgroup()) {
+		return false
+	}
+
+	for i := 0; i < n; i++ {
+		a, b, x := verifier.a[i], verifier.b[i], verifier.x[i]
+		if z[i] == nil || !verifier.DLog.IsInSubgroup(x) || !verifier.DLog.IsInSubgroup(a) ||
+			!verifier.DLog.IsInSubgroup(b) || a.IsInfinity() {
+			return false
+		}
+		if !verifyECDLogEquation(verifier.DLog, a, b, x, challenges[i], z[i]) {
+			return false
+		}
+	}
+	return true
+}
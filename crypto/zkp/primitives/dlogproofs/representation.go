@@ -0,0 +1,165 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlogproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+)
+
+// ProveRepresentation demonstrates how the prover proves that it knows (x_1,...,x_k)
+// such that y = g_1^x_1 * ... * g_k^x_k where g_i are given generators of cyclic group G.
+// Note that Schnorr is a special case of RepresentationProver where only one base is used.
+func ProveRepresentation(group *groups.SchnorrGroup, secrets, bases []*big.Int,
+	y *big.Int) (bool, error) {
+	prover, err := NewRepresentationProver(group, secrets, bases, y)
+	if err != nil {
+		return false, err
+	}
+	verifier := NewRepresentationVerifier(group, bases, y)
+
+	proofRandomData, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	verifier.SetProofRandomData(proofRandomData)
+
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	proofData, err := prover.GetProofData(challenge)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(proofData), nil
+}
+
+// Proving that it knows (x_1,...,x_k) such that y = g_1^x_1 * ... * g_k^x_k (mod p).
+type RepresentationProver struct {
+	Group        *groups.SchnorrGroup
+	secrets      []*big.Int
+	bases        []*big.Int
+	randomValues []*big.Int
+	y            *big.Int
+}
+
+func NewRepresentationProver(group *groups.SchnorrGroup, secrets,
+	bases []*big.Int, y *big.Int) (*RepresentationProver, error) {
+	if len(secrets) != len(bases) || len(bases) == 0 {
+		return nil, fmt.Errorf("Number of secrets and representation bases should be the same.")
+	}
+
+	return &RepresentationProver{
+		Group:   group,
+		secrets: secrets,
+		bases:   bases,
+		y:       y,
+	}, nil
+}
+
+func (prover *RepresentationProver) GetProofRandomData() (*big.Int, error) {
+	// t = g_1^r_1 * ... * g_k^r_k where g_i are bases and r_i are random values
+	t := big.NewInt(1)
+	prover.randomValues = make([]*big.Int, len(prover.bases))
+	for i, base := range prover.bases {
+		randomValue, err := common.GetRandomInt(prover.Group.Q)
+		if err != nil {
+			return nil, err
+		}
+		prover.randomValues[i] = randomValue
+		t = prover.Group.Mul(t, prover.Group.Exp(base, prover.randomValues[i]))
+	}
+	return t, nil
+}
+
+// GetProofData returns an error if the proof random data has not been generated.
+func (prover *RepresentationProver) GetProofData(challenge *big.Int) ([]*big.Int, error) {
+	// z_i = r_i + challenge * secrets[i]
+	return representationProofData(challenge, prover.secrets, prover.randomValues,
+		prover.Group.Q)
+}
+
+type RepresentationVerifier struct {
+	Group           *groups.SchnorrGroup
+	bases           []*big.Int
+	proofRandomData *big.Int
+	y               *big.Int
+	challenge       *big.Int
+}
+
+func NewRepresentationVerifier(group *groups.SchnorrGroup, bases []*big.Int,
+	y *big.Int) *RepresentationVerifier {
+	return &RepresentationVerifier{
+		Group: group,
+		bases: bases,
+		y:     y,
+	}
+}
+
+func (verifier *RepresentationVerifier) SetProofRandomData(proofRandomData *big.Int) {
+	verifier.proofRandomData = proofRandomData
+}
+
+func (verifier *RepresentationVerifier) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(verifier.Group.Q)
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return challenge, nil
+}
+
+// SetChallenge sets the challenge which was not generated by the verifier (for example
+// the one derived by Fiat-Shamir heuristic).
+func (verifier *RepresentationVerifier) SetChallenge(challenge *big.Int) {
+	verifier.challenge = challenge
+}
+
+func (verifier *RepresentationVerifier) Verify(proofData []*big.Int) bool {
+	if len(proofData) != len(verifier.bases) || len(proofData) == 0 ||
+		verifier.proofRandomData == nil {
+		return false
+	}
+
+	// check:
+	// g_1^z_1 * ... * g_k^z_k = (g_1^x_1 * ... * g_k^x_k)^challenge * (g_1^r_1 * ... * g_k^r_k)
+	// computed as g_1^z_1 * ... * g_k^z_k * y^(-challenge) = g_1^r_1 * ... * g_k^r_k
+	bases := append(append([]*big.Int{}, verifier.bases...), verifier.y)
+	exponents := append(append([]*big.Int{}, proofData...), new(big.Int).Neg(verifier.challenge))
+	left := common.MultiExp(bases, exponents, verifier.Group.P)
+
+	return left != nil && left.Cmp(verifier.proofRandomData) == 0
+}
+
+// representationProofData returns z_i = r_i + challenge * secrets[i] (mod order).
+func representationProofData(challenge *big.Int, secrets, randomValues []*big.Int,
+	order *big.Int) ([]*big.Int, error) {
+	if randomValues == nil {
+		return nil, errNoProofRandomData
+	}
+	proofData := make([]*big.Int, len(secrets))
+	for i, secret := range secrets {
+		z := new(big.Int).Mul(challenge, secret)
+		z.Add(z, randomValues[i])
+		proofData[i] = z.Mod(z, order)
+	}
+	return proofData, nil
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlogproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// ProveECRepresentation demonstrates how the prover proves that it knows (x_1,...,x_k)
+// such that y = g_1^x_1 * ... * g_k^x_k where g_i are given points of EC group.
+func ProveECRepresentation(curve dlog.Curve, secrets []*big.Int,
+	bases []*types.ECGroupElement, y *types.ECGroupElement) (bool, error) {
+	prover, err := NewRepresentationECProver(curve, secrets, bases, y)
+	if err != nil {
+		return false, err
+	}
+	verifier := NewRepresentationECVerifier(curve, bases, y)
+
+	proofRandomData, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	verifier.SetProofRandomData(proofRandomData)
+
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	proofData, err := prover.GetProofData(challenge)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(proofData), nil
+}
+
+// Proving that it knows (x_1,...,x_k) such that y = g_1^x_1 * ... * g_k^x_k in EC group.
+type RepresentationECProver struct {
+	DLog         *dlog.ECDLog
+	secrets      []*big.Int
+	bases        []*types.ECGroupElement
+	randomValues []*big.Int
+	y            *types.ECGroupElement
+}
+
+func NewRepresentationECProver(curve dlog.Curve, secrets []*big.Int,
+	bases []*types.ECGroupElement, y *types.ECGroupElement) (*RepresentationECProver, error) {
+	if len(secrets) != len(bases) || len(bases) == 0 {
+		return nil, fmt.Errorf("Number of secrets and representation bases should be the same.")
+	}
+
+	return &RepresentationECProver{
+		DLog:    dlog.NewECDLog(curve),
+		secrets: secrets,
+		bases:   bases,
+		y:       y,
+	}, nil
+}
+
+func (prover *RepresentationECProver) GetProofRandomData() (*types.ECGroupElement, error) {
+	// t = g_1^r_1 * ... * g_k^r_k where g_i are bases and r_i are random values
+	prover.randomValues = make([]*big.Int, len(prover.bases))
+	for i := range prover.bases {
+		randomValue, err := common.GetRandomInt(prover.DLog.GetOrderOfSubgroup())
+		if err != nil {
+			return nil, err
+		}
+		prover.randomValues[i] = randomValue
+	}
+	return common.MultiExpEC(prover.DLog.Curve, prover.bases, prover.randomValues), nil
+}
+
+// GetProofData returns an error if the proof random data has not been generated.
+func (prover *RepresentationECProver) GetProofData(challenge *big.Int) ([]*big.Int, error) {
+	// z_i = r_i + challenge * secrets[i]
+	return representationProofData(challenge, prover.secrets, prover.randomValues,
+		prover.DLog.GetOrderOfSubgroup())
+}
+
+type RepresentationECVerifier struct {
+	DLog            *dlog.ECDLog
+	bases           []*types.ECGroupElement
+	proofRandomData *types.ECGroupElement
+	y               *types.ECGroupElement
+	challenge       *big.Int
+}
+
+func NewRepresentationECVerifier(curve dlog.Curve, bases []*types.ECGroupElement,
+	y *types.ECGroupElement) *RepresentationECVerifier {
+	return &RepresentationECVerifier{
+		DLog:  dlog.NewECDLog(curve),
+		bases: bases,
+		y:     y,
+	}
+}
+
+func (verifier *RepresentationECVerifier) SetProofRandomData(proofRandomData *types.ECGroupElement) {
+	verifier.proofRandomData = proofRandomData
+}
+
+func (verifier *RepresentationECVerifier) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(verifier.DLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return challenge, nil
+}
+
+// SetChallenge sets the challenge which was not generated by the verifier (for example
+// the one derived by Fiat-Shamir heuristic).
+func (verifier *RepresentationECVerifier) SetChallenge(challenge *big.Int) {
+	verifier.challenge = challenge
+}
+
+func (verifier *RepresentationECVerifier) Verify(proofData []*big.Int) bool {
+	if len(proofData) != len(verifier.bases) || len(proofData) == 0 || verifier.challenge == nil ||
+		!verifier.DLog.IsInSubgroup(verifier.proofRandomData) || !verifier.DLog.IsInSubgroup(verifier.y) {
+		return false
+	}
+	for _, base := range verifier.bases {
+		if !verifier.DLog.IsInSubgroup(base) {
+			return false
+		}
+	}
+
+	// check:
+	// g_1^z_1 * ... * g_k^z_k = (g_1^x_1 * ... * g_k^x_k)^challenge * (g_1^r_1 * ... * g_k^r_k)
+	// computed as g_1^z_1 * ... * g_k^z_k * y^(-challenge) = g_1^r_1 * ... * g_k^r_k
+	bases := append(append([]*types.ECGroupElement{}, verifier.bases...), verifier.y)
+	exponents := append(append([]*big.Int{}, proofData...), new(big.Int).Neg(verifier.challenge))
+	left := common.MultiExpEC(verifier.DLog.Curve, bases, exponents)
+
+	return left.Equals(verifier.proofRandomData)
+}
@@ -21,23 +21,78 @@ import (
 	"github.com/xlab-si/emmy/crypto/commitments"
 	"github.com/xlab-si/emmy/crypto/common"
 	"github.com/xlab-si/emmy/crypto/groups"
-	"github.com/xlab-si/emmy/types"
 	"math/big"
+	"sync"
 )
 
 // ProveDLogKnowledge demonstrates how prover can prove the knowledge of log_g1(t1) - that
 // means g1^secret = t1.
-func ProveDLogKnowledge(secret, g1, t1 *big.Int, group *groups.SchnorrGroup) bool {
-	prover := NewSchnorrProver(group, types.Sigma)
-	verifier := NewSchnorrVerifier(group, types.Sigma)
+func ProveDLogKnowledge(secret, g1, t1 *big.Int, group *groups.SchnorrGroup) (bool, error) {
+	prover, err := NewSchnorrProver(group)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewSchnorrVerifier(group)
+	if err != nil {
+		return false, err
+	}
+
+	x, err := prover.GetProofRandomData(secret, g1)
+	if err != nil {
+		return false, err
+	}
+	verifier.SetProofRandomData(x, g1, t1)
+
+	challenge, _, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	z, _, err := prover.GetProofData(challenge)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(z), nil
+}
 
-	x := prover.GetProofRandomData(secret, g1)
+// ProveDLogKnowledgeToDesignatedVerifier demonstrates how prover can prove the knowledge
+// of log_g1(t1) in a way which convinces only the verifier with the given public key.
+func ProveDLogKnowledgeToDesignatedVerifier(secret, g1, t1 *big.Int,
+	group *groups.SchnorrGroup) (bool, error) {
+	prover, err := NewSchnorrProver(group, WithProtocolVariant(DesignatedVerifier{}))
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewSchnorrVerifier(group, WithProtocolVariant(DesignatedVerifier{}))
+	if err != nil {
+		return false, err
+	}
+
+	// the verifier's public key is usually known in advance (for example from a certificate)
+	prover.SetVerifierPublicKey(verifier.GetPublicKey())
+	commitment, err := verifier.GetChallengeCommitment()
+	if err != nil {
+		return false, err
+	}
+	prover.PedersenReceiver.SetCommitment(commitment)
+
+	x, err := prover.GetProofRandomData(secret, g1)
+	if err != nil {
+		return false, err
+	}
 	verifier.SetProofRandomData(x, g1, t1)
 
-	challenge, _ := verifier.GetChallenge()
-	z, _ := prover.GetProofData(challenge)
-	verified := verifier.Verify(z, nil)
-	return verified
+	challenge, r, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	if !prover.PedersenReceiver.CheckDecommitment(r, challenge) {
+		return false, nil
+	}
+	z, _, err := prover.GetProofData(challenge)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(z), nil
 }
 
 // TODO: demonstrator for ZKP and ZKPOK
@@ -49,34 +104,71 @@ type SchnorrProver struct {
 	a                *big.Int
 	r                *big.Int
 	PedersenReceiver *commitments.PedersenReceiver // only needed for ZKP and ZKPOK, not for sigma
-	protocolType     types.ProtocolType
+	variant          SchnorrVariant
+	gTable           *groups.ExpTable // nil if not precomputed, see Precompute
+	window           int
+	mutex            sync.Mutex
 }
 
-func NewSchnorrProver(group *groups.SchnorrGroup, protocolType types.ProtocolType) *SchnorrProver {
-	var prover SchnorrProver
-	prover = SchnorrProver{
-		Group:        group,
-		protocolType: protocolType,
+func NewSchnorrProver(group *groups.SchnorrGroup, opts ...SchnorrOption) (*SchnorrProver,
+	error) {
+	variant := newSchnorrOptions(opts).variant
+	PedersenReceiver, err := variant.newPedersenReceiver(group)
+	if err != nil {
+		return nil, err
 	}
 
-	if protocolType != types.Sigma {
-		// TODO: currently Pedersen is using the same dlog as SchnorrProver, this
-		// is because SchnorrVerifier for ZKP/ZKPOK needs to know Pedersen's dlog
-		// to generate a challenge and create a commitment
-		prover.PedersenReceiver = commitments.NewPedersenReceiverFromExistingDLog(group)
-	}
-
-	return &prover
+	return &SchnorrProver{
+		Group:            group,
+		PedersenReceiver: PedersenReceiver,
+		variant:          variant,
+	}, nil
 }
 
 // Returns pedersenReceiver's h. Verifier needs h to prepare a commitment.
 func (prover *SchnorrProver) GetOpeningMsg() *big.Int {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
 	h := prover.PedersenReceiver.GetH()
 	return h
 }
 
+// SetVerifierPublicKey sets the public key of the verifier in DesignatedVerifier protocol.
+// The verifier commits to the challenge using its public key, which the prover needs to
+// check the decommitment.
+func (prover *SchnorrProver) SetVerifierPublicKey(publicKey *big.Int) {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	prover.PedersenReceiver = commitments.NewPedersenReceiverFromH(prover.Group, publicKey)
+	if prover.window > 0 {
+		prover.PedersenReceiver.Precompute(prover.window)
+	}
+}
+
+// Precompute builds the tables for exponentiation of g (and h of the Pedersen receiver
+// in ZKP, ZKPOK and DesignatedVerifier protocols) with windows of the given size, which cuts
+// the latency of each proof where the base a is g. Building the tables is costly, so it pays
+// off when the prover is used for many proofs - it is meant to be called right after
+// the prover is created.
+func (prover *SchnorrProver) Precompute(windowSize int) error {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	gTable, err := groups.NewExpTableWithWindow(prover.Group, prover.Group.G, windowSize)
+	if err != nil {
+		return err
+	}
+	if prover.PedersenReceiver != nil {
+		if err := prover.PedersenReceiver.Precompute(windowSize); err != nil {
+			return err
+		}
+	}
+	prover.gTable = gTable
+	prover.window = windowSize
+	return nil
+}
+
 // GetProofRandomData sets prover.secret and prover.a, and returns a^r % p where r is random.
-func (prover *SchnorrProver) GetProofRandomData(secret, a *big.Int) *big.Int {
+func (prover *SchnorrProver) GetProofRandomData(secret, a *big.Int) (*big.Int, error) {
 	// TODO: name GetProofRandomData is not ok, but I am not sure what would be the best way
 	// to fix it.
 	// It might be replaced with something that
@@ -90,30 +182,88 @@ func (prover *SchnorrProver) GetProofRandomData(secret, a *big.Int) *big.Int {
 	// The problem is the same for all proofs.
 
 	// x = a^r % p, where r is random
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
 	prover.a = a
 	prover.secret = secret
-	r := common.GetRandomInt(prover.Group.Q)
+	r, err := common.GetRandomInt(prover.Group.Q)
+	if err != nil {
+		return nil, err
+	}
 	prover.r = r
+	if prover.gTable != nil && a.Cmp(prover.Group.G) == 0 {
+		return prover.gTable.Exp(r), nil
+	}
 	x := prover.Group.Exp(a, r)
 
-	return x
+	return x, nil
+}
+
+// Reset discards the randomness of an unfinished proof.
+func (prover *SchnorrProver) Reset() {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	prover.a, prover.secret, prover.r = nil, nil, nil
+}
+
+// MarshalState encodes the state of the prover (see UnmarshalState). Note that the state
+// contains the secret and the randomness of the proof - it needs to be kept secret and
+// restored only once, because responding to two challenges for the same randomness
+// reveals the secret.
+func (prover *SchnorrProver) MarshalState() ([]byte, error) {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	var trapdoor, h, commitment *big.Int
+	if prover.PedersenReceiver != nil {
+		trapdoor = prover.PedersenReceiver.GetTrapdoor()
+		h = prover.PedersenReceiver.GetH()
+		commitment = prover.PedersenReceiver.GetCommitment()
+	}
+	return marshalState(prover.variant.ProtocolType(), prover.secret, prover.a, prover.r, trapdoor, h,
+		commitment)
+}
+
+// UnmarshalState restores the state encoded by MarshalState. The prover needs to be created
+// with the same group and variant as the one which saved the state.
+func (prover *SchnorrProver) UnmarshalState(data []byte) error {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	v, err := unmarshalState(data, prover.variant.ProtocolType(), 6)
+	if err != nil {
+		return err
+	}
+	prover.secret, prover.a, prover.r = v[0], v[1], v[2]
+	trapdoor, h, commitment := v[3], v[4], v[5]
+	if trapdoor != nil && prover.PedersenReceiver != nil {
+		prover.PedersenReceiver.SetTrapdoor(trapdoor)
+	} else if h != nil {
+		prover.PedersenReceiver = commitments.NewPedersenReceiverFromH(prover.Group, h)
+	}
+	if commitment != nil && prover.PedersenReceiver != nil {
+		prover.PedersenReceiver.SetCommitment(commitment)
+	}
+	return nil
 }
 
 // It receives challenge defined by a verifier, and returns z = r + challenge * w
 // and trapdoor in ZKPOK.
-func (prover *SchnorrProver) GetProofData(challenge *big.Int) (*big.Int, *big.Int) {
+// It returns an error if the proof random data has not been generated or has been already
+// used (see Reset).
+func (prover *SchnorrProver) GetProofData(challenge *big.Int) (*big.Int, *big.Int, error) {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	if err := checkProofRandomData(prover.r); err != nil {
+		return nil, nil, err
+	}
+
 	// z = r + challenge * w
 	z := new(big.Int)
 	z.Mul(challenge, prover.secret)
 	z.Add(z, prover.r)
 	z.Mod(z, prover.Group.Q)
+	prover.r = nil
 
-	if prover.protocolType != types.ZKPOK {
-		return z, nil
-	} else {
-		trapdoor := prover.PedersenReceiver.GetTrapdoor()
-		return z, trapdoor
-	}
+	return z, prover.variant.revealTrapdoor(prover.PedersenReceiver), nil
 }
 
 type SchnorrVerifier struct {
@@ -123,70 +273,182 @@ type SchnorrVerifier struct {
 	b                 *big.Int
 	challenge         *big.Int
 	pedersenCommitter *commitments.PedersenCommitter // not needed in sigma protocol, only in ZKP and ZKPOK
-	protocolType      types.ProtocolType
+	variant           SchnorrVariant
+	trapdoorVerified  bool     // only in ZKPOK
+	secretKey         *big.Int // only in DesignatedVerifier
+	mutex             sync.Mutex
 }
 
-func NewSchnorrVerifier(group *groups.SchnorrGroup, protocolType types.ProtocolType) *SchnorrVerifier {
-	verifier := SchnorrVerifier{
-		Group:        group,
-		protocolType: protocolType,
+func NewSchnorrVerifier(group *groups.SchnorrGroup, opts ...SchnorrOption) (*SchnorrVerifier,
+	error) {
+	variant := newSchnorrOptions(opts).variant
+	pedersenCommitter, secretKey, err := variant.newPedersenCommitter(group)
+	if err != nil {
+		return nil, err
 	}
-	if protocolType != types.Sigma {
-		verifier.pedersenCommitter = commitments.NewPedersenCommitter(group)
+	return &SchnorrVerifier{
+		Group:             group,
+		pedersenCommitter: pedersenCommitter,
+		variant:           variant,
+		secretKey:         secretKey,
+	}, nil
+}
+
+// SetSecretKey sets the (long-term) secret key of the verifier in DesignatedVerifier
+// protocol (by default a random key is generated for each verifier).
+func (verifier *SchnorrVerifier) SetSecretKey(secretKey *big.Int) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.secretKey = secretKey
+	verifier.pedersenCommitter.SetH(verifier.Group.Exp(verifier.Group.G, secretKey))
+}
+
+// GetPublicKey returns the public key of the verifier in DesignatedVerifier protocol.
+func (verifier *SchnorrVerifier) GetPublicKey() *big.Int {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	return verifier.Group.Exp(verifier.Group.G, verifier.secretKey)
+}
+
+// GetChallengeCommitment is used in DesignatedVerifier protocol - it generates the challenge
+// and returns the commitment to it which can be opened to any value by the verifier (because
+// it knows the secret key), but not by anybody else.
+func (verifier *SchnorrVerifier) GetChallengeCommitment() (*big.Int, error) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	challenge, err := verifier.generateChallenge()
+	if err != nil {
+		return nil, err
 	}
-	return &verifier
+	return verifier.pedersenCommitter.GetCommitMsg(challenge)
 }
 
 // GenerateChallenge is used in ZKP where challenge needs to be
 // chosen (and committed to) before sigma protocol starts.
-func (verifier *SchnorrVerifier) GenerateChallenge() *big.Int {
-	challenge := common.GetRandomInt(verifier.Group.Q)
+func (verifier *SchnorrVerifier) GenerateChallenge() (*big.Int, error) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	return verifier.generateChallenge()
+}
+
+func (verifier *SchnorrVerifier) generateChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(verifier.Group.Q)
+	if err != nil {
+		return nil, err
+	}
 	verifier.challenge = challenge
-	return challenge
+	return challenge, nil
 }
 
-func (verifier *SchnorrVerifier) GetOpeningMsgReply(h *big.Int) *big.Int {
+func (verifier *SchnorrVerifier) GetOpeningMsgReply(h *big.Int) (*big.Int, error) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
 	verifier.pedersenCommitter.SetH(h) // h = g^a where a is a trapdoor
-	challenge := verifier.GenerateChallenge()
-	commitment, _ := verifier.pedersenCommitter.GetCommitMsg(challenge)
-	return commitment
+	challenge, err := verifier.generateChallenge()
+	if err != nil {
+		return nil, err
+	}
+	return verifier.pedersenCommitter.GetCommitMsg(challenge)
 }
 
 // TODO: similar as described above for GetProofRandomData - this one is not setting
 // only proofRandomData, thus it might be split (a, b for example set in SchnorrVerifier constructor).
 func (verifier *SchnorrVerifier) SetProofRandomData(x, a, b *big.Int) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
 	verifier.x = x
 	verifier.a = a
 	verifier.b = b
 }
 
+// SetChallenge sets the challenge which was not generated by the verifier (for example
+// the one derived by Fiat-Shamir heuristic).
+func (verifier *SchnorrVerifier) SetChallenge(challenge *big.Int) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.challenge = challenge
+}
+
 // It returns a challenge and commitment to challenge (this latter only for ZKP and ZKPOK).
-func (verifier *SchnorrVerifier) GetChallenge() (*big.Int, *big.Int) {
-	if verifier.protocolType == types.Sigma {
-		challenge := verifier.GenerateChallenge()
-		return challenge, nil
-	} else {
-		challenge, r2 := verifier.pedersenCommitter.GetDecommitMsg()
-		return challenge, r2
-	}
+func (verifier *SchnorrVerifier) GetChallenge() (*big.Int, *big.Int, error) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	return verifier.variant.getChallenge(verifier.generateChallenge, verifier.pedersenCommitter)
+}
+
+// VerifyTrapdoor checks the trapdoor of the Pedersen commitment which the prover reveals
+// in ZKPOK after the proof data is sent. In ZKPOK Verify fails unless the trapdoor
+// has been verified.
+func (verifier *SchnorrVerifier) VerifyTrapdoor(trapdoor *big.Int) bool {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.trapdoorVerified = verifier.variant.verifyTrapdoor(verifier.pedersenCommitter,
+		trapdoor)
+	return verifier.trapdoorVerified
 }
 
 // It receives y = r + w * challenge. It returns true if a^y = a^r * (a^secret) ^ challenge, otherwise false.
-func (verifier *SchnorrVerifier) Verify(z *big.Int, trapdoor *big.Int) bool {
-	if verifier.protocolType == types.ZKPOK {
-		valid := verifier.pedersenCommitter.VerifyTrapdoor(trapdoor)
-		if !valid {
-			return false
-		}
+func (verifier *SchnorrVerifier) Verify(z *big.Int) bool {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	if !isSet(verifier.x, verifier.a, verifier.b, verifier.challenge, z) {
+		return false
 	}
 
-	left := verifier.Group.Exp(verifier.a, z)
-	r1 := verifier.Group.Exp(verifier.b, verifier.challenge)
-	right := verifier.Group.Mul(r1, verifier.x)
+	proved := verifyDLogEquation(verifier.Group, verifier.a, verifier.b, verifier.x,
+		verifier.challenge, z)
+	return verifier.variant.verified(proved, verifier.trapdoorVerified)
+}
 
-	if left.Cmp(right) == 0 {
-		return true
-	} else {
-		return false
+// verifyDLogEquation checks a^z = b^challenge * x, which is computed as
+// a^z * b^(-challenge) = x with a single multi-exponentiation.
+func verifyDLogEquation(group *groups.SchnorrGroup, a, b, x, challenge, z *big.Int) bool {
+	left := common.MultiExp([]*big.Int{a, b}, []*big.Int{z, new(big.Int).Neg(challenge)},
+		group.P)
+	return left != nil && left.Cmp(x) == 0
+}
+
+// Reset discards the proof random data, challenge and verified trapdoor of the last proof.
+// The secret key of the verifier in DesignatedVerifier protocol is kept.
+func (verifier *SchnorrVerifier) Reset() {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.x, verifier.a, verifier.b, verifier.challenge = nil, nil, nil, nil
+	verifier.trapdoorVerified = false
+}
+
+// MarshalState encodes the state of the verifier (see UnmarshalState). Note that in
+// DesignatedVerifier protocol the state contains the secret key of the verifier.
+func (verifier *SchnorrVerifier) MarshalState() ([]byte, error) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	var h, committedValue, r *big.Int
+	if verifier.pedersenCommitter != nil {
+		h = verifier.pedersenCommitter.GetH()
+		committedValue, r = verifier.pedersenCommitter.GetDecommitMsg()
+	}
+	return marshalState(verifier.variant.ProtocolType(), verifier.x, verifier.a, verifier.b,
+		verifier.challenge, h, committedValue, r, verifier.secretKey,
+		boolValue(verifier.trapdoorVerified))
+}
+
+// UnmarshalState restores the state encoded by MarshalState. The verifier needs to be created
+// with the same group and variant as the one which saved the state.
+func (verifier *SchnorrVerifier) UnmarshalState(data []byte) error {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	v, err := unmarshalState(data, verifier.variant.ProtocolType(), 9)
+	if err != nil {
+		return err
+	}
+	verifier.x, verifier.a, verifier.b, verifier.challenge = v[0], v[1], v[2], v[3]
+	if verifier.pedersenCommitter != nil {
+		if v[4] != nil {
+			verifier.pedersenCommitter.SetH(v[4])
+		}
+		verifier.pedersenCommitter.SetDecommitMsg(v[5], v[6])
 	}
+	verifier.secretKey = v[7]
+	verifier.trapdoorVerified = v[8] != nil
+	return nil
 }
@@ -0,0 +1,139 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlogproofs
+
+import (
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+)
+
+// batchWeightBitLength is the bit length of random weights used in batch verification - the
+// probability that a batch with an invalid proof is accepted is at most 2^-batchWeightBitLength.
+const batchWeightBitLength = 128
+
+// SchnorrBatchVerifier verifies many Schnorr proofs (for example the ones received in
+// a short time period by a service) at once. Instead of checking a_i^z_i = x_i * b_i^c_i
+// for each proof, it checks (small exponents test by Bellare, Garay, Rabin):
+//
+//	prod (a_i^(w_i * z_i) * x_i^(-w_i) * b_i^(-w_i * c_i)) = 1
+//
+// where w_i are random weights. The bases which appear in more proofs (like a common
+// generator or the public key of a client proving many times) are merged and the product
+// is computed with a single multi-exponentiation, thus the cost grows much slower with
+// the number of proofs than when each proof is verified separately.
+//
+// Verify only tells whether all proofs are valid - when it fails, the proofs need to be
+// verified separately (for example with SchnorrVerifier) to find the invalid ones.
+type SchnorrBatchVerifier struct {
+	Group  *groups.SchnorrGroup
+	proofs []*schnorrBatchProof
+}
+
+// schnorrBatchProof holds the statement (a, b) and the proof (x, challenge, z) that
+// the prover knows log_a(b).
+type schnorrBatchProof struct {
+	a, b, x   *big.Int
+	challenge *big.Int
+	z         *big.Int
+}
+
+func NewSchnorrBatchVerifier(group *groups.SchnorrGroup) *SchnorrBatchVerifier {
+	return &SchnorrBatchVerifier{
+		Group: group,
+	}
+}
+
+// Add adds the proof that the prover knows log_a(b): x is the proof random data, challenge
+// is the challenge (generated by the verifier or derived by Fiat-Shamir heuristic) and z is
+// the proof data.
+func (verifier *SchnorrBatchVerifier) Add(a, b, x, challenge, z *big.Int) {
+	verifier.proofs = append(verifier.proofs, &schnorrBatchProof{
+		a:         a,
+		b:         b,
+		x:         x,
+		challenge: challenge,
+		z:         z,
+	})
+}
+
+// Len returns the number of added proofs.
+func (verifier *SchnorrBatchVerifier) Len() int {
+	return len(verifier.proofs)
+}
+
+// Verify returns true if all added proofs are valid (and when no proof has been added).
+func (verifier *SchnorrBatchVerifier) Verify() (bool, error) {
+	group := verifier.Group
+	m := newBatchExponents(group.Q)
+	for _, p := range verifier.proofs {
+		if p.a == nil || p.b == nil || p.x == nil || p.challenge == nil || p.z == nil {
+			return false, nil
+		}
+		w, err := getBatchWeight()
+		if err != nil {
+			return false, err
+		}
+		m.add(p.a, new(big.Int).Mul(w, p.z))
+		m.add(p.x, new(big.Int).Neg(w))
+		m.add(p.b, new(big.Int).Neg(w.Mul(w, p.challenge)))
+	}
+
+	// the weights do not detect the elements which are not in the subgroup of order Q
+	// (for example -x instead of x), each distinct element is checked once
+	for _, base := range m.bases {
+		if base.Sign() <= 0 || base.Cmp(group.P) >= 0 || !group.IsElementInGroup(base) {
+			return false, nil
+		}
+	}
+	return GetBatchBackend().MultiExp(group, m.bases, m.exponents).Cmp(big.NewInt(1)) == 0, nil
+}
+
+// batchExponents accumulates exponents (mod order) of the bases in the batch, each distinct
+// base is stored only once.
+type batchExponents struct {
+	order     *big.Int
+	index     map[string]int
+	bases     []*big.Int
+	exponents []*big.Int
+}
+
+func newBatchExponents(order *big.Int) *batchExponents {
+	return &batchExponents{
+		order: order,
+		index: make(map[string]int),
+	}
+}
+
+func (m *batchExponents) add(base, exponent *big.Int) {
+	key := base.String()
+	i, ok := m.index[key]
+	if !ok {
+		i = len(m.bases)
+		m.index[key] = i
+		m.bases = append(m.bases, base)
+		m.exponents = append(m.exponents, new(big.Int))
+	}
+	m.exponents[i].Add(m.exponents[i], exponent)
+	m.exponents[i].Mod(m.exponents[i], m.order)
+}
+
+// getBatchWeight returns a random weight for the small exponents test.
+func getBatchWeight() (*big.Int, error) {
+	return common.GetRandomInt(new(big.Int).Lsh(big.NewInt(1), batchWeightBitLength))
+}
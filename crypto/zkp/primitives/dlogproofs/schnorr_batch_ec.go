@@ -0,0 +1,108 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlogproofs
+
+import (
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// SchnorrECBatchVerifier verifies many Schnorr proofs in EC group at once, see
+// SchnorrBatchVerifier. The points which appear in more proofs are merged, thus the product
+// requires one scalar multiplication per distinct point (and not two per proof).
+type SchnorrECBatchVerifier struct {
+	DLog   *dlog.ECDLog
+	proofs []*schnorrECBatchProof
+}
+
+// schnorrECBatchProof holds the statement (a, b) and the proof (x, challenge, z) that
+// the prover knows log_a(b).
+type schnorrECBatchProof struct {
+	a, b, x   *types.ECGroupElement
+	challenge *big.Int
+	z         *big.Int
+}
+
+func NewSchnorrECBatchVerifier(curve dlog.Curve) *SchnorrECBatchVerifier {
+	return &SchnorrECBatchVerifier{
+		DLog: dlog.NewECDLog(curve),
+	}
+}
+
+// Add adds the proof that the prover knows log_a(b): x is the proof random data, challenge
+// is the challenge (generated by the verifier or derived by Fiat-Shamir heuristic) and z is
+// the proof data.
+func (verifier *SchnorrECBatchVerifier) Add(a, b, x *types.ECGroupElement, challenge, z *big.Int) {
+	verifier.proofs = append(verifier.proofs, &schnorrECBatchProof{
+		a:         a,
+		b:         b,
+		x:         x,
+		challenge: challenge,
+		z:         z,
+	})
+}
+
+// Len returns the number of added proofs.
+func (verifier *SchnorrECBatchVerifier) Len() int {
+	return len(verifier.proofs)
+}
+
+// Verify returns true if all added proofs are valid (and when no proof has been added).
+func (verifier *SchnorrECBatchVerifier) Verify() (bool, error) {
+	dLog := verifier.DLog
+	m := newBatchExponents(dLog.OrderOfSubgroup)
+	points := make(map[string]*types.ECGroupElement)
+	add := func(p *types.ECGroupElement, exponent *big.Int) bool {
+		// operations with the points which are not on the curve might panic
+		if p == nil || p.X == nil || p.Y == nil || !dLog.Curve.IsOnCurve(p.X, p.Y) {
+			return false
+		}
+		// a point is represented by its x coordinate and the parity of y
+		key := new(big.Int).Lsh(p.X, 1)
+		key.SetBit(key, 0, p.Y.Bit(0))
+		m.add(key, exponent)
+		points[key.String()] = p
+		return true
+	}
+	for _, p := range verifier.proofs {
+		if p.challenge == nil || p.z == nil {
+			return false, nil
+		}
+		w, err := getBatchWeight()
+		if err != nil {
+			return false, err
+		}
+		if !add(p.a, new(big.Int).Mul(w, p.z)) || !add(p.x, new(big.Int).Neg(w)) ||
+			!add(p.b, new(big.Int).Neg(w.Mul(w, p.challenge))) {
+			return false, nil
+		}
+	}
+	if len(m.bases) == 0 {
+		return true, nil
+	}
+
+	bases := make([]*types.ECGroupElement, len(m.bases))
+	for i, key := range m.bases {
+		bases[i] = points[key.String()]
+	}
+	// NIST curves have cofactor 1, thus each point on the curve is in the group and
+	// the result needs to be the point at infinity
+	result := GetBatchBackend().MultiExpEC(dLog, bases, m.exponents)
+	return result.X.Sign() == 0 && result.Y.Sign() == 0, nil
+}
@@ -18,29 +18,42 @@
 package dlogproofs
 
 import (
+	"fmt"
 	"github.com/xlab-si/emmy/crypto/commitments"
 	"github.com/xlab-si/emmy/crypto/common"
 	"github.com/xlab-si/emmy/crypto/dlog"
 	"github.com/xlab-si/emmy/types"
 	"math/big"
+	"sync"
 )
 
 // ProveECDLogKnowledge demonstrates how prover can prove the knowledge of log_g1(t1) - that
 // means g1^secret = t1 in EC group.
 func ProveECDLogKnowledge(secret *big.Int, g1, t1 *types.ECGroupElement, curve dlog.Curve) (bool, error) {
-	prover, err := NewSchnorrECProver(curve, types.Sigma)
+	prover, err := NewSchnorrECProver(WithCurve(curve))
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewSchnorrECVerifier(WithCurve(curve))
 	if err != nil {
 		return false, err
 	}
-	verifier := NewSchnorrECVerifier(curve, types.Sigma)
 
-	x := prover.GetProofRandomData(secret, g1)
+	x, err := prover.GetProofRandomData(secret, g1)
+	if err != nil {
+		return false, err
+	}
 	verifier.SetProofRandomData(x, g1, t1)
 
-	challenge, _ := verifier.GetChallenge()
-	z, _ := prover.GetProofData(challenge)
-	verified := verifier.Verify(z, nil)
-	return verified, nil
+	challenge, _, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	z, _, err := prover.GetProofData(challenge)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(z), nil
 }
 
 // TODO: demonstrator for ZKP and ZKPOK
@@ -68,55 +81,145 @@ type SchnorrECProver struct {
 	secret           *big.Int
 	r                *big.Int                        // ProofRandomData
 	PedersenReceiver *commitments.PedersenECReceiver // only needed for ZKP and ZKPOK, not for sigma
-	protocolType     types.ProtocolType
+	variant          SchnorrECVariant
+	precomputed      bool // see Precompute
+	mutex            sync.Mutex
 }
 
-func NewSchnorrECProver(curveType dlog.Curve, protocolType types.ProtocolType) (*SchnorrECProver, error) {
-	dLog := dlog.NewECDLog(curveType)
-	prover := SchnorrECProver{
-		DLog:         dLog,
-		protocolType: protocolType,
+// NewSchnorrECProver returns an error if the variant is not supported in EC (see
+// WithProtocolVariant).
+func NewSchnorrECProver(opts ...SchnorrOption) (*SchnorrECProver, error) {
+	options := newSchnorrOptions(opts)
+	variant, err := options.ecVariant()
+	if err != nil {
+		return nil, err
 	}
-
-	if protocolType != types.Sigma {
-		prover.PedersenReceiver = commitments.NewPedersenECReceiver(curveType)
+	PedersenReceiver, err := variant.newPedersenECReceiver(options.curve)
+	if err != nil {
+		return nil, err
 	}
 
-	return &prover, nil
+	return &SchnorrECProver{
+		DLog:             dlog.NewECDLog(options.curve),
+		PedersenReceiver: PedersenReceiver,
+		variant:          variant,
+	}, nil
 }
 
 // Returns pedersenReceiver's h. Verifier needs h to prepare a commitment.
 func (prover *SchnorrECProver) GetOpeningMsg() *types.ECGroupElement {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
 	return prover.PedersenReceiver.GetH()
 }
 
 // It contains also value b = a^secret.
 func (prover *SchnorrECProver) GetProofRandomData(secret *big.Int,
-	a *types.ECGroupElement) *types.ECGroupElement {
-	r := common.GetRandomInt(prover.DLog.GetOrderOfSubgroup())
+	a *types.ECGroupElement) (*types.ECGroupElement, error) {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	r, err := common.GetRandomInt(prover.DLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, err
+	}
 	prover.r = r
 	prover.a = a
 	prover.secret = secret
-	x1, x2 := prover.DLog.Exponentiate(a.X, a.Y, r)
 
-	return types.NewECGroupElement(x1, x2)
+	if prover.precomputed && a.Equals(prover.g()) {
+		return prover.DLog.ExpBaseG(r), nil
+	}
+	return prover.DLog.Exp(a, r), nil
+}
+
+// Precompute switches the exponentiations of the base point g to the fixed-base tables
+// of crypto/elliptic (several times faster than exponentiation of an arbitrary point) and
+// builds the tables, which are otherwise built lazily by the first proof. crypto/elliptic
+// uses its own window size, thus windowSize (see SchnorrProver.Precompute) is only checked
+// to be positive. Other points (like h of the Pedersen receiver) cannot be exponentiated
+// faster through crypto/elliptic - tables of affine points would be slower than
+// the exponentiation itself, as each point addition converts the coordinates.
+func (prover *SchnorrECProver) Precompute(windowSize int) error {
+	if windowSize < 1 {
+		return fmt.Errorf("window size needs to be positive")
+	}
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	prover.DLog.ExpBaseG(big.NewInt(1))
+	prover.precomputed = true
+	return nil
+}
+
+// g returns the base point of the curve.
+func (prover *SchnorrECProver) g() *types.ECGroupElement {
+	params := prover.DLog.Curve.Params()
+	return types.NewECGroupElement(params.Gx, params.Gy)
+}
+
+// Reset discards the randomness of an unfinished proof.
+func (prover *SchnorrECProver) Reset() {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	prover.a, prover.secret, prover.r = nil, nil, nil
+}
+
+// MarshalState encodes the state of the prover (see UnmarshalState). Note that the state
+// contains the secret and the randomness of the proof - it needs to be kept secret and
+// restored only once, because responding to two challenges for the same randomness
+// reveals the secret.
+func (prover *SchnorrECProver) MarshalState() ([]byte, error) {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	var trapdoor *big.Int
+	var commitment *types.ECGroupElement
+	if prover.PedersenReceiver != nil {
+		trapdoor = prover.PedersenReceiver.GetTrapdoor()
+		commitment = prover.PedersenReceiver.GetCommitment()
+	}
+	aX, aY := pointValues(prover.a)
+	cX, cY := pointValues(commitment)
+	return marshalState(prover.variant.ProtocolType(), prover.secret, aX, aY, prover.r, trapdoor, cX, cY)
+}
+
+// UnmarshalState restores the state encoded by MarshalState. The prover needs to be created
+// with the same curve and variant as the one which saved the state.
+func (prover *SchnorrECProver) UnmarshalState(data []byte) error {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	v, err := unmarshalState(data, prover.variant.ProtocolType(), 7)
+	if err != nil {
+		return err
+	}
+	prover.secret, prover.a, prover.r = v[0], valuesPoint(v[1], v[2]), v[3]
+	if prover.PedersenReceiver != nil {
+		if v[4] != nil {
+			prover.PedersenReceiver.SetTrapdoor(v[4])
+		}
+		if commitment := valuesPoint(v[5], v[6]); commitment != nil {
+			prover.PedersenReceiver.SetCommitment(commitment)
+		}
+	}
+	return nil
 }
 
 // It receives challenge defined by a verifier, and returns z = r + challenge * w
-// and trapdoor in ZKPOK.
-func (prover *SchnorrECProver) GetProofData(challenge *big.Int) (*big.Int, *big.Int) {
+// and trapdoor in ZKPOK. It returns an error if the proof random data has not been
+// generated or has been already used (see Reset).
+func (prover *SchnorrECProver) GetProofData(challenge *big.Int) (*big.Int, *big.Int, error) {
+	prover.mutex.Lock()
+	defer prover.mutex.Unlock()
+	if err := checkProofRandomData(prover.r); err != nil {
+		return nil, nil, err
+	}
+
 	// z = r + challenge * secret
 	z := new(big.Int)
 	z.Mul(challenge, prover.secret)
 	z.Add(z, prover.r)
 	z.Mod(z, prover.DLog.GetOrderOfSubgroup())
+	prover.r = nil
 
-	if prover.protocolType != types.ZKPOK {
-		return z, nil
-	} else {
-		trapdoor := prover.PedersenReceiver.GetTrapdoor()
-		return z, trapdoor
-	}
+	return z, prover.variant.revealTrapdoor(prover.PedersenReceiver), nil
 }
 
 type SchnorrECVerifier struct {
@@ -126,71 +229,159 @@ type SchnorrECVerifier struct {
 	b                 *types.ECGroupElement
 	challenge         *big.Int
 	pedersenCommitter *commitments.PedersenECCommitter // not needed in sigma protocol, only in ZKP and ZKPOK
-	protocolType      types.ProtocolType
+	variant           SchnorrECVariant
+	trapdoorVerified  bool // only in ZKPOK
+	mutex             sync.Mutex
 }
 
-func NewSchnorrECVerifier(curveType dlog.Curve, protocolType types.ProtocolType) *SchnorrECVerifier {
-	dLog := dlog.NewECDLog(curveType)
-	verifier := SchnorrECVerifier{
-		DLog:         dLog,
-		protocolType: protocolType,
-	}
-
-	if protocolType != types.Sigma {
-		verifier.pedersenCommitter = commitments.NewPedersenECCommitter(curveType)
+// NewSchnorrECVerifier returns an error if the variant is not supported in EC (see
+// WithProtocolVariant).
+func NewSchnorrECVerifier(opts ...SchnorrOption) (*SchnorrECVerifier, error) {
+	options := newSchnorrOptions(opts)
+	variant, err := options.ecVariant()
+	if err != nil {
+		return nil, err
 	}
-
-	return &verifier
+	return &SchnorrECVerifier{
+		DLog:              dlog.NewECDLog(options.curve),
+		pedersenCommitter: variant.newPedersenECCommitter(options.curve),
+		variant:           variant,
+	}, nil
 }
 
 // GenerateChallenge is used in ZKP where challenge needs to be
 // chosen (and committed to) before sigma protocol starts.
-func (verifier *SchnorrECVerifier) GenerateChallenge() *big.Int {
-	challenge := common.GetRandomInt(verifier.DLog.GetOrderOfSubgroup())
+func (verifier *SchnorrECVerifier) GenerateChallenge() (*big.Int, error) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	return verifier.generateChallenge()
+}
+
+func (verifier *SchnorrECVerifier) generateChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(verifier.DLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, err
+	}
 	verifier.challenge = challenge
-	return challenge
+	return challenge, nil
 }
 
-func (verifier *SchnorrECVerifier) GetOpeningMsgReply(h *types.ECGroupElement) *types.ECGroupElement {
+func (verifier *SchnorrECVerifier) GetOpeningMsgReply(h *types.ECGroupElement) (*types.ECGroupElement, error) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
 	verifier.pedersenCommitter.SetH(h) // h = g^a where a is a trapdoor
-	challenge := verifier.GenerateChallenge()
+	challenge, err := verifier.generateChallenge()
+	if err != nil {
+		return nil, err
+	}
 	commitment, _ := verifier.pedersenCommitter.GetCommitMsg(challenge)
-	return commitment
+	return commitment, nil
 }
 
 // TODO: t transferred at some other stage?
 func (verifier *SchnorrECVerifier) SetProofRandomData(x, a, b *types.ECGroupElement) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
 	verifier.x = x
 	verifier.a = a
 	verifier.b = b
 }
 
+// SetChallenge sets the challenge which was not generated by the verifier (for example
+// the one derived by Fiat-Shamir heuristic).
+func (verifier *SchnorrECVerifier) SetChallenge(challenge *big.Int) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.challenge = challenge
+}
+
 // It returns a challenge and commitment to challenge (this latter only for ZKP and ZKPOK).
-func (verifier *SchnorrECVerifier) GetChallenge() (*big.Int, *big.Int) {
-	if verifier.protocolType == types.Sigma {
-		challenge := verifier.GenerateChallenge()
-		return challenge, nil
-	} else {
-		challenge, r2 := verifier.pedersenCommitter.GetDecommitMsg()
-		return challenge, r2
-	}
+func (verifier *SchnorrECVerifier) GetChallenge() (*big.Int, *big.Int, error) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	return verifier.variant.getChallenge(verifier.generateChallenge, verifier.pedersenCommitter)
 }
 
-func (verifier *SchnorrECVerifier) Verify(z *big.Int, trapdoor *big.Int) bool {
-	if verifier.protocolType == types.ZKPOK {
-		valid := verifier.pedersenCommitter.VerifyTrapdoor(trapdoor)
-		if !valid {
-			return false
-		}
+// VerifyTrapdoor checks the trapdoor of the Pedersen commitment which the prover reveals
+// in ZKPOK after the proof data is sent. In ZKPOK Verify fails unless the trapdoor
+// has been verified.
+func (verifier *SchnorrECVerifier) VerifyTrapdoor(trapdoor *big.Int) bool {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.trapdoorVerified = verifier.variant.verifyTrapdoor(verifier.pedersenCommitter,
+		trapdoor)
+	return verifier.trapdoorVerified
+}
+
+func (verifier *SchnorrECVerifier) Verify(z *big.Int) bool {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	if !verifier.DLog.IsInSubgroup(verifier.x) || !verifier.DLog.IsInSubgroup(verifier.b) ||
+		!verifier.DLog.IsInSubgroup(verifier.a) || verifier.a.IsInfinity() ||
+		!isSet(verifier.challenge, z) {
+		return false
 	}
-	left1, left2 := verifier.DLog.Exponentiate(verifier.a.X, verifier.a.Y, z)
 
-	r1, r2 := verifier.DLog.Exponentiate(verifier.b.X, verifier.b.Y, verifier.challenge)
-	right1, right2 := verifier.DLog.Multiply(r1, r2, verifier.x.X, verifier.x.Y)
+	proved := verifyECDLogEquation(verifier.DLog, verifier.a, verifier.b, verifier.x,
+		verifier.challenge, z)
+	return verifier.variant.verified(proved, verifier.trapdoorVerified)
+}
 
-	if left1.Cmp(right1) == 0 && left2.Cmp(right2) == 0 {
-		return true
-	} else {
-		return false
+// verifyECDLogEquation checks a^z = b^challenge * x, which is computed as
+// a^z * b^(-challenge) = x with a single multi-exponentiation.
+func verifyECDLogEquation(dLog *dlog.ECDLog, a, b, x *types.ECGroupElement,
+	challenge, z *big.Int) bool {
+	left := common.MultiExpEC(dLog.Curve, []*types.ECGroupElement{a, b},
+		[]*big.Int{z, new(big.Int).Neg(challenge)})
+	return left.Equals(x)
+}
+
+// Reset discards the proof random data, challenge and verified trapdoor of the last proof.
+func (verifier *SchnorrECVerifier) Reset() {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.x, verifier.a, verifier.b, verifier.challenge = nil, nil, nil, nil
+	verifier.trapdoorVerified = false
+}
+
+// MarshalState encodes the state of the verifier (see UnmarshalState).
+func (verifier *SchnorrECVerifier) MarshalState() ([]byte, error) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	var h *types.ECGroupElement
+	var committedValue, r *big.Int
+	if verifier.pedersenCommitter != nil {
+		h = verifier.pedersenCommitter.GetH()
+		committedValue, r = verifier.pedersenCommitter.GetDecommitMsg()
+	}
+	xX, xY := pointValues(verifier.x)
+	aX, aY := pointValues(verifier.a)
+	bX, bY := pointValues(verifier.b)
+	hX, hY := pointValues(h)
+	return marshalState(verifier.variant.ProtocolType(), xX, xY, aX, aY, bX, bY, verifier.challenge,
+		hX, hY, committedValue, r, boolValue(verifier.trapdoorVerified))
+}
+
+// UnmarshalState restores the state encoded by MarshalState. The verifier needs to be created
+// with the same curve and variant as the one which saved the state. The points are
+// checked in Verify.
+func (verifier *SchnorrECVerifier) UnmarshalState(data []byte) error {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	v, err := unmarshalState(data, verifier.variant.ProtocolType(), 12)
+	if err != nil {
+		return err
+	}
+	verifier.x = valuesPoint(v[0], v[1])
+	verifier.a = valuesPoint(v[2], v[3])
+	verifier.b = valuesPoint(v[4], v[5])
+	verifier.challenge = v[6]
+	if verifier.pedersenCommitter != nil {
+		if h := valuesPoint(v[7], v[8]); h != nil {
+			verifier.pedersenCommitter.SetH(h)
+		}
+		verifier.pedersenCommitter.SetDecommitMsg(v[9], v[10])
 	}
+	verifier.trapdoorVerified = v[11] != nil
+	return nil
 }
@@ -0,0 +1,369 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlogproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+	"sync"
+)
+
+// Threshold Schnorr proof is a proof of knowledge of log_g(y) where the secret x (y = g^x)
+// is shared among n participants with Shamir's secret sharing, such that any threshold
+// of them can jointly produce the proof, while fewer learn nothing about x. The proof
+// is an ordinary Schnorr proof - the verifier does not need to know that the secret is shared.
+//
+// The participants of the set S (|S| >= threshold) each choose r_i and send x_i = g^r_i to
+// the coordinator, which sends x = prod x_i to the verifier. Given the challenge c, each
+// participant responds with z_i = r_i + c * lambda_i * x_i where lambda_i is its Lagrange
+// coefficient for the set S, and the coordinator sends z = sum z_i to the verifier.
+// The coordinator checks each z_i against the public verification key g^x_i of the
+// participant, which identifies the participants providing invalid responses.
+
+// ProveThresholdDLogKnowledge demonstrates how the holders of the shares prove the knowledge
+// of log_g(publicKey.Y).
+func ProveThresholdDLogKnowledge(shares []*SchnorrKeyShare, publicKey *ThresholdSchnorrPublicKey,
+	group *groups.SchnorrGroup) (bool, error) {
+	signers := make([]int, len(shares))
+	participants := make([]*ThresholdSchnorrParticipant, len(shares))
+	for i, share := range shares {
+		signers[i] = share.Index
+		participants[i] = NewThresholdSchnorrParticipant(group, share)
+	}
+	coordinator, err := NewThresholdSchnorrCoordinator(group, publicKey, signers)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewSchnorrVerifier(group)
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range participants {
+		x, err := p.GetProofRandomData(signers)
+		if err != nil {
+			return false, err
+		}
+		if err := coordinator.SetProofRandomData(p.Index(), x); err != nil {
+			return false, err
+		}
+	}
+	x, err := coordinator.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	verifier.SetProofRandomData(x, group.G, publicKey.Y)
+
+	challenge, _, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	coordinator.SetChallenge(challenge)
+	for _, p := range participants {
+		z, err := p.GetProofData(challenge)
+		if err != nil {
+			return false, err
+		}
+		if err := coordinator.SetProofData(p.Index(), z); err != nil {
+			return false, err
+		}
+	}
+	z, err := coordinator.GetProofData()
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(z), nil
+}
+
+// SchnorrKeyShare is the share of the secret of one participant in threshold Schnorr proof.
+type SchnorrKeyShare struct {
+	Index int      // the (positive) point at which the sharing polynomial is evaluated
+	Share *big.Int // the value of the sharing polynomial at Index
+}
+
+// ThresholdSchnorrPublicKey is the public key y = g^x of the shared secret x together
+// with the verification keys g^x_i of the shares.
+type ThresholdSchnorrPublicKey struct {
+	Y                *big.Int
+	Threshold        int
+	VerificationKeys map[int]*big.Int // by the index of the share
+}
+
+// SplitSchnorrSecret splits secret into n shares, any threshold of which can jointly prove
+// the knowledge of the secret. The dealer needs to be trusted to forget the secret and
+// to distribute each share only to its participant.
+func SplitSchnorrSecret(group *groups.SchnorrGroup, secret *big.Int, threshold,
+	n int) ([]*SchnorrKeyShare, *ThresholdSchnorrPublicKey, error) {
+	if threshold < 1 || threshold > n {
+		return nil, nil, fmt.Errorf("threshold needs to be between 1 and %d", n)
+	}
+	if big.NewInt(int64(n)).Cmp(group.Q) >= 0 {
+		return nil, nil, fmt.Errorf("too many shares for the group")
+	}
+
+	polynomial, err := common.NewRandomPolynomial(threshold-1, group.Q)
+	if err != nil {
+		return nil, nil, err
+	}
+	polynomial.SetCoefficient(0, new(big.Int).Mod(secret, group.Q))
+
+	shares := make([]*SchnorrKeyShare, n)
+	publicKey := &ThresholdSchnorrPublicKey{
+		Y:                group.Exp(group.G, secret),
+		Threshold:        threshold,
+		VerificationKeys: make(map[int]*big.Int, n),
+	}
+	for i := 1; i <= n; i++ {
+		share := polynomial.GetValue(big.NewInt(int64(i)))
+		shares[i-1] = &SchnorrKeyShare{
+			Index: i,
+			Share: share,
+		}
+		publicKey.VerificationKeys[i] = group.Exp(group.G, share)
+	}
+	return shares, publicKey, nil
+}
+
+// ThresholdSchnorrLagrangeCoefficient returns the coefficient lambda_i = prod j / (j - i)
+// (j from signers, j != i) of the participant with the given index, such that the secret
+// is sum lambda_i * x_i over the shares x_i of the signers.
+func ThresholdSchnorrLagrangeCoefficient(group *groups.SchnorrGroup, index int,
+	signers []int) (*big.Int, error) {
+	if err := checkSigners(signers); err != nil {
+		return nil, err
+	}
+	numerator := big.NewInt(1)
+	denominator := big.NewInt(1)
+	found := false
+	for _, j := range signers {
+		if j == index {
+			found = true
+			continue
+		}
+		numerator.Mul(numerator, big.NewInt(int64(j)))
+		numerator.Mod(numerator, group.Q)
+		denominator.Mul(denominator, big.NewInt(int64(j-index)))
+		denominator.Mod(denominator, group.Q)
+	}
+	if !found {
+		return nil, fmt.Errorf("participant %d is not among the signers", index)
+	}
+	inv := new(big.Int).ModInverse(denominator, group.Q)
+	if inv == nil {
+		return nil, fmt.Errorf("indices of the signers are not valid for the group")
+	}
+	return numerator.Mul(numerator, inv).Mod(numerator, group.Q), nil
+}
+
+// checkSigners checks that the indices of the signers are positive and distinct.
+func checkSigners(signers []int) error {
+	seen := make(map[int]bool, len(signers))
+	for _, j := range signers {
+		if j < 1 {
+			return fmt.Errorf("index of the participant needs to be positive, got %d", j)
+		}
+		if seen[j] {
+			return fmt.Errorf("participant %d is listed twice", j)
+		}
+		seen[j] = true
+	}
+	return nil
+}
+
+// ThresholdSchnorrParticipant holds one share of the secret and computes its part of
+// the threshold Schnorr proof.
+type ThresholdSchnorrParticipant struct {
+	Group  *groups.SchnorrGroup
+	share  *SchnorrKeyShare
+	r      *big.Int
+	lambda *big.Int
+	mutex  sync.Mutex
+}
+
+func NewThresholdSchnorrParticipant(group *groups.SchnorrGroup,
+	share *SchnorrKeyShare) *ThresholdSchnorrParticipant {
+	return &ThresholdSchnorrParticipant{
+		Group: group,
+		share: share,
+	}
+}
+
+// Index returns the index of the participant's share.
+func (p *ThresholdSchnorrParticipant) Index() int {
+	return p.share.Index
+}
+
+// GetProofRandomData starts the proof with the given signers (which need to include
+// the participant) and returns g^r_i where r_i is random.
+func (p *ThresholdSchnorrParticipant) GetProofRandomData(signers []int) (*big.Int, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	lambda, err := ThresholdSchnorrLagrangeCoefficient(p.Group, p.share.Index, signers)
+	if err != nil {
+		return nil, err
+	}
+	p.lambda = lambda
+	p.r, err = common.GetRandomInt(p.Group.Q)
+	if err != nil {
+		return nil, err
+	}
+	return p.Group.Exp(p.Group.G, p.r), nil
+}
+
+// GetProofData returns z_i = r_i + challenge * lambda_i * x_i. The proof random data
+// can be used only for one challenge.
+func (p *ThresholdSchnorrParticipant) GetProofData(challenge *big.Int) (*big.Int, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.r == nil {
+		return nil, errNoProofRandomData
+	}
+	z := new(big.Int).Mul(challenge, p.lambda)
+	z.Mul(z, p.share.Share)
+	z.Add(z, p.r)
+	z.Mod(z, p.Group.Q)
+	p.r, p.lambda = nil, nil
+	return z, nil
+}
+
+// Reset discards the randomness of an unfinished proof.
+func (p *ThresholdSchnorrParticipant) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.r, p.lambda = nil, nil
+}
+
+// ThresholdSchnorrCoordinator combines the messages of the signers into the messages
+// of the Schnorr proof and checks the messages of each signer.
+type ThresholdSchnorrCoordinator struct {
+	Group     *groups.SchnorrGroup
+	PublicKey *ThresholdSchnorrPublicKey
+	signers   []int
+	lambdas   map[int]*big.Int
+	xs        map[int]*big.Int
+	zs        map[int]*big.Int
+	challenge *big.Int
+	mutex     sync.Mutex
+}
+
+// NewThresholdSchnorrCoordinator returns the coordinator of the proof by the given signers,
+// which need to be at least publicKey.Threshold participants with known verification keys.
+func NewThresholdSchnorrCoordinator(group *groups.SchnorrGroup,
+	publicKey *ThresholdSchnorrPublicKey, signers []int) (*ThresholdSchnorrCoordinator, error) {
+	if len(signers) < publicKey.Threshold {
+		return nil, fmt.Errorf("at least %d signers are needed, got %d",
+			publicKey.Threshold, len(signers))
+	}
+	lambdas := make(map[int]*big.Int, len(signers))
+	for _, i := range signers {
+		if publicKey.VerificationKeys[i] == nil {
+			return nil, fmt.Errorf("verification key of participant %d is not known", i)
+		}
+		lambda, err := ThresholdSchnorrLagrangeCoefficient(group, i, signers)
+		if err != nil {
+			return nil, err
+		}
+		lambdas[i] = lambda
+	}
+	return &ThresholdSchnorrCoordinator{
+		Group:     group,
+		PublicKey: publicKey,
+		signers:   signers,
+		lambdas:   lambdas,
+		xs:        make(map[int]*big.Int, len(signers)),
+		zs:        make(map[int]*big.Int, len(signers)),
+	}, nil
+}
+
+// Signers returns the indices of the participants which produce the proof.
+func (c *ThresholdSchnorrCoordinator) Signers() []int {
+	return c.signers
+}
+
+// SetProofRandomData sets the proof random data g^r_i of the signer with the given index.
+func (c *ThresholdSchnorrCoordinator) SetProofRandomData(index int, x *big.Int) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.lambdas[index] == nil {
+		return fmt.Errorf("participant %d is not among the signers", index)
+	}
+	if !c.Group.IsElementInGroup(x) {
+		return fmt.Errorf("proof random data of participant %d is not in the group", index)
+	}
+	c.xs[index] = x
+	return nil
+}
+
+// GetProofRandomData returns the proof random data of the Schnorr proof, once the proof
+// random data of all the signers is set.
+func (c *ThresholdSchnorrCoordinator) GetProofRandomData() (*big.Int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	x := big.NewInt(1)
+	for _, i := range c.signers {
+		if c.xs[i] == nil {
+			return nil, fmt.Errorf("proof random data of participant %d is missing", i)
+		}
+		x = c.Group.Mul(x, c.xs[i])
+	}
+	return x, nil
+}
+
+// SetChallenge sets the challenge of the verifier.
+func (c *ThresholdSchnorrCoordinator) SetChallenge(challenge *big.Int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.challenge = challenge
+}
+
+// SetProofData sets the proof data z_i of the signer with the given index. It returns
+// an error if g^z_i != g^r_i * (g^x_i)^(challenge * lambda_i), which means that the signer
+// has not used its share (or the proof random data it has sent).
+func (c *ThresholdSchnorrCoordinator) SetProofData(index int, z *big.Int) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.xs[index] == nil || c.challenge == nil {
+		return fmt.Errorf("proof random data of participant %d and challenge need to be set",
+			index)
+	}
+	e := new(big.Int).Mul(c.challenge, c.lambdas[index])
+	e.Mod(e, c.Group.Q)
+	if !verifyDLogEquation(c.Group, c.Group.G, c.PublicKey.VerificationKeys[index],
+		c.xs[index], e, z) {
+		return fmt.Errorf("proof data of participant %d is not valid", index)
+	}
+	c.zs[index] = z
+	return nil
+}
+
+// GetProofData returns the proof data of the Schnorr proof, once the valid proof data of all
+// the signers is set.
+func (c *ThresholdSchnorrCoordinator) GetProofData() (*big.Int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	z := big.NewInt(0)
+	for _, i := range c.signers {
+		if c.zs[i] == nil {
+			return nil, fmt.Errorf("proof data of participant %d is missing", i)
+		}
+		z.Add(z, c.zs[i])
+	}
+	return z.Mod(z, c.Group.Q), nil
+}
@@ -0,0 +1,248 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlogproofs
+
+import (
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// Variants of the Schnorr protocol are separate types: Sigma, ZKP, ZKPOK and
+// DesignatedVerifier. The variant is passed to the constructors of provers and verifiers
+// (see WithProtocolVariant), which delegate to it everything in which the variants differ.
+// DesignatedVerifier is implemented only in Z_p, thus it is not accepted by the constructors
+// of the EC prover and verifier.
+
+// schnorrVariant contains the parts of the variant which are the same in Z_p and EC.
+type schnorrVariant interface {
+	// ProtocolType identifies the variant in the saved state of provers and verifiers.
+	ProtocolType() types.ProtocolType
+	// getChallenge returns the challenge and its decommitment (nil in sigma protocol).
+	getChallenge(generate func() (*big.Int, error),
+		committer decommitter) (*big.Int, *big.Int, error)
+	// revealTrapdoor returns the trapdoor which the prover sends together with the proof
+	// data (nil if it is not revealed).
+	revealTrapdoor(receiver trapdoorHolder) *big.Int
+	// verifyTrapdoor checks the trapdoor revealed by the prover.
+	verifyTrapdoor(committer trapdoorVerifier, trapdoor *big.Int) bool
+	// verified combines the result of the sigma protocol with the trapdoor check.
+	verified(proved, trapdoorVerified bool) bool
+}
+
+// SchnorrVariant is the variant of SchnorrProver and SchnorrVerifier.
+type SchnorrVariant interface {
+	schnorrVariant
+	// newPedersenReceiver returns the prover's receiver of the commitment to the challenge
+	// (nil if it is not known when the prover is created).
+	newPedersenReceiver(group *groups.SchnorrGroup) (*commitments.PedersenReceiver, error)
+	// newPedersenCommitter returns the verifier's committer of the challenge and
+	// the secret key of the verifier (nil if the verifier has no key).
+	newPedersenCommitter(group *groups.SchnorrGroup) (*commitments.PedersenCommitter,
+		*big.Int, error)
+}
+
+// SchnorrECVariant is the variant of SchnorrECProver and SchnorrECVerifier.
+type SchnorrECVariant interface {
+	schnorrVariant
+	newPedersenECReceiver(curve dlog.Curve) (*commitments.PedersenECReceiver, error)
+	newPedersenECCommitter(curve dlog.Curve) *commitments.PedersenECCommitter
+}
+
+type decommitter interface {
+	GetDecommitMsg() (*big.Int, *big.Int)
+}
+
+type trapdoorHolder interface {
+	GetTrapdoor() *big.Int
+}
+
+type trapdoorVerifier interface {
+	VerifyTrapdoor(trapdoor *big.Int) bool
+}
+
+// Sigma is the sigma protocol: the verifier simply generates the challenge.
+type Sigma struct{}
+
+func (Sigma) ProtocolType() types.ProtocolType {
+	return types.Sigma
+}
+
+func (Sigma) getChallenge(generate func() (*big.Int, error),
+	committer decommitter) (*big.Int, *big.Int, error) {
+	challenge, err := generate()
+	return challenge, nil, err
+}
+
+func (Sigma) revealTrapdoor(receiver trapdoorHolder) *big.Int {
+	return nil
+}
+
+func (Sigma) verifyTrapdoor(committer trapdoorVerifier, trapdoor *big.Int) bool {
+	return false
+}
+
+func (Sigma) verified(proved, trapdoorVerified bool) bool {
+	return proved
+}
+
+func (Sigma) newPedersenReceiver(group *groups.SchnorrGroup) (*commitments.PedersenReceiver,
+	error) {
+	return nil, nil
+}
+
+func (Sigma) newPedersenCommitter(group *groups.SchnorrGroup) (*commitments.PedersenCommitter,
+	*big.Int, error) {
+	return nil, nil, nil
+}
+
+func (Sigma) newPedersenECReceiver(curve dlog.Curve) (*commitments.PedersenECReceiver, error) {
+	return nil, nil
+}
+
+func (Sigma) newPedersenECCommitter(curve dlog.Curve) *commitments.PedersenECCommitter {
+	return nil
+}
+
+// ZKP is the zero knowledge proof: the prover sends h = g^trapdoor before the sigma
+// protocol starts and the verifier commits to the challenge using h.
+type ZKP struct{}
+
+func (ZKP) ProtocolType() types.ProtocolType {
+	return types.ZKP
+}
+
+func (ZKP) getChallenge(generate func() (*big.Int, error),
+	committer decommitter) (*big.Int, *big.Int, error) {
+	challenge, r := committer.GetDecommitMsg()
+	return challenge, r, nil
+}
+
+func (ZKP) revealTrapdoor(receiver trapdoorHolder) *big.Int {
+	return nil
+}
+
+func (ZKP) verifyTrapdoor(committer trapdoorVerifier, trapdoor *big.Int) bool {
+	return false
+}
+
+func (ZKP) verified(proved, trapdoorVerified bool) bool {
+	return proved
+}
+
+func (ZKP) newPedersenReceiver(group *groups.SchnorrGroup) (*commitments.PedersenReceiver,
+	error) {
+	// TODO: currently Pedersen is using the same dlog as SchnorrProver, this
+	// is because SchnorrVerifier for ZKP/ZKPOK needs to know Pedersen's dlog
+	// to generate a challenge and create a commitment
+	return commitments.NewPedersenReceiverFromExistingDLog(group)
+}
+
+func (ZKP) newPedersenCommitter(group *groups.SchnorrGroup) (*commitments.PedersenCommitter,
+	*big.Int, error) {
+	return commitments.NewPedersenCommitter(group), nil, nil
+}
+
+func (ZKP) newPedersenECReceiver(curve dlog.Curve) (*commitments.PedersenECReceiver, error) {
+	return commitments.NewPedersenECReceiver(curve)
+}
+
+func (ZKP) newPedersenECCommitter(curve dlog.Curve) *commitments.PedersenECCommitter {
+	return commitments.NewPedersenECCommitter(curve)
+}
+
+// ZKPOK is the zero knowledge proof of knowledge: as ZKP, but the prover reveals
+// the trapdoor together with the proof data and the proof is accepted only if the trapdoor
+// is verified.
+type ZKPOK struct {
+	ZKP
+}
+
+func (ZKPOK) ProtocolType() types.ProtocolType {
+	return types.ZKPOK
+}
+
+func (ZKPOK) revealTrapdoor(receiver trapdoorHolder) *big.Int {
+	return receiver.GetTrapdoor()
+}
+
+func (ZKPOK) verifyTrapdoor(committer trapdoorVerifier, trapdoor *big.Int) bool {
+	return trapdoor != nil && committer.VerifyTrapdoor(trapdoor)
+}
+
+func (ZKPOK) verified(proved, trapdoorVerified bool) bool {
+	return proved && trapdoorVerified
+}
+
+// DesignatedVerifier is the proof which convinces only the verifier with the given public
+// key (see types.DesignatedVerifier): as in ZKP the challenge is committed before the sigma
+// protocol starts, but with the verifier's public key, which the prover sets with
+// SetVerifierPublicKey.
+type DesignatedVerifier struct{}
+
+func (DesignatedVerifier) ProtocolType() types.ProtocolType {
+	return types.DesignatedVerifier
+}
+
+func (DesignatedVerifier) getChallenge(generate func() (*big.Int, error),
+	committer decommitter) (*big.Int, *big.Int, error) {
+	return ZKP{}.getChallenge(generate, committer)
+}
+
+func (DesignatedVerifier) revealTrapdoor(receiver trapdoorHolder) *big.Int {
+	return nil
+}
+
+func (DesignatedVerifier) verifyTrapdoor(committer trapdoorVerifier, trapdoor *big.Int) bool {
+	return false
+}
+
+func (DesignatedVerifier) verified(proved, trapdoorVerified bool) bool {
+	return proved
+}
+
+func (DesignatedVerifier) newPedersenReceiver(
+	group *groups.SchnorrGroup) (*commitments.PedersenReceiver, error) {
+	return nil, nil
+}
+
+// newPedersenCommitter generates a random secret key of the verifier (see
+// SchnorrVerifier.SetSecretKey).
+func (DesignatedVerifier) newPedersenCommitter(
+	group *groups.SchnorrGroup) (*commitments.PedersenCommitter, *big.Int, error) {
+	secretKey, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, nil, err
+	}
+	committer := commitments.NewPedersenCommitter(group)
+	committer.SetH(group.Exp(group.G, secretKey))
+	return committer, secretKey, nil
+}
+
+var (
+	_ SchnorrVariant   = Sigma{}
+	_ SchnorrVariant   = ZKP{}
+	_ SchnorrVariant   = ZKPOK{}
+	_ SchnorrVariant   = DesignatedVerifier{}
+	_ SchnorrECVariant = Sigma{}
+	_ SchnorrECVariant = ZKP{}
+	_ SchnorrECVariant = ZKPOK{}
+)
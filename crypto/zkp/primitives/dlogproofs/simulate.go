@@ -0,0 +1,88 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlogproofs
+
+import (
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// Simulators produce accepting transcripts (proof random data and proof data) for a given
+// challenge without knowing the witness. The transcripts are distributed as the transcripts
+// of honest provers, which is what makes the protocols honest-verifier zero-knowledge.
+// They are needed for OR-compositions (the branches which are not known are simulated)
+// and for designated-verifier proofs (the proof is an OR of the statement and
+// the knowledge of the verifier's secret key).
+//
+// Note that blinded transcripts (see DLogEqualityBTranscriptVerifier) cannot be simulated
+// by the verifier - this is the point of blinding. The prover's messages in
+// the blinded transfer are the ones of dlog equality, thus they are simulated
+// by SimulateDLogEquality.
+
+// SimulateSchnorr returns x and z such that a^z = x * b^challenge.
+func SimulateSchnorr(group *groups.SchnorrGroup, a, b, challenge *big.Int) (*big.Int,
+	*big.Int, error) {
+	z, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, nil, err
+	}
+	x := simulateDLogEquation(group, a, b, challenge, z)
+	return x, z, nil
+}
+
+// SimulateDLogEquality returns x1, x2 and z such that g1^z = x1 * t1^challenge and
+// g2^z = x2 * t2^challenge.
+func SimulateDLogEquality(group *groups.SchnorrGroup, g1, g2, t1, t2,
+	challenge *big.Int) (*big.Int, *big.Int, *big.Int, error) {
+	z, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	x1 := simulateDLogEquation(group, g1, t1, challenge, z)
+	x2 := simulateDLogEquation(group, g2, t2, challenge, z)
+	return x1, x2, z, nil
+}
+
+// SimulatePartialDLog returns the triples and c1, z1, c2, z2 which are accepted by
+// PartialDLogVerifier for the given challenge. Both branches are simulated - c1 is
+// chosen at random and c2 = c1 XOR challenge.
+func SimulatePartialDLog(group *groups.SchnorrGroup, a1, b1, a2, b2,
+	challenge *big.Int) (*types.Triple, *types.Triple, *big.Int, *big.Int, *big.Int,
+	*big.Int, error) {
+	c1, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, err
+	}
+	c2 := new(big.Int).Xor(c1, challenge)
+	x1, z1, err := SimulateSchnorr(group, a1, b1, c1)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, err
+	}
+	x2, z2, err := SimulateSchnorr(group, a2, b2, c2)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, err
+	}
+	return types.NewTriple(x1, a1, b1), types.NewTriple(x2, a2, b2), c1, z1, c2, z2, nil
+}
+
+// simulateDLogEquation returns x = a^z * b^(-challenge).
+func simulateDLogEquation(group *groups.SchnorrGroup, a, b, challenge, z *big.Int) *big.Int {
+	return group.Mul(group.Exp(a, z), group.Inv(group.Exp(b, challenge)))
+}
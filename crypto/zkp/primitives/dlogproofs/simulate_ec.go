@@ -0,0 +1,76 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlogproofs
+
+import (
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// SimulateECSchnorr returns x and z such that a^z = x * b^challenge.
+func SimulateECSchnorr(dLog *dlog.ECDLog, a, b *types.ECGroupElement,
+	challenge *big.Int) (*types.ECGroupElement, *big.Int, error) {
+	z, err := common.GetRandomInt(dLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, nil, err
+	}
+	x := simulateECDLogEquation(dLog, a, b, challenge, z)
+	return x, z, nil
+}
+
+// SimulateECDLogEquality returns x1, x2 and z such that g1^z = x1 * t1^challenge and
+// g2^z = x2 * t2^challenge.
+func SimulateECDLogEquality(dLog *dlog.ECDLog, g1, g2, t1, t2 *types.ECGroupElement,
+	challenge *big.Int) (*types.ECGroupElement, *types.ECGroupElement, *big.Int, error) {
+	z, err := common.GetRandomInt(dLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	x1 := simulateECDLogEquation(dLog, g1, t1, challenge, z)
+	x2 := simulateECDLogEquation(dLog, g2, t2, challenge, z)
+	return x1, x2, z, nil
+}
+
+// SimulatePartialECDLog returns the triples and c1, z1, c2, z2 which are accepted by
+// PartialECDLogVerifier for the given challenge.
+func SimulatePartialECDLog(dLog *dlog.ECDLog, a1, b1, a2, b2 *types.ECGroupElement,
+	challenge *big.Int) (*types.ECTriple, *types.ECTriple, *big.Int, *big.Int, *big.Int,
+	*big.Int, error) {
+	c1, err := common.GetRandomInt(dLog.GetOrderOfSubgroup())
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, err
+	}
+	c2 := new(big.Int).Xor(c1, challenge)
+	x1, z1, err := SimulateECSchnorr(dLog, a1, b1, c1)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, err
+	}
+	x2, z2, err := SimulateECSchnorr(dLog, a2, b2, c2)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, err
+	}
+	return types.NewECTriple(x1, a1, b1), types.NewECTriple(x2, a2, b2), c1, z1, c2, z2, nil
+}
+
+// simulateECDLogEquation returns x = a^z * b^(-challenge).
+func simulateECDLogEquation(dLog *dlog.ECDLog, a, b *types.ECGroupElement,
+	challenge, z *big.Int) *types.ECGroupElement {
+	return dLog.Mul(dLog.Exp(a, z), dLog.Inv(dLog.Exp(b, challenge)))
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dlogproofs
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// Provers and verifiers of Schnorr, DLogEquality and PartialDLog protocols (and their EC
+// counterparts) are safe for concurrent use - all methods of an instance are serialized by
+// an internal mutex. However, an instance runs one proof at a time:
+//
+//  - the prover consumes the randomness of GetProofRandomData in GetProofData, because
+//    responding to two different challenges for the same randomness reveals the secret.
+//    GetProofData thus returns an error unless it is preceded by a fresh GetProofRandomData.
+//  - the verifier keeps the proof random data and challenge until Reset or the next proof.
+//    Verify returns false when the proof random data or challenge have not been set.
+//
+// Reset discards the state of a (possibly unfinished) proof, after which the instance can
+// be reused, for example from a pool of instances in server handlers. Settings which are
+// not bound to a single proof (variant, group, keys of the verifier) are kept.
+//
+// The state of Schnorr provers and verifiers (Z_p and EC) can be saved with MarshalState
+// after the commitment phase and restored with UnmarshalState into an instance created with
+// the same group (curve) and variant - possibly in another process. This way a long
+// running interactive session can be checkpointed or moved to another server.
+
+var errNoProofRandomData = errors.New("dlogproofs: GetProofData needs to be preceded " +
+	"by GetProofRandomData, proof random data can be used only for one proof")
+
+// checkProofRandomData returns an error when any of the prover's randomness has not been
+// generated or has already been used for a proof.
+func checkProofRandomData(rs ...*big.Int) error {
+	for _, r := range rs {
+		if r == nil {
+			return errNoProofRandomData
+		}
+	}
+	return nil
+}
+
+// isSet returns true when all the given values have been set.
+func isSet(values ...*big.Int) bool {
+	for _, v := range values {
+		if v == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// encodedState is the ASN.1 form of the state of a prover or a verifier. Values are in
+// the order defined by each type, Set tells which of them have been set (unset values are
+// encoded as 0).
+type encodedState struct {
+	ProtocolType int
+	Values       []*big.Int
+	Set          []bool
+}
+
+// marshalState encodes the protocol type and values (which can be nil) of the state.
+func marshalState(protocolType types.ProtocolType, values ...*big.Int) ([]byte, error) {
+	enc := encodedState{
+		ProtocolType: int(protocolType),
+		Values:       make([]*big.Int, len(values)),
+		Set:          make([]bool, len(values)),
+	}
+	for i, v := range values {
+		enc.Values[i] = big.NewInt(0)
+		if v != nil {
+			enc.Values[i] = v
+			enc.Set[i] = true
+		}
+	}
+	return asn1.Marshal(enc)
+}
+
+// unmarshalState decodes the state encoded by marshalState. It checks that the state has
+// been saved by the instance of the given protocol type and that it contains n values.
+func unmarshalState(data []byte, protocolType types.ProtocolType, n int) ([]*big.Int, error) {
+	var enc encodedState
+	rest, err := asn1.Unmarshal(data, &enc)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("trailing data after state")
+	}
+	if enc.ProtocolType != int(protocolType) {
+		return nil, fmt.Errorf("state of protocol type %d cannot be restored into protocol type %d",
+			enc.ProtocolType, protocolType)
+	}
+	if len(enc.Values) != n || len(enc.Set) != n {
+		return nil, fmt.Errorf("malformed state")
+	}
+	values := make([]*big.Int, n)
+	for i, v := range enc.Values {
+		if enc.Set[i] {
+			values[i] = v
+		}
+	}
+	return values, nil
+}
+
+// pointValues returns the coordinates of p (nil values if p is nil).
+func pointValues(p *types.ECGroupElement) (*big.Int, *big.Int) {
+	if p == nil {
+		return nil, nil
+	}
+	return p.X, p.Y
+}
+
+// valuesPoint returns the point with coordinates x and y (nil if they are not set).
+func valuesPoint(x, y *big.Int) *types.ECGroupElement {
+	if x == nil || y == nil {
+		return nil
+	}
+	return types.NewECGroupElement(x, y)
+}
+
+// boolValue encodes b as 1 or nil.
+func boolValue(b bool) *big.Int {
+	if b {
+		return big.NewInt(1)
+	}
+	return nil
+}
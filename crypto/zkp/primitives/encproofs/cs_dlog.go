@@ -0,0 +1,341 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+)
+
+// ProveCSDLogEncryption demonstrates how prover can encrypt the discrete logarithm m of
+// delta = base^m with Camenisch-Shoup encryption and prove that the ciphertext contains m.
+// The verifier needs only the public key, while the holder of the secret key can decrypt m.
+func ProveCSDLogEncryption(pubKey *encryption.CSPaillierPubKey, group *groups.SchnorrGroup,
+	base, m, label *big.Int) (bool, error) {
+	prover, err := NewCSDLogEncryptionProver(pubKey, group, base, m, label)
+	if err != nil {
+		return false, err
+	}
+	delta := group.Exp(base, m)
+	verifier, err := NewCSDLogEncryptionVerifier(pubKey, group, base, delta, label)
+	if err != nil {
+		return false, err
+	}
+
+	randomData, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(prover.GetEncryption(), randomData); err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+// CSDLogEncryption is Camenisch-Shoup ciphertext (U, E, V) together with the commitment
+// L = g1^m * h1^s to the plaintext in the group of the public key which is used for
+// verifiable encryption (VerifiableEncGroupN).
+type CSDLogEncryption struct {
+	U *big.Int
+	E *big.Int
+	V *big.Int
+	L *big.Int
+}
+
+type CSDLogProofRandomData struct {
+	U1     *big.Int
+	E1     *big.Int
+	V1     *big.Int
+	Delta1 *big.Int
+	L1     *big.Int
+}
+
+// CSDLogProofData holds the responses which are computed in integers (they can be negative).
+type CSDLogProofData struct {
+	RTilde *big.Int
+	STilde *big.Int
+	MTilde *big.Int
+}
+
+// CSDLogEncryptionProver is the verifiable encryption of discrete logarithms from
+// Camenisch, Shoup: Practical Verifiable Encryption and Decryption of Discrete Logarithms.
+// Unlike CSPaillier.Verify (which needs the secret key), the proof is verified with
+// the public key only, so that the ciphertext can be checked by anybody (for example by
+// an organization) and decrypted only by the holder of the secret key (for example by an
+// auditor). The discrete logarithm is taken in the given Schnorr group instead of
+// in the group Gamma of the public key.
+type CSDLogEncryptionProver struct {
+	pubKey     *encryption.CSPaillierPubKey
+	group      *groups.SchnorrGroup
+	base       *big.Int
+	m          *big.Int
+	label      *big.Int
+	r          *big.Int
+	s          *big.Int
+	r1         *big.Int
+	s1         *big.Int
+	m1         *big.Int
+	encryption *CSDLogEncryption
+}
+
+// NewCSDLogEncryptionProver encrypts m (which needs to be from Z_Q) under the label.
+func NewCSDLogEncryptionProver(pubKey *encryption.CSPaillierPubKey, group *groups.SchnorrGroup,
+	base, m, label *big.Int) (*CSDLogEncryptionProver, error) {
+	if err := checkCSParams(pubKey, group); err != nil {
+		return nil, err
+	}
+	if m.Sign() < 0 || m.Cmp(group.Q) >= 0 {
+		return nil, fmt.Errorf("discrete logarithm needs to be from Z_Q")
+	}
+
+	r, err := common.GetRandomInt(new(big.Int).Div(pubKey.N, big.NewInt(4)))
+	if err != nil {
+		return nil, err
+	}
+	u, e, v, err := encryption.NewCSPaillierFromPubKey(pubKey).EncryptWithR(m, label, r)
+	if err != nil {
+		return nil, err
+	}
+
+	// l = g1^m * h1^s where s is from [0, n/4)
+	s, err := common.GetRandomInt(new(big.Int).Div(pubKey.VerifiableEncGroupN, big.NewInt(4)))
+	if err != nil {
+		return nil, err
+	}
+	l := new(big.Int).Exp(pubKey.VerifiableEncGroupG1, m, pubKey.VerifiableEncGroupN)
+	l.Mul(l, new(big.Int).Exp(pubKey.VerifiableEncGroupH1, s, pubKey.VerifiableEncGroupN))
+	l.Mod(l, pubKey.VerifiableEncGroupN)
+
+	return &CSDLogEncryptionProver{
+		pubKey:     pubKey,
+		group:      group,
+		base:       base,
+		m:          m,
+		label:      label,
+		r:          r,
+		s:          s,
+		encryption: &CSDLogEncryption{U: u, E: e, V: v, L: l},
+	}, nil
+}
+
+// GetEncryption returns the ciphertext and the commitment to the plaintext.
+func (prover *CSDLogEncryptionProver) GetEncryption() *CSDLogEncryption {
+	return prover.encryption
+}
+
+func (prover *CSDLogEncryptionProver) GetProofRandomData() (*CSDLogProofRandomData, error) {
+	pubKey := prover.pubKey
+	t := pow2(pubKey.K + pubKey.K1 - 2)
+	r1, err := randomSymmetric(new(big.Int).Mul(pubKey.N, t))
+	if err != nil {
+		return nil, err
+	}
+	s1, err := randomSymmetric(new(big.Int).Mul(pubKey.VerifiableEncGroupN, t))
+	if err != nil {
+		return nil, err
+	}
+	m1, err := randomSymmetric(new(big.Int).Mul(prover.group.Q, pow2(pubKey.K+pubKey.K1)))
+	if err != nil {
+		return nil, err
+	}
+	prover.r1, prover.s1, prover.m1 = r1, s1, m1
+
+	n2 := new(big.Int).Mul(pubKey.N, pubKey.N)
+	twoR1 := new(big.Int).Lsh(r1, 1)
+	h := new(big.Int).Add(pubKey.N, big.NewInt(1))
+
+	// u1 = g^(2*r1), e1 = y1^(2*r1) * h^(2*m1), v1 = (y2 * y3^hash(u, e, L))^(2*r1)
+	u1 := common.Exponentiate(pubKey.G, twoR1, n2)
+	e1 := common.Exponentiate(pubKey.Y1, twoR1, n2)
+	e1.Mul(e1, common.Exponentiate(h, new(big.Int).Lsh(m1, 1), n2))
+	e1.Mod(e1, n2)
+	v1 := common.Exponentiate(csHashBase(pubKey, prover.encryption, prover.label), twoR1, n2)
+
+	// delta1 = base^m1, l1 = g1^m1 * h1^s1
+	delta1 := common.Exponentiate(prover.base, m1, prover.group.P)
+	l1 := common.Exponentiate(pubKey.VerifiableEncGroupG1, m1, pubKey.VerifiableEncGroupN)
+	l1.Mul(l1, common.Exponentiate(pubKey.VerifiableEncGroupH1, s1, pubKey.VerifiableEncGroupN))
+	l1.Mod(l1, pubKey.VerifiableEncGroupN)
+
+	return &CSDLogProofRandomData{U1: u1, E1: e1, V1: v1, Delta1: delta1, L1: l1}, nil
+}
+
+// GetProofData returns rTilde = r1 - c*r, sTilde = s1 - c*s and mTilde = m1 - c*m.
+func (prover *CSDLogEncryptionProver) GetProofData(challenge *big.Int) *CSDLogProofData {
+	return &CSDLogProofData{
+		RTilde: new(big.Int).Sub(prover.r1, new(big.Int).Mul(challenge, prover.r)),
+		STilde: new(big.Int).Sub(prover.s1, new(big.Int).Mul(challenge, prover.s)),
+		MTilde: new(big.Int).Sub(prover.m1, new(big.Int).Mul(challenge, prover.m)),
+	}
+}
+
+type CSDLogEncryptionVerifier struct {
+	pubKey     *encryption.CSPaillierPubKey
+	group      *groups.SchnorrGroup
+	base       *big.Int
+	delta      *big.Int
+	label      *big.Int
+	encryption *CSDLogEncryption
+	randomData *CSDLogProofRandomData
+	challenge  *big.Int
+}
+
+// NewCSDLogEncryptionVerifier returns a verifier of the proof that the ciphertext
+// (encrypted under the label) contains log_base(delta).
+func NewCSDLogEncryptionVerifier(pubKey *encryption.CSPaillierPubKey,
+	group *groups.SchnorrGroup, base, delta, label *big.Int) (*CSDLogEncryptionVerifier, error) {
+	if err := checkCSParams(pubKey, group); err != nil {
+		return nil, err
+	}
+	if !group.IsElementInGroup(base) || !group.IsElementInGroup(delta) {
+		return nil, fmt.Errorf("base and delta need to be in the group")
+	}
+	return &CSDLogEncryptionVerifier{
+		pubKey: pubKey,
+		group:  group,
+		base:   base,
+		delta:  delta,
+		label:  label,
+	}, nil
+}
+
+func (verifier *CSDLogEncryptionVerifier) SetProofRandomData(enc *CSDLogEncryption,
+	data *CSDLogProofRandomData) error {
+	n2 := new(big.Int).Mul(verifier.pubKey.N, verifier.pubKey.N)
+	for _, x := range []*big.Int{enc.U, enc.E, enc.V, data.U1, data.E1, data.V1} {
+		if !isUnit(x, n2) {
+			return fmt.Errorf("ciphertext or proof random data is not from Z_n^2*")
+		}
+	}
+	// v needs to be in its canonical form (see CSPaillier.Abs)
+	if enc.V.Cmp(new(big.Int).Rsh(n2, 1)) > 0 {
+		return fmt.Errorf("v is not in its canonical form")
+	}
+	if !isUnit(enc.L, verifier.pubKey.VerifiableEncGroupN) ||
+		!isUnit(data.L1, verifier.pubKey.VerifiableEncGroupN) {
+		return fmt.Errorf("commitment is not from Z_n*")
+	}
+	if !verifier.group.IsElementInGroup(data.Delta1) {
+		return fmt.Errorf("proof random data is not in the group")
+	}
+	verifier.encryption = enc
+	verifier.randomData = data
+	return nil
+}
+
+// GetChallenge returns a random challenge from [0, 2^K).
+func (verifier *CSDLogEncryptionVerifier) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(pow2(verifier.pubKey.K))
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return challenge, nil
+}
+
+// Verify checks:
+//   - u1 = u^(2c) * g^(2*rTilde),
+//   - e1 = e^(2c) * y1^(2*rTilde) * h^(2*mTilde),
+//   - v1 = v^(2c) * (y2 * y3^hash(u, e, L))^(2*rTilde),
+//   - delta1 = delta^c * base^mTilde,
+//   - l1 = l^c * g1^mTilde * h1^sTilde,
+//   - |mTilde| < n/4.
+func (verifier *CSDLogEncryptionVerifier) Verify(data *CSDLogProofData) bool {
+	pubKey := verifier.pubKey
+	enc, rd, c := verifier.encryption, verifier.randomData, verifier.challenge
+	if enc == nil || c == nil {
+		return false
+	}
+	if new(big.Int).Abs(data.MTilde).Cmp(new(big.Int).Div(pubKey.N, big.NewInt(4))) >= 0 {
+		return false
+	}
+
+	n2 := new(big.Int).Mul(pubKey.N, pubKey.N)
+	twoC := new(big.Int).Lsh(c, 1)
+	twoRTilde := new(big.Int).Lsh(data.RTilde, 1)
+	h := new(big.Int).Add(pubKey.N, big.NewInt(1))
+	mulMod := func(m *big.Int, xs ...*big.Int) *big.Int {
+		r := big.NewInt(1)
+		for _, x := range xs {
+			r.Mul(r, x)
+			r.Mod(r, m)
+		}
+		return r
+	}
+
+	u1 := mulMod(n2, common.Exponentiate(enc.U, twoC, n2),
+		common.Exponentiate(pubKey.G, twoRTilde, n2))
+	e1 := mulMod(n2, common.Exponentiate(enc.E, twoC, n2),
+		common.Exponentiate(pubKey.Y1, twoRTilde, n2),
+		common.Exponentiate(h, new(big.Int).Lsh(data.MTilde, 1), n2))
+	v1 := mulMod(n2, common.Exponentiate(enc.V, twoC, n2),
+		common.Exponentiate(csHashBase(pubKey, enc, verifier.label), twoRTilde, n2))
+	delta1 := mulMod(verifier.group.P, common.Exponentiate(verifier.delta, c, verifier.group.P),
+		common.Exponentiate(verifier.base, data.MTilde, verifier.group.P))
+	nAux := pubKey.VerifiableEncGroupN
+	l1 := mulMod(nAux, common.Exponentiate(enc.L, c, nAux),
+		common.Exponentiate(pubKey.VerifiableEncGroupG1, data.MTilde, nAux),
+		common.Exponentiate(pubKey.VerifiableEncGroupH1, data.STilde, nAux))
+
+	return u1.Cmp(rd.U1) == 0 && e1.Cmp(rd.E1) == 0 && v1.Cmp(rd.V1) == 0 &&
+		delta1.Cmp(rd.Delta1) == 0 && l1.Cmp(rd.L1) == 0
+}
+
+// csHashBase returns y2 * y3^hash(u, e, label) mod n^2.
+func csHashBase(pubKey *encryption.CSPaillierPubKey, enc *CSDLogEncryption,
+	label *big.Int) *big.Int {
+	n2 := new(big.Int).Mul(pubKey.N, pubKey.N)
+	t := new(big.Int).Exp(pubKey.Y3, common.Hash(enc.U, enc.E, label), n2)
+	t.Mul(t, pubKey.Y2)
+	return t.Mod(t, n2)
+}
+
+// checkCSParams checks that 2^K < Q and Q * 2^(K + K1 + 3) < n, which is required for
+// the soundness of the proof.
+func checkCSParams(pubKey *encryption.CSPaillierPubKey, group *groups.SchnorrGroup) error {
+	if pubKey.K <= 0 || pubKey.K1 <= 0 || pubKey.VerifiableEncGroupN == nil {
+		return fmt.Errorf("public key has no parameters for verifiable encryption")
+	}
+	if pow2(pubKey.K).Cmp(group.Q) >= 0 {
+		return fmt.Errorf("2^K needs to be smaller than the order of the group")
+	}
+	b := new(big.Int).Mul(group.Q, pow2(pubKey.K+pubKey.K1+3))
+	if b.Cmp(pubKey.N) >= 0 {
+		return fmt.Errorf("Q * 2^(K + K1 + 3) needs to be smaller than n")
+	}
+	return nil
+}
+
+// randomSymmetric returns a random integer from (-b, b).
+func randomSymmetric(b *big.Int) (*big.Int, error) {
+	return common.GetRandomIntFromRange(new(big.Int).Neg(b), b)
+}
+
+func isUnit(x, n *big.Int) bool {
+	if x == nil || x.Sign() <= 0 || x.Cmp(n) >= 0 {
+		return false
+	}
+	return new(big.Int).GCD(nil, nil, x, n).Cmp(big.NewInt(1)) == 0
+}
@@ -0,0 +1,307 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+)
+
+// ProveElGamalPlaintextEquality demonstrates how prover can prove that ElGamal ciphertexts
+// c1 = Enc(pubKey1, m; r1) and c2 = Enc(pubKey2, m; r2) encrypt the same plaintext.
+func ProveElGamalPlaintextEquality(pubKey1, pubKey2 *encryption.ElGamalPubKey, m, r1,
+	r2 *big.Int) (bool, error) {
+	c1, err := pubKey1.EncryptWithR(m, r1)
+	if err != nil {
+		return false, err
+	}
+	c2, err := pubKey2.EncryptWithR(m, r2)
+	if err != nil {
+		return false, err
+	}
+	prover, err := NewElGamalPlaintextEqualityProver(pubKey1, pubKey2, c1, c2, r1, r2)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewElGamalPlaintextEqualityVerifier(pubKey1, pubKey2, c1, c2)
+	if err != nil {
+		return false, err
+	}
+	return runElGamalPlaintextEquality(prover, verifier)
+}
+
+// ProveElGamalKeyRotation demonstrates how the holder of the first secret key can decrypt
+// c1 and encrypt the plaintext under pubKey2 (with randomness r2), and prove that
+// the new ciphertext contains the same plaintext.
+func ProveElGamalKeyRotation(elgamal1 *encryption.ElGamal, pubKey2 *encryption.ElGamalPubKey,
+	c1 *encryption.ElGamalCiphertext, r2 *big.Int) (bool, error) {
+	m, err := elgamal1.Decrypt(c1)
+	if err != nil {
+		return false, err
+	}
+	c2, err := pubKey2.EncryptWithR(m, r2)
+	if err != nil {
+		return false, err
+	}
+	prover, err := NewElGamalKeyRotationProver(elgamal1, pubKey2, c1, c2, r2)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewElGamalKeyRotationVerifier(elgamal1.GetPubKey(), pubKey2, c1, c2)
+	if err != nil {
+		return false, err
+	}
+	return runElGamalPlaintextEquality(prover, verifier)
+}
+
+func runElGamalPlaintextEquality(prover *ElGamalPlaintextEqualityProver,
+	verifier *ElGamalPlaintextEqualityVerifier) (bool, error) {
+	proofRandomData, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(proofRandomData); err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	z1, z2 := prover.GetProofData(challenge)
+	return verifier.Verify(z1, z2), nil
+}
+
+// ElGamalPlaintextEqualityProver proves that c1 = (A1, B1) under the public key y1 and
+// c2 = (A2, B2) under y2 (both in the same group) encrypt the same plaintext. This holds
+// iff B1/B2 = y1^r1 * y2^-r2 where A1 = g^r1 and A2 = g^r2. As y1^r1 = A1^x1, the equality
+// can be proved either by the encryptor (knowing r1 and r2) or by the holder of the first
+// secret key x1 who re-encrypts the plaintext under y2 (key rotation).
+//
+// Both cases are the proof of knowledge of a, b such that:
+//
+//	g^a = u1, g^b = u2, w1^a * w2^b = v
+//
+// where u2 = A2, w2 = y2^-1, v = B1/B2, and (u1, w1) = (A1, y1) for the encryptor or
+// (u1, w1) = (y1, A1) for the key holder. This is Chaum-Pedersen proof of dlog equality
+// extended to two secrets:
+//   - prover chooses k1, k2 from Z_q and sends T1 = g^k1, T2 = g^k2, T3 = w1^k1 * w2^k2,
+//   - verifier sends challenge c from Z_q,
+//   - prover sends z1 = k1 + c*a mod q and z2 = k2 + c*b mod q,
+//   - verifier checks g^z1 = T1 * u1^c, g^z2 = T2 * u2^c and w1^z1 * w2^z2 = T3 * v^c.
+//
+// When both ciphertexts are under the same key and c2 is a re-encryption of c1 with
+// randomness s, it suffices to prove log_g(A2/A1) = log_y(B2/B1) = s using
+// dlogproofs.ProveDLogEquality.
+type ElGamalPlaintextEqualityProver struct {
+	statement *elgamalEqualityStatement
+	a         *big.Int
+	b         *big.Int
+	k1        *big.Int
+	k2        *big.Int
+}
+
+// ElGamalPlaintextEqualityProofRandomData is the prover's first message.
+type ElGamalPlaintextEqualityProofRandomData struct {
+	T1 *big.Int
+	T2 *big.Int
+	T3 *big.Int
+}
+
+// NewElGamalPlaintextEqualityProver returns the prover which knows the randomness r1 and r2
+// used to encrypt c1 and c2.
+func NewElGamalPlaintextEqualityProver(pubKey1, pubKey2 *encryption.ElGamalPubKey, c1,
+	c2 *encryption.ElGamalCiphertext, r1, r2 *big.Int) (*ElGamalPlaintextEqualityProver, error) {
+	statement, err := newElGamalEqualityStatement(pubKey1, pubKey2, c1, c2, false)
+	if err != nil {
+		return nil, err
+	}
+	return &ElGamalPlaintextEqualityProver{
+		statement: statement,
+		a:         r1,
+		b:         r2,
+	}, nil
+}
+
+// NewElGamalKeyRotationProver returns the prover which knows the secret key of c1 and
+// the randomness r2 used to encrypt c2.
+func NewElGamalKeyRotationProver(elgamal1 *encryption.ElGamal, pubKey2 *encryption.ElGamalPubKey,
+	c1, c2 *encryption.ElGamalCiphertext, r2 *big.Int) (*ElGamalPlaintextEqualityProver, error) {
+	statement, err := newElGamalEqualityStatement(elgamal1.GetPubKey(), pubKey2, c1, c2, true)
+	if err != nil {
+		return nil, err
+	}
+	return &ElGamalPlaintextEqualityProver{
+		statement: statement,
+		a:         elgamal1.GetSecretKey(),
+		b:         r2,
+	}, nil
+}
+
+// GetProofRandomData returns T1 = g^k1, T2 = g^k2 and T3 = w1^k1 * w2^k2.
+func (prover *ElGamalPlaintextEqualityProver) GetProofRandomData() (
+	*ElGamalPlaintextEqualityProofRandomData, error) {
+	group := prover.statement.group
+	k1, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, err
+	}
+	k2, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, err
+	}
+	prover.k1, prover.k2 = k1, k2
+	return &ElGamalPlaintextEqualityProofRandomData{
+		T1: group.Exp(group.G, k1),
+		T2: group.Exp(group.G, k2),
+		T3: prover.statement.combine(k1, k2),
+	}, nil
+}
+
+// GetProofData returns z1 = k1 + challenge*a mod q and z2 = k2 + challenge*b mod q.
+func (prover *ElGamalPlaintextEqualityProver) GetProofData(challenge *big.Int) (*big.Int,
+	*big.Int) {
+	q := prover.statement.group.Q
+	z1 := new(big.Int).Mul(challenge, prover.a)
+	z1.Add(z1, prover.k1)
+	z2 := new(big.Int).Mul(challenge, prover.b)
+	z2.Add(z2, prover.k2)
+	return z1.Mod(z1, q), z2.Mod(z2, q)
+}
+
+type ElGamalPlaintextEqualityVerifier struct {
+	statement       *elgamalEqualityStatement
+	proofRandomData *ElGamalPlaintextEqualityProofRandomData
+	challenge       *big.Int
+}
+
+// NewElGamalPlaintextEqualityVerifier returns a verifier of the proof that c1 (encrypted
+// under pubKey1) and c2 (encrypted under pubKey2) contain the same plaintext, given by
+// the encryptor.
+func NewElGamalPlaintextEqualityVerifier(pubKey1, pubKey2 *encryption.ElGamalPubKey, c1,
+	c2 *encryption.ElGamalCiphertext) (*ElGamalPlaintextEqualityVerifier, error) {
+	statement, err := newElGamalEqualityStatement(pubKey1, pubKey2, c1, c2, false)
+	if err != nil {
+		return nil, err
+	}
+	return &ElGamalPlaintextEqualityVerifier{
+		statement: statement,
+	}, nil
+}
+
+// NewElGamalKeyRotationVerifier returns a verifier of the proof that c1 and c2 contain
+// the same plaintext, given by the holder of the secret key which corresponds to pubKey1.
+func NewElGamalKeyRotationVerifier(pubKey1, pubKey2 *encryption.ElGamalPubKey, c1,
+	c2 *encryption.ElGamalCiphertext) (*ElGamalPlaintextEqualityVerifier, error) {
+	statement, err := newElGamalEqualityStatement(pubKey1, pubKey2, c1, c2, true)
+	if err != nil {
+		return nil, err
+	}
+	return &ElGamalPlaintextEqualityVerifier{
+		statement: statement,
+	}, nil
+}
+
+func (verifier *ElGamalPlaintextEqualityVerifier) SetProofRandomData(
+	data *ElGamalPlaintextEqualityProofRandomData) error {
+	group := verifier.statement.group
+	if data == nil || !group.IsElementInGroup(data.T1) || !group.IsElementInGroup(data.T2) ||
+		!group.IsElementInGroup(data.T3) {
+		return fmt.Errorf("proof random data is not from the group")
+	}
+	verifier.proofRandomData = data
+	return nil
+}
+
+// GetChallenge returns a random challenge from Z_q.
+func (verifier *ElGamalPlaintextEqualityVerifier) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(verifier.statement.group.Q)
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return challenge, nil
+}
+
+// Verify checks g^z1 = T1 * u1^c, g^z2 = T2 * u2^c and w1^z1 * w2^z2 = T3 * v^c.
+func (verifier *ElGamalPlaintextEqualityVerifier) Verify(z1, z2 *big.Int) bool {
+	data := verifier.proofRandomData
+	if data == nil || verifier.challenge == nil || z1 == nil || z2 == nil {
+		return false
+	}
+	s := verifier.statement
+	group := s.group
+	q := group.Q
+	if z1.Sign() < 0 || z1.Cmp(q) >= 0 || z2.Sign() < 0 || z2.Cmp(q) >= 0 {
+		return false
+	}
+	c := verifier.challenge
+	check := func(left, t, x *big.Int) bool {
+		right := group.Mul(t, group.Exp(x, c))
+		return left.Cmp(right) == 0
+	}
+	return check(group.Exp(group.G, z1), data.T1, s.u1) &&
+		check(group.Exp(group.G, z2), data.T2, s.u2) &&
+		check(s.combine(z1, z2), data.T3, s.v)
+}
+
+// elgamalEqualityStatement are the values from the relation
+// g^a = u1, g^b = u2, w1^a * w2^b = v (see ElGamalPlaintextEqualityProver).
+type elgamalEqualityStatement struct {
+	group *groups.SchnorrGroup
+	u1    *big.Int
+	u2    *big.Int
+	w1    *big.Int
+	w2    *big.Int
+	v     *big.Int
+}
+
+func newElGamalEqualityStatement(pubKey1, pubKey2 *encryption.ElGamalPubKey, c1,
+	c2 *encryption.ElGamalCiphertext, keyRotation bool) (*elgamalEqualityStatement, error) {
+	group := pubKey1.Group
+	other := pubKey2.Group
+	if group.P.Cmp(other.P) != 0 || group.Q.Cmp(other.Q) != 0 || group.G.Cmp(other.G) != 0 {
+		return nil, fmt.Errorf("public keys need to be in the same group")
+	}
+	if !group.IsElementInGroup(pubKey1.Y) || !group.IsElementInGroup(pubKey2.Y) {
+		return nil, fmt.Errorf("public key is not from the group")
+	}
+	if !pubKey1.IsCiphertext(c1) || !pubKey2.IsCiphertext(c2) {
+		return nil, fmt.Errorf("ciphertext is not from the group")
+	}
+
+	statement := &elgamalEqualityStatement{
+		group: group,
+		u1:    c1.A,
+		u2:    c2.A,
+		w1:    pubKey1.Y,
+		w2:    group.Inv(pubKey2.Y),
+		v:     group.Mul(c1.B, group.Inv(c2.B)),
+	}
+	if keyRotation {
+		statement.u1, statement.w1 = pubKey1.Y, c1.A
+	}
+	return statement, nil
+}
+
+// combine returns w1^x1 * w2^x2.
+func (s *elgamalEqualityStatement) combine(x1, x2 *big.Int) *big.Int {
+	return s.group.Mul(s.group.Exp(s.w1, x1), s.group.Exp(s.w2, x2))
+}
@@ -0,0 +1,174 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"math/big"
+)
+
+// ProveElGamalDecryption demonstrates how the holder of the secret key can decrypt c and
+// prove that the returned plaintext is the correct decryption of c.
+func ProveElGamalDecryption(elgamal *encryption.ElGamal,
+	c *encryption.ElGamalCiphertext) (bool, error) {
+	prover, err := NewElGamalDecryptionProver(elgamal, c)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewElGamalDecryptionVerifier(elgamal.GetPubKey(), c, prover.GetPlaintext())
+	if err != nil {
+		return false, err
+	}
+	x1, x2, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge(x1, x2)
+	if err != nil {
+		return false, err
+	}
+	z, err := prover.GetProofData(challenge)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(z), nil
+}
+
+// ElGamalDecryptionProver proves that m is the decryption of c = (A, B) under the public
+// key y = g^x. As m = B / A^x, this is the proof of log_g(y) = log_A(B/m) - Chaum-Pedersen
+// proof of dlog equality (see dlogproofs.DLogEqualityProver). The plaintext is revealed,
+// thus the proof can be used for verifiable tallying: the authority decrypts the (shuffled
+// or homomorphically combined) ciphertexts and proves each decryption.
+//
+// The non-interactive variant is fiatshamir.NewDLogEqualityProver with the values returned
+// by ElGamalDecryptionStatement.
+type ElGamalDecryptionProver struct {
+	statement *ElGamalDecryptionStatement
+	secret    *big.Int
+	prover    *dlogproofs.DLogEqualityProver
+}
+
+// NewElGamalDecryptionProver decrypts c and returns the prover of the correct decryption.
+func NewElGamalDecryptionProver(elgamal *encryption.ElGamal,
+	c *encryption.ElGamalCiphertext) (*ElGamalDecryptionProver, error) {
+	m, err := elgamal.Decrypt(c)
+	if err != nil {
+		return nil, err
+	}
+	statement, err := NewElGamalDecryptionStatement(elgamal.GetPubKey(), c, m)
+	if err != nil {
+		return nil, err
+	}
+	return &ElGamalDecryptionProver{
+		statement: statement,
+		secret:    elgamal.GetSecretKey(),
+		prover:    dlogproofs.NewDLogEqualityProver(statement.Group),
+	}, nil
+}
+
+// GetPlaintext returns the decryption of the ciphertext.
+func (prover *ElGamalDecryptionProver) GetPlaintext() *big.Int {
+	return prover.statement.M
+}
+
+// GetProofRandomData returns x1 = g^r and x2 = A^r.
+func (prover *ElGamalDecryptionProver) GetProofRandomData() (*big.Int, *big.Int, error) {
+	s := prover.statement
+	return prover.prover.GetProofRandomData(prover.secret, s.G1, s.G2)
+}
+
+// GetProofData returns z = r + challenge * x mod q. It returns an error if the proof random
+// data has not been generated or has been already used.
+func (prover *ElGamalDecryptionProver) GetProofData(challenge *big.Int) (*big.Int, error) {
+	return prover.prover.GetProofData(challenge)
+}
+
+type ElGamalDecryptionVerifier struct {
+	statement *ElGamalDecryptionStatement
+	verifier  *dlogproofs.DLogEqualityVerifier
+}
+
+// NewElGamalDecryptionVerifier returns a verifier of the proof that m is the decryption
+// of c under pubKey.
+func NewElGamalDecryptionVerifier(pubKey *encryption.ElGamalPubKey,
+	c *encryption.ElGamalCiphertext, m *big.Int) (*ElGamalDecryptionVerifier, error) {
+	statement, err := NewElGamalDecryptionStatement(pubKey, c, m)
+	if err != nil {
+		return nil, err
+	}
+	return &ElGamalDecryptionVerifier{
+		statement: statement,
+		verifier:  dlogproofs.NewDLogEqualityVerifier(statement.Group),
+	}, nil
+}
+
+// GetChallenge sets the proof random data and returns a random challenge from Z_q.
+func (verifier *ElGamalDecryptionVerifier) GetChallenge(x1, x2 *big.Int) (*big.Int, error) {
+	group := verifier.statement.Group
+	if !group.IsElementInGroup(x1) || !group.IsElementInGroup(x2) {
+		return nil, fmt.Errorf("proof random data is not from the group")
+	}
+	s := verifier.statement
+	return verifier.verifier.GetChallenge(s.G1, s.G2, s.T1, s.T2, x1, x2)
+}
+
+// Verify checks that g^z = x1 * y^challenge and A^z = x2 * (B/m)^challenge.
+func (verifier *ElGamalDecryptionVerifier) Verify(z *big.Int) bool {
+	if z == nil || z.Sign() < 0 || z.Cmp(verifier.statement.Group.Q) >= 0 {
+		return false
+	}
+	return verifier.verifier.Verify(z)
+}
+
+// ElGamalDecryptionStatement is the dlog equality log_G1(T1) = log_G2(T2) which holds iff
+// M is the decryption of the ciphertext: G1 = g, T1 = y, G2 = A and T2 = B/M.
+type ElGamalDecryptionStatement struct {
+	Group *groups.SchnorrGroup
+	M     *big.Int
+	G1    *big.Int
+	T1    *big.Int
+	G2    *big.Int
+	T2    *big.Int
+}
+
+// NewElGamalDecryptionStatement returns the statement that m is the decryption of c under
+// pubKey. It returns an error if any of the values is not from the group.
+func NewElGamalDecryptionStatement(pubKey *encryption.ElGamalPubKey,
+	c *encryption.ElGamalCiphertext, m *big.Int) (*ElGamalDecryptionStatement, error) {
+	group := pubKey.Group
+	if !group.IsElementInGroup(pubKey.Y) {
+		return nil, fmt.Errorf("public key is not from the group")
+	}
+	if !pubKey.IsCiphertext(c) {
+		return nil, fmt.Errorf("ciphertext is not from the group")
+	}
+	if !group.IsElementInGroup(m) {
+		return nil, fmt.Errorf("plaintext is not from the group")
+	}
+	return &ElGamalDecryptionStatement{
+		Group: group,
+		M:     m,
+		G1:    group.G,
+		T1:    pubKey.Y,
+		G2:    c.A,
+		T2:    group.Mul(c.B, group.Inv(m)),
+	}, nil
+}
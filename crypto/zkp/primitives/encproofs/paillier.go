@@ -0,0 +1,241 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/zkp/security"
+	"math/big"
+)
+
+// ChallengeBitLength is the (default) bit length of challenges in the proofs about Paillier
+// ciphertexts. The challenges need to be smaller than the prime factors of the Paillier
+// modulus.
+const ChallengeBitLength = 128
+
+// ProvePaillierPlaintextKnowledge demonstrates how prover can prove that it knows
+// the plaintext m of Paillier ciphertext c = g^m * r^n mod n^2.
+func ProvePaillierPlaintextKnowledge(pubKey *encryption.PaillierPubKey, m, r *big.Int) (bool,
+	error) {
+	c, err := encryption.NewPubPaillier(pubKey).EncryptWithR(m, r)
+	if err != nil {
+		return false, err
+	}
+	prover, err := NewPaillierPlaintextProver(pubKey, m, r)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewPaillierPlaintextVerifier(pubKey, c)
+	if err != nil {
+		return false, err
+	}
+
+	a, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(a); err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	z, w := prover.GetProofData(challenge)
+	return verifier.Verify(z, w), nil
+}
+
+// PaillierPlaintextProver proves the knowledge of m and r such that c = g^m * r^n mod n^2:
+//   - prover chooses x from Z_n, s from Z_n* and sends a = g^x * s^n mod n^2,
+//   - verifier sends challenge e,
+//   - prover sends z = x + e*m mod n and w = s * r^e * g^((x + e*m) / n) mod n^2,
+//   - verifier checks g^z * w^n = a * c^e mod n^2.
+type PaillierPlaintextProver struct {
+	pubKey *encryption.PaillierPubKey
+	m      *big.Int
+	r      *big.Int
+	x      *big.Int
+	s      *big.Int
+}
+
+func NewPaillierPlaintextProver(pubKey *encryption.PaillierPubKey, m, r *big.Int) (
+	*PaillierPlaintextProver, error) {
+	if err := checkPlaintext(pubKey, m, r); err != nil {
+		return nil, err
+	}
+	return &PaillierPlaintextProver{
+		pubKey: pubKey,
+		m:      m,
+		r:      r,
+	}, nil
+}
+
+// GetProofRandomData returns a = g^x * s^n mod n^2.
+func (prover *PaillierPlaintextProver) GetProofRandomData() (*big.Int, error) {
+	n := prover.pubKey.GetN()
+	x, err := common.GetRandomInt(n)
+	if err != nil {
+		return nil, err
+	}
+	s, err := common.GetRandomZnInvertibleElement(n)
+	if err != nil {
+		return nil, err
+	}
+	prover.x, prover.s = x, s
+	return encrypt(prover.pubKey, x, s), nil
+}
+
+// GetProofData returns z = x + e*m mod n and w = s * r^e * g^((x + e*m) / n) mod n^2.
+func (prover *PaillierPlaintextProver) GetProofData(challenge *big.Int) (*big.Int, *big.Int) {
+	n := prover.pubKey.GetN()
+	n2 := new(big.Int).Mul(n, n)
+	t := new(big.Int).Mul(challenge, prover.m)
+	t.Add(t, prover.x)
+	k, z := new(big.Int).DivMod(t, n, new(big.Int)) // k = (x + e*m) / n, z = (x + e*m) mod n
+
+	w := new(big.Int).Exp(prover.r, challenge, n2)
+	w.Mul(w, prover.s)
+	w.Mul(w, new(big.Int).Exp(prover.pubKey.GetG(), k, n2))
+	w.Mod(w, n2)
+	return z, w
+}
+
+type PaillierPlaintextVerifier struct {
+	pubKey       *encryption.PaillierPubKey
+	c            *big.Int
+	a            *big.Int
+	challenge    *big.Int
+	challengeLen int
+}
+
+// NewPaillierPlaintextVerifier returns a verifier of the proof that the prover knows
+// the plaintext of c.
+func NewPaillierPlaintextVerifier(pubKey *encryption.PaillierPubKey, c *big.Int) (
+	*PaillierPlaintextVerifier, error) {
+	if err := checkPubKey(pubKey); err != nil {
+		return nil, err
+	}
+	if !isInvertibleModN2(pubKey, c) {
+		return nil, fmt.Errorf("ciphertext is not from Z_n^2*")
+	}
+	return &PaillierPlaintextVerifier{
+		pubKey:       pubKey,
+		c:            c,
+		challengeLen: ChallengeBitLength,
+	}, nil
+}
+
+// SetSecurity sets the bit length of the challenges given by params, the challenges still
+// need to be smaller than the prime factors of the modulus.
+func (verifier *PaillierPlaintextVerifier) SetSecurity(params security.Params) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	bits := params.ChallengeBits(verifier.challengeLen)
+	if verifier.pubKey.GetN().BitLen() < 2*bits+2 {
+		return fmt.Errorf("Paillier modulus is too small for %d-bit challenges", bits)
+	}
+	verifier.challengeLen = bits
+	return nil
+}
+
+func (verifier *PaillierPlaintextVerifier) SetProofRandomData(a *big.Int) error {
+	if !isInvertibleModN2(verifier.pubKey, a) {
+		return fmt.Errorf("proof random data is not from Z_n^2*")
+	}
+	verifier.a = a
+	return nil
+}
+
+// GetChallenge returns a random challenge from [0, 2^ChallengeBitLength) (or of the bit
+// length set by SetSecurity).
+func (verifier *PaillierPlaintextVerifier) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(pow2(verifier.challengeLen))
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return challenge, nil
+}
+
+// It receives z = x + e*m mod n and w, and checks g^z * w^n = a * c^e mod n^2.
+func (verifier *PaillierPlaintextVerifier) Verify(z, w *big.Int) bool {
+	n := verifier.pubKey.GetN()
+	if z.Sign() < 0 || z.Cmp(n) >= 0 || !isInvertibleModN2(verifier.pubKey, w) {
+		return false
+	}
+	return verifyPaillierEquation(verifier.pubKey, verifier.c, verifier.a, verifier.challenge,
+		z, w)
+}
+
+// verifyPaillierEquation checks g^z * w^n = a * c^e mod n^2.
+func verifyPaillierEquation(pubKey *encryption.PaillierPubKey, c, a, e, z, w *big.Int) bool {
+	n := pubKey.GetN()
+	n2 := new(big.Int).Mul(n, n)
+	left := encrypt(pubKey, z, w)
+	right := new(big.Int).Exp(c, e, n2)
+	right.Mul(right, a)
+	right.Mod(right, n2)
+	return left.Cmp(right) == 0
+}
+
+// encrypt returns g^m * r^n mod n^2 (unlike encryption.Paillier.EncryptWithR it does not
+// require m < n).
+func encrypt(pubKey *encryption.PaillierPubKey, m, r *big.Int) *big.Int {
+	n := pubKey.GetN()
+	n2 := new(big.Int).Mul(n, n)
+	c := new(big.Int).Exp(pubKey.GetG(), m, n2)
+	c.Mul(c, new(big.Int).Exp(r, n, n2))
+	return c.Mod(c, n2)
+}
+
+func checkPubKey(pubKey *encryption.PaillierPubKey) error {
+	n, g := pubKey.GetN(), pubKey.GetG()
+	if n == nil || g == nil || n.BitLen() < 2*ChallengeBitLength+2 {
+		return fmt.Errorf("Paillier modulus is too small")
+	}
+	if !isInvertibleModN2(pubKey, g) {
+		return fmt.Errorf("Paillier generator is not from Z_n^2*")
+	}
+	return nil
+}
+
+func checkPlaintext(pubKey *encryption.PaillierPubKey, m, r *big.Int) error {
+	if err := checkPubKey(pubKey); err != nil {
+		return err
+	}
+	n := pubKey.GetN()
+	if m.Sign() < 0 || m.Cmp(n) >= 0 {
+		return fmt.Errorf("plaintext needs to be from Z_n")
+	}
+	if r.Sign() <= 0 || r.Cmp(n) >= 0 || new(big.Int).GCD(nil, nil, r, n).Cmp(big.NewInt(1)) != 0 {
+		return fmt.Errorf("randomness needs to be from Z_n*")
+	}
+	return nil
+}
+
+func isInvertibleModN2(pubKey *encryption.PaillierPubKey, x *big.Int) bool {
+	n := pubKey.GetN()
+	return isUnit(x, new(big.Int).Mul(n, n))
+}
+
+func pow2(bits int) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(bits))
+}
@@ -0,0 +1,242 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+)
+
+// StatisticalHidingBitLength determines how well the response in the committed value proof
+// hides the plaintext (statistical distance is about 2^(-StatisticalHidingBitLength)).
+const StatisticalHidingBitLength = 80
+
+// ProvePaillierCommittedPlaintext demonstrates how prover can prove that the Paillier
+// ciphertext c = g^m * r^n mod n^2 encrypts the value m committed in Pedersen commitment
+// C = G^m * H^t in the Schnorr group.
+func ProvePaillierCommittedPlaintext(pubKey *encryption.PaillierPubKey,
+	group *groups.SchnorrGroup, h, m, r, t *big.Int) (bool, error) {
+	c, err := encryption.NewPubPaillier(pubKey).EncryptWithR(m, r)
+	if err != nil {
+		return false, err
+	}
+	commitment := group.Mul(group.Exp(group.G, m), group.Exp(h, t))
+
+	prover, err := NewPaillierCommittedPlaintextProver(pubKey, group, h, m, r, t)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewPaillierCommittedPlaintextVerifier(pubKey, group, h, c, commitment)
+	if err != nil {
+		return false, err
+	}
+
+	a, commitmentA, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(a, commitmentA); err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	z, w, v := prover.GetProofData(challenge)
+	return verifier.Verify(z, w, v), nil
+}
+
+// PaillierCommittedPlaintextProver proves that c = g^m * r^n mod n^2 and C = G^m * H^t
+// for the same m. As the orders of both groups differ, the response for m is computed
+// in integers (the same as in dlogproofs.CrossGroupDLogEqualityProver):
+//   - prover chooses x from [0, 2^(|Q| + ChallengeBitLength + StatisticalHidingBitLength)),
+//     s from Z_n*, u from Z_Q and sends a = g^x * s^n mod n^2 and A = G^x * H^u mod P,
+//   - verifier sends challenge e,
+//   - prover sends z = x + e*m (in integers), w = s * r^e mod n^2 and v = u + e*t mod Q,
+//   - verifier checks that z is in the range above, g^z * w^n = a * c^e mod n^2 and
+//     G^z * H^v = A * C^e mod P.
+//
+// The Paillier modulus needs to be longer than the bound on z, so that the extracted m
+// is the same modulo n and modulo Q. The commitment is binding only if the prover does not
+// know log_G(H) (H is usually chosen by the verifier).
+type PaillierCommittedPlaintextProver struct {
+	pubKey *encryption.PaillierPubKey
+	group  *groups.SchnorrGroup
+	h      *big.Int
+	m      *big.Int
+	r      *big.Int
+	t      *big.Int
+	x      *big.Int
+	s      *big.Int
+	u      *big.Int
+}
+
+func NewPaillierCommittedPlaintextProver(pubKey *encryption.PaillierPubKey,
+	group *groups.SchnorrGroup, h, m, r, t *big.Int) (*PaillierCommittedPlaintextProver, error) {
+	if err := checkCommittedBound(pubKey, group); err != nil {
+		return nil, err
+	}
+	if err := checkPlaintext(pubKey, m, r); err != nil {
+		return nil, err
+	}
+	if m.Cmp(group.Q) >= 0 {
+		return nil, fmt.Errorf("plaintext needs to be from Z_Q")
+	}
+	return &PaillierCommittedPlaintextProver{
+		pubKey: pubKey,
+		group:  group,
+		h:      h,
+		m:      m,
+		r:      r,
+		t:      t,
+	}, nil
+}
+
+// GetProofRandomData returns a = g^x * s^n mod n^2 and A = G^x * H^u mod P.
+func (prover *PaillierCommittedPlaintextProver) GetProofRandomData() (*big.Int, *big.Int,
+	error) {
+	x, err := common.GetRandomInt(committedResponseBound(prover.group))
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := common.GetRandomZnInvertibleElement(prover.pubKey.GetN())
+	if err != nil {
+		return nil, nil, err
+	}
+	u, err := common.GetRandomInt(prover.group.Q)
+	if err != nil {
+		return nil, nil, err
+	}
+	prover.x, prover.s, prover.u = x, s, u
+
+	a := encrypt(prover.pubKey, x, s)
+	commitmentA := prover.group.Mul(prover.group.Exp(prover.group.G, x),
+		prover.group.Exp(prover.h, u))
+	return a, commitmentA, nil
+}
+
+// GetProofData returns z = x + e*m, w = s * r^e mod n^2 and v = u + e*t mod Q.
+func (prover *PaillierCommittedPlaintextProver) GetProofData(challenge *big.Int) (*big.Int,
+	*big.Int, *big.Int) {
+	n := prover.pubKey.GetN()
+	n2 := new(big.Int).Mul(n, n)
+
+	z := new(big.Int).Mul(challenge, prover.m)
+	z.Add(z, prover.x)
+	w := new(big.Int).Exp(prover.r, challenge, n2)
+	w.Mul(w, prover.s)
+	w.Mod(w, n2)
+	v := new(big.Int).Mul(challenge, prover.t)
+	v.Add(v, prover.u)
+	v.Mod(v, prover.group.Q)
+	return z, w, v
+}
+
+type PaillierCommittedPlaintextVerifier struct {
+	pubKey      *encryption.PaillierPubKey
+	group       *groups.SchnorrGroup
+	h           *big.Int
+	c           *big.Int
+	commitment  *big.Int
+	a           *big.Int
+	commitmentA *big.Int
+	challenge   *big.Int
+}
+
+// NewPaillierCommittedPlaintextVerifier returns a verifier of the proof that c encrypts
+// the value committed in commitment = G^m * H^t.
+func NewPaillierCommittedPlaintextVerifier(pubKey *encryption.PaillierPubKey,
+	group *groups.SchnorrGroup, h, c, commitment *big.Int) (*PaillierCommittedPlaintextVerifier,
+	error) {
+	if err := checkCommittedBound(pubKey, group); err != nil {
+		return nil, err
+	}
+	if !isInvertibleModN2(pubKey, c) {
+		return nil, fmt.Errorf("ciphertext is not from Z_n^2*")
+	}
+	if !group.IsElementInGroup(commitment) {
+		return nil, fmt.Errorf("commitment is not in the group")
+	}
+	return &PaillierCommittedPlaintextVerifier{
+		pubKey:     pubKey,
+		group:      group,
+		h:          h,
+		c:          c,
+		commitment: commitment,
+	}, nil
+}
+
+func (verifier *PaillierCommittedPlaintextVerifier) SetProofRandomData(a,
+	commitmentA *big.Int) error {
+	if !isInvertibleModN2(verifier.pubKey, a) {
+		return fmt.Errorf("proof random data is not from Z_n^2*")
+	}
+	if !verifier.group.IsElementInGroup(commitmentA) {
+		return fmt.Errorf("proof random data is not in the group")
+	}
+	verifier.a, verifier.commitmentA = a, commitmentA
+	return nil
+}
+
+// GetChallenge returns a random challenge from [0, 2^ChallengeBitLength).
+func (verifier *PaillierCommittedPlaintextVerifier) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(pow2(ChallengeBitLength))
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return challenge, nil
+}
+
+// It receives z = x + e*m, w = s * r^e and v = u + e*t, and checks g^z * w^n = a * c^e mod n^2
+// and G^z * H^v = A * C^e mod P.
+func (verifier *PaillierCommittedPlaintextVerifier) Verify(z, w, v *big.Int) bool {
+	bound := new(big.Int).Add(committedResponseBound(verifier.group),
+		new(big.Int).Mul(pow2(ChallengeBitLength), verifier.group.Q))
+	if z.Sign() < 0 || z.Cmp(bound) >= 0 || !isInvertibleModN2(verifier.pubKey, w) {
+		return false
+	}
+	if !verifyPaillierEquation(verifier.pubKey, verifier.c, verifier.a, verifier.challenge,
+		z, w) {
+		return false
+	}
+
+	group := verifier.group
+	left := group.Mul(group.Exp(group.G, z), group.Exp(verifier.h, v))
+	right := group.Mul(verifier.commitmentA, group.Exp(verifier.commitment, verifier.challenge))
+	return left.Cmp(right) == 0
+}
+
+// committedResponseBound returns 2^(|Q| + ChallengeBitLength + StatisticalHidingBitLength).
+func committedResponseBound(group *groups.SchnorrGroup) *big.Int {
+	return pow2(group.Q.BitLen() + ChallengeBitLength + StatisticalHidingBitLength)
+}
+
+func checkCommittedBound(pubKey *encryption.PaillierPubKey, group *groups.SchnorrGroup) error {
+	if err := checkPubKey(pubKey); err != nil {
+		return err
+	}
+	if pubKey.GetN().BitLen() <= group.Q.BitLen()+ChallengeBitLength+
+		StatisticalHidingBitLength+1 {
+		return fmt.Errorf("Paillier modulus is too small for the order of the group")
+	}
+	return nil
+}
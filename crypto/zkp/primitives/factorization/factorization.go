@@ -0,0 +1,212 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package factorization
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"math/big"
+)
+
+// SecurityBits is the bit length of the challenge in the proof of knowledge of
+// the factorization.
+const SecurityBits = 80
+
+// statisticalBits determines how well the response hides N - phi(N).
+const statisticalBits = 80
+
+// numBases is the number of bases z_i.
+const numBases = 2
+
+// ProveFactorizationKnowledge demonstrates how prover can prove that it knows
+// the factorization n = p * q.
+func ProveFactorizationKnowledge(n, p, q *big.Int) (bool, error) {
+	prover, err := NewFactorizationProver(n, p, q)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewFactorizationVerifier(n)
+	if err != nil {
+		return false, err
+	}
+
+	x, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge(x)
+	if err != nil {
+		return false, err
+	}
+	y, err := prover.GetProofData(challenge)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(y), nil
+}
+
+// FactorizationProver proves the knowledge of the factorization of RSA modulus n
+// (Poupard, Stern: Short proofs of knowledge for factoring). The bases z_i are derived
+// from n by hashing, so that the prover cannot choose them:
+//   - prover chooses r from [0, A) and sends x_i = z_i^r mod n,
+//   - verifier sends challenge e from [0, 2^SecurityBits),
+//   - prover sends y = r + (n - phi(n)) * e (computed in integers),
+//   - verifier checks y < A and z_i^(y - n * e) = x_i mod n.
+//
+// Two accepting responses for different challenges give a multiple of the order of z_i,
+// which reveals the factorization of n. A is chosen to be 2^statisticalBits times bigger
+// than (n - phi(n)) * 2^SecurityBits, so y does not leak n - phi(n) = p + q - 1. Note that
+// this requires p and q to be of about the same length (like in RSA moduli).
+type FactorizationProver struct {
+	n       *big.Int
+	nMinPhi *big.Int
+	bases   []*big.Int
+	bound   *big.Int
+	r       *big.Int
+}
+
+func NewFactorizationProver(n, p, q *big.Int) (*FactorizationProver, error) {
+	if new(big.Int).Mul(p, q).Cmp(n) != 0 {
+		return nil, fmt.Errorf("p * q is not n")
+	}
+	bound := responseBound(n)
+	// n - phi(n) = p + q - 1
+	nMinPhi := new(big.Int).Add(p, q)
+	nMinPhi.Sub(nMinPhi, big.NewInt(1))
+	if nMinPhi.BitLen() > (n.BitLen()+1)/2+2 {
+		return nil, fmt.Errorf("factors of n are too unbalanced")
+	}
+	return &FactorizationProver{
+		n:       n,
+		nMinPhi: nMinPhi,
+		bases:   factorizationBases(n),
+		bound:   bound,
+	}, nil
+}
+
+// GetProofRandomData returns x_i = z_i^r mod n.
+func (prover *FactorizationProver) GetProofRandomData() ([]*big.Int, error) {
+	r, err := common.GetRandomInt(prover.bound)
+	if err != nil {
+		return nil, err
+	}
+	prover.r = r
+	x := make([]*big.Int, len(prover.bases))
+	for i, z := range prover.bases {
+		x[i] = new(big.Int).Exp(z, r, prover.n)
+	}
+	return x, nil
+}
+
+// GetProofData returns y = r + (n - phi(n)) * challenge.
+func (prover *FactorizationProver) GetProofData(challenge *big.Int) (*big.Int, error) {
+	if prover.r == nil {
+		return nil, fmt.Errorf("proof random data has not been generated")
+	}
+	if challenge.Sign() < 0 || challenge.BitLen() > SecurityBits {
+		return nil, fmt.Errorf("challenge is out of range")
+	}
+	y := new(big.Int).Mul(prover.nMinPhi, challenge)
+	y.Add(y, prover.r)
+	prover.r = nil
+	return y, nil
+}
+
+type FactorizationVerifier struct {
+	n         *big.Int
+	bases     []*big.Int
+	bound     *big.Int
+	x         []*big.Int
+	challenge *big.Int
+}
+
+// NewFactorizationVerifier returns a verifier for modulus n. It fails if n is obviously
+// not an RSA modulus (it is even or shares a factor with one of the bases).
+func NewFactorizationVerifier(n *big.Int) (*FactorizationVerifier, error) {
+	if n.Sign() <= 0 || n.Bit(0) == 0 {
+		return nil, fmt.Errorf("n needs to be odd and positive")
+	}
+	bases := factorizationBases(n)
+	for _, z := range bases {
+		if new(big.Int).GCD(nil, nil, z, n).Cmp(big.NewInt(1)) != 0 {
+			return nil, fmt.Errorf("n is not an RSA modulus")
+		}
+	}
+	return &FactorizationVerifier{
+		n:     n,
+		bases: bases,
+		bound: responseBound(n),
+	}, nil
+}
+
+// GetChallenge stores x and returns a random challenge from [0, 2^SecurityBits).
+func (verifier *FactorizationVerifier) GetChallenge(x []*big.Int) (*big.Int, error) {
+	challenge, err := common.GetRandomIntOfLength(SecurityBits)
+	if err != nil {
+		return nil, err
+	}
+	verifier.x = x
+	verifier.challenge = challenge
+	return verifier.challenge, nil
+}
+
+// Verify checks 0 <= y < A and z_i^(y - n * e) = x_i mod n.
+func (verifier *FactorizationVerifier) Verify(y *big.Int) bool {
+	if len(verifier.x) != len(verifier.bases) || y.Sign() < 0 || y.Cmp(verifier.bound) >= 0 {
+		return false
+	}
+	exp := new(big.Int).Mul(verifier.n, verifier.challenge)
+	exp.Sub(y, exp)
+	for i, z := range verifier.bases {
+		if common.Exponentiate(z, exp, verifier.n).Cmp(verifier.x[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// responseBound returns A = 2^(|n|/2 + 2 + SecurityBits + statisticalBits).
+func responseBound(n *big.Int) *big.Int {
+	bits := (n.BitLen()+1)/2 + 2 + SecurityBits + statisticalBits
+	return new(big.Int).Lsh(big.NewInt(1), uint(bits))
+}
+
+// factorizationBases maps n to numBases elements of Z_n: z_i is SHA-512 of (n, i, counter)
+// for as many counters as needed to get |n| + 128 bits, reduced modulo n.
+func factorizationBases(n *big.Int) []*big.Int {
+	bases := make([]*big.Int, numBases)
+	nBytes := n.Bytes()
+	for i := range bases {
+		var digest []byte
+		for counter := uint32(0); len(digest)*8 < n.BitLen()+128; counter++ {
+			h := sha512.New()
+			h.Write([]byte("emmy/factorization"))
+			h.Write(nBytes)
+			b := make([]byte, 8)
+			binary.BigEndian.PutUint32(b, uint32(i))
+			binary.BigEndian.PutUint32(b[4:], counter)
+			h.Write(b)
+			digest = h.Sum(digest)
+		}
+		bases[i] = new(big.Int).SetBytes(digest)
+		bases[i].Mod(bases[i], n)
+	}
+	return bases
+}
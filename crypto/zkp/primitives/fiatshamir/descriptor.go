@@ -0,0 +1,184 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package fiatshamir
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+)
+
+// Object identifiers of the groups in the descriptors. Elliptic curves have their standard
+// identifiers (RFC 5480), other groups are identified under the emmy arc (see package der)
+// and determined by their parameters.
+const (
+	OIDSchnorrGroup = "2.25.102764937083622588717371394607346474509.3.1"
+	OIDQRGroup      = "2.25.102764937083622588717371394607346474509.3.2" // QR_N, N is RSA modulus
+	OIDP224         = "1.3.132.0.33"
+	OIDP256         = "1.2.840.10045.3.1.7"
+	OIDP384         = "1.3.132.0.34"
+	OIDP521         = "1.3.132.0.35"
+)
+
+// Descriptor is a canonical description of the statement of a non-interactive proof:
+//
+//	StatementDescriptor ::= SEQUENCE {
+//		scheme        PrintableString,
+//		groups        SEQUENCE OF PrintableString,  -- object identifiers in dotted form
+//		generators    SEQUENCE OF OCTET STRING,     -- SHA-256 of the parameters of each group
+//		publicInputs  SEQUENCE OF INTEGER
+//	}
+//
+// It is attached to each proof and the verifier checks that it matches the statement which
+// the verifier expects. The descriptor is also hashed into the challenge, thus a proof
+// cannot be presented for a statement with the same public values in another group or with
+// other generators (for example a proof from another emmy deployment).
+type Descriptor struct {
+	Scheme       string
+	Groups       []string
+	Generators   [][]byte
+	PublicInputs []*big.Int
+}
+
+// Group describes a group in which a statement is defined.
+type Group struct {
+	OID string
+	// Params are the public values which determine the group (for example the modulus,
+	// the order and the generators).
+	Params []*big.Int
+}
+
+// Describer is implemented by the protocols which specify the groups of their statements.
+// Protocols which do not implement it are described only by their name and statement.
+type Describer interface {
+	Groups() []Group
+}
+
+// Describe returns the descriptor of the statement of the protocol.
+func Describe(p Protocol) *Descriptor {
+	d := &Descriptor{
+		Scheme:       p.Name(),
+		Groups:       []string{},
+		Generators:   [][]byte{},
+		PublicInputs: p.Statement(),
+	}
+	if describer, ok := p.(Describer); ok {
+		for _, g := range describer.Groups() {
+			d.Groups = append(d.Groups, g.OID)
+			d.Generators = append(d.Generators, g.hash())
+		}
+	}
+	return d
+}
+
+// CheckDescriptor returns an error describing the difference if the proof was not
+// produced for the statement of protocol p.
+func CheckDescriptor(p Protocol, proof *Proof) error {
+	expected := Describe(p)
+	d := proof.Descriptor
+	if d.Scheme != expected.Scheme {
+		return fmt.Errorf("proof of %s, expected %s", d.Scheme, expected.Scheme)
+	}
+	if len(d.Groups) != len(expected.Groups) || len(d.Generators) != len(expected.Groups) {
+		return fmt.Errorf("proof in %d groups, expected %d", len(d.Groups),
+			len(expected.Groups))
+	}
+	for i, oid := range expected.Groups {
+		if d.Groups[i] != oid {
+			return fmt.Errorf("proof in group %s, expected %s", d.Groups[i], oid)
+		}
+		if !bytes.Equal(d.Generators[i], expected.Generators[i]) {
+			return fmt.Errorf("proof with other parameters of group %s", oid)
+		}
+	}
+	if len(d.PublicInputs) != len(expected.PublicInputs) {
+		return fmt.Errorf("proof with %d public inputs, expected %d", len(d.PublicInputs),
+			len(expected.PublicInputs))
+	}
+	for i, v := range expected.PublicInputs {
+		if d.PublicInputs[i] == nil || d.PublicInputs[i].Cmp(v) != 0 {
+			return fmt.Errorf("public input %d of the proof differs", i)
+		}
+	}
+	return nil
+}
+
+// SchnorrGroup describes the Schnorr group.
+func SchnorrGroup(group *groups.SchnorrGroup) Group {
+	return Group{
+		OID:    OIDSchnorrGroup,
+		Params: []*big.Int{group.P, group.Q, group.G},
+	}
+}
+
+// CurveGroup describes the group of the elliptic curve.
+func CurveGroup(curve dlog.Curve) Group {
+	params := dlog.GetEllipticCurve(curve).Params()
+	return Group{
+		OID:    curveOIDs[params.Name],
+		Params: []*big.Int{params.P, params.N, params.B, params.Gx, params.Gy},
+	}
+}
+
+// QRGroup describes the group of quadratic residues modulo N in which Damgard-Fujisaki
+// commitments are computed.
+func QRGroup(params *commitments.DamgardFujisakiParams) Group {
+	return Group{
+		OID:    OIDQRGroup,
+		Params: []*big.Int{params.N, params.G, params.H},
+	}
+}
+
+// PrimeOrderGroup describes the group given by its backend. Backends other than Schnorr
+// groups and the supported elliptic curves have no object identifier, they are determined
+// only by their parameters.
+func PrimeOrderGroup(group groups.PrimeOrderGroup) Group {
+	oid := curveOIDs[group.Name()]
+	if group.Name() == "Schnorr" {
+		oid = OIDSchnorrGroup
+	}
+	params := append(append([]*big.Int{}, group.Params()...), group.Order())
+	return Group{
+		OID:    oid,
+		Params: append(params, group.Generator().Ints()...),
+	}
+}
+
+var curveOIDs = map[string]string{
+	"P-224": OIDP224,
+	"P-256": OIDP256,
+	"P-384": OIDP384,
+	"P-521": OIDP521,
+}
+
+// hash returns SHA-256 of the group parameters, each prefixed with its length.
+func (g Group) hash() []byte {
+	h := sha256.New()
+	l := make([]byte, 8)
+	for _, p := range g.Params {
+		binary.BigEndian.PutUint64(l, uint64(len(p.Bytes())))
+		h.Write(l)
+		h.Write(p.Bytes())
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,220 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package fiatshamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/asn1"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// envelopeNonceLength is the length of the nonces generated by NewEnvelope, shorter nonces
+// are rejected by VerifyEnvelope.
+const envelopeNonceLength = 16
+
+// Envelope wraps a non-interactive proof together with its metadata - the time when
+// it was issued, the time when it expires, the verifier which it is intended for (audience)
+// and a nonce. The metadata is bound to the proof (it is hashed into the challenge), thus
+// it cannot be changed without invalidating the proof. Times are in Unix seconds.
+type Envelope struct {
+	Audience string
+	IssuedAt int64
+	Expires  int64
+	Nonce    []byte
+	Proof    Proof
+}
+
+// NewEnvelope produces the proof in an envelope for the given audience, valid for ttl.
+// Nonce can be obtained from the verifier (for challenge-response freshness), when it is nil
+// a random one is generated. Context is bound to the proof as in Prove.
+func NewEnvelope(prover Prover, audience string, ttl time.Duration, nonce,
+	context []byte) (*Envelope, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("ttl needs to be positive")
+	}
+	if nonce == nil {
+		nonce = make([]byte, envelopeNonceLength)
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, fmt.Errorf("error generating nonce: %v", err)
+		}
+	}
+
+	now := time.Now()
+	e := &Envelope{
+		Audience: audience,
+		IssuedAt: now.Unix(),
+		Expires:  now.Add(ttl).Unix(),
+		Nonce:    nonce,
+	}
+	proof, err := Prove(prover, e.context(context))
+	if err != nil {
+		return nil, err
+	}
+	e.Proof = *proof
+	return e, nil
+}
+
+// context returns the context of the enveloped proof - the hash of the metadata and
+// the context of the application, each prefixed with its length.
+func (e *Envelope) context(context []byte) []byte {
+	h := sha512.New()
+	write := func(b []byte) {
+		l := make([]byte, 8)
+		binary.BigEndian.PutUint64(l, uint64(len(b)))
+		h.Write(l)
+		h.Write(b)
+	}
+
+	times := make([]byte, 16)
+	binary.BigEndian.PutUint64(times, uint64(e.IssuedAt))
+	binary.BigEndian.PutUint64(times[8:], uint64(e.Expires))
+	write([]byte("emmy/envelope"))
+	write([]byte(e.Audience))
+	write(times)
+	write(e.Nonce)
+	write(context)
+	return h.Sum(nil)
+}
+
+// Marshal encodes the envelope (ASN.1 DER), so that it can be stored or sent as a blob.
+func (e *Envelope) Marshal() ([]byte, error) {
+	return asn1.Marshal(*e)
+}
+
+// UnmarshalEnvelope decodes the envelope encoded by Marshal.
+func UnmarshalEnvelope(data []byte) (*Envelope, error) {
+	e := new(Envelope)
+	if _, err := asn1.Unmarshal(data, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// EnvelopePolicy contains the checks of the envelope metadata which are performed by
+// VerifyEnvelope.
+type EnvelopePolicy struct {
+	// Audience is the identifier of the verifier, envelopes for other audiences are rejected.
+	Audience string
+	// Context is the context of the application which the proof was bound to.
+	Context []byte
+	// Nonce is the nonce which the verifier issued to the prover, when set the envelope
+	// needs to carry the same nonce.
+	Nonce []byte
+	// Nonces records the nonces of the accepted envelopes until they expire, so that
+	// the envelopes cannot be replayed. When nil, replays are not detected.
+	Nonces *NonceCache
+	// MaxLifetime bounds the validity period of the envelopes (Expires - IssuedAt),
+	// 0 means no bound.
+	MaxLifetime time.Duration
+	// ClockSkew is the tolerated difference between the clocks of the prover and
+	// the verifier.
+	ClockSkew time.Duration
+	// Now returns the current time, time.Now is used when it is nil.
+	Now func() time.Time
+}
+
+// VerifyEnvelope checks the metadata of the envelope against the policy and the enveloped
+// proof, it returns nil if the envelope is accepted. The nonce is recorded in
+// the policy's NonceCache only when all the other checks pass.
+func VerifyEnvelope(verifier Verifier, e *Envelope, policy *EnvelopePolicy) error {
+	if e == nil {
+		return fmt.Errorf("envelope is missing")
+	}
+	if e.Audience != policy.Audience {
+		return fmt.Errorf("envelope is for audience %q", e.Audience)
+	}
+
+	now := time.Now()
+	if policy.Now != nil {
+		now = policy.Now()
+	}
+	issuedAt := time.Unix(e.IssuedAt, 0)
+	expires := time.Unix(e.Expires, 0)
+	if !expires.After(issuedAt) {
+		return fmt.Errorf("envelope expires before it is issued")
+	}
+	if issuedAt.After(now.Add(policy.ClockSkew)) {
+		return fmt.Errorf("envelope is issued in the future")
+	}
+	if !expires.After(now.Add(-policy.ClockSkew)) {
+		return fmt.Errorf("envelope expired")
+	}
+	if policy.MaxLifetime > 0 && expires.Sub(issuedAt) > policy.MaxLifetime {
+		return fmt.Errorf("envelope lifetime exceeds %v", policy.MaxLifetime)
+	}
+
+	if len(e.Nonce) < envelopeNonceLength {
+		return fmt.Errorf("envelope nonce is too short")
+	}
+	if policy.Nonce != nil && !bytes.Equal(e.Nonce, policy.Nonce) {
+		return fmt.Errorf("envelope nonce does not match")
+	}
+
+	if !Verify(verifier, &e.Proof, e.context(policy.Context)) {
+		return fmt.Errorf("proof is not valid")
+	}
+	if policy.Nonces != nil && !policy.Nonces.Use(e.Nonce, expires.Add(policy.ClockSkew), now) {
+		return fmt.Errorf("envelope was already used")
+	}
+	return nil
+}
+
+// VerifyEncodedEnvelope checks the envelope encoded with Envelope.Marshal.
+func VerifyEncodedEnvelope(verifier Verifier, envelope []byte, policy *EnvelopePolicy) error {
+	e, err := UnmarshalEnvelope(envelope)
+	if err != nil {
+		return fmt.Errorf("envelope cannot be decoded: %v", err)
+	}
+	return VerifyEnvelope(verifier, e, policy)
+}
+
+// NonceCache holds the nonces of the accepted envelopes until they expire. It can be shared
+// between goroutines.
+type NonceCache struct {
+	sync.Mutex
+	nonces map[string]time.Time
+}
+
+func NewNonceCache() *NonceCache {
+	return &NonceCache{
+		nonces: make(map[string]time.Time),
+	}
+}
+
+// Use records the nonce until expires and returns true, or returns false if the nonce
+// is already recorded. Expired nonces (with respect to now) are removed from the cache.
+func (c *NonceCache) Use(nonce []byte, expires, now time.Time) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	for n, exp := range c.nonces {
+		if !exp.After(now) {
+			delete(c.nonces, n)
+		}
+	}
+	if _, ok := c.nonces[string(nonce)]; ok {
+		return false
+	}
+	c.nonces[string(nonce)] = expires
+	return true
+}
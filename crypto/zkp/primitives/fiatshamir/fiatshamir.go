@@ -0,0 +1,141 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package fiatshamir turns interactive sigma protocols into non-interactive proofs by
+// deriving the challenge from the hash of the statement and the proof random data
+// (Fiat-Shamir heuristic). Such proofs can be stored and verified offline, without
+// the client-server communication.
+package fiatshamir
+
+import (
+	"crypto/sha512"
+	"encoding/asn1"
+	"encoding/binary"
+	"math/big"
+)
+
+// Protocol is a sigma protocol with a fixed statement.
+type Protocol interface {
+	// Name identifies the protocol - it is hashed into the challenge, so that a proof
+	// of one protocol cannot be passed as a proof of another one.
+	Name() string
+	// Statement returns the public values which the proof is about.
+	Statement() []*big.Int
+	// ChallengeSpace returns the bound for the challenges, which are from [0, ChallengeSpace).
+	ChallengeSpace() *big.Int
+}
+
+type Prover interface {
+	Protocol
+	GetProofRandomData() ([]*big.Int, error)
+	GetProofData(challenge *big.Int) ([]*big.Int, error)
+}
+
+type Verifier interface {
+	Protocol
+	Verify(proofRandomData []*big.Int, challenge *big.Int, proofData []*big.Int) bool
+}
+
+// Simulator is implemented by the provers and verifiers of the protocols which can produce
+// accepting transcripts for a given challenge without the witness (as sigma.Protocol).
+type Simulator interface {
+	Protocol
+	Simulate(challenge *big.Int) (proofRandomData []*big.Int, proofData []*big.Int, err error)
+}
+
+// Proof is a non-interactive proof - the challenge is not included as it is recomputed
+// by the verifier. Descriptor describes the statement which was proved.
+type Proof struct {
+	Descriptor      Descriptor
+	ProofRandomData []*big.Int
+	ProofData       []*big.Int
+}
+
+// Prove produces a non-interactive proof. Context (for example the verifier's name and
+// a nonce) is bound to the proof, so that the proof is not valid in any other context.
+func Prove(prover Prover, context []byte) (*Proof, error) {
+	proofRandomData, err := prover.GetProofRandomData()
+	if err != nil {
+		return nil, err
+	}
+	challenge := GetChallenge(prover, proofRandomData, context)
+	proofData, err := prover.GetProofData(challenge)
+	if err != nil {
+		return nil, err
+	}
+	return &Proof{
+		Descriptor:      *Describe(prover),
+		ProofRandomData: proofRandomData,
+		ProofData:       proofData,
+	}, nil
+}
+
+// Verify checks the non-interactive proof which was produced in the given context. Proofs
+// whose descriptor does not match the statement of the verifier are rejected (see
+// CheckDescriptor).
+func Verify(verifier Verifier, proof *Proof, context []byte) bool {
+	if proof == nil || CheckDescriptor(verifier, proof) != nil {
+		return false
+	}
+	challenge := GetChallenge(verifier, proof.ProofRandomData, context)
+	return verifier.Verify(proof.ProofRandomData, challenge, proof.ProofData)
+}
+
+// GetChallenge returns the hash of the protocol name, context, groups, statement and proof
+// random data, reduced into the challenge space. Each value is prefixed with its length, thus
+// different inputs cannot produce the same hashed string.
+func GetChallenge(p Protocol, proofRandomData []*big.Int, context []byte) *big.Int {
+	h := sha512.New()
+	write := func(b []byte) {
+		l := make([]byte, 8)
+		binary.BigEndian.PutUint64(l, uint64(len(b)))
+		h.Write(l)
+		h.Write(b)
+	}
+
+	d := Describe(p)
+	write([]byte(d.Scheme))
+	write(context)
+	write([]byte{byte(len(d.Groups))})
+	for i, oid := range d.Groups {
+		write([]byte(oid))
+		write(d.Generators[i])
+	}
+	for _, values := range [][]*big.Int{d.PublicInputs, proofRandomData} {
+		write([]byte{byte(len(values))})
+		for _, v := range values {
+			write(v.Bytes())
+		}
+	}
+
+	challenge := new(big.Int).SetBytes(h.Sum(nil))
+	return challenge.Mod(challenge, p.ChallengeSpace())
+}
+
+// Marshal encodes the proof (ASN.1 DER), so that it can be stored or sent as a blob.
+func (proof *Proof) Marshal() ([]byte, error) {
+	return asn1.Marshal(*proof)
+}
+
+// UnmarshalProof decodes the proof encoded by Marshal.
+func UnmarshalProof(data []byte) (*Proof, error) {
+	proof := new(Proof)
+	if _, err := asn1.Unmarshal(data, proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
@@ -0,0 +1,514 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package fiatshamir
+
+import (
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// Adapters of the sigma protocols from dlogproofs to Prover and Verifier.
+
+// schnorr proves the knowledge of log_a(b).
+type schnorr struct {
+	group    *groups.SchnorrGroup
+	a        *big.Int
+	b        *big.Int
+	secret   *big.Int
+	prover   *dlogproofs.SchnorrProver
+	verifier *dlogproofs.SchnorrVerifier
+}
+
+func NewSchnorrProver(group *groups.SchnorrGroup, secret, a, b *big.Int) Prover {
+	prover, _ := dlogproofs.NewSchnorrProver(group)
+	return &schnorr{
+		group:  group,
+		a:      a,
+		b:      b,
+		secret: secret,
+		prover: prover,
+	}
+}
+
+func NewSchnorrVerifier(group *groups.SchnorrGroup, a, b *big.Int) Verifier {
+	verifier, _ := dlogproofs.NewSchnorrVerifier(group)
+	return &schnorr{
+		group:    group,
+		a:        a,
+		b:        b,
+		verifier: verifier,
+	}
+}
+
+func (p *schnorr) Name() string             { return "Schnorr" }
+func (p *schnorr) Statement() []*big.Int    { return []*big.Int{p.a, p.b} }
+func (p *schnorr) ChallengeSpace() *big.Int { return p.group.Q }
+func (p *schnorr) Groups() []Group          { return []Group{SchnorrGroup(p.group)} }
+
+func (p *schnorr) GetProofRandomData() ([]*big.Int, error) {
+	x, err := p.prover.GetProofRandomData(p.secret, p.a)
+	if err != nil {
+		return nil, err
+	}
+	return []*big.Int{x}, nil
+}
+
+func (p *schnorr) GetProofData(challenge *big.Int) ([]*big.Int, error) {
+	z, _, err := p.prover.GetProofData(challenge)
+	if err != nil {
+		return nil, err
+	}
+	return []*big.Int{z}, nil
+}
+
+func (p *schnorr) Verify(proofRandomData []*big.Int, challenge *big.Int,
+	proofData []*big.Int) bool {
+	if len(proofRandomData) != 1 || len(proofData) != 1 {
+		return false
+	}
+	p.verifier.SetProofRandomData(proofRandomData[0], p.a, p.b)
+	p.verifier.SetChallenge(challenge)
+	return p.verifier.Verify(proofData[0])
+}
+
+func (p *schnorr) Simulate(challenge *big.Int) ([]*big.Int, []*big.Int, error) {
+	x, z, err := dlogproofs.SimulateSchnorr(p.group, p.a, p.b, challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []*big.Int{x}, []*big.Int{z}, nil
+}
+
+// schnorrEC proves the knowledge of log_a(b) in the elliptic curve group.
+type schnorrEC struct {
+	curve    dlog.Curve
+	a        *types.ECGroupElement
+	b        *types.ECGroupElement
+	secret   *big.Int
+	prover   *dlogproofs.SchnorrECProver
+	verifier *dlogproofs.SchnorrECVerifier
+}
+
+func NewSchnorrECProver(curve dlog.Curve, secret *big.Int, a, b *types.ECGroupElement) Prover {
+	prover, _ := dlogproofs.NewSchnorrECProver(dlogproofs.WithCurve(curve))
+	return &schnorrEC{
+		curve:  curve,
+		a:      a,
+		b:      b,
+		secret: secret,
+		prover: prover,
+	}
+}
+
+func NewSchnorrECVerifier(curve dlog.Curve, a, b *types.ECGroupElement) Verifier {
+	verifier, _ := dlogproofs.NewSchnorrECVerifier(dlogproofs.WithCurve(curve))
+	return &schnorrEC{
+		curve:    curve,
+		a:        a,
+		b:        b,
+		verifier: verifier,
+	}
+}
+
+func (p *schnorrEC) Name() string { return "SchnorrEC" }
+
+func (p *schnorrEC) Statement() []*big.Int {
+	return []*big.Int{big.NewInt(int64(p.curve)), p.a.X, p.a.Y, p.b.X, p.b.Y}
+}
+
+func (p *schnorrEC) ChallengeSpace() *big.Int {
+	return dlog.GetEllipticCurve(p.curve).Params().N
+}
+
+func (p *schnorrEC) Groups() []Group { return []Group{CurveGroup(p.curve)} }
+
+func (p *schnorrEC) GetProofRandomData() ([]*big.Int, error) {
+	x, err := p.prover.GetProofRandomData(p.secret, p.a)
+	if err != nil {
+		return nil, err
+	}
+	return []*big.Int{x.X, x.Y}, nil
+}
+
+func (p *schnorrEC) GetProofData(challenge *big.Int) ([]*big.Int, error) {
+	z, _, err := p.prover.GetProofData(challenge)
+	if err != nil {
+		return nil, err
+	}
+	return []*big.Int{z}, nil
+}
+
+func (p *schnorrEC) Verify(proofRandomData []*big.Int, challenge *big.Int,
+	proofData []*big.Int) bool {
+	if len(proofRandomData) != 2 || len(proofData) != 1 {
+		return false
+	}
+	// operations with the points which are not on the curve might panic
+	c := dlog.GetEllipticCurve(p.curve)
+	if !c.IsOnCurve(proofRandomData[0], proofRandomData[1]) ||
+		!c.IsOnCurve(p.a.X, p.a.Y) || !c.IsOnCurve(p.b.X, p.b.Y) {
+		return false
+	}
+	x := types.NewECGroupElement(proofRandomData[0], proofRandomData[1])
+	p.verifier.SetProofRandomData(x, p.a, p.b)
+	p.verifier.SetChallenge(challenge)
+	return p.verifier.Verify(proofData[0])
+}
+
+func (p *schnorrEC) Simulate(challenge *big.Int) ([]*big.Int, []*big.Int, error) {
+	x, z, err := dlogproofs.SimulateECSchnorr(dlog.NewECDLog(p.curve), p.a, p.b, challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []*big.Int{x.X, x.Y}, []*big.Int{z}, nil
+}
+
+// dlogEquality proves the knowledge of log_g1(t1) = log_g2(t2).
+type dlogEquality struct {
+	group    *groups.SchnorrGroup
+	g1       *big.Int
+	g2       *big.Int
+	t1       *big.Int
+	t2       *big.Int
+	secret   *big.Int
+	prover   *dlogproofs.DLogEqualityProver
+	verifier *dlogproofs.DLogEqualityVerifier
+}
+
+func NewDLogEqualityProver(group *groups.SchnorrGroup, secret, g1, g2, t1, t2 *big.Int) Prover {
+	return &dlogEquality{
+		group:  group,
+		g1:     g1,
+		g2:     g2,
+		t1:     t1,
+		t2:     t2,
+		secret: secret,
+		prover: dlogproofs.NewDLogEqualityProver(group),
+	}
+}
+
+func NewDLogEqualityVerifier(group *groups.SchnorrGroup, g1, g2, t1, t2 *big.Int) Verifier {
+	return &dlogEquality{
+		group:    group,
+		g1:       g1,
+		g2:       g2,
+		t1:       t1,
+		t2:       t2,
+		verifier: dlogproofs.NewDLogEqualityVerifier(group),
+	}
+}
+
+func (p *dlogEquality) Name() string             { return "DLogEquality" }
+func (p *dlogEquality) Statement() []*big.Int    { return []*big.Int{p.g1, p.g2, p.t1, p.t2} }
+func (p *dlogEquality) ChallengeSpace() *big.Int { return p.group.Q }
+func (p *dlogEquality) Groups() []Group          { return []Group{SchnorrGroup(p.group)} }
+
+func (p *dlogEquality) GetProofRandomData() ([]*big.Int, error) {
+	x1, x2, err := p.prover.GetProofRandomData(p.secret, p.g1, p.g2)
+	if err != nil {
+		return nil, err
+	}
+	return []*big.Int{x1, x2}, nil
+}
+
+func (p *dlogEquality) GetProofData(challenge *big.Int) ([]*big.Int, error) {
+	z, err := p.prover.GetProofData(challenge)
+	if err != nil {
+		return nil, err
+	}
+	return []*big.Int{z}, nil
+}
+
+func (p *dlogEquality) Verify(proofRandomData []*big.Int, challenge *big.Int,
+	proofData []*big.Int) bool {
+	if len(proofRandomData) != 2 || len(proofData) != 1 {
+		return false
+	}
+	p.verifier.SetProofRandomData(p.g1, p.g2, p.t1, p.t2, proofRandomData[0],
+		proofRandomData[1])
+	p.verifier.SetChallenge(challenge)
+	return p.verifier.Verify(proofData[0])
+}
+
+func (p *dlogEquality) Simulate(challenge *big.Int) ([]*big.Int, []*big.Int, error) {
+	x1, x2, z, err := dlogproofs.SimulateDLogEquality(p.group, p.g1, p.g2, p.t1, p.t2, challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []*big.Int{x1, x2}, []*big.Int{z}, nil
+}
+
+// partialDLog proves the knowledge of log_a1(b1) or log_a2(b2) (prover knows log_a1(b1)).
+type partialDLog struct {
+	group    *groups.SchnorrGroup
+	a1       *big.Int
+	b1       *big.Int
+	a2       *big.Int
+	b2       *big.Int
+	secret   *big.Int
+	prover   *dlogproofs.PartialDLogProver
+	verifier *dlogproofs.PartialDLogVerifier
+}
+
+func NewPartialDLogProver(group *groups.SchnorrGroup, secret1, a1, b1, a2, b2 *big.Int) Prover {
+	return &partialDLog{
+		group:  group,
+		a1:     a1,
+		b1:     b1,
+		a2:     a2,
+		b2:     b2,
+		secret: secret1,
+		prover: dlogproofs.NewPartialDLogProver(group),
+	}
+}
+
+func NewPartialDLogVerifier(group *groups.SchnorrGroup, a1, b1, a2, b2 *big.Int) Verifier {
+	return &partialDLog{
+		group:    group,
+		a1:       a1,
+		b1:       b1,
+		a2:       a2,
+		b2:       b2,
+		verifier: dlogproofs.NewPartialDLogVerifier(group),
+	}
+}
+
+func (p *partialDLog) Name() string { return "PartialDLog" }
+
+// Statement does not reveal which of the two dlogs the prover knows - the pairs are
+// sorted.
+func (p *partialDLog) Statement() []*big.Int {
+	if p.a1.Cmp(p.a2) < 0 || (p.a1.Cmp(p.a2) == 0 && p.b1.Cmp(p.b2) <= 0) {
+		return []*big.Int{p.a1, p.b1, p.a2, p.b2}
+	}
+	return []*big.Int{p.a2, p.b2, p.a1, p.b1}
+}
+
+func (p *partialDLog) ChallengeSpace() *big.Int { return p.group.Q }
+
+func (p *partialDLog) Groups() []Group { return []Group{SchnorrGroup(p.group)} }
+
+func (p *partialDLog) GetProofRandomData() ([]*big.Int, error) {
+	t1, t2, err := p.prover.GetProofRandomData(p.secret, p.a1, p.b1, p.a2, p.b2)
+	if err != nil {
+		return nil, err
+	}
+	return []*big.Int{t1.A, t1.B, t1.C, t2.A, t2.B, t2.C}, nil
+}
+
+func (p *partialDLog) GetProofData(challenge *big.Int) ([]*big.Int, error) {
+	c1, z1, c2, z2, err := p.prover.GetProofData(challenge)
+	if err != nil {
+		return nil, err
+	}
+	return []*big.Int{c1, z1, c2, z2}, nil
+}
+
+func (p *partialDLog) Verify(proofRandomData []*big.Int, challenge *big.Int,
+	proofData []*big.Int) bool {
+	if len(proofRandomData) != 6 || len(proofData) != 4 {
+		return false
+	}
+	t1 := types.NewTriple(proofRandomData[0], proofRandomData[1], proofRandomData[2])
+	t2 := types.NewTriple(proofRandomData[3], proofRandomData[4], proofRandomData[5])
+	// the triples need to be about the statement (in any order)
+	sameAs := func(t *types.Triple, a, b *big.Int) bool {
+		return t.B.Cmp(a) == 0 && t.C.Cmp(b) == 0
+	}
+	if !(sameAs(t1, p.a1, p.b1) && sameAs(t2, p.a2, p.b2)) &&
+		!(sameAs(t1, p.a2, p.b2) && sameAs(t2, p.a1, p.b1)) {
+		return false
+	}
+
+	p.verifier.SetProofRandomData(t1, t2)
+	p.verifier.SetChallenge(challenge)
+	return p.verifier.Verify(proofData[0], proofData[1], proofData[2], proofData[3])
+}
+
+func (p *partialDLog) Simulate(challenge *big.Int) ([]*big.Int, []*big.Int, error) {
+	t1, t2, c1, z1, c2, z2, err := dlogproofs.SimulatePartialDLog(p.group, p.a1, p.b1, p.a2, p.b2,
+		challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []*big.Int{t1.A, t1.B, t1.C, t2.A, t2.B, t2.C}, []*big.Int{c1, z1, c2, z2}, nil
+}
+
+// dhTuple proves that (g, ga, gb, gab) is a Diffie-Hellman tuple.
+type dhTuple struct {
+	group    *groups.SchnorrGroup
+	g        *big.Int
+	ga       *big.Int
+	gb       *big.Int
+	gab      *big.Int
+	a        *big.Int
+	prover   *dlogproofs.DHTupleProver
+	verifier *dlogproofs.DHTupleVerifier
+}
+
+func NewDHTupleProver(group *groups.SchnorrGroup, a, g, ga, gb, gab *big.Int) Prover {
+	return &dhTuple{
+		group:  group,
+		g:      g,
+		ga:     ga,
+		gb:     gb,
+		gab:    gab,
+		a:      a,
+		prover: dlogproofs.NewDHTupleProver(group),
+	}
+}
+
+func NewDHTupleVerifier(group *groups.SchnorrGroup, g, ga, gb, gab *big.Int) Verifier {
+	return &dhTuple{
+		group:    group,
+		g:        g,
+		ga:       ga,
+		gb:       gb,
+		gab:      gab,
+		verifier: dlogproofs.NewDHTupleVerifier(group),
+	}
+}
+
+func (p *dhTuple) Name() string             { return "DHTuple" }
+func (p *dhTuple) Statement() []*big.Int    { return []*big.Int{p.g, p.ga, p.gb, p.gab} }
+func (p *dhTuple) ChallengeSpace() *big.Int { return p.group.Q }
+func (p *dhTuple) Groups() []Group          { return []Group{SchnorrGroup(p.group)} }
+
+func (p *dhTuple) GetProofRandomData() ([]*big.Int, error) {
+	x1, x2, err := p.prover.GetProofRandomData(p.a, p.g, p.gb)
+	if err != nil {
+		return nil, err
+	}
+	return []*big.Int{x1, x2}, nil
+}
+
+func (p *dhTuple) GetProofData(challenge *big.Int) ([]*big.Int, error) {
+	z, err := p.prover.GetProofData(challenge)
+	if err != nil {
+		return nil, err
+	}
+	return []*big.Int{z}, nil
+}
+
+func (p *dhTuple) Verify(proofRandomData []*big.Int, challenge *big.Int,
+	proofData []*big.Int) bool {
+	if len(proofRandomData) != 2 || len(proofData) != 1 {
+		return false
+	}
+	p.verifier.SetProofRandomData(p.g, p.ga, p.gb, p.gab, proofRandomData[0],
+		proofRandomData[1])
+	p.verifier.SetChallenge(challenge)
+	return p.verifier.Verify(proofData[0])
+}
+
+func (p *dhTuple) Simulate(challenge *big.Int) ([]*big.Int, []*big.Int, error) {
+	x1, x2, z, err := dlogproofs.SimulateDLogEquality(p.group, p.g, p.gb, p.ga, p.gab, challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []*big.Int{x1, x2}, []*big.Int{z}, nil
+}
+
+// dhTupleEC proves that (g, ga, gb, gab) is a Diffie-Hellman tuple in the elliptic curve group.
+type dhTupleEC struct {
+	curve    dlog.Curve
+	g        *types.ECGroupElement
+	ga       *types.ECGroupElement
+	gb       *types.ECGroupElement
+	gab      *types.ECGroupElement
+	a        *big.Int
+	prover   *dlogproofs.ECDHTupleProver
+	verifier *dlogproofs.ECDHTupleVerifier
+}
+
+func NewECDHTupleProver(curve dlog.Curve, a *big.Int, g, ga, gb,
+	gab *types.ECGroupElement) Prover {
+	return &dhTupleEC{
+		curve:  curve,
+		g:      g,
+		ga:     ga,
+		gb:     gb,
+		gab:    gab,
+		a:      a,
+		prover: dlogproofs.NewECDHTupleProver(curve),
+	}
+}
+
+func NewECDHTupleVerifier(curve dlog.Curve, g, ga, gb, gab *types.ECGroupElement) Verifier {
+	return &dhTupleEC{
+		curve:    curve,
+		g:        g,
+		ga:       ga,
+		gb:       gb,
+		gab:      gab,
+		verifier: dlogproofs.NewECDHTupleVerifier(curve),
+	}
+}
+
+func (p *dhTupleEC) Name() string { return "DHTupleEC" }
+
+func (p *dhTupleEC) Statement() []*big.Int {
+	return []*big.Int{big.NewInt(int64(p.curve)), p.g.X, p.g.Y, p.ga.X, p.ga.Y, p.gb.X, p.gb.Y,
+		p.gab.X, p.gab.Y}
+}
+
+func (p *dhTupleEC) ChallengeSpace() *big.Int {
+	return dlog.GetEllipticCurve(p.curve).Params().N
+}
+
+func (p *dhTupleEC) Groups() []Group { return []Group{CurveGroup(p.curve)} }
+
+func (p *dhTupleEC) GetProofRandomData() ([]*big.Int, error) {
+	x1, x2, err := p.prover.GetProofRandomData(p.a, p.g, p.gb)
+	if err != nil {
+		return nil, err
+	}
+	return []*big.Int{x1.X, x1.Y, x2.X, x2.Y}, nil
+}
+
+func (p *dhTupleEC) GetProofData(challenge *big.Int) ([]*big.Int, error) {
+	z, err := p.prover.GetProofData(challenge)
+	if err != nil {
+		return nil, err
+	}
+	return []*big.Int{z}, nil
+}
+
+func (p *dhTupleEC) Verify(proofRandomData []*big.Int, challenge *big.Int,
+	proofData []*big.Int) bool {
+	if len(proofRandomData) != 4 || len(proofData) != 1 {
+		return false
+	}
+	x1 := types.NewECGroupElement(proofRandomData[0], proofRandomData[1])
+	x2 := types.NewECGroupElement(proofRandomData[2], proofRandomData[3])
+	p.verifier.SetProofRandomData(p.g, p.ga, p.gb, p.gab, x1, x2)
+	p.verifier.SetChallenge(challenge)
+	return p.verifier.Verify(proofData[0])
+}
+
+func (p *dhTupleEC) Simulate(challenge *big.Int) ([]*big.Int, []*big.Int, error) {
+	x1, x2, z, err := dlogproofs.SimulateECDLogEquality(dlog.NewECDLog(p.curve), p.g, p.gb, p.ga,
+		p.gab, challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []*big.Int{x1.X, x1.Y, x2.X, x2.Y}, []*big.Int{z}, nil
+}
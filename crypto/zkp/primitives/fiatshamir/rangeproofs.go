@@ -0,0 +1,209 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package fiatshamir
+
+import (
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/rangeproofs"
+	"math/big"
+)
+
+// Adapters of the square and non-negativity proofs from rangeproofs to Prover and Verifier.
+
+// square proves that c commits to a square (Pedersen or Damgard-Fujisaki commitment).
+type square struct {
+	name           string
+	statement      []*big.Int
+	group          Group
+	challengeSpace *big.Int
+	prover         *rangeproofs.SquareProver
+	verifier       *rangeproofs.SquareVerifier
+}
+
+func pedersenSquare(group *groups.SchnorrGroup, h, c *big.Int) *square {
+	return &square{
+		name:           "PedersenSquare",
+		statement:      []*big.Int{group.P, group.Q, group.G, h, c},
+		group:          SchnorrGroup(group),
+		challengeSpace: group.Q,
+	}
+}
+
+func NewPedersenSquareProver(group *groups.SchnorrGroup, h, c, x, r *big.Int) Prover {
+	p := pedersenSquare(group, h, c)
+	p.prover = rangeproofs.NewPedersenSquareProver(group, h, x, r)
+	return p
+}
+
+func NewPedersenSquareVerifier(group *groups.SchnorrGroup, h, c *big.Int) Verifier {
+	p := pedersenSquare(group, h, c)
+	p.verifier = rangeproofs.NewPedersenSquareVerifier(group, h, c)
+	return p
+}
+
+func dfSquare(params *commitments.DamgardFujisakiParams, c *big.Int) *square {
+	return &square{
+		name:           "DamgardFujisakiSquare",
+		statement:      []*big.Int{params.N, params.G, params.H, c},
+		group:          QRGroup(params),
+		challengeSpace: integerChallengeSpace(),
+	}
+}
+
+func NewDamgardFujisakiSquareProver(params *commitments.DamgardFujisakiParams,
+	c, x, r *big.Int) Prover {
+	p := dfSquare(params, c)
+	p.prover = rangeproofs.NewDamgardFujisakiSquareProver(params, x, r)
+	return p
+}
+
+func NewDamgardFujisakiSquareVerifier(params *commitments.DamgardFujisakiParams,
+	c *big.Int) Verifier {
+	p := dfSquare(params, c)
+	p.verifier = rangeproofs.NewDamgardFujisakiSquareVerifier(params, c)
+	return p
+}
+
+func (p *square) Name() string             { return p.name }
+func (p *square) Statement() []*big.Int    { return p.statement }
+func (p *square) ChallengeSpace() *big.Int { return p.challengeSpace }
+func (p *square) Groups() []Group          { return []Group{p.group} }
+
+func (p *square) GetProofRandomData() ([]*big.Int, error) {
+	data, err := p.prover.GetProofRandomData()
+	if err != nil {
+		return nil, err
+	}
+	return []*big.Int{data.C1, data.T1, data.T2}, nil
+}
+
+func (p *square) GetProofData(challenge *big.Int) ([]*big.Int, error) {
+	data := p.prover.GetProofData(challenge)
+	return []*big.Int{data.Z, data.W1, data.W2}, nil
+}
+
+func (p *square) Verify(proofRandomData []*big.Int, challenge *big.Int,
+	proofData []*big.Int) bool {
+	if len(proofRandomData) != 3 || len(proofData) != 3 {
+		return false
+	}
+	err := p.verifier.SetProofRandomData(&rangeproofs.SquareProofRandomData{
+		C1: proofRandomData[0],
+		T1: proofRandomData[1],
+		T2: proofRandomData[2],
+	})
+	if err != nil {
+		return false
+	}
+	p.verifier.SetChallenge(challenge)
+	return p.verifier.Verify(&rangeproofs.SquareProofData{
+		Z:  proofData[0],
+		W1: proofData[1],
+		W2: proofData[2],
+	})
+}
+
+// nonNegative proves that the value committed in Damgard-Fujisaki commitment c is
+// non-negative.
+type nonNegative struct {
+	params   *commitments.DamgardFujisakiParams
+	c        *big.Int
+	prover   *rangeproofs.NonNegativeProver
+	verifier *rangeproofs.NonNegativeVerifier
+}
+
+// NewNonNegativeProver returns an error if x is negative.
+func NewNonNegativeProver(params *commitments.DamgardFujisakiParams, c, x, r *big.Int) (Prover,
+	error) {
+	prover, err := rangeproofs.NewNonNegativeProver(params, x, r)
+	if err != nil {
+		return nil, err
+	}
+	return &nonNegative{
+		params: params,
+		c:      c,
+		prover: prover,
+	}, nil
+}
+
+func NewNonNegativeVerifier(params *commitments.DamgardFujisakiParams, c *big.Int) Verifier {
+	return &nonNegative{
+		params:   params,
+		c:        c,
+		verifier: rangeproofs.NewNonNegativeVerifier(params, c),
+	}
+}
+
+func (p *nonNegative) Name() string { return "NonNegative" }
+
+func (p *nonNegative) Statement() []*big.Int {
+	return []*big.Int{p.params.N, p.params.G, p.params.H, p.c}
+}
+
+func (p *nonNegative) ChallengeSpace() *big.Int { return integerChallengeSpace() }
+
+func (p *nonNegative) Groups() []Group { return []Group{QRGroup(p.params)} }
+
+// GetProofRandomData returns the three commitments followed by C1, T1, T2 of each of the
+// four square proofs.
+func (p *nonNegative) GetProofRandomData() ([]*big.Int, error) {
+	data, err := p.prover.GetProofRandomData()
+	if err != nil {
+		return nil, err
+	}
+	values := append([]*big.Int{}, data.C...)
+	for _, d := range data.Squares {
+		values = append(values, d.C1, d.T1, d.T2)
+	}
+	return values, nil
+}
+
+func (p *nonNegative) GetProofData(challenge *big.Int) ([]*big.Int, error) {
+	var values []*big.Int
+	for _, d := range p.prover.GetProofData(challenge) {
+		values = append(values, d.Z, d.W1, d.W2)
+	}
+	return values, nil
+}
+
+func (p *nonNegative) Verify(proofRandomData []*big.Int, challenge *big.Int,
+	proofData []*big.Int) bool {
+	if len(proofRandomData) != 15 || len(proofData) != 12 {
+		return false
+	}
+	randomData := &rangeproofs.NonNegativeProofRandomData{
+		C: proofRandomData[:3],
+	}
+	data := make([]*rangeproofs.SquareProofData, 4)
+	for i := 0; i < 4; i++ {
+		rd, d := proofRandomData[3+3*i:], proofData[3*i:]
+		randomData.Squares = append(randomData.Squares,
+			&rangeproofs.SquareProofRandomData{C1: rd[0], T1: rd[1], T2: rd[2]})
+		data[i] = &rangeproofs.SquareProofData{Z: d[0], W1: d[1], W2: d[2]}
+	}
+	if err := p.verifier.SetProofRandomData(randomData); err != nil {
+		return false
+	}
+	p.verifier.SetChallenge(challenge)
+	return p.verifier.Verify(data)
+}
+
+func integerChallengeSpace() *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), rangeproofs.IntegerChallengeBitLength)
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package fiatshamir
+
+import (
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+// Functions in this file verify encoded proofs (see Proof.Marshal) given only the public
+// parameters, so that the services which need to check emmy proofs can do it without
+// setting up any provers or verifiers, for example:
+//
+//	if !fiatshamir.VerifySchnorr(proof, context, group, g, publicKey) {
+//		return errInvalidProof
+//	}
+//
+// Proofs which cannot be decoded are invalid.
+
+// VerifyEncoded checks the encoded proof which was produced in the given context. It can
+// be used with any Verifier (for example with the compositions from package sigma).
+func VerifyEncoded(verifier Verifier, proof, context []byte) bool {
+	p, err := UnmarshalProof(proof)
+	if err != nil {
+		return false
+	}
+	return Verify(verifier, p, context)
+}
+
+// VerifySchnorr checks the encoded proof of knowledge of log_a(b).
+func VerifySchnorr(proof, context []byte, group *groups.SchnorrGroup, a, b *big.Int) bool {
+	return VerifyEncoded(NewSchnorrVerifier(group, a, b), proof, context)
+}
+
+// VerifySchnorrEC checks the encoded proof of knowledge of log_a(b) in EC group.
+func VerifySchnorrEC(proof, context []byte, curve dlog.Curve, a, b *types.ECGroupElement) bool {
+	return VerifyEncoded(NewSchnorrECVerifier(curve, a, b), proof, context)
+}
+
+// VerifyDLogEquality checks the encoded proof of knowledge of log_g1(t1) = log_g2(t2).
+func VerifyDLogEquality(proof, context []byte, group *groups.SchnorrGroup,
+	g1, g2, t1, t2 *big.Int) bool {
+	return VerifyEncoded(NewDLogEqualityVerifier(group, g1, g2, t1, t2), proof, context)
+}
+
+// VerifyPartialDLog checks the encoded proof of knowledge of log_a1(b1) or log_a2(b2).
+func VerifyPartialDLog(proof, context []byte, group *groups.SchnorrGroup,
+	a1, b1, a2, b2 *big.Int) bool {
+	return VerifyEncoded(NewPartialDLogVerifier(group, a1, b1, a2, b2), proof, context)
+}
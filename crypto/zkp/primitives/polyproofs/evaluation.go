@@ -0,0 +1,362 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package polyproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+)
+
+// ProvePolynomialEvaluation demonstrates how prover can prove that y = P(x) for
+// the polynomial P with coefficients a_0,...,a_d, where the coefficients, x and y are
+// committed with Pedersen commitments.
+func ProvePolynomialEvaluation(group *groups.SchnorrGroup, h *big.Int,
+	coefficients []*big.Int, x *big.Int) (bool, error) {
+	commit := func(value, r *big.Int) *big.Int {
+		return group.Mul(group.Exp(group.G, value), group.Exp(h, r))
+	}
+	coefficientsR, err := common.GetRandomInts(len(coefficients), group.Q)
+	if err != nil {
+		return false, err
+	}
+	coefficientCommitments := make([]*big.Int, len(coefficients))
+	for i, a := range coefficients {
+		coefficientCommitments[i] = commit(a, coefficientsR[i])
+	}
+	rx, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return false, err
+	}
+	ry, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return false, err
+	}
+	cx := commit(x, rx)
+	cy := commit(EvaluatePolynomial(group, coefficients, x), ry)
+
+	prover, err := NewPolynomialEvaluationProver(group, h, coefficients, coefficientsR, x, rx, ry)
+	if err != nil {
+		return false, err
+	}
+	verifier := NewPolynomialEvaluationVerifier(group, h, coefficientCommitments, cx, cy)
+
+	data, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(data); err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+// EvaluatePolynomial returns a_0 + a_1 * x + ... + a_d * x^d mod q.
+func EvaluatePolynomial(group *groups.SchnorrGroup, coefficients []*big.Int,
+	x *big.Int) *big.Int {
+	y := big.NewInt(0)
+	for i := len(coefficients) - 1; i >= 0; i-- { // Horner's rule
+		y.Mul(y, x)
+		y.Add(y, coefficients[i])
+		y.Mod(y, group.Q)
+	}
+	return y
+}
+
+// PolynomialEvaluationProver proves that y = P(x) mod q, where the coefficients a_i of P are
+// committed in C_i = g^(a_i) * h^(r_i), x in cx = g^x * h^rx and y in cy = g^y * h^ry, without
+// revealing P, x or y (this is what is needed for example in oblivious polynomial evaluation
+// in private set intersection protocols, where the polynomial has the roots in the elements
+// of a set).
+//
+// Prover commits to the powers of x: P_1 = cx, P_(i+1) = P_i^x * h^(t_i), and to the terms
+// of the polynomial: D_i = P_i^(a_i) * h^(v_i) for i = 1,...,d. It proves in a single sigma
+// protocol that the same x is used for cx and as the exponent in all P_(i+1), that the same
+// a_i is committed in C_i and used as the exponent in D_i, and that cy / (C_0 * D_1 * ... * D_d)
+// is a power of h (thus y = a_0 + a_1 * x + ... + a_d * x^d). The proof has O(d) size.
+type PolynomialEvaluationProver struct {
+	Group         *groups.SchnorrGroup
+	h             *big.Int
+	coefficients  []*big.Int
+	coefficientsR []*big.Int
+	x             *big.Int
+	rx            *big.Int
+	ry            *big.Int
+
+	t  []*big.Int // randomness in P_(i+1) = P_i^x * h^(t_i)
+	v  []*big.Int // randomness in D_i = P_i^(a_i) * h^(v_i)
+	w  *big.Int   // log_h(cy / (C_0 * D_1 * ... * D_d))
+	kx *big.Int
+	kr *big.Int
+	kt []*big.Int
+	ka []*big.Int
+	kc []*big.Int // masks for the randomness of C_i
+	kv []*big.Int
+	kw *big.Int
+}
+
+// PolynomialEvaluationProofRandomData holds the commitments to the powers of x (starting with
+// x^2) and to the terms of the polynomial, and the first messages for all equations.
+type PolynomialEvaluationProofRandomData struct {
+	Powers []*big.Int // P_2, ..., P_d
+	Terms  []*big.Int // D_1, ..., D_d
+	TX     *big.Int   // g^kx * h^kr
+	TP     []*big.Int // P_i^kx * h^(kt_i) for i = 1,...,d-1
+	TC     []*big.Int // g^(ka_i) * h^(kc_i) for i = 1,...,d
+	TD     []*big.Int // P_i^(ka_i) * h^(kv_i) for i = 1,...,d
+	TY     *big.Int   // h^kw
+}
+
+// PolynomialEvaluationProofData holds the responses.
+type PolynomialEvaluationProofData struct {
+	ZX *big.Int
+	ZR *big.Int
+	ZT []*big.Int
+	ZA []*big.Int
+	ZC []*big.Int
+	ZV []*big.Int
+	ZW *big.Int
+}
+
+// NewPolynomialEvaluationProver returns a prover for the polynomial with the given
+// coefficients (a_0 first) committed with the given randomness, x committed with randomness
+// rx and y = P(x) committed with randomness ry.
+func NewPolynomialEvaluationProver(group *groups.SchnorrGroup, h *big.Int, coefficients,
+	coefficientsR []*big.Int, x, rx, ry *big.Int) (*PolynomialEvaluationProver, error) {
+	if len(coefficients) == 0 || len(coefficients) != len(coefficientsR) {
+		return nil, fmt.Errorf("each coefficient needs to be committed")
+	}
+	return &PolynomialEvaluationProver{
+		Group:         group,
+		h:             h,
+		coefficients:  coefficients,
+		coefficientsR: coefficientsR,
+		x:             x,
+		rx:            rx,
+		ry:            ry,
+	}, nil
+}
+
+func (prover *PolynomialEvaluationProver) GetProofRandomData() (*PolynomialEvaluationProofRandomData,
+	error) {
+	group, h, q := prover.Group, prover.h, prover.Group.Q
+	d := len(prover.coefficients) - 1
+	tLen := 0
+	if d > 0 {
+		tLen = d - 1
+	}
+	// t, v, kx, kr, kw, kt, ka, kc and kv are all random from Z_q
+	random, err := common.GetRandomInts(2*tLen+4*d+3, q)
+	if err != nil {
+		return nil, err
+	}
+	next := func(n int) []*big.Int {
+		values := random[:n]
+		random = random[n:]
+		return values
+	}
+
+	// powers[i-1] = P_i, s[i-1] is its randomness
+	powers := []*big.Int{group.Mul(group.Exp(group.G, prover.x), group.Exp(h, prover.rx))}
+	s := []*big.Int{prover.rx}
+	prover.t = next(tLen)
+	for i := 0; i < d-1; i++ {
+		powers = append(powers, group.Mul(group.Exp(powers[i], prover.x),
+			group.Exp(h, prover.t[i])))
+		si := new(big.Int).Mul(prover.x, s[i])
+		si.Add(si, prover.t[i])
+		s = append(s, si.Mod(si, q))
+	}
+
+	prover.v = next(d)
+	terms := make([]*big.Int, d)
+	// w = ry - r_0 - sum(a_i * s_i + v_i)
+	w := new(big.Int).Sub(prover.ry, prover.coefficientsR[0])
+	for i := 0; i < d; i++ {
+		a := prover.coefficients[i+1]
+		terms[i] = group.Mul(group.Exp(powers[i], a), group.Exp(h, prover.v[i]))
+		u := new(big.Int).Mul(a, s[i])
+		u.Add(u, prover.v[i])
+		w.Sub(w, u)
+	}
+	prover.w = w.Mod(w, q)
+
+	k := next(3)
+	prover.kx, prover.kr, prover.kw = k[0], k[1], k[2]
+	prover.kt, prover.ka, prover.kc, prover.kv = next(len(prover.t)), next(d), next(d), next(d)
+
+	data := &PolynomialEvaluationProofRandomData{
+		Powers: powers[1:],
+		Terms:  terms,
+		TX:     group.Mul(group.Exp(group.G, prover.kx), group.Exp(h, prover.kr)),
+		TY:     group.Exp(h, prover.kw),
+	}
+	for i := range prover.t {
+		data.TP = append(data.TP, group.Mul(group.Exp(powers[i], prover.kx),
+			group.Exp(h, prover.kt[i])))
+	}
+	for i := 0; i < d; i++ {
+		data.TC = append(data.TC, group.Mul(group.Exp(group.G, prover.ka[i]),
+			group.Exp(h, prover.kc[i])))
+		data.TD = append(data.TD, group.Mul(group.Exp(powers[i], prover.ka[i]),
+			group.Exp(h, prover.kv[i])))
+	}
+	return data, nil
+}
+
+func (prover *PolynomialEvaluationProver) GetProofData(
+	challenge *big.Int) *PolynomialEvaluationProofData {
+	q := prover.Group.Q
+	response := func(mask, secret *big.Int) *big.Int {
+		z := new(big.Int).Mul(challenge, secret)
+		z.Add(z, mask)
+		return z.Mod(z, q)
+	}
+	d := len(prover.coefficients) - 1
+	data := &PolynomialEvaluationProofData{
+		ZX: response(prover.kx, prover.x),
+		ZR: response(prover.kr, prover.rx),
+		ZW: response(prover.kw, prover.w),
+	}
+	for i := range prover.t {
+		data.ZT = append(data.ZT, response(prover.kt[i], prover.t[i]))
+	}
+	for i := 0; i < d; i++ {
+		data.ZA = append(data.ZA, response(prover.ka[i], prover.coefficients[i+1]))
+		data.ZC = append(data.ZC, response(prover.kc[i], prover.coefficientsR[i+1]))
+		data.ZV = append(data.ZV, response(prover.kv[i], prover.v[i]))
+	}
+	return data
+}
+
+type PolynomialEvaluationVerifier struct {
+	Group                  *groups.SchnorrGroup
+	h                      *big.Int
+	coefficientCommitments []*big.Int
+	cx                     *big.Int
+	cy                     *big.Int
+	randomData             *PolynomialEvaluationProofRandomData
+	challenge              *big.Int
+}
+
+// NewPolynomialEvaluationVerifier returns a verifier of the proof that cy commits to P(x),
+// where the coefficients of P (a_0 first) are committed in coefficientCommitments and x in cx.
+func NewPolynomialEvaluationVerifier(group *groups.SchnorrGroup, h *big.Int,
+	coefficientCommitments []*big.Int, cx, cy *big.Int) *PolynomialEvaluationVerifier {
+	return &PolynomialEvaluationVerifier{
+		Group:                  group,
+		h:                      h,
+		coefficientCommitments: coefficientCommitments,
+		cx:                     cx,
+		cy:                     cy,
+	}
+}
+
+func (verifier *PolynomialEvaluationVerifier) SetProofRandomData(
+	data *PolynomialEvaluationProofRandomData) error {
+	d := len(verifier.coefficientCommitments) - 1
+	if d < 0 {
+		return fmt.Errorf("No coefficient commitments.")
+	}
+	if data == nil || len(data.Powers) != max0(d-1) || len(data.TP) != max0(d-1) ||
+		len(data.Terms) != d || len(data.TC) != d || len(data.TD) != d {
+		return fmt.Errorf("Polynomial evaluation proof random data does not match the degree.")
+	}
+	elements := []*big.Int{data.TX, data.TY}
+	for _, values := range [][]*big.Int{data.Powers, data.Terms, data.TP, data.TC, data.TD} {
+		elements = append(elements, values...)
+	}
+	for _, e := range elements {
+		if e == nil || !verifier.Group.IsElementInGroup(e) {
+			return fmt.Errorf("Polynomial evaluation proof random data is not from the group.")
+		}
+	}
+	verifier.randomData = data
+	return nil
+}
+
+func (verifier *PolynomialEvaluationVerifier) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(verifier.Group.Q)
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return verifier.challenge, nil
+}
+
+// SetChallenge sets the challenge which was not generated by the verifier (for example
+// the one derived by Fiat-Shamir heuristic).
+func (verifier *PolynomialEvaluationVerifier) SetChallenge(challenge *big.Int) {
+	verifier.challenge = challenge
+}
+
+// Verify checks (with P_1 = cx):
+//
+//	g^zx * h^zr = TX * cx^challenge
+//	P_i^zx * h^(zt_i) = TP_i * P_(i+1)^challenge for i = 1,...,d-1
+//	g^(za_i) * h^(zc_i) = TC_i * C_i^challenge for i = 1,...,d
+//	P_i^(za_i) * h^(zv_i) = TD_i * D_i^challenge for i = 1,...,d
+//	h^zw = TY * (cy / (C_0 * D_1 * ... * D_d))^challenge
+func (verifier *PolynomialEvaluationVerifier) Verify(data *PolynomialEvaluationProofData) bool {
+	group, h, c := verifier.Group, verifier.h, verifier.challenge
+	rd := verifier.randomData
+	d := len(verifier.coefficientCommitments) - 1
+	if rd == nil || c == nil || data == nil || data.ZX == nil || data.ZR == nil ||
+		data.ZW == nil || len(data.ZT) != max0(d-1) || len(data.ZA) != d ||
+		len(data.ZC) != d || len(data.ZV) != d {
+		return false
+	}
+
+	check := func(base1, exp1, exp2, t, y *big.Int) bool {
+		left := group.Mul(group.Exp(base1, exp1), group.Exp(h, exp2))
+		right := group.Mul(t, group.Exp(y, c))
+		return left.Cmp(right) == 0
+	}
+
+	if !check(group.G, data.ZX, data.ZR, rd.TX, verifier.cx) {
+		return false
+	}
+	powers := append([]*big.Int{verifier.cx}, rd.Powers...)
+	for i := 0; i < d-1; i++ {
+		if !check(powers[i], data.ZX, data.ZT[i], rd.TP[i], powers[i+1]) {
+			return false
+		}
+	}
+	sum := verifier.coefficientCommitments[0]
+	for i := 0; i < d; i++ {
+		if !check(group.G, data.ZA[i], data.ZC[i], rd.TC[i], verifier.coefficientCommitments[i+1]) ||
+			!check(powers[i], data.ZA[i], data.ZV[i], rd.TD[i], rd.Terms[i]) {
+			return false
+		}
+		sum = group.Mul(sum, rd.Terms[i])
+	}
+	rest := group.Mul(verifier.cy, group.Inv(sum))
+	return group.Exp(h, data.ZW).Cmp(group.Mul(rd.TY, group.Exp(rest, c))) == 0
+}
+
+func max0(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
@@ -27,18 +27,24 @@ import (
 // the verifier does not know whether knowledge of f^(-1)(u1) or f^(-1)(u2) was proved.
 // Note that PartialDLogKnowledge is a special case of PartialPreimageKnowledge.
 func ProvePartialPreimageKnowledge(homomorphism func(*big.Int) *big.Int, H common.Group,
-	q, v1, u1, u2 *big.Int) bool {
+	q, v1, u1, u2 *big.Int) (bool, error) {
 	prover := NewPartialPreimageProver(homomorphism, H, q, v1, u1, u2)
 	verifier := NewPartialPreimageVerifier(homomorphism, H, q)
 
-	pair1, pair2 := prover.GetProofRandomData()
+	pair1, pair2, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
 
 	verifier.SetProofRandomData(pair1, pair2)
-	challenge := verifier.GetChallenge()
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
 
 	c1, z1, c2, z2 := prover.GetProofData(challenge)
 	verified := verifier.Verify(c1, z1, c2, z2)
-	return verified
+	return verified, nil
 }
 
 type PartialPreimageProver struct {
@@ -68,10 +74,19 @@ func NewPartialPreimageProver(homomorphism func(*big.Int) *big.Int, H common.Gro
 
 // GetProofRandomData returns QOneWayHomomorphism(r1) and QOneWayHomomorphism(r2)/(u2^c2)
 // in random order and where r1 and r2 are random from H.
-func (prover *PartialPreimageProver) GetProofRandomData() (*types.Pair, *types.Pair) {
-	r1 := prover.H.GetRandomElement()
-	c2 := common.GetRandomInt(prover.Q)
-	z2 := prover.H.GetRandomElement()
+func (prover *PartialPreimageProver) GetProofRandomData() (*types.Pair, *types.Pair, error) {
+	r1, err := prover.H.GetRandomElement()
+	if err != nil {
+		return nil, nil, err
+	}
+	c2, err := common.GetRandomInt(prover.Q)
+	if err != nil {
+		return nil, nil, err
+	}
+	z2, err := prover.H.GetRandomElement()
+	if err != nil {
+		return nil, nil, err
+	}
 	prover.r1 = r1
 	prover.c2 = c2
 	prover.z2 = z2
@@ -82,16 +97,19 @@ func (prover *PartialPreimageProver) GetProofRandomData() (*types.Pair, *types.P
 	x2 = prover.H.Mul(x2, u2ToC2Inv)
 
 	// we need to make sure that the order does not reveal which secret we do know:
-	ord := common.GetRandomInt(big.NewInt(2))
+	ord, err := common.GetRandomInt(big.NewInt(2))
+	if err != nil {
+		return nil, nil, err
+	}
 	pair1 := types.NewPair(x1, prover.u1)
 	pair2 := types.NewPair(x2, prover.u2)
 
 	if ord.Cmp(big.NewInt(0)) == 0 {
 		prover.ord = 0
-		return pair1, pair2
+		return pair1, pair2, nil
 	} else {
 		prover.ord = 1
-		return pair2, pair1
+		return pair2, pair1, nil
 	}
 }
 
@@ -132,10 +150,13 @@ func (verifier *PartialPreimageVerifier) SetProofRandomData(pair1, pair2 *types.
 	verifier.pair2 = pair2
 }
 
-func (verifier *PartialPreimageVerifier) GetChallenge() *big.Int {
-	challenge := common.GetRandomInt(verifier.Q)
+func (verifier *PartialPreimageVerifier) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(verifier.Q)
+	if err != nil {
+		return nil, err
+	}
 	verifier.challenge = challenge
-	return challenge
+	return challenge, nil
 }
 
 func (verifier *PartialPreimageVerifier) verifyPair(pair *types.Pair,
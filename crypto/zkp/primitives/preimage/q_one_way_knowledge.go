@@ -27,18 +27,24 @@ import (
 // ProvePreimageKnowledge demonstrates how given homomorphism f:H->G and element u from G
 // prover can prove the knowledge of v such that f(v) = u.
 func ProvePreimageKnowledge(homomorphism func(*big.Int) *big.Int, H common.Group,
-	challengeMax, u, v *big.Int) bool {
+	challengeMax, u, v *big.Int) (bool, error) {
 	prover := NewFPreimageProver(homomorphism, H, v)
-	proofRandomData := prover.GetProofRandomData()
+	proofRandomData, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
 
 	verifier := NewFPreimageVerifier(homomorphism, H, challengeMax, u)
 	verifier.SetProofRandomData(proofRandomData)
-	challenge := verifier.GetChallenge()
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
 
 	z := prover.GetProofData(challenge)
 	proved := verifier.Verify(z)
 
-	return proved
+	return proved, nil
 }
 
 // Given q-one-way homomorphism f: H -> G and u from group G, we want to prove that
@@ -60,15 +66,18 @@ func NewFPreimageProver(homomorphism func(*big.Int) *big.Int, H common.Group, v
 }
 
 // Chooses random r from H and returns QOneWayHomomorpism(r).
-func (prover *FPreimageProver) GetProofRandomData() *big.Int {
+func (prover *FPreimageProver) GetProofRandomData() (*big.Int, error) {
 	// TODO: see SchnorrProver comment, note that here setting of the required parameters (v) is
 	// done in the constructor.
 
 	// x = QOneWayHomomorphism(r), where r is random
-	r := prover.H.GetRandomElement()
+	r, err := prover.H.GetRandomElement()
+	if err != nil {
+		return nil, err
+	}
 	prover.r = r
 	x := prover.QOneWayHomomorphism(r)
-	return x
+	return x, nil
 }
 
 // GetProofData receives challenge defined by a verifier, and returns z = r * v^challenge.
@@ -102,10 +111,13 @@ func (verifier *FPreimageVerifier) SetProofRandomData(x *big.Int) {
 	verifier.x = x
 }
 
-func (verifier *FPreimageVerifier) GetChallenge() *big.Int {
-	challenge := common.GetRandomInt(verifier.ChallengeMax)
+func (verifier *FPreimageVerifier) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(verifier.ChallengeMax)
+	if err != nil {
+		return nil, err
+	}
 	verifier.challenge = challenge
-	return challenge
+	return challenge, nil
 }
 
 // It receives z = r * v^challenge. It returns true if QOneWayHomomorphism(z) = x * u^challenge, otherwise false.
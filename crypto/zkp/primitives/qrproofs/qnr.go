@@ -35,10 +35,16 @@ func ProveQNR(y *big.Int, qr *dlog.QR) (bool, error) {
 	m := qr.N.BitLen()
 
 	for i := 0; i < m; i++ {
-		w, pairs := verifier.GetChallenge()
+		w, pairs, err := verifier.GetChallenge()
+		if err != nil {
+			return false, err
+		}
 		prover.SetProofRandomData(w)
 		// get challenge from prover for proving that verifier is not cheating
-		randVector := prover.GetChallenge()
+		randVector, err := prover.GetChallenge()
+		if err != nil {
+			return false, err
+		}
 
 		verProof := verifier.GetProofData(randVector)
 
@@ -74,12 +80,15 @@ func NewQNRProver(qr *dlog.QR, y *big.Int) *QNRProver {
 	}
 }
 
-func (prover *QNRProver) GetChallenge() []int {
+func (prover *QNRProver) GetChallenge() ([]int, error) {
 	m := prover.QR.N.BitLen()
 	var randVector []int
 	for i := 0; i < m; i++ {
 		// todo: remove big.Int
-		b := common.GetRandomInt(big.NewInt(2)) // 0 or 1
+		b, err := common.GetRandomInt(big.NewInt(2)) // 0 or 1
+		if err != nil {
+			return nil, err
+		}
 		var r int
 		if b.Cmp(big.NewInt(0)) == 0 {
 			r = 0
@@ -88,7 +97,7 @@ func (prover *QNRProver) GetChallenge() []int {
 		}
 		randVector = append(randVector, r)
 	}
-	return randVector
+	return randVector, nil
 }
 
 func (prover *QNRProver) SetProofRandomData(w *big.Int) {
@@ -150,14 +159,20 @@ func NewQNRVerifier(qr *dlog.QR, y *big.Int) *QNRVerifier {
 	}
 }
 
-func (verifier *QNRVerifier) GetChallenge() (*big.Int, []*types.Pair) {
-	r := common.GetRandomInt(verifier.QR.N)
+func (verifier *QNRVerifier) GetChallenge() (*big.Int, []*types.Pair, error) {
+	r, err := common.GetRandomInt(verifier.QR.N)
+	if err != nil {
+		return nil, nil, err
+	}
 	// checking that gcd(r, N) = 1 is not needed as the probability is low
 	verifier.r = r
 	verifier.pairs = verifier.pairs[:0] // clear verifier.pairs
 	r2 := verifier.QR.Multiply(r, r)
 
-	b := common.GetRandomInt(big.NewInt(2)) // 0 or 1
+	b, err := common.GetRandomInt(big.NewInt(2)) // 0 or 1
+	if err != nil {
+		return nil, nil, err
+	}
 	var w *big.Int
 
 	if b.Cmp(big.NewInt(0)) == 0 {
@@ -171,13 +186,22 @@ func (verifier *QNRVerifier) GetChallenge() (*big.Int, []*types.Pair) {
 	m := verifier.QR.N.BitLen()
 	var pairs []*types.Pair
 	for i := 0; i < m; i++ {
-		r1 := common.GetRandomInt(verifier.QR.N)
-		r2 := common.GetRandomInt(verifier.QR.N)
+		r1, err := common.GetRandomInt(verifier.QR.N)
+		if err != nil {
+			return nil, nil, err
+		}
+		r2, err := common.GetRandomInt(verifier.QR.N)
+		if err != nil {
+			return nil, nil, err
+		}
 		aj := verifier.QR.Multiply(r1, r1) // r1^2
 		bj := verifier.QR.Multiply(r2, r2)
 		bj = verifier.QR.Multiply(bj, verifier.y) // r2^2 * y
 
-		bitj := common.GetRandomInt(big.NewInt(2)) // 0 or 1
+		bitj, err := common.GetRandomInt(big.NewInt(2)) // 0 or 1
+		if err != nil {
+			return nil, nil, err
+		}
 
 		verifier.pairs = append(verifier.pairs, &types.Pair{A: r1, B: r2})
 
@@ -196,7 +220,7 @@ func (verifier *QNRVerifier) GetChallenge() (*big.Int, []*types.Pair) {
 		pairs = append(pairs, pair)
 	}
 
-	return w, pairs
+	return w, pairs, nil
 }
 
 func (verifier *QNRVerifier) GetProofData(randVector []int) []*types.Pair {
@@ -23,28 +23,35 @@ import (
 	"errors"
 	"github.com/xlab-si/emmy/crypto/common"
 	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/repetition"
 	"math/big"
 )
 
 // ProveQR demonstrates how the prover can prove that y1^2 is QR.
-func ProveQR(y1 *big.Int, group *groups.SchnorrGroup) bool {
+func ProveQR(y1 *big.Int, group *groups.SchnorrGroup) (bool, error) {
 	y := group.Mul(y1, y1)
 	prover := NewQRProver(group, y1)
 	verifier := NewQRVerifier(y, group)
 	m := group.P.BitLen()
 
 	for i := 0; i < m; i++ {
-		x := prover.GetProofRandomData()
-		c := verifier.GetChallenge(x)
+		x, err := prover.GetProofRandomData()
+		if err != nil {
+			return false, err
+		}
+		c, err := verifier.GetChallenge(x)
+		if err != nil {
+			return false, err
+		}
 
 		z, _ := prover.GetProofData(c)
 
 		proved := verifier.Verify(z)
 		if !proved {
-			return false
+			return false, nil
 		}
 	}
-	return true
+	return true, nil
 }
 
 type QRProver struct {
@@ -63,11 +70,14 @@ func NewQRProver(group *groups.SchnorrGroup, y1 *big.Int) *QRProver {
 	}
 }
 
-func (prover *QRProver) GetProofRandomData() *big.Int {
-	r := common.GetRandomInt(prover.Group.P)
+func (prover *QRProver) GetProofRandomData() (*big.Int, error) {
+	r, err := common.GetRandomInt(prover.Group.P)
+	if err != nil {
+		return nil, err
+	}
 	prover.r = r
 	x := prover.Group.Exp(r, big.NewInt(2))
-	return x
+	return x, nil
 }
 
 func (prover *QRProver) GetProofData(challenge *big.Int) (*big.Int, error) {
@@ -97,21 +107,87 @@ func NewQRVerifier(y *big.Int, group *groups.SchnorrGroup) *QRVerifier {
 	}
 }
 
-func (verifier *QRVerifier) GetChallenge(x *big.Int) *big.Int {
+func (verifier *QRVerifier) GetChallenge(x *big.Int) (*big.Int, error) {
 	verifier.x = x
-	c := common.GetRandomInt(big.NewInt(2)) // 0 or 1
+	c, err := common.GetRandomInt(big.NewInt(2)) // 0 or 1
+	if err != nil {
+		return nil, err
+	}
 	verifier.challenge = c
-	return c
+	return c, nil
 }
 
 func (verifier *QRVerifier) Verify(z *big.Int) bool {
+	return verifyQR(verifier.Group, verifier.y, verifier.x, verifier.challenge, z)
+}
+
+// verifyQR checks z^2 = x (for challenge 0) or z^2 = x * y (for challenge 1).
+func verifyQR(group *groups.SchnorrGroup, y, x, challenge, z *big.Int) bool {
 	z2 := new(big.Int).Mul(z, z)
-	z2.Mod(z2, verifier.Group.P)
-	if verifier.challenge.Cmp(big.NewInt(0)) == 0 {
-		return z2.Cmp(verifier.x) == 0
+	z2.Mod(z2, group.P)
+	if challenge.Cmp(big.NewInt(0)) == 0 {
+		return z2.Cmp(x) == 0
 	} else {
-		s := new(big.Int).Mul(verifier.x, verifier.y)
-		s.Mod(s, verifier.Group.P)
+		s := new(big.Int).Mul(x, y)
+		s.Mod(s, group.P)
 		return z2.Cmp(s) == 0
 	}
 }
+
+// ProveQRParallel demonstrates how the prover can prove that y1^2 is QR in a single round,
+// with soundness error 2^(-soundnessBits).
+func ProveQRParallel(y1 *big.Int, group *groups.SchnorrGroup, soundnessBits int) (bool,
+	error) {
+	prover, err := NewQRParallelProver(group, y1, soundnessBits)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewQRParallelVerifier(group.Mul(y1, y1), group, soundnessBits)
+	if err != nil {
+		return false, err
+	}
+
+	xs, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge(xs)
+	if err != nil {
+		return false, err
+	}
+	zs, err := prover.GetProofData(challenge)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(zs), nil
+}
+
+// NewQRParallelProver returns the prover which runs as many QR proofs (with bit challenges)
+// in parallel as needed for the soundness error 2^(-soundnessBits).
+func NewQRParallelProver(group *groups.SchnorrGroup, y1 *big.Int,
+	soundnessBits int) (*repetition.ParallelProver, error) {
+	return repetition.NewParallelProver(2, soundnessBits, func() repetition.Prover {
+		return qrRun{NewQRProver(group, y1)}
+	})
+}
+
+// NewQRParallelVerifier returns the verifier of the parallel QR proofs of y with soundness
+// error 2^(-soundnessBits).
+func NewQRParallelVerifier(y *big.Int, group *groups.SchnorrGroup,
+	soundnessBits int) (*repetition.ParallelVerifier, error) {
+	return repetition.NewParallelVerifier(2, soundnessBits, qrRunVerifier{group, y})
+}
+
+// qrRun is the prover of one of the parallel runs of the QR proof.
+type qrRun struct {
+	*QRProver
+}
+
+type qrRunVerifier struct {
+	group *groups.SchnorrGroup
+	y     *big.Int
+}
+
+func (v qrRunVerifier) Verify(x, challenge, z *big.Int) bool {
+	return verifyQR(v.group, v.y, x, challenge, z)
+}
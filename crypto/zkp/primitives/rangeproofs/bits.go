@@ -0,0 +1,189 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package rangeproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+)
+
+// ProveBitDecomposition demonstrates how prover can prove that the value x committed in
+// Pedersen commitment c = g^x * h^r has n bits and commit to each of them.
+func ProveBitDecomposition(group *groups.SchnorrGroup, h, x, r *big.Int, n int) (bool, error) {
+	c := group.Mul(group.Exp(group.G, x), group.Exp(h, r))
+	prover, err := NewBitDecompositionProver(group, h, x, r, n)
+	if err != nil {
+		return false, err
+	}
+	verifier := NewBitDecompositionVerifier(group, h, c, n)
+
+	data, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(data); err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+// BitDecompositionProver splits the value x committed in c = g^x * h^r into the commitments
+// C_i = g^(bit_i) * h^(r_i) to its n bits and proves that each C_i commits to 0 or 1
+// (the same proof is used for both halves of RangeProver). The randomness r_i is chosen
+// such that prod(C_i^(2^i)) = c, which the verifier checks, thus the bits sum up to x.
+//
+// The bit commitments (see BitDecompositionVerifier.GetBitCommitments) and their openings
+// (see GetBits) can be used by further proofs about the bits, for example set-membership
+// and comparison predicates.
+type BitDecompositionProver struct {
+	Group *groups.SchnorrGroup
+	bits  *bitsProver
+}
+
+// NewBitDecompositionProver returns a prover for the value x committed with randomness r
+// (c = g^x * h^r). It returns an error if x is not from [0, 2^n).
+func NewBitDecompositionProver(group *groups.SchnorrGroup, h, x, r *big.Int,
+	n int) (*BitDecompositionProver, error) {
+	if err := checkBitLen(group, n); err != nil {
+		return nil, err
+	}
+	if x.Sign() < 0 || x.BitLen() > n {
+		return nil, fmt.Errorf("Value does not have %d bits.", n)
+	}
+	bits, err := newBitsProver(group, h, x, r, n)
+	if err != nil {
+		return nil, err
+	}
+	return &BitDecompositionProver{
+		Group: group,
+		bits:  bits,
+	}, nil
+}
+
+// GetBits returns the bits of x (starting with the least significant one) and
+// the randomness of their commitments.
+func (prover *BitDecompositionProver) GetBits() ([]*big.Int, []*big.Int) {
+	n := len(prover.bits.bits)
+	bits := make([]*big.Int, n)
+	rs := make([]*big.Int, n)
+	for i, bit := range prover.bits.bits {
+		bits[i] = big.NewInt(int64(bit))
+		rs[i] = new(big.Int).Set(prover.bits.rs[i])
+	}
+	return bits, rs
+}
+
+// GetProofRandomData returns the bit commitments and the first messages of the proofs.
+func (prover *BitDecompositionProver) GetProofRandomData() ([]*BitProofRandomData, error) {
+	return prover.bits.getProofRandomData()
+}
+
+func (prover *BitDecompositionProver) GetProofData(challenge *big.Int) []*BitProofData {
+	return prover.bits.getProofData(challenge)
+}
+
+type BitDecompositionVerifier struct {
+	Group      *groups.SchnorrGroup
+	h          *big.Int
+	c          *big.Int
+	n          int
+	randomData []*BitProofRandomData
+	challenge  *big.Int
+}
+
+// NewBitDecompositionVerifier returns a verifier of the proof that the value committed
+// in c has n bits.
+func NewBitDecompositionVerifier(group *groups.SchnorrGroup, h, c *big.Int,
+	n int) *BitDecompositionVerifier {
+	return &BitDecompositionVerifier{
+		Group: group,
+		h:     h,
+		c:     c,
+		n:     n,
+	}
+}
+
+// SetProofRandomData checks that there are n bit commitments and that they are
+// the decomposition of c.
+func (verifier *BitDecompositionVerifier) SetProofRandomData(data []*BitProofRandomData) error {
+	group := verifier.Group
+	if err := checkBitLen(group, verifier.n); err != nil {
+		return err
+	}
+	if len(data) != verifier.n {
+		return fmt.Errorf("Bit decomposition needs to have %d bits.", verifier.n)
+	}
+	if err := checkBitProofRandomData(group, data); err != nil {
+		return err
+	}
+	if !group.IsElementInGroup(verifier.c) || composeBits(group, data).Cmp(verifier.c) != 0 {
+		return fmt.Errorf("Bit commitments do not match the commitment.")
+	}
+
+	verifier.randomData = data
+	return nil
+}
+
+// GetBitCommitments returns the commitments to the bits (starting with the least
+// significant one) which were set by SetProofRandomData. Note that they are to be used
+// only after the proof is verified.
+func (verifier *BitDecompositionVerifier) GetBitCommitments() []*big.Int {
+	commitments := make([]*big.Int, len(verifier.randomData))
+	for i, d := range verifier.randomData {
+		commitments[i] = new(big.Int).Set(d.C)
+	}
+	return commitments
+}
+
+func (verifier *BitDecompositionVerifier) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(verifier.Group.Q)
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return verifier.challenge, nil
+}
+
+// SetChallenge sets the challenge which was not generated by the verifier (for example
+// the one derived by Fiat-Shamir heuristic).
+func (verifier *BitDecompositionVerifier) SetChallenge(challenge *big.Int) {
+	verifier.challenge = challenge
+}
+
+// Verify checks that each of the bit commitments is a commitment to 0 or 1.
+func (verifier *BitDecompositionVerifier) Verify(data []*BitProofData) bool {
+	if verifier.randomData == nil || verifier.challenge == nil {
+		return false
+	}
+	return verifyBits(verifier.Group, verifier.h, verifier.challenge, verifier.randomData, data)
+}
+
+// checkBitLen checks that the values with n bits can be committed in the group without
+// the reduction modulo q.
+func checkBitLen(group *groups.SchnorrGroup, n int) error {
+	if n < 1 || n >= group.Q.BitLen()-1 {
+		return fmt.Errorf("Bit length %d is not supported for the group.", n)
+	}
+	return nil
+}
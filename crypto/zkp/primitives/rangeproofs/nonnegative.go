@@ -0,0 +1,296 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package rangeproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/common"
+	"math/big"
+)
+
+// ProveNonNegative demonstrates how prover can prove that the value x committed in
+// Damgard-Fujisaki commitment c = g^x * h^r is non-negative.
+func ProveNonNegative(params *commitments.DamgardFujisakiParams, x, r *big.Int) (bool, error) {
+	c := params.Commit(x, r)
+	prover, err := NewNonNegativeProver(params, x, r)
+	if err != nil {
+		return false, err
+	}
+	verifier := NewNonNegativeVerifier(params, c)
+
+	proofRandomData, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(proofRandomData); err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+// NonNegativeProver proves that the value committed in Damgard-Fujisaki commitment
+// c = g^x * h^r is non-negative (Lipmaa: On Diophantine Complexity and Statistical
+// Zero-Knowledge Arguments). Prover writes x as the sum of four squares x1^2 + ... + x4^2
+// (Lagrange), commits to the squares as C_i = g^(x_i^2) * h^(r_i) where r_4 = r - r_1 - r_2
+// - r_3 (thus the verifier can compute C_4 = c / (C_1 * C_2 * C_3) itself) and proves that
+// each C_i commits to a square (with the same challenge for all four proofs). This is not
+// possible with Pedersen commitments in a Schnorr group, where all values are in Z_q - use
+// RangeProver there.
+type NonNegativeProver struct {
+	Params  *commitments.DamgardFujisakiParams
+	squares [4]*SquareProver
+	c       [3]*big.Int
+}
+
+// NonNegativeProofRandomData holds the commitments to the first three squares (the last one
+// is computed by the verifier) and the proof random data of the four square proofs.
+type NonNegativeProofRandomData struct {
+	C       []*big.Int
+	Squares []*SquareProofRandomData
+}
+
+// NewNonNegativeProver returns a prover for the value x committed with randomness r
+// (c = g^x * h^r mod N). It returns an error if x is negative.
+func NewNonNegativeProver(params *commitments.DamgardFujisakiParams,
+	x, r *big.Int) (*NonNegativeProver, error) {
+	if x.Sign() < 0 {
+		return nil, fmt.Errorf("Value is negative.")
+	}
+	roots, err := fourSquares(x)
+	if err != nil {
+		return nil, err
+	}
+
+	prover := &NonNegativeProver{
+		Params: params,
+	}
+	r4 := new(big.Int).Set(r)
+	for i := 0; i < 3; i++ {
+		ri, err := common.GetRandomInt(params.RandomnessBound())
+		if err != nil {
+			return nil, err
+		}
+		r4.Sub(r4, ri)
+		prover.c[i] = params.Commit(new(big.Int).Mul(roots[i], roots[i]), ri)
+		prover.squares[i] = NewDamgardFujisakiSquareProver(params, roots[i], ri)
+	}
+	prover.squares[3] = NewDamgardFujisakiSquareProver(params, roots[3], r4)
+	return prover, nil
+}
+
+func (prover *NonNegativeProver) GetProofRandomData() (*NonNegativeProofRandomData, error) {
+	data := &NonNegativeProofRandomData{
+		C:       prover.c[:],
+		Squares: make([]*SquareProofRandomData, 4),
+	}
+	for i, square := range prover.squares {
+		d, err := square.GetProofRandomData()
+		if err != nil {
+			return nil, err
+		}
+		data.Squares[i] = d
+	}
+	return data, nil
+}
+
+func (prover *NonNegativeProver) GetProofData(challenge *big.Int) []*SquareProofData {
+	data := make([]*SquareProofData, 4)
+	for i, square := range prover.squares {
+		data[i] = square.GetProofData(challenge)
+	}
+	return data
+}
+
+type NonNegativeVerifier struct {
+	Params    *commitments.DamgardFujisakiParams
+	c         *big.Int
+	squares   []*SquareVerifier
+	challenge *big.Int
+}
+
+// NewNonNegativeVerifier returns a verifier of the proof that the value committed in c is
+// non-negative.
+func NewNonNegativeVerifier(params *commitments.DamgardFujisakiParams,
+	c *big.Int) *NonNegativeVerifier {
+	return &NonNegativeVerifier{
+		Params: params,
+		c:      c,
+	}
+}
+
+// SetProofRandomData computes C_4 = c / (C_1 * C_2 * C_3) and sets the proof random data
+// of the square proofs.
+func (verifier *NonNegativeVerifier) SetProofRandomData(data *NonNegativeProofRandomData) error {
+	group := newDamgardFujisakiGroup(verifier.Params)
+	if data == nil || len(data.C) != 3 || len(data.Squares) != 4 {
+		return fmt.Errorf("Non-negativity proof needs three commitments and four square proofs.")
+	}
+	if !group.isElement(verifier.c) {
+		return fmt.Errorf("Commitment is not from the group.")
+	}
+
+	c4 := verifier.c
+	cs := append([]*big.Int{}, data.C...)
+	for _, ci := range data.C {
+		if !group.isElement(ci) {
+			return fmt.Errorf("Commitment to a square is not from the group.")
+		}
+		c4 = group.mul(c4, group.inv(ci))
+	}
+	cs = append(cs, c4)
+
+	squares := make([]*SquareVerifier, 4)
+	for i, ci := range cs {
+		squares[i] = NewDamgardFujisakiSquareVerifier(verifier.Params, ci)
+		if err := squares[i].SetProofRandomData(data.Squares[i]); err != nil {
+			return err
+		}
+	}
+	verifier.squares = squares
+	return nil
+}
+
+func (verifier *NonNegativeVerifier) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(new(big.Int).Lsh(big.NewInt(1), IntegerChallengeBitLength))
+	if err != nil {
+		return nil, err
+	}
+	verifier.SetChallenge(challenge)
+	return challenge, nil
+}
+
+// SetChallenge sets the challenge which was not generated by the verifier (for example
+// the one derived by Fiat-Shamir heuristic).
+func (verifier *NonNegativeVerifier) SetChallenge(challenge *big.Int) {
+	verifier.challenge = challenge
+	for _, square := range verifier.squares {
+		square.SetChallenge(challenge)
+	}
+}
+
+// Verify checks that each of the four commitments is a commitment to a square.
+func (verifier *NonNegativeVerifier) Verify(data []*SquareProofData) bool {
+	if verifier.squares == nil || verifier.challenge == nil || len(data) != 4 {
+		return false
+	}
+	for i, square := range verifier.squares {
+		if !square.Verify(data[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// fourSquares returns a, b, c, d such that x = a^2 + b^2 + c^2 + d^2 (Rabin, Shallit:
+// Randomized algorithms in number theory). It chooses random a, b until p = x - a^2 - b^2
+// is a prime p = 1 (mod 4) (or p < 3), which is a sum of two squares. For x = 0 (mod 4)
+// such p does not exist, thus x = 4^k * m is decomposed as 2^k times the roots of m.
+func fourSquares(x *big.Int) ([4]*big.Int, error) {
+	var roots [4]*big.Int
+	if x.Sign() < 0 {
+		return roots, fmt.Errorf("Value is negative.")
+	}
+	if x.Sign() > 0 && x.Bit(0) == 0 && x.Bit(1) == 0 {
+		roots, err := fourSquares(new(big.Int).Rsh(x, 2))
+		if err != nil {
+			return roots, err
+		}
+		for i := range roots {
+			roots[i].Lsh(roots[i], 1)
+		}
+		return roots, nil
+	}
+	if x.Cmp(big.NewInt(1024)) < 0 {
+		return smallFourSquares(x.Int64()), nil
+	}
+
+	one := big.NewInt(1)
+	for {
+		a, err := common.GetRandomInt(new(big.Int).Add(new(big.Int).Sqrt(x), one))
+		if err != nil {
+			return roots, err
+		}
+		rest := new(big.Int).Sub(x, new(big.Int).Mul(a, a))
+		b, err := common.GetRandomInt(new(big.Int).Add(new(big.Int).Sqrt(rest), one))
+		if err != nil {
+			return roots, err
+		}
+		p := rest.Sub(rest, new(big.Int).Mul(b, b))
+
+		c, d, ok := twoSquares(p)
+		if ok {
+			roots[0], roots[1], roots[2], roots[3] = a, b, c, d
+			return roots, nil
+		}
+	}
+}
+
+// twoSquares returns c, d such that p = c^2 + d^2 if p < 3 or p is a prime p = 1 (mod 4)
+// (Hermite-Serret: Euclidean algorithm on p and a square root of -1 modulo p).
+func twoSquares(p *big.Int) (*big.Int, *big.Int, bool) {
+	if p.Cmp(big.NewInt(3)) < 0 {
+		sq := smallFourSquares(p.Int64())
+		return sq[0], sq[1], true
+	}
+	if new(big.Int).And(p, big.NewInt(3)).Cmp(big.NewInt(1)) != 0 || !p.ProbablyPrime(20) {
+		return nil, nil, false
+	}
+
+	// s = u^((p-1)/4) is a square root of -1 when u is a quadratic non-residue
+	pMinusOne := new(big.Int).Sub(p, big.NewInt(1))
+	e := new(big.Int).Rsh(pMinusOne, 2)
+	var s *big.Int
+	for u := int64(2); ; u++ {
+		s = new(big.Int).Exp(big.NewInt(u), e, p)
+		if new(big.Int).Exp(s, big.NewInt(2), p).Cmp(pMinusOne) == 0 {
+			break
+		}
+	}
+
+	r0, r1 := new(big.Int).Set(p), s
+	for new(big.Int).Mul(r1, r1).Cmp(p) > 0 {
+		r0, r1 = r1, new(big.Int).Mod(r0, r1)
+	}
+	c := r1
+	d := new(big.Int).Sqrt(new(big.Int).Sub(p, new(big.Int).Mul(c, c)))
+	if new(big.Int).Add(new(big.Int).Mul(c, c), new(big.Int).Mul(d, d)).Cmp(p) != 0 {
+		return nil, nil, false
+	}
+	return c, d, true
+}
+
+// smallFourSquares finds the decomposition of a small non-negative x by exhaustive search.
+func smallFourSquares(x int64) [4]*big.Int {
+	for a := int64(0); a*a <= x; a++ {
+		for b := a; a*a+b*b <= x; b++ {
+			for c := b; a*a+b*b+c*c <= x; c++ {
+				d := x - a*a - b*b - c*c
+				root := new(big.Int).Sqrt(big.NewInt(d))
+				if root.Int64()*root.Int64() == d {
+					return [4]*big.Int{big.NewInt(a), big.NewInt(b), big.NewInt(c), root}
+				}
+			}
+		}
+	}
+	return [4]*big.Int{} // not reached, every non-negative integer is a sum of four squares
+}
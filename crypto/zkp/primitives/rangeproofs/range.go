@@ -0,0 +1,395 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package rangeproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+)
+
+// ProveRange demonstrates how prover can prove that the value x committed in Pedersen
+// commitment c = g^x * h^r lies in [a, b].
+func ProveRange(group *groups.SchnorrGroup, h, x, r, a, b *big.Int) (bool, error) {
+	c := group.Mul(group.Exp(group.G, x), group.Exp(h, r))
+	prover, err := NewRangeProver(group, h, x, r, a, b)
+	if err != nil {
+		return false, err
+	}
+	verifier := NewRangeVerifier(group, h, c, a, b)
+
+	data, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(data); err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+// Range proof for a value committed with Pedersen commitment (see commitments.PedersenCommitter)
+// in a Schnorr group. Prover proves that x from c = g^x * h^r lies in [a, b] by proving that
+// both x - a and b - x are from [0, 2^n) where n is the bit length of b - a:
+//   - c / g^a = g^(x-a) * h^r is split into commitments to the bits of x - a:
+//     C_i = g^(bit_i) * h^(r_i) where sum(r_i * 2^i) = r, thus prod(C_i^(2^i)) = c / g^a
+//     (verifier checks this),
+//   - for each C_i prover proves the knowledge of log_h(C_i) or log_h(C_i / g), which means
+//     that the committed bit is 0 or 1 (OR composition of Schnorr proofs as in Cramer,
+//     Damgard, Schoenmakers: Proofs of partial knowledge, with the same challenge for all bits),
+//   - the same for g^b / c = g^(b-x) * h^(-r).
+//
+// Note that Boudot's range proof (which is more efficient for large ranges) requires
+// a group of hidden order and thus does not apply to the Pedersen commitments in Schnorr groups.
+type RangeProver struct {
+	Group *groups.SchnorrGroup
+	h     *big.Int
+	lower *bitsProver
+	upper *bitsProver
+}
+
+// BitProofRandomData holds the commitment C to a bit and the first messages of both
+// branches of the OR proof.
+type BitProofRandomData struct {
+	C  *big.Int
+	T0 *big.Int
+	T1 *big.Int
+}
+
+// BitProofData holds the challenges and responses of both branches of the OR proof.
+type BitProofData struct {
+	E0 *big.Int
+	E1 *big.Int
+	Z0 *big.Int
+	Z1 *big.Int
+}
+
+type RangeProofRandomData struct {
+	Lower []*BitProofRandomData // bits of x - a
+	Upper []*BitProofRandomData // bits of b - x
+}
+
+type RangeProofData struct {
+	Lower []*BitProofData
+	Upper []*BitProofData
+}
+
+// NewRangeProver returns a prover for the value x committed with randomness r
+// (c = g^x * h^r). It returns an error if x is not in [a, b].
+func NewRangeProver(group *groups.SchnorrGroup, h, x, r, a, b *big.Int) (*RangeProver, error) {
+	n, err := rangeBitLen(group, a, b)
+	if err != nil {
+		return nil, err
+	}
+	if x.Cmp(a) < 0 || x.Cmp(b) > 0 {
+		return nil, fmt.Errorf("Value is not in the range [%v, %v].", a, b)
+	}
+
+	rNeg := new(big.Int).Sub(group.Q, new(big.Int).Mod(r, group.Q))
+	lower, err := newBitsProver(group, h, new(big.Int).Sub(x, a), r, n)
+	if err != nil {
+		return nil, err
+	}
+	upper, err := newBitsProver(group, h, new(big.Int).Sub(b, x), rNeg, n)
+	if err != nil {
+		return nil, err
+	}
+	return &RangeProver{
+		Group: group,
+		h:     h,
+		lower: lower,
+		upper: upper,
+	}, nil
+}
+
+func (prover *RangeProver) GetProofRandomData() (*RangeProofRandomData, error) {
+	lower, err := prover.lower.getProofRandomData()
+	if err != nil {
+		return nil, err
+	}
+	upper, err := prover.upper.getProofRandomData()
+	if err != nil {
+		return nil, err
+	}
+	return &RangeProofRandomData{
+		Lower: lower,
+		Upper: upper,
+	}, nil
+}
+
+func (prover *RangeProver) GetProofData(challenge *big.Int) *RangeProofData {
+	return &RangeProofData{
+		Lower: prover.lower.getProofData(challenge),
+		Upper: prover.upper.getProofData(challenge),
+	}
+}
+
+type RangeVerifier struct {
+	Group      *groups.SchnorrGroup
+	h          *big.Int
+	c          *big.Int
+	a          *big.Int
+	b          *big.Int
+	randomData *RangeProofRandomData
+	challenge  *big.Int
+}
+
+// NewRangeVerifier returns a verifier of the proof that the value committed in c is in [a, b].
+func NewRangeVerifier(group *groups.SchnorrGroup, h, c, a, b *big.Int) *RangeVerifier {
+	return &RangeVerifier{
+		Group: group,
+		h:     h,
+		c:     c,
+		a:     a,
+		b:     b,
+	}
+}
+
+// SetProofRandomData checks that the bit commitments are the decomposition of c / g^a
+// and g^b / c.
+func (verifier *RangeVerifier) SetProofRandomData(data *RangeProofRandomData) error {
+	group := verifier.Group
+	n, err := rangeBitLen(group, verifier.a, verifier.b)
+	if err != nil {
+		return err
+	}
+	if len(data.Lower) != n || len(data.Upper) != n {
+		return fmt.Errorf("Bit decomposition needs to have %d bits.", n)
+	}
+	if err := checkBitProofRandomData(group, append(data.Lower, data.Upper...)); err != nil {
+		return err
+	}
+
+	lower := group.Mul(verifier.c, group.Inv(group.Exp(group.G, verifier.a)))
+	upper := group.Mul(group.Exp(group.G, verifier.b), group.Inv(verifier.c))
+	if composeBits(group, data.Lower).Cmp(lower) != 0 ||
+		composeBits(group, data.Upper).Cmp(upper) != 0 {
+		return fmt.Errorf("Bit commitments do not match the commitment.")
+	}
+
+	verifier.randomData = data
+	return nil
+}
+
+func (verifier *RangeVerifier) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(verifier.Group.Q)
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return verifier.challenge, nil
+}
+
+// Verify checks that each of the bit commitments is a commitment to 0 or 1.
+func (verifier *RangeVerifier) Verify(data *RangeProofData) bool {
+	if verifier.randomData == nil || verifier.challenge == nil || data == nil {
+		return false
+	}
+	return verifyBits(verifier.Group, verifier.h, verifier.challenge, verifier.randomData.Lower,
+		data.Lower) &&
+		verifyBits(verifier.Group, verifier.h, verifier.challenge, verifier.randomData.Upper,
+			data.Upper)
+}
+
+// checkBitProofRandomData checks that all the values are from the group.
+func checkBitProofRandomData(group *groups.SchnorrGroup, data []*BitProofRandomData) error {
+	for _, d := range data {
+		if d == nil {
+			return fmt.Errorf("Bit proof random data is missing.")
+		}
+		for _, el := range []*big.Int{d.C, d.T0, d.T1} {
+			if el == nil || el.Sign() <= 0 || el.Cmp(group.P) >= 0 || !group.IsElementInGroup(el) {
+				return fmt.Errorf("Bit proof random data is not from the group.")
+			}
+		}
+	}
+	return nil
+}
+
+// verifyBits checks the proofs that the bit commitments commit to 0 or 1.
+func verifyBits(group *groups.SchnorrGroup, h, challenge *big.Int, randomData []*BitProofRandomData,
+	data []*BitProofData) bool {
+	if len(data) != len(randomData) {
+		return false
+	}
+	for i, d := range data {
+		if d == nil || !verifyBit(group, h, challenge, randomData[i], d) {
+			return false
+		}
+	}
+	return true
+}
+
+// verifyBit checks e0 + e1 = challenge, h^z0 = T0 * C^e0 and h^z1 = T1 * (C/g)^e1.
+func verifyBit(group *groups.SchnorrGroup, h, challenge *big.Int, rd *BitProofRandomData,
+	d *BitProofData) bool {
+	if d.E0 == nil || d.E1 == nil || d.Z0 == nil || d.Z1 == nil {
+		return false
+	}
+	e := new(big.Int).Add(d.E0, d.E1)
+	if e.Mod(e, group.Q).Cmp(challenge) != 0 {
+		return false
+	}
+
+	s1 := group.Mul(rd.C, group.Inv(group.G))
+	left0 := group.Exp(h, d.Z0)
+	right0 := group.Mul(rd.T0, group.Exp(rd.C, d.E0))
+	left1 := group.Exp(h, d.Z1)
+	right1 := group.Mul(rd.T1, group.Exp(s1, d.E1))
+	return left0.Cmp(right0) == 0 && left1.Cmp(right1) == 0
+}
+
+// bitsProver proves that y committed in g^y * h^r is from [0, 2^n).
+type bitsProver struct {
+	group *groups.SchnorrGroup
+	h     *big.Int
+	bits  []uint
+	rs    []*big.Int // randomness of bit commitments
+	ks    []*big.Int // randomness of the real branches
+	es    []*big.Int // challenges of the simulated branches
+	zs    []*big.Int // responses of the simulated branches
+}
+
+func newBitsProver(group *groups.SchnorrGroup, h, y, r *big.Int, n int) (*bitsProver, error) {
+	prover := &bitsProver{
+		group: group,
+		h:     h,
+		bits:  make([]uint, n),
+		rs:    make([]*big.Int, n),
+	}
+
+	// r_(n-1) = (r - sum_(i<n-1) r_i * 2^i) / 2^(n-1)
+	sum := big.NewInt(0)
+	for i := 0; i < n; i++ {
+		prover.bits[i] = y.Bit(i)
+		if i < n-1 {
+			r, err := common.GetRandomInt(group.Q)
+			if err != nil {
+				return nil, err
+			}
+			prover.rs[i] = r
+			sum.Add(sum, new(big.Int).Lsh(prover.rs[i], uint(i)))
+		}
+	}
+	last := new(big.Int).Sub(r, sum)
+	pow := new(big.Int).Lsh(big.NewInt(1), uint(n-1))
+	last.Mul(last, new(big.Int).ModInverse(pow, group.Q))
+	prover.rs[n-1] = last.Mod(last, group.Q)
+
+	return prover, nil
+}
+
+func (prover *bitsProver) getProofRandomData() ([]*BitProofRandomData, error) {
+	group := prover.group
+	n := len(prover.bits)
+	prover.ks = make([]*big.Int, n)
+	prover.es = make([]*big.Int, n)
+	prover.zs = make([]*big.Int, n)
+
+	data := make([]*BitProofRandomData, n)
+	for i, bit := range prover.bits {
+		c := group.Exp(prover.h, prover.rs[i])
+		if bit == 1 {
+			c = group.Mul(c, group.G)
+		}
+		// statements of the branches: S0 = C, S1 = C / g
+		s := []*big.Int{c, group.Mul(c, group.Inv(group.G))}
+
+		k, err := common.GetRandomInt(group.Q)
+		if err != nil {
+			return nil, err
+		}
+		prover.ks[i] = k
+		e, err := common.GetRandomInt(group.Q)
+		if err != nil {
+			return nil, err
+		}
+		prover.es[i] = e
+		z, err := common.GetRandomInt(group.Q)
+		if err != nil {
+			return nil, err
+		}
+		prover.zs[i] = z
+		t := make([]*big.Int, 2)
+		t[bit] = group.Exp(prover.h, prover.ks[i])
+		// simulated branch: t = h^z * S^(-e)
+		t[1-bit] = group.Mul(group.Exp(prover.h, prover.zs[i]),
+			group.Inv(group.Exp(s[1-bit], prover.es[i])))
+
+		data[i] = &BitProofRandomData{
+			C:  c,
+			T0: t[0],
+			T1: t[1],
+		}
+	}
+	return data, nil
+}
+
+func (prover *bitsProver) getProofData(challenge *big.Int) []*BitProofData {
+	q := prover.group.Q
+	data := make([]*BitProofData, len(prover.bits))
+	for i, bit := range prover.bits {
+		e := make([]*big.Int, 2)
+		z := make([]*big.Int, 2)
+		e[1-bit] = prover.es[i]
+		z[1-bit] = prover.zs[i]
+		e[bit] = new(big.Int).Sub(challenge, prover.es[i])
+		e[bit].Mod(e[bit], q)
+		z[bit] = new(big.Int).Mul(e[bit], prover.rs[i])
+		z[bit].Add(z[bit], prover.ks[i])
+		z[bit].Mod(z[bit], q)
+
+		data[i] = &BitProofData{
+			E0: e[0],
+			E1: e[1],
+			Z0: z[0],
+			Z1: z[1],
+		}
+	}
+	return data
+}
+
+// composeBits returns prod(C_i^(2^i)).
+func composeBits(group *groups.SchnorrGroup, data []*BitProofRandomData) *big.Int {
+	result := big.NewInt(1)
+	for i, d := range data {
+		result = group.Mul(result, group.Exp(d.C, new(big.Int).Lsh(big.NewInt(1), uint(i))))
+	}
+	return result
+}
+
+// rangeBitLen returns the number of bits which are needed for the values from [0, b - a].
+func rangeBitLen(group *groups.SchnorrGroup, a, b *big.Int) (int, error) {
+	d := new(big.Int).Sub(b, a)
+	if d.Sign() < 0 {
+		return 0, fmt.Errorf("Invalid range [%v, %v].", a, b)
+	}
+	n := d.BitLen()
+	if n == 0 {
+		n = 1
+	}
+	if n >= group.Q.BitLen()-1 {
+		return 0, fmt.Errorf("Range [%v, %v] is too large for the group.", a, b)
+	}
+	return n, nil
+}
@@ -0,0 +1,329 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package rangeproofs
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+)
+
+// IntegerChallengeBitLength is the bit length of the challenges in the proofs about
+// Damgard-Fujisaki commitments (the challenges need to be smaller than the smallest prime
+// factor of the order of QR_N).
+const IntegerChallengeBitLength = 128
+
+// ProveSquare demonstrates how prover can prove that the value committed in Damgard-Fujisaki
+// commitment c = g^(x^2) * h^r is a square.
+func ProveSquare(params *commitments.DamgardFujisakiParams, x, r *big.Int) (bool, error) {
+	c := params.Commit(new(big.Int).Mul(x, x), r)
+	prover := NewDamgardFujisakiSquareProver(params, x, r)
+	verifier := NewDamgardFujisakiSquareVerifier(params, c)
+
+	proofRandomData, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(proofRandomData); err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+// Square proof (Boudot: Efficient Proofs that a Committed Number Lies in an Interval) proves
+// that c = g^(x^2) * h^r commits to a square: prover commits to x as c1 = g^x * h^r1 and proves
+// that the same x is committed in c1 and used as the exponent in c = c1^x * h^r2 where
+// r2 = r - x * r1.
+//
+// For Pedersen commitments in a Schnorr group the exponents are computed modulo q, thus the
+// proof only shows that the committed value is a square modulo q. For Damgard-Fujisaki
+// commitments the responses are integers (statistically hiding the secrets) and the committed
+// value is a square of an integer, which is what NonNegativeProver builds upon.
+type SquareProver struct {
+	group *commitmentGroup
+	x     *big.Int
+	r     *big.Int
+	r1    *big.Int
+	r2    *big.Int
+	a     *big.Int
+	b1    *big.Int
+	b2    *big.Int
+}
+
+// SquareProofRandomData holds the commitment C1 to x and the first messages for both
+// equations.
+type SquareProofRandomData struct {
+	C1 *big.Int
+	T1 *big.Int // g^a * h^b1
+	T2 *big.Int // c1^a * h^b2
+}
+
+// SquareProofData holds the responses for x, r1 and r2.
+type SquareProofData struct {
+	Z  *big.Int
+	W1 *big.Int
+	W2 *big.Int
+}
+
+// NewPedersenSquareProver returns a prover for c = g^(x^2) * h^r in a Schnorr group.
+func NewPedersenSquareProver(group *groups.SchnorrGroup, h, x, r *big.Int) *SquareProver {
+	return &SquareProver{
+		group: newPedersenGroup(group, h),
+		x:     x,
+		r:     r,
+	}
+}
+
+// NewDamgardFujisakiSquareProver returns a prover for c = g^(x^2) * h^r mod N. The absolute
+// value of x needs to be smaller than N and r smaller than 2^(K+3) * N.
+func NewDamgardFujisakiSquareProver(params *commitments.DamgardFujisakiParams,
+	x, r *big.Int) *SquareProver {
+	return &SquareProver{
+		group: newDamgardFujisakiGroup(params),
+		x:     x,
+		r:     r,
+	}
+}
+
+func (prover *SquareProver) GetProofRandomData() (*SquareProofRandomData, error) {
+	group := prover.group
+	r1, err := group.randomExponent(group.rBound)
+	if err != nil {
+		return nil, err
+	}
+	// r2 = r - x * r1
+	r2 := new(big.Int).Mul(prover.x, r1)
+	r2 = group.reduce(r2.Sub(prover.r, r2))
+
+	a, err := group.randomMask(group.xBound)
+	if err != nil {
+		return nil, err
+	}
+	b1, err := group.randomMask(group.rBound)
+	if err != nil {
+		return nil, err
+	}
+	b2, err := group.randomMask(group.r2Bound())
+	if err != nil {
+		return nil, err
+	}
+	prover.r1, prover.r2, prover.a, prover.b1, prover.b2 = r1, r2, a, b1, b2
+
+	c1 := group.commit(group.g, prover.x, r1)
+	return &SquareProofRandomData{
+		C1: c1,
+		T1: group.commit(group.g, a, b1),
+		T2: group.commit(c1, a, b2),
+	}, nil
+}
+
+func (prover *SquareProver) GetProofData(challenge *big.Int) *SquareProofData {
+	group := prover.group
+	return &SquareProofData{
+		Z:  group.response(prover.a, challenge, prover.x),
+		W1: group.response(prover.b1, challenge, prover.r1),
+		W2: group.response(prover.b2, challenge, prover.r2),
+	}
+}
+
+type SquareVerifier struct {
+	group      *commitmentGroup
+	c          *big.Int
+	randomData *SquareProofRandomData
+	challenge  *big.Int
+}
+
+// NewPedersenSquareVerifier returns a verifier of the proof that c commits to a square
+// (modulo q).
+func NewPedersenSquareVerifier(group *groups.SchnorrGroup, h, c *big.Int) *SquareVerifier {
+	return &SquareVerifier{
+		group: newPedersenGroup(group, h),
+		c:     c,
+	}
+}
+
+// NewDamgardFujisakiSquareVerifier returns a verifier of the proof that c commits to a square.
+func NewDamgardFujisakiSquareVerifier(params *commitments.DamgardFujisakiParams,
+	c *big.Int) *SquareVerifier {
+	return &SquareVerifier{
+		group: newDamgardFujisakiGroup(params),
+		c:     c,
+	}
+}
+
+func (verifier *SquareVerifier) SetProofRandomData(data *SquareProofRandomData) error {
+	if data == nil || !verifier.group.isElement(data.C1) || !verifier.group.isElement(data.T1) ||
+		!verifier.group.isElement(data.T2) {
+		return fmt.Errorf("Square proof random data is not from the group.")
+	}
+	verifier.randomData = data
+	return nil
+}
+
+func (verifier *SquareVerifier) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(verifier.group.challengeSpace())
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return verifier.challenge, nil
+}
+
+// SetChallenge sets the challenge which was not generated by the verifier (for example
+// the one derived by Fiat-Shamir heuristic).
+func (verifier *SquareVerifier) SetChallenge(challenge *big.Int) {
+	verifier.challenge = challenge
+}
+
+// Verify checks g^z * h^w1 = T1 * c1^challenge and c1^z * h^w2 = T2 * c^challenge.
+func (verifier *SquareVerifier) Verify(data *SquareProofData) bool {
+	group := verifier.group
+	rd := verifier.randomData
+	if rd == nil || verifier.challenge == nil || data == nil || data.Z == nil ||
+		data.W1 == nil || data.W2 == nil || !group.isElement(verifier.c) {
+		return false
+	}
+
+	left1 := group.commit(group.g, data.Z, data.W1)
+	right1 := group.mul(rd.T1, group.exp(rd.C1, verifier.challenge))
+	left2 := group.commit(rd.C1, data.Z, data.W2)
+	right2 := group.mul(rd.T2, group.exp(verifier.c, verifier.challenge))
+
+	return left1.Cmp(right1) == 0 && left2.Cmp(right2) == 0
+}
+
+// commitmentGroup is the group in which the commitments are computed: either a Schnorr group
+// (Pedersen commitments, exponents modulo q) or QR_N (Damgard-Fujisaki commitments, integer
+// exponents as the order is hidden).
+type commitmentGroup struct {
+	modulus *big.Int
+	g       *big.Int
+	h       *big.Int
+	order   *big.Int // nil when the order is hidden
+	xBound  *big.Int // bound for the absolute value of the committed values
+	rBound  *big.Int // bound for the randomness of the commitments
+}
+
+func newPedersenGroup(group *groups.SchnorrGroup, h *big.Int) *commitmentGroup {
+	return &commitmentGroup{
+		modulus: group.P,
+		g:       group.G,
+		h:       h,
+		order:   group.Q,
+		xBound:  group.Q,
+		rBound:  group.Q,
+	}
+}
+
+func newDamgardFujisakiGroup(params *commitments.DamgardFujisakiParams) *commitmentGroup {
+	return &commitmentGroup{
+		modulus: params.N,
+		g:       params.G,
+		h:       params.H,
+		xBound:  params.N,
+		rBound:  new(big.Int).Lsh(params.RandomnessBound(), 3), // up to the sum of 8 commitments
+	}
+}
+
+// r2Bound returns the bound for r2 = r - x * r1.
+func (group *commitmentGroup) r2Bound() *big.Int {
+	if group.order != nil {
+		return group.order
+	}
+	b := new(big.Int).Mul(group.xBound, group.rBound)
+	return b.Add(b, group.rBound)
+}
+
+func (group *commitmentGroup) challengeSpace() *big.Int {
+	if group.order != nil {
+		return group.order
+	}
+	return new(big.Int).Lsh(big.NewInt(1), IntegerChallengeBitLength)
+}
+
+// randomExponent returns a random exponent from [0, bound) (from Z_q for Pedersen).
+func (group *commitmentGroup) randomExponent(bound *big.Int) (*big.Int, error) {
+	return common.GetRandomInt(bound)
+}
+
+// randomMask returns a random value which hides secret * challenge for secrets smaller
+// than bound: from Z_q for Pedersen and from [0, bound * 2^(t+K)) for Damgard-Fujisaki.
+func (group *commitmentGroup) randomMask(bound *big.Int) (*big.Int, error) {
+	if group.order != nil {
+		return common.GetRandomInt(group.order)
+	}
+	shift := uint(IntegerChallengeBitLength + commitments.DamgardFujisakiK)
+	return common.GetRandomInt(new(big.Int).Lsh(bound, shift))
+}
+
+// response returns mask + challenge * secret (modulo q for Pedersen).
+func (group *commitmentGroup) response(mask, challenge, secret *big.Int) *big.Int {
+	z := new(big.Int).Mul(challenge, secret)
+	return group.reduce(z.Add(z, mask))
+}
+
+func (group *commitmentGroup) reduce(x *big.Int) *big.Int {
+	if group.order == nil {
+		return x
+	}
+	return x.Mod(x, group.order)
+}
+
+func (group *commitmentGroup) exp(base, exponent *big.Int) *big.Int {
+	if group.order != nil {
+		exponent = new(big.Int).Mod(exponent, group.order)
+	}
+	// negative exponents are computed with the inverse of the base
+	r := new(big.Int).Exp(base, exponent, group.modulus)
+	if r == nil {
+		return big.NewInt(0)
+	}
+	return r
+}
+
+func (group *commitmentGroup) mul(x, y *big.Int) *big.Int {
+	z := new(big.Int).Mul(x, y)
+	return z.Mod(z, group.modulus)
+}
+
+func (group *commitmentGroup) inv(x *big.Int) *big.Int {
+	return new(big.Int).ModInverse(x, group.modulus)
+}
+
+// commit returns base^x * h^r.
+func (group *commitmentGroup) commit(base, x, r *big.Int) *big.Int {
+	return group.mul(group.exp(base, x), group.exp(group.h, r))
+}
+
+// isElement checks that x is an invertible element (and from the subgroup of order q for
+// Pedersen).
+func (group *commitmentGroup) isElement(x *big.Int) bool {
+	if x == nil || x.Sign() <= 0 || x.Cmp(group.modulus) >= 0 {
+		return false
+	}
+	if group.order != nil {
+		return new(big.Int).Exp(x, group.order, group.modulus).Cmp(big.NewInt(1)) == 0
+	}
+	return new(big.Int).GCD(nil, nil, x, group.modulus).Cmp(big.NewInt(1)) == 0
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package repetition amplifies the soundness of sigma protocols with a small challenge space
+// (for example bit challenges) by parallel repetition. The runs of the protocol are combined
+// into a single proof: the prover sends the proof random data of all the runs, the verifier
+// sends one challenge from [0, c^n) (where c is the size of the challenge space of a run and
+// n the number of runs) whose base-c digits are the challenges of the runs, and the prover
+// sends the proof data of all the runs, which are checked with a single Verify call.
+// The number of runs is chosen so that the soundness error is at most 2^(-soundnessBits).
+package repetition
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/zkp/security"
+	"math/big"
+)
+
+// Prover is the prover of one run of the protocol.
+type Prover interface {
+	GetProofRandomData() (*big.Int, error)
+	GetProofData(challenge *big.Int) (*big.Int, error)
+}
+
+// Verifier checks the transcript of one run of the protocol.
+type Verifier interface {
+	Verify(proofRandomData, challenge, proofData *big.Int) bool
+}
+
+// Repetitions returns the number of runs of a protocol with challenge space of the given
+// size (the soundness error of one run is 1/challengeSpace) which are needed for
+// the soundness error 2^(-soundnessBits).
+func Repetitions(soundnessBits int, challengeSpace int64) (int, error) {
+	if challengeSpace < 2 {
+		return 0, fmt.Errorf("challenge space needs to contain at least two challenges")
+	}
+	if soundnessBits < security.MinBitLength || soundnessBits > security.MaxBitLength {
+		return 0, fmt.Errorf("soundness needs to be from [%d, %d] bits",
+			security.MinBitLength, security.MaxBitLength)
+	}
+	n := security.Repetitions(soundnessBits, 1/float64(challengeSpace))
+	if n > security.MaxRepetitions {
+		return 0, fmt.Errorf("more than %d repetitions would be needed",
+			security.MaxRepetitions)
+	}
+	return n, nil
+}
+
+// Challenges splits the combined challenge into the challenges of n runs (base-challengeSpace
+// digits, the least significant one first).
+func Challenges(challenge *big.Int, challengeSpace int64, n int) []*big.Int {
+	c := big.NewInt(challengeSpace)
+	rest := new(big.Int).Set(challenge)
+	challenges := make([]*big.Int, n)
+	for i := range challenges {
+		challenges[i] = new(big.Int)
+		rest.DivMod(rest, c, challenges[i])
+	}
+	return challenges
+}
+
+// combinedChallengeSpace returns challengeSpace^n.
+func combinedChallengeSpace(challengeSpace int64, n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(challengeSpace), big.NewInt(int64(n)), nil)
+}
+
+type ParallelProver struct {
+	provers        []Prover
+	challengeSpace int64
+}
+
+// NewParallelProver returns the prover which runs the protocol with the given challenge
+// space in parallel, so that the soundness error is 2^(-soundnessBits). Each run is proved
+// by a new prover returned by newProver.
+func NewParallelProver(challengeSpace int64, soundnessBits int,
+	newProver func() Prover) (*ParallelProver, error) {
+	n, err := Repetitions(soundnessBits, challengeSpace)
+	if err != nil {
+		return nil, err
+	}
+	provers := make([]Prover, n)
+	for i := range provers {
+		provers[i] = newProver()
+	}
+	return &ParallelProver{
+		provers:        provers,
+		challengeSpace: challengeSpace,
+	}, nil
+}
+
+// Repetitions returns the number of runs.
+func (prover *ParallelProver) Repetitions() int {
+	return len(prover.provers)
+}
+
+// GetProofRandomData returns the proof random data of all the runs.
+func (prover *ParallelProver) GetProofRandomData() ([]*big.Int, error) {
+	xs := make([]*big.Int, len(prover.provers))
+	for i, p := range prover.provers {
+		x, err := p.GetProofRandomData()
+		if err != nil {
+			return nil, err
+		}
+		xs[i] = x
+	}
+	return xs, nil
+}
+
+// GetProofData returns the proof data of all the runs for the combined challenge.
+func (prover *ParallelProver) GetProofData(challenge *big.Int) ([]*big.Int, error) {
+	n := len(prover.provers)
+	if challenge.Sign() < 0 ||
+		challenge.Cmp(combinedChallengeSpace(prover.challengeSpace, n)) >= 0 {
+		return nil, fmt.Errorf("challenge is out of range")
+	}
+	zs := make([]*big.Int, n)
+	for i, c := range Challenges(challenge, prover.challengeSpace, n) {
+		z, err := prover.provers[i].GetProofData(c)
+		if err != nil {
+			return nil, err
+		}
+		zs[i] = z
+	}
+	return zs, nil
+}
+
+type ParallelVerifier struct {
+	verifier        Verifier
+	challengeSpace  int64
+	n               int
+	proofRandomData []*big.Int
+	challenge       *big.Int
+}
+
+// NewParallelVerifier returns the verifier of the parallel runs of the protocol with
+// the given challenge space, which accepts the proofs with soundness error at most
+// 2^(-soundnessBits).
+func NewParallelVerifier(challengeSpace int64, soundnessBits int,
+	verifier Verifier) (*ParallelVerifier, error) {
+	n, err := Repetitions(soundnessBits, challengeSpace)
+	if err != nil {
+		return nil, err
+	}
+	return &ParallelVerifier{
+		verifier:       verifier,
+		challengeSpace: challengeSpace,
+		n:              n,
+	}, nil
+}
+
+// Repetitions returns the number of runs.
+func (verifier *ParallelVerifier) Repetitions() int {
+	return verifier.n
+}
+
+// ChallengeSpace returns the bound for the combined challenges (challengeSpace^n), which
+// can be used to derive the challenge non-interactively.
+func (verifier *ParallelVerifier) ChallengeSpace() *big.Int {
+	return combinedChallengeSpace(verifier.challengeSpace, verifier.n)
+}
+
+// GetChallenge stores the proof random data of all the runs and returns a random combined
+// challenge.
+func (verifier *ParallelVerifier) GetChallenge(proofRandomData []*big.Int) (*big.Int,
+	error) {
+	if len(proofRandomData) != verifier.n {
+		return nil, fmt.Errorf("proof random data of %d runs expected", verifier.n)
+	}
+	challenge, err := common.GetRandomInt(verifier.ChallengeSpace())
+	if err != nil {
+		return nil, err
+	}
+	verifier.proofRandomData = proofRandomData
+	verifier.challenge = challenge
+	return verifier.challenge, nil
+}
+
+// Verify checks the transcripts of all the runs.
+func (verifier *ParallelVerifier) Verify(proofData []*big.Int) bool {
+	if verifier.challenge == nil || len(proofData) != verifier.n {
+		return false
+	}
+	challenges := Challenges(verifier.challenge, verifier.challengeSpace, verifier.n)
+	for i, z := range proofData {
+		x := verifier.proofRandomData[i]
+		if x == nil || z == nil || !verifier.verifier.Verify(x, challenges[i], z) {
+			return false
+		}
+	}
+	return true
+}
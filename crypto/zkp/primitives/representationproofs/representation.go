@@ -15,146 +15,57 @@
  *
  */
 
+// Package representationproofs is kept for compatibility, the proof of knowledge of
+// representation is now in package dlogproofs (along with its EC variant).
+//
+// Deprecated: use dlogproofs.RepresentationProver and dlogproofs.RepresentationVerifier.
 package representationproofs
 
 import (
-	"fmt"
 	"github.com/xlab-si/emmy/crypto/common"
 	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
 	"math/big"
 )
 
 // ProveKnowledgeOfRepresentation demonstrates how the prover proves that it knows (x_1,...,x_k)
 // such that y = g_1^x_1 * ... * g_k^x_k where g_i are given generators of cyclic group G.
 // Note that Schnorr is a special case of RepresentationProver where only one base is used.
-func ProveKnowledgeOfRepresentation() bool {
+func ProveKnowledgeOfRepresentation() (bool, error) {
 	group, err := groups.NewSchnorrGroup(256)
 	if err != nil {
-		fmt.Printf("Error when generating SchnorrGroup: %v", err)
-		return false
-	}
-
-	var bases [3]*big.Int
-	for i := 0; i < len(bases); i++ {
-		r := common.GetRandomInt(group.Q)
-		base := group.Exp(group.G, r)
-		bases[i] = base
-	}
-
-	var secrets [3]*big.Int
-	for i := 0; i < 3; i++ {
-		secret := common.GetRandomInt(group.Q)
-		secrets[i] = secret
+		return false, err
 	}
 
+	bases := make([]*big.Int, 3)
+	secrets := make([]*big.Int, 3)
 	// y = g_1^x_1 * ... * g_k^x_k where g_i are bases and x_i are secrets
 	y := big.NewInt(1)
 	for i := 0; i < 3; i++ {
-		f := group.Exp(bases[i], secrets[i])
-		y = group.Mul(y, f)
-	}
-
-	prover, err := NewRepresentationProver(group, secrets[:], bases[:], y)
-	if err != nil {
-		fmt.Printf("Error when instantiating RepresentationProver")
-		return false
+		bases[i], err = group.GetRandomElement()
+		if err != nil {
+			return false, err
+		}
+		secrets[i], err = common.GetRandomInt(group.Q)
+		if err != nil {
+			return false, err
+		}
+		y = group.Mul(y, group.Exp(bases[i], secrets[i]))
 	}
-	verifier := NewRepresentationVerifier(group, bases[:], y)
 
-	proofRandomData := prover.GetProofRandomData()
-	verifier.SetProofRandomData(proofRandomData)
-
-	challenge := verifier.GetChallenge()
-	proofData := prover.GetProofData(challenge)
-	verified := verifier.Verify(proofData)
-	return verified
+	return dlogproofs.ProveRepresentation(group, secrets, bases, y)
 }
 
-type RepresentationProver struct {
-	Group        *groups.SchnorrGroup
-	secrets      []*big.Int
-	bases        []*big.Int
-	randomValues []*big.Int
-	y            *big.Int
-}
+type RepresentationProver = dlogproofs.RepresentationProver
+
+type RepresentationVerifier = dlogproofs.RepresentationVerifier
 
 func NewRepresentationProver(group *groups.SchnorrGroup, secrets,
 	bases []*big.Int, y *big.Int) (*RepresentationProver, error) {
-	if len(secrets) != len(bases) {
-		return nil, fmt.Errorf("Number of secrets and representation bases shoud be the same.")
-	}
-
-	return &RepresentationProver{
-		Group:   group,
-		secrets: secrets,
-		bases:   bases,
-		y:       y,
-	}, nil
-}
-
-func (prover *RepresentationProver) GetProofRandomData() *big.Int {
-	// t = g_1^r_1 * ... * g_k^r_k where g_i are bases and r_i are random values
-	t := big.NewInt(1)
-	var randomValues []*big.Int
-	for i := 0; i < len(prover.bases); i++ {
-		r := common.GetRandomInt(prover.Group.Q)
-		randomValues = append(randomValues, r)
-		f := prover.Group.Exp(prover.bases[i], r)
-		t = prover.Group.Mul(t, f)
-	}
-	prover.randomValues = randomValues
-	return t
-}
-
-func (prover *RepresentationProver) GetProofData(challenge *big.Int) []*big.Int {
-	// z_i = r_i + challenge * secrets[i]
-	var proofData []*big.Int
-	for i := 0; i < len(prover.bases); i++ {
-		z_i := prover.Group.Mul(challenge, prover.secrets[i])
-		z_i = prover.Group.Add(z_i, prover.randomValues[i])
-		proofData = append(proofData, z_i)
-	}
-	return proofData
-}
-
-type RepresentationVerifier struct {
-	Group           *groups.SchnorrGroup
-	bases           []*big.Int
-	proofRandomData *big.Int
-	y               *big.Int
-	challenge       *big.Int
+	return dlogproofs.NewRepresentationProver(group, secrets, bases, y)
 }
 
 func NewRepresentationVerifier(group *groups.SchnorrGroup, bases []*big.Int,
 	y *big.Int) *RepresentationVerifier {
-	return &RepresentationVerifier{
-		Group: group,
-		bases: bases,
-		y:     y,
-	}
-}
-
-func (verifier *RepresentationVerifier) SetProofRandomData(proofRandomData *big.Int) {
-	verifier.proofRandomData = proofRandomData
-}
-
-func (verifier *RepresentationVerifier) GetChallenge() *big.Int {
-	challenge := common.GetRandomInt(verifier.Group.Q)
-	verifier.challenge = challenge
-	return challenge
-}
-
-func (verifier *RepresentationVerifier) Verify(proofData []*big.Int) bool {
-	// check:
-	// g_1^z_1 * ... * g_k^z_k = (g_1^x_1 * ... * g_k^x_k)^challenge * (g_1^r_1 * ... * g_k^r_k)
-	left := big.NewInt(1)
-	for i := 0; i < len(verifier.bases); i++ {
-		t := verifier.Group.Exp(verifier.bases[i], proofData[i])
-		left = verifier.Group.Mul(left, t)
-	}
-
-	right := verifier.Group.Exp(verifier.y, verifier.challenge)
-	right = verifier.Group.Mul(right, verifier.proofRandomData)
-
-	return left.Cmp(right) == 0
+	return dlogproofs.NewRepresentationVerifier(group, bases, y)
 }
@@ -0,0 +1,526 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package shuffle
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+)
+
+// ProveShuffle demonstrates how the mix server shuffles a list of ElGamal ciphertexts and
+// proves that the output list contains the re-encryptions of the input ciphertexts (in
+// a permuted order), without revealing the permutation.
+func ProveShuffle(pubKey *encryption.ElGamalPubKey,
+	input []*encryption.ElGamalCiphertext) (bool, error) {
+	output, permutation, randomness, err := Shuffle(pubKey, input)
+	if err != nil {
+		return false, err
+	}
+	prover, err := NewShuffleProver(pubKey, input, output, permutation, randomness)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewShuffleVerifier(pubKey, input, output)
+	if err != nil {
+		return false, err
+	}
+
+	u, err := prover.GetPermutationCommitment()
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetPermutationCommitment(u); err != nil {
+		return false, err
+	}
+	proofRandomData, err := prover.GetProofRandomData(verifier.GetExponents())
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(proofRandomData); err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+// Shuffle re-encrypts the ciphertexts and permutes them randomly. Besides the shuffled
+// ciphertexts it returns the permutation and the randomness which are needed to prove
+// the shuffle: output[i] = ReEncrypt(input[permutation[i]], randomness[i]).
+func Shuffle(pubKey *encryption.ElGamalPubKey, input []*encryption.ElGamalCiphertext) (
+	[]*encryption.ElGamalCiphertext, []int, []*big.Int, error) {
+	permutation, err := randomPermutation(len(input))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	output := make([]*encryption.ElGamalCiphertext, len(input))
+	randomness := make([]*big.Int, len(input))
+	for i, j := range permutation {
+		if !pubKey.IsCiphertext(input[j]) {
+			return nil, nil, nil, fmt.Errorf("Ciphertext %d is not from the group", j)
+		}
+		randomness[i], err = common.GetRandomInt(pubKey.Group.Q)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		output[i] = pubKey.ReEncrypt(input[j], randomness[i])
+	}
+	return output, permutation, randomness, nil
+}
+
+// Proof of a shuffle (Terelius, Wikstrom: Proofs of Restricted Shuffles) - the variant used
+// in Verificatum mix-net. Let output[i] = ReEncrypt(input[π(i)], s_i). The prover first commits
+// to the permutation matrix: u_j = g^r_j * h_π^-1(j). The verifier then chooses random
+// exponents e_1,...,e_N. For e'_i = e_π(i) the following holds:
+//
+//	prod_j u_j^e_j = g^<r,e> * prod_i h_i^e'_i
+//	prod_i output[i]^e'_i = Enc(1, <s,e'>) * prod_j input[j]^e_j
+//
+// The prover proves (with a single sigma protocol) the knowledge of e' and the randomness
+// such that both equalities hold and that prod_i e'_i = prod_i e_i (using a chain of
+// commitments b_i = g^β_i * b_(i-1)^e'_i where b_0 = h). Together with prod_j u_j =
+// g^sum(r_j) * prod_i h_i this implies that u is a commitment to a permutation matrix and
+// that e' is the vector e permuted by it (Schwartz-Zippel).
+//
+// Generators h, h_1,...,h_N are derived by hashing into the group, so that nobody
+// knows their discrete logarithms.
+type ShuffleProver struct {
+	pubKey      *encryption.ElGamalPubKey
+	h           *big.Int
+	hs          []*big.Int
+	input       []*encryption.ElGamalCiphertext
+	output      []*encryption.ElGamalCiphertext
+	permutation []int
+	randomness  []*big.Int
+	r           []*big.Int // randomness of the permutation commitment
+	ePrime      []*big.Int
+	beta        []*big.Int
+	rBar        *big.Int
+	rHat        *big.Int
+	rTilde      *big.Int
+	rPrime      *big.Int
+	omega       []*big.Int // ω1, ω2, ω3, ω4
+	omegaHat    []*big.Int
+	omegaPrime  []*big.Int
+}
+
+// ShuffleProofRandomData is the first message of the sigma protocol: the chain of
+// commitments B and the prover's commitments for all relations.
+type ShuffleProofRandomData struct {
+	B    []*big.Int
+	T1   *big.Int
+	T2   *big.Int
+	T3   *big.Int
+	T4   *encryption.ElGamalCiphertext
+	THat []*big.Int
+}
+
+// ShuffleProofData contains the responses of the sigma protocol.
+type ShuffleProofData struct {
+	K1     *big.Int
+	K2     *big.Int
+	K3     *big.Int
+	K4     *big.Int
+	KHat   []*big.Int
+	KPrime []*big.Int
+}
+
+func NewShuffleProver(pubKey *encryption.ElGamalPubKey, input,
+	output []*encryption.ElGamalCiphertext, permutation []int,
+	randomness []*big.Int) (*ShuffleProver, error) {
+	if err := checkLists(pubKey, input, output); err != nil {
+		return nil, err
+	}
+	if len(permutation) != len(input) || len(randomness) != len(input) {
+		return nil, fmt.Errorf("Permutation and randomness need to be of the same length as ciphertexts")
+	}
+	if !isPermutation(permutation) {
+		return nil, fmt.Errorf("Not a permutation")
+	}
+	h, hs := getGenerators(pubKey.Group, len(input))
+	return &ShuffleProver{
+		pubKey:      pubKey,
+		h:           h,
+		hs:          hs,
+		input:       input,
+		output:      output,
+		permutation: permutation,
+		randomness:  randomness,
+	}, nil
+}
+
+// GetPermutationCommitment returns u_j = g^r_j * h_π^-1(j).
+func (prover *ShuffleProver) GetPermutationCommitment() ([]*big.Int, error) {
+	group := prover.pubKey.Group
+	n := len(prover.input)
+	r, err := randomExponents(group, n)
+	if err != nil {
+		return nil, err
+	}
+	prover.r = r
+	u := make([]*big.Int, n)
+	for i, j := range prover.permutation {
+		u[j] = group.Mul(group.Exp(group.G, r[j]), prover.hs[i])
+	}
+	return u, nil
+}
+
+// GetProofRandomData receives the exponents e chosen by the verifier and returns
+// the first message of the sigma protocol.
+func (prover *ShuffleProver) GetProofRandomData(e []*big.Int) (*ShuffleProofRandomData, error) {
+	n := len(prover.input)
+	if prover.r == nil {
+		return nil, fmt.Errorf("Permutation commitment has not been computed yet")
+	}
+	if len(e) != n {
+		return nil, fmt.Errorf("The number of exponents needs to be %d", n)
+	}
+	group := prover.pubKey.Group
+	q := group.Q
+
+	prover.ePrime = make([]*big.Int, n)
+	for i, j := range prover.permutation {
+		prover.ePrime[i] = new(big.Int).Mod(e[j], q)
+	}
+	prover.rBar = big.NewInt(0)
+	prover.rTilde = big.NewInt(0)
+	prover.rPrime = big.NewInt(0)
+	for j := 0; j < n; j++ {
+		prover.rBar.Add(prover.rBar, prover.r[j])
+		prover.rTilde.Add(prover.rTilde, new(big.Int).Mul(prover.r[j], e[j]))
+		prover.rPrime.Add(prover.rPrime, new(big.Int).Mul(prover.randomness[j],
+			prover.ePrime[j]))
+	}
+	prover.rBar.Mod(prover.rBar, q)
+	prover.rTilde.Mod(prover.rTilde, q)
+	prover.rPrime.Mod(prover.rPrime, q)
+
+	var err error
+	if prover.beta, err = randomExponents(group, n); err != nil {
+		return nil, err
+	}
+	if prover.omega, err = randomExponents(group, 4); err != nil {
+		return nil, err
+	}
+	if prover.omegaHat, err = randomExponents(group, n); err != nil {
+		return nil, err
+	}
+	if prover.omegaPrime, err = randomExponents(group, n); err != nil {
+		return nil, err
+	}
+
+	// b_i = g^β_i * b_(i-1)^e'_i = g^rHat_i * h^(e'_1*...*e'_i)
+	b := make([]*big.Int, n)
+	tHat := make([]*big.Int, n)
+	prev := prover.h
+	prover.rHat = big.NewInt(0)
+	for i := 0; i < n; i++ {
+		b[i] = group.Mul(group.Exp(group.G, prover.beta[i]), group.Exp(prev, prover.ePrime[i]))
+		tHat[i] = group.Mul(group.Exp(group.G, prover.omegaHat[i]),
+			group.Exp(prev, prover.omegaPrime[i]))
+		prover.rHat.Mul(prover.rHat, prover.ePrime[i])
+		prover.rHat.Add(prover.rHat, prover.beta[i])
+		prover.rHat.Mod(prover.rHat, q)
+		prev = b[i]
+	}
+
+	t3 := group.Mul(group.Exp(group.G, prover.omega[2]),
+		common.MultiExp(prover.hs, prover.omegaPrime, group.P))
+	t4 := prover.pubKey.EncryptOne(new(big.Int).Neg(prover.omega[3]))
+	t4 = prover.pubKey.Mul(t4, multiExpCiphertexts(prover.pubKey, prover.output,
+		prover.omegaPrime))
+
+	return &ShuffleProofRandomData{
+		B:    b,
+		T1:   group.Exp(group.G, prover.omega[0]),
+		T2:   group.Exp(group.G, prover.omega[1]),
+		T3:   t3,
+		T4:   t4,
+		THat: tHat,
+	}, nil
+}
+
+// GetProofData returns the responses k = ω + challenge * secret (mod q) for all secrets.
+func (prover *ShuffleProver) GetProofData(challenge *big.Int) *ShuffleProofData {
+	q := prover.pubKey.Group.Q
+	response := func(omega, secret *big.Int) *big.Int {
+		k := new(big.Int).Mul(challenge, secret)
+		k.Add(k, omega)
+		return k.Mod(k, q)
+	}
+	n := len(prover.input)
+	kHat := make([]*big.Int, n)
+	kPrime := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		kHat[i] = response(prover.omegaHat[i], prover.beta[i])
+		kPrime[i] = response(prover.omegaPrime[i], prover.ePrime[i])
+	}
+	return &ShuffleProofData{
+		K1:     response(prover.omega[0], prover.rBar),
+		K2:     response(prover.omega[1], prover.rHat),
+		K3:     response(prover.omega[2], prover.rTilde),
+		K4:     response(prover.omega[3], prover.rPrime),
+		KHat:   kHat,
+		KPrime: kPrime,
+	}
+}
+
+type ShuffleVerifier struct {
+	pubKey          *encryption.ElGamalPubKey
+	h               *big.Int
+	hs              []*big.Int
+	input           []*encryption.ElGamalCiphertext
+	output          []*encryption.ElGamalCiphertext
+	u               []*big.Int
+	e               []*big.Int
+	proofRandomData *ShuffleProofRandomData
+	challenge       *big.Int
+}
+
+func NewShuffleVerifier(pubKey *encryption.ElGamalPubKey, input,
+	output []*encryption.ElGamalCiphertext) (*ShuffleVerifier, error) {
+	if err := checkLists(pubKey, input, output); err != nil {
+		return nil, err
+	}
+	h, hs := getGenerators(pubKey.Group, len(input))
+	return &ShuffleVerifier{
+		pubKey: pubKey,
+		h:      h,
+		hs:     hs,
+		input:  input,
+		output: output,
+	}, nil
+}
+
+// SetPermutationCommitment stores the commitment u and chooses random exponents.
+func (verifier *ShuffleVerifier) SetPermutationCommitment(u []*big.Int) error {
+	n := len(verifier.input)
+	if len(u) != n || !areElements(verifier.pubKey.Group, u) {
+		return fmt.Errorf("Permutation commitment needs to consist of %d group elements", n)
+	}
+	e, err := randomExponents(verifier.pubKey.Group, n)
+	if err != nil {
+		return err
+	}
+	verifier.u = u
+	verifier.e = e
+	return nil
+}
+
+// GetExponents returns the exponents e which were chosen in SetPermutationCommitment.
+func (verifier *ShuffleVerifier) GetExponents() []*big.Int {
+	return verifier.e
+}
+
+func (verifier *ShuffleVerifier) SetProofRandomData(data *ShuffleProofRandomData) error {
+	group := verifier.pubKey.Group
+	n := len(verifier.input)
+	if data == nil || len(data.B) != n || len(data.THat) != n ||
+		!areElements(group, data.B) || !areElements(group, data.THat) ||
+		!areElements(group, []*big.Int{data.T1, data.T2, data.T3}) ||
+		!verifier.pubKey.IsCiphertext(data.T4) {
+		return fmt.Errorf("Proof random data is not valid")
+	}
+	verifier.proofRandomData = data
+	return nil
+}
+
+func (verifier *ShuffleVerifier) GetChallenge() (*big.Int, error) {
+	challenge, err := common.GetRandomInt(verifier.pubKey.Group.Q)
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return verifier.challenge, nil
+}
+
+// Verify checks all the relations of the sigma protocol (see ShuffleProver).
+func (verifier *ShuffleVerifier) Verify(proofData *ShuffleProofData) bool {
+	data := verifier.proofRandomData
+	n := len(verifier.input)
+	if verifier.e == nil || data == nil || verifier.challenge == nil || proofData == nil ||
+		proofData.K1 == nil || proofData.K2 == nil || proofData.K3 == nil ||
+		proofData.K4 == nil || len(proofData.KHat) != n || len(proofData.KPrime) != n {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		if proofData.KHat[i] == nil || proofData.KPrime[i] == nil {
+			return false
+		}
+	}
+	group := verifier.pubKey.Group
+	q := group.Q
+	c := verifier.challenge
+	// left returns x^c * t
+	left := func(x, t *big.Int) *big.Int {
+		return group.Mul(group.Exp(x, c), t)
+	}
+	gExp := func(k *big.Int) *big.Int {
+		return group.Exp(group.G, new(big.Int).Mod(k, q))
+	}
+
+	// C = prod_j u_j / prod_j h_j
+	cc := big.NewInt(1)
+	for j := 0; j < n; j++ {
+		cc = group.Mul(cc, group.Mul(verifier.u[j], group.Inv(verifier.hs[j])))
+	}
+	if left(cc, data.T1).Cmp(gExp(proofData.K1)) != 0 {
+		return false
+	}
+
+	// D = b_N / h^(prod_i e_i)
+	eProd := big.NewInt(1)
+	for _, e := range verifier.e {
+		eProd.Mul(eProd, e)
+		eProd.Mod(eProd, q)
+	}
+	d := group.Mul(data.B[n-1], group.Inv(group.Exp(verifier.h, eProd)))
+	if left(d, data.T2).Cmp(gExp(proofData.K2)) != 0 {
+		return false
+	}
+
+	// A = prod_j u_j^e_j
+	a := common.MultiExp(verifier.u, verifier.e, group.P)
+	right := group.Mul(gExp(proofData.K3),
+		common.MultiExp(verifier.hs, reduceAll(proofData.KPrime, q), group.P))
+	if left(a, data.T3).Cmp(right) != 0 {
+		return false
+	}
+
+	// F = prod_j input[j]^e_j
+	pubKey := verifier.pubKey
+	f := multiExpCiphertexts(pubKey, verifier.input, verifier.e)
+	leftF := pubKey.Mul(pubKey.Exp(f, c), data.T4)
+	rightF := pubKey.Mul(pubKey.EncryptOne(new(big.Int).Neg(proofData.K4)),
+		multiExpCiphertexts(pubKey, verifier.output, reduceAll(proofData.KPrime, q)))
+	if !leftF.Equals(rightF) {
+		return false
+	}
+
+	prev := verifier.h
+	for i := 0; i < n; i++ {
+		rightB := group.Mul(gExp(proofData.KHat[i]),
+			group.Exp(prev, new(big.Int).Mod(proofData.KPrime[i], q)))
+		if left(data.B[i], data.THat[i]).Cmp(rightB) != 0 {
+			return false
+		}
+		prev = data.B[i]
+	}
+	return true
+}
+
+// getGenerators returns generators h, h_1,...,h_n which are derived by hashing into the group.
+func getGenerators(group *groups.SchnorrGroup, n int) (*big.Int, []*big.Int) {
+	domain := new(big.Int).SetBytes([]byte("shuffle"))
+	hs := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		hs[i] = group.HashIntoElement(domain, big.NewInt(int64(i+1)))
+	}
+	return group.HashIntoElement(domain, big.NewInt(0)), hs
+}
+
+// checkLists checks that input and output are non-empty lists of the same length which
+// contain only valid ciphertexts.
+func checkLists(pubKey *encryption.ElGamalPubKey, input,
+	output []*encryption.ElGamalCiphertext) error {
+	if len(input) == 0 || len(input) != len(output) {
+		return fmt.Errorf("Input and output need to be non-empty lists of the same length")
+	}
+	for i := range input {
+		if !pubKey.IsCiphertext(input[i]) || !pubKey.IsCiphertext(output[i]) {
+			return fmt.Errorf("Ciphertext %d is not from the group", i)
+		}
+	}
+	return nil
+}
+
+// multiExpCiphertexts returns prod_i c_i^exponents_i.
+func multiExpCiphertexts(pubKey *encryption.ElGamalPubKey, c []*encryption.ElGamalCiphertext,
+	exponents []*big.Int) *encryption.ElGamalCiphertext {
+	as := make([]*big.Int, len(c))
+	bs := make([]*big.Int, len(c))
+	for i := range c {
+		as[i], bs[i] = c[i].A, c[i].B
+	}
+	p := pubKey.Group.P
+	return &encryption.ElGamalCiphertext{
+		A: common.MultiExp(as, exponents, p),
+		B: common.MultiExp(bs, exponents, p),
+	}
+}
+
+func randomExponents(group *groups.SchnorrGroup, n int) ([]*big.Int, error) {
+	exponents := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		e, err := common.GetRandomInt(group.Q)
+		if err != nil {
+			return nil, err
+		}
+		exponents[i] = e
+	}
+	return exponents, nil
+}
+
+// randomPermutation returns a random permutation of {0,...,n-1} (Fisher-Yates).
+func randomPermutation(n int) ([]int, error) {
+	permutation := make([]int, n)
+	for i := range permutation {
+		permutation[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j, err := common.GetRandomInt(big.NewInt(int64(i + 1)))
+		if err != nil {
+			return nil, err
+		}
+		permutation[i], permutation[j.Int64()] = permutation[j.Int64()], permutation[i]
+	}
+	return permutation, nil
+}
+
+func isPermutation(permutation []int) bool {
+	seen := make([]bool, len(permutation))
+	for _, j := range permutation {
+		if j < 0 || j >= len(permutation) || seen[j] {
+			return false
+		}
+		seen[j] = true
+	}
+	return true
+}
+
+func areElements(group *groups.SchnorrGroup, elements []*big.Int) bool {
+	for _, el := range elements {
+		if !group.IsElementInGroup(el) {
+			return false
+		}
+	}
+	return true
+}
+
+func reduceAll(values []*big.Int, q *big.Int) []*big.Int {
+	reduced := make([]*big.Int, len(values))
+	for i, v := range values {
+		reduced[i] = new(big.Int).Mod(v, q)
+	}
+	return reduced
+}
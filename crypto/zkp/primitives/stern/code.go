@@ -0,0 +1,193 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package stern
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// Parameters of the code: length N, redundancy R (number of rows of the parity-check
+// matrix) and weight W of the secret, which is close to the Gilbert-Varshamov bound, so
+// that the syndrome decoding problem is hard (about 2^80 operations with the best known
+// information set decoding attacks).
+const (
+	N = 768
+	R = 384
+	W = 76
+)
+
+// seedLen is the byte length of the seeds from which matrices, permutations and vectors
+// are expanded.
+const seedLen = 32
+
+// Params define a random binary linear code by its parity-check matrix H, which is
+// expanded from Seed (so that only the seed needs to be sent).
+type Params struct {
+	Seed []byte
+	h    [][]byte // R rows of N bits
+}
+
+// NewParams returns the parameters with a random parity-check matrix.
+func NewParams() (*Params, error) {
+	seed, err := randomSeed()
+	if err != nil {
+		return nil, err
+	}
+	return NewParamsFromSeed(seed)
+}
+
+// NewParamsFromSeed returns the parameters with the parity-check matrix expanded from seed.
+func NewParamsFromSeed(seed []byte) (*Params, error) {
+	if len(seed) != seedLen {
+		return nil, fmt.Errorf("seed needs to be %d bytes long", seedLen)
+	}
+	rows := expand(seed, "matrix", R*N/8)
+	h := make([][]byte, R)
+	for i := range h {
+		h[i] = rows[i*N/8 : (i+1)*N/8]
+	}
+	return &Params{
+		Seed: seed,
+		h:    h,
+	}, nil
+}
+
+// GenerateKey returns a random secret e (a vector of N bits with weight W) and the public
+// key, which is the syndrome H*e.
+func (params *Params) GenerateKey() ([]byte, []byte, error) {
+	seed, err := randomSeed()
+	if err != nil {
+		return nil, nil, err
+	}
+	e := make([]byte, N/8)
+	for i := 0; i < W; i++ {
+		setBit(e, i, 1)
+	}
+	e = permute(e, permutation(seed))
+	return e, params.Syndrome(e), nil
+}
+
+// Syndrome returns H*v, a vector of R bits.
+func (params *Params) Syndrome(v []byte) []byte {
+	s := make([]byte, R/8)
+	for i, row := range params.h {
+		var acc byte
+		for j := range row {
+			acc ^= row[j] & v[j]
+		}
+		setBit(s, i, parity(acc))
+	}
+	return s
+}
+
+// permutation returns a permutation of N elements, expanded from seed with Fisher-Yates
+// shuffle.
+func permutation(seed []byte) []int {
+	p := make([]int, N)
+	for i := range p {
+		p[i] = i
+	}
+	// 4 bytes per random index, with enough of them for the rejection sampling
+	stream := expand(seed, "permutation", 8*N)
+	for i := N - 1; i > 0; i-- {
+		bound := uint32(i + 1)
+		limit := ^uint32(0) - ^uint32(0)%bound
+		var r uint32
+		for {
+			if len(stream) < 4 {
+				stream = expand(stream, "permutation", 8*N)
+			}
+			r = binary.BigEndian.Uint32(stream)
+			stream = stream[4:]
+			if r < limit {
+				break
+			}
+		}
+		j := int(r % bound)
+		p[i], p[j] = p[j], p[i]
+	}
+	return p
+}
+
+// permute moves the i-th bit of v to the position p[i].
+func permute(v []byte, p []int) []byte {
+	out := make([]byte, len(v))
+	for i, pi := range p {
+		setBit(out, pi, bit(v, i))
+	}
+	return out
+}
+
+// expand returns n pseudorandom bytes derived from seed (SHA-256 in counter mode).
+func expand(seed []byte, label string, n int) []byte {
+	out := make([]byte, 0, n+sha256.Size)
+	var counter [4]byte
+	for i := uint32(0); len(out) < n; i++ {
+		binary.BigEndian.PutUint32(counter[:], i)
+		h := sha256.New()
+		h.Write([]byte(label))
+		h.Write(seed)
+		h.Write(counter[:])
+		out = h.Sum(out)
+	}
+	return out[:n]
+}
+
+func randomSeed() ([]byte, error) {
+	seed := make([]byte, seedLen)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
+func xor(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i]
+	}
+	return out
+}
+
+func weight(v []byte) int {
+	w := 0
+	for _, b := range v {
+		for ; b != 0; b &= b - 1 {
+			w++
+		}
+	}
+	return w
+}
+
+func parity(b byte) byte {
+	b ^= b >> 4
+	b ^= b >> 2
+	b ^= b >> 1
+	return b & 1
+}
+
+func bit(v []byte, i int) byte {
+	return (v[i/8] >> uint(i%8)) & 1
+}
+
+func setBit(v []byte, i int, b byte) {
+	v[i/8] = v[i/8]&^(1<<uint(i%8)) | b<<uint(i%8)
+}
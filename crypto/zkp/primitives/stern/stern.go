@@ -0,0 +1,322 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package stern implements Stern's zero-knowledge identification protocol (J. Stern: A new
+// paradigm for public key identification), which is based on the hardness of decoding
+// random linear codes. Unlike the dlog-based protocols it is not broken by quantum
+// computers, thus it is a post-quantum option for identification.
+//
+// The secret is a vector e of weight W and the public key is its syndrome s = H*e, where
+// H is the parity-check matrix of a random code. In each round:
+//   - prover chooses a random permutation sigma and a random vector y, and commits to
+//     c1 = (sigma, H*y), c2 = sigma(y) and c3 = sigma(y + e),
+//   - verifier chooses a challenge b from {0, 1, 2},
+//   - prover opens two of the commitments: for b = 0 it reveals sigma and y (c1, c2),
+//     for b = 1 sigma and y + e (c1, c3 as H*(y + e) + s = H*y), for b = 2 sigma(y) and
+//     sigma(e) (c2, c3 and the weight of sigma(e) is W).
+//
+// A cheating prover passes a round with probability 2/3, thus SternRounds rounds (or more,
+// see SternProver.SetSecurity) are run in parallel. Permutations and vectors y are expanded from seeds, so that only the seeds
+// need to be revealed.
+package stern
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/zkp/security"
+)
+
+// SternRounds is the default number of parallel rounds, the soundness error
+// is (2/3)^137 < 2^-80.
+const SternRounds = 137
+
+// roundError is the probability that a cheating prover passes a single round.
+const roundError = 2.0 / 3
+
+// rounds returns the number of rounds given by params (def if they do not determine it).
+func rounds(params security.Params, def int) (int, error) {
+	if err := params.Validate(); err != nil {
+		return 0, err
+	}
+	n := params.Runs(roundError, def)
+	if err := security.CheckRepetitions(n, roundError); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// ProveStern demonstrates how prover can identify itself with Stern's protocol.
+func ProveStern(params *Params, secret []byte) (bool, error) {
+	prover, err := NewSternProver(params, secret)
+	if err != nil {
+		return false, err
+	}
+	verifier := NewSternVerifier(params, params.Syndrome(secret))
+
+	commitments, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	challenges, err := verifier.GetChallenges(commitments)
+	if err != nil {
+		return false, err
+	}
+	responses, err := prover.GetProofData(challenges)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(responses), nil
+}
+
+// SternCommitment contains the three commitments of one round.
+type SternCommitment struct {
+	C1 []byte
+	C2 []byte
+	C3 []byte
+}
+
+// SternResponse opens two of the commitments of one round (see the package description),
+// Salts are the salts of the opened commitments. For b = 0 SigmaSeed and YSeed are set,
+// for b = 1 SigmaSeed and Y (which is y + e), and for b = 2 Y (which is sigma(y)) and
+// E (which is sigma(e)).
+type SternResponse struct {
+	SigmaSeed []byte
+	YSeed     []byte
+	Y         []byte
+	E         []byte
+	Salts     [][]byte
+}
+
+type sternRound struct {
+	sigmaSeed []byte
+	ySeed     []byte
+	salts     [][]byte
+}
+
+type SternProver struct {
+	Params    *Params
+	secret    []byte
+	rounds    []*sternRound
+	numRounds int
+}
+
+func NewSternProver(params *Params, secret []byte) (*SternProver, error) {
+	if len(secret) != N/8 || weight(secret) != W {
+		return nil, fmt.Errorf("secret needs to be a vector of %d bits with weight %d", N, W)
+	}
+	return &SternProver{
+		Params:    params,
+		secret:    secret,
+		numRounds: SternRounds,
+	}, nil
+}
+
+// SetSecurity sets the number of rounds to params.Repetitions (or the number of rounds
+// needed for params.Level), which needs to give at least the soundness of SternRounds.
+// The verifier accepts the proofs with
+// the number of rounds set by its own SetSecurity or more.
+func (prover *SternProver) SetSecurity(params security.Params) error {
+	n, err := rounds(params, prover.numRounds)
+	if err != nil {
+		return err
+	}
+	prover.numRounds = n
+	return nil
+}
+
+// GetProofRandomData returns the commitments of all the rounds.
+func (prover *SternProver) GetProofRandomData() ([]*SternCommitment, error) {
+	prover.rounds = make([]*sternRound, prover.numRounds)
+	commitments := make([]*SternCommitment, prover.numRounds)
+	for i := range commitments {
+		round := &sternRound{
+			salts: make([][]byte, 3),
+		}
+		for _, seed := range []*[]byte{&round.sigmaSeed, &round.ySeed, &round.salts[0],
+			&round.salts[1], &round.salts[2]} {
+			s, err := randomSeed()
+			if err != nil {
+				return nil, err
+			}
+			*seed = s
+		}
+		sigma := permutation(round.sigmaSeed)
+		y := expand(round.ySeed, "vector", N/8)
+
+		prover.rounds[i] = round
+		commitments[i] = &SternCommitment{
+			C1: commit(round.salts[0], round.sigmaSeed, prover.Params.Syndrome(y)),
+			C2: commit(round.salts[1], permute(y, sigma)),
+			C3: commit(round.salts[2], permute(xor(y, prover.secret), sigma)),
+		}
+	}
+	return commitments, nil
+}
+
+// GetProofData opens the commitments of each round as requested by the challenges.
+func (prover *SternProver) GetProofData(challenges []int) ([]*SternResponse, error) {
+	if prover.rounds == nil {
+		return nil, fmt.Errorf("proof random data has not been generated")
+	}
+	if len(challenges) != len(prover.rounds) {
+		return nil, fmt.Errorf("%d challenges expected", len(prover.rounds))
+	}
+
+	responses := make([]*SternResponse, len(challenges))
+	for i, b := range challenges {
+		round := prover.rounds[i]
+		y := expand(round.ySeed, "vector", N/8)
+		switch b {
+		case 0:
+			responses[i] = &SternResponse{
+				SigmaSeed: round.sigmaSeed,
+				YSeed:     round.ySeed,
+				Salts:     [][]byte{round.salts[0], round.salts[1]},
+			}
+		case 1:
+			responses[i] = &SternResponse{
+				SigmaSeed: round.sigmaSeed,
+				Y:         xor(y, prover.secret),
+				Salts:     [][]byte{round.salts[0], round.salts[2]},
+			}
+		case 2:
+			sigma := permutation(round.sigmaSeed)
+			responses[i] = &SternResponse{
+				Y:     permute(y, sigma),
+				E:     permute(prover.secret, sigma),
+				Salts: [][]byte{round.salts[1], round.salts[2]},
+			}
+		default:
+			return nil, fmt.Errorf("challenge needs to be 0, 1 or 2")
+		}
+	}
+	prover.rounds = nil
+	return responses, nil
+}
+
+type SternVerifier struct {
+	Params      *Params
+	publicKey   []byte
+	commitments []*SternCommitment
+	challenges  []int
+	minRounds   int
+}
+
+func NewSternVerifier(params *Params, publicKey []byte) *SternVerifier {
+	return &SternVerifier{
+		Params:    params,
+		publicKey: publicKey,
+		minRounds: SternRounds,
+	}
+}
+
+// SetSecurity sets the minimal number of rounds to params.Repetitions (or the number
+// of rounds needed for params.Level), which needs to give at least the soundness
+// of SternRounds.
+func (verifier *SternVerifier) SetSecurity(params security.Params) error {
+	n, err := rounds(params, verifier.minRounds)
+	if err != nil {
+		return err
+	}
+	verifier.minRounds = n
+	return nil
+}
+
+// GetChallenges stores the commitments and returns a random challenge from {0, 1, 2}
+// for each round. At least SternRounds rounds (or the number set by SetSecurity) are
+// required.
+func (verifier *SternVerifier) GetChallenges(commitments []*SternCommitment) ([]int, error) {
+	if len(commitments) < verifier.minRounds || len(commitments) > security.MaxRepetitions {
+		return nil, fmt.Errorf("commitments of %d to %d rounds expected", verifier.minRounds,
+			security.MaxRepetitions)
+	}
+	challenges := make([]int, 0, len(commitments))
+	buf := make([]byte, len(commitments))
+	for len(challenges) < len(commitments) {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		for _, b := range buf {
+			// 255 is rejected so that the challenges are uniform
+			if b < 255 && len(challenges) < len(commitments) {
+				challenges = append(challenges, int(b%3))
+			}
+		}
+	}
+	verifier.commitments = commitments
+	verifier.challenges = challenges
+	return challenges, nil
+}
+
+// Verify checks the opened commitments of all the rounds.
+func (verifier *SternVerifier) Verify(responses []*SternResponse) bool {
+	if verifier.challenges == nil || len(responses) != len(verifier.challenges) ||
+		len(verifier.publicKey) != R/8 {
+		return false
+	}
+	for i, resp := range responses {
+		if resp == nil || len(resp.Salts) != 2 || len(resp.Salts[0]) != seedLen ||
+			len(resp.Salts[1]) != seedLen ||
+			!verifier.verifyRound(verifier.commitments[i], verifier.challenges[i], resp) {
+			return false
+		}
+	}
+	return true
+}
+
+func (verifier *SternVerifier) verifyRound(c *SternCommitment, b int,
+	resp *SternResponse) bool {
+	params := verifier.Params
+	switch b {
+	case 0:
+		if len(resp.SigmaSeed) != seedLen || len(resp.YSeed) != seedLen {
+			return false
+		}
+		sigma := permutation(resp.SigmaSeed)
+		y := expand(resp.YSeed, "vector", N/8)
+		return bytes.Equal(c.C1, commit(resp.Salts[0], resp.SigmaSeed, params.Syndrome(y))) &&
+			bytes.Equal(c.C2, commit(resp.Salts[1], permute(y, sigma)))
+	case 1:
+		if len(resp.SigmaSeed) != seedLen || len(resp.Y) != N/8 {
+			return false
+		}
+		sigma := permutation(resp.SigmaSeed)
+		hy := xor(params.Syndrome(resp.Y), verifier.publicKey)
+		return bytes.Equal(c.C1, commit(resp.Salts[0], resp.SigmaSeed, hy)) &&
+			bytes.Equal(c.C3, commit(resp.Salts[1], permute(resp.Y, sigma)))
+	case 2:
+		if len(resp.Y) != N/8 || len(resp.E) != N/8 || weight(resp.E) != W {
+			return false
+		}
+		return bytes.Equal(c.C2, commit(resp.Salts[0], resp.Y)) &&
+			bytes.Equal(c.C3, commit(resp.Salts[1], xor(resp.Y, resp.E)))
+	}
+	return false
+}
+
+// commit returns SHA-256 of the salt and the values (which are of fixed lengths, thus
+// the encoding is unambiguous).
+func commit(salt []byte, values ...[]byte) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	for _, v := range values {
+		h.Write(v)
+	}
+	return h.Sum(nil)
+}
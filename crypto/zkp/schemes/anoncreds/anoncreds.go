@@ -0,0 +1,336 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package anoncreds implements anonymous credentials with multiple attributes (in the style
+// of Idemix and Hyperledger AnonCreds) on top of CL signatures. The holder obtains
+// the credential on its master secret (hidden from the issuer) and the attributes set by
+// the issuer, and later shows it with a presentation, which discloses only the requested
+// attributes and proves predicates (for example age >= 18) about the hidden ones. Different
+// presentations of the same credential cannot be linked.
+//
+// CL signatures are used rather than BBS+ (see signatures/bbsplus), as their blocks are
+// integers, which is what predicate proofs with integer commitments (Damgard-Fujisaki)
+// require.
+package anoncreds
+
+import (
+	"errors"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"math/big"
+)
+
+// AttributeBitLen is the maximal bit length of the (non-negative) attribute values, as
+// given by the bit length of the blocks of CL signatures.
+const AttributeBitLen = 160
+
+// PublicKey is the issuer's public key: CL public key for the master secret (block 0) and
+// the attributes (block i+1 for Attributes[i]), and the parameters of the commitments which
+// are used in predicate proofs. The keys of DeviceBound credentials have one more block for
+// the device secret (see Device).
+type PublicKey struct {
+	CL          *signatures.CLPubKey
+	Commitments *commitments.DamgardFujisakiParams
+	Attributes  []string
+	DeviceBound bool
+}
+
+type Issuer struct {
+	cl     *signatures.CL
+	pubKey *PublicKey
+}
+
+// NewIssuer generates the key for the credentials with the given attributes. The holders
+// must not know the factorization of the modulus of params (the issuer or the verifiers
+// generate them with commitments.NewDamgardFujisakiParams), otherwise they could prove
+// false predicates.
+func NewIssuer(attributes []string, params *commitments.DamgardFujisakiParams) (*Issuer,
+	error) {
+	return newIssuer(attributes, params, false)
+}
+
+func newIssuer(attributes []string, params *commitments.DamgardFujisakiParams,
+	deviceBound bool) (*Issuer, error) {
+	if len(attributes) == 0 {
+		return nil, errors.New("credential needs at least one attribute")
+	}
+	names := make(map[string]bool, len(attributes))
+	for _, name := range attributes {
+		if name == "" || names[name] {
+			return nil, fmt.Errorf("invalid or repeated attribute name: %q", name)
+		}
+		names[name] = true
+	}
+
+	numOfBlocks := len(attributes) + 1
+	if deviceBound {
+		numOfBlocks++
+	}
+	cl := signatures.NewCL(numOfBlocks)
+	return &Issuer{
+		cl: cl,
+		pubKey: &PublicKey{
+			CL:          cl.GetPubKey(),
+			Commitments: params,
+			Attributes:  append([]string{}, attributes...),
+			DeviceBound: deviceBound,
+		},
+	}, nil
+}
+
+func (issuer *Issuer) GetPublicKey() *PublicKey {
+	return issuer.pubKey
+}
+
+// index returns the index of the block of the attribute (-1 if there is no such attribute).
+func (pubKey *PublicKey) index(name string) int {
+	for i, attribute := range pubKey.Attributes {
+		if attribute == name {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// blocks returns the blocks of the attributes, which need to be given for exactly
+// the attributes of the key.
+func (pubKey *PublicKey) blocks(attributes map[string]*big.Int) (map[int]*big.Int, error) {
+	if len(attributes) != len(pubKey.Attributes) {
+		return nil, errors.New("the number of attributes is not correct")
+	}
+	blocks := make(map[int]*big.Int, len(attributes))
+	for name, value := range attributes {
+		i := pubKey.index(name)
+		if i < 0 {
+			return nil, fmt.Errorf("unknown attribute: %s", name)
+		}
+		if value == nil || value.Sign() < 0 || value.BitLen() > AttributeBitLen {
+			return nil, fmt.Errorf("invalid value of attribute %s", name)
+		}
+		blocks[i] = value
+	}
+	return blocks, nil
+}
+
+// Holder holds the master secret which is hidden in all its credentials.
+type Holder struct {
+	secret *big.Int
+}
+
+func NewHolder() (*Holder, error) {
+	secret, err := common.GetRandomIntOfLength(AttributeBitLen)
+	if err != nil {
+		return nil, err
+	}
+	return &Holder{
+		secret: secret,
+	}, nil
+}
+
+// Credential holds the attributes and the issuer's CL signature on them and the holder's
+// master secret.
+type Credential struct {
+	Attributes map[string]*big.Int
+	secret     *big.Int
+	signature  *signatures.CLSignature
+}
+
+// blocks returns the signed blocks of the credential (the master secret is block 0). The device
+// needs to be given exactly for the credentials of device bound keys.
+func (credential *Credential) blocks(pubKey *PublicKey, device *Device) ([]*big.Int, error) {
+	if pubKey.DeviceBound != (device != nil) {
+		return nil, errors.New("device is required exactly for device bound credentials")
+	}
+	blocks, err := pubKey.blocks(credential.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	blocks[0] = credential.secret
+	if device != nil {
+		blocks[pubKey.deviceIndex()] = device.secret
+	}
+	m_Ls := make([]*big.Int, len(blocks))
+	for i, m := range blocks {
+		m_Ls[i] = m
+	}
+	return m_Ls, nil
+}
+
+// NewPossessionProver returns the prover of the possession of the credential with all
+// the attributes hidden, and the master secret of the credential. It is used by the proofs
+// which are composed with the proof of possession and which are linked to the master secret
+// with its random value (block 0, see signatures.CLPossessionProver.GetBlockRandomValue).
+func NewPossessionProver(pubKey *PublicKey, credential *Credential) (
+	*signatures.CLPossessionProver, *big.Int, error) {
+	m_Ls, err := credential.blocks(pubKey, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	prover, err := signatures.NewCLPossessionProver(pubKey.CL, m_Ls, credential.signature, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prover, credential.secret, nil
+}
+
+// IssueCredential demonstrates how the holder obtains the credential with the given
+// attributes from the issuer.
+func IssueCredential(issuer *Issuer, holder *Holder, attributes map[string]*big.Int) (
+	*Credential, error) {
+	return issueCredential(issuer, holder, nil, attributes)
+}
+
+func issueCredential(issuer *Issuer, holder *Holder, device *Device,
+	attributes map[string]*big.Int) (*Credential, error) {
+	receiver, err := newCredentialReceiver(issuer.GetPublicKey(), holder, device)
+	if err != nil {
+		return nil, err
+	}
+	credentialIssuer, err := NewCredentialIssuer(issuer, attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	U, t, err := receiver.GetProofRandomData()
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := credentialIssuer.GetChallenge(U, t)
+	if err != nil {
+		return nil, err
+	}
+	var v, e, s *big.Int
+	if device != nil {
+		zS1, zM, zD := receiver.GetBoundProofData(challenge)
+		v, e, s, err = credentialIssuer.IssueBoundCredential(zS1, zM, zD)
+	} else {
+		zS1, zM := receiver.GetProofData(challenge)
+		v, e, s, err = credentialIssuer.IssueCredential(zS1, zM)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return receiver.GetCredential(v, e, s, attributes)
+}
+
+// CredentialReceiver is the holder's side of the issuance - it commits to the master secret
+// and proves the knowledge of it (see signatures.CLIssueReceiver).
+type CredentialReceiver struct {
+	pubKey   *PublicKey
+	holder   *Holder
+	receiver *signatures.CLIssueReceiver
+}
+
+// NewCredentialReceiver returns an error for device bound keys (see
+// NewBoundCredentialReceiver).
+func NewCredentialReceiver(pubKey *PublicKey, holder *Holder) (*CredentialReceiver, error) {
+	return newCredentialReceiver(pubKey, holder, nil)
+}
+
+func newCredentialReceiver(pubKey *PublicKey, holder *Holder, device *Device) (
+	*CredentialReceiver, error) {
+	if pubKey.DeviceBound != (device != nil) {
+		return nil, errors.New("device is required exactly for device bound credentials")
+	}
+	hidden := map[int]*big.Int{0: holder.secret}
+	if device != nil {
+		hidden[pubKey.deviceIndex()] = device.secret
+	}
+	receiver, err := signatures.NewCLIssueReceiver(pubKey.CL, hidden)
+	if err != nil {
+		return nil, err
+	}
+	return &CredentialReceiver{
+		pubKey:   pubKey,
+		holder:   holder,
+		receiver: receiver,
+	}, nil
+}
+
+// GetProofRandomData returns the commitment U to the master secret and the first message of
+// the proof of knowledge of it.
+func (receiver *CredentialReceiver) GetProofRandomData() (*big.Int, *big.Int, error) {
+	return receiver.receiver.GetProofRandomData()
+}
+
+// GetProofData returns the responses for the randomness of U and for the master secret.
+func (receiver *CredentialReceiver) GetProofData(challenge *big.Int) (*big.Int, *big.Int) {
+	zS1, zM := receiver.receiver.GetProofData(challenge)
+	return zS1, zM[0]
+}
+
+// GetCredential takes the issuer's (v, e, s) and the attributes set by the issuer and
+// returns the credential, if the signature is valid.
+func (receiver *CredentialReceiver) GetCredential(v, e, s *big.Int,
+	attributes map[string]*big.Int) (*Credential, error) {
+	known, err := receiver.pubKey.blocks(attributes)
+	if err != nil {
+		return nil, err
+	}
+	signature, _, err := receiver.receiver.GetSignature(v, e, s, known)
+	if err != nil {
+		return nil, err
+	}
+
+	credential := &Credential{
+		Attributes: make(map[string]*big.Int, len(attributes)),
+		secret:     receiver.holder.secret,
+		signature:  signature,
+	}
+	for name, value := range attributes {
+		credential.Attributes[name] = new(big.Int).Set(value)
+	}
+	return credential, nil
+}
+
+// CredentialIssuer is the issuer's side of the issuance of the credential with the given
+// attributes.
+type CredentialIssuer struct {
+	pubKey *PublicKey
+	issuer *signatures.CLIssuer
+}
+
+// NewCredentialIssuer returns an error if the attributes are not given for exactly
+// the attributes of the issuer's key or if their values are out of range.
+func NewCredentialIssuer(issuer *Issuer, attributes map[string]*big.Int) (*CredentialIssuer,
+	error) {
+	known, err := issuer.pubKey.blocks(attributes)
+	if err != nil {
+		return nil, err
+	}
+	return &CredentialIssuer{
+		pubKey: issuer.pubKey,
+		issuer: signatures.NewCLIssuer(issuer.cl, known),
+	}, nil
+}
+
+func (issuer *CredentialIssuer) GetChallenge(U, t *big.Int) (*big.Int, error) {
+	return issuer.issuer.GetChallenge(U, t)
+}
+
+// IssueCredential verifies the proof of knowledge of the master secret committed in U and
+// returns the issuer's part of the signature (v, e, s). For device bound keys
+// IssueBoundCredential needs to be used.
+func (issuer *CredentialIssuer) IssueCredential(zS1, zM *big.Int) (*big.Int, *big.Int,
+	*big.Int, error) {
+	if zM == nil {
+		return nil, nil, nil, errors.New("response for the master secret is missing")
+	}
+	return issuer.issuer.Verify(zS1, map[int]*big.Int{0: zM})
+}
@@ -0,0 +1,432 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package anoncreds
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/rangeproofs"
+	"math/big"
+)
+
+// Delegation: the root issuer delegates the right to issue credentials (with the same
+// attributes) to other issuers, which can delegate it further. The delegation certificates
+// are published, and the verifier checks the tree of certificates up to the root, which
+// gives it the set of the authorized issuers (see VerifyDelegation). A credential issued by
+// any of them is shown with DelegatedPresentationProver, which proves in zero knowledge
+// that the credential was issued by one of the authorized issuers (OR proof of the
+// possession of the credential for each of their keys), but not by which one - the verifier
+// learns neither the issuer of the credential nor the chain from it to the root.
+// The anonymity set is thus the set of all the authorized issuers, and the presentation grows
+// linearly with it. Predicates and device bound keys are not supported in delegated
+// presentations.
+
+// Delegator is an issuer which can delegate the right to issue credentials - the root or
+// an issuer to which the right was delegated.
+type Delegator struct {
+	Issuer     *Issuer
+	signingKey *ecdsa.PrivateKey
+}
+
+func NewDelegator(attributes []string, params *commitments.DamgardFujisakiParams) (*Delegator,
+	error) {
+	issuer, err := NewIssuer(attributes, params)
+	if err != nil {
+		return nil, err
+	}
+	signingKey, err := ecdsa.GenerateKey(dlog.GetEllipticCurve(dlog.P256), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Delegator{
+		Issuer:     issuer,
+		signingKey: signingKey,
+	}, nil
+}
+
+// GetSigningPubKey returns the key which verifies the certificates issued by the delegator.
+func (delegator *Delegator) GetSigningPubKey() *ecdsa.PublicKey {
+	return &delegator.signingKey.PublicKey
+}
+
+// Delegate issues the certificate which grants the delegatee the right to issue credentials
+// with the same attributes as the delegator's and to delegate it further.
+func (delegator *Delegator) Delegate(delegatee *Delegator) (*DelegationCertificate, error) {
+	pubKey := delegatee.Issuer.GetPublicKey()
+	if !sameAttributes(delegator.Issuer.GetPublicKey(), pubKey) {
+		return nil, errors.New("delegatee's attributes differ from the delegator's")
+	}
+	signingPubKey := delegatee.GetSigningPubKey()
+	r, s, err := ecdsa.Sign(rand.Reader, delegator.signingKey,
+		hashDelegation(pubKey, signingPubKey))
+	if err != nil {
+		return nil, err
+	}
+	return &DelegationCertificate{
+		PubKey:        pubKey,
+		SigningPubKey: signingPubKey,
+		R:             r,
+		S:             s,
+	}, nil
+}
+
+// DelegationCertificate holds the keys of the delegatee signed by the delegator. It does not
+// name the delegator - VerifyDelegation finds it among the authorized issuers.
+type DelegationCertificate struct {
+	PubKey        *PublicKey
+	SigningPubKey *ecdsa.PublicKey
+	R             *big.Int
+	S             *big.Int
+}
+
+// VerifyDelegation checks that each certificate is signed by the root or by a delegatee of
+// another valid certificate and returns the keys of all the authorized issuers (the root
+// first), which are used in delegated presentations. The certificates can be given in any
+// order.
+func VerifyDelegation(root *PublicKey, rootSigningPubKey *ecdsa.PublicKey,
+	certificates []*DelegationCertificate) ([]*PublicKey, error) {
+	if root.DeviceBound {
+		return nil, errors.New("device bound keys are not supported")
+	}
+	keys := []*PublicKey{root}
+	signers := []*ecdsa.PublicKey{rootSigningPubKey}
+	verified := make([]bool, len(certificates))
+	for found := true; found; {
+		found = false
+		for j, cert := range certificates {
+			if verified[j] || cert == nil || cert.PubKey == nil || cert.SigningPubKey == nil ||
+				cert.R == nil || cert.S == nil {
+				continue
+			}
+			hashed := hashDelegation(cert.PubKey, cert.SigningPubKey)
+			for _, signer := range signers {
+				if ecdsa.Verify(signer, hashed, cert.R, cert.S) {
+					verified[j], found = true, true
+					keys = append(keys, cert.PubKey)
+					signers = append(signers, cert.SigningPubKey)
+					break
+				}
+			}
+		}
+	}
+
+	for j, cert := range certificates {
+		if !verified[j] {
+			return nil, errors.New("delegation certificate is not signed by an authorized issuer")
+		}
+		if !sameAttributes(root, cert.PubKey) || cert.PubKey.DeviceBound {
+			return nil, errors.New("delegated key differs from the root's")
+		}
+	}
+	return keys, nil
+}
+
+// ProveDelegatedCredential demonstrates how the holder of the credential which was issued
+// with pubKey (one of the keys of the authorized issuers) shows it without revealing
+// the issuer.
+func ProveDelegatedCredential(keys []*PublicKey, pubKey *PublicKey, credential *Credential,
+	request *PresentationRequest) (bool, error) {
+	prover, err := NewDelegatedPresentationProver(keys, pubKey, credential, request)
+	if err != nil {
+		return false, err
+	}
+	disclosed := make(map[string]*big.Int, len(request.Disclosed))
+	for _, name := range request.Disclosed {
+		disclosed[name] = credential.Attributes[name]
+	}
+	verifier, err := NewDelegatedPresentationVerifier(keys, request, disclosed)
+	if err != nil {
+		return false, err
+	}
+
+	proofRandomData, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge(proofRandomData)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+// DelegatedProofRandomData holds the randomized signature and the first message of
+// the proof of possession for each of the keys.
+type DelegatedProofRandomData struct {
+	V []*big.Int
+	T []*big.Int
+}
+
+// DelegatedProofData holds the challenge and the responses of the proof of possession
+// for each of the keys - the challenges XOR to the verifier's challenge.
+type DelegatedProofData struct {
+	Challenges []*big.Int
+	ZE         []*big.Int
+	ZS         []*big.Int
+	ZM         []map[int]*big.Int
+}
+
+// DelegatedPresentationProver proves the possession of the credential for the key of its
+// issuer and simulates the proofs for the other keys with the challenges it chooses itself.
+// The challenge for the issuer's key is then the XOR of the verifier's challenge and
+// the chosen challenges, so the prover can simulate all but one proof.
+type DelegatedPresentationProver struct {
+	keys       []*PublicKey
+	index      int
+	possession *signatures.CLPossessionProver
+	disclosed  []map[int]*big.Int
+	data       *DelegatedProofData
+}
+
+// NewDelegatedPresentationProver returns an error if pubKey is not among the keys, if
+// the request has predicates or if it is not valid for the keys.
+func NewDelegatedPresentationProver(keys []*PublicKey, pubKey *PublicKey,
+	credential *Credential, request *PresentationRequest) (*DelegatedPresentationProver,
+	error) {
+	index := -1
+	for i, key := range keys {
+		if key.CL.GetN().Cmp(pubKey.CL.GetN()) == 0 {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, errors.New("issuer's key is not among the authorized keys")
+	}
+	disclosed, err := checkDelegatedRequest(keys, request, credential.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	m_Ls, err := credential.blocks(pubKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	indices := make([]int, 0, len(disclosed[index]))
+	for i := range disclosed[index] {
+		indices = append(indices, i)
+	}
+	possession, err := signatures.NewCLPossessionProver(pubKey.CL, m_Ls,
+		credential.signature, indices)
+	if err != nil {
+		return nil, err
+	}
+	return &DelegatedPresentationProver{
+		keys:       keys,
+		index:      index,
+		possession: possession,
+		disclosed:  disclosed,
+	}, nil
+}
+
+func (prover *DelegatedPresentationProver) GetProofRandomData() (*DelegatedProofRandomData,
+	error) {
+	n := len(prover.keys)
+	randomData := &DelegatedProofRandomData{
+		V: make([]*big.Int, n),
+		T: make([]*big.Int, n),
+	}
+	prover.data = &DelegatedProofData{
+		Challenges: make([]*big.Int, n),
+		ZE:         make([]*big.Int, n),
+		ZS:         make([]*big.Int, n),
+		ZM:         make([]map[int]*big.Int, n),
+	}
+	for i, key := range prover.keys {
+		if i == prover.index {
+			randomData.V[i], randomData.T[i] = prover.possession.GetProofRandomData()
+			continue
+		}
+		challenge, err := common.GetRandomIntOfLength(rangeproofs.IntegerChallengeBitLength)
+		if err != nil {
+			return nil, err
+		}
+		v, t, zE, zS, zM, err := signatures.SimulateCLPossession(key.CL, prover.disclosed[i],
+			challenge)
+		if err != nil {
+			return nil, err
+		}
+		randomData.V[i], randomData.T[i] = v, t
+		prover.data.Challenges[i] = challenge
+		prover.data.ZE[i], prover.data.ZS[i], prover.data.ZM[i] = zE, zS, zM
+	}
+	return randomData, nil
+}
+
+func (prover *DelegatedPresentationProver) GetProofData(
+	challenge *big.Int) *DelegatedProofData {
+	c := new(big.Int).Set(challenge)
+	for i, ci := range prover.data.Challenges {
+		if i != prover.index {
+			c.Xor(c, ci)
+		}
+	}
+	i := prover.index
+	prover.data.Challenges[i] = c
+	prover.data.ZE[i], prover.data.ZS[i], prover.data.ZM[i] =
+		prover.possession.GetProofData(c)
+	return prover.data
+}
+
+type DelegatedPresentationVerifier struct {
+	keys       []*PublicKey
+	possession []*signatures.CLPossessionVerifier
+	v          []*big.Int
+	t          []*big.Int
+	challenge  *big.Int
+}
+
+// NewDelegatedPresentationVerifier returns the verifier of the presentation of a credential
+// issued with one of the keys (see VerifyDelegation) with the given values of the disclosed
+// attributes.
+func NewDelegatedPresentationVerifier(keys []*PublicKey, request *PresentationRequest,
+	disclosed map[string]*big.Int) (*DelegatedPresentationVerifier, error) {
+	if len(disclosed) != len(request.Disclosed) {
+		return nil, errors.New("disclosed attributes do not match the request")
+	}
+	blocks, err := checkDelegatedRequest(keys, request, disclosed)
+	if err != nil {
+		return nil, err
+	}
+	possession := make([]*signatures.CLPossessionVerifier, len(keys))
+	for i, key := range keys {
+		possession[i] = signatures.NewCLPossessionVerifier(key.CL, blocks[i])
+	}
+	return &DelegatedPresentationVerifier{
+		keys:       keys,
+		possession: possession,
+	}, nil
+}
+
+func (verifier *DelegatedPresentationVerifier) GetChallenge(
+	data *DelegatedProofRandomData) (*big.Int, error) {
+	if data == nil || len(data.V) != len(verifier.keys) || len(data.T) != len(verifier.keys) {
+		return nil, errors.New("presentation proof random data is not complete")
+	}
+	challenge, err := common.GetRandomIntOfLength(rangeproofs.IntegerChallengeBitLength)
+	if err != nil {
+		return nil, err
+	}
+	verifier.v = data.V
+	verifier.t = data.T
+	verifier.challenge = challenge
+	return challenge, nil
+}
+
+// Verify checks that the challenges XOR to the verifier's challenge and are not longer than
+// it, and the proof of possession for each of the keys.
+func (verifier *DelegatedPresentationVerifier) Verify(data *DelegatedProofData) bool {
+	n := len(verifier.keys)
+	if verifier.challenge == nil || data == nil || len(data.Challenges) != n ||
+		len(data.ZE) != n || len(data.ZS) != n || len(data.ZM) != n {
+		return false
+	}
+	c := new(big.Int)
+	for _, ci := range data.Challenges {
+		if ci == nil || ci.Sign() < 0 || ci.BitLen() > rangeproofs.IntegerChallengeBitLength {
+			return false
+		}
+		c.Xor(c, ci)
+	}
+	if c.Cmp(verifier.challenge) != 0 {
+		return false
+	}
+	for i, possession := range verifier.possession {
+		possession.SetChallenge(verifier.v[i], verifier.t[i], data.Challenges[i])
+		if !possession.Verify(data.ZE[i], data.ZS[i], data.ZM[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkDelegatedRequest checks that the request has no predicates and is valid for all
+// the keys, and returns the blocks of the disclosed attributes for each key.
+func checkDelegatedRequest(keys []*PublicKey, request *PresentationRequest,
+	values map[string]*big.Int) ([]map[int]*big.Int, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no authorized keys")
+	}
+	if len(request.Predicates) > 0 {
+		return nil, errors.New("predicates are not supported in delegated presentations")
+	}
+	blocks := make([]map[int]*big.Int, len(keys))
+	for i, key := range keys {
+		if key.DeviceBound {
+			return nil, errors.New("device bound keys are not supported")
+		}
+		indices, _, err := request.check(key)
+		if err != nil {
+			return nil, err
+		}
+		blocks[i] = make(map[int]*big.Int, len(indices))
+		for j, index := range indices {
+			value := values[request.Disclosed[j]]
+			if value == nil {
+				return nil, errors.New("disclosed attributes do not match the request")
+			}
+			blocks[i][index] = value
+		}
+	}
+	return blocks, nil
+}
+
+func sameAttributes(pubKey1, pubKey2 *PublicKey) bool {
+	if len(pubKey1.Attributes) != len(pubKey2.Attributes) {
+		return false
+	}
+	for i, name := range pubKey1.Attributes {
+		if pubKey2.Attributes[i] != name {
+			return false
+		}
+	}
+	return true
+}
+
+// hashDelegation hashes the keys of the delegatee, where each value is prefixed by its
+// length, so that different keys cannot have the same encoding.
+func hashDelegation(pubKey *PublicKey, signingPubKey *ecdsa.PublicKey) []byte {
+	var values [][]byte
+	cl := pubKey.CL
+	for _, x := range []*big.Int{cl.GetN(), cl.GetB(), cl.GetC()} {
+		values = append(values, x.Bytes())
+	}
+	for _, a := range cl.GetA() {
+		values = append(values, a.Bytes())
+	}
+	if params := pubKey.Commitments; params != nil {
+		values = append(values, params.N.Bytes(), params.G.Bytes(), params.H.Bytes())
+	}
+	for _, name := range pubKey.Attributes {
+		values = append(values, []byte(name))
+	}
+	values = append(values, signingPubKey.X.Bytes(), signingPubKey.Y.Bytes())
+
+	h := sha512.New()
+	l := make([]byte, 8)
+	for _, value := range values {
+		binary.BigEndian.PutUint64(l, uint64(len(value)))
+		h.Write(l)
+		h.Write(value)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package anoncreds
+
+import (
+	"errors"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/common"
+	"math/big"
+)
+
+// Device holds the secret of the holder's device, which never leaves the device. Credentials
+// of device bound keys (see NewDeviceBoundIssuer) are bound to it: the device secret is
+// the last block of the credential, which is committed to when the credential is issued
+// (thus hidden from the issuer, as the master secret is) and which is never disclosed, thus
+// each presentation proves the knowledge of it in zero knowledge. The credential does not
+// contain the device secret, so a stolen credential cannot be shown from another device,
+// and the presentations of the same device cannot be linked.
+type Device struct {
+	secret *big.Int
+}
+
+func NewDevice() (*Device, error) {
+	secret, err := common.GetRandomIntOfLength(AttributeBitLen)
+	if err != nil {
+		return nil, err
+	}
+	return &Device{
+		secret: secret,
+	}, nil
+}
+
+// NewDeviceBoundIssuer is as NewIssuer, but the credentials are bound to the holder's device.
+func NewDeviceBoundIssuer(attributes []string,
+	params *commitments.DamgardFujisakiParams) (*Issuer, error) {
+	return newIssuer(attributes, params, true)
+}
+
+// deviceIndex returns the index of the block of the device secret.
+func (pubKey *PublicKey) deviceIndex() int {
+	return len(pubKey.Attributes) + 1
+}
+
+// IssueBoundCredential demonstrates how the holder obtains the credential which is bound to
+// the device.
+func IssueBoundCredential(issuer *Issuer, holder *Holder, device *Device,
+	attributes map[string]*big.Int) (*Credential, error) {
+	if device == nil {
+		return nil, errors.New("device is required")
+	}
+	return issueCredential(issuer, holder, device, attributes)
+}
+
+// NewBoundCredentialReceiver returns the receiver of the credential which is bound to
+// the device - it commits to the device secret together with the master secret.
+func NewBoundCredentialReceiver(pubKey *PublicKey, holder *Holder, device *Device) (
+	*CredentialReceiver, error) {
+	if device == nil {
+		return nil, errors.New("device is required")
+	}
+	return newCredentialReceiver(pubKey, holder, device)
+}
+
+// GetBoundProofData returns the responses for the randomness of U, for the master secret and
+// for the device secret.
+func (receiver *CredentialReceiver) GetBoundProofData(challenge *big.Int) (*big.Int, *big.Int,
+	*big.Int) {
+	zS1, zM := receiver.receiver.GetProofData(challenge)
+	return zS1, zM[0], zM[receiver.pubKey.deviceIndex()]
+}
+
+// IssueBoundCredential verifies the proof of knowledge of the master secret and the device
+// secret committed in U and returns the issuer's part of the signature (v, e, s).
+func (issuer *CredentialIssuer) IssueBoundCredential(zS1, zM, zD *big.Int) (*big.Int,
+	*big.Int, *big.Int, error) {
+	if !issuer.pubKey.DeviceBound {
+		return nil, nil, nil, errors.New("credentials are not bound to devices")
+	}
+	if zM == nil || zD == nil {
+		return nil, nil, nil, errors.New("response for the master or device secret is missing")
+	}
+	return issuer.issuer.Verify(zS1, map[int]*big.Int{0: zM, issuer.pubKey.deviceIndex(): zD})
+}
+
+// NewBoundPresentationProver is as NewPresentationProver, but for the credential which is
+// bound to the device - the device proves the knowledge of its secret as a hidden block of
+// the credential. The verifier (see NewPresentationVerifier) is the same as for other
+// credentials.
+func NewBoundPresentationProver(pubKey *PublicKey, credential *Credential, device *Device,
+	request *PresentationRequest) (*PresentationProver, error) {
+	if device == nil {
+		return nil, errors.New("device is required")
+	}
+	return newPresentationProver(pubKey, credential, device, request)
+}
+
+// ProveBoundCredential demonstrates how the holder shows the credential which is bound to
+// the device.
+func ProveBoundCredential(pubKey *PublicKey, credential *Credential, device *Device,
+	request *PresentationRequest) (bool, error) {
+	if device == nil {
+		return false, errors.New("device is required")
+	}
+	return proveCredential(pubKey, credential, device, request)
+}
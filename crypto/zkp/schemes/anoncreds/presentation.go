@@ -0,0 +1,427 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package anoncreds
+
+import (
+	"errors"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/rangeproofs"
+	"math/big"
+)
+
+type PredicateType int
+
+const (
+	GreaterOrEqual PredicateType = iota
+	LessOrEqual
+)
+
+func (t PredicateType) String() string {
+	switch t {
+	case GreaterOrEqual:
+		return ">="
+	case LessOrEqual:
+		return "<="
+	}
+	return fmt.Sprintf("PredicateType(%d)", int(t))
+}
+
+// ParsePredicateType returns the type of the predicate given by its operator (">=" or "<=").
+func ParsePredicateType(s string) (PredicateType, error) {
+	for _, t := range []PredicateType{GreaterOrEqual, LessOrEqual} {
+		if t.String() == s {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown predicate: %s", s)
+}
+
+// Predicate states that the value of the hidden attribute is greater (or less) than or
+// equal to Bound (which needs to be non-negative).
+type Predicate struct {
+	Attribute string
+	Type      PredicateType
+	Bound     *big.Int
+}
+
+// PresentationRequest specifies the attributes which are disclosed in the presentation and
+// the predicates which are proved about the hidden ones.
+type PresentationRequest struct {
+	Disclosed  []string
+	Predicates []*Predicate
+}
+
+// check returns the indices of the blocks of the disclosed attributes and of the attributes
+// of the predicates (which need to be hidden).
+func (request *PresentationRequest) check(pubKey *PublicKey) ([]int, []int, error) {
+	disclosed := make([]int, len(request.Disclosed))
+	isDisclosed := make(map[int]bool, len(request.Disclosed))
+	for j, name := range request.Disclosed {
+		i := pubKey.index(name)
+		if i < 0 || isDisclosed[i] {
+			return nil, nil, fmt.Errorf("unknown or repeated attribute: %s", name)
+		}
+		disclosed[j] = i
+		isDisclosed[i] = true
+	}
+	predicates := make([]int, len(request.Predicates))
+	for j, predicate := range request.Predicates {
+		// the values of the attributes are non-negative, thus negative bounds are useless
+		if predicate == nil || predicate.Bound == nil || predicate.Bound.Sign() < 0 {
+			return nil, nil, errors.New("predicate needs a non-negative bound")
+		}
+		if predicate.Type != GreaterOrEqual && predicate.Type != LessOrEqual {
+			return nil, nil, fmt.Errorf("unknown predicate type: %v", predicate.Type)
+		}
+		i := pubKey.index(predicate.Attribute)
+		if i < 0 || isDisclosed[i] {
+			return nil, nil, fmt.Errorf("predicate on unknown or disclosed attribute: %s",
+				predicate.Attribute)
+		}
+		predicates[j] = i
+	}
+	return disclosed, predicates, nil
+}
+
+// ProveCredential demonstrates how the holder shows the credential to the verifier,
+// disclosing the attributes and proving the predicates of the request.
+func ProveCredential(pubKey *PublicKey, credential *Credential,
+	request *PresentationRequest) (bool, error) {
+	return proveCredential(pubKey, credential, nil, request)
+}
+
+func proveCredential(pubKey *PublicKey, credential *Credential, device *Device,
+	request *PresentationRequest) (bool, error) {
+	prover, err := newPresentationProver(pubKey, credential, device, request)
+	if err != nil {
+		return false, err
+	}
+	disclosed := make(map[string]*big.Int, len(request.Disclosed))
+	for _, name := range request.Disclosed {
+		disclosed[name] = credential.Attributes[name]
+	}
+	verifier, err := NewPresentationVerifier(pubKey, request, disclosed)
+	if err != nil {
+		return false, err
+	}
+
+	proofRandomData, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge(proofRandomData)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+// PresentationProofRandomData holds the randomized signature V and the first message T of
+// the proof of possession of the credential and the data of each predicate.
+type PresentationProofRandomData struct {
+	V          *big.Int
+	T          *big.Int
+	Predicates []*PredicateProofRandomData
+}
+
+// PredicateProofRandomData holds the commitment C to the attribute, the first message T of
+// the proof that C commits to the attribute of the credential and the proof random data of
+// the non-negativity proof.
+type PredicateProofRandomData struct {
+	C           *big.Int
+	T           *big.Int
+	NonNegative *rangeproofs.NonNegativeProofRandomData
+}
+
+// PresentationProofData holds the responses of the proof of possession (ZM for the hidden
+// blocks, where the master secret is block 0, the attribute i is block i+1 and the device
+// secret of device bound credentials is the last block) and the responses of each predicate.
+type PresentationProofData struct {
+	ZE         *big.Int
+	ZS         *big.Int
+	ZM         map[int]*big.Int
+	Predicates []*PredicateProofData
+}
+
+// PredicateProofData holds the response for the randomness of the commitment to
+// the attribute and the responses of the non-negativity proof.
+type PredicateProofData struct {
+	ZR          *big.Int
+	NonNegative []*rangeproofs.SquareProofData
+}
+
+// PresentationProver proves the possession of the credential (see
+// signatures.CLPossessionProver), where the disclosed attributes are given to the verifier.
+// For each predicate it commits to the attribute as C = g^m * h^r (Damgard-Fujisaki), proves
+// that C commits to the block of the credential (the response for m is the same as in
+// the proof of possession) and that m - bound (or bound - m) committed in C / g^bound
+// (or g^bound / C) is non-negative (see rangeproofs.NonNegativeProver). All proofs use
+// the same challenge.
+type PresentationProver struct {
+	pubKey     *PublicKey
+	possession *signatures.CLPossessionProver
+	predicates []*predicateProver
+}
+
+type predicateProver struct {
+	index       int
+	m           *big.Int
+	r           *big.Int
+	c           *big.Int
+	rR          *big.Int
+	nonNegative *rangeproofs.NonNegativeProver
+}
+
+// NewPresentationProver returns an error if the request is not valid for the key or if
+// the credential does not satisfy its predicates. For device bound keys
+// NewBoundPresentationProver needs to be used.
+func NewPresentationProver(pubKey *PublicKey, credential *Credential,
+	request *PresentationRequest) (*PresentationProver, error) {
+	return newPresentationProver(pubKey, credential, nil, request)
+}
+
+func newPresentationProver(pubKey *PublicKey, credential *Credential, device *Device,
+	request *PresentationRequest) (*PresentationProver, error) {
+	disclosed, predicateIndices, err := request.check(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	m_Ls, err := credential.blocks(pubKey, device)
+	if err != nil {
+		return nil, err
+	}
+	possession, err := signatures.NewCLPossessionProver(pubKey.CL, m_Ls,
+		credential.signature, disclosed)
+	if err != nil {
+		return nil, err
+	}
+
+	params := pubKey.Commitments
+	predicates := make([]*predicateProver, len(request.Predicates))
+	for j, predicate := range request.Predicates {
+		i := predicateIndices[j]
+		r, err := common.GetRandomInt(params.RandomnessBound())
+		if err != nil {
+			return nil, err
+		}
+		// x = m - bound and r' = r for >=, x = bound - m and r' = -r for <=
+		x := new(big.Int).Sub(m_Ls[i], predicate.Bound)
+		rPrime := new(big.Int).Set(r)
+		if predicate.Type == LessOrEqual {
+			x.Neg(x)
+			rPrime.Neg(rPrime)
+		}
+		if x.Sign() < 0 {
+			return nil, fmt.Errorf("credential does not satisfy the predicate %s %v %v",
+				predicate.Attribute, predicate.Type, predicate.Bound)
+		}
+		nonNegative, err := rangeproofs.NewNonNegativeProver(params, x, rPrime)
+		if err != nil {
+			return nil, err
+		}
+		predicates[j] = &predicateProver{
+			index:       i,
+			m:           m_Ls[i],
+			r:           r,
+			c:           params.Commit(m_Ls[i], r),
+			nonNegative: nonNegative,
+		}
+	}
+
+	return &PresentationProver{
+		pubKey:     pubKey,
+		possession: possession,
+		predicates: predicates,
+	}, nil
+}
+
+func (prover *PresentationProver) GetProofRandomData() (*PresentationProofRandomData, error) {
+	params := prover.pubKey.Commitments
+	v, t := prover.possession.GetProofRandomData()
+	data := &PresentationProofRandomData{
+		V:          v,
+		T:          t,
+		Predicates: make([]*PredicateProofRandomData, len(prover.predicates)),
+	}
+	for j, predicate := range prover.predicates {
+		rR, err := common.GetRandomIntOfLength(params.RandomnessBound().BitLen() +
+			rangeproofs.IntegerChallengeBitLength + commitments.DamgardFujisakiK)
+		if err != nil {
+			return nil, err
+		}
+		predicate.rR = rR
+		nonNegative, err := predicate.nonNegative.GetProofRandomData()
+		if err != nil {
+			return nil, err
+		}
+		data.Predicates[j] = &PredicateProofRandomData{
+			C:           predicate.c,
+			T:           params.Commit(prover.possession.GetBlockRandomValue(predicate.index), rR),
+			NonNegative: nonNegative,
+		}
+	}
+	return data, nil
+}
+
+func (prover *PresentationProver) GetProofData(challenge *big.Int) *PresentationProofData {
+	zE, zS, zM := prover.possession.GetProofData(challenge)
+	data := &PresentationProofData{
+		ZE:         zE,
+		ZS:         zS,
+		ZM:         zM,
+		Predicates: make([]*PredicateProofData, len(prover.predicates)),
+	}
+	for j, predicate := range prover.predicates {
+		zR := new(big.Int).Mul(challenge, predicate.r)
+		data.Predicates[j] = &PredicateProofData{
+			ZR:          zR.Add(zR, predicate.rR),
+			NonNegative: predicate.nonNegative.GetProofData(challenge),
+		}
+	}
+	return data
+}
+
+type PresentationVerifier struct {
+	pubKey     *PublicKey
+	request    *PresentationRequest
+	possession *signatures.CLPossessionVerifier
+	predicates []*predicateVerifier
+	challenge  *big.Int
+}
+
+type predicateVerifier struct {
+	index       int
+	c           *big.Int
+	t           *big.Int
+	nonNegative *rangeproofs.NonNegativeVerifier
+}
+
+// NewPresentationVerifier returns the verifier of the presentation with the given values of
+// the disclosed attributes, which need to be given for exactly the attributes disclosed by
+// the request.
+func NewPresentationVerifier(pubKey *PublicKey, request *PresentationRequest,
+	disclosed map[string]*big.Int) (*PresentationVerifier, error) {
+	indices, _, err := request.check(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(disclosed) != len(indices) {
+		return nil, errors.New("disclosed attributes do not match the request")
+	}
+	blocks := make(map[int]*big.Int, len(indices))
+	for j, i := range indices {
+		value := disclosed[request.Disclosed[j]]
+		if value == nil {
+			return nil, errors.New("disclosed attributes do not match the request")
+		}
+		blocks[i] = value
+	}
+
+	return &PresentationVerifier{
+		pubKey:     pubKey,
+		request:    request,
+		possession: signatures.NewCLPossessionVerifier(pubKey.CL, blocks),
+	}, nil
+}
+
+// GetChallenge checks that the proof random data is given for each predicate and returns
+// the challenge for all the proofs.
+func (verifier *PresentationVerifier) GetChallenge(data *PresentationProofRandomData) (
+	*big.Int, error) {
+	params := verifier.pubKey.Commitments
+	if data == nil || data.V == nil || data.T == nil ||
+		len(data.Predicates) != len(verifier.request.Predicates) {
+		return nil, errors.New("presentation proof random data is not complete")
+	}
+	_, indices, err := verifier.request.check(verifier.pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	predicates := make([]*predicateVerifier, len(data.Predicates))
+	for j, predicate := range verifier.request.Predicates {
+		d := data.Predicates[j]
+		if d == nil || !isInvertible(d.C, params.N) || !isInvertible(d.T, params.N) {
+			return nil, errors.New("invalid commitment to the attribute")
+		}
+		// C / g^bound for >=, g^bound / C for <=
+		c := common.Exponentiate(params.G, new(big.Int).Neg(predicate.Bound), params.N)
+		c.Mul(c, d.C)
+		c.Mod(c, params.N)
+		if predicate.Type == LessOrEqual {
+			c.ModInverse(c, params.N)
+		}
+		nonNegative := rangeproofs.NewNonNegativeVerifier(params, c)
+		if err := nonNegative.SetProofRandomData(d.NonNegative); err != nil {
+			return nil, err
+		}
+		predicates[j] = &predicateVerifier{
+			index:       indices[j],
+			c:           d.C,
+			t:           d.T,
+			nonNegative: nonNegative,
+		}
+	}
+
+	challenge, err := common.GetRandomIntOfLength(rangeproofs.IntegerChallengeBitLength)
+	if err != nil {
+		return nil, err
+	}
+	verifier.possession.SetChallenge(data.V, data.T, challenge)
+	for _, predicate := range predicates {
+		predicate.nonNegative.SetChallenge(challenge)
+	}
+	verifier.predicates = predicates
+	verifier.challenge = challenge
+	return challenge, nil
+}
+
+// Verify checks the proof of possession of the credential (which bounds e of the signature,
+// see signatures.CLPossessionVerifier - a credential forged from the public key with e = 1
+// is thus rejected), that the commitment of each predicate commits to the attribute of
+// the credential (g^zM * h^zR = T * C^challenge) and the non-negativity proofs.
+func (verifier *PresentationVerifier) Verify(data *PresentationProofData) bool {
+	params := verifier.pubKey.Commitments
+	if verifier.challenge == nil || data == nil ||
+		len(data.Predicates) != len(verifier.predicates) ||
+		!verifier.possession.Verify(data.ZE, data.ZS, data.ZM) {
+		return false
+	}
+	for j, predicate := range verifier.predicates {
+		d := data.Predicates[j]
+		if d == nil || d.ZR == nil {
+			return false
+		}
+		left := params.Commit(data.ZM[predicate.index], d.ZR)
+		right := new(big.Int).Exp(predicate.c, verifier.challenge, params.N)
+		right.Mul(right, predicate.t)
+		right.Mod(right, params.N)
+		if left == nil || left.Cmp(right) != 0 || !predicate.nonNegative.Verify(d.NonNegative) {
+			return false
+		}
+	}
+	return true
+}
+
+func isInvertible(x, n *big.Int) bool {
+	return x != nil && x.Sign() > 0 && x.Cmp(n) < 0 &&
+		new(big.Int).GCD(nil, nil, x, n).Cmp(big.NewInt(1)) == 0
+}
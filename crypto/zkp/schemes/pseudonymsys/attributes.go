@@ -0,0 +1,384 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pseudonymsys
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/rangeproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/anoncreds"
+	"math/big"
+)
+
+// AttributeBitLen is the bit length of the values of the attributes embedded into
+// the credentials (for example ages, roles and expiry dates as Unix seconds).
+const AttributeBitLen = 64
+
+// Attributes returns the values of the attributes (by name) which the organization embeds
+// into the credential for the nym (a, b), or an error if no credential is to be issued.
+type Attributes func(a, b *big.Int) (map[string]*big.Int, error)
+
+// OrgAttributeKeys are the keys with which the organization signs the attributes of
+// the credentials - secret t_i for the attribute with name Names[i].
+//
+// The attribute with value m is committed as V = a^m * A^r, where (a, b) is the nym,
+// A = b^s2 is a part of the credential and r is chosen by the organization (the user cannot
+// open V to another value as it does not know log_a(A)). The organization signs it as
+// D = V^t_i and includes D into B = (a * A * prod(D_i))^s1, so the user needs to blind
+// all the parts of the credential with the same gamma. The organization proves that
+// log_V(D) = log_g(g^t_i) with a blinded transcript as for A and B.
+type OrgAttributeKeys struct {
+	Names   []string
+	secrets []*big.Int
+}
+
+// NewOrgAttributeKeys generates the keys for the attributes with the given names.
+func NewOrgAttributeKeys(group *groups.SchnorrGroup, names []string) (*OrgAttributeKeys, error) {
+	if err := checkAttributeNames(names); err != nil {
+		return nil, err
+	}
+	secrets := make([]*big.Int, len(names))
+	for i := range names {
+		t, err := common.GetRandomInt(group.Q)
+		if err != nil {
+			return nil, err
+		}
+		secrets[i] = t
+	}
+	return &OrgAttributeKeys{
+		Names:   names,
+		secrets: secrets,
+	}, nil
+}
+
+// GetPubKeys returns the public keys g^t_i of the attributes.
+func (keys *OrgAttributeKeys) GetPubKeys(group *groups.SchnorrGroup) *OrgAttributePubKeys {
+	h := make([]*big.Int, len(keys.secrets))
+	for i, t := range keys.secrets {
+		h[i] = group.Exp(group.G, t)
+	}
+	return NewOrgAttributePubKeys(keys.Names, h)
+}
+
+// OrgAttributePubKeys are the public keys H[i] of the attributes with names Names[i].
+type OrgAttributePubKeys struct {
+	Names []string
+	H     []*big.Int
+}
+
+func NewOrgAttributePubKeys(names []string, h []*big.Int) *OrgAttributePubKeys {
+	return &OrgAttributePubKeys{
+		Names: names,
+		H:     h,
+	}
+}
+
+// CredentialAttribute is an attribute embedded into the credential, blinded together with
+// the credential: V is the commitment (a^gamma)^Value * (A^gamma)^R, D = V^t_i and
+// T is the transcript of the organization's proof that log_V(D) = log_g(g^t_i).
+// Value and R are nil for the attributes which are hidden in the presented credential.
+type CredentialAttribute struct {
+	Name  string
+	Value *big.Int
+	R     *big.Int
+	V     *big.Int
+	D     *big.Int
+	T     *dlogproofs.Transcript
+}
+
+// IssuedAttribute is an attribute as it is sent by the organization when issuing
+// the credential: the value, the randomness of its commitment, D and the proof random data
+// (X1, X2) of the proof that log_V(D) = log_g(g^t_i).
+type IssuedAttribute struct {
+	Name  string
+	Value *big.Int
+	R     *big.Int
+	D     *big.Int
+	X1    *big.Int
+	X2    *big.Int
+}
+
+// Attribute returns the attribute of the credential with the given name or nil if
+// the credential does not have it.
+func (credential *Credential) Attribute(name string) *CredentialAttribute {
+	for _, attr := range credential.Attributes {
+		if attr.Name == name {
+			return attr
+		}
+	}
+	return nil
+}
+
+// Present returns the copy of the credential where only the attributes with the given
+// names are disclosed - the values of the others are removed.
+func (credential *Credential) Present(disclosed []string) *Credential {
+	isDisclosed := make(map[string]bool, len(disclosed))
+	for _, name := range disclosed {
+		isDisclosed[name] = true
+	}
+	presented := *credential
+	presented.Attributes = make([]*CredentialAttribute, len(credential.Attributes))
+	for i, attr := range credential.Attributes {
+		a := *attr
+		if !isDisclosed[attr.Name] {
+			a.Value = nil
+			a.R = nil
+		}
+		presented.Attributes[i] = &a
+	}
+	return &presented
+}
+
+// commitAttribute returns a^value * A^r.
+func commitAttribute(group *groups.SchnorrGroup, a, A, value, r *big.Int) *big.Int {
+	return group.Mul(group.Exp(a, value), group.Exp(A, r))
+}
+
+// verifyAttributes checks the transcripts of the attributes of the credential (which need
+// to be exactly those of pubKeys, in the same order) and returns the product of their D.
+// The commitments of the disclosed attributes are computed from their values.
+func verifyAttributes(group *groups.SchnorrGroup, credential *Credential,
+	pubKeys *OrgAttributePubKeys) (*big.Int, bool) {
+	product := big.NewInt(1)
+	if pubKeys == nil {
+		return product, len(credential.Attributes) == 0
+	}
+	if len(credential.Attributes) != len(pubKeys.Names) {
+		return nil, false
+	}
+	for i, attr := range credential.Attributes {
+		if attr == nil || attr.Name != pubKeys.Names[i] || attr.T == nil ||
+			!group.IsElementInGroup(attr.D) {
+			return nil, false
+		}
+		v := attr.V
+		if attr.Value != nil {
+			if attr.Value.Sign() < 0 || attr.Value.BitLen() > AttributeBitLen || attr.R == nil {
+				return nil, false
+			}
+			v = commitAttribute(group, credential.SmallAToGamma, credential.AToGamma,
+				attr.Value, attr.R)
+		}
+		if !group.IsElementInGroup(v) ||
+			!dlogproofs.VerifyBlindedTranscript(attr.T, group, group.G, pubKeys.H[i], v, attr.D) {
+			return nil, false
+		}
+		product = group.Mul(product, attr.D)
+	}
+	return product, true
+}
+
+// checkAttributes checks that the values are given for exactly the named attributes
+// and that they have at most AttributeBitLen bits.
+func checkAttributes(names []string, values map[string]*big.Int) error {
+	if len(values) != len(names) {
+		return fmt.Errorf("values need to be given for attributes %v", names)
+	}
+	for _, name := range names {
+		value, ok := values[name]
+		if !ok || value == nil || value.Sign() < 0 || value.BitLen() > AttributeBitLen {
+			return fmt.Errorf("attribute %s needs a value from [0, 2^%d)", name, AttributeBitLen)
+		}
+	}
+	return nil
+}
+
+func checkAttributeNames(names []string) error {
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name == "" || seen[name] {
+			return fmt.Errorf("attribute names need to be non-empty and unique")
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// PredicateProver proves predicates about the hidden attributes of the credential when it
+// is transferred. The commitment V = a'^m * A'^r of the attribute (a' and A' are the parts
+// of the blinded credential) is a Pedersen commitment with bases a' and A', thus
+// V / a'^bound (or a'^bound / V) is split into the commitments to its AttributeBitLen bits
+// (see rangeproofs.BitDecompositionProver), which proves that m - bound (or bound - m) is
+// non-negative. The proofs use the challenge of the transfer (see OrgCredentialVerifier).
+type PredicateProver struct {
+	provers []*rangeproofs.BitDecompositionProver
+}
+
+// NewPredicateProver returns an error if the predicates are not about the attributes of
+// the credential or if the credential does not satisfy them.
+func NewPredicateProver(group *groups.SchnorrGroup, credential *Credential,
+	predicates []*anoncreds.Predicate) (*PredicateProver, error) {
+	bitsGroup, err := predicateGroup(group, credential)
+	if err != nil {
+		return nil, err
+	}
+	provers := make([]*rangeproofs.BitDecompositionProver, len(predicates))
+	for j, predicate := range predicates {
+		attr, err := predicateAttribute(credential, predicate)
+		if err != nil {
+			return nil, err
+		}
+		if attr.Value == nil || attr.R == nil {
+			return nil, fmt.Errorf("value of attribute %s is not known", predicate.Attribute)
+		}
+		// x = m - bound and r for >=, x = bound - m and -r for <=
+		x := new(big.Int).Sub(attr.Value, predicate.Bound)
+		r := new(big.Int).Set(attr.R)
+		if predicate.Type == anoncreds.LessOrEqual {
+			x.Neg(x)
+			r.Neg(r)
+			r.Mod(r, group.Q)
+		}
+		if x.Sign() < 0 {
+			return nil, fmt.Errorf("credential does not satisfy the predicate %s %v %v",
+				predicate.Attribute, predicate.Type, predicate.Bound)
+		}
+		provers[j], err = rangeproofs.NewBitDecompositionProver(bitsGroup, credential.AToGamma,
+			x, r, AttributeBitLen)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &PredicateProver{
+		provers: provers,
+	}, nil
+}
+
+// GetProofRandomData returns the bit commitments and the first messages of the proofs
+// of each predicate.
+func (prover *PredicateProver) GetProofRandomData() ([][]*rangeproofs.BitProofRandomData, error) {
+	data := make([][]*rangeproofs.BitProofRandomData, len(prover.provers))
+	for j, p := range prover.provers {
+		r, err := p.GetProofRandomData()
+		if err != nil {
+			return nil, err
+		}
+		data[j] = r
+	}
+	return data, nil
+}
+
+func (prover *PredicateProver) GetProofData(challenge *big.Int) [][]*rangeproofs.BitProofData {
+	data := make([][]*rangeproofs.BitProofData, len(prover.provers))
+	for j, p := range prover.provers {
+		data[j] = p.GetProofData(challenge)
+	}
+	return data
+}
+
+type PredicateVerifier struct {
+	verifiers []*rangeproofs.BitDecompositionVerifier
+}
+
+// NewPredicateVerifier returns the verifier of the predicates about the hidden attributes
+// of the presented credential. The credential itself needs to be verified separately
+// (see OrgCredentialVerifier).
+func NewPredicateVerifier(group *groups.SchnorrGroup, credential *Credential,
+	predicates []*anoncreds.Predicate) (*PredicateVerifier, error) {
+	bitsGroup, err := predicateGroup(group, credential)
+	if err != nil {
+		return nil, err
+	}
+	verifiers := make([]*rangeproofs.BitDecompositionVerifier, len(predicates))
+	for j, predicate := range predicates {
+		attr, err := predicateAttribute(credential, predicate)
+		if err != nil {
+			return nil, err
+		}
+		if attr.Value != nil || !group.IsElementInGroup(attr.V) {
+			return nil, fmt.Errorf("attribute %s needs to be hidden", predicate.Attribute)
+		}
+		// V / a'^bound for >=, a'^bound / V for <=
+		c := group.Mul(attr.V, group.Inv(group.Exp(credential.SmallAToGamma, predicate.Bound)))
+		if predicate.Type == anoncreds.LessOrEqual {
+			c = group.Inv(c)
+		}
+		verifiers[j] = rangeproofs.NewBitDecompositionVerifier(bitsGroup, credential.AToGamma,
+			c, AttributeBitLen)
+	}
+	return &PredicateVerifier{
+		verifiers: verifiers,
+	}, nil
+}
+
+// SetProofRandomData checks that the bit commitments of each predicate are
+// the decomposition of its commitment.
+func (verifier *PredicateVerifier) SetProofRandomData(
+	data [][]*rangeproofs.BitProofRandomData) error {
+	if len(data) != len(verifier.verifiers) {
+		return fmt.Errorf("proof random data needs to be given for %d predicates",
+			len(verifier.verifiers))
+	}
+	for j, v := range verifier.verifiers {
+		if err := v.SetProofRandomData(data[j]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetChallenge sets the challenge of the transfer for all the proofs.
+func (verifier *PredicateVerifier) SetChallenge(challenge *big.Int) {
+	for _, v := range verifier.verifiers {
+		v.SetChallenge(challenge)
+	}
+}
+
+func (verifier *PredicateVerifier) Verify(data [][]*rangeproofs.BitProofData) bool {
+	if len(data) != len(verifier.verifiers) {
+		return false
+	}
+	for j, v := range verifier.verifiers {
+		if !v.Verify(data[j]) {
+			return false
+		}
+	}
+	return true
+}
+
+// predicateGroup returns the group with generator a' of the blinded credential, in which
+// the commitments of the attributes are Pedersen commitments with the second base A'.
+func predicateGroup(group *groups.SchnorrGroup, credential *Credential) (*groups.SchnorrGroup,
+	error) {
+	one := big.NewInt(1)
+	if !group.IsElementInGroup(credential.SmallAToGamma) ||
+		!group.IsElementInGroup(credential.AToGamma) ||
+		credential.SmallAToGamma.Cmp(one) == 0 || credential.AToGamma.Cmp(one) == 0 {
+		return nil, fmt.Errorf("invalid credential")
+	}
+	return groups.NewSchnorrGroupFromParams(group.P, credential.SmallAToGamma, group.Q), nil
+}
+
+func predicateAttribute(credential *Credential,
+	predicate *anoncreds.Predicate) (*CredentialAttribute, error) {
+	// the values of the attributes are non-negative, thus negative bounds are useless
+	if predicate == nil || predicate.Bound == nil || predicate.Bound.Sign() < 0 ||
+		predicate.Bound.BitLen() > AttributeBitLen {
+		return nil, fmt.Errorf("predicate needs a bound from [0, 2^%d)", AttributeBitLen)
+	}
+	if predicate.Type != anoncreds.GreaterOrEqual && predicate.Type != anoncreds.LessOrEqual {
+		return nil, fmt.Errorf("unknown predicate type: %v", predicate.Type)
+	}
+	attr := credential.Attribute(predicate.Attribute)
+	if attr == nil {
+		return nil, fmt.Errorf("credential does not have attribute %s", predicate.Attribute)
+	}
+	return attr, nil
+}
@@ -0,0 +1,313 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pseudonymsys
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+)
+
+// Blacklistable authentication (BLAC-style): at each authentication the user sends
+// a ticket (h, tag = h^x), where h is a random group element and x is the master key
+// (log_a(b) for the nym (a, b) which is used for authentication). Tickets of different
+// sessions cannot be linked, but when a session turns out to be misbehaving, the verifier
+// can put its ticket on the blacklist. At each authentication the user proves that:
+//   - the tag is computed with the master key: log_a(b) = log_h(tag),
+//   - none of the blacklisted tickets was computed with the master key: for each blacklisted
+//     (h_i, t_i) it sends C_i = (h_i^x / t_i)^r_i != 1 and proves the knowledge of
+//     alpha_i = x * r_i, beta_i = -r_i such that C_i = h_i^alpha_i * t_i^beta_i and
+//     1 = a^alpha_i * b^beta_i (the latter holds only if alpha_i = -x * beta_i, thus C_i
+//     can be 1 only if t_i = h_i^x).
+//
+// The verifier never learns which master key was blacklisted. The proof is accepted only for
+// nyms which are registered with the organization (the organization registers a nym only
+// after the user proved that it is generated with the master key certified by the CA) or
+// for nyms of credentials issued by the organization, thus a blacklisted user cannot escape
+// the blacklist with a nym of a fresh master key. Note
+// however that the nym is sent in the clear, thus the sessions authenticated with the same
+// nym are linkable - the user needs to register a fresh nym for each session for
+// the sessions to be unlinkable (the blacklisting still applies to all the nyms of
+// the master key).
+type BlacklistTicket struct {
+	H   *big.Int
+	Tag *big.Int
+}
+
+func NewBlacklistTicket(h, tag *big.Int) *BlacklistTicket {
+	return &BlacklistTicket{
+		H:   h,
+		Tag: tag,
+	}
+}
+
+type BlacklistProver struct {
+	Group  *groups.SchnorrGroup
+	secret *big.Int
+	nym    *Pseudonym
+	ticket *BlacklistTicket
+	r      *big.Int
+	alphas []*big.Int
+	betas  []*big.Int
+	rAlpha []*big.Int
+	rBeta  []*big.Int
+}
+
+func NewBlacklistProver(group *groups.SchnorrGroup, secret *big.Int,
+	nym *Pseudonym) *BlacklistProver {
+	return &BlacklistProver{
+		Group:  group,
+		secret: secret,
+		nym:    nym,
+	}
+}
+
+// GetTicket returns a fresh ticket for this session.
+func (prover *BlacklistProver) GetTicket() (*BlacklistTicket, error) {
+	h, err := prover.Group.GetRandomElement()
+	if err != nil {
+		return nil, err
+	}
+	prover.ticket = NewBlacklistTicket(h, prover.Group.Exp(h, prover.secret))
+	return prover.ticket, nil
+}
+
+// GetProofRandomData returns x1 = a^r, x2 = h^r (for the proof that the tag is properly
+// computed) and for each blacklisted ticket C_i, y_i = h_i^rAlpha_i * t_i^rBeta_i and
+// w_i = a^rAlpha_i * b^rBeta_i.
+func (prover *BlacklistProver) GetProofRandomData(blacklist []*BlacklistTicket) (*big.Int,
+	*big.Int, []*big.Int, []*big.Int, []*big.Int, error) {
+	group := prover.Group
+	r, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+	prover.r = r
+	x1 := group.Exp(prover.nym.A, prover.r)
+	x2 := group.Exp(prover.ticket.H, prover.r)
+
+	n := len(blacklist)
+	prover.alphas = make([]*big.Int, n)
+	prover.betas = make([]*big.Int, n)
+	prover.rAlpha = make([]*big.Int, n)
+	prover.rBeta = make([]*big.Int, n)
+	cs := make([]*big.Int, n)
+	ys := make([]*big.Int, n)
+	ws := make([]*big.Int, n)
+	for i, ticket := range blacklist {
+		c := group.Mul(group.Exp(ticket.H, prover.secret), group.Inv(ticket.Tag))
+		if c.Cmp(big.NewInt(1)) == 0 {
+			return nil, nil, nil, nil, nil, fmt.Errorf("The user is blacklisted.")
+		}
+
+		r, err := common.GetRandomInt(group.Q)
+		if err != nil {
+			return nil, nil, nil, nil, nil, err
+		}
+		cs[i] = group.Exp(c, r)
+		prover.alphas[i] = new(big.Int).Mul(prover.secret, r)
+		prover.alphas[i].Mod(prover.alphas[i], group.Q)
+		prover.betas[i] = new(big.Int).Sub(group.Q, r)
+
+		prover.rAlpha[i], err = common.GetRandomInt(group.Q)
+		if err != nil {
+			return nil, nil, nil, nil, nil, err
+		}
+		prover.rBeta[i], err = common.GetRandomInt(group.Q)
+		if err != nil {
+			return nil, nil, nil, nil, nil, err
+		}
+		ys[i] = group.Mul(group.Exp(ticket.H, prover.rAlpha[i]),
+			group.Exp(ticket.Tag, prover.rBeta[i]))
+		ws[i] = group.Mul(group.Exp(prover.nym.A, prover.rAlpha[i]),
+			group.Exp(prover.nym.B, prover.rBeta[i]))
+	}
+
+	return x1, x2, cs, ys, ws, nil
+}
+
+// GetProofData returns z = r + challenge * x and for each blacklisted ticket
+// zAlpha_i = rAlpha_i + challenge * alpha_i, zBeta_i = rBeta_i + challenge * beta_i
+// (all modulo group order).
+func (prover *BlacklistProver) GetProofData(challenge *big.Int) (*big.Int, []*big.Int,
+	[]*big.Int) {
+	q := prover.Group.Q
+	z := blacklistResponse(prover.r, challenge, prover.secret, q)
+	zAlphas := make([]*big.Int, len(prover.alphas))
+	zBetas := make([]*big.Int, len(prover.betas))
+	for i := range prover.alphas {
+		zAlphas[i] = blacklistResponse(prover.rAlpha[i], challenge, prover.alphas[i], q)
+		zBetas[i] = blacklistResponse(prover.rBeta[i], challenge, prover.betas[i], q)
+	}
+	return z, zAlphas, zBetas
+}
+
+type BlacklistVerifier struct {
+	Group      *groups.SchnorrGroup
+	registry   *NymRegistry
+	orgPubKeys *OrgPubKeys
+	blacklist  []*BlacklistTicket
+	nym        *Pseudonym
+	ticket     *BlacklistTicket
+	x1         *big.Int
+	x2         *big.Int
+	cs         []*big.Int
+	ys         []*big.Int
+	ws         []*big.Int
+	challenge  *big.Int
+}
+
+// NewBlacklistVerifier returns a verifier which accepts proofs only for the nyms registered
+// in the registry (see GetChallenge).
+func NewBlacklistVerifier(group *groups.SchnorrGroup, registry *NymRegistry) *BlacklistVerifier {
+	return &BlacklistVerifier{
+		Group:    group,
+		registry: registry,
+	}
+}
+
+// NewCredentialBlacklistVerifier returns a verifier which accepts proofs only for the nyms of
+// credentials issued by the organization with orgPubKeys (see GetChallengeForCredential).
+func NewCredentialBlacklistVerifier(group *groups.SchnorrGroup,
+	orgPubKeys *OrgPubKeys) *BlacklistVerifier {
+	return &BlacklistVerifier{
+		Group:      group,
+		orgPubKeys: orgPubKeys,
+	}
+}
+
+// AddToBlacklist blocks the user who authenticated with the ticket.
+func (verifier *BlacklistVerifier) AddToBlacklist(ticket *BlacklistTicket) {
+	verifier.blacklist = append(verifier.blacklist, ticket)
+}
+
+// GetBlacklist returns the blacklisted tickets - the user needs them to prove that it
+// is not on the blacklist.
+func (verifier *BlacklistVerifier) GetBlacklist() []*BlacklistTicket {
+	return verifier.blacklist
+}
+
+// GetChallenge receives the nym, which needs to be registered in the registry of
+// the verifier, the ticket and the first message of the proof.
+func (verifier *BlacklistVerifier) GetChallenge(nym *Pseudonym, ticket *BlacklistTicket,
+	x1, x2 *big.Int, cs, ys, ws []*big.Int) (*big.Int, error) {
+	if verifier.registry == nil || nym == nil || !verifier.registry.IsRegistered(nym) {
+		return nil, fmt.Errorf("The nym is not registered.")
+	}
+	return verifier.getChallenge(nym, ticket, x1, x2, cs, ys, ws)
+}
+
+// GetChallengeForCredential is as GetChallenge, but the proof is for the nym of
+// the credential (SmallAToGamma, SmallBToGamma), which needs to be issued by
+// the organization with the public keys of the verifier.
+func (verifier *BlacklistVerifier) GetChallengeForCredential(credential *Credential,
+	ticket *BlacklistTicket, x1, x2 *big.Int, cs, ys, ws []*big.Int) (*big.Int, error) {
+	if verifier.orgPubKeys == nil || credential == nil || credential.T1 == nil ||
+		credential.T2 == nil || !verifyCredential(verifier.Group, credential, verifier.orgPubKeys) {
+		return nil, fmt.Errorf("Credential is not valid.")
+	}
+	nym := NewPseudonym(credential.SmallAToGamma, credential.SmallBToGamma)
+	return verifier.getChallenge(nym, ticket, x1, x2, cs, ys, ws)
+}
+
+func (verifier *BlacklistVerifier) getChallenge(nym *Pseudonym, ticket *BlacklistTicket,
+	x1, x2 *big.Int, cs, ys, ws []*big.Int) (*big.Int, error) {
+	group := verifier.Group
+	// h = 1 (or any h outside of the group) would make the tag independent of the master key
+	if ticket == nil || !isGenerator(group, ticket.H) || !isGenerator(group, ticket.Tag) {
+		return nil, fmt.Errorf("The ticket is not valid.")
+	}
+	if !isGenerator(group, nym.A) || !group.IsElementInGroup(nym.B) ||
+		!group.IsElementInGroup(x1) || !group.IsElementInGroup(x2) {
+		return nil, fmt.Errorf("The proof is not valid.")
+	}
+	n := len(verifier.blacklist)
+	if len(cs) != n || len(ys) != n || len(ws) != n {
+		return nil, fmt.Errorf("The proof does not cover the whole blacklist.")
+	}
+	for i, c := range cs {
+		if !group.IsElementInGroup(c) || !group.IsElementInGroup(ys[i]) ||
+			!group.IsElementInGroup(ws[i]) {
+			return nil, fmt.Errorf("The proof is not valid.")
+		}
+		if c.Cmp(big.NewInt(1)) == 0 {
+			return nil, fmt.Errorf("The user is blacklisted.")
+		}
+	}
+
+	verifier.nym = nym
+	verifier.ticket = ticket
+	verifier.x1 = x1
+	verifier.x2 = x2
+	verifier.cs = cs
+	verifier.ys = ys
+	verifier.ws = ws
+	challenge, err := common.GetRandomInt(verifier.Group.Q)
+	if err != nil {
+		return nil, err
+	}
+	verifier.challenge = challenge
+	return verifier.challenge, nil
+}
+
+// Verify checks a^z = x1 * b^challenge, h^z = x2 * tag^challenge and for each blacklisted
+// ticket h_i^zAlpha_i * t_i^zBeta_i = y_i * C_i^challenge, a^zAlpha_i * b^zBeta_i = w_i.
+func (verifier *BlacklistVerifier) Verify(z *big.Int, zAlphas, zBetas []*big.Int) bool {
+	group := verifier.Group
+	ch := verifier.challenge
+	if len(zAlphas) != len(verifier.blacklist) || len(zBetas) != len(verifier.blacklist) {
+		return false
+	}
+
+	left1 := group.Exp(verifier.nym.A, z)
+	right1 := group.Mul(verifier.x1, group.Exp(verifier.nym.B, ch))
+	left2 := group.Exp(verifier.ticket.H, z)
+	right2 := group.Mul(verifier.x2, group.Exp(verifier.ticket.Tag, ch))
+	if left1.Cmp(right1) != 0 || left2.Cmp(right2) != 0 {
+		return false
+	}
+
+	for i, ticket := range verifier.blacklist {
+		left := group.Mul(group.Exp(ticket.H, zAlphas[i]), group.Exp(ticket.Tag, zBetas[i]))
+		right := group.Mul(verifier.ys[i], group.Exp(verifier.cs[i], ch))
+		if left.Cmp(right) != 0 {
+			return false
+		}
+		left = group.Mul(group.Exp(verifier.nym.A, zAlphas[i]),
+			group.Exp(verifier.nym.B, zBetas[i]))
+		if left.Cmp(verifier.ws[i]) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isGenerator returns true if x is an element of the group other than 1 (as the order of
+// the group is prime, each such element generates the group).
+func isGenerator(group *groups.SchnorrGroup, x *big.Int) bool {
+	return group.IsElementInGroup(x) && x.Cmp(big.NewInt(1)) != 0
+}
+
+// blacklistResponse returns r + challenge * secret mod q.
+func blacklistResponse(r, challenge, secret, q *big.Int) *big.Int {
+	z := new(big.Int).Mul(challenge, secret)
+	z.Add(z, r)
+	return z.Mod(z, q)
+}
@@ -18,14 +18,10 @@
 package pseudonymsys
 
 import (
-	"crypto/ecdsa"
-	"crypto/rand"
 	"fmt"
 	"github.com/xlab-si/emmy/crypto/common"
-	"github.com/xlab-si/emmy/crypto/dlog"
 	"github.com/xlab-si/emmy/crypto/groups"
 	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
-	"github.com/xlab-si/emmy/types"
 	"math/big"
 )
 
@@ -33,64 +29,112 @@ type CA struct {
 	SchnorrVerifier *dlogproofs.SchnorrVerifier
 	a               *big.Int
 	b               *big.Int
-	privateKey      *ecdsa.PrivateKey
+	signers         []CASigner
+	signer          CASigner
 }
 
 type CACertificate struct {
-	BlindedA *big.Int
-	BlindedB *big.Int
-	R        *big.Int
-	S        *big.Int
+	BlindedA  *big.Int
+	BlindedB  *big.Int
+	R         *big.Int
+	S         *big.Int
+	Algorithm SignatureAlgorithm
+	// SCT is the promise of the CA log that the certificate was logged
+	// (nil if CA does not use a log, see CALog).
+	SCT *SignedCertificateTimestamp
 }
 
-func NewCACertificate(blindedA, blindedB, r, s *big.Int) *CACertificate {
+func NewCACertificate(blindedA, blindedB, r, s *big.Int, alg SignatureAlgorithm) *CACertificate {
 	return &CACertificate{
-		BlindedA: blindedA,
-		BlindedB: blindedB,
-		R:        r,
-		S:        s,
+		BlindedA:  blindedA,
+		BlindedB:  blindedB,
+		R:         r,
+		S:         s,
+		Algorithm: alg,
 	}
 }
 
-func NewCA(group *groups.SchnorrGroup, d, x, y *big.Int) *CA {
-	c := dlog.GetEllipticCurve(dlog.P256)
-	pubKey := ecdsa.PublicKey{Curve: c, X: x, Y: y}
-	privateKey := ecdsa.PrivateKey{PublicKey: pubKey, D: d}
+// Verify checks the CA signature of the certificate. The public key needs to be for
+// the algorithm which is specified in the certificate.
+func (cert *CACertificate) Verify(pubKey CAPubKey) bool {
+	if pubKey.Algorithm() != cert.Algorithm {
+		return false
+	}
+	return pubKey.Verify(common.HashIntoBytes(cert.BlindedA, cert.BlindedB), cert.R, cert.S)
+}
+
+// NewCA returns CA which signs certificates with ECDSA key d (and public key (x, y)).
+func NewCA(group *groups.SchnorrGroup, d, x, y *big.Int) (*CA, error) {
+	return NewCAWithSigners(group, NewECDSASigner(d, x, y))
+}
 
-	schnorrVerifier := dlogproofs.NewSchnorrVerifier(group, types.Sigma)
+// NewCAWithSigners returns CA which is able to sign certificates with any of the signers.
+// The order of the signers is the order of CA's preference - the first one is used unless
+// a different one is chosen by Negotiate.
+func NewCAWithSigners(group *groups.SchnorrGroup, signers ...CASigner) (*CA, error) {
+	schnorrVerifier, err := dlogproofs.NewSchnorrVerifier(group)
+	if err != nil {
+		return nil, err
+	}
 	ca := CA{
 		SchnorrVerifier: schnorrVerifier,
-		privateKey:      &privateKey,
+		signers:         signers,
+	}
+	if len(signers) > 0 {
+		ca.signer = signers[0]
 	}
 
-	return &ca
+	return &ca, nil
 }
 
-func (ca *CA) GetChallenge(a, b, x *big.Int) *big.Int {
+// Negotiate chooses the signature algorithm for the certificate - the most preferred one
+// (by CA) among the algorithms which are supported by the user.
+func (ca *CA) Negotiate(supported []SignatureAlgorithm) (SignatureAlgorithm, error) {
+	for _, signer := range ca.signers {
+		for _, alg := range supported {
+			if signer.Algorithm() == alg {
+				ca.signer = signer
+				return alg, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("None of the signature algorithms %v is supported by CA.", supported)
+}
+
+func (ca *CA) GetChallenge(a, b, x *big.Int) (*big.Int, error) {
 	// TODO: check if b is really a valuable external user's public master key; if not, close the session
 
 	ca.a = a
 	ca.b = b
 	ca.SchnorrVerifier.SetProofRandomData(x, a, b)
-	challenge, _ := ca.SchnorrVerifier.GetChallenge()
-	return challenge
+	challenge, _, err := ca.SchnorrVerifier.GetChallenge()
+	if err != nil {
+		return nil, err
+	}
+	return challenge, nil
 }
 
 func (ca *CA) Verify(z *big.Int) (*CACertificate, error) {
-	verified := ca.SchnorrVerifier.Verify(z, nil)
+	verified := ca.SchnorrVerifier.Verify(z)
 	if verified {
-		r := common.GetRandomInt(ca.SchnorrVerifier.Group.Q)
+		if ca.signer == nil {
+			return nil, fmt.Errorf("CA has no signing key.")
+		}
+		r, err := common.GetRandomInt(ca.SchnorrVerifier.Group.Q)
+		if err != nil {
+			return nil, err
+		}
 		blindedA := ca.SchnorrVerifier.Group.Exp(ca.a, r)
 		blindedB := ca.SchnorrVerifier.Group.Exp(ca.b, r)
 		// blindedA, blindedB must be used only once (never use the same pair for two
 		// different organizations)
 
 		hashed := common.HashIntoBytes(blindedA, blindedB)
-		r, s, err := ecdsa.Sign(rand.Reader, ca.privateKey, hashed)
+		r, s, err := ca.signer.Sign(hashed)
 		if err != nil {
 			return nil, err
 		} else {
-			return NewCACertificate(blindedA, blindedB, r, s), nil
+			return NewCACertificate(blindedA, blindedB, r, s, ca.signer.Algorithm()), nil
 		}
 	} else {
 		return nil, fmt.Errorf("The knowledge of secret was not verified.")
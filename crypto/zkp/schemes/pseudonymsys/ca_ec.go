@@ -57,7 +57,7 @@ func NewCAEC(d, x, y *big.Int, curveType dlog.Curve) *CAEC {
 	pubKey := ecdsa.PublicKey{Curve: c, X: x, Y: y}
 	privateKey := ecdsa.PrivateKey{PublicKey: pubKey, D: d}
 
-	schnorrVerifier := dlogproofs.NewSchnorrECVerifier(curveType, types.Sigma)
+	schnorrVerifier, _ := dlogproofs.NewSchnorrECVerifier(dlogproofs.WithCurve(curveType))
 	ca := CAEC{
 		SchnorrVerifier: schnorrVerifier,
 		privateKey:      &privateKey,
@@ -66,20 +66,26 @@ func NewCAEC(d, x, y *big.Int, curveType dlog.Curve) *CAEC {
 	return &ca
 }
 
-func (ca *CAEC) GetChallenge(a, b, x *types.ECGroupElement) *big.Int {
+func (ca *CAEC) GetChallenge(a, b, x *types.ECGroupElement) (*big.Int, error) {
 	// TODO: check if b is really a valuable external user's public master key; if not, close the session
 
 	ca.a = a
 	ca.b = b
 	ca.SchnorrVerifier.SetProofRandomData(x, a, b)
-	challenge, _ := ca.SchnorrVerifier.GetChallenge()
-	return challenge
+	challenge, _, err := ca.SchnorrVerifier.GetChallenge()
+	if err != nil {
+		return nil, err
+	}
+	return challenge, nil
 }
 
 func (ca *CAEC) Verify(z *big.Int) (*CACertificateEC, error) {
-	verified := ca.SchnorrVerifier.Verify(z, nil)
+	verified := ca.SchnorrVerifier.Verify(z)
 	if verified {
-		r := common.GetRandomInt(ca.SchnorrVerifier.DLog.OrderOfSubgroup)
+		r, err := common.GetRandomInt(ca.SchnorrVerifier.DLog.OrderOfSubgroup)
+		if err != nil {
+			return nil, err
+		}
 		blindedA1, blindedA2 := ca.SchnorrVerifier.DLog.Exponentiate(ca.a.X, ca.a.Y, r)
 		blindedB1, blindedB2 := ca.SchnorrVerifier.DLog.Exponentiate(ca.b.X, ca.b.Y, r)
 		// blindedA, blindedB must be used only once (never use the same pair for two
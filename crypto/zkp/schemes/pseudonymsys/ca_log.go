@@ -0,0 +1,255 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package pseudonymsys
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// SignedCertificateTimestamp is a promise of the CA log that the certificate was
+// included into the log at the given time.
+type SignedCertificateTimestamp struct {
+	Timestamp int64
+	R         *big.Int
+	S         *big.Int
+}
+
+func NewSignedCertificateTimestamp(timestamp int64, r, s *big.Int) *SignedCertificateTimestamp {
+	return &SignedCertificateTimestamp{
+		Timestamp: timestamp,
+		R:         r,
+		S:         s,
+	}
+}
+
+// SignedTreeHead commits to the first Size entries of the log. Root is the root of
+// the Merkle tree over the entries (see commitments.MerkleLog).
+type SignedTreeHead struct {
+	Size      int
+	Root      []byte
+	Timestamp int64
+	R         *big.Int
+	S         *big.Int
+}
+
+// CALogEntry is a certificate issued by the CA together with the time when it
+// was submitted to the log.
+type CALogEntry struct {
+	Certificate *CACertificate
+	Timestamp   int64
+}
+
+// CALog is an append-only log of the certificates issued by the CA, similar to
+// certificate transparency logs. CA submits each certificate it issues and passes
+// the returned SignedCertificateTimestamp to the user. Monitors periodically obtain
+// the entries and a SignedTreeHead - the tree head is the root of a Merkle tree over all
+// entries, thus the log cannot remove or change the entries which were once included
+// without the monitors noticing it: the log proves that each new tree head extends
+// the previous one (GetConsistencyProof), and that a certificate is included in the log
+// (GetInclusionProof) without sending all the entries. Monitors can thus detect
+// certificates which were issued but never requested by the legitimate users (for example
+// a CA issuing certificates to itself to create sybil nyms).
+//
+// Note that the certificates contain only blinded values, so the log does not reveal
+// the users' master keys.
+type CALog struct {
+	privateKey *ecdsa.PrivateKey
+	entries    []*CALogEntry
+	tree       *commitments.MerkleLog
+	sync.Mutex
+}
+
+// NewCALog returns a log which signs timestamps and tree heads with ECDSA (P256) key d.
+func NewCALog(d, x, y *big.Int) *CALog {
+	c := dlog.GetEllipticCurve(dlog.P256)
+	pubKey := ecdsa.PublicKey{Curve: c, X: x, Y: y}
+	return &CALog{
+		privateKey: &ecdsa.PrivateKey{PublicKey: pubKey, D: d},
+		tree:       commitments.NewMerkleLog(),
+	}
+}
+
+// Submit appends the certificate to the log and returns a signed timestamp of the inclusion.
+func (log *CALog) Submit(cert *CACertificate) (*SignedCertificateTimestamp, error) {
+	log.Lock()
+	defer log.Unlock()
+
+	timestamp := time.Now().Unix()
+	r, s, err := ecdsa.Sign(rand.Reader, log.privateKey, hashCALogEntry(cert, timestamp))
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &CALogEntry{
+		Certificate: cert,
+		Timestamp:   timestamp,
+	}
+	log.entries = append(log.entries, entry)
+	log.tree.Append(encodeCALogEntry(entry))
+	return NewSignedCertificateTimestamp(timestamp, r, s), nil
+}
+
+// GetEntries returns the entries from start (inclusive) to end (exclusive).
+func (log *CALog) GetEntries(start, end int) ([]*CALogEntry, error) {
+	log.Lock()
+	defer log.Unlock()
+
+	if start < 0 || end > len(log.entries) || start > end {
+		return nil, fmt.Errorf("Entries from %d to %d are not in the log", start, end)
+	}
+	entries := make([]*CALogEntry, end-start)
+	copy(entries, log.entries[start:end])
+	return entries, nil
+}
+
+// GetSignedTreeHead returns the signed head of the whole log.
+func (log *CALog) GetSignedTreeHead() (*SignedTreeHead, error) {
+	log.Lock()
+	defer log.Unlock()
+
+	size := log.tree.Size()
+	root, err := log.tree.GetRoot(size)
+	if err != nil {
+		return nil, err
+	}
+	timestamp := time.Now().Unix()
+	r, s, err := ecdsa.Sign(rand.Reader, log.privateKey, hashCALogTreeHead(size, root, timestamp))
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedTreeHead{
+		Size:      size,
+		Root:      root,
+		Timestamp: timestamp,
+		R:         r,
+		S:         s,
+	}, nil
+}
+
+// GetInclusionProof returns the proof that the entry at index is included in the tree of
+// the first size entries (see VerifyCALogInclusion).
+func (log *CALog) GetInclusionProof(index, size int) ([][]byte, error) {
+	log.Lock()
+	defer log.Unlock()
+	return log.tree.GetInclusionProof(index, size)
+}
+
+// GetConsistencyProof returns the proof that the tree of the first newSize entries extends
+// the tree of the first oldSize entries (see VerifyCALogConsistency).
+func (log *CALog) GetConsistencyProof(oldSize, newSize int) ([][]byte, error) {
+	log.Lock()
+	defer log.Unlock()
+	return log.tree.GetConsistencyProof(oldSize, newSize)
+}
+
+// VerifySignedCertificateTimestamp checks that the timestamp was signed by the log
+// with public key (x, y) for the given certificate.
+func VerifySignedCertificateTimestamp(x, y *big.Int, cert *CACertificate,
+	sct *SignedCertificateTimestamp) bool {
+	pubKey := ecdsa.PublicKey{Curve: dlog.GetEllipticCurve(dlog.P256), X: x, Y: y}
+	return ecdsa.Verify(&pubKey, hashCALogEntry(cert, sct.Timestamp), sct.R, sct.S)
+}
+
+// VerifySignedTreeHead checks that the tree head was signed by the log with public
+// key (x, y) and that it commits exactly to the given entries (these need to be all
+// the entries of the log up to the size of the tree head).
+func VerifySignedTreeHead(x, y *big.Int, sth *SignedTreeHead, entries []*CALogEntry) bool {
+	if !verifyCALogTreeHeadSignature(x, y, sth) || len(entries) != sth.Size {
+		return false
+	}
+
+	tree := commitments.NewMerkleLog()
+	for _, entry := range entries {
+		tree.Append(encodeCALogEntry(entry))
+	}
+	root, err := tree.GetRoot(len(entries))
+	return err == nil && bytes.Equal(root, sth.Root)
+}
+
+// VerifyCALogInclusion checks that the tree head was signed by the log with public key
+// (x, y) and that the entry is at index in the tree of the tree head.
+func VerifyCALogInclusion(x, y *big.Int, sth *SignedTreeHead, entry *CALogEntry, index int,
+	proof [][]byte) bool {
+	return verifyCALogTreeHeadSignature(x, y, sth) &&
+		commitments.VerifyMerkleLogInclusion(encodeCALogEntry(entry), index, sth.Size, proof,
+			sth.Root)
+}
+
+// VerifyCALogConsistency checks that both tree heads were signed by the log with public
+// key (x, y) and that the tree of newSTH extends the tree of oldSTH - the log did not
+// remove or change any of the entries which were included in oldSTH.
+func VerifyCALogConsistency(x, y *big.Int, oldSTH, newSTH *SignedTreeHead,
+	proof [][]byte) bool {
+	return verifyCALogTreeHeadSignature(x, y, oldSTH) &&
+		verifyCALogTreeHeadSignature(x, y, newSTH) &&
+		commitments.VerifyMerkleLogConsistency(oldSTH.Size, newSTH.Size, oldSTH.Root,
+			newSTH.Root, proof)
+}
+
+func verifyCALogTreeHeadSignature(x, y *big.Int, sth *SignedTreeHead) bool {
+	pubKey := ecdsa.PublicKey{Curve: dlog.GetEllipticCurve(dlog.P256), X: x, Y: y}
+	return sth != nil && ecdsa.Verify(&pubKey,
+		hashCALogTreeHead(sth.Size, sth.Root, sth.Timestamp), sth.R, sth.S)
+}
+
+func hashCALogEntry(cert *CACertificate, timestamp int64) []byte {
+	return common.HashIntoBytes(big.NewInt(timestamp), cert.BlindedA, cert.BlindedB,
+		cert.R, cert.S, big.NewInt(int64(cert.Algorithm)))
+}
+
+// encodeCALogEntry returns the leaf of the entry in the Merkle tree - the fields of
+// the entry, each prefixed with its length.
+func encodeCALogEntry(entry *CALogEntry) []byte {
+	var b []byte
+	write := func(field []byte) {
+		l := make([]byte, 8)
+		binary.BigEndian.PutUint64(l, uint64(len(field)))
+		b = append(b, l...)
+		b = append(b, field...)
+	}
+	cert := entry.Certificate
+	write(big.NewInt(entry.Timestamp).Bytes())
+	write(cert.BlindedA.Bytes())
+	write(cert.BlindedB.Bytes())
+	write(cert.R.Bytes())
+	write(cert.S.Bytes())
+	write(big.NewInt(int64(cert.Algorithm)).Bytes())
+	return b
+}
+
+func hashCALogTreeHead(size int, root []byte, timestamp int64) []byte {
+	h := sha512.New()
+	h.Write([]byte("emmy/ca-log-tree-head"))
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b, uint64(size))
+	binary.BigEndian.PutUint64(b[8:], uint64(timestamp))
+	h.Write(b)
+	h.Write(root)
+	return h.Sum(nil)
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pseudonymsys
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/factorization"
+)
+
+// When the CA signs certificates with RSA (see NewRSASigner), clients and organizations
+// can require the CA to prove that it knows the factorization of its RSA modulus before
+// trusting the public key. This rejects keys that the CA did not generate itself (for
+// example a modulus copied from another party or one whose factorization nobody knows).
+// Note that the proof does not show that the modulus has exactly two prime factors.
+
+// NewCAModulusProver returns a prover of the knowledge of the factorization of the CA
+// RSA modulus.
+func NewCAModulusProver(rsa *signatures.RSA) (*factorization.FactorizationProver, error) {
+	primes := rsa.GetPrimes()
+	if len(primes) != 2 {
+		return nil, fmt.Errorf("RSA modulus needs to have two prime factors")
+	}
+	return factorization.NewFactorizationProver(rsa.GetPubKey().N, primes[0], primes[1])
+}
+
+// NewCAModulusVerifier returns a verifier of the proof that the CA knows the factorization
+// of its RSA modulus.
+func NewCAModulusVerifier(
+	pubKey *signatures.RSAPubKey) (*factorization.FactorizationVerifier, error) {
+	return factorization.NewFactorizationVerifier(pubKey.N)
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pseudonymsys
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"math/big"
+)
+
+// When the CA certificate is shown by revealing the signature, the organization obtains
+// a transferable proof that the user was registered by the CA - it can show the same
+// certificate to anybody else. When the certificate is signed with RSA (see NewRSASigner),
+// the user can instead reveal only blindedA and blindedB and prove that it knows the CA
+// signature on them. The proof transcript can be simulated without the signature, so
+// it cannot be reused by the organization.
+//
+// Note that blindedA and blindedB are still revealed, so the showings of the same
+// certificate can be linked - a fresh certificate is needed for each organization.
+
+// NewCACertificateShowProver returns a prover of the knowledge of the RSA signature
+// of the certificate.
+func NewCACertificateShowProver(cert *CACertificate,
+	pubKey *signatures.RSAPubKey) (*signatures.RSASignatureProver, error) {
+	if cert.Algorithm != RSA {
+		return nil, fmt.Errorf("certificate is not signed with RSA")
+	}
+	return signatures.NewRSASignatureProver(pubKey,
+		common.HashIntoBytes(cert.BlindedA, cert.BlindedB), cert.R)
+}
+
+// NewCACertificateShowVerifier returns a verifier of the proof that the user knows
+// the RSA signature of the certificate (blindedA, blindedB).
+func NewCACertificateShowVerifier(blindedA, blindedB *big.Int,
+	pubKey *signatures.RSAPubKey) (*signatures.RSASignatureVerifier, error) {
+	return signatures.NewRSASignatureVerifier(pubKey,
+		common.HashIntoBytes(blindedA, blindedB))
+}
@@ -0,0 +1,287 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package pseudonymsys
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"golang.org/x/crypto/ed25519"
+	"math/big"
+)
+
+// SignatureAlgorithm identifies the algorithm which was used by the CA to sign
+// the certificate. The zero value is ECDSA, which is the only algorithm supported by
+// the older versions, so the certificates which do not specify the algorithm are ECDSA.
+type SignatureAlgorithm int32
+
+const (
+	ECDSA SignatureAlgorithm = iota
+	Ed25519
+	Schnorr
+	RSA
+)
+
+func (alg SignatureAlgorithm) String() string {
+	switch alg {
+	case ECDSA:
+		return "ECDSA"
+	case Ed25519:
+		return "Ed25519"
+	case Schnorr:
+		return "Schnorr"
+	case RSA:
+		return "RSA"
+	}
+	return fmt.Sprintf("SignatureAlgorithm(%d)", int32(alg))
+}
+
+// CASigner signs the (hashed) certificates. All signatures are represented by a pair
+// of integers (r, s), so certificates have the same form regardless of the algorithm.
+type CASigner interface {
+	Algorithm() SignatureAlgorithm
+	Sign(hashed []byte) (*big.Int, *big.Int, error)
+}
+
+// CAPubKey verifies the signatures of a CASigner.
+type CAPubKey interface {
+	Algorithm() SignatureAlgorithm
+	Verify(hashed []byte, r, s *big.Int) bool
+}
+
+type ecdsaSigner struct {
+	privateKey *ecdsa.PrivateKey
+}
+
+// NewECDSASigner returns a signer with ECDSA (P256) key d and public key (x, y).
+func NewECDSASigner(d, x, y *big.Int) CASigner {
+	c := dlog.GetEllipticCurve(dlog.P256)
+	pubKey := ecdsa.PublicKey{Curve: c, X: x, Y: y}
+	return &ecdsaSigner{
+		privateKey: &ecdsa.PrivateKey{PublicKey: pubKey, D: d},
+	}
+}
+
+func (signer *ecdsaSigner) Algorithm() SignatureAlgorithm {
+	return ECDSA
+}
+
+func (signer *ecdsaSigner) Sign(hashed []byte) (*big.Int, *big.Int, error) {
+	return ecdsa.Sign(rand.Reader, signer.privateKey, hashed)
+}
+
+type ecdsaPubKey struct {
+	pubKey *ecdsa.PublicKey
+}
+
+// NewECDSAPubKey returns ECDSA (P256) public key (x, y).
+func NewECDSAPubKey(x, y *big.Int) CAPubKey {
+	c := dlog.GetEllipticCurve(dlog.P256)
+	return &ecdsaPubKey{
+		pubKey: &ecdsa.PublicKey{Curve: c, X: x, Y: y},
+	}
+}
+
+func (pubKey *ecdsaPubKey) Algorithm() SignatureAlgorithm {
+	return ECDSA
+}
+
+func (pubKey *ecdsaPubKey) Verify(hashed []byte, r, s *big.Int) bool {
+	return ecdsa.Verify(pubKey.pubKey, hashed, r, s)
+}
+
+type ed25519Signer struct {
+	privateKey ed25519.PrivateKey
+}
+
+// NewEd25519Signer returns a signer with Ed25519 key derived from the seed.
+func NewEd25519Signer(seed []byte) (CASigner, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("Ed25519 seed needs to be %d bytes long", ed25519.SeedSize)
+	}
+	return &ed25519Signer{
+		privateKey: ed25519.NewKeyFromSeed(seed),
+	}, nil
+}
+
+func (signer *ed25519Signer) Algorithm() SignatureAlgorithm {
+	return Ed25519
+}
+
+// Sign returns the first and the second half of Ed25519 signature as integers.
+func (signer *ed25519Signer) Sign(hashed []byte) (*big.Int, *big.Int, error) {
+	sig := ed25519.Sign(signer.privateKey, hashed)
+	half := ed25519.SignatureSize / 2
+	return new(big.Int).SetBytes(sig[:half]), new(big.Int).SetBytes(sig[half:]), nil
+}
+
+type ed25519PubKey struct {
+	pubKey ed25519.PublicKey
+}
+
+func NewEd25519PubKey(pubKey []byte) (CAPubKey, error) {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("Ed25519 public key needs to be %d bytes long",
+			ed25519.PublicKeySize)
+	}
+	return &ed25519PubKey{
+		pubKey: ed25519.PublicKey(pubKey),
+	}, nil
+}
+
+func (pubKey *ed25519PubKey) Algorithm() SignatureAlgorithm {
+	return Ed25519
+}
+
+func (pubKey *ed25519PubKey) Verify(hashed []byte, r, s *big.Int) bool {
+	half := ed25519.SignatureSize / 2
+	if r.Sign() < 0 || s.Sign() < 0 || r.BitLen() > 8*half || s.BitLen() > 8*half {
+		return false
+	}
+	// leading zeros were lost when the halves were converted to integers
+	sig := make([]byte, ed25519.SignatureSize)
+	rBytes, sBytes := r.Bytes(), s.Bytes()
+	copy(sig[half-len(rBytes):half], rBytes)
+	copy(sig[ed25519.SignatureSize-len(sBytes):], sBytes)
+	return ed25519.Verify(pubKey.pubKey, hashed, sig)
+}
+
+// schnorrSigner produces Schnorr signatures in P256 group: for a random k it computes
+// R = k * G, e = H(R, hashed) and s = k + e * d (mod order), the signature is (e, s).
+type schnorrSigner struct {
+	curve elliptic.Curve
+	d     *big.Int
+}
+
+// NewSchnorrSigner returns a signer with Schnorr (P256) key d.
+func NewSchnorrSigner(d *big.Int) CASigner {
+	return &schnorrSigner{
+		curve: dlog.GetEllipticCurve(dlog.P256),
+		d:     d,
+	}
+}
+
+func (signer *schnorrSigner) Algorithm() SignatureAlgorithm {
+	return Schnorr
+}
+
+func (signer *schnorrSigner) Sign(hashed []byte) (*big.Int, *big.Int, error) {
+	order := signer.curve.Params().N
+	k, err := rand.Int(rand.Reader, new(big.Int).Sub(order, big.NewInt(1)))
+	if err != nil {
+		return nil, nil, err
+	}
+	k.Add(k, big.NewInt(1))
+
+	rX, rY := signer.curve.ScalarBaseMult(k.Bytes())
+	e := schnorrSignatureChallenge(order, rX, rY, hashed)
+	s := new(big.Int).Mul(e, signer.d)
+	s.Add(s, k)
+	s.Mod(s, order)
+	return e, s, nil
+}
+
+type schnorrPubKey struct {
+	curve elliptic.Curve
+	x     *big.Int
+	y     *big.Int
+}
+
+// NewSchnorrPubKey returns Schnorr (P256) public key (x, y) = d * G.
+func NewSchnorrPubKey(x, y *big.Int) CAPubKey {
+	return &schnorrPubKey{
+		curve: dlog.GetEllipticCurve(dlog.P256),
+		x:     x,
+		y:     y,
+	}
+}
+
+func (pubKey *schnorrPubKey) Algorithm() SignatureAlgorithm {
+	return Schnorr
+}
+
+// Verify checks that H(s * G - e * pubKey, hashed) = e.
+func (pubKey *schnorrPubKey) Verify(hashed []byte, e, s *big.Int) bool {
+	order := pubKey.curve.Params().N
+	if e.Sign() <= 0 || e.Cmp(order) >= 0 || s.Sign() <= 0 || s.Cmp(order) >= 0 {
+		return false
+	}
+	if !pubKey.curve.IsOnCurve(pubKey.x, pubKey.y) {
+		return false
+	}
+
+	sX, sY := pubKey.curve.ScalarBaseMult(s.Bytes())
+	eX, eY := pubKey.curve.ScalarMult(pubKey.x, pubKey.y, e.Bytes())
+	eY.Sub(pubKey.curve.Params().P, eY) // -e * pubKey
+	rX, rY := pubKey.curve.Add(sX, sY, eX, eY)
+	if rX.Sign() == 0 && rY.Sign() == 0 {
+		return false
+	}
+	return schnorrSignatureChallenge(order, rX, rY, hashed).Cmp(e) == 0
+}
+
+func schnorrSignatureChallenge(order, rX, rY *big.Int, hashed []byte) *big.Int {
+	e := common.Hash(rX, rY, new(big.Int).SetBytes(hashed))
+	return e.Mod(e, order)
+}
+
+// rsaSigner produces full-domain-hash RSA signatures. The signature is r, while s is
+// always 0. Unlike the other algorithms, the possession of RSA signature can be proved
+// without revealing it (see CACertificateShowProver).
+type rsaSigner struct {
+	rsa *signatures.RSA
+}
+
+// NewRSASigner returns a signer with the given RSA key.
+func NewRSASigner(rsa *signatures.RSA) CASigner {
+	return &rsaSigner{
+		rsa: rsa,
+	}
+}
+
+func (signer *rsaSigner) Algorithm() SignatureAlgorithm {
+	return RSA
+}
+
+func (signer *rsaSigner) Sign(hashed []byte) (*big.Int, *big.Int, error) {
+	return signer.rsa.Sign(hashed), big.NewInt(0), nil
+}
+
+type rsaPubKey struct {
+	pubKey *signatures.RSAPubKey
+}
+
+func NewRSAPubKey(pubKey *signatures.RSAPubKey) CAPubKey {
+	return &rsaPubKey{
+		pubKey: pubKey,
+	}
+}
+
+func (pubKey *rsaPubKey) Algorithm() SignatureAlgorithm {
+	return RSA
+}
+
+func (pubKey *rsaPubKey) Verify(hashed []byte, r, s *big.Int) bool {
+	if s == nil || s.Sign() != 0 {
+		return false
+	}
+	return pubKey.pubKey.Verify(hashed, r)
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pseudonymsys
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"math/big"
+	"sync"
+	"time"
+)
+
+type CertificateStatus int32
+
+const (
+	Good CertificateStatus = iota
+	Revoked
+)
+
+func (status CertificateStatus) String() string {
+	switch status {
+	case Good:
+		return "good"
+	case Revoked:
+		return "revoked"
+	}
+	return "unknown"
+}
+
+// CAStatusResponse is a statement of the CA about the status of a certificate at the
+// given time.
+type CAStatusResponse struct {
+	Status    CertificateStatus
+	Timestamp int64
+	R         *big.Int
+	S         *big.Int
+}
+
+func NewCAStatusResponse(status CertificateStatus, timestamp int64,
+	r, s *big.Int) *CAStatusResponse {
+	return &CAStatusResponse{
+		Status:    status,
+		Timestamp: timestamp,
+		R:         r,
+		S:         s,
+	}
+}
+
+// CAStatusResponder keeps track of the revoked CA certificates (for example when the user's
+// master key is compromised) and answers OCSP-style status queries.
+//
+// Status is meant to be queried by the user for its own certificate (the CA already knows
+// the certificate as it issued it) and then presented to the organization together with
+// the certificate when registering a nym. The organization only verifies the signature and
+// the freshness of the response, thus CA never learns where the certificate is used.
+type CAStatusResponder struct {
+	privateKey *ecdsa.PrivateKey
+	revoked    map[string]int64
+	sync.Mutex
+}
+
+// NewCAStatusResponder returns a responder which signs the responses with ECDSA (P256) key d.
+func NewCAStatusResponder(d, x, y *big.Int) *CAStatusResponder {
+	c := dlog.GetEllipticCurve(dlog.P256)
+	pubKey := ecdsa.PublicKey{Curve: c, X: x, Y: y}
+	return &CAStatusResponder{
+		privateKey: &ecdsa.PrivateKey{PublicKey: pubKey, D: d},
+		revoked:    make(map[string]int64),
+	}
+}
+
+// Revoke marks the certificate as revoked.
+func (responder *CAStatusResponder) Revoke(cert *CACertificate) {
+	responder.Lock()
+	defer responder.Unlock()
+
+	key := caCertificateKey(cert)
+	if _, ok := responder.revoked[key]; !ok {
+		responder.revoked[key] = time.Now().Unix()
+	}
+}
+
+// IsRevoked returns true if the certificate was revoked.
+func (responder *CAStatusResponder) IsRevoked(cert *CACertificate) bool {
+	responder.Lock()
+	defer responder.Unlock()
+
+	_, revoked := responder.revoked[caCertificateKey(cert)]
+	return revoked
+}
+
+// GetStatus returns the signed status of the certificate at the current time.
+func (responder *CAStatusResponder) GetStatus(cert *CACertificate) (*CAStatusResponse, error) {
+	status := Good
+	if responder.IsRevoked(cert) {
+		status = Revoked
+	}
+
+	timestamp := time.Now().Unix()
+	r, s, err := ecdsa.Sign(rand.Reader, responder.privateKey,
+		hashCAStatus(cert, status, timestamp))
+	if err != nil {
+		return nil, err
+	}
+	return NewCAStatusResponse(status, timestamp, r, s), nil
+}
+
+// VerifyCAStatusResponse checks that the response about the certificate was signed by the
+// responder with public key (x, y). Note that the caller needs to check the status and
+// whether the response is fresh enough (see Timestamp).
+func VerifyCAStatusResponse(x, y *big.Int, cert *CACertificate, resp *CAStatusResponse) bool {
+	pubKey := ecdsa.PublicKey{Curve: dlog.GetEllipticCurve(dlog.P256), X: x, Y: y}
+	return ecdsa.Verify(&pubKey, hashCAStatus(cert, resp.Status, resp.Timestamp),
+		resp.R, resp.S)
+}
+
+func caCertificateKey(cert *CACertificate) string {
+	return hex.EncodeToString(common.HashIntoBytes(cert.BlindedA, cert.BlindedB))
+}
+
+func hashCAStatus(cert *CACertificate, status CertificateStatus, timestamp int64) []byte {
+	return common.HashIntoBytes(cert.BlindedA, cert.BlindedB, big.NewInt(int64(status)),
+		big.NewInt(timestamp))
+}
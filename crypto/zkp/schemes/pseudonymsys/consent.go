@@ -0,0 +1,165 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pseudonymsys
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// ConsentReceipt is a record of a presentation - what was disclosed, to whom and when.
+// Instead of the presented values it contains only the hash of the presentation transcript,
+// so the receipt itself does not reveal the credential or the nym. The receipt is signed by
+// the wallet, so it cannot be later modified unnoticed.
+type ConsentReceipt struct {
+	Recipient      string
+	Disclosed      []string
+	Timestamp      int64
+	TranscriptHash []byte
+	R              *big.Int
+	S              *big.Int
+}
+
+func NewConsentReceipt(recipient string, disclosed []string, timestamp int64,
+	transcriptHash []byte, r, s *big.Int) *ConsentReceipt {
+	return &ConsentReceipt{
+		Recipient:      recipient,
+		Disclosed:      disclosed,
+		Timestamp:      timestamp,
+		TranscriptHash: transcriptHash,
+		R:              r,
+		S:              s,
+	}
+}
+
+// ConsentWallet issues consent receipts and stores them.
+type ConsentWallet struct {
+	signingKey *ecdsa.PrivateKey
+	receipts   []*ConsentReceipt
+	mutex      sync.Mutex
+}
+
+func NewConsentWallet() (*ConsentWallet, error) {
+	signingKey, err := ecdsa.GenerateKey(dlog.GetEllipticCurve(dlog.P256), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConsentWallet{
+		signingKey: signingKey,
+	}, nil
+}
+
+// GetPubKey returns the public key which is needed to verify receipts issued by the wallet.
+func (wallet *ConsentWallet) GetPubKey() *ecdsa.PublicKey {
+	return &wallet.signingKey.PublicKey
+}
+
+// AddReceipt signs a receipt for the presentation with the given transcript values and
+// stores it in the wallet.
+func (wallet *ConsentWallet) AddReceipt(recipient string, disclosed []string,
+	transcript ...*big.Int) (*ConsentReceipt, error) {
+	transcriptHash := hashConsentTranscript(transcript)
+	timestamp := time.Now().Unix()
+	hashed := hashConsentReceipt(recipient, disclosed, timestamp, transcriptHash)
+	r, s, err := ecdsa.Sign(rand.Reader, wallet.signingKey, hashed)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := NewConsentReceipt(recipient, disclosed, timestamp, transcriptHash, r, s)
+	wallet.mutex.Lock()
+	wallet.receipts = append(wallet.receipts, receipt)
+	wallet.mutex.Unlock()
+	return receipt, nil
+}
+
+// GetReceipts returns all receipts stored in the wallet.
+func (wallet *ConsentWallet) GetReceipts() []*ConsentReceipt {
+	wallet.mutex.Lock()
+	defer wallet.mutex.Unlock()
+	receipts := make([]*ConsentReceipt, len(wallet.receipts))
+	copy(receipts, wallet.receipts)
+	return receipts
+}
+
+// VerifyConsentReceipt checks that the receipt was signed by the wallet with
+// the given public key.
+func VerifyConsentReceipt(pubKey *ecdsa.PublicKey, receipt *ConsentReceipt) error {
+	hashed := hashConsentReceipt(receipt.Recipient, receipt.Disclosed, receipt.Timestamp,
+		receipt.TranscriptHash)
+	if !ecdsa.Verify(pubKey, hashed, receipt.R, receipt.S) {
+		return fmt.Errorf("Consent receipt signature is not valid")
+	}
+	return nil
+}
+
+// MatchesTranscript returns true if the receipt was issued for the presentation with
+// the given transcript values.
+func (receipt *ConsentReceipt) MatchesTranscript(transcript ...*big.Int) bool {
+	return bytes.Equal(hashConsentTranscript(transcript), receipt.TranscriptHash)
+}
+
+// hashConsentTranscript hashes the transcript values, each prefixed with its length.
+func hashConsentTranscript(transcript []*big.Int) []byte {
+	h := sha512.New()
+	h.Write([]byte("emmy/consent-transcript"))
+	for _, value := range transcript {
+		b := value.Bytes()
+		l := make([]byte, 8)
+		binary.BigEndian.PutUint64(l, uint64(len(b)))
+		h.Write(l)
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// hashConsentReceipt hashes the fields of the receipt, each prefixed with its length (and
+// the disclosed names with their number), so that different receipts cannot have the same
+// encoding.
+func hashConsentReceipt(recipient string, disclosed []string, timestamp int64,
+	transcriptHash []byte) []byte {
+	h := sha512.New()
+	h.Write([]byte("emmy/consent-receipt"))
+	write := func(b []byte) {
+		l := make([]byte, 8)
+		binary.BigEndian.PutUint64(l, uint64(len(b)))
+		h.Write(l)
+		h.Write(b)
+	}
+	write([]byte(recipient))
+	count := make([]byte, 8)
+	binary.BigEndian.PutUint64(count, uint64(len(disclosed)))
+	h.Write(count)
+	for _, name := range disclosed {
+		write([]byte(name))
+	}
+	ts := make([]byte, 8)
+	binary.BigEndian.PutUint64(ts, uint64(timestamp))
+	h.Write(ts)
+	write(transcriptHash)
+	return h.Sum(nil)
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package pseudonymsys
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/anoncreds"
+	"math/big"
+	"time"
+)
+
+// Epoch tickets enable a service to limit the number of actions per user per epoch (for
+// example per day) without learning who the user is. For each scope (service) and epoch
+// there is a base h = HashIntoElement(scope, epoch) and the user's ticket is h^m, where m is
+// the master secret of the user's anonymous credential (see anoncreds). Within one epoch
+// the ticket is always the same, so the service can count how many times it was used (see
+// RateLimiter), while tickets of different epochs (or different scopes) cannot be linked.
+//
+// The credential is not revealed: the user proves the possession of it with all
+// the attributes hidden (see signatures.CLPossessionProver) and proves that log_h(ticket) is
+// the master secret with the same challenge and the same random value rM as the master
+// secret has in the proof of possession - it sends x = h^rM and the verifier checks
+// h^zM = x * ticket^challenge, where zM is the response for the master secret. Thus only
+// the ticket and the proof are revealed, and they cannot be linked to the credential or to
+// the tickets of other epochs.
+//
+// Note that the master secret needs to be different for each user - the holder uses the same
+// master secret in all its credentials (see anoncreds.Holder), but it is up to the issuer
+// to issue at most one credential per user.
+
+// GetEpoch returns the index of the epoch of the given duration to which t belongs.
+func GetEpoch(t time.Time, epochDuration time.Duration) int64 {
+	return t.Unix() / int64(epochDuration/time.Second)
+}
+
+// GetEpochBase returns the base for tickets of the given scope and epoch.
+func GetEpochBase(group *groups.SchnorrGroup, scope string, epoch int64) *big.Int {
+	return group.HashIntoElement(new(big.Int).SetBytes([]byte(scope)), big.NewInt(epoch))
+}
+
+type EpochTicketProver struct {
+	Group            *groups.SchnorrGroup
+	PossessionProver *signatures.CLPossessionProver
+	secret           *big.Int
+	base             *big.Int
+}
+
+// NewEpochTicketProver returns the prover of the tickets computed with the master secret of
+// the credential issued by the issuer with pubKey.
+func NewEpochTicketProver(group *groups.SchnorrGroup, pubKey *anoncreds.PublicKey,
+	credential *anoncreds.Credential) (*EpochTicketProver, error) {
+	prover, secret, err := anoncreds.NewPossessionProver(pubKey, credential)
+	if err != nil {
+		return nil, err
+	}
+	return &EpochTicketProver{
+		Group:            group,
+		PossessionProver: prover,
+		secret:           secret,
+	}, nil
+}
+
+// GetTicket returns the ticket for the given scope and epoch.
+func (prover *EpochTicketProver) GetTicket(scope string, epoch int64) *big.Int {
+	prover.base = GetEpochBase(prover.Group, scope, epoch)
+	return prover.Group.Exp(prover.base, prover.secret)
+}
+
+// GetProofRandomData returns the randomized signature v and the first message t of the proof
+// of possession of the credential, and x = h^rM for the ticket (obtained with GetTicket).
+func (prover *EpochTicketProver) GetProofRandomData() (*big.Int, *big.Int, *big.Int) {
+	v, t := prover.PossessionProver.GetProofRandomData()
+	x := prover.Group.Exp(prover.base, prover.PossessionProver.GetBlockRandomValue(0))
+	return v, t, x
+}
+
+// GetProofData returns the responses of the proof of possession of the credential (zM holds
+// the responses for all the blocks, the master secret is block 0).
+func (prover *EpochTicketProver) GetProofData(challenge *big.Int) (*big.Int, *big.Int,
+	map[int]*big.Int) {
+	return prover.PossessionProver.GetProofData(challenge)
+}
+
+// EpochTicketVerifier verifies the ticket of a single action and records the action with
+// the limiter, which is shared by all the verifiers of the service.
+type EpochTicketVerifier struct {
+	PossessionVerifier *signatures.CLPossessionVerifier
+	limiter            *RateLimiter
+	scope              string
+	epoch              int64
+	base               *big.Int
+	ticket             *big.Int
+	x                  *big.Int
+	challenge          *big.Int
+}
+
+// NewEpochTicketVerifier returns the verifier of a ticket for the scope, which needs to be
+// computed with the master secret of a credential issued by the issuer with pubKey.
+func NewEpochTicketVerifier(limiter *RateLimiter, pubKey *anoncreds.PublicKey,
+	scope string) *EpochTicketVerifier {
+	return &EpochTicketVerifier{
+		PossessionVerifier: signatures.NewCLPossessionVerifier(pubKey.CL, nil),
+		limiter:            limiter,
+		scope:              scope,
+	}
+}
+
+// GetChallenge receives the ticket for the epoch (which needs to be the current epoch of
+// the limiter) and the first messages of the proofs (see EpochTicketProver), and returns
+// the challenge for both proofs.
+func (verifier *EpochTicketVerifier) GetChallenge(epoch int64, ticket, v, t,
+	x *big.Int) (*big.Int, error) {
+	group := verifier.limiter.Group
+	if epoch != verifier.limiter.GetEpoch() {
+		return nil, fmt.Errorf("Ticket is not for the current epoch.")
+	}
+	if !group.IsElementInGroup(ticket) || !group.IsElementInGroup(x) || v == nil || t == nil {
+		return nil, fmt.Errorf("The proof is not valid.")
+	}
+
+	challenge, err := verifier.PossessionVerifier.GetChallenge(v, t)
+	if err != nil {
+		return nil, err
+	}
+	verifier.epoch = epoch
+	verifier.base = GetEpochBase(group, verifier.scope, epoch)
+	verifier.ticket = ticket
+	verifier.x = x
+	verifier.challenge = challenge
+	return challenge, nil
+}
+
+// Verify checks the proof of possession of the credential and that the ticket is computed
+// with its master secret (h^zM = x * ticket^challenge). If both proofs are valid and
+// the limit has not been reached, the action is recorded and nil is returned. The limit is
+// checked and the action recorded atomically (see RateLimiter), thus concurrent actions with
+// the same ticket cannot exceed the limit.
+func (verifier *EpochTicketVerifier) Verify(zE, zS *big.Int, zM map[int]*big.Int) error {
+	if verifier.challenge == nil || !verifier.PossessionVerifier.Verify(zE, zS, zM) {
+		return fmt.Errorf("Credential proof is not valid.")
+	}
+	group := verifier.limiter.Group
+	left := group.Exp(verifier.base, zM[0])
+	right := group.Mul(verifier.x, group.Exp(verifier.ticket, verifier.challenge))
+	if left.Cmp(right) != 0 {
+		return fmt.Errorf("Ticket proof is not valid.")
+	}
+
+	return verifier.limiter.Reserve(verifier.scope, verifier.epoch, verifier.ticket)
+}
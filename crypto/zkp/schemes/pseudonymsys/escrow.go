@@ -0,0 +1,151 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pseudonymsys
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/encproofs"
+	"math/big"
+	"sync"
+)
+
+// NymEscrow is the master secret s of the owner of the nym (nymB = nymA^s) encrypted with
+// Camenisch-Shoup verifiable encryption under the auditor's key. The organization verifies
+// that the ciphertext contains s without learning it, while the auditor can decrypt s and
+// recover the owner's master public key g^s (which is known to CA), for example when
+// the nym is used for abuse.
+type NymEscrow struct {
+	Nym        *Pseudonym
+	Encryption *encproofs.CSDLogEncryption
+}
+
+// NymEscrowLabel returns the label under which the master secret is encrypted. It binds
+// the ciphertext to the nym, so that it cannot be used for another nym.
+func NymEscrowLabel(nym *Pseudonym) *big.Int {
+	return common.Hash(nym.A, nym.B)
+}
+
+// NewNymEscrowProver returns a prover which encrypts the master secret under the auditor's
+// key and proves that the ciphertext contains log_nymA(nymB).
+func NewNymEscrowProver(group *groups.SchnorrGroup, escrowKey *encryption.CSPaillierPubKey,
+	nym *Pseudonym, secret *big.Int) (*encproofs.CSDLogEncryptionProver, error) {
+	return encproofs.NewCSDLogEncryptionProver(escrowKey, group, nym.A, secret,
+		NymEscrowLabel(nym))
+}
+
+// OrgNymEscrow verifies that the escrow of a registered nym contains the nym's master secret.
+type OrgNymEscrow struct {
+	Group     *groups.SchnorrGroup
+	escrowKey *encryption.CSPaillierPubKey
+	verifier  *encproofs.CSDLogEncryptionVerifier
+	escrow    *NymEscrow
+}
+
+func NewOrgNymEscrow(group *groups.SchnorrGroup,
+	escrowKey *encryption.CSPaillierPubKey) *OrgNymEscrow {
+	return &OrgNymEscrow{
+		Group:     group,
+		escrowKey: escrowKey,
+	}
+}
+
+// GetChallenge receives the escrow together with the proof random data.
+func (org *OrgNymEscrow) GetChallenge(escrow *NymEscrow,
+	randomData *encproofs.CSDLogProofRandomData) (*big.Int, error) {
+	// TODO: check in a database that the nym is registered with the organization
+	verifier, err := encproofs.NewCSDLogEncryptionVerifier(org.escrowKey, org.Group,
+		escrow.Nym.A, escrow.Nym.B, NymEscrowLabel(escrow.Nym))
+	if err != nil {
+		return nil, err
+	}
+	if err := verifier.SetProofRandomData(escrow.Encryption, randomData); err != nil {
+		return nil, err
+	}
+	org.verifier = verifier
+	org.escrow = escrow
+	return verifier.GetChallenge()
+}
+
+// Verify returns the verified escrow, or an error if the proof is not valid.
+func (org *OrgNymEscrow) Verify(proofData *encproofs.CSDLogProofData) (*NymEscrow, error) {
+	if org.verifier == nil || !org.verifier.Verify(proofData) {
+		return nil, fmt.Errorf("The escrow is not valid.")
+	}
+	return org.escrow, nil
+}
+
+// NymEscrowRegistry keeps the verified escrows of the nyms. It is safe for concurrent use.
+type NymEscrowRegistry struct {
+	escrows map[string]*NymEscrow // nymA:nymB -> escrow
+	mutex   sync.Mutex
+}
+
+func NewNymEscrowRegistry() *NymEscrowRegistry {
+	return &NymEscrowRegistry{
+		escrows: make(map[string]*NymEscrow),
+	}
+}
+
+// Add stores the escrow (it replaces the previous escrow of the same nym).
+func (registry *NymEscrowRegistry) Add(escrow *NymEscrow) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.escrows[nymKey(escrow.Nym)] = escrow
+}
+
+// Get returns the escrow of the nym or nil if the nym has no escrow.
+func (registry *NymEscrowRegistry) Get(nym *Pseudonym) *NymEscrow {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	return registry.escrows[nymKey(nym)]
+}
+
+func nymKey(nym *Pseudonym) string {
+	return nym.A.String() + ":" + nym.B.String()
+}
+
+// Auditor holds the escrow secret key and recovers the identities behind the nyms.
+type Auditor struct {
+	Group      *groups.SchnorrGroup
+	cspaillier *encryption.CSPaillier
+}
+
+// NewAuditor returns an auditor; cspaillier needs to hold the secret key.
+func NewAuditor(group *groups.SchnorrGroup, cspaillier *encryption.CSPaillier) *Auditor {
+	return &Auditor{
+		Group:      group,
+		cspaillier: cspaillier,
+	}
+}
+
+// RecoverIdentity decrypts the master secret s from the escrow and returns the master
+// public key g^s of the nym's owner.
+func (auditor *Auditor) RecoverIdentity(escrow *NymEscrow) (*big.Int, error) {
+	enc := escrow.Encryption
+	s, err := auditor.cspaillier.Decrypt(enc.U, enc.E, enc.V, NymEscrowLabel(escrow.Nym))
+	if err != nil {
+		return nil, err
+	}
+	if auditor.Group.Exp(escrow.Nym.A, s).Cmp(escrow.Nym.B) != 0 {
+		return nil, fmt.Errorf("escrow does not contain the master secret of the nym")
+	}
+	return auditor.Group.Exp(auditor.Group.G, s), nil
+}
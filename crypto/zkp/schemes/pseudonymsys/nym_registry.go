@@ -0,0 +1,430 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pseudonymsys
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// nymRegistryDepth is the depth of the sparse Merkle tree of the nym registry. The leaf
+// of a nym is at the index given by SHA-256 of the nym.
+const nymRegistryDepth = 8 * sha256.Size
+
+// SignedNymRegistryRoot is the root of the nym registry published at the end of an epoch.
+// It commits to all the nyms registered before, and to the root of the previous epoch
+// (Previous is the root of the empty tree for the first epoch).
+type SignedNymRegistryRoot struct {
+	Epoch     uint64
+	Size      int
+	Root      []byte
+	Previous  []byte
+	Timestamp int64
+	R         *big.Int
+	S         *big.Int
+}
+
+// Verify checks that the root was signed by the registry with public key (x, y).
+func (root *SignedNymRegistryRoot) Verify(x, y *big.Int) bool {
+	pubKey := ecdsa.PublicKey{Curve: dlog.GetEllipticCurve(dlog.P256), X: x, Y: y}
+	return ecdsa.Verify(&pubKey, root.hash(), root.R, root.S)
+}
+
+func (root *SignedNymRegistryRoot) hash() []byte {
+	return common.HashIntoBytes(new(big.Int).SetUint64(root.Epoch),
+		big.NewInt(int64(root.Size)), new(big.Int).SetBytes(root.Root),
+		new(big.Int).SetBytes(root.Previous), big.NewInt(root.Timestamp))
+}
+
+// NymInclusionProof shows that a nym is included in the registry with the given root.
+// Siblings are the hashes of the siblings on the path from the leaf to the root, but only
+// those which are not roots of empty subtrees - Bitmap has the i-th bit set if the sibling
+// at level i (0 is the leaf level) is in Siblings.
+type NymInclusionProof struct {
+	Bitmap   []byte
+	Siblings [][]byte
+}
+
+// Verify checks that the proof ties the nym to the root (the signature of the root needs
+// to be checked separately with SignedNymRegistryRoot.Verify).
+func (proof *NymInclusionProof) Verify(root *SignedNymRegistryRoot, nym *Pseudonym) bool {
+	if len(proof.Bitmap) != nymRegistryDepth/8 {
+		return false
+	}
+
+	index := nymRegistryIndex(nym)
+	hash := hashNymRegistryLeaf(index)
+	siblings := proof.Siblings
+	for level := 0; level < nymRegistryDepth; level++ {
+		sibling := nymRegistryEmptyHashes[level]
+		if proof.Bitmap[level/8]&(1<<uint(level%8)) != 0 {
+			if len(siblings) == 0 {
+				return false
+			}
+			sibling, siblings = siblings[0], siblings[1:]
+		}
+		if index.Bit(level) == 0 {
+			hash = hashNymRegistryNode(hash, sibling)
+		} else {
+			hash = hashNymRegistryNode(sibling, hash)
+		}
+	}
+
+	return len(siblings) == 0 && bytes.Equal(hash, root.Root)
+}
+
+// NymRegistry keeps the nyms registered with the organization in a sparse Merkle tree.
+// At the end of each epoch the pending registrations are added to the tree and its root
+// is signed and published. The user obtains a proof that its nym is included in the
+// published root, and can thus show that it was registered with the organization before
+// the end of the epoch without revealing the other nyms.
+//
+// Auditors obtain the nyms added in each epoch (GetEpochNyms) and replay them with
+// NymRegistryAuditor, which detects registries which remove or change nyms once they
+// were published, or which present different roots to different users (the roots are
+// chained and signed, so two different roots of the same epoch are an evidence of it).
+type NymRegistry struct {
+	privateKey    *ecdsa.PrivateKey
+	epochDuration time.Duration
+	tree          *sparseMerkleTree
+	registered    map[string]bool // published and pending nyms
+	pending       []*Pseudonym
+	epochs        [][]*Pseudonym // nyms added in each epoch
+	roots         []*SignedNymRegistryRoot
+	published     time.Time
+	sync.Mutex
+}
+
+// NewNymRegistry returns a registry which publishes a root after each epochDuration and
+// signs it with ECDSA (P256) key d. If epochDuration is 0, roots are only published with
+// Publish.
+func NewNymRegistry(epochDuration time.Duration, d, x, y *big.Int) *NymRegistry {
+	c := dlog.GetEllipticCurve(dlog.P256)
+	pubKey := ecdsa.PublicKey{Curve: c, X: x, Y: y}
+	return &NymRegistry{
+		privateKey:    &ecdsa.PrivateKey{PublicKey: pubKey, D: d},
+		epochDuration: epochDuration,
+		tree:          newSparseMerkleTree(),
+		registered:    make(map[string]bool),
+		published:     time.Now(),
+	}
+}
+
+// Register adds the nym to the registry. It is included in the root published at the end
+// of the current epoch.
+func (registry *NymRegistry) Register(nym *Pseudonym) error {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if err := registry.publishIfDue(); err != nil {
+		return err
+	}
+	if registry.registered[nymKey(nym)] {
+		return fmt.Errorf("Nym is already registered")
+	}
+	registry.registered[nymKey(nym)] = true
+	registry.pending = append(registry.pending, nym)
+	return nil
+}
+
+// IsRegistered returns true if the nym has been registered (it might not be included in
+// a published root yet).
+func (registry *NymRegistry) IsRegistered(nym *Pseudonym) bool {
+	registry.Lock()
+	defer registry.Unlock()
+	return registry.registered[nymKey(nym)]
+}
+
+// Publish ends the current epoch - it adds the pending nyms to the tree and returns
+// the signed root.
+func (registry *NymRegistry) Publish() (*SignedNymRegistryRoot, error) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if err := registry.publish(); err != nil {
+		return nil, err
+	}
+	return registry.roots[len(registry.roots)-1], nil
+}
+
+// GetRoot returns the last published root.
+func (registry *NymRegistry) GetRoot() (*SignedNymRegistryRoot, error) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if err := registry.publishIfDue(); err != nil {
+		return nil, err
+	}
+	if len(registry.roots) == 0 {
+		return nil, fmt.Errorf("No root has been published yet")
+	}
+	return registry.roots[len(registry.roots)-1], nil
+}
+
+// GetEpochNyms returns the root published at the end of the epoch (epochs are numbered from 1)
+// and the nyms added to the tree in it.
+func (registry *NymRegistry) GetEpochNyms(epoch uint64) (*SignedNymRegistryRoot, []*Pseudonym,
+	error) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if err := registry.publishIfDue(); err != nil {
+		return nil, nil, err
+	}
+	if epoch == 0 || epoch > uint64(len(registry.roots)) {
+		return nil, nil, fmt.Errorf("Epoch %d has not been published", epoch)
+	}
+	nyms := make([]*Pseudonym, len(registry.epochs[epoch-1]))
+	copy(nyms, registry.epochs[epoch-1])
+	return registry.roots[epoch-1], nyms, nil
+}
+
+// Prove returns the proof that the nym is included in the last published root, together
+// with the root.
+func (registry *NymRegistry) Prove(nym *Pseudonym) (*NymInclusionProof,
+	*SignedNymRegistryRoot, error) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if err := registry.publishIfDue(); err != nil {
+		return nil, nil, err
+	}
+	index := nymRegistryIndex(nym)
+	if !registry.tree.contains(index) {
+		if registry.registered[nymKey(nym)] {
+			return nil, nil, fmt.Errorf("Nym will be included at the end of the epoch")
+		}
+		return nil, nil, fmt.Errorf("Nym is not registered")
+	}
+	return registry.tree.prove(index), registry.roots[len(registry.roots)-1], nil
+}
+
+func (registry *NymRegistry) publishIfDue() error {
+	if registry.epochDuration > 0 && time.Since(registry.published) >= registry.epochDuration {
+		return registry.publish()
+	}
+	return nil
+}
+
+func (registry *NymRegistry) publish() error {
+	previous := registry.tree.root()
+	for _, nym := range registry.pending {
+		registry.tree.insert(nymRegistryIndex(nym))
+	}
+
+	root := &SignedNymRegistryRoot{
+		Epoch:     uint64(len(registry.roots) + 1),
+		Size:      registry.tree.size,
+		Root:      registry.tree.root(),
+		Previous:  previous,
+		Timestamp: time.Now().Unix(),
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, registry.privateKey, root.hash())
+	if err != nil {
+		return err
+	}
+	root.R, root.S = r, s
+
+	registry.roots = append(registry.roots, root)
+	registry.epochs = append(registry.epochs, registry.pending)
+	registry.pending = nil
+	registry.published = time.Now()
+	return nil
+}
+
+// NymRegistryAuditor verifies the consistency of the roots published by the nym registry:
+// it replays the nyms added in each epoch and checks that this gives the published root.
+// Epochs need to be audited in order, starting with the first one.
+type NymRegistryAuditor struct {
+	x      *big.Int
+	y      *big.Int
+	tree   *sparseMerkleTree
+	epoch  uint64
+	failed bool
+}
+
+// NewNymRegistryAuditor returns an auditor of the registry with public key (x, y).
+func NewNymRegistryAuditor(x, y *big.Int) *NymRegistryAuditor {
+	return &NymRegistryAuditor{
+		x:    x,
+		y:    y,
+		tree: newSparseMerkleTree(),
+	}
+}
+
+// Audit checks that root is the signed root of the next epoch and that it is obtained from
+// the root of the previous epoch by adding exactly the given nyms. Once an audit fails,
+// the registry is not consistent and all the following audits fail.
+func (auditor *NymRegistryAuditor) Audit(root *SignedNymRegistryRoot, nyms []*Pseudonym) error {
+	if auditor.failed {
+		return fmt.Errorf("registry failed a previous audit")
+	}
+	if !root.Verify(auditor.x, auditor.y) {
+		return fmt.Errorf("root signature is not valid")
+	}
+	if root.Epoch != auditor.epoch+1 {
+		return fmt.Errorf("root of epoch %d expected, got %d", auditor.epoch+1, root.Epoch)
+	}
+	if !bytes.Equal(root.Previous, auditor.tree.root()) {
+		return fmt.Errorf("root is not chained to the previous epoch")
+	}
+
+	indices := make(map[string]bool, len(nyms))
+	for _, nym := range nyms {
+		index := nymRegistryIndex(nym)
+		if auditor.tree.contains(index) || indices[index.String()] {
+			return fmt.Errorf("nym is added more than once")
+		}
+		indices[index.String()] = true
+	}
+
+	// the tree cannot be reverted, thus the auditor fails for good on a mismatch
+	for _, nym := range nyms {
+		auditor.tree.insert(nymRegistryIndex(nym))
+	}
+	if auditor.tree.size != root.Size || !bytes.Equal(auditor.tree.root(), root.Root) {
+		auditor.failed = true
+		return fmt.Errorf("root does not match the nyms of the epoch")
+	}
+	auditor.epoch = root.Epoch
+	return nil
+}
+
+// sparseMerkleTree is a Merkle tree with 2^nymRegistryDepth leaves, where only the nodes
+// which are not roots of empty subtrees are stored.
+type sparseMerkleTree struct {
+	nodes map[string][]byte
+	size  int
+}
+
+func newSparseMerkleTree() *sparseMerkleTree {
+	return &sparseMerkleTree{
+		nodes: make(map[string][]byte),
+	}
+}
+
+func (tree *sparseMerkleTree) root() []byte {
+	return tree.node(nymRegistryDepth, big.NewInt(0))
+}
+
+func (tree *sparseMerkleTree) contains(index *big.Int) bool {
+	_, ok := tree.nodes[sparseMerkleKey(0, index)]
+	return ok
+}
+
+// insert sets the leaf at index and updates the nodes on the path to the root.
+func (tree *sparseMerkleTree) insert(index *big.Int) {
+	if tree.contains(index) {
+		return
+	}
+	tree.size++
+
+	hash := hashNymRegistryLeaf(index)
+	pos := new(big.Int).Set(index)
+	for level := 0; ; level++ {
+		tree.nodes[sparseMerkleKey(level, pos)] = hash
+		if level == nymRegistryDepth {
+			return
+		}
+		sibling := tree.node(level, new(big.Int).Xor(pos, big.NewInt(1)))
+		if pos.Bit(0) == 0 {
+			hash = hashNymRegistryNode(hash, sibling)
+		} else {
+			hash = hashNymRegistryNode(sibling, hash)
+		}
+		pos.Rsh(pos, 1)
+	}
+}
+
+func (tree *sparseMerkleTree) prove(index *big.Int) *NymInclusionProof {
+	proof := &NymInclusionProof{
+		Bitmap: make([]byte, nymRegistryDepth/8),
+	}
+	pos := new(big.Int).Set(index)
+	for level := 0; level < nymRegistryDepth; level++ {
+		sibling, ok := tree.nodes[sparseMerkleKey(level, new(big.Int).Xor(pos, big.NewInt(1)))]
+		if ok {
+			proof.Bitmap[level/8] |= 1 << uint(level%8)
+			proof.Siblings = append(proof.Siblings, sibling)
+		}
+		pos.Rsh(pos, 1)
+	}
+	return proof
+}
+
+// node returns the hash of the node at the given level and position within the level.
+func (tree *sparseMerkleTree) node(level int, pos *big.Int) []byte {
+	if hash, ok := tree.nodes[sparseMerkleKey(level, pos)]; ok {
+		return hash
+	}
+	return nymRegistryEmptyHashes[level]
+}
+
+func sparseMerkleKey(level int, pos *big.Int) string {
+	return fmt.Sprintf("%d:%x", level, pos)
+}
+
+// nymRegistryIndex returns the index of the leaf of the nym.
+func nymRegistryIndex(nym *Pseudonym) *big.Int {
+	h := sha256.New()
+	for _, x := range []*big.Int{nym.A, nym.B} {
+		b := x.Bytes()
+		binary.Write(h, binary.BigEndian, uint32(len(b)))
+		h.Write(b)
+	}
+	return new(big.Int).SetBytes(h.Sum(nil))
+}
+
+// nymRegistryEmptyHashes are the roots of empty subtrees at each level.
+var nymRegistryEmptyHashes = func() [][]byte {
+	hashes := make([][]byte, nymRegistryDepth+1)
+	empty := sha256.Sum256([]byte{2})
+	hashes[0] = empty[:]
+	for level := 1; level <= nymRegistryDepth; level++ {
+		hashes[level] = hashNymRegistryNode(hashes[level-1], hashes[level-1])
+	}
+	return hashes
+}()
+
+// Leaves, empty leaves and inner nodes are hashed with different prefixes (like
+// in commitments.MerkleCommitter).
+func hashNymRegistryLeaf(index *big.Int) []byte {
+	b := index.Bytes()
+	h := sha256.New()
+	h.Write([]byte{0})
+	h.Write(make([]byte, sha256.Size-len(b)))
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+func hashNymRegistryNode(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
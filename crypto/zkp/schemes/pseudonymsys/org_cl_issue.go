@@ -0,0 +1,109 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pseudonymsys
+
+import (
+	"errors"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"math/big"
+)
+
+// CLAttributes returns the blocks (indexed as in the CL public key) which the organization
+// signs for the nym (a, b), or an error if no credential is to be issued for the nym.
+type CLAttributes func(a, b *big.Int) (map[int]*big.Int, error)
+
+// OrgCLCredentialIssuer issues CL credentials (Idemix-style anonymous credentials) within
+// the organization flow of the pseudonym system. The user first authenticates with its nym
+// (proves the knowledge of log_a(b) as when obtaining pseudonymsys credentials), then
+// the organization determines the attributes for the nym and signs them together with
+// the blocks hidden by the user, for example its master secret (see signatures.CLIssuer).
+// The user later proves the possession of the credential with signatures.CLPossessionProver,
+// disclosing only some of the attributes, and the proof cannot be linked to the nym.
+type OrgCLCredentialIssuer struct {
+	Group           *groups.SchnorrGroup
+	SchnorrVerifier *dlogproofs.SchnorrVerifier
+	cl              *signatures.CL
+	attributes      CLAttributes
+	a               *big.Int
+	b               *big.Int
+	issuer          *signatures.CLIssuer
+}
+
+func NewOrgCLCredentialIssuer(group *groups.SchnorrGroup, cl *signatures.CL,
+	attributes CLAttributes) (*OrgCLCredentialIssuer, error) {
+	verifier, err := dlogproofs.NewSchnorrVerifier(group)
+	if err != nil {
+		return nil, err
+	}
+	return &OrgCLCredentialIssuer{
+		Group:           group,
+		SchnorrVerifier: verifier,
+		cl:              cl,
+		attributes:      attributes,
+	}, nil
+}
+
+func (org *OrgCLCredentialIssuer) GetAuthenticationChallenge(a, b, x *big.Int) (*big.Int, error) {
+	org.a = a
+	org.b = b
+	org.SchnorrVerifier.SetProofRandomData(x, a, b)
+	challenge, _, err := org.SchnorrVerifier.GetChallenge()
+	if err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// VerifyAuthentication verifies that the user knows log_a(b) and returns the attributes
+// which the organization signs for the nym.
+func (org *OrgCLCredentialIssuer) VerifyAuthentication(z *big.Int) (map[int]*big.Int, error) {
+	if !org.SchnorrVerifier.Verify(z) {
+		return nil, errors.New("Authentication with organization failed")
+	}
+	known, err := org.attributes(org.a, org.b)
+	if err != nil {
+		return nil, err
+	}
+	org.issuer = signatures.NewCLIssuer(org.cl, known)
+	return known, nil
+}
+
+// GetCommitmentChallenge returns the challenge for the proof of knowledge of the blocks
+// committed in U (see signatures.CLIssueReceiver).
+func (org *OrgCLCredentialIssuer) GetCommitmentChallenge(U, t *big.Int) (*big.Int, error) {
+	if org.issuer == nil {
+		return nil, errors.New("the nym is not authenticated")
+	}
+	return org.issuer.GetChallenge(U, t)
+}
+
+// IssueCredential verifies the proof of knowledge of the committed blocks and returns
+// v, e, s from which the user obtains the credential with
+// signatures.CLIssueReceiver.GetSignature. Each authentication can be used for a single
+// credential only.
+func (org *OrgCLCredentialIssuer) IssueCredential(zS1 *big.Int, zM map[int]*big.Int) (*big.Int,
+	*big.Int, *big.Int, error) {
+	if org.issuer == nil {
+		return nil, nil, nil, errors.New("the nym is not authenticated")
+	}
+	issuer := org.issuer
+	org.issuer = nil
+	return issuer.Verify(zS1, zM)
+}
@@ -19,9 +19,10 @@ package pseudonymsys
 
 import (
 	"errors"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
 	"github.com/xlab-si/emmy/crypto/groups"
 	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
-	"github.com/xlab-si/emmy/types"
 	"math/big"
 )
 
@@ -32,6 +33,7 @@ type Credential struct {
 	BToGamma      *big.Int
 	T1            *dlogproofs.Transcript
 	T2            *dlogproofs.Transcript
+	Attributes    []*CredentialAttribute
 }
 
 func NewCredential(aToGamma, bToGamma, AToGamma, BToGamma *big.Int,
@@ -47,9 +49,12 @@ func NewCredential(aToGamma, bToGamma, AToGamma, BToGamma *big.Int,
 	return credential
 }
 
+// OrgPubKeys are the public keys of the organization. Attributes are the keys of
+// the attributes embedded into its credentials (nil if it does not embed any).
 type OrgPubKeys struct {
-	H1 *big.Int
-	H2 *big.Int
+	H1         *big.Int
+	H2         *big.Int
+	Attributes *OrgAttributePubKeys
 }
 
 func NewOrgPubKeys(h1, h2 *big.Int) *OrgPubKeys {
@@ -70,12 +75,21 @@ type OrgCredentialIssuer struct {
 	EqualityProver2 *dlogproofs.DLogEqualityBTranscriptProver
 	a               *big.Int
 	b               *big.Int
+
+	// the following fields are needed for issuing a credential with attributes
+	attributeKeys    *OrgAttributeKeys
+	attributes       Attributes
+	issuedAttributes []*IssuedAttribute
+	attributeProvers []*dlogproofs.DLogEqualityBTranscriptProver
 }
 
-func NewOrgCredentialIssuer(group *groups.SchnorrGroup, s1, s2 *big.Int) *OrgCredentialIssuer {
+func NewOrgCredentialIssuer(group *groups.SchnorrGroup, s1, s2 *big.Int) (*OrgCredentialIssuer, error) {
 	// g1 = a_tilde, t1 = b_tilde,
 	// g2 = a, t2 = b
-	schnorrVerifier := dlogproofs.NewSchnorrVerifier(group, types.Sigma)
+	schnorrVerifier, err := dlogproofs.NewSchnorrVerifier(group)
+	if err != nil {
+		return nil, err
+	}
 	equalityProver1 := dlogproofs.NewDLogEqualityBTranscriptProver(group)
 	equalityProver2 := dlogproofs.NewDLogEqualityBTranscriptProver(group)
 	org := OrgCredentialIssuer{
@@ -87,30 +101,54 @@ func NewOrgCredentialIssuer(group *groups.SchnorrGroup, s1, s2 *big.Int) *OrgCre
 		EqualityProver2: equalityProver2,
 	}
 
-	return &org
+	return &org, nil
 }
 
-func (org *OrgCredentialIssuer) GetAuthenticationChallenge(a, b, x *big.Int) *big.Int {
+// SetAttributes makes the organization embed into the credential the attributes returned
+// by attributes for the authenticated nym, signed with keys (see OrgAttributeKeys).
+func (org *OrgCredentialIssuer) SetAttributes(keys *OrgAttributeKeys, attributes Attributes) {
+	org.attributeKeys = keys
+	org.attributes = attributes
+}
+
+func (org *OrgCredentialIssuer) GetAuthenticationChallenge(a, b, x *big.Int) (*big.Int, error) {
 	// TODO: check if (a, b) is registered; if not, close the session
 
 	org.a = a
 	org.b = b
 	org.SchnorrVerifier.SetProofRandomData(x, a, b)
-	challenge, _ := org.SchnorrVerifier.GetChallenge()
-	return challenge
+	challenge, _, err := org.SchnorrVerifier.GetChallenge()
+	if err != nil {
+		return nil, err
+	}
+	return challenge, nil
 }
 
 // Verifies that user knows log_a(b). Sends back proof random data (g1^r, g2^r) for both equality proofs.
+// When the attributes are set, their proof random data is given by GetIssuedAttributes.
 func (org *OrgCredentialIssuer) VerifyAuthentication(z *big.Int) (
 	*big.Int, *big.Int, *big.Int, *big.Int, *big.Int, *big.Int, error) {
-	verified := org.SchnorrVerifier.Verify(z, nil)
+	verified := org.SchnorrVerifier.Verify(z)
 	if verified {
 		A := org.Group.Exp(org.b, org.s2)
 		aA := org.Group.Mul(org.a, A)
+		if org.attributeKeys != nil {
+			d, err := org.issueAttributes(A)
+			if err != nil {
+				return nil, nil, nil, nil, nil, nil, err
+			}
+			aA = org.Group.Mul(aA, d)
+		}
 		B := org.Group.Exp(aA, org.s1)
 
-		x11, x12 := org.EqualityProver1.GetProofRandomData(org.s2, org.Group.G, org.b)
-		x21, x22 := org.EqualityProver2.GetProofRandomData(org.s1, org.Group.G, aA)
+		x11, x12, err := org.EqualityProver1.GetProofRandomData(org.s2, org.Group.G, org.b)
+		if err != nil {
+			return nil, nil, nil, nil, nil, nil, err
+		}
+		x21, x22, err := org.EqualityProver2.GetProofRandomData(org.s1, org.Group.G, aA)
+		if err != nil {
+			return nil, nil, nil, nil, nil, nil, err
+		}
 
 		return x11, x12, x21, x22, A, B, nil
 	} else {
@@ -119,9 +157,84 @@ func (org *OrgCredentialIssuer) VerifyAuthentication(z *big.Int) (
 	}
 }
 
+// issueAttributes commits to the attributes of the nym and signs them (see
+// OrgAttributeKeys). It returns the product of the signatures D_i.
+func (org *OrgCredentialIssuer) issueAttributes(A *big.Int) (*big.Int, error) {
+	keys := org.attributeKeys
+	if org.attributes == nil {
+		return nil, errors.New("the attributes of the credential are not set")
+	}
+	values, err := org.attributes(org.a, org.b)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAttributes(keys.Names, values); err != nil {
+		return nil, err
+	}
+
+	product := big.NewInt(1)
+	org.issuedAttributes = make([]*IssuedAttribute, len(keys.Names))
+	org.attributeProvers = make([]*dlogproofs.DLogEqualityBTranscriptProver, len(keys.Names))
+	for i, name := range keys.Names {
+		r, err := common.GetRandomInt(org.Group.Q)
+		if err != nil {
+			return nil, err
+		}
+		v := commitAttribute(org.Group, org.a, A, values[name], r)
+		d := org.Group.Exp(v, keys.secrets[i])
+		product = org.Group.Mul(product, d)
+
+		prover := dlogproofs.NewDLogEqualityBTranscriptProver(org.Group)
+		x1, x2, err := prover.GetProofRandomData(keys.secrets[i], org.Group.G, v)
+		if err != nil {
+			return nil, err
+		}
+		org.attributeProvers[i] = prover
+		org.issuedAttributes[i] = &IssuedAttribute{
+			Name:  name,
+			Value: values[name],
+			R:     r,
+			D:     d,
+			X1:    x1,
+			X2:    x2,
+		}
+	}
+	return product, nil
+}
+
+// GetIssuedAttributes returns the attributes embedded into the credential (in the order of
+// the attribute keys), or nil if the organization does not embed attributes.
+func (org *OrgCredentialIssuer) GetIssuedAttributes() []*IssuedAttribute {
+	return org.issuedAttributes
+}
+
 func (org *OrgCredentialIssuer) GetEqualityProofData(challenge1,
-	challenge2 *big.Int) (*big.Int, *big.Int) {
-	z1 := org.EqualityProver1.GetProofData(challenge1)
-	z2 := org.EqualityProver2.GetProofData(challenge2)
-	return z1, z2
+	challenge2 *big.Int) (*big.Int, *big.Int, error) {
+	z1, err := org.EqualityProver1.GetProofData(challenge1)
+	if err != nil {
+		return nil, nil, err
+	}
+	z2, err := org.EqualityProver2.GetProofData(challenge2)
+	if err != nil {
+		return nil, nil, err
+	}
+	return z1, z2, nil
+}
+
+// GetAttributeProofData returns the responses of the equality proofs of the attributes
+// for the given challenges (one for each attribute).
+func (org *OrgCredentialIssuer) GetAttributeProofData(challenges []*big.Int) ([]*big.Int,
+	error) {
+	if len(challenges) != len(org.attributeProvers) {
+		return nil, fmt.Errorf("challenges need to be given for %d attributes",
+			len(org.attributeProvers))
+	}
+	z := make([]*big.Int, len(challenges))
+	for i, prover := range org.attributeProvers {
+		var err error
+		if z[i], err = prover.GetProofData(challenges[i]); err != nil {
+			return nil, err
+		}
+	}
+	return z, nil
 }
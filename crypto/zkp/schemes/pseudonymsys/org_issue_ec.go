@@ -74,7 +74,7 @@ type OrgCredentialIssuerEC struct {
 func NewOrgCredentialIssuerEC(s1, s2 *big.Int, curveType dlog.Curve) *OrgCredentialIssuerEC {
 	// g1 = a_tilde, t1 = b_tilde,
 	// g2 = a, t2 = b
-	schnorrVerifier := dlogproofs.NewSchnorrECVerifier(curveType, types.Sigma)
+	schnorrVerifier, _ := dlogproofs.NewSchnorrECVerifier(dlogproofs.WithCurve(curveType))
 	equalityProver1 := dlogproofs.NewECDLogEqualityBTranscriptProver(curveType)
 	equalityProver2 := dlogproofs.NewECDLogEqualityBTranscriptProver(curveType)
 	org := OrgCredentialIssuerEC{
@@ -88,21 +88,24 @@ func NewOrgCredentialIssuerEC(s1, s2 *big.Int, curveType dlog.Curve) *OrgCredent
 	return &org
 }
 
-func (org *OrgCredentialIssuerEC) GetAuthenticationChallenge(a, b, x *types.ECGroupElement) *big.Int {
+func (org *OrgCredentialIssuerEC) GetAuthenticationChallenge(a, b, x *types.ECGroupElement) (*big.Int, error) {
 	// TODO: check if (a, b) is registered; if not, close the session
 
 	org.a = a
 	org.b = b
 	org.SchnorrVerifier.SetProofRandomData(x, a, b)
-	challenge, _ := org.SchnorrVerifier.GetChallenge()
-	return challenge
+	challenge, _, err := org.SchnorrVerifier.GetChallenge()
+	if err != nil {
+		return nil, err
+	}
+	return challenge, nil
 }
 
 // Verifies that user knows log_a(b). Sends back proof random data (g1^r, g2^r) for both equality proofs.
 func (org *OrgCredentialIssuerEC) VerifyAuthentication(z *big.Int) (
 	*types.ECGroupElement, *types.ECGroupElement, *types.ECGroupElement,
 	*types.ECGroupElement, *types.ECGroupElement, *types.ECGroupElement, error) {
-	verified := org.SchnorrVerifier.Verify(z, nil)
+	verified := org.SchnorrVerifier.Verify(z)
 	if verified {
 		A1, A2 := org.SchnorrVerifier.DLog.Exponentiate(org.b.X, org.b.Y, org.s2)
 		aA1, aA2 := org.SchnorrVerifier.DLog.Multiply(org.a.X, org.a.Y, A1, A2)
@@ -116,8 +119,14 @@ func (org *OrgCredentialIssuerEC) VerifyAuthentication(z *big.Int) (
 		g2 := types.NewECGroupElement(org.b.X, org.b.Y)
 		g3 := types.NewECGroupElement(aA1, aA2)
 
-		x11, x12 := org.EqualityProver1.GetProofRandomData(org.s2, g1, g2)
-		x21, x22 := org.EqualityProver2.GetProofRandomData(org.s1, g1, g3)
+		x11, x12, err := org.EqualityProver1.GetProofRandomData(org.s2, g1, g2)
+		if err != nil {
+			return nil, nil, nil, nil, nil, nil, err
+		}
+		x21, x22, err := org.EqualityProver2.GetProofRandomData(org.s1, g1, g3)
+		if err != nil {
+			return nil, nil, nil, nil, nil, nil, err
+		}
 
 		return x11, x12, x21, x22, A, B, nil
 	} else {
@@ -127,8 +136,14 @@ func (org *OrgCredentialIssuerEC) VerifyAuthentication(z *big.Int) (
 }
 
 func (org *OrgCredentialIssuerEC) GetEqualityProofData(challenge1,
-	challenge2 *big.Int) (*big.Int, *big.Int) {
-	z1 := org.EqualityProver1.GetProofData(challenge1)
-	z2 := org.EqualityProver2.GetProofData(challenge2)
-	return z1, z2
+	challenge2 *big.Int) (*big.Int, *big.Int, error) {
+	z1, err := org.EqualityProver1.GetProofData(challenge1)
+	if err != nil {
+		return nil, nil, err
+	}
+	z2, err := org.EqualityProver2.GetProofData(challenge2)
+	if err != nil {
+		return nil, nil, err
+	}
+	return z1, z2, nil
 }
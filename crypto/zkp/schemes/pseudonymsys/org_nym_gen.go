@@ -18,10 +18,7 @@
 package pseudonymsys
 
 import (
-	"crypto/ecdsa"
 	"fmt"
-	"github.com/xlab-si/emmy/crypto/common"
-	"github.com/xlab-si/emmy/crypto/dlog"
 	"github.com/xlab-si/emmy/crypto/groups"
 	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
 	"math/big"
@@ -41,29 +38,41 @@ func NewPseudonym(a, b *big.Int) *Pseudonym {
 
 type OrgNymGen struct {
 	EqualityVerifier *dlogproofs.DLogEqualityVerifier
-	x                *big.Int
-	y                *big.Int
+	caPubKeys        []CAPubKey
 }
 
+// NewOrgNymGen returns OrgNymGen which accepts certificates signed with CA's
+// ECDSA key (x, y).
 func NewOrgNymGen(group *groups.SchnorrGroup, x, y *big.Int) *OrgNymGen {
+	return NewOrgNymGenWithCAPubKeys(group, NewECDSAPubKey(x, y))
+}
+
+// NewOrgNymGenWithCAPubKeys returns OrgNymGen which accepts certificates signed with
+// any of the CA's keys.
+func NewOrgNymGenWithCAPubKeys(group *groups.SchnorrGroup, caPubKeys ...CAPubKey) *OrgNymGen {
 	verifier := dlogproofs.NewDLogEqualityVerifier(group)
 	org := OrgNymGen{
 		EqualityVerifier: verifier,
-		x:                x,
-		y:                y,
+		caPubKeys:        caPubKeys,
 	}
 	return &org
 }
 
-func (org *OrgNymGen) GetChallenge(nymA, blindedA, nymB, blindedB, x1, x2,
-	r, s *big.Int) (*big.Int, error) {
-	c := dlog.GetEllipticCurve(dlog.P256)
-	pubKey := ecdsa.PublicKey{Curve: c, X: org.x, Y: org.y}
-
-	hashed := common.HashIntoBytes(blindedA, blindedB)
-	verified := ecdsa.Verify(&pubKey, hashed, r, s)
+func (org *OrgNymGen) GetChallenge(nymA, nymB, x1, x2 *big.Int,
+	caCertificate *CACertificate) (*big.Int, error) {
+	verified := false
+	for _, pubKey := range org.caPubKeys {
+		if caCertificate.Verify(pubKey) {
+			verified = true
+			break
+		}
+	}
 	if verified {
-		challenge := org.EqualityVerifier.GetChallenge(nymA, blindedA, nymB, blindedB, x1, x2)
+		challenge, err := org.EqualityVerifier.GetChallenge(nymA, caCertificate.BlindedA, nymB,
+			caCertificate.BlindedB, x1, x2)
+		if err != nil {
+			return nil, err
+		}
 		return challenge, nil
 	} else {
 		return nil, fmt.Errorf("The signature is not valid.")
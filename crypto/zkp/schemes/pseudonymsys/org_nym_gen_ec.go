@@ -65,7 +65,10 @@ func (org *OrgNymGenEC) GetChallenge(nymA, blindedA, nymB, blindedB,
 	hashed := common.HashIntoBytes(blindedA.X, blindedA.Y, blindedB.X, blindedB.Y)
 	verified := ecdsa.Verify(&pubKey, hashed, r, s)
 	if verified {
-		challenge := org.EqualityVerifier.GetChallenge(nymA, blindedA, nymB, blindedB, x1, x2)
+		challenge, err := org.EqualityVerifier.GetChallenge(nymA, blindedA, nymB, blindedB, x1, x2)
+		if err != nil {
+			return nil, err
+		}
 		return challenge, nil
 	} else {
 		return nil, fmt.Errorf("The signature is not valid.")
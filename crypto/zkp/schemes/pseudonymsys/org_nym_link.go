@@ -0,0 +1,67 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pseudonymsys
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"math/big"
+)
+
+// OrgNymLink is used for account recovery - when a user loses the device where the nym
+// registered with the organization was used, it registers a new nym (using a master
+// key restored from backup) and proves that the new nym belongs to the same master key
+// as the old one: log_oldA(oldB) = log_newA(newB). The organization can then re-bind to
+// the new nym whatever was bound to the old one (for example re-issue credentials).
+// Note that the user does not reveal the master key.
+type OrgNymLink struct {
+	EqualityVerifier *dlogproofs.DLogEqualityVerifier
+	oldNym           *Pseudonym
+	newNym           *Pseudonym
+}
+
+func NewOrgNymLink(group *groups.SchnorrGroup) *OrgNymLink {
+	return &OrgNymLink{
+		EqualityVerifier: dlogproofs.NewDLogEqualityVerifier(group),
+	}
+}
+
+// GetChallenge receives the old and the new nym together with the proof random data
+// x1 = oldA^r, x2 = newA^r.
+func (org *OrgNymLink) GetChallenge(oldNym, newNym *Pseudonym, x1, x2 *big.Int) (*big.Int,
+	error) {
+	// TODO: check in a database that both nyms are registered with the organization
+	if oldNym.A.Cmp(newNym.A) == 0 {
+		return nil, fmt.Errorf("The new nym needs to differ from the old one.")
+	}
+
+	org.oldNym = oldNym
+	org.newNym = newNym
+	challenge, err := org.EqualityVerifier.GetChallenge(oldNym.A, newNym.A, oldNym.B, newNym.B,
+		x1, x2)
+	if err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// Verify returns true if the new nym is proved to belong to the same master key as the old one.
+func (org *OrgNymLink) Verify(z *big.Int) bool {
+	return org.EqualityVerifier.Verify(z)
+}
@@ -45,13 +45,16 @@ func NewOrgCredentialVerifier(group *groups.SchnorrGroup, s1, s2 *big.Int) *OrgC
 	return &org
 }
 
-func (org *OrgCredentialVerifier) GetAuthenticationChallenge(a, b, a1, b1, x1, x2 *big.Int) *big.Int {
+func (org *OrgCredentialVerifier) GetAuthenticationChallenge(a, b, a1, b1, x1, x2 *big.Int) (*big.Int, error) {
 	// TODO: check if (a, b) is registered; if not, close the session
 
 	org.a = a
 	org.b = b
-	challenge := org.EqualityVerifier.GetChallenge(a, a1, b, b1, x1, x2)
-	return challenge
+	challenge, err := org.EqualityVerifier.GetChallenge(a, a1, b, b1, x1, x2)
+	if err != nil {
+		return nil, err
+	}
+	return challenge, nil
 }
 
 func (org *OrgCredentialVerifier) VerifyAuthentication(z *big.Int,
@@ -61,17 +64,24 @@ func (org *OrgCredentialVerifier) VerifyAuthentication(z *big.Int,
 		return false
 	}
 
-	valid1 := dlogproofs.VerifyBlindedTranscript(credential.T1, org.Group, org.Group.G, orgPubKeys.H2,
-		credential.SmallBToGamma, credential.AToGamma)
+	return verifyCredential(org.Group, credential, orgPubKeys)
+}
 
-	aAToGamma := org.Group.Mul(credential.SmallAToGamma, credential.AToGamma)
-	valid2 := dlogproofs.VerifyBlindedTranscript(credential.T2, org.Group, org.Group.G, orgPubKeys.H1,
-		aAToGamma, credential.BToGamma)
+// verifyCredential checks that the credential (including its attributes) has been issued
+// by the organization with orgPubKeys.
+func verifyCredential(group *groups.SchnorrGroup, credential *Credential,
+	orgPubKeys *OrgPubKeys) bool {
+	valid1 := dlogproofs.VerifyBlindedTranscript(credential.T1, group, group.G, orgPubKeys.H2,
+		credential.SmallBToGamma, credential.AToGamma)
 
-	if valid1 && valid2 {
-		return true
-	} else {
+	d, ok := verifyAttributes(group, credential, orgPubKeys.Attributes)
+	if !ok {
 		return false
 	}
+	aAToGamma := group.Mul(credential.SmallAToGamma, credential.AToGamma)
+	aAToGamma = group.Mul(aAToGamma, d)
+	valid2 := dlogproofs.VerifyBlindedTranscript(credential.T2, group, group.G, orgPubKeys.H1,
+		aAToGamma, credential.BToGamma)
 
+	return valid1 && valid2
 }
@@ -47,13 +47,16 @@ func NewOrgCredentialVerifierEC(s1, s2 *big.Int, curveType dlog.Curve) *OrgCrede
 }
 
 func (org *OrgCredentialVerifierEC) GetAuthenticationChallenge(a, b, a1, b1,
-	x1, x2 *types.ECGroupElement) *big.Int {
+	x1, x2 *types.ECGroupElement) (*big.Int, error) {
 	// TODO: check if (a, b) is registered; if not, close the session
 
 	org.a = a
 	org.b = b
-	challenge := org.EqualityVerifier.GetChallenge(a, a1, b, b1, x1, x2)
-	return challenge
+	challenge, err := org.EqualityVerifier.GetChallenge(a, a1, b, b1, x1, x2)
+	if err != nil {
+		return nil, err
+	}
+	return challenge, nil
 }
 
 func (org *OrgCredentialVerifierEC) VerifyAuthentication(z *big.Int,
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package pseudonymsys
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"time"
+)
+
+// Minimal bit lengths of the group parameters which are accepted by Params.Validate.
+const (
+	MinModulusBitLen = 2048
+	MinOrderBitLen   = 224
+)
+
+// Params are the parameters of the pseudonym system (in modular arithmetic) which need
+// to be shared by the CA, the organizations and the users.
+type Params struct {
+	Group *groups.SchnorrGroup
+}
+
+func NewParams(group *groups.SchnorrGroup) *Params {
+	return &Params{
+		Group: group,
+	}
+}
+
+// Validate checks that the group is a valid Schnorr group and that its parameters
+// are long enough.
+func (params *Params) Validate() error {
+	if params.Group == nil {
+		return fmt.Errorf("Group is not set")
+	}
+	if err := params.Group.Validate(); err != nil {
+		return err
+	}
+	if params.Group.P.BitLen() < MinModulusBitLen {
+		return fmt.Errorf("Modulus needs to be at least %d bits long", MinModulusBitLen)
+	}
+	if params.Group.Q.BitLen() < MinOrderBitLen {
+		return fmt.Errorf("Group order needs to be at least %d bits long", MinOrderBitLen)
+	}
+	return nil
+}
+
+// RateLimitParams are the parameters of the rate limiting (see RateLimiter) which need to be
+// shared by the server and the users - the users compute the epoch tickets for the period.
+type RateLimitParams struct {
+	Limit  int
+	Period time.Duration
+}
+
+func NewRateLimitParams(limit int, period time.Duration) *RateLimitParams {
+	return &RateLimitParams{
+		Limit:  limit,
+		Period: period,
+	}
+}
+
+// Validate checks that at least one action per period is allowed and that the period
+// is at least one second long.
+func (params *RateLimitParams) Validate() error {
+	if params.Limit < 1 {
+		return fmt.Errorf("Limit needs to be positive")
+	}
+	if params.Period < time.Second {
+		return fmt.Errorf("Period needs to be at least one second long")
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pseudonymsys
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// PossessionNonceLength is the length (in bytes) of the nonces of proof-of-possession
+// challenges.
+const PossessionNonceLength = 32
+
+// PossessionChallenge is issued to the user which claims to own the nym. The user answers
+// it with ProvePossession before the challenge expires.
+type PossessionChallenge struct {
+	ID      string
+	Nonce   []byte
+	Expires time.Time
+}
+
+// PossessionResponse is the non-interactive Schnorr proof of knowledge of the master
+// secret of the nym (B = A^secret), bound to the nonce of the challenge.
+type PossessionResponse struct {
+	C *big.Int
+	Z *big.Int
+}
+
+// ProvePossession answers the challenge - it proves the knowledge of secret such that
+// nym.B = nym.A^secret.
+func ProvePossession(group *groups.SchnorrGroup, secret *big.Int, nym *Pseudonym,
+	challenge *PossessionChallenge) (*PossessionResponse, error) {
+	r, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, err
+	}
+	t := group.Exp(nym.A, r)
+	c := possessionChallenge(group, nym, t, challenge)
+	z := new(big.Int).Mul(c, secret)
+	z.Add(z, r)
+	z.Mod(z, group.Q)
+	return &PossessionResponse{
+		C: c,
+		Z: z,
+	}, nil
+}
+
+// PossessionVerifier provides a one-round proof of possession of the nyms from the registry
+// to the services which are not part of the pseudonym system (for example custom login
+// pages): the service obtains a challenge for the nym which the user claims to own
+// (CreateChallenge), passes it to the user and verifies the user's response (VerifyResponse).
+// Each challenge can be answered only once and only before it expires.
+type PossessionVerifier struct {
+	group      *groups.SchnorrGroup
+	registry   *NymRegistry
+	ttl        time.Duration
+	challenges map[string]*pendingPossession
+	sync.Mutex
+}
+
+type pendingPossession struct {
+	nym       *Pseudonym
+	challenge *PossessionChallenge
+}
+
+// NewPossessionVerifier returns a verifier of the possession of the nyms registered
+// in registry, whose challenges expire after ttl.
+func NewPossessionVerifier(group *groups.SchnorrGroup, registry *NymRegistry,
+	ttl time.Duration) *PossessionVerifier {
+	return &PossessionVerifier{
+		group:      group,
+		registry:   registry,
+		ttl:        ttl,
+		challenges: make(map[string]*pendingPossession),
+	}
+}
+
+// CreateChallenge returns a fresh challenge for the proof of possession of the nym.
+func (verifier *PossessionVerifier) CreateChallenge(nym *Pseudonym) (*PossessionChallenge,
+	error) {
+	if nym == nil || nym.A == nil || nym.B == nil {
+		return nil, fmt.Errorf("Nym is not valid")
+	}
+	if !verifier.registry.IsRegistered(nym) {
+		return nil, fmt.Errorf("Nym is not registered")
+	}
+
+	id := make([]byte, 16)
+	nonce := make([]byte, PossessionNonceLength)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	challenge := &PossessionChallenge{
+		ID:      hex.EncodeToString(id),
+		Nonce:   nonce,
+		Expires: time.Now().Add(verifier.ttl),
+	}
+
+	verifier.Lock()
+	defer verifier.Unlock()
+	verifier.removeExpired()
+	verifier.challenges[challenge.ID] = &pendingPossession{
+		nym:       nym,
+		challenge: challenge,
+	}
+	return challenge, nil
+}
+
+// VerifyResponse returns the nym for which the challenge with the given ID was created if
+// the response proves its possession. It returns an error if the challenge is not known
+// (it has already been answered or it has expired) or the proof is not valid.
+func (verifier *PossessionVerifier) VerifyResponse(id string,
+	response *PossessionResponse) (*Pseudonym, error) {
+	verifier.Lock()
+	pending, ok := verifier.challenges[id]
+	delete(verifier.challenges, id)
+	verifier.Unlock()
+
+	if !ok || time.Now().After(pending.challenge.Expires) {
+		return nil, fmt.Errorf("Challenge is not known or has expired")
+	}
+	if response == nil || response.C == nil || response.Z == nil {
+		return nil, fmt.Errorf("Response is not valid")
+	}
+
+	// t = A^z * B^(-c)
+	group := verifier.group
+	nym := pending.nym
+	t := common.MultiExp([]*big.Int{nym.A, nym.B},
+		[]*big.Int{response.Z, new(big.Int).Neg(response.C)}, group.P)
+	if t == nil || possessionChallenge(group, nym, t, pending.challenge).Cmp(response.C) != 0 {
+		return nil, fmt.Errorf("Proof of possession is not valid")
+	}
+	return nym, nil
+}
+
+func (verifier *PossessionVerifier) removeExpired() {
+	now := time.Now()
+	for id, pending := range verifier.challenges {
+		if now.After(pending.challenge.Expires) {
+			delete(verifier.challenges, id)
+		}
+	}
+}
+
+// possessionChallenge returns the Fiat-Shamir challenge which binds the proof to the nym
+// and to the challenge of the verifier.
+func possessionChallenge(group *groups.SchnorrGroup, nym *Pseudonym, t *big.Int,
+	challenge *PossessionChallenge) *big.Int {
+	c := common.Hash(group.G, nym.A, nym.B, t, new(big.Int).SetBytes([]byte(challenge.ID)),
+		new(big.Int).SetBytes(challenge.Nonce))
+	return c.Mod(c, group.Q)
+}
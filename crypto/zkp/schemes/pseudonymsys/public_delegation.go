@@ -0,0 +1,150 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pseudonymsys
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+)
+
+// PublicDelegationKeys are the keys of an entity that is able to issue credentials - the root
+// organization or a credential holder to whom the right to issue credentials was delegated.
+// Keys s1, s2 (and public keys h1 = g^s1, h2 = g^s2) are used to issue ordinary
+// pseudonymsys credentials, while signingKey is used to delegate the right to issue credentials
+// further down the chain.
+//
+// Delegation is public, not anonymous: the chain of certificates is presented in the clear
+// together with the credential, so the verifier learns the public keys of every intermediate
+// issuer and can link all credentials which were issued through the same chain. The keys are
+// not related to the master key or nyms of the delegatee, but they serve as a stable
+// identifier of the delegatee. Delegation which hides the issuer of the credential and
+// the chain from it to the root is implemented for anonymous credentials (see
+// anoncreds.VerifyDelegation).
+type PublicDelegationKeys struct {
+	Group      *groups.SchnorrGroup
+	PubKeys    *OrgPubKeys
+	s1         *big.Int
+	s2         *big.Int
+	signingKey *ecdsa.PrivateKey
+}
+
+func NewPublicDelegationKeys(group *groups.SchnorrGroup) (*PublicDelegationKeys, error) {
+	s1, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, err
+	}
+	s2, err := common.GetRandomInt(group.Q)
+	if err != nil {
+		return nil, err
+	}
+	signingKey, err := ecdsa.GenerateKey(dlog.GetEllipticCurve(dlog.P256), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PublicDelegationKeys{
+		Group:      group,
+		PubKeys:    NewOrgPubKeys(group.Exp(group.G, s1), group.Exp(group.G, s2)),
+		s1:         s1,
+		s2:         s2,
+		signingKey: signingKey,
+	}, nil
+}
+
+// GetSigningPubKey returns the public key which is needed to verify delegation
+// certificates issued with these keys.
+func (keys *PublicDelegationKeys) GetSigningPubKey() *ecdsa.PublicKey {
+	return &keys.signingKey.PublicKey
+}
+
+// GetCredentialIssuer returns OrgCredentialIssuer which issues credentials with
+// these delegation keys.
+func (keys *PublicDelegationKeys) GetCredentialIssuer() (*OrgCredentialIssuer, error) {
+	return NewOrgCredentialIssuer(keys.Group, keys.s1, keys.s2)
+}
+
+// Delegate issues a certificate which grants the owner of delegatee keys the right
+// to issue credentials.
+func (keys *PublicDelegationKeys) Delegate(
+	delegatee *PublicDelegationKeys) (*PublicDelegationCertificate, error) {
+	signingPubKey := delegatee.GetSigningPubKey()
+	hashed := common.HashIntoBytes(delegatee.PubKeys.H1, delegatee.PubKeys.H2,
+		signingPubKey.X, signingPubKey.Y)
+	r, s, err := ecdsa.Sign(rand.Reader, keys.signingKey, hashed)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPublicDelegationCertificate(delegatee.PubKeys, signingPubKey.X, signingPubKey.Y,
+		r, s), nil
+}
+
+// PublicDelegationCertificate is a link in the delegation chain. It contains the public keys
+// of the delegatee and a signature of these keys by the delegator. Certificates are not
+// hidden from the verifier (see PublicDelegationKeys).
+type PublicDelegationCertificate struct {
+	PubKeys  *OrgPubKeys
+	SigningX *big.Int
+	SigningY *big.Int
+	R        *big.Int
+	S        *big.Int
+}
+
+func NewPublicDelegationCertificate(pubKeys *OrgPubKeys, signingX, signingY, r,
+	s *big.Int) *PublicDelegationCertificate {
+	return &PublicDelegationCertificate{
+		PubKeys:  pubKeys,
+		SigningX: signingX,
+		SigningY: signingY,
+		R:        r,
+		S:        s,
+	}
+}
+
+// VerifyPublicDelegationChain checks that each certificate in the chain is signed by the
+// delegator from the previous link (the first one by the root). It returns the
+// public keys of the last delegator in the chain - these are to be used when verifying
+// a credential (see OrgCredentialVerifier.VerifyAuthentication). The chain is checked
+// in the clear, so the caller sees the keys of all intermediate issuers.
+func VerifyPublicDelegationChain(rootPubKey *ecdsa.PublicKey,
+	chain []*PublicDelegationCertificate) (*OrgPubKeys, error) {
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("Delegation chain is empty")
+	}
+
+	signerPubKey := rootPubKey
+	for i, cert := range chain {
+		hashed := common.HashIntoBytes(cert.PubKeys.H1, cert.PubKeys.H2,
+			cert.SigningX, cert.SigningY)
+		if !ecdsa.Verify(signerPubKey, hashed, cert.R, cert.S) {
+			return nil, fmt.Errorf("Delegation certificate at level %d is not valid", i+1)
+		}
+		signerPubKey = &ecdsa.PublicKey{
+			Curve: dlog.GetEllipticCurve(dlog.P256),
+			X:     cert.SigningX,
+			Y:     cert.SigningY,
+		}
+	}
+
+	return chain[len(chain)-1].PubKeys, nil
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package pseudonymsys
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// RateLimiter enforces "at most limit actions per human per scope per period". A human is
+// represented by a (personhood) anonymous credential, each action needs to be accompanied by
+// an epoch ticket for the scope and the current period, computed with the master secret of
+// the credential (see EpochTicketProver). The credential itself is not revealed, thus
+// the actions of different scopes or periods cannot be linked to each other or to
+// the credential - only the actions in the same scope and period are linkable (they carry
+// the same ticket).
+//
+// RateLimiter is safe for concurrent use - it keeps the usage of tickets for all scopes,
+// while a new EpochTicketVerifier needs to be created for each action.
+type RateLimiter struct {
+	Group  *groups.SchnorrGroup
+	limit  int
+	period time.Duration
+	epoch  int64
+	usage  map[string]map[string]int // scope -> ticket -> number of actions
+	mutex  sync.Mutex
+}
+
+func NewRateLimiter(group *groups.SchnorrGroup, params *RateLimitParams) (*RateLimiter, error) {
+	if err := params.Validate(); err != nil {
+		return nil, fmt.Errorf("Invalid rate limit parameters: %v", err)
+	}
+	return &RateLimiter{
+		Group:  group,
+		limit:  params.Limit,
+		period: params.Period,
+		usage:  make(map[string]map[string]int),
+	}, nil
+}
+
+// GetEpoch returns the current epoch - the ticket for the current action needs to be
+// computed for this epoch.
+func (limiter *RateLimiter) GetEpoch() int64 {
+	return GetEpoch(time.Now(), limiter.period)
+}
+
+// Reserve records the action with the ticket if the ticket has not yet reached the limit,
+// otherwise it returns an error. The limit is checked and the action recorded atomically.
+// The ticket needs to be verified before (EpochTicketVerifier calls Reserve once the proofs
+// are verified).
+func (limiter *RateLimiter) Reserve(scope string, epoch int64, ticket *big.Int) error {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if epoch > limiter.epoch {
+		limiter.epoch = epoch
+		limiter.usage = make(map[string]map[string]int)
+	}
+	if epoch < limiter.epoch {
+		return fmt.Errorf("Ticket for epoch %d has expired.", epoch)
+	}
+	if limiter.usage[scope] == nil {
+		limiter.usage[scope] = make(map[string]int)
+	}
+	if limiter.usage[scope][ticket.String()] >= limiter.limit {
+		return fmt.Errorf("Limit of actions in scope %s has been reached.", scope)
+	}
+	limiter.usage[scope][ticket.String()]++
+	return nil
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pseudonymsys
+
+import (
+	"github.com/xlab-si/emmy/crypto/accumulators"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/common"
+	"math/big"
+)
+
+// RevocationHandleBitLength is the bit length of the primes which represent credentials
+// in the revocation accumulators.
+const RevocationHandleBitLength = 256
+
+// Credential revocation: an organization maintains an RSA accumulator of the revocation
+// handles of the revoked credentials (the handle is a prime derived from the credential,
+// see CredentialRevocationHandle). When transferring the credential, the user obtains
+// the non-membership witness for the current accumulator value and proves in zero knowledge
+// that it knows the witness. As the credential is revealed in the transfer anyway,
+// the commitment to the handle is computed by both parties as g^x (with zero randomness)
+// which binds the proof to the credential.
+//
+// CredentialRevocationHandle returns the prime which represents the credential in
+// the accumulators.
+func CredentialRevocationHandle(credential *Credential) (*big.Int, error) {
+	data := common.ConcatenateNumbers(credential.SmallAToGamma, credential.SmallBToGamma,
+		credential.AToGamma, credential.BToGamma)
+	return accumulators.HashToPrime(data, RevocationHandleBitLength)
+}
+
+// NewCredentialNonRevocationProver returns a prover of the statement that the credential is
+// not revoked in the accumulator value v.
+func NewCredentialNonRevocationProver(params *commitments.DamgardFujisakiParams, v *big.Int,
+	credential *Credential,
+	witness *accumulators.NonMembershipWitness) (*accumulators.NonMembershipProver, error) {
+	x, err := CredentialRevocationHandle(credential)
+	if err != nil {
+		return nil, err
+	}
+	r := big.NewInt(0)
+	return accumulators.NewNonMembershipProver(params, v, params.Commit(x, r), x, r, witness),
+		nil
+}
+
+// NewCredentialNonRevocationVerifier returns a verifier of the statement that the credential
+// is not revoked in the accumulator value v.
+func NewCredentialNonRevocationVerifier(params *commitments.DamgardFujisakiParams, v *big.Int,
+	credential *Credential) (*accumulators.NonMembershipVerifier, error) {
+	x, err := CredentialRevocationHandle(credential)
+	if err != nil {
+		return nil, err
+	}
+	return accumulators.NewNonMembershipVerifier(params, v, params.Commit(x, big.NewInt(0))),
+		nil
+}
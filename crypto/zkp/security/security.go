@@ -0,0 +1,117 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package security contains the security parameters of the proofs, which can be adjusted
+// to the security level targeted by a deployment (for example 112, 128 or 192 bits).
+package security
+
+import (
+	"fmt"
+	"math"
+)
+
+// Bounds of the parameters. Parameters below MinBitLength bits are not considered secure,
+// the upper bounds prevent the peers from requesting excessive computation.
+const (
+	MinBitLength   = 80
+	MaxBitLength   = 512
+	MaxRepetitions = 1024
+)
+
+// Params are the security parameters of a proof. Zero values are not set - the parameters
+// which are not set are derived from Level, or the defaults of the scheme are used if
+// Level is not set either. Each scheme uses only the parameters which apply to it.
+type Params struct {
+	// Level is the targeted security level in bits.
+	Level int
+	// ChallengeBitLength is the bit length of the challenges, which determines
+	// the soundness error of the proofs with large challenge space.
+	ChallengeBitLength int
+	// StatisticalBitLength determines how well the responses (computed in integers) hide
+	// the secrets - the statistical distance is about 2^(-StatisticalBitLength).
+	StatisticalBitLength int
+	// Repetitions is the number of (parallel) runs of the protocols with small challenge
+	// space.
+	Repetitions int
+}
+
+// Validate checks that the parameters which are set are within the bounds.
+func (params Params) Validate() error {
+	for _, p := range []struct {
+		name  string
+		value int
+	}{
+		{"security level", params.Level},
+		{"challenge bit length", params.ChallengeBitLength},
+		{"statistical bit length", params.StatisticalBitLength},
+	} {
+		if p.value != 0 && (p.value < MinBitLength || p.value > MaxBitLength) {
+			return fmt.Errorf("%s needs to be from [%d, %d]", p.name, MinBitLength,
+				MaxBitLength)
+		}
+	}
+	if params.Repetitions < 0 || params.Repetitions > MaxRepetitions {
+		return fmt.Errorf("repetitions need to be from [1, %d]", MaxRepetitions)
+	}
+	return nil
+}
+
+// ChallengeBits returns ChallengeBitLength, Level or def - the first of them which is set.
+func (params Params) ChallengeBits(def int) int {
+	return firstSet(params.ChallengeBitLength, params.Level, def)
+}
+
+// StatisticalBits returns StatisticalBitLength, Level or def - the first of them which
+// is set.
+func (params Params) StatisticalBits(def int) int {
+	return firstSet(params.StatisticalBitLength, params.Level, def)
+}
+
+// Runs returns the number of runs of a protocol whose single run has soundness error
+// roundError (for example 2/3 for Stern's protocol): Repetitions if it is set, otherwise
+// the number of runs needed for Level (see Repetitions) or def.
+func (params Params) Runs(roundError float64, def int) int {
+	if params.Repetitions == 0 && params.Level != 0 {
+		return Repetitions(params.Level, roundError)
+	}
+	return firstSet(params.Repetitions, def)
+}
+
+// Repetitions returns the number of runs of a protocol with soundness error roundError
+// of a single run, so that the soundness error is at most 2^(-level).
+func Repetitions(level int, roundError float64) int {
+	return int(math.Ceil(float64(level) / -math.Log2(roundError)))
+}
+
+// CheckRepetitions checks that the number of runs of a protocol with soundness error
+// roundError of a single run gives at least MinBitLength bits of security.
+func CheckRepetitions(repetitions int, roundError float64) error {
+	if min := Repetitions(MinBitLength, roundError); repetitions < min ||
+		repetitions > MaxRepetitions {
+		return fmt.Errorf("repetitions need to be from [%d, %d]", min, MaxRepetitions)
+	}
+	return nil
+}
+
+func firstSet(values ...int) int {
+	for _, v := range values {
+		if v != 0 {
+			return v
+		}
+	}
+	return 0
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package session drives interactive proofs as state machines. Each party of a protocol is
+// a ProofSession which consumes the message of the other party and returns its reply, thus
+// the code which transports the messages (server handlers, tests, simulators) does not need
+// to know which protocol it runs:
+//
+//	reply, done, err := prover.Next(nil) // the prover starts
+//	for !done && err == nil {
+//		reply, done, err = verifier.Next(reply)
+//		...
+//	}
+//
+// Messages are vectors of integers, as in packages sigma and fiatshamir. Run executes both
+// parties and records the Transcript, which can be checked again later.
+package session
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ProofSession is one party of an interactive proof.
+type ProofSession interface {
+	// Next consumes the message of the other party (nil for the first message of the party
+	// which starts the protocol) and returns the reply. When done is true, the session is
+	// finished - reply is then the last message which needs to be delivered (if not nil).
+	Next(msg []*big.Int) (reply []*big.Int, done bool, err error)
+}
+
+var (
+	// ErrRejected is returned by the prover's session when the verifier rejects the proof.
+	ErrRejected = errors.New("session: proof was rejected")
+	// ErrFinished is returned by Next when it is called on a finished session.
+	ErrFinished = errors.New("session: session is already finished")
+	// ErrUnexpectedMsg is returned by Next when the message is not well-formed.
+	ErrUnexpectedMsg = errors.New("session: unexpected message")
+)
+
+// Transcript holds the messages of a session in the order they were sent, starting with
+// the first message of the prover.
+type Transcript struct {
+	Messages [][]*big.Int
+}
+
+// maxRounds limits the number of messages in Run, so that misbehaving sessions which never
+// finish do not loop forever.
+const maxRounds = 1024
+
+// Run drives the prover and the verifier until both are finished. It returns whether
+// the verifier accepted the proof, together with the transcript.
+func Run(prover, verifier ProofSession) (bool, *Transcript, error) {
+	transcript := &Transcript{}
+	parties := []ProofSession{prover, verifier}
+	finished := []bool{false, false}
+	var msg []*big.Int
+	for i := 0; i < maxRounds; i++ {
+		party := i % 2
+		if finished[party] {
+			break
+		}
+		reply, done, err := parties[party].Next(msg)
+		if err == ErrRejected {
+			return false, transcript, nil
+		}
+		if err != nil {
+			return false, transcript, err
+		}
+		finished[party] = done
+		if reply == nil {
+			if done {
+				return finished[1], transcript, nil
+			}
+			return false, transcript, ErrUnexpectedMsg
+		}
+		transcript.Messages = append(transcript.Messages, reply)
+		msg = reply
+	}
+	return false, transcript, errors.New("session: too many messages")
+}
@@ -0,0 +1,197 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package session
+
+import (
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/fiatshamir"
+	"math/big"
+)
+
+// Sessions of sigma protocols exchange four messages: the prover's proof random data,
+// the verifier's challenge (a vector with one integer), the prover's proof data and
+// the verifier's status (1 if the proof is accepted, 0 otherwise).
+//
+// SigmaProver and SigmaVerifier are satisfied by the adapters from package fiatshamir and by
+// the protocols from package sigma, thus any of them can be run as a session.
+
+type SigmaProver interface {
+	ChallengeSpace() *big.Int
+	GetProofRandomData() ([]*big.Int, error)
+	GetProofData(challenge *big.Int) ([]*big.Int, error)
+}
+
+type SigmaVerifier interface {
+	ChallengeSpace() *big.Int
+	Verify(proofRandomData []*big.Int, challenge *big.Int, proofData []*big.Int) bool
+}
+
+const (
+	stateProofRandomData = iota
+	stateProofData
+	stateStatus
+	stateFinished
+)
+
+type sigmaProverSession struct {
+	prover SigmaProver
+	state  int
+}
+
+// NewSigmaProverSession returns the prover's session of the sigma protocol.
+func NewSigmaProverSession(prover SigmaProver) ProofSession {
+	return &sigmaProverSession{
+		prover: prover,
+	}
+}
+
+func (s *sigmaProverSession) Next(msg []*big.Int) ([]*big.Int, bool, error) {
+	switch s.state {
+	case stateProofRandomData:
+		if msg != nil {
+			return nil, false, ErrUnexpectedMsg
+		}
+		s.state = stateProofData
+		proofRandomData, err := s.prover.GetProofRandomData()
+		if err != nil {
+			return nil, true, err
+		}
+		return proofRandomData, false, nil
+	case stateProofData:
+		if len(msg) != 1 || !isInRange(msg[0], s.prover.ChallengeSpace()) {
+			return nil, false, ErrUnexpectedMsg
+		}
+		s.state = stateStatus
+		proofData, err := s.prover.GetProofData(msg[0])
+		if err != nil {
+			return nil, true, err
+		}
+		return proofData, false, nil
+	case stateStatus:
+		if len(msg) != 1 || msg[0] == nil {
+			return nil, false, ErrUnexpectedMsg
+		}
+		s.state = stateFinished
+		if msg[0].Cmp(big.NewInt(1)) != 0 {
+			return nil, true, ErrRejected
+		}
+		return nil, true, nil
+	}
+	return nil, true, ErrFinished
+}
+
+type sigmaVerifierSession struct {
+	verifier        SigmaVerifier
+	state           int
+	proofRandomData []*big.Int
+	challenge       *big.Int
+}
+
+// NewSigmaVerifierSession returns the verifier's session of the sigma protocol. The result
+// is sent to the prover in the last message.
+func NewSigmaVerifierSession(verifier SigmaVerifier) ProofSession {
+	return &sigmaVerifierSession{
+		verifier: verifier,
+	}
+}
+
+func (s *sigmaVerifierSession) Next(msg []*big.Int) ([]*big.Int, bool, error) {
+	switch s.state {
+	case stateProofRandomData:
+		if msg == nil {
+			return nil, false, ErrUnexpectedMsg
+		}
+		challenge, err := common.GetRandomInt(s.verifier.ChallengeSpace())
+		if err != nil {
+			return nil, false, err
+		}
+		s.proofRandomData, s.challenge = msg, challenge
+		s.state = stateProofData
+		return []*big.Int{challenge}, false, nil
+	case stateProofData:
+		if msg == nil {
+			return nil, false, ErrUnexpectedMsg
+		}
+		s.state = stateFinished
+		status := big.NewInt(0)
+		if s.verifier.Verify(s.proofRandomData, s.challenge, msg) {
+			status = big.NewInt(1)
+		}
+		return []*big.Int{status}, true, nil
+	}
+	return nil, true, ErrFinished
+}
+
+// VerifySigmaTranscript checks the transcript of a sigma protocol session again (for example
+// when it is audited later) - the verifier needs to be for the same statement.
+func VerifySigmaTranscript(verifier SigmaVerifier, transcript *Transcript) bool {
+	if transcript == nil || len(transcript.Messages) < 3 {
+		return false
+	}
+	m := transcript.Messages
+	if len(m[1]) != 1 || !isInRange(m[1][0], verifier.ChallengeSpace()) {
+		return false
+	}
+	return verifier.Verify(m[0], m[1][0], m[2])
+}
+
+// NewSchnorrProverSession returns the prover's session of the proof of knowledge
+// of log_a(b).
+func NewSchnorrProverSession(group *groups.SchnorrGroup, secret, a, b *big.Int) ProofSession {
+	return NewSigmaProverSession(fiatshamir.NewSchnorrProver(group, secret, a, b))
+}
+
+// NewSchnorrVerifierSession returns the verifier's session of the proof of knowledge
+// of log_a(b).
+func NewSchnorrVerifierSession(group *groups.SchnorrGroup, a, b *big.Int) ProofSession {
+	return NewSigmaVerifierSession(fiatshamir.NewSchnorrVerifier(group, a, b))
+}
+
+// NewDLogEqualityProverSession returns the prover's session of the proof of
+// log_g1(t1) = log_g2(t2).
+func NewDLogEqualityProverSession(group *groups.SchnorrGroup, secret, g1, g2, t1,
+	t2 *big.Int) ProofSession {
+	return NewSigmaProverSession(fiatshamir.NewDLogEqualityProver(group, secret, g1, g2, t1, t2))
+}
+
+// NewDLogEqualityVerifierSession returns the verifier's session of the proof of
+// log_g1(t1) = log_g2(t2).
+func NewDLogEqualityVerifierSession(group *groups.SchnorrGroup, g1, g2, t1,
+	t2 *big.Int) ProofSession {
+	return NewSigmaVerifierSession(fiatshamir.NewDLogEqualityVerifier(group, g1, g2, t1, t2))
+}
+
+// NewPartialDLogProverSession returns the prover's session of the proof of knowledge
+// of log_a1(b1) or log_a2(b2) (the prover knows log_a1(b1)).
+func NewPartialDLogProverSession(group *groups.SchnorrGroup, secret1, a1, b1, a2,
+	b2 *big.Int) ProofSession {
+	return NewSigmaProverSession(fiatshamir.NewPartialDLogProver(group, secret1, a1, b1, a2, b2))
+}
+
+// NewPartialDLogVerifierSession returns the verifier's session of the proof of knowledge
+// of log_a1(b1) or log_a2(b2).
+func NewPartialDLogVerifierSession(group *groups.SchnorrGroup, a1, b1, a2,
+	b2 *big.Int) ProofSession {
+	return NewSigmaVerifierSession(fiatshamir.NewPartialDLogVerifier(group, a1, b1, a2, b2))
+}
+
+// isInRange checks that x is from [0, bound).
+func isInRange(x, bound *big.Int) bool {
+	return x != nil && x.Sign() >= 0 && x.Cmp(bound) < 0
+}
@@ -0,0 +1,285 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package sigma
+
+import (
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/fiatshamir"
+	"math/big"
+	"strings"
+)
+
+type and struct {
+	protocols      []Protocol
+	challengeSpace *big.Int
+}
+
+// And returns the protocol for proving all the statements - the same challenge
+// is used for all of them.
+func And(protocols ...Protocol) Protocol {
+	return &and{
+		protocols:      protocols,
+		challengeSpace: commonChallengeSpace(protocols),
+	}
+}
+
+func (p *and) Name() string {
+	return "AND(" + names(p.protocols) + ")"
+}
+
+func (p *and) Statement() []*big.Int {
+	return statements(p.protocols)
+}
+
+func (p *and) ChallengeSpace() *big.Int {
+	return p.challengeSpace
+}
+
+func (p *and) Groups() []fiatshamir.Group {
+	return groupsOf(p.protocols)
+}
+
+func (p *and) HasWitness() bool {
+	for _, protocol := range p.protocols {
+		if !protocol.HasWitness() {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *and) GetProofRandomData() ([]*big.Int, error) {
+	data := make([][]*big.Int, len(p.protocols))
+	for i, protocol := range p.protocols {
+		var err error
+		if data[i], err = protocol.GetProofRandomData(); err != nil {
+			return nil, err
+		}
+	}
+	return pack(data...), nil
+}
+
+func (p *and) GetProofData(challenge *big.Int) ([]*big.Int, error) {
+	data := make([][]*big.Int, len(p.protocols))
+	for i, protocol := range p.protocols {
+		var err error
+		if data[i], err = protocol.GetProofData(challenge); err != nil {
+			return nil, err
+		}
+	}
+	return pack(data...), nil
+}
+
+func (p *and) Verify(proofRandomData []*big.Int, challenge *big.Int, proofData []*big.Int) bool {
+	randomData, ok1 := unpack(proofRandomData, len(p.protocols))
+	data, ok2 := unpack(proofData, len(p.protocols))
+	if !ok1 || !ok2 || !validInts([]*big.Int{challenge}, p.challengeSpace) {
+		return false
+	}
+	for i, protocol := range p.protocols {
+		if !protocol.Verify(randomData[i], challenge, data[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *and) Simulate(challenge *big.Int) ([]*big.Int, []*big.Int, error) {
+	randomData := make([][]*big.Int, len(p.protocols))
+	data := make([][]*big.Int, len(p.protocols))
+	for i, protocol := range p.protocols {
+		var err error
+		if randomData[i], data[i], err = protocol.Simulate(challenge); err != nil {
+			return nil, nil, err
+		}
+	}
+	return pack(randomData...), pack(data...), nil
+}
+
+type or struct {
+	protocols      []Protocol
+	challengeSpace *big.Int
+	known          int // index of the protocol with the known witness
+	challenges     []*big.Int
+	simulated      [][]*big.Int // proof data of the simulated protocols
+}
+
+// Or returns the protocol for proving at least one of the statements. The challenges
+// of the statements are k-bit strings which XOR into the verifier's challenge (see
+// commonChallengeSpace). The prover needs to know the witness for one of the statements,
+// the others are simulated.
+func Or(protocols ...Protocol) Protocol {
+	p := &or{
+		protocols:      protocols,
+		challengeSpace: commonChallengeSpace(protocols),
+		known:          -1,
+	}
+	for i, protocol := range protocols {
+		if protocol.HasWitness() {
+			p.known = i
+			break
+		}
+	}
+	return p
+}
+
+func (p *or) Name() string {
+	return "OR(" + names(p.protocols) + ")"
+}
+
+func (p *or) Statement() []*big.Int {
+	return statements(p.protocols)
+}
+
+func (p *or) ChallengeSpace() *big.Int {
+	return p.challengeSpace
+}
+
+func (p *or) Groups() []fiatshamir.Group {
+	return groupsOf(p.protocols)
+}
+
+func (p *or) HasWitness() bool {
+	return p.known >= 0
+}
+
+func (p *or) GetProofRandomData() ([]*big.Int, error) {
+	n := len(p.protocols)
+	randomData := make([][]*big.Int, n)
+	p.challenges = make([]*big.Int, n)
+	p.simulated = make([][]*big.Int, n)
+	for i, protocol := range p.protocols {
+		var err error
+		if i == p.known {
+			randomData[i], err = protocol.GetProofRandomData()
+		} else if p.challenges[i], err = common.GetRandomInt(p.challengeSpace); err == nil {
+			randomData[i], p.simulated[i], err = protocol.Simulate(p.challenges[i])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pack(randomData...), nil
+}
+
+// GetProofData returns the challenges of all the statements followed by their proof data.
+func (p *or) GetProofData(challenge *big.Int) ([]*big.Int, error) {
+	if p.challenges == nil {
+		return nil, errNoProofRandomData
+	}
+	c := new(big.Int).Set(challenge)
+	for i, ci := range p.challenges {
+		if i != p.known {
+			c.Xor(c, ci)
+		}
+	}
+	p.challenges[p.known] = c
+
+	data := make([][]*big.Int, len(p.protocols))
+	for i, protocol := range p.protocols {
+		if i == p.known {
+			var err error
+			if data[i], err = protocol.GetProofData(c); err != nil {
+				return nil, err
+			}
+		} else {
+			data[i] = p.simulated[i]
+		}
+	}
+	return append(pack(p.challenges), pack(data...)...), nil
+}
+
+func (p *or) Verify(proofRandomData []*big.Int, challenge *big.Int, proofData []*big.Int) bool {
+	n := len(p.protocols)
+	if len(proofData) < n+1 {
+		return false
+	}
+	challenges, ok := unpack(proofData[:n+1], 1)
+	randomData, ok1 := unpack(proofRandomData, n)
+	data, ok2 := unpack(proofData[n+1:], n)
+	if !ok || !ok1 || !ok2 || len(challenges[0]) != n ||
+		!validInts(challenges[0], p.challengeSpace) ||
+		!validInts([]*big.Int{challenge}, p.challengeSpace) {
+		return false
+	}
+
+	c := new(big.Int)
+	for _, ci := range challenges[0] {
+		c.Xor(c, ci)
+	}
+	if c.Cmp(challenge) != 0 {
+		return false
+	}
+	for i, protocol := range p.protocols {
+		if !protocol.Verify(randomData[i], challenges[0][i], data[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *or) Simulate(challenge *big.Int) ([]*big.Int, []*big.Int, error) {
+	n := len(p.protocols)
+	challenges := make([]*big.Int, n)
+	randomData := make([][]*big.Int, n)
+	data := make([][]*big.Int, n)
+	c := new(big.Int).Set(challenge)
+	for i := 0; i < n-1; i++ {
+		var err error
+		if challenges[i], err = common.GetRandomInt(p.challengeSpace); err != nil {
+			return nil, nil, err
+		}
+		c.Xor(c, challenges[i])
+	}
+	challenges[n-1] = c
+	for i, protocol := range p.protocols {
+		var err error
+		if randomData[i], data[i], err = protocol.Simulate(challenges[i]); err != nil {
+			return nil, nil, err
+		}
+	}
+	return pack(randomData...), append(pack(challenges), pack(data...)...), nil
+}
+
+func names(protocols []Protocol) string {
+	n := make([]string, len(protocols))
+	for i, p := range protocols {
+		n[i] = p.Name()
+	}
+	return strings.Join(n, ",")
+}
+
+// groupsOf returns the groups of the protocols (in the order of the protocols) which
+// describe them.
+func groupsOf(protocols []Protocol) []fiatshamir.Group {
+	var g []fiatshamir.Group
+	for _, p := range protocols {
+		if describer, ok := p.(fiatshamir.Describer); ok {
+			g = append(g, describer.Groups()...)
+		}
+	}
+	return g
+}
+
+func statements(protocols []Protocol) []*big.Int {
+	s := make([][]*big.Int, len(protocols))
+	for i, p := range protocols {
+		s[i] = p.Statement()
+	}
+	return pack(s...)
+}
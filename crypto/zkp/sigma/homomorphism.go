@@ -0,0 +1,248 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package sigma
+
+import (
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+)
+
+// Homomorphism is a group homomorphism phi: Z_q^n -> H. Any implementation is turned into
+// the protocol for proving the knowledge of a preimage (see NewPreimage) - this is
+// U. Maurer: Unifying zero-knowledge proofs of knowledge. Schnorr, Okamoto, Chaum-Pedersen,
+// proofs of the knowledge of ElGamal plaintexts and many others are instances of it,
+// so a new protocol only needs its homomorphism to be written.
+//
+// Elements of H are vectors of integers. The order q needs to be prime (or at least
+// all its prime factors need to be larger than the challenges) for the protocol to be sound.
+type Homomorphism interface {
+	// Name identifies the homomorphism.
+	Name() string
+	// Params returns the public values which define the homomorphism.
+	Params() []*big.Int
+	// Order returns q, the preimages are vectors of integers modulo q.
+	Order() *big.Int
+	// Dim returns n, the length of the preimages.
+	Dim() int
+	// Eval returns phi(x).
+	Eval(x []*big.Int) []*big.Int
+	// Mul returns a * b in H.
+	Mul(a, b []*big.Int) []*big.Int
+	// Exp returns a^e in H.
+	Exp(a []*big.Int, e *big.Int) []*big.Int
+	// Inv returns the inverse of a in H.
+	Inv(a []*big.Int) []*big.Int
+	// IsElement checks that a is an element of H (it is called on the values
+	// received from the prover).
+	IsElement(a []*big.Int) bool
+}
+
+type preimage struct {
+	phi    Homomorphism
+	y      []*big.Int
+	secret []*big.Int // nil if the witness is not known
+	r      []*big.Int
+}
+
+// NewPreimage returns the protocol for proving the knowledge of secret such that
+// phi(secret) = y. Secret is nil if it is not known.
+func NewPreimage(phi Homomorphism, y []*big.Int, secret []*big.Int) Protocol {
+	return &preimage{
+		phi:    phi,
+		y:      y,
+		secret: secret,
+	}
+}
+
+func (p *preimage) Name() string {
+	return "Preimage(" + p.phi.Name() + ")"
+}
+
+func (p *preimage) Statement() []*big.Int {
+	return pack(p.phi.Params(), p.y)
+}
+
+func (p *preimage) ChallengeSpace() *big.Int {
+	return p.phi.Order()
+}
+
+func (p *preimage) HasWitness() bool {
+	return p.secret != nil
+}
+
+// GetProofRandomData returns t = phi(r) for random r.
+func (p *preimage) GetProofRandomData() ([]*big.Int, error) {
+	r, err := p.randomPreimage()
+	if err != nil {
+		return nil, err
+	}
+	p.r = r
+	return p.phi.Eval(p.r), nil
+}
+
+// GetProofData returns z = r + challenge * secret mod q.
+func (p *preimage) GetProofData(challenge *big.Int) ([]*big.Int, error) {
+	if p.r == nil {
+		return nil, errNoProofRandomData
+	}
+	q := p.phi.Order()
+	z := make([]*big.Int, len(p.r))
+	for i := range p.r {
+		z[i] = new(big.Int).Mul(challenge, p.secret[i])
+		z[i].Add(z[i], p.r[i])
+		z[i].Mod(z[i], q)
+	}
+	return z, nil
+}
+
+// Verify checks that phi(z) = t * y^challenge.
+func (p *preimage) Verify(proofRandomData []*big.Int, challenge *big.Int,
+	proofData []*big.Int) bool {
+	if len(proofData) != p.phi.Dim() || !validInts(proofData, p.phi.Order()) ||
+		!p.phi.IsElement(proofRandomData) {
+		return false
+	}
+	right := p.phi.Mul(proofRandomData, p.phi.Exp(p.y, challenge))
+	return equalInts(p.phi.Eval(proofData), right)
+}
+
+// Simulate chooses z at random and computes t = phi(z) * y^(-challenge).
+func (p *preimage) Simulate(challenge *big.Int) ([]*big.Int, []*big.Int, error) {
+	z, err := p.randomPreimage()
+	if err != nil {
+		return nil, nil, err
+	}
+	t := p.phi.Mul(p.phi.Eval(z), p.phi.Inv(p.phi.Exp(p.y, challenge)))
+	return t, z, nil
+}
+
+func (p *preimage) randomPreimage() ([]*big.Int, error) {
+	return common.GetRandomInts(p.phi.Dim(), p.phi.Order())
+}
+
+// schnorrHomomorphism is phi(x)_i = prod_j bases[i][j]^x_j in the Schnorr group.
+type schnorrHomomorphism struct {
+	group *groups.SchnorrGroup
+	bases [][]*big.Int
+}
+
+// NewSchnorrHomomorphism returns the homomorphism from Z_q^n to the m-th power of
+// the Schnorr group given by the m x n matrix of bases: phi(x)_i = prod_j bases[i][j]^x_j.
+// Nil bases stand for 1. For example, with bases [[g, h]] the preimage proof is the proof
+// of the knowledge of Pedersen opening, with [[g], [h]] it is the dlog equality and
+// with [[g, nil], [pk, g]] it is the knowledge of the plaintext of ElGamal ciphertext
+// (g^r, pk^r * g^m).
+func NewSchnorrHomomorphism(group *groups.SchnorrGroup, bases [][]*big.Int) Homomorphism {
+	return &schnorrHomomorphism{
+		group: group,
+		bases: bases,
+	}
+}
+
+func (phi *schnorrHomomorphism) Name() string {
+	return "Schnorr"
+}
+
+// Params returns P followed by the bases (nil bases are encoded as 1).
+func (phi *schnorrHomomorphism) Params() []*big.Int {
+	params := []*big.Int{phi.group.P}
+	for _, row := range phi.bases {
+		params = append(params, big.NewInt(int64(len(row))))
+		for _, base := range row {
+			if base == nil {
+				base = big.NewInt(1)
+			}
+			params = append(params, base)
+		}
+	}
+	return params
+}
+
+func (phi *schnorrHomomorphism) Order() *big.Int {
+	return phi.group.Q
+}
+
+func (phi *schnorrHomomorphism) Dim() int {
+	if len(phi.bases) == 0 {
+		return 0
+	}
+	return len(phi.bases[0])
+}
+
+func (phi *schnorrHomomorphism) Eval(x []*big.Int) []*big.Int {
+	y := make([]*big.Int, len(phi.bases))
+	for i, row := range phi.bases {
+		y[i] = big.NewInt(1)
+		for j, base := range row {
+			if base != nil {
+				y[i] = phi.group.Mul(y[i], phi.group.Exp(base, x[j]))
+			}
+		}
+	}
+	return y
+}
+
+func (phi *schnorrHomomorphism) Mul(a, b []*big.Int) []*big.Int {
+	c := make([]*big.Int, len(a))
+	for i := range a {
+		c[i] = phi.group.Mul(a[i], b[i])
+	}
+	return c
+}
+
+func (phi *schnorrHomomorphism) Exp(a []*big.Int, e *big.Int) []*big.Int {
+	c := make([]*big.Int, len(a))
+	for i := range a {
+		c[i] = phi.group.Exp(a[i], e)
+	}
+	return c
+}
+
+func (phi *schnorrHomomorphism) Inv(a []*big.Int) []*big.Int {
+	c := make([]*big.Int, len(a))
+	for i := range a {
+		c[i] = phi.group.Inv(a[i])
+	}
+	return c
+}
+
+func (phi *schnorrHomomorphism) IsElement(a []*big.Int) bool {
+	if len(a) != len(phi.bases) {
+		return false
+	}
+	for _, el := range a {
+		if !phi.group.IsElementInGroup(el) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalInts checks that a and b have the same length and values.
+func equalInts(a, b []*big.Int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] == nil || b[i] == nil || a[i].Cmp(b[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,366 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package sigma
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/fiatshamir"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+)
+
+type representation struct {
+	name    string
+	group   groups.PrimeOrderGroup
+	bases   []groups.Element
+	y       groups.Element
+	secrets []groups.Scalar // nil if the witness is not known
+	r       []groups.Scalar
+}
+
+// NewGroupRepresentation returns the protocol for proving the knowledge of secrets such that
+// y = bases[0]^secrets[0] * ... * bases[n-1]^secrets[n-1] in any prime order group (see
+// groups.PrimeOrderGroup). Secrets are nil if the protocol is used only by the verifier or
+// as a simulated branch of Or.
+func NewGroupRepresentation(group groups.PrimeOrderGroup, bases []groups.Element,
+	y groups.Element, secrets []groups.Scalar) Protocol {
+	return &representation{
+		name:    "Representation",
+		group:   group,
+		bases:   bases,
+		y:       y,
+		secrets: secrets,
+	}
+}
+
+// NewRepresentation returns the protocol for proving the knowledge of secrets such that
+// y = bases[0]^secrets[0] * ... * bases[n-1]^secrets[n-1]. With one base this is
+// the knowledge of dlog (Schnorr), with bases g, h the knowledge of the opening of
+// Pedersen commitment. Secrets are nil if the protocol is used only by the verifier or
+// as a simulated branch of Or. If y or any of the bases is not from the group, the protocol
+// cannot be proved and nothing is verified.
+func NewRepresentation(group *groups.SchnorrGroup, bases []*big.Int, y *big.Int,
+	secrets []*big.Int) Protocol {
+	backend := groups.NewSchnorrBackend(group)
+	elements, ok := toElements(backend, append([]*big.Int{y}, bases...))
+	if !ok {
+		return newInvalid("Representation", backend)
+	}
+	return NewGroupRepresentation(backend, elements[1:], elements[0],
+		toScalars(backend, secrets))
+}
+
+// NewDLog returns the protocol for proving the knowledge of secret such that y = g^secret.
+func NewDLog(group *groups.SchnorrGroup, g, y, secret *big.Int) Protocol {
+	var secrets []*big.Int
+	if secret != nil {
+		secrets = []*big.Int{secret}
+	}
+	return NewRepresentation(group, []*big.Int{g}, y, secrets)
+}
+
+// NewECDLog returns the protocol for proving the knowledge of secret such that
+// b = a^secret in the elliptic curve group. Secret is nil if it is not known.
+func NewECDLog(curve dlog.Curve, a, b *types.ECGroupElement, secret *big.Int) Protocol {
+	backend := dlog.NewECBackend(curve)
+	elements, ok := toElements(backend, []*big.Int{b.X, b.Y, a.X, a.Y})
+	if !ok {
+		return newInvalid("ECDLog", backend)
+	}
+	var secrets []groups.Scalar
+	if secret != nil {
+		secrets = []groups.Scalar{backend.Scalar(secret)}
+	}
+	p := NewGroupRepresentation(backend, elements[1:], elements[0], secrets).(*representation)
+	p.name = "ECDLog"
+	return p
+}
+
+func (p *representation) Name() string {
+	return p.name
+}
+
+func (p *representation) Statement() []*big.Int {
+	return append(append([]*big.Int{}, p.group.Params()...), ints(append(
+		[]groups.Element{p.y}, p.bases...))...)
+}
+
+func (p *representation) ChallengeSpace() *big.Int {
+	return p.group.Order()
+}
+
+func (p *representation) Groups() []fiatshamir.Group {
+	return []fiatshamir.Group{fiatshamir.PrimeOrderGroup(p.group)}
+}
+
+func (p *representation) HasWitness() bool {
+	return p.secrets != nil
+}
+
+// GetProofRandomData returns prod(bases[i]^r_i) for random r_i.
+func (p *representation) GetProofRandomData() ([]*big.Int, error) {
+	r, err := randomScalars(p.group, len(p.bases))
+	if err != nil {
+		return nil, err
+	}
+	p.r = r
+	return p.multiExp(p.r).Ints(), nil
+}
+
+// GetProofData returns z_i = r_i + challenge * secrets[i] mod q.
+func (p *representation) GetProofData(challenge *big.Int) ([]*big.Int, error) {
+	if p.r == nil {
+		return nil, errNoProofRandomData
+	}
+	c := p.group.Scalar(challenge)
+	z := make([]groups.Scalar, len(p.bases))
+	for i := range p.bases {
+		z[i] = p.r[i].Add(c.Mul(p.secrets[i]))
+	}
+	return bigInts(z), nil
+}
+
+// Verify checks that prod(bases[i]^z_i) = t * y^challenge.
+func (p *representation) Verify(proofRandomData []*big.Int, challenge *big.Int,
+	proofData []*big.Int) bool {
+	if len(proofData) != len(p.bases) || !validInts(proofData, p.group.Order()) {
+		return false
+	}
+	t, err := p.group.Element(proofRandomData)
+	if err != nil {
+		return false
+	}
+	right := t.Mul(p.y.Exp(p.group.Scalar(challenge)))
+	return p.multiExp(toScalars(p.group, proofData)).Equals(right)
+}
+
+// Simulate chooses z_i at random and computes t = prod(bases[i]^z_i) * y^(-challenge).
+func (p *representation) Simulate(challenge *big.Int) ([]*big.Int, []*big.Int, error) {
+	z, err := randomScalars(p.group, len(p.bases))
+	if err != nil {
+		return nil, nil, err
+	}
+	t := p.multiExp(z).Mul(p.y.Exp(p.group.Scalar(challenge).Neg()))
+	return t.Ints(), bigInts(z), nil
+}
+
+func (p *representation) multiExp(exps []groups.Scalar) groups.Element {
+	result := p.group.Identity()
+	for i, base := range p.bases {
+		result = result.Mul(base.Exp(exps[i]))
+	}
+	return result
+}
+
+type dlogEquality struct {
+	group  groups.PrimeOrderGroup
+	g1, t1 groups.Element
+	g2, t2 groups.Element
+	secret groups.Scalar // nil if the witness is not known
+	r      groups.Scalar
+}
+
+// NewGroupDLogEquality returns the protocol for proving that log_g1(t1) = log_g2(t2)
+// (Chaum-Pedersen) in any prime order group. Secret is nil if it is not known.
+func NewGroupDLogEquality(group groups.PrimeOrderGroup, g1, t1, g2, t2 groups.Element,
+	secret groups.Scalar) Protocol {
+	return &dlogEquality{
+		group:  group,
+		g1:     g1,
+		t1:     t1,
+		g2:     g2,
+		t2:     t2,
+		secret: secret,
+	}
+}
+
+// NewDLogEquality returns the protocol for proving that log_g1(t1) = log_g2(t2)
+// (Chaum-Pedersen). Secret is nil if it is not known.
+func NewDLogEquality(group *groups.SchnorrGroup, g1, t1, g2, t2, secret *big.Int) Protocol {
+	backend := groups.NewSchnorrBackend(group)
+	e, ok := toElements(backend, []*big.Int{g1, t1, g2, t2})
+	if !ok {
+		return newInvalid("DLogEquality", backend)
+	}
+	var s groups.Scalar
+	if secret != nil {
+		s = backend.Scalar(secret)
+	}
+	return NewGroupDLogEquality(backend, e[0], e[1], e[2], e[3], s)
+}
+
+func (p *dlogEquality) Name() string {
+	return "DLogEquality"
+}
+
+func (p *dlogEquality) Statement() []*big.Int {
+	return append(append([]*big.Int{}, p.group.Params()...),
+		ints([]groups.Element{p.g1, p.t1, p.g2, p.t2})...)
+}
+
+func (p *dlogEquality) ChallengeSpace() *big.Int {
+	return p.group.Order()
+}
+
+func (p *dlogEquality) Groups() []fiatshamir.Group {
+	return []fiatshamir.Group{fiatshamir.PrimeOrderGroup(p.group)}
+}
+
+func (p *dlogEquality) HasWitness() bool {
+	return p.secret != nil
+}
+
+func (p *dlogEquality) GetProofRandomData() ([]*big.Int, error) {
+	r, err := randomScalars(p.group, 1)
+	if err != nil {
+		return nil, err
+	}
+	p.r = r[0]
+	return ints([]groups.Element{p.g1.Exp(p.r), p.g2.Exp(p.r)}), nil
+}
+
+func (p *dlogEquality) GetProofData(challenge *big.Int) ([]*big.Int, error) {
+	if p.r == nil {
+		return nil, errNoProofRandomData
+	}
+	z := p.r.Add(p.group.Scalar(challenge).Mul(p.secret))
+	return []*big.Int{z.BigInt()}, nil
+}
+
+// Verify checks that g1^z = x1 * t1^challenge and g2^z = x2 * t2^challenge.
+func (p *dlogEquality) Verify(proofRandomData []*big.Int, challenge *big.Int,
+	proofData []*big.Int) bool {
+	if len(proofData) != 1 || !validInts(proofData, p.group.Order()) ||
+		len(proofRandomData)%2 != 0 {
+		return false
+	}
+	l := len(proofRandomData) / 2
+	x1, err1 := p.group.Element(proofRandomData[:l])
+	x2, err2 := p.group.Element(proofRandomData[l:])
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	c := p.group.Scalar(challenge)
+	z := p.group.Scalar(proofData[0])
+	return p.g1.Exp(z).Equals(x1.Mul(p.t1.Exp(c))) && p.g2.Exp(z).Equals(x2.Mul(p.t2.Exp(c)))
+}
+
+func (p *dlogEquality) Simulate(challenge *big.Int) ([]*big.Int, []*big.Int, error) {
+	r, err := randomScalars(p.group, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	z := r[0]
+	c := p.group.Scalar(challenge).Neg()
+	x1 := p.g1.Exp(z).Mul(p.t1.Exp(c))
+	x2 := p.g2.Exp(z).Mul(p.t2.Exp(c))
+	return ints([]groups.Element{x1, x2}), []*big.Int{z.BigInt()}, nil
+}
+
+// invalid is the protocol for a statement with values which are not from the group - it
+// cannot be proved and no proof is accepted.
+type invalid struct {
+	name  string
+	group groups.PrimeOrderGroup
+}
+
+func newInvalid(name string, group groups.PrimeOrderGroup) Protocol {
+	return &invalid{
+		name:  name,
+		group: group,
+	}
+}
+
+func (p *invalid) Name() string             { return p.name }
+func (p *invalid) Statement() []*big.Int    { return p.group.Params() }
+func (p *invalid) ChallengeSpace() *big.Int { return p.group.Order() }
+func (p *invalid) HasWitness() bool         { return false }
+func (p *invalid) GetProofRandomData() ([]*big.Int, error) {
+	return nil, fmt.Errorf("%v cannot be proved", p.name)
+}
+
+func (p *invalid) GetProofData(challenge *big.Int) ([]*big.Int, error) {
+	return nil, fmt.Errorf("%v cannot be proved", p.name)
+}
+
+func (p *invalid) Verify(proofRandomData []*big.Int, challenge *big.Int,
+	proofData []*big.Int) bool {
+	return false
+}
+
+func (p *invalid) Simulate(challenge *big.Int) ([]*big.Int, []*big.Int, error) {
+	return nil, nil, nil
+}
+
+// toElements decodes the values into elements of the group, each element taking
+// the same number of integers. It returns false if any of them is not from the group.
+func toElements(group groups.PrimeOrderGroup, values []*big.Int) ([]groups.Element, bool) {
+	l := len(group.Identity().Ints())
+	if len(values)%l != 0 {
+		return nil, false
+	}
+	elements := make([]groups.Element, len(values)/l)
+	for i := range elements {
+		e, err := group.Element(values[i*l : (i+1)*l])
+		if err != nil {
+			return nil, false
+		}
+		elements[i] = e
+	}
+	return elements, true
+}
+
+// toScalars returns the values as scalars (nil for nil values).
+func toScalars(group groups.PrimeOrderGroup, values []*big.Int) []groups.Scalar {
+	if values == nil {
+		return nil
+	}
+	scalars := make([]groups.Scalar, len(values))
+	for i, v := range values {
+		scalars[i] = group.Scalar(v)
+	}
+	return scalars
+}
+
+func randomScalars(group groups.PrimeOrderGroup, n int) ([]groups.Scalar, error) {
+	values, err := common.GetRandomInts(n, group.Order())
+	if err != nil {
+		return nil, err
+	}
+	return toScalars(group, values), nil
+}
+
+func bigInts(scalars []groups.Scalar) []*big.Int {
+	values := make([]*big.Int, len(scalars))
+	for i, s := range scalars {
+		values[i] = s.BigInt()
+	}
+	return values
+}
+
+// ints concatenates the encodings of the elements.
+func ints(elements []groups.Element) []*big.Int {
+	var values []*big.Int
+	for _, e := range elements {
+		values = append(values, e.Ints()...)
+	}
+	return values
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package sigma
+
+import (
+	"github.com/xlab-si/emmy/crypto/groups"
+	"math/big"
+)
+
+// NewSetMembership returns the protocol for proving that the value x committed in Pedersen
+// commitment c = g^x * h^r (see commitments.PedersenCommitter) is one of the values of
+// the public set, without revealing which one (for example that the country attribute is
+// one of the EU countries). It is an OR composition of the proofs of knowledge of
+// log_h(c / g^s) for all s from the set - the prover knows r only for s = x.
+//
+// The proof size is linear in the size of the set, thus the protocol is meant for small
+// sets (for large sets the proofs based on signatures of the set elements are more
+// efficient, see Camenisch, Chaabouni, Shelat: Efficient protocols for set membership
+// and range proofs). The verifier passes nil for x and r.
+func NewSetMembership(group *groups.SchnorrGroup, h, c *big.Int, set []*big.Int,
+	x, r *big.Int) Protocol {
+	branches := make([]Protocol, len(set))
+	for i, s := range set {
+		var secret *big.Int
+		if x != nil && r != nil && equalMod(x, s, group.Q) {
+			secret = r
+		}
+		// c / g^s = h^r iff s = x
+		y := group.Mul(c, group.Inv(group.Exp(group.G, s)))
+		branches[i] = NewDLog(group, h, y, secret)
+	}
+	return Or(branches...)
+}
+
+// equalMod returns true if a = b (mod n).
+func equalMod(a, b, n *big.Int) bool {
+	d := new(big.Int).Sub(a, b)
+	return d.Mod(d, n).Sign() == 0
+}
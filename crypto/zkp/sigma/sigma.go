@@ -0,0 +1,138 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package sigma provides composable sigma protocols. Statements are built from the basic
+// protocols (knowledge of a representation, dlog equality, EC dlog) with And and Or
+// combinators into arbitrary trees, for example:
+//
+//	sigma.Or(dlog, sigma.And(opening, equality))
+//
+// and proved without writing a bespoke prover. OR composition follows Cramer, Damgard,
+// Schoenmakers: Proofs of partial knowledge - the prover simulates the branches for which
+// it does not know the witness.
+//
+// The basic protocols are written against groups.PrimeOrderGroup (see NewGroupRepresentation
+// and NewGroupDLogEquality), thus they work with Schnorr groups, elliptic curves and any other
+// backend.
+//
+// New protocols can be defined by their homomorphism (see Homomorphism and NewPreimage),
+// the prover, verifier and simulator are derived from it.
+//
+// The messages of all protocols are vectors of integers, thus the protocols can be
+// turned into non-interactive proofs with the fiatshamir package.
+package sigma
+
+import (
+	"errors"
+	"github.com/xlab-si/emmy/crypto/common"
+	"math/big"
+)
+
+var errNoProofRandomData = errors.New("sigma: GetProofData needs to be preceded " +
+	"by GetProofRandomData")
+
+// Protocol is a sigma protocol for a fixed statement. The prover's methods
+// (GetProofRandomData, GetProofData) can be called only if HasWitness returns true,
+// GetProofData returns an error if it is not preceded by GetProofRandomData.
+type Protocol interface {
+	// Name identifies the protocol (it is used for example by Fiat-Shamir heuristic).
+	Name() string
+	// Statement returns the public values which the proof is about.
+	Statement() []*big.Int
+	// ChallengeSpace returns the bound for the challenges, which are from [0, ChallengeSpace).
+	ChallengeSpace() *big.Int
+	// HasWitness returns true if the protocol can be proved (the witness is known).
+	HasWitness() bool
+	GetProofRandomData() ([]*big.Int, error)
+	GetProofData(challenge *big.Int) ([]*big.Int, error)
+	Verify(proofRandomData []*big.Int, challenge *big.Int, proofData []*big.Int) bool
+	// Simulate returns an accepting transcript for the given challenge without
+	// the knowledge of the witness.
+	Simulate(challenge *big.Int) ([]*big.Int, []*big.Int, error)
+}
+
+// Run demonstrates the interactive execution of the protocol (the verifier chooses
+// a random challenge). It returns an error if the witness is not known.
+func Run(p Protocol) (bool, error) {
+	if !p.HasWitness() {
+		return false, errors.New("witness for the protocol is not known")
+	}
+	proofRandomData, err := p.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	challenge, err := common.GetRandomInt(p.ChallengeSpace())
+	if err != nil {
+		return false, err
+	}
+	proofData, err := p.GetProofData(challenge)
+	if err != nil {
+		return false, err
+	}
+	return p.Verify(proofRandomData, challenge, proofData), nil
+}
+
+// commonChallengeSpace returns 2^k where k is the largest number such that 2^k is not
+// greater than any of the challenge spaces. The challenges of the composed protocols
+// are k-bit strings, thus they are valid challenges for each of the protocols.
+func commonChallengeSpace(protocols []Protocol) *big.Int {
+	k := -1
+	for _, p := range protocols {
+		if l := p.ChallengeSpace().BitLen() - 1; k < 0 || l < k {
+			k = l
+		}
+	}
+	return new(big.Int).Lsh(big.NewInt(1), uint(k))
+}
+
+// pack concatenates the vectors, each prefixed with its length.
+func pack(vectors ...[]*big.Int) []*big.Int {
+	var result []*big.Int
+	for _, v := range vectors {
+		result = append(result, big.NewInt(int64(len(v))))
+		result = append(result, v...)
+	}
+	return result
+}
+
+// unpack splits the vector packed by pack into n vectors. It returns false if the vector
+// is not well-formed.
+func unpack(packed []*big.Int, n int) ([][]*big.Int, bool) {
+	result := make([][]*big.Int, n)
+	for i := 0; i < n; i++ {
+		if len(packed) == 0 || packed[0] == nil || !packed[0].IsInt64() {
+			return nil, false
+		}
+		l := packed[0].Int64()
+		if l < 0 || l > int64(len(packed)-1) {
+			return nil, false
+		}
+		result[i] = packed[1 : l+1]
+		packed = packed[l+1:]
+	}
+	return result, len(packed) == 0
+}
+
+// validInts checks that values are non-nil integers from [0, bound).
+func validInts(values []*big.Int, bound *big.Int) bool {
+	for _, v := range values {
+		if v == nil || v.Sign() < 0 || v.Cmp(bound) >= 0 {
+			return false
+		}
+	}
+	return true
+}
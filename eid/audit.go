@@ -0,0 +1,225 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package eid
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"math/big"
+	"sync"
+)
+
+// AuditRecord records a single issuance. It does not contain the subject - only the hash of
+// the subject (together with the eID provider), so that the issuances for a given person can
+// be found when the person (or a court order) provides the identifier, and the names of
+// the claims which were mapped. The values of the claims are stored only when the issuer
+// is configured to do so (see Issuer.SetValueEncryption) - encrypted, so that they can be
+// erased (see AuditLog.EraseSubject) without breaking the hash chain.
+type AuditRecord struct {
+	Sequence    uint64
+	Timestamp   int64
+	Format      string
+	Issuer      string // the eID provider
+	SubjectHash []byte
+	LoA         LoA
+	Mapping     string
+	Claims      []string
+	Root        *big.Int // the Merkle root of the attributes signed in the credential
+	Values      *encryption.Envelope
+	PrevHash    []byte
+	Hash        []byte
+}
+
+// SubjectHash returns the hash of the subject of the assertion issued by the eID provider.
+func SubjectHash(issuer, subject string) []byte {
+	h := sha256.New()
+	writeAuditField(h, []byte("emmy/eid/subject"))
+	writeAuditField(h, []byte(issuer))
+	writeAuditField(h, []byte(subject))
+	return h.Sum(nil)
+}
+
+// hash returns H(prevHash, fields of the record).
+func (record *AuditRecord) hash() []byte {
+	h := sha256.New()
+	writeAuditField(h, record.PrevHash)
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], record.Sequence)
+	writeAuditField(h, buf[:])
+	binary.BigEndian.PutUint64(buf[:], uint64(record.Timestamp))
+	writeAuditField(h, buf[:])
+	binary.BigEndian.PutUint64(buf[:], uint64(record.LoA))
+	writeAuditField(h, buf[:])
+	for _, field := range []string{record.Format, record.Issuer, record.Mapping} {
+		writeAuditField(h, []byte(field))
+	}
+	writeAuditField(h, record.SubjectHash)
+	binary.BigEndian.PutUint64(buf[:], uint64(len(record.Claims)))
+	writeAuditField(h, buf[:])
+	for _, claim := range record.Claims {
+		writeAuditField(h, []byte(claim))
+	}
+	if record.Root != nil {
+		writeAuditField(h, record.Root.Bytes())
+	} else {
+		writeAuditField(h, nil)
+	}
+	// the wrapped data key (and its KEK) is not hashed, so that it can be rewrapped or erased
+	if record.Values != nil {
+		writeAuditField(h, record.Values.Nonce)
+		writeAuditField(h, record.Values.Ciphertext)
+	}
+	return h.Sum(nil)
+}
+
+func writeAuditField(h interface{ Write([]byte) (int, error) }, b []byte) {
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(len(b)))
+	h.Write(l[:])
+	h.Write(b)
+}
+
+// AuditLog is an append-only audit trail of the issuances. Each record contains the hash
+// of the previous one, thus records cannot be removed or changed without breaking the chain
+// (the hash of the last record can be published or countersigned periodically). It is safe
+// for concurrent use.
+type AuditLog struct {
+	records []*AuditRecord
+	mutex   sync.Mutex
+}
+
+func NewAuditLog() *AuditLog {
+	return &AuditLog{}
+}
+
+// Append sets the sequence number and the hashes of the record and appends it to the log.
+func (log *AuditLog) Append(record *AuditRecord) {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	record.Sequence = uint64(len(log.records))
+	record.PrevHash = nil
+	if len(log.records) > 0 {
+		record.PrevHash = log.records[len(log.records)-1].Hash
+	}
+	record.Hash = record.hash()
+	log.records = append(log.records, record)
+}
+
+// Records returns all the records of the log.
+func (log *AuditLog) Records() []*AuditRecord {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	return append([]*AuditRecord(nil), log.records...)
+}
+
+// Head returns the hash of the last record (nil for the empty log).
+func (log *AuditLog) Head() []byte {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	if len(log.records) == 0 {
+		return nil
+	}
+	return log.records[len(log.records)-1].Hash
+}
+
+// sealValues encrypts the values of the claims of the record, the subject hash is used as
+// the associated data.
+func (record *AuditRecord) sealValues(kek encryption.KeyEncrypter, values map[string]string) error {
+	plaintext, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	record.Values, err = encryption.Seal(kek, plaintext, record.SubjectHash)
+	return err
+}
+
+// OpenValues returns the values of the claims of the record. It returns
+// encryption.ErrErased if the values have been erased.
+func (record *AuditRecord) OpenValues(kek encryption.KeyEncrypter) (map[string]string, error) {
+	if record.Values == nil {
+		return nil, fmt.Errorf("record %d contains no values", record.Sequence)
+	}
+	plaintext, err := record.Values.Open(kek, record.SubjectHash)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	if err := json.Unmarshal(plaintext, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// RewrapValues wraps the data keys of the values of all the records with the current key
+// of kek, so that the previous keys can be destroyed (see encryption.LocalKeyRing). The hash
+// chain is not affected.
+func (log *AuditLog) RewrapValues(kek encryption.KeyEncrypter) error {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	for _, record := range log.records {
+		if record.Values == nil {
+			continue
+		}
+		if err := record.Values.Rewrap(kek); err != nil {
+			return fmt.Errorf("record %d: %v", record.Sequence, err)
+		}
+	}
+	return nil
+}
+
+// EraseSubject erases the values of the claims of all the records of the subject (see
+// SubjectHash) and returns the number of erased records. The records themselves are kept,
+// thus the hash chain is not affected.
+func (log *AuditLog) EraseSubject(subjectHash []byte) int {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	erased := 0
+	for _, record := range log.records {
+		if record.Values == nil || record.Values.Erased() ||
+			!bytes.Equal(record.SubjectHash, subjectHash) {
+			continue
+		}
+		record.Values.Erase()
+		erased++
+	}
+	return erased
+}
+
+// VerifyAuditTrail checks the hash chain of the records and that the hash of the last record
+// is head.
+func VerifyAuditTrail(records []*AuditRecord, head []byte) error {
+	var prevHash []byte
+	for i, record := range records {
+		if record.Sequence != uint64(i) {
+			return fmt.Errorf("record %d has sequence number %d", i, record.Sequence)
+		}
+		if !bytes.Equal(record.PrevHash, prevHash) || !bytes.Equal(record.Hash, record.hash()) {
+			return fmt.Errorf("hash chain is broken at record %d", i)
+		}
+		prevHash = record.Hash
+	}
+	if !bytes.Equal(prevHash, head) {
+		return fmt.Errorf("hash of the last record does not match the head")
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package eid maps the attributes from national eID assertions (eIDAS SAML assertions or
+// OpenID Connect ID tokens) into the attributes of emmy credentials at issuance: the user
+// authenticates at the eID provider, the issuer maps the claims of the verified assertion
+// into integer attributes (see Mapping), commits to them with a Merkle commitment and signs
+// the root with CL signature (see Issuer). Each issuance is recorded in a hash-chained audit
+// trail which does not contain the values of the attributes (see AuditLog).
+//
+// The assertions themselves are checked by AssertionVerifier implementations which wrap
+// the SAML and OIDC libraries (signature of the eID provider, audience, validity period, ...).
+package eid
+
+import (
+	"fmt"
+	"time"
+)
+
+// LoA is the level of assurance of the electronic identification (eIDAS regulation, art. 8).
+type LoA int
+
+const (
+	LoALow LoA = iota + 1
+	LoASubstantial
+	LoAHigh
+)
+
+// URIs of the levels of assurance as used in eIDAS SAML assertions.
+const (
+	LoALowURI         = "http://eidas.europa.eu/LoA/low"
+	LoASubstantialURI = "http://eidas.europa.eu/LoA/substantial"
+	LoAHighURI        = "http://eidas.europa.eu/LoA/high"
+)
+
+// ParseLoA returns the level of assurance for the eIDAS URI.
+func ParseLoA(uri string) (LoA, error) {
+	switch uri {
+	case LoALowURI:
+		return LoALow, nil
+	case LoASubstantialURI:
+		return LoASubstantial, nil
+	case LoAHighURI:
+		return LoAHigh, nil
+	}
+	return 0, fmt.Errorf("unknown level of assurance: %s", uri)
+}
+
+func (loa LoA) String() string {
+	switch loa {
+	case LoALow:
+		return LoALowURI
+	case LoASubstantial:
+		return LoASubstantialURI
+	case LoAHigh:
+		return LoAHighURI
+	}
+	return fmt.Sprintf("LoA(%d)", int(loa))
+}
+
+// Formats of the assertions.
+const (
+	FormatSAML = "saml"
+	FormatOIDC = "oidc"
+)
+
+// Assertion holds the claims of a verified eID assertion. Claims are keyed by the attribute
+// names as they appear in the assertion (eIDAS attribute URIs for SAML, claim names for OIDC).
+type Assertion struct {
+	Format   string
+	Issuer   string // entity ID of the eID provider (eIDAS node) or OIDC issuer
+	Subject  string
+	LoA      LoA
+	IssuedAt time.Time
+	Claims   map[string]string
+}
+
+// AssertionVerifier checks the raw assertion (XML of the SAML assertion or the ID token) and
+// returns its claims. It needs to check the signature of the eID provider, that the assertion
+// is intended for the issuer and that it is still valid.
+type AssertionVerifier interface {
+	Format() string
+	Verify(raw []byte) (*Assertion, error)
+}
+
+// Attributes of a natural person from the eIDAS minimum data set.
+const (
+	EIDASPersonIdentifier = "http://eidas.europa.eu/attributes/naturalperson/PersonIdentifier"
+	EIDASFamilyName       = "http://eidas.europa.eu/attributes/naturalperson/CurrentFamilyName"
+	EIDASGivenName        = "http://eidas.europa.eu/attributes/naturalperson/CurrentGivenName"
+	EIDASDateOfBirth      = "http://eidas.europa.eu/attributes/naturalperson/DateOfBirth"
+)
+
+// Standard OpenID Connect claims.
+const (
+	OIDCSubject    = "sub"
+	OIDCFamilyName = "family_name"
+	OIDCGivenName  = "given_name"
+	OIDCBirthdate  = "birthdate"
+)
@@ -0,0 +1,136 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package eid
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"math/big"
+	"time"
+)
+
+// Credential holds the attributes mapped from the assertion, committed with a Merkle
+// commitment whose root is signed by the issuer. The holder uses Committer to reveal
+// single attributes (see signatures.CL.VerifyMerkleDisclosure).
+type Credential struct {
+	Attributes []*big.Int
+	Root       *big.Int
+	Signature  *signatures.CLSignature
+	Committer  *commitments.MerkleCommitter
+}
+
+// Issuer issues credentials from eID assertions: it verifies the assertion with the verifier
+// for its format, maps its claims with the mapping for the format and signs the Merkle root
+// of the attributes with CL signature (see signatures.NewMerkleCL).
+type Issuer struct {
+	cl        *signatures.CL
+	verifiers map[string]AssertionVerifier
+	mappings  map[string]*Mapping
+	log       *AuditLog
+	kek       encryption.KeyEncrypter
+}
+
+func NewIssuer(cl *signatures.CL, log *AuditLog) *Issuer {
+	return &Issuer{
+		cl:        cl,
+		verifiers: make(map[string]AssertionVerifier),
+		mappings:  make(map[string]*Mapping),
+		log:       log,
+	}
+}
+
+// SetValueEncryption makes the issuer store the values of the mapped claims in the audit
+// records (for example for the registration records which need to be kept), encrypted
+// with the data keys wrapped with kek (see AuditRecord.OpenValues). Nil disables it.
+func (issuer *Issuer) SetValueEncryption(kek encryption.KeyEncrypter) {
+	issuer.kek = kek
+}
+
+// AddFormat registers the verifier and the mapping for the assertions of the verifier's
+// format.
+func (issuer *Issuer) AddFormat(verifier AssertionVerifier, mapping *Mapping) {
+	issuer.verifiers[verifier.Format()] = verifier
+	issuer.mappings[verifier.Format()] = mapping
+}
+
+// Issue verifies the raw assertion of the given format and issues the credential. The issuance
+// is recorded in the audit log.
+func (issuer *Issuer) Issue(format string, raw []byte) (*Credential, error) {
+	verifier, ok := issuer.verifiers[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported assertion format: %s", format)
+	}
+	assertion, err := verifier.Verify(raw)
+	if err != nil {
+		return nil, fmt.Errorf("assertion is not valid: %v", err)
+	}
+	return issuer.IssueFromAssertion(assertion)
+}
+
+// IssueFromAssertion issues the credential from the assertion which has already been
+// verified.
+func (issuer *Issuer) IssueFromAssertion(assertion *Assertion) (*Credential, error) {
+	mapping, ok := issuer.mappings[assertion.Format]
+	if !ok {
+		return nil, fmt.Errorf("no mapping for assertion format: %s", assertion.Format)
+	}
+	attributes, claims, err := mapping.Map(assertion)
+	if err != nil {
+		return nil, err
+	}
+
+	committer := commitments.NewMerkleCommitter()
+	root, err := committer.GetCommitMsg(attributes)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := issuer.cl.SignMerkleRoot(root)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &AuditRecord{
+		Timestamp:   time.Now().Unix(),
+		Format:      assertion.Format,
+		Issuer:      assertion.Issuer,
+		SubjectHash: SubjectHash(assertion.Issuer, assertion.Subject),
+		LoA:         assertion.LoA,
+		Mapping:     mapping.Name,
+		Claims:      claims,
+		Root:        root,
+	}
+	if issuer.kek != nil {
+		values := make(map[string]string, len(claims))
+		for _, claim := range claims {
+			values[claim] = assertion.Claims[claim]
+		}
+		if err := record.sealValues(issuer.kek, values); err != nil {
+			return nil, err
+		}
+	}
+	issuer.log.Append(record)
+
+	return &Credential{
+		Attributes: attributes,
+		Root:       root,
+		Signature:  signature,
+		Committer:  committer,
+	}, nil
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package eid
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// AttributeBitLength is the bit length of the encoded attributes - they fit into a message
+// block of CL signatures, thus the attributes can be used in the proofs about CL signed
+// attributes as well.
+const AttributeBitLength = 160
+
+// Encoder converts the value of a claim into an attribute.
+type Encoder func(value string) (*big.Int, error)
+
+// EncodeString hashes the value into an attribute of AttributeBitLength bits. The value is
+// normalized (trimmed), so the same name from different eID providers maps into the same
+// attribute.
+func EncodeString(value string) (*big.Int, error) {
+	h := sha256.Sum256([]byte(strings.TrimSpace(value)))
+	x := new(big.Int).SetBytes(h[:])
+	return x.Rsh(x, 8*sha256.Size-AttributeBitLength), nil
+}
+
+// EncodeDate encodes the date in the form YYYY-MM-DD (as used in eIDAS and OIDC) as
+// the integer YYYYMMDD, which preserves the order of dates - range proofs can thus be used
+// to prove for example that the holder is older than 18 years.
+func EncodeDate(value string) (*big.Int, error) {
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %s: %v", value, err)
+	}
+	return big.NewInt(int64(date.Year()*10000 + int(date.Month())*100 + date.Day())), nil
+}
+
+// EncodeInteger encodes a non-negative decimal integer.
+func EncodeInteger(value string) (*big.Int, error) {
+	x, ok := new(big.Int).SetString(strings.TrimSpace(value), 10)
+	if !ok || x.Sign() < 0 || x.BitLen() > AttributeBitLength {
+		return nil, fmt.Errorf("invalid integer attribute: %s", value)
+	}
+	return x, nil
+}
+
+// Rule maps the claim into the attribute. Optional claims which are missing in the assertion
+// are mapped to 0.
+type Rule struct {
+	Claim    string
+	Encode   Encoder
+	Required bool
+}
+
+// Mapping is an ordered list of rules - the i-th attribute of the credential is obtained by
+// the i-th rule. Assertions with a lower level of assurance than MinLoA are rejected.
+type Mapping struct {
+	Name   string
+	MinLoA LoA
+	Rules  []Rule
+}
+
+// Map returns the attributes of the credential and the names of the claims which were used.
+func (mapping *Mapping) Map(assertion *Assertion) ([]*big.Int, []string, error) {
+	if assertion.LoA < mapping.MinLoA {
+		return nil, nil, fmt.Errorf("level of assurance %v is lower than required %v",
+			assertion.LoA, mapping.MinLoA)
+	}
+	attributes := make([]*big.Int, len(mapping.Rules))
+	var claims []string
+	for i, rule := range mapping.Rules {
+		value, ok := assertion.Claims[rule.Claim]
+		if !ok {
+			if rule.Required {
+				return nil, nil, fmt.Errorf("required claim %s is missing", rule.Claim)
+			}
+			attributes[i] = big.NewInt(0)
+			continue
+		}
+		attribute, err := rule.Encode(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("claim %s: %v", rule.Claim, err)
+		}
+		attributes[i] = attribute
+		claims = append(claims, rule.Claim)
+	}
+	return attributes, claims, nil
+}
+
+// EIDASNaturalPersonMapping maps the eIDAS minimum data set of a natural person into
+// the attributes (person identifier, family name, given name, date of birth). The level of
+// assurance needs to be at least substantial.
+func EIDASNaturalPersonMapping() *Mapping {
+	return &Mapping{
+		Name:   "eidas-natural-person",
+		MinLoA: LoASubstantial,
+		Rules: []Rule{
+			{Claim: EIDASPersonIdentifier, Encode: EncodeString, Required: true},
+			{Claim: EIDASFamilyName, Encode: EncodeString, Required: true},
+			{Claim: EIDASGivenName, Encode: EncodeString, Required: true},
+			{Claim: EIDASDateOfBirth, Encode: EncodeDate, Required: true},
+		},
+	}
+}
+
+// OIDCMapping maps the standard OpenID Connect claims into the same attributes as
+// EIDASNaturalPersonMapping (the subject stands for the person identifier).
+func OIDCMapping() *Mapping {
+	return &Mapping{
+		Name:   "oidc-natural-person",
+		MinLoA: LoASubstantial,
+		Rules: []Rule{
+			{Claim: OIDCSubject, Encode: EncodeString, Required: true},
+			{Claim: OIDCFamilyName, Encode: EncodeString, Required: true},
+			{Claim: OIDCGivenName, Encode: EncodeString, Required: true},
+			{Claim: OIDCBirthdate, Encode: EncodeDate},
+		},
+	}
+}
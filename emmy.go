@@ -30,7 +30,7 @@ func main() {
 	app.Version = "0.1"
 	app.Usage = `A CLI app for running emmy server, emmy clients 
 		and examples of proofs offered by the emmy library`
-	app.Commands = []cli.Command{emmy.ServerCmd, emmy.ClientCmd}
+	app.Commands = []cli.Command{emmy.ServerCmd, emmy.ClientCmd, emmy.DemoCmd}
 
 	app.Run(os.Args)
 }
@@ -0,0 +1,276 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Command client is the CLI of the members and moderators of the example anonymous forum
+// (see package forum). The master key and the credential of the member are kept in
+// the wallet file.
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"github.com/urfave/cli"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/examples/forum"
+	"github.com/xlab-si/emmy/log"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// wallet is the content of the wallet file.
+type wallet struct {
+	Secret     *big.Int
+	Credential *pseudonymsys.Credential
+}
+
+var commonFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "forum",
+		Value: "https://localhost:8443",
+		Usage: "`URL` of the forum",
+	},
+	cli.StringFlag{
+		Name:  "domain",
+		Value: "forum.example.com",
+		Usage: "`DOMAIN` of the forum",
+	},
+	cli.StringFlag{
+		Name:  "wallet",
+		Value: "forum-wallet.json",
+		Usage: "`PATH` to the wallet file",
+	},
+	cli.StringFlag{
+		Name:  "cacert",
+		Value: filepath.Join(config.LoadTestdataDir(), "server.pem"),
+		Usage: "`PATH` to certificate file of the CA that issued the servers' certificates",
+	},
+}
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "forum-client"
+	app.Usage = "A client of the anonymous forum (example application of emmy)"
+	app.Commands = []cli.Command{
+		{
+			Name:      "join",
+			Usage:     "Obtains a credential from emmy server and registers an account",
+			ArgsUsage: "NAME",
+			Flags: append(commonFlags, cli.StringFlag{
+				Name:  "server",
+				Value: config.LoadServerEndpoint(),
+				Usage: "`URI` of emmy server in the form serverHost:serverPort",
+			}),
+			Action: action(join),
+		},
+		{
+			Name:      "post",
+			Usage:     "Publishes a post",
+			ArgsUsage: "TEXT",
+			Flags: append(commonFlags, cli.BoolFlag{
+				Name:  "anonymous",
+				Usage: "Whether to post without the name of the account",
+			}),
+			Action: action(post),
+		},
+		{
+			Name:   "read",
+			Usage:  "Prints the posts",
+			Flags:  commonFlags,
+			Action: action(read),
+		},
+		{
+			Name:      "ban",
+			Usage:     "Bans the author of the post (moderators only)",
+			ArgsUsage: "POST_ID",
+			Flags: append(commonFlags, cli.StringFlag{
+				Name:   "moderator-token",
+				EnvVar: "FORUM_MODERATOR_TOKEN",
+				Usage:  "`TOKEN` of the moderator",
+			}),
+			Action: action(ban),
+		},
+	}
+	app.Run(os.Args)
+}
+
+func action(f func(*cli.Context) error) func(*cli.Context) error {
+	return func(ctx *cli.Context) error {
+		if err := f(ctx); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		return nil
+	}
+}
+
+// join obtains the CA certificate, registers a nym with the organization, obtains
+// the credential for it and registers the domain nym at the forum.
+func join(ctx *cli.Context) error {
+	name := ctx.Args().First()
+	if name == "" {
+		return fmt.Errorf("name of the account is required")
+	}
+	if _, err := os.Stat(ctx.String("wallet")); err == nil {
+		return fmt.Errorf("wallet %s already exists", ctx.String("wallet"))
+	}
+
+	client.SetLogger(log.NewNullLogger())
+	conn, err := client.GetConnection(ctx.String("server"), ctx.String("cacert"), false)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	params := config.LoadPseudonymsysParams()
+	caClient, err := client.NewPseudonymsysCAClient(conn, params)
+	if err != nil {
+		return err
+	}
+	c, err := client.NewPseudonymsysClient(conn, params)
+	if err != nil {
+		return err
+	}
+
+	secret, err := c.GenerateMasterKey()
+	if err != nil {
+		return err
+	}
+	masterNym := pseudonymsys.NewPseudonym(params.Group.G, params.Group.Exp(params.Group.G,
+		secret))
+	caCertificate, err := caClient.ObtainCertificate(secret, masterNym)
+	if err != nil {
+		return err
+	}
+	nym, err := c.GenerateNym(secret, caCertificate)
+	if err != nil {
+		return err
+	}
+	h1, h2 := config.LoadPseudonymsysOrgPubKeys("org1")
+	credential, err := c.ObtainCredential(secret, nym, pseudonymsys.NewOrgPubKeys(h1, h2))
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(&wallet{
+		Secret:     secret,
+		Credential: credential,
+	})
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(ctx.String("wallet"), data, 0600); err != nil {
+		return err
+	}
+
+	member := forum.NewMember(ctx.String("domain"), params.Group, secret, credential)
+	forumClient, err := newForumClient(ctx)
+	if err != nil {
+		return err
+	}
+	account, err := forumClient.Register(member, name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Registered account %s\n", account.Name)
+	return nil
+}
+
+func post(ctx *cli.Context) error {
+	text := strings.Join(ctx.Args(), " ")
+	data, err := ioutil.ReadFile(ctx.String("wallet"))
+	if err != nil {
+		return err
+	}
+	var w wallet
+	if err := json.Unmarshal(data, &w); err != nil {
+		return fmt.Errorf("invalid wallet: %v", err)
+	}
+
+	member := forum.NewMember(ctx.String("domain"), config.LoadGroup("pseudonymsys"), w.Secret,
+		w.Credential)
+	forumClient, err := newForumClient(ctx)
+	if err != nil {
+		return err
+	}
+	p, err := forumClient.Post(member, text, !ctx.Bool("anonymous"))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Published post %d\n", p.ID)
+	return nil
+}
+
+func read(ctx *cli.Context) error {
+	forumClient, err := newForumClient(ctx)
+	if err != nil {
+		return err
+	}
+	posts, err := forumClient.GetPosts()
+	if err != nil {
+		return err
+	}
+	for _, p := range posts {
+		author := p.Author
+		if author == "" {
+			author = "anonymous"
+		}
+		fmt.Printf("#%d %s (%s): %s\n", p.ID, author, p.Time.Format(time.RFC822), p.Text)
+	}
+	return nil
+}
+
+func ban(ctx *cli.Context) error {
+	postID, err := strconv.Atoi(ctx.Args().First())
+	if err != nil {
+		return fmt.Errorf("ID of the post is required")
+	}
+	forumClient, err := newForumClient(ctx)
+	if err != nil {
+		return err
+	}
+	if err := forumClient.Ban(ctx.String("moderator-token"), postID); err != nil {
+		return err
+	}
+	fmt.Printf("Banned the author of post %d\n", postID)
+	return nil
+}
+
+// newForumClient returns the client of the forum which trusts the certificates issued by
+// the CA from the cacert flag.
+func newForumClient(ctx *cli.Context) (*forum.Client, error) {
+	caCert, err := ioutil.ReadFile(ctx.String("cacert"))
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("invalid certificate %s", ctx.String("cacert"))
+	}
+	c := forum.NewClient(ctx.String("forum"))
+	c.Client.Transport = &http.Transport{
+		TLSClientConfig: &tls.Config{RootCAs: roots},
+	}
+	return c, nil
+}
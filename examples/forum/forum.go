@@ -0,0 +1,468 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package forum is an example application built on emmy - an anonymous forum where each
+// person can have at most one account. It is meant as a starting point for applications
+// and as an integration test of the pseudonym system schemes.
+//
+// Members are persons with a credential issued by the organization which the forum
+// trusts (for example an identity provider which issues the credentials after checking
+// the person's identity, see client.PseudonymsysClient). The forum never learns who they are:
+//   - An account is a domain nym: (h, h^x), where h is derived from the domain of the forum
+//     and x is the member's master key. The member registers it by transferring
+//     the credential to it, thus a person cannot register two accounts, while accounts
+//     of the same person at different forums cannot be linked.
+//   - Each post carries a rate-limit ticket (see pseudonymsys.RateLimiter), which limits
+//     the number of posts per person per day, and a blacklist ticket with the proof that
+//     the author is not on the blacklist (see pseudonymsys.BlacklistProver). Moderators ban
+//     the author of a post by blacklisting its ticket, without learning who the author is.
+//   - Posts are signed by the account or anonymous. Note that posts made with the same
+//     credential can be linked by the forum (the credential is shown with each post),
+//     members who need unlinkable anonymous posts use a separate credential for them.
+//
+// All the proofs are interactive: the member sends the first messages of the proofs,
+// the forum answers with the challenges (and the ID of the session), and the member
+// completes the proofs (see Handler for the HTTP API and Client for its client).
+package forum
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// Forum keeps the accounts, posts and the blacklist of the forum. It is safe for
+// concurrent use.
+type Forum struct {
+	Domain     string
+	group      *groups.SchnorrGroup
+	orgPubKeys *pseudonymsys.OrgPubKeys
+	limiter    *pseudonymsys.RateLimiter
+	ttl        time.Duration
+	accounts   map[string]*Account // by the domain nym
+	posts      []*Post
+	blacklist  []*pseudonymsys.BlacklistTicket
+	sessions   map[string]*session
+	mutex      sync.Mutex
+}
+
+// Account is a registered domain nym with the name chosen by the member.
+type Account struct {
+	Name   string
+	Nym    *big.Int
+	Banned bool
+}
+
+// Post is a message on the forum. Author is the name of the account, it is empty for
+// anonymous posts.
+type Post struct {
+	ID     int
+	Author string
+	Text   string
+	Time   time.Time
+	ticket *pseudonymsys.BlacklistTicket
+}
+
+// session is a registration or a post whose proofs wait for the member's responses.
+type session struct {
+	expires time.Time
+	// registration
+	account    *Account
+	credential *pseudonymsys.Credential
+	transfer   *pseudonymsys.OrgCredentialVerifier
+	// post
+	post      *Post
+	epoch     int64
+	ticket    *big.Int
+	rateLimit *dlogproofs.DLogEqualityVerifier
+	blacklist *pseudonymsys.BlacklistVerifier
+	author    *dlogproofs.DLogEqualityVerifier
+}
+
+// NewForum returns the forum with the given domain, which accepts the credentials issued
+// by the organization with orgPubKeys and at most postsPerDay posts per person per day.
+func NewForum(domain string, group *groups.SchnorrGroup, orgPubKeys *pseudonymsys.OrgPubKeys,
+	postsPerDay int) (*Forum, error) {
+	limiter, err := pseudonymsys.NewRateLimiter(group,
+		pseudonymsys.NewRateLimitParams(postsPerDay, 24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	return &Forum{
+		Domain:     domain,
+		group:      group,
+		orgPubKeys: orgPubKeys,
+		limiter:    limiter,
+		ttl:        time.Minute,
+		accounts:   make(map[string]*Account),
+		sessions:   make(map[string]*session),
+	}, nil
+}
+
+// GetDomainBase returns the first part of the domain nyms of the forum with the given domain.
+func GetDomainBase(group *groups.SchnorrGroup, domain string) *big.Int {
+	return group.HashIntoElement(new(big.Int).SetBytes([]byte("emmy/forum/"+domain)),
+		big.NewInt(0))
+}
+
+// GetRateLimitScope returns the scope of the rate-limit tickets of the posts.
+func GetRateLimitScope(domain string) string {
+	return "forum/" + domain + "/posts"
+}
+
+// Challenges are the challenges of the proofs of a registration or a post.
+type Challenges struct {
+	ID        string
+	Transfer  *big.Int `json:",omitempty"`
+	RateLimit *big.Int `json:",omitempty"`
+	Blacklist *big.Int `json:",omitempty"`
+	Author    *big.Int `json:",omitempty"`
+}
+
+// RegistrationRequest registers the domain nym (GetDomainBase, Nym) with the given name - it
+// contains the credential and the first message of the proof that
+// log_h(Nym) = log_credential.SmallAToGamma(credential.SmallBToGamma).
+type RegistrationRequest struct {
+	Name       string
+	Nym        *big.Int
+	Credential *pseudonymsys.Credential
+	X1         *big.Int
+	X2         *big.Int
+}
+
+// RegistrationResponse completes the proof of the registration.
+type RegistrationResponse struct {
+	ID string
+	Z  *big.Int
+}
+
+// StartRegistration checks that the name and the domain nym are not taken and returns
+// the challenge of the transfer of the credential to the domain nym.
+func (forum *Forum) StartRegistration(req *RegistrationRequest) (*Challenges, error) {
+	if req.Name == "" || len(req.Name) > 64 {
+		return nil, fmt.Errorf("name needs to have between 1 and 64 characters")
+	}
+	if !forum.isElement(req.Nym) || req.Credential == nil ||
+		!forum.isElement(req.Credential.SmallAToGamma) ||
+		!forum.isElement(req.Credential.SmallBToGamma) || req.X1 == nil || req.X2 == nil {
+		return nil, fmt.Errorf("invalid registration request")
+	}
+
+	if err := forum.checkAccount(req.Name, req.Nym); err != nil {
+		return nil, err
+	}
+
+	transfer := pseudonymsys.NewOrgCredentialVerifier(forum.group, nil, nil)
+	challenge, err := transfer.GetAuthenticationChallenge(GetDomainBase(forum.group, forum.Domain),
+		req.Nym, req.Credential.SmallAToGamma, req.Credential.SmallBToGamma, req.X1, req.X2)
+	if err != nil {
+		return nil, err
+	}
+	id, err := forum.addSession(&session{
+		account: &Account{
+			Name: req.Name,
+			Nym:  req.Nym,
+		},
+		credential: req.Credential,
+		transfer:   transfer,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Challenges{
+		ID:       id,
+		Transfer: challenge,
+	}, nil
+}
+
+// FinishRegistration verifies the proof and the credential and registers the account.
+func (forum *Forum) FinishRegistration(resp *RegistrationResponse) (*Account, error) {
+	s, err := forum.getSession(resp.ID)
+	if err != nil {
+		return nil, err
+	}
+	if s.transfer == nil || resp.Z == nil ||
+		!s.transfer.VerifyAuthentication(resp.Z, s.credential, forum.orgPubKeys) {
+		return nil, fmt.Errorf("registration proof is not valid")
+	}
+
+	forum.mutex.Lock()
+	defer forum.mutex.Unlock()
+	// the account could have been registered in the meantime
+	if err := forum.checkAccountLocked(s.account.Name, s.account.Nym); err != nil {
+		return nil, err
+	}
+	forum.accounts[s.account.Nym.String()] = s.account
+	return s.account, nil
+}
+
+// checkAccount returns an error if the person with the domain nym already has an account or
+// if the name is taken.
+func (forum *Forum) checkAccount(name string, nym *big.Int) error {
+	forum.mutex.Lock()
+	defer forum.mutex.Unlock()
+	return forum.checkAccountLocked(name, nym)
+}
+
+func (forum *Forum) checkAccountLocked(name string, nym *big.Int) error {
+	if forum.accounts[nym.String()] != nil {
+		return fmt.Errorf("person already has an account")
+	}
+	for _, account := range forum.accounts {
+		if account.Name == name {
+			return fmt.Errorf("name %s is taken", name)
+		}
+	}
+	return nil
+}
+
+// Blacklist is the state which the member needs for posting - the current epoch of
+// the rate-limit tickets and the blacklisted tickets.
+type Blacklist struct {
+	Epoch   int64
+	Tickets []*pseudonymsys.BlacklistTicket
+}
+
+func (forum *Forum) GetBlacklist() *Blacklist {
+	forum.mutex.Lock()
+	defer forum.mutex.Unlock()
+	return &Blacklist{
+		Epoch:   forum.limiter.GetEpoch(),
+		Tickets: append([]*pseudonymsys.BlacklistTicket{}, forum.blacklist...),
+	}
+}
+
+// PostRequest contains the text of the post, the credential and the first messages of
+// the proofs:
+//   - the rate-limit ticket h^x for the epoch, where h is pseudonymsys.GetEpochBase of
+//     the scope of the posts, and the proof that it is computed with the master key of
+//     the credential (X1, X2, see dlogproofs.DLogEqualityProver),
+//   - the blacklist ticket and the proof that it is computed with the master key of
+//     the credential, which has not been used for any of the blacklisted tickets
+//     (BlacklistX1, ... BlacklistWs, see pseudonymsys.BlacklistProver),
+//   - for posts signed by the account, its domain nym and the proof that it belongs to
+//     the credential (AuthorX1, AuthorX2).
+type PostRequest struct {
+	Text            string
+	Credential      *pseudonymsys.Credential
+	Epoch           int64
+	Ticket          *big.Int
+	X1              *big.Int
+	X2              *big.Int
+	BlacklistTicket *pseudonymsys.BlacklistTicket
+	BlacklistX1     *big.Int
+	BlacklistX2     *big.Int
+	BlacklistCs     []*big.Int
+	BlacklistYs     []*big.Int
+	BlacklistWs     []*big.Int
+	Author          *big.Int `json:",omitempty"`
+	AuthorX1        *big.Int `json:",omitempty"`
+	AuthorX2        *big.Int `json:",omitempty"`
+}
+
+// PostResponse completes the proofs of the post.
+type PostResponse struct {
+	ID               string
+	Z                *big.Int
+	BlacklistZ       *big.Int
+	BlacklistZAlphas []*big.Int
+	BlacklistZBetas  []*big.Int
+	AuthorZ          *big.Int `json:",omitempty"`
+}
+
+// StartPost checks the post and returns the challenges of its proofs.
+func (forum *Forum) StartPost(req *PostRequest) (*Challenges, error) {
+	if req.Text == "" || len(req.Text) > 4096 {
+		return nil, fmt.Errorf("text needs to have between 1 and 4096 characters")
+	}
+	credential := req.Credential
+	if credential == nil || !forum.isElement(credential.SmallAToGamma) ||
+		!forum.isElement(credential.SmallBToGamma) || !forum.isElement(req.Ticket) ||
+		req.X1 == nil || req.X2 == nil || req.BlacklistTicket == nil ||
+		!forum.isElement(req.BlacklistTicket.H) || !forum.isElement(req.BlacklistTicket.Tag) ||
+		req.BlacklistX1 == nil || req.BlacklistX2 == nil {
+		return nil, fmt.Errorf("invalid post request")
+	}
+
+	post := &Post{
+		Text:   req.Text,
+		ticket: req.BlacklistTicket,
+	}
+	// the credential is shown for the blacklist proof anyway, thus the rate-limit ticket is
+	// proved against it: log_credential.SmallAToGamma(credential.SmallBToGamma) = log_h(ticket)
+	if req.Epoch != forum.limiter.GetEpoch() {
+		return nil, fmt.Errorf("ticket is not for the current epoch")
+	}
+	base := pseudonymsys.GetEpochBase(forum.group, GetRateLimitScope(forum.Domain), req.Epoch)
+	rateLimit := dlogproofs.NewDLogEqualityVerifier(forum.group)
+	rateLimitChallenge, err := rateLimit.GetChallenge(credential.SmallAToGamma, base,
+		credential.SmallBToGamma, req.Ticket, req.X1, req.X2)
+	if err != nil {
+		return nil, err
+	}
+
+	forum.mutex.Lock()
+	blacklist := pseudonymsys.NewCredentialBlacklistVerifier(forum.group, forum.orgPubKeys)
+	for _, ticket := range forum.blacklist {
+		blacklist.AddToBlacklist(ticket)
+	}
+	var account *Account
+	if req.Author != nil {
+		account = forum.accounts[req.Author.String()]
+	}
+	forum.mutex.Unlock()
+
+	blacklistChallenge, err := blacklist.GetChallengeForCredential(credential, req.BlacklistTicket,
+		req.BlacklistX1, req.BlacklistX2, req.BlacklistCs, req.BlacklistYs, req.BlacklistWs)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &session{
+		post:      post,
+		epoch:     req.Epoch,
+		ticket:    req.Ticket,
+		rateLimit: rateLimit,
+		blacklist: blacklist,
+	}
+	challenges := &Challenges{
+		RateLimit: rateLimitChallenge,
+		Blacklist: blacklistChallenge,
+	}
+	if req.Author != nil {
+		if account == nil || account.Banned || req.AuthorX1 == nil || req.AuthorX2 == nil {
+			return nil, fmt.Errorf("account cannot post")
+		}
+		// log_h(author) = log_credential.SmallAToGamma(credential.SmallBToGamma)
+		post.Author = account.Name
+		s.author = dlogproofs.NewDLogEqualityVerifier(forum.group)
+		challenges.Author, err = s.author.GetChallenge(GetDomainBase(forum.group, forum.Domain),
+			credential.SmallAToGamma, req.Author, credential.SmallBToGamma, req.AuthorX1,
+			req.AuthorX2)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if challenges.ID, err = forum.addSession(s); err != nil {
+		return nil, err
+	}
+	return challenges, nil
+}
+
+// FinishPost verifies the proofs of the post and publishes it.
+func (forum *Forum) FinishPost(resp *PostResponse) (*Post, error) {
+	s, err := forum.getSession(resp.ID)
+	if err != nil {
+		return nil, err
+	}
+	if s.post == nil || resp.Z == nil || resp.BlacklistZ == nil {
+		return nil, fmt.Errorf("invalid post response")
+	}
+	if s.author != nil && (resp.AuthorZ == nil || !s.author.Verify(resp.AuthorZ)) {
+		return nil, fmt.Errorf("post is not signed by the account")
+	}
+	if !s.blacklist.Verify(resp.BlacklistZ, resp.BlacklistZAlphas, resp.BlacklistZBetas) {
+		return nil, fmt.Errorf("blacklist proof is not valid")
+	}
+	if !s.rateLimit.Verify(resp.Z) {
+		return nil, fmt.Errorf("rate-limit proof is not valid")
+	}
+	// the limit is checked (and the post is counted) last
+	err = forum.limiter.Reserve(GetRateLimitScope(forum.Domain), s.epoch, s.ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	forum.mutex.Lock()
+	defer forum.mutex.Unlock()
+	s.post.ID = len(forum.posts) + 1
+	s.post.Time = time.Now()
+	forum.posts = append(forum.posts, s.post)
+	return s.post, nil
+}
+
+// GetPosts returns the posts of the forum from the oldest to the newest.
+func (forum *Forum) GetPosts() []*Post {
+	forum.mutex.Lock()
+	defer forum.mutex.Unlock()
+	return append([]*Post{}, forum.posts...)
+}
+
+// Ban blacklists the author of the post with the given ID and removes the post. Its account
+// (if the post was signed) is marked as banned.
+func (forum *Forum) Ban(postID int) error {
+	forum.mutex.Lock()
+	defer forum.mutex.Unlock()
+	if postID < 1 || postID > len(forum.posts) || forum.posts[postID-1].ticket == nil {
+		return fmt.Errorf("post %d does not exist or has been removed", postID)
+	}
+	post := forum.posts[postID-1]
+	forum.blacklist = append(forum.blacklist, post.ticket)
+	for _, account := range forum.accounts {
+		if post.Author != "" && account.Name == post.Author {
+			account.Banned = true
+		}
+	}
+	forum.posts[postID-1] = &Post{
+		ID:   post.ID,
+		Text: "[removed by moderator]",
+		Time: post.Time,
+	}
+	return nil
+}
+
+func (forum *Forum) isElement(x *big.Int) bool {
+	return x != nil && forum.group.IsElementInGroup(x) && x.Cmp(big.NewInt(1)) != 0
+}
+
+func (forum *Forum) addSession(s *session) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	s.expires = time.Now().Add(forum.ttl)
+
+	forum.mutex.Lock()
+	defer forum.mutex.Unlock()
+	now := time.Now()
+	for id, pending := range forum.sessions {
+		if now.After(pending.expires) {
+			delete(forum.sessions, id)
+		}
+	}
+	forum.sessions[hex.EncodeToString(id)] = s
+	return hex.EncodeToString(id), nil
+}
+
+// getSession returns (and removes) the session with the given ID.
+func (forum *Forum) getSession(id string) (*session, error) {
+	forum.mutex.Lock()
+	defer forum.mutex.Unlock()
+	s, ok := forum.sessions[id]
+	delete(forum.sessions, id)
+	if !ok || time.Now().After(s.expires) {
+		return nil, fmt.Errorf("session is not known or has expired")
+	}
+	return s, nil
+}
@@ -0,0 +1,241 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package forum
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxRequestSize limits the size of the requests (the blacklist proofs grow with
+// the blacklist).
+const maxRequestSize = 4 << 20
+
+// banRequest is the body of the moderator's request to ban the author of the post.
+type banRequest struct {
+	ID int
+}
+
+// Handler returns the HTTP API of the forum (all bodies are JSON):
+//
+//	GET  /posts                                       returns []Post
+//	GET  /blacklist                                   returns Blacklist
+//	POST /register/start  RegistrationRequest         returns Challenges
+//	POST /register/finish RegistrationResponse        returns Account
+//	POST /posts/start     PostRequest                 returns Challenges
+//	POST /posts/finish    PostResponse                returns Post
+//	POST /ban             {"ID"} (moderators only)
+//
+// Moderators authenticate with the header "Authorization: Bearer <moderatorToken>", if
+// moderatorToken is empty, nobody can ban.
+func (forum *Forum) Handler(moderatorToken string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/posts", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, forum.GetPosts(), nil)
+	})
+	mux.HandleFunc("/blacklist", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, forum.GetBlacklist(), nil)
+	})
+	mux.HandleFunc("/register/start", func(w http.ResponseWriter, r *http.Request) {
+		var req RegistrationRequest
+		if readJSON(w, r, &req) {
+			challenges, err := forum.StartRegistration(&req)
+			writeJSON(w, challenges, err)
+		}
+	})
+	mux.HandleFunc("/register/finish", func(w http.ResponseWriter, r *http.Request) {
+		var resp RegistrationResponse
+		if readJSON(w, r, &resp) {
+			account, err := forum.FinishRegistration(&resp)
+			writeJSON(w, account, err)
+		}
+	})
+	mux.HandleFunc("/posts/start", func(w http.ResponseWriter, r *http.Request) {
+		var req PostRequest
+		if readJSON(w, r, &req) {
+			challenges, err := forum.StartPost(&req)
+			writeJSON(w, challenges, err)
+		}
+	})
+	mux.HandleFunc("/posts/finish", func(w http.ResponseWriter, r *http.Request) {
+		var resp PostResponse
+		if readJSON(w, r, &resp) {
+			post, err := forum.FinishPost(&resp)
+			writeJSON(w, post, err)
+		}
+	})
+	mux.HandleFunc("/ban", func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if moderatorToken == "" ||
+			subtle.ConstantTimeCompare([]byte(token), []byte(moderatorToken)) != 1 {
+			http.Error(w, "Only moderators can ban.", http.StatusUnauthorized)
+			return
+		}
+		var req banRequest
+		if readJSON(w, r, &req) {
+			writeJSON(w, struct{}{}, forum.Ban(req.ID))
+		}
+	})
+	return mux
+}
+
+// readJSON decodes the body of the POST request into v. It writes an error and returns false
+// if the request is not valid.
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is supported.", http.StatusMethodNotAllowed)
+		return false
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestSize)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, "Invalid request.", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// writeJSON writes v or the error (as plain text with status 400).
+func writeJSON(w http.ResponseWriter, v interface{}, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// Client is the client of the HTTP API of the forum at URL (see Forum.Handler).
+type Client struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewClient(url string) *Client {
+	return &Client{
+		URL:    strings.TrimSuffix(url, "/"),
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Register registers the member's domain nym with the given name.
+func (c *Client) Register(member *Member, name string) (*Account, error) {
+	registration, err := member.NewRegistration(name)
+	if err != nil {
+		return nil, err
+	}
+	var challenges Challenges
+	if err := c.call("/register/start", "", registration.Request, &challenges); err != nil {
+		return nil, err
+	}
+	if challenges.Transfer == nil {
+		return nil, fmt.Errorf("forum did not send the challenge")
+	}
+
+	resp, err := registration.GetResponse(&challenges)
+	if err != nil {
+		return nil, err
+	}
+	var account Account
+	if err := c.call("/register/finish", "", resp, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Post publishes the post with the given text, signed by the member's account if signed
+// is true.
+func (c *Client) Post(member *Member, text string, signed bool) (*Post, error) {
+	var blacklist Blacklist
+	if err := c.call("/blacklist", "", nil, &blacklist); err != nil {
+		return nil, err
+	}
+	posting, err := member.NewPost(text, signed, &blacklist)
+	if err != nil {
+		return nil, err
+	}
+
+	var challenges Challenges
+	if err := c.call("/posts/start", "", posting.Request, &challenges); err != nil {
+		return nil, err
+	}
+	if challenges.RateLimit == nil || challenges.Blacklist == nil ||
+		(signed && challenges.Author == nil) {
+		return nil, fmt.Errorf("forum did not send the challenges")
+	}
+
+	resp, err := posting.GetResponse(&challenges)
+	if err != nil {
+		return nil, err
+	}
+	var post Post
+	if err := c.call("/posts/finish", "", resp, &post); err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+func (c *Client) GetPosts() ([]*Post, error) {
+	var posts []*Post
+	if err := c.call("/posts", "", nil, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// Ban bans the author of the post with the given ID - the moderator authenticates with
+// the token.
+func (c *Client) Ban(moderatorToken string, postID int) error {
+	return c.call("/ban", moderatorToken, &banRequest{ID: postID}, &struct{}{})
+}
+
+// call sends req (GET if it is nil, otherwise POST) and decodes the response into resp.
+func (c *Client) call(path, token string, req, resp interface{}) error {
+	method := http.MethodGet
+	var body []byte
+	if req != nil {
+		method = http.MethodPost
+		var err error
+		if body, err = json.Marshal(req); err != nil {
+			return err
+		}
+	}
+	httpReq, err := http.NewRequest(method, c.URL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	httpResp, err := c.Client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(httpResp.Body)
+		return fmt.Errorf("forum refused the request: %s", strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(httpResp.Body).Decode(resp)
+}
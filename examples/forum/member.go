@@ -0,0 +1,179 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package forum
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+)
+
+// Member computes the proofs of the member of the forum with the given domain, who has
+// the master key secret and the credential issued for one of its nyms.
+type Member struct {
+	Domain     string
+	group      *groups.SchnorrGroup
+	secret     *big.Int
+	credential *pseudonymsys.Credential
+}
+
+func NewMember(domain string, group *groups.SchnorrGroup, secret *big.Int,
+	credential *pseudonymsys.Credential) *Member {
+	return &Member{
+		Domain:     domain,
+		group:      group,
+		secret:     secret,
+		credential: credential,
+	}
+}
+
+// GetNym returns the second part of the member's domain nym (the first one is given
+// by GetDomainBase).
+func (member *Member) GetNym() *big.Int {
+	return member.group.Exp(GetDomainBase(member.group, member.Domain), member.secret)
+}
+
+// Registration is the registration of the domain nym - the request is sent to the forum and
+// the challenges are answered with GetResponse.
+type Registration struct {
+	Request *RegistrationRequest
+	prover  *dlogproofs.DLogEqualityProver
+}
+
+func (member *Member) NewRegistration(name string) (*Registration, error) {
+	prover := dlogproofs.NewDLogEqualityProver(member.group)
+	x1, x2, err := prover.GetProofRandomData(member.secret,
+		GetDomainBase(member.group, member.Domain), member.credential.SmallAToGamma)
+	if err != nil {
+		return nil, err
+	}
+	return &Registration{
+		Request: &RegistrationRequest{
+			Name:       name,
+			Nym:        member.GetNym(),
+			Credential: member.credential,
+			X1:         x1,
+			X2:         x2,
+		},
+		prover: prover,
+	}, nil
+}
+
+func (registration *Registration) GetResponse(challenges *Challenges) (*RegistrationResponse,
+	error) {
+	z, err := registration.prover.GetProofData(challenges.Transfer)
+	if err != nil {
+		return nil, err
+	}
+	return &RegistrationResponse{
+		ID: challenges.ID,
+		Z:  z,
+	}, nil
+}
+
+// Posting is a post which is being published - the request is sent to the forum and
+// the challenges are answered with GetResponse.
+type Posting struct {
+	Request   *PostRequest
+	rateLimit *dlogproofs.DLogEqualityProver
+	blacklist *pseudonymsys.BlacklistProver
+	author    *dlogproofs.DLogEqualityProver
+}
+
+// NewPost returns the post with the given text, signed by the member's account if signed is
+// true. blacklist is the current state of the forum (see Forum.GetBlacklist), an error is
+// returned if the member is blacklisted.
+func (member *Member) NewPost(text string, signed bool, blacklist *Blacklist) (*Posting,
+	error) {
+	credential := member.credential
+	credentialNym := pseudonymsys.NewPseudonym(credential.SmallAToGamma,
+		credential.SmallBToGamma)
+
+	base := pseudonymsys.GetEpochBase(member.group, GetRateLimitScope(member.Domain),
+		blacklist.Epoch)
+	ticket := member.group.Exp(base, member.secret)
+	rateLimit := dlogproofs.NewDLogEqualityProver(member.group)
+	x1, x2, err := rateLimit.GetProofRandomData(member.secret, credentialNym.A, base)
+	if err != nil {
+		return nil, err
+	}
+
+	blacklistProver := pseudonymsys.NewBlacklistProver(member.group, member.secret,
+		credentialNym)
+	blacklistTicket, err := blacklistProver.GetTicket()
+	if err != nil {
+		return nil, err
+	}
+	bx1, bx2, cs, ys, ws, err := blacklistProver.GetProofRandomData(blacklist.Tickets)
+	if err != nil {
+		return nil, fmt.Errorf("member cannot post: %v", err)
+	}
+
+	posting := &Posting{
+		Request: &PostRequest{
+			Text:            text,
+			Credential:      credential,
+			Epoch:           blacklist.Epoch,
+			Ticket:          ticket,
+			X1:              x1,
+			X2:              x2,
+			BlacklistTicket: blacklistTicket,
+			BlacklistX1:     bx1,
+			BlacklistX2:     bx2,
+			BlacklistCs:     cs,
+			BlacklistYs:     ys,
+			BlacklistWs:     ws,
+		},
+		rateLimit: rateLimit,
+		blacklist: blacklistProver,
+	}
+	if signed {
+		posting.author = dlogproofs.NewDLogEqualityProver(member.group)
+		posting.Request.Author = member.GetNym()
+		posting.Request.AuthorX1, posting.Request.AuthorX2, err = posting.author.GetProofRandomData(
+			member.secret, GetDomainBase(member.group, member.Domain),
+			credential.SmallAToGamma)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return posting, nil
+}
+
+func (posting *Posting) GetResponse(challenges *Challenges) (*PostResponse, error) {
+	z, zAlphas, zBetas := posting.blacklist.GetProofData(challenges.Blacklist)
+	rateLimitZ, err := posting.rateLimit.GetProofData(challenges.RateLimit)
+	if err != nil {
+		return nil, err
+	}
+	resp := &PostResponse{
+		ID:               challenges.ID,
+		Z:                rateLimitZ,
+		BlacklistZ:       z,
+		BlacklistZAlphas: zAlphas,
+		BlacklistZBetas:  zBetas,
+	}
+	if posting.author != nil {
+		if resp.AuthorZ, err = posting.author.GetProofData(challenges.Author); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Command server runs the example anonymous forum (see package forum) together with emmy
+// server, which acts as the CA and as the organization issuing the credentials of
+// the members (org1 from the configuration).
+package main
+
+import (
+	"github.com/urfave/cli"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/examples/forum"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "forum-server"
+	app.Usage = "An anonymous forum with one account per person (example application of emmy)"
+	app.Flags = []cli.Flag{
+		cli.IntFlag{
+			Name:  "port, p",
+			Value: config.LoadServerPort(),
+			Usage: "`PORT` where emmy server will listen for client connections",
+		},
+		cli.StringFlag{
+			Name:  "http",
+			Value: ":8443",
+			Usage: "`ADDRESS` where the forum will listen for HTTPS requests",
+		},
+		cli.StringFlag{
+			Name:  "domain",
+			Value: "forum.example.com",
+			Usage: "`DOMAIN` of the forum (accounts are different for each domain)",
+		},
+		cli.IntFlag{
+			Name:  "posts",
+			Value: 10,
+			Usage: "`NUMBER` of posts per person per day",
+		},
+		cli.StringFlag{
+			Name:   "moderator-token",
+			EnvVar: "FORUM_MODERATOR_TOKEN",
+			Usage:  "`TOKEN` with which the moderators ban (banning is disabled if not set)",
+		},
+		cli.StringFlag{
+			Name:  "cert",
+			Value: filepath.Join(config.LoadTestdataDir(), "server.pem"),
+			Usage: "`PATH` to servers certificate file",
+		},
+		cli.StringFlag{
+			Name:  "key",
+			Value: filepath.Join(config.LoadTestdataDir(), "server.key"),
+			Usage: "`PATH` to server key file",
+		},
+		cli.StringFlag{
+			Name:  "loglevel, l",
+			Value: "info",
+			Usage: "debug|info|notice|error|critical",
+		},
+	}
+	app.Action = func(ctx *cli.Context) error {
+		if err := run(ctx); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		return nil
+	}
+	app.Run(os.Args)
+}
+
+func run(ctx *cli.Context) error {
+	logger, err := log.NewStdoutLogger("forum", ctx.String("loglevel"), log.FORMAT_LONG)
+	if err != nil {
+		return err
+	}
+	srv, err := server.NewProtocolServer(ctx.String("cert"), ctx.String("key"), logger)
+	if err != nil {
+		return err
+	}
+	errs := make(chan error, 2)
+	go func() {
+		errs <- srv.Start(ctx.Int("port"))
+	}()
+
+	params := config.LoadPseudonymsysParams()
+	h1, h2 := config.LoadPseudonymsysOrgPubKeys("org1")
+	f, err := forum.NewForum(ctx.String("domain"), params.Group,
+		pseudonymsys.NewOrgPubKeys(h1, h2), ctx.Int("posts"))
+	if err != nil {
+		return err
+	}
+	go func() {
+		logger.Noticef("Forum %s listening for HTTPS requests at %s", f.Domain,
+			ctx.String("http"))
+		errs <- http.ListenAndServeTLS(ctx.String("http"), ctx.String("cert"),
+			ctx.String("key"), f.Handler(ctx.String("moderator-token")))
+	}()
+	return <-errs
+}
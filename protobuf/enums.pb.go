@@ -32,6 +32,23 @@ const (
 	SchemaType_PSEUDONYMSYS_TRANSFER_CREDENTIAL_EC SchemaType = 12
 	SchemaType_QR                                  SchemaType = 13
 	SchemaType_QNR                                 SchemaType = 14
+	SchemaType_PSEUDONYMSYS_RATE_LIMIT             SchemaType = 15
+	SchemaType_EXTENSION                           SchemaType = 16
+	SchemaType_PSEUDONYMSYS_CA_STATUS              SchemaType = 17
+	SchemaType_RANGE_PROOF                         SchemaType = 18
+	SchemaType_PAILLIER_PLAINTEXT                  SchemaType = 19
+	SchemaType_PSEUDONYMSYS_NYM_ESCROW             SchemaType = 20
+	SchemaType_REVOCATION_UPDATES                  SchemaType = 21
+	SchemaType_GPS                                 SchemaType = 22
+	SchemaType_PSEUDONYMSYS_NYM_REGISTRY           SchemaType = 23
+	SchemaType_STERN                               SchemaType = 24
+	SchemaType_ABUSE_REPORT                        SchemaType = 25
+	SchemaType_LATTICE_SHORT_VECTOR                SchemaType = 26
+	SchemaType_THRESHOLD_SCHNORR                   SchemaType = 27
+	SchemaType_BLIND_SCHNORR                       SchemaType = 28
+	SchemaType_PARTIALLY_BLIND_SCHNORR             SchemaType = 29
+	SchemaType_ANONCREDS_ISSUE                     SchemaType = 30
+	SchemaType_ANONCREDS_SHOW                      SchemaType = 31
 )
 
 var SchemaType_name = map[int32]string{
@@ -50,6 +67,23 @@ var SchemaType_name = map[int32]string{
 	12: "PSEUDONYMSYS_TRANSFER_CREDENTIAL_EC",
 	13: "QR",
 	14: "QNR",
+	15: "PSEUDONYMSYS_RATE_LIMIT",
+	16: "EXTENSION",
+	17: "PSEUDONYMSYS_CA_STATUS",
+	18: "RANGE_PROOF",
+	19: "PAILLIER_PLAINTEXT",
+	20: "PSEUDONYMSYS_NYM_ESCROW",
+	21: "REVOCATION_UPDATES",
+	22: "GPS",
+	23: "PSEUDONYMSYS_NYM_REGISTRY",
+	24: "STERN",
+	25: "ABUSE_REPORT",
+	26: "LATTICE_SHORT_VECTOR",
+	27: "THRESHOLD_SCHNORR",
+	28: "BLIND_SCHNORR",
+	29: "PARTIALLY_BLIND_SCHNORR",
+	30: "ANONCREDS_ISSUE",
+	31: "ANONCREDS_SHOW",
 }
 var SchemaType_value = map[string]int32{
 	"PEDERSEN":                            0,
@@ -65,8 +99,25 @@ var SchemaType_value = map[string]int32{
 	"PSEUDONYMSYS_NYM_GEN_EC":             10,
 	"PSEUDONYMSYS_ISSUE_CREDENTIAL_EC":    11,
 	"PSEUDONYMSYS_TRANSFER_CREDENTIAL_EC": 12,
-	"QR":  13,
-	"QNR": 14,
+	"QR":                                  13,
+	"QNR":                                 14,
+	"PSEUDONYMSYS_RATE_LIMIT":             15,
+	"EXTENSION":                           16,
+	"PSEUDONYMSYS_CA_STATUS":              17,
+	"RANGE_PROOF":                         18,
+	"PAILLIER_PLAINTEXT":                  19,
+	"PSEUDONYMSYS_NYM_ESCROW":             20,
+	"REVOCATION_UPDATES":                  21,
+	"GPS":                                 22,
+	"PSEUDONYMSYS_NYM_REGISTRY":           23,
+	"STERN":                               24,
+	"ABUSE_REPORT":                        25,
+	"LATTICE_SHORT_VECTOR":                26,
+	"THRESHOLD_SCHNORR":                   27,
+	"BLIND_SCHNORR":                       28,
+	"PARTIALLY_BLIND_SCHNORR":             29,
+	"ANONCREDS_ISSUE":                     30,
+	"ANONCREDS_SHOW":                      31,
 }
 
 func (x SchemaType) String() string {
@@ -107,23 +158,35 @@ func init() {
 func init() { proto.RegisterFile("enums.proto", fileDescriptor2) }
 
 var fileDescriptor2 = []byte{
-	// 285 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x84, 0x51, 0x5d, 0x4b, 0xc3, 0x30,
-	0x14, 0xd5, 0xce, 0xb5, 0xdd, 0xed, 0x3e, 0xc2, 0x55, 0x54, 0x10, 0x41, 0x51, 0x10, 0xf6, 0xb0,
-	0x17, 0x7f, 0x41, 0xc9, 0xe2, 0x0c, 0xeb, 0xd2, 0x2e, 0xb7, 0x13, 0xe6, 0x4b, 0xe9, 0xa4, 0xa2,
-	0x0f, 0xfb, 0x60, 0x6e, 0x0f, 0xfe, 0x60, 0xff, 0x87, 0xc9, 0x86, 0x60, 0xa7, 0xb0, 0xa7, 0x9b,
-	0x73, 0xcf, 0x49, 0xce, 0xe1, 0x04, 0x82, 0x62, 0xb6, 0x9e, 0x7e, 0x74, 0x16, 0xcb, 0xf9, 0x6a,
-	0x8e, 0xfe, 0x66, 0x4c, 0xd6, 0xaf, 0xed, 0x2f, 0x07, 0x80, 0x5e, 0xde, 0x8a, 0x69, 0x9e, 0x7e,
-	0x2e, 0x0a, 0xac, 0x83, 0x9f, 0x88, 0xae, 0xd0, 0x24, 0x14, 0x3b, 0xc0, 0x16, 0x04, 0x3f, 0x28,
-	0x13, 0x9c, 0x1d, 0x62, 0x00, 0x1e, 0xf1, 0x47, 0x15, 0x6b, 0xcd, 0x1c, 0x6c, 0x9a, 0x9b, 0x5b,
-	0x60, 0xc9, 0x8a, 0xc5, 0x9c, 0x92, 0x50, 0x46, 0x91, 0x14, 0x9a, 0x1d, 0xe1, 0x31, 0xb4, 0x12,
-	0x12, 0xa3, 0x6e, 0xac, 0xc6, 0x03, 0x1a, 0x53, 0xc6, 0x43, 0x56, 0xc5, 0x73, 0x38, 0x29, 0x2d,
-	0xcd, 0xc8, 0x7a, 0xc6, 0xcc, 0xc5, 0x6b, 0xb8, 0x2c, 0x31, 0x92, 0x68, 0x24, 0x32, 0xae, 0x4d,
-	0x00, 0x95, 0xca, 0x30, 0x62, 0x1e, 0xde, 0xc2, 0x55, 0x49, 0x92, 0xea, 0x50, 0xd1, 0x83, 0xd0,
-	0xbf, 0x55, 0x3e, 0x9e, 0x02, 0xee, 0xf8, 0xda, 0x7c, 0x35, 0xbc, 0x80, 0xb3, 0xff, 0xac, 0x2d,
-	0x09, 0x7f, 0x9e, 0xde, 0x75, 0xb7, 0xaa, 0x00, 0xef, 0xe0, 0x66, 0x5f, 0x00, 0x2b, 0xac, 0xa3,
-	0x0b, 0xce, 0x50, 0xb3, 0x06, 0x7a, 0x50, 0x19, 0x2a, 0xcd, 0x9a, 0xed, 0x0e, 0x34, 0xb6, 0x35,
-	0x3f, 0xe5, 0xcb, 0xf7, 0x7c, 0xb6, 0xc2, 0x1a, 0x54, 0x49, 0xf6, 0x06, 0xa1, 0xa9, 0xd9, 0x88,
-	0x9e, 0xfb, 0x89, 0xa9, 0xd7, 0xec, 0xcc, 0x21, 0xee, 0x33, 0x67, 0xe2, 0x6e, 0x7e, 0xe8, 0xfe,
-	0x3b, 0x00, 0x00, 0xff, 0xff, 0x3d, 0x85, 0x92, 0x24, 0xb7, 0x01, 0x00, 0x00,
+	// 471 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x53, 0x4b, 0x4f, 0x5b, 0x4d,
+	0x0c, 0x85, 0xf0, 0xe5, 0xe5, 0xbc, 0x1c, 0x03, 0xe1, 0xf5, 0xa5, 0x0f, 0xb5, 0x52, 0x25, 0x16,
+	0x6c, 0xfa, 0x0b, 0x86, 0x1b, 0x93, 0x8c, 0x72, 0x33, 0x73, 0xf1, 0x4c, 0x02, 0x61, 0x33, 0x0a,
+	0x55, 0xaa, 0x76, 0xc1, 0x43, 0x14, 0x16, 0xfd, 0xab, 0xfd, 0x35, 0xd5, 0xdc, 0x06, 0xd1, 0x24,
+	0x48, 0x5d, 0x5d, 0xd9, 0x3e, 0xbe, 0xe7, 0x1c, 0xdb, 0x03, 0xb5, 0xf9, 0xed, 0xd3, 0xcd, 0x8f,
+	0x93, 0xfb, 0x87, 0xbb, 0xc7, 0x3b, 0xaa, 0xe4, 0x9f, 0xeb, 0xa7, 0xaf, 0xc7, 0xbf, 0x8a, 0x00,
+	0xee, 0xcb, 0xb7, 0xf9, 0xcd, 0xcc, 0xff, 0xbc, 0x9f, 0x53, 0x1d, 0x2a, 0x19, 0xf7, 0x58, 0x1c,
+	0x1b, 0xdc, 0xa0, 0x16, 0xd4, 0x9e, 0xa3, 0xc0, 0x09, 0x6e, 0x52, 0x0d, 0xca, 0x2e, 0x19, 0x18,
+	0x2b, 0x82, 0x05, 0x6a, 0x02, 0x2c, 0x82, 0x58, 0xdc, 0x8a, 0x71, 0xe2, 0x32, 0xa5, 0xd3, 0x54,
+	0xb3, 0xe0, 0x7f, 0xb4, 0x0d, 0xad, 0xcc, 0xf1, 0xb8, 0x67, 0xcd, 0x74, 0xe4, 0xa6, 0x2e, 0x24,
+	0x0a, 0x8b, 0xb4, 0x0f, 0x3b, 0x4b, 0x49, 0x33, 0x1d, 0x85, 0x3e, 0x1b, 0x2c, 0xd1, 0x7b, 0xe8,
+	0x2e, 0x55, 0xb4, 0x73, 0x63, 0x0e, 0x89, 0x70, 0x8f, 0x8d, 0xd7, 0x2a, 0xc5, 0x32, 0x7d, 0x84,
+	0x77, 0x4b, 0x10, 0x2f, 0xca, 0xb8, 0x33, 0x96, 0xbf, 0x51, 0x15, 0xea, 0x00, 0xad, 0xf0, 0x46,
+	0x7d, 0x55, 0x3a, 0x82, 0xbd, 0xd7, 0xa8, 0x63, 0x11, 0xd6, 0x7e, 0xbd, 0xca, 0x1e, 0x51, 0x35,
+	0xfa, 0x04, 0x1f, 0xfe, 0x25, 0x20, 0x02, 0xeb, 0x54, 0x82, 0xc2, 0xb9, 0x60, 0x83, 0xca, 0xb0,
+	0x75, 0x6e, 0x04, 0x9b, 0x6b, 0xe4, 0xa2, 0x3c, 0x87, 0x54, 0x8f, 0xb4, 0xc7, 0x16, 0x35, 0xa0,
+	0xca, 0x97, 0x9e, 0x8d, 0xd3, 0xd6, 0x20, 0xd2, 0x21, 0x74, 0x56, 0x0d, 0x38, 0xaf, 0xfc, 0xd8,
+	0x61, 0x3b, 0xae, 0x44, 0x94, 0xe9, 0x73, 0xc8, 0xc4, 0xda, 0x33, 0xa4, 0xdc, 0xed, 0x62, 0xe6,
+	0x21, 0x4b, 0x95, 0x36, 0x9e, 0x2f, 0x3d, 0x6e, 0xbf, 0xea, 0x96, 0x5d, 0x22, 0xf6, 0x02, 0x77,
+	0x62, 0x93, 0xf0, 0xc4, 0x26, 0xca, 0x6b, 0x6b, 0xc2, 0x38, 0xeb, 0x29, 0xcf, 0x0e, 0x77, 0xa3,
+	0xdc, 0x7e, 0xe6, 0xb0, 0x43, 0x5d, 0x38, 0x58, 0xeb, 0x16, 0xee, 0x6b, 0xe7, 0x65, 0x8a, 0x7b,
+	0x54, 0x85, 0xa2, 0xf3, 0x2c, 0x06, 0xf7, 0x09, 0xa1, 0xae, 0x4e, 0xc7, 0x8e, 0x83, 0x70, 0x66,
+	0xc5, 0xe3, 0x41, 0x5c, 0x71, 0xaa, 0xbc, 0xd7, 0x09, 0x07, 0x37, 0xb0, 0xe2, 0xc3, 0x84, 0x13,
+	0x6f, 0x05, 0x0f, 0x69, 0x17, 0xda, 0x7e, 0x20, 0xec, 0x06, 0x36, 0xed, 0x85, 0xe7, 0x43, 0x3a,
+	0xa2, 0x36, 0x34, 0x4e, 0x53, 0x6d, 0x5e, 0x52, 0xff, 0xe7, 0xea, 0x95, 0xc4, 0x79, 0xa6, 0xd3,
+	0xb0, 0x5c, 0xec, 0xc6, 0xc3, 0x52, 0xc6, 0x9a, 0x38, 0xf3, 0xc5, 0xa2, 0xf0, 0x0d, 0x11, 0x34,
+	0x5f, 0x92, 0x6e, 0x60, 0x2f, 0xf0, 0xed, 0xf1, 0x09, 0x34, 0xfe, 0xdc, 0xf6, 0x64, 0xf6, 0xf0,
+	0x7d, 0x76, 0xfb, 0x98, 0xeb, 0xd6, 0xfd, 0x91, 0xc2, 0x8d, 0x68, 0xf5, 0x6a, 0x98, 0xe1, 0x66,
+	0xcc, 0x5d, 0x0d, 0x33, 0x3b, 0xc4, 0xc2, 0x75, 0x29, 0x7f, 0x16, 0x9f, 0x7f, 0x0f, 0x00, 0x42,
+	0x24, 0xd9, 0x7a, 0x2c, 0x03, 0x00, 0x00,
 }
@@ -6,11 +6,13 @@
 Package protobuf is a generated protocol buffer package.
 
 It is generated from these files:
+
 	messages.proto
 	services.proto
 	enums.proto
 
 It has these top-level messages:
+
 	Message
 	EmptyMsg
 	ServiceInfo
@@ -45,12 +47,55 @@ It has these top-level messages:
 	CSPaillierProofRandomData
 	CSPaillierProofData
 	SessionKey
+	PseudonymsysRateLimitData
+	ExtensionMsg
+	PseudonymsysCARequest
+	SignedCertificateTimestamp
+	PseudonymsysCAStatus
+	HybridKEMInit
+	HybridKEMResponse
+	EncryptedMsg
+	BitProofRandomData
+	BitProofData
+	RangeProofRandomData
+	RangeProofData
+	Trapdoor
+	AttestationRequest
+	AttestationEvidence
+	PaillierPlaintextProofRandomData
+	PaillierPlaintextProofData
+	PseudonymsysNymEscrowData
+	RevocationSnapshot
+	RevocationSubscription
+	RevocationUpdate
+	GPSProofRandomData
+	NymRegistryRoot
+	NymInclusionProof
+	SternCommitment
+	SternProofRandomData
+	SternResponse
+	SternProofData
+	AbuseReport
+	LatticeShortVectorProof
+	ThresholdSchnorrRequest
+	PartiallyBlindSchnorrRequest
+	PartiallyBlindSchnorrCommitment
+	PartiallyBlindSchnorrResponse
+	AnonCredsIssueRequest
+	AnonCredsCredential
+	AnonCredsPresentationRequest
+	AnonCredsProofRandomData
+	AnonCredsProofData
+	PseudonymsysIssuedAttribute
+	PseudonymsysCredentialAttribute
+	RepeatedBigInt
 */
 package protobuf
 
 import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
+import google_protobuf "github.com/golang/protobuf/ptypes/any"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -94,9 +139,43 @@ type Message struct {
 	//	*Message_RepeatedPair
 	//	*Message_Eint
 	//	*Message_SessionKey
+	//	*Message_PseudonymsysRateLimitData
+	//	*Message_Extension
+	//	*Message_PseudonymsysCaRequest
+	//	*Message_PseudonymsysCaStatus
+	//	*Message_HybridKemInit
+	//	*Message_HybridKemResponse
+	//	*Message_Encrypted
+	//	*Message_RangeProofRandomData
+	//	*Message_RangeProofData
+	//	*Message_Trapdoor
+	//	*Message_AttestationRequest
+	//	*Message_AttestationEvidence
+	//	*Message_PaillierPlaintextProofRandomData
+	//	*Message_PaillierPlaintextProofData
+	//	*Message_PseudonymsysNymEscrowData
+	//	*Message_RevocationSubscription
+	//	*Message_RevocationUpdate
+	//	*Message_GpsProofRandomData
+	//	*Message_NymInclusionProof
+	//	*Message_SternProofRandomData
+	//	*Message_SternProofData
+	//	*Message_AbuseReport
+	//	*Message_LatticeShortVectorProof
+	//	*Message_ThresholdSchnorrRequest
+	//	*Message_PartiallyBlindSchnorrRequest
+	//	*Message_PartiallyBlindSchnorrCommitment
+	//	*Message_PartiallyBlindSchnorrResponse
+	//	*Message_AnonCredsIssueRequest
+	//	*Message_AnonCredsCredential
+	//	*Message_AnonCredsPresentationRequest
+	//	*Message_AnonCredsProofRandomData
+	//	*Message_AnonCredsProofData
+	//	*Message_RepeatedBigint
 	Content       isMessage_Content `protobuf_oneof:"content"`
 	ClientId      int32             `protobuf:"varint,28,opt,name=clientId" json:"clientId,omitempty"`
 	ProtocolError string            `protobuf:"bytes,29,opt,name=ProtocolError" json:"ProtocolError,omitempty"`
+	Curve         int32             `protobuf:"varint,35,opt,name=Curve" json:"Curve,omitempty"`
 }
 
 func (m *Message) Reset()                    { *m = Message{} }
@@ -186,6 +265,105 @@ type Message_Eint struct {
 type Message_SessionKey struct {
 	SessionKey *SessionKey `protobuf:"bytes,30,opt,name=SessionKey,oneof"`
 }
+type Message_PseudonymsysRateLimitData struct {
+	PseudonymsysRateLimitData *PseudonymsysRateLimitData `protobuf:"bytes,31,opt,name=pseudonymsys_rate_limit_data,json=pseudonymsysRateLimitData" json:"pseudonymsys_rate_limit_data,omitempty"`
+}
+type Message_Extension struct {
+	Extension *ExtensionMsg `protobuf:"bytes,32,opt,name=extension" json:"extension,omitempty"`
+}
+type Message_PseudonymsysCaRequest struct {
+	PseudonymsysCaRequest *PseudonymsysCARequest `protobuf:"bytes,33,opt,name=pseudonymsys_ca_request,json=pseudonymsysCaRequest" json:"pseudonymsys_ca_request,omitempty"`
+}
+type Message_PseudonymsysCaStatus struct {
+	PseudonymsysCaStatus *PseudonymsysCAStatus `protobuf:"bytes,34,opt,name=pseudonymsys_ca_status,json=pseudonymsysCaStatus" json:"pseudonymsys_ca_status,omitempty"`
+}
+type Message_HybridKemInit struct {
+	HybridKemInit *HybridKEMInit `protobuf:"bytes,36,opt,name=hybrid_kem_init,json=hybridKemInit" json:"hybrid_kem_init,omitempty"`
+}
+type Message_HybridKemResponse struct {
+	HybridKemResponse *HybridKEMResponse `protobuf:"bytes,37,opt,name=hybrid_kem_response,json=hybridKemResponse" json:"hybrid_kem_response,omitempty"`
+}
+type Message_Encrypted struct {
+	Encrypted *EncryptedMsg `protobuf:"bytes,38,opt,name=encrypted" json:"encrypted,omitempty"`
+}
+type Message_RangeProofRandomData struct {
+	RangeProofRandomData *RangeProofRandomData `protobuf:"bytes,39,opt,name=range_proof_random_data,json=rangeProofRandomData" json:"range_proof_random_data,omitempty"`
+}
+type Message_RangeProofData struct {
+	RangeProofData *RangeProofData `protobuf:"bytes,40,opt,name=range_proof_data,json=rangeProofData" json:"range_proof_data,omitempty"`
+}
+type Message_Trapdoor struct {
+	Trapdoor *Trapdoor `protobuf:"bytes,41,opt,name=trapdoor" json:"trapdoor,omitempty"`
+}
+type Message_AttestationRequest struct {
+	AttestationRequest *AttestationRequest `protobuf:"bytes,42,opt,name=attestation_request,json=attestationRequest" json:"attestation_request,omitempty"`
+}
+type Message_AttestationEvidence struct {
+	AttestationEvidence *AttestationEvidence `protobuf:"bytes,43,opt,name=attestation_evidence,json=attestationEvidence" json:"attestation_evidence,omitempty"`
+}
+type Message_PaillierPlaintextProofRandomData struct {
+	PaillierPlaintextProofRandomData *PaillierPlaintextProofRandomData `protobuf:"bytes,44,opt,name=paillier_plaintext_proof_random_data,json=paillierPlaintextProofRandomData" json:"paillier_plaintext_proof_random_data,omitempty"`
+}
+type Message_PaillierPlaintextProofData struct {
+	PaillierPlaintextProofData *PaillierPlaintextProofData `protobuf:"bytes,45,opt,name=paillier_plaintext_proof_data,json=paillierPlaintextProofData" json:"paillier_plaintext_proof_data,omitempty"`
+}
+type Message_PseudonymsysNymEscrowData struct {
+	PseudonymsysNymEscrowData *PseudonymsysNymEscrowData `protobuf:"bytes,46,opt,name=pseudonymsys_nym_escrow_data,json=pseudonymsysNymEscrowData" json:"pseudonymsys_nym_escrow_data,omitempty"`
+}
+type Message_RevocationSubscription struct {
+	RevocationSubscription *RevocationSubscription `protobuf:"bytes,47,opt,name=revocation_subscription,json=revocationSubscription" json:"revocation_subscription,omitempty"`
+}
+type Message_RevocationUpdate struct {
+	RevocationUpdate *RevocationUpdate `protobuf:"bytes,48,opt,name=revocation_update,json=revocationUpdate" json:"revocation_update,omitempty"`
+}
+type Message_GpsProofRandomData struct {
+	GpsProofRandomData *GPSProofRandomData `protobuf:"bytes,49,opt,name=gps_proof_random_data,json=gpsProofRandomData" json:"gps_proof_random_data,omitempty"`
+}
+type Message_NymInclusionProof struct {
+	NymInclusionProof *NymInclusionProof `protobuf:"bytes,50,opt,name=nym_inclusion_proof,json=nymInclusionProof" json:"nym_inclusion_proof,omitempty"`
+}
+type Message_SternProofRandomData struct {
+	SternProofRandomData *SternProofRandomData `protobuf:"bytes,51,opt,name=stern_proof_random_data,json=sternProofRandomData" json:"stern_proof_random_data,omitempty"`
+}
+type Message_SternProofData struct {
+	SternProofData *SternProofData `protobuf:"bytes,52,opt,name=stern_proof_data,json=sternProofData" json:"stern_proof_data,omitempty"`
+}
+type Message_AbuseReport struct {
+	AbuseReport *AbuseReport `protobuf:"bytes,53,opt,name=abuse_report,json=abuseReport" json:"abuse_report,omitempty"`
+}
+type Message_LatticeShortVectorProof struct {
+	LatticeShortVectorProof *LatticeShortVectorProof `protobuf:"bytes,54,opt,name=lattice_short_vector_proof,json=latticeShortVectorProof" json:"lattice_short_vector_proof,omitempty"`
+}
+type Message_ThresholdSchnorrRequest struct {
+	ThresholdSchnorrRequest *ThresholdSchnorrRequest `protobuf:"bytes,55,opt,name=threshold_schnorr_request,json=thresholdSchnorrRequest" json:"threshold_schnorr_request,omitempty"`
+}
+type Message_PartiallyBlindSchnorrRequest struct {
+	PartiallyBlindSchnorrRequest *PartiallyBlindSchnorrRequest `protobuf:"bytes,56,opt,name=partially_blind_schnorr_request,json=partiallyBlindSchnorrRequest" json:"partially_blind_schnorr_request,omitempty"`
+}
+type Message_PartiallyBlindSchnorrCommitment struct {
+	PartiallyBlindSchnorrCommitment *PartiallyBlindSchnorrCommitment `protobuf:"bytes,57,opt,name=partially_blind_schnorr_commitment,json=partiallyBlindSchnorrCommitment" json:"partially_blind_schnorr_commitment,omitempty"`
+}
+type Message_PartiallyBlindSchnorrResponse struct {
+	PartiallyBlindSchnorrResponse *PartiallyBlindSchnorrResponse `protobuf:"bytes,58,opt,name=partially_blind_schnorr_response,json=partiallyBlindSchnorrResponse" json:"partially_blind_schnorr_response,omitempty"`
+}
+type Message_AnonCredsIssueRequest struct {
+	AnonCredsIssueRequest *AnonCredsIssueRequest `protobuf:"bytes,59,opt,name=anon_creds_issue_request,json=anonCredsIssueRequest" json:"anon_creds_issue_request,omitempty"`
+}
+type Message_AnonCredsCredential struct {
+	AnonCredsCredential *AnonCredsCredential `protobuf:"bytes,60,opt,name=anon_creds_credential,json=anonCredsCredential" json:"anon_creds_credential,omitempty"`
+}
+type Message_AnonCredsPresentationRequest struct {
+	AnonCredsPresentationRequest *AnonCredsPresentationRequest `protobuf:"bytes,61,opt,name=anon_creds_presentation_request,json=anonCredsPresentationRequest" json:"anon_creds_presentation_request,omitempty"`
+}
+type Message_AnonCredsProofRandomData struct {
+	AnonCredsProofRandomData *AnonCredsProofRandomData `protobuf:"bytes,62,opt,name=anon_creds_proof_random_data,json=anonCredsProofRandomData" json:"anon_creds_proof_random_data,omitempty"`
+}
+type Message_AnonCredsProofData struct {
+	AnonCredsProofData *AnonCredsProofData `protobuf:"bytes,63,opt,name=anon_creds_proof_data,json=anonCredsProofData" json:"anon_creds_proof_data,omitempty"`
+}
+type Message_RepeatedBigint struct {
+	RepeatedBigint *RepeatedBigInt `protobuf:"bytes,64,opt,name=repeated_bigint,json=repeatedBigint" json:"repeated_bigint,omitempty"`
+}
 
 func (*Message_Empty) isMessage_Content()                                {}
 func (*Message_Bigint) isMessage_Content()                               {}
@@ -213,6 +391,39 @@ func (*Message_RepeatedInt) isMessage_Content()                          {}
 func (*Message_RepeatedPair) isMessage_Content()                         {}
 func (*Message_Eint) isMessage_Content()                                 {}
 func (*Message_SessionKey) isMessage_Content()                           {}
+func (*Message_PseudonymsysRateLimitData) isMessage_Content()            {}
+func (*Message_Extension) isMessage_Content()                            {}
+func (*Message_PseudonymsysCaRequest) isMessage_Content()                {}
+func (*Message_PseudonymsysCaStatus) isMessage_Content()                 {}
+func (*Message_HybridKemInit) isMessage_Content()                        {}
+func (*Message_HybridKemResponse) isMessage_Content()                    {}
+func (*Message_Encrypted) isMessage_Content()                            {}
+func (*Message_RangeProofRandomData) isMessage_Content()                 {}
+func (*Message_RangeProofData) isMessage_Content()                       {}
+func (*Message_Trapdoor) isMessage_Content()                             {}
+func (*Message_AttestationRequest) isMessage_Content()                   {}
+func (*Message_AttestationEvidence) isMessage_Content()                  {}
+func (*Message_PaillierPlaintextProofRandomData) isMessage_Content()     {}
+func (*Message_PaillierPlaintextProofData) isMessage_Content()           {}
+func (*Message_PseudonymsysNymEscrowData) isMessage_Content()            {}
+func (*Message_RevocationSubscription) isMessage_Content()               {}
+func (*Message_RevocationUpdate) isMessage_Content()                     {}
+func (*Message_GpsProofRandomData) isMessage_Content()                   {}
+func (*Message_NymInclusionProof) isMessage_Content()                    {}
+func (*Message_SternProofRandomData) isMessage_Content()                 {}
+func (*Message_SternProofData) isMessage_Content()                       {}
+func (*Message_AbuseReport) isMessage_Content()                          {}
+func (*Message_LatticeShortVectorProof) isMessage_Content()              {}
+func (*Message_ThresholdSchnorrRequest) isMessage_Content()              {}
+func (*Message_PartiallyBlindSchnorrRequest) isMessage_Content()         {}
+func (*Message_PartiallyBlindSchnorrCommitment) isMessage_Content()      {}
+func (*Message_PartiallyBlindSchnorrResponse) isMessage_Content()        {}
+func (*Message_AnonCredsIssueRequest) isMessage_Content()                {}
+func (*Message_AnonCredsCredential) isMessage_Content()                  {}
+func (*Message_AnonCredsPresentationRequest) isMessage_Content()         {}
+func (*Message_AnonCredsProofRandomData) isMessage_Content()             {}
+func (*Message_AnonCredsProofData) isMessage_Content()                   {}
+func (*Message_RepeatedBigint) isMessage_Content()                       {}
 
 func (m *Message) GetContent() isMessage_Content {
 	if m != nil {
@@ -417,6 +628,237 @@ func (m *Message) GetSessionKey() *SessionKey {
 	return nil
 }
 
+func (m *Message) GetPseudonymsysRateLimitData() *PseudonymsysRateLimitData {
+	if x, ok := m.GetContent().(*Message_PseudonymsysRateLimitData); ok {
+		return x.PseudonymsysRateLimitData
+	}
+	return nil
+}
+
+func (m *Message) GetExtension() *ExtensionMsg {
+	if x, ok := m.GetContent().(*Message_Extension); ok {
+		return x.Extension
+	}
+	return nil
+}
+
+func (m *Message) GetPseudonymsysCaRequest() *PseudonymsysCARequest {
+	if x, ok := m.GetContent().(*Message_PseudonymsysCaRequest); ok {
+		return x.PseudonymsysCaRequest
+	}
+	return nil
+}
+
+func (m *Message) GetPseudonymsysCaStatus() *PseudonymsysCAStatus {
+	if x, ok := m.GetContent().(*Message_PseudonymsysCaStatus); ok {
+		return x.PseudonymsysCaStatus
+	}
+	return nil
+}
+
+func (m *Message) GetHybridKemInit() *HybridKEMInit {
+	if x, ok := m.GetContent().(*Message_HybridKemInit); ok {
+		return x.HybridKemInit
+	}
+	return nil
+}
+
+func (m *Message) GetHybridKemResponse() *HybridKEMResponse {
+	if x, ok := m.GetContent().(*Message_HybridKemResponse); ok {
+		return x.HybridKemResponse
+	}
+	return nil
+}
+
+func (m *Message) GetEncrypted() *EncryptedMsg {
+	if x, ok := m.GetContent().(*Message_Encrypted); ok {
+		return x.Encrypted
+	}
+	return nil
+}
+
+func (m *Message) GetRangeProofRandomData() *RangeProofRandomData {
+	if x, ok := m.GetContent().(*Message_RangeProofRandomData); ok {
+		return x.RangeProofRandomData
+	}
+	return nil
+}
+
+func (m *Message) GetRangeProofData() *RangeProofData {
+	if x, ok := m.GetContent().(*Message_RangeProofData); ok {
+		return x.RangeProofData
+	}
+	return nil
+}
+
+func (m *Message) GetTrapdoor() *Trapdoor {
+	if x, ok := m.GetContent().(*Message_Trapdoor); ok {
+		return x.Trapdoor
+	}
+	return nil
+}
+
+func (m *Message) GetAttestationRequest() *AttestationRequest {
+	if x, ok := m.GetContent().(*Message_AttestationRequest); ok {
+		return x.AttestationRequest
+	}
+	return nil
+}
+
+func (m *Message) GetAttestationEvidence() *AttestationEvidence {
+	if x, ok := m.GetContent().(*Message_AttestationEvidence); ok {
+		return x.AttestationEvidence
+	}
+	return nil
+}
+
+func (m *Message) GetPaillierPlaintextProofRandomData() *PaillierPlaintextProofRandomData {
+	if x, ok := m.GetContent().(*Message_PaillierPlaintextProofRandomData); ok {
+		return x.PaillierPlaintextProofRandomData
+	}
+	return nil
+}
+
+func (m *Message) GetPaillierPlaintextProofData() *PaillierPlaintextProofData {
+	if x, ok := m.GetContent().(*Message_PaillierPlaintextProofData); ok {
+		return x.PaillierPlaintextProofData
+	}
+	return nil
+}
+
+func (m *Message) GetPseudonymsysNymEscrowData() *PseudonymsysNymEscrowData {
+	if x, ok := m.GetContent().(*Message_PseudonymsysNymEscrowData); ok {
+		return x.PseudonymsysNymEscrowData
+	}
+	return nil
+}
+
+func (m *Message) GetRevocationSubscription() *RevocationSubscription {
+	if x, ok := m.GetContent().(*Message_RevocationSubscription); ok {
+		return x.RevocationSubscription
+	}
+	return nil
+}
+
+func (m *Message) GetRevocationUpdate() *RevocationUpdate {
+	if x, ok := m.GetContent().(*Message_RevocationUpdate); ok {
+		return x.RevocationUpdate
+	}
+	return nil
+}
+
+func (m *Message) GetGpsProofRandomData() *GPSProofRandomData {
+	if x, ok := m.GetContent().(*Message_GpsProofRandomData); ok {
+		return x.GpsProofRandomData
+	}
+	return nil
+}
+
+func (m *Message) GetNymInclusionProof() *NymInclusionProof {
+	if x, ok := m.GetContent().(*Message_NymInclusionProof); ok {
+		return x.NymInclusionProof
+	}
+	return nil
+}
+
+func (m *Message) GetSternProofRandomData() *SternProofRandomData {
+	if x, ok := m.GetContent().(*Message_SternProofRandomData); ok {
+		return x.SternProofRandomData
+	}
+	return nil
+}
+
+func (m *Message) GetSternProofData() *SternProofData {
+	if x, ok := m.GetContent().(*Message_SternProofData); ok {
+		return x.SternProofData
+	}
+	return nil
+}
+
+func (m *Message) GetAbuseReport() *AbuseReport {
+	if x, ok := m.GetContent().(*Message_AbuseReport); ok {
+		return x.AbuseReport
+	}
+	return nil
+}
+
+func (m *Message) GetLatticeShortVectorProof() *LatticeShortVectorProof {
+	if x, ok := m.GetContent().(*Message_LatticeShortVectorProof); ok {
+		return x.LatticeShortVectorProof
+	}
+	return nil
+}
+
+func (m *Message) GetThresholdSchnorrRequest() *ThresholdSchnorrRequest {
+	if x, ok := m.GetContent().(*Message_ThresholdSchnorrRequest); ok {
+		return x.ThresholdSchnorrRequest
+	}
+	return nil
+}
+
+func (m *Message) GetPartiallyBlindSchnorrRequest() *PartiallyBlindSchnorrRequest {
+	if x, ok := m.GetContent().(*Message_PartiallyBlindSchnorrRequest); ok {
+		return x.PartiallyBlindSchnorrRequest
+	}
+	return nil
+}
+
+func (m *Message) GetPartiallyBlindSchnorrCommitment() *PartiallyBlindSchnorrCommitment {
+	if x, ok := m.GetContent().(*Message_PartiallyBlindSchnorrCommitment); ok {
+		return x.PartiallyBlindSchnorrCommitment
+	}
+	return nil
+}
+
+func (m *Message) GetPartiallyBlindSchnorrResponse() *PartiallyBlindSchnorrResponse {
+	if x, ok := m.GetContent().(*Message_PartiallyBlindSchnorrResponse); ok {
+		return x.PartiallyBlindSchnorrResponse
+	}
+	return nil
+}
+
+func (m *Message) GetAnonCredsIssueRequest() *AnonCredsIssueRequest {
+	if x, ok := m.GetContent().(*Message_AnonCredsIssueRequest); ok {
+		return x.AnonCredsIssueRequest
+	}
+	return nil
+}
+
+func (m *Message) GetAnonCredsCredential() *AnonCredsCredential {
+	if x, ok := m.GetContent().(*Message_AnonCredsCredential); ok {
+		return x.AnonCredsCredential
+	}
+	return nil
+}
+
+func (m *Message) GetAnonCredsPresentationRequest() *AnonCredsPresentationRequest {
+	if x, ok := m.GetContent().(*Message_AnonCredsPresentationRequest); ok {
+		return x.AnonCredsPresentationRequest
+	}
+	return nil
+}
+
+func (m *Message) GetAnonCredsProofRandomData() *AnonCredsProofRandomData {
+	if x, ok := m.GetContent().(*Message_AnonCredsProofRandomData); ok {
+		return x.AnonCredsProofRandomData
+	}
+	return nil
+}
+
+func (m *Message) GetAnonCredsProofData() *AnonCredsProofData {
+	if x, ok := m.GetContent().(*Message_AnonCredsProofData); ok {
+		return x.AnonCredsProofData
+	}
+	return nil
+}
+
+func (m *Message) GetRepeatedBigint() *RepeatedBigInt {
+	if x, ok := m.GetContent().(*Message_RepeatedBigint); ok {
+		return x.RepeatedBigint
+	}
+	return nil
+}
+
 func (m *Message) GetClientId() int32 {
 	if m != nil {
 		return m.ClientId
@@ -431,6 +873,13 @@ func (m *Message) GetProtocolError() string {
 	return ""
 }
 
+func (m *Message) GetCurve() int32 {
+	if m != nil {
+		return m.Curve
+	}
+	return 0
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Message) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _Message_OneofMarshaler, _Message_OneofUnmarshaler, _Message_OneofSizer, []interface{}{
@@ -460,6 +909,39 @@ func (*Message) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error
 		(*Message_RepeatedPair)(nil),
 		(*Message_Eint)(nil),
 		(*Message_SessionKey)(nil),
+		(*Message_PseudonymsysRateLimitData)(nil),
+		(*Message_Extension)(nil),
+		(*Message_PseudonymsysCaRequest)(nil),
+		(*Message_PseudonymsysCaStatus)(nil),
+		(*Message_HybridKemInit)(nil),
+		(*Message_HybridKemResponse)(nil),
+		(*Message_Encrypted)(nil),
+		(*Message_RangeProofRandomData)(nil),
+		(*Message_RangeProofData)(nil),
+		(*Message_Trapdoor)(nil),
+		(*Message_AttestationRequest)(nil),
+		(*Message_AttestationEvidence)(nil),
+		(*Message_PaillierPlaintextProofRandomData)(nil),
+		(*Message_PaillierPlaintextProofData)(nil),
+		(*Message_PseudonymsysNymEscrowData)(nil),
+		(*Message_RevocationSubscription)(nil),
+		(*Message_RevocationUpdate)(nil),
+		(*Message_GpsProofRandomData)(nil),
+		(*Message_NymInclusionProof)(nil),
+		(*Message_SternProofRandomData)(nil),
+		(*Message_SternProofData)(nil),
+		(*Message_AbuseReport)(nil),
+		(*Message_LatticeShortVectorProof)(nil),
+		(*Message_ThresholdSchnorrRequest)(nil),
+		(*Message_PartiallyBlindSchnorrRequest)(nil),
+		(*Message_PartiallyBlindSchnorrCommitment)(nil),
+		(*Message_PartiallyBlindSchnorrResponse)(nil),
+		(*Message_AnonCredsIssueRequest)(nil),
+		(*Message_AnonCredsCredential)(nil),
+		(*Message_AnonCredsPresentationRequest)(nil),
+		(*Message_AnonCredsProofRandomData)(nil),
+		(*Message_AnonCredsProofData)(nil),
+		(*Message_RepeatedBigint)(nil),
 	}
 }
 
@@ -595,6 +1077,171 @@ func _Message_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.SessionKey); err != nil {
 			return err
 		}
+	case *Message_PseudonymsysRateLimitData:
+		b.EncodeVarint(31<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.PseudonymsysRateLimitData); err != nil {
+			return err
+		}
+	case *Message_Extension:
+		b.EncodeVarint(32<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Extension); err != nil {
+			return err
+		}
+	case *Message_PseudonymsysCaRequest:
+		b.EncodeVarint(33<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.PseudonymsysCaRequest); err != nil {
+			return err
+		}
+	case *Message_PseudonymsysCaStatus:
+		b.EncodeVarint(34<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.PseudonymsysCaStatus); err != nil {
+			return err
+		}
+	case *Message_HybridKemInit:
+		b.EncodeVarint(36<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.HybridKemInit); err != nil {
+			return err
+		}
+	case *Message_HybridKemResponse:
+		b.EncodeVarint(37<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.HybridKemResponse); err != nil {
+			return err
+		}
+	case *Message_Encrypted:
+		b.EncodeVarint(38<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Encrypted); err != nil {
+			return err
+		}
+	case *Message_RangeProofRandomData:
+		b.EncodeVarint(39<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.RangeProofRandomData); err != nil {
+			return err
+		}
+	case *Message_RangeProofData:
+		b.EncodeVarint(40<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.RangeProofData); err != nil {
+			return err
+		}
+	case *Message_Trapdoor:
+		b.EncodeVarint(41<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Trapdoor); err != nil {
+			return err
+		}
+	case *Message_AttestationRequest:
+		b.EncodeVarint(42<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.AttestationRequest); err != nil {
+			return err
+		}
+	case *Message_AttestationEvidence:
+		b.EncodeVarint(43<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.AttestationEvidence); err != nil {
+			return err
+		}
+	case *Message_PaillierPlaintextProofRandomData:
+		b.EncodeVarint(44<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.PaillierPlaintextProofRandomData); err != nil {
+			return err
+		}
+	case *Message_PaillierPlaintextProofData:
+		b.EncodeVarint(45<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.PaillierPlaintextProofData); err != nil {
+			return err
+		}
+	case *Message_PseudonymsysNymEscrowData:
+		b.EncodeVarint(46<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.PseudonymsysNymEscrowData); err != nil {
+			return err
+		}
+	case *Message_RevocationSubscription:
+		b.EncodeVarint(47<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.RevocationSubscription); err != nil {
+			return err
+		}
+	case *Message_RevocationUpdate:
+		b.EncodeVarint(48<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.RevocationUpdate); err != nil {
+			return err
+		}
+	case *Message_GpsProofRandomData:
+		b.EncodeVarint(49<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.GpsProofRandomData); err != nil {
+			return err
+		}
+	case *Message_NymInclusionProof:
+		b.EncodeVarint(50<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.NymInclusionProof); err != nil {
+			return err
+		}
+	case *Message_SternProofRandomData:
+		b.EncodeVarint(51<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.SternProofRandomData); err != nil {
+			return err
+		}
+	case *Message_SternProofData:
+		b.EncodeVarint(52<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.SternProofData); err != nil {
+			return err
+		}
+	case *Message_AbuseReport:
+		b.EncodeVarint(53<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.AbuseReport); err != nil {
+			return err
+		}
+	case *Message_LatticeShortVectorProof:
+		b.EncodeVarint(54<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.LatticeShortVectorProof); err != nil {
+			return err
+		}
+	case *Message_ThresholdSchnorrRequest:
+		b.EncodeVarint(55<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.ThresholdSchnorrRequest); err != nil {
+			return err
+		}
+	case *Message_PartiallyBlindSchnorrRequest:
+		b.EncodeVarint(56<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.PartiallyBlindSchnorrRequest); err != nil {
+			return err
+		}
+	case *Message_PartiallyBlindSchnorrCommitment:
+		b.EncodeVarint(57<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.PartiallyBlindSchnorrCommitment); err != nil {
+			return err
+		}
+	case *Message_PartiallyBlindSchnorrResponse:
+		b.EncodeVarint(58<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.PartiallyBlindSchnorrResponse); err != nil {
+			return err
+		}
+	case *Message_AnonCredsIssueRequest:
+		b.EncodeVarint(59<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.AnonCredsIssueRequest); err != nil {
+			return err
+		}
+	case *Message_AnonCredsCredential:
+		b.EncodeVarint(60<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.AnonCredsCredential); err != nil {
+			return err
+		}
+	case *Message_AnonCredsPresentationRequest:
+		b.EncodeVarint(61<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.AnonCredsPresentationRequest); err != nil {
+			return err
+		}
+	case *Message_AnonCredsProofRandomData:
+		b.EncodeVarint(62<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.AnonCredsProofRandomData); err != nil {
+			return err
+		}
+	case *Message_AnonCredsProofData:
+		b.EncodeVarint(63<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.AnonCredsProofData); err != nil {
+			return err
+		}
+	case *Message_RepeatedBigint:
+		b.EncodeVarint(64<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.RepeatedBigint); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("Message.Content has unexpected type %T", x)
@@ -812,24 +1459,288 @@ func _Message_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer
 		err := b.DecodeMessage(msg)
 		m.Content = &Message_SessionKey{msg}
 		return true, err
-	default:
-		return false, nil
-	}
-}
-
-func _Message_OneofSizer(msg proto.Message) (n int) {
-	m := msg.(*Message)
-	// content
-	switch x := m.Content.(type) {
-	case *Message_Empty:
-		s := proto.Size(x.Empty)
-		n += proto.SizeVarint(3<<3 | proto.WireBytes)
-		n += proto.SizeVarint(uint64(s))
-		n += s
-	case *Message_Bigint:
-		s := proto.Size(x.Bigint)
-		n += proto.SizeVarint(4<<3 | proto.WireBytes)
-		n += proto.SizeVarint(uint64(s))
+	case 31: // content.pseudonymsys_rate_limit_data
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(PseudonymsysRateLimitData)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_PseudonymsysRateLimitData{msg}
+		return true, err
+	case 32: // content.extension
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ExtensionMsg)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_Extension{msg}
+		return true, err
+	case 33: // content.pseudonymsys_ca_request
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(PseudonymsysCARequest)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_PseudonymsysCaRequest{msg}
+		return true, err
+	case 34: // content.pseudonymsys_ca_status
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(PseudonymsysCAStatus)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_PseudonymsysCaStatus{msg}
+		return true, err
+	case 36: // content.hybrid_kem_init
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(HybridKEMInit)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_HybridKemInit{msg}
+		return true, err
+	case 37: // content.hybrid_kem_response
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(HybridKEMResponse)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_HybridKemResponse{msg}
+		return true, err
+	case 38: // content.encrypted
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(EncryptedMsg)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_Encrypted{msg}
+		return true, err
+	case 39: // content.range_proof_random_data
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(RangeProofRandomData)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_RangeProofRandomData{msg}
+		return true, err
+	case 40: // content.range_proof_data
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(RangeProofData)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_RangeProofData{msg}
+		return true, err
+	case 41: // content.trapdoor
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Trapdoor)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_Trapdoor{msg}
+		return true, err
+	case 42: // content.attestation_request
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(AttestationRequest)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_AttestationRequest{msg}
+		return true, err
+	case 43: // content.attestation_evidence
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(AttestationEvidence)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_AttestationEvidence{msg}
+		return true, err
+	case 44: // content.paillier_plaintext_proof_random_data
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(PaillierPlaintextProofRandomData)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_PaillierPlaintextProofRandomData{msg}
+		return true, err
+	case 45: // content.paillier_plaintext_proof_data
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(PaillierPlaintextProofData)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_PaillierPlaintextProofData{msg}
+		return true, err
+	case 46: // content.pseudonymsys_nym_escrow_data
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(PseudonymsysNymEscrowData)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_PseudonymsysNymEscrowData{msg}
+		return true, err
+	case 47: // content.revocation_subscription
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(RevocationSubscription)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_RevocationSubscription{msg}
+		return true, err
+	case 48: // content.revocation_update
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(RevocationUpdate)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_RevocationUpdate{msg}
+		return true, err
+	case 49: // content.gps_proof_random_data
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(GPSProofRandomData)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_GpsProofRandomData{msg}
+		return true, err
+	case 50: // content.nym_inclusion_proof
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(NymInclusionProof)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_NymInclusionProof{msg}
+		return true, err
+	case 51: // content.stern_proof_random_data
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SternProofRandomData)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_SternProofRandomData{msg}
+		return true, err
+	case 52: // content.stern_proof_data
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SternProofData)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_SternProofData{msg}
+		return true, err
+	case 53: // content.abuse_report
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(AbuseReport)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_AbuseReport{msg}
+		return true, err
+	case 54: // content.lattice_short_vector_proof
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(LatticeShortVectorProof)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_LatticeShortVectorProof{msg}
+		return true, err
+	case 55: // content.threshold_schnorr_request
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ThresholdSchnorrRequest)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_ThresholdSchnorrRequest{msg}
+		return true, err
+	case 56: // content.partially_blind_schnorr_request
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(PartiallyBlindSchnorrRequest)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_PartiallyBlindSchnorrRequest{msg}
+		return true, err
+	case 57: // content.partially_blind_schnorr_commitment
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(PartiallyBlindSchnorrCommitment)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_PartiallyBlindSchnorrCommitment{msg}
+		return true, err
+	case 58: // content.partially_blind_schnorr_response
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(PartiallyBlindSchnorrResponse)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_PartiallyBlindSchnorrResponse{msg}
+		return true, err
+	case 59: // content.anon_creds_issue_request
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(AnonCredsIssueRequest)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_AnonCredsIssueRequest{msg}
+		return true, err
+	case 60: // content.anon_creds_credential
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(AnonCredsCredential)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_AnonCredsCredential{msg}
+		return true, err
+	case 61: // content.anon_creds_presentation_request
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(AnonCredsPresentationRequest)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_AnonCredsPresentationRequest{msg}
+		return true, err
+	case 62: // content.anon_creds_proof_random_data
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(AnonCredsProofRandomData)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_AnonCredsProofRandomData{msg}
+		return true, err
+	case 63: // content.anon_creds_proof_data
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(AnonCredsProofData)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_AnonCredsProofData{msg}
+		return true, err
+	case 64: // content.repeated_bigint
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(RepeatedBigInt)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_RepeatedBigint{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _Message_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*Message)
+	// content
+	switch x := m.Content.(type) {
+	case *Message_Empty:
+		s := proto.Size(x.Empty)
+		n += proto.SizeVarint(3<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_Bigint:
+		s := proto.Size(x.Bigint)
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
 		n += s
 	case *Message_EcGroupElement:
 		s := proto.Size(x.EcGroupElement)
@@ -949,6 +1860,171 @@ func _Message_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(30<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *Message_PseudonymsysRateLimitData:
+		s := proto.Size(x.PseudonymsysRateLimitData)
+		n += proto.SizeVarint(31<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_Extension:
+		s := proto.Size(x.Extension)
+		n += proto.SizeVarint(32<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_PseudonymsysCaRequest:
+		s := proto.Size(x.PseudonymsysCaRequest)
+		n += proto.SizeVarint(33<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_PseudonymsysCaStatus:
+		s := proto.Size(x.PseudonymsysCaStatus)
+		n += proto.SizeVarint(34<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_HybridKemInit:
+		s := proto.Size(x.HybridKemInit)
+		n += proto.SizeVarint(36<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_HybridKemResponse:
+		s := proto.Size(x.HybridKemResponse)
+		n += proto.SizeVarint(37<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_Encrypted:
+		s := proto.Size(x.Encrypted)
+		n += proto.SizeVarint(38<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_RangeProofRandomData:
+		s := proto.Size(x.RangeProofRandomData)
+		n += proto.SizeVarint(39<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_RangeProofData:
+		s := proto.Size(x.RangeProofData)
+		n += proto.SizeVarint(40<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_Trapdoor:
+		s := proto.Size(x.Trapdoor)
+		n += proto.SizeVarint(41<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_AttestationRequest:
+		s := proto.Size(x.AttestationRequest)
+		n += proto.SizeVarint(42<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_AttestationEvidence:
+		s := proto.Size(x.AttestationEvidence)
+		n += proto.SizeVarint(43<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_PaillierPlaintextProofRandomData:
+		s := proto.Size(x.PaillierPlaintextProofRandomData)
+		n += proto.SizeVarint(44<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_PaillierPlaintextProofData:
+		s := proto.Size(x.PaillierPlaintextProofData)
+		n += proto.SizeVarint(45<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_PseudonymsysNymEscrowData:
+		s := proto.Size(x.PseudonymsysNymEscrowData)
+		n += proto.SizeVarint(46<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_RevocationSubscription:
+		s := proto.Size(x.RevocationSubscription)
+		n += proto.SizeVarint(47<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_RevocationUpdate:
+		s := proto.Size(x.RevocationUpdate)
+		n += proto.SizeVarint(48<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_GpsProofRandomData:
+		s := proto.Size(x.GpsProofRandomData)
+		n += proto.SizeVarint(49<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_NymInclusionProof:
+		s := proto.Size(x.NymInclusionProof)
+		n += proto.SizeVarint(50<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_SternProofRandomData:
+		s := proto.Size(x.SternProofRandomData)
+		n += proto.SizeVarint(51<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_SternProofData:
+		s := proto.Size(x.SternProofData)
+		n += proto.SizeVarint(52<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_AbuseReport:
+		s := proto.Size(x.AbuseReport)
+		n += proto.SizeVarint(53<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_LatticeShortVectorProof:
+		s := proto.Size(x.LatticeShortVectorProof)
+		n += proto.SizeVarint(54<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_ThresholdSchnorrRequest:
+		s := proto.Size(x.ThresholdSchnorrRequest)
+		n += proto.SizeVarint(55<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_PartiallyBlindSchnorrRequest:
+		s := proto.Size(x.PartiallyBlindSchnorrRequest)
+		n += proto.SizeVarint(56<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_PartiallyBlindSchnorrCommitment:
+		s := proto.Size(x.PartiallyBlindSchnorrCommitment)
+		n += proto.SizeVarint(57<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_PartiallyBlindSchnorrResponse:
+		s := proto.Size(x.PartiallyBlindSchnorrResponse)
+		n += proto.SizeVarint(58<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_AnonCredsIssueRequest:
+		s := proto.Size(x.AnonCredsIssueRequest)
+		n += proto.SizeVarint(59<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_AnonCredsCredential:
+		s := proto.Size(x.AnonCredsCredential)
+		n += proto.SizeVarint(60<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_AnonCredsPresentationRequest:
+		s := proto.Size(x.AnonCredsPresentationRequest)
+		n += proto.SizeVarint(61<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_AnonCredsProofRandomData:
+		s := proto.Size(x.AnonCredsProofRandomData)
+		n += proto.SizeVarint(62<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_AnonCredsProofData:
+		s := proto.Size(x.AnonCredsProofData)
+		n += proto.SizeVarint(63<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_RepeatedBigint:
+		s := proto.Size(x.RepeatedBigint)
+		n += proto.SizeVarint(64<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -1229,14 +2305,15 @@ func (m *SchnorrProofData) GetTrapdoor() []byte {
 }
 
 type PseudonymsysNymGenProofRandomData struct {
-	X1 []byte `protobuf:"bytes,1,opt,name=X1,proto3" json:"X1,omitempty"`
-	A1 []byte `protobuf:"bytes,2,opt,name=A1,proto3" json:"A1,omitempty"`
-	B1 []byte `protobuf:"bytes,3,opt,name=B1,proto3" json:"B1,omitempty"`
-	X2 []byte `protobuf:"bytes,4,opt,name=X2,proto3" json:"X2,omitempty"`
-	A2 []byte `protobuf:"bytes,5,opt,name=A2,proto3" json:"A2,omitempty"`
-	B2 []byte `protobuf:"bytes,6,opt,name=B2,proto3" json:"B2,omitempty"`
-	R  []byte `protobuf:"bytes,7,opt,name=R,proto3" json:"R,omitempty"`
-	S  []byte `protobuf:"bytes,8,opt,name=S,proto3" json:"S,omitempty"`
+	X1        []byte `protobuf:"bytes,1,opt,name=X1,proto3" json:"X1,omitempty"`
+	A1        []byte `protobuf:"bytes,2,opt,name=A1,proto3" json:"A1,omitempty"`
+	B1        []byte `protobuf:"bytes,3,opt,name=B1,proto3" json:"B1,omitempty"`
+	X2        []byte `protobuf:"bytes,4,opt,name=X2,proto3" json:"X2,omitempty"`
+	A2        []byte `protobuf:"bytes,5,opt,name=A2,proto3" json:"A2,omitempty"`
+	B2        []byte `protobuf:"bytes,6,opt,name=B2,proto3" json:"B2,omitempty"`
+	R         []byte `protobuf:"bytes,7,opt,name=R,proto3" json:"R,omitempty"`
+	S         []byte `protobuf:"bytes,8,opt,name=S,proto3" json:"S,omitempty"`
+	Algorithm int32  `protobuf:"varint,9,opt,name=Algorithm" json:"Algorithm,omitempty"`
 }
 
 func (m *PseudonymsysNymGenProofRandomData) Reset()         { *m = PseudonymsysNymGenProofRandomData{} }
@@ -1302,6 +2379,13 @@ func (m *PseudonymsysNymGenProofRandomData) GetS() []byte {
 	return nil
 }
 
+func (m *PseudonymsysNymGenProofRandomData) GetAlgorithm() int32 {
+	if m != nil {
+		return m.Algorithm
+	}
+	return 0
+}
+
 type PseudonymsysNymGenProofRandomDataEC struct {
 	X1 *ECGroupElement `protobuf:"bytes,1,opt,name=X1" json:"X1,omitempty"`
 	A1 *ECGroupElement `protobuf:"bytes,2,opt,name=A1" json:"A1,omitempty"`
@@ -1377,10 +2461,12 @@ func (m *PseudonymsysNymGenProofRandomDataEC) GetS() []byte {
 }
 
 type PseudonymsysCACertificate struct {
-	BlindedA []byte `protobuf:"bytes,1,opt,name=BlindedA,proto3" json:"BlindedA,omitempty"`
-	BlindedB []byte `protobuf:"bytes,2,opt,name=BlindedB,proto3" json:"BlindedB,omitempty"`
-	R        []byte `protobuf:"bytes,3,opt,name=R,proto3" json:"R,omitempty"`
-	S        []byte `protobuf:"bytes,4,opt,name=S,proto3" json:"S,omitempty"`
+	BlindedA  []byte                      `protobuf:"bytes,1,opt,name=BlindedA,proto3" json:"BlindedA,omitempty"`
+	BlindedB  []byte                      `protobuf:"bytes,2,opt,name=BlindedB,proto3" json:"BlindedB,omitempty"`
+	R         []byte                      `protobuf:"bytes,3,opt,name=R,proto3" json:"R,omitempty"`
+	S         []byte                      `protobuf:"bytes,4,opt,name=S,proto3" json:"S,omitempty"`
+	Algorithm int32                       `protobuf:"varint,5,opt,name=Algorithm" json:"Algorithm,omitempty"`
+	SCT       *SignedCertificateTimestamp `protobuf:"bytes,6,opt,name=SCT" json:"SCT,omitempty"`
 }
 
 func (m *PseudonymsysCACertificate) Reset()                    { *m = PseudonymsysCACertificate{} }
@@ -1416,7 +2502,21 @@ func (m *PseudonymsysCACertificate) GetS() []byte {
 	return nil
 }
 
-type PseudonymsysCACertificateEC struct {
+func (m *PseudonymsysCACertificate) GetAlgorithm() int32 {
+	if m != nil {
+		return m.Algorithm
+	}
+	return 0
+}
+
+func (m *PseudonymsysCACertificate) GetSCT() *SignedCertificateTimestamp {
+	if m != nil {
+		return m.SCT
+	}
+	return nil
+}
+
+type PseudonymsysCACertificateEC struct {
 	BlindedA *ECGroupElement `protobuf:"bytes,1,opt,name=BlindedA" json:"BlindedA,omitempty"`
 	BlindedB *ECGroupElement `protobuf:"bytes,2,opt,name=BlindedB" json:"BlindedB,omitempty"`
 	R        []byte          `protobuf:"bytes,3,opt,name=R,proto3" json:"R,omitempty"`
@@ -1457,12 +2557,13 @@ func (m *PseudonymsysCACertificateEC) GetS() []byte {
 }
 
 type PseudonymsysIssueProofRandomData struct {
-	X11 []byte `protobuf:"bytes,1,opt,name=X11,proto3" json:"X11,omitempty"`
-	X12 []byte `protobuf:"bytes,2,opt,name=X12,proto3" json:"X12,omitempty"`
-	X21 []byte `protobuf:"bytes,3,opt,name=X21,proto3" json:"X21,omitempty"`
-	X22 []byte `protobuf:"bytes,4,opt,name=X22,proto3" json:"X22,omitempty"`
-	A   []byte `protobuf:"bytes,5,opt,name=A,proto3" json:"A,omitempty"`
-	B   []byte `protobuf:"bytes,6,opt,name=B,proto3" json:"B,omitempty"`
+	X11        []byte                         `protobuf:"bytes,1,opt,name=X11,proto3" json:"X11,omitempty"`
+	X12        []byte                         `protobuf:"bytes,2,opt,name=X12,proto3" json:"X12,omitempty"`
+	X21        []byte                         `protobuf:"bytes,3,opt,name=X21,proto3" json:"X21,omitempty"`
+	X22        []byte                         `protobuf:"bytes,4,opt,name=X22,proto3" json:"X22,omitempty"`
+	A          []byte                         `protobuf:"bytes,5,opt,name=A,proto3" json:"A,omitempty"`
+	B          []byte                         `protobuf:"bytes,6,opt,name=B,proto3" json:"B,omitempty"`
+	Attributes []*PseudonymsysIssuedAttribute `protobuf:"bytes,7,rep,name=Attributes" json:"Attributes,omitempty"`
 }
 
 func (m *PseudonymsysIssueProofRandomData) Reset()         { *m = PseudonymsysIssueProofRandomData{} }
@@ -1514,6 +2615,13 @@ func (m *PseudonymsysIssueProofRandomData) GetB() []byte {
 	return nil
 }
 
+func (m *PseudonymsysIssueProofRandomData) GetAttributes() []*PseudonymsysIssuedAttribute {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
 type PseudonymsysIssueProofRandomDataEC struct {
 	X11 *ECGroupElement `protobuf:"bytes,1,opt,name=X11" json:"X11,omitempty"`
 	X12 *ECGroupElement `protobuf:"bytes,2,opt,name=X12" json:"X12,omitempty"`
@@ -1653,12 +2761,13 @@ func (m *PseudonymsysTranscriptEC) GetZAlpha() []byte {
 }
 
 type PseudonymsysCredential struct {
-	SmallAToGamma []byte                  `protobuf:"bytes,1,opt,name=SmallAToGamma,proto3" json:"SmallAToGamma,omitempty"`
-	SmallBToGamma []byte                  `protobuf:"bytes,2,opt,name=SmallBToGamma,proto3" json:"SmallBToGamma,omitempty"`
-	AToGamma      []byte                  `protobuf:"bytes,3,opt,name=AToGamma,proto3" json:"AToGamma,omitempty"`
-	BToGamma      []byte                  `protobuf:"bytes,4,opt,name=BToGamma,proto3" json:"BToGamma,omitempty"`
-	T1            *PseudonymsysTranscript `protobuf:"bytes,5,opt,name=T1" json:"T1,omitempty"`
-	T2            *PseudonymsysTranscript `protobuf:"bytes,6,opt,name=T2" json:"T2,omitempty"`
+	SmallAToGamma []byte                             `protobuf:"bytes,1,opt,name=SmallAToGamma,proto3" json:"SmallAToGamma,omitempty"`
+	SmallBToGamma []byte                             `protobuf:"bytes,2,opt,name=SmallBToGamma,proto3" json:"SmallBToGamma,omitempty"`
+	AToGamma      []byte                             `protobuf:"bytes,3,opt,name=AToGamma,proto3" json:"AToGamma,omitempty"`
+	BToGamma      []byte                             `protobuf:"bytes,4,opt,name=BToGamma,proto3" json:"BToGamma,omitempty"`
+	T1            *PseudonymsysTranscript            `protobuf:"bytes,5,opt,name=T1" json:"T1,omitempty"`
+	T2            *PseudonymsysTranscript            `protobuf:"bytes,6,opt,name=T2" json:"T2,omitempty"`
+	Attributes    []*PseudonymsysCredentialAttribute `protobuf:"bytes,7,rep,name=Attributes" json:"Attributes,omitempty"`
 }
 
 func (m *PseudonymsysCredential) Reset()                    { *m = PseudonymsysCredential{} }
@@ -1708,6 +2817,13 @@ func (m *PseudonymsysCredential) GetT2() *PseudonymsysTranscript {
 	return nil
 }
 
+func (m *PseudonymsysCredential) GetAttributes() []*PseudonymsysCredentialAttribute {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
 type PseudonymsysCredentialEC struct {
 	SmallAToGamma *ECGroupElement           `protobuf:"bytes,1,opt,name=SmallAToGamma" json:"SmallAToGamma,omitempty"`
 	SmallBToGamma *ECGroupElement           `protobuf:"bytes,2,opt,name=SmallBToGamma" json:"SmallBToGamma,omitempty"`
@@ -1771,6 +2887,10 @@ type PseudonymsysTransferCredentialData struct {
 	NymA       []byte                  `protobuf:"bytes,4,opt,name=NymA,proto3" json:"NymA,omitempty"`
 	NymB       []byte                  `protobuf:"bytes,5,opt,name=NymB,proto3" json:"NymB,omitempty"`
 	Credential *PseudonymsysCredential `protobuf:"bytes,6,opt,name=Credential" json:"Credential,omitempty"`
+	// attributes disclosed and predicates proved about the hidden ones, and the bit
+	// commitments and the first messages (C, T0, T1 of each bit) of the predicate proofs
+	Request    *AnonCredsPresentationRequest `protobuf:"bytes,7,opt,name=Request" json:"Request,omitempty"`
+	Predicates [][]byte                      `protobuf:"bytes,8,rep,name=Predicates,proto3" json:"Predicates,omitempty"`
 }
 
 func (m *PseudonymsysTransferCredentialData) Reset()         { *m = PseudonymsysTransferCredentialData{} }
@@ -1822,6 +2942,20 @@ func (m *PseudonymsysTransferCredentialData) GetCredential() *PseudonymsysCreden
 	return nil
 }
 
+func (m *PseudonymsysTransferCredentialData) GetRequest() *AnonCredsPresentationRequest {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (m *PseudonymsysTransferCredentialData) GetPredicates() [][]byte {
+	if m != nil {
+		return m.Predicates
+	}
+	return nil
+}
+
 type PseudonymsysTransferCredentialDataEC struct {
 	OrgName    string                    `protobuf:"bytes,1,opt,name=OrgName" json:"OrgName,omitempty"`
 	X1         *ECGroupElement           `protobuf:"bytes,2,opt,name=X1" json:"X1,omitempty"`
@@ -2336,6 +3470,1617 @@ func (m *SessionKey) GetValue() string {
 	return ""
 }
 
+type PseudonymsysRateLimitData struct {
+	Scope  string `protobuf:"bytes,1,opt,name=Scope" json:"Scope,omitempty"`
+	Epoch  int64  `protobuf:"varint,2,opt,name=Epoch" json:"Epoch,omitempty"`
+	Ticket []byte `protobuf:"bytes,3,opt,name=Ticket,proto3" json:"Ticket,omitempty"`
+	V      []byte `protobuf:"bytes,4,opt,name=V,proto3" json:"V,omitempty"`
+	T      []byte `protobuf:"bytes,5,opt,name=T,proto3" json:"T,omitempty"`
+	X      []byte `protobuf:"bytes,6,opt,name=X,proto3" json:"X,omitempty"`
+}
+
+func (m *PseudonymsysRateLimitData) Reset()                    { *m = PseudonymsysRateLimitData{} }
+func (m *PseudonymsysRateLimitData) String() string            { return proto.CompactTextString(m) }
+func (*PseudonymsysRateLimitData) ProtoMessage()               {}
+func (*PseudonymsysRateLimitData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
+
+func (m *PseudonymsysRateLimitData) GetScope() string {
+	if m != nil {
+		return m.Scope
+	}
+	return ""
+}
+
+func (m *PseudonymsysRateLimitData) GetEpoch() int64 {
+	if m != nil {
+		return m.Epoch
+	}
+	return 0
+}
+
+func (m *PseudonymsysRateLimitData) GetTicket() []byte {
+	if m != nil {
+		return m.Ticket
+	}
+	return nil
+}
+
+func (m *PseudonymsysRateLimitData) GetV() []byte {
+	if m != nil {
+		return m.V
+	}
+	return nil
+}
+
+func (m *PseudonymsysRateLimitData) GetT() []byte {
+	if m != nil {
+		return m.T
+	}
+	return nil
+}
+
+func (m *PseudonymsysRateLimitData) GetX() []byte {
+	if m != nil {
+		return m.X
+	}
+	return nil
+}
+
+type ExtensionMsg struct {
+	Scheme  string               `protobuf:"bytes,1,opt,name=Scheme" json:"Scheme,omitempty"`
+	Payload *google_protobuf.Any `protobuf:"bytes,2,opt,name=Payload" json:"Payload,omitempty"`
+}
+
+func (m *ExtensionMsg) Reset()                    { *m = ExtensionMsg{} }
+func (m *ExtensionMsg) String() string            { return proto.CompactTextString(m) }
+func (*ExtensionMsg) ProtoMessage()               {}
+func (*ExtensionMsg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+
+func (m *ExtensionMsg) GetScheme() string {
+	if m != nil {
+		return m.Scheme
+	}
+	return ""
+}
+
+func (m *ExtensionMsg) GetPayload() *google_protobuf.Any {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+type PseudonymsysCARequest struct {
+	ProofRandomData     *SchnorrProofRandomData `protobuf:"bytes,1,opt,name=ProofRandomData" json:"ProofRandomData,omitempty"`
+	SignatureAlgorithms []int32                 `protobuf:"varint,2,rep,packed,name=SignatureAlgorithms" json:"SignatureAlgorithms,omitempty"`
+}
+
+func (m *PseudonymsysCARequest) Reset()                    { *m = PseudonymsysCARequest{} }
+func (m *PseudonymsysCARequest) String() string            { return proto.CompactTextString(m) }
+func (*PseudonymsysCARequest) ProtoMessage()               {}
+func (*PseudonymsysCARequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+
+func (m *PseudonymsysCARequest) GetProofRandomData() *SchnorrProofRandomData {
+	if m != nil {
+		return m.ProofRandomData
+	}
+	return nil
+}
+
+func (m *PseudonymsysCARequest) GetSignatureAlgorithms() []int32 {
+	if m != nil {
+		return m.SignatureAlgorithms
+	}
+	return nil
+}
+
+type SignedCertificateTimestamp struct {
+	Timestamp int64  `protobuf:"varint,1,opt,name=Timestamp" json:"Timestamp,omitempty"`
+	R         []byte `protobuf:"bytes,2,opt,name=R,proto3" json:"R,omitempty"`
+	S         []byte `protobuf:"bytes,3,opt,name=S,proto3" json:"S,omitempty"`
+}
+
+func (m *SignedCertificateTimestamp) Reset()                    { *m = SignedCertificateTimestamp{} }
+func (m *SignedCertificateTimestamp) String() string            { return proto.CompactTextString(m) }
+func (*SignedCertificateTimestamp) ProtoMessage()               {}
+func (*SignedCertificateTimestamp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+
+func (m *SignedCertificateTimestamp) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *SignedCertificateTimestamp) GetR() []byte {
+	if m != nil {
+		return m.R
+	}
+	return nil
+}
+
+func (m *SignedCertificateTimestamp) GetS() []byte {
+	if m != nil {
+		return m.S
+	}
+	return nil
+}
+
+type PseudonymsysCAStatus struct {
+	Status    int32  `protobuf:"varint,1,opt,name=Status" json:"Status,omitempty"`
+	Timestamp int64  `protobuf:"varint,2,opt,name=Timestamp" json:"Timestamp,omitempty"`
+	R         []byte `protobuf:"bytes,3,opt,name=R,proto3" json:"R,omitempty"`
+	S         []byte `protobuf:"bytes,4,opt,name=S,proto3" json:"S,omitempty"`
+}
+
+func (m *PseudonymsysCAStatus) Reset()                    { *m = PseudonymsysCAStatus{} }
+func (m *PseudonymsysCAStatus) String() string            { return proto.CompactTextString(m) }
+func (*PseudonymsysCAStatus) ProtoMessage()               {}
+func (*PseudonymsysCAStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
+
+func (m *PseudonymsysCAStatus) GetStatus() int32 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+func (m *PseudonymsysCAStatus) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *PseudonymsysCAStatus) GetR() []byte {
+	if m != nil {
+		return m.R
+	}
+	return nil
+}
+
+func (m *PseudonymsysCAStatus) GetS() []byte {
+	if m != nil {
+		return m.S
+	}
+	return nil
+}
+
+type HybridKEMInit struct {
+	X25519 []byte `protobuf:"bytes,1,opt,name=X25519,proto3" json:"X25519,omitempty"`
+	MLKEM  []byte `protobuf:"bytes,2,opt,name=MLKEM,proto3" json:"MLKEM,omitempty"`
+}
+
+func (m *HybridKEMInit) Reset()                    { *m = HybridKEMInit{} }
+func (m *HybridKEMInit) String() string            { return proto.CompactTextString(m) }
+func (*HybridKEMInit) ProtoMessage()               {}
+func (*HybridKEMInit) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
+
+func (m *HybridKEMInit) GetX25519() []byte {
+	if m != nil {
+		return m.X25519
+	}
+	return nil
+}
+
+func (m *HybridKEMInit) GetMLKEM() []byte {
+	if m != nil {
+		return m.MLKEM
+	}
+	return nil
+}
+
+type HybridKEMResponse struct {
+	X25519 []byte `protobuf:"bytes,1,opt,name=X25519,proto3" json:"X25519,omitempty"`
+	MLKEM  []byte `protobuf:"bytes,2,opt,name=MLKEM,proto3" json:"MLKEM,omitempty"`
+}
+
+func (m *HybridKEMResponse) Reset()                    { *m = HybridKEMResponse{} }
+func (m *HybridKEMResponse) String() string            { return proto.CompactTextString(m) }
+func (*HybridKEMResponse) ProtoMessage()               {}
+func (*HybridKEMResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
+
+func (m *HybridKEMResponse) GetX25519() []byte {
+	if m != nil {
+		return m.X25519
+	}
+	return nil
+}
+
+func (m *HybridKEMResponse) GetMLKEM() []byte {
+	if m != nil {
+		return m.MLKEM
+	}
+	return nil
+}
+
+type EncryptedMsg struct {
+	Ciphertext []byte `protobuf:"bytes,1,opt,name=Ciphertext,proto3" json:"Ciphertext,omitempty"`
+}
+
+func (m *EncryptedMsg) Reset()                    { *m = EncryptedMsg{} }
+func (m *EncryptedMsg) String() string            { return proto.CompactTextString(m) }
+func (*EncryptedMsg) ProtoMessage()               {}
+func (*EncryptedMsg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
+
+func (m *EncryptedMsg) GetCiphertext() []byte {
+	if m != nil {
+		return m.Ciphertext
+	}
+	return nil
+}
+
+type BitProofRandomData struct {
+	C  []byte `protobuf:"bytes,1,opt,name=C,proto3" json:"C,omitempty"`
+	T0 []byte `protobuf:"bytes,2,opt,name=T0,proto3" json:"T0,omitempty"`
+	T1 []byte `protobuf:"bytes,3,opt,name=T1,proto3" json:"T1,omitempty"`
+}
+
+func (m *BitProofRandomData) Reset()                    { *m = BitProofRandomData{} }
+func (m *BitProofRandomData) String() string            { return proto.CompactTextString(m) }
+func (*BitProofRandomData) ProtoMessage()               {}
+func (*BitProofRandomData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{42} }
+
+func (m *BitProofRandomData) GetC() []byte {
+	if m != nil {
+		return m.C
+	}
+	return nil
+}
+
+func (m *BitProofRandomData) GetT0() []byte {
+	if m != nil {
+		return m.T0
+	}
+	return nil
+}
+
+func (m *BitProofRandomData) GetT1() []byte {
+	if m != nil {
+		return m.T1
+	}
+	return nil
+}
+
+type BitProofData struct {
+	E0 []byte `protobuf:"bytes,1,opt,name=E0,proto3" json:"E0,omitempty"`
+	E1 []byte `protobuf:"bytes,2,opt,name=E1,proto3" json:"E1,omitempty"`
+	Z0 []byte `protobuf:"bytes,3,opt,name=Z0,proto3" json:"Z0,omitempty"`
+	Z1 []byte `protobuf:"bytes,4,opt,name=Z1,proto3" json:"Z1,omitempty"`
+}
+
+func (m *BitProofData) Reset()                    { *m = BitProofData{} }
+func (m *BitProofData) String() string            { return proto.CompactTextString(m) }
+func (*BitProofData) ProtoMessage()               {}
+func (*BitProofData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{43} }
+
+func (m *BitProofData) GetE0() []byte {
+	if m != nil {
+		return m.E0
+	}
+	return nil
+}
+
+func (m *BitProofData) GetE1() []byte {
+	if m != nil {
+		return m.E1
+	}
+	return nil
+}
+
+func (m *BitProofData) GetZ0() []byte {
+	if m != nil {
+		return m.Z0
+	}
+	return nil
+}
+
+func (m *BitProofData) GetZ1() []byte {
+	if m != nil {
+		return m.Z1
+	}
+	return nil
+}
+
+type RangeProofRandomData struct {
+	C     []byte                `protobuf:"bytes,1,opt,name=C,proto3" json:"C,omitempty"`
+	A     []byte                `protobuf:"bytes,2,opt,name=A,proto3" json:"A,omitempty"`
+	B     []byte                `protobuf:"bytes,3,opt,name=B,proto3" json:"B,omitempty"`
+	Lower []*BitProofRandomData `protobuf:"bytes,4,rep,name=Lower" json:"Lower,omitempty"`
+	Upper []*BitProofRandomData `protobuf:"bytes,5,rep,name=Upper" json:"Upper,omitempty"`
+}
+
+func (m *RangeProofRandomData) Reset()                    { *m = RangeProofRandomData{} }
+func (m *RangeProofRandomData) String() string            { return proto.CompactTextString(m) }
+func (*RangeProofRandomData) ProtoMessage()               {}
+func (*RangeProofRandomData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{44} }
+
+func (m *RangeProofRandomData) GetC() []byte {
+	if m != nil {
+		return m.C
+	}
+	return nil
+}
+
+func (m *RangeProofRandomData) GetA() []byte {
+	if m != nil {
+		return m.A
+	}
+	return nil
+}
+
+func (m *RangeProofRandomData) GetB() []byte {
+	if m != nil {
+		return m.B
+	}
+	return nil
+}
+
+func (m *RangeProofRandomData) GetLower() []*BitProofRandomData {
+	if m != nil {
+		return m.Lower
+	}
+	return nil
+}
+
+func (m *RangeProofRandomData) GetUpper() []*BitProofRandomData {
+	if m != nil {
+		return m.Upper
+	}
+	return nil
+}
+
+type RangeProofData struct {
+	Lower []*BitProofData `protobuf:"bytes,1,rep,name=Lower" json:"Lower,omitempty"`
+	Upper []*BitProofData `protobuf:"bytes,2,rep,name=Upper" json:"Upper,omitempty"`
+}
+
+func (m *RangeProofData) Reset()                    { *m = RangeProofData{} }
+func (m *RangeProofData) String() string            { return proto.CompactTextString(m) }
+func (*RangeProofData) ProtoMessage()               {}
+func (*RangeProofData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{45} }
+
+func (m *RangeProofData) GetLower() []*BitProofData {
+	if m != nil {
+		return m.Lower
+	}
+	return nil
+}
+
+func (m *RangeProofData) GetUpper() []*BitProofData {
+	if m != nil {
+		return m.Upper
+	}
+	return nil
+}
+
+type Trapdoor struct {
+	Trapdoor []byte `protobuf:"bytes,1,opt,name=Trapdoor,proto3" json:"Trapdoor,omitempty"`
+}
+
+func (m *Trapdoor) Reset()                    { *m = Trapdoor{} }
+func (m *Trapdoor) String() string            { return proto.CompactTextString(m) }
+func (*Trapdoor) ProtoMessage()               {}
+func (*Trapdoor) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{46} }
+
+func (m *Trapdoor) GetTrapdoor() []byte {
+	if m != nil {
+		return m.Trapdoor
+	}
+	return nil
+}
+
+// Sent by the client which requires the evidence that the server runs in a TEE.
+type AttestationRequest struct {
+	Nonce []byte `protobuf:"bytes,1,opt,name=Nonce,proto3" json:"Nonce,omitempty"`
+}
+
+func (m *AttestationRequest) Reset()                    { *m = AttestationRequest{} }
+func (m *AttestationRequest) String() string            { return proto.CompactTextString(m) }
+func (*AttestationRequest) ProtoMessage()               {}
+func (*AttestationRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{47} }
+
+func (m *AttestationRequest) GetNonce() []byte {
+	if m != nil {
+		return m.Nonce
+	}
+	return nil
+}
+
+// TEE evidence (for example SGX quote) which binds the client's nonce and the session key exchange.
+type AttestationEvidence struct {
+	Format   string `protobuf:"bytes,1,opt,name=Format" json:"Format,omitempty"`
+	Evidence []byte `protobuf:"bytes,2,opt,name=Evidence,proto3" json:"Evidence,omitempty"`
+}
+
+func (m *AttestationEvidence) Reset()                    { *m = AttestationEvidence{} }
+func (m *AttestationEvidence) String() string            { return proto.CompactTextString(m) }
+func (*AttestationEvidence) ProtoMessage()               {}
+func (*AttestationEvidence) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{48} }
+
+func (m *AttestationEvidence) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+func (m *AttestationEvidence) GetEvidence() []byte {
+	if m != nil {
+		return m.Evidence
+	}
+	return nil
+}
+
+// Paillier public key and ciphertext together with the first message of the proof of plaintext
+// knowledge. Commitment and CommitmentA are set only when proving that the plaintext is
+// the value committed with Pedersen commitment.
+type PaillierPlaintextProofRandomData struct {
+	N           []byte `protobuf:"bytes,1,opt,name=N,proto3" json:"N,omitempty"`
+	G           []byte `protobuf:"bytes,2,opt,name=G,proto3" json:"G,omitempty"`
+	C           []byte `protobuf:"bytes,3,opt,name=C,proto3" json:"C,omitempty"`
+	A           []byte `protobuf:"bytes,4,opt,name=A,proto3" json:"A,omitempty"`
+	Commitment  []byte `protobuf:"bytes,5,opt,name=Commitment,proto3" json:"Commitment,omitempty"`
+	CommitmentA []byte `protobuf:"bytes,6,opt,name=CommitmentA,proto3" json:"CommitmentA,omitempty"`
+}
+
+func (m *PaillierPlaintextProofRandomData) Reset()         { *m = PaillierPlaintextProofRandomData{} }
+func (m *PaillierPlaintextProofRandomData) String() string { return proto.CompactTextString(m) }
+func (*PaillierPlaintextProofRandomData) ProtoMessage()    {}
+func (*PaillierPlaintextProofRandomData) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{49}
+}
+
+func (m *PaillierPlaintextProofRandomData) GetN() []byte {
+	if m != nil {
+		return m.N
+	}
+	return nil
+}
+
+func (m *PaillierPlaintextProofRandomData) GetG() []byte {
+	if m != nil {
+		return m.G
+	}
+	return nil
+}
+
+func (m *PaillierPlaintextProofRandomData) GetC() []byte {
+	if m != nil {
+		return m.C
+	}
+	return nil
+}
+
+func (m *PaillierPlaintextProofRandomData) GetA() []byte {
+	if m != nil {
+		return m.A
+	}
+	return nil
+}
+
+func (m *PaillierPlaintextProofRandomData) GetCommitment() []byte {
+	if m != nil {
+		return m.Commitment
+	}
+	return nil
+}
+
+func (m *PaillierPlaintextProofRandomData) GetCommitmentA() []byte {
+	if m != nil {
+		return m.CommitmentA
+	}
+	return nil
+}
+
+type PaillierPlaintextProofData struct {
+	Z []byte `protobuf:"bytes,1,opt,name=Z,proto3" json:"Z,omitempty"`
+	W []byte `protobuf:"bytes,2,opt,name=W,proto3" json:"W,omitempty"`
+	V []byte `protobuf:"bytes,3,opt,name=V,proto3" json:"V,omitempty"`
+}
+
+func (m *PaillierPlaintextProofData) Reset()                    { *m = PaillierPlaintextProofData{} }
+func (m *PaillierPlaintextProofData) String() string            { return proto.CompactTextString(m) }
+func (*PaillierPlaintextProofData) ProtoMessage()               {}
+func (*PaillierPlaintextProofData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{50} }
+
+func (m *PaillierPlaintextProofData) GetZ() []byte {
+	if m != nil {
+		return m.Z
+	}
+	return nil
+}
+
+func (m *PaillierPlaintextProofData) GetW() []byte {
+	if m != nil {
+		return m.W
+	}
+	return nil
+}
+
+func (m *PaillierPlaintextProofData) GetV() []byte {
+	if m != nil {
+		return m.V
+	}
+	return nil
+}
+
+// Master secret of the nym owner encrypted under the escrow key (Camenisch-Shoup ciphertext
+// (U, E, V) and commitment L) together with the first message of the proof that it is log_NymA(NymB).
+type PseudonymsysNymEscrowData struct {
+	NymA            []byte                     `protobuf:"bytes,1,opt,name=NymA,proto3" json:"NymA,omitempty"`
+	NymB            []byte                     `protobuf:"bytes,2,opt,name=NymB,proto3" json:"NymB,omitempty"`
+	U               []byte                     `protobuf:"bytes,3,opt,name=U,proto3" json:"U,omitempty"`
+	E               []byte                     `protobuf:"bytes,4,opt,name=E,proto3" json:"E,omitempty"`
+	V               []byte                     `protobuf:"bytes,5,opt,name=V,proto3" json:"V,omitempty"`
+	L               []byte                     `protobuf:"bytes,6,opt,name=L,proto3" json:"L,omitempty"`
+	ProofRandomData *CSPaillierProofRandomData `protobuf:"bytes,7,opt,name=ProofRandomData" json:"ProofRandomData,omitempty"`
+}
+
+func (m *PseudonymsysNymEscrowData) Reset()                    { *m = PseudonymsysNymEscrowData{} }
+func (m *PseudonymsysNymEscrowData) String() string            { return proto.CompactTextString(m) }
+func (*PseudonymsysNymEscrowData) ProtoMessage()               {}
+func (*PseudonymsysNymEscrowData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{51} }
+
+func (m *PseudonymsysNymEscrowData) GetNymA() []byte {
+	if m != nil {
+		return m.NymA
+	}
+	return nil
+}
+
+func (m *PseudonymsysNymEscrowData) GetNymB() []byte {
+	if m != nil {
+		return m.NymB
+	}
+	return nil
+}
+
+func (m *PseudonymsysNymEscrowData) GetU() []byte {
+	if m != nil {
+		return m.U
+	}
+	return nil
+}
+
+func (m *PseudonymsysNymEscrowData) GetE() []byte {
+	if m != nil {
+		return m.E
+	}
+	return nil
+}
+
+func (m *PseudonymsysNymEscrowData) GetV() []byte {
+	if m != nil {
+		return m.V
+	}
+	return nil
+}
+
+func (m *PseudonymsysNymEscrowData) GetL() []byte {
+	if m != nil {
+		return m.L
+	}
+	return nil
+}
+
+func (m *PseudonymsysNymEscrowData) GetProofRandomData() *CSPaillierProofRandomData {
+	if m != nil {
+		return m.ProofRandomData
+	}
+	return nil
+}
+
+// Signed list of the blacklisted tickets (H, Tag) at the given version of the revocation registry.
+type RevocationSnapshot struct {
+	Version   uint64  `protobuf:"varint,1,opt,name=Version" json:"Version,omitempty"`
+	Timestamp int64   `protobuf:"varint,2,opt,name=Timestamp" json:"Timestamp,omitempty"`
+	Tickets   []*Pair `protobuf:"bytes,3,rep,name=Tickets" json:"Tickets,omitempty"`
+	R         []byte  `protobuf:"bytes,4,opt,name=R,proto3" json:"R,omitempty"`
+	S         []byte  `protobuf:"bytes,5,opt,name=S,proto3" json:"S,omitempty"`
+}
+
+func (m *RevocationSnapshot) Reset()                    { *m = RevocationSnapshot{} }
+func (m *RevocationSnapshot) String() string            { return proto.CompactTextString(m) }
+func (*RevocationSnapshot) ProtoMessage()               {}
+func (*RevocationSnapshot) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{52} }
+
+func (m *RevocationSnapshot) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *RevocationSnapshot) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *RevocationSnapshot) GetTickets() []*Pair {
+	if m != nil {
+		return m.Tickets
+	}
+	return nil
+}
+
+func (m *RevocationSnapshot) GetR() []byte {
+	if m != nil {
+		return m.R
+	}
+	return nil
+}
+
+func (m *RevocationSnapshot) GetS() []byte {
+	if m != nil {
+		return m.S
+	}
+	return nil
+}
+
+// Version of the revocation registry which the client already has.
+type RevocationSubscription struct {
+	Version uint64 `protobuf:"varint,1,opt,name=Version" json:"Version,omitempty"`
+}
+
+func (m *RevocationSubscription) Reset()                    { *m = RevocationSubscription{} }
+func (m *RevocationSubscription) String() string            { return proto.CompactTextString(m) }
+func (*RevocationSubscription) ProtoMessage()               {}
+func (*RevocationSubscription) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{53} }
+
+func (m *RevocationSubscription) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+// Tickets revoked after version From up to Version, together with the signature of
+// the snapshot of the registry at Version (see RevocationSnapshot).
+type RevocationUpdate struct {
+	From      uint64  `protobuf:"varint,1,opt,name=From" json:"From,omitempty"`
+	Version   uint64  `protobuf:"varint,2,opt,name=Version" json:"Version,omitempty"`
+	Timestamp int64   `protobuf:"varint,3,opt,name=Timestamp" json:"Timestamp,omitempty"`
+	Tickets   []*Pair `protobuf:"bytes,4,rep,name=Tickets" json:"Tickets,omitempty"`
+	R         []byte  `protobuf:"bytes,5,opt,name=R,proto3" json:"R,omitempty"`
+	S         []byte  `protobuf:"bytes,6,opt,name=S,proto3" json:"S,omitempty"`
+}
+
+func (m *RevocationUpdate) Reset()                    { *m = RevocationUpdate{} }
+func (m *RevocationUpdate) String() string            { return proto.CompactTextString(m) }
+func (*RevocationUpdate) ProtoMessage()               {}
+func (*RevocationUpdate) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{54} }
+
+func (m *RevocationUpdate) GetFrom() uint64 {
+	if m != nil {
+		return m.From
+	}
+	return 0
+}
+
+func (m *RevocationUpdate) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *RevocationUpdate) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *RevocationUpdate) GetTickets() []*Pair {
+	if m != nil {
+		return m.Tickets
+	}
+	return nil
+}
+
+func (m *RevocationUpdate) GetR() []byte {
+	if m != nil {
+		return m.R
+	}
+	return nil
+}
+
+func (m *RevocationUpdate) GetS() []byte {
+	if m != nil {
+		return m.S
+	}
+	return nil
+}
+
+// Parameters (N, G), public key V and the first message X of GPS identification.
+type GPSProofRandomData struct {
+	N []byte `protobuf:"bytes,1,opt,name=N,proto3" json:"N,omitempty"`
+	G []byte `protobuf:"bytes,2,opt,name=G,proto3" json:"G,omitempty"`
+	V []byte `protobuf:"bytes,3,opt,name=V,proto3" json:"V,omitempty"`
+	X []byte `protobuf:"bytes,4,opt,name=X,proto3" json:"X,omitempty"`
+}
+
+func (m *GPSProofRandomData) Reset()                    { *m = GPSProofRandomData{} }
+func (m *GPSProofRandomData) String() string            { return proto.CompactTextString(m) }
+func (*GPSProofRandomData) ProtoMessage()               {}
+func (*GPSProofRandomData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{55} }
+
+func (m *GPSProofRandomData) GetN() []byte {
+	if m != nil {
+		return m.N
+	}
+	return nil
+}
+
+func (m *GPSProofRandomData) GetG() []byte {
+	if m != nil {
+		return m.G
+	}
+	return nil
+}
+
+func (m *GPSProofRandomData) GetV() []byte {
+	if m != nil {
+		return m.V
+	}
+	return nil
+}
+
+func (m *GPSProofRandomData) GetX() []byte {
+	if m != nil {
+		return m.X
+	}
+	return nil
+}
+
+// Signed root of the pseudonymsys nym registry published at the end of an epoch.
+type NymRegistryRoot struct {
+	Epoch     uint64 `protobuf:"varint,1,opt,name=Epoch" json:"Epoch,omitempty"`
+	Size      int64  `protobuf:"varint,2,opt,name=Size" json:"Size,omitempty"`
+	Root      []byte `protobuf:"bytes,3,opt,name=Root,proto3" json:"Root,omitempty"`
+	Previous  []byte `protobuf:"bytes,4,opt,name=Previous,proto3" json:"Previous,omitempty"`
+	Timestamp int64  `protobuf:"varint,5,opt,name=Timestamp" json:"Timestamp,omitempty"`
+	R         []byte `protobuf:"bytes,6,opt,name=R,proto3" json:"R,omitempty"`
+	S         []byte `protobuf:"bytes,7,opt,name=S,proto3" json:"S,omitempty"`
+}
+
+func (m *NymRegistryRoot) Reset()                    { *m = NymRegistryRoot{} }
+func (m *NymRegistryRoot) String() string            { return proto.CompactTextString(m) }
+func (*NymRegistryRoot) ProtoMessage()               {}
+func (*NymRegistryRoot) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{56} }
+
+func (m *NymRegistryRoot) GetEpoch() uint64 {
+	if m != nil {
+		return m.Epoch
+	}
+	return 0
+}
+
+func (m *NymRegistryRoot) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *NymRegistryRoot) GetRoot() []byte {
+	if m != nil {
+		return m.Root
+	}
+	return nil
+}
+
+func (m *NymRegistryRoot) GetPrevious() []byte {
+	if m != nil {
+		return m.Previous
+	}
+	return nil
+}
+
+func (m *NymRegistryRoot) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *NymRegistryRoot) GetR() []byte {
+	if m != nil {
+		return m.R
+	}
+	return nil
+}
+
+func (m *NymRegistryRoot) GetS() []byte {
+	if m != nil {
+		return m.S
+	}
+	return nil
+}
+
+// Proof that a nym is included in the nym registry with the given root.
+type NymInclusionProof struct {
+	Root     *NymRegistryRoot `protobuf:"bytes,1,opt,name=Root" json:"Root,omitempty"`
+	Bitmap   []byte           `protobuf:"bytes,2,opt,name=Bitmap,proto3" json:"Bitmap,omitempty"`
+	Siblings [][]byte         `protobuf:"bytes,3,rep,name=Siblings,proto3" json:"Siblings,omitempty"`
+}
+
+func (m *NymInclusionProof) Reset()                    { *m = NymInclusionProof{} }
+func (m *NymInclusionProof) String() string            { return proto.CompactTextString(m) }
+func (*NymInclusionProof) ProtoMessage()               {}
+func (*NymInclusionProof) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{57} }
+
+func (m *NymInclusionProof) GetRoot() *NymRegistryRoot {
+	if m != nil {
+		return m.Root
+	}
+	return nil
+}
+
+func (m *NymInclusionProof) GetBitmap() []byte {
+	if m != nil {
+		return m.Bitmap
+	}
+	return nil
+}
+
+func (m *NymInclusionProof) GetSiblings() [][]byte {
+	if m != nil {
+		return m.Siblings
+	}
+	return nil
+}
+
+type SternCommitment struct {
+	C1 []byte `protobuf:"bytes,1,opt,name=C1,proto3" json:"C1,omitempty"`
+	C2 []byte `protobuf:"bytes,2,opt,name=C2,proto3" json:"C2,omitempty"`
+	C3 []byte `protobuf:"bytes,3,opt,name=C3,proto3" json:"C3,omitempty"`
+}
+
+func (m *SternCommitment) Reset()                    { *m = SternCommitment{} }
+func (m *SternCommitment) String() string            { return proto.CompactTextString(m) }
+func (*SternCommitment) ProtoMessage()               {}
+func (*SternCommitment) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{58} }
+
+func (m *SternCommitment) GetC1() []byte {
+	if m != nil {
+		return m.C1
+	}
+	return nil
+}
+
+func (m *SternCommitment) GetC2() []byte {
+	if m != nil {
+		return m.C2
+	}
+	return nil
+}
+
+func (m *SternCommitment) GetC3() []byte {
+	if m != nil {
+		return m.C3
+	}
+	return nil
+}
+
+// Parameters (the seed of the parity-check matrix), public key (syndrome) and the commitments
+// of all the rounds of Stern identification.
+type SternProofRandomData struct {
+	ParamsSeed  []byte             `protobuf:"bytes,1,opt,name=ParamsSeed,proto3" json:"ParamsSeed,omitempty"`
+	PublicKey   []byte             `protobuf:"bytes,2,opt,name=PublicKey,proto3" json:"PublicKey,omitempty"`
+	Commitments []*SternCommitment `protobuf:"bytes,3,rep,name=Commitments" json:"Commitments,omitempty"`
+}
+
+func (m *SternProofRandomData) Reset()                    { *m = SternProofRandomData{} }
+func (m *SternProofRandomData) String() string            { return proto.CompactTextString(m) }
+func (*SternProofRandomData) ProtoMessage()               {}
+func (*SternProofRandomData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{59} }
+
+func (m *SternProofRandomData) GetParamsSeed() []byte {
+	if m != nil {
+		return m.ParamsSeed
+	}
+	return nil
+}
+
+func (m *SternProofRandomData) GetPublicKey() []byte {
+	if m != nil {
+		return m.PublicKey
+	}
+	return nil
+}
+
+func (m *SternProofRandomData) GetCommitments() []*SternCommitment {
+	if m != nil {
+		return m.Commitments
+	}
+	return nil
+}
+
+type SternResponse struct {
+	SigmaSeed []byte   `protobuf:"bytes,1,opt,name=SigmaSeed,proto3" json:"SigmaSeed,omitempty"`
+	YSeed     []byte   `protobuf:"bytes,2,opt,name=YSeed,proto3" json:"YSeed,omitempty"`
+	Y         []byte   `protobuf:"bytes,3,opt,name=Y,proto3" json:"Y,omitempty"`
+	E         []byte   `protobuf:"bytes,4,opt,name=E,proto3" json:"E,omitempty"`
+	Salts     [][]byte `protobuf:"bytes,5,rep,name=Salts,proto3" json:"Salts,omitempty"`
+}
+
+func (m *SternResponse) Reset()                    { *m = SternResponse{} }
+func (m *SternResponse) String() string            { return proto.CompactTextString(m) }
+func (*SternResponse) ProtoMessage()               {}
+func (*SternResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{60} }
+
+func (m *SternResponse) GetSigmaSeed() []byte {
+	if m != nil {
+		return m.SigmaSeed
+	}
+	return nil
+}
+
+func (m *SternResponse) GetYSeed() []byte {
+	if m != nil {
+		return m.YSeed
+	}
+	return nil
+}
+
+func (m *SternResponse) GetY() []byte {
+	if m != nil {
+		return m.Y
+	}
+	return nil
+}
+
+func (m *SternResponse) GetE() []byte {
+	if m != nil {
+		return m.E
+	}
+	return nil
+}
+
+func (m *SternResponse) GetSalts() [][]byte {
+	if m != nil {
+		return m.Salts
+	}
+	return nil
+}
+
+type SternProofData struct {
+	Responses []*SternResponse `protobuf:"bytes,1,rep,name=Responses" json:"Responses,omitempty"`
+}
+
+func (m *SternProofData) Reset()                    { *m = SternProofData{} }
+func (m *SternProofData) String() string            { return proto.CompactTextString(m) }
+func (*SternProofData) ProtoMessage()               {}
+func (*SternProofData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{61} }
+
+func (m *SternProofData) GetResponses() []*SternResponse {
+	if m != nil {
+		return m.Responses
+	}
+	return nil
+}
+
+// Evidence of a misbehaving session (blacklist ticket and transcript hash), signed by the verifier.
+type AbuseReport struct {
+	Ticket         *Pair  `protobuf:"bytes,1,opt,name=Ticket" json:"Ticket,omitempty"`
+	TranscriptHash []byte `protobuf:"bytes,2,opt,name=TranscriptHash,proto3" json:"TranscriptHash,omitempty"`
+	Reason         string `protobuf:"bytes,3,opt,name=Reason" json:"Reason,omitempty"`
+	Nym            *Pair  `protobuf:"bytes,4,opt,name=Nym" json:"Nym,omitempty"`
+	Reporter       string `protobuf:"bytes,5,opt,name=Reporter" json:"Reporter,omitempty"`
+	Timestamp      int64  `protobuf:"varint,6,opt,name=Timestamp" json:"Timestamp,omitempty"`
+	R              []byte `protobuf:"bytes,7,opt,name=R,proto3" json:"R,omitempty"`
+	S              []byte `protobuf:"bytes,8,opt,name=S,proto3" json:"S,omitempty"`
+}
+
+func (m *AbuseReport) Reset()                    { *m = AbuseReport{} }
+func (m *AbuseReport) String() string            { return proto.CompactTextString(m) }
+func (*AbuseReport) ProtoMessage()               {}
+func (*AbuseReport) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{62} }
+
+func (m *AbuseReport) GetTicket() *Pair {
+	if m != nil {
+		return m.Ticket
+	}
+	return nil
+}
+
+func (m *AbuseReport) GetTranscriptHash() []byte {
+	if m != nil {
+		return m.TranscriptHash
+	}
+	return nil
+}
+
+func (m *AbuseReport) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *AbuseReport) GetNym() *Pair {
+	if m != nil {
+		return m.Nym
+	}
+	return nil
+}
+
+func (m *AbuseReport) GetReporter() string {
+	if m != nil {
+		return m.Reporter
+	}
+	return ""
+}
+
+func (m *AbuseReport) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *AbuseReport) GetR() []byte {
+	if m != nil {
+		return m.R
+	}
+	return nil
+}
+
+func (m *AbuseReport) GetS() []byte {
+	if m != nil {
+		return m.S
+	}
+	return nil
+}
+
+// Statement A * s = t (A is given by rows, each polynomial encoded with lattice.Poly.Bytes)
+// together with the non-interactive proof of knowledge of short s.
+type LatticeShortVectorProof struct {
+	Width     int32    `protobuf:"varint,1,opt,name=Width" json:"Width,omitempty"`
+	A         [][]byte `protobuf:"bytes,2,rep,name=A,proto3" json:"A,omitempty"`
+	T         [][]byte `protobuf:"bytes,3,rep,name=T,proto3" json:"T,omitempty"`
+	Bound     int64    `protobuf:"varint,4,opt,name=Bound" json:"Bound,omitempty"`
+	Challenge []byte   `protobuf:"bytes,5,opt,name=Challenge,proto3" json:"Challenge,omitempty"`
+	Z         [][]byte `protobuf:"bytes,6,rep,name=Z,proto3" json:"Z,omitempty"`
+}
+
+func (m *LatticeShortVectorProof) Reset()                    { *m = LatticeShortVectorProof{} }
+func (m *LatticeShortVectorProof) String() string            { return proto.CompactTextString(m) }
+func (*LatticeShortVectorProof) ProtoMessage()               {}
+func (*LatticeShortVectorProof) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{63} }
+
+func (m *LatticeShortVectorProof) GetWidth() int32 {
+	if m != nil {
+		return m.Width
+	}
+	return 0
+}
+
+func (m *LatticeShortVectorProof) GetA() [][]byte {
+	if m != nil {
+		return m.A
+	}
+	return nil
+}
+
+func (m *LatticeShortVectorProof) GetT() [][]byte {
+	if m != nil {
+		return m.T
+	}
+	return nil
+}
+
+func (m *LatticeShortVectorProof) GetBound() int64 {
+	if m != nil {
+		return m.Bound
+	}
+	return 0
+}
+
+func (m *LatticeShortVectorProof) GetChallenge() []byte {
+	if m != nil {
+		return m.Challenge
+	}
+	return nil
+}
+
+func (m *LatticeShortVectorProof) GetZ() [][]byte {
+	if m != nil {
+		return m.Z
+	}
+	return nil
+}
+
+// Start of the threshold Schnorr proof by the signers (indices of the shares), sent by
+// the coordinator to the participant holding the share with the given index.
+type ThresholdSchnorrRequest struct {
+	Index   int32   `protobuf:"varint,1,opt,name=Index" json:"Index,omitempty"`
+	Signers []int32 `protobuf:"varint,2,rep,packed,name=Signers" json:"Signers,omitempty"`
+}
+
+func (m *ThresholdSchnorrRequest) Reset()                    { *m = ThresholdSchnorrRequest{} }
+func (m *ThresholdSchnorrRequest) String() string            { return proto.CompactTextString(m) }
+func (*ThresholdSchnorrRequest) ProtoMessage()               {}
+func (*ThresholdSchnorrRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{64} }
+
+func (m *ThresholdSchnorrRequest) GetIndex() int32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *ThresholdSchnorrRequest) GetSigners() []int32 {
+	if m != nil {
+		return m.Signers
+	}
+	return nil
+}
+
+// Request for a partially blind Schnorr signature with the public information (metadata)
+// proposed by the user.
+type PartiallyBlindSchnorrRequest struct {
+	Info []byte `protobuf:"bytes,1,opt,name=Info,proto3" json:"Info,omitempty"`
+}
+
+func (m *PartiallyBlindSchnorrRequest) Reset()                    { *m = PartiallyBlindSchnorrRequest{} }
+func (m *PartiallyBlindSchnorrRequest) String() string            { return proto.CompactTextString(m) }
+func (*PartiallyBlindSchnorrRequest) ProtoMessage()               {}
+func (*PartiallyBlindSchnorrRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{65} }
+
+func (m *PartiallyBlindSchnorrRequest) GetInfo() []byte {
+	if m != nil {
+		return m.Info
+	}
+	return nil
+}
+
+// Commitment of the signer of the partially blind Schnorr signature, together with
+// the public information which is embedded in the signature.
+type PartiallyBlindSchnorrCommitment struct {
+	Info []byte `protobuf:"bytes,1,opt,name=Info,proto3" json:"Info,omitempty"`
+	A    []byte `protobuf:"bytes,2,opt,name=A,proto3" json:"A,omitempty"`
+	B    []byte `protobuf:"bytes,3,opt,name=B,proto3" json:"B,omitempty"`
+}
+
+func (m *PartiallyBlindSchnorrCommitment) Reset()         { *m = PartiallyBlindSchnorrCommitment{} }
+func (m *PartiallyBlindSchnorrCommitment) String() string { return proto.CompactTextString(m) }
+func (*PartiallyBlindSchnorrCommitment) ProtoMessage()    {}
+func (*PartiallyBlindSchnorrCommitment) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{66}
+}
+
+func (m *PartiallyBlindSchnorrCommitment) GetInfo() []byte {
+	if m != nil {
+		return m.Info
+	}
+	return nil
+}
+
+func (m *PartiallyBlindSchnorrCommitment) GetA() []byte {
+	if m != nil {
+		return m.A
+	}
+	return nil
+}
+
+func (m *PartiallyBlindSchnorrCommitment) GetB() []byte {
+	if m != nil {
+		return m.B
+	}
+	return nil
+}
+
+// Response of the signer of the partially blind Schnorr signature to the blinded challenge.
+type PartiallyBlindSchnorrResponse struct {
+	R []byte `protobuf:"bytes,1,opt,name=R,proto3" json:"R,omitempty"`
+	C []byte `protobuf:"bytes,2,opt,name=C,proto3" json:"C,omitempty"`
+	S []byte `protobuf:"bytes,3,opt,name=S,proto3" json:"S,omitempty"`
+	D []byte `protobuf:"bytes,4,opt,name=D,proto3" json:"D,omitempty"`
+}
+
+func (m *PartiallyBlindSchnorrResponse) Reset()                    { *m = PartiallyBlindSchnorrResponse{} }
+func (m *PartiallyBlindSchnorrResponse) String() string            { return proto.CompactTextString(m) }
+func (*PartiallyBlindSchnorrResponse) ProtoMessage()               {}
+func (*PartiallyBlindSchnorrResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{67} }
+
+func (m *PartiallyBlindSchnorrResponse) GetR() []byte {
+	if m != nil {
+		return m.R
+	}
+	return nil
+}
+
+func (m *PartiallyBlindSchnorrResponse) GetC() []byte {
+	if m != nil {
+		return m.C
+	}
+	return nil
+}
+
+func (m *PartiallyBlindSchnorrResponse) GetS() []byte {
+	if m != nil {
+		return m.S
+	}
+	return nil
+}
+
+func (m *PartiallyBlindSchnorrResponse) GetD() []byte {
+	if m != nil {
+		return m.D
+	}
+	return nil
+}
+
+// Request for an anonymous credential with the requested attributes (names and values),
+// together with the commitment U to the holder's master secret and the first message T of
+// the proof of knowledge of it.
+type AnonCredsIssueRequest struct {
+	Names  []string `protobuf:"bytes,1,rep,name=Names" json:"Names,omitempty"`
+	Values [][]byte `protobuf:"bytes,2,rep,name=Values,proto3" json:"Values,omitempty"`
+	U      []byte   `protobuf:"bytes,3,opt,name=U,proto3" json:"U,omitempty"`
+	T      []byte   `protobuf:"bytes,4,opt,name=T,proto3" json:"T,omitempty"`
+}
+
+func (m *AnonCredsIssueRequest) Reset()                    { *m = AnonCredsIssueRequest{} }
+func (m *AnonCredsIssueRequest) String() string            { return proto.CompactTextString(m) }
+func (*AnonCredsIssueRequest) ProtoMessage()               {}
+func (*AnonCredsIssueRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{68} }
+
+func (m *AnonCredsIssueRequest) GetNames() []string {
+	if m != nil {
+		return m.Names
+	}
+	return nil
+}
+
+func (m *AnonCredsIssueRequest) GetValues() [][]byte {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+func (m *AnonCredsIssueRequest) GetU() []byte {
+	if m != nil {
+		return m.U
+	}
+	return nil
+}
+
+func (m *AnonCredsIssueRequest) GetT() []byte {
+	if m != nil {
+		return m.T
+	}
+	return nil
+}
+
+// Issuer's part of the signature of the anonymous credential with the attributes which were
+// signed.
+type AnonCredsCredential struct {
+	V      []byte   `protobuf:"bytes,1,opt,name=V,proto3" json:"V,omitempty"`
+	E      []byte   `protobuf:"bytes,2,opt,name=E,proto3" json:"E,omitempty"`
+	S      []byte   `protobuf:"bytes,3,opt,name=S,proto3" json:"S,omitempty"`
+	Names  []string `protobuf:"bytes,4,rep,name=Names" json:"Names,omitempty"`
+	Values [][]byte `protobuf:"bytes,5,rep,name=Values,proto3" json:"Values,omitempty"`
+}
+
+func (m *AnonCredsCredential) Reset()                    { *m = AnonCredsCredential{} }
+func (m *AnonCredsCredential) String() string            { return proto.CompactTextString(m) }
+func (*AnonCredsCredential) ProtoMessage()               {}
+func (*AnonCredsCredential) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{69} }
+
+func (m *AnonCredsCredential) GetV() []byte {
+	if m != nil {
+		return m.V
+	}
+	return nil
+}
+
+func (m *AnonCredsCredential) GetE() []byte {
+	if m != nil {
+		return m.E
+	}
+	return nil
+}
+
+func (m *AnonCredsCredential) GetS() []byte {
+	if m != nil {
+		return m.S
+	}
+	return nil
+}
+
+func (m *AnonCredsCredential) GetNames() []string {
+	if m != nil {
+		return m.Names
+	}
+	return nil
+}
+
+func (m *AnonCredsCredential) GetValues() [][]byte {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+// Attributes which need to be disclosed in the presentation of an anonymous credential and
+// the predicates (attribute, operator and bound) which need to be proved.
+type AnonCredsPresentationRequest struct {
+	Disclosed           []string `protobuf:"bytes,1,rep,name=Disclosed" json:"Disclosed,omitempty"`
+	PredicateAttributes []string `protobuf:"bytes,2,rep,name=PredicateAttributes" json:"PredicateAttributes,omitempty"`
+	PredicateTypes      []string `protobuf:"bytes,3,rep,name=PredicateTypes" json:"PredicateTypes,omitempty"`
+	PredicateBounds     [][]byte `protobuf:"bytes,4,rep,name=PredicateBounds,proto3" json:"PredicateBounds,omitempty"`
+}
+
+func (m *AnonCredsPresentationRequest) Reset()                    { *m = AnonCredsPresentationRequest{} }
+func (m *AnonCredsPresentationRequest) String() string            { return proto.CompactTextString(m) }
+func (*AnonCredsPresentationRequest) ProtoMessage()               {}
+func (*AnonCredsPresentationRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{70} }
+
+func (m *AnonCredsPresentationRequest) GetDisclosed() []string {
+	if m != nil {
+		return m.Disclosed
+	}
+	return nil
+}
+
+func (m *AnonCredsPresentationRequest) GetPredicateAttributes() []string {
+	if m != nil {
+		return m.PredicateAttributes
+	}
+	return nil
+}
+
+func (m *AnonCredsPresentationRequest) GetPredicateTypes() []string {
+	if m != nil {
+		return m.PredicateTypes
+	}
+	return nil
+}
+
+func (m *AnonCredsPresentationRequest) GetPredicateBounds() [][]byte {
+	if m != nil {
+		return m.PredicateBounds
+	}
+	return nil
+}
+
+// Values of the disclosed attributes and the proof random data of the presentation: V and T
+// of the proof of possession, and C, T and the non-negativity proof random data (C1, C2, C3
+// and C1, T1, T2 of each of the four square proofs) of each predicate.
+type AnonCredsProofRandomData struct {
+	DisclosedValues [][]byte `protobuf:"bytes,1,rep,name=DisclosedValues,proto3" json:"DisclosedValues,omitempty"`
+	V               []byte   `protobuf:"bytes,2,opt,name=V,proto3" json:"V,omitempty"`
+	T               []byte   `protobuf:"bytes,3,opt,name=T,proto3" json:"T,omitempty"`
+	Predicates      [][]byte `protobuf:"bytes,4,rep,name=Predicates,proto3" json:"Predicates,omitempty"`
+}
+
+func (m *AnonCredsProofRandomData) Reset()                    { *m = AnonCredsProofRandomData{} }
+func (m *AnonCredsProofRandomData) String() string            { return proto.CompactTextString(m) }
+func (*AnonCredsProofRandomData) ProtoMessage()               {}
+func (*AnonCredsProofRandomData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{71} }
+
+func (m *AnonCredsProofRandomData) GetDisclosedValues() [][]byte {
+	if m != nil {
+		return m.DisclosedValues
+	}
+	return nil
+}
+
+func (m *AnonCredsProofRandomData) GetV() []byte {
+	if m != nil {
+		return m.V
+	}
+	return nil
+}
+
+func (m *AnonCredsProofRandomData) GetT() []byte {
+	if m != nil {
+		return m.T
+	}
+	return nil
+}
+
+func (m *AnonCredsProofRandomData) GetPredicates() [][]byte {
+	if m != nil {
+		return m.Predicates
+	}
+	return nil
+}
+
+// Responses of the presentation: ZE, ZS and the responses for the hidden blocks (in
+// the order of the blocks) of the proof of possession, and ZR and the responses (Z, W1, W2)
+// of each of the four square proofs of each predicate.
+type AnonCredsProofData struct {
+	ZE         []byte   `protobuf:"bytes,1,opt,name=ZE,proto3" json:"ZE,omitempty"`
+	ZS         []byte   `protobuf:"bytes,2,opt,name=ZS,proto3" json:"ZS,omitempty"`
+	ZM         [][]byte `protobuf:"bytes,3,rep,name=ZM,proto3" json:"ZM,omitempty"`
+	Predicates [][]byte `protobuf:"bytes,4,rep,name=Predicates,proto3" json:"Predicates,omitempty"`
+}
+
+func (m *AnonCredsProofData) Reset()                    { *m = AnonCredsProofData{} }
+func (m *AnonCredsProofData) String() string            { return proto.CompactTextString(m) }
+func (*AnonCredsProofData) ProtoMessage()               {}
+func (*AnonCredsProofData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{72} }
+
+func (m *AnonCredsProofData) GetZE() []byte {
+	if m != nil {
+		return m.ZE
+	}
+	return nil
+}
+
+func (m *AnonCredsProofData) GetZS() []byte {
+	if m != nil {
+		return m.ZS
+	}
+	return nil
+}
+
+func (m *AnonCredsProofData) GetZM() [][]byte {
+	if m != nil {
+		return m.ZM
+	}
+	return nil
+}
+
+func (m *AnonCredsProofData) GetPredicates() [][]byte {
+	if m != nil {
+		return m.Predicates
+	}
+	return nil
+}
+
+// Attribute embedded into pseudonymsys credential with the randomness of its commitment,
+// the organization's signature D of the commitment and the proof random data of the proof
+// that the signature is valid.
+type PseudonymsysIssuedAttribute struct {
+	Name  string `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty"`
+	Value []byte `protobuf:"bytes,2,opt,name=Value,proto3" json:"Value,omitempty"`
+	R     []byte `protobuf:"bytes,3,opt,name=R,proto3" json:"R,omitempty"`
+	D     []byte `protobuf:"bytes,4,opt,name=D,proto3" json:"D,omitempty"`
+	X1    []byte `protobuf:"bytes,5,opt,name=X1,proto3" json:"X1,omitempty"`
+	X2    []byte `protobuf:"bytes,6,opt,name=X2,proto3" json:"X2,omitempty"`
+}
+
+func (m *PseudonymsysIssuedAttribute) Reset()                    { *m = PseudonymsysIssuedAttribute{} }
+func (m *PseudonymsysIssuedAttribute) String() string            { return proto.CompactTextString(m) }
+func (*PseudonymsysIssuedAttribute) ProtoMessage()               {}
+func (*PseudonymsysIssuedAttribute) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{73} }
+
+func (m *PseudonymsysIssuedAttribute) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *PseudonymsysIssuedAttribute) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *PseudonymsysIssuedAttribute) GetR() []byte {
+	if m != nil {
+		return m.R
+	}
+	return nil
+}
+
+func (m *PseudonymsysIssuedAttribute) GetD() []byte {
+	if m != nil {
+		return m.D
+	}
+	return nil
+}
+
+func (m *PseudonymsysIssuedAttribute) GetX1() []byte {
+	if m != nil {
+		return m.X1
+	}
+	return nil
+}
+
+func (m *PseudonymsysIssuedAttribute) GetX2() []byte {
+	if m != nil {
+		return m.X2
+	}
+	return nil
+}
+
+// Attribute of the (blinded) pseudonymsys credential - the value and the randomness of
+// the commitment are given only for the disclosed attributes, the commitment V only for
+// the hidden ones.
+type PseudonymsysCredentialAttribute struct {
+	Name      string                  `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty"`
+	Disclosed bool                    `protobuf:"varint,2,opt,name=Disclosed" json:"Disclosed,omitempty"`
+	Value     []byte                  `protobuf:"bytes,3,opt,name=Value,proto3" json:"Value,omitempty"`
+	R         []byte                  `protobuf:"bytes,4,opt,name=R,proto3" json:"R,omitempty"`
+	V         []byte                  `protobuf:"bytes,5,opt,name=V,proto3" json:"V,omitempty"`
+	D         []byte                  `protobuf:"bytes,6,opt,name=D,proto3" json:"D,omitempty"`
+	T         *PseudonymsysTranscript `protobuf:"bytes,7,opt,name=T" json:"T,omitempty"`
+}
+
+func (m *PseudonymsysCredentialAttribute) Reset()         { *m = PseudonymsysCredentialAttribute{} }
+func (m *PseudonymsysCredentialAttribute) String() string { return proto.CompactTextString(m) }
+func (*PseudonymsysCredentialAttribute) ProtoMessage()    {}
+func (*PseudonymsysCredentialAttribute) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{74}
+}
+
+func (m *PseudonymsysCredentialAttribute) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *PseudonymsysCredentialAttribute) GetDisclosed() bool {
+	if m != nil {
+		return m.Disclosed
+	}
+	return false
+}
+
+func (m *PseudonymsysCredentialAttribute) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *PseudonymsysCredentialAttribute) GetR() []byte {
+	if m != nil {
+		return m.R
+	}
+	return nil
+}
+
+func (m *PseudonymsysCredentialAttribute) GetV() []byte {
+	if m != nil {
+		return m.V
+	}
+	return nil
+}
+
+func (m *PseudonymsysCredentialAttribute) GetD() []byte {
+	if m != nil {
+		return m.D
+	}
+	return nil
+}
+
+func (m *PseudonymsysCredentialAttribute) GetT() *PseudonymsysTranscript {
+	if m != nil {
+		return m.T
+	}
+	return nil
+}
+
+type RepeatedBigInt struct {
+	X [][]byte `protobuf:"bytes,1,rep,name=X,proto3" json:"X,omitempty"`
+}
+
+func (m *RepeatedBigInt) Reset()                    { *m = RepeatedBigInt{} }
+func (m *RepeatedBigInt) String() string            { return proto.CompactTextString(m) }
+func (*RepeatedBigInt) ProtoMessage()               {}
+func (*RepeatedBigInt) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{75} }
+
+func (m *RepeatedBigInt) GetX() [][]byte {
+	if m != nil {
+		return m.X
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Message)(nil), "protobuf.Message")
 	proto.RegisterType((*EmptyMsg)(nil), "protobuf.EmptyMsg")
@@ -2371,140 +5116,326 @@ func init() {
 	proto.RegisterType((*CSPaillierProofRandomData)(nil), "protobuf.CSPaillierProofRandomData")
 	proto.RegisterType((*CSPaillierProofData)(nil), "protobuf.CSPaillierProofData")
 	proto.RegisterType((*SessionKey)(nil), "protobuf.SessionKey")
+	proto.RegisterType((*PseudonymsysRateLimitData)(nil), "protobuf.PseudonymsysRateLimitData")
+	proto.RegisterType((*ExtensionMsg)(nil), "protobuf.ExtensionMsg")
+	proto.RegisterType((*PseudonymsysCARequest)(nil), "protobuf.PseudonymsysCARequest")
+	proto.RegisterType((*SignedCertificateTimestamp)(nil), "protobuf.SignedCertificateTimestamp")
+	proto.RegisterType((*PseudonymsysCAStatus)(nil), "protobuf.PseudonymsysCAStatus")
+	proto.RegisterType((*HybridKEMInit)(nil), "protobuf.HybridKEMInit")
+	proto.RegisterType((*HybridKEMResponse)(nil), "protobuf.HybridKEMResponse")
+	proto.RegisterType((*EncryptedMsg)(nil), "protobuf.EncryptedMsg")
+	proto.RegisterType((*BitProofRandomData)(nil), "protobuf.BitProofRandomData")
+	proto.RegisterType((*BitProofData)(nil), "protobuf.BitProofData")
+	proto.RegisterType((*RangeProofRandomData)(nil), "protobuf.RangeProofRandomData")
+	proto.RegisterType((*RangeProofData)(nil), "protobuf.RangeProofData")
+	proto.RegisterType((*Trapdoor)(nil), "protobuf.Trapdoor")
+	proto.RegisterType((*AttestationRequest)(nil), "protobuf.AttestationRequest")
+	proto.RegisterType((*AttestationEvidence)(nil), "protobuf.AttestationEvidence")
+	proto.RegisterType((*PaillierPlaintextProofRandomData)(nil), "protobuf.PaillierPlaintextProofRandomData")
+	proto.RegisterType((*PaillierPlaintextProofData)(nil), "protobuf.PaillierPlaintextProofData")
+	proto.RegisterType((*PseudonymsysNymEscrowData)(nil), "protobuf.PseudonymsysNymEscrowData")
+	proto.RegisterType((*RevocationSnapshot)(nil), "protobuf.RevocationSnapshot")
+	proto.RegisterType((*RevocationSubscription)(nil), "protobuf.RevocationSubscription")
+	proto.RegisterType((*RevocationUpdate)(nil), "protobuf.RevocationUpdate")
+	proto.RegisterType((*GPSProofRandomData)(nil), "protobuf.GPSProofRandomData")
+	proto.RegisterType((*NymRegistryRoot)(nil), "protobuf.NymRegistryRoot")
+	proto.RegisterType((*NymInclusionProof)(nil), "protobuf.NymInclusionProof")
+	proto.RegisterType((*SternCommitment)(nil), "protobuf.SternCommitment")
+	proto.RegisterType((*SternProofRandomData)(nil), "protobuf.SternProofRandomData")
+	proto.RegisterType((*SternResponse)(nil), "protobuf.SternResponse")
+	proto.RegisterType((*SternProofData)(nil), "protobuf.SternProofData")
+	proto.RegisterType((*AbuseReport)(nil), "protobuf.AbuseReport")
+	proto.RegisterType((*LatticeShortVectorProof)(nil), "protobuf.LatticeShortVectorProof")
+	proto.RegisterType((*ThresholdSchnorrRequest)(nil), "protobuf.ThresholdSchnorrRequest")
+	proto.RegisterType((*PartiallyBlindSchnorrRequest)(nil), "protobuf.PartiallyBlindSchnorrRequest")
+	proto.RegisterType((*PartiallyBlindSchnorrCommitment)(nil), "protobuf.PartiallyBlindSchnorrCommitment")
+	proto.RegisterType((*PartiallyBlindSchnorrResponse)(nil), "protobuf.PartiallyBlindSchnorrResponse")
+	proto.RegisterType((*AnonCredsIssueRequest)(nil), "protobuf.AnonCredsIssueRequest")
+	proto.RegisterType((*AnonCredsCredential)(nil), "protobuf.AnonCredsCredential")
+	proto.RegisterType((*AnonCredsPresentationRequest)(nil), "protobuf.AnonCredsPresentationRequest")
+	proto.RegisterType((*AnonCredsProofRandomData)(nil), "protobuf.AnonCredsProofRandomData")
+	proto.RegisterType((*AnonCredsProofData)(nil), "protobuf.AnonCredsProofData")
+	proto.RegisterType((*PseudonymsysIssuedAttribute)(nil), "protobuf.PseudonymsysIssuedAttribute")
+	proto.RegisterType((*PseudonymsysCredentialAttribute)(nil), "protobuf.PseudonymsysCredentialAttribute")
+	proto.RegisterType((*RepeatedBigInt)(nil), "protobuf.RepeatedBigInt")
 }
 
 func init() { proto.RegisterFile("messages.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 2068 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe4, 0x58, 0x4f, 0x73, 0x1b, 0x49,
-	0x15, 0x47, 0xb2, 0xad, 0xd8, 0x6d, 0xd9, 0x38, 0x6d, 0xc7, 0x3b, 0x8e, 0x37, 0x29, 0x67, 0x92,
-	0x0d, 0x26, 0x18, 0x17, 0x52, 0x52, 0x1c, 0x28, 0x48, 0xad, 0x24, 0x0b, 0xdb, 0x1b, 0xdb, 0xeb,
-	0x1d, 0xd9, 0x5e, 0x3b, 0x55, 0x94, 0x18, 0x8f, 0xda, 0xca, 0x54, 0x49, 0x33, 0x62, 0x46, 0x32,
-	0xe5, 0x2a, 0x0e, 0x4b, 0x51, 0x05, 0x9c, 0x39, 0x70, 0xe1, 0xc0, 0x05, 0xbe, 0x01, 0x57, 0x4e,
-	0x5c, 0xf8, 0x08, 0x54, 0xf1, 0x1d, 0xf8, 0x0c, 0xbc, 0x7e, 0xdd, 0x3d, 0xd3, 0x23, 0x8d, 0x67,
-	0x94, 0xf3, 0x9e, 0xa6, 0xdf, 0xeb, 0xdf, 0xfb, 0xd3, 0xaf, 0x5f, 0xbf, 0x79, 0xdd, 0x64, 0xb9,
-	0xcf, 0xc2, 0xd0, 0xee, 0xb2, 0x70, 0x77, 0x10, 0xf8, 0x43, 0x9f, 0xce, 0xe3, 0xe7, 0x7a, 0x74,
-	0xf3, 0x78, 0x91, 0x79, 0xa3, 0xbe, 0x64, 0x9b, 0x7f, 0x59, 0x25, 0x0f, 0x8e, 0x05, 0x92, 0xee,
-	0x90, 0x52, 0xe8, 0x7c, 0x60, 0x7d, 0xdb, 0x28, 0x6c, 0x15, 0xb6, 0x97, 0xab, 0x6b, 0xbb, 0x4a,
-	0x66, 0xb7, 0x85, 0xfc, 0xb3, 0xbb, 0x01, 0xb3, 0x24, 0x86, 0xbe, 0x25, 0xcb, 0x62, 0xd4, 0xbe,
-	0xb5, 0x03, 0xd7, 0xf6, 0x86, 0x46, 0x11, 0xa5, 0x3e, 0x19, 0x97, 0xba, 0x10, 0xd3, 0xd6, 0x52,
-	0xa8, 0x93, 0xf4, 0x15, 0x99, 0x63, 0xfd, 0xc1, 0xf0, 0xce, 0x98, 0x01, 0xb1, 0xc5, 0x2a, 0x8d,
-	0xc5, 0x9a, 0x9c, 0x7d, 0x1c, 0x76, 0x0f, 0xbe, 0x63, 0x09, 0x08, 0x60, 0x4b, 0xd7, 0x6e, 0xd7,
-	0x05, 0x1b, 0xb3, 0x08, 0x5e, 0x89, 0xc1, 0x75, 0xb7, 0x7b, 0xe8, 0x0d, 0x01, 0x2a, 0x11, 0x74,
-	0x8f, 0xac, 0x30, 0xa7, 0xdd, 0x0d, 0xfc, 0xd1, 0xa0, 0xcd, 0x7a, 0xac, 0xcf, 0x40, 0x6a, 0x0e,
-	0xa5, 0x0c, 0xcd, 0x44, 0x63, 0x9f, 0x03, 0x9a, 0x62, 0x1e, 0xa4, 0x97, 0x99, 0xa3, 0x73, 0xb8,
-	0xc5, 0x70, 0x68, 0x0f, 0x47, 0xa1, 0x51, 0x1a, 0xb7, 0xd8, 0x42, 0x3e, 0xb7, 0x28, 0x10, 0xf4,
-	0x73, 0xb2, 0x3c, 0x60, 0x1d, 0x16, 0x84, 0xcc, 0x6b, 0xdf, 0xb8, 0x41, 0x38, 0x34, 0x1e, 0xa0,
-	0x8c, 0x16, 0x89, 0x53, 0x39, 0xff, 0x73, 0x3e, 0x0d, 0xa2, 0x4b, 0x03, 0x9d, 0x41, 0xcf, 0xc9,
-	0xa3, 0x48, 0x43, 0x87, 0x39, 0x7e, 0xbf, 0xef, 0x0e, 0xd1, 0xf1, 0x79, 0x54, 0xf4, 0x74, 0x52,
-	0xd1, 0x9e, 0x86, 0x02, 0x7d, 0x6b, 0x83, 0x14, 0x3e, 0xfd, 0x82, 0x50, 0x88, 0xb9, 0xe7, 0x07,
-	0x41, 0x1b, 0x14, 0xf8, 0x37, 0xed, 0x8e, 0x3d, 0xb4, 0x8d, 0x05, 0xd4, 0xf9, 0x38, 0xb1, 0x4d,
-	0x1c, 0x73, 0xca, 0x21, 0x7b, 0x80, 0x00, 0x7d, 0x2b, 0xe1, 0x18, 0x8f, 0xfe, 0x82, 0x6c, 0x24,
-	0x75, 0x05, 0xb6, 0xd7, 0xf1, 0xfb, 0x42, 0x25, 0x41, 0x95, 0x5b, 0xe9, 0x2a, 0x2d, 0x04, 0x4a,
-	0xc5, 0xeb, 0x61, 0xea, 0x0c, 0xed, 0x90, 0x4f, 0x95, 0x7a, 0xd8, 0xbd, 0x49, 0x0b, 0x8b, 0x68,
-	0xc1, 0x9c, 0xb0, 0xd0, 0x6c, 0x4c, 0xda, 0x30, 0xa4, 0xa6, 0xa6, 0x33, 0x6e, 0xe5, 0x98, 0xac,
-	0x3a, 0x61, 0x7b, 0x60, 0xbb, 0xbd, 0x9e, 0xcb, 0x82, 0xb6, 0x3f, 0x60, 0x9e, 0xeb, 0x75, 0x8d,
-	0x32, 0x2a, 0xdf, 0x8c, 0x95, 0x37, 0x5a, 0xa7, 0x12, 0xf3, 0xa5, 0x80, 0x80, 0xd6, 0x87, 0x4e,
-	0x38, 0xc6, 0xa4, 0x67, 0x64, 0x5d, 0x57, 0xa7, 0xc5, 0x78, 0x09, 0x35, 0x3e, 0x49, 0xd3, 0xa8,
-	0x87, 0x79, 0x35, 0xd6, 0x19, 0x47, 0xba, 0x4b, 0x9e, 0x4c, 0x6a, 0xd5, 0x63, 0xb1, 0x8c, 0xca,
-	0x9f, 0xdf, 0xab, 0x3c, 0x11, 0x8c, 0x8d, 0x31, 0x13, 0x5a, 0x34, 0x18, 0xd9, 0x1c, 0x84, 0x6c,
-	0xd4, 0xf1, 0xbd, 0xbb, 0x7e, 0x78, 0x17, 0xb6, 0x1d, 0xbb, 0xed, 0xb0, 0x60, 0xe8, 0xde, 0xb8,
-	0x8e, 0x3d, 0x64, 0xc6, 0x77, 0xc7, 0xcd, 0x9c, 0x6a, 0xe0, 0x46, 0xad, 0x11, 0x43, 0xb9, 0x19,
-	0x5d, 0x53, 0xc3, 0xd6, 0x26, 0xe9, 0x37, 0x05, 0xf2, 0x32, 0x61, 0x07, 0x3e, 0xed, 0x2e, 0x64,
-	0xfa, 0xe4, 0xca, 0x56, 0xd0, 0xe4, 0x0f, 0xd2, 0x4d, 0x9e, 0xdc, 0xf5, 0xf7, 0x99, 0x37, 0xb9,
-	0xc2, 0x67, 0x83, 0x3c, 0x10, 0xfd, 0x0d, 0x79, 0x91, 0xf0, 0xc0, 0x0d, 0xc3, 0x11, 0x4b, 0xb1,
-	0xff, 0x10, 0xed, 0xbf, 0x4a, 0xb7, 0x7f, 0xc8, 0x85, 0x26, 0xcd, 0x6f, 0x0d, 0x72, 0x30, 0xf4,
-	0x67, 0x64, 0xa9, 0xe3, 0x8f, 0xae, 0x7b, 0xac, 0x2d, 0x8b, 0x18, 0x45, 0x33, 0xeb, 0xb1, 0x99,
-	0x3d, 0x9c, 0x8e, 0x4a, 0x59, 0xb9, 0xa3, 0x68, 0x5e, 0xd0, 0x7e, 0x5b, 0x20, 0x9f, 0x25, 0xbc,
-	0x1f, 0x82, 0xcb, 0xe1, 0x0d, 0xa4, 0x86, 0x13, 0xc0, 0xa9, 0xf7, 0x86, 0xae, 0xdd, 0x13, 0xee,
-	0xaf, 0xa2, 0xde, 0x9d, 0x74, 0xf7, 0xcf, 0xa4, 0x54, 0x23, 0x12, 0x92, 0x0b, 0x30, 0x07, 0xb9,
-	0x28, 0xda, 0x23, 0x4f, 0x33, 0x52, 0x05, 0x8e, 0xac, 0xb1, 0x86, 0xb6, 0x3f, 0x9b, 0x22, 0x5b,
-	0x9a, 0x0d, 0x30, 0xba, 0x79, 0x6f, 0xbe, 0x34, 0x1d, 0xfa, 0x87, 0x02, 0xf9, 0xfe, 0x74, 0x19,
-	0xc3, 0x2d, 0x3f, 0x42, 0xcb, 0x3f, 0xfc, 0x88, 0xa4, 0x41, 0x0f, 0x9e, 0xe7, 0xa6, 0x0d, 0x78,
-	0xf2, 0xbb, 0x02, 0xf9, 0xde, 0x34, 0x99, 0xc3, 0xfd, 0x58, 0xcf, 0x8a, 0x7e, 0x5a, 0x62, 0xa0,
-	0x1b, 0x66, 0x5e, 0xfa, 0x80, 0x17, 0x7f, 0x2c, 0x90, 0xed, 0xa9, 0x32, 0x80, 0xbb, 0xf1, 0x09,
-	0xba, 0xb1, 0xfb, 0x31, 0x49, 0x80, 0x8e, 0xbc, 0xc8, 0x4f, 0x03, 0x70, 0xe5, 0x82, 0xac, 0xff,
-	0xca, 0x0b, 0xda, 0xb7, 0x2c, 0x80, 0xed, 0xe2, 0x0e, 0x7c, 0xb0, 0x7b, 0x3d, 0xe6, 0x75, 0x99,
-	0x61, 0x8c, 0xff, 0xaa, 0xbe, 0x3a, 0xb1, 0x2e, 0x24, 0xac, 0xa1, 0x50, 0xfc, 0x57, 0x05, 0xf2,
-	0x13, 0x7c, 0xfa, 0x13, 0x52, 0x0e, 0xd8, 0x80, 0xc1, 0xfe, 0x77, 0xda, 0xfc, 0x88, 0x6c, 0xa0,
-	0xb6, 0x47, 0xb1, 0x36, 0x4b, 0xce, 0x8a, 0x13, 0xb2, 0x18, 0xc4, 0x24, 0x3f, 0x5f, 0x91, 0x2c,
-	0x94, 0xcd, 0xc0, 0x78, 0x3c, 0x7e, 0xbe, 0x94, 0x30, 0x54, 0xc2, 0x80, 0x9f, 0xaf, 0x40, 0xa3,
-	0xe9, 0x1a, 0x99, 0x6d, 0x72, 0x93, 0x9b, 0x20, 0x35, 0x07, 0xb3, 0x48, 0xd1, 0x1f, 0x13, 0xd2,
-	0x82, 0xbe, 0xc8, 0xf5, 0xbd, 0x77, 0xec, 0xce, 0x78, 0x8a, 0x1a, 0xf5, 0x86, 0x28, 0x9a, 0x03,
-	0x09, 0x0d, 0x49, 0x1f, 0x93, 0x79, 0x07, 0x8a, 0xad, 0x37, 0x3c, 0xec, 0x18, 0x9f, 0x72, 0x8d,
-	0x56, 0x44, 0xd3, 0x17, 0x64, 0xe9, 0x94, 0x2b, 0x70, 0xfc, 0x5e, 0x33, 0x08, 0xfc, 0xc0, 0x78,
-	0x02, 0x80, 0x05, 0x2b, 0xc9, 0xac, 0x2f, 0x90, 0x07, 0x8e, 0xef, 0x0d, 0x41, 0xc4, 0x24, 0x64,
-	0x5e, 0x35, 0x43, 0x66, 0x9b, 0x2c, 0xb6, 0x58, 0x70, 0xeb, 0x3a, 0xec, 0xd0, 0xbb, 0xf1, 0x29,
-	0x25, 0xb3, 0x9e, 0xdd, 0x67, 0xd8, 0xaa, 0x2d, 0x58, 0x38, 0xa6, 0x5b, 0x64, 0xb1, 0xc3, 0x42,
-	0x27, 0x70, 0x07, 0x43, 0xf0, 0x06, 0xfb, 0xb1, 0x05, 0x4b, 0x67, 0x71, 0xef, 0x60, 0x09, 0xb7,
-	0x2e, 0x34, 0x0b, 0xd8, 0x77, 0x2d, 0x58, 0x11, 0x6d, 0x9a, 0xa4, 0x24, 0x5a, 0x1b, 0x6a, 0x90,
-	0x07, 0xad, 0x91, 0xe3, 0xc0, 0xa2, 0x50, 0xfd, 0xbc, 0xa5, 0x48, 0xd3, 0x20, 0x25, 0x51, 0xa5,
-	0xe8, 0x32, 0x29, 0x5e, 0x56, 0x70, 0xba, 0x6c, 0xc1, 0xc8, 0xdc, 0x25, 0x65, 0xbd, 0x8a, 0x8d,
-	0xcf, 0x23, 0x5d, 0x45, 0x97, 0x38, 0x5d, 0x35, 0x9f, 0x40, 0x2c, 0x12, 0x3d, 0x50, 0x99, 0x14,
-	0x0e, 0x24, 0xbe, 0x70, 0x60, 0x56, 0xc9, 0x5a, 0x5a, 0xab, 0xc3, 0x51, 0x97, 0x0a, 0x75, 0xc9,
-	0x29, 0x4b, 0xea, 0x2c, 0x58, 0xe6, 0x0e, 0x59, 0x4e, 0xf6, 0x75, 0x93, 0xe8, 0x2b, 0x85, 0xbe,
-	0x82, 0xe5, 0xce, 0xe2, 0xf6, 0x03, 0xb7, 0xa6, 0x30, 0x35, 0x4e, 0xd5, 0x15, 0xa6, 0x6e, 0xd6,
-	0xc9, 0x7a, 0x7a, 0x27, 0x33, 0xa9, 0xb9, 0xa6, 0xa4, 0xa4, 0x8e, 0x19, 0xa5, 0xe3, 0x4f, 0x05,
-	0x62, 0xdc, 0xd7, 0xac, 0xd0, 0x97, 0x4a, 0x4d, 0x46, 0x77, 0xca, 0x0d, 0xbc, 0x54, 0x06, 0x32,
-	0x71, 0x35, 0x8e, 0xab, 0xcb, 0x86, 0x3a, 0x03, 0x57, 0x37, 0x7f, 0x4a, 0x56, 0xc6, 0xbb, 0x3e,
-	0xee, 0xf6, 0x7b, 0xb5, 0xa4, 0xf7, 0x3c, 0x53, 0xa0, 0x08, 0x0c, 0x3a, 0x3e, 0xa4, 0xa9, 0x58,
-	0x59, 0x44, 0x9b, 0x7f, 0x2d, 0x90, 0x67, 0xb9, 0x45, 0x36, 0x2d, 0x03, 0x6a, 0x15, 0x95, 0x01,
-	0x35, 0xa4, 0xeb, 0x15, 0x19, 0x27, 0x18, 0xc9, 0x0c, 0x99, 0x55, 0x19, 0x82, 0xf8, 0x2a, 0xb6,
-	0xee, 0x1c, 0x8f, 0x74, 0xbd, 0x8a, 0xed, 0x38, 0xc7, 0x57, 0xc5, 0xe6, 0x3f, 0x90, 0x9b, 0xcf,
-	0xa9, 0x16, 0xb6, 0xcb, 0x40, 0xb5, 0xcc, 0x7f, 0x16, 0xc9, 0xf3, 0x29, 0x7e, 0x03, 0x74, 0x3b,
-	0xf2, 0x31, 0x2b, 0x60, 0xdc, 0xfb, 0xed, 0xc8, 0xfb, 0x4c, 0x64, 0x0d, 0x91, 0x72, 0x5d, 0x99,
-	0xc8, 0x3a, 0x22, 0xe5, 0x8a, 0xb3, 0xad, 0x57, 0xd1, 0x7a, 0x35, 0xef, 0x1a, 0x83, 0x51, 0xda,
-	0x8e, 0xa2, 0x94, 0x6d, 0x3d, 0x3b, 0x7e, 0x3e, 0xd9, 0xb8, 0xf7, 0xff, 0xcd, 0x53, 0xa3, 0xde,
-	0x73, 0xbd, 0x0e, 0xeb, 0xa8, 0x83, 0x13, 0xd1, 0xda, 0x9c, 0x3a, 0x46, 0x11, 0x2d, 0x0c, 0xce,
-	0x24, 0x0c, 0xce, 0x2a, 0x83, 0x7f, 0x2f, 0x90, 0xcd, 0x8c, 0x8e, 0x81, 0xbe, 0x19, 0xb3, 0x99,
-	0xb5, 0xb8, 0xd8, 0x9b, 0x37, 0x63, 0xde, 0x4c, 0x23, 0x95, 0xed, 0xe7, 0xef, 0x0b, 0x64, 0x2b,
-	0xef, 0xbf, 0x4e, 0x57, 0xc8, 0xcc, 0x65, 0x45, 0xa5, 0x3e, 0x1f, 0x0a, 0x8e, 0x2a, 0x7f, 0x7c,
-	0x88, 0x9c, 0xaa, 0x4a, 0x7f, 0x3e, 0x14, 0x1c, 0x75, 0x00, 0xf8, 0x50, 0x94, 0x95, 0xb9, 0x44,
-	0x59, 0x29, 0xa9, 0xb2, 0xf2, 0xb7, 0x22, 0x31, 0xf3, 0x1b, 0x0c, 0xb8, 0xc7, 0x46, 0xae, 0x64,
-	0x2d, 0x1e, 0x9d, 0x7c, 0x15, 0x3b, 0x99, 0x83, 0xad, 0x22, 0xb6, 0x9a, 0x9f, 0xe5, 0xb8, 0xb0,
-	0x57, 0xf1, 0xc2, 0x72, 0xb0, 0x55, 0x51, 0xe8, 0xe6, 0xa6, 0x2c, 0x74, 0xa5, 0xfc, 0x42, 0xf7,
-	0x4b, 0xb2, 0x3e, 0xd1, 0xff, 0xe0, 0xdf, 0x30, 0xab, 0xee, 0xf3, 0x9f, 0xeb, 0x81, 0x1d, 0x7e,
-	0x90, 0xbb, 0x83, 0x63, 0xba, 0x4e, 0x4a, 0xef, 0x6b, 0xbd, 0xc1, 0x07, 0x5b, 0xee, 0x90, 0xa4,
-	0xcc, 0x3f, 0x43, 0x7d, 0x4f, 0x37, 0x01, 0xe1, 0x7f, 0xa9, 0x8c, 0x4c, 0xb3, 0x9c, 0xdc, 0xfa,
-	0xfe, 0x71, 0x8e, 0x7d, 0x53, 0x4c, 0xae, 0x3d, 0xee, 0xe5, 0x78, 0x23, 0xd2, 0xea, 0x43, 0xeb,
-	0x55, 0x3b, 0xf3, 0xf7, 0xed, 0xbe, 0x7c, 0xf0, 0x29, 0x5b, 0x49, 0x66, 0x84, 0xaa, 0x2b, 0x54,
-	0x51, 0x43, 0x29, 0x26, 0x3f, 0xf1, 0x91, 0x1a, 0xe1, 0x56, 0x44, 0x63, 0x35, 0x50, 0x73, 0xb3,
-	0xb2, 0x1a, 0xa8, 0xb9, 0x1f, 0x91, 0xe2, 0x59, 0x45, 0x6e, 0xf5, 0x56, 0x46, 0xb7, 0x8a, 0xa1,
-	0xb4, 0x00, 0x8b, 0x12, 0xaa, 0xb4, 0x4d, 0x23, 0x51, 0x35, 0xff, 0x57, 0x4c, 0xee, 0x4d, 0x1c,
-	0x02, 0xd8, 0x9b, 0xb7, 0x69, 0x41, 0xc8, 0x8a, 0xff, 0x58, 0x78, 0xde, 0xa6, 0x85, 0x27, 0x5f,
-	0x3e, 0x0a, 0xc0, 0x9b, 0xb1, 0xc0, 0x65, 0x16, 0xa7, 0x9a, 0x26, 0x95, 0x08, 0x69, 0x76, 0x49,
-	0x53, 0x52, 0x55, 0x2d, 0xd8, 0x66, 0x5e, 0xe8, 0x9a, 0x0d, 0x0c, 0x77, 0x55, 0x0b, 0xf7, 0x74,
-	0x32, 0x55, 0xf3, 0xdf, 0x85, 0x64, 0x55, 0xba, 0xe7, 0x3a, 0x09, 0x0d, 0xe6, 0x97, 0x41, 0xf7,
-	0x24, 0xee, 0x5f, 0x15, 0x29, 0x9b, 0x86, 0xe2, 0x58, 0xdb, 0x38, 0x13, 0x35, 0x05, 0x70, 0x00,
-	0xe0, 0x5f, 0x5e, 0x93, 0xd9, 0x84, 0x63, 0xc9, 0xab, 0xcb, 0x4a, 0x89, 0x63, 0xfa, 0x39, 0x21,
-	0xb1, 0xcd, 0xec, 0x9c, 0x89, 0x71, 0x96, 0x26, 0x63, 0xfe, 0xa3, 0x48, 0x5e, 0x4c, 0x73, 0x75,
-	0xca, 0x58, 0xcc, 0x76, 0xb4, 0x98, 0x29, 0xba, 0x0b, 0xb9, 0xcc, 0xbc, 0x4e, 0x60, 0x47, 0x0b,
-	0x40, 0x16, 0x56, 0x84, 0x66, 0x47, 0x0b, 0x4d, 0x1e, 0xba, 0x4e, 0xeb, 0x29, 0x41, 0x33, 0xf3,
-	0x82, 0x06, 0x3b, 0xaf, 0x87, 0xed, 0x0b, 0xb2, 0x96, 0x76, 0xf1, 0xe3, 0x05, 0xf6, 0x6b, 0x55,
-	0x6e, 0xbf, 0x86, 0xd2, 0x32, 0xc7, 0x9b, 0xef, 0x10, 0x82, 0x33, 0x03, 0x46, 0x96, 0x35, 0x23,
-	0xc0, 0xb6, 0xc4, 0xa4, 0xf9, 0x8c, 0x2c, 0x6a, 0xd7, 0x3e, 0xbe, 0xcf, 0xf0, 0xe1, 0x77, 0x92,
-	0x19, 0xb8, 0x56, 0xe1, 0xd8, 0x7c, 0x43, 0xca, 0xfa, 0xe5, 0x2e, 0x56, 0x5c, 0xc8, 0x52, 0xfc,
-	0xdf, 0x22, 0x59, 0x8d, 0x1f, 0xcd, 0x5a, 0x0c, 0xae, 0xd1, 0x43, 0x7e, 0x79, 0x03, 0x27, 0x4f,
-	0x94, 0x93, 0x27, 0x9c, 0xda, 0x57, 0xff, 0x84, 0x7d, 0x99, 0x99, 0x33, 0x63, 0x99, 0x99, 0x68,
-	0x57, 0x2f, 0x5f, 0xab, 0x76, 0xf5, 0xf2, 0x35, 0x5c, 0x2b, 0xe7, 0xf6, 0x8e, 0xfc, 0xee, 0xa9,
-	0xfc, 0x65, 0x0b, 0x42, 0x71, 0xf7, 0x65, 0xe3, 0x25, 0x08, 0xc5, 0xfd, 0x4a, 0x36, 0x60, 0x82,
-	0x80, 0x7a, 0xb7, 0x2a, 0xe2, 0x68, 0xc3, 0xb5, 0xaa, 0xe9, 0x89, 0x07, 0xea, 0x13, 0x7c, 0xbf,
-	0x2d, 0x5b, 0x69, 0x53, 0x70, 0x64, 0xd7, 0x26, 0xd9, 0xfb, 0x15, 0x7c, 0x9f, 0x2d, 0x5b, 0xa9,
-	0x73, 0xe9, 0x32, 0x07, 0x15, 0x7c, 0x71, 0x4d, 0x95, 0x39, 0xa8, 0xf0, 0xc8, 0xbc, 0xc3, 0x57,
-	0xd3, 0x39, 0xab, 0xf0, 0x8e, 0xaf, 0xfc, 0x5d, 0x05, 0x9f, 0x3c, 0xe7, 0x2c, 0x18, 0x99, 0xff,
-	0x29, 0x92, 0x15, 0xed, 0x49, 0x72, 0x74, 0x3d, 0x45, 0x68, 0xaf, 0xa2, 0xd0, 0x5e, 0x61, 0x68,
-	0xaf, 0xa2, 0xd0, 0x5e, 0x61, 0x68, 0xaf, 0xa2, 0xd0, 0x5e, 0x7d, 0x9b, 0x43, 0xfb, 0x6b, 0xf2,
-	0x70, 0xe2, 0x6d, 0x9a, 0x8b, 0x9c, 0xab, 0xd0, 0x9e, 0x73, 0xaa, 0xa9, 0x42, 0xdb, 0xe4, 0xd4,
-	0x85, 0xea, 0x65, 0x2f, 0x30, 0x18, 0xac, 0x37, 0x54, 0x3f, 0x63, 0x41, 0x70, 0xee, 0x91, 0x7d,
-	0xcd, 0x7a, 0x32, 0xc2, 0x82, 0xe0, 0x92, 0x47, 0xaa, 0xdd, 0x3c, 0x32, 0x43, 0xb2, 0x71, 0xef,
-	0x2b, 0x33, 0xf7, 0xf2, 0x3c, 0xba, 0xe9, 0x9d, 0xe3, 0xfe, 0x35, 0xa3, 0x22, 0xde, 0x44, 0xfa,
-	0x22, 0xda, 0xdf, 0x8b, 0x0a, 0xef, 0x58, 0xd0, 0x72, 0x45, 0x75, 0x2c, 0x82, 0xe2, 0xb8, 0xa3,
-	0x8a, 0xda, 0xe7, 0xa3, 0x8a, 0xf9, 0xaf, 0x82, 0x7e, 0x4c, 0xe3, 0x9b, 0x2a, 0xc8, 0x5b, 0x67,
-	0x6e, 0xaf, 0xc3, 0xa4, 0x4d, 0x49, 0xf1, 0xf7, 0x0f, 0x31, 0x3a, 0x0c, 0x4f, 0x58, 0x17, 0x1d,
-	0x98, 0xb7, 0x74, 0x16, 0x97, 0x6c, 0x09, 0x49, 0xe1, 0x8d, 0xa4, 0xb8, 0x64, 0x4b, 0x93, 0x9c,
-	0x15, 0x92, 0xad, 0xa4, 0xe4, 0xb1, 0x90, 0x14, 0xfe, 0x49, 0x8a, 0x4b, 0x1e, 0x6b, 0x92, 0x25,
-	0x21, 0xa9, 0xb1, 0x4c, 0x53, 0x7f, 0x49, 0xe2, 0xc1, 0xbe, 0xb5, 0x7b, 0x23, 0xf5, 0xaf, 0x10,
-	0xc4, 0x75, 0x09, 0xcb, 0xd4, 0xeb, 0xff, 0x07, 0x00, 0x00, 0xff, 0xff, 0x4d, 0x12, 0x3c, 0xa3,
-	0xb6, 0x1b, 0x00, 0x00,
+	// 4373 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe4, 0x3b, 0x4d, 0x6f, 0x1b, 0xc9,
+	0x72, 0x1a, 0x7e, 0x49, 0x2a, 0xd3, 0xb2, 0xdd, 0x96, 0xa5, 0xb1, 0xfc, 0x25, 0xcf, 0x7a, 0xb5,
+	0xb2, 0x9f, 0x57, 0x6b, 0xd2, 0xde, 0x4d, 0xde, 0xc7, 0x6e, 0x96, 0xa4, 0xb8, 0x96, 0xd6, 0x92,
+	0x56, 0x3b, 0xa4, 0x65, 0x49, 0x41, 0xc0, 0x37, 0x1a, 0xb6, 0xa8, 0xc1, 0x23, 0x67, 0x66, 0x67,
+	0x86, 0xda, 0x65, 0x90, 0xc3, 0x0b, 0xde, 0x21, 0xc9, 0x21, 0x87, 0x04, 0x48, 0x4e, 0x39, 0xbe,
+	0x07, 0x04, 0x39, 0xe7, 0xfa, 0x80, 0x00, 0x8b, 0x5c, 0x12, 0xe4, 0x1e, 0x20, 0xbf, 0x21, 0xf9,
+	0x05, 0x39, 0x04, 0xfd, 0x35, 0xd3, 0xf3, 0xc1, 0xa1, 0x7c, 0xce, 0x49, 0xac, 0xea, 0xfa, 0xe8,
+	0xae, 0xae, 0xa9, 0xaa, 0xae, 0x6e, 0xc1, 0xd2, 0x08, 0xfb, 0xbe, 0x31, 0xc0, 0xfe, 0x96, 0xeb,
+	0x39, 0x81, 0x83, 0x16, 0xe8, 0x9f, 0xb3, 0xf1, 0xf9, 0xda, 0x35, 0x6c, 0x8f, 0x47, 0x1c, 0xbd,
+	0x76, 0x77, 0xe0, 0x38, 0x83, 0x21, 0xfe, 0x44, 0x8c, 0x7e, 0x62, 0xd8, 0x13, 0x36, 0xa4, 0xfd,
+	0xef, 0x53, 0x98, 0xdf, 0x67, 0x42, 0xd0, 0x73, 0xa8, 0xf8, 0xe6, 0x05, 0x1e, 0x19, 0xaa, 0xb2,
+	0xae, 0x6c, 0x2e, 0xd5, 0x97, 0xb7, 0x04, 0xc3, 0x56, 0x87, 0xe2, 0xbb, 0x13, 0x17, 0xeb, 0x9c,
+	0x06, 0x7d, 0x01, 0x4b, 0xec, 0x57, 0xef, 0xd2, 0xf0, 0x2c, 0xc3, 0x0e, 0xd4, 0x02, 0xe5, 0x5a,
+	0x4d, 0x72, 0x1d, 0xb1, 0x61, 0xfd, 0xba, 0x2f, 0x83, 0xe8, 0x19, 0x94, 0xf1, 0xc8, 0x0d, 0x26,
+	0x6a, 0x71, 0x5d, 0xd9, 0xbc, 0x56, 0x47, 0x11, 0x5b, 0x9b, 0xa0, 0xf7, 0xfd, 0xc1, 0xce, 0x9c,
+	0xce, 0x48, 0xd0, 0x33, 0xa8, 0x9c, 0x59, 0x03, 0xcb, 0x0e, 0xd4, 0x12, 0x25, 0xbe, 0x19, 0x11,
+	0x37, 0xad, 0xc1, 0xae, 0x1d, 0xec, 0xcc, 0xe9, 0x9c, 0x02, 0x6d, 0xc3, 0x4d, 0x6c, 0xf6, 0x06,
+	0x9e, 0x33, 0x76, 0x7b, 0x78, 0x88, 0x47, 0xd8, 0x0e, 0xd4, 0x32, 0xe5, 0x52, 0x25, 0x15, 0xad,
+	0xd7, 0x84, 0xa0, 0xcd, 0xc6, 0x77, 0xe6, 0xf4, 0x25, 0x6c, 0xca, 0x18, 0xa2, 0xd1, 0x0f, 0x8c,
+	0x60, 0xec, 0xab, 0x95, 0xa4, 0xc6, 0x0e, 0xc5, 0x13, 0x8d, 0x8c, 0x02, 0x7d, 0x09, 0x4b, 0x2e,
+	0xee, 0x63, 0xcf, 0xc7, 0x76, 0xef, 0xdc, 0xf2, 0xfc, 0x40, 0x9d, 0xa7, 0x3c, 0x92, 0x25, 0x0e,
+	0xf9, 0xf8, 0x57, 0x64, 0x78, 0x67, 0x4e, 0xbf, 0xee, 0xca, 0x08, 0xf4, 0x16, 0xee, 0x84, 0x12,
+	0xfa, 0xd8, 0x74, 0x46, 0x23, 0x2b, 0xa0, 0x13, 0x5f, 0xa0, 0x82, 0x1e, 0xa6, 0x05, 0x6d, 0x4b,
+	0x54, 0x3b, 0x73, 0xfa, 0xb2, 0x9b, 0x81, 0x47, 0x5f, 0x03, 0xf2, 0xcd, 0x0b, 0xdb, 0xf1, 0xbc,
+	0x9e, 0xeb, 0x39, 0xce, 0x79, 0xaf, 0x6f, 0x04, 0x86, 0xba, 0x48, 0x65, 0xae, 0xc5, 0xb6, 0x89,
+	0xd0, 0x1c, 0x12, 0x92, 0x6d, 0x23, 0x30, 0x76, 0xe6, 0xf4, 0x9b, 0x7e, 0x02, 0x87, 0xfe, 0x04,
+	0xee, 0xc6, 0x65, 0x79, 0x86, 0xdd, 0x77, 0x46, 0x4c, 0x24, 0x50, 0x91, 0xeb, 0xd9, 0x22, 0x75,
+	0x4a, 0xc8, 0x05, 0xaf, 0xf8, 0x99, 0x23, 0xa8, 0x0f, 0xf7, 0x85, 0x78, 0x6c, 0x66, 0x68, 0xb8,
+	0x46, 0x35, 0x68, 0x29, 0x0d, 0xed, 0x56, 0x5a, 0x87, 0xca, 0x25, 0xb5, 0xcd, 0xa4, 0x96, 0x7d,
+	0xb8, 0x6d, 0xfa, 0x3d, 0xd7, 0xb0, 0x86, 0x43, 0x0b, 0x7b, 0x3d, 0xc7, 0xc5, 0xb6, 0x65, 0x0f,
+	0xd4, 0x2a, 0x15, 0x7e, 0x2f, 0x12, 0xde, 0xea, 0x1c, 0x72, 0x9a, 0x6f, 0x18, 0xc9, 0xce, 0x9c,
+	0x7e, 0xcb, 0xf4, 0x13, 0x48, 0xd4, 0x85, 0x15, 0x59, 0x9c, 0x64, 0xe3, 0xeb, 0x54, 0xe2, 0x83,
+	0x2c, 0x89, 0xb2, 0x99, 0x6f, 0x9b, 0x7e, 0x0a, 0x8d, 0x06, 0xf0, 0x20, 0x2d, 0x55, 0xb6, 0xc5,
+	0x12, 0x15, 0xfe, 0xc1, 0x54, 0xe1, 0x31, 0x63, 0xdc, 0x35, 0xfd, 0x29, 0x83, 0x08, 0xc3, 0x3d,
+	0xd7, 0xc7, 0xe3, 0xbe, 0x63, 0x4f, 0x46, 0xfe, 0xc4, 0xef, 0x99, 0x46, 0xcf, 0xc4, 0x5e, 0x60,
+	0x9d, 0x5b, 0xa6, 0x11, 0x60, 0xf5, 0x46, 0x52, 0xcd, 0xa1, 0x44, 0xdc, 0x6a, 0xb4, 0x22, 0x52,
+	0xa2, 0x46, 0x96, 0xd4, 0x32, 0xa4, 0x41, 0xf4, 0x6b, 0x05, 0x36, 0x62, 0x7a, 0xec, 0xc9, 0xa8,
+	0x37, 0xc0, 0x76, 0xc6, 0xca, 0x6e, 0x52, 0x95, 0x3f, 0xc9, 0x56, 0x79, 0x30, 0x19, 0xbd, 0xc6,
+	0x76, 0x7a, 0x85, 0x8f, 0xdd, 0x59, 0x44, 0xe8, 0xcf, 0xe0, 0x49, 0x6c, 0x06, 0x96, 0xef, 0x8f,
+	0x71, 0x86, 0xfe, 0x5b, 0x54, 0xff, 0xb3, 0x6c, 0xfd, 0xbb, 0x84, 0x29, 0xad, 0x7e, 0xdd, 0x9d,
+	0x41, 0x83, 0x3e, 0x87, 0xeb, 0x7d, 0x67, 0x7c, 0x36, 0xc4, 0x3d, 0x1e, 0xc4, 0x10, 0x55, 0xb3,
+	0x12, 0xa9, 0xd9, 0xa6, 0xc3, 0x61, 0x28, 0xab, 0xf6, 0x05, 0x4c, 0x02, 0xda, 0x9f, 0x2b, 0xf0,
+	0x61, 0x6c, 0xf6, 0x81, 0x67, 0xd8, 0xfe, 0x39, 0xf6, 0x7a, 0xa6, 0x87, 0xfb, 0xd8, 0x0e, 0x2c,
+	0x63, 0xc8, 0xa6, 0x7f, 0x9b, 0xca, 0x7d, 0x9e, 0x3d, 0xfd, 0x2e, 0xe7, 0x6a, 0x85, 0x4c, 0x7c,
+	0x01, 0x9a, 0x3b, 0x93, 0x0a, 0x0d, 0xe1, 0x61, 0x8e, 0xab, 0xf4, 0xb0, 0xa9, 0x2e, 0x53, 0xdd,
+	0x1f, 0x5e, 0xc1, 0x5b, 0xda, 0xad, 0x9d, 0x39, 0xfd, 0xde, 0x54, 0x7f, 0x69, 0x9b, 0xe8, 0x2f,
+	0x14, 0x78, 0x7a, 0x35, 0x8f, 0x21, 0x9a, 0xef, 0x50, 0xcd, 0x1f, 0xbf, 0x87, 0xd3, 0xd0, 0x19,
+	0x7c, 0x30, 0xd3, 0x6d, 0xda, 0x26, 0xfa, 0x8d, 0x02, 0x1f, 0x5d, 0xc5, 0x73, 0xc8, 0x3c, 0x56,
+	0xf2, 0xac, 0x9f, 0xe5, 0x18, 0xed, 0x56, 0xd2, 0xfa, 0x99, 0x54, 0x26, 0xfa, 0x4b, 0x05, 0x36,
+	0xaf, 0xe4, 0x01, 0x64, 0x1a, 0xab, 0x74, 0x1a, 0x5b, 0xef, 0xe3, 0x04, 0x74, 0x22, 0x4f, 0x66,
+	0xbb, 0x41, 0xdb, 0x44, 0x47, 0xb0, 0xf2, 0x9d, 0xed, 0xf5, 0x2e, 0xb1, 0x67, 0x9d, 0x93, 0xe8,
+	0x64, 0x5e, 0x18, 0xc3, 0x21, 0xb6, 0x07, 0x58, 0x55, 0x93, 0xa9, 0xea, 0xdb, 0x03, 0xfd, 0x88,
+	0x93, 0xb5, 0x04, 0x15, 0x49, 0x55, 0xdf, 0xd9, 0x5e, 0x0a, 0x8f, 0x7e, 0x06, 0x55, 0x0f, 0xbb,
+	0xd8, 0x08, 0x70, 0xbf, 0x47, 0x3e, 0x91, 0xbb, 0x54, 0xda, 0x9d, 0x48, 0x9a, 0xce, 0x47, 0xd9,
+	0x17, 0x72, 0xcd, 0x8b, 0x40, 0xf2, 0x7d, 0x85, 0xbc, 0xae, 0x61, 0x79, 0xea, 0x5a, 0xf2, 0xfb,
+	0x12, 0xcc, 0x87, 0x86, 0xe5, 0x91, 0xef, 0xcb, 0x93, 0x60, 0xb4, 0x0c, 0xa5, 0x36, 0x51, 0x79,
+	0x6f, 0x5d, 0xd9, 0x2c, 0xef, 0xcc, 0xe9, 0x14, 0x42, 0x9f, 0x01, 0x74, 0xb0, 0xef, 0x5b, 0x8e,
+	0xfd, 0x06, 0x4f, 0xd4, 0x87, 0x54, 0xa2, 0x5c, 0x10, 0x85, 0x63, 0x3b, 0x73, 0xba, 0x44, 0x89,
+	0xce, 0xe1, 0x7e, 0x6c, 0xab, 0x3c, 0xf2, 0x7d, 0x0c, 0xad, 0x91, 0x15, 0xb0, 0x6f, 0xf4, 0x51,
+	0x5e, 0x54, 0xd5, 0x8d, 0x00, 0xef, 0x11, 0x5a, 0x11, 0xbc, 0xdd, 0x69, 0x83, 0xe8, 0x33, 0x58,
+	0xc4, 0x3f, 0x04, 0xd8, 0x26, 0x7a, 0xd5, 0xf5, 0xe4, 0x82, 0xdb, 0x62, 0x88, 0x95, 0x51, 0x11,
+	0x29, 0x3a, 0x81, 0xd5, 0xe4, 0x97, 0xec, 0xe1, 0xef, 0xc6, 0xd8, 0x0f, 0xd4, 0xc7, 0x54, 0xca,
+	0xa3, 0x69, 0x9f, 0xb0, 0xce, 0xc8, 0x76, 0xe6, 0xf4, 0x3b, 0xf1, 0x8f, 0x97, 0x0f, 0x10, 0xdf,
+	0x48, 0x8a, 0xe6, 0x35, 0x94, 0x96, 0x2a, 0x63, 0x62, 0x92, 0xc3, 0x8a, 0x6a, 0x39, 0x2e, 0x98,
+	0xe1, 0x51, 0x03, 0x6e, 0x5c, 0x4c, 0xce, 0x3c, 0xab, 0xdf, 0xfb, 0x15, 0x1e, 0xf5, 0x2c, 0xdb,
+	0x0a, 0xd4, 0x27, 0xc9, 0x02, 0x6b, 0x87, 0x12, 0xbc, 0x69, 0xef, 0xef, 0xda, 0x16, 0x2d, 0xb0,
+	0x18, 0xc7, 0x1b, 0x3c, 0x22, 0x08, 0x92, 0xf8, 0x25, 0x11, 0x1e, 0xf6, 0x5d, 0xc7, 0xf6, 0xb1,
+	0xfa, 0x61, 0x32, 0xf1, 0x87, 0x62, 0x74, 0x4e, 0x42, 0x12, 0x7f, 0x28, 0x4a, 0x20, 0xa9, 0xf1,
+	0x6d, 0xd3, 0x9b, 0xb8, 0x01, 0xee, 0xab, 0x1b, 0x29, 0xe3, 0x8b, 0x21, 0x61, 0x7c, 0x01, 0xa3,
+	0x77, 0xb0, 0xea, 0x19, 0xf6, 0x20, 0x2b, 0xf5, 0x7c, 0x94, 0x34, 0x91, 0x4e, 0x08, 0xd3, 0xe9,
+	0x66, 0xd9, 0xcb, 0xc0, 0x93, 0xa2, 0x57, 0x16, 0x4c, 0x25, 0x6e, 0x26, 0x8b, 0xde, 0x48, 0x22,
+	0x97, 0xb5, 0xe4, 0xc5, 0x30, 0xe8, 0x05, 0x2c, 0x04, 0x9e, 0xe1, 0xf6, 0x1d, 0xc7, 0x53, 0x9f,
+	0x26, 0xab, 0xf2, 0x2e, 0x1f, 0xd9, 0x99, 0xd3, 0x43, 0x2a, 0xf4, 0x0d, 0xdc, 0x36, 0x82, 0x00,
+	0x93, 0x6d, 0xb6, 0x1c, 0x3b, 0xf4, 0xa4, 0x67, 0x94, 0xf9, 0x7e, 0xc4, 0xdc, 0x88, 0x88, 0x22,
+	0x37, 0x42, 0x46, 0x0a, 0x8b, 0x74, 0x58, 0x96, 0x05, 0xe2, 0x4b, 0xab, 0x8f, 0x6d, 0x13, 0xab,
+	0x3f, 0x49, 0x16, 0x54, 0x92, 0xc4, 0x36, 0x27, 0x22, 0x05, 0x95, 0x91, 0x46, 0xd3, 0xec, 0x1f,
+	0x56, 0x53, 0x43, 0xc3, 0xb2, 0x03, 0xfc, 0x43, 0x90, 0xb1, 0x05, 0xcf, 0x53, 0xd9, 0x9f, 0x73,
+	0x1d, 0x0a, 0xa6, 0xac, 0xec, 0x3f, 0x83, 0x06, 0x59, 0xf0, 0x60, 0xaa, 0x76, 0xaa, 0xf6, 0x63,
+	0xaa, 0xf6, 0xc9, 0x2c, 0xb5, 0x5c, 0xe1, 0x9a, 0x3b, 0x75, 0x34, 0x15, 0x7b, 0x48, 0xda, 0xc4,
+	0xbe, 0xe9, 0x39, 0xdf, 0x33, 0x4d, 0x5b, 0x79, 0xb1, 0xe7, 0x60, 0x32, 0x6a, 0x53, 0xda, 0xac,
+	0xd8, 0x13, 0x1b, 0x44, 0x7f, 0x0c, 0xab, 0x1e, 0xbe, 0x74, 0x4c, 0xb6, 0x47, 0xfe, 0xf8, 0xcc,
+	0x37, 0x3d, 0xcb, 0x25, 0x80, 0xfa, 0x49, 0xf2, 0x24, 0xa0, 0x87, 0x84, 0x1d, 0x89, 0x8e, 0x9c,
+	0x04, 0xbc, 0xcc, 0x11, 0xb4, 0x0b, 0xb7, 0x24, 0xe1, 0x63, 0xb7, 0x4f, 0x6a, 0xd1, 0x17, 0xc9,
+	0x33, 0x4b, 0x24, 0xf6, 0x2d, 0xa5, 0x20, 0x67, 0x16, 0x2f, 0x81, 0x43, 0xdf, 0xc2, 0x9d, 0x81,
+	0xeb, 0x67, 0xec, 0x74, 0x2d, 0xe9, 0x9f, 0xaf, 0x0f, 0x3b, 0xe9, 0xbd, 0x45, 0x03, 0xd7, 0xcf,
+	0x38, 0x41, 0x10, 0xab, 0x5a, 0xb6, 0x39, 0x1c, 0x93, 0x78, 0xca, 0x84, 0xab, 0xf5, 0x64, 0x20,
+	0x39, 0x98, 0x8c, 0x76, 0x05, 0x0d, 0x95, 0x41, 0x02, 0x89, 0x9d, 0x44, 0x92, 0x80, 0xe0, 0x07,
+	0xd8, 0xcb, 0xaa, 0x85, 0x5f, 0x26, 0x03, 0x42, 0x87, 0x10, 0x66, 0x04, 0x04, 0x3f, 0x03, 0x4f,
+	0x02, 0x82, 0x2c, 0x98, 0x4a, 0x7c, 0x95, 0x0c, 0x08, 0x91, 0x44, 0x11, 0x10, 0xfc, 0x18, 0x86,
+	0x64, 0x65, 0xe3, 0x6c, 0xec, 0xe3, 0x9e, 0x87, 0x5d, 0xc7, 0x0b, 0xd4, 0x4f, 0x93, 0x59, 0xb9,
+	0x41, 0x46, 0x75, 0x3a, 0x48, 0xb2, 0xb2, 0x11, 0x81, 0xe8, 0x97, 0xb0, 0x36, 0x34, 0x82, 0xc0,
+	0x32, 0x71, 0xcf, 0xbf, 0x70, 0xbc, 0xa0, 0x77, 0x89, 0xcd, 0xc0, 0xe1, 0xe7, 0x19, 0xf5, 0x33,
+	0x2a, 0xe9, 0x71, 0x24, 0x69, 0x8f, 0xd1, 0x76, 0x08, 0xe9, 0x11, 0xa5, 0x14, 0x66, 0x5b, 0x1d,
+	0x66, 0x0f, 0xa1, 0x1e, 0xdc, 0x0d, 0x2e, 0x3c, 0xec, 0x5f, 0x38, 0xc3, 0x7e, 0x4f, 0x9c, 0x1e,
+	0x45, 0x08, 0xfa, 0x83, 0xa4, 0x82, 0xae, 0x20, 0xe5, 0x27, 0xc7, 0x28, 0x0e, 0xad, 0x06, 0xd9,
+	0x43, 0xc8, 0x81, 0x47, 0xae, 0xe1, 0x91, 0xea, 0x67, 0x38, 0xe9, 0x9d, 0x0d, 0x2d, 0x3b, 0xad,
+	0xe6, 0x0f, 0xa9, 0x9a, 0x0d, 0xf9, 0xe3, 0xe5, 0x0c, 0x4d, 0x42, 0x9f, 0xd2, 0x75, 0xdf, 0xcd,
+	0x19, 0x47, 0x3f, 0x80, 0x36, 0x4d, 0xa1, 0xd4, 0x14, 0xf8, 0x29, 0xd5, 0xf9, 0x74, 0x86, 0xce,
+	0x96, 0xdc, 0x1f, 0x78, 0xe4, 0xe6, 0x93, 0x20, 0x0f, 0xd6, 0xa7, 0x2f, 0x95, 0x67, 0xcb, 0x9f,
+	0x51, 0xbd, 0x1f, 0xcd, 0x5c, 0x6b, 0x98, 0x39, 0x1f, 0xb8, 0x79, 0x04, 0xe8, 0x14, 0x54, 0xc3,
+	0x76, 0x6c, 0x5a, 0xc4, 0x8a, 0xca, 0x5a, 0xd8, 0xf5, 0xe7, 0xc9, 0x5a, 0xa4, 0x61, 0x3b, 0x36,
+	0xa9, 0x46, 0x59, 0x8d, 0x2c, 0xd5, 0x22, 0x46, 0xd6, 0x00, 0xea, 0xc0, 0x1d, 0x49, 0x76, 0x54,
+	0x26, 0xab, 0xbf, 0x48, 0x25, 0x12, 0xc1, 0x1f, 0xd5, 0xba, 0x34, 0x91, 0xa4, 0xd1, 0xc4, 0x1f,
+	0x24, 0xa1, 0xae, 0x87, 0x7d, 0x6c, 0x27, 0x32, 0xdf, 0xe7, 0x49, 0x7f, 0x08, 0xc5, 0x1f, 0x4a,
+	0xe4, 0x92, 0x3f, 0x18, 0x39, 0xe3, 0xa4, 0x2b, 0x12, 0x53, 0x98, 0x8c, 0x11, 0x5f, 0x24, 0xbb,
+	0x22, 0x92, 0xb6, 0x54, 0x57, 0xc4, 0x98, 0x32, 0x46, 0xc2, 0x64, 0x4a, 0x0b, 0x15, 0xff, 0x47,
+	0xa9, 0x34, 0x1e, 0x13, 0x21, 0xc2, 0xa4, 0x91, 0xc2, 0xa2, 0x16, 0xdc, 0x08, 0x4b, 0x72, 0x7e,
+	0xe8, 0xfd, 0x32, 0x55, 0x8e, 0x70, 0x82, 0xf0, 0xd8, 0xbb, 0xe4, 0x45, 0x18, 0x52, 0x82, 0xaf,
+	0xc1, 0x82, 0x39, 0xb4, 0xb0, 0x1d, 0xec, 0xf6, 0xd5, 0xfb, 0xa4, 0x38, 0xd7, 0x43, 0x18, 0x3d,
+	0x81, 0xeb, 0x87, 0x44, 0x90, 0xe9, 0x0c, 0xdb, 0x9e, 0xe7, 0x78, 0xea, 0x83, 0x75, 0x65, 0x73,
+	0x51, 0x8f, 0x23, 0xd1, 0x32, 0x94, 0x5b, 0x63, 0xef, 0x12, 0xab, 0x1f, 0x50, 0x76, 0x06, 0x34,
+	0x17, 0x61, 0xde, 0x74, 0xec, 0x00, 0xdb, 0x81, 0x06, 0xb0, 0x20, 0xba, 0x8d, 0x5a, 0x0f, 0xae,
+	0x75, 0xb0, 0x77, 0x69, 0x99, 0x78, 0xd7, 0x3e, 0x77, 0x10, 0x82, 0x92, 0x6d, 0x8c, 0x30, 0xed,
+	0x85, 0x2e, 0xea, 0xf4, 0x37, 0x5a, 0x87, 0x6b, 0x7d, 0x1c, 0x25, 0xbb, 0x02, 0x1d, 0x92, 0x51,
+	0x64, 0xce, 0xae, 0xe7, 0x90, 0xca, 0xc3, 0xa3, 0x8d, 0xcd, 0x45, 0x3d, 0x84, 0x35, 0x0d, 0x2a,
+	0xbc, 0xa2, 0x55, 0x61, 0xbe, 0x33, 0x36, 0x4d, 0xec, 0xfb, 0x54, 0xfc, 0x82, 0x2e, 0x40, 0x4d,
+	0x85, 0x0a, 0xb3, 0x07, 0x5a, 0x82, 0xc2, 0x71, 0x8d, 0x0e, 0x57, 0xf5, 0xc2, 0x71, 0x4d, 0xdb,
+	0x82, 0xaa, 0xdc, 0x26, 0x48, 0x8e, 0x53, 0xb8, 0xae, 0x16, 0x38, 0x5c, 0xd7, 0x1e, 0xc0, 0xf5,
+	0x58, 0xd7, 0x11, 0x55, 0x41, 0xd9, 0xe1, 0xf4, 0xca, 0x8e, 0x56, 0x87, 0xe5, 0xac, 0x5e, 0x22,
+	0xa1, 0x3a, 0x16, 0x54, 0xc7, 0x04, 0xd2, 0xb9, 0x4c, 0x45, 0xd7, 0x9e, 0xc3, 0x52, 0xbc, 0x71,
+	0x9a, 0xa6, 0x3e, 0x11, 0xd4, 0x27, 0x9a, 0x06, 0x25, 0x7a, 0xbe, 0xaa, 0x82, 0xd2, 0x10, 0x34,
+	0x0d, 0x02, 0x35, 0x05, 0x4d, 0x53, 0x6b, 0xc2, 0x4a, 0x76, 0xab, 0x30, 0x2d, 0xb9, 0xa1, 0x16,
+	0x62, 0x32, 0x8a, 0x42, 0xc6, 0xdf, 0x2a, 0xa0, 0x4e, 0xeb, 0x06, 0xa2, 0x0d, 0x21, 0x26, 0xa7,
+	0xfd, 0x4b, 0x14, 0x6c, 0x08, 0x05, 0xb9, 0x74, 0x0d, 0xb4, 0x21, 0x54, 0xe7, 0xd2, 0x35, 0xb5,
+	0x5f, 0xc0, 0xcd, 0x64, 0x5b, 0x95, 0x4c, 0xfb, 0x54, 0x2c, 0xe9, 0x94, 0x78, 0x8a, 0x28, 0xa9,
+	0xf9, 0xca, 0x42, 0x58, 0xfb, 0xbd, 0x02, 0x8f, 0x67, 0x76, 0x31, 0xb2, 0x3c, 0xa0, 0x51, 0x13,
+	0x1e, 0xd0, 0xa0, 0x70, 0xb3, 0xc6, 0xed, 0x54, 0x68, 0x0a, 0x0f, 0x29, 0x09, 0x0f, 0xa1, 0xf4,
+	0x75, 0xb5, 0xcc, 0xe9, 0x29, 0xdc, 0xac, 0xab, 0x15, 0x4e, 0x5f, 0x67, 0x9b, 0x3f, 0xcf, 0x37,
+	0x9f, 0x40, 0x1d, 0xda, 0x8f, 0xae, 0xea, 0x4a, 0x07, 0xdd, 0x87, 0xc5, 0xc6, 0x70, 0xe0, 0x78,
+	0x56, 0x70, 0x31, 0xa2, 0x1d, 0xe5, 0xb2, 0x1e, 0x21, 0xb4, 0xdf, 0x17, 0xe0, 0x83, 0x2b, 0x74,
+	0x61, 0xd0, 0x66, 0xb8, 0x82, 0x3c, 0x73, 0x92, 0xb5, 0x6d, 0x86, 0x6b, 0xcb, 0xa5, 0x6c, 0x50,
+	0x4a, 0xbe, 0xea, 0x5c, 0xca, 0x26, 0xa5, 0xe4, 0xf6, 0xc8, 0xd7, 0x5e, 0x47, 0x9b, 0xa1, 0xa5,
+	0xf2, 0xb5, 0x53, 0x4a, 0x6e, 0xc3, 0x7c, 0xed, 0xb9, 0xd6, 0xd5, 0xfe, 0x55, 0x81, 0xbb, 0x53,
+	0xfb, 0x67, 0xc4, 0x73, 0x68, 0x3e, 0xc5, 0x7d, 0xf1, 0x5d, 0x85, 0xb0, 0x34, 0x26, 0xbe, 0xb2,
+	0x10, 0x66, 0x1a, 0x8b, 0x31, 0x8d, 0xa5, 0xcc, 0xfd, 0x2c, 0x27, 0xf6, 0x13, 0x7d, 0x06, 0xc5,
+	0x4e, 0xab, 0xab, 0x56, 0x92, 0x27, 0x95, 0x8e, 0x35, 0xb0, 0x71, 0x5f, 0x9a, 0x5b, 0xd7, 0x1a,
+	0x91, 0xe3, 0xd7, 0xc8, 0xd5, 0x09, 0x83, 0xf6, 0x3b, 0x05, 0xee, 0xe5, 0xf4, 0x01, 0xd1, 0xab,
+	0xc4, 0x4a, 0xf2, 0x6c, 0x16, 0xad, 0xf1, 0x55, 0x62, 0x8d, 0x57, 0xe1, 0xca, 0x5d, 0xbd, 0xf6,
+	0x1f, 0x0a, 0xac, 0xcf, 0xea, 0xd6, 0xa1, 0x9b, 0x50, 0x3c, 0xae, 0x89, 0xef, 0x8d, 0xfc, 0x64,
+	0x18, 0x11, 0x73, 0xc9, 0x4f, 0x8a, 0xa9, 0x8b, 0x6f, 0x8e, 0xfc, 0x64, 0x18, 0xf1, 0xd5, 0x91,
+	0x9f, 0x2c, 0x96, 0x95, 0x63, 0xb1, 0x8c, 0x7d, 0x73, 0x4a, 0x13, 0xb5, 0x01, 0x1a, 0x41, 0xe0,
+	0x59, 0x67, 0xe3, 0x00, 0xfb, 0xea, 0xfc, 0x7a, 0x71, 0x7a, 0x4f, 0x95, 0xce, 0xb1, 0x1f, 0x52,
+	0xeb, 0x12, 0xa3, 0xf6, 0xdb, 0x02, 0x68, 0xb3, 0xbb, 0x8f, 0xe8, 0x59, 0xb4, 0xa2, 0x3c, 0x1b,
+	0xd2, 0xb5, 0x3e, 0x8b, 0xd6, 0x3a, 0x83, 0xb6, 0x8e, 0x9e, 0x45, 0x56, 0xc8, 0xa7, 0xad, 0x33,
+	0xb9, 0xf5, 0xd9, 0x5f, 0x21, 0xb5, 0xdc, 0x86, 0xb0, 0xdc, 0x55, 0x82, 0x74, 0x65, 0x76, 0x90,
+	0xfe, 0x25, 0xac, 0xa4, 0x9a, 0xa3, 0x34, 0x93, 0xe7, 0xe5, 0x2c, 0x52, 0x18, 0xec, 0x18, 0xfe,
+	0x05, 0xdf, 0x64, 0xfa, 0x1b, 0xad, 0x40, 0xe5, 0xb4, 0x31, 0x74, 0x2f, 0x0c, 0xbe, 0xd1, 0x1c,
+	0xd2, 0xfe, 0x5e, 0x01, 0x35, 0x5b, 0x45, 0xbb, 0x85, 0x36, 0x84, 0x92, 0xab, 0x2c, 0x67, 0x66,
+	0x6e, 0x7a, 0xbf, 0x89, 0xfd, 0x7b, 0x21, 0xbe, 0x76, 0xa9, 0xca, 0x7d, 0x02, 0xd7, 0x3b, 0x23,
+	0x63, 0x38, 0x6c, 0x74, 0x9d, 0xd7, 0xc6, 0x88, 0xdf, 0x06, 0x57, 0xf5, 0x38, 0x32, 0xa4, 0x6a,
+	0x0a, 0xaa, 0x82, 0x44, 0x25, 0x90, 0x24, 0x1c, 0x85, 0x62, 0xd8, 0xb4, 0x16, 0x1a, 0xd2, 0x58,
+	0xc8, 0x5c, 0xe2, 0xa1, 0x4a, 0x8c, 0xbd, 0x80, 0x42, 0xb7, 0xa6, 0x96, 0x93, 0xcd, 0x84, 0x6c,
+	0x53, 0xea, 0x85, 0x6e, 0x8d, 0x72, 0x88, 0xc0, 0x7b, 0x15, 0x8e, 0x3a, 0xda, 0xcd, 0xf8, 0xd6,
+	0x9e, 0x66, 0x73, 0x46, 0xd6, 0xc9, 0xfe, 0xde, 0xfe, 0xa7, 0x00, 0x6a, 0x36, 0x7d, 0xbb, 0x85,
+	0xbe, 0xc8, 0xb2, 0x67, 0xde, 0x56, 0x26, 0x2c, 0xfd, 0x45, 0x96, 0xa5, 0x67, 0xf3, 0x87, 0xb6,
+	0x7c, 0x95, 0xd8, 0x83, 0xdc, 0x70, 0xd9, 0x90, 0xb8, 0x62, 0xbb, 0x93, 0x1f, 0x64, 0x05, 0x57,
+	0x5d, 0xda, 0x37, 0x6d, 0xd6, 0x2e, 0xb4, 0x5b, 0x74, 0xe7, 0xea, 0xd2, 0xce, 0x5d, 0x8d, 0xa7,
+	0xae, 0xfd, 0x53, 0x22, 0xc0, 0x4d, 0xb9, 0xb6, 0x52, 0x61, 0xfe, 0x1b, 0x6f, 0x70, 0x10, 0x95,
+	0xf1, 0x02, 0xe4, 0xb5, 0x53, 0x21, 0x51, 0x3d, 0x17, 0xc3, 0xda, 0x08, 0x41, 0xe9, 0x60, 0x32,
+	0x6a, 0x70, 0xc7, 0xa4, 0xbf, 0x39, 0xae, 0xc9, 0x63, 0x37, 0xfd, 0x8d, 0xbe, 0x04, 0x88, 0x74,
+	0xe6, 0xbb, 0x5f, 0x44, 0xa7, 0x4b, 0x3c, 0xe8, 0x4b, 0x98, 0xe7, 0xc7, 0x3d, 0x75, 0xfe, 0x7d,
+	0x0e, 0x8f, 0xba, 0x60, 0x43, 0x0f, 0x01, 0x0e, 0x3d, 0xdc, 0xa7, 0x49, 0xd5, 0x57, 0x17, 0xd6,
+	0x8b, 0x9b, 0x55, 0x5d, 0xc2, 0x68, 0xff, 0x5c, 0x80, 0x27, 0x57, 0xb9, 0x04, 0xca, 0x31, 0xd7,
+	0x66, 0x68, 0xae, 0x2b, 0x14, 0x6a, 0xdc, 0x90, 0xb3, 0x8a, 0xaa, 0xe7, 0x92, 0x89, 0xf3, 0x68,
+	0x99, 0xf1, 0x9f, 0x4b, 0xc6, 0x9f, 0x45, 0xdd, 0x44, 0xcd, 0x8c, 0x6d, 0xd1, 0x66, 0x6d, 0x4b,
+	0xbb, 0x25, 0x6f, 0x8c, 0xf6, 0x35, 0x2c, 0x67, 0x5d, 0x61, 0x91, 0x6c, 0xf0, 0x4e, 0xe4, 0x86,
+	0x77, 0xe8, 0x09, 0x94, 0xc9, 0x29, 0xc7, 0x57, 0x0b, 0x34, 0x80, 0x2c, 0xc5, 0xda, 0xb8, 0x9e,
+	0xce, 0x06, 0xb5, 0xc7, 0x70, 0x4d, 0xba, 0xc0, 0x22, 0x9e, 0xb4, 0x6b, 0x07, 0xe4, 0xf0, 0x57,
+	0xdc, 0x2c, 0xeb, 0xf4, 0xb7, 0xf6, 0x0a, 0xaa, 0xf2, 0x35, 0x55, 0x24, 0x58, 0xc9, 0x13, 0xfc,
+	0x5f, 0x05, 0xb8, 0x1d, 0x5d, 0xff, 0x77, 0xb0, 0xe9, 0xe1, 0x80, 0x5c, 0x43, 0x55, 0x41, 0x39,
+	0x10, 0x93, 0x3c, 0x20, 0xd0, 0x6b, 0x91, 0xc0, 0x5e, 0x73, 0xdf, 0x2f, 0x26, 0x7c, 0x3f, 0x76,
+	0x2e, 0x38, 0x7e, 0x29, 0xce, 0x05, 0xc7, 0x2f, 0xc9, 0x29, 0x7a, 0x7b, 0xcf, 0x19, 0x1c, 0xf2,
+	0x32, 0x85, 0x01, 0x02, 0xfb, 0x9a, 0xd7, 0xb0, 0x0c, 0x10, 0xd8, 0x6f, 0x79, 0x2d, 0xcb, 0x00,
+	0xf4, 0x02, 0x6e, 0x33, 0x3b, 0x1a, 0x67, 0x43, 0xdc, 0xb6, 0xd9, 0x53, 0x9b, 0x03, 0x7a, 0x6e,
+	0xa8, 0xea, 0x59, 0x43, 0xa8, 0x0e, 0xcb, 0x69, 0xf4, 0xeb, 0x1a, 0x7d, 0x69, 0x52, 0xd5, 0x33,
+	0xc7, 0xb2, 0x79, 0x76, 0x6a, 0xea, 0xb5, 0x69, 0x3c, 0x3b, 0x35, 0x62, 0x99, 0x37, 0xf4, 0xfd,
+	0x47, 0x59, 0x57, 0xde, 0x90, 0x95, 0xbf, 0xa9, 0xd1, 0xc7, 0x1b, 0x65, 0xbd, 0xf0, 0xa6, 0xa6,
+	0xfd, 0x67, 0x01, 0x6e, 0x46, 0xd6, 0x3d, 0x1c, 0x9f, 0x5d, 0xc1, 0xb4, 0x27, 0xa1, 0x69, 0x4f,
+	0xa8, 0x69, 0x4f, 0x42, 0xd3, 0x9e, 0x50, 0xd3, 0x9e, 0x84, 0xa6, 0x3d, 0xf9, 0xff, 0x6c, 0xda,
+	0xef, 0xe1, 0x56, 0xea, 0x95, 0x0d, 0x61, 0x79, 0x2b, 0x4c, 0xfb, 0x96, 0x40, 0x6d, 0x61, 0xda,
+	0x36, 0x81, 0x8e, 0x44, 0xfd, 0x7e, 0x44, 0x8d, 0x81, 0x87, 0x81, 0xa8, 0x1c, 0x18, 0x40, 0xb0,
+	0x7b, 0xc6, 0x19, 0x1e, 0x72, 0x0b, 0x33, 0x80, 0x70, 0xee, 0x89, 0x12, 0x7b, 0x4f, 0xf3, 0xe1,
+	0xee, 0xd4, 0xf7, 0x32, 0x64, 0x96, 0x6f, 0xc3, 0x23, 0xf5, 0x5b, 0xba, 0x7f, 0xed, 0x30, 0x4d,
+	0xb4, 0x29, 0x7c, 0x14, 0xee, 0xef, 0x51, 0x8d, 0x94, 0x57, 0x54, 0x73, 0x4d, 0x94, 0x57, 0x0c,
+	0x22, 0x74, 0x7b, 0x35, 0xb1, 0xcf, 0x7b, 0x35, 0xed, 0x47, 0x05, 0x6e, 0x27, 0xb4, 0x52, 0x7d,
+	0x2b, 0x50, 0xd1, 0xbb, 0xd6, 0xb0, 0x8f, 0xb9, 0x4e, 0x0e, 0x91, 0x46, 0x13, 0xfb, 0xb5, 0xeb,
+	0x1f, 0xe0, 0x01, 0x9d, 0xc0, 0x82, 0x2e, 0xa3, 0x08, 0x67, 0x87, 0x71, 0xb2, 0xd9, 0x54, 0x3a,
+	0x21, 0x67, 0x47, 0xe2, 0x2c, 0x31, 0xce, 0x4e, 0x9c, 0x73, 0x9f, 0x71, 0xb2, 0xf9, 0x55, 0xf6,
+	0x43, 0xce, 0x7d, 0x89, 0xb3, 0xc2, 0x38, 0x25, 0x94, 0xa6, 0xc9, 0x77, 0xe2, 0xc4, 0xd8, 0x97,
+	0xc6, 0x70, 0x2c, 0x72, 0x05, 0x03, 0xb4, 0xbf, 0x4a, 0x1c, 0x5d, 0xe3, 0xb7, 0xd6, 0xcb, 0x50,
+	0xee, 0x98, 0x8e, 0x1b, 0xf2, 0x50, 0x80, 0x60, 0xdb, 0xae, 0x63, 0x5e, 0xd0, 0x75, 0x16, 0x75,
+	0x06, 0x90, 0x79, 0x76, 0x2d, 0xf3, 0x57, 0x38, 0x10, 0x2b, 0x64, 0x10, 0x73, 0x84, 0x92, 0x70,
+	0x84, 0x2a, 0x28, 0x5d, 0x71, 0x9a, 0xea, 0xb2, 0xae, 0x11, 0xdf, 0xea, 0x63, 0xed, 0x08, 0xaa,
+	0xf2, 0x45, 0x38, 0xb5, 0x19, 0x79, 0x83, 0x28, 0xd4, 0x73, 0x08, 0x6d, 0xc1, 0xfc, 0xa1, 0x31,
+	0x19, 0x3a, 0x46, 0x9f, 0xa7, 0xb8, 0xe5, 0x2d, 0xf6, 0x62, 0x52, 0xce, 0xc4, 0x13, 0x5d, 0x10,
+	0x69, 0x7f, 0xa7, 0xc0, 0x9d, 0xcc, 0xbb, 0x71, 0xf4, 0x35, 0xdc, 0x48, 0xb8, 0x94, 0xaa, 0x24,
+	0x6b, 0x82, 0xec, 0x86, 0x97, 0x9e, 0x64, 0x24, 0x5f, 0x36, 0x39, 0x5f, 0x1b, 0xc1, 0xd8, 0xc3,
+	0xe1, 0x51, 0x9c, 0xe5, 0x99, 0xb2, 0x9e, 0x35, 0xa4, 0x1d, 0xc1, 0xda, 0xf4, 0x13, 0x39, 0x39,
+	0xe2, 0x87, 0x00, 0x9d, 0x55, 0x51, 0x8f, 0x10, 0xf1, 0x4e, 0x1f, 0x3b, 0x1e, 0x17, 0xc5, 0xf1,
+	0xf8, 0x02, 0x96, 0xb3, 0x2e, 0xec, 0xa9, 0x3d, 0xe9, 0x2f, 0x2a, 0xae, 0xac, 0x73, 0x28, 0xae,
+	0xa9, 0x90, 0xa9, 0x69, 0xca, 0x41, 0xfc, 0x73, 0xb8, 0x1e, 0xbb, 0xc9, 0x27, 0x2a, 0x8e, 0xeb,
+	0x9f, 0x7e, 0x5a, 0xfb, 0xa9, 0xf8, 0x40, 0x18, 0x44, 0x5c, 0x66, 0x7f, 0xef, 0x4d, 0x7b, 0x9f,
+	0x4f, 0x99, 0x01, 0x5a, 0x03, 0x6e, 0xa5, 0x6e, 0xf0, 0xdf, 0x53, 0xc4, 0x16, 0x54, 0xe5, 0xfb,
+	0x7b, 0x52, 0x5c, 0xb5, 0x2c, 0xf7, 0x02, 0x7b, 0xe4, 0xaa, 0x95, 0x4b, 0x90, 0x30, 0x5a, 0x13,
+	0x50, 0xd3, 0x0a, 0x32, 0xba, 0x97, 0x2d, 0x4e, 0xac, 0xb4, 0x48, 0x34, 0xe8, 0xbe, 0x10, 0x51,
+	0xa4, 0xfb, 0x82, 0xc2, 0x61, 0x14, 0xe9, 0xd6, 0xb4, 0x03, 0xa8, 0x0a, 0x19, 0x22, 0x0a, 0xb5,
+	0x5f, 0x88, 0x28, 0xd4, 0x7e, 0x91, 0x15, 0x85, 0x4e, 0x5f, 0x08, 0xfe, 0x53, 0x3a, 0x7e, 0x2a,
+	0x22, 0x50, 0xe1, 0xb4, 0xa6, 0xfd, 0xa3, 0x02, 0xcb, 0x59, 0xcf, 0x07, 0x12, 0xd3, 0xca, 0x69,
+	0xaa, 0xa2, 0x3a, 0x94, 0xf7, 0x9c, 0xef, 0xb1, 0xa7, 0x96, 0xd6, 0x8b, 0xf1, 0x3b, 0x80, 0xf4,
+	0x6a, 0x75, 0x46, 0x4a, 0x78, 0xde, 0xba, 0x2e, 0xf6, 0xd4, 0xf2, 0x55, 0x78, 0x28, 0xa9, 0x36,
+	0x84, 0xa5, 0xf8, 0xb3, 0x04, 0xf4, 0x5c, 0x68, 0x66, 0x75, 0xcf, 0x4a, 0x5a, 0x8a, 0xac, 0xf3,
+	0xb9, 0xd0, 0x59, 0xc8, 0xa7, 0x66, 0xda, 0x36, 0xa2, 0x9e, 0x6b, 0xac, 0xff, 0xaa, 0x24, 0xfa,
+	0xaf, 0xcf, 0x00, 0xa5, 0x5f, 0x2c, 0x10, 0x87, 0x39, 0x70, 0xc8, 0x63, 0x04, 0x46, 0xce, 0x00,
+	0x6d, 0x17, 0x6e, 0x67, 0xbc, 0x45, 0x20, 0x5e, 0xf7, 0x95, 0xe3, 0x8d, 0x8c, 0x40, 0xc4, 0x1a,
+	0x06, 0x11, 0xb5, 0x82, 0x46, 0x34, 0xe8, 0x04, 0xac, 0xfd, 0x03, 0x69, 0x43, 0xcd, 0x7a, 0x4f,
+	0x90, 0x57, 0x7e, 0xd0, 0xfd, 0x2d, 0xc6, 0xf6, 0xb7, 0x24, 0xf6, 0x97, 0x38, 0x72, 0x74, 0x87,
+	0x58, 0xe6, 0x8e, 0x1c, 0x62, 0x48, 0xf8, 0x8f, 0xa0, 0x06, 0x0f, 0xa2, 0x32, 0x4a, 0xfb, 0x0a,
+	0xd6, 0xa6, 0x3f, 0x4d, 0x48, 0x74, 0xb7, 0x69, 0x91, 0x5c, 0x10, 0x45, 0x72, 0x2c, 0x77, 0x6b,
+	0xff, 0x96, 0x48, 0x11, 0xf1, 0xc7, 0x05, 0xe2, 0xe4, 0xa5, 0x64, 0x9c, 0xbc, 0x0a, 0xd2, 0xc9,
+	0x8b, 0xd6, 0x0a, 0xc5, 0x58, 0xad, 0x50, 0x8a, 0xd5, 0x0a, 0x65, 0x29, 0x45, 0x44, 0xf9, 0x1f,
+	0xed, 0xa7, 0x43, 0xf4, 0xfc, 0x95, 0x1f, 0xd4, 0xa6, 0xa2, 0x34, 0xb9, 0x7d, 0x40, 0xd2, 0x1b,
+	0x07, 0xdb, 0x70, 0xfd, 0x0b, 0x27, 0x20, 0x47, 0xa9, 0x23, 0xec, 0xd1, 0xc7, 0x59, 0x64, 0x21,
+	0x25, 0x5d, 0x80, 0x33, 0x82, 0xe3, 0x26, 0xcc, 0xb3, 0x34, 0xe7, 0xab, 0xc5, 0xcc, 0xba, 0x5f,
+	0x0c, 0xb3, 0x30, 0x5a, 0x8a, 0x85, 0xd1, 0xb2, 0x08, 0xa3, 0x75, 0x58, 0xc9, 0x7e, 0x77, 0x31,
+	0x7d, 0x5e, 0xda, 0x6f, 0x15, 0xb8, 0x99, 0x7c, 0x55, 0x41, 0x0c, 0xff, 0x95, 0xe7, 0x8c, 0x38,
+	0x2d, 0xfd, 0x2d, 0x8b, 0x28, 0xe4, 0x2c, 0xad, 0x98, 0xb3, 0xb4, 0xd2, 0x15, 0x96, 0x56, 0x8e,
+	0x2d, 0xad, 0x22, 0x96, 0xb6, 0x07, 0x28, 0xfd, 0x58, 0x63, 0xd6, 0x47, 0x21, 0x15, 0x8e, 0xb4,
+	0x42, 0x28, 0x89, 0x0a, 0xe1, 0x77, 0x0a, 0xdc, 0x38, 0x98, 0x8c, 0x74, 0x3c, 0xb0, 0xfc, 0xc0,
+	0x9b, 0xe8, 0x8e, 0x13, 0x44, 0xd5, 0x08, 0x5b, 0x34, 0x03, 0x88, 0x25, 0x3a, 0xd6, 0x9f, 0x62,
+	0xbe, 0x63, 0xf4, 0x37, 0xc1, 0x11, 0x0e, 0xd1, 0x70, 0xa3, 0xdc, 0x6b, 0xb0, 0x70, 0xe8, 0xe1,
+	0x4b, 0xcb, 0x19, 0xfb, 0xa2, 0xab, 0x25, 0xe0, 0xb8, 0x7d, 0xca, 0x99, 0x79, 0xb1, 0x12, 0x5b,
+	0xf5, 0xbc, 0x58, 0xf5, 0x25, 0xdc, 0x4a, 0xbd, 0x28, 0x41, 0x1f, 0x73, 0xf5, 0xac, 0xc2, 0xb8,
+	0x1b, 0x7b, 0x7c, 0x22, 0xaf, 0x88, 0xcf, 0x6c, 0x85, 0x5c, 0x2d, 0x06, 0x23, 0xc3, 0xe5, 0xa6,
+	0xe1, 0x10, 0x99, 0x71, 0xc7, 0x22, 0x57, 0xfe, 0x03, 0xe6, 0x73, 0x55, 0x3d, 0x84, 0xb5, 0x06,
+	0xdc, 0xa0, 0x8f, 0x44, 0xa4, 0x38, 0xb1, 0x04, 0x85, 0x56, 0x58, 0x22, 0xb7, 0x68, 0x32, 0x6a,
+	0x85, 0xf7, 0x8e, 0x2d, 0x7a, 0xc4, 0x69, 0xbd, 0x14, 0xc9, 0xa9, 0xf5, 0x52, 0xfb, 0x1b, 0x05,
+	0x96, 0xb3, 0x9e, 0xae, 0xd0, 0xb6, 0x85, 0xe1, 0x19, 0x23, 0xbf, 0x83, 0x71, 0x5f, 0x64, 0xd6,
+	0x08, 0x43, 0xac, 0x75, 0x38, 0x3e, 0x1b, 0x5a, 0x26, 0x79, 0x80, 0xc9, 0xe4, 0x47, 0x08, 0xf4,
+	0x73, 0x39, 0x5c, 0x89, 0x8f, 0xe5, 0x6e, 0xe2, 0x6d, 0x4b, 0x44, 0x21, 0x47, 0x32, 0x5f, 0x1b,
+	0xc3, 0x75, 0x3a, 0x1e, 0xd6, 0x08, 0xf7, 0x61, 0xb1, 0x63, 0x0d, 0x46, 0x86, 0x34, 0x95, 0x08,
+	0x41, 0x3c, 0xe2, 0x84, 0x8e, 0xf0, 0x4a, 0x81, 0x02, 0xec, 0xb6, 0x93, 0xfb, 0xd5, 0x49, 0x22,
+	0x00, 0x91, 0x3a, 0xd7, 0x18, 0x06, 0x3e, 0x4d, 0x85, 0x55, 0x9d, 0x01, 0xda, 0x6b, 0x58, 0x8a,
+	0x3f, 0xb9, 0x41, 0x9f, 0xc2, 0xa2, 0x98, 0x83, 0x38, 0xe8, 0xaf, 0x26, 0xd6, 0x20, 0xc6, 0xf5,
+	0x88, 0x52, 0xfb, 0x6f, 0x05, 0xae, 0x49, 0x4f, 0x6f, 0xd0, 0x46, 0x58, 0x2a, 0x33, 0x5f, 0x48,
+	0x7e, 0x59, 0x7c, 0x14, 0x6d, 0xc0, 0x52, 0xd4, 0x4a, 0xa3, 0xbd, 0x62, 0xb6, 0xa2, 0x04, 0x96,
+	0x1e, 0x4b, 0xb0, 0xe1, 0x3b, 0x36, 0xbf, 0xc3, 0xe6, 0x10, 0x5a, 0x87, 0xe2, 0xc1, 0x64, 0xa4,
+	0x96, 0x32, 0x95, 0x90, 0x21, 0xe2, 0x4c, 0x6c, 0x4e, 0xb4, 0x0c, 0xa0, 0xf7, 0xdf, 0x02, 0x8e,
+	0xbb, 0x7f, 0x25, 0xd3, 0xfd, 0xa7, 0xdc, 0x87, 0xfd, 0xb5, 0x02, 0xab, 0x53, 0x1e, 0x08, 0x11,
+	0x53, 0xbf, 0xb3, 0xfa, 0xc1, 0x05, 0xaf, 0x41, 0x19, 0x20, 0x6a, 0x9b, 0x62, 0x58, 0xdb, 0x74,
+	0xb9, 0x6f, 0x2b, 0x5d, 0xc2, 0xd1, 0x74, 0xc6, 0x76, 0x9f, 0xae, 0xa3, 0xa8, 0x33, 0x80, 0xcc,
+	0x2e, 0xec, 0xf1, 0xf0, 0xe0, 0xb3, 0x18, 0x6b, 0xfa, 0x9c, 0xaa, 0x15, 0x26, 0xe1, 0x54, 0xdb,
+	0x85, 0xd5, 0x29, 0xcf, 0x89, 0x88, 0xf0, 0x5d, 0xbb, 0x8f, 0x7f, 0x10, 0xd3, 0xa1, 0x00, 0xbd,
+	0xf0, 0x27, 0x95, 0xb9, 0x27, 0xea, 0x77, 0x01, 0x6a, 0x75, 0xb8, 0x9f, 0xf7, 0x64, 0x88, 0xb5,
+	0x8a, 0xce, 0x1d, 0x91, 0x0e, 0xc9, 0x6f, 0xed, 0x5b, 0x78, 0x34, 0xe3, 0xc9, 0x4f, 0x16, 0x5b,
+	0xee, 0x25, 0xfa, 0x3b, 0x78, 0x90, 0xfb, 0x9a, 0x87, 0x6d, 0x8f, 0x22, 0x6d, 0x4f, 0x4b, 0x2d,
+	0x48, 0x85, 0x46, 0x74, 0x5a, 0x20, 0xd0, 0xb6, 0xf8, 0x12, 0xb6, 0x35, 0x03, 0xee, 0x64, 0x3e,
+	0xdd, 0xa1, 0xd5, 0x94, 0x31, 0xe2, 0x6e, 0xbf, 0xa8, 0x33, 0x80, 0x78, 0xde, 0x11, 0x39, 0x47,
+	0xfa, 0x7c, 0xf3, 0x38, 0x94, 0xce, 0xf6, 0x5d, 0xa1, 0xa2, 0xab, 0x8d, 0xe0, 0x76, 0xc6, 0x23,
+	0x1e, 0x16, 0xf7, 0x15, 0x29, 0xee, 0xc7, 0x9b, 0x09, 0xd2, 0x8c, 0xc3, 0xa9, 0x94, 0xb2, 0xa7,
+	0x52, 0x96, 0xa7, 0xa2, 0xfd, 0x8b, 0x02, 0xf7, 0xf3, 0x1a, 0xb3, 0xc4, 0x93, 0xb6, 0x2d, 0xdf,
+	0x1c, 0x3a, 0x3e, 0x0d, 0x26, 0x44, 0x64, 0x84, 0x20, 0xc7, 0xba, 0xb0, 0x37, 0x2b, 0xdd, 0x3f,
+	0x14, 0x28, 0x5d, 0xd6, 0x10, 0xf9, 0x6a, 0x43, 0x34, 0xf9, 0x17, 0x3c, 0x16, 0xed, 0x16, 0xf5,
+	0x04, 0x16, 0x6d, 0xc2, 0x8d, 0x10, 0x43, 0x7d, 0x9a, 0x2d, 0xa8, 0xaa, 0x27, 0xd1, 0xda, 0xaf,
+	0x15, 0x50, 0xa7, 0x3d, 0x15, 0x22, 0x62, 0xc2, 0xd9, 0x72, 0x03, 0x28, 0x4c, 0x4c, 0x02, 0xcd,
+	0x2c, 0x5c, 0x88, 0x9d, 0xc4, 0x8b, 0xe2, 0x24, 0x1e, 0x6f, 0x4a, 0x97, 0x52, 0x4d, 0xe9, 0x3e,
+	0xa0, 0xf4, 0x6b, 0x22, 0x7a, 0x92, 0x69, 0x8b, 0xe4, 0x72, 0xda, 0xa6, 0x70, 0x47, 0x24, 0x97,
+	0xd3, 0x0e, 0x85, 0xf7, 0xf9, 0x97, 0x5c, 0x38, 0xdd, 0x9f, 0xa9, 0xe5, 0x37, 0x89, 0x0b, 0xe8,
+	0xc4, 0xa5, 0x29, 0x2d, 0x2c, 0xa5, 0x47, 0x3e, 0xe4, 0x37, 0xf1, 0x06, 0xba, 0x3e, 0x11, 0xed,
+	0x29, 0x90, 0x3e, 0xb5, 0x46, 0x3e, 0xce, 0x1b, 0xaa, 0xe5, 0x44, 0x43, 0xb5, 0x12, 0x3e, 0xc5,
+	0xf9, 0x51, 0x81, 0x47, 0x33, 0xae, 0x93, 0x32, 0x67, 0x12, 0x73, 0x24, 0xd6, 0x03, 0x8a, 0x10,
+	0xd1, 0x3c, 0x8b, 0xa9, 0x79, 0xca, 0x65, 0x61, 0xbc, 0x10, 0xde, 0x16, 0x15, 0xc6, 0x36, 0xda,
+	0x22, 0xfb, 0x35, 0x7f, 0xc5, 0x0b, 0x33, 0xa5, 0xab, 0x3d, 0x84, 0xa5, 0xf8, 0x93, 0x2d, 0xf1,
+	0x46, 0x87, 0x06, 0xc5, 0xe3, 0xb3, 0x0a, 0x95, 0xf1, 0xf2, 0xff, 0x06, 0x00, 0xdb, 0xb9, 0x84,
+	0x1c, 0x95, 0x3a, 0x00, 0x00,
 }
@@ -0,0 +1,34 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package protobuf
+
+import (
+	"google.golang.org/grpc"
+)
+
+// This file is not generated. It exposes descriptions of emmy services, so that applications
+// can register emmy services with their own gRPC server (see grpc.Server.RegisterService).
+
+// ProtocolServiceDesc returns the description of the Protocol service.
+func ProtocolServiceDesc() *grpc.ServiceDesc {
+	return &_Protocol_serviceDesc
+}
+
+// InfoServiceDesc returns the description of the Info service.
+func InfoServiceDesc() *grpc.ServiceDesc {
+	return &_Info_serviceDesc
+}
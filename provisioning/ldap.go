@@ -0,0 +1,126 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package provisioning
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LDAPClient is the subset of LDAP operations needed by LDAPProvisioner. It is implemented
+// by LDIFWriter, and by a thin adapter of the LDAP client library the deployment uses.
+type LDAPClient interface {
+	Add(dn string, attributes map[string][]string) error
+	Delete(dn string) error
+}
+
+// LDAPProvisioner provisions the accounts as entries uid=<account ID>,<BaseDN> with
+// the object classes ObjectClasses (by default account from RFC 4524, whose only required
+// attribute is uid).
+type LDAPProvisioner struct {
+	BaseDN        string
+	ObjectClasses []string
+	client        LDAPClient
+}
+
+func NewLDAPProvisioner(client LDAPClient, baseDN string) *LDAPProvisioner {
+	return &LDAPProvisioner{
+		BaseDN:        baseDN,
+		ObjectClasses: []string{"top", "account"},
+		client:        client,
+	}
+}
+
+// DN returns the distinguished name of the entry for the account (account IDs need no
+// escaping).
+func (p *LDAPProvisioner) DN(id string) string {
+	return fmt.Sprintf("uid=%s,%s", id, p.BaseDN)
+}
+
+func (p *LDAPProvisioner) Provision(id string) error {
+	return p.client.Add(p.DN(id), map[string][]string{
+		"objectClass": p.ObjectClasses,
+		"uid":         {id},
+	})
+}
+
+func (p *LDAPProvisioner) Deprovision(id string) error {
+	return p.client.Delete(p.DN(id))
+}
+
+// LDIFWriter writes the operations as LDIF change records (RFC 2849), for example to be
+// applied with ldapmodify in a batch. It is safe for concurrent use.
+type LDIFWriter struct {
+	w     io.Writer
+	mutex sync.Mutex
+}
+
+func NewLDIFWriter(w io.Writer) *LDIFWriter {
+	return &LDIFWriter{
+		w: w,
+	}
+}
+
+func (l *LDIFWriter) Add(dn string, attributes map[string][]string) error {
+	var record strings.Builder
+	record.WriteString(ldifLine("dn", dn))
+	record.WriteString("changetype: add\n")
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range attributes[name] {
+			record.WriteString(ldifLine(name, value))
+		}
+	}
+	return l.write(record.String())
+}
+
+func (l *LDIFWriter) Delete(dn string) error {
+	return l.write(ldifLine("dn", dn) + "changetype: delete\n")
+}
+
+func (l *LDIFWriter) write(record string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	_, err := io.WriteString(l.w, record+"\n")
+	return err
+}
+
+// ldifLine returns "name: value", or "name:: base64(value)" when the value is not
+// a SAFE-STRING of RFC 2849.
+func ldifLine(name, value string) string {
+	safe := true
+	for i, c := range []byte(value) {
+		if c == 0 || c == '\n' || c == '\r' || c > 127 ||
+			(i == 0 && (c == ' ' || c == ':' || c == '<')) {
+			safe = false
+			break
+		}
+	}
+	if safe && !strings.HasSuffix(value, " ") {
+		return name + ": " + value + "\n"
+	}
+	return name + ":: " + base64.StdEncoding.EncodeToString([]byte(value)) + "\n"
+}
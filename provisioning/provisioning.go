@@ -0,0 +1,60 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package provisioning ties the nyms of the pseudonym system into existing directory
+// workflows: when a nym is registered, an opaque account is provisioned in the enterprise
+// directory (via SCIM or LDAP), and when the credential used with the nym is revoked,
+// the account is deprovisioned. The account is identified only by the hash of the nym,
+// so the directory learns nothing about the user beyond what the organization knows.
+package provisioning
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+)
+
+// Provisioner creates and removes accounts in a directory. Both operations need to be
+// idempotent - provisioning an existing account and deprovisioning an unknown one succeed.
+type Provisioner interface {
+	Provision(id string) error
+	Deprovision(id string) error
+}
+
+// NymAccountID returns the identifier of the account which corresponds to the nym.
+func NymAccountID(nym *pseudonymsys.Pseudonym) string {
+	return accountID(nym.A, nym.B)
+}
+
+// NymECAccountID returns the identifier of the account which corresponds to the EC nym.
+func NymECAccountID(nym *pseudonymsys.PseudonymEC) string {
+	return accountID(nym.A.X, nym.A.Y, nym.B.X, nym.B.Y)
+}
+
+// accountID returns "nym-" followed by the first 128 bits of the hash of the numbers in hex
+// (which is a valid user name and LDAP attribute value without any escaping).
+func accountID(numbers ...*big.Int) string {
+	h := sha256.New()
+	h.Write([]byte("emmy/provisioning"))
+	for _, n := range numbers {
+		b := n.Bytes()
+		h.Write([]byte{byte(len(b) >> 8), byte(len(b))})
+		h.Write(b)
+	}
+	return "nym-" + hex.EncodeToString(h.Sum(nil)[:16])
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package provisioning
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	scimUserSchema    = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimPatchOpSchema = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	scimContentType   = "application/scim+json"
+)
+
+// SCIMProvisioner provisions the accounts as users of a SCIM 2.0 service provider (RFC 7644):
+// the user name and the external ID of the user are the account ID. Deprovisioned users
+// are deleted, or only deactivated (active = false) when Deactivate is set.
+type SCIMProvisioner struct {
+	BaseURL    string // for example https://directory.example.com/scim/v2
+	Token      string // bearer token, if set
+	Deactivate bool
+	Client     *http.Client
+}
+
+func NewSCIMProvisioner(baseURL, token string) *SCIMProvisioner {
+	return &SCIMProvisioner{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Token:   token,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type scimUser struct {
+	Schemas    []string `json:"schemas"`
+	ID         string   `json:"id,omitempty"`
+	UserName   string   `json:"userName"`
+	ExternalID string   `json:"externalId,omitempty"`
+	Active     bool     `json:"active"`
+}
+
+type scimListResponse struct {
+	TotalResults int        `json:"totalResults"`
+	Resources    []scimUser `json:"Resources"`
+}
+
+func (p *SCIMProvisioner) Provision(id string) error {
+	user := scimUser{
+		Schemas:    []string{scimUserSchema},
+		UserName:   id,
+		ExternalID: id,
+		Active:     true,
+	}
+	status, _, err := p.do("POST", "/Users", user)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusCreated && status != http.StatusConflict { // conflict: exists
+		return fmt.Errorf("SCIM user %s was not created: status %d", id, status)
+	}
+	return nil
+}
+
+func (p *SCIMProvisioner) Deprovision(id string) error {
+	filter := url.QueryEscape(fmt.Sprintf(`userName eq "%s"`, id))
+	status, body, err := p.do("GET", "/Users?filter="+filter, nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("SCIM user %s was not found: status %d", id, status)
+	}
+	var list scimListResponse
+	if err := json.Unmarshal(body, &list); err != nil {
+		return err
+	}
+
+	for _, user := range list.Resources {
+		if user.UserName != id || user.ID == "" {
+			continue
+		}
+		path := "/Users/" + url.PathEscape(user.ID)
+		if p.Deactivate {
+			patch := map[string]interface{}{
+				"schemas": []string{scimPatchOpSchema},
+				"Operations": []map[string]interface{}{
+					{"op": "replace", "value": map[string]bool{"active": false}},
+				},
+			}
+			status, _, err = p.do("PATCH", path, patch)
+		} else {
+			status, _, err = p.do("DELETE", path, nil)
+		}
+		if err != nil {
+			return err
+		}
+		if status != http.StatusOK && status != http.StatusNoContent &&
+			status != http.StatusNotFound {
+			return fmt.Errorf("SCIM user %s was not deprovisioned: status %d", id, status)
+		}
+	}
+	return nil
+}
+
+// do sends the request with JSON body (if not nil) and returns the status and the body of
+// the response.
+func (p *SCIMProvisioner) do(method, path string, body interface{}) (int, []byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, p.BaseURL+path, reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Accept", scimContentType)
+	if body != nil {
+		req.Header.Set("Content-Type", scimContentType)
+	}
+	if p.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Token)
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, data, nil
+}
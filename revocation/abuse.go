@@ -0,0 +1,208 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package revocation
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AbuseReport is the evidence of a misbehaving session, submitted by the verifier (Reporter)
+// to the issuer: the blacklist ticket of the session and the hash of the session transcript.
+// The ticket does not identify the user, it can only be put on the blacklist. Nym is set
+// only when the user authenticated to the verifier with a nym (for example when
+// the credential was transferred), as it enables the inspection of escrowed identities
+// (see pseudonymsys.Auditor).
+type AbuseReport struct {
+	Ticket         *pseudonymsys.BlacklistTicket
+	TranscriptHash []byte
+	Reason         string
+	Nym            *pseudonymsys.Pseudonym
+	Reporter       string
+	Timestamp      int64
+	R              *big.Int
+	S              *big.Int
+}
+
+func NewAbuseReport(ticket *pseudonymsys.BlacklistTicket, transcriptHash []byte,
+	reason string) *AbuseReport {
+	return &AbuseReport{
+		Ticket:         ticket,
+		TranscriptHash: transcriptHash,
+		Reason:         reason,
+	}
+}
+
+// Sign timestamps the report and signs it in the name of the reporter with ECDSA (P256)
+// key d.
+func (report *AbuseReport) Sign(reporter string, d, x, y *big.Int) error {
+	pubKey := ecdsa.PublicKey{Curve: dlog.GetEllipticCurve(dlog.P256), X: x, Y: y}
+	report.Reporter = reporter
+	report.Timestamp = time.Now().Unix()
+	r, s, err := ecdsa.Sign(rand.Reader, &ecdsa.PrivateKey{PublicKey: pubKey, D: d},
+		report.hash())
+	if err != nil {
+		return err
+	}
+	report.R, report.S = r, s
+	return nil
+}
+
+// Verify checks that the report was signed with public key (x, y).
+func (report *AbuseReport) Verify(x, y *big.Int) bool {
+	if report.Ticket == nil || report.R == nil || report.S == nil {
+		return false
+	}
+	pubKey := ecdsa.PublicKey{Curve: dlog.GetEllipticCurve(dlog.P256), X: x, Y: y}
+	return ecdsa.Verify(&pubKey, report.hash(), report.R, report.S)
+}
+
+func (report *AbuseReport) hash() []byte {
+	h := sha512.New()
+	h.Write([]byte("emmy/abuse-report"))
+	write := func(b []byte) {
+		l := make([]byte, 8)
+		binary.BigEndian.PutUint64(l, uint64(len(b)))
+		h.Write(l)
+		h.Write(b)
+	}
+	write(report.Ticket.H.Bytes())
+	write(report.Ticket.Tag.Bytes())
+	write(report.TranscriptHash)
+	write([]byte(report.Reason))
+	if report.Nym != nil {
+		write(report.Nym.A.Bytes())
+		write(report.Nym.B.Bytes())
+	} else {
+		write(nil)
+		write(nil)
+	}
+	write([]byte(report.Reporter))
+	timestamp := make([]byte, 8)
+	binary.BigEndian.PutUint64(timestamp, uint64(report.Timestamp))
+	h.Write(timestamp)
+	return h.Sum(nil)
+}
+
+// Escalation is a ticket which was reported by at least threshold reporters (see AbuseDesk),
+// together with the reports.
+type Escalation struct {
+	Ticket  *pseudonymsys.BlacklistTicket
+	Version uint64 // version of the registry at which the ticket was revoked
+	Reports []*AbuseReport
+}
+
+// AbuseDesk collects the abuse reports of the verifiers. A single report does not lead
+// to any action, so that a single (possibly malicious) verifier cannot get users
+// blacklisted. Once reports from threshold distinct reporters are collected for the same
+// ticket, the ticket is revoked in the registry and the reports are escalated. Only the
+// escalated reports are exposed - they are the basis for the decision about
+// the inspection of the escrowed identity, which is thus never done routinely.
+// It is safe for concurrent use.
+type AbuseDesk struct {
+	registry    Registry
+	threshold   int
+	reporters   map[string]*ecdsa.PublicKey
+	reports     map[string]map[string]*AbuseReport // by ticket and reporter
+	escalations []*Escalation
+	sync.Mutex
+}
+
+// NewAbuseDesk returns a desk which revokes the tickets in the registry once they are
+// reported by threshold reporters (at least 1).
+func NewAbuseDesk(registry Registry, threshold int) *AbuseDesk {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &AbuseDesk{
+		registry:  registry,
+		threshold: threshold,
+		reporters: make(map[string]*ecdsa.PublicKey),
+		reports:   make(map[string]map[string]*AbuseReport),
+	}
+}
+
+// AddReporter registers the verifier whose reports are accepted, with its ECDSA (P256)
+// public key (x, y).
+func (desk *AbuseDesk) AddReporter(name string, x, y *big.Int) {
+	desk.Lock()
+	defer desk.Unlock()
+	desk.reporters[name] = &ecdsa.PublicKey{Curve: dlog.GetEllipticCurve(dlog.P256), X: x, Y: y}
+}
+
+// Submit verifies the report and stores it. It returns true when the report caused
+// the ticket to be revoked. Repeated reports of the same reporter for the same ticket
+// replace the previous one.
+func (desk *AbuseDesk) Submit(report *AbuseReport) (bool, error) {
+	desk.Lock()
+	defer desk.Unlock()
+
+	pubKey, ok := desk.reporters[report.Reporter]
+	if !ok {
+		return false, fmt.Errorf("unknown reporter %s", report.Reporter)
+	}
+	if !report.Verify(pubKey.X, pubKey.Y) {
+		return false, fmt.Errorf("report signature is not valid")
+	}
+
+	key := ticketKey(report.Ticket)
+	if desk.reports[key] == nil {
+		desk.reports[key] = make(map[string]*AbuseReport)
+	}
+	_, reported := desk.reports[key][report.Reporter]
+	desk.reports[key][report.Reporter] = report
+	if reported || len(desk.reports[key]) != desk.threshold {
+		return false, nil
+	}
+
+	version, err := desk.registry.Revoke(report.Ticket)
+	if err != nil {
+		delete(desk.reports[key], report.Reporter)
+		return false, err
+	}
+	reports := make([]*AbuseReport, 0, len(desk.reports[key]))
+	for _, r := range desk.reports[key] {
+		reports = append(reports, r)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Reporter < reports[j].Reporter })
+	desk.escalations = append(desk.escalations, &Escalation{
+		Ticket:  report.Ticket,
+		Version: version,
+		Reports: reports,
+	})
+	return true, nil
+}
+
+// Escalations returns the tickets which were revoked, in the order of revocation.
+func (desk *AbuseDesk) Escalations() []*Escalation {
+	desk.Lock()
+	defer desk.Unlock()
+
+	escalations := make([]*Escalation, len(desk.escalations))
+	copy(escalations, desk.escalations)
+	return escalations
+}
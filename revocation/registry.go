@@ -0,0 +1,94 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package revocation keeps the blacklisted tickets of the blacklistable authentication
+// (see pseudonymsys.BlacklistVerifier) in a versioned registry. Every revocation increments
+// the version of the registry, and the list of tickets at any version can be exported as
+// a snapshot signed by the issuer. Verifiers which have no connection to the registry
+// (for example air-gapped ones) load the latest snapshot file and check non-revocation
+// proofs against it.
+package revocation
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"sync"
+)
+
+// Registry stores the blacklisted tickets. Versions start at 1 (version 0 is the empty
+// registry) and each newly revoked ticket gets the next version.
+type Registry interface {
+	// Revoke puts the ticket on the blacklist and returns the version at which it was
+	// revoked. Revoking an already revoked ticket returns its existing version.
+	Revoke(ticket *pseudonymsys.BlacklistTicket) (uint64, error)
+	// Version returns the current version of the registry.
+	Version() (uint64, error)
+	// Tickets returns the tickets revoked up to (including) the given version, ordered by
+	// the version of revocation.
+	Tickets(version uint64) ([]*pseudonymsys.BlacklistTicket, error)
+}
+
+// MemoryRegistry is an in-memory Registry (for example for testing). It is safe for
+// concurrent use.
+type MemoryRegistry struct {
+	tickets  []*pseudonymsys.BlacklistTicket
+	versions map[string]uint64
+	mutex    sync.Mutex
+}
+
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{
+		versions: make(map[string]uint64),
+	}
+}
+
+func (registry *MemoryRegistry) Revoke(ticket *pseudonymsys.BlacklistTicket) (uint64, error) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	key := ticketKey(ticket)
+	if version, ok := registry.versions[key]; ok {
+		return version, nil
+	}
+	registry.tickets = append(registry.tickets, ticket)
+	version := uint64(len(registry.tickets))
+	registry.versions[key] = version
+	return version, nil
+}
+
+func (registry *MemoryRegistry) Version() (uint64, error) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	return uint64(len(registry.tickets)), nil
+}
+
+func (registry *MemoryRegistry) Tickets(version uint64) ([]*pseudonymsys.BlacklistTicket,
+	error) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if version > uint64(len(registry.tickets)) {
+		return nil, fmt.Errorf("version %d does not exist", version)
+	}
+	tickets := make([]*pseudonymsys.BlacklistTicket, version)
+	copy(tickets, registry.tickets[:version])
+	return tickets, nil
+}
+
+func ticketKey(ticket *pseudonymsys.BlacklistTicket) string {
+	return ticket.H.Text(16) + ":" + ticket.Tag.Text(16)
+}
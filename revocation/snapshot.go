@@ -0,0 +1,175 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package revocation
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"github.com/xlab-si/emmy/storage"
+	"math/big"
+	"time"
+)
+
+// Snapshot is the list of blacklisted tickets at the given version of the registry,
+// signed by the issuer at the given time (Unix seconds).
+type Snapshot struct {
+	Version   uint64
+	Timestamp int64
+	Tickets   []*pseudonymsys.BlacklistTicket
+	R         *big.Int
+	S         *big.Int
+}
+
+// SnapshotSigner exports signed snapshots of the registry.
+type SnapshotSigner struct {
+	registry   Registry
+	privateKey *ecdsa.PrivateKey
+}
+
+// NewSnapshotSigner returns a signer which signs the snapshots of the registry with
+// ECDSA (P256) key d.
+func NewSnapshotSigner(registry Registry, d, x, y *big.Int) *SnapshotSigner {
+	pubKey := ecdsa.PublicKey{Curve: dlog.GetEllipticCurve(dlog.P256), X: x, Y: y}
+	return &SnapshotSigner{
+		registry:   registry,
+		privateKey: &ecdsa.PrivateKey{PublicKey: pubKey, D: d},
+	}
+}
+
+// GetRegistry returns the registry whose snapshots are signed.
+func (signer *SnapshotSigner) GetRegistry() Registry {
+	return signer.registry
+}
+
+// Snapshot returns the signed snapshot of the current version of the registry.
+func (signer *SnapshotSigner) Snapshot() (*Snapshot, error) {
+	version, err := signer.registry.Version()
+	if err != nil {
+		return nil, err
+	}
+	return signer.SnapshotAt(version)
+}
+
+// SnapshotAt returns the signed snapshot of the given version of the registry.
+func (signer *SnapshotSigner) SnapshotAt(version uint64) (*Snapshot, error) {
+	tickets, err := signer.registry.Tickets(version)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{
+		Version:   version,
+		Timestamp: time.Now().Unix(),
+		Tickets:   tickets,
+	}
+	snapshot.R, snapshot.S, err = ecdsa.Sign(rand.Reader, signer.privateKey, snapshot.hash())
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Verify checks that the snapshot was signed by the issuer with public key (x, y).
+func (snapshot *Snapshot) Verify(x, y *big.Int) bool {
+	if snapshot.R == nil || snapshot.S == nil {
+		return false
+	}
+	pubKey := ecdsa.PublicKey{Curve: dlog.GetEllipticCurve(dlog.P256), X: x, Y: y}
+	return ecdsa.Verify(&pubKey, snapshot.hash(), snapshot.R, snapshot.S)
+}
+
+// IsFresh returns true if the snapshot was signed less than maxAge ago. Verifiers should
+// refuse to check proofs against snapshots older than their revocation policy allows.
+func (snapshot *Snapshot) IsFresh(maxAge time.Duration) bool {
+	return time.Since(time.Unix(snapshot.Timestamp, 0)) < maxAge
+}
+
+// Store writes the snapshot into a file which can be transferred to offline verifiers.
+func (snapshot *Snapshot) Store(path string) error {
+	msg := &pb.RevocationSnapshot{
+		Version:   snapshot.Version,
+		Timestamp: snapshot.Timestamp,
+		Tickets:   make([]*pb.Pair, len(snapshot.Tickets)),
+		R:         snapshot.R.Bytes(),
+		S:         snapshot.S.Bytes(),
+	}
+	for i, ticket := range snapshot.Tickets {
+		msg.Tickets[i] = &pb.Pair{A: ticket.H.Bytes(), B: ticket.Tag.Bytes()}
+	}
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return storage.Store(data, path)
+}
+
+// LoadSnapshot reads the snapshot from the file and checks that it was signed by
+// the issuer with public key (x, y).
+func LoadSnapshot(path string, x, y *big.Int) (*Snapshot, error) {
+	data, err := storage.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	msg := &pb.RevocationSnapshot{}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		Version:   msg.Version,
+		Timestamp: msg.Timestamp,
+		Tickets:   make([]*pseudonymsys.BlacklistTicket, len(msg.Tickets)),
+		R:         new(big.Int).SetBytes(msg.R),
+		S:         new(big.Int).SetBytes(msg.S),
+	}
+	for i, t := range msg.Tickets {
+		snapshot.Tickets[i] = pseudonymsys.NewBlacklistTicket(new(big.Int).SetBytes(t.A),
+			new(big.Int).SetBytes(t.B))
+	}
+	if !snapshot.Verify(x, y) {
+		return nil, fmt.Errorf("invalid signature of the revocation snapshot")
+	}
+	return snapshot, nil
+}
+
+// hash binds the signature to the version, the time and the (ordered) tickets.
+func (snapshot *Snapshot) hash() []byte {
+	h := sha512.New()
+	h.Write([]byte("emmy/revocation"))
+	header := make([]byte, 24)
+	binary.BigEndian.PutUint64(header, snapshot.Version)
+	binary.BigEndian.PutUint64(header[8:], uint64(snapshot.Timestamp))
+	binary.BigEndian.PutUint64(header[16:], uint64(len(snapshot.Tickets)))
+	h.Write(header)
+	for _, ticket := range snapshot.Tickets {
+		for _, x := range []*big.Int{ticket.H, ticket.Tag} {
+			b := x.Bytes()
+			l := make([]byte, 8)
+			binary.BigEndian.PutUint64(l, uint64(len(b)))
+			h.Write(l)
+			h.Write(b)
+		}
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package revocation
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const sqlCreateTable = `CREATE TABLE IF NOT EXISTS emmy_revocation (
+	version BIGINT PRIMARY KEY,
+	h TEXT NOT NULL,
+	tag TEXT NOT NULL,
+	UNIQUE (h, tag)
+)`
+
+// SQLRegistry is a Registry stored in an SQL database (the table emmy_revocation is
+// created if it does not exist). Group elements are stored as hex strings, so that the
+// registry works with any database supported by database/sql - the caller opens the
+// database with the driver of its choice.
+//
+// Revocations are done in transactions. When several processes revoke at the same time,
+// one of the transactions can fail on the primary key (version) and the revocation needs
+// to be repeated.
+type SQLRegistry struct {
+	db       *sql.DB
+	dollarPH bool // whether the driver uses $1, $2, ... placeholders instead of ?
+}
+
+// NewSQLRegistry returns a registry in db which was opened with the given driver name
+// (needed to choose the placeholder syntax).
+func NewSQLRegistry(db *sql.DB, driver string) (*SQLRegistry, error) {
+	registry := &SQLRegistry{
+		db:       db,
+		dollarPH: driver == "postgres" || driver == "pgx",
+	}
+	if _, err := db.Exec(sqlCreateTable); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+func (registry *SQLRegistry) Revoke(ticket *pseudonymsys.BlacklistTicket) (uint64, error) {
+	h, tag := ticket.H.Text(16), ticket.Tag.Text(16)
+	tx, err := registry.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var version uint64
+	err = tx.QueryRow(registry.query(
+		"SELECT version FROM emmy_revocation WHERE h = ? AND tag = ?"), h, tag).Scan(&version)
+	if err == nil {
+		return version, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	if err := tx.QueryRow(
+		"SELECT COALESCE(MAX(version), 0) FROM emmy_revocation").Scan(&version); err != nil {
+		return 0, err
+	}
+	version++
+	if _, err := tx.Exec(registry.query(
+		"INSERT INTO emmy_revocation (version, h, tag) VALUES (?, ?, ?)"),
+		version, h, tag); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+func (registry *SQLRegistry) Version() (uint64, error) {
+	var version uint64
+	err := registry.db.QueryRow(
+		"SELECT COALESCE(MAX(version), 0) FROM emmy_revocation").Scan(&version)
+	return version, err
+}
+
+func (registry *SQLRegistry) Tickets(version uint64) ([]*pseudonymsys.BlacklistTicket,
+	error) {
+	rows, err := registry.db.Query(registry.query(
+		"SELECT version, h, tag FROM emmy_revocation WHERE version <= ? ORDER BY version"),
+		version)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tickets []*pseudonymsys.BlacklistTicket
+	for rows.Next() {
+		var v uint64
+		var h, tag string
+		if err := rows.Scan(&v, &h, &tag); err != nil {
+			return nil, err
+		}
+		hInt, ok1 := new(big.Int).SetString(h, 16)
+		tagInt, ok2 := new(big.Int).SetString(tag, 16)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("invalid ticket at version %d", v)
+		}
+		tickets = append(tickets, pseudonymsys.NewBlacklistTicket(hInt, tagInt))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// versions are consecutive, so a shorter list means the version does not exist yet
+	if uint64(len(tickets)) != version {
+		return nil, fmt.Errorf("version %d does not exist", version)
+	}
+	return tickets, nil
+}
+
+// query replaces ? placeholders with $1, $2, ... when the driver requires it.
+func (registry *SQLRegistry) query(q string) string {
+	if !registry.dollarPH {
+		return q
+	}
+	parts := strings.Split(q, "?")
+	res := parts[0]
+	for i, part := range parts[1:] {
+		res += "$" + strconv.Itoa(i+1) + part
+	}
+	return res
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package revocation
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+	"sync"
+)
+
+// Clients do not need to download the whole blacklist each time it changes. The registry
+// only grows, so a client which holds the snapshot at version v needs just the tickets
+// revoked after v (an Update). The update carries the signature of the new snapshot, thus
+// the client can check that its local copy (Replica) is exactly the list signed by
+// the issuer.
+//
+// Note that in the blacklistable authentication the non-revocation proof is computed
+// directly from the list of tickets, so there are no other per-user witnesses to update -
+// keeping the replica in sync is all the client needs to do.
+
+// Update contains the tickets revoked after version From up to (including) Version and
+// the signature of the snapshot at Version.
+type Update struct {
+	From      uint64
+	Version   uint64
+	Timestamp int64
+	Tickets   []*pseudonymsys.BlacklistTicket
+	R         *big.Int
+	S         *big.Int
+}
+
+// Update returns the update from version from to the current version of the registry.
+// When there were no revocations since from, the update contains no tickets but a fresh
+// signature.
+func (signer *SnapshotSigner) Update(from uint64) (*Update, error) {
+	snapshot, err := signer.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	if from > snapshot.Version {
+		return nil, fmt.Errorf("version %d does not exist", from)
+	}
+	return &Update{
+		From:      from,
+		Version:   snapshot.Version,
+		Timestamp: snapshot.Timestamp,
+		Tickets:   snapshot.Tickets[from:],
+		R:         snapshot.R,
+		S:         snapshot.S,
+	}, nil
+}
+
+// Replica is the client's copy of the registry which is kept in sync by applying updates.
+// It is safe for concurrent use.
+type Replica struct {
+	x        *big.Int
+	y        *big.Int
+	snapshot *Snapshot
+	mutex    sync.Mutex
+}
+
+// NewReplica returns an empty replica of the registry whose snapshots are signed with
+// public key (x, y).
+func NewReplica(x, y *big.Int) *Replica {
+	return &Replica{
+		x:        x,
+		y:        y,
+		snapshot: &Snapshot{},
+	}
+}
+
+// NewReplicaFromSnapshot returns a replica which starts from the snapshot (for example
+// one loaded with LoadSnapshot).
+func NewReplicaFromSnapshot(snapshot *Snapshot, x, y *big.Int) (*Replica, error) {
+	if !snapshot.Verify(x, y) {
+		return nil, fmt.Errorf("invalid signature of the revocation snapshot")
+	}
+	return &Replica{
+		x:        x,
+		y:        y,
+		snapshot: snapshot,
+	}, nil
+}
+
+// Version returns the version of the registry which the replica holds.
+func (replica *Replica) Version() uint64 {
+	replica.mutex.Lock()
+	defer replica.mutex.Unlock()
+	return replica.snapshot.Version
+}
+
+// Snapshot returns the latest signed snapshot (with no signature if no update has been
+// applied to an empty replica yet).
+func (replica *Replica) Snapshot() *Snapshot {
+	replica.mutex.Lock()
+	defer replica.mutex.Unlock()
+	return replica.snapshot
+}
+
+// Tickets returns the blacklisted tickets, to be used in the non-revocation proof
+// (see pseudonymsys.BlacklistProver).
+func (replica *Replica) Tickets() []*pseudonymsys.BlacklistTicket {
+	return replica.Snapshot().Tickets
+}
+
+// Apply appends the tickets from the update. It fails (and leaves the replica unchanged)
+// if the update does not start at the version of the replica or if the resulting list
+// is not the one signed by the issuer.
+func (replica *Replica) Apply(update *Update) error {
+	replica.mutex.Lock()
+	defer replica.mutex.Unlock()
+
+	current := replica.snapshot
+	if update.From != current.Version {
+		return fmt.Errorf("update starts at version %d, replica is at %d", update.From,
+			current.Version)
+	}
+	if update.Version-update.From != uint64(len(update.Tickets)) {
+		return fmt.Errorf("update does not contain all tickets up to version %d",
+			update.Version)
+	}
+	if update.Timestamp < current.Timestamp {
+		return fmt.Errorf("update is older than the replica")
+	}
+
+	tickets := make([]*pseudonymsys.BlacklistTicket, 0, update.Version)
+	tickets = append(tickets, current.Tickets...)
+	snapshot := &Snapshot{
+		Version:   update.Version,
+		Timestamp: update.Timestamp,
+		Tickets:   append(tickets, update.Tickets...),
+		R:         update.R,
+		S:         update.S,
+	}
+	if !snapshot.Verify(replica.x, replica.y) {
+		return fmt.Errorf("invalid signature of the revocation update")
+	}
+	replica.snapshot = snapshot
+	return nil
+}
+
+// NotifyingRegistry wraps a registry and notifies the subscribers (for example the server
+// streaming updates to clients) about the revocations done through it.
+type NotifyingRegistry struct {
+	Registry
+	changed chan struct{}
+	mutex   sync.Mutex
+}
+
+func NewNotifyingRegistry(registry Registry) *NotifyingRegistry {
+	return &NotifyingRegistry{
+		Registry: registry,
+		changed:  make(chan struct{}),
+	}
+}
+
+func (registry *NotifyingRegistry) Revoke(ticket *pseudonymsys.BlacklistTicket) (uint64,
+	error) {
+	version, err := registry.Registry.Revoke(ticket)
+	if err != nil {
+		return 0, err
+	}
+	registry.mutex.Lock()
+	close(registry.changed)
+	registry.changed = make(chan struct{})
+	registry.mutex.Unlock()
+	return version, nil
+}
+
+// Changed returns a channel which is closed at the next revocation.
+func (registry *NotifyingRegistry) Changed() <-chan struct{} {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	return registry.changed
+}
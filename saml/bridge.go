@@ -0,0 +1,142 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package saml bridges emmy credential presentations to SAML 2.0 service providers (for
+// example the SPs of academic Shibboleth federations) which cannot verify zero-knowledge
+// proofs: after the presentation has been verified, the bridge (acting as a SAML identity
+// provider) issues a short-lived signed assertion which contains only the disclosed
+// attributes. The subject of the assertion is a random transient NameID, so assertions issued
+// for different presentations of the same credential cannot be linked by the SP.
+package saml
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"math/big"
+	"time"
+)
+
+// AuthnContextClassRef denotes in the assertions that the user authenticated by
+// a zero-knowledge presentation of an emmy credential.
+const AuthnContextClassRef = "urn:emmy:ac:classes:ZKPresentation"
+
+// DefaultLifetime is the validity period of the assertions.
+const DefaultLifetime = 5 * time.Minute
+
+// Attribute is a disclosed attribute. Name is the SAML attribute name (usually an URI or
+// OID, for example urn:oid:1.3.6.1.4.1.5923.1.1.1.1 for eduPersonAffiliation).
+type Attribute struct {
+	Name   string
+	Values []string
+}
+
+// Presentation holds the attributes disclosed in a verified presentation.
+type Presentation struct {
+	Attributes []Attribute
+}
+
+// NewMerkleCLPresentation verifies the disclosure of the attributes of Merkle-ized
+// credential (see signatures.CL.VerifyMerkleDisclosure) and returns the presentation with
+// the revealed attributes (as decimal integers) named by their index in names.
+func NewMerkleCLPresentation(cl *signatures.CL, root *big.Int, signature *signatures.CLSignature,
+	decommitments []*commitments.MerkleDecommitment, names []string) (*Presentation, error) {
+	verified, err := cl.VerifyMerkleDisclosure(root, signature, decommitments)
+	if err != nil {
+		return nil, err
+	}
+	if !verified {
+		return nil, fmt.Errorf("disclosed attributes are not valid")
+	}
+
+	presentation := new(Presentation)
+	for _, decommitment := range decommitments {
+		if decommitment.Index < 0 || decommitment.Index >= len(names) {
+			return nil, fmt.Errorf("no name for attribute %d", decommitment.Index)
+		}
+		presentation.Attributes = append(presentation.Attributes, Attribute{
+			Name:   names[decommitment.Index],
+			Values: []string{decommitment.Attribute.String()},
+		})
+	}
+	return presentation, nil
+}
+
+// Request identifies the SP for which the assertion is issued.
+type Request struct {
+	Audience     string // entity ID of the SP
+	Recipient    string // assertion consumer service URL of the SP
+	InResponseTo string // ID of the SP's authentication request (empty for unsolicited ones)
+}
+
+// Bridge issues the assertions signed with RSA-SHA256 (enveloped XML signature with
+// exclusive canonicalization). The certificate is the one published in the bridge's
+// IdP metadata.
+type Bridge struct {
+	EntityID    string
+	Lifetime    time.Duration
+	key         *rsa.PrivateKey
+	certificate *x509.Certificate
+}
+
+func NewBridge(entityID string, key *rsa.PrivateKey, certificate *x509.Certificate) *Bridge {
+	return &Bridge{
+		EntityID:    entityID,
+		Lifetime:    DefaultLifetime,
+		key:         key,
+		certificate: certificate,
+	}
+}
+
+// Issue returns the signed assertion (XML) for the verified presentation.
+func (bridge *Bridge) Issue(presentation *Presentation, request *Request) ([]byte, error) {
+	if request.Audience == "" || request.Recipient == "" {
+		return nil, fmt.Errorf("audience and recipient of the assertion are required")
+	}
+	id, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	nameID, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	assertion := &assertion{
+		ID:           id,
+		IssueInstant: now,
+		NotOnOrAfter: now.Add(bridge.Lifetime),
+		Issuer:       bridge.EntityID,
+		NameID:       nameID,
+		Request:      request,
+		Attributes:   presentation.Attributes,
+	}
+	return assertion.sign(bridge.key, bridge.certificate)
+}
+
+// randomID returns a random identifier (it starts with a letter as required for xs:ID).
+func randomID() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package saml
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"strings"
+	"time"
+)
+
+const (
+	namespaceAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+	namespaceDSig      = "http://www.w3.org/2000/09/xmldsig#"
+	algorithmExcC14N   = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	algorithmEnveloped = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	algorithmRSASHA256 = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	algorithmSHA256    = "http://www.w3.org/2001/04/xmlenc#sha256"
+	nameIDTransient    = "urn:oasis:names:tc:SAML:2.0:nameid-format:transient"
+	attributeNameURI   = "urn:oasis:names:tc:SAML:2.0:attrname-format:uri"
+	confirmationBearer = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+)
+
+// assertion is serialized directly in the exclusive canonical form (attributes in
+// lexicographic order, no empty-element tags, canonical escaping), thus the digest and
+// the signature are computed over the very bytes which are sent and no XML
+// canonicalization library is needed.
+type assertion struct {
+	ID           string
+	IssueInstant time.Time
+	NotOnOrAfter time.Time
+	Issuer       string
+	NameID       string
+	Request      *Request
+	Attributes   []Attribute
+}
+
+// sign returns the assertion with the enveloped signature, which is placed after the Issuer
+// as required by the SAML schema.
+func (a *assertion) sign(key *rsa.PrivateKey, certificate *x509.Certificate) ([]byte, error) {
+	head, body := a.canonical()
+	digest := sha256.Sum256([]byte(head + body))
+
+	signedInfo := new(xmlWriter)
+	signedInfo.open("ds:SignedInfo")
+	signedInfo.element("ds:CanonicalizationMethod", "", "Algorithm", algorithmExcC14N)
+	signedInfo.element("ds:SignatureMethod", "", "Algorithm", algorithmRSASHA256)
+	signedInfo.open("ds:Reference", "URI", "#"+a.ID)
+	signedInfo.open("ds:Transforms")
+	signedInfo.element("ds:Transform", "", "Algorithm", algorithmEnveloped)
+	signedInfo.element("ds:Transform", "", "Algorithm", algorithmExcC14N)
+	signedInfo.close("ds:Transforms")
+	signedInfo.element("ds:DigestMethod", "", "Algorithm", algorithmSHA256)
+	signedInfo.element("ds:DigestValue", base64.StdEncoding.EncodeToString(digest[:]))
+	signedInfo.close("ds:Reference")
+	signedInfo.close("ds:SignedInfo")
+
+	// in the canonical form SignedInfo declares the namespace it inherits from Signature
+	canonicalSignedInfo := strings.Replace(signedInfo.String(), "<ds:SignedInfo>",
+		`<ds:SignedInfo xmlns:ds="`+namespaceDSig+`">`, 1)
+	hashed := sha256.Sum256([]byte(canonicalSignedInfo))
+	signatureValue, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		return nil, err
+	}
+
+	signature := new(xmlWriter)
+	signature.open("ds:Signature", "xmlns:ds", namespaceDSig)
+	signature.WriteString(signedInfo.String())
+	signature.element("ds:SignatureValue", base64.StdEncoding.EncodeToString(signatureValue))
+	signature.open("ds:KeyInfo")
+	signature.open("ds:X509Data")
+	signature.element("ds:X509Certificate", base64.StdEncoding.EncodeToString(certificate.Raw))
+	signature.close("ds:X509Data")
+	signature.close("ds:KeyInfo")
+	signature.close("ds:Signature")
+
+	return []byte(head + signature.String() + body), nil
+}
+
+// canonical returns the canonical form of the assertion split after the Issuer (where
+// the signature is to be inserted).
+func (a *assertion) canonical() (string, string) {
+	instant := a.IssueInstant.Format(time.RFC3339)
+	notOnOrAfter := a.NotOnOrAfter.Format(time.RFC3339)
+
+	head := new(xmlWriter)
+	head.open("saml:Assertion", "xmlns:saml", namespaceAssertion, "ID", a.ID,
+		"IssueInstant", instant, "Version", "2.0")
+	head.element("saml:Issuer", a.Issuer)
+
+	body := new(xmlWriter)
+	body.open("saml:Subject")
+	body.element("saml:NameID", a.NameID, "Format", nameIDTransient,
+		"SPNameQualifier", a.Request.Audience)
+	body.open("saml:SubjectConfirmation", "Method", confirmationBearer)
+	if a.Request.InResponseTo != "" {
+		body.element("saml:SubjectConfirmationData", "", "InResponseTo",
+			a.Request.InResponseTo, "NotOnOrAfter", notOnOrAfter, "Recipient",
+			a.Request.Recipient)
+	} else {
+		body.element("saml:SubjectConfirmationData", "", "NotOnOrAfter", notOnOrAfter,
+			"Recipient", a.Request.Recipient)
+	}
+	body.close("saml:SubjectConfirmation")
+	body.close("saml:Subject")
+
+	body.open("saml:Conditions", "NotBefore", instant, "NotOnOrAfter", notOnOrAfter)
+	body.open("saml:AudienceRestriction")
+	body.element("saml:Audience", a.Request.Audience)
+	body.close("saml:AudienceRestriction")
+	body.close("saml:Conditions")
+
+	body.open("saml:AuthnStatement", "AuthnInstant", instant)
+	body.open("saml:AuthnContext")
+	body.element("saml:AuthnContextClassRef", AuthnContextClassRef)
+	body.close("saml:AuthnContext")
+	body.close("saml:AuthnStatement")
+
+	if len(a.Attributes) > 0 {
+		body.open("saml:AttributeStatement")
+		for _, attribute := range a.Attributes {
+			body.open("saml:Attribute", "Name", attribute.Name, "NameFormat", attributeNameURI)
+			for _, value := range attribute.Values {
+				body.element("saml:AttributeValue", value)
+			}
+			body.close("saml:Attribute")
+		}
+		body.close("saml:AttributeStatement")
+	}
+	body.close("saml:Assertion")
+
+	return head.String(), body.String()
+}
+
+// xmlWriter writes elements in the canonical form - the callers need to pass
+// the namespace declarations first and the other attributes in lexicographic order.
+type xmlWriter struct {
+	bytes.Buffer
+}
+
+func (w *xmlWriter) open(name string, attributes ...string) {
+	w.WriteString("<" + name)
+	for i := 0; i+1 < len(attributes); i += 2 {
+		w.WriteString(" " + attributes[i] + `="` + escapeAttribute(attributes[i+1]) + `"`)
+	}
+	w.WriteString(">")
+}
+
+func (w *xmlWriter) close(name string) {
+	w.WriteString("</" + name + ">")
+}
+
+// element writes the element with the text content (possibly empty).
+func (w *xmlWriter) element(name, text string, attributes ...string) {
+	w.open(name, attributes...)
+	w.WriteString(escapeText(text))
+	w.close(name)
+}
+
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+
+var attributeEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;",
+	"\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func escapeAttribute(s string) string {
+	return attributeEscaper.Replace(s)
+}
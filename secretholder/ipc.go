@@ -0,0 +1,209 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package secretholder
+
+import (
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+	"net"
+	"net/rpc"
+)
+
+// Server serves the secret holder to the clients connected over a local socket (net/rpc).
+type Server struct {
+	name      string
+	newHolder func() interface{}
+}
+
+// NewSchnorrServer returns the server which holds the secret for Schnorr proofs in Schnorr
+// group.
+func NewSchnorrServer(group *groups.SchnorrGroup, secret *big.Int) *Server {
+	return &Server{
+		name: "Schnorr",
+		newHolder: func() interface{} {
+			return &schnorrService{NewSchnorr(group, secret)}
+		},
+	}
+}
+
+// NewSchnorrECServer returns the server which holds the secret for Schnorr proofs in EC group.
+func NewSchnorrECServer(curve dlog.Curve, secret *big.Int) *Server {
+	return &Server{
+		name: "SchnorrEC",
+		newHolder: func() interface{} {
+			return &schnorrECService{NewSchnorrEC(curve, secret)}
+		},
+	}
+}
+
+// Serve accepts the connections on the listener (which should be accessible only to the
+// client, for example a unix socket with restricted permissions) until the listener is closed.
+func (s *Server) Serve(lis net.Listener) error {
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			return err
+		}
+		srv := rpc.NewServer()
+		if err := srv.RegisterName(s.name, s.newHolder()); err != nil {
+			conn.Close()
+			return err
+		}
+		go srv.ServeConn(conn)
+	}
+}
+
+type schnorrService struct {
+	holder *Schnorr
+}
+
+func (s *schnorrService) GetPublicKey(a *big.Int, b *big.Int) error {
+	return set(b)(s.holder.GetPublicKey(a))
+}
+
+func (s *schnorrService) GetProofRandomData(a *big.Int, x *big.Int) error {
+	return set(x)(s.holder.GetProofRandomData(a))
+}
+
+func (s *schnorrService) GetProofData(challenge *big.Int, z *big.Int) error {
+	return set(z)(s.holder.GetProofData(challenge))
+}
+
+type schnorrECService struct {
+	holder *SchnorrEC
+}
+
+func (s *schnorrECService) GetPublicKey(a *types.ECGroupElement, b *types.ECGroupElement) error {
+	return setEC(b)(s.holder.GetPublicKey(a))
+}
+
+func (s *schnorrECService) GetProofRandomData(a *types.ECGroupElement,
+	x *types.ECGroupElement) error {
+	return setEC(x)(s.holder.GetProofRandomData(a))
+}
+
+func (s *schnorrECService) GetProofData(challenge *big.Int, z *big.Int) error {
+	return set(z)(s.holder.GetProofData(challenge))
+}
+
+// set returns a function which copies the result into the reply.
+func set(reply *big.Int) func(*big.Int, error) error {
+	return func(result *big.Int, err error) error {
+		if err != nil {
+			return err
+		}
+		reply.Set(result)
+		return nil
+	}
+}
+
+func setEC(reply *types.ECGroupElement) func(*types.ECGroupElement, error) error {
+	return func(result *types.ECGroupElement, err error) error {
+		if err != nil {
+			return err
+		}
+		*reply = *result
+		return nil
+	}
+}
+
+// RemoteSchnorr is the client of the secret holder for Schnorr proofs in Schnorr group which
+// runs in another process.
+type RemoteSchnorr struct {
+	client *rpc.Client
+}
+
+// DialSchnorr connects to the secret holder listening on the unix socket.
+func DialSchnorr(path string) (*RemoteSchnorr, error) {
+	client, err := rpc.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteSchnorr{client}, nil
+}
+
+func (r *RemoteSchnorr) GetPublicKey(a *big.Int) (*big.Int, error) {
+	return r.call("Schnorr.GetPublicKey", a)
+}
+
+func (r *RemoteSchnorr) GetProofRandomData(a *big.Int) (*big.Int, error) {
+	return r.call("Schnorr.GetProofRandomData", a)
+}
+
+func (r *RemoteSchnorr) GetProofData(challenge *big.Int) (*big.Int, error) {
+	return r.call("Schnorr.GetProofData", challenge)
+}
+
+func (r *RemoteSchnorr) Close() error {
+	return r.client.Close()
+}
+
+func (r *RemoteSchnorr) call(method string, arg *big.Int) (*big.Int, error) {
+	reply := new(big.Int)
+	if err := r.client.Call(method, arg, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// RemoteSchnorrEC is the client of the secret holder for Schnorr proofs in EC group which
+// runs in another process.
+type RemoteSchnorrEC struct {
+	client *rpc.Client
+}
+
+// DialSchnorrEC connects to the secret holder listening on the unix socket.
+func DialSchnorrEC(path string) (*RemoteSchnorrEC, error) {
+	client, err := rpc.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteSchnorrEC{client}, nil
+}
+
+func (r *RemoteSchnorrEC) GetPublicKey(a *types.ECGroupElement) (*types.ECGroupElement, error) {
+	return r.callEC("SchnorrEC.GetPublicKey", a)
+}
+
+func (r *RemoteSchnorrEC) GetProofRandomData(a *types.ECGroupElement) (*types.ECGroupElement,
+	error) {
+	return r.callEC("SchnorrEC.GetProofRandomData", a)
+}
+
+func (r *RemoteSchnorrEC) GetProofData(challenge *big.Int) (*big.Int, error) {
+	z := new(big.Int)
+	if err := r.client.Call("SchnorrEC.GetProofData", challenge, z); err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+func (r *RemoteSchnorrEC) Close() error {
+	return r.client.Close()
+}
+
+func (r *RemoteSchnorrEC) callEC(method string, arg *types.ECGroupElement) (
+	*types.ECGroupElement, error) {
+	reply := new(types.ECGroupElement)
+	if err := r.client.Call(method, arg, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package secretholder separates the computations which depend on the prover's secret from
+// the rest of the client. A secret holder keeps the secret (and the randomness of the
+// proofs in progress) and answers only the requests which are needed to run the protocol:
+// the client, which handles the public protocol messages and the connection to the server,
+// never sees the secret.
+//
+// The secret holder can run in the same process (NewSchnorr, NewSchnorrEC) or in a separate
+// hardened process (or an enclave) which serves the clients over a local socket:
+//
+//	// secret holder process
+//	lis, _ := net.Listen("unix", "/run/emmy/holder.sock")
+//	secretholder.NewSchnorrServer(group, secret).Serve(lis)
+//
+//	// client process
+//	holder, _ := secretholder.DialSchnorr("/run/emmy/holder.sock")
+//	defer holder.Close()
+//	c, _ := client.NewSchnorrClientFromSecretHolder(conn, group, holder)
+//
+// Each connection to the server has its own state. Proof random data is used for a single
+// proof data only (otherwise two responses to different challenges would reveal the secret).
+package secretholder
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+	"sync"
+)
+
+// Schnorr holds the secret w for Schnorr proofs in Schnorr group.
+type Schnorr struct {
+	group  *groups.SchnorrGroup
+	secret *big.Int
+	r      *big.Int
+	sync.Mutex
+}
+
+func NewSchnorr(group *groups.SchnorrGroup, secret *big.Int) *Schnorr {
+	return &Schnorr{
+		group:  group,
+		secret: secret,
+	}
+}
+
+// GetPublicKey returns a^w.
+func (s *Schnorr) GetPublicKey(a *big.Int) (*big.Int, error) {
+	if !s.group.IsElementInGroup(a) {
+		return nil, fmt.Errorf("Base is not in the group.")
+	}
+	return s.group.Exp(a, s.secret), nil
+}
+
+// GetProofRandomData returns x = a^r where r is random.
+func (s *Schnorr) GetProofRandomData(a *big.Int) (*big.Int, error) {
+	if !s.group.IsElementInGroup(a) {
+		return nil, fmt.Errorf("Base is not in the group.")
+	}
+	s.Lock()
+	defer s.Unlock()
+	r, err := common.GetRandomInt(s.group.Q)
+	if err != nil {
+		return nil, err
+	}
+	s.r = r
+	return s.group.Exp(a, r), nil
+}
+
+// GetProofData returns z = r + challenge * w (mod q) for r from the last GetProofRandomData.
+func (s *Schnorr) GetProofData(challenge *big.Int) (*big.Int, error) {
+	s.Lock()
+	defer s.Unlock()
+	r := s.r
+	s.r = nil
+	return proofData(r, challenge, s.secret, s.group.Q)
+}
+
+// SchnorrEC holds the secret w for Schnorr proofs in EC group.
+type SchnorrEC struct {
+	dlog   *dlog.ECDLog
+	secret *big.Int
+	r      *big.Int
+	sync.Mutex
+}
+
+func NewSchnorrEC(curve dlog.Curve, secret *big.Int) *SchnorrEC {
+	return &SchnorrEC{
+		dlog:   dlog.NewECDLog(curve),
+		secret: secret,
+	}
+}
+
+// GetPublicKey returns a^w.
+func (s *SchnorrEC) GetPublicKey(a *types.ECGroupElement) (*types.ECGroupElement, error) {
+	return s.exp(a, s.secret)
+}
+
+// GetProofRandomData returns x = a^r where r is random.
+func (s *SchnorrEC) GetProofRandomData(a *types.ECGroupElement) (*types.ECGroupElement, error) {
+	s.Lock()
+	defer s.Unlock()
+	r, err := common.GetRandomInt(s.dlog.OrderOfSubgroup)
+	if err != nil {
+		return nil, err
+	}
+	s.r = r
+	return s.exp(a, r)
+}
+
+// GetProofData returns z = r + challenge * w (mod q) for r from the last GetProofRandomData.
+func (s *SchnorrEC) GetProofData(challenge *big.Int) (*big.Int, error) {
+	s.Lock()
+	defer s.Unlock()
+	r := s.r
+	s.r = nil
+	return proofData(r, challenge, s.secret, s.dlog.OrderOfSubgroup)
+}
+
+func (s *SchnorrEC) exp(a *types.ECGroupElement, exponent *big.Int) (*types.ECGroupElement,
+	error) {
+	// operations with the points which are not on the curve might panic
+	if a == nil || a.X == nil || a.Y == nil || !s.dlog.Curve.IsOnCurve(a.X, a.Y) {
+		return nil, fmt.Errorf("Base is not on the curve.")
+	}
+	x, y := s.dlog.Exponentiate(a.X, a.Y, exponent)
+	return types.NewECGroupElement(x, y), nil
+}
+
+func proofData(r, challenge, secret, order *big.Int) (*big.Int, error) {
+	if r == nil {
+		return nil, fmt.Errorf("No proof random data to respond to.")
+	}
+	if challenge == nil {
+		return nil, fmt.Errorf("Challenge is missing.")
+	}
+	z := new(big.Int).Mul(challenge, secret)
+	z.Add(z, r)
+	return z.Mod(z, order), nil
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"github.com/xlab-si/emmy/revocation"
+	"math/big"
+)
+
+// SetAbuseDesk sets the desk which collects the abuse reports of the verifiers. If desk is
+// nil (the default), reports are refused.
+func (s *Server) SetAbuseDesk(desk *revocation.AbuseDesk) {
+	s.abuseDesk = desk
+}
+
+// GetAbuseDesk returns the desk which collects the abuse reports of the verifiers.
+func (s *Server) GetAbuseDesk() *revocation.AbuseDesk {
+	return s.abuseDesk
+}
+
+// AbuseReport accepts the verifier's report of a misbehaving session.
+func (s *Server) AbuseReport(req *pb.Message, stream pb.Protocol_RunServer) error {
+	data := req.GetAbuseReport()
+	if s.abuseDesk == nil || data == nil {
+		return s.send(&pb.Message{ProtocolError: "Abuse reports are not accepted."}, stream)
+	}
+	if data.Ticket == nil {
+		return s.send(&pb.Message{ProtocolError: "Ticket expected."}, stream)
+	}
+
+	report := revocation.NewAbuseReport(pseudonymsys.NewBlacklistTicket(
+		new(big.Int).SetBytes(data.Ticket.A), new(big.Int).SetBytes(data.Ticket.B)),
+		data.TranscriptHash, data.Reason)
+	if data.Nym != nil {
+		report.Nym = pseudonymsys.NewPseudonym(new(big.Int).SetBytes(data.Nym.A),
+			new(big.Int).SetBytes(data.Nym.B))
+	}
+	report.Reporter = data.Reporter
+	report.Timestamp = data.Timestamp
+	report.R = new(big.Int).SetBytes(data.R)
+	report.S = new(big.Int).SetBytes(data.S)
+
+	revoked, err := s.abuseDesk.Submit(report)
+	if err != nil {
+		s.logger.Warningf("Abuse report of %s was refused: %v", data.Reporter, err)
+		return s.send(&pb.Message{ProtocolError: "Abuse report was refused."}, stream)
+	}
+	if revoked {
+		s.logger.Notice("Reported ticket was revoked")
+		s.deprovisionRevoked(report.Ticket)
+	}
+
+	resp := &pb.Message{
+		Content: &pb.Message_Status{&pb.Status{Success: true}},
+	}
+	return s.send(resp, stream)
+}
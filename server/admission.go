@@ -0,0 +1,206 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"golang.org/x/net/context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rejectedSessions counts the sessions which were not admitted because the server was
+// over its budget (see AdmissionController).
+var rejectedSessions = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "emmy",
+		Subsystem: "server",
+		Name:      "rejected_sessions_total",
+		Help:      "Number of sessions rejected by admission control.",
+	},
+	[]string{"schema", "reason"},
+)
+
+func init() {
+	prometheus.MustRegister(rejectedSessions)
+}
+
+// AdmissionController admits sessions while the estimated CPU cost of all running sessions
+// fits into the budget. Sessions beyond the budget wait in a FIFO queue until enough running
+// sessions finish. When the queue is full or the session waits longer than the queue timeout,
+// the session is rejected, so that the latency of the admitted sessions does not grow
+// with the load.
+//
+// Costs are relative - for example in the units of the cost of a Schnorr proof. A session
+// which costs more than the whole budget is admitted only when no other session is running.
+type AdmissionController struct {
+	budget       float64
+	queueLength  int
+	queueTimeout time.Duration
+	mutex        sync.Mutex
+	inUse        float64
+	queue        []*admissionTicket
+}
+
+type admissionTicket struct {
+	cost     float64
+	admitted chan struct{}
+}
+
+// NewAdmissionController returns a controller with the given budget, where at most
+// queueLength sessions wait for at most queueTimeout to be admitted.
+func NewAdmissionController(budget float64, queueLength int,
+	queueTimeout time.Duration) *AdmissionController {
+	return &AdmissionController{
+		budget:       budget,
+		queueLength:  queueLength,
+		queueTimeout: queueTimeout,
+	}
+}
+
+// Admit blocks until the session of the given schema and cost is admitted and returns
+// the function which needs to be called when the session finishes. It returns an error
+// when the session is rejected or the context is done before the session is admitted.
+func (c *AdmissionController) Admit(ctx context.Context, schema pb.SchemaType,
+	cost float64) (func(), error) {
+	release := func() {
+		c.release(cost)
+	}
+
+	c.mutex.Lock()
+	if len(c.queue) == 0 && c.fits(cost) {
+		c.inUse += cost
+		c.mutex.Unlock()
+		return release, nil
+	}
+	if len(c.queue) >= c.queueLength {
+		c.mutex.Unlock()
+		rejectedSessions.WithLabelValues(schema.String(), "queue_full").Inc()
+		return nil, fmt.Errorf("Server is overloaded.")
+	}
+	ticket := &admissionTicket{
+		cost:     cost,
+		admitted: make(chan struct{}),
+	}
+	c.queue = append(c.queue, ticket)
+	c.mutex.Unlock()
+
+	timer := time.NewTimer(c.queueTimeout)
+	defer timer.Stop()
+	reason := "queue_timeout"
+	select {
+	case <-ticket.admitted:
+		return release, nil
+	case <-timer.C:
+	case <-ctx.Done():
+		reason = "canceled"
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	select {
+	case <-ticket.admitted:
+		// admitted just before the ticket was removed
+		return release, nil
+	default:
+	}
+	c.remove(ticket)
+	rejectedSessions.WithLabelValues(schema.String(), reason).Inc()
+	return nil, fmt.Errorf("Server is overloaded.")
+}
+
+// fits returns whether a session with the given cost can run now.
+func (c *AdmissionController) fits(cost float64) bool {
+	return c.inUse == 0 || c.inUse+cost <= c.budget
+}
+
+// release frees the cost of a finished session and admits the waiting sessions in the order
+// of arrival (a costly session is not overtaken by cheaper ones, so it is not starved).
+func (c *AdmissionController) release(cost float64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.inUse -= cost
+	if c.inUse < 0 {
+		c.inUse = 0
+	}
+	for len(c.queue) > 0 && c.fits(c.queue[0].cost) {
+		ticket := c.queue[0]
+		c.queue = c.queue[1:]
+		c.inUse += ticket.cost
+		close(ticket.admitted)
+	}
+}
+
+func (c *AdmissionController) remove(ticket *admissionTicket) {
+	for i, t := range c.queue {
+		if t == ticket {
+			c.queue = append(c.queue[:i], c.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetAdmissionController sets the controller which admits the sessions of the server.
+// If controller is nil, all sessions are admitted.
+func (s *Server) SetAdmissionController(controller *AdmissionController) {
+	s.admission = controller
+}
+
+// SetSessionCost sets the estimated cost of the sessions of the given schema (see
+// AdmissionController).
+func (s *Server) SetSessionCost(schema pb.SchemaType, cost float64) {
+	if s.sessionCosts == nil {
+		s.sessionCosts = make(map[pb.SchemaType]float64)
+	}
+	s.sessionCosts[schema] = cost
+}
+
+// sessionCost estimates the cost of the session which starts with req. The cost of
+// the schema is scaled with the size of the curve for EC based schemas (scalar
+// multiplication takes roughly quadratic time in the size of the field, P256 is the base)
+// and with the number of values in the request for batched requests.
+func (s *Server) sessionCost(req *pb.Message, curve dlog.Curve) float64 {
+	cost, ok := s.sessionCosts[req.Schema]
+	if !ok {
+		cost = s.defaultSessionCost
+	}
+	if strings.HasSuffix(req.Schema.String(), "_EC") {
+		bits := float64(dlog.GetEllipticCurve(curve).Params().BitSize)
+		cost *= math.Pow(bits/256, 2)
+	}
+	return cost * float64(batchSize(req))
+}
+
+// batchSize returns the number of values in the request which are processed separately.
+func batchSize(req *pb.Message) int {
+	n := 0
+	if ints := req.GetRepeatedInt(); ints != nil {
+		n = len(ints.Ints)
+	} else if pairs := req.GetRepeatedPair(); pairs != nil {
+		n = len(pairs.Pairs)
+	}
+	if n < 1 {
+		return 1
+	}
+	return n
+}
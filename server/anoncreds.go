@@ -0,0 +1,277 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/rangeproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/anoncreds"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"math/big"
+)
+
+// AttributePolicy decides the attributes of the anonymous credential, given the attributes
+// requested by the client. It can change them (for example set the values which are known
+// to the server) or return an error when the request is refused.
+type AttributePolicy func(requested map[string]*big.Int) (map[string]*big.Int, error)
+
+// SetAnonCredsIssuer sets the issuer of anonymous credentials with the attributes decided by
+// policy (see client.AnonCredsClient). If issuer is nil (the default), the server does not
+// issue anonymous credentials.
+func (s *Server) SetAnonCredsIssuer(issuer *anoncreds.Issuer, policy AttributePolicy) {
+	s.anonCredsIssuer = issuer
+	s.attributePolicy = policy
+}
+
+// SetAnonCredsVerifier sets the issuer's public key of the anonymous credentials which are
+// accepted by the server and the presentation request which the clients need to fulfil
+// when showing them. If pubKey is nil (the default), the server does not accept anonymous
+// credentials.
+func (s *Server) SetAnonCredsVerifier(pubKey *anoncreds.PublicKey,
+	request *anoncreds.PresentationRequest) {
+	s.anonCredsPubKey = pubKey
+	s.anonCredsRequest = request
+}
+
+// AnonCredsIssue issues an anonymous credential on the client's master secret (which is
+// not revealed to the server) and the attributes approved by the server's policy.
+func (s *Server) AnonCredsIssue(req *pb.Message, stream pb.Protocol_RunServer) error {
+	if s.anonCredsIssuer == nil || s.attributePolicy == nil {
+		return s.send(&pb.Message{
+			ProtocolError: "Anonymous credentials are not issued.",
+		}, stream)
+	}
+	data := req.GetAnonCredsIssueRequest()
+	if data == nil || len(data.Names) != len(data.Values) {
+		return s.send(&pb.Message{
+			ProtocolError: "Anonymous credential request expected.",
+		}, stream)
+	}
+	requested := make(map[string]*big.Int, len(data.Names))
+	for i, name := range data.Names {
+		requested[name] = new(big.Int).SetBytes(data.Values[i])
+	}
+	attributes, err := s.attributePolicy(requested)
+	if err != nil {
+		return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+	}
+	issuer, err := anoncreds.NewCredentialIssuer(s.anonCredsIssuer, attributes)
+	if err != nil {
+		return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+	}
+
+	challenge, err := issuer.GetChallenge(new(big.Int).SetBytes(data.U),
+		new(big.Int).SetBytes(data.T))
+	if err != nil {
+		return err
+	}
+	resp := &pb.Message{
+		Content: &pb.Message_Bigint{&pb.BigInt{X1: challenge.Bytes()}},
+	}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err = s.receive(stream)
+	if err != nil {
+		return err
+	}
+	proofData := req.GetDoubleBigint()
+	if proofData == nil {
+		return s.send(&pb.Message{
+			ProtocolError: "Proof of knowledge of the master secret expected.",
+		}, stream)
+	}
+	v, e, sig, err := issuer.IssueCredential(new(big.Int).SetBytes(proofData.X1),
+		new(big.Int).SetBytes(proofData.X2))
+	if err != nil {
+		return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+	}
+
+	credential := &pb.AnonCredsCredential{
+		V: v.Bytes(),
+		E: e.Bytes(),
+		S: sig.Bytes(),
+	}
+	for _, name := range s.anonCredsIssuer.GetPublicKey().Attributes {
+		credential.Names = append(credential.Names, name)
+		credential.Values = append(credential.Values, attributes[name].Bytes())
+	}
+	resp = &pb.Message{
+		Content: &pb.Message_AnonCredsCredential{credential},
+	}
+	return s.send(resp, stream)
+}
+
+// AnonCredsShow sends the presentation request to the client and verifies the presentation
+// of its anonymous credential.
+func (s *Server) AnonCredsShow(req *pb.Message, stream pb.Protocol_RunServer) error {
+	if s.anonCredsPubKey == nil || s.anonCredsRequest == nil {
+		return s.send(&pb.Message{
+			ProtocolError: "Anonymous credentials are not accepted.",
+		}, stream)
+	}
+	request := &pb.AnonCredsPresentationRequest{
+		Disclosed: s.anonCredsRequest.Disclosed,
+	}
+	for _, predicate := range s.anonCredsRequest.Predicates {
+		request.PredicateAttributes = append(request.PredicateAttributes, predicate.Attribute)
+		request.PredicateTypes = append(request.PredicateTypes, predicate.Type.String())
+		request.PredicateBounds = append(request.PredicateBounds, predicate.Bound.Bytes())
+	}
+	resp := &pb.Message{
+		Content: &pb.Message_AnonCredsPresentationRequest{request},
+	}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err := s.receive(stream)
+	if err != nil {
+		return err
+	}
+	data := req.GetAnonCredsProofRandomData()
+	numOfPredicates := len(s.anonCredsRequest.Predicates)
+	if data == nil || len(data.DisclosedValues) != len(request.Disclosed) ||
+		len(data.Predicates) != numOfPredicates*predicateProofRandomDataLen {
+		return s.send(&pb.Message{
+			ProtocolError: "Presentation proof random data expected.",
+		}, stream)
+	}
+	disclosed := make(map[string]*big.Int, len(request.Disclosed))
+	for i, name := range request.Disclosed {
+		disclosed[name] = new(big.Int).SetBytes(data.DisclosedValues[i])
+	}
+	verifier, err := anoncreds.NewPresentationVerifier(s.anonCredsPubKey,
+		s.anonCredsRequest, disclosed)
+	if err != nil {
+		return err
+	}
+	proofRandomData := &anoncreds.PresentationProofRandomData{
+		V:          new(big.Int).SetBytes(data.V),
+		T:          new(big.Int).SetBytes(data.T),
+		Predicates: make([]*anoncreds.PredicateProofRandomData, numOfPredicates),
+	}
+	for j := range proofRandomData.Predicates {
+		proofRandomData.Predicates[j] = toPredicateProofRandomData(
+			data.Predicates[j*predicateProofRandomDataLen : (j+1)*predicateProofRandomDataLen])
+	}
+	challenge, err := verifier.GetChallenge(proofRandomData)
+	if err != nil {
+		return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+	}
+	resp = &pb.Message{
+		Content: &pb.Message_Bigint{&pb.BigInt{X1: challenge.Bytes()}},
+	}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err = s.receive(stream)
+	if err != nil {
+		return err
+	}
+	// responses for the hidden blocks (the master secret is block 0) are sent in the order
+	// of the blocks
+	isDisclosed := make(map[string]bool, len(request.Disclosed))
+	for _, name := range request.Disclosed {
+		isDisclosed[name] = true
+	}
+	hidden := []int{0}
+	for i, name := range s.anonCredsPubKey.Attributes {
+		if !isDisclosed[name] {
+			hidden = append(hidden, i+1)
+		}
+	}
+	proofData := req.GetAnonCredsProofData()
+	if proofData == nil || len(proofData.ZM) != len(hidden) ||
+		len(proofData.Predicates) != numOfPredicates*predicateProofDataLen {
+		return s.send(&pb.Message{
+			ProtocolError: "Presentation proof data expected.",
+		}, stream)
+	}
+	zM := make(map[int]*big.Int, len(hidden))
+	for k, i := range hidden {
+		zM[i] = new(big.Int).SetBytes(proofData.ZM[k])
+	}
+	presentationProofData := &anoncreds.PresentationProofData{
+		ZE:         new(big.Int).SetBytes(proofData.ZE),
+		ZS:         new(big.Int).SetBytes(proofData.ZS),
+		ZM:         zM,
+		Predicates: make([]*anoncreds.PredicateProofData, numOfPredicates),
+	}
+	for j := range presentationProofData.Predicates {
+		presentationProofData.Predicates[j] = toPredicateProofData(
+			proofData.Predicates[j*predicateProofDataLen : (j+1)*predicateProofDataLen])
+	}
+	valid := verifier.Verify(presentationProofData)
+
+	resp = &pb.Message{
+		Content: &pb.Message_Status{&pb.Status{Success: valid}},
+	}
+	return s.send(resp, stream)
+}
+
+// predicateProofRandomDataLen is the number of values of the proof random data of each
+// predicate: C, T, three commitments to the squares and C1, T1, T2 of each square proof.
+const predicateProofRandomDataLen = 17
+
+// predicateProofDataLen is the number of values of the proof data of each predicate: ZR and
+// Z, W1, W2 of each square proof.
+const predicateProofDataLen = 13
+
+func toPredicateProofRandomData(values [][]byte) *anoncreds.PredicateProofRandomData {
+	x := make([]*big.Int, len(values))
+	for i, v := range values {
+		x[i] = new(big.Int).SetBytes(v)
+	}
+	data := &anoncreds.PredicateProofRandomData{
+		C: x[0],
+		T: x[1],
+		NonNegative: &rangeproofs.NonNegativeProofRandomData{
+			C:       x[2:5],
+			Squares: make([]*rangeproofs.SquareProofRandomData, 4),
+		},
+	}
+	for k := range data.NonNegative.Squares {
+		data.NonNegative.Squares[k] = &rangeproofs.SquareProofRandomData{
+			C1: x[5+3*k],
+			T1: x[6+3*k],
+			T2: x[7+3*k],
+		}
+	}
+	return data
+}
+
+func toPredicateProofData(values [][]byte) *anoncreds.PredicateProofData {
+	x := make([]*big.Int, len(values))
+	for i, v := range values {
+		x[i] = new(big.Int).SetBytes(v)
+	}
+	data := &anoncreds.PredicateProofData{
+		ZR:          x[0],
+		NonNegative: make([]*rangeproofs.SquareProofData, 4),
+	}
+	for k := range data.NonNegative {
+		data.NonNegative[k] = &rangeproofs.SquareProofData{
+			Z:  x[1+3*k],
+			W1: x[2+3*k],
+			W2: x[3+3*k],
+		}
+	}
+	return data
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"github.com/xlab-si/emmy/crypto/signatures/blindschnorr"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"math/big"
+)
+
+// SetBlindSchnorrSigner sets the signer which issues blind Schnorr signatures to
+// the clients (see client.BlindSchnorrClient). If signer is nil (the default), the server
+// does not issue blind signatures.
+func (s *Server) SetBlindSchnorrSigner(signer *blindschnorr.Signer) {
+	s.blindSigner = signer
+}
+
+// BlindSchnorr issues a blind Schnorr signature of a message which is not revealed to
+// the server.
+func (s *Server) BlindSchnorr(req *pb.Message, stream pb.Protocol_RunServer) error {
+	if s.blindSigner == nil {
+		return s.send(&pb.Message{ProtocolError: "Blind Schnorr signatures are not supported."},
+			stream)
+	}
+	if req.GetEmpty() == nil {
+		return s.send(&pb.Message{ProtocolError: "Empty message expected."}, stream)
+	}
+
+	session := s.blindSigner.NewSession()
+	commitment, err := session.GetCommitment()
+	if err != nil {
+		return err
+	}
+	resp := &pb.Message{
+		Content: &pb.Message_Bigint{&pb.BigInt{X1: commitment.Bytes()}},
+	}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err = s.receive(stream)
+	if err != nil {
+		return err
+	}
+	challenge := req.GetBigint()
+	if challenge == nil {
+		return s.send(&pb.Message{ProtocolError: "Blinded challenge expected."}, stream)
+	}
+	response, err := session.GetResponse(new(big.Int).SetBytes(challenge.X1))
+	if err != nil {
+		return err
+	}
+	resp = &pb.Message{
+		Content: &pb.Message_Bigint{&pb.BigInt{X1: response.Bytes()}},
+	}
+	return s.send(resp, stream)
+}
+
+// InfoPolicy decides the public information which is embedded in a partially blind
+// signature, given the information requested by the client. It can return different
+// information (for example with the expiry date set by the server) or an error when
+// the request is refused.
+type InfoPolicy func(requested []byte) ([]byte, error)
+
+// SetPartiallyBlindSchnorrSigner sets the signer which issues partially blind Schnorr
+// signatures with the public information decided by policy (see
+// client.PartiallyBlindSchnorrClient). If signer is nil (the default), the server does not
+// issue partially blind signatures.
+func (s *Server) SetPartiallyBlindSchnorrSigner(signer *blindschnorr.Signer,
+	policy InfoPolicy) {
+	s.partialSigner = signer
+	s.infoPolicy = policy
+}
+
+// PartiallyBlindSchnorr issues a partially blind Schnorr signature of a message which is not
+// revealed to the server, with the public information approved by the server's policy.
+func (s *Server) PartiallyBlindSchnorr(req *pb.Message, stream pb.Protocol_RunServer) error {
+	if s.partialSigner == nil || s.infoPolicy == nil {
+		return s.send(&pb.Message{
+			ProtocolError: "Partially blind Schnorr signatures are not supported.",
+		}, stream)
+	}
+	data := req.GetPartiallyBlindSchnorrRequest()
+	if data == nil {
+		return s.send(&pb.Message{
+			ProtocolError: "Partially blind Schnorr request expected.",
+		}, stream)
+	}
+	info, err := s.infoPolicy(data.Info)
+	if err != nil {
+		return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+	}
+
+	session := s.partialSigner.NewPartiallyBlindSession(info)
+	a, b, err := session.GetCommitment()
+	if err != nil {
+		return err
+	}
+	resp := &pb.Message{
+		Content: &pb.Message_PartiallyBlindSchnorrCommitment{
+			&pb.PartiallyBlindSchnorrCommitment{
+				Info: info,
+				A:    a.Bytes(),
+				B:    b.Bytes(),
+			},
+		},
+	}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err = s.receive(stream)
+	if err != nil {
+		return err
+	}
+	challenge := req.GetBigint()
+	if challenge == nil {
+		return s.send(&pb.Message{ProtocolError: "Blinded challenge expected."}, stream)
+	}
+	response, err := session.GetResponse(new(big.Int).SetBytes(challenge.X1))
+	if err != nil {
+		return err
+	}
+	resp = &pb.Message{
+		Content: &pb.Message_PartiallyBlindSchnorrResponse{
+			&pb.PartiallyBlindSchnorrResponse{
+				R: response.R.Bytes(),
+				C: response.C.Bytes(),
+				S: response.S.Bytes(),
+				D: response.D.Bytes(),
+			},
+		},
+	}
+	return s.send(resp, stream)
+}
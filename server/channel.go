@@ -0,0 +1,156 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"github.com/xlab-si/emmy/attestation"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	pb "github.com/xlab-si/emmy/protobuf"
+)
+
+// encryptedStream wraps the stream of a session where the client initiated the hybrid
+// (X25519 + ML-KEM) key exchange - all the messages which are sent or received through it
+// are encrypted. This protects the transcripts regardless of TLS (which might be terminated
+// by a proxy or use a classical key exchange only).
+type encryptedStream struct {
+	pb.Protocol_RunServer
+	cipher     *encryption.ChannelCipher
+	transcript []byte // messages of the key exchange
+}
+
+func (s *encryptedStream) Send(msg *pb.Message) error {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return s.Protocol_RunServer.Send(&pb.Message{
+		Content: &pb.Message_Encrypted{
+			&pb.EncryptedMsg{
+				Ciphertext: s.cipher.Seal(data),
+			},
+		},
+	})
+}
+
+func (s *encryptedStream) Recv() (*pb.Message, error) {
+	msg, err := s.Protocol_RunServer.Recv()
+	if err != nil {
+		return nil, err
+	}
+	encrypted := msg.GetEncrypted()
+	if encrypted == nil {
+		return nil, fmt.Errorf("Received unencrypted message on encrypted stream.")
+	}
+	data, err := s.cipher.Open(encrypted.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	msg = &pb.Message{}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// SetRequireHybridKEM sets whether the server refuses the sessions where the client did
+// not initiate the hybrid key exchange (by default such sessions are allowed).
+func (s *Server) SetRequireHybridKEM(require bool) {
+	s.requireHybridKEM = require
+}
+
+// hybridKEMHandshake completes the key exchange which was initiated by the client and
+// returns the stream which encrypts all the further messages of the session.
+func (s *Server) hybridKEMHandshake(init *pb.HybridKEMInit,
+	stream pb.Protocol_RunServer) (pb.Protocol_RunServer, error) {
+	key, ct, err := encryption.HybridEncapsulate(&encryption.HybridKEMPublicKey{
+		X25519: init.X25519,
+		MLKEM:  init.MLKEM,
+	})
+	if err != nil {
+		s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+		return nil, err
+	}
+	cipher, err := encryption.NewChannelCipher(key, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.Message{
+		Content: &pb.Message_HybridKemResponse{
+			&pb.HybridKEMResponse{
+				X25519: ct.X25519,
+				MLKEM:  ct.MLKEM,
+			},
+		},
+	}
+	if err := s.send(resp, stream); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Hybrid key exchange completed, the session is encrypted")
+
+	var transcript []byte
+	for _, b := range [][]byte{init.X25519, init.MLKEM, ct.X25519, ct.MLKEM} {
+		transcript = append(transcript, b...)
+	}
+	return &encryptedStream{
+		Protocol_RunServer: stream,
+		cipher:             cipher,
+		transcript:         transcript,
+	}, nil
+}
+
+// SetAttestor sets the attestor which produces the evidence that the server runs in a TEE
+// for the clients which request it (by default attestation requests are refused).
+func (s *Server) SetAttestor(attestor attestation.Attestor) {
+	s.attestor = attestor
+}
+
+// attest responds to the client's attestation request with the evidence which is bound to
+// the client's nonce and the key exchange of the session (if the session is encrypted).
+func (s *Server) attest(req *pb.AttestationRequest, stream pb.Protocol_RunServer) error {
+	if s.attestor == nil {
+		s.send(&pb.Message{ProtocolError: "Attestation is not supported."}, stream)
+		return fmt.Errorf("Attestation requested, but no attestor is set.")
+	}
+	var transcript []byte
+	if es, ok := stream.(*encryptedStream); ok {
+		transcript = es.transcript
+	}
+	evidence, err := s.attestor.Attest(attestation.ReportData(req.Nonce, transcript))
+	if err != nil {
+		s.send(&pb.Message{ProtocolError: "Attestation failed."}, stream)
+		return err
+	}
+
+	resp := &pb.Message{
+		Content: &pb.Message_AttestationEvidence{
+			&pb.AttestationEvidence{
+				Format:   s.attestor.Format(),
+				Evidence: evidence,
+			},
+		},
+	}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+	s.logger.Info("Attestation evidence sent")
+	return nil
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"github.com/xlab-si/emmy/crypto/zkp/compiler"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"math/big"
+)
+
+// challengeVariant returns the variant of the protocol which the client requested - the
+// protobuf value is decoded once here and the handlers only deal with compiler.Variant.
+func challengeVariant(variant pb.SchemaVariant) compiler.Variant {
+	switch variant {
+	case pb.SchemaVariant_ZKP:
+		return compiler.ZKP{}
+	case pb.SchemaVariant_ZKPOK:
+		return compiler.ZKPOK{}
+	default:
+		return compiler.Sigma{}
+	}
+}
+
+// challengeSetter is a verifier of a sigma protocol which uses the challenge generated by
+// compiler.ChallengeCommitter instead of generating its own.
+type challengeSetter interface {
+	SetChallenge(challenge *big.Int)
+}
+
+// openChallengeCommitment runs the opening phase of ZKP and ZKPOK (see package compiler) for
+// any sigma protocol: req needs to contain the prover's h (PedersenFirst), the reply is
+// the commitment to the challenge. It returns the next message of the client - in sigma
+// protocol there is no opening phase and req is returned.
+func (s *Server) openChallengeCommitment(req *pb.Message, committer compiler.ChallengeCommitter,
+	stream pb.Protocol_RunServer) (*pb.Message, error) {
+	if !committer.HasOpening() {
+		return req, nil
+	}
+
+	h := new(big.Int).SetBytes(req.GetPedersenFirst().GetH())
+	commitment, err := committer.GetOpeningMsgReply(h)
+	if err != nil {
+		s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+		return nil, err
+	}
+
+	resp := &pb.Message{
+		Content: &pb.Message_Bigint{
+			&pb.BigInt{X1: commitment.Bytes()},
+		},
+	}
+	if err = s.send(resp, stream); err != nil {
+		return nil, err
+	}
+	return s.receive(stream)
+}
+
+// sendChallenge sets the challenge in the verifier and sends it to the client together
+// with the decommitment.
+func (s *Server) sendChallenge(committer compiler.ChallengeCommitter, verifier challengeSetter,
+	stream pb.Protocol_RunServer) error {
+	challenge, decommitment, err := committer.GetChallenge()
+	if err != nil {
+		return err
+	}
+	if decommitment == nil { // sigma protocol
+		decommitment = new(big.Int)
+	}
+	verifier.SetChallenge(challenge)
+
+	// pb.PedersenDecommitment is used also for sigma protocol (where there is no decommitment)
+	resp := &pb.Message{
+		Content: &pb.Message_PedersenDecommitment{
+			&pb.PedersenDecommitment{
+				X: challenge.Bytes(),
+				R: decommitment.Bytes(),
+			},
+		},
+	}
+	return s.send(resp, stream)
+}
+
+// receiveTrapdoor is called after the proof data is received - if the variant requires
+// the trapdoor (ZKPOK), it acknowledges the proof data and verifies the trapdoor which is then
+// sent by the client.
+func (s *Server) receiveTrapdoor(committer compiler.ChallengeCommitter,
+	stream pb.Protocol_RunServer) error {
+	if !committer.RequiresTrapdoor() {
+		return nil
+	}
+
+	resp := &pb.Message{Content: &pb.Message_Empty{&pb.EmptyMsg{}}}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+	req, err := s.receive(stream)
+	if err != nil {
+		return err
+	}
+	trapdoor := new(big.Int).SetBytes(req.GetTrapdoor().GetTrapdoor())
+	if !committer.VerifyTrapdoor(trapdoor) {
+		s.logger.Debug("Trapdoor of the Pedersen commitment is not valid")
+	}
+	return nil
+}
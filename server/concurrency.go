@@ -0,0 +1,260 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TenantMetadataKey is the gRPC metadata key with the tenant of the client (see
+// client.WithTenant).
+const TenantMetadataKey = "emmy-tenant"
+
+// ClientIdentity returns the identifier of the client which opened the session, sessions
+// with the same identifier share the limits of ConcurrencyLimiter.
+type ClientIdentity func(ctx context.Context, req *pb.Message) string
+
+// PeerAddress identifies the clients by their IP address.
+func PeerAddress(ctx context.Context, req *pb.Message) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// ClientID identifies the clients by the ID which they send in the messages. Note that
+// the ID is chosen by the client, thus the limits can be avoided by changing it.
+func ClientID(ctx context.Context, req *pb.Message) string {
+	return strconv.Itoa(int(req.ClientId))
+}
+
+// Tenant identifies the clients by their tenant (see TenantMetadataKey), clients which
+// do not send it are identified by their IP address. The tenant is chosen by the client,
+// thus it should be used when it is set or checked by a trusted proxy.
+func Tenant(ctx context.Context, req *pb.Message) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if tenants := md.Get(TenantMetadataKey); len(tenants) > 0 && tenants[0] != "" {
+			return "tenant:" + tenants[0]
+		}
+	}
+	return PeerAddress(ctx, req)
+}
+
+// ClientIdentities maps the names of the identities (as used in the configuration) to
+// the functions.
+var ClientIdentities = map[string]ClientIdentity{
+	"peer":      PeerAddress,
+	"client_id": ClientID,
+	"tenant":    Tenant,
+}
+
+// ConcurrencyLimiter limits the number of sessions which are open at the same time - in
+// total and per client. Sessions beyond the limits wait in the queue of their client, and
+// when a session finishes, the waiting sessions are admitted in round robin over
+// the clients (fair queuing), so that a few clients with many sessions cannot take all
+// the stream handlers from the others. When the queue of the client is full or the session
+// waits longer than the queue timeout, the session is rejected.
+type ConcurrencyLimiter struct {
+	total        int // 0 means no limit
+	perClient    int // 0 means no limit
+	queueLength  int
+	queueTimeout time.Duration
+	mutex        sync.Mutex
+	limits       map[string]int
+	inUse        int
+	running      map[string]int
+	waiting      map[string][]*concurrencyTicket
+	order        []string // clients with waiting sessions, in the order of admission
+}
+
+type concurrencyTicket struct {
+	admitted chan struct{}
+}
+
+// NewConcurrencyLimiter returns a limiter which runs at most total sessions, at most
+// perClient of them for the same client (0 means no limit), where at most queueLength
+// sessions of each client wait for at most queueTimeout to be admitted.
+func NewConcurrencyLimiter(total, perClient, queueLength int,
+	queueTimeout time.Duration) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		total:        total,
+		perClient:    perClient,
+		queueLength:  queueLength,
+		queueTimeout: queueTimeout,
+		limits:       make(map[string]int),
+		running:      make(map[string]int),
+		waiting:      make(map[string][]*concurrencyTicket),
+	}
+}
+
+// SetClientLimit overrides the number of sessions which the client can run at the same
+// time (for example for a tenant which needs more), 0 means no limit.
+func (l *ConcurrencyLimiter) SetClientLimit(client string, limit int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.limits[client] = limit
+	l.dispatch()
+}
+
+// Acquire blocks until the session of the client is admitted and returns the function which
+// needs to be called when the session finishes. It returns an error when the session is
+// rejected or the context is done before the session is admitted.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context, schema pb.SchemaType,
+	client string) (func(), error) {
+	release := func() {
+		l.release(client)
+	}
+
+	l.mutex.Lock()
+	if len(l.waiting[client]) == 0 && l.fits(client) {
+		l.admit(client)
+		l.mutex.Unlock()
+		return release, nil
+	}
+	if len(l.waiting[client]) >= l.queueLength {
+		l.mutex.Unlock()
+		rejectedSessions.WithLabelValues(schema.String(), "client_queue_full").Inc()
+		return nil, fmt.Errorf("Too many concurrent sessions.")
+	}
+	ticket := &concurrencyTicket{
+		admitted: make(chan struct{}),
+	}
+	if len(l.waiting[client]) == 0 {
+		l.order = append(l.order, client)
+	}
+	l.waiting[client] = append(l.waiting[client], ticket)
+	l.mutex.Unlock()
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+	reason := "client_queue_timeout"
+	select {
+	case <-ticket.admitted:
+		return release, nil
+	case <-timer.C:
+	case <-ctx.Done():
+		reason = "canceled"
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	select {
+	case <-ticket.admitted:
+		// admitted just before the ticket was removed
+		return release, nil
+	default:
+	}
+	l.remove(client, ticket)
+	rejectedSessions.WithLabelValues(schema.String(), reason).Inc()
+	return nil, fmt.Errorf("Too many concurrent sessions.")
+}
+
+// fits returns whether a session of the client can run now.
+func (l *ConcurrencyLimiter) fits(client string) bool {
+	if l.total > 0 && l.inUse >= l.total {
+		return false
+	}
+	limit, ok := l.limits[client]
+	if !ok {
+		limit = l.perClient
+	}
+	return limit == 0 || l.running[client] < limit
+}
+
+func (l *ConcurrencyLimiter) admit(client string) {
+	l.inUse++
+	l.running[client]++
+}
+
+func (l *ConcurrencyLimiter) release(client string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.inUse--
+	l.running[client]--
+	if l.running[client] <= 0 {
+		delete(l.running, client)
+	}
+	l.dispatch()
+}
+
+// dispatch admits the waiting sessions while there is room for them. Each client which
+// gets a session admitted is moved to the end of the order, thus the clients take turns.
+func (l *ConcurrencyLimiter) dispatch() {
+	for {
+		i := 0
+		for i < len(l.order) && !l.fits(l.order[i]) {
+			i++
+		}
+		if i == len(l.order) {
+			return
+		}
+		client := l.order[i]
+		ticket := l.waiting[client][0]
+		l.waiting[client] = l.waiting[client][1:]
+		l.order = append(l.order[:i], l.order[i+1:]...)
+		if len(l.waiting[client]) > 0 {
+			l.order = append(l.order, client)
+		} else {
+			delete(l.waiting, client)
+		}
+		l.admit(client)
+		close(ticket.admitted)
+	}
+}
+
+func (l *ConcurrencyLimiter) remove(client string, ticket *concurrencyTicket) {
+	queue := l.waiting[client]
+	for i, t := range queue {
+		if t == ticket {
+			queue = append(queue[:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(queue) > 0 {
+		l.waiting[client] = queue
+		return
+	}
+	delete(l.waiting, client)
+	for i, c := range l.order {
+		if c == client {
+			l.order = append(l.order[:i], l.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetConcurrencyLimiter sets the limiter of the sessions which are open at the same time,
+// where the clients are identified by identity (see ClientIdentities). If limiter is nil,
+// the number of sessions is not limited.
+func (s *Server) SetConcurrencyLimiter(limiter *ConcurrencyLimiter, identity ClientIdentity) {
+	s.concurrency = limiter
+	s.clientIdentity = identity
+}
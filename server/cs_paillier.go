@@ -61,7 +61,10 @@ func (s *Server) CSPaillier(req *pb.Message, secKeyPath string, stream pb.Protoc
 	delta1 := new(big.Int).SetBytes(pRandData.Delta1)
 	l1 := new(big.Int).SetBytes(pRandData.L1)
 
-	c := decryptor.GetChallenge()
+	c, err := decryptor.GetChallenge()
+	if err != nil {
+		return err
+	}
 	decryptor.SetProofRandomData(u1, e1, v1, delta1, l1, c)
 
 	challenge := pb.BigInt{
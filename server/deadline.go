@@ -0,0 +1,124 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"sync"
+	"time"
+)
+
+// timedOutSessions counts the sessions which were aborted because the client did not send
+// the next message in time.
+var timedOutSessions = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "emmy",
+		Subsystem: "server",
+		Name:      "timed_out_sessions_total",
+		Help:      "Number of sessions aborted because a round deadline was exceeded.",
+	},
+	[]string{"schema"},
+)
+
+func init() {
+	prometheus.MustRegister(timedOutSessions)
+}
+
+// SetDefaultRoundTimeout sets the deadline for each message of the client (after the first
+// one) in the sessions of schemas without their own deadline (see SetRoundTimeout). When
+// the deadline is exceeded, for example because the client stalls after the challenge is sent,
+// the session is aborted. Zero timeout means no deadline.
+func (s *Server) SetDefaultRoundTimeout(timeout time.Duration) {
+	s.defaultRoundTimeout = timeout
+}
+
+// SetRoundTimeout sets the deadline for each message of the client in the sessions of
+// the given schema. Zero timeout means no deadline.
+func (s *Server) SetRoundTimeout(schema pb.SchemaType, timeout time.Duration) {
+	if s.roundTimeouts == nil {
+		s.roundTimeouts = make(map[pb.SchemaType]time.Duration)
+	}
+	s.roundTimeouts[schema] = timeout
+}
+
+// roundTimeout returns the deadline for each message of the client for the given schema.
+func (s *Server) roundTimeout(schema pb.SchemaType) time.Duration {
+	if timeout, ok := s.roundTimeouts[schema]; ok {
+		return timeout
+	}
+	return s.defaultRoundTimeout
+}
+
+// deadlineStream wraps the stream of a session - Recv fails when the client does not send
+// the message within the timeout. The session is then aborted: the handler returns with
+// an error, which closes the stream and releases the session's state.
+type deadlineStream struct {
+	pb.Protocol_RunServer
+	schema   pb.SchemaType
+	timeout  time.Duration
+	mutex    sync.Mutex
+	timedOut bool
+}
+
+func newDeadlineStream(stream pb.Protocol_RunServer, schema pb.SchemaType,
+	timeout time.Duration) *deadlineStream {
+	return &deadlineStream{
+		Protocol_RunServer: stream,
+		schema:             schema,
+		timeout:            timeout,
+	}
+}
+
+type recvResult struct {
+	msg *pb.Message
+	err error
+}
+
+func (s *deadlineStream) Recv() (*pb.Message, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.timedOut {
+		return nil, s.timeoutErr()
+	}
+
+	// Recv cannot be interrupted - when the deadline is exceeded, the goroutine finishes
+	// once the stream is closed (after the handler returns)
+	c := make(chan recvResult, 1)
+	go func() {
+		msg, err := s.Protocol_RunServer.Recv()
+		c <- recvResult{msg, err}
+	}()
+
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+	select {
+	case res := <-c:
+		return res.msg, res.err
+	case <-timer.C:
+		s.timedOut = true
+		timedOutSessions.WithLabelValues(s.schema.String()).Inc()
+		s.Protocol_RunServer.Send(&pb.Message{ProtocolError: "Round deadline exceeded."})
+		return nil, s.timeoutErr()
+	}
+}
+
+func (s *deadlineStream) timeoutErr() error {
+	return fmt.Errorf("Client did not respond within %v.", s.timeout)
+}
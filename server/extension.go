@@ -0,0 +1,215 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package server
+
+import (
+	"fmt"
+	"github.com/golang/protobuf/ptypes/any"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"plugin"
+	"strings"
+	"sync"
+)
+
+// Extension is a scheme which is not part of emmy, but is shipped as a separate module
+// and registered with the server (see RegisterExtension and LoadExtensionPlugin).
+// Clients request it with SchemaType_EXTENSION and the name of the extension, while
+// the messages of the scheme are carried as protobuf Any payloads, so the extension
+// can define its own message types without changing emmy's protobuf definitions.
+type Extension interface {
+	// Name returns the name under which clients request the extension.
+	Name() string
+	// Run executes the server side of the scheme. It receives the payload of the first
+	// client's message, a stream for further communication with the client and the storage
+	// where the extension can keep its state between the runs.
+	Run(payload *any.Any, stream ExtensionStream, storage ExtensionStorage) error
+}
+
+// ExtensionStream is used by the extension to exchange payloads with the client.
+type ExtensionStream interface {
+	Send(payload *any.Any) error
+	Receive() (*any.Any, error)
+}
+
+// ExtensionStorage is a key-value storage for the state of extensions. Keys are namespaced
+// by the name of the extension, so extensions cannot access each other's state.
+type ExtensionStorage interface {
+	Get(key string) ([]byte, bool)
+	Put(key string, value []byte) error
+}
+
+var extensions = make(map[string]Extension)
+var extensionsMutex sync.RWMutex
+
+// RegisterExtension makes the extension available to all emmy servers. It is meant
+// to be called from the init function of the package which implements the extension.
+// The name must not contain "/", which separates the name from the keys in the storage -
+// otherwise the state of extension "a/b" would overlap with the keys "b/..." of
+// extension "a".
+func RegisterExtension(ext Extension) error {
+	extensionsMutex.Lock()
+	defer extensionsMutex.Unlock()
+
+	name := ext.Name()
+	if name == "" {
+		return fmt.Errorf("Extension name must not be empty")
+	}
+	if strings.Contains(name, "/") {
+		return fmt.Errorf("Extension name %s must not contain /", name)
+	}
+	if _, exists := extensions[name]; exists {
+		return fmt.Errorf("Extension %s is already registered", name)
+	}
+	extensions[name] = ext
+	return nil
+}
+
+// GetExtension returns the extension registered under the given name.
+func GetExtension(name string) (Extension, bool) {
+	extensionsMutex.RLock()
+	defer extensionsMutex.RUnlock()
+
+	ext, ok := extensions[name]
+	return ext, ok
+}
+
+// LoadExtensionPlugin opens a Go plugin (built with -buildmode=plugin) at the given path
+// and registers the extension it exports as a variable or function named Extension.
+func LoadExtensionPlugin(path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("Could not open plugin %s: %v", path, err)
+	}
+	sym, err := p.Lookup("Extension")
+	if err != nil {
+		return fmt.Errorf("Plugin %s does not export Extension: %v", path, err)
+	}
+
+	switch ext := sym.(type) {
+	case *Extension:
+		return RegisterExtension(*ext)
+	case func() Extension:
+		return RegisterExtension(ext())
+	default:
+		return fmt.Errorf("Plugin %s exports Extension of unsupported type %T", path, sym)
+	}
+}
+
+// memoryStorage is the default ExtensionStorage which keeps the state in memory.
+type memoryStorage struct {
+	data map[string][]byte
+	sync.RWMutex
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{
+		data: make(map[string][]byte),
+	}
+}
+
+func (s *memoryStorage) Get(key string) ([]byte, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	value, ok := s.data[key]
+	return value, ok
+}
+
+func (s *memoryStorage) Put(key string, value []byte) error {
+	s.Lock()
+	defer s.Unlock()
+	s.data[key] = value
+	return nil
+}
+
+// extensionStorage restricts the extension to its own namespace of the storage.
+type extensionStorage struct {
+	prefix  string
+	storage ExtensionStorage
+}
+
+func (s *extensionStorage) Get(key string) ([]byte, bool) {
+	return s.storage.Get(s.prefix + key)
+}
+
+func (s *extensionStorage) Put(key string, value []byte) error {
+	return s.storage.Put(s.prefix+key, value)
+}
+
+// extensionStream wraps payloads into ExtensionMsg messages of the gRPC stream.
+type extensionStream struct {
+	server *Server
+	scheme string
+	stream pb.Protocol_RunServer
+}
+
+func (s *extensionStream) Send(payload *any.Any) error {
+	msg := &pb.Message{
+		Content: &pb.Message_Extension{
+			&pb.ExtensionMsg{
+				Scheme:  s.scheme,
+				Payload: payload,
+			},
+		},
+	}
+	return s.server.send(msg, s.stream)
+}
+
+func (s *extensionStream) Receive() (*any.Any, error) {
+	req, err := s.server.receive(s.stream)
+	if err != nil {
+		return nil, err
+	}
+	data := req.GetExtension()
+	if data == nil {
+		return nil, fmt.Errorf("Expected extension message, got %T", req.Content)
+	}
+	return data.Payload, nil
+}
+
+// SetExtensionStorage replaces the default in-memory storage of extensions, for example
+// with a storage that persists the state to a database.
+func (s *Server) SetExtensionStorage(storage ExtensionStorage) {
+	s.extensionStorage = storage
+}
+
+// Extension runs the extension which was requested by the client.
+func (s *Server) Extension(req *pb.Message, stream pb.Protocol_RunServer) error {
+	data := req.GetExtension()
+	if data == nil {
+		return fmt.Errorf("Expected extension message, got %T", req.Content)
+	}
+
+	ext, ok := GetExtension(data.Scheme)
+	if !ok {
+		resp := &pb.Message{
+			ProtocolError: fmt.Sprintf("Extension %s is not registered", data.Scheme),
+		}
+		return s.send(resp, stream)
+	}
+
+	s.logger.Noticef("Running extension %s", data.Scheme)
+	extStream := &extensionStream{
+		server: s,
+		scheme: data.Scheme,
+		stream: stream,
+	}
+	extStorage := &extensionStorage{
+		prefix:  data.Scheme + "/",
+		storage: s.extensionStorage,
+	}
+	return ext.Run(data.Payload, extStream, extStorage)
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"math/big"
+)
+
+// gpsMinModulusBitLen is the minimal bit length of the modulus of GPS parameters.
+const gpsMinModulusBitLen = 1024
+
+// GPS verifies the client's identification with the GPS scheme. The client sends its
+// parameters (for example the modulus of its RSA key) together with the public key.
+func (s *Server) GPS(req *pb.Message, stream pb.Protocol_RunServer) error {
+	data := req.GetGpsProofRandomData()
+	if data == nil {
+		return s.send(&pb.Message{ProtocolError: "GPS proof random data expected."}, stream)
+	}
+	params := &dlogproofs.GPSParams{
+		N: new(big.Int).SetBytes(data.N),
+		G: new(big.Int).SetBytes(data.G),
+	}
+	if params.N.BitLen() < gpsMinModulusBitLen || params.N.Bit(0) == 0 ||
+		params.G.Cmp(big.NewInt(1)) <= 0 || params.G.Cmp(params.N) >= 0 {
+		return s.send(&pb.Message{ProtocolError: "Invalid GPS parameters."}, stream)
+	}
+
+	verifier := dlogproofs.NewGPSVerifier(params, new(big.Int).SetBytes(data.V))
+	if err := verifier.SetSecurity(s.proofSecurity(pb.SchemaType_GPS)); err != nil {
+		return err
+	}
+	challenge, err := verifier.GetChallenge(new(big.Int).SetBytes(data.X))
+	if err != nil {
+		return err
+	}
+	resp := &pb.Message{
+		Content: &pb.Message_Bigint{&pb.BigInt{X1: challenge.Bytes()}},
+	}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err = s.receive(stream)
+	if err != nil {
+		return err
+	}
+	proofData := req.GetBigint()
+	if proofData == nil {
+		return s.send(&pb.Message{ProtocolError: "GPS proof data expected."}, stream)
+	}
+	valid := verifier.Verify(new(big.Int).SetBytes(proofData.X1))
+
+	resp = &pb.Message{
+		Content: &pb.Message_Status{&pb.Status{Success: valid}},
+	}
+	return s.send(resp, stream)
+}
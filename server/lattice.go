@@ -0,0 +1,103 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/lattice"
+	pb "github.com/xlab-si/emmy/protobuf"
+)
+
+// latticeMaxPolys limits the size of the matrix of the statements of lattice proofs.
+const latticeMaxPolys = 64
+
+// LatticeShortVector verifies the client's non-interactive proof of knowledge of a short
+// solution of a lattice relation (see lattice.ShortVectorStatement).
+func (s *Server) LatticeShortVector(req *pb.Message, stream pb.Protocol_RunServer) error {
+	data := req.GetLatticeShortVectorProof()
+	if data == nil {
+		return s.send(&pb.Message{ProtocolError: "Lattice proof expected."}, stream)
+	}
+	statement, proof, err := toShortVectorProof(data)
+	if err != nil {
+		s.logger.Debugf("Invalid lattice proof: %v", err)
+		return s.send(&pb.Message{ProtocolError: "Invalid lattice proof."}, stream)
+	}
+
+	resp := &pb.Message{
+		Content: &pb.Message_Status{&pb.Status{Success: proof.Verify(statement)}},
+	}
+	return s.send(resp, stream)
+}
+
+func toShortVectorProof(data *pb.LatticeShortVectorProof) (*lattice.ShortVectorStatement,
+	*lattice.ShortVectorProof, error) {
+	width := int(data.Width)
+	if width <= 0 || len(data.A) == 0 || len(data.A)%width != 0 ||
+		len(data.A) > latticeMaxPolys || len(data.T) != len(data.A)/width ||
+		len(data.Z) != width {
+		return nil, nil, fmt.Errorf("dimensions do not match")
+	}
+	// masking bound Bound * 36 * N * width needs to be far below Q
+	if data.Bound <= 0 || data.Bound > 1<<10 {
+		return nil, nil, fmt.Errorf("bound is out of range")
+	}
+
+	polys := func(encoded [][]byte) (lattice.PolyVec, error) {
+		v := make(lattice.PolyVec, len(encoded))
+		for i, b := range encoded {
+			p, err := lattice.NewPolyFromBytes(b)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = p
+		}
+		return v, nil
+	}
+	entries, err := polys(data.A)
+	if err != nil {
+		return nil, nil, err
+	}
+	a := make([]lattice.PolyVec, len(entries)/width)
+	for i := range a {
+		a[i] = entries[i*width : (i+1)*width]
+	}
+	t, err := polys(data.T)
+	if err != nil {
+		return nil, nil, err
+	}
+	z, err := polys(data.Z)
+	if err != nil {
+		return nil, nil, err
+	}
+	challenge, err := lattice.NewPolyFromBytes(data.Challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	statement := &lattice.ShortVectorStatement{
+		A:     a,
+		T:     t,
+		Bound: data.Bound,
+	}
+	proof := &lattice.ShortVectorProof{
+		Challenge: challenge,
+		Z:         z,
+	}
+	return statement, proof, nil
+}
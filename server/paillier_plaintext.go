@@ -0,0 +1,149 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/encproofs"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"math/big"
+)
+
+// SetPaillierEscrowKey restricts the proofs of Paillier plaintext knowledge to the ciphertexts
+// under the given key (for example the key of the escrow authority). If key is nil, proofs
+// are accepted for any key which is proposed by the client.
+func (s *Server) SetPaillierEscrowKey(key *encryption.PaillierPubKey) {
+	s.escrowKey = key
+}
+
+// plaintextVerifier is implemented by the verifiers of both variants of the proof.
+type plaintextVerifier interface {
+	GetChallenge() (*big.Int, error)
+	verify(data *pb.PaillierPlaintextProofData) bool
+}
+
+type paillierPlaintextVerifier struct {
+	*encproofs.PaillierPlaintextVerifier
+}
+
+func (v paillierPlaintextVerifier) verify(data *pb.PaillierPlaintextProofData) bool {
+	return v.Verify(new(big.Int).SetBytes(data.Z), new(big.Int).SetBytes(data.W))
+}
+
+type paillierCommittedPlaintextVerifier struct {
+	*encproofs.PaillierCommittedPlaintextVerifier
+}
+
+func (v paillierCommittedPlaintextVerifier) verify(data *pb.PaillierPlaintextProofData) bool {
+	return v.Verify(new(big.Int).SetBytes(data.Z), new(big.Int).SetBytes(data.W),
+		new(big.Int).SetBytes(data.V))
+}
+
+// PaillierPlaintext verifies that the client knows the plaintext of Paillier ciphertext.
+// If the client sends a Pedersen commitment as well, it verifies that the plaintext
+// is the committed value.
+func (s *Server) PaillierPlaintext(group *groups.SchnorrGroup, stream pb.Protocol_RunServer) error {
+	params, err := s.pedersenParams.get(group)
+	if err != nil {
+		return err
+	}
+	receiver := commitments.NewPedersenReceiverFromParams(params)
+	h := receiver.GetH()
+
+	resp := &pb.Message{Content: &pb.Message_PedersenFirst{&pb.PedersenFirst{H: h.Bytes()}}}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err := s.receive(stream)
+	if err != nil {
+		return err
+	}
+	data := req.GetPaillierPlaintextProofRandomData()
+	if data == nil {
+		return s.send(&pb.Message{ProtocolError: "Proof random data expected."}, stream)
+	}
+	verifier, err := s.newPlaintextVerifier(group, h, data)
+	if err != nil {
+		s.logger.Debug(err)
+		return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+	}
+
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return err
+	}
+	resp = &pb.Message{Content: &pb.Message_Bigint{&pb.BigInt{X1: challenge.Bytes()}}}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err = s.receive(stream)
+	if err != nil {
+		return err
+	}
+	proofData := req.GetPaillierPlaintextProofData()
+	valid := proofData != nil && verifier.verify(proofData)
+
+	s.logger.Noticef("Paillier plaintext proof (committed: %v) success: **%v**",
+		len(data.Commitment) > 0, valid)
+
+	resp = &pb.Message{Content: &pb.Message_Status{&pb.Status{Success: valid}}}
+	return s.send(resp, stream)
+}
+
+func (s *Server) newPlaintextVerifier(group *groups.SchnorrGroup, h *big.Int,
+	data *pb.PaillierPlaintextProofRandomData) (plaintextVerifier, error) {
+	pubKey := encryption.NewPaillierPubKey(new(big.Int).SetBytes(data.N),
+		new(big.Int).SetBytes(data.G))
+	if s.escrowKey != nil && (pubKey.GetN().Cmp(s.escrowKey.GetN()) != 0 ||
+		pubKey.GetG().Cmp(s.escrowKey.GetG()) != 0) {
+		return nil, fmt.Errorf("Paillier key is not the escrow key.")
+	}
+	c := new(big.Int).SetBytes(data.C)
+	a := new(big.Int).SetBytes(data.A)
+
+	if len(data.Commitment) == 0 {
+		verifier, err := encproofs.NewPaillierPlaintextVerifier(pubKey, c)
+		if err != nil {
+			return nil, err
+		}
+		err = verifier.SetSecurity(s.proofSecurity(pb.SchemaType_PAILLIER_PLAINTEXT))
+		if err != nil {
+			return nil, err
+		}
+		if err := verifier.SetProofRandomData(a); err != nil {
+			return nil, err
+		}
+		return paillierPlaintextVerifier{verifier}, nil
+	}
+
+	verifier, err := encproofs.NewPaillierCommittedPlaintextVerifier(pubKey, group, h, c,
+		new(big.Int).SetBytes(data.Commitment))
+	if err != nil {
+		return nil, err
+	}
+	commitmentA := new(big.Int).SetBytes(data.CommitmentA)
+	if err := verifier.SetProofRandomData(a, commitmentA); err != nil {
+		return nil, err
+	}
+	return paillierCommittedPlaintextVerifier{verifier}, nil
+}
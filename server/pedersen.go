@@ -22,10 +22,51 @@ import (
 	"github.com/xlab-si/emmy/crypto/groups"
 	pb "github.com/xlab-si/emmy/protobuf"
 	"math/big"
+	"sync"
+	"time"
 )
 
+// pedersenParamsCache keeps Pedersen receiver parameters for each group, so that they
+// are not generated for each session. The parameters are regenerated after the rotation
+// period (if the period is 0, they are generated for each session).
+type pedersenParamsCache struct {
+	rotation time.Duration
+	params   map[string]*commitments.PedersenReceiverParams
+	created  map[string]time.Time
+	sync.Mutex
+}
+
+func newPedersenParamsCache(rotation time.Duration) *pedersenParamsCache {
+	return &pedersenParamsCache{
+		rotation: rotation,
+		params:   make(map[string]*commitments.PedersenReceiverParams),
+		created:  make(map[string]time.Time),
+	}
+}
+
+func (c *pedersenParamsCache) get(group *groups.SchnorrGroup) (*commitments.PedersenReceiverParams, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	key := group.P.String() + "," + group.G.String()
+	params, ok := c.params[key]
+	if !ok || time.Since(c.created[key]) >= c.rotation {
+		var err error
+		if params, err = commitments.NewPedersenReceiverParams(group); err != nil {
+			return nil, err
+		}
+		c.params[key] = params
+		c.created[key] = time.Now()
+	}
+	return params, nil
+}
+
 func (s *Server) Pedersen(group *groups.SchnorrGroup, stream pb.Protocol_RunServer) error {
-	pedersenReceiver := commitments.NewPedersenReceiver(group)
+	params, err := s.pedersenParams.get(group)
+	if err != nil {
+		return err
+	}
+	pedersenReceiver := commitments.NewPedersenReceiverFromParams(params)
 
 	h := pedersenReceiver.GetH()
 
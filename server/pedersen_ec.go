@@ -26,7 +26,10 @@ import (
 )
 
 func (s *Server) PedersenEC(curveType dlog.Curve, stream pb.Protocol_RunServer) error {
-	pedersenECReceiver := commitments.NewPedersenECReceiver(curveType)
+	pedersenECReceiver, err := commitments.NewPedersenECReceiver(curveType)
+	if err != nil {
+		return err
+	}
 
 	h := pedersenECReceiver.GetH()
 	ecge := pb.ECGroupElement{
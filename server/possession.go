@@ -0,0 +1,146 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+// SetPossessionVerifier sets the verifier which serves the proof-of-possession API for
+// external services (see PossessionHandler). If verifier is nil (the default), the API
+// is disabled.
+func (s *Server) SetPossessionVerifier(verifier *pseudonymsys.PossessionVerifier) {
+	s.possession = verifier
+}
+
+// possessionChallengeRequest and the other types below are the JSON messages of the
+// proof-of-possession API. Big integers and nonces are encoded as big-endian bytes
+// (base64 in JSON).
+type possessionChallengeRequest struct {
+	NymA []byte `json:"nym_a"`
+	NymB []byte `json:"nym_b"`
+}
+
+type possessionChallengeResponse struct {
+	ID      string    `json:"id"`
+	Nonce   []byte    `json:"nonce"`
+	Expires time.Time `json:"expires"`
+}
+
+type possessionVerifyRequest struct {
+	ID string `json:"id"`
+	C  []byte `json:"c"`
+	Z  []byte `json:"z"`
+}
+
+type possessionVerifyResponse struct {
+	Valid bool   `json:"valid"`
+	NymA  []byte `json:"nym_a,omitempty"`
+	NymB  []byte `json:"nym_b,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// PossessionHandler returns the handler of the proof-of-possession API, which external
+// services use to check that the user owns a registered nym (for example on a login page).
+// It serves two calls, both with JSON bodies:
+//
+//	POST /challenge {"nym_a", "nym_b"} returns {"id", "nonce", "expires"}
+//	POST /verify {"id", "c", "z"} returns {"valid", "nym_a", "nym_b"}
+//
+// where the user computes c and z with pseudonymsys.ProvePossession. Start serves it under
+// /possession/ next to /metrics - applications with their own HTTP server mount it
+// themselves (with http.StripPrefix).
+func (s *Server) PossessionHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/challenge", s.serveCreateChallenge)
+	mux.HandleFunc("/verify", s.serveVerifyResponse)
+	return mux
+}
+
+func (s *Server) serveCreateChallenge(w http.ResponseWriter, r *http.Request) {
+	if !s.checkPossessionRequest(w, r) {
+		return
+	}
+	var req possessionChallengeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request.", http.StatusBadRequest)
+		return
+	}
+
+	nym := pseudonymsys.NewPseudonym(new(big.Int).SetBytes(req.NymA),
+		new(big.Int).SetBytes(req.NymB))
+	challenge, err := s.possession.CreateChallenge(nym)
+	if err != nil {
+		s.logger.Debugf("Proof-of-possession challenge refused: %v", err)
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, &possessionChallengeResponse{
+		ID:      challenge.ID,
+		Nonce:   challenge.Nonce,
+		Expires: challenge.Expires,
+	})
+}
+
+func (s *Server) serveVerifyResponse(w http.ResponseWriter, r *http.Request) {
+	if !s.checkPossessionRequest(w, r) {
+		return
+	}
+	var req possessionVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request.", http.StatusBadRequest)
+		return
+	}
+
+	nym, err := s.possession.VerifyResponse(req.ID, &pseudonymsys.PossessionResponse{
+		C: new(big.Int).SetBytes(req.C),
+		Z: new(big.Int).SetBytes(req.Z),
+	})
+	if err != nil {
+		writeJSON(w, &possessionVerifyResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, &possessionVerifyResponse{
+		Valid: true,
+		NymA:  nym.A.Bytes(),
+		NymB:  nym.B.Bytes(),
+	})
+}
+
+// checkPossessionRequest writes an error and returns false if the request cannot be served.
+func (s *Server) checkPossessionRequest(w http.ResponseWriter, r *http.Request) bool {
+	if s.possession == nil {
+		http.Error(w, "Proof of possession is disabled.", http.StatusNotFound)
+		return false
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is supported.", http.StatusMethodNotAllowed)
+		return false
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<16)
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/provisioning"
+)
+
+// SetProvisioner sets the provisioner which creates an opaque directory account for each
+// generated nym, and removes the accounts of the nyms reported with a ticket once the ticket
+// is revoked (see SetAbuseDesk). If provisioner is nil (the default), no accounts are
+// provisioned. Provisioning failures are logged, but they do not affect the protocols.
+func (s *Server) SetProvisioner(provisioner provisioning.Provisioner) {
+	s.provisioner = provisioner
+}
+
+func (s *Server) provision(id string) {
+	if s.provisioner == nil {
+		return
+	}
+	if err := s.provisioner.Provision(id); err != nil {
+		s.logger.Warningf("Account %s was not provisioned: %v", id, err)
+	}
+}
+
+// deprovisionRevoked deprovisions the accounts of the nyms from the reports which led to
+// the revocation of the ticket.
+func (s *Server) deprovisionRevoked(ticket *pseudonymsys.BlacklistTicket) {
+	if s.provisioner == nil {
+		return
+	}
+	escalations := s.abuseDesk.Escalations()
+	for i := len(escalations) - 1; i >= 0; i-- {
+		e := escalations[i]
+		if e.Ticket.H.Cmp(ticket.H) != 0 || e.Ticket.Tag.Cmp(ticket.Tag) != 0 {
+			continue
+		}
+		deprovisioned := make(map[string]bool)
+		for _, report := range e.Reports {
+			if report.Nym == nil {
+				continue
+			}
+			id := provisioning.NymAccountID(report.Nym)
+			if deprovisioned[id] {
+				continue
+			}
+			deprovisioned[id] = true
+			if err := s.provisioner.Deprovision(id); err != nil {
+				s.logger.Warningf("Account %s was not deprovisioned: %v", id, err)
+			}
+		}
+		return
+	}
+}
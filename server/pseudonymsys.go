@@ -20,15 +20,16 @@ package server
 import (
 	"github.com/xlab-si/emmy/config"
 	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/rangeproofs"
 	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
 	pb "github.com/xlab-si/emmy/protobuf"
+	"github.com/xlab-si/emmy/provisioning"
 	"math/big"
 )
 
 func (s *Server) PseudonymsysGenerateNym(req *pb.Message, stream pb.Protocol_RunServer) error {
 	group := config.LoadGroup("pseudonymsys")
-	caPubKeyX, caPubKeyY := config.LoadPseudonymsysCAPubKey()
-	org := pseudonymsys.NewOrgNymGen(group, caPubKeyX, caPubKeyY)
+	org := pseudonymsys.NewOrgNymGenWithCAPubKeys(group, config.LoadPseudonymsysCAPubKeys()...)
 
 	proofRandData := req.GetPseudonymsysNymGenProofRandomData()
 	x1 := new(big.Int).SetBytes(proofRandData.X1)
@@ -39,8 +40,10 @@ func (s *Server) PseudonymsysGenerateNym(req *pb.Message, stream pb.Protocol_Run
 	blindedB := new(big.Int).SetBytes(proofRandData.B2)
 	signatureR := new(big.Int).SetBytes(proofRandData.R)
 	signatureS := new(big.Int).SetBytes(proofRandData.S)
+	caCertificate := pseudonymsys.NewCACertificate(blindedA, blindedB, signatureR, signatureS,
+		pseudonymsys.SignatureAlgorithm(proofRandData.Algorithm))
 
-	challenge, err := org.GetChallenge(nymA, blindedA, nymB, blindedB, x1, x2, signatureR, signatureS)
+	challenge, err := org.GetChallenge(nymA, nymB, x1, x2, caCertificate)
 	var resp *pb.Message
 	if err != nil {
 		resp = &pb.Message{
@@ -71,6 +74,14 @@ func (s *Server) PseudonymsysGenerateNym(req *pb.Message, stream pb.Protocol_Run
 	proofData := req.GetSchnorrProofData() // SchnorrProofData is used in DLog equality proof as well
 	z := new(big.Int).SetBytes(proofData.Z)
 	valid := org.Verify(z)
+	if valid && s.nymRegistry != nil {
+		if err := s.nymRegistry.Register(pseudonymsys.NewPseudonym(nymA, nymB)); err != nil {
+			s.logger.Warningf("Nym was not registered: %v", err)
+		}
+	}
+	if valid {
+		s.provision(provisioning.NymAccountID(pseudonymsys.NewPseudonym(nymA, nymB)))
+	}
 
 	resp = &pb.Message{
 		Content: &pb.Message_Status{&pb.Status{Success: valid}},
@@ -86,13 +97,22 @@ func (s *Server) PseudonymsysGenerateNym(req *pb.Message, stream pb.Protocol_Run
 func (s *Server) PseudonymsysIssueCredential(req *pb.Message, stream pb.Protocol_RunServer) error {
 	group := config.LoadGroup("pseudonymsys")
 	s1, s2 := config.LoadPseudonymsysOrgSecrets("org1", "dlog")
-	org := pseudonymsys.NewOrgCredentialIssuer(group, s1, s2)
+	org, err := pseudonymsys.NewOrgCredentialIssuer(group, s1, s2)
+	if err != nil {
+		return err
+	}
+	if s.attributeKeys != nil {
+		org.SetAttributes(s.attributeKeys, s.nymAttributes)
+	}
 
 	sProofRandData := req.GetSchnorrProofRandomData()
 	x := new(big.Int).SetBytes(sProofRandData.X)
 	a := new(big.Int).SetBytes(sProofRandData.A)
 	b := new(big.Int).SetBytes(sProofRandData.B)
-	challenge := org.GetAuthenticationChallenge(a, b, x)
+	challenge, err := org.GetAuthenticationChallenge(a, b, x)
+	if err != nil {
+		return err
+	}
 
 	resp := &pb.Message{
 		Content: &pb.Message_Bigint{
@@ -106,7 +126,7 @@ func (s *Server) PseudonymsysIssueCredential(req *pb.Message, stream pb.Protocol
 		return err
 	}
 
-	req, err := s.receive(stream)
+	req, err = s.receive(stream)
 	if err != nil {
 		return err
 	}
@@ -123,17 +143,27 @@ func (s *Server) PseudonymsysIssueCredential(req *pb.Message, stream pb.Protocol
 			ProtocolError: err.Error(),
 		}
 	} else {
+		randomData := &pb.PseudonymsysIssueProofRandomData{
+			X11: x11.Bytes(),
+			X12: x12.Bytes(),
+			X21: x21.Bytes(),
+			X22: x22.Bytes(),
+			A:   A.Bytes(),
+			B:   B.Bytes(),
+		}
+		for _, attr := range org.GetIssuedAttributes() {
+			randomData.Attributes = append(randomData.Attributes,
+				&pb.PseudonymsysIssuedAttribute{
+					Name:  attr.Name,
+					Value: attr.Value.Bytes(),
+					R:     attr.R.Bytes(),
+					D:     attr.D.Bytes(),
+					X1:    attr.X1.Bytes(),
+					X2:    attr.X2.Bytes(),
+				})
+		}
 		resp = &pb.Message{
-			Content: &pb.Message_PseudonymsysIssueProofRandomData{
-				&pb.PseudonymsysIssueProofRandomData{
-					X11: x11.Bytes(),
-					X12: x12.Bytes(),
-					X21: x21.Bytes(),
-					X22: x22.Bytes(),
-					A:   A.Bytes(),
-					B:   B.Bytes(),
-				},
-			},
+			Content: &pb.Message_PseudonymsysIssueProofRandomData{randomData},
 		}
 	}
 
@@ -146,11 +176,42 @@ func (s *Server) PseudonymsysIssueCredential(req *pb.Message, stream pb.Protocol
 		return err
 	}
 
+	// with attributes the challenges of their equality proofs follow the two challenges
+	if numOfAttributes := len(org.GetIssuedAttributes()); numOfAttributes > 0 {
+		challenges := req.GetRepeatedBigint()
+		if challenges == nil || len(challenges.X) != 2+numOfAttributes {
+			return s.send(&pb.Message{ProtocolError: "Challenges expected."}, stream)
+		}
+		c := make([]*big.Int, len(challenges.X))
+		for i, x := range challenges.X {
+			c[i] = new(big.Int).SetBytes(x)
+		}
+		z1, z2, err := org.GetEqualityProofData(c[0], c[1])
+		if err != nil {
+			return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+		}
+		z, err := org.GetAttributeProofData(c[2:])
+		if err != nil {
+			return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+		}
+		resp = &pb.Message{
+			Content: &pb.Message_RepeatedBigint{
+				&pb.RepeatedBigInt{
+					X: append([][]byte{z1.Bytes(), z2.Bytes()}, toBytes(z)...),
+				},
+			},
+		}
+		return s.send(resp, stream)
+	}
+
 	challenges := req.GetDoubleBigint()
 	challenge1 := new(big.Int).SetBytes(challenges.X1)
 	challenge2 := new(big.Int).SetBytes(challenges.X2)
 
-	z1, z2 := org.GetEqualityProofData(challenge1, challenge2)
+	z1, z2, err := org.GetEqualityProofData(challenge1, challenge2)
+	if err != nil {
+		return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+	}
 	resp = &pb.Message{
 		Content: &pb.Message_DoubleBigint{
 			&pb.DoubleBigInt{
@@ -179,30 +240,34 @@ func (s *Server) PseudonymsysTransferCredential(req *pb.Message, stream pb.Proto
 	nymA := new(big.Int).SetBytes(data.NymA)
 	nymB := new(big.Int).SetBytes(data.NymB)
 
-	t1 := dlogproofs.NewTranscript(
-		new(big.Int).SetBytes(data.Credential.T1.A),
-		new(big.Int).SetBytes(data.Credential.T1.B),
-		new(big.Int).SetBytes(data.Credential.T1.Hash),
-		new(big.Int).SetBytes(data.Credential.T1.ZAlpha),
-	)
+	credential := toCredential(data.Credential)
 
-	t2 := dlogproofs.NewTranscript(
-		new(big.Int).SetBytes(data.Credential.T2.A),
-		new(big.Int).SetBytes(data.Credential.T2.B),
-		new(big.Int).SetBytes(data.Credential.T2.Hash),
-		new(big.Int).SetBytes(data.Credential.T2.ZAlpha),
-	)
-
-	credential := pseudonymsys.NewCredential(
-		new(big.Int).SetBytes(data.Credential.SmallAToGamma),
-		new(big.Int).SetBytes(data.Credential.SmallBToGamma),
-		new(big.Int).SetBytes(data.Credential.AToGamma),
-		new(big.Int).SetBytes(data.Credential.BToGamma),
-		t1, t2,
-	)
+	// attributes disclosed and predicates proved about the hidden ones
+	request, err := toAttributeRequest(data.Request)
+	if err == nil {
+		err = s.checkAttributeRequest(credential, request)
+	}
+	var predicates *pseudonymsys.PredicateVerifier
+	if err == nil {
+		predicates, err = pseudonymsys.NewPredicateVerifier(group, credential, request.Predicates)
+	}
+	if err == nil {
+		var randomData [][]*rangeproofs.BitProofRandomData
+		randomData, err = toPredicateBitProofRandomData(data.Predicates, len(request.Predicates))
+		if err == nil {
+			err = predicates.SetProofRandomData(randomData)
+		}
+	}
+	if err != nil {
+		return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+	}
 
-	challenge := org.GetAuthenticationChallenge(nymA, nymB,
+	challenge, err := org.GetAuthenticationChallenge(nymA, nymB,
 		credential.SmallAToGamma, credential.SmallBToGamma, x1, x2)
+	if err != nil {
+		return err
+	}
+	predicates.SetChallenge(challenge)
 
 	resp := &pb.Message{
 		Content: &pb.Message_Bigint{
@@ -216,19 +281,31 @@ func (s *Server) PseudonymsysTransferCredential(req *pb.Message, stream pb.Proto
 		return err
 	}
 
-	req, err := s.receive(stream)
+	req, err = s.receive(stream)
 	if err != nil {
 		return err
 	}
 
 	// PubKeys of the organization that issue a credential:
-	h1, h2 := config.LoadPseudonymsysOrgPubKeys(orgName)
-	orgPubKeys := pseudonymsys.NewOrgPubKeys(h1, h2)
-
-	proofData := req.GetBigint()
-	z := new(big.Int).SetBytes(proofData.X1)
+	orgPubKeys := s.getOrgPubKeys(orgName)
+
+	// with predicates their responses follow z
+	var z *big.Int
+	verified := true
+	if len(request.Predicates) > 0 {
+		proofData := req.GetRepeatedBigint()
+		if proofData == nil || len(proofData.X) == 0 {
+			return s.send(&pb.Message{ProtocolError: "Proof data expected."}, stream)
+		}
+		z = new(big.Int).SetBytes(proofData.X[0])
+		bits, err := toPredicateBitProofData(proofData.X[1:], len(request.Predicates))
+		verified = err == nil && predicates.Verify(bits)
+	} else {
+		proofData := req.GetBigint()
+		z = new(big.Int).SetBytes(proofData.X1)
+	}
 
-	verified := org.VerifyAuthentication(z, credential, orgPubKeys)
+	verified = org.VerifyAuthentication(z, credential, orgPubKeys) && verified
 
 	resp = &pb.Message{}
 	// If something went wrong (either user was not authenticated or secure session key could not
@@ -255,3 +332,30 @@ func (s *Server) PseudonymsysTransferCredential(req *pb.Message, stream pb.Proto
 
 	return nil
 }
+
+// toCredential converts the protobuf representation of the credential.
+func toCredential(data *pb.PseudonymsysCredential) *pseudonymsys.Credential {
+	t1 := dlogproofs.NewTranscript(
+		new(big.Int).SetBytes(data.T1.A),
+		new(big.Int).SetBytes(data.T1.B),
+		new(big.Int).SetBytes(data.T1.Hash),
+		new(big.Int).SetBytes(data.T1.ZAlpha),
+	)
+
+	t2 := dlogproofs.NewTranscript(
+		new(big.Int).SetBytes(data.T2.A),
+		new(big.Int).SetBytes(data.T2.B),
+		new(big.Int).SetBytes(data.T2.Hash),
+		new(big.Int).SetBytes(data.T2.ZAlpha),
+	)
+
+	credential := pseudonymsys.NewCredential(
+		new(big.Int).SetBytes(data.SmallAToGamma),
+		new(big.Int).SetBytes(data.SmallBToGamma),
+		new(big.Int).SetBytes(data.AToGamma),
+		new(big.Int).SetBytes(data.BToGamma),
+		t1, t2,
+	)
+	credential.Attributes = toCredentialAttributes(data.Attributes)
+	return credential
+}
@@ -0,0 +1,209 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/rangeproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/anoncreds"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"math/big"
+)
+
+// SetPseudonymsysAttributes makes the server embed attributes into the pseudonymsys
+// credentials which it issues - the values for the nym are given by attributes and signed
+// with keys. If keys is nil (the default), the credentials have no attributes.
+func (s *Server) SetPseudonymsysAttributes(keys *pseudonymsys.OrgAttributeKeys,
+	attributes pseudonymsys.Attributes) {
+	s.attributeKeys = keys
+	s.nymAttributes = attributes
+}
+
+// SetPseudonymsysAttributeVerifier sets the public keys of the attributes of the credentials
+// issued by other organizations (by the name of the organization) and the attributes which
+// the clients need to disclose and the predicates they need to prove when transferring
+// the credentials. If request is nil, the clients can choose what they disclose.
+func (s *Server) SetPseudonymsysAttributeVerifier(
+	pubKeys map[string]*pseudonymsys.OrgAttributePubKeys, request *anoncreds.PresentationRequest) {
+	s.attributePubKeys = pubKeys
+	s.attributeRequest = request
+}
+
+// getOrgPubKeys returns the public keys (including the keys of the attributes) of
+// the organization which issued the credential.
+func (s *Server) getOrgPubKeys(orgName string) *pseudonymsys.OrgPubKeys {
+	h1, h2 := config.LoadPseudonymsysOrgPubKeys(orgName)
+	orgPubKeys := pseudonymsys.NewOrgPubKeys(h1, h2)
+	orgPubKeys.Attributes = s.attributePubKeys[orgName]
+	return orgPubKeys
+}
+
+// checkAttributeRequest checks that the presented credential discloses exactly
+// the attributes of the request and that the request fulfils the server's one.
+func (s *Server) checkAttributeRequest(credential *pseudonymsys.Credential,
+	request *anoncreds.PresentationRequest) error {
+	disclosed := make(map[string]bool, len(request.Disclosed))
+	for _, name := range request.Disclosed {
+		attr := credential.Attribute(name)
+		if attr == nil || attr.Value == nil || disclosed[name] {
+			return fmt.Errorf("attribute %s is not disclosed", name)
+		}
+		disclosed[name] = true
+	}
+	for _, attr := range credential.Attributes {
+		if attr.Value != nil && !disclosed[attr.Name] {
+			return fmt.Errorf("attribute %s is disclosed but not requested", attr.Name)
+		}
+	}
+
+	if s.attributeRequest == nil {
+		return nil
+	}
+	for _, name := range s.attributeRequest.Disclosed {
+		if !disclosed[name] {
+			return fmt.Errorf("attribute %s needs to be disclosed", name)
+		}
+	}
+	for _, required := range s.attributeRequest.Predicates {
+		proved := false
+		for _, predicate := range request.Predicates {
+			if predicate.Attribute == required.Attribute && predicate.Type == required.Type &&
+				predicate.Bound.Cmp(required.Bound) == 0 {
+				proved = true
+			}
+		}
+		if !proved {
+			return fmt.Errorf("predicate %s %v %v needs to be proved", required.Attribute,
+				required.Type, required.Bound)
+		}
+	}
+	return nil
+}
+
+func toAttributeRequest(request *pb.AnonCredsPresentationRequest) (
+	*anoncreds.PresentationRequest, error) {
+	if request == nil {
+		return &anoncreds.PresentationRequest{}, nil
+	}
+	if len(request.PredicateAttributes) != len(request.PredicateTypes) ||
+		len(request.PredicateAttributes) != len(request.PredicateBounds) {
+		return nil, fmt.Errorf("invalid presentation request")
+	}
+	presentationRequest := &anoncreds.PresentationRequest{
+		Disclosed: request.Disclosed,
+	}
+	for i, name := range request.PredicateAttributes {
+		t, err := anoncreds.ParsePredicateType(request.PredicateTypes[i])
+		if err != nil {
+			return nil, err
+		}
+		presentationRequest.Predicates = append(presentationRequest.Predicates,
+			&anoncreds.Predicate{
+				Attribute: name,
+				Type:      t,
+				Bound:     new(big.Int).SetBytes(request.PredicateBounds[i]),
+			})
+	}
+	return presentationRequest, nil
+}
+
+// toCredentialAttributes converts the protobuf representation of the attributes of
+// the credential - the values are set only for the disclosed ones.
+func toCredentialAttributes(
+	data []*pb.PseudonymsysCredentialAttribute) []*pseudonymsys.CredentialAttribute {
+	attributes := make([]*pseudonymsys.CredentialAttribute, len(data))
+	for i, a := range data {
+		attr := &pseudonymsys.CredentialAttribute{
+			Name: a.Name,
+			V:    new(big.Int).SetBytes(a.V),
+			D:    new(big.Int).SetBytes(a.D),
+		}
+		if a.Disclosed {
+			attr.Value = new(big.Int).SetBytes(a.Value)
+			attr.R = new(big.Int).SetBytes(a.R)
+		}
+		if a.T != nil {
+			attr.T = dlogproofs.NewTranscript(
+				new(big.Int).SetBytes(a.T.A),
+				new(big.Int).SetBytes(a.T.B),
+				new(big.Int).SetBytes(a.T.Hash),
+				new(big.Int).SetBytes(a.T.ZAlpha),
+			)
+		}
+		attributes[i] = attr
+	}
+	return attributes
+}
+
+// toPredicateBitProofRandomData converts the values of the predicate proofs (C, T0, T1 of each of
+// the pseudonymsys.AttributeBitLen bits of each predicate).
+func toPredicateBitProofRandomData(values [][]byte, numOfPredicates int) (
+	[][]*rangeproofs.BitProofRandomData, error) {
+	n := pseudonymsys.AttributeBitLen
+	if len(values) != 3*n*numOfPredicates {
+		return nil, fmt.Errorf("proof random data of the predicates expected")
+	}
+	data := make([][]*rangeproofs.BitProofRandomData, numOfPredicates)
+	for j := range data {
+		data[j] = make([]*rangeproofs.BitProofRandomData, n)
+		for k := range data[j] {
+			x := values[3*(j*n+k):]
+			data[j][k] = &rangeproofs.BitProofRandomData{
+				C:  new(big.Int).SetBytes(x[0]),
+				T0: new(big.Int).SetBytes(x[1]),
+				T1: new(big.Int).SetBytes(x[2]),
+			}
+		}
+	}
+	return data, nil
+}
+
+// toPredicateBitProofData converts the responses of the predicate proofs (E0, E1, Z0, Z1 of each bit
+// of each predicate).
+func toPredicateBitProofData(values [][]byte, numOfPredicates int) ([][]*rangeproofs.BitProofData,
+	error) {
+	n := pseudonymsys.AttributeBitLen
+	if len(values) != 4*n*numOfPredicates {
+		return nil, fmt.Errorf("proof data of the predicates expected")
+	}
+	data := make([][]*rangeproofs.BitProofData, numOfPredicates)
+	for j := range data {
+		data[j] = make([]*rangeproofs.BitProofData, n)
+		for k := range data[j] {
+			x := values[4*(j*n+k):]
+			data[j][k] = &rangeproofs.BitProofData{
+				E0: new(big.Int).SetBytes(x[0]),
+				E1: new(big.Int).SetBytes(x[1]),
+				Z0: new(big.Int).SetBytes(x[2]),
+				Z1: new(big.Int).SetBytes(x[3]),
+			}
+		}
+	}
+	return data, nil
+}
+
+func toBytes(values []*big.Int) [][]byte {
+	b := make([][]byte, len(values))
+	for i, v := range values {
+		b[i] = v.Bytes()
+	}
+	return b
+}
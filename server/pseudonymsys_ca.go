@@ -28,16 +28,36 @@ func (s *Server) PseudonymsysCA(req *pb.Message, stream pb.Protocol_RunServer) e
 	var err error
 
 	group := config.LoadGroup("pseudonymsys")
-	d := config.LoadPseudonymsysCASecret()
-	pubKeyX, pubKeyY := config.LoadPseudonymsysCAPubKey()
-	ca := pseudonymsys.NewCA(group, d, pubKeyX, pubKeyY)
+	ca, err := pseudonymsys.NewCAWithSigners(group, config.LoadPseudonymsysCASigners()...)
+	if err != nil {
+		return err
+	}
 
+	// Clients which do not send PseudonymsysCARequest support only ECDSA.
 	sProofRandData := req.GetSchnorrProofRandomData()
+	algs := []pseudonymsys.SignatureAlgorithm{pseudonymsys.ECDSA}
+	if caReq := req.GetPseudonymsysCaRequest(); caReq != nil {
+		sProofRandData = caReq.ProofRandomData
+		algs = make([]pseudonymsys.SignatureAlgorithm, len(caReq.SignatureAlgorithms))
+		for i, alg := range caReq.SignatureAlgorithms {
+			algs[i] = pseudonymsys.SignatureAlgorithm(alg)
+		}
+	}
+	if _, err := ca.Negotiate(algs); err != nil {
+		resp := &pb.Message{
+			ProtocolError: err.Error(),
+		}
+		return s.send(resp, stream)
+	}
+
 	x := new(big.Int).SetBytes(sProofRandData.X)
 	a := new(big.Int).SetBytes(sProofRandData.A)
 	b := new(big.Int).SetBytes(sProofRandData.B)
 
-	challenge := ca.GetChallenge(a, b, x)
+	challenge, err := ca.GetChallenge(a, b, x)
+	if err != nil {
+		return err
+	}
 	resp := &pb.Message{
 		Content: &pb.Message_Bigint{
 			&pb.BigInt{
@@ -58,15 +78,29 @@ func (s *Server) PseudonymsysCA(req *pb.Message, stream pb.Protocol_RunServer) e
 	sProofData := req.GetSchnorrProofData()
 	z := new(big.Int).SetBytes(sProofData.Z)
 	cert, err := ca.Verify(z)
+	var sct *pb.SignedCertificateTimestamp
+	if err == nil && s.caLog != nil {
+		var logSct *pseudonymsys.SignedCertificateTimestamp
+		logSct, err = s.caLog.Submit(cert)
+		if err == nil {
+			sct = &pb.SignedCertificateTimestamp{
+				Timestamp: logSct.Timestamp,
+				R:         logSct.R.Bytes(),
+				S:         logSct.S.Bytes(),
+			}
+		}
+	}
 
 	if err == nil {
 		resp = &pb.Message{
 			Content: &pb.Message_PseudonymsysCaCertificate{
 				&pb.PseudonymsysCACertificate{
-					BlindedA: cert.BlindedA.Bytes(),
-					BlindedB: cert.BlindedB.Bytes(),
-					R:        cert.R.Bytes(),
-					S:        cert.S.Bytes(),
+					BlindedA:  cert.BlindedA.Bytes(),
+					BlindedB:  cert.BlindedB.Bytes(),
+					R:         cert.R.Bytes(),
+					S:         cert.S.Bytes(),
+					Algorithm: int32(cert.Algorithm),
+					SCT:       sct,
 				},
 			},
 		}
@@ -85,3 +119,38 @@ func (s *Server) PseudonymsysCA(req *pb.Message, stream pb.Protocol_RunServer) e
 
 	return nil
 }
+
+// PseudonymsysCAStatus answers the query about the status of the CA certificate.
+func (s *Server) PseudonymsysCAStatus(req *pb.Message, stream pb.Protocol_RunServer) error {
+	cert := req.GetPseudonymsysCaCertificate()
+	if s.caStatus == nil || cert == nil {
+		resp := &pb.Message{
+			ProtocolError: "CA certificate status is not available.",
+		}
+		return s.send(resp, stream)
+	}
+
+	certificate := pseudonymsys.NewCACertificate(
+		new(big.Int).SetBytes(cert.BlindedA), new(big.Int).SetBytes(cert.BlindedB),
+		new(big.Int).SetBytes(cert.R), new(big.Int).SetBytes(cert.S),
+		pseudonymsys.SignatureAlgorithm(cert.Algorithm))
+	status, err := s.caStatus.GetStatus(certificate)
+	if err != nil {
+		resp := &pb.Message{
+			ProtocolError: err.Error(),
+		}
+		return s.send(resp, stream)
+	}
+
+	resp := &pb.Message{
+		Content: &pb.Message_PseudonymsysCaStatus{
+			&pb.PseudonymsysCAStatus{
+				Status:    int32(status.Status),
+				Timestamp: status.Timestamp,
+				R:         status.R.Bytes(),
+				S:         status.S.Bytes(),
+			},
+		},
+	}
+	return s.send(resp, stream)
+}
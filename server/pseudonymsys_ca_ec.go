@@ -39,7 +39,10 @@ func (s *Server) PseudonymsysCAEC(curveType dlog.Curve, req *pb.Message,
 	a := types.ToECGroupElement(sProofRandData.A)
 	b := types.ToECGroupElement(sProofRandData.B)
 
-	challenge := ca.GetChallenge(a, b, x)
+	challenge, err := ca.GetChallenge(a, b, x)
+	if err != nil {
+		return err
+	}
 	resp := &pb.Message{
 		Content: &pb.Message_Bigint{
 			&pb.BigInt{
@@ -23,6 +23,7 @@ import (
 	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
 	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
 	pb "github.com/xlab-si/emmy/protobuf"
+	"github.com/xlab-si/emmy/provisioning"
 	"github.com/xlab-si/emmy/types"
 	"math/big"
 )
@@ -73,6 +74,9 @@ func (s *Server) PseudonymsysGenerateNymEC(curveType dlog.Curve, req *pb.Message
 	proofData := req.GetSchnorrProofData() // SchnorrProofData is used in DLog equality proof as well
 	z := new(big.Int).SetBytes(proofData.Z)
 	valid := org.Verify(z)
+	if valid {
+		s.provision(provisioning.NymECAccountID(pseudonymsys.NewPseudonymEC(nymA, nymB)))
+	}
 
 	resp = &pb.Message{
 		Content: &pb.Message_Status{&pb.Status{Success: valid}},
@@ -94,7 +98,10 @@ func (s *Server) PseudonymsysIssueCredentialEC(curveType dlog.Curve, req *pb.Mes
 
 	s1, s2 := config.LoadPseudonymsysOrgSecrets("org1", "ecdlog")
 	org := pseudonymsys.NewOrgCredentialIssuerEC(s1, s2, curveType)
-	challenge := org.GetAuthenticationChallenge(a, b, x)
+	challenge, err := org.GetAuthenticationChallenge(a, b, x)
+	if err != nil {
+		return err
+	}
 
 	resp := &pb.Message{
 		Content: &pb.Message_Bigint{
@@ -108,7 +115,7 @@ func (s *Server) PseudonymsysIssueCredentialEC(curveType dlog.Curve, req *pb.Mes
 		return err
 	}
 
-	req, err := s.receive(stream)
+	req, err = s.receive(stream)
 	if err != nil {
 		return err
 	}
@@ -153,7 +160,10 @@ func (s *Server) PseudonymsysIssueCredentialEC(curveType dlog.Curve, req *pb.Mes
 	challenge1 := new(big.Int).SetBytes(challenges.X1)
 	challenge2 := new(big.Int).SetBytes(challenges.X2)
 
-	z1, z2 := org.GetEqualityProofData(challenge1, challenge2)
+	z1, z2, err := org.GetEqualityProofData(challenge1, challenge2)
+	if err != nil {
+		return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+	}
 	resp = &pb.Message{
 		Content: &pb.Message_DoubleBigint{
 			&pb.DoubleBigInt{
@@ -206,8 +216,11 @@ func (s *Server) PseudonymsysTransferCredentialEC(curveType dlog.Curve, req *pb.
 		t1, t2,
 	)
 
-	challenge := org.GetAuthenticationChallenge(nymA, nymB,
+	challenge, err := org.GetAuthenticationChallenge(nymA, nymB,
 		credential.SmallAToGamma, credential.SmallBToGamma, x1, x2)
+	if err != nil {
+		return err
+	}
 
 	resp := &pb.Message{
 		Content: &pb.Message_Bigint{
@@ -221,7 +234,7 @@ func (s *Server) PseudonymsysTransferCredentialEC(curveType dlog.Curve, req *pb.
 		return err
 	}
 
-	req, err := s.receive(stream)
+	req, err = s.receive(stream)
 	if err != nil {
 		return err
 	}
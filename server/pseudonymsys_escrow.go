@@ -0,0 +1,110 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/encproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"math/big"
+)
+
+// SetNymEscrowKey sets the auditor's public key under which the master secrets of nyms
+// are escrowed. If key is nil (the default), escrows are refused.
+func (s *Server) SetNymEscrowKey(key *encryption.CSPaillierPubKey) {
+	s.nymEscrowKey = key
+}
+
+// GetNymEscrowRegistry returns the registry of the verified escrows. The auditor obtains
+// the escrow of a nym from it (see pseudonymsys.Auditor).
+func (s *Server) GetNymEscrowRegistry() *pseudonymsys.NymEscrowRegistry {
+	return s.nymEscrows
+}
+
+// PseudonymsysNymEscrow verifies that the client's escrow contains the master secret
+// of the nym and stores it into the registry.
+func (s *Server) PseudonymsysNymEscrow(req *pb.Message, stream pb.Protocol_RunServer) error {
+	if s.nymEscrowKey == nil {
+		return s.send(&pb.Message{ProtocolError: "Nym escrow is not supported."}, stream)
+	}
+	data := req.GetPseudonymsysNymEscrowData()
+	if data == nil || data.ProofRandomData == nil {
+		return s.send(&pb.Message{ProtocolError: "Escrow data expected."}, stream)
+	}
+
+	group := config.LoadGroup("pseudonymsys")
+	org := pseudonymsys.NewOrgNymEscrow(group, s.nymEscrowKey)
+	escrow := &pseudonymsys.NymEscrow{
+		Nym: pseudonymsys.NewPseudonym(new(big.Int).SetBytes(data.NymA),
+			new(big.Int).SetBytes(data.NymB)),
+		Encryption: &encproofs.CSDLogEncryption{
+			U: new(big.Int).SetBytes(data.U),
+			E: new(big.Int).SetBytes(data.E),
+			V: new(big.Int).SetBytes(data.V),
+			L: new(big.Int).SetBytes(data.L),
+		},
+	}
+	pRandomData := data.ProofRandomData
+	challenge, err := org.GetChallenge(escrow, &encproofs.CSDLogProofRandomData{
+		U1:     new(big.Int).SetBytes(pRandomData.U1),
+		E1:     new(big.Int).SetBytes(pRandomData.E1),
+		V1:     new(big.Int).SetBytes(pRandomData.V1),
+		Delta1: new(big.Int).SetBytes(pRandomData.Delta1),
+		L1:     new(big.Int).SetBytes(pRandomData.L1),
+	})
+	if err != nil {
+		s.logger.Debug(err)
+		return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+	}
+	resp := &pb.Message{Content: &pb.Message_Bigint{&pb.BigInt{X1: challenge.Bytes()}}}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err = s.receive(stream)
+	if err != nil {
+		return err
+	}
+	pData := req.GetCsPaillierProofData()
+	if pData == nil {
+		return s.send(&pb.Message{ProtocolError: "Proof data expected."}, stream)
+	}
+	verified, err := org.Verify(&encproofs.CSDLogProofData{
+		RTilde: signedInt(pData.RTilde, pData.RTildeIsNeg),
+		STilde: signedInt(pData.STilde, pData.STildeIsNeg),
+		MTilde: signedInt(pData.MTilde, pData.MTildeIsNeg),
+	})
+	if err == nil {
+		s.nymEscrows.Add(verified)
+	}
+	s.logger.Noticef("Nym escrow success: **%v**", err == nil)
+
+	resp = &pb.Message{Content: &pb.Message_Status{&pb.Status{Success: err == nil}}}
+	return s.send(resp, stream)
+}
+
+// signedInt returns the integer with the absolute value abs (encoded as big-endian bytes).
+func signedInt(abs []byte, isNeg bool) *big.Int {
+	x := new(big.Int).SetBytes(abs)
+	if isNeg {
+		x.Neg(x)
+	}
+	return x
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"math/big"
+)
+
+// SetNymRegistry sets the registry to which the nyms are added once they are generated.
+// If registry is nil (the default), nyms are not registered and inclusion proofs are refused.
+func (s *Server) SetNymRegistry(registry *pseudonymsys.NymRegistry) {
+	s.nymRegistry = registry
+}
+
+// GetNymRegistry returns the registry of the generated nyms. Auditors obtain the published
+// roots and the nyms of each epoch from it (see pseudonymsys.NymRegistryAuditor).
+func (s *Server) GetNymRegistry() *pseudonymsys.NymRegistry {
+	return s.nymRegistry
+}
+
+// PseudonymsysNymRegistry returns the proof that the client's nym is included in the last
+// published root of the nym registry.
+func (s *Server) PseudonymsysNymRegistry(req *pb.Message, stream pb.Protocol_RunServer) error {
+	if s.nymRegistry == nil {
+		return s.send(&pb.Message{ProtocolError: "Nym registry is not available."}, stream)
+	}
+	data := req.GetDoubleBigint()
+	if data == nil {
+		return s.send(&pb.Message{ProtocolError: "Nym expected."}, stream)
+	}
+
+	nym := pseudonymsys.NewPseudonym(new(big.Int).SetBytes(data.X1),
+		new(big.Int).SetBytes(data.X2))
+	proof, root, err := s.nymRegistry.Prove(nym)
+	if err != nil {
+		return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+	}
+
+	resp := &pb.Message{
+		Content: &pb.Message_NymInclusionProof{
+			&pb.NymInclusionProof{
+				Root: &pb.NymRegistryRoot{
+					Epoch:     root.Epoch,
+					Size:      int64(root.Size),
+					Root:      root.Root,
+					Previous:  root.Previous,
+					Timestamp: root.Timestamp,
+					R:         root.R.Bytes(),
+					S:         root.S.Bytes(),
+				},
+				Bitmap:   proof.Bitmap,
+				Siblings: proof.Siblings,
+			},
+		},
+	}
+	return s.send(resp, stream)
+}
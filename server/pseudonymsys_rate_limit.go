@@ -0,0 +1,92 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package server
+
+import (
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/anoncreds"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"math/big"
+)
+
+// SetRateLimitIssuer sets the public key of the issuer of the (personhood) anonymous
+// credentials with which the clients obtain the authorization of rate-limited actions.
+// If pubKey is nil (the default), the server does not authorize actions.
+func (s *Server) SetRateLimitIssuer(pubKey *anoncreds.PublicKey) {
+	s.rateLimitPubKey = pubKey
+}
+
+// PseudonymsysRateLimit authorizes an action in the requested scope if the client proves that
+// it holds a credential of the rate limit issuer and that the limit of actions for this
+// credential (that is for the human behind it) in the current period has not been reached
+// yet. The client reveals only the ticket for the scope and the period and the proof that
+// the ticket is computed with the master secret of the credential (see
+// pseudonymsys.EpochTicketVerifier), thus the server cannot link the actions of different
+// scopes or periods.
+func (s *Server) PseudonymsysRateLimit(req *pb.Message, stream pb.Protocol_RunServer) error {
+	if s.rateLimitPubKey == nil {
+		return s.send(&pb.Message{
+			ProtocolError: "Actions are not authorized.",
+		}, stream)
+	}
+	data := req.GetPseudonymsysRateLimitData()
+	if data == nil {
+		return s.send(&pb.Message{
+			ProtocolError: "Rate limit ticket expected.",
+		}, stream)
+	}
+	verifier := pseudonymsys.NewEpochTicketVerifier(s.rateLimiter, s.rateLimitPubKey,
+		data.Scope)
+
+	challenge, err := verifier.GetChallenge(data.Epoch, new(big.Int).SetBytes(data.Ticket),
+		new(big.Int).SetBytes(data.V), new(big.Int).SetBytes(data.T),
+		new(big.Int).SetBytes(data.X))
+	if err != nil {
+		return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+	}
+	resp := &pb.Message{
+		Content: &pb.Message_Bigint{&pb.BigInt{X1: challenge.Bytes()}},
+	}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err = s.receive(stream)
+	if err != nil {
+		return err
+	}
+	// responses for all the blocks of the credential (the master secret is block 0)
+	proofData := req.GetAnonCredsProofData()
+	if proofData == nil || len(proofData.ZM) != len(s.rateLimitPubKey.Attributes)+1 {
+		return s.send(&pb.Message{
+			ProtocolError: "Rate limit proof data expected.",
+		}, stream)
+	}
+	zM := make(map[int]*big.Int, len(proofData.ZM))
+	for i, z := range proofData.ZM {
+		zM[i] = new(big.Int).SetBytes(z)
+	}
+
+	resp = &pb.Message{}
+	if err := verifier.Verify(new(big.Int).SetBytes(proofData.ZE),
+		new(big.Int).SetBytes(proofData.ZS), zM); err != nil {
+		resp.ProtocolError = err.Error()
+	} else {
+		resp.Content = &pb.Message_Status{&pb.Status{Success: true}}
+	}
+	return s.send(resp, stream)
+}
@@ -54,7 +54,10 @@ func (s *Server) QNR(req *pb.Message, qr *dlog.QR,
 			return fmt.Errorf("Shoud receive empty message at this point")
 		}
 
-		w, pairs := verifier.GetChallenge()
+		w, pairs, err := verifier.GetChallenge()
+		if err != nil {
+			return err
+		}
 		pbPairs := []*pb.Pair{}
 
 		for j := 0; j < m; j++ {
@@ -48,7 +48,10 @@ func (s *Server) QR(req *pb.Message, group *groups.SchnorrGroup,
 			return err
 		}
 		proofRandomData := new(big.Int).SetBytes(req.GetBigint().X1)
-		challenge := verifier.GetChallenge(proofRandomData)
+		challenge, err := verifier.GetChallenge(proofRandomData)
+		if err != nil {
+			return err
+		}
 
 		resp := &pb.Message{
 			Content: &pb.Message_Bigint{
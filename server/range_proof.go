@@ -0,0 +1,109 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/rangeproofs"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"math/big"
+)
+
+// RangeProof verifies that the value committed by the client with Pedersen commitment
+// lies in the range [a, b] which is sent together with the commitment.
+func (s *Server) RangeProof(group *groups.SchnorrGroup, stream pb.Protocol_RunServer) error {
+	params, err := s.pedersenParams.get(group)
+	if err != nil {
+		return err
+	}
+	receiver := commitments.NewPedersenReceiverFromParams(params)
+	h := receiver.GetH()
+
+	resp := &pb.Message{Content: &pb.Message_PedersenFirst{&pb.PedersenFirst{H: h.Bytes()}}}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err := s.receive(stream)
+	if err != nil {
+		return err
+	}
+	data := req.GetRangeProofRandomData()
+	c := new(big.Int).SetBytes(data.C)
+	a := new(big.Int).SetBytes(data.A)
+	b := new(big.Int).SetBytes(data.B)
+
+	verifier := rangeproofs.NewRangeVerifier(group, h, c, a, b)
+	if err := verifier.SetProofRandomData(&rangeproofs.RangeProofRandomData{
+		Lower: toBitProofRandomData(data.Lower),
+		Upper: toBitProofRandomData(data.Upper),
+	}); err != nil {
+		s.logger.Debug(err)
+		return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+	}
+
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return err
+	}
+	resp = &pb.Message{Content: &pb.Message_Bigint{&pb.BigInt{X1: challenge.Bytes()}}}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err = s.receive(stream)
+	if err != nil {
+		return err
+	}
+	proofData := req.GetRangeProofData()
+	valid := verifier.Verify(&rangeproofs.RangeProofData{
+		Lower: toBitProofData(proofData.Lower),
+		Upper: toBitProofData(proofData.Upper),
+	})
+
+	s.logger.Noticef("Range proof for [%v, %v] success: **%v**", a, b, valid)
+
+	resp = &pb.Message{Content: &pb.Message_Status{&pb.Status{Success: valid}}}
+	return s.send(resp, stream)
+}
+
+func toBitProofRandomData(data []*pb.BitProofRandomData) []*rangeproofs.BitProofRandomData {
+	result := make([]*rangeproofs.BitProofRandomData, len(data))
+	for i, d := range data {
+		result[i] = &rangeproofs.BitProofRandomData{
+			C:  new(big.Int).SetBytes(d.C),
+			T0: new(big.Int).SetBytes(d.T0),
+			T1: new(big.Int).SetBytes(d.T1),
+		}
+	}
+	return result
+}
+
+func toBitProofData(data []*pb.BitProofData) []*rangeproofs.BitProofData {
+	result := make([]*rangeproofs.BitProofData, len(data))
+	for i, d := range data {
+		result[i] = &rangeproofs.BitProofData{
+			E0: new(big.Int).SetBytes(d.E0),
+			E1: new(big.Int).SetBytes(d.E1),
+			Z0: new(big.Int).SetBytes(d.Z0),
+			Z1: new(big.Int).SetBytes(d.Z1),
+		}
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	pb "github.com/xlab-si/emmy/protobuf"
+	"github.com/xlab-si/emmy/revocation"
+	"time"
+)
+
+// revocationPollInterval is how often the registry is checked for new revocations when
+// it does not notify about them (for example SQLRegistry shared by several servers).
+var revocationPollInterval = 10 * time.Second
+
+// SetRevocationSigner sets the signer of the revocation registry snapshots, which enables
+// streaming of revocation updates to the clients. If signer is nil (the default), update
+// subscriptions are refused. Updates are pushed as soon as the registry is changed when
+// it is a revocation.NotifyingRegistry, otherwise the registry is polled.
+func (s *Server) SetRevocationSigner(signer *revocation.SnapshotSigner) {
+	s.revocationSigner = signer
+}
+
+// RevocationUpdates streams the updates of the revocation registry to the client, starting
+// from the version the client already has. The first update is sent immediately (it has
+// no tickets if the client is up to date, but it refreshes the signature of the client's
+// replica), then an update is sent after each change of the registry, until the client
+// closes the stream.
+func (s *Server) RevocationUpdates(req *pb.Message, stream pb.Protocol_RunServer) error {
+	sub := req.GetRevocationSubscription()
+	if s.revocationSigner == nil || sub == nil {
+		return s.send(&pb.Message{ProtocolError: "Revocation updates are not available."},
+			stream)
+	}
+
+	var changed func() <-chan struct{}
+	if registry, ok := s.revocationSigner.GetRegistry().(*revocation.NotifyingRegistry); ok {
+		changed = registry.Changed
+	}
+
+	from := sub.Version
+	first := true
+	for {
+		var notify <-chan struct{}
+		if changed != nil {
+			notify = changed()
+		}
+
+		version, err := s.revocationSigner.GetRegistry().Version()
+		if err != nil {
+			s.logger.Errorf("Revocation registry failed: %v", err)
+			return s.send(&pb.Message{ProtocolError: "Revocation registry is not available."},
+				stream)
+		}
+		if first || version > from {
+			update, err := s.revocationSigner.Update(from)
+			if err != nil {
+				s.logger.Debug(err)
+				return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+			}
+			if err := s.send(toRevocationUpdateMsg(update), stream); err != nil {
+				return err
+			}
+			from = update.Version
+			first = false
+		}
+
+		timer := time.NewTimer(revocationPollInterval)
+		select {
+		case <-notify:
+		case <-timer.C:
+		case <-stream.Context().Done():
+			timer.Stop()
+			return nil
+		}
+		timer.Stop()
+	}
+}
+
+func toRevocationUpdateMsg(update *revocation.Update) *pb.Message {
+	tickets := make([]*pb.Pair, len(update.Tickets))
+	for i, ticket := range update.Tickets {
+		tickets[i] = &pb.Pair{A: ticket.H.Bytes(), B: ticket.Tag.Bytes()}
+	}
+	return &pb.Message{
+		Content: &pb.Message_RevocationUpdate{
+			&pb.RevocationUpdate{
+				From:      update.From,
+				Version:   update.Version,
+				Timestamp: update.Timestamp,
+				Tickets:   tickets,
+				R:         update.R.Bytes(),
+				S:         update.S.Bytes(),
+			},
+		},
+	}
+}
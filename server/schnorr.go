@@ -19,62 +19,32 @@ package server
 
 import (
 	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/compiler"
 	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
 	pb "github.com/xlab-si/emmy/protobuf"
-	"github.com/xlab-si/emmy/types"
 	"math/big"
 )
 
 func (s *Server) Schnorr(req *pb.Message, group *groups.SchnorrGroup,
-	protocolType types.ProtocolType, stream pb.Protocol_RunServer) error {
-	verifier := dlogproofs.NewSchnorrVerifier(group, protocolType)
-	var err error
-
-	if protocolType != types.Sigma {
-		// ZKP, ZKPOK
-		pedersenFirst := req.GetPedersenFirst()
-		h := new(big.Int).SetBytes(pedersenFirst.H)
-		commitment := verifier.GetOpeningMsgReply(h)
-
-		resp := &pb.Message{
-			Content: &pb.Message_Bigint{
-				&pb.BigInt{X1: commitment.Bytes()},
-			},
-		}
-
-		if err = s.send(resp, stream); err != nil {
-			return err
-		}
+	variant compiler.Variant, stream pb.Protocol_RunServer) error {
+	verifier, err := dlogproofs.NewSchnorrVerifier(group)
+	if err != nil {
+		return err
+	}
+	committer := variant.NewChallengeCommitter(group, group.Q)
 
-		req, err = s.receive(stream)
-		if err != nil {
-			return err
-		}
+	req, err = s.openChallengeCommitment(req, committer, stream)
+	if err != nil {
+		return err
 	}
 
 	sProofRandData := req.GetSchnorrProofRandomData()
-
 	x := new(big.Int).SetBytes(sProofRandData.X)
 	a := new(big.Int).SetBytes(sProofRandData.A)
 	b := new(big.Int).SetBytes(sProofRandData.B)
 	verifier.SetProofRandomData(x, a, b)
 
-	challenge, r2 := verifier.GetChallenge() // r2 is nil in sigma protocol
-	if r2 == nil {
-		r2 = new(big.Int)
-	}
-
-	// pb.PedersenDecommitment is used also for SigmaProtocol (where there is no r2)
-	resp := &pb.Message{
-		Content: &pb.Message_PedersenDecommitment{
-			&pb.PedersenDecommitment{
-				X: challenge.Bytes(),
-				R: r2.Bytes(),
-			},
-		},
-	}
-
-	if err := s.send(resp, stream); err != nil {
+	if err = s.sendChallenge(committer, verifier, stream); err != nil {
 		return err
 	}
 
@@ -82,13 +52,14 @@ func (s *Server) Schnorr(req *pb.Message, group *groups.SchnorrGroup,
 	if err != nil {
 		return err
 	}
+	z := new(big.Int).SetBytes(req.GetSchnorrProofData().Z)
 
-	sProofData := req.GetSchnorrProofData()
-	z := new(big.Int).SetBytes(sProofData.Z)
-	trapdoor := new(big.Int).SetBytes(sProofData.Trapdoor)
-	valid := verifier.Verify(z, trapdoor)
+	if err = s.receiveTrapdoor(committer, stream); err != nil {
+		return err
+	}
+	valid := committer.Verified(verifier.Verify(z))
 
-	resp = &pb.Message{
+	resp := &pb.Message{
 		Content: &pb.Message_Status{&pb.Status{Success: valid}},
 	}
 
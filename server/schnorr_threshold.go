@@ -0,0 +1,87 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"math/big"
+)
+
+// SetThresholdSchnorrShare sets the share of the secret (in the schnorr group from the
+// configuration) which the server uses as a participant of threshold Schnorr proofs
+// coordinated by the clients (see client.ThresholdSchnorrHolder). If share is nil
+// (the default), the server does not participate in threshold proofs.
+func (s *Server) SetThresholdSchnorrShare(share *dlogproofs.SchnorrKeyShare) {
+	s.thresholdShare = share
+}
+
+// ThresholdSchnorr computes the server's part of the threshold Schnorr proof which
+// the client coordinates.
+func (s *Server) ThresholdSchnorr(req *pb.Message, stream pb.Protocol_RunServer) error {
+	if s.thresholdShare == nil {
+		return s.send(&pb.Message{ProtocolError: "Threshold Schnorr proofs are not supported."},
+			stream)
+	}
+	data := req.GetThresholdSchnorrRequest()
+	if data == nil {
+		return s.send(&pb.Message{ProtocolError: "Threshold Schnorr request expected."}, stream)
+	}
+	if int(data.Index) != s.thresholdShare.Index {
+		return s.send(&pb.Message{
+			ProtocolError: fmt.Sprintf("Server holds the share %d, not %d.",
+				s.thresholdShare.Index, data.Index),
+		}, stream)
+	}
+	signers := make([]int, len(data.Signers))
+	for i, j := range data.Signers {
+		signers[i] = int(j)
+	}
+
+	participant := dlogproofs.NewThresholdSchnorrParticipant(config.LoadGroup("schnorr"),
+		s.thresholdShare)
+	x, err := participant.GetProofRandomData(signers)
+	if err != nil {
+		return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+	}
+	resp := &pb.Message{
+		Content: &pb.Message_Bigint{&pb.BigInt{X1: x.Bytes()}},
+	}
+	if err = s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err = s.receive(stream)
+	if err != nil {
+		return err
+	}
+	challenge := req.GetBigint()
+	if challenge == nil {
+		return s.send(&pb.Message{ProtocolError: "Challenge expected."}, stream)
+	}
+	z, err := participant.GetProofData(new(big.Int).SetBytes(challenge.X1))
+	if err != nil {
+		return err
+	}
+	resp = &pb.Message{
+		Content: &pb.Message_Bigint{&pb.BigInt{X1: z.Bytes()}},
+	}
+	return s.send(resp, stream)
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/zkp/security"
+	pb "github.com/xlab-si/emmy/protobuf"
+)
+
+// SetDefaultSecurityParams sets the security parameters of the proofs of schemas without
+// their own parameters (see SetSecurityParams). Parameters which are not set keep
+// the defaults of the schemes.
+func (s *Server) SetDefaultSecurityParams(params security.Params) error {
+	if err := params.Validate(); err != nil {
+		return fmt.Errorf("invalid default security parameters: %v", err)
+	}
+	s.defaultSecurity = params
+	return nil
+}
+
+// SetSecurityParams sets the security parameters of the proofs of the given schema.
+// Currently they apply to GPS (challenge and statistical bit length), STERN (repetitions)
+// and PAILLIER_PLAINTEXT (challenge bit length), clients need to use the same parameters
+// (see client.WithSecurityParams).
+func (s *Server) SetSecurityParams(schema pb.SchemaType, params security.Params) error {
+	if err := params.Validate(); err != nil {
+		return fmt.Errorf("invalid security parameters for %v: %v", schema, err)
+	}
+	if s.securityParams == nil {
+		s.securityParams = make(map[pb.SchemaType]security.Params)
+	}
+	s.securityParams[schema] = params
+	return nil
+}
+
+// proofSecurity returns the security parameters of the proofs of the given schema.
+func (s *Server) proofSecurity(schema pb.SchemaType) security.Params {
+	if params, ok := s.securityParams[schema]; ok {
+		return params
+	}
+	return s.defaultSecurity
+}
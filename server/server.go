@@ -21,11 +21,21 @@ import (
 	"fmt"
 	"github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/xlab-si/emmy/attestation"
 	"github.com/xlab-si/emmy/config"
 	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/signatures/blindschnorr"
+	"github.com/xlab-si/emmy/crypto/zkp/compiler"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/anoncreds"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/crypto/zkp/security"
 	"github.com/xlab-si/emmy/log"
 	pb "github.com/xlab-si/emmy/protobuf"
-	"github.com/xlab-si/emmy/types"
+	"github.com/xlab-si/emmy/provisioning"
+	"github.com/xlab-si/emmy/revocation"
+	"github.com/xlab-si/emmy/stats"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"io"
@@ -33,16 +43,231 @@ import (
 	"net"
 	"net/http"
 	"path/filepath"
+	"time"
 )
 
 var _ pb.ProtocolServer = (*Server)(nil)
 
 type Server struct {
-	grpcServer *grpc.Server
-	logger     log.Logger
+	grpcServer       *grpc.Server
+	logger           log.Logger
+	rateLimiter      *pseudonymsys.RateLimiter
+	extensionStorage ExtensionStorage
+	caLog            *pseudonymsys.CALog
+	caStatus         *pseudonymsys.CAStatusResponder
+	curves           []dlog.Curve
+	requireHybridKEM bool
+	attestor         attestation.Attestor
+	escrowKey        *encryption.PaillierPubKey
+	nymEscrowKey     *encryption.CSPaillierPubKey
+	nymEscrows       *pseudonymsys.NymEscrowRegistry
+	nymRegistry      *pseudonymsys.NymRegistry
+	revocationSigner *revocation.SnapshotSigner
+	abuseDesk        *revocation.AbuseDesk
+	provisioner      provisioning.Provisioner
+	thresholdShare   *dlogproofs.SchnorrKeyShare
+	possession       *pseudonymsys.PossessionVerifier
+	blindSigner      *blindschnorr.Signer
+	partialSigner    *blindschnorr.Signer
+	infoPolicy       InfoPolicy
+	anonCredsIssuer  *anoncreds.Issuer
+	attributePolicy  AttributePolicy
+	anonCredsPubKey  *anoncreds.PublicKey
+	anonCredsRequest *anoncreds.PresentationRequest
+	rateLimitPubKey  *anoncreds.PublicKey
+	attributeKeys    *pseudonymsys.OrgAttributeKeys
+	nymAttributes    pseudonymsys.Attributes
+	attributePubKeys map[string]*pseudonymsys.OrgAttributePubKeys
+	attributeRequest *anoncreds.PresentationRequest
+	usage            *stats.UsageStats
+	pedersenParams   *pedersenParamsCache
+	// group for the commitments to the challenges of the EC protocols
+	compilerParams *compiler.Params
+	// deadlines for each message of the client, see SetRoundTimeout
+	defaultRoundTimeout time.Duration
+	roundTimeouts       map[pb.SchemaType]time.Duration
+	// admission control of sessions by their estimated cost, see SetAdmissionController
+	admission          *AdmissionController
+	defaultSessionCost float64
+	sessionCosts       map[pb.SchemaType]float64
+	// limits of concurrent sessions per client, see SetConcurrencyLimiter
+	concurrency    *ConcurrencyLimiter
+	clientIdentity ClientIdentity
+	// security parameters of the proofs, see SetSecurityParams
+	defaultSecurity security.Params
+	securityParams  map[pb.SchemaType]security.Params
 	*sessionManager
 }
 
+// NewServer initializes an instance of the Server struct which is not bound to any gRPC server.
+// This is to support applications which run their own gRPC server (with their own interceptors,
+// TLS configuration and lifecycle) - emmy services are added to such gRPC server with
+// RegisterServices, or with the service descriptions from ServiceDescs.
+func NewServer(logger log.Logger) (*Server, error) {
+	sessionManager, err := newSessionManager(config.LoadSessionKeyMinByteLen())
+	if err != nil {
+		logger.Warning(err)
+	}
+
+	caX, caY := config.LoadPseudonymsysCAPubKey()
+	caStatus := pseudonymsys.NewCAStatusResponder(config.LoadPseudonymsysCASecret(), caX, caY)
+
+	rateLimiter, err := pseudonymsys.NewRateLimiter(config.LoadGroup("pseudonymsys"),
+		config.LoadRateLimitParams())
+	if err != nil {
+		return nil, err
+	}
+	compilerParams := config.LoadCompilerParams()
+	if err := compilerParams.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid compiler parameters: %v", err)
+	}
+	defaultRoundTimeout, roundTimeouts := config.LoadRoundTimeouts()
+	defaultSessionCost, sessionCosts := config.LoadSessionCosts()
+
+	var usage *stats.UsageStats
+	if epsilon := config.LoadUsageStatsEpsilon(); epsilon > 0 {
+		schemas := make([]string, 0, len(pb.SchemaType_name))
+		for _, name := range pb.SchemaType_name {
+			schemas = append(schemas, name)
+		}
+		if usage, err = stats.NewUsageStats(epsilon, schemas); err != nil {
+			logger.Warning(err)
+		}
+	}
+
+	var admission *AdmissionController
+	if budget, queueLength, queueTimeout := config.LoadAdmission(); budget > 0 {
+		admission = NewAdmissionController(budget, queueLength, queueTimeout)
+	}
+
+	var concurrency *ConcurrencyLimiter
+	total, perClient, clientQueueLength, clientQueueTimeout := config.LoadConcurrency()
+	if total > 0 || perClient > 0 {
+		concurrency = NewConcurrencyLimiter(total, perClient, clientQueueLength,
+			clientQueueTimeout)
+		for client, limit := range config.LoadClientConcurrencyLimits() {
+			concurrency.SetClientLimit(client, limit)
+		}
+	}
+	clientIdentity, ok := ClientIdentities[config.LoadClientIdentity()]
+	if !ok {
+		return nil, fmt.Errorf("unknown client identity %v", config.LoadClientIdentity())
+	}
+
+	s := &Server{
+		logger:              logger,
+		rateLimiter:         rateLimiter,
+		compilerParams:      compilerParams,
+		extensionStorage:    newMemoryStorage(),
+		caLog:               pseudonymsys.NewCALog(config.LoadPseudonymsysCALogKey()),
+		caStatus:            caStatus,
+		nymEscrows:          pseudonymsys.NewNymEscrowRegistry(),
+		usage:               usage,
+		curves:              config.LoadCurves(),
+		pedersenParams:      newPedersenParamsCache(config.LoadPedersenReceiverRotation()),
+		defaultRoundTimeout: defaultRoundTimeout,
+		roundTimeouts:       roundTimeouts,
+		admission:           admission,
+		defaultSessionCost:  defaultSessionCost,
+		sessionCosts:        sessionCosts,
+		concurrency:         concurrency,
+		clientIdentity:      clientIdentity,
+		sessionManager:      sessionManager,
+	}
+
+	defaultSecurity, securityParams := config.LoadSecurityParams()
+	if err := s.SetDefaultSecurityParams(defaultSecurity); err != nil {
+		return nil, err
+	}
+	for schema, params := range securityParams {
+		if err := s.SetSecurityParams(schema, params); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// SetCALog replaces the log where pseudonymsys CA submits the issued certificates.
+// If log is nil, certificates are not logged.
+func (s *Server) SetCALog(log *pseudonymsys.CALog) {
+	s.caLog = log
+}
+
+// GetCALog returns the log where pseudonymsys CA submits the issued certificates.
+// Monitors can use it to obtain the log entries and signed tree heads.
+func (s *Server) GetCALog() *pseudonymsys.CALog {
+	return s.caLog
+}
+
+// SetCAStatusResponder replaces the responder which answers the status queries for
+// pseudonymsys CA certificates. If responder is nil, status queries are refused.
+func (s *Server) SetCAStatusResponder(responder *pseudonymsys.CAStatusResponder) {
+	s.caStatus = responder
+}
+
+// GetCAStatusResponder returns the responder which answers the status queries for
+// pseudonymsys CA certificates. CA uses it to revoke certificates.
+func (s *Server) GetCAStatusResponder() *pseudonymsys.CAStatusResponder {
+	return s.caStatus
+}
+
+// SetCurves sets the elliptic curves which are supported for EC based schemas. The first one
+// is used when the client does not propose a curve. Note that EC based pseudonymsys schemas
+// are only supported on the curves for which the keys of the CA and the organization are
+// configured.
+func (s *Server) SetCurves(curves ...dlog.Curve) {
+	s.curves = curves
+}
+
+// Curves returns the elliptic curves which are supported for the schema, the default one first.
+func (s *Server) Curves(schema pb.SchemaType) []dlog.Curve {
+	curves := s.curves
+	if len(curves) == 0 {
+		curves = []dlog.Curve{dlog.P256}
+	}
+	switch schema {
+	case pb.SchemaType_PSEUDONYMSYS_CA_EC, pb.SchemaType_PSEUDONYMSYS_NYM_GEN_EC,
+		pb.SchemaType_PSEUDONYMSYS_ISSUE_CREDENTIAL_EC,
+		pb.SchemaType_PSEUDONYMSYS_TRANSFER_CREDENTIAL_EC:
+		withKeys := []dlog.Curve{}
+		for _, c := range curves {
+			if pseudonymsysKeysOnCurve(c) {
+				withKeys = append(withKeys, c)
+			}
+		}
+		return withKeys
+	}
+	return curves
+}
+
+// selectCurve returns the curve for the session - the one proposed by the client if it is
+// supported for the schema, or the default one if the client did not propose any.
+func (s *Server) selectCurve(schema pb.SchemaType, proposed dlog.Curve) (dlog.Curve, error) {
+	curves := s.Curves(schema)
+	if len(curves) == 0 {
+		return 0, fmt.Errorf("Schema %v is not supported on any of the configured curves", schema)
+	}
+	if proposed == 0 {
+		return curves[0], nil
+	}
+	for _, c := range curves {
+		if c == proposed {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("Curve %v is not supported for schema %v, supported curves: %v",
+		proposed, schema, curves)
+}
+
+// pseudonymsysKeysOnCurve returns true if the configured EC keys of pseudonymsys CA and
+// organization (org1) are points on the curve.
+func pseudonymsysKeysOnCurve(curve dlog.Curve) bool {
+	c := dlog.GetEllipticCurve(curve)
+	caX, caY := config.LoadPseudonymsysCAPubKey()
+	h1X, h1Y, h2X, h2Y := config.LoadPseudonymsysOrgPubKeysEC("org1")
+	return c.IsOnCurve(caX, caY) && c.IsOnCurve(h1X, h1Y) && c.IsOnCurve(h2X, h2Y)
+}
+
 // NewProtocolServer initializes an instance of the Server struct and returns a pointer.
 // It performs some default configuration (tracing of gRPC communication and interceptors)
 // and registers RPC protocol server with gRPC server. It requires TLS cert and keyfile
@@ -61,30 +286,25 @@ func NewProtocolServer(certFile, keyFile string, logger log.Logger) (*Server, er
 
 	logger.Infof("Successfully read certificate [%s] and key [%s]", certFile, keyFile)
 
-	sessionManager, err := newSessionManager(config.LoadSessionKeyMinByteLen())
+	server, err := NewServer(logger)
 	if err != nil {
-		logger.Warning(err)
+		return nil, err
 	}
 
 	// Allow as much concurrent streams as possible and register a gRPC stream interceptor
 	// for logging and monitoring purposes.
-	server := &Server{
-		grpcServer: grpc.NewServer(
-			grpc.Creds(creds),
-			grpc.MaxConcurrentStreams(math.MaxUint32),
-			grpc.StreamInterceptor(grpc_prometheus.StreamServerInterceptor),
-		),
-		logger: logger,
-		sessionManager: sessionManager,
-	}
+	server.grpcServer = grpc.NewServer(
+		grpc.Creds(creds),
+		grpc.MaxConcurrentStreams(math.MaxUint32),
+		grpc.StreamInterceptor(grpc_prometheus.StreamServerInterceptor),
+	)
 
 	// Disable tracing by default, as is used for debugging purposes.
 	// The user will be able to turn it on via Server's EnableTracing function.
 	grpc.EnableTracing = false
 
 	// Register our services with the supporting gRPC server
-	pb.RegisterProtocolServer(server.grpcServer, server)
-	pb.RegisterInfoServer(server.grpcServer, server)
+	server.RegisterServices(server.grpcServer)
 
 	// Initialize gRPC metrics offered by Prometheus package
 	grpc_prometheus.Register(server.grpcServer)
@@ -93,31 +313,69 @@ func NewProtocolServer(certFile, keyFile string, logger log.Logger) (*Server, er
 	return server, nil
 }
 
+// RegisterServices registers emmy's Protocol and Info services with the given gRPC server.
+func (s *Server) RegisterServices(grpcServer *grpc.Server) {
+	pb.RegisterProtocolServer(grpcServer, s)
+	pb.RegisterInfoServer(grpcServer, s)
+}
+
+// ServiceDescs returns descriptions of emmy's services. Each of them is to be registered
+// with s as the implementation, for example grpcServer.RegisterService(desc, s).
+func (s *Server) ServiceDescs() []*grpc.ServiceDesc {
+	return []*grpc.ServiceDesc{
+		pb.ProtocolServiceDesc(),
+		pb.InfoServiceDesc(),
+	}
+}
+
 // Start configures and starts the protocol server at the requested port.
+// It is only to be used with servers created by NewProtocolServer.
 func (s *Server) Start(port int) error {
+	if s.grpcServer == nil {
+		return fmt.Errorf("Server is not bound to its own gRPC server")
+	}
+
 	connStr := fmt.Sprintf(":%d", port)
 	listener, err := net.Listen("tcp", connStr)
 	if err != nil {
 		return fmt.Errorf("Could not connect: %v", err)
 	}
+	return s.Serve(listener)
+}
+
+// Serve is the same as Start, but it accepts the connections on the given listener (for
+// example one listening on port 0, which is assigned a free port by the system).
+func (s *Server) Serve(listener net.Listener) error {
+	if s.grpcServer == nil {
+		return fmt.Errorf("Server is not bound to its own gRPC server")
+	}
 
 	// Register Prometheus metrics handler and serve metrics page on the desired endpoint.
 	// Metrics are handled via HTTP in a separate goroutine as gRPC requests,
 	// as grpc server's performance over HTTP (grpcServer.ServeHTTP) is much worse.
-	http.Handle("/metrics", prometheus.Handler())
-
-	// After this, /metrics will be available, along with /debug/requests, /debug/events in
-	// case server's EnableTracing function is called.
-	go http.ListenAndServe(":8881", nil)
+	// Each server uses its own mux, so that more servers can be started in one process.
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", prometheus.Handler())
+	mux.HandleFunc("/usage", s.serveUsage)
+	mux.Handle("/possession/", http.StripPrefix("/possession", s.PossessionHandler()))
+	// tracing registers its pages with the default mux
+	mux.Handle("/debug/", http.DefaultServeMux)
+
+	// After this, /metrics, /usage and /possession/ will be available, along with
+	// /debug/requests, /debug/events in case server's EnableTracing function is called.
+	go http.ListenAndServe(":8881", mux)
 
 	// From here on, gRPC server will accept connections
-	s.logger.Noticef("Emmy server listening for connections on port %d", port)
+	s.logger.Noticef("Emmy server listening for connections on %v", listener.Addr())
 	s.grpcServer.Serve(listener)
 	return nil
 }
 
 // Teardown stops the protocol server by gracefully stopping enclosed gRPC server.
 func (s *Server) Teardown() {
+	if s.grpcServer == nil {
+		return
+	}
 	s.logger.Notice("Tearing down gRPC server")
 	s.grpcServer.GracefulStop()
 }
@@ -162,6 +420,27 @@ func (s *Server) Run(stream pb.Protocol_RunServer) error {
 		return err
 	}
 
+	if kemInit := req.GetHybridKemInit(); kemInit != nil {
+		if stream, err = s.hybridKEMHandshake(kemInit, stream); err != nil {
+			return err
+		}
+		if req, err = s.receive(stream); err != nil {
+			return err
+		}
+	} else if s.requireHybridKEM {
+		s.logger.Errorf("Client [ %v ] did not initiate hybrid key exchange", req.ClientId)
+		return s.send(&pb.Message{ProtocolError: "Hybrid key exchange is required."}, stream)
+	}
+
+	if attReq := req.GetAttestationRequest(); attReq != nil {
+		if err = s.attest(attReq, stream); err != nil {
+			return err
+		}
+		if req, err = s.receive(stream); err != nil {
+			return err
+		}
+	}
+
 	reqClientId := req.ClientId
 	reqSchemaType := req.Schema
 	reqSchemaVariant := req.SchemaVariant
@@ -180,10 +459,35 @@ func (s *Server) Run(stream pb.Protocol_RunServer) error {
 
 	s.logger.Noticef("Client [ %v ] requested schema %v, variant %v", reqClientId, reqSchemaTypeStr, reqSchemaVariantStr)
 
-	// Convert Sigma, ZKP or ZKPOK protocol type to a types type
-	protocolType := types.ToProtocolType(reqSchemaVariant)
-	// This curve will be used for all schemes
-	curve := dlog.P256
+	if timeout := s.roundTimeout(reqSchemaType); timeout > 0 {
+		stream = newDeadlineStream(stream, reqSchemaType, timeout)
+	}
+
+	variant := challengeVariant(reqSchemaVariant)
+	curve, err := s.selectCurve(reqSchemaType, dlog.Curve(req.Curve))
+	if err != nil {
+		s.logger.Errorf("Client [ %v ]: %v", reqClientId, err)
+		return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+	}
+
+	if s.concurrency != nil {
+		client := s.clientIdentity(stream.Context(), req)
+		release, err := s.concurrency.Acquire(stream.Context(), reqSchemaType, client)
+		if err != nil {
+			s.logger.Warningf("Client [ %v ] was not admitted: %v", reqClientId, err)
+			return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+		}
+		defer release()
+	}
+
+	if s.admission != nil {
+		release, err := s.admission.Admit(stream.Context(), reqSchemaType, s.sessionCost(req, curve))
+		if err != nil {
+			s.logger.Warningf("Client [ %v ] was not admitted: %v", reqClientId, err)
+			return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+		}
+		defer release()
+	}
 
 	switch reqSchemaType {
 	case pb.SchemaType_PEDERSEN_EC:
@@ -191,19 +495,51 @@ func (s *Server) Run(stream pb.Protocol_RunServer) error {
 	case pb.SchemaType_PEDERSEN:
 		group := config.LoadGroup("pedersen")
 		err = s.Pedersen(group, stream)
+	case pb.SchemaType_RANGE_PROOF:
+		group := config.LoadGroup("pedersen")
+		err = s.RangeProof(group, stream)
+	case pb.SchemaType_PAILLIER_PLAINTEXT:
+		group := config.LoadGroup("pedersen")
+		err = s.PaillierPlaintext(group, stream)
 	case pb.SchemaType_SCHNORR:
 		group := config.LoadGroup("schnorr")
-		err = s.Schnorr(req, group, protocolType, stream)
+		err = s.Schnorr(req, group, variant, stream)
 	case pb.SchemaType_SCHNORR_EC:
-		err = s.SchnorrEC(req, protocolType, stream, curve)
+		err = s.SchnorrEC(req, variant, stream, curve)
 	case pb.SchemaType_CSPAILLIER:
 		keyDir := config.LoadKeyDirFromConfig()
 		secKeyPath := filepath.Join(keyDir, "cspaillierseckey.txt")
 		err = s.CSPaillier(req, secKeyPath, stream)
 	case pb.SchemaType_PSEUDONYMSYS_CA:
 		err = s.PseudonymsysCA(req, stream)
+	case pb.SchemaType_PSEUDONYMSYS_CA_STATUS:
+		err = s.PseudonymsysCAStatus(req, stream)
 	case pb.SchemaType_PSEUDONYMSYS_NYM_GEN:
 		err = s.PseudonymsysGenerateNym(req, stream)
+	case pb.SchemaType_PSEUDONYMSYS_NYM_ESCROW:
+		err = s.PseudonymsysNymEscrow(req, stream)
+	case pb.SchemaType_PSEUDONYMSYS_NYM_REGISTRY:
+		err = s.PseudonymsysNymRegistry(req, stream)
+	case pb.SchemaType_GPS:
+		err = s.GPS(req, stream)
+	case pb.SchemaType_STERN:
+		err = s.Stern(req, stream)
+	case pb.SchemaType_LATTICE_SHORT_VECTOR:
+		err = s.LatticeShortVector(req, stream)
+	case pb.SchemaType_THRESHOLD_SCHNORR:
+		err = s.ThresholdSchnorr(req, stream)
+	case pb.SchemaType_BLIND_SCHNORR:
+		err = s.BlindSchnorr(req, stream)
+	case pb.SchemaType_PARTIALLY_BLIND_SCHNORR:
+		err = s.PartiallyBlindSchnorr(req, stream)
+	case pb.SchemaType_ANONCREDS_ISSUE:
+		err = s.AnonCredsIssue(req, stream)
+	case pb.SchemaType_ANONCREDS_SHOW:
+		err = s.AnonCredsShow(req, stream)
+	case pb.SchemaType_REVOCATION_UPDATES:
+		err = s.RevocationUpdates(req, stream)
+	case pb.SchemaType_ABUSE_REPORT:
+		err = s.AbuseReport(req, stream)
 	case pb.SchemaType_PSEUDONYMSYS_ISSUE_CREDENTIAL:
 		err = s.PseudonymsysIssueCredential(req, stream)
 	case pb.SchemaType_PSEUDONYMSYS_TRANSFER_CREDENTIAL:
@@ -216,12 +552,16 @@ func (s *Server) Run(stream pb.Protocol_RunServer) error {
 		err = s.PseudonymsysIssueCredentialEC(curve, req, stream)
 	case pb.SchemaType_PSEUDONYMSYS_TRANSFER_CREDENTIAL_EC:
 		err = s.PseudonymsysTransferCredentialEC(curve, req, stream)
+	case pb.SchemaType_PSEUDONYMSYS_RATE_LIMIT:
+		err = s.PseudonymsysRateLimit(req, stream)
 	case pb.SchemaType_QR:
 		group := config.LoadGroup("pseudonymsys")
 		err = s.QR(req, group, stream)
 	case pb.SchemaType_QNR:
 		qr := config.LoadQR("qrsmall") // only for testing
 		err = s.QNR(req, qr, stream)
+	case pb.SchemaType_EXTENSION:
+		err = s.Extension(req, stream)
 	}
 
 	if err != nil {
@@ -229,6 +569,9 @@ func (s *Server) Run(stream pb.Protocol_RunServer) error {
 		return fmt.Errorf("FAIL: %v", err)
 	}
 
+	if s.usage != nil {
+		s.usage.Record(reqSchemaTypeStr, time.Now())
+	}
 	s.logger.Notice("RPC finished successfully")
 	return nil
 }
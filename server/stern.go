@@ -0,0 +1,84 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/stern"
+	pb "github.com/xlab-si/emmy/protobuf"
+)
+
+// Stern verifies the client's identification with Stern's (post-quantum) protocol. The client
+// sends the seed of the parity-check matrix together with its public key.
+func (s *Server) Stern(req *pb.Message, stream pb.Protocol_RunServer) error {
+	data := req.GetSternProofRandomData()
+	if data == nil {
+		return s.send(&pb.Message{ProtocolError: "Stern proof random data expected."}, stream)
+	}
+	params, err := stern.NewParamsFromSeed(data.ParamsSeed)
+	if err != nil {
+		return s.send(&pb.Message{ProtocolError: "Invalid Stern parameters."}, stream)
+	}
+
+	commitments := make([]*stern.SternCommitment, len(data.Commitments))
+	for i, c := range data.Commitments {
+		commitments[i] = &stern.SternCommitment{C1: c.C1, C2: c.C2, C3: c.C3}
+	}
+	verifier := stern.NewSternVerifier(params, data.PublicKey)
+	if err := verifier.SetSecurity(s.proofSecurity(pb.SchemaType_STERN)); err != nil {
+		return err
+	}
+	challenges, err := verifier.GetChallenges(commitments)
+	if err != nil {
+		return s.send(&pb.Message{ProtocolError: err.Error()}, stream)
+	}
+	ints := make([]int32, len(challenges))
+	for i, b := range challenges {
+		ints[i] = int32(b)
+	}
+	resp := &pb.Message{
+		Content: &pb.Message_RepeatedInt{&pb.RepeatedInt{Ints: ints}},
+	}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err = s.receive(stream)
+	if err != nil {
+		return err
+	}
+	proofData := req.GetSternProofData()
+	if proofData == nil {
+		return s.send(&pb.Message{ProtocolError: "Stern proof data expected."}, stream)
+	}
+	responses := make([]*stern.SternResponse, len(proofData.Responses))
+	for i, r := range proofData.Responses {
+		responses[i] = &stern.SternResponse{
+			SigmaSeed: r.SigmaSeed,
+			YSeed:     r.YSeed,
+			Y:         r.Y,
+			E:         r.E,
+			Salts:     r.Salts,
+		}
+	}
+	valid := verifier.Verify(responses)
+
+	resp = &pb.Message{
+		Content: &pb.Message_Status{&pb.Status{Success: valid}},
+	}
+	return s.send(resp, stream)
+}
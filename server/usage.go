@@ -0,0 +1,53 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"github.com/xlab-si/emmy/stats"
+	"net/http"
+	"time"
+)
+
+// SetUsageStats sets the statistics where the finished sessions are counted. If stats is nil,
+// sessions are not counted.
+func (s *Server) SetUsageStats(stats *stats.UsageStats) {
+	s.usage = stats
+}
+
+// GetUsageStats returns the statistics of the finished sessions. Only the noisy counts
+// (see stats.UsageStats) are to be reported to the operators.
+func (s *Server) GetUsageStats() *stats.UsageStats {
+	return s.usage
+}
+
+// serveUsage writes the report of the usage statistics as JSON.
+func (s *Server) serveUsage(w http.ResponseWriter, r *http.Request) {
+	if s.usage == nil {
+		http.Error(w, "Usage statistics are disabled.", http.StatusNotFound)
+		return
+	}
+	report, err := s.usage.Report(time.Now())
+	if err != nil {
+		s.logger.Errorf("Usage statistics failed: %v", err)
+		http.Error(w, "Usage statistics are not available.", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package stats reports aggregate usage of the server with differential privacy. Exact
+// per-schema counts of sessions at a fine granularity form a side-channel against
+// anonymous users (a verifier which knows when a user presented a credential could link
+// the presentation to other observations), thus only noisy counts are released.
+package stats
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// retention is how long the released counts are kept.
+const retention = 7 * 24 * time.Hour
+
+// HourlyUsage is the (noisy) number of sessions of the schema in the hour starting at Hour.
+type HourlyUsage struct {
+	Hour   time.Time
+	Schema string
+	Count  int64
+}
+
+// UsageStats counts the sessions per schema per hour and releases the counts with
+// the Laplace mechanism. Each session changes one count by one, thus adding noise
+// Laplace(1/epsilon) gives epsilon-differential privacy for each single session
+// (a user with k sessions within an hour is protected with k*epsilon).
+//
+// The counts of an hour are released only when the hour is over, and they are released
+// for all the schemas (also those with no sessions, as the presence of a count would
+// reveal that the schema was used). The noise is drawn once and the exact counts are
+// discarded afterwards - repeated reports return the same noisy counts, so that
+// the noise cannot be averaged out. It is safe for concurrent use.
+type UsageStats struct {
+	epsilon  float64
+	schemas  []string
+	counts   map[int64]map[string]int64 // exact counts of the hours not yet released
+	released []*HourlyUsage
+	last     int64 // the last released hour
+	sync.Mutex
+}
+
+// NewUsageStats returns the stats of the given schemas, which are released with
+// privacy parameter epsilon (smaller epsilon means more noise).
+func NewUsageStats(epsilon float64, schemas []string) (*UsageStats, error) {
+	if epsilon <= 0 {
+		return nil, fmt.Errorf("epsilon needs to be positive")
+	}
+	s := make([]string, len(schemas))
+	copy(s, schemas)
+	sort.Strings(s)
+	return &UsageStats{
+		epsilon: epsilon,
+		schemas: s,
+		counts:  make(map[int64]map[string]int64),
+	}, nil
+}
+
+// Record counts a session of the schema which took place at time t.
+func (stats *UsageStats) Record(schema string, t time.Time) {
+	stats.Lock()
+	defer stats.Unlock()
+
+	hour := hourOf(t)
+	if stats.last != 0 && hour <= stats.last {
+		// the hour has already been released
+		return
+	}
+	if stats.counts[hour] == nil {
+		stats.counts[hour] = make(map[string]int64)
+	}
+	stats.counts[hour][schema]++
+}
+
+// Report returns the noisy counts of the hours which are over at time now (and are
+// within the retention period), ordered by the hour and the schema.
+func (stats *UsageStats) Report(now time.Time) ([]*HourlyUsage, error) {
+	stats.Lock()
+	defer stats.Unlock()
+
+	current := hourOf(now)
+	oldest := hourOf(now.Add(-retention))
+	hours := make([]int64, 0, len(stats.counts))
+	for hour := range stats.counts {
+		if hour < current {
+			hours = append(hours, hour)
+		}
+	}
+	sort.Slice(hours, func(i, j int) bool { return hours[i] < hours[j] })
+
+	for _, hour := range hours {
+		// hours without sessions between the released ones are released as well
+		start := hour
+		if stats.last != 0 {
+			start = stats.last + 1
+		}
+		if start < oldest {
+			start = oldest
+		}
+		for h := start; h <= hour; h++ {
+			if err := stats.release(h); err != nil {
+				return nil, err
+			}
+		}
+		delete(stats.counts, hour)
+		stats.last = hour
+	}
+
+	i := 0
+	for i < len(stats.released) && stats.released[i].Hour.Unix()/3600 < oldest {
+		i++
+	}
+	stats.released = stats.released[i:]
+
+	report := make([]*HourlyUsage, len(stats.released))
+	copy(report, stats.released)
+	return report, nil
+}
+
+// release adds noise to the counts of the hour.
+func (stats *UsageStats) release(hour int64) error {
+	for _, schema := range stats.schemas {
+		noise, err := laplace(1 / stats.epsilon)
+		if err != nil {
+			return err
+		}
+		count := int64(math.Max(0, math.Floor(float64(stats.counts[hour][schema])+noise+0.5)))
+		stats.released = append(stats.released, &HourlyUsage{
+			Hour:   time.Unix(hour*3600, 0).UTC(),
+			Schema: schema,
+			Count:  count,
+		})
+	}
+	return nil
+}
+
+func hourOf(t time.Time) int64 {
+	return t.Unix() / 3600
+}
+
+// laplace returns a sample from the Laplace distribution with mean 0 and scale b. It uses
+// crypto/rand, as predictable noise could be subtracted from the released counts.
+func laplace(b float64) (float64, error) {
+	var buf [8]byte
+	for {
+		if _, err := rand.Read(buf[:]); err != nil {
+			return 0, err
+		}
+		// uniform from (-1/2, 1/2) with 53 bits of precision
+		u := float64(binary.BigEndian.Uint64(buf[:])>>11)/(1<<53) - 0.5
+		if u == -0.5 {
+			continue
+		}
+		if u < 0 {
+			return b * math.Log(1+2*u), nil
+		}
+		return -b * math.Log(1-2*u), nil
+	}
+}
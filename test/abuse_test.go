@@ -0,0 +1,119 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/revocation"
+	"github.com/xlab-si/emmy/server"
+	"testing"
+)
+
+func newTestAbuseReport() (*revocation.AbuseReport, error) {
+	group := config.LoadGroup("pseudonymsys")
+	h, err := group.GetRandomElement()
+	if err != nil {
+		return nil, err
+	}
+	ticket := pseudonymsys.NewBlacklistTicket(h, group.Exp(h, randomInt(group.Q)))
+	transcriptHash := sha256.Sum256([]byte("transcript"))
+	return revocation.NewAbuseReport(ticket, transcriptHash[:], "spam"), nil
+}
+
+func TestAbuseDesk(t *testing.T) {
+	registry := revocation.NewMemoryRegistry()
+	desk := revocation.NewAbuseDesk(registry, 2)
+	key1, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	key2, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	desk.AddReporter("org1", key1.X, key1.Y)
+	desk.AddReporter("org2", key2.X, key2.Y)
+
+	report, err := newTestAbuseReport()
+	assert.Nil(t, err)
+	assert.Nil(t, report.Sign("org3", key1.D, key1.X, key1.Y))
+	_, err = desk.Submit(report)
+	assert.NotNil(t, err, "Report of unknown reporter should be refused")
+	assert.Nil(t, report.Sign("org2", key1.D, key1.X, key1.Y))
+	_, err = desk.Submit(report)
+	assert.NotNil(t, err, "Report signed with other key should be refused")
+
+	assert.Nil(t, report.Sign("org1", key1.D, key1.X, key1.Y))
+	revoked, err := desk.Submit(report)
+	assert.Nil(t, err)
+	assert.False(t, revoked, "Single report should not revoke the ticket")
+	revoked, err = desk.Submit(report)
+	assert.Nil(t, err)
+	assert.False(t, revoked, "Repeated report should not revoke the ticket")
+	assert.Equal(t, 0, len(desk.Escalations()), "Reports should not be escalated")
+
+	second := revocation.NewAbuseReport(report.Ticket, report.TranscriptHash, "spam")
+	assert.Nil(t, second.Sign("org2", key2.D, key2.X, key2.Y))
+	revoked, err = desk.Submit(second)
+	assert.Nil(t, err)
+	assert.True(t, revoked, "Reports of two reporters should revoke the ticket")
+
+	version, _ := registry.Version()
+	assert.Equal(t, uint64(1), version, "Ticket should be revoked")
+	escalations := desk.Escalations()
+	if len(escalations) != 1 {
+		t.Fatalf("One escalation expected, got %d", len(escalations))
+	}
+	assert.Equal(t, 2, len(escalations[0].Reports))
+	assert.Equal(t, "org1", escalations[0].Reports[0].Reporter)
+}
+
+func TestGRPC_AbuseReport(t *testing.T) {
+	registry := revocation.NewMemoryRegistry()
+	desk := revocation.NewAbuseDesk(registry, 1)
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	desk.AddReporter("org1", key.X, key.Y)
+
+	logger, _ := log.NewStdoutLogger("abuseServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer(logger)
+	assert.Nil(t, err)
+	srv.SetAbuseDesk(desk)
+	address, stop := startTestServer(t, srv)
+	defer stop()
+
+	conn, err := client.GetConnection(address, "testdata/server.pem", false)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	c, err := client.NewAbuseReportClient(conn, "org1", key.D, key.X, key.Y)
+	assert.Nil(t, err)
+	report, err := newTestAbuseReport()
+	assert.Nil(t, err)
+	assert.Nil(t, c.Report(report), "Report should be accepted")
+	version, _ := registry.Version()
+	assert.Equal(t, uint64(1), version, "Reported ticket should be revoked")
+
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c, err = client.NewAbuseReportClient(conn, "org1", other.D, other.X, other.Y)
+	assert.Nil(t, err)
+	report, err = newTestAbuseReport()
+	assert.Nil(t, err)
+	assert.NotNil(t, c.Report(report), "Report with wrong key should be refused")
+}
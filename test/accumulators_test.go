@@ -0,0 +1,123 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/accumulators"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+	"testing"
+)
+
+func TestRSAAccumulator(t *testing.T) {
+	acc, err := accumulators.NewRSAAccumulator(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := acc.Params
+
+	var elements []*big.Int
+	for i := 0; i < 3; i++ {
+		x, _ := accumulators.HashToPrime([]byte{byte(i)}, 128)
+		elements = append(elements, x)
+		assert.Nil(t, acc.Add(x), "adding a prime should succeed")
+	}
+	assert.NotNil(t, acc.Add(elements[0]), "adding an accumulated element should fail")
+	assert.NotNil(t, acc.Add(big.NewInt(15)), "adding a composite should fail")
+
+	x, _ := accumulators.HashToPrime([]byte("not revoked"), 128)
+	witness, err := acc.GetNonMembershipWitness(x)
+	assert.Nil(t, err, "should be able to get the witness for a non-accumulated element")
+	assert.True(t, accumulators.VerifyNonMembershipWitness(params, acc.Value, x, witness),
+		"non-membership witness should be valid")
+	_, err = acc.GetNonMembershipWitness(elements[1])
+	assert.NotNil(t, err, "there should be no witness for an accumulated element")
+
+	rx := randomInt(params.RandomnessBound())
+	proved, err := accumulators.ProveNonMembership(params, acc.Value, x, rx, witness)
+	assert.Nil(t, err)
+	assert.True(t, proved, "non-membership proof should succeed")
+
+	// the witness for x is not valid for the accumulated element
+	proved, _ = accumulators.ProveNonMembership(params, acc.Value, elements[1], rx, witness)
+	assert.False(t, proved, "non-membership proof for an accumulated element should fail")
+
+	assert.Nil(t, acc.Add(x))
+	assert.False(t, accumulators.VerifyNonMembershipWitness(params, acc.Value, x, witness),
+		"witness should be invalid after x is accumulated")
+	proved, _ = accumulators.ProveNonMembership(params, acc.Value, x, rx, witness)
+	assert.False(t, proved, "non-membership proof for an accumulated element should fail")
+
+	assert.Nil(t, acc.Delete(x))
+	witness, err = acc.GetNonMembershipWitness(x)
+	assert.Nil(t, err, "should be able to get the witness after the element is deleted")
+	proved, _ = accumulators.ProveNonMembership(params, acc.Value, x, rx, witness)
+	assert.True(t, proved, "non-membership proof should succeed after the element is deleted")
+}
+
+func TestPseudonymsysRevocation(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	acc, err := accumulators.NewRSAAccumulator(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newCredential := func() *pseudonymsys.Credential {
+		elements := make([]*big.Int, 4)
+		for i := range elements {
+			elements[i], err = group.GetRandomElement()
+			assert.Nil(t, err)
+		}
+		return pseudonymsys.NewCredential(elements[0], elements[1], elements[2], elements[3],
+			nil, nil)
+	}
+	transfer := func(credential *pseudonymsys.Credential) bool {
+		handle, _ := pseudonymsys.CredentialRevocationHandle(credential)
+		witness, err := acc.GetNonMembershipWitness(handle)
+		if err != nil {
+			return false
+		}
+		prover, _ := pseudonymsys.NewCredentialNonRevocationProver(acc.Params, acc.Value,
+			credential, witness)
+		verifier, _ := pseudonymsys.NewCredentialNonRevocationVerifier(acc.Params, acc.Value,
+			credential)
+		proofRandomData, err := prover.GetProofRandomData()
+		if err != nil {
+			return false
+		}
+		if err := verifier.SetProofRandomData(proofRandomData); err != nil {
+			return false
+		}
+		challenge, err := verifier.GetChallenge()
+		if err != nil {
+			return false
+		}
+		return verifier.Verify(prover.GetProofData(challenge))
+	}
+
+	credential1 := newCredential()
+	credential2 := newCredential()
+	assert.True(t, transfer(credential1), "credential which is not revoked should be accepted")
+
+	handle, err := pseudonymsys.CredentialRevocationHandle(credential1)
+	assert.Nil(t, err)
+	assert.Nil(t, acc.Add(handle))
+	assert.False(t, transfer(credential1), "revoked credential should be rejected")
+	assert.True(t, transfer(credential2), "credential which is not revoked should be accepted")
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/anoncreds"
+	"math/big"
+	"testing"
+)
+
+func TestAnonCredsDelegation(t *testing.T) {
+	params := getTestDFParams(t)
+	attributeNames := []string{"name", "role"}
+	root, err := anoncreds.NewDelegator(attributeNames, params)
+	assert.Nil(t, err)
+	delegator1, err := anoncreds.NewDelegator(attributeNames, params)
+	assert.Nil(t, err)
+	delegator2, err := anoncreds.NewDelegator(attributeNames, params)
+	assert.Nil(t, err)
+	other, err := anoncreds.NewDelegator(attributeNames, params)
+	assert.Nil(t, err)
+
+	cert1, err := root.Delegate(delegator1)
+	assert.Nil(t, err)
+	cert2, err := delegator1.Delegate(delegator2)
+	assert.Nil(t, err)
+	cert3, err := root.Delegate(other)
+	assert.Nil(t, err)
+	// certificates can be given in any order
+	certificates := []*anoncreds.DelegationCertificate{cert2, cert3, cert1}
+	rootPubKey := root.Issuer.GetPublicKey()
+	keys, err := anoncreds.VerifyDelegation(rootPubKey, root.GetSigningPubKey(), certificates)
+	assert.Nil(t, err, "Delegation should be valid")
+	assert.Equal(t, 4, len(keys))
+
+	_, err = anoncreds.VerifyDelegation(rootPubKey, root.GetSigningPubKey(),
+		[]*anoncreds.DelegationCertificate{cert2, cert3})
+	assert.NotNil(t, err, "Delegation with a missing link should not be valid")
+	_, err = anoncreds.VerifyDelegation(other.Issuer.GetPublicKey(), other.GetSigningPubKey(),
+		certificates)
+	assert.NotNil(t, err, "Delegation from a wrong root should not be valid")
+	differentKey, err := anoncreds.NewDelegator([]string{"name"}, params)
+	assert.Nil(t, err)
+	_, err = root.Delegate(differentKey)
+	assert.NotNil(t, err, "Delegation to a key with other attributes should be rejected")
+
+	// a level-2 credential issued by delegator2
+	holder, err := anoncreds.NewHolder()
+	assert.Nil(t, err)
+	attributes := map[string]*big.Int{
+		"name": new(big.Int).SetBytes([]byte("Ana Novak")),
+		"role": big.NewInt(2),
+	}
+	pubKey := delegator2.Issuer.GetPublicKey()
+	credential, err := anoncreds.IssueCredential(delegator2.Issuer, holder, attributes)
+	assert.Nil(t, err)
+
+	request := &anoncreds.PresentationRequest{Disclosed: []string{"role"}}
+	proved, err := anoncreds.ProveDelegatedCredential(keys, pubKey, credential, request)
+	assert.Nil(t, err)
+	assert.True(t, proved, "Credential of an authorized issuer should be accepted")
+
+	// the proof does not depend on which of the authorized issuers issued the credential
+	rootCredential, err := anoncreds.IssueCredential(root.Issuer, holder, attributes)
+	assert.Nil(t, err)
+	proved, err = anoncreds.ProveDelegatedCredential(keys, rootPubKey, rootCredential, request)
+	assert.Nil(t, err)
+	assert.True(t, proved, "Credential of the root should be accepted")
+
+	// a credential of an issuer without a valid chain to the root
+	outsider, err := anoncreds.NewDelegator(attributeNames, params)
+	assert.Nil(t, err)
+	outsiderCredential, err := anoncreds.IssueCredential(outsider.Issuer, holder, attributes)
+	assert.Nil(t, err)
+	_, err = anoncreds.ProveDelegatedCredential(keys, outsider.Issuer.GetPublicKey(),
+		outsiderCredential, request)
+	assert.NotNil(t, err, "Prover should need the key of an authorized issuer")
+	forged := append([]*anoncreds.PublicKey{}, keys...)
+	forged[1] = outsider.Issuer.GetPublicKey()
+	prover, err := anoncreds.NewDelegatedPresentationProver(forged,
+		outsider.Issuer.GetPublicKey(), outsiderCredential, request)
+	assert.Nil(t, err)
+	verifier, err := anoncreds.NewDelegatedPresentationVerifier(keys, request,
+		map[string]*big.Int{"role": big.NewInt(2)})
+	assert.Nil(t, err)
+	proofRandomData, err := prover.GetProofRandomData()
+	assert.Nil(t, err)
+	challenge, err := verifier.GetChallenge(proofRandomData)
+	assert.Nil(t, err)
+	assert.False(t, verifier.Verify(prover.GetProofData(challenge)),
+		"Credential of an issuer without a valid chain should be rejected")
+
+	// wrong disclosed value
+	prover, err = anoncreds.NewDelegatedPresentationProver(keys, pubKey, credential, request)
+	assert.Nil(t, err)
+	verifier, err = anoncreds.NewDelegatedPresentationVerifier(keys, request,
+		map[string]*big.Int{"role": big.NewInt(1)})
+	assert.Nil(t, err)
+	proofRandomData, err = prover.GetProofRandomData()
+	assert.Nil(t, err)
+	challenge, err = verifier.GetChallenge(proofRandomData)
+	assert.Nil(t, err)
+	assert.False(t, verifier.Verify(prover.GetProofData(challenge)),
+		"Presentation with a wrong disclosed value should be rejected")
+
+	_, err = anoncreds.NewDelegatedPresentationProver(keys, pubKey, credential,
+		&anoncreds.PresentationRequest{Predicates: []*anoncreds.Predicate{
+			{Attribute: "role", Type: anoncreds.GreaterOrEqual, Bound: big.NewInt(1)},
+		}})
+	assert.NotNil(t, err, "Predicates should not be supported")
+}
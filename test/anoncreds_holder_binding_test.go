@@ -0,0 +1,92 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/anoncreds"
+	"math/big"
+	"testing"
+)
+
+func TestAnonCredsHolderBinding(t *testing.T) {
+	issuer, err := anoncreds.NewDeviceBoundIssuer([]string{"name", "age"}, getTestDFParams(t))
+	assert.Nil(t, err)
+	pubKey := issuer.GetPublicKey()
+	holder, err := anoncreds.NewHolder()
+	assert.Nil(t, err)
+	device, err := anoncreds.NewDevice()
+	assert.Nil(t, err)
+	attributes := map[string]*big.Int{
+		"name": new(big.Int).SetBytes([]byte("Ana Novak")),
+		"age":  big.NewInt(30),
+	}
+
+	_, err = anoncreds.IssueCredential(issuer, holder, attributes)
+	assert.NotNil(t, err, "Device bound credential should not be issued without the device")
+	credential, err := anoncreds.IssueBoundCredential(issuer, holder, device, attributes)
+	assert.Nil(t, err, "Device bound credential should be issued")
+
+	request := &anoncreds.PresentationRequest{
+		Disclosed: []string{"name"},
+		Predicates: []*anoncreds.Predicate{
+			{Attribute: "age", Type: anoncreds.GreaterOrEqual, Bound: big.NewInt(18)},
+		},
+	}
+	proved, err := anoncreds.ProveBoundCredential(pubKey, credential, device, request)
+	assert.Nil(t, err)
+	assert.True(t, proved, "Holder should show the credential with the device")
+
+	// the credential alone is not enough to show it
+	_, err = anoncreds.ProveCredential(pubKey, credential, request)
+	assert.NotNil(t, err, "Device bound credential should not be shown without the device")
+	otherDevice, err := anoncreds.NewDevice()
+	assert.Nil(t, err)
+	proved, err = anoncreds.ProveBoundCredential(pubKey, credential, otherDevice, request)
+	assert.Nil(t, err)
+	assert.False(t, proved, "Credential should not be shown from another device")
+
+	// the response for the device secret cannot be left out of the presentation
+	prover, err := anoncreds.NewBoundPresentationProver(pubKey, credential, device, request)
+	assert.Nil(t, err)
+	verifier, err := anoncreds.NewPresentationVerifier(pubKey, request,
+		map[string]*big.Int{"name": attributes["name"]})
+	assert.Nil(t, err)
+	proofRandomData, err := prover.GetProofRandomData()
+	assert.Nil(t, err)
+	challenge, err := verifier.GetChallenge(proofRandomData)
+	assert.Nil(t, err)
+	proofData := prover.GetProofData(challenge)
+	delete(proofData.ZM, len(pubKey.Attributes)+1)
+	assert.False(t, verifier.Verify(proofData),
+		"Presentation without the proof of the device secret should be rejected")
+
+	// the issuer needs to verify the proof of knowledge of the device secret
+	receiver, err := anoncreds.NewBoundCredentialReceiver(pubKey, holder, device)
+	assert.Nil(t, err)
+	credentialIssuer, err := anoncreds.NewCredentialIssuer(issuer, attributes)
+	assert.Nil(t, err)
+	U, tValue, err := receiver.GetProofRandomData()
+	assert.Nil(t, err)
+	challenge, err = credentialIssuer.GetChallenge(U, tValue)
+	assert.Nil(t, err)
+	zS1, zM, _ := receiver.GetBoundProofData(challenge)
+	_, _, _, err = credentialIssuer.IssueCredential(zS1, zM)
+	assert.NotNil(t, err, "Credential should not be issued without the proof of the device secret")
+	_, _, _, err = credentialIssuer.IssueBoundCredential(zS1, zM, big.NewInt(42))
+	assert.NotNil(t, err, "Credential should not be issued with a wrong device response")
+}
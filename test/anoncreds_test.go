@@ -0,0 +1,196 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/anoncreds"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+	"math/big"
+	"testing"
+)
+
+func TestAnonCreds(t *testing.T) {
+	issuer, err := anoncreds.NewIssuer([]string{"name", "birth_year", "country"},
+		getTestDFParams(t))
+	assert.Nil(t, err)
+	pubKey := issuer.GetPublicKey()
+	holder, err := anoncreds.NewHolder()
+	assert.Nil(t, err)
+	attributes := map[string]*big.Int{
+		"name":       new(big.Int).SetBytes([]byte("Ana Novak")),
+		"birth_year": big.NewInt(1990),
+		"country":    big.NewInt(386),
+	}
+	credential, err := anoncreds.IssueCredential(issuer, holder, attributes)
+	assert.Nil(t, err)
+	assert.Equal(t, attributes, credential.Attributes)
+
+	_, err = anoncreds.NewCredentialIssuer(issuer, map[string]*big.Int{"name": big.NewInt(1)})
+	assert.NotNil(t, err, "all attributes should be required")
+
+	request := &anoncreds.PresentationRequest{
+		Disclosed: []string{"country"},
+		Predicates: []*anoncreds.Predicate{
+			{Attribute: "birth_year", Type: anoncreds.LessOrEqual, Bound: big.NewInt(2000)},
+			{Attribute: "birth_year", Type: anoncreds.GreaterOrEqual, Bound: big.NewInt(1900)},
+		},
+	}
+	proved, err := anoncreds.ProveCredential(pubKey, credential, request)
+	assert.Nil(t, err)
+	assert.True(t, proved, "presentation should be verified")
+
+	request.Predicates[0].Bound = big.NewInt(1989)
+	_, err = anoncreds.ProveCredential(pubKey, credential, request)
+	assert.NotNil(t, err, "predicate which does not hold should not be proved")
+
+	request = &anoncreds.PresentationRequest{Disclosed: []string{"birth_year"},
+		Predicates: []*anoncreds.Predicate{
+			{Attribute: "birth_year", Type: anoncreds.GreaterOrEqual, Bound: big.NewInt(0)},
+		}}
+	_, err = anoncreds.ProveCredential(pubKey, credential, request)
+	assert.NotNil(t, err, "predicate on a disclosed attribute should be rejected")
+}
+
+func TestAnonCredsPresentationDisclosedValues(t *testing.T) {
+	issuer, err := anoncreds.NewIssuer([]string{"name", "age"}, getTestDFParams(t))
+	assert.Nil(t, err)
+	pubKey := issuer.GetPublicKey()
+	holder, err := anoncreds.NewHolder()
+	assert.Nil(t, err)
+	credential, err := anoncreds.IssueCredential(issuer, holder,
+		map[string]*big.Int{"name": big.NewInt(42), "age": big.NewInt(17)})
+	assert.Nil(t, err)
+
+	request := &anoncreds.PresentationRequest{Disclosed: []string{"name"},
+		Predicates: []*anoncreds.Predicate{
+			{Attribute: "age", Type: anoncreds.LessOrEqual, Bound: big.NewInt(17)},
+		}}
+	prover, err := anoncreds.NewPresentationProver(pubKey, credential, request)
+	assert.Nil(t, err)
+	verifier, err := anoncreds.NewPresentationVerifier(pubKey, request,
+		map[string]*big.Int{"name": big.NewInt(43)})
+	assert.Nil(t, err)
+	proofRandomData, err := prover.GetProofRandomData()
+	assert.Nil(t, err)
+	challenge, err := verifier.GetChallenge(proofRandomData)
+	assert.Nil(t, err)
+	assert.False(t, verifier.Verify(prover.GetProofData(challenge)),
+		"presentation with wrong disclosed value should not be verified")
+
+	// the commitment of the predicate needs to commit to the attribute of the credential
+	prover, err = anoncreds.NewPresentationProver(pubKey, credential, request)
+	assert.Nil(t, err)
+	verifier, err = anoncreds.NewPresentationVerifier(pubKey, request,
+		map[string]*big.Int{"name": big.NewInt(42)})
+	assert.Nil(t, err)
+	proofRandomData, err = prover.GetProofRandomData()
+	assert.Nil(t, err)
+	challenge, err = verifier.GetChallenge(proofRandomData)
+	assert.Nil(t, err)
+	proofData := prover.GetProofData(challenge)
+	proofData.Predicates[0].ZR.Add(proofData.Predicates[0].ZR, big.NewInt(1))
+	assert.False(t, verifier.Verify(proofData),
+		"predicate on a value not linked to the credential should not be verified")
+}
+
+// TestAnonCredsForgedCredential checks that the presentation of a credential which was not
+// issued, but forged from the issuer's public key with e = 1, is rejected.
+func TestAnonCredsForgedCredential(t *testing.T) {
+	issuer, err := anoncreds.NewIssuer([]string{"name", "age"}, getTestDFParams(t))
+	assert.Nil(t, err)
+	pubKey := issuer.GetPublicKey()
+	secret := randomInt(new(big.Int).Lsh(big.NewInt(1), anoncreds.AttributeBitLen))
+	m_Ls := []*big.Int{secret, big.NewInt(42), big.NewInt(17)}
+	forged := forgeCLSignature(pubKey.CL, m_Ls)
+
+	request := &anoncreds.PresentationRequest{Disclosed: []string{"name", "age"}}
+	verifier, err := anoncreds.NewPresentationVerifier(pubKey, request,
+		map[string]*big.Int{"name": big.NewInt(42), "age": big.NewInt(17)})
+	assert.Nil(t, err)
+	possession, err := signatures.NewCLPossessionProver(pubKey.CL, m_Ls, forged, []int{1, 2})
+	assert.Nil(t, err)
+	v, tt := possession.GetProofRandomData()
+	challenge, err := verifier.GetChallenge(&anoncreds.PresentationProofRandomData{V: v, T: tt})
+	assert.Nil(t, err)
+	zE, zS, zM := possession.GetProofData(challenge)
+	assert.False(t, verifier.Verify(&anoncreds.PresentationProofData{ZE: zE, ZS: zS, ZM: zM}),
+		"presentation of a credential with e = 1 should not be verified")
+}
+
+func TestGRPC_AnonCreds(t *testing.T) {
+	issuer, err := anoncreds.NewIssuer([]string{"name", "birth_year"}, getTestDFParams(t))
+	assert.Nil(t, err)
+	pubKey := issuer.GetPublicKey()
+	srv, err := server.NewServer(log.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the server sets the year of birth it knows, the name is taken from the request
+	srv.SetAnonCredsIssuer(issuer, func(requested map[string]*big.Int) (map[string]*big.Int,
+		error) {
+		if requested["name"] == nil {
+			return nil, fmt.Errorf("name is required")
+		}
+		return map[string]*big.Int{
+			"name":       requested["name"],
+			"birth_year": big.NewInt(1990),
+		}, nil
+	})
+	srv.SetAnonCredsVerifier(pubKey, &anoncreds.PresentationRequest{
+		Disclosed: []string{"name"},
+		Predicates: []*anoncreds.Predicate{
+			{Attribute: "birth_year", Type: anoncreds.LessOrEqual, Bound: big.NewInt(2000)},
+		},
+	})
+	address, stop := startTestServer(t, srv)
+	defer stop()
+
+	conn, err := client.GetConnection(address, "testdata/server.pem", false)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	holder, err := anoncreds.NewHolder()
+	assert.Nil(t, err)
+	c, err := client.NewAnonCredsClient(conn, pubKey, holder)
+	assert.Nil(t, err)
+	credential, err := c.ObtainCredential(map[string]*big.Int{
+		"name":       big.NewInt(42),
+		"birth_year": big.NewInt(1900),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(1990), credential.Attributes["birth_year"],
+		"attribute should be set by the server")
+
+	accepted, err := c.ProveCredential(credential)
+	assert.Nil(t, err)
+	assert.True(t, accepted, "presentation should be accepted")
+
+	c.SetRequestPolicy(func(request *anoncreds.PresentationRequest) error {
+		return fmt.Errorf("disclosure of %v is not allowed", request.Disclosed)
+	})
+	_, err = c.ProveCredential(credential)
+	assert.NotNil(t, err, "request refused by the policy should not be fulfilled")
+
+	_, err = c.ObtainCredential(map[string]*big.Int{"birth_year": big.NewInt(1990)})
+	assert.NotNil(t, err, "server should refuse the request")
+}
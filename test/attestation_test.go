@@ -0,0 +1,102 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/attestation"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+	"math/big"
+	"testing"
+)
+
+func TestSimulatedAttestation(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	measurement := []byte("verifier build 1")
+	attestor := attestation.NewSimulatedAttestor(key, measurement)
+	reportData := attestation.ReportData([]byte("nonce"), []byte("transcript"))
+	assert.Equal(t, attestation.ReportDataLen, len(reportData))
+
+	evidence, err := attestor.Attest(reportData)
+	assert.Nil(t, err)
+	verifier := attestation.NewSimulatedVerifier(&key.PublicKey, measurement)
+	assert.Nil(t, verifier.Verify(attestor.Format(), evidence, reportData))
+
+	assert.NotNil(t, verifier.Verify("sgx", evidence, reportData),
+		"evidence of unknown format should not be accepted")
+	assert.NotNil(t, verifier.Verify(attestor.Format(), evidence,
+		attestation.ReportData([]byte("nonce"), nil)),
+		"evidence for another session should not be accepted")
+	other := attestation.NewSimulatedVerifier(&key.PublicKey, []byte("verifier build 2"))
+	assert.NotNil(t, other.Verify(attestor.Format(), evidence, reportData),
+		"evidence with unexpected measurement should not be accepted")
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	other = attestation.NewSimulatedVerifier(&otherKey.PublicKey, measurement)
+	assert.NotNil(t, other.Verify(attestor.Format(), evidence, reportData),
+		"evidence signed by another key should not be accepted")
+}
+
+func TestGRPC_Attestation(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	measurement := []byte("verifier build 1")
+
+	logger, _ := log.NewStdoutLogger("attestedServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer(logger)
+	assert.Nil(t, err)
+	srv.SetAttestor(attestation.NewSimulatedAttestor(key, measurement))
+	address, stop := startTestServer(t, srv)
+	defer stop()
+
+	conn, err := client.GetConnection(address, "testdata/server.pem", false)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	group := config.LoadGroup("schnorr")
+	secret := big.NewInt(345345345334)
+	verifier := attestation.NewSimulatedVerifier(&key.PublicKey, measurement)
+	c, err := client.NewSchnorrClient(conn, group, secret, client.WithAttestation(verifier))
+	assert.Nil(t, err)
+	assert.Nil(t, c.Run(), "attested session should finish without errors")
+
+	c, err = client.NewSchnorrClient(conn, group, secret, client.WithAttestation(verifier),
+		client.WithHybridKEM())
+	assert.Nil(t, err)
+	assert.Nil(t, c.Run(), "attested encrypted session should finish without errors")
+
+	other := attestation.NewSimulatedVerifier(&key.PublicKey, []byte("verifier build 2"))
+	c, err = client.NewSchnorrClient(conn, group, secret, client.WithAttestation(other))
+	assert.Nil(t, err)
+	assert.NotNil(t, c.Run(), "session with unexpected measurement should fail")
+
+	c, err = client.NewSchnorrClient(testGrpcClientConn, group, secret,
+		client.WithAttestation(verifier))
+	assert.Nil(t, err)
+	assert.NotNil(t, c.Run(), "session with server without attestor should fail")
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/signatures/bbsplus"
+	"math/big"
+	"testing"
+)
+
+func bbsPlusMessages() []*big.Int {
+	return []*big.Int{
+		bbsplus.MessageFromBytes([]byte("Alice")),
+		bbsplus.MessageFromBytes([]byte("1990-01-01")),
+		bbsplus.MessageFromBytes([]byte("SI")),
+		big.NewInt(42),
+	}
+}
+
+func TestBBSPlus(t *testing.T) {
+	messages := bbsPlusMessages()
+	signer, err := bbsplus.NewSigner(len(messages))
+	assert.Nil(t, err)
+	pubKey := signer.GetPubKey()
+	signature, err := signer.Sign(messages)
+	assert.Nil(t, err)
+	assert.True(t, bbsplus.Verify(pubKey, messages, signature), "signature should be verified")
+
+	changed := bbsPlusMessages()
+	changed[3] = big.NewInt(43)
+	assert.False(t, bbsplus.Verify(pubKey, changed, signature),
+		"signature of other messages should not be verified")
+	other, err := bbsplus.NewSigner(len(messages))
+	assert.Nil(t, err)
+	assert.False(t, bbsplus.Verify(other.GetPubKey(), messages, signature),
+		"signature should not be verified with another key")
+
+	_, err = signer.Sign(messages[:3])
+	assert.NotNil(t, err, "the number of messages should match the key")
+
+	// the generators are derived deterministically
+	assert.Equal(t, bbsplus.NewParams(2).H[1].Marshal(), pubKey.Params.H[1].Marshal())
+}
+
+func TestBBSPlusSelectiveDisclosure(t *testing.T) {
+	messages := bbsPlusMessages()
+	signer, err := bbsplus.NewSigner(len(messages))
+	assert.Nil(t, err)
+	pubKey := signer.GetPubKey()
+	signature, err := signer.Sign(messages)
+	assert.Nil(t, err)
+
+	// interactive proof disclosing the country
+	prover, err := bbsplus.NewProver(pubKey, messages, signature, []int{2})
+	assert.Nil(t, err)
+	verifier, err := bbsplus.NewVerifier(pubKey, map[int]*big.Int{2: messages[2]})
+	assert.Nil(t, err)
+	data, err := prover.GetProofRandomData()
+	assert.Nil(t, err)
+	challenge, err := verifier.GetChallenge(data)
+	assert.Nil(t, err)
+	assert.True(t, verifier.Verify(prover.GetProofData(challenge)),
+		"proof of possession should be verified")
+
+	// the randomized signatures of two proofs differ
+	data2, err := prover.GetProofRandomData()
+	assert.Nil(t, err)
+	assert.NotEqual(t, data.APrime.Marshal(), data2.APrime.Marshal())
+
+	// a wrong disclosed value is detected
+	verifier, err = bbsplus.NewVerifier(pubKey, map[int]*big.Int{2: big.NewInt(1)})
+	assert.Nil(t, err)
+	challenge, err = verifier.GetChallenge(data2)
+	assert.Nil(t, err)
+	assert.False(t, verifier.Verify(prover.GetProofData(challenge)),
+		"proof with a wrong disclosed message should not be verified")
+
+	nonce := []byte("verifier nonce")
+	disclosed := map[int]*big.Int{0: messages[0], 3: messages[3]}
+	proof, err := bbsplus.ProveSelectiveDisclosure(pubKey, messages, signature, []int{3, 0},
+		nonce)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(proof.Responses.M), "only hidden messages should be in the proof")
+	assert.True(t, bbsplus.VerifySelectiveDisclosure(pubKey, disclosed, nonce, proof),
+		"non-interactive proof should be verified")
+	assert.False(t, bbsplus.VerifySelectiveDisclosure(pubKey, disclosed, []byte("other"),
+		proof), "proof should be bound to the nonce")
+	assert.False(t, bbsplus.VerifySelectiveDisclosure(pubKey,
+		map[int]*big.Int{0: messages[0], 3: big.NewInt(7)}, nonce, proof),
+		"proof with a wrong disclosed message should not be verified")
+	assert.False(t, bbsplus.VerifySelectiveDisclosure(pubKey, map[int]*big.Int{0: messages[0]},
+		nonce, proof), "proof should not be verified with other disclosed indices")
+
+	// all messages can be hidden
+	proof, err = bbsplus.ProveSelectiveDisclosure(pubKey, messages, signature, nil, nonce)
+	assert.Nil(t, err)
+	assert.True(t, bbsplus.VerifySelectiveDisclosure(pubKey, map[int]*big.Int{}, nonce, proof))
+
+	_, err = bbsplus.NewProver(pubKey, messages, signature, []int{4})
+	assert.NotNil(t, err, "index out of range should be rejected")
+	_, err = bbsplus.NewProver(pubKey, messages, signature, []int{1, 1})
+	assert.NotNil(t, err, "index disclosed twice should be rejected")
+	_, err = bbsplus.NewProver(pubKey, bbsPlusMessages()[:3], signature, nil)
+	assert.NotNil(t, err, "invalid signature should be rejected")
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/signatures/blindschnorr"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+	"math/big"
+	"testing"
+)
+
+func TestBlindSchnorr(t *testing.T) {
+	signer, err := blindschnorr.NewSigner(config.LoadGroup("schnorr"))
+	assert.Nil(t, err)
+	publicKey := signer.GetPublicKey()
+	message := []byte("token serial number")
+
+	session := signer.NewSession()
+	user := blindschnorr.NewUser(publicKey, message)
+	r, err := session.GetCommitment()
+	assert.Nil(t, err)
+	c, err := user.Blind(r)
+	assert.Nil(t, err)
+	s, err := session.GetResponse(c)
+	assert.Nil(t, err)
+	signature, err := user.Unblind(s)
+	assert.Nil(t, err)
+
+	assert.True(t, blindschnorr.Verify(publicKey, message, signature),
+		"blind signature should be verified")
+	assert.False(t, blindschnorr.Verify(publicKey, []byte("another message"), signature),
+		"signature of another message should not be verified")
+	// the signer does not see the challenge of the signature
+	assert.NotEqual(t, c, signature.C)
+
+	_, err = session.GetResponse(c)
+	assert.NotNil(t, err, "commitment should be used only once")
+
+	// the response is checked before unblinding
+	user = blindschnorr.NewUser(publicKey, message)
+	r, err = signer.NewSession().GetCommitment()
+	assert.Nil(t, err)
+	c, err = user.Blind(r)
+	assert.Nil(t, err)
+	_, err = user.Unblind(new(big.Int).Add(s, big.NewInt(1)))
+	assert.NotNil(t, err, "invalid response should be rejected")
+}
+
+func TestGRPC_BlindSchnorr(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	signer, err := blindschnorr.NewSigner(group)
+	assert.Nil(t, err)
+	srv, err := server.NewServer(log.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.SetBlindSchnorrSigner(signer)
+	address, stop := startTestServer(t, srv)
+	defer stop()
+
+	conn, err := client.GetConnection(address, "testdata/server.pem", false)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	c, err := client.NewBlindSchnorrClient(conn, signer.GetPublicKey())
+	assert.Nil(t, err)
+	message := []byte("token serial number")
+	signature, err := c.ObtainSignature(message)
+	assert.Nil(t, err)
+	assert.True(t, blindschnorr.Verify(signer.GetPublicKey(), message, signature),
+		"blind signature should be verified")
+
+	// the test server does not issue blind signatures
+	c, err = client.NewBlindSchnorrClient(testGrpcClientConn, signer.GetPublicKey())
+	assert.Nil(t, err)
+	_, err = c.ObtainSignature(message)
+	assert.NotNil(t, err, "server without a signer should refuse to sign")
+}
+
+func TestPartiallyBlindSchnorr(t *testing.T) {
+	signer, err := blindschnorr.NewSigner(config.LoadGroup("schnorr"))
+	assert.Nil(t, err)
+	publicKey := signer.GetPublicKey()
+	info := []byte("type=ticket;expires=2027-01-01")
+	message := []byte("token serial number")
+
+	session := signer.NewPartiallyBlindSession(info)
+	user := blindschnorr.NewPartiallyBlindUser(publicKey, info, message)
+	a, b, err := session.GetCommitment()
+	assert.Nil(t, err)
+	e, err := user.Blind(a, b)
+	assert.Nil(t, err)
+	resp, err := session.GetResponse(e)
+	assert.Nil(t, err)
+	signature, err := user.Unblind(resp)
+	assert.Nil(t, err)
+
+	assert.True(t, blindschnorr.VerifyPartiallyBlind(publicKey, message, signature),
+		"partially blind signature should be verified")
+	assert.False(t, blindschnorr.VerifyPartiallyBlind(publicKey, []byte("another message"),
+		signature), "signature of another message should not be verified")
+	signature.Info = []byte("type=ticket;expires=2099-01-01")
+	assert.False(t, blindschnorr.VerifyPartiallyBlind(publicKey, message, signature),
+		"signature with changed public information should not be verified")
+
+	_, err = session.GetResponse(e)
+	assert.NotNil(t, err, "commitment should be used only once")
+
+	// the user cannot obtain a signature with other information than the signer's
+	session = signer.NewPartiallyBlindSession(info)
+	user = blindschnorr.NewPartiallyBlindUser(publicKey, []byte("type=admin"), message)
+	a, b, err = session.GetCommitment()
+	assert.Nil(t, err)
+	e, err = user.Blind(a, b)
+	assert.Nil(t, err)
+	resp, err = session.GetResponse(e)
+	assert.Nil(t, err)
+	_, err = user.Unblind(resp)
+	assert.NotNil(t, err, "response for other information should be rejected")
+}
+
+func TestGRPC_PartiallyBlindSchnorr(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	signer, err := blindschnorr.NewSigner(group)
+	assert.Nil(t, err)
+	srv, err := server.NewServer(log.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the server issues only tickets and sets their expiry date
+	srv.SetPartiallyBlindSchnorrSigner(signer, func(requested []byte) ([]byte, error) {
+		if string(requested) != "ticket" {
+			return nil, fmt.Errorf("only tickets are issued")
+		}
+		return []byte("ticket;expires=2027-01-01"), nil
+	})
+	address, stop := startTestServer(t, srv)
+	defer stop()
+
+	conn, err := client.GetConnection(address, "testdata/server.pem", false)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	c, err := client.NewPartiallyBlindSchnorrClient(conn, signer.GetPublicKey())
+	assert.Nil(t, err)
+	message := []byte("token serial number")
+	signature, err := c.ObtainSignature([]byte("ticket"), message)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("ticket;expires=2027-01-01"), signature.Info)
+	assert.True(t, blindschnorr.VerifyPartiallyBlind(signer.GetPublicKey(), message,
+		signature), "partially blind signature should be verified")
+
+	_, err = c.ObtainSignature([]byte("admin"), message)
+	assert.NotNil(t, err, "server should refuse the information")
+}
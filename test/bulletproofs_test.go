@@ -0,0 +1,94 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/bulletproofs"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+	"testing"
+)
+
+func TestBulletproofs(t *testing.T) {
+	params, err := bulletproofs.NewParams(dlog.P256, 16, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := params.DLog.OrderOfSubgroup
+
+	values := []*big.Int{big.NewInt(0), big.NewInt(18), big.NewInt(40000), big.NewInt(65535)}
+	gammas := make([]*big.Int, len(values))
+	commitments := make([]*types.ECGroupElement, len(values))
+	for i, v := range values {
+		gammas[i] = randomInt(q)
+		commitments[i] = params.Commit(v, gammas[i])
+	}
+
+	// single value
+	proof, err := params.ProveRange(values[1:2], gammas[1:2])
+	assert.Nil(t, err)
+	assert.True(t, params.VerifyRange(commitments[1:2], proof), "Range proof should be verified")
+	assert.Equal(t, 4, len(proof.IPP.L))
+	assert.False(t, params.VerifyRange(commitments[2:3], proof),
+		"Range proof should not be verified for another commitment")
+
+	// aggregated
+	proof, err = params.ProveRange(values, gammas)
+	assert.Nil(t, err)
+	assert.True(t, params.VerifyRange(commitments, proof), "Aggregated range proof should be verified")
+	assert.Equal(t, 6, len(proof.IPP.L))
+	proof.THat.Add(proof.THat, big.NewInt(1))
+	assert.False(t, params.VerifyRange(commitments, proof), "Modified proof should not be verified")
+
+	_, err = params.ProveRange([]*big.Int{big.NewInt(65536)}, gammas[:1])
+	assert.NotNil(t, err, "Value out of range should not be proved")
+	_, err = params.ProveRange(values[:3], gammas[:3])
+	assert.NotNil(t, err, "Number of values needs to be a power of 2")
+}
+
+func TestBulletproofsEncoding(t *testing.T) {
+	params, err := bulletproofs.NewParams(dlog.P256, 16, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := params.DLog.OrderOfSubgroup
+
+	values := []*big.Int{big.NewInt(7), big.NewInt(1000)}
+	gammas := []*big.Int{randomInt(q), randomInt(q)}
+	commitments := []*types.ECGroupElement{params.Commit(values[0], gammas[0]),
+		params.Commit(values[1], gammas[1])}
+
+	proof, err := params.ProveRange(values, gammas)
+	assert.Nil(t, err)
+	data, err := proof.Marshal()
+	assert.Nil(t, err)
+	decoded, err := bulletproofs.UnmarshalRangeProof(data)
+	assert.Nil(t, err)
+	assert.True(t, params.VerifyRange(commitments, decoded), "Decoded proof should be verified")
+
+	assert.True(t, bulletproofs.VerifyRangeProof(data, dlog.P256, 16, commitments),
+		"Encoded proof should be verified")
+	assert.False(t, bulletproofs.VerifyRangeProof(data, dlog.P256, 32, commitments),
+		"Encoded proof should not be verified for another range")
+	assert.False(t, bulletproofs.VerifyRangeProof(data, dlog.P256, 16, commitments[:1]),
+		"Encoded proof should not be verified for another commitments")
+	assert.False(t, bulletproofs.VerifyRangeProof(data[:len(data)-1], dlog.P256, 16, commitments),
+		"Malformed proof should not be verified")
+}
@@ -20,9 +20,14 @@ package test
 import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
 	"github.com/xlab-si/emmy/crypto/commitments"
 	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/groups"
 	"github.com/xlab-si/emmy/crypto/zkp/primitives/commitments"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
 	"testing"
 )
 
@@ -40,7 +45,7 @@ func TestRSABasedCommitment(t *testing.T) {
 		t.Errorf("Error when initializing RSABasedCommitter")
 	}
 
-	a := common.GetRandomInt(committer.Q)
+	a := randomInt(committer.Q)
 	c, _ := committer.GetCommitMsg(a)
 
 	receiver.SetCommitment(c)
@@ -69,3 +74,179 @@ func TestCommitmentMultiplicationProof(t *testing.T) {
 
 	assert.Equal(t, true, proved, "Commitments multiplication proof failed.")
 }
+
+func TestMerkleCommitment(t *testing.T) {
+	var attributes []*big.Int
+	for i := 0; i < 100; i++ {
+		attributes = append(attributes, big.NewInt(int64(i)))
+	}
+
+	committer := commitments.NewMerkleCommitter()
+	root, err := committer.GetCommitMsg(attributes)
+	if err != nil {
+		t.Errorf("Error when committing to attributes: %v", err)
+	}
+
+	receiver := commitments.NewMerkleReceiver()
+	receiver.SetCommitment(root)
+	for _, index := range []int{0, 42, 99} {
+		decommitment, _ := committer.GetDecommitMsg(index)
+		success := receiver.CheckDecommitment(decommitment)
+		assert.Equal(t, true, success, "Merkle commitment does not work correctly")
+	}
+
+	decommitment, _ := committer.GetDecommitMsg(42)
+	decommitment.Attribute = big.NewInt(43)
+	success := receiver.CheckDecommitment(decommitment)
+	assert.Equal(t, false, success, "Merkle commitment accepted a wrong attribute")
+}
+
+func TestMerkleLog(t *testing.T) {
+	log := commitments.NewMerkleLog()
+	entries := make([][]byte, 9)
+	roots := make([][]byte, len(entries)+1)
+	roots[0], _ = log.GetRoot(0)
+	for i := range entries {
+		entries[i] = []byte(fmt.Sprintf("entry %d", i))
+		assert.Equal(t, i, log.Append(entries[i]))
+		roots[i+1], _ = log.GetRoot(i + 1)
+	}
+
+	for size := 1; size <= len(entries); size++ {
+		for index := 0; index < size; index++ {
+			proof, err := log.GetInclusionProof(index, size)
+			assert.Nil(t, err)
+			assert.True(t, commitments.VerifyMerkleLogInclusion(entries[index], index, size,
+				proof, roots[size]), "inclusion proof should be verified")
+			assert.False(t, commitments.VerifyMerkleLogInclusion([]byte("other entry"),
+				index, size, proof, roots[size]),
+				"inclusion proof of a different entry should not be verified")
+		}
+		for oldSize := 0; oldSize <= size; oldSize++ {
+			proof, err := log.GetConsistencyProof(oldSize, size)
+			assert.Nil(t, err)
+			assert.True(t, commitments.VerifyMerkleLogConsistency(oldSize, size, roots[oldSize],
+				roots[size], proof), "consistency proof should be verified")
+		}
+	}
+
+	// a log which changed the third entry cannot prove consistency with the original tree
+	forked := commitments.NewMerkleLog()
+	for i, entry := range entries {
+		if i == 2 {
+			entry = []byte("changed entry")
+		}
+		forked.Append(entry)
+	}
+	forkedRoot, _ := forked.GetRoot(len(entries))
+	for oldSize := 3; oldSize < len(entries); oldSize++ {
+		proof, _ := forked.GetConsistencyProof(oldSize, len(entries))
+		assert.False(t, commitments.VerifyMerkleLogConsistency(oldSize, len(entries),
+			roots[oldSize], forkedRoot, proof),
+			"consistency proof of a changed log should not be verified")
+	}
+
+	_, err := log.GetInclusionProof(len(entries), len(entries))
+	assert.NotNil(t, err, "inclusion proof of a missing entry should not be returned")
+	_, err = log.GetConsistencyProof(2, len(entries)+1)
+	assert.NotNil(t, err, "consistency proof beyond the log should not be returned")
+}
+
+func TestExpTable(t *testing.T) {
+	group := config.LoadGroup("pedersen")
+	table := groups.NewExpTable(group, group.G)
+	for _, e := range []*big.Int{big.NewInt(0), big.NewInt(1), randomInt(group.Q),
+		new(big.Int).Sub(group.Q, big.NewInt(1))} {
+		assert.Equal(t, group.Exp(group.G, e), table.Exp(e), "table exponentiation is wrong")
+	}
+
+	table, err := groups.NewExpTableWithWindow(group, group.G, 7)
+	assert.Nil(t, err)
+	e := randomInt(group.Q)
+	assert.Equal(t, group.Exp(group.G, e), table.Exp(e), "table exponentiation is wrong")
+	_, err = groups.NewExpTableWithWindow(group, group.G, groups.MaxExpTableWindow+1)
+	assert.NotNil(t, err, "too large window should not be accepted")
+}
+
+func TestPedersenReceiverFromParams(t *testing.T) {
+	group := config.LoadGroup("pedersen")
+	params, err := commitments.NewPedersenReceiverParams(group)
+	assert.Nil(t, err)
+
+	for i := 0; i < 2; i++ {
+		receiver := commitments.NewPedersenReceiverFromParams(params)
+		committer := commitments.NewPedersenCommitter(group)
+		committer.SetH(receiver.GetH())
+
+		val := randomInt(group.Q)
+		c, err := committer.GetCommitMsg(val)
+		assert.Nil(t, err)
+		receiver.SetCommitment(c)
+		committedVal, r := committer.GetDecommitMsg()
+		assert.True(t, receiver.CheckDecommitment(r, committedVal), "decommitment should succeed")
+		assert.False(t, receiver.CheckDecommitment(r, new(big.Int).Add(committedVal,
+			big.NewInt(1))), "decommitment to another value should fail")
+	}
+}
+
+func TestPedersenECInvalidH(t *testing.T) {
+	committer := commitments.NewPedersenECCommitter(dlog.P256)
+	committer.SetH(types.NewECGroupElementInfinity())
+	_, err := committer.GetCommitMsg(big.NewInt(7))
+	assert.NotNil(t, err, "commitment with h at infinity would not hide the value")
+
+	committer.SetH(types.NewECGroupElement(big.NewInt(1), big.NewInt(1)))
+	_, err = committer.GetCommitMsg(big.NewInt(7))
+	assert.NotNil(t, err, "h needs to be on the curve")
+
+	receiver, err := commitments.NewPedersenECReceiver(dlog.P256)
+	assert.Nil(t, err)
+	committer.SetH(receiver.GetH())
+	c, err := committer.GetCommitMsg(big.NewInt(7))
+	assert.Nil(t, err)
+	receiver.SetCommitment(c)
+	val, r := committer.GetDecommitMsg()
+	assert.True(t, receiver.CheckDecommitment(r, val), "Pedersen EC decommitment failed")
+	assert.True(t, committer.VerifyTrapdoor(receiver.GetTrapdoor()))
+}
+
+func TestCommitmentEquality(t *testing.T) {
+	group := config.LoadGroup("pedersen")
+	h, err := group.GetRandomElement()
+	assert.Nil(t, err)
+	dLog := dlog.NewECDLog(dlog.P256)
+	hEC := dLog.ExpBaseG(randomInt(dLog.OrderOfSubgroup))
+	dfParams := getTestDFParams(t)
+	l := 64
+	x, err := common.GetRandomIntOfLength(l)
+	assert.Nil(t, err)
+
+	r1 := randomInt(group.Q)
+	c1 := group.Mul(group.Exp(group.G, x), group.Exp(h, r1))
+	r2 := randomInt(dLog.OrderOfSubgroup)
+	c2 := dLog.Mul(dLog.ExpBaseG(x), dLog.Exp(hEC, r2))
+	r3 := randomInt(dfParams.RandomnessBound())
+	c3 := dfParams.Commit(x, r3)
+
+	coms := []commitmentzkp.EqualityCommitment{
+		commitmentzkp.NewPedersenEqualityCommitment(group, h, c1),
+		commitmentzkp.NewPedersenECEqualityCommitment(dlog.P256, hEC, c2),
+		commitmentzkp.NewDamgardFujisakiEqualityCommitment(dfParams, c3),
+	}
+	proved, err := commitmentzkp.ProveCommitmentEquality(coms, x, []*big.Int{r1, r2, r3}, l)
+	assert.Nil(t, err)
+	assert.True(t, proved, "Commitment equality proof failed")
+
+	// the DF commitment contains another value
+	other := new(big.Int).Add(x, big.NewInt(1))
+	coms[2] = commitmentzkp.NewDamgardFujisakiEqualityCommitment(dfParams,
+		dfParams.Commit(other, r3))
+	proved, err = commitmentzkp.ProveCommitmentEquality(coms, x, []*big.Int{r1, r2, r3}, l)
+	assert.Nil(t, err)
+	assert.False(t, proved, "Commitments of different values should not be proved equal")
+
+	_, err = commitmentzkp.NewCommitmentEqualityProver(coms, x, []*big.Int{r1, r2, r3}, 200)
+	assert.NotNil(t, err, "Too large bit length should be rejected")
+	_, err = commitmentzkp.NewCommitmentEqualityProver(coms, x, []*big.Int{r1, r2, r3}, 8)
+	assert.NotNil(t, err, "Value out of range should be rejected")
+}
@@ -18,10 +18,15 @@
 package test
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
 	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/types"
 	"log"
 	"math/big"
+	"sync"
 	"testing"
 )
 
@@ -33,7 +38,10 @@ func TestLCM(t *testing.T) {
 }
 
 func TestGetGermainPrime(t *testing.T) {
-	p := common.GetGermainPrime(512)
+	p, err := common.GetGermainPrime(512)
+	if err != nil {
+		t.Fatalf("Error when generating Germain prime: %v", err)
+	}
 	p1 := new(big.Int).Add(p, p)
 	p1.Add(p1, big.NewInt(1))
 
@@ -107,3 +115,151 @@ func TestGetGeneratorOfZnSubgroup(t *testing.T) {
 
 	assert.Equal(t, g, big.NewInt(1), "not a generator")
 }
+
+func TestRandomIntInvalidArguments(t *testing.T) {
+	_, err := common.GetRandomInt(big.NewInt(0))
+	assert.NotNil(t, err, "GetRandomInt should fail for max = 0")
+	_, err = common.GetRandomInt(big.NewInt(-5))
+	assert.NotNil(t, err, "GetRandomInt should fail for negative max")
+	_, err = common.GetRandomIntFromRange(big.NewInt(10), big.NewInt(10))
+	assert.NotNil(t, err, "GetRandomIntFromRange should fail for empty range")
+	_, err = common.GetRandomIntOfLength(0)
+	assert.NotNil(t, err, "GetRandomIntOfLength should fail for bit length 0")
+	_, err = common.GetRandomZnInvertibleElement(big.NewInt(1))
+	assert.NotNil(t, err, "GetRandomZnInvertibleElement should fail for n = 1")
+
+	r, err := common.GetRandomIntOfLength(256)
+	if err != nil {
+		t.Fatalf("Error in GetRandomIntOfLength: %v", err)
+	}
+	assert.Equal(t, 256, r.BitLen(), "GetRandomIntOfLength returned wrong length")
+}
+
+func TestRandomConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			p, err := common.GetGermainPrime(128)
+			if err == nil && !p.ProbablyPrime(20) {
+				err = fmt.Errorf("p is not prime")
+			}
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			max := big.NewInt(1000)
+			for j := 0; j < 100; j++ {
+				r, err := common.GetRandomInt(max)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if r.Sign() < 0 || r.Cmp(max) >= 0 {
+					errs <- fmt.Errorf("GetRandomInt out of range")
+					return
+				}
+			}
+			errs <- nil
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.Nil(t, err, "concurrent use of random functions failed")
+	}
+}
+
+// naiveMultiExp exponentiates each base separately.
+func naiveMultiExp(bases, exponents []*big.Int, modulus *big.Int) *big.Int {
+	result := big.NewInt(1)
+	for i, base := range bases {
+		b, e := base, exponents[i]
+		if e.Sign() < 0 {
+			b, e = new(big.Int).ModInverse(b, modulus), new(big.Int).Neg(e)
+		}
+		result.Mul(result, new(big.Int).Exp(b, e, modulus))
+		result.Mod(result, modulus)
+	}
+	return result
+}
+
+func randomMultiExpInput(n int) ([]*big.Int, []*big.Int, *big.Int, error) {
+	group := config.LoadGroup("schnorr")
+	bases := make([]*big.Int, n)
+	exponents := make([]*big.Int, n)
+	for i := range bases {
+		r, err := group.GetRandomElement()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		bases[i] = r
+		exponents[i] = randomInt(group.Q)
+	}
+	return bases, exponents, group.P, nil
+}
+
+func TestMultiExp(t *testing.T) {
+	// small inputs use Straus' method, large ones Pippenger's
+	for _, n := range []int{1, 2, 5, 40, 300} {
+		bases, exponents, p, err := randomMultiExpInput(n)
+		assert.Nil(t, err)
+		exponents[0] = big.NewInt(0)
+		if n > 1 {
+			exponents[1] = new(big.Int).Neg(exponents[1])
+			bases[n-1] = bases[0] // repeated base
+		}
+		assert.Equal(t, naiveMultiExp(bases, exponents, p), common.MultiExp(bases, exponents, p),
+			"multi-exponentiation of %d bases is wrong", n)
+	}
+
+	m := big.NewInt(15)
+	assert.Nil(t, common.MultiExp([]*big.Int{big.NewInt(2), big.NewInt(3)},
+		[]*big.Int{big.NewInt(1), big.NewInt(-1)}, m), "3 is not invertible mod 15")
+	assert.Equal(t, big.NewInt(1), common.MultiExp([]*big.Int{big.NewInt(2)},
+		[]*big.Int{big.NewInt(4)}, m))
+
+	dLog := dlog.NewECDLog(dlog.P256)
+	g := dLog.ExpBaseG(big.NewInt(1))
+	points := []*types.ECGroupElement{g, dLog.ExpBaseG(big.NewInt(7)), g,
+		types.NewECGroupElementInfinity()}
+	exponents := []*big.Int{big.NewInt(5), big.NewInt(-2), big.NewInt(3), big.NewInt(9)}
+	assert.True(t, dLog.ExpBaseG(big.NewInt(5-14+3)).Equals(common.MultiExpEC(dLog.Curve,
+		points, exponents)), "EC multi-exponentiation is wrong")
+	assert.True(t, common.MultiExpEC(dLog.Curve, points[:2],
+		[]*big.Int{big.NewInt(7), big.NewInt(-1)}).IsInfinity())
+}
+
+func benchmarkMultiExp(b *testing.B, n int, naive bool) {
+	bases, exponents, p, err := randomMultiExpInput(n)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if naive {
+			naiveMultiExp(bases, exponents, p)
+		} else {
+			common.MultiExp(bases, exponents, p)
+		}
+	}
+}
+
+func BenchmarkMultiExp2(b *testing.B)        { benchmarkMultiExp(b, 2, false) }
+func BenchmarkMultiExp2Naive(b *testing.B)   { benchmarkMultiExp(b, 2, true) }
+func BenchmarkMultiExp16(b *testing.B)       { benchmarkMultiExp(b, 16, false) }
+func BenchmarkMultiExp16Naive(b *testing.B)  { benchmarkMultiExp(b, 16, true) }
+func BenchmarkMultiExp256(b *testing.B)      { benchmarkMultiExp(b, 256, false) }
+func BenchmarkMultiExp256Naive(b *testing.B) { benchmarkMultiExp(b, 256, true) }
+
+// randomInt returns a random integer from [0, max). Failing to read from the source of
+// randomness is not something the tests can recover from, so it panics instead.
+func randomInt(max *big.Int) *big.Int {
+	x, err := common.GetRandomInt(max)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
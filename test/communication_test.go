@@ -22,18 +22,21 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/xlab-si/emmy/client"
 	"github.com/xlab-si/emmy/config"
-	"github.com/xlab-si/emmy/crypto/common"
 	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
 	"github.com/xlab-si/emmy/log"
 	pb "github.com/xlab-si/emmy/protobuf"
 	"github.com/xlab-si/emmy/server"
+	"github.com/xlab-si/emmy/types"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"math/big"
+	"net"
 	"os"
 	"testing"
 )
 
-var testGrpcServerEndpoint = "localhost:7008"
+var testGrpcServerEndpoint string
 
 // testGrpcClientConn is re-used for all the test clients
 var testGrpcClientConn *grpc.ClientConn
@@ -54,7 +57,13 @@ func TestMain(m *testing.M) {
 	clientLogger, err := log.NewStdoutLogger("client", log.NOTICE, log.FORMAT_SHORT)
 	client.SetLogger(clientLogger)
 
-	go server.Start(7008)
+	var listener net.Listener
+	listener, testGrpcServerEndpoint, err = listenLocal()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	go server.Serve(listener)
 
 	// Establish a connection to previously started server
 	testGrpcClientConn, err = client.GetConnection(testGrpcServerEndpoint,
@@ -73,9 +82,38 @@ func TestMain(m *testing.M) {
 	os.Exit(returnCode)
 }
 
+// listenLocal listens on a free port (chosen by the system), so that the tests do not
+// depend on particular ports being available. It returns the listener and the address
+// for the clients.
+func listenLocal() (net.Listener, string, error) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return nil, "", err
+	}
+	return listener, fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port), nil
+}
+
+// startTestServer registers the services of srv with a new gRPC server which uses
+// the test TLS certificate and serves them on a free port. It returns the address of
+// the server and a function which stops it.
+func startTestServer(t *testing.T, srv *server.Server) (string, func()) {
+	creds, err := credentials.NewServerTLSFromFile("testdata/server.pem", "testdata/server.key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer(grpc.Creds(creds))
+	srv.RegisterServices(grpcServer)
+	listener, address, err := listenLocal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go grpcServer.Serve(listener)
+	return address, grpcServer.Stop
+}
+
 func testPedersen(n *big.Int) error {
 	group := config.LoadGroup("pedersen")
-	c, err := client.NewPedersenClient(testGrpcClientConn, pb.SchemaVariant_SIGMA, group, n)
+	c, err := client.NewPedersenClient(testGrpcClientConn, group, n)
 	if err != nil {
 		return err
 	}
@@ -83,7 +121,7 @@ func testPedersen(n *big.Int) error {
 }
 
 func testPedersenEC(n *big.Int) error {
-	c, err := client.NewPedersenECClient(testGrpcClientConn, n, dlog.P256)
+	c, err := client.NewPedersenECClient(testGrpcClientConn, n, client.WithCurve(dlog.P256))
 	if err != nil {
 		return err
 	}
@@ -92,7 +130,8 @@ func testPedersenEC(n *big.Int) error {
 
 func testSchnorr(n *big.Int, variant pb.SchemaVariant) error {
 	dlog := config.LoadGroup("schnorr")
-	c, err := client.NewSchnorrClient(testGrpcClientConn, variant, dlog, n)
+	c, err := client.NewSchnorrClient(testGrpcClientConn, dlog, n,
+		client.WithProtocolVariant(variant))
 	if err != nil {
 		return err
 	}
@@ -100,13 +139,52 @@ func testSchnorr(n *big.Int, variant pb.SchemaVariant) error {
 }
 
 func testSchnorrEC(n *big.Int, variant pb.SchemaVariant) error {
-	c, err := client.NewSchnorrECClient(testGrpcClientConn, variant, dlog.P256, n)
+	c, err := client.NewSchnorrECClient(testGrpcClientConn, config.LoadCompilerParams(), n,
+		client.WithCurve(dlog.P256), client.WithProtocolVariant(variant))
 	if err != nil {
 		return err
 	}
 	return c.Run()
 }
 
+func TestGRPC_CurveNegotiation(t *testing.T) {
+	n := big.NewInt(345345345334)
+
+	params := config.LoadCompilerParams()
+	for _, curve := range config.LoadCurves() {
+		c, err := client.NewSchnorrECClient(testGrpcClientConn, params, n, client.WithCurve(curve))
+		assert.Nil(t, err)
+		assert.Nil(t, c.Run(), "session with curve %v should finish without errors", curve)
+	}
+
+	c, err := client.NewSchnorrECClient(testGrpcClientConn, params, n,
+		client.WithCurve(dlog.Curve(42)))
+	assert.Nil(t, err)
+	assert.NotNil(t, c.Run(), "server should refuse unsupported curve")
+
+	// the keys of pseudonymsys CA are configured for P256 only
+	ecdlog := dlog.NewECDLog(dlog.P384)
+	g := types.NewECGroupElement(ecdlog.Curve.Params().Gx, ecdlog.Curve.Params().Gy)
+	caClient, err := client.NewPseudonymsysCAClientEC(testGrpcClientConn,
+		client.WithCurve(dlog.P384))
+	assert.Nil(t, err)
+	_, err = caClient.ObtainCertificate(n, pseudonymsys.NewPseudonymEC(g, ecdlog.ExpBaseG(n)))
+	assert.NotNil(t, err, "server should refuse the curve on which it has no keys")
+
+	srv, err := server.NewServer(log.NewNullLogger())
+	assert.Nil(t, err)
+	assert.Equal(t, config.LoadCurves(), srv.Curves(pb.SchemaType_SCHNORR_EC))
+	assert.Equal(t, []dlog.Curve{dlog.P256}, srv.Curves(pb.SchemaType_PSEUDONYMSYS_CA_EC))
+}
+
+func TestGRPC_HybridKEM(t *testing.T) {
+	n := big.NewInt(345345345334)
+	dlog := config.LoadGroup("schnorr")
+	c, err := client.NewSchnorrClient(testGrpcClientConn, dlog, n, client.WithHybridKEM())
+	assert.Nil(t, err)
+	assert.Nil(t, c.Run(), "encrypted session should finish without errors")
+}
+
 func TestGRPC_Commitments(t *testing.T) {
 	commitVal := big.NewInt(121212121)
 
@@ -135,8 +213,37 @@ func testCSPaillier(m, l *big.Int, pubKeyPath string) error {
 }
 
 func TestGRPC_Encryption(t *testing.T) {
-	m := common.GetRandomInt(big.NewInt(8685849))
-	l := common.GetRandomInt(big.NewInt(340002223232))
+	m := randomInt(big.NewInt(8685849))
+	l := randomInt(big.NewInt(340002223232))
 
 	assert.NotNil(t, testCSPaillier(m, l, "testdata/cspaillierpubkey.txt"), "should finish with error")
 }
+
+func TestGRPC_ClientHooks(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	n := big.NewInt(345345345334)
+
+	sent, received := 0, 0
+	countSent := func(id int32, msg *pb.Message) (*pb.Message, error) {
+		sent++
+		return msg, nil
+	}
+	countReceived := func(id int32, msg *pb.Message) (*pb.Message, error) {
+		received++
+		return msg, nil
+	}
+	c, err := client.NewSchnorrClient(testGrpcClientConn, group, n,
+		client.WithSendHook(countSent), client.WithReceiveHook(countReceived))
+	assert.Nil(t, err)
+	assert.Nil(t, c.Run(), "should finish without errors")
+	assert.True(t, sent > 0, "send hook was not called")
+	assert.Equal(t, sent, received, "each request should have a response")
+
+	failing := func(id int32, msg *pb.Message) (*pb.Message, error) {
+		return nil, fmt.Errorf("injected failure")
+	}
+	c, err = client.NewSchnorrClient(testGrpcClientConn, group, n,
+		client.WithSendHook(failing))
+	assert.Nil(t, err)
+	assert.NotNil(t, c.Run(), "should fail due to the send hook")
+}
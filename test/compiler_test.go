@@ -0,0 +1,164 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/compiler"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+	"testing"
+)
+
+var variants = []compiler.Variant{compiler.Sigma{}, compiler.ZKP{}, compiler.ZKPOK{}}
+
+// compiledSigma is a sigma protocol which is run within the compiled variant.
+type compiledSigma func(challenge func() *big.Int) bool
+
+// runCompiled runs the sigma protocol compiled into the given variant, with the challenge
+// committed in the given group. If revealTrapdoor is false, the prover does not reveal
+// the trapdoor (which matters only in ZKPOK).
+func runCompiled(group *groups.SchnorrGroup, variant compiler.Variant, challengeSpace *big.Int,
+	protocol compiledSigma, revealTrapdoor bool) (bool, error) {
+	receiver, err := variant.NewChallengeReceiver(group)
+	if err != nil {
+		return false, err
+	}
+	committer := variant.NewChallengeCommitter(group, challengeSpace)
+
+	if h := receiver.GetOpeningMsg(); h != nil {
+		if !committer.HasOpening() {
+			return false, nil
+		}
+		commitment, err := committer.GetOpeningMsgReply(h)
+		if err != nil {
+			return false, nil
+		}
+		receiver.SetCommitment(commitment)
+	}
+
+	proved := protocol(func() *big.Int {
+		challenge, decommitment, err := committer.GetChallenge()
+		if err != nil || !receiver.CheckChallenge(challenge, decommitment) {
+			return nil
+		}
+		return challenge
+	})
+
+	trapdoor := receiver.GetTrapdoor()
+	if (trapdoor != nil) != committer.RequiresTrapdoor() {
+		return false, nil
+	}
+	if trapdoor != nil && revealTrapdoor {
+		committer.VerifyTrapdoor(trapdoor)
+	}
+	return committer.Verified(proved), nil
+}
+
+func schnorrSigma(group *groups.SchnorrGroup) compiledSigma {
+	return func(challenge func() *big.Int) bool {
+		prover, _ := dlogproofs.NewSchnorrProver(group)
+		verifier, _ := dlogproofs.NewSchnorrVerifier(group)
+		secret := randomInt(group.Q)
+		x, err := prover.GetProofRandomData(secret, group.G)
+		if err != nil {
+			return false
+		}
+		verifier.SetProofRandomData(x, group.G, group.Exp(group.G, secret))
+		c := challenge()
+		if c == nil {
+			return false
+		}
+		verifier.SetChallenge(c)
+		z, _, err := prover.GetProofData(c)
+		if err != nil {
+			return false
+		}
+		return verifier.Verify(z)
+	}
+}
+
+func schnorrECSigma(curve dlog.Curve) compiledSigma {
+	return func(challenge func() *big.Int) bool {
+		prover, _ := dlogproofs.NewSchnorrECProver(dlogproofs.WithCurve(curve))
+		verifier, _ := dlogproofs.NewSchnorrECVerifier(dlogproofs.WithCurve(curve))
+		secret := randomInt(prover.DLog.OrderOfSubgroup)
+		params := prover.DLog.Curve.Params()
+		a := types.NewECGroupElement(params.Gx, params.Gy)
+		b := types.NewECGroupElement(prover.DLog.ExponentiateBaseG(secret))
+		x, err := prover.GetProofRandomData(secret, a)
+		if err != nil {
+			return false
+		}
+		verifier.SetProofRandomData(x, a, b)
+		c := challenge()
+		if c == nil {
+			return false
+		}
+		verifier.SetChallenge(c)
+		z, _, err := prover.GetProofData(c)
+		if err != nil {
+			return false
+		}
+		return verifier.Verify(z)
+	}
+}
+
+func TestCompiler(t *testing.T) {
+	pedersenGroup := config.LoadGroup("pedersen")
+	schnorrGroup := config.LoadGroup("schnorr")
+	primitives := map[string]struct {
+		group          *groups.SchnorrGroup
+		challengeSpace *big.Int
+		protocol       compiledSigma
+	}{
+		"Schnorr":   {schnorrGroup, schnorrGroup.Q, schnorrSigma(schnorrGroup)},
+		"SchnorrEC": {pedersenGroup, dlog.NewECDLog(dlog.P256).OrderOfSubgroup, schnorrECSigma(dlog.P256)},
+	}
+
+	for _, variant := range variants {
+		protocolType := variant.ProtocolType()
+		for name, p := range primitives {
+			proved, err := runCompiled(p.group, variant, p.challengeSpace, p.protocol, true)
+			assert.Nil(t, err)
+			assert.True(t, proved, "%s should be proved in variant %d", name, protocolType)
+			proved, err = runCompiled(p.group, variant, p.challengeSpace, p.protocol, false)
+			assert.Nil(t, err)
+			assert.Equal(t, protocolType != types.ZKPOK, proved,
+				"%s without the trapdoor should be proved only if it is not ZKPOK", name)
+		}
+	}
+
+	receiver, err := compiler.ZKP{}.NewChallengeReceiver(pedersenGroup)
+	assert.Nil(t, err)
+	committer := compiler.ZKP{}.NewChallengeCommitter(pedersenGroup, pedersenGroup.Q)
+	commitment, err := committer.GetOpeningMsgReply(receiver.GetOpeningMsg())
+	assert.Nil(t, err)
+	receiver.SetCommitment(commitment)
+	challenge, decommitment, err := committer.GetChallenge()
+	assert.Nil(t, err)
+	assert.False(t, receiver.CheckChallenge(new(big.Int).Add(challenge, big.NewInt(1)),
+		decommitment), "Changed challenge should not match the commitment")
+
+	_, err = committer.GetOpeningMsgReply(big.NewInt(1))
+	assert.NotNil(t, err, "Invalid opening message should be rejected")
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/cli"
+	"strings"
+	"testing"
+)
+
+// TestGRPC_Demo runs the demo scenarios against their own in-process server.
+func TestGRPC_Demo(t *testing.T) {
+	var out bytes.Buffer
+	err := cli.RunDemo(&out, 0, "testdata/server.pem", "testdata/server.key", "error", "")
+	assert.Nil(t, err, "demo scenarios should finish without errors:\n%s", out.String())
+	for _, scenario := range []string{"register", "issue", "present", "revoke"} {
+		assert.True(t, strings.Contains(out.String(), "=== OK: "+scenario),
+			"scenario %s should be run", scenario)
+	}
+
+	out.Reset()
+	err = cli.RunDemo(&out, 0, "testdata/server.pem", "testdata/server.key", "error", "issue")
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(out.String(), "=== OK: issue"))
+	assert.False(t, strings.Contains(out.String(), "present"),
+		"scenarios after issue should not be run")
+
+	err = cli.RunDemo(&out, 0, "testdata/server.pem", "testdata/server.key", "error", "unknown")
+	assert.NotNil(t, err, "unknown scenario should not run")
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestCryptoWithoutConfig checks that crypto packages do not depend on emmy's configuration,
+// so they can be used as a library without emmy's config files present. All the parameters
+// need to be passed to crypto primitives by the caller (see for example config.LoadGroup).
+func TestCryptoWithoutConfig(t *testing.T) {
+	forbidden := []string{
+		"github.com/xlab-si/emmy/config",
+		"github.com/spf13/viper",
+	}
+
+	err := filepath.Walk("../crypto", func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return err
+		}
+		pkg, err := build.ImportDir(path, 0)
+		if _, noGo := err.(*build.NoGoError); noGo {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		for _, imp := range pkg.Imports {
+			for _, f := range forbidden {
+				assert.False(t, strings.HasPrefix(imp, f), "%s imports %s", path, imp)
+			}
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"encoding/asn1"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/zkp/der"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/bulletproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/fiatshamir"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+	"testing"
+)
+
+func TestDEREncoding(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	secret := randomInt(group.Q)
+	b := group.Exp(group.G, secret)
+	fsProof, err := fiatshamir.Prove(fiatshamir.NewSchnorrProver(group, secret, group.G, b), nil)
+	assert.Nil(t, err)
+
+	params, err := bulletproofs.NewParams(dlog.P256, 8, 1)
+	assert.Nil(t, err)
+	gamma := randomInt(params.DLog.OrderOfSubgroup)
+	rangeProof, err := params.ProveRange([]*big.Int{big.NewInt(42)}, []*big.Int{gamma})
+	assert.Nil(t, err)
+
+	ints := func(k int64) []*big.Int {
+		return []*big.Int{big.NewInt(k), big.NewInt(k + 1), big.NewInt(k + 2), big.NewInt(k + 3)}
+	}
+	pt := func(k int64) *types.ECGroupElement {
+		return types.NewECGroupElement(big.NewInt(k), big.NewInt(k+1))
+	}
+	v := ints(1)
+	credential := pseudonymsys.NewCredential(v[0], v[1], v[2], v[3],
+		dlogproofs.NewTranscript(v[0], v[1], v[2], v[3]),
+		dlogproofs.NewTranscript(v[3], v[2], v[1], v[0]))
+	credentialEC := pseudonymsys.NewCredentialEC(pt(1), pt(3), pt(5), pt(7),
+		dlogproofs.NewTranscriptEC(v[0], v[1], v[2], v[3], v[0], v[1]),
+		dlogproofs.NewTranscriptEC(v[3], v[2], v[1], v[0], v[3], v[2]))
+	cert := pseudonymsys.NewCACertificate(v[0], v[1], v[2], v[3], pseudonymsys.Ed25519)
+	loggedCert := pseudonymsys.NewCACertificate(v[0], v[1], v[2], v[3], pseudonymsys.ECDSA)
+	loggedCert.SCT = pseudonymsys.NewSignedCertificateTimestamp(1500000000, v[0], v[1])
+	certEC := pseudonymsys.NewCACertificateEC(pt(1), pt(3), v[2], v[3])
+
+	objects := map[string]interface{}{
+		der.OIDFiatShamirProof:           fsProof,
+		der.OIDBulletproofsRangeProof:    rangeProof,
+		der.OIDPseudonymsysCredential:    credential,
+		der.OIDPseudonymsysCredentialEC:  credentialEC,
+		der.OIDPseudonymsysCertificate:   loggedCert,
+		der.OIDPseudonymsysCertificateEC: certEC,
+	}
+	for oid, obj := range objects {
+		data, err := der.Marshal(obj)
+		assert.Nil(t, err, oid)
+		objType, err := der.TypeOf(data)
+		assert.Nil(t, err)
+		assert.Equal(t, oid, objType)
+		decoded, err := der.Unmarshal(data)
+		assert.Nil(t, err, oid)
+		assert.Equal(t, obj, decoded, "decoded object should be the same as the encoded one")
+
+		_, err = der.Unmarshal(append(data, 0))
+		assert.NotNil(t, err, "trailing data should not be accepted")
+	}
+
+	data, err := der.Marshal(cert)
+	assert.Nil(t, err)
+	decoded, err := der.Unmarshal(data)
+	assert.Nil(t, err)
+	assert.Equal(t, cert, decoded, "certificate without SCT should be decoded")
+
+	_, err = der.Marshal(big.NewInt(1))
+	assert.NotNil(t, err, "encoding of unsupported types should fail")
+	_, err = der.Marshal(pseudonymsys.NewCACertificate(nil, v[1], v[2], v[3],
+		pseudonymsys.ECDSA))
+	assert.NotNil(t, err, "encoding of incomplete certificate should fail")
+
+	// objects of other types are not decoded
+	other, err := asn1.Marshal(struct {
+		Type    asn1.ObjectIdentifier
+		Content int `asn1:"explicit,tag:0"`
+	}{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}, 1})
+	assert.Nil(t, err)
+	objType, err := der.TypeOf(other)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.2.840.113549.1.7.1", objType)
+	_, err = der.Unmarshal(other)
+	assert.NotNil(t, err, "objects of other types should not be decoded")
+}
@@ -18,37 +18,117 @@
 package test
 
 import (
+	"crypto/elliptic"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/commitments"
 	"github.com/xlab-si/emmy/crypto/common"
 	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/groups"
 	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/rangeproofs"
 	"github.com/xlab-si/emmy/types"
+	"io/ioutil"
 	"math/big"
+	"math/rand"
+	"sync"
 	"testing"
 )
 
 func TestDLogKnowledge(t *testing.T) {
 	group := config.LoadGroup("pseudonymsys")
 
-	secret := common.GetRandomInt(group.Q)
+	secret := randomInt(group.Q)
 	groupOrder := new(big.Int).Sub(group.P, big.NewInt(1))
 	g1, _ := common.GetGeneratorOfZnSubgroup(group.P, groupOrder, group.Q)
 	t1 := group.Exp(g1, secret)
-	proved := dlogproofs.ProveDLogKnowledge(secret, g1, t1, group)
+	proved, err := dlogproofs.ProveDLogKnowledge(secret, g1, t1, group)
+	assert.Nil(t, err)
 
 	assert.Equal(t, proved, true, "DLogKnowledge does not work correctly")
 }
 
+func TestDLogKnowledgeZKPOKTrapdoor(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	secret := randomInt(group.Q)
+	b := group.Exp(group.G, secret)
+
+	prover, err := dlogproofs.NewSchnorrProver(group,
+		dlogproofs.WithProtocolVariant(dlogproofs.ZKPOK{}))
+	assert.Nil(t, err)
+	verifier, err := dlogproofs.NewSchnorrVerifier(group,
+		dlogproofs.WithProtocolVariant(dlogproofs.ZKPOK{}))
+	assert.Nil(t, err)
+	commitment, err := verifier.GetOpeningMsgReply(prover.GetOpeningMsg())
+	assert.Nil(t, err)
+	prover.PedersenReceiver.SetCommitment(commitment)
+
+	x, err := prover.GetProofRandomData(secret, group.G)
+	assert.Nil(t, err)
+	verifier.SetProofRandomData(x, group.G, b)
+	challenge, r, err := verifier.GetChallenge()
+	assert.Nil(t, err)
+	assert.True(t, prover.PedersenReceiver.CheckDecommitment(r, challenge))
+	z, trapdoor, err := prover.GetProofData(challenge)
+	assert.Nil(t, err)
+
+	assert.False(t, verifier.Verify(z), "ZKPOK should not be verified without the trapdoor")
+	assert.False(t, verifier.VerifyTrapdoor(new(big.Int).Add(trapdoor, big.NewInt(1))))
+	assert.False(t, verifier.Verify(z), "ZKPOK should not be verified with a wrong trapdoor")
+	assert.True(t, verifier.VerifyTrapdoor(trapdoor))
+	assert.True(t, verifier.Verify(z), "ZKPOK should be verified")
+}
+
+func TestDLogKnowledgeDesignatedVerifier(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	secret := randomInt(group.Q)
+	b := group.Exp(group.G, secret)
+	proved, err := dlogproofs.ProveDLogKnowledgeToDesignatedVerifier(secret, group.G, b, group)
+	assert.Nil(t, err)
+	assert.True(t, proved, "proof to designated verifier should be verified")
+
+	// the verifier can produce a valid transcript without the prover's secret: it chooses
+	// challenge and z, and opens its commitment to the challenge using the secret key
+	sk := randomInt(group.Q)
+	verifier, err := dlogproofs.NewSchnorrVerifier(group,
+		dlogproofs.WithProtocolVariant(dlogproofs.DesignatedVerifier{}))
+	assert.Nil(t, err)
+	verifier.SetSecretKey(sk)
+	prover, err := dlogproofs.NewSchnorrProver(group,
+		dlogproofs.WithProtocolVariant(dlogproofs.DesignatedVerifier{}))
+	assert.Nil(t, err)
+	prover.SetVerifierPublicKey(verifier.GetPublicKey())
+	commitment, err := verifier.GetChallengeCommitment()
+	assert.Nil(t, err)
+	prover.PedersenReceiver.SetCommitment(commitment)
+	committed, r0, err := verifier.GetChallenge()
+	assert.Nil(t, err)
+
+	challenge := randomInt(group.Q)
+	z := randomInt(group.Q)
+	x := group.Mul(group.Exp(group.G, z), group.Inv(group.Exp(b, challenge)))
+	// g^c0 * h^r0 = g^c * h^r for r = r0 + (c0 - c) / sk
+	r := new(big.Int).Sub(committed, challenge)
+	r.Mul(r, new(big.Int).ModInverse(sk, group.Q))
+	r.Add(r, r0)
+	r.Mod(r, group.Q)
+
+	assert.True(t, prover.PedersenReceiver.CheckDecommitment(r, challenge),
+		"verifier should be able to open the commitment to any challenge")
+	verifier.SetProofRandomData(x, group.G, b)
+	verifier.SetChallenge(challenge)
+	assert.True(t, verifier.Verify(z), "simulated transcript should look valid")
+}
+
 func TestECDLogKnowledge(t *testing.T) {
 	dLog := dlog.NewECDLog(dlog.P256)
 
-	exp1 := common.GetRandomInt(dLog.OrderOfSubgroup)
+	exp1 := randomInt(dLog.OrderOfSubgroup)
 	a1X, a1Y := dLog.ExponentiateBaseG(exp1)
 	a1 := types.NewECGroupElement(a1X, a1Y)
 
-	secret := common.GetRandomInt(dLog.OrderOfSubgroup)
+	secret := randomInt(dLog.OrderOfSubgroup)
 	b1X, b1Y := dLog.Exponentiate(a1X, a1Y, secret)
 	b1 := types.NewECGroupElement(b1X, b1Y)
 
@@ -64,14 +144,15 @@ func TestECDLogKnowledge(t *testing.T) {
 func TestDLogEquality(t *testing.T) {
 	group := config.LoadGroup("pseudonymsys")
 
-	secret := common.GetRandomInt(group.Q)
+	secret := randomInt(group.Q)
 	groupOrder := new(big.Int).Sub(group.P, big.NewInt(1))
 	g1, _ := common.GetGeneratorOfZnSubgroup(group.P, groupOrder, group.Q)
 	g2, _ := common.GetGeneratorOfZnSubgroup(group.P, groupOrder, group.Q)
 
 	t1 := group.Exp(g1, secret)
 	t2 := group.Exp(g2, secret)
-	proved := dlogproofs.ProveDLogEquality(secret, g1, g2, t1, t2, group)
+	proved, err := dlogproofs.ProveDLogEquality(secret, g1, g2, t1, t2, group)
+	assert.Nil(t, err)
 
 	assert.Equal(t, proved, true, "DLogEquality does not work correctly")
 }
@@ -80,9 +161,10 @@ func TestDLogEqualityBlindedTranscript(t *testing.T) {
 	group := config.LoadGroup("pseudonymsys")
 
 	eProver := dlogproofs.NewDLogEqualityBTranscriptProver(group)
-	eVerifier := dlogproofs.NewDLogEqualityBTranscriptVerifier(group, nil)
+	eVerifier, err := dlogproofs.NewDLogEqualityBTranscriptVerifier(group, nil)
+	assert.Nil(t, err)
 
-	secret := common.GetRandomInt(group.Q)
+	secret := randomInt(group.Q)
 	groupOrder := new(big.Int).Sub(eProver.Group.P, big.NewInt(1))
 	g1, _ := common.GetGeneratorOfZnSubgroup(eProver.Group.P, groupOrder, eProver.Group.Q)
 	g2, _ := common.GetGeneratorOfZnSubgroup(eProver.Group.P, groupOrder, eProver.Group.Q)
@@ -90,22 +172,42 @@ func TestDLogEqualityBlindedTranscript(t *testing.T) {
 	t1 := eProver.Group.Exp(g1, secret)
 	t2 := eProver.Group.Exp(g2, secret)
 
-	x1, x2 := eProver.GetProofRandomData(secret, g1, g2)
+	x1, x2, err := eProver.GetProofRandomData(secret, g1, g2)
+	assert.Nil(t, err)
 
-	challenge := eVerifier.GetChallenge(g1, g2, t1, t2, x1, x2)
-	z := eProver.GetProofData(challenge)
+	challenge, err := eVerifier.GetChallenge(g1, g2, t1, t2, x1, x2)
+	assert.Nil(t, err)
+	z, err := eProver.GetProofData(challenge)
+	assert.Nil(t, err)
 	_, transcript, G2, T2 := eVerifier.Verify(z)
 
 	valid := dlogproofs.VerifyBlindedTranscript(transcript, eProver.Group, g1, t1, G2, T2)
 	assert.Equal(t, valid, true, "DLogEqualityBTranscript does not work correctly")
 }
 
+func TestSchnorrECOptions(t *testing.T) {
+	prover, err := dlogproofs.NewSchnorrECProver()
+	assert.Nil(t, err)
+	assert.Equal(t, dlog.NewECDLog(dlog.P256).OrderOfSubgroup, prover.DLog.OrderOfSubgroup,
+		"P256 should be the default curve")
+	verifier, err := dlogproofs.NewSchnorrECVerifier(dlogproofs.WithCurve(dlog.P384))
+	assert.Nil(t, err)
+	assert.Equal(t, dlog.NewECDLog(dlog.P384).OrderOfSubgroup, verifier.DLog.OrderOfSubgroup)
+
+	_, err = dlogproofs.NewSchnorrECProver(
+		dlogproofs.WithProtocolVariant(dlogproofs.DesignatedVerifier{}))
+	assert.NotNil(t, err, "DesignatedVerifier should not be accepted in EC")
+	_, err = dlogproofs.NewSchnorrECVerifier(
+		dlogproofs.WithProtocolVariant(dlogproofs.DesignatedVerifier{}))
+	assert.NotNil(t, err, "DesignatedVerifier should not be accepted in EC")
+}
+
 func TestDLogEqualityEC(t *testing.T) {
 	dLog := dlog.NewECDLog(dlog.P256)
-	secret := common.GetRandomInt(dLog.OrderOfSubgroup)
+	secret := randomInt(dLog.OrderOfSubgroup)
 
-	r1 := common.GetRandomInt(dLog.OrderOfSubgroup)
-	r2 := common.GetRandomInt(dLog.OrderOfSubgroup)
+	r1 := randomInt(dLog.OrderOfSubgroup)
+	r2 := randomInt(dLog.OrderOfSubgroup)
 
 	g11, g12 := dLog.ExponentiateBaseG(r1)
 	g21, g22 := dLog.ExponentiateBaseG(r2)
@@ -119,14 +221,19 @@ func TestDLogEqualityEC(t *testing.T) {
 	t1 := types.NewECGroupElement(t11, t12)
 	t2 := types.NewECGroupElement(t21, t22)
 
-	proved := dlogproofs.ProveECDLogEquality(secret, g1, g2, t1, t2, dlog.P256)
+	proved, err := dlogproofs.ProveECDLogEquality(secret, g1, g2, t1, t2, dlog.P256)
+	assert.Nil(t, err)
 	assert.Equal(t, proved, true, "DLogEqualityEC does not work correctly")
 
 	eProver := dlogproofs.NewECDLogEqualityBTranscriptProver(dlog.P256)
-	eVerifier := dlogproofs.NewECDLogEqualityBTranscriptVerifier(dlog.P256, nil)
-	x1, x2 := eProver.GetProofRandomData(secret, g1, g2)
-	challenge := eVerifier.GetChallenge(g1, g2, t1, t2, x1, x2)
-	z := eProver.GetProofData(challenge)
+	eVerifier, err := dlogproofs.NewECDLogEqualityBTranscriptVerifier(dlog.P256, nil)
+	assert.Nil(t, err)
+	x1, x2, err := eProver.GetProofRandomData(secret, g1, g2)
+	assert.Nil(t, err)
+	challenge, err := eVerifier.GetChallenge(g1, g2, t1, t2, x1, x2)
+	assert.Nil(t, err)
+	z, err := eProver.GetProofData(challenge)
+	assert.Nil(t, err)
 	_, transcript, G2, T2 := eVerifier.Verify(z)
 	valid := dlogproofs.VerifyBlindedTranscriptEC(transcript, dlog.P256, g1, t1, G2, T2)
 
@@ -134,11 +241,56 @@ func TestDLogEqualityEC(t *testing.T) {
 
 }
 
+func TestDHTuple(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	a := randomInt(group.Q)
+	b := randomInt(group.Q)
+	g := group.G
+	ga := group.Exp(g, a)
+	gb := group.Exp(g, b)
+	gab := group.Exp(gb, a)
+
+	proved, err := dlogproofs.ProveDHTuple(a, g, ga, gb, gab, group)
+	assert.Nil(t, err)
+	assert.True(t, proved, "DH tuple should be proved")
+	proved, err = dlogproofs.ProveDHTuple(a, g, ga, gb, group.Mul(gab, g), group)
+	assert.Nil(t, err)
+	assert.False(t, proved, "tuple which is not DH tuple should not be proved")
+
+	// elements which are not in the group are rejected
+	prover := dlogproofs.NewDHTupleProver(group)
+	verifier := dlogproofs.NewDHTupleVerifier(group)
+	x1, x2, err := prover.GetProofRandomData(a, g, gb)
+	assert.Nil(t, err)
+	challenge, err := verifier.GetChallenge(g, ga, gb, new(big.Int).Add(gab, group.P), x1, x2)
+	assert.Nil(t, err)
+	z, err := prover.GetProofData(challenge)
+	assert.Nil(t, err)
+	assert.False(t, verifier.Verify(z), "non-canonical element should not be accepted")
+}
+
+func TestECDHTuple(t *testing.T) {
+	dLog := dlog.NewECDLog(dlog.P256)
+	a := randomInt(dLog.OrderOfSubgroup)
+	b := randomInt(dLog.OrderOfSubgroup)
+	g := types.NewECGroupElement(dLog.ExponentiateBaseG(big.NewInt(1)))
+	ga := dLog.Exp(g, a)
+	gb := dLog.Exp(g, b)
+	gab := dLog.Exp(gb, a)
+
+	proved, err := dlogproofs.ProveECDHTuple(a, g, ga, gb, gab, dlog.P256)
+	assert.Nil(t, err)
+	assert.True(t, proved, "DH tuple should be proved")
+	proved, err = dlogproofs.ProveECDHTuple(a, g, ga, gb, dLog.Exp(gab, big.NewInt(2)), dlog.P256)
+	assert.Nil(t, err)
+	assert.False(t, proved, "tuple which is not DH tuple should not be proved")
+}
+
 func TestPartialDLogKnowledge(t *testing.T) {
 	group := config.LoadGroup("pseudonymsys")
 
-	secret1 := common.GetRandomInt(group.Q)
-	x := common.GetRandomInt(group.Q)
+	secret1 := randomInt(group.Q)
+	x := randomInt(group.Q)
 
 	groupOrder := new(big.Int).Sub(group.P, big.NewInt(1))
 	a1, _ := common.GetGeneratorOfZnSubgroup(group.P, groupOrder, group.Q)
@@ -147,7 +299,8 @@ func TestPartialDLogKnowledge(t *testing.T) {
 	//b1, _ := dlog.Exponentiate(a1, secret1)
 	// we pretend that we don't know x:
 	b2 := group.Exp(a2, x)
-	proved := dlogproofs.ProvePartialDLogKnowledge(group, secret1, a1, a2, b2)
+	proved, err := dlogproofs.ProvePartialDLogKnowledge(group, secret1, a1, a2, b2)
+	assert.Nil(t, err)
 
 	assert.Equal(t, proved, true, "ProvePartialDLogKnowledge does not work correctly")
 }
@@ -155,22 +308,992 @@ func TestPartialDLogKnowledge(t *testing.T) {
 func TestPartialECDLogKnowledge(t *testing.T) {
 	dlog := dlog.NewECDLog(dlog.P256)
 
-	exp1 := common.GetRandomInt(dlog.OrderOfSubgroup)
-	exp2 := common.GetRandomInt(dlog.OrderOfSubgroup)
+	exp1 := randomInt(dlog.OrderOfSubgroup)
+	exp2 := randomInt(dlog.OrderOfSubgroup)
 	a1X, a1Y := dlog.ExponentiateBaseG(exp1)
 	a1 := types.NewECGroupElement(a1X, a1Y)
 	a2X, a2Y := dlog.ExponentiateBaseG(exp2)
 	a2 := types.NewECGroupElement(a2X, a2Y)
 
-	secret1 := common.GetRandomInt(dlog.OrderOfSubgroup)
-	x := common.GetRandomInt(dlog.OrderOfSubgroup)
+	secret1 := randomInt(dlog.OrderOfSubgroup)
+	x := randomInt(dlog.OrderOfSubgroup)
 
 	//b1X, b1Y := dlog.ExponentiateBaseG(secret1)
 	// we pretend that we don't know x:
 	b2X, b2Y := dlog.ExponentiateBaseG(x)
 	b2 := types.NewECGroupElement(b2X, b2Y)
 
-	proved := dlogproofs.ProvePartialECDLogKnowledge(dlog, secret1, a1, a2, b2)
+	proved, err := dlogproofs.ProvePartialECDLogKnowledge(dlog, secret1, a1, a2, b2)
+	assert.Nil(t, err)
 
 	assert.Equal(t, proved, true, "ProvePartialECDLogKnowledge does not work correctly")
 }
+
+func TestDLogSimulators(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	// nobody knows the dlogs of the statements
+	a := group.Exp(group.G, randomInt(group.Q))
+	b := group.Exp(group.G, randomInt(group.Q))
+	t1 := group.Exp(group.G, randomInt(group.Q))
+	t2 := group.Exp(group.G, randomInt(group.Q))
+	challenge := randomInt(group.Q)
+
+	x, z, err := dlogproofs.SimulateSchnorr(group, a, b, challenge)
+	assert.Nil(t, err)
+	schnorrVerifier, err := dlogproofs.NewSchnorrVerifier(group)
+	assert.Nil(t, err)
+	schnorrVerifier.SetProofRandomData(x, a, b)
+	schnorrVerifier.SetChallenge(challenge)
+	assert.True(t, schnorrVerifier.Verify(z), "simulated Schnorr transcript should be verified")
+
+	x1, x2, z, err := dlogproofs.SimulateDLogEquality(group, group.G, a, t1, t2, challenge)
+	assert.Nil(t, err)
+	equalityVerifier := dlogproofs.NewDLogEqualityVerifier(group)
+	equalityVerifier.SetProofRandomData(group.G, a, t1, t2, x1, x2)
+	equalityVerifier.SetChallenge(challenge)
+	assert.True(t, equalityVerifier.Verify(z), "simulated dlog equality transcript should be verified")
+
+	triple1, triple2, c1, z1, c2, z2, err := dlogproofs.SimulatePartialDLog(group, a, b, t1, t2,
+		challenge)
+	assert.Nil(t, err)
+	partialVerifier := dlogproofs.NewPartialDLogVerifier(group)
+	partialVerifier.SetProofRandomData(triple1, triple2)
+	partialVerifier.SetChallenge(challenge)
+	assert.True(t, partialVerifier.Verify(c1, z1, c2, z2),
+		"simulated partial dlog transcript should be verified")
+	partialVerifier.SetChallenge(new(big.Int).Add(challenge, big.NewInt(1)))
+	assert.False(t, partialVerifier.Verify(c1, z1, c2, z2),
+		"simulated transcript should not be verified for another challenge")
+}
+
+func TestECDLogSimulators(t *testing.T) {
+	dLog := dlog.NewECDLog(dlog.P256)
+	q := dLog.OrderOfSubgroup
+	var points []*types.ECGroupElement
+	for i := 0; i < 4; i++ {
+		points = append(points, dLog.ExpBaseG(randomInt(q)))
+	}
+	challenge := randomInt(q)
+
+	x, z, err := dlogproofs.SimulateECSchnorr(dLog, points[0], points[1], challenge)
+	assert.Nil(t, err)
+	schnorrVerifier, _ := dlogproofs.NewSchnorrECVerifier()
+	schnorrVerifier.SetProofRandomData(x, points[0], points[1])
+	schnorrVerifier.SetChallenge(challenge)
+	assert.True(t, schnorrVerifier.Verify(z), "simulated Schnorr transcript should be verified")
+
+	triple1, triple2, c1, z1, c2, z2, err := dlogproofs.SimulatePartialECDLog(dLog, points[0],
+		points[1], points[2], points[3], challenge)
+	assert.Nil(t, err)
+	partialVerifier := dlogproofs.NewPartialECDLogVerifier(dLog)
+	partialVerifier.SetProofRandomData(triple1, triple2)
+	partialVerifier.SetChallenge(challenge)
+	assert.True(t, partialVerifier.Verify(c1, z1, c2, z2),
+		"simulated partial dlog transcript should be verified")
+}
+
+func TestSchnorrProverSingleUse(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	secret := randomInt(group.Q)
+	b := group.Exp(group.G, secret)
+
+	prover, err := dlogproofs.NewSchnorrProver(group)
+	assert.Nil(t, err)
+	verifier, err := dlogproofs.NewSchnorrVerifier(group)
+	assert.Nil(t, err)
+	assert.False(t, verifier.Verify(big.NewInt(1)), "verifier without proof random data")
+
+	x, err := prover.GetProofRandomData(secret, group.G)
+	assert.Nil(t, err)
+	verifier.SetProofRandomData(x, group.G, b)
+	challenge, _, err := verifier.GetChallenge()
+	assert.Nil(t, err)
+	z, _, err := prover.GetProofData(challenge)
+	assert.Nil(t, err)
+	assert.True(t, verifier.Verify(z), "DLogKnowledge does not work correctly")
+
+	// the second response for the same proof random data would reveal the secret
+	_, _, err = prover.GetProofData(big.NewInt(1))
+	assert.NotNil(t, err, "prover should not respond twice to the same proof random data")
+
+	prover.GetProofRandomData(secret, group.G)
+	prover.Reset()
+	_, _, err = prover.GetProofData(challenge)
+	assert.NotNil(t, err, "prover should not respond after Reset")
+	verifier.Reset()
+	assert.False(t, verifier.Verify(z), "verifier should not verify after Reset")
+
+	x, err = prover.GetProofRandomData(secret, group.G)
+	assert.Nil(t, err)
+	verifier.SetProofRandomData(x, group.G, b)
+	challenge, _, err = verifier.GetChallenge()
+	assert.Nil(t, err)
+	z, _, err = prover.GetProofData(challenge)
+	assert.Nil(t, err)
+	assert.True(t, verifier.Verify(z), "reset instances should be reusable")
+}
+
+func TestSchnorrStateSerialization(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	secret := randomInt(group.Q)
+	b := group.Exp(group.G, secret)
+
+	// the state is saved after each step and the next step runs in fresh instances
+	prover, err := dlogproofs.NewSchnorrProver(group,
+		dlogproofs.WithProtocolVariant(dlogproofs.ZKPOK{}))
+	assert.Nil(t, err)
+	verifier, err := dlogproofs.NewSchnorrVerifier(group,
+		dlogproofs.WithProtocolVariant(dlogproofs.ZKPOK{}))
+	assert.Nil(t, err)
+	commitment, err := verifier.GetOpeningMsgReply(prover.GetOpeningMsg())
+	assert.Nil(t, err)
+	prover.PedersenReceiver.SetCommitment(commitment)
+	x, err := prover.GetProofRandomData(secret, group.G)
+	assert.Nil(t, err)
+	verifier.SetProofRandomData(x, group.G, b)
+
+	proverState, err := prover.MarshalState()
+	assert.Nil(t, err)
+	verifierState, err := verifier.MarshalState()
+	assert.Nil(t, err)
+	prover, err = dlogproofs.NewSchnorrProver(group,
+		dlogproofs.WithProtocolVariant(dlogproofs.ZKPOK{}))
+	assert.Nil(t, err)
+	verifier, err = dlogproofs.NewSchnorrVerifier(group,
+		dlogproofs.WithProtocolVariant(dlogproofs.ZKPOK{}))
+	assert.Nil(t, err)
+	assert.Nil(t, prover.UnmarshalState(proverState))
+	assert.Nil(t, verifier.UnmarshalState(verifierState))
+
+	challenge, r, err := verifier.GetChallenge()
+	assert.Nil(t, err)
+	assert.True(t, prover.PedersenReceiver.CheckDecommitment(r, challenge),
+		"restored prover should check the decommitment of the challenge")
+	z, trapdoor, err := prover.GetProofData(challenge)
+	assert.Nil(t, err)
+	assert.True(t, verifier.VerifyTrapdoor(trapdoor))
+	verifierState, _ = verifier.MarshalState()
+	verifier, err = dlogproofs.NewSchnorrVerifier(group,
+		dlogproofs.WithProtocolVariant(dlogproofs.ZKPOK{}))
+	assert.Nil(t, err)
+	assert.Nil(t, verifier.UnmarshalState(verifierState))
+	assert.True(t, verifier.Verify(z), "proof should be verified by restored verifier")
+
+	// designated verifier keeps its secret key in the state
+	prover, err = dlogproofs.NewSchnorrProver(group,
+		dlogproofs.WithProtocolVariant(dlogproofs.DesignatedVerifier{}))
+	assert.Nil(t, err)
+	verifier, err = dlogproofs.NewSchnorrVerifier(group,
+		dlogproofs.WithProtocolVariant(dlogproofs.DesignatedVerifier{}))
+	assert.Nil(t, err)
+	prover.SetVerifierPublicKey(verifier.GetPublicKey())
+	commitment, err = verifier.GetChallengeCommitment()
+	assert.Nil(t, err)
+	prover.PedersenReceiver.SetCommitment(commitment)
+	x, err = prover.GetProofRandomData(secret, group.G)
+	assert.Nil(t, err)
+	verifier.SetProofRandomData(x, group.G, b)
+	proverState, _ = prover.MarshalState()
+	verifierState, _ = verifier.MarshalState()
+	prover, err = dlogproofs.NewSchnorrProver(group,
+		dlogproofs.WithProtocolVariant(dlogproofs.DesignatedVerifier{}))
+	assert.Nil(t, err)
+	verifier, err = dlogproofs.NewSchnorrVerifier(group,
+		dlogproofs.WithProtocolVariant(dlogproofs.DesignatedVerifier{}))
+	assert.Nil(t, err)
+	assert.Nil(t, prover.UnmarshalState(proverState))
+	assert.Nil(t, verifier.UnmarshalState(verifierState))
+	challenge, r, err = verifier.GetChallenge()
+	assert.Nil(t, err)
+	assert.True(t, prover.PedersenReceiver.CheckDecommitment(r, challenge))
+	z, _, err = prover.GetProofData(challenge)
+	assert.Nil(t, err)
+	assert.True(t, verifier.Verify(z), "designated verifier proof should be verified")
+
+	// EC sigma protocol
+	dLog := dlog.NewECDLog(dlog.P256)
+	bEC := dLog.ExpBaseG(secret)
+	gEC := dLog.ExpBaseG(big.NewInt(1))
+	proverEC, _ := dlogproofs.NewSchnorrECProver()
+	verifierEC, _ := dlogproofs.NewSchnorrECVerifier()
+	xEC, err := proverEC.GetProofRandomData(secret, gEC)
+	assert.Nil(t, err)
+	verifierEC.SetProofRandomData(xEC, gEC, bEC)
+	challenge, _, err = verifierEC.GetChallenge()
+	assert.Nil(t, err)
+	proverState, _ = proverEC.MarshalState()
+	verifierState, _ = verifierEC.MarshalState()
+	proverEC, _ = dlogproofs.NewSchnorrECProver()
+	verifierEC, _ = dlogproofs.NewSchnorrECVerifier()
+	assert.Nil(t, proverEC.UnmarshalState(proverState))
+	assert.Nil(t, verifierEC.UnmarshalState(verifierState))
+	z, _, err = proverEC.GetProofData(challenge)
+	assert.Nil(t, err)
+	assert.True(t, verifierEC.Verify(z), "EC proof should be verified by restored verifier")
+
+	verifier, err = dlogproofs.NewSchnorrVerifier(group)
+	assert.Nil(t, err)
+	assert.NotNil(t, verifier.UnmarshalState(verifierState),
+		"state of another protocol should not be restored")
+	assert.NotNil(t, verifierEC.UnmarshalState(verifierState[:len(verifierState)-1]),
+		"truncated state should not be restored")
+}
+
+func TestPartialDLogProverSingleUse(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	exp1 := randomInt(group.Q)
+	exp2 := randomInt(group.Q)
+	a1 := group.Exp(group.G, exp1)
+	a2 := group.Exp(group.G, exp2)
+	secret1 := randomInt(group.Q)
+	b1 := group.Exp(a1, secret1)
+	b2 := group.Exp(a2, randomInt(group.Q))
+
+	prover := dlogproofs.NewPartialDLogProver(group)
+	verifier := dlogproofs.NewPartialDLogVerifier(group)
+	triple1, triple2, err := prover.GetProofRandomData(secret1, a1, b1, a2, b2)
+	assert.Nil(t, err)
+	verifier.SetProofRandomData(triple1, triple2)
+	challenge, err := verifier.GetChallenge()
+	assert.Nil(t, err)
+	c1, z1, c2, z2, err := prover.GetProofData(challenge)
+	assert.Nil(t, err)
+	assert.True(t, verifier.Verify(c1, z1, c2, z2), "PartialDLogKnowledge does not work")
+
+	_, _, _, _, err = prover.GetProofData(challenge)
+	assert.NotNil(t, err, "prover should not respond twice to the same proof random data")
+}
+
+func TestSchnorrPooledConcurrent(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	provers := sync.Pool{New: func() interface{} {
+		prover, _ := dlogproofs.NewSchnorrProver(group)
+		return prover
+	}}
+	verifiers := sync.Pool{New: func() interface{} {
+		verifier, _ := dlogproofs.NewSchnorrVerifier(group)
+		return verifier
+	}}
+
+	var wg sync.WaitGroup
+	results := make(chan bool, 40)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				prover := provers.Get().(*dlogproofs.SchnorrProver)
+				verifier := verifiers.Get().(*dlogproofs.SchnorrVerifier)
+				secret := randomInt(group.Q)
+				x, err := prover.GetProofRandomData(secret, group.G)
+				assert.Nil(t, err)
+				verifier.SetProofRandomData(x, group.G, group.Exp(group.G, secret))
+				challenge, _, err := verifier.GetChallenge()
+				assert.Nil(t, err)
+				z, _, err := prover.GetProofData(challenge)
+				assert.Nil(t, err)
+				results <- verifier.Verify(z)
+				prover.Reset()
+				verifier.Reset()
+				provers.Put(prover)
+				verifiers.Put(verifier)
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+	for verified := range results {
+		assert.True(t, verified, "pooled Schnorr instances should be reusable")
+	}
+}
+
+func TestKeyCorrespondence(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	dLog := dlog.NewECDLog(dlog.P256)
+	params, err := dlogproofs.NewCrossGroupParams(256)
+	assert.Nil(t, err)
+
+	secret := randomInt(dLog.OrderOfSubgroup)
+	if secret.Cmp(group.Q) >= 0 {
+		secret.Mod(secret, group.Q)
+	}
+	t1 := group.Exp(group.G, secret)
+
+	g := types.NewECGroupElement(dLog.Curve.Params().Gx, dLog.Curve.Params().Gy)
+	tX, tY := dLog.ExponentiateBaseG(secret)
+	tEC := types.NewECGroupElement(tX, tY)
+
+	proved, err := dlogproofs.ProveKeyCorrespondence(secret, group.G, t1, g, tEC, group,
+		dlog.P256, params)
+	assert.Nil(t, err, "KeyCorrespondence should not produce an error")
+	assert.Equal(t, proved, true, "KeyCorrespondence does not work correctly")
+
+	otherX, otherY := dLog.ExponentiateBaseG(new(big.Int).Add(secret, big.NewInt(1)))
+	other := types.NewECGroupElement(otherX, otherY)
+	proved, _ = dlogproofs.ProveKeyCorrespondence(secret, group.G, t1, g, other, group,
+		dlog.P256, params)
+	assert.Equal(t, proved, false, "KeyCorrespondence should fail for different secrets")
+
+	// the response needs to open the commitment to the secret in RSA group as well
+	prover := dlogproofs.NewKeyCorrespondenceProver(group, dlog.P256, params)
+	verifier := dlogproofs.NewKeyCorrespondenceVerifier(group, dlog.P256, params)
+	commitment, proofRandomData, err := prover.GetProofRandomData(secret, group.G, g)
+	assert.Nil(t, err)
+	challenge, err := verifier.GetChallenge(group.G, t1, g, tEC, commitment, proofRandomData)
+	assert.Nil(t, err)
+	proofData := prover.GetProofData(challenge)
+	proofData.ZS.Add(proofData.ZS, big.NewInt(1))
+	assert.False(t, verifier.Verify(proofData),
+		"KeyCorrespondence should fail when the commitment is not opened")
+
+	_, _, err = prover.GetProofRandomData(group.Q, group.G, g)
+	assert.NotNil(t, err, "secret not smaller than the orders should not be proved")
+
+	// a dishonest prover uses x larger than the orders, thus log_g(t1) and log_gEC(tEC)
+	// differ - the cross-group proof alone is accepted, but x cannot be proved to be
+	// smaller than the orders
+	m := group.Q
+	if dLog.OrderOfSubgroup.Cmp(m) < 0 {
+		m = dLog.OrderOfSubgroup
+	}
+	x := new(big.Int).Add(m, big.NewInt(12345))
+	t1 = group.Exp(group.G, x)
+	tX, tY = dLog.ExponentiateBaseG(new(big.Int).Mod(x, dLog.OrderOfSubgroup))
+	tEC = types.NewECGroupElement(tX, tY)
+	prover = dlogproofs.NewKeyCorrespondenceProver(group, dlog.P256, params)
+	verifier = dlogproofs.NewKeyCorrespondenceVerifier(group, dlog.P256, params)
+	commitment, crossGroup, err := prover.CrossGroupDLogEqualityProver.GetProofRandomData(x,
+		group.G, g)
+	assert.Nil(t, err)
+	dfParams := &commitments.DamgardFujisakiParams{N: params.N, G: params.H1, H: params.H2}
+	s := prover.GetCommitmentOpening()
+	lower, err := rangeproofs.NewNonNegativeProver(dfParams, x, s)
+	assert.Nil(t, err)
+	// m - 1 - x is negative, the prover can only prove the non-negativity of another value
+	upper, err := rangeproofs.NewNonNegativeProver(dfParams, big.NewInt(0), new(big.Int).Neg(s))
+	assert.Nil(t, err)
+	lowerRandomData, err := lower.GetProofRandomData()
+	assert.Nil(t, err)
+	upperRandomData, err := upper.GetProofRandomData()
+	assert.Nil(t, err)
+	challenge, err = verifier.GetChallenge(group.G, t1, g, tEC, commitment,
+		&dlogproofs.KeyCorrespondenceProofRandomData{
+			CrossGroup: crossGroup,
+			Lower:      lowerRandomData,
+			Upper:      upperRandomData,
+		})
+	assert.Nil(t, err)
+	z, zS := prover.CrossGroupDLogEqualityProver.GetProofData(challenge)
+	assert.True(t, verifier.CrossGroupDLogEqualityVerifier.Verify(z, zS),
+		"cross-group proof alone should not bound the secret")
+	assert.False(t, verifier.Verify(&dlogproofs.KeyCorrespondenceProofData{
+		Z:     z,
+		ZS:    zS,
+		Lower: lower.GetProofData(challenge),
+		Upper: upper.GetProofData(challenge),
+	}), "KeyCorrespondence should fail for secret larger than the orders")
+}
+
+func TestCrossGroupDLogEquality(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	dLog := dlog.NewECDLog(dlog.P256)
+	params, err := dlogproofs.NewCrossGroupParams(256)
+	assert.Nil(t, err)
+
+	l := 64
+	secret := randomInt(new(big.Int).Lsh(big.NewInt(1), uint(l)))
+	t1 := group.Exp(group.G, secret)
+	g := types.NewECGroupElement(dLog.Curve.Params().Gx, dLog.Curve.Params().Gy)
+	tEC := types.NewECGroupElement(dLog.ExponentiateBaseG(secret))
+
+	proved, err := dlogproofs.ProveCrossGroupDLogEquality(secret, group.G, t1, g, tEC, group,
+		dlog.P256, params, l)
+	assert.Nil(t, err)
+	assert.True(t, proved, "cross-group dlog equality should be proved")
+
+	other := types.NewECGroupElement(dLog.ExponentiateBaseG(new(big.Int).Add(secret, big.NewInt(1))))
+	proved, err = dlogproofs.ProveCrossGroupDLogEquality(secret, group.G, t1, g, other, group,
+		dlog.P256, params, l)
+	assert.Nil(t, err)
+	assert.False(t, proved, "cross-group dlog equality should fail for different secrets")
+
+	tooBig := new(big.Int).Lsh(big.NewInt(1), uint(l))
+	_, err = dlogproofs.ProveCrossGroupDLogEquality(tooBig, group.G, group.Exp(group.G, tooBig),
+		g, types.NewECGroupElement(dLog.ExponentiateBaseG(tooBig)), group, dlog.P256, params, l)
+	assert.NotNil(t, err, "secret out of range should not be proved")
+
+	_, err = dlogproofs.NewCrossGroupDLogEqualityProver(group, dlog.P256, params, 94)
+	assert.NotNil(t, err, "range too large for the orders of the groups should be refused")
+}
+
+func TestCurves(t *testing.T) {
+	for _, curve := range []dlog.Curve{dlog.P224, dlog.P256, dlog.P384, dlog.P521} {
+		parsed, err := dlog.ParseCurve(curve.String())
+		assert.Nil(t, err)
+		assert.Equal(t, curve, parsed)
+	}
+	_, err := dlog.ParseCurve("secp256k1")
+	assert.NotNil(t, err, "unknown curve should not be parsed")
+
+	curves := config.LoadCurves()
+	assert.NotEmpty(t, curves, "server should support some curves")
+	assert.Equal(t, dlog.P256, curves[0], "P256 should be the default curve")
+}
+
+// partialDLogStatements returns n statements b[i] = a[i]^x[i] where the secrets x[i] are
+// known (non-nil) for k randomly chosen statements.
+func TestECPointInfinity(t *testing.T) {
+	invalid := types.NewECGroupElement(big.NewInt(1), big.NewInt(1))
+	for _, curve := range []dlog.Curve{dlog.P224, dlog.P256, dlog.P384, dlog.P521} {
+		dLog := dlog.NewECDLog(curve)
+		n := dLog.OrderOfSubgroup
+		inf := types.NewECGroupElementInfinity()
+		g := dLog.ExpBaseG(big.NewInt(1))
+		p := dLog.ExpBaseG(randomInt(n))
+
+		assert.True(t, dLog.IsValid(inf), "infinity should be valid")
+		assert.True(t, dLog.IsValid(p), "point on the curve should be valid")
+		assert.False(t, dLog.IsValid(invalid), "point not on the curve should not be valid")
+		assert.False(t, dLog.IsValid(types.NewECGroupElement(nil, nil)))
+		assert.False(t, dLog.IsValid(nil))
+		assert.False(t, g.IsInfinity())
+		assert.False(t, inf.Equals(nil))
+
+		assert.True(t, dLog.ExpBaseG(big.NewInt(0)).IsInfinity(), "g^0")
+		assert.True(t, dLog.ExpBaseG(n).IsInfinity(), "g^n")
+		assert.True(t, dLog.Exp(p, big.NewInt(0)).IsInfinity(), "p^0")
+		assert.True(t, dLog.Exp(p, n).IsInfinity(), "p^n")
+		assert.True(t, dLog.Exp(inf, big.NewInt(5)).IsInfinity(), "infinity^5")
+		assert.True(t, dLog.Inv(inf).IsInfinity(), "infinity^-1")
+		assert.True(t, dLog.Mul(inf, inf).IsInfinity(), "infinity * infinity")
+		assert.True(t, dLog.Mul(p, dLog.Inv(p)).IsInfinity(), "p * p^-1")
+
+		assert.True(t, dLog.Mul(inf, p).Equals(p), "infinity * p")
+		assert.True(t, dLog.Mul(p, inf).Equals(p), "p * infinity")
+		assert.True(t, dLog.Mul(p, p).Equals(dLog.Exp(p, big.NewInt(2))), "p * p")
+		assert.True(t, dLog.ExpBaseG(big.NewInt(-1)).Equals(dLog.Inv(g)), "g^-1")
+		assert.True(t, dLog.Exp(p, new(big.Int).Add(n, big.NewInt(1))).Equals(p), "p^(n+1)")
+		assert.True(t, dLog.Exp(dLog.Inv(p), big.NewInt(3)).Equals(
+			dLog.Exp(p, big.NewInt(-3))), "(p^-1)^3")
+
+		// coordinate based functions handle the same cases
+		x, y := dLog.Exponentiate(p.X, p.Y, big.NewInt(-1))
+		assert.True(t, types.NewECGroupElement(x, y).Equals(dLog.Inv(p)), "Exponentiate(p, -1)")
+		x, y = dLog.Inverse(inf.X, inf.Y)
+		assert.True(t, types.NewECGroupElement(x, y).IsInfinity(), "Inverse(infinity)")
+		x, y = dLog.Multiply(p.X, p.Y, inf.X, inf.Y)
+		assert.True(t, types.NewECGroupElement(x, y).Equals(p), "Multiply(p, infinity)")
+	}
+}
+
+// toyCurve returns y^2 = x^3 - 3x + 10 over F_1009 which has 1048 = 8 * 131 points.
+func toyCurve() *dlog.ECDLog {
+	params := &elliptic.CurveParams{
+		P:       big.NewInt(1009),
+		N:       big.NewInt(131),
+		B:       big.NewInt(10),
+		Gx:      big.NewInt(531),
+		Gy:      big.NewInt(739),
+		BitSize: 10,
+		Name:    "toy",
+	}
+	return &dlog.ECDLog{
+		Curve:           params,
+		OrderOfSubgroup: params.N,
+		Cofactor:        big.NewInt(8),
+	}
+}
+
+func TestECCofactor(t *testing.T) {
+	for _, curve := range []dlog.Curve{dlog.P224, dlog.P256, dlog.P384, dlog.P521} {
+		dLog := dlog.NewECDLog(curve)
+		p := dLog.ExpBaseG(randomInt(dLog.OrderOfSubgroup))
+		assert.Equal(t, big.NewInt(1), dLog.Cofactor)
+		assert.True(t, dLog.IsInSubgroup(p), "all points are in the subgroup for cofactor 1")
+		assert.True(t, dLog.ClearCofactor(p).Equals(p))
+	}
+
+	dLog := toyCurve()
+	g := dLog.ExpBaseG(big.NewInt(1))
+	outside := types.NewECGroupElement(big.NewInt(0), big.NewInt(162))
+	assert.True(t, dLog.IsValid(outside), "point should be on the curve")
+	assert.False(t, dLog.IsInSubgroup(outside), "point should not be in the subgroup")
+	assert.False(t, dLog.IsInSubgroup(types.NewECGroupElement(big.NewInt(1), big.NewInt(1))))
+	assert.True(t, dLog.IsInSubgroup(types.NewECGroupElementInfinity()))
+	assert.True(t, dLog.IsInSubgroup(g), "generator should be in the subgroup")
+	assert.True(t, dLog.IsInSubgroup(dLog.Exp(g, big.NewInt(77))))
+
+	cleared := dLog.ClearCofactor(outside)
+	assert.True(t, dLog.IsInSubgroup(cleared), "cleared point should be in the subgroup")
+	// (114, 0) is of order 2, clearing the cofactor maps it to the point at infinity
+	assert.True(t, dLog.ClearCofactor(
+		types.NewECGroupElement(big.NewInt(114), big.NewInt(0))).IsInfinity())
+}
+
+func TestSchnorrGroupCofactor(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	cofactor := group.Cofactor()
+	pMin := new(big.Int).Sub(group.P, big.NewInt(1))
+	assert.Equal(t, pMin, new(big.Int).Mul(cofactor, group.Q), "P - 1 = R * Q")
+
+	x := randomInt(group.P)
+	for x.Sign() == 0 {
+		x = randomInt(group.P)
+	}
+	assert.True(t, group.IsElementInGroup(group.ClearCofactor(x)),
+		"cleared element should be in the group")
+	assert.False(t, group.IsElementInGroup(pMin), "P - 1 is of order 2")
+
+	el, err := group.GetRandomElement()
+	assert.Nil(t, err)
+	assert.True(t, group.IsElementInGroup(el))
+	assert.False(t, group.IsElementInGroup(new(big.Int).Add(el, group.P)),
+		"non-canonical representation should not be accepted")
+	assert.False(t, group.IsElementInGroup(big.NewInt(0)))
+}
+
+func TestSchnorrGroupPEM(t *testing.T) {
+	// parameters generated by openssl dsaparam and openssl genpkey -genparam
+	for _, file := range []string{"dsaparams.pem", "x942params.pem", "dhparams.pem"} {
+		data, err := ioutil.ReadFile("testdata/" + file)
+		assert.Nil(t, err)
+		group, err := groups.ParsePEMParameters(data)
+		assert.Nil(t, err, file)
+		assert.Nil(t, group.Validate(), file)
+
+		for _, pemType := range []string{groups.PEMTypeDSA, groups.PEMTypeX942} {
+			encoded, err := group.MarshalPEM(pemType)
+			assert.Nil(t, err)
+			decoded, err := groups.ParsePEMParameters(encoded)
+			assert.Nil(t, err, pemType)
+			assert.Equal(t, group, decoded, "encoded group should be decoded")
+		}
+	}
+
+	group := config.LoadGroup("schnorr")
+	invalid := groups.NewSchnorrGroupFromParams(group.P, big.NewInt(1), group.Q)
+	encoded, err := invalid.MarshalPEM(groups.PEMTypeDSA)
+	assert.Nil(t, err)
+	_, err = groups.ParsePEMParameters(encoded)
+	assert.NotNil(t, err, "invalid group should not be parsed")
+	_, err = group.MarshalPEM(groups.PEMTypePKCS3)
+	assert.NotNil(t, err, "PKCS #3 parameters cannot hold Q")
+}
+
+func TestECVerifiersRejectInvalidPoints(t *testing.T) {
+	dLog := dlog.NewECDLog(dlog.P256)
+	invalid := types.NewECGroupElement(big.NewInt(1), big.NewInt(1))
+	inf := types.NewECGroupElementInfinity()
+	a := dLog.ExpBaseG(randomInt(dLog.OrderOfSubgroup))
+	b := dLog.ExpBaseG(randomInt(dLog.OrderOfSubgroup))
+
+	verifier, _ := dlogproofs.NewSchnorrECVerifier()
+	verifier.SetProofRandomData(invalid, a, b)
+	verifier.GetChallenge()
+	assert.False(t, verifier.Verify(big.NewInt(1)), "invalid proof random data")
+
+	// with the base at infinity anything could be "proved"
+	verifier.SetProofRandomData(inf, inf, b)
+	verifier.SetChallenge(big.NewInt(0))
+	assert.False(t, verifier.Verify(big.NewInt(1)), "base at infinity")
+
+	eqVerifier := dlogproofs.NewECDLogEqualityVerifier(dlog.P256)
+	eqVerifier.GetChallenge(a, invalid, b, b, a, a)
+	assert.False(t, eqVerifier.Verify(big.NewInt(1)), "invalid base")
+
+	// the identity is a valid public value: its dlog is 0
+	secret := big.NewInt(0)
+	prover, _ := dlogproofs.NewSchnorrECProver()
+	verifier.Reset()
+	x, err := prover.GetProofRandomData(secret, a)
+	assert.Nil(t, err)
+	verifier.SetProofRandomData(x, a, inf)
+	challenge, _, err := verifier.GetChallenge()
+	assert.Nil(t, err)
+	z, _, err := prover.GetProofData(challenge)
+	assert.Nil(t, err)
+	assert.True(t, verifier.Verify(z), "proof for the point at infinity")
+}
+
+func partialDLogStatements(group *groups.SchnorrGroup, k, n int) ([]*big.Int, []*big.Int,
+	[]*big.Int) {
+	a, b, secrets := make([]*big.Int, n), make([]*big.Int, n), make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		a[i] = group.Exp(group.G, randomInt(group.Q))
+		b[i] = group.Exp(a[i], randomInt(group.Q))
+	}
+	for _, i := range rand.Perm(n)[:k] {
+		secrets[i] = randomInt(group.Q)
+		b[i] = group.Exp(a[i], secrets[i])
+	}
+	return a, b, secrets
+}
+
+func TestPartialDLogKnowledgeN(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+
+	for _, kn := range [][2]int{{1, 1}, {1, 2}, {1, 10}, {4, 10}, {10, 10}, {1, 100}, {50, 100}} {
+		k, n := kn[0], kn[1]
+		a, b, secrets := partialDLogStatements(group, k, n)
+		proved, err := dlogproofs.ProvePartialDLogKnowledgeN(group, k, a, b, secrets)
+		assert.Nil(t, err)
+		assert.True(t, proved, "%d out of %d partial dlog knowledge should be proved", k, n)
+	}
+
+	a, b, secrets := partialDLogStatements(group, 2, 5)
+	_, err := dlogproofs.NewPartialDLogProverN(group, 3, a, b, secrets)
+	assert.NotNil(t, err, "prover should not prove knowledge of secrets it does not know")
+	_, err = dlogproofs.NewPartialDLogProverN(group, 0, a, b, secrets)
+	assert.NotNil(t, err, "k should be at least 1")
+
+	// prover which knows 2 secrets tries to pass a proof of knowledge of 3 secrets - it
+	// chooses the challenges of the remaining 3 proofs, which is one too many
+	prover, err := dlogproofs.NewPartialDLogProverN(group, 2, a, b, secrets)
+	assert.Nil(t, err)
+	verifier := dlogproofs.NewPartialDLogVerifierN(group, 3, a, b)
+	x, err := prover.GetProofRandomData()
+	assert.Nil(t, err)
+	verifier.SetProofRandomData(x)
+	challenge, err := verifier.GetChallenge()
+	assert.Nil(t, err)
+	challenges, z := prover.GetProofData(challenge)
+	assert.False(t, verifier.Verify(challenges, z), "2 out of 5 proof should not pass as 3 out of 5")
+
+	verifier = dlogproofs.NewPartialDLogVerifierN(group, 2, a, b)
+	x, err = prover.GetProofRandomData()
+	assert.Nil(t, err)
+	verifier.SetProofRandomData(x)
+	challenge, err = verifier.GetChallenge()
+	assert.Nil(t, err)
+	challenges, z = prover.GetProofData(challenge)
+	assert.True(t, verifier.Verify(challenges, z))
+	challenges[0] = new(big.Int).Add(challenges[0], big.NewInt(1))
+	assert.False(t, verifier.Verify(challenges, z), "modified challenge should not be accepted")
+}
+
+// partialECDLogStatements is partialDLogStatements in EC group.
+func partialECDLogStatements(ecdlog *dlog.ECDLog, k, n int) ([]*types.ECGroupElement,
+	[]*types.ECGroupElement, []*big.Int) {
+	order := ecdlog.OrderOfSubgroup
+	a, b := make([]*types.ECGroupElement, n), make([]*types.ECGroupElement, n)
+	secrets := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		a[i] = types.NewECGroupElement(ecdlog.ExponentiateBaseG(randomInt(order)))
+		b[i] = types.NewECGroupElement(ecdlog.ExponentiateBaseG(randomInt(order)))
+	}
+	for _, i := range rand.Perm(n)[:k] {
+		secrets[i] = randomInt(order)
+		b[i] = types.NewECGroupElement(ecdlog.Exponentiate(a[i].X, a[i].Y, secrets[i]))
+	}
+	return a, b, secrets
+}
+
+func TestPartialECDLogKnowledgeN(t *testing.T) {
+	ecdlog := dlog.NewECDLog(dlog.P256)
+
+	for _, kn := range [][2]int{{1, 2}, {3, 10}, {1, 100}, {30, 100}} {
+		k, n := kn[0], kn[1]
+		a, b, secrets := partialECDLogStatements(ecdlog, k, n)
+		proved, err := dlogproofs.ProvePartialECDLogKnowledgeN(ecdlog, k, a, b, secrets)
+		assert.Nil(t, err)
+		assert.True(t, proved, "%d out of %d partial EC dlog knowledge should be proved", k, n)
+	}
+
+	a, b, secrets := partialECDLogStatements(ecdlog, 1, 3)
+	prover, err := dlogproofs.NewPartialECDLogProverN(ecdlog, 1, a, b, secrets)
+	assert.Nil(t, err)
+	verifier := dlogproofs.NewPartialECDLogVerifierN(ecdlog, 2, a, b)
+	x, err := prover.GetProofRandomData()
+	assert.Nil(t, err)
+	verifier.SetProofRandomData(x)
+	challenge, err := verifier.GetChallenge()
+	assert.Nil(t, err)
+	challenges, z := prover.GetProofData(challenge)
+	assert.False(t, verifier.Verify(challenges, z), "1 out of 3 proof should not pass as 2 out of 3")
+}
+
+func TestSchnorrBatchVerifier(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	batch := dlogproofs.NewSchnorrBatchVerifier(group)
+	verified, err := batch.Verify()
+	assert.Nil(t, err)
+	assert.True(t, verified, "empty batch should be verified")
+
+	secret := randomInt(group.Q)
+	for i := 0; i < 20; i++ {
+		a := group.G
+		if i%2 == 1 {
+			// some proofs are about other bases and public keys
+			a, err = group.GetRandomElement()
+			assert.Nil(t, err)
+		}
+		b := group.Exp(a, secret)
+		prover, err := dlogproofs.NewSchnorrProver(group)
+		assert.Nil(t, err)
+		x, err := prover.GetProofRandomData(secret, a)
+		assert.Nil(t, err)
+		challenge := randomInt(group.Q)
+		z, _, err := prover.GetProofData(challenge)
+		assert.Nil(t, err)
+		batch.Add(a, b, x, challenge, z)
+	}
+	assert.Equal(t, 20, batch.Len())
+	verified, err = batch.Verify()
+	assert.Nil(t, err)
+	assert.True(t, verified, "batch of valid proofs should be verified")
+
+	prover, err := dlogproofs.NewSchnorrProver(group)
+	assert.Nil(t, err)
+	x, err := prover.GetProofRandomData(secret, group.G)
+	assert.Nil(t, err)
+	z, _, err := prover.GetProofData(big.NewInt(7))
+	assert.Nil(t, err)
+	batch.Add(group.G, group.Exp(group.G, secret), x, big.NewInt(8), z)
+	verified, err = batch.Verify()
+	assert.Nil(t, err)
+	assert.False(t, verified, "batch with invalid proof should not be verified")
+
+	// -x is not in the subgroup, a^z = (-x) * b^c does not hold, but might pass the weighted check
+	batch = dlogproofs.NewSchnorrBatchVerifier(group)
+	x, err = prover.GetProofRandomData(secret, group.G)
+	assert.Nil(t, err)
+	z, _, err = prover.GetProofData(big.NewInt(7))
+	assert.Nil(t, err)
+	batch.Add(group.G, group.Exp(group.G, secret), new(big.Int).Sub(group.P, x), big.NewInt(7), z)
+	verified, err = batch.Verify()
+	assert.Nil(t, err)
+	assert.False(t, verified, "batch with element outside of subgroup should not be verified")
+}
+
+func TestSchnorrECBatchVerifier(t *testing.T) {
+	batch := dlogproofs.NewSchnorrECBatchVerifier(dlog.P256)
+	dLog := batch.DLog
+	secret := randomInt(dLog.OrderOfSubgroup)
+	gX, gY := dLog.ExponentiateBaseG(big.NewInt(1))
+	a := types.NewECGroupElement(gX, gY)
+	bX, bY := dLog.Exponentiate(gX, gY, secret)
+	b := types.NewECGroupElement(bX, bY)
+
+	for i := 0; i < 20; i++ {
+		prover, err := dlogproofs.NewSchnorrECProver()
+		assert.Nil(t, err)
+		x, err := prover.GetProofRandomData(secret, a)
+		assert.Nil(t, err)
+		challenge := randomInt(dLog.OrderOfSubgroup)
+		z, _, err := prover.GetProofData(challenge)
+		assert.Nil(t, err)
+		batch.Add(a, b, x, challenge, z)
+	}
+	verified, err := batch.Verify()
+	assert.Nil(t, err)
+	assert.True(t, verified, "batch of valid proofs should be verified")
+
+	prover, _ := dlogproofs.NewSchnorrECProver()
+	x, err := prover.GetProofRandomData(secret, a)
+	assert.Nil(t, err)
+	z, _, err := prover.GetProofData(big.NewInt(7))
+	assert.Nil(t, err)
+	batch.Add(a, b, x, big.NewInt(7), new(big.Int).Add(z, big.NewInt(1)))
+	verified, err = batch.Verify()
+	assert.Nil(t, err)
+	assert.False(t, verified, "batch with invalid proof should not be verified")
+
+	batch = dlogproofs.NewSchnorrECBatchVerifier(dlog.P256)
+	batch.Add(a, b, types.NewECGroupElement(x.X, new(big.Int).Add(x.Y, big.NewInt(1))),
+		big.NewInt(7), z)
+	verified, err = batch.Verify()
+	assert.Nil(t, err)
+	assert.False(t, verified, "batch with point not on the curve should not be verified")
+}
+
+func TestSchnorrPrecompute(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	secret := randomInt(group.Q)
+	b := group.Exp(group.G, secret)
+
+	prover, err := dlogproofs.NewSchnorrProver(group,
+		dlogproofs.WithProtocolVariant(dlogproofs.ZKPOK{}))
+	assert.Nil(t, err)
+	assert.NotNil(t, prover.Precompute(0), "window size 0 should not be accepted")
+	assert.Nil(t, prover.Precompute(6))
+	for i := 0; i < 2; i++ {
+		verifier, err := dlogproofs.NewSchnorrVerifier(group,
+			dlogproofs.WithProtocolVariant(dlogproofs.ZKPOK{}))
+		assert.Nil(t, err)
+		commitment, err := verifier.GetOpeningMsgReply(prover.GetOpeningMsg())
+		assert.Nil(t, err)
+		prover.PedersenReceiver.SetCommitment(commitment)
+		x, err := prover.GetProofRandomData(secret, group.G)
+		assert.Nil(t, err)
+		verifier.SetProofRandomData(x, group.G, b)
+		challenge, r, err := verifier.GetChallenge()
+		assert.Nil(t, err)
+		assert.True(t, prover.PedersenReceiver.CheckDecommitment(r, challenge),
+			"decommitment should be checked with precomputed tables")
+		z, trapdoor, err := prover.GetProofData(challenge)
+		assert.Nil(t, err)
+		assert.True(t, verifier.VerifyTrapdoor(trapdoor))
+		assert.True(t, verifier.Verify(z), "proof with precomputed tables should pass")
+	}
+
+	// the tables are not used for other bases
+	a := group.Exp(group.G, randomInt(group.Q))
+	proved, err := dlogproofs.ProveDLogKnowledge(secret, a, group.Exp(a, secret), group)
+	assert.Nil(t, err)
+	assert.True(t, proved)
+
+	dLog := dlog.NewECDLog(dlog.P384)
+	g := dLog.ExpBaseG(big.NewInt(1))
+	bEC := dLog.ExpBaseG(secret)
+	proverEC, _ := dlogproofs.NewSchnorrECProver(dlogproofs.WithCurve(dlog.P384),
+		dlogproofs.WithProtocolVariant(dlogproofs.ZKPOK{}))
+	assert.NotNil(t, proverEC.Precompute(0))
+	assert.Nil(t, proverEC.Precompute(5))
+	verifierEC, err := dlogproofs.NewSchnorrECVerifier(dlogproofs.WithCurve(dlog.P384),
+		dlogproofs.WithProtocolVariant(dlogproofs.ZKPOK{}))
+	assert.Nil(t, err)
+	commitment, err := verifierEC.GetOpeningMsgReply(proverEC.GetOpeningMsg())
+	assert.Nil(t, err)
+	proverEC.PedersenReceiver.SetCommitment(commitment)
+	x, err := proverEC.GetProofRandomData(secret, g)
+	assert.Nil(t, err)
+	verifierEC.SetProofRandomData(x, g, bEC)
+	challenge, r, err := verifierEC.GetChallenge()
+	assert.Nil(t, err)
+	assert.True(t, proverEC.PedersenReceiver.CheckDecommitment(r, challenge))
+	z, trapdoor, err := proverEC.GetProofData(challenge)
+	assert.Nil(t, err)
+	assert.True(t, verifierEC.VerifyTrapdoor(trapdoor))
+	assert.True(t, verifierEC.Verify(z), "EC proof with precomputed tables should pass")
+}
+
+func benchmarkSchnorrProofRandomData(b *testing.B, window int) {
+	group := config.LoadGroup("schnorr")
+	secret := randomInt(group.Q)
+	prover, err := dlogproofs.NewSchnorrProver(group)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if window > 0 {
+		prover.Precompute(window)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		prover.GetProofRandomData(secret, group.G)
+		prover.Reset()
+	}
+}
+
+func BenchmarkSchnorrProofRandomData(b *testing.B) {
+	benchmarkSchnorrProofRandomData(b, 0)
+}
+
+func BenchmarkSchnorrProofRandomDataPrecomputed(b *testing.B) {
+	benchmarkSchnorrProofRandomData(b, 6)
+}
+
+func benchmarkSchnorrECProofRandomData(b *testing.B, curve dlog.Curve, window int) {
+	dLog := dlog.NewECDLog(curve)
+	g := dLog.ExpBaseG(big.NewInt(1))
+	secret := randomInt(dLog.OrderOfSubgroup)
+	prover, _ := dlogproofs.NewSchnorrECProver(dlogproofs.WithCurve(curve))
+	if window > 0 {
+		prover.Precompute(window)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		prover.GetProofRandomData(secret, g)
+		prover.Reset()
+	}
+}
+
+func BenchmarkSchnorrECProofRandomData(b *testing.B) {
+	benchmarkSchnorrECProofRandomData(b, dlog.P384, 0)
+}
+
+func BenchmarkSchnorrECProofRandomDataPrecomputed(b *testing.B) {
+	benchmarkSchnorrECProofRandomData(b, dlog.P384, 6)
+}
+
+// schnorrBatch returns the batch verifier with n proofs about different bases.
+func schnorrBatch(group *groups.SchnorrGroup, n int) (*dlogproofs.SchnorrBatchVerifier, error) {
+	batch := dlogproofs.NewSchnorrBatchVerifier(group)
+	secret := randomInt(group.Q)
+	prover, err := dlogproofs.NewSchnorrProver(group)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		a, err := group.GetRandomElement()
+		if err != nil {
+			return nil, err
+		}
+		x, err := prover.GetProofRandomData(secret, a)
+		if err != nil {
+			return nil, err
+		}
+		challenge := randomInt(group.Q)
+		z, _, _ := prover.GetProofData(challenge)
+		batch.Add(a, group.Exp(a, secret), x, challenge, z)
+	}
+	return batch, nil
+}
+
+func TestParallelBatchBackend(t *testing.T) {
+	defer dlogproofs.SetBatchBackend(dlogproofs.GetBatchBackend())
+	dlogproofs.SetBatchBackend(dlogproofs.NewParallelBatchBackend(4))
+
+	group := config.LoadGroup("schnorr")
+	batch, err := schnorrBatch(group, 150)
+	assert.Nil(t, err)
+	verified, err := batch.Verify()
+	assert.Nil(t, err)
+	assert.True(t, verified, "batch of valid proofs should be verified in parallel")
+	batch.Add(group.G, group.G, group.G, big.NewInt(1), big.NewInt(1))
+	verified, err = batch.Verify()
+	assert.Nil(t, err)
+	assert.False(t, verified, "batch with invalid proof should not be verified")
+
+	dLog := dlog.NewECDLog(dlog.P256)
+	batchEC := dlogproofs.NewSchnorrECBatchVerifier(dlog.P256)
+	secret := randomInt(dLog.OrderOfSubgroup)
+	prover, _ := dlogproofs.NewSchnorrECProver()
+	for i := 0; i < 100; i++ {
+		a := dLog.ExpBaseG(randomInt(dLog.OrderOfSubgroup))
+		x, err := prover.GetProofRandomData(secret, a)
+		assert.Nil(t, err)
+		challenge := randomInt(dLog.OrderOfSubgroup)
+		z, _, err := prover.GetProofData(challenge)
+		assert.Nil(t, err)
+		batchEC.Add(a, dLog.Exp(a, secret), x, challenge, z)
+	}
+	verified, err = batchEC.Verify()
+	assert.Nil(t, err)
+	assert.True(t, verified, "EC batch of valid proofs should be verified in parallel")
+	g := dLog.ExpBaseG(big.NewInt(1))
+	batchEC.Add(g, g, g, big.NewInt(1), big.NewInt(1))
+	verified, err = batchEC.Verify()
+	assert.Nil(t, err)
+	assert.False(t, verified, "EC batch with invalid proof should not be verified")
+}
+
+func benchmarkSchnorrBatchVerifier(b *testing.B, backend dlogproofs.BatchBackend) {
+	defer dlogproofs.SetBatchBackend(dlogproofs.GetBatchBackend())
+	dlogproofs.SetBatchBackend(backend)
+	batch, err := schnorrBatch(config.LoadGroup("schnorr"), 500)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		batch.Verify()
+	}
+}
+
+func BenchmarkSchnorrBatchVerifier(b *testing.B) {
+	benchmarkSchnorrBatchVerifier(b, nil)
+}
+
+func BenchmarkSchnorrBatchVerifierParallel(b *testing.B) {
+	benchmarkSchnorrBatchVerifier(b, dlogproofs.NewParallelBatchBackend(0))
+}
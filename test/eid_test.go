@@ -0,0 +1,197 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"github.com/xlab-si/emmy/eid"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// jsonAssertionVerifier accepts the assertions as JSON without checking them (the real
+// verifiers wrap SAML and OIDC libraries).
+type jsonAssertionVerifier struct{}
+
+func (jsonAssertionVerifier) Format() string {
+	return eid.FormatSAML
+}
+
+func (jsonAssertionVerifier) Verify(raw []byte) (*eid.Assertion, error) {
+	var assertion eid.Assertion
+	if err := json.Unmarshal(raw, &assertion); err != nil {
+		return nil, err
+	}
+	if assertion.Issuer != "https://eidas.example.si" {
+		return nil, fmt.Errorf("unknown eID provider")
+	}
+	return &assertion, nil
+}
+
+func TestEIDMapping(t *testing.T) {
+	mapping := eid.EIDASNaturalPersonMapping()
+	assertion := &eid.Assertion{
+		Format:  eid.FormatSAML,
+		Issuer:  "https://eidas.example.si",
+		Subject: "SI/DE/1234567890",
+		LoA:     eid.LoASubstantial,
+		Claims: map[string]string{
+			eid.EIDASPersonIdentifier: "SI/DE/1234567890",
+			eid.EIDASFamilyName:       "Novak",
+			eid.EIDASGivenName:        " Ana",
+			eid.EIDASDateOfBirth:      "1990-05-17",
+		},
+	}
+	attributes, claims, err := mapping.Map(assertion)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(claims))
+	assert.Equal(t, big.NewInt(19900517), attributes[3], "date should be encoded as YYYYMMDD")
+	givenName, _ := eid.EncodeString("Ana")
+	assert.Equal(t, givenName, attributes[2], "values should be normalized")
+
+	assertion.LoA = eid.LoALow
+	_, _, err = mapping.Map(assertion)
+	assert.NotNil(t, err, "assertion with too low level of assurance should be rejected")
+
+	assertion.LoA = eid.LoAHigh
+	delete(assertion.Claims, eid.EIDASDateOfBirth)
+	_, _, err = mapping.Map(assertion)
+	assert.NotNil(t, err, "assertion without required claim should be rejected")
+
+	oidc := &eid.Assertion{
+		Format: eid.FormatOIDC,
+		LoA:    eid.LoASubstantial,
+		Claims: map[string]string{
+			eid.OIDCSubject:    "248289761001",
+			eid.OIDCFamilyName: "Novak",
+			eid.OIDCGivenName:  "Ana",
+		},
+	}
+	attributes, claims, err = eid.OIDCMapping().Map(oidc)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(claims))
+	assert.Equal(t, big.NewInt(0), attributes[3], "missing optional claim should be mapped to 0")
+}
+
+func TestEIDIssuance(t *testing.T) {
+	cl := signatures.NewMerkleCL()
+	log := eid.NewAuditLog()
+	issuer := eid.NewIssuer(cl, log)
+	issuer.AddFormat(jsonAssertionVerifier{}, eid.EIDASNaturalPersonMapping())
+
+	raw, _ := json.Marshal(&eid.Assertion{
+		Format:   eid.FormatSAML,
+		Issuer:   "https://eidas.example.si",
+		Subject:  "SI/DE/1234567890",
+		LoA:      eid.LoAHigh,
+		IssuedAt: time.Now(),
+		Claims: map[string]string{
+			eid.EIDASPersonIdentifier: "SI/DE/1234567890",
+			eid.EIDASFamilyName:       "Novak",
+			eid.EIDASGivenName:        "Ana",
+			eid.EIDASDateOfBirth:      "1990-05-17",
+		},
+	})
+	credential, err := issuer.Issue(eid.FormatSAML, raw)
+	assert.Nil(t, err)
+	_, err = issuer.Issue(eid.FormatOIDC, raw)
+	assert.NotNil(t, err, "assertion of unsupported format should be rejected")
+
+	// holder reveals only the date of birth
+	decommitment, err := credential.Committer.GetDecommitMsg(3)
+	assert.Nil(t, err)
+	verified, err := cl.VerifyMerkleDisclosure(credential.Root, credential.Signature,
+		[]*commitments.MerkleDecommitment{decommitment})
+	assert.Nil(t, err)
+	assert.True(t, verified, "revealed attribute should be verified")
+
+	_, err = issuer.Issue(eid.FormatSAML, raw)
+	assert.Nil(t, err)
+	records := log.Records()
+	assert.Equal(t, 2, len(records))
+	assert.Equal(t, eid.SubjectHash("https://eidas.example.si", "SI/DE/1234567890"),
+		records[0].SubjectHash)
+	assert.Equal(t, credential.Root, records[0].Root)
+	assert.Nil(t, eid.VerifyAuditTrail(records, log.Head()))
+
+	records[0].Claims = records[0].Claims[:1]
+	assert.NotNil(t, eid.VerifyAuditTrail(records, log.Head()),
+		"changed audit record should be detected")
+}
+
+func TestEIDIssuanceValueEncryption(t *testing.T) {
+	ring, err := encryption.NewLocalKeyRing()
+	assert.Nil(t, err)
+	log := eid.NewAuditLog()
+	issuer := eid.NewIssuer(signatures.NewMerkleCL(), log)
+	issuer.AddFormat(jsonAssertionVerifier{}, eid.EIDASNaturalPersonMapping())
+	issuer.SetValueEncryption(ring)
+
+	for _, subject := range []string{"SI/DE/1234567890", "SI/DE/0987654321"} {
+		raw, _ := json.Marshal(&eid.Assertion{
+			Format:   eid.FormatSAML,
+			Issuer:   "https://eidas.example.si",
+			Subject:  subject,
+			LoA:      eid.LoAHigh,
+			IssuedAt: time.Now(),
+			Claims: map[string]string{
+				eid.EIDASPersonIdentifier: subject,
+				eid.EIDASFamilyName:       "Novak",
+				eid.EIDASGivenName:        "Ana",
+				eid.EIDASDateOfBirth:      "1990-05-17",
+			},
+		})
+		_, err = issuer.Issue(eid.FormatSAML, raw)
+		assert.Nil(t, err)
+	}
+	records := log.Records()
+	values, err := records[0].OpenValues(ring)
+	assert.Nil(t, err)
+	assert.Equal(t, "SI/DE/1234567890", values[eid.EIDASPersonIdentifier])
+	assert.Equal(t, "1990-05-17", values[eid.EIDASDateOfBirth])
+
+	// key rotation
+	oldKeyID := records[0].Values.KeyID
+	_, err = ring.Rotate()
+	assert.Nil(t, err)
+	assert.Nil(t, log.RewrapValues(ring))
+	assert.Nil(t, ring.DestroyKey(oldKeyID))
+	values, err = records[1].OpenValues(ring)
+	assert.Nil(t, err)
+	assert.Equal(t, "SI/DE/0987654321", values[eid.EIDASPersonIdentifier])
+
+	// crypto-erasure of the values of one subject
+	erased := log.EraseSubject(eid.SubjectHash("https://eidas.example.si", "SI/DE/1234567890"))
+	assert.Equal(t, 1, erased)
+	_, err = records[0].OpenValues(ring)
+	assert.Equal(t, encryption.ErrErased, err)
+	_, err = records[1].OpenValues(ring)
+	assert.Nil(t, err)
+	assert.Nil(t, eid.VerifyAuditTrail(records, log.Head()),
+		"rotation and erasure should not break the hash chain")
+
+	records[1].Values.Ciphertext[0] ^= 1
+	assert.NotNil(t, eid.VerifyAuditTrail(records, log.Head()),
+		"changed values should be detected")
+}
@@ -0,0 +1,260 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/encproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/fiatshamir"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+var testPaillier = encryption.NewPaillier(512)
+
+var testCSPaillierKey struct {
+	once       sync.Once
+	cspaillier *encryption.CSPaillier
+	err        error
+}
+
+// getTestCSPaillier returns CS Paillier key pair which is generated only once, as
+// the generation is slow.
+func getTestCSPaillier(t *testing.T) *encryption.CSPaillier {
+	testCSPaillierKey.once.Do(func() {
+		testCSPaillierKey.cspaillier, testCSPaillierKey.err = encryption.NewCSPaillier(
+			&encryption.CSPaillierSecParams{
+				L:        512,
+				RoLength: 160,
+				K:        158,
+				K1:       158,
+			})
+	})
+	if testCSPaillierKey.err != nil {
+		t.Fatalf("Error when generating CSPaillier key: %v", testCSPaillierKey.err)
+	}
+	return testCSPaillierKey.cspaillier
+}
+
+func TestPaillierPlaintextKnowledge(t *testing.T) {
+	pubKey := testPaillier.GetPubKey()
+	m := randomInt(pubKey.GetN())
+	r, err := common.GetRandomZnInvertibleElement(pubKey.GetN())
+	assert.Nil(t, err)
+
+	proved, err := encproofs.ProvePaillierPlaintextKnowledge(pubKey, m, r)
+	assert.Nil(t, err)
+	assert.True(t, proved, "Proof of Paillier plaintext knowledge does not work correctly")
+
+	// prover knows the plaintext of another ciphertext
+	c, _ := testPaillier.EncryptWithR(new(big.Int).Add(m, big.NewInt(1)), r)
+	prover, _ := encproofs.NewPaillierPlaintextProver(pubKey, m, r)
+	verifier, err := encproofs.NewPaillierPlaintextVerifier(pubKey, c)
+	assert.Nil(t, err)
+	a, _ := prover.GetProofRandomData()
+	assert.Nil(t, verifier.SetProofRandomData(a))
+	challenge, _ := verifier.GetChallenge()
+	assert.False(t, verifier.Verify(prover.GetProofData(challenge)),
+		"Proof for another ciphertext should not be accepted")
+}
+
+func TestPaillierCommittedPlaintext(t *testing.T) {
+	pubKey := testPaillier.GetPubKey()
+	group := config.LoadGroup("pedersen")
+	h, err := group.GetRandomElement()
+	assert.Nil(t, err)
+	m := randomInt(group.Q)
+	r, err := common.GetRandomZnInvertibleElement(pubKey.GetN())
+	assert.Nil(t, err)
+	tr := randomInt(group.Q)
+
+	proved, err := encproofs.ProvePaillierCommittedPlaintext(pubKey, group, h, m, r, tr)
+	assert.Nil(t, err)
+	assert.True(t, proved, "Proof of committed Paillier plaintext does not work correctly")
+
+	// ciphertext encrypts another value than the committed one
+	c, _ := testPaillier.EncryptWithR(new(big.Int).Add(m, big.NewInt(1)), r)
+	commitment := group.Mul(group.Exp(group.G, m), group.Exp(h, tr))
+	prover, _ := encproofs.NewPaillierCommittedPlaintextProver(pubKey, group, h, m, r, tr)
+	verifier, err := encproofs.NewPaillierCommittedPlaintextVerifier(pubKey, group, h, c,
+		commitment)
+	assert.Nil(t, err)
+	a, commitmentA, _ := prover.GetProofRandomData()
+	assert.Nil(t, verifier.SetProofRandomData(a, commitmentA))
+	challenge, _ := verifier.GetChallenge()
+	assert.False(t, verifier.Verify(prover.GetProofData(challenge)),
+		"Proof for another ciphertext should not be accepted")
+
+	smallKey := encryption.NewPaillier(128).GetPubKey()
+	_, err = encproofs.NewPaillierCommittedPlaintextProver(smallKey, group, h, m, r, tr)
+	assert.NotNil(t, err, "Paillier modulus shorter than the bound should not be accepted")
+}
+
+// TestGRPC_PaillierPlaintext requires a running server (it is started in communication_test.go).
+func TestGRPC_PaillierPlaintext(t *testing.T) {
+	pubKey := testPaillier.GetPubKey()
+	group := config.LoadGroup("pedersen")
+	m := randomInt(group.Q)
+
+	c, err := client.NewPaillierPlaintextClient(testGrpcClientConn, pubKey, m)
+	assert.Nil(t, err)
+	proved, err := c.Run()
+	assert.Nil(t, err)
+	assert.True(t, proved, "Proof of Paillier plaintext knowledge should be accepted")
+
+	c, err = client.NewPaillierCommittedPlaintextClient(testGrpcClientConn, group, pubKey, m)
+	assert.Nil(t, err)
+	proved, err = c.Run()
+	assert.Nil(t, err)
+	assert.True(t, proved, "Proof of committed Paillier plaintext should be accepted")
+
+	p, err := testPaillier.Decrypt(c.GetCiphertext())
+	assert.Nil(t, err)
+	assert.Equal(t, m, p, "Escrowed value should be recovered by decryption")
+}
+
+func TestCSDLogEncryption(t *testing.T) {
+	cspaillier := getTestCSPaillier(t)
+	group := config.LoadGroup("pseudonymsys")
+	base := group.Exp(group.G, randomInt(group.Q))
+	m := randomInt(group.Q)
+	label := big.NewInt(42)
+
+	proved, err := encproofs.ProveCSDLogEncryption(cspaillier.PubKey, group, base, m, label)
+	assert.Nil(t, err)
+	assert.True(t, proved, "Verifiable encryption of discrete logarithm does not work correctly")
+
+	prover, err := encproofs.NewCSDLogEncryptionProver(cspaillier.PubKey, group, base, m, label)
+	assert.Nil(t, err)
+	enc := prover.GetEncryption()
+	decrypted, err := cspaillier.Decrypt(enc.U, enc.E, enc.V, label)
+	assert.Nil(t, err)
+	assert.Equal(t, m, decrypted, "Decrypted value should be the discrete logarithm")
+
+	// the ciphertext does not contain log_base(delta)
+	delta := group.Exp(base, new(big.Int).Add(m, big.NewInt(1)))
+	verifier, err := encproofs.NewCSDLogEncryptionVerifier(cspaillier.PubKey, group, base, delta,
+		label)
+	assert.Nil(t, err)
+	randomData, _ := prover.GetProofRandomData()
+	assert.Nil(t, verifier.SetProofRandomData(enc, randomData))
+	challenge, _ := verifier.GetChallenge()
+	assert.False(t, verifier.Verify(prover.GetProofData(challenge)),
+		"Proof for another discrete logarithm should not be accepted")
+
+	// the ciphertext is encrypted under another label
+	verifier, _ = encproofs.NewCSDLogEncryptionVerifier(cspaillier.PubKey, group, base,
+		group.Exp(base, m), big.NewInt(43))
+	randomData, _ = prover.GetProofRandomData()
+	assert.Nil(t, verifier.SetProofRandomData(enc, randomData))
+	challenge, _ = verifier.GetChallenge()
+	assert.False(t, verifier.Verify(prover.GetProofData(challenge)),
+		"Proof for another label should not be accepted")
+}
+
+func TestElGamalPlaintextEquality(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	elgamal1, _ := encryption.NewElGamal(group)
+	elgamal2, _ := encryption.NewElGamal(group)
+	pubKey1, pubKey2 := elgamal1.GetPubKey(), elgamal2.GetPubKey()
+	m, err := group.GetRandomElement()
+	assert.Nil(t, err)
+	r1, r2 := randomInt(group.Q), randomInt(group.Q)
+
+	proved, err := encproofs.ProveElGamalPlaintextEquality(pubKey1, pubKey2, m, r1, r2)
+	assert.Nil(t, err)
+	assert.True(t, proved, "Proof of ElGamal plaintext equality does not work correctly")
+
+	proved, err = encproofs.ProveElGamalPlaintextEquality(pubKey1, pubKey1, m, r1, r2)
+	assert.Nil(t, err)
+	assert.True(t, proved, "Proof of plaintext equality under the same key should be accepted")
+
+	c1, _ := pubKey1.EncryptWithR(m, r1)
+	proved, err = encproofs.ProveElGamalKeyRotation(elgamal1, pubKey2, c1, r2)
+	assert.Nil(t, err)
+	assert.True(t, proved, "Proof of ElGamal key rotation does not work correctly")
+
+	// the second ciphertext encrypts another plaintext
+	c2, _ := pubKey2.EncryptWithR(group.Mul(m, group.G), r2)
+	prover, err := encproofs.NewElGamalPlaintextEqualityProver(pubKey1, pubKey2, c1, c2, r1, r2)
+	assert.Nil(t, err)
+	verifier, err := encproofs.NewElGamalPlaintextEqualityVerifier(pubKey1, pubKey2, c1, c2)
+	assert.Nil(t, err)
+	data, _ := prover.GetProofRandomData()
+	assert.Nil(t, verifier.SetProofRandomData(data))
+	challenge, _ := verifier.GetChallenge()
+	assert.False(t, verifier.Verify(prover.GetProofData(challenge)),
+		"Proof for different plaintexts should not be accepted")
+
+	prover, _ = encproofs.NewElGamalKeyRotationProver(elgamal1, pubKey2, c1, c2, r2)
+	verifier, _ = encproofs.NewElGamalKeyRotationVerifier(pubKey1, pubKey2, c1, c2)
+	data, _ = prover.GetProofRandomData()
+	assert.Nil(t, verifier.SetProofRandomData(data))
+	challenge, _ = verifier.GetChallenge()
+	assert.False(t, verifier.Verify(prover.GetProofData(challenge)),
+		"Key rotation proof for different plaintexts should not be accepted")
+
+	otherGroup, _ := groups.NewSchnorrSafePrimeGroup(64)
+	otherKey, _ := encryption.NewElGamal(otherGroup)
+	_, err = encproofs.NewElGamalPlaintextEqualityVerifier(pubKey1, otherKey.GetPubKey(), c1, c2)
+	assert.NotNil(t, err, "Public keys from different groups should not be accepted")
+}
+
+func TestElGamalDecryption(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	elgamal, _ := encryption.NewElGamal(group)
+	pubKey := elgamal.GetPubKey()
+	m, err := group.GetRandomElement()
+	assert.Nil(t, err)
+	c, _ := pubKey.Encrypt(m)
+
+	proved, err := encproofs.ProveElGamalDecryption(elgamal, c)
+	assert.Nil(t, err)
+	assert.True(t, proved, "Proof of ElGamal decryption does not work correctly")
+
+	// the prover claims another plaintext
+	prover, err := encproofs.NewElGamalDecryptionProver(elgamal, c)
+	assert.Nil(t, err)
+	assert.Equal(t, m, prover.GetPlaintext())
+	verifier, err := encproofs.NewElGamalDecryptionVerifier(pubKey, c, group.Mul(m, group.G))
+	assert.Nil(t, err)
+	x1, x2, err := prover.GetProofRandomData()
+	assert.Nil(t, err)
+	challenge, err := verifier.GetChallenge(x1, x2)
+	assert.Nil(t, err)
+	z, err := prover.GetProofData(challenge)
+	assert.Nil(t, err)
+	assert.False(t, verifier.Verify(z), "Proof for a wrong plaintext should not be accepted")
+
+	_, err = encproofs.NewElGamalDecryptionVerifier(pubKey, c, big.NewInt(0))
+	assert.NotNil(t, err, "Plaintext which is not from the group should not be accepted")
+
+	// non-interactive variant
+	s, _ := encproofs.NewElGamalDecryptionStatement(pubKey, c, m)
+	proof, err := fiatshamir.Prove(fiatshamir.NewDLogEqualityProver(group, elgamal.GetSecretKey(),
+		s.G1, s.G2, s.T1, s.T2), nil)
+	assert.Nil(t, err)
+	assert.True(t, fiatshamir.Verify(fiatshamir.NewDLogEqualityVerifier(group, s.G1, s.G2,
+		s.T1, s.T2), proof, nil), "Non-interactive proof of decryption should be verified")
+}
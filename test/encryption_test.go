@@ -20,7 +20,6 @@ package test
 import (
 	"github.com/stretchr/testify/assert"
 	"github.com/xlab-si/emmy/config"
-	"github.com/xlab-si/emmy/crypto/common"
 	"github.com/xlab-si/emmy/crypto/encryption"
 	"math/big"
 	"path/filepath"
@@ -31,7 +30,7 @@ func TestPaillier(t *testing.T) {
 	paillier := encryption.NewPaillier(1024)
 	pubKey := paillier.GetPubKey()
 
-	m := common.GetRandomInt(big.NewInt(123412341234123))
+	m := randomInt(big.NewInt(123412341234123))
 	pubPaillier := encryption.NewPubPaillier(pubKey)
 	c, _ := pubPaillier.Encrypt(m)
 	p, _ := paillier.Decrypt(c)
@@ -51,14 +50,17 @@ func TestCSPaillier(t *testing.T) {
 	secKeyPath := filepath.Join(dir, "cspaillierseckey.txt")
 	pubKeyPath := filepath.Join(dir, "cspaillierpubkey.txt")
 
-	cspaillier := encryption.NewCSPaillier(&secParams)
+	cspaillier, err := encryption.NewCSPaillier(&secParams)
+	if err != nil {
+		t.Fatalf("Error when generating CSPaillier key: %v", err)
+	}
 	cspaillier.StoreSecKey(secKeyPath)
 	cspaillier.StorePubKey(pubKeyPath)
 
 	cspaillierPub, _ := encryption.NewCSPaillierFromPubKeyFile(pubKeyPath)
 
-	m := common.GetRandomInt(big.NewInt(8685849))
-	label := common.GetRandomInt(big.NewInt(340002223232))
+	m := randomInt(big.NewInt(8685849))
+	label := randomInt(big.NewInt(340002223232))
 	u, e, v, _ := cspaillierPub.Encrypt(m, label)
 
 	cspaillierSec, _ := encryption.NewCSPaillierFromSecKey(secKeyPath)
@@ -66,3 +68,101 @@ func TestCSPaillier(t *testing.T) {
 
 	assert.Equal(t, m, p, "Camenisch-Shoup modified Paillier encryption/decryption does not work correctly")
 }
+
+func TestHybridKEM(t *testing.T) {
+	key, err := encryption.NewHybridKEMPrivateKey()
+	assert.Nil(t, err)
+	sharedKey, ct, err := encryption.HybridEncapsulate(key.PublicKey())
+	assert.Nil(t, err)
+	decapsulated, err := key.Decapsulate(ct)
+	assert.Nil(t, err)
+	assert.Equal(t, sharedKey, decapsulated, "both sides should obtain the same key")
+
+	otherKey, _ := encryption.NewHybridKEMPrivateKey()
+	decapsulated, _ = otherKey.Decapsulate(ct)
+	assert.NotEqual(t, sharedKey, decapsulated, "other key should not obtain the same key")
+
+	initiator, err := encryption.NewChannelCipher(sharedKey, true)
+	assert.Nil(t, err)
+	responder, err := encryption.NewChannelCipher(sharedKey, false)
+	assert.Nil(t, err)
+
+	c1 := initiator.Seal([]byte("first"))
+	c2 := initiator.Seal([]byte("second"))
+	_, err = responder.Open(c2)
+	assert.NotNil(t, err, "reordered message should not be decrypted")
+	p1, err := responder.Open(c1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("first"), p1)
+	p2, err := responder.Open(c2)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("second"), p2)
+	_, err = responder.Open(c2)
+	assert.NotNil(t, err, "replayed message should not be decrypted")
+
+	c3 := responder.Seal([]byte("third"))
+	_, err = responder.Open(c3)
+	assert.NotNil(t, err, "reflected message should not be decrypted")
+	c3[0] ^= 1
+	_, err = initiator.Open(c3)
+	assert.NotNil(t, err, "tampered message should not be decrypted")
+}
+
+func TestElGamal(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	elgamal, err := encryption.NewElGamal(group)
+	assert.Nil(t, err)
+	pubKey := elgamal.GetPubKey()
+
+	m, err := group.GetRandomElement()
+	assert.Nil(t, err)
+	c, err := pubKey.Encrypt(m)
+	assert.Nil(t, err)
+	p, err := elgamal.Decrypt(c)
+	assert.Nil(t, err)
+	assert.Equal(t, m, p, "ElGamal encryption/decryption does not work correctly")
+
+	c1 := pubKey.ReEncrypt(c, randomInt(group.Q))
+	assert.False(t, c.Equals(c1), "Re-encryption should change the ciphertext")
+	p, err = elgamal.Decrypt(c1)
+	assert.Nil(t, err)
+	assert.Equal(t, m, p, "Re-encrypted ciphertext should decrypt to the same message")
+
+	_, err = pubKey.Encrypt(new(big.Int).Add(group.P, big.NewInt(1)))
+	assert.NotNil(t, err, "Message which is not from the group should not be accepted")
+}
+
+func TestEnvelope(t *testing.T) {
+	ring, err := encryption.NewLocalKeyRing()
+	assert.Nil(t, err)
+	ad := []byte("record 1")
+	env, err := encryption.Seal(ring, []byte("Ana Novak"), ad)
+	assert.Nil(t, err)
+	plaintext, err := env.Open(ring, ad)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("Ana Novak"), plaintext)
+	_, err = env.Open(ring, []byte("record 2"))
+	assert.NotNil(t, err, "envelope should not be opened with different associated data")
+
+	// rotation - the old key can be destroyed after rewrapping
+	oldKeyID := env.KeyID
+	ciphertext := env.Ciphertext
+	other, err := encryption.Seal(ring, []byte("Janez Novak"), ad)
+	assert.Nil(t, err)
+	newKeyID, err := ring.Rotate()
+	assert.Nil(t, err)
+	assert.Nil(t, env.Rewrap(ring))
+	assert.Equal(t, newKeyID, env.KeyID)
+	assert.Equal(t, ciphertext, env.Ciphertext, "rewrapping should not change the ciphertext")
+	assert.NotNil(t, ring.DestroyKey(newKeyID), "current key should not be destroyed")
+	assert.Nil(t, ring.DestroyKey(oldKeyID))
+	plaintext, err = env.Open(ring, ad)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("Ana Novak"), plaintext)
+	_, err = other.Open(ring, ad)
+	assert.Equal(t, encryption.ErrErased, err, "data wrapped with destroyed key should be erased")
+
+	env.Erase()
+	_, err = env.Open(ring, ad)
+	assert.Equal(t, encryption.ErrErased, err)
+}
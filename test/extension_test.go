@@ -0,0 +1,114 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package test
+
+import (
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/server"
+	"strconv"
+	"testing"
+)
+
+// echoExtension returns the received payloads back to the client, the value of
+// the last payload is replaced by the number of runs of the extension.
+type echoExtension struct{}
+
+func (e *echoExtension) Name() string {
+	return "echo"
+}
+
+func (e *echoExtension) Run(payload *any.Any, stream server.ExtensionStream,
+	storage server.ExtensionStorage) error {
+	runs := 0
+	if value, ok := storage.Get("runs"); ok {
+		runs, _ = strconv.Atoi(string(value))
+	}
+	runs++
+	if err := storage.Put("runs", []byte(strconv.Itoa(runs))); err != nil {
+		return err
+	}
+
+	if err := stream.Send(payload); err != nil {
+		return err
+	}
+	payload, err := stream.Receive()
+	if err != nil {
+		return err
+	}
+	return stream.Send(&any.Any{
+		TypeUrl: payload.TypeUrl,
+		Value:   []byte(strconv.Itoa(runs)),
+	})
+}
+
+func init() {
+	server.RegisterExtension(&echoExtension{})
+}
+
+// namedExtension is echoExtension registered under another name.
+type namedExtension struct {
+	echoExtension
+	name string
+}
+
+func (e *namedExtension) Name() string {
+	return e.name
+}
+
+func TestRegisterExtension(t *testing.T) {
+	ext, ok := server.GetExtension("echo")
+	assert.True(t, ok, "registered extension not found")
+	assert.Equal(t, "echo", ext.Name())
+
+	err := server.RegisterExtension(&echoExtension{})
+	assert.NotNil(t, err, "extension registered twice")
+
+	_, ok = server.GetExtension("nonexistent")
+	assert.False(t, ok, "unregistered extension found")
+
+	// the key k of extension echo/runs would be the same as the key runs/k of echo
+	err = server.RegisterExtension(&namedExtension{name: "echo/runs"})
+	assert.NotNil(t, err, "extension name with / should be rejected")
+	_, ok = server.GetExtension("echo/runs")
+	assert.False(t, ok, "extension name with / should not be registered")
+}
+
+func TestExtension(t *testing.T) {
+	for i := 1; i <= 2; i++ {
+		c, err := client.NewExtensionClient(testGrpcClientConn, "echo")
+		assert.Nil(t, err)
+
+		payload := &any.Any{TypeUrl: "test/echo", Value: []byte("hello")}
+		resp, err := c.Exchange(payload)
+		assert.Nil(t, err)
+		assert.Equal(t, payload.Value, resp.Value, "extension did not echo the payload")
+
+		resp, err = c.Exchange(payload)
+		assert.Nil(t, err)
+		assert.Equal(t, strconv.Itoa(i), string(resp.Value),
+			"extension state was not kept in the storage")
+		assert.Nil(t, c.Close())
+	}
+
+	c, err := client.NewExtensionClient(testGrpcClientConn, "nonexistent")
+	assert.Nil(t, err)
+	_, err = c.Exchange(&any.Any{})
+	assert.NotNil(t, err, "unregistered extension should not run")
+	c.Close()
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/factorization"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+	"testing"
+)
+
+func TestFactorizationKnowledge(t *testing.T) {
+	rsa, err := signatures.NewRSA(1024)
+	if err != nil {
+		t.Fatalf("error when generating RSA key: %v", err)
+	}
+	n := rsa.GetPubKey().N
+	p, q := rsa.GetPrimes()[0], rsa.GetPrimes()[1]
+
+	proved, err := factorization.ProveFactorizationKnowledge(n, p, q)
+	assert.Nil(t, err)
+	assert.True(t, proved, "proof of knowledge of factorization should pass")
+
+	_, err = factorization.NewFactorizationProver(n, p, big.NewInt(3))
+	assert.NotNil(t, err, "prover should not accept wrong factors")
+	_, err = factorization.NewFactorizationVerifier(new(big.Int).Lsh(n, 1))
+	assert.NotNil(t, err, "verifier should not accept even modulus")
+
+	// prover which knows the factorization of another modulus
+	other, _ := signatures.NewRSA(1024)
+	prover, _ := factorization.NewFactorizationProver(other.GetPubKey().N,
+		other.GetPrimes()[0], other.GetPrimes()[1])
+	verifier, _ := factorization.NewFactorizationVerifier(n)
+	x, _ := prover.GetProofRandomData()
+	challenge, _ := verifier.GetChallenge(x)
+	y, _ := prover.GetProofData(challenge)
+	assert.False(t, verifier.Verify(y), "proof for other modulus should fail")
+
+	// CA proves that its RSA modulus is well-formed
+	caProver, err := pseudonymsys.NewCAModulusProver(rsa)
+	assert.Nil(t, err)
+	caVerifier, err := pseudonymsys.NewCAModulusVerifier(rsa.GetPubKey())
+	assert.Nil(t, err)
+	x, _ = caProver.GetProofRandomData()
+	challenge, err = caVerifier.GetChallenge(x)
+	assert.Nil(t, err)
+	y, err = caProver.GetProofData(challenge)
+	assert.Nil(t, err)
+	assert.True(t, caVerifier.Verify(y), "proof of the CA modulus should pass")
+}
@@ -0,0 +1,395 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/fiatshamir"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestFiatShamirSchnorr(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	secret := randomInt(group.Q)
+	b := group.Exp(group.G, secret)
+	context := []byte("verifier1")
+
+	proof, err := fiatshamir.Prove(fiatshamir.NewSchnorrProver(group, secret, group.G, b), context)
+	assert.Nil(t, err)
+	blob, err := proof.Marshal()
+	assert.Nil(t, err)
+	proof, err = fiatshamir.UnmarshalProof(blob)
+	assert.Nil(t, err)
+
+	verifier := fiatshamir.NewSchnorrVerifier(group, group.G, b)
+	assert.True(t, fiatshamir.Verify(verifier, proof, context), "proof should be verified")
+	assert.False(t, fiatshamir.Verify(verifier, proof, []byte("verifier2")),
+		"proof should not be verified in another context")
+	other := fiatshamir.NewSchnorrVerifier(group, group.G, group.Mul(b, group.G))
+	assert.False(t, fiatshamir.Verify(other, proof, context),
+		"proof should not be verified for another statement")
+}
+
+func TestFiatShamirSchnorrEC(t *testing.T) {
+	dLog := dlog.NewECDLog(dlog.P256)
+	secret := randomInt(dLog.OrderOfSubgroup)
+	aX, aY := dLog.ExponentiateBaseG(randomInt(dLog.OrderOfSubgroup))
+	bX, bY := dLog.Exponentiate(aX, aY, secret)
+	a := types.NewECGroupElement(aX, aY)
+	b := types.NewECGroupElement(bX, bY)
+
+	proof, err := fiatshamir.Prove(fiatshamir.NewSchnorrECProver(dlog.P256, secret, a, b), nil)
+	assert.Nil(t, err)
+	verifier := fiatshamir.NewSchnorrECVerifier(dlog.P256, a, b)
+	assert.True(t, fiatshamir.Verify(verifier, proof, nil), "proof should be verified")
+
+	proof.ProofData[0] = new(big.Int).Add(proof.ProofData[0], big.NewInt(1))
+	assert.False(t, fiatshamir.Verify(verifier, proof, nil), "changed proof should not be verified")
+}
+
+func TestFiatShamirDLogEquality(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	secret := randomInt(group.Q)
+	g1 := group.G
+	g2 := group.Exp(group.G, randomInt(group.Q))
+	t1 := group.Exp(g1, secret)
+	t2 := group.Exp(g2, secret)
+
+	proof, err := fiatshamir.Prove(fiatshamir.NewDLogEqualityProver(group, secret, g1, g2, t1, t2),
+		nil)
+	assert.Nil(t, err)
+	verifier := fiatshamir.NewDLogEqualityVerifier(group, g1, g2, t1, t2)
+	assert.True(t, fiatshamir.Verify(verifier, proof, nil), "proof should be verified")
+
+	verifier = fiatshamir.NewDLogEqualityVerifier(group, g1, g2, t1, group.Mul(t2, g2))
+	assert.False(t, fiatshamir.Verify(verifier, proof, nil),
+		"proof of unequal dlogs should not be verified")
+}
+
+func TestFiatShamirDHTuple(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	a := randomInt(group.Q)
+	g := group.G
+	ga := group.Exp(g, a)
+	gb := group.Exp(g, randomInt(group.Q))
+	gab := group.Exp(gb, a)
+
+	proof, err := fiatshamir.Prove(fiatshamir.NewDHTupleProver(group, a, g, ga, gb, gab), nil)
+	assert.Nil(t, err)
+	verifier := fiatshamir.NewDHTupleVerifier(group, g, ga, gb, gab)
+	assert.True(t, fiatshamir.Verify(verifier, proof, nil), "proof should be verified")
+	verifier = fiatshamir.NewDHTupleVerifier(group, g, ga, gab, gb)
+	assert.False(t, fiatshamir.Verify(verifier, proof, nil),
+		"proof of another tuple should not be verified")
+
+	dLog := dlog.NewECDLog(dlog.P256)
+	a = randomInt(dLog.OrderOfSubgroup)
+	gEC := types.NewECGroupElement(dLog.ExponentiateBaseG(big.NewInt(1)))
+	gaEC := dLog.Exp(gEC, a)
+	gbEC := dLog.Exp(gEC, randomInt(dLog.OrderOfSubgroup))
+	gabEC := dLog.Exp(gbEC, a)
+
+	proof, err = fiatshamir.Prove(fiatshamir.NewECDHTupleProver(dlog.P256, a, gEC, gaEC, gbEC,
+		gabEC), nil)
+	assert.Nil(t, err)
+	ecVerifier := fiatshamir.NewECDHTupleVerifier(dlog.P256, gEC, gaEC, gbEC, gabEC)
+	assert.True(t, fiatshamir.Verify(ecVerifier, proof, nil), "EC proof should be verified")
+	proof.ProofData[0] = new(big.Int).Add(proof.ProofData[0], big.NewInt(1))
+	assert.False(t, fiatshamir.Verify(ecVerifier, proof, nil),
+		"changed proof should not be verified")
+}
+
+func TestFiatShamirPartialDLog(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	secret1 := randomInt(group.Q)
+	a1 := group.G
+	b1 := group.Exp(a1, secret1)
+	a2 := group.Exp(group.G, randomInt(group.Q))
+	b2 := group.Exp(group.G, randomInt(group.Q))
+
+	proof, err := fiatshamir.Prove(fiatshamir.NewPartialDLogProver(group, secret1, a1, b1, a2, b2),
+		nil)
+	assert.Nil(t, err)
+	// the verifier does not know which of the two dlogs is known by the prover
+	verifier := fiatshamir.NewPartialDLogVerifier(group, a2, b2, a1, b1)
+	assert.True(t, fiatshamir.Verify(verifier, proof, nil), "proof should be verified")
+
+	b3 := group.Exp(group.G, randomInt(group.Q))
+	verifier = fiatshamir.NewPartialDLogVerifier(group, a1, b1, a2, b3)
+	assert.False(t, fiatshamir.Verify(verifier, proof, nil),
+		"proof about another statement should not be verified")
+}
+
+func TestFiatShamirSimulate(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	a := group.Exp(group.G, randomInt(group.Q))
+	b := group.Exp(group.G, randomInt(group.Q))
+	dLog := dlog.NewECDLog(dlog.P256)
+	aEC := dLog.ExpBaseG(randomInt(dLog.OrderOfSubgroup))
+	bEC := dLog.ExpBaseG(randomInt(dLog.OrderOfSubgroup))
+
+	verifiers := []fiatshamir.Verifier{
+		fiatshamir.NewSchnorrVerifier(group, a, b),
+		fiatshamir.NewSchnorrECVerifier(dlog.P256, aEC, bEC),
+		fiatshamir.NewDLogEqualityVerifier(group, group.G, a, b, group.G),
+		fiatshamir.NewPartialDLogVerifier(group, group.G, a, b, group.G),
+	}
+	for _, verifier := range verifiers {
+		simulator := verifier.(fiatshamir.Simulator)
+		challenge := randomInt(verifier.ChallengeSpace())
+		proofRandomData, proofData, err := simulator.Simulate(challenge)
+		assert.Nil(t, err)
+		assert.True(t, verifier.Verify(proofRandomData, challenge, proofData),
+			"simulated transcript of %s should be verified", verifier.Name())
+		// the simulated transcript is not a non-interactive proof
+		proof := &fiatshamir.Proof{ProofRandomData: proofRandomData, ProofData: proofData}
+		assert.False(t, fiatshamir.Verify(verifier, proof, nil),
+			"simulated transcript of %s should not be a valid proof", verifier.Name())
+	}
+}
+
+func TestFiatShamirSquare(t *testing.T) {
+	params := getTestDFParams(t)
+	x := big.NewInt(12345)
+	r := randomInt(params.RandomnessBound())
+	c := params.Commit(new(big.Int).Mul(x, x), r)
+	context := []byte("verifier1")
+
+	proof, err := fiatshamir.Prove(fiatshamir.NewDamgardFujisakiSquareProver(params, c, x, r), context)
+	assert.Nil(t, err)
+	blob, err := proof.Marshal()
+	assert.Nil(t, err)
+	proof, err = fiatshamir.UnmarshalProof(blob)
+	assert.Nil(t, err)
+	verifier := fiatshamir.NewDamgardFujisakiSquareVerifier(params, c)
+	assert.True(t, fiatshamir.Verify(verifier, proof, context), "proof should be verified")
+	other := fiatshamir.NewDamgardFujisakiSquareVerifier(params, params.Commit(x, r))
+	assert.False(t, fiatshamir.Verify(other, proof, context),
+		"proof should not be verified for another commitment")
+
+	group := config.LoadGroup("pedersen")
+	h, err := group.GetRandomElement()
+	assert.Nil(t, err)
+	r = randomInt(group.Q)
+	c = group.Mul(group.Exp(group.G, new(big.Int).Mul(x, x)), group.Exp(h, r))
+	proof, err = fiatshamir.Prove(fiatshamir.NewPedersenSquareProver(group, h, c, x, r), context)
+	assert.Nil(t, err)
+	assert.True(t, fiatshamir.Verify(fiatshamir.NewPedersenSquareVerifier(group, h, c), proof,
+		context), "Pedersen square proof should be verified")
+}
+
+func TestFiatShamirNonNegative(t *testing.T) {
+	params := getTestDFParams(t)
+	x := big.NewInt(2017)
+	r := randomInt(params.RandomnessBound())
+	c := params.Commit(x, r)
+	context := []byte("verifier1")
+
+	prover, err := fiatshamir.NewNonNegativeProver(params, c, x, r)
+	assert.Nil(t, err)
+	proof, err := fiatshamir.Prove(prover, context)
+	assert.Nil(t, err)
+	blob, err := proof.Marshal()
+	assert.Nil(t, err)
+	proof, err = fiatshamir.UnmarshalProof(blob)
+	assert.Nil(t, err)
+	verifier := fiatshamir.NewNonNegativeVerifier(params, c)
+	assert.True(t, fiatshamir.Verify(verifier, proof, context), "proof should be verified")
+	assert.False(t, fiatshamir.Verify(verifier, proof, []byte("verifier2")),
+		"proof should not be verified in another context")
+}
+
+func TestFiatShamirVerifyEncoded(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	secret := randomInt(group.Q)
+	g2 := group.Exp(group.G, randomInt(group.Q))
+	t1 := group.Exp(group.G, secret)
+	t2 := group.Exp(g2, secret)
+	context := []byte("verifier1")
+
+	nip, err := fiatshamir.Prove(fiatshamir.NewSchnorrProver(group, secret, group.G, t1),
+		context)
+	assert.Nil(t, err)
+	proof, err := nip.Marshal()
+	assert.Nil(t, err)
+	assert.True(t, fiatshamir.VerifySchnorr(proof, context, group, group.G, t1),
+		"proof should be verified")
+	assert.False(t, fiatshamir.VerifySchnorr(proof, nil, group, group.G, t1),
+		"proof should not be verified in another context")
+	assert.False(t, fiatshamir.VerifySchnorr(proof[1:], context, group, group.G, t1),
+		"malformed proof should not be verified")
+
+	nip, err = fiatshamir.Prove(fiatshamir.NewDLogEqualityProver(group, secret, group.G, g2,
+		t1, t2), context)
+	assert.Nil(t, err)
+	proof, err = nip.Marshal()
+	assert.Nil(t, err)
+	assert.True(t, fiatshamir.VerifyDLogEquality(proof, context, group, group.G, g2, t1, t2),
+		"proof should be verified")
+
+	nip, err = fiatshamir.Prove(fiatshamir.NewPartialDLogProver(group, secret, group.G, t1,
+		g2, group.G), context)
+	assert.Nil(t, err)
+	proof, err = nip.Marshal()
+	assert.Nil(t, err)
+	assert.True(t, fiatshamir.VerifyPartialDLog(proof, context, group, group.G, t1, g2, group.G),
+		"proof should be verified")
+
+	dLog := dlog.NewECDLog(dlog.P256)
+	aX, aY := dLog.ExponentiateBaseG(big.NewInt(1))
+	bX, bY := dLog.ExponentiateBaseG(secret)
+	a := types.NewECGroupElement(aX, aY)
+	b := types.NewECGroupElement(bX, bY)
+	nip, err = fiatshamir.Prove(fiatshamir.NewSchnorrECProver(dlog.P256, secret, a, b),
+		context)
+	assert.Nil(t, err)
+	proof, err = nip.Marshal()
+	assert.Nil(t, err)
+	assert.True(t, fiatshamir.VerifySchnorrEC(proof, context, dlog.P256, a, b),
+		"proof should be verified")
+	assert.False(t, fiatshamir.VerifySchnorrEC(proof, context, dlog.P256, a,
+		types.NewECGroupElement(bX, aY)), "proof for a point not on the curve should not be verified")
+}
+
+func TestFiatShamirDescriptor(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	secret := randomInt(group.Q)
+	g := group.Exp(group.G, randomInt(group.Q))
+	b := group.Exp(g, secret)
+
+	proof, err := fiatshamir.Prove(fiatshamir.NewSchnorrProver(group, secret, g, b), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "Schnorr", proof.Descriptor.Scheme)
+	assert.Equal(t, []string{fiatshamir.OIDSchnorrGroup}, proof.Descriptor.Groups)
+	blob, err := proof.Marshal()
+	assert.Nil(t, err)
+	proof, err = fiatshamir.UnmarshalProof(blob)
+	assert.Nil(t, err)
+	verifier := fiatshamir.NewSchnorrVerifier(group, g, b)
+	assert.Nil(t, fiatshamir.CheckDescriptor(verifier, proof))
+	assert.True(t, fiatshamir.Verify(verifier, proof, nil), "proof should be verified")
+
+	// the same public values in a group with another generator (another deployment)
+	other := groups.NewSchnorrGroupFromParams(group.P, g, group.Q)
+	otherVerifier := fiatshamir.NewSchnorrVerifier(other, g, b)
+	assert.NotNil(t, fiatshamir.CheckDescriptor(otherVerifier, proof))
+	assert.False(t, fiatshamir.Verify(otherVerifier, proof, nil),
+		"proof should not be verified in another group")
+
+	// the descriptor cannot be changed to match the verifier's expectation
+	proof.Descriptor = *fiatshamir.Describe(otherVerifier)
+	assert.Nil(t, fiatshamir.CheckDescriptor(otherVerifier, proof))
+	assert.False(t, fiatshamir.Verify(otherVerifier, proof, nil),
+		"proof with changed descriptor should not be verified")
+
+	dhProof, err := fiatshamir.Prove(fiatshamir.NewDHTupleProver(group, secret, group.G,
+		group.Exp(group.G, secret), g, b), nil)
+	assert.Nil(t, err)
+	dhProof.Descriptor.Scheme = "Schnorr"
+	assert.False(t, fiatshamir.Verify(verifier, dhProof, nil),
+		"proof of another scheme should not be verified")
+
+	dLog := dlog.NewECDLog(dlog.P256)
+	a := types.NewECGroupElement(dLog.Curve.Params().Gx, dLog.Curve.Params().Gy)
+	ecProof, err := fiatshamir.Prove(fiatshamir.NewSchnorrECProver(dlog.P256, big.NewInt(2), a,
+		types.NewECGroupElement(dLog.ExponentiateBaseG(big.NewInt(2)))), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{fiatshamir.OIDP256}, ecProof.Descriptor.Groups)
+}
+
+func TestFiatShamirEnvelope(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	secret := randomInt(group.Q)
+	b := group.Exp(group.G, secret)
+	prover := fiatshamir.NewSchnorrProver(group, secret, group.G, b)
+	verifier := fiatshamir.NewSchnorrVerifier(group, group.G, b)
+
+	envelope, err := fiatshamir.NewEnvelope(prover, "verifier1", time.Minute, nil,
+		[]byte("login"))
+	assert.Nil(t, err)
+	blob, err := envelope.Marshal()
+	assert.Nil(t, err)
+
+	policy := &fiatshamir.EnvelopePolicy{
+		Audience:    "verifier1",
+		Context:     []byte("login"),
+		Nonces:      fiatshamir.NewNonceCache(),
+		MaxLifetime: time.Hour,
+		ClockSkew:   time.Second,
+	}
+	assert.Nil(t, fiatshamir.VerifyEncodedEnvelope(verifier, blob, policy))
+	assert.NotNil(t, fiatshamir.VerifyEncodedEnvelope(verifier, blob, policy),
+		"envelope should not be accepted twice")
+	assert.NotNil(t, fiatshamir.VerifyEncodedEnvelope(verifier, blob[1:], policy),
+		"malformed envelope should not be accepted")
+
+	envelope, err = fiatshamir.NewEnvelope(prover, "verifier1", time.Minute, nil,
+		[]byte("login"))
+	assert.Nil(t, err)
+	check := func(change func(*fiatshamir.Envelope, *fiatshamir.EnvelopePolicy), msg string) {
+		e := *envelope
+		e.Nonce = append([]byte{}, envelope.Nonce...)
+		p := *policy
+		p.Nonces = nil
+		change(&e, &p)
+		assert.NotNil(t, fiatshamir.VerifyEnvelope(verifier, &e, &p), msg)
+	}
+	check(func(e *fiatshamir.Envelope, p *fiatshamir.EnvelopePolicy) {
+		p.Audience = "verifier2"
+	}, "envelope should not be accepted by another audience")
+	check(func(e *fiatshamir.Envelope, p *fiatshamir.EnvelopePolicy) {
+		e.Audience = "verifier2"
+		p.Audience = "verifier2"
+	}, "audience should not be changed")
+	check(func(e *fiatshamir.Envelope, p *fiatshamir.EnvelopePolicy) {
+		p.Context = []byte("payment")
+	}, "envelope should not be accepted in another context")
+	check(func(e *fiatshamir.Envelope, p *fiatshamir.EnvelopePolicy) {
+		p.Now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	}, "expired envelope should not be accepted")
+	check(func(e *fiatshamir.Envelope, p *fiatshamir.EnvelopePolicy) {
+		p.Now = func() time.Time { return time.Now().Add(-time.Minute) }
+	}, "envelope issued in the future should not be accepted")
+	check(func(e *fiatshamir.Envelope, p *fiatshamir.EnvelopePolicy) {
+		e.Expires += 60
+	}, "expiry should not be changed")
+	check(func(e *fiatshamir.Envelope, p *fiatshamir.EnvelopePolicy) {
+		p.MaxLifetime = time.Second
+	}, "envelope with too long lifetime should not be accepted")
+	check(func(e *fiatshamir.Envelope, p *fiatshamir.EnvelopePolicy) {
+		p.Nonce = []byte("nonce of the verifier")
+	}, "envelope with another nonce should not be accepted")
+	check(func(e *fiatshamir.Envelope, p *fiatshamir.EnvelopePolicy) {
+		e.Nonce[0] ^= 1
+	}, "nonce should not be changed")
+
+	// nonce issued by the verifier
+	nonce := []byte("nonce of the verifier")
+	envelope, err = fiatshamir.NewEnvelope(prover, "verifier1", time.Minute, nonce, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, fiatshamir.VerifyEnvelope(verifier, envelope, &fiatshamir.EnvelopePolicy{
+		Audience: "verifier1",
+		Nonce:    nonce,
+	}))
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/examples/forum"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestForum(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	s1, s2 := config.LoadPseudonymsysOrgSecrets("org1", "dlog")
+	h1, h2 := config.LoadPseudonymsysOrgPubKeys("org1")
+	orgPubKeys := pseudonymsys.NewOrgPubKeys(h1, h2)
+	org, err := pseudonymsys.NewOrgCredentialIssuer(group, s1, s2)
+	assert.Nil(t, err)
+
+	f, err := forum.NewForum("forum.example.com", group, orgPubKeys, 2)
+	assert.Nil(t, err)
+	srv := httptest.NewServer(f.Handler("moderator-token"))
+	defer srv.Close()
+	c := forum.NewClient(srv.URL)
+
+	// each credential is issued for a new nym of the person
+	obtain := func(secret *big.Int) *pseudonymsys.Credential {
+		nymA := group.Exp(group.G, randomInt(group.Q))
+		nym := pseudonymsys.NewPseudonym(nymA, group.Exp(nymA, secret))
+		return obtainCredential(group, org, orgPubKeys, secret, nym)
+	}
+	newMember := func(secret *big.Int) *forum.Member {
+		return forum.NewMember("forum.example.com", group, secret, obtain(secret))
+	}
+	aliceSecret := randomInt(group.Q)
+	bobSecret := randomInt(group.Q)
+	alice := newMember(aliceSecret)
+	bob := newMember(bobSecret)
+
+	account, err := c.Register(alice, "alice")
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", account.Name)
+	_, err = c.Register(bob, "alice")
+	assert.NotNil(t, err, "Name should not be registered twice")
+	_, err = c.Register(bob, "bob")
+	assert.Nil(t, err)
+	_, err = c.Register(newMember(aliceSecret), "alice2")
+	assert.NotNil(t, err, "Person should not register a second account")
+
+	// the credential needs to be issued by the organization
+	eveSecret := randomInt(group.Q)
+	forgedCredential := obtain(eveSecret)
+	forgedCredential.T2.ZAlpha.Add(forgedCredential.T2.ZAlpha, big.NewInt(1))
+	eve := forum.NewMember("forum.example.com", group, eveSecret, forgedCredential)
+	_, err = c.Register(eve, "eve")
+	assert.NotNil(t, err, "Account should not be registered with a forged credential")
+	_, err = c.Post(eve, "Hello", false)
+	assert.NotNil(t, err, "Post with a forged credential should not be published")
+
+	post, err := c.Post(alice, "Hello", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", post.Author)
+	post, err = c.Post(alice, "Hello again", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "", post.Author, "Anonymous post should not have the author")
+	_, err = c.Post(newMember(aliceSecret), "Hello with another credential", false)
+	assert.NotNil(t, err, "Person should not exceed the limit of posts")
+	_, err = c.Post(newMember(randomInt(group.Q)), "Hello", true)
+	assert.NotNil(t, err, "Post should not be signed by an account which does not exist")
+
+	bobPost, err := c.Post(bob, "Spam", false)
+	assert.Nil(t, err)
+	assert.NotNil(t, c.Ban("wrong-token", bobPost.ID), "Only moderators should ban")
+	assert.Nil(t, c.Ban("moderator-token", bobPost.ID))
+	_, err = c.Post(bob, "More spam", true)
+	assert.NotNil(t, err, "Banned person should not post")
+	_, err = c.Post(newMember(bobSecret), "More spam", false)
+	assert.NotNil(t, err, "Banned person should not post with another credential")
+	_, err = c.Post(newMember(randomInt(group.Q)), "Hello", false)
+	assert.Nil(t, err, "Other persons should post after the blacklist changes")
+
+	posts, err := c.GetPosts()
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(posts))
+	assert.Equal(t, "Hello", posts[0].Text)
+	assert.Equal(t, "alice", posts[0].Author)
+	assert.NotEqual(t, "Spam", posts[2].Text, "Banned post should be removed")
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"crypto/rand"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"math/big"
+	"testing"
+)
+
+// testGPSParams returns GPS parameters over the modulus of a fresh RSA key.
+func testGPSParams(t *testing.T, bits int) *dlogproofs.GPSParams {
+	rsa, err := signatures.NewRSA(bits)
+	if err != nil {
+		t.Fatalf("error when generating RSA key: %v", err)
+	}
+	return dlogproofs.NewGPSParams(rsa.GetPubKey().N)
+}
+
+func TestGPS(t *testing.T) {
+	params := testGPSParams(t, 1024)
+	secret, publicKey, err := params.GenerateKey()
+	if err != nil {
+		t.Fatalf("error when generating GPS key: %v", err)
+	}
+
+	proved, err := dlogproofs.ProveGPS(params, secret)
+	assert.Nil(t, err)
+	assert.True(t, proved, "GPS identification should pass")
+
+	// prover with a different secret than the one of the public key
+	prover, _ := dlogproofs.NewGPSProver(params, new(big.Int).Add(secret, big.NewInt(1)))
+	verifier := dlogproofs.NewGPSVerifier(params, publicKey)
+	x, _ := prover.GetProofRandomData()
+	challenge, _ := verifier.GetChallenge(x)
+	y, _ := prover.GetProofData(challenge)
+	assert.False(t, verifier.Verify(y), "GPS identification with wrong secret should fail")
+
+	_, err = dlogproofs.NewGPSProver(params, new(big.Int).Lsh(big.NewInt(1),
+		dlogproofs.GPSSecretBitLen))
+	assert.NotNil(t, err, "prover should not accept too long secret")
+}
+
+func TestGRPC_GPS(t *testing.T) {
+	params := testGPSParams(t, 1024)
+	secret, _, _ := params.GenerateKey()
+	c, err := client.NewGPSClient(testGrpcClientConn, params, secret)
+	if err != nil {
+		t.Fatalf("error when creating GPS client: %v", err)
+	}
+	success, err := c.Run()
+	assert.Nil(t, err, "should finish without errors")
+	assert.True(t, success, "GPS identification should pass")
+
+	// modulus too short to be accepted by the server - it is built directly from two primes,
+	// as crypto/rsa does not generate such short keys
+	p, err := rand.Prime(rand.Reader, 256)
+	assert.Nil(t, err)
+	q, err := rand.Prime(rand.Reader, 256)
+	assert.Nil(t, err)
+	shortParams := dlogproofs.NewGPSParams(new(big.Int).Mul(p, q))
+	c, _ = client.NewGPSClient(testGrpcClientConn, shortParams, secret)
+	_, err = c.Run()
+	assert.NotNil(t, err, "server should reject short modulus")
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/crypto/lattice"
+	"testing"
+)
+
+func TestLatticeRing(t *testing.T) {
+	x := lattice.NewPoly()
+	x[1] = 1
+	xToN1 := lattice.NewPoly()
+	xToN1[lattice.N-1] = 1
+	// X^(N-1) * X = X^N = -1
+	assert.True(t, xToN1.Mul(x).Equals(lattice.NewPolyFromCoefficients(-1)), "X^N should be -1")
+	assert.Equal(t, int64(1), xToN1.Mul(x).InfNorm())
+
+	a := lattice.GetRandomPoly()
+	b := lattice.GetRandomPoly()
+	assert.True(t, a.Mul(b).Equals(b.Mul(a)), "multiplication should be commutative")
+	assert.True(t, a.Add(b).Sub(b).Equals(a))
+}
+
+func TestBDLOPCommitment(t *testing.T) {
+	params := lattice.NewBDLOPParams()
+	committer := lattice.NewBDLOPCommitter(params)
+	receiver := lattice.NewBDLOPReceiver(params)
+
+	m := lattice.NewPolyFromCoefficients(1, 2, 3, 4)
+	c := committer.GetCommitMsg(m)
+	receiver.SetCommitment(c)
+
+	val, r := committer.GetDecommitMsg()
+	assert.True(t, receiver.CheckDecommitment(val, r), "commitment should be opened")
+	assert.False(t, receiver.CheckDecommitment(lattice.NewPolyFromCoefficients(1, 2, 3), r),
+		"commitment should not be opened to another value")
+	long := lattice.GetRandomBoundedPolyVec(lattice.BDLOPWidth, 2)
+	long[0][0] = 2
+	assert.False(t, receiver.CheckDecommitment(val, long), "randomness needs to be short")
+
+	assert.True(t, lattice.ProveBDLOPOpening(params, c, r), "opening proof should be verified")
+
+	other := lattice.NewBDLOPCommitter(params).GetCommitMsg(m)
+	assert.False(t, lattice.ProveBDLOPOpening(params, other, r),
+		"opening proof should not be verified for another commitment")
+}
+
+func testShortVectorStatement() (*lattice.ShortVectorStatement, lattice.PolyVec) {
+	a := make([]lattice.PolyVec, 2)
+	for i := range a {
+		a[i] = lattice.PolyVec{lattice.GetRandomPoly(), lattice.GetRandomPoly(),
+			lattice.GetRandomPoly()}
+	}
+	s := lattice.GetRandomBoundedPolyVec(3, 1)
+	return lattice.NewShortVectorStatement(a, s, 1), s
+}
+
+func TestLatticeShortVector(t *testing.T) {
+	statement, s := testShortVectorStatement()
+	proof, err := lattice.ProveShortVector(statement, s)
+	if err != nil {
+		t.Fatalf("error when proving: %v", err)
+	}
+	assert.True(t, proof.Verify(statement), "short vector proof should be verified")
+
+	other, _ := testShortVectorStatement()
+	assert.False(t, proof.Verify(other), "proof should not be verified for another statement")
+	proof.Z[0][0]++
+	assert.False(t, proof.Verify(statement), "proof with tampered response should fail")
+
+	long := lattice.GetRandomBoundedPolyVec(3, 1)
+	long[0][0] = 2
+	_, err = lattice.NewShortVectorProver(statement, long)
+	assert.NotNil(t, err, "prover should not accept long secret")
+
+	prover, err := lattice.NewShortVectorProver(statement, s)
+	if err != nil {
+		t.Fatalf("error when creating prover: %v", err)
+	}
+	verifier := lattice.NewShortVectorVerifier(statement)
+	for {
+		verifier.SetProofRandomData(prover.GetProofRandomData())
+		z, err := prover.GetProofData(verifier.GetChallenge())
+		if err == nil {
+			assert.True(t, verifier.Verify(z), "interactive short vector proof should pass")
+			break
+		}
+	}
+}
+
+func TestGRPC_LatticeShortVector(t *testing.T) {
+	statement, s := testShortVectorStatement()
+	c, err := client.NewLatticeShortVectorClient(testGrpcClientConn, statement, s)
+	if err != nil {
+		t.Fatalf("error when creating lattice client: %v", err)
+	}
+	success, err := c.Run()
+	assert.Nil(t, err, "should finish without errors")
+	assert.True(t, success, "short vector proof should pass")
+}
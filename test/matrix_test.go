@@ -0,0 +1,662 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"github.com/xlab-si/emmy/crypto/signatures/blindschnorr"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/stern"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/anoncreds"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/log"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"github.com/xlab-si/emmy/revocation"
+	"github.com/xlab-si/emmy/server"
+	"github.com/xlab-si/emmy/types"
+	"google.golang.org/grpc"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// matrixCell is one combination of the test matrix. Curve is only set for EC based schemas.
+type matrixCell struct {
+	schema    pb.SchemaType
+	variant   pb.SchemaVariant
+	curve     dlog.Curve
+	ec        bool
+	transport string
+}
+
+func (cell matrixCell) String() string {
+	curve := "-"
+	if cell.ec {
+		curve = cell.curve.String()
+	}
+	return fmt.Sprintf("%v/%v/%v/%v", cell.schema, cell.variant, curve, cell.transport)
+}
+
+// matrixEntry runs an end-to-end session of one schema. Options for the variant, curve and
+// transport of the cell are prepared by the matrix and need to be passed to the clients.
+type matrixEntry struct {
+	ec  bool // the schema is run on each of the configured curves
+	run func(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error
+}
+
+// matrixEntries needs an entry for each schema - a schema without it fails the matrix.
+var matrixEntries = map[pb.SchemaType]matrixEntry{
+	pb.SchemaType_PEDERSEN:    {run: runMatrixPedersen},
+	pb.SchemaType_PEDERSEN_EC: {ec: true, run: runMatrixPedersenEC},
+	pb.SchemaType_SCHNORR:     {run: runMatrixSchnorr},
+	pb.SchemaType_SCHNORR_EC:  {ec: true, run: runMatrixSchnorrEC},
+	pb.SchemaType_CSPAILLIER:  {run: runMatrixCSPaillier},
+	pb.SchemaType_QR:          {run: runMatrixQR},
+	pb.SchemaType_QNR:         {run: runMatrixQNR},
+	pb.SchemaType_RANGE_PROOF: {run: runMatrixRangeProof},
+	pb.SchemaType_EXTENSION:   {run: runMatrixExtension},
+
+	pb.SchemaType_PAILLIER_PLAINTEXT:   {run: runMatrixPaillierPlaintext},
+	pb.SchemaType_REVOCATION_UPDATES:   {run: runMatrixRevocationUpdates},
+	pb.SchemaType_ABUSE_REPORT:         {run: runMatrixAbuseReport},
+	pb.SchemaType_GPS:                  {run: runMatrixGPS},
+	pb.SchemaType_STERN:                {run: runMatrixStern},
+	pb.SchemaType_LATTICE_SHORT_VECTOR: {run: runMatrixLatticeShortVector},
+	pb.SchemaType_THRESHOLD_SCHNORR:    {run: runMatrixThresholdSchnorr},
+	pb.SchemaType_BLIND_SCHNORR:        {run: runMatrixBlindSchnorr},
+
+	pb.SchemaType_PARTIALLY_BLIND_SCHNORR: {run: runMatrixPartiallyBlindSchnorr},
+	pb.SchemaType_ANONCREDS_ISSUE:         {run: runMatrixAnonCreds},
+	pb.SchemaType_ANONCREDS_SHOW:          {run: runMatrixAnonCreds},
+
+	pb.SchemaType_PSEUDONYMSYS_CA:                  {run: runMatrixPseudonymsys},
+	pb.SchemaType_PSEUDONYMSYS_CA_STATUS:           {run: runMatrixPseudonymsys},
+	pb.SchemaType_PSEUDONYMSYS_NYM_GEN:             {run: runMatrixPseudonymsys},
+	pb.SchemaType_PSEUDONYMSYS_ISSUE_CREDENTIAL:    {run: runMatrixPseudonymsys},
+	pb.SchemaType_PSEUDONYMSYS_TRANSFER_CREDENTIAL: {run: runMatrixPseudonymsys},
+	pb.SchemaType_PSEUDONYMSYS_RATE_LIMIT:          {run: runMatrixPseudonymsys},
+	pb.SchemaType_PSEUDONYMSYS_NYM_ESCROW:          {run: runMatrixPseudonymsys},
+	pb.SchemaType_PSEUDONYMSYS_NYM_REGISTRY:        {run: runMatrixPseudonymsys},
+
+	pb.SchemaType_PSEUDONYMSYS_CA_EC:                  {ec: true, run: runMatrixPseudonymsysEC},
+	pb.SchemaType_PSEUDONYMSYS_NYM_GEN_EC:             {ec: true, run: runMatrixPseudonymsysEC},
+	pb.SchemaType_PSEUDONYMSYS_ISSUE_CREDENTIAL_EC:    {ec: true, run: runMatrixPseudonymsysEC},
+	pb.SchemaType_PSEUDONYMSYS_TRANSFER_CREDENTIAL_EC: {ec: true, run: runMatrixPseudonymsysEC},
+}
+
+// matrixGap is a combination of schema and variant which is known not to be supported.
+type matrixGap struct {
+	schema  pb.SchemaType
+	variant pb.SchemaVariant
+}
+
+// matrixSigmaOnly lists the schemas for which only the sigma variant is implemented, along with
+// the reason. The server runs them as sigma protocols regardless of the requested variant.
+var matrixSigmaOnly = map[pb.SchemaType]string{
+	pb.SchemaType_PEDERSEN:                "commitments are not proofs, only sigma applies",
+	pb.SchemaType_PEDERSEN_EC:             "commitments are not proofs, only sigma applies",
+	pb.SchemaType_CSPAILLIER:              "only sigma is implemented",
+	pb.SchemaType_QR:                      "only sigma is implemented",
+	pb.SchemaType_QNR:                     "only sigma is implemented",
+	pb.SchemaType_RANGE_PROOF:             "only sigma is implemented",
+	pb.SchemaType_PAILLIER_PLAINTEXT:      "only sigma is implemented",
+	pb.SchemaType_GPS:                     "only sigma is implemented",
+	pb.SchemaType_STERN:                   "only sigma is implemented",
+	pb.SchemaType_LATTICE_SHORT_VECTOR:    "only sigma is implemented",
+	pb.SchemaType_THRESHOLD_SCHNORR:       "only sigma is implemented",
+	pb.SchemaType_ANONCREDS_ISSUE:         "only sigma is implemented",
+	pb.SchemaType_ANONCREDS_SHOW:          "only sigma is implemented",
+	pb.SchemaType_EXTENSION:               "variants are up to the extension",
+	pb.SchemaType_BLIND_SCHNORR:           "signing protocol, variants do not apply",
+	pb.SchemaType_PARTIALLY_BLIND_SCHNORR: "signing protocol, variants do not apply",
+	pb.SchemaType_REVOCATION_UPDATES:      "not a proof, variants do not apply",
+	pb.SchemaType_ABUSE_REPORT:            "not a proof, variants do not apply",
+
+	pb.SchemaType_PSEUDONYMSYS_CA:                  "only sigma is implemented",
+	pb.SchemaType_PSEUDONYMSYS_CA_STATUS:           "not a proof, variants do not apply",
+	pb.SchemaType_PSEUDONYMSYS_NYM_GEN:             "only sigma is implemented",
+	pb.SchemaType_PSEUDONYMSYS_ISSUE_CREDENTIAL:    "only sigma is implemented",
+	pb.SchemaType_PSEUDONYMSYS_TRANSFER_CREDENTIAL: "only sigma is implemented",
+	pb.SchemaType_PSEUDONYMSYS_RATE_LIMIT:          "only sigma is implemented",
+	pb.SchemaType_PSEUDONYMSYS_NYM_ESCROW:          "only sigma is implemented",
+	pb.SchemaType_PSEUDONYMSYS_NYM_REGISTRY:        "not a proof, variants do not apply",
+
+	pb.SchemaType_PSEUDONYMSYS_CA_EC:                  "only sigma is implemented",
+	pb.SchemaType_PSEUDONYMSYS_NYM_GEN_EC:             "only sigma is implemented",
+	pb.SchemaType_PSEUDONYMSYS_ISSUE_CREDENTIAL_EC:    "only sigma is implemented",
+	pb.SchemaType_PSEUDONYMSYS_TRANSFER_CREDENTIAL_EC: "only sigma is implemented",
+}
+
+// matrixGaps lists the combinations of schema and variant which are known not to be supported
+// (ZKP and ZKPOK variants of the schemas from matrixSigmaOnly), along with the reason. Cells
+// of these combinations are skipped, all the other cells need to finish without errors.
+var matrixGaps = func() map[matrixGap]string {
+	gaps := map[matrixGap]string{}
+	for schema, reason := range matrixSigmaOnly {
+		gaps[matrixGap{schema, pb.SchemaVariant_ZKP}] = reason
+		gaps[matrixGap{schema, pb.SchemaVariant_ZKPOK}] = reason
+	}
+	return gaps
+}()
+
+// matrixEnv is the server of the test matrix (configured for all the schemas) and the keys
+// which the clients need to run the schemas against it.
+type matrixEnv struct {
+	srv           *server.Server
+	conn          *grpc.ClientConn
+	cspaillier    *encryption.CSPaillier
+	revocationKey *ecdsa.PrivateKey
+	reporterKey   *ecdsa.PrivateKey
+	blindSigner   *blindschnorr.Signer
+	anonIssuer    *anoncreds.Issuer
+}
+
+// newMatrixEnv starts the server of the test matrix. It returns the environment and
+// a function which stops the server.
+func newMatrixEnv(t *testing.T) (*matrixEnv, func()) {
+	env := &matrixEnv{cspaillier: getTestCSPaillier(t)}
+	var err error
+	if env.revocationKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+	if env.reporterKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+	if env.blindSigner, err = blindschnorr.NewSigner(config.LoadGroup("schnorr")); err != nil {
+		t.Fatal(err)
+	}
+	env.anonIssuer, err = anoncreds.NewIssuer([]string{"matrix"}, getTestDFParams(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registryKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	group := config.LoadGroup("schnorr")
+	shares, _, err := dlogproofs.SplitSchnorrSecret(group, randomInt(group.Q), 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if env.srv, err = server.NewServer(log.NewNullLogger()); err != nil {
+		t.Fatal(err)
+	}
+	env.srv.SetNymEscrowKey(env.cspaillier.PubKey)
+	env.srv.SetNymRegistry(pseudonymsys.NewNymRegistry(0, registryKey.D, registryKey.X,
+		registryKey.Y))
+	registry := revocation.NewNotifyingRegistry(revocation.NewMemoryRegistry())
+	env.srv.SetRevocationSigner(revocation.NewSnapshotSigner(registry, env.revocationKey.D,
+		env.revocationKey.X, env.revocationKey.Y))
+	desk := revocation.NewAbuseDesk(registry, 1)
+	desk.AddReporter("org1", env.reporterKey.X, env.reporterKey.Y)
+	env.srv.SetAbuseDesk(desk)
+	env.srv.SetThresholdSchnorrShare(shares[0])
+	env.srv.SetBlindSchnorrSigner(env.blindSigner)
+	env.srv.SetPartiallyBlindSchnorrSigner(env.blindSigner,
+		func(requested []byte) ([]byte, error) {
+			return requested, nil
+		})
+	env.srv.SetAnonCredsIssuer(env.anonIssuer,
+		func(requested map[string]*big.Int) (map[string]*big.Int, error) {
+			return requested, nil
+		})
+	env.srv.SetAnonCredsVerifier(env.anonIssuer.GetPublicKey(),
+		&anoncreds.PresentationRequest{Disclosed: []string{"matrix"}})
+	env.srv.SetRateLimitIssuer(env.anonIssuer.GetPublicKey())
+
+	address, stop := startTestServer(t, env.srv)
+	if env.conn, err = client.GetConnection(address, "testdata/server.pem", false); err != nil {
+		stop()
+		t.Fatal(err)
+	}
+	return env, func() {
+		env.conn.Close()
+		stop()
+	}
+}
+
+// TestGRPC_Matrix runs an end-to-end session for each combination of schema, variant,
+// curve (for EC based schemas) and transport, so that incomplete combinations show up
+// as failing cells.
+func TestGRPC_Matrix(t *testing.T) {
+	env, stop := newMatrixEnv(t)
+	defer stop()
+
+	transports := map[string][]client.ClientOption{
+		"tls":       nil,
+		"hybridkem": {client.WithHybridKEM()},
+	}
+
+	for _, schema := range sortedEnum(pb.SchemaType_name) {
+		schema := pb.SchemaType(schema)
+		entry, ok := matrixEntries[schema]
+		if !ok {
+			t.Run(schema.String(), func(t *testing.T) {
+				t.Errorf("Schema %v has no entry in the test matrix", schema)
+			})
+			continue
+		}
+
+		curves := []dlog.Curve{0}
+		if entry.ec {
+			curves = env.srv.Curves(schema)
+		}
+		for _, variant := range sortedEnum(pb.SchemaVariant_name) {
+			for _, curve := range curves {
+				for _, transport := range []string{"tls", "hybridkem"} {
+					cell := matrixCell{
+						schema:    schema,
+						variant:   pb.SchemaVariant(variant),
+						curve:     curve,
+						ec:        entry.ec,
+						transport: transport,
+					}
+					opts := append([]client.ClientOption{
+						client.WithProtocolVariant(cell.variant),
+					}, transports[transport]...)
+					if entry.ec {
+						opts = append(opts, client.WithCurve(curve))
+					}
+
+					t.Run(cell.String(), func(t *testing.T) {
+						if reason, ok := matrixGaps[matrixGap{cell.schema, cell.variant}]; ok {
+							t.Skip(reason)
+						}
+						if err := entry.run(env, cell, opts...); err != nil {
+							t.Errorf("Session should finish without errors: %v", err)
+						}
+					})
+				}
+			}
+		}
+	}
+}
+
+// sortedEnum returns the values of a protobuf enum in ascending order.
+func sortedEnum(names map[int32]string) []int32 {
+	values := make([]int32, 0, len(names))
+	for v := range names {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return values
+}
+
+func runMatrixPedersen(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error {
+	c, err := client.NewPedersenClient(env.conn, config.LoadGroup("pedersen"),
+		big.NewInt(121212121), opts...)
+	if err != nil {
+		return err
+	}
+	return c.Run()
+}
+
+func runMatrixPedersenEC(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error {
+	c, err := client.NewPedersenECClient(env.conn, big.NewInt(121212121), opts...)
+	if err != nil {
+		return err
+	}
+	return c.Run()
+}
+
+func runMatrixSchnorr(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error {
+	c, err := client.NewSchnorrClient(env.conn, config.LoadGroup("schnorr"),
+		big.NewInt(345345345334), opts...)
+	if err != nil {
+		return err
+	}
+	return c.Run()
+}
+
+func runMatrixSchnorrEC(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error {
+	c, err := client.NewSchnorrECClient(env.conn, config.LoadCompilerParams(),
+		big.NewInt(345345345334), opts...)
+	if err != nil {
+		return err
+	}
+	return c.Run()
+}
+
+func runMatrixCSPaillier(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error {
+	// the server reads the secret key from the key directory
+	dir := config.LoadKeyDirFromConfig()
+	pubKeyPath := filepath.Join(dir, "cspaillierpubkey.txt")
+	if err := env.cspaillier.StoreSecKey(filepath.Join(dir, "cspaillierseckey.txt")); err != nil {
+		return err
+	}
+	if err := env.cspaillier.StorePubKey(pubKeyPath); err != nil {
+		return err
+	}
+
+	m := randomInt(big.NewInt(8685849))
+	l := randomInt(big.NewInt(340002223232))
+	c, err := client.NewCSPaillierClient(env.conn, pubKeyPath, m, l, opts...)
+	if err != nil {
+		return err
+	}
+	return c.Run()
+}
+
+// proved turns the result of the clients which report whether the statement was proved
+// into an error.
+func proved(ok bool, err error) error {
+	if err == nil && !ok {
+		return fmt.Errorf("Proof was not accepted")
+	}
+	return err
+}
+
+func runMatrixQR(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error {
+	group := config.LoadGroup("pseudonymsys")
+	c, err := client.NewQRClient(env.conn, group, randomInt(group.P), opts...)
+	if err != nil {
+		return err
+	}
+	return proved(c.Run())
+}
+
+func runMatrixQNR(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error {
+	qr := config.LoadQR("qrsmall")
+	// y is a quadratic non-residue in qrsmall only if it is not a residue modulo
+	// one of the primes, thus it is searched for randomly
+	y := randomInt(qr.N)
+	for big.Jacobi(y, qr.Factors[0]) != -1 {
+		y = randomInt(qr.N)
+	}
+	c, err := client.NewQNRClient(env.conn, qr, y, opts...)
+	if err != nil {
+		return err
+	}
+	return proved(c.Run())
+}
+
+func runMatrixRangeProof(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error {
+	c, err := client.NewRangeProofClient(env.conn, config.LoadGroup("pedersen"),
+		big.NewInt(35), big.NewInt(18), big.NewInt(150), opts...)
+	if err != nil {
+		return err
+	}
+	return proved(c.Run())
+}
+
+func runMatrixPaillierPlaintext(env *matrixEnv, cell matrixCell,
+	opts ...client.ClientOption) error {
+	c, err := client.NewPaillierCommittedPlaintextClient(env.conn,
+		config.LoadGroup("pedersen"), testPaillier.GetPubKey(), big.NewInt(35), opts...)
+	if err != nil {
+		return err
+	}
+	return proved(c.Run())
+}
+
+func runMatrixGPS(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error {
+	rsa, err := signatures.NewRSA(1024)
+	if err != nil {
+		return err
+	}
+	params := dlogproofs.NewGPSParams(rsa.GetPubKey().N)
+	secret, _, err := params.GenerateKey()
+	if err != nil {
+		return err
+	}
+	c, err := client.NewGPSClient(env.conn, params, secret, opts...)
+	if err != nil {
+		return err
+	}
+	return proved(c.Run())
+}
+
+func runMatrixStern(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error {
+	params, err := stern.NewParams()
+	if err != nil {
+		return err
+	}
+	secret, _, err := params.GenerateKey()
+	if err != nil {
+		return err
+	}
+	c, err := client.NewSternClient(env.conn, params, secret, opts...)
+	if err != nil {
+		return err
+	}
+	return proved(c.Run())
+}
+
+func runMatrixLatticeShortVector(env *matrixEnv, cell matrixCell,
+	opts ...client.ClientOption) error {
+	statement, s := testShortVectorStatement()
+	c, err := client.NewLatticeShortVectorClient(env.conn, statement, s, opts...)
+	if err != nil {
+		return err
+	}
+	return proved(c.Run())
+}
+
+func runMatrixThresholdSchnorr(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error {
+	c, err := client.NewThresholdSchnorrShareClient(env.conn, 1, opts...)
+	if err != nil {
+		return err
+	}
+	if _, err := c.GetProofRandomData([]int{1, 2}); err != nil {
+		return err
+	}
+	_, err = c.GetProofData(big.NewInt(1))
+	return err
+}
+
+func runMatrixBlindSchnorr(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error {
+	c, err := client.NewBlindSchnorrClient(env.conn, env.blindSigner.GetPublicKey(), opts...)
+	if err != nil {
+		return err
+	}
+	_, err = c.ObtainSignature([]byte("matrix"))
+	return err
+}
+
+func runMatrixPartiallyBlindSchnorr(env *matrixEnv, cell matrixCell,
+	opts ...client.ClientOption) error {
+	c, err := client.NewPartiallyBlindSchnorrClient(env.conn, env.blindSigner.GetPublicKey(),
+		opts...)
+	if err != nil {
+		return err
+	}
+	_, err = c.ObtainSignature([]byte("matrix"), []byte("matrix"))
+	return err
+}
+
+func runMatrixAnonCreds(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error {
+	holder, err := anoncreds.NewHolder()
+	if err != nil {
+		return err
+	}
+	c, err := client.NewAnonCredsClient(env.conn, env.anonIssuer.GetPublicKey(), holder,
+		opts...)
+	if err != nil {
+		return err
+	}
+	credential, err := c.ObtainCredential(map[string]*big.Int{"matrix": big.NewInt(1)})
+	if err != nil || cell.schema == pb.SchemaType_ANONCREDS_ISSUE {
+		return err
+	}
+	accepted, err := c.ProveCredential(credential)
+	if err == nil && !accepted {
+		err = fmt.Errorf("presentation was not accepted")
+	}
+	return err
+}
+
+func runMatrixRevocationUpdates(env *matrixEnv, cell matrixCell,
+	opts ...client.ClientOption) error {
+	key := env.revocationKey
+	c, err := client.NewRevocationClient(env.conn, revocation.NewReplica(key.X, key.Y),
+		opts...)
+	if err != nil {
+		return err
+	}
+	return c.Sync()
+}
+
+func runMatrixAbuseReport(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error {
+	key := env.reporterKey
+	c, err := client.NewAbuseReportClient(env.conn, "org1", key.D, key.X, key.Y, opts...)
+	if err != nil {
+		return err
+	}
+	report, err := newTestAbuseReport()
+	if err != nil {
+		return err
+	}
+	return c.Report(report)
+}
+
+func runMatrixExtension(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error {
+	c, err := client.NewExtensionClient(env.conn, "echo", opts...)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	payload := &any.Any{TypeUrl: "test/echo", Value: []byte("hello")}
+	for i := 0; i < 2; i++ {
+		if _, err := c.Exchange(payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runMatrixPseudonymsys runs the pseudonymsys steps (CA, nym generation, credential
+// issuance, transfer) up to and including the schema of the cell.
+func runMatrixPseudonymsys(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error {
+	params := config.LoadPseudonymsysParams()
+	group := params.Group
+	caClient, err := client.NewPseudonymsysCAClient(env.conn, params, opts...)
+	if err != nil {
+		return err
+	}
+	c, err := client.NewPseudonymsysClient(env.conn, params, opts...)
+	if err != nil {
+		return err
+	}
+
+	if cell.schema == pb.SchemaType_PSEUDONYMSYS_RATE_LIMIT {
+		holder, err := anoncreds.NewHolder()
+		if err != nil {
+			return err
+		}
+		credential, err := anoncreds.IssueCredential(env.anonIssuer, holder,
+			map[string]*big.Int{"matrix": big.NewInt(1)})
+		if err != nil {
+			return err
+		}
+		// each cell acts in its own scope, so that it does not hit the limit
+		return c.AuthorizeAction(cell.String(), config.LoadRateLimitParams(),
+			env.anonIssuer.GetPublicKey(), credential)
+	}
+
+	secret, err := c.GenerateMasterKey()
+	if err != nil {
+		return err
+	}
+	masterNym := pseudonymsys.NewPseudonym(group.G, group.Exp(group.G, secret))
+	caCertificate, err := caClient.ObtainCertificate(secret, masterNym)
+	if err != nil || cell.schema == pb.SchemaType_PSEUDONYMSYS_CA {
+		return err
+	}
+	if cell.schema == pb.SchemaType_PSEUDONYMSYS_CA_STATUS {
+		_, err := caClient.GetCertificateStatus(caCertificate)
+		return err
+	}
+
+	nym, err := c.GenerateNym(secret, caCertificate)
+	if err != nil || cell.schema == pb.SchemaType_PSEUDONYMSYS_NYM_GEN {
+		return err
+	}
+	if cell.schema == pb.SchemaType_PSEUDONYMSYS_NYM_ESCROW {
+		return c.EscrowNym(nym, secret, env.cspaillier.PubKey)
+	}
+	if cell.schema == pb.SchemaType_PSEUDONYMSYS_NYM_REGISTRY {
+		if _, err := env.srv.GetNymRegistry().Publish(); err != nil {
+			return err
+		}
+		_, _, err := c.GetNymInclusionProof(nym)
+		return err
+	}
+
+	orgName := "org1"
+	h1, h2 := config.LoadPseudonymsysOrgPubKeys(orgName)
+	credential, err := c.ObtainCredential(secret, nym, pseudonymsys.NewOrgPubKeys(h1, h2))
+	if err != nil || cell.schema == pb.SchemaType_PSEUDONYMSYS_ISSUE_CREDENTIAL {
+		return err
+	}
+	_, err = c.TransferCredential(orgName, secret, nym, credential)
+	return err
+}
+
+// runMatrixPseudonymsysEC is the same as runMatrixPseudonymsys, but for EC based schemas.
+func runMatrixPseudonymsysEC(env *matrixEnv, cell matrixCell, opts ...client.ClientOption) error {
+	ecdlog := dlog.NewECDLog(cell.curve)
+	caClient, err := client.NewPseudonymsysCAClientEC(env.conn, opts...)
+	if err != nil {
+		return err
+	}
+	c, err := client.NewPseudonymsysClientEC(env.conn, opts...)
+	if err != nil {
+		return err
+	}
+
+	secret, err := c.GenerateMasterKey()
+	if err != nil {
+		return err
+	}
+	nymA := types.NewECGroupElement(ecdlog.Curve.Params().Gx, ecdlog.Curve.Params().Gy)
+	nymB1, nymB2 := ecdlog.Exponentiate(nymA.X, nymA.Y, secret)
+	masterNym := pseudonymsys.NewPseudonymEC(nymA, types.NewECGroupElement(nymB1, nymB2))
+	caCertificate, err := caClient.ObtainCertificate(secret, masterNym)
+	if err != nil || cell.schema == pb.SchemaType_PSEUDONYMSYS_CA_EC {
+		return err
+	}
+
+	nym, err := c.GenerateNym(secret, caCertificate)
+	if err != nil || cell.schema == pb.SchemaType_PSEUDONYMSYS_NYM_GEN_EC {
+		return err
+	}
+
+	orgName := "org1"
+	h1X, h1Y, h2X, h2Y := config.LoadPseudonymsysOrgPubKeysEC(orgName)
+	orgPubKeys := pseudonymsys.NewOrgPubKeysEC(types.NewECGroupElement(h1X, h1Y),
+		types.NewECGroupElement(h2X, h2Y))
+	credential, err := c.ObtainCredential(secret, nym, orgPubKeys)
+	if err != nil || cell.schema == pb.SchemaType_PSEUDONYMSYS_ISSUE_CREDENTIAL_EC {
+		return err
+	}
+
+	_, err = c.TransferCredential(orgName, secret, nym, credential)
+	return err
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/polyproofs"
+	"math/big"
+	"testing"
+)
+
+func TestPolynomialEvaluation(t *testing.T) {
+	group := config.LoadGroup("pedersen")
+	h, err := group.GetRandomElement()
+	assert.Nil(t, err)
+
+	// P(x) = (x - 3)(x - 5)(x - 8) = x^3 - 16x^2 + 79x - 120
+	coefficients := []*big.Int{big.NewInt(-120), big.NewInt(79), big.NewInt(-16), big.NewInt(1)}
+	for i, a := range coefficients {
+		coefficients[i] = new(big.Int).Mod(a, group.Q)
+	}
+	assert.Equal(t, 0, polyproofs.EvaluatePolynomial(group, coefficients, big.NewInt(5)).Sign(),
+		"5 should be the root of the polynomial")
+
+	for _, x := range []*big.Int{big.NewInt(5), big.NewInt(7), randomInt(group.Q)} {
+		proved, err := polyproofs.ProvePolynomialEvaluation(group, h, coefficients, x)
+		assert.Nil(t, err)
+		assert.True(t, proved, "Polynomial evaluation proof does not work correctly")
+	}
+	for _, degree := range []int{0, 1} {
+		proved, _ := polyproofs.ProvePolynomialEvaluation(group, h, coefficients[:degree+1],
+			big.NewInt(2))
+		assert.True(t, proved, "Polynomial evaluation proof does not work for low degrees")
+	}
+
+	// prover claims P(7) = 1 (instead of -8)
+	x, rx, ry := big.NewInt(7), randomInt(group.Q), randomInt(group.Q)
+	commit := func(value, r *big.Int) *big.Int {
+		return group.Mul(group.Exp(group.G, value), group.Exp(h, r))
+	}
+	coefficientsR := make([]*big.Int, len(coefficients))
+	coefficientCommitments := make([]*big.Int, len(coefficients))
+	for i, a := range coefficients {
+		coefficientsR[i] = randomInt(group.Q)
+		coefficientCommitments[i] = commit(a, coefficientsR[i])
+	}
+	prover, err := polyproofs.NewPolynomialEvaluationProver(group, h, coefficients,
+		coefficientsR, x, rx, ry)
+	assert.Nil(t, err)
+	verifier := polyproofs.NewPolynomialEvaluationVerifier(group, h, coefficientCommitments,
+		commit(x, rx), commit(big.NewInt(1), ry))
+	proofRandomData, err := prover.GetProofRandomData()
+	assert.Nil(t, err)
+	assert.Nil(t, verifier.SetProofRandomData(proofRandomData))
+	challenge, err := verifier.GetChallenge()
+	assert.Nil(t, err)
+	assert.False(t, verifier.Verify(prover.GetProofData(challenge)),
+		"Proof for a wrong evaluation should fail")
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newPossessionVerifier returns the verifier with a single registered nym and its secret.
+func newPossessionVerifier(t *testing.T, ttl time.Duration) (*pseudonymsys.PossessionVerifier,
+	*pseudonymsys.Pseudonym, *big.Int) {
+	group := config.LoadGroup("pseudonymsys")
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("error when generating key: %v", err)
+	}
+	registry := pseudonymsys.NewNymRegistry(0, key.D, key.X, key.Y)
+	secret := randomInt(group.Q)
+	a := group.Exp(group.G, randomInt(group.Q))
+	nym := pseudonymsys.NewPseudonym(a, group.Exp(a, secret))
+	assert.Nil(t, registry.Register(nym))
+	return pseudonymsys.NewPossessionVerifier(group, registry, ttl), nym, secret
+}
+
+func TestPossessionVerifier(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	verifier, nym, secret := newPossessionVerifier(t, time.Minute)
+
+	challenge, err := verifier.CreateChallenge(nym)
+	assert.Nil(t, err)
+	response, err := pseudonymsys.ProvePossession(group, secret, nym, challenge)
+	assert.Nil(t, err)
+	owner, err := verifier.VerifyResponse(challenge.ID, response)
+	assert.Nil(t, err)
+	assert.Equal(t, nym, owner)
+
+	_, err = verifier.VerifyResponse(challenge.ID, response)
+	assert.NotNil(t, err, "challenge should be answered only once")
+
+	challenge, err = verifier.CreateChallenge(nym)
+	assert.Nil(t, err)
+	response, err = pseudonymsys.ProvePossession(group, randomInt(group.Q), nym, challenge)
+	assert.Nil(t, err)
+	_, err = verifier.VerifyResponse(challenge.ID, response)
+	assert.NotNil(t, err, "proof with a wrong secret should not be valid")
+
+	// response to another challenge
+	challenge1, err := verifier.CreateChallenge(nym)
+	assert.Nil(t, err)
+	challenge2, err := verifier.CreateChallenge(nym)
+	assert.Nil(t, err)
+	response, err = pseudonymsys.ProvePossession(group, secret, nym, challenge1)
+	assert.Nil(t, err)
+	_, err = verifier.VerifyResponse(challenge2.ID, response)
+	assert.NotNil(t, err, "response should be bound to the challenge")
+
+	a := group.Exp(group.G, randomInt(group.Q))
+	_, err = verifier.CreateChallenge(pseudonymsys.NewPseudonym(a, group.Exp(a, secret)))
+	assert.NotNil(t, err, "challenge should not be created for unregistered nyms")
+
+	verifier, nym, secret = newPossessionVerifier(t, time.Millisecond)
+	challenge, err = verifier.CreateChallenge(nym)
+	assert.Nil(t, err)
+	response, err = pseudonymsys.ProvePossession(group, secret, nym, challenge)
+	assert.Nil(t, err)
+	time.Sleep(10 * time.Millisecond)
+	_, err = verifier.VerifyResponse(challenge.ID, response)
+	assert.NotNil(t, err, "expired challenge should not be accepted")
+}
+
+func TestPossessionHandler(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	verifier, nym, secret := newPossessionVerifier(t, time.Minute)
+	srv, err := server.NewServer(log.NewNullLogger())
+	assert.Nil(t, err)
+
+	handler := httptest.NewServer(srv.PossessionHandler())
+	defer handler.Close()
+	post := func(path string, req, resp interface{}) int {
+		body, _ := json.Marshal(req)
+		r, err := http.Post(handler.URL+path, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Body.Close()
+		if resp != nil {
+			json.NewDecoder(r.Body).Decode(resp)
+		}
+		return r.StatusCode
+	}
+	nymReq := map[string][]byte{"nym_a": nym.A.Bytes(), "nym_b": nym.B.Bytes()}
+	assert.Equal(t, http.StatusNotFound, post("/challenge", nymReq, nil),
+		"API should be disabled without verifier")
+
+	srv.SetPossessionVerifier(verifier)
+	var challenge struct {
+		ID      string    `json:"id"`
+		Nonce   []byte    `json:"nonce"`
+		Expires time.Time `json:"expires"`
+	}
+	assert.Equal(t, http.StatusOK, post("/challenge", nymReq, &challenge))
+	assert.True(t, challenge.Expires.After(time.Now()))
+
+	response, err := pseudonymsys.ProvePossession(group, secret, nym, &pseudonymsys.PossessionChallenge{
+		ID:    challenge.ID,
+		Nonce: challenge.Nonce,
+	})
+	assert.Nil(t, err)
+	var result struct {
+		Valid bool   `json:"valid"`
+		NymA  []byte `json:"nym_a"`
+		Error string `json:"error"`
+	}
+	verifyReq := map[string]interface{}{
+		"id": challenge.ID,
+		"c":  response.C.Bytes(),
+		"z":  response.Z.Bytes(),
+	}
+	assert.Equal(t, http.StatusOK, post("/verify", verifyReq, &result))
+	assert.True(t, result.Valid, "proof of possession should be valid")
+	assert.Equal(t, nym.A.Bytes(), result.NymA)
+
+	result.Valid = false
+	assert.Equal(t, http.StatusOK, post("/verify", verifyReq, &result))
+	assert.False(t, result.Valid, "challenge should not be reused")
+	assert.NotEmpty(t, result.Error)
+
+	nymReq["nym_b"] = group.G.Bytes()
+	assert.Equal(t, http.StatusNotFound, post("/challenge", nymReq, nil),
+		"challenge should not be created for unregistered nyms")
+}
@@ -29,10 +29,12 @@ func TestFPreimage(t *testing.T) {
 	if err != nil {
 		t.Errorf("Error when generating RSABasedQOneWay homomorphism")
 	}
-	v := H.GetRandomElement()
+	v, err := H.GetRandomElement()
+	assert.Nil(t, err)
 	u := homomorphism(v)
 
-	proved := preimage.ProvePreimageKnowledge(homomorphism, H, Q, u, v)
+	proved, err := preimage.ProvePreimageKnowledge(homomorphism, H, Q, u, v)
+	assert.Nil(t, err)
 
 	assert.Equal(t, true, proved, "FPreimage proof does not work correctly")
 }
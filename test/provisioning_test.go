@@ -0,0 +1,188 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/provisioning"
+	"github.com/xlab-si/emmy/server"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSCIM is a SCIM service provider which keeps the users in memory.
+type fakeSCIM struct {
+	users map[string]map[string]interface{} // by id
+	next  int
+	sync.Mutex
+}
+
+func (f *fakeSCIM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/scim/v2/Users/")
+	switch {
+	case r.Method == "POST":
+		var user map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&user)
+		for _, u := range f.users {
+			if u["userName"] == user["userName"] {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+		}
+		f.next++
+		user["id"] = string(rune('a' + f.next))
+		f.users[user["id"].(string)] = user
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(user)
+	case r.Method == "GET":
+		var resources []map[string]interface{}
+		for _, u := range f.users {
+			if strings.Contains(r.URL.Query().Get("filter"), u["userName"].(string)) {
+				resources = append(resources, u)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"totalResults": len(resources),
+			"Resources":    resources,
+		})
+	case r.Method == "PATCH" && f.users[id] != nil:
+		f.users[id]["active"] = false
+		json.NewEncoder(w).Encode(f.users[id])
+	case r.Method == "DELETE" && f.users[id] != nil:
+		delete(f.users, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestSCIMProvisioner(t *testing.T) {
+	scim := &fakeSCIM{users: make(map[string]map[string]interface{})}
+	srv := httptest.NewServer(scim)
+	defer srv.Close()
+
+	nym := pseudonymsys.NewPseudonym(big.NewInt(12), big.NewInt(34))
+	id := provisioning.NymAccountID(nym)
+	assert.True(t, strings.HasPrefix(id, "nym-"))
+	assert.NotEqual(t, id, provisioning.NymAccountID(
+		pseudonymsys.NewPseudonym(big.NewInt(12), big.NewInt(35))))
+
+	p := provisioning.NewSCIMProvisioner(srv.URL+"/scim/v2/", "secret")
+	assert.Nil(t, p.Provision(id))
+	assert.Nil(t, p.Provision(id), "provisioning an existing account should succeed")
+	assert.Equal(t, 1, len(scim.users))
+
+	p.Deactivate = true
+	assert.Nil(t, p.Deprovision(id))
+	for _, user := range scim.users {
+		assert.Equal(t, false, user["active"], "user should be deactivated")
+	}
+	p.Deactivate = false
+	assert.Nil(t, p.Deprovision(id))
+	assert.Equal(t, 0, len(scim.users), "user should be deleted")
+	assert.Nil(t, p.Deprovision(id), "deprovisioning an unknown account should succeed")
+
+	assert.NotNil(t, provisioning.NewSCIMProvisioner(srv.URL+"/scim/v2", "wrong").Provision(id))
+}
+
+func TestLDAPProvisioner(t *testing.T) {
+	var buf bytes.Buffer
+	p := provisioning.NewLDAPProvisioner(provisioning.NewLDIFWriter(&buf),
+		"ou=nyms,dc=example,dc=com")
+	assert.Nil(t, p.Provision("nym-0123"))
+	assert.Nil(t, p.Deprovision("nym-0123"))
+	assert.Equal(t, "dn: uid=nym-0123,ou=nyms,dc=example,dc=com\n"+
+		"changetype: add\n"+
+		"objectClass: top\n"+
+		"objectClass: account\n"+
+		"uid: nym-0123\n\n"+
+		"dn: uid=nym-0123,ou=nyms,dc=example,dc=com\n"+
+		"changetype: delete\n\n", buf.String())
+
+	buf.Reset()
+	provisioning.NewLDIFWriter(&buf).Add("cn=Žiga,dc=si", map[string][]string{"cn": {"Žiga"}})
+	assert.Equal(t, "dn:: Y249xb1pZ2EsZGM9c2k=\nchangetype: add\ncn:: xb1pZ2E=\n\n",
+		buf.String(), "values which are not safe strings should be base64 encoded")
+}
+
+// recordingProvisioner records the provisioned accounts.
+type recordingProvisioner struct {
+	accounts map[string]bool
+	sync.Mutex
+}
+
+func (p *recordingProvisioner) Provision(id string) error {
+	p.Lock()
+	defer p.Unlock()
+	p.accounts[id] = true
+	return nil
+}
+
+func (p *recordingProvisioner) Deprovision(id string) error {
+	p.Lock()
+	defer p.Unlock()
+	delete(p.accounts, id)
+	return nil
+}
+
+func TestGRPC_PseudonymsysProvisioning(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("provisioningServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer(logger)
+	assert.Nil(t, err)
+	provisioner := &recordingProvisioner{accounts: make(map[string]bool)}
+	srv.SetProvisioner(provisioner)
+	address, stop := startTestServer(t, srv)
+	defer stop()
+
+	conn, err := client.GetConnection(address, "testdata/server.pem", false)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	params := config.LoadPseudonymsysParams()
+	group := params.Group
+	caClient, err := client.NewPseudonymsysCAClient(conn, params)
+	assert.Nil(t, err)
+	c, err := client.NewPseudonymsysClient(conn, params)
+	assert.Nil(t, err)
+	userSecret, err := c.GenerateMasterKey()
+	assert.Nil(t, err)
+	masterNym := pseudonymsys.NewPseudonym(group.G, group.Exp(group.G, userSecret))
+	caCertificate, err := caClient.ObtainCertificate(userSecret, masterNym)
+	assert.Nil(t, err)
+	nym, err := c.GenerateNym(userSecret, caCertificate)
+	assert.Nil(t, err)
+
+	assert.Equal(t, map[string]bool{provisioning.NymAccountID(nym): true},
+		provisioner.accounts, "account should be provisioned for the generated nym")
+}
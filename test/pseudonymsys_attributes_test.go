@@ -0,0 +1,316 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/anoncreds"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+	"math/big"
+	"testing"
+)
+
+// obtainCredentialWithAttributes executes the credential issuance protocol without gRPC
+// for the organization which embeds attributes into the credential.
+func obtainCredentialWithAttributes(group *groups.SchnorrGroup,
+	org *pseudonymsys.OrgCredentialIssuer, orgPubKeys *pseudonymsys.OrgPubKeys,
+	userSecret *big.Int, nym *pseudonymsys.Pseudonym) (*pseudonymsys.Credential, error) {
+	gamma := randomInt(group.Q)
+	equalityVerifier1, err := dlogproofs.NewDLogEqualityBTranscriptVerifier(group, gamma)
+	if err != nil {
+		return nil, err
+	}
+	equalityVerifier2, err := dlogproofs.NewDLogEqualityBTranscriptVerifier(group, gamma)
+	if err != nil {
+		return nil, err
+	}
+
+	schnorrProver, err := dlogproofs.NewSchnorrProver(group)
+	if err != nil {
+		return nil, err
+	}
+	x, err := schnorrProver.GetProofRandomData(userSecret, nym.A)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := org.GetAuthenticationChallenge(nym.A, nym.B, x)
+	if err != nil {
+		return nil, err
+	}
+	z, _, err := schnorrProver.GetProofData(challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	x11, x12, x21, x22, A, B, err := org.VerifyAuthentication(z)
+	if err != nil {
+		return nil, err
+	}
+
+	issued := org.GetIssuedAttributes()
+	aA := group.Mul(nym.A, A)
+	challenges := make([]*big.Int, len(issued))
+	verifiers := make([]*dlogproofs.DLogEqualityBTranscriptVerifier, len(issued))
+	for i, attr := range issued {
+		v := group.Mul(group.Exp(nym.A, attr.Value), group.Exp(A, attr.R))
+		verifiers[i], err = dlogproofs.NewDLogEqualityBTranscriptVerifier(group, gamma)
+		if err != nil {
+			return nil, err
+		}
+		challenges[i], err = verifiers[i].GetChallenge(group.G, v, orgPubKeys.Attributes.H[i], attr.D,
+			attr.X1, attr.X2)
+		if err != nil {
+			return nil, err
+		}
+		aA = group.Mul(aA, attr.D)
+	}
+	challenge1, err := equalityVerifier1.GetChallenge(group.G, nym.B, orgPubKeys.H2, A, x11, x12)
+	if err != nil {
+		return nil, err
+	}
+	challenge2, err := equalityVerifier2.GetChallenge(group.G, aA, orgPubKeys.H1, B, x21, x22)
+	if err != nil {
+		return nil, err
+	}
+	z1, z2, err := org.GetEqualityProofData(challenge1, challenge2)
+	if err != nil {
+		return nil, err
+	}
+	zAttributes, err := org.GetAttributeProofData(challenges)
+	if err != nil {
+		return nil, err
+	}
+
+	verified1, transcript1, bToGamma, AToGamma := equalityVerifier1.Verify(z1)
+	verified2, transcript2, _, BToGamma := equalityVerifier2.Verify(z2)
+	if !verified1 || !verified2 {
+		return nil, fmt.Errorf("credential is not valid")
+	}
+	credential := pseudonymsys.NewCredential(group.Exp(nym.A, gamma), bToGamma, AToGamma,
+		BToGamma, transcript1, transcript2)
+	for i, attr := range issued {
+		verified, transcript, vToGamma, dToGamma := verifiers[i].Verify(zAttributes[i])
+		if !verified {
+			return nil, fmt.Errorf("attribute %s is not valid", attr.Name)
+		}
+		credential.Attributes = append(credential.Attributes,
+			&pseudonymsys.CredentialAttribute{
+				Name:  attr.Name,
+				Value: attr.Value,
+				R:     attr.R,
+				V:     vToGamma,
+				D:     dToGamma,
+				T:     transcript,
+			})
+	}
+	return credential, nil
+}
+
+// provePredicates proves the predicates about the hidden attributes of the credential
+// together with the transfer of the credential.
+func provePredicates(group *groups.SchnorrGroup, orgPubKeys *pseudonymsys.OrgPubKeys,
+	userSecret *big.Int, nym *pseudonymsys.Pseudonym, credential *pseudonymsys.Credential,
+	presented *pseudonymsys.Credential, predicates []*anoncreds.Predicate) bool {
+	prover, err := pseudonymsys.NewPredicateProver(group, credential, predicates)
+	if err != nil {
+		return false
+	}
+	verifier, err := pseudonymsys.NewPredicateVerifier(group, presented, predicates)
+	if err != nil {
+		return false
+	}
+	proofRandomData, err := prover.GetProofRandomData()
+	if err != nil {
+		return false
+	}
+	if err := verifier.SetProofRandomData(proofRandomData); err != nil {
+		return false
+	}
+
+	org := pseudonymsys.NewOrgCredentialVerifier(group, nil, nil)
+	equalityProver := dlogproofs.NewDLogEqualityProver(group)
+	x1, x2, err := equalityProver.GetProofRandomData(userSecret, nym.A, presented.SmallAToGamma)
+	if err != nil {
+		return false
+	}
+	challenge, err := org.GetAuthenticationChallenge(nym.A, nym.B,
+		presented.SmallAToGamma, presented.SmallBToGamma, x1, x2)
+	if err != nil {
+		return false
+	}
+	verifier.SetChallenge(challenge)
+	z, err := equalityProver.GetProofData(challenge)
+	if err != nil {
+		return false
+	}
+	return org.VerifyAuthentication(z, presented, orgPubKeys) &&
+		verifier.Verify(prover.GetProofData(challenge))
+}
+
+func TestPseudonymsysAttributes(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	s1, s2 := config.LoadPseudonymsysOrgSecrets("org1", "dlog")
+	h1, h2 := config.LoadPseudonymsysOrgPubKeys("org1")
+	keys, err := pseudonymsys.NewOrgAttributeKeys(group, []string{"age", "role"})
+	assert.Nil(t, err)
+	orgPubKeys := pseudonymsys.NewOrgPubKeys(h1, h2)
+	orgPubKeys.Attributes = keys.GetPubKeys(group)
+
+	org, err := pseudonymsys.NewOrgCredentialIssuer(group, s1, s2)
+	assert.Nil(t, err)
+	org.SetAttributes(keys, func(a, b *big.Int) (map[string]*big.Int, error) {
+		return map[string]*big.Int{"age": big.NewInt(25), "role": big.NewInt(3)}, nil
+	})
+
+	userSecret := randomInt(group.Q)
+	nymA := group.Exp(group.G, randomInt(group.Q))
+	nym := pseudonymsys.NewPseudonym(nymA, group.Exp(nymA, userSecret))
+	credential, err := obtainCredentialWithAttributes(group, org, orgPubKeys, userSecret, nym)
+	assert.Nil(t, err, "Credential with attributes should be issued")
+	assert.Equal(t, big.NewInt(25), credential.Attribute("age").Value)
+
+	nymA2 := group.Exp(group.G, randomInt(group.Q))
+	nym2 := pseudonymsys.NewPseudonym(nymA2, group.Exp(nymA2, userSecret))
+	assert.True(t, transferCredential(group, orgPubKeys, userSecret, nym2, credential),
+		"Credential with disclosed attributes should be accepted")
+	assert.True(t, transferCredential(group, orgPubKeys, userSecret, nym2,
+		credential.Present([]string{"role"})), "Credential with hidden age should be accepted")
+	assert.Nil(t, credential.Present([]string{"role"}).Attribute("age").Value,
+		"Hidden attribute should not have a value")
+	assert.NotNil(t, credential.Attribute("age").Value,
+		"Presenting should not change the credential")
+
+	// the values of the attributes cannot be changed
+	forged := credential.Present([]string{"age", "role"})
+	forged.Attributes[0].Value = big.NewInt(30)
+	assert.False(t, transferCredential(group, orgPubKeys, userSecret, nym2, forged),
+		"Credential with a changed attribute should not be accepted")
+	// the attributes cannot be removed
+	removed := credential.Present(nil)
+	removed.Attributes = removed.Attributes[1:]
+	assert.False(t, transferCredential(group, orgPubKeys, userSecret, nym2, removed),
+		"Credential without an attribute should not be accepted")
+	assert.False(t, transferCredential(group, pseudonymsys.NewOrgPubKeys(h1, h2), userSecret,
+		nym2, credential), "Credential should not be accepted without the attribute keys")
+
+	presented := credential.Present(nil)
+	predicate := func(t anoncreds.PredicateType, bound int64) []*anoncreds.Predicate {
+		return []*anoncreds.Predicate{
+			{Attribute: "age", Type: t, Bound: big.NewInt(bound)},
+		}
+	}
+	assert.True(t, provePredicates(group, orgPubKeys, userSecret, nym2, credential, presented,
+		predicate(anoncreds.GreaterOrEqual, 18)), "age >= 18 should be proved")
+	assert.True(t, provePredicates(group, orgPubKeys, userSecret, nym2, credential, presented,
+		predicate(anoncreds.LessOrEqual, 25)), "age <= 25 should be proved")
+	assert.False(t, provePredicates(group, orgPubKeys, userSecret, nym2, credential, presented,
+		predicate(anoncreds.GreaterOrEqual, 26)), "age >= 26 should not be proved")
+	assert.False(t, provePredicates(group, orgPubKeys, userSecret, nym2, credential,
+		credential.Present([]string{"age"}), predicate(anoncreds.GreaterOrEqual, 18)),
+		"Predicates should be proved only about hidden attributes")
+
+	// the commitment of the hidden attribute cannot be replaced
+	otherCredential, err := obtainCredentialWithAttributes(group, org, orgPubKeys, userSecret,
+		nym)
+	assert.Nil(t, err)
+	swapped := credential.Present(nil)
+	swapped.Attributes[0] = otherCredential.Present(nil).Attributes[0]
+	assert.False(t, transferCredential(group, orgPubKeys, userSecret, nym2, swapped),
+		"Credential with an attribute of another credential should not be accepted")
+}
+
+func TestGRPC_PseudonymsysAttributes(t *testing.T) {
+	params := config.LoadPseudonymsysParams()
+	group := params.Group
+	keys, err := pseudonymsys.NewOrgAttributeKeys(group, []string{"age", "role"})
+	assert.Nil(t, err)
+
+	srv, err := server.NewServer(log.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.SetPseudonymsysAttributes(keys, func(a, b *big.Int) (map[string]*big.Int, error) {
+		return map[string]*big.Int{"age": big.NewInt(25), "role": big.NewInt(3)}, nil
+	})
+	srv.SetPseudonymsysAttributeVerifier(map[string]*pseudonymsys.OrgAttributePubKeys{
+		"org1": keys.GetPubKeys(group),
+	}, &anoncreds.PresentationRequest{
+		Disclosed: []string{"role"},
+		Predicates: []*anoncreds.Predicate{
+			{Attribute: "age", Type: anoncreds.GreaterOrEqual, Bound: big.NewInt(18)},
+		},
+	})
+	address, stop := startTestServer(t, srv)
+	defer stop()
+
+	conn, err := client.GetConnection(address, "testdata/server.pem", false)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	caClient, err := client.NewPseudonymsysCAClient(conn, params)
+	assert.Nil(t, err)
+	c, err := client.NewPseudonymsysClient(conn, params)
+	assert.Nil(t, err)
+	userSecret, err := c.GenerateMasterKey()
+	assert.Nil(t, err)
+	masterNym := pseudonymsys.NewPseudonym(group.G, group.Exp(group.G, userSecret))
+	caCertificate, err := caClient.ObtainCertificate(userSecret, masterNym)
+	assert.Nil(t, err)
+	nym1, err := c.GenerateNym(userSecret, caCertificate)
+	assert.Nil(t, err)
+
+	h1, h2 := config.LoadPseudonymsysOrgPubKeys("org1")
+	orgPubKeys := pseudonymsys.NewOrgPubKeys(h1, h2)
+	_, err = c.ObtainCredential(userSecret, nym1, orgPubKeys)
+	assert.NotNil(t, err, "Credential should not be obtained without the attribute keys")
+	orgPubKeys.Attributes = keys.GetPubKeys(group)
+	credential, err := c.ObtainCredential(userSecret, nym1, orgPubKeys)
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(3), credential.Attribute("role").Value)
+
+	caCertificate2, err := caClient.ObtainCertificate(userSecret, masterNym)
+	assert.Nil(t, err)
+	nym2, err := c.GenerateNym(userSecret, caCertificate2)
+	assert.Nil(t, err)
+
+	request := &anoncreds.PresentationRequest{
+		Disclosed: []string{"role"},
+		Predicates: []*anoncreds.Predicate{
+			{Attribute: "age", Type: anoncreds.GreaterOrEqual, Bound: big.NewInt(18)},
+		},
+	}
+	sessionKey, err := c.TransferCredentialWithAttributes("org1", userSecret, nym2, credential,
+		request)
+	assert.Nil(t, err)
+	assert.NotNil(t, sessionKey, "Credential should be accepted")
+
+	_, err = c.TransferCredential("org1", userSecret, nym2, credential)
+	assert.NotNil(t, err, "Credential should not be accepted without the required disclosure")
+
+	request.Disclosed = []string{"role", "age"}
+	request.Predicates = nil
+	_, err = c.TransferCredentialWithAttributes("org1", userSecret, nym2, credential, request)
+	assert.NotNil(t, err, "Credential should not be accepted without the required predicate")
+}
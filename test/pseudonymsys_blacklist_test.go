@@ -0,0 +1,182 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+	"testing"
+)
+
+// newBlacklistNymRegistry returns a nym registry with a fresh signing key.
+func newBlacklistNymRegistry(t *testing.T) *pseudonymsys.NymRegistry {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("error when generating key: %v", err)
+	}
+	return pseudonymsys.NewNymRegistry(0, key.D, key.X, key.Y)
+}
+
+func TestPseudonymsysBlacklist(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	registry := newBlacklistNymRegistry(t)
+	verifier := pseudonymsys.NewBlacklistVerifier(group, registry)
+
+	authenticate := func(secret *big.Int, nym *pseudonymsys.Pseudonym) (
+		*pseudonymsys.BlacklistTicket, bool) {
+		prover := pseudonymsys.NewBlacklistProver(group, secret, nym)
+		ticket, err := prover.GetTicket()
+		assert.Nil(t, err)
+		x1, x2, cs, ys, ws, err := prover.GetProofRandomData(verifier.GetBlacklist())
+		if err != nil {
+			return ticket, false
+		}
+		challenge, err := verifier.GetChallenge(nym, ticket, x1, x2, cs, ys, ws)
+		if err != nil {
+			return ticket, false
+		}
+		z, zAlphas, zBetas := prover.GetProofData(challenge)
+		return ticket, verifier.Verify(z, zAlphas, zBetas)
+	}
+	selfMadeNym := func(secret *big.Int) *pseudonymsys.Pseudonym {
+		a := group.Exp(group.G, randomInt(group.Q))
+		return pseudonymsys.NewPseudonym(a, group.Exp(a, secret))
+	}
+	// nyms are registered by the organization once the user proves that they belong to
+	// its certified master key
+	generateNym := func(secret *big.Int) *pseudonymsys.Pseudonym {
+		nym := selfMadeNym(secret)
+		assert.Nil(t, registry.Register(nym))
+		return nym
+	}
+
+	secret1 := randomInt(group.Q)
+	secret2 := randomInt(group.Q)
+	ticket1, ok := authenticate(secret1, generateNym(secret1))
+	assert.Equal(t, true, ok, "Authentication with empty blacklist should succeed")
+	_, ok = authenticate(secret2, generateNym(secret2))
+	assert.Equal(t, true, ok, "Authentication with empty blacklist should succeed")
+
+	verifier.AddToBlacklist(ticket1)
+	_, ok = authenticate(secret2, generateNym(secret2))
+	assert.Equal(t, true, ok, "User who is not blacklisted should authenticate")
+	_, ok = authenticate(secret1, generateNym(secret1))
+	assert.Equal(t, false, ok, "Blacklisted user should not authenticate (even with a new nym)")
+
+	// the blacklisted user generates a nym of a fresh master key, which is not on
+	// the blacklist, but the nym is not registered
+	secret3 := randomInt(group.Q)
+	_, ok = authenticate(secret3, selfMadeNym(secret3))
+	assert.Equal(t, false, ok, "Blacklisted user should not authenticate with a self-made nym")
+	_, ok = authenticate(secret2, selfMadeNym(secret2))
+	assert.Equal(t, false, ok, "Unregistered nym should be rejected")
+}
+
+func TestPseudonymsysBlacklistInvalidTicket(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	registry := newBlacklistNymRegistry(t)
+	verifier := pseudonymsys.NewBlacklistVerifier(group, registry)
+	secret := randomInt(group.Q)
+	a := group.Exp(group.G, randomInt(group.Q))
+	nym := pseudonymsys.NewPseudonym(a, group.Exp(a, secret))
+	assert.Nil(t, registry.Register(nym))
+
+	prover := pseudonymsys.NewBlacklistProver(group, secret, nym)
+	ticket, err := prover.GetTicket()
+	assert.Nil(t, err)
+	x1, x2, cs, ys, ws, err := prover.GetProofRandomData(verifier.GetBlacklist())
+	assert.Nil(t, err)
+
+	minusOne := new(big.Int).Sub(group.P, big.NewInt(1)) // of order 2, not in the group
+	invalid := []*pseudonymsys.BlacklistTicket{
+		nil,
+		pseudonymsys.NewBlacklistTicket(big.NewInt(0), big.NewInt(0)),
+		pseudonymsys.NewBlacklistTicket(big.NewInt(1), big.NewInt(1)),
+		pseudonymsys.NewBlacklistTicket(minusOne, ticket.Tag),
+		pseudonymsys.NewBlacklistTicket(ticket.H, big.NewInt(1)),
+		pseudonymsys.NewBlacklistTicket(ticket.H, minusOne),
+		pseudonymsys.NewBlacklistTicket(ticket.H, new(big.Int).Add(ticket.Tag, group.P)),
+	}
+	for _, tk := range invalid {
+		_, err := verifier.GetChallenge(nym, tk, x1, x2, cs, ys, ws)
+		assert.NotNil(t, err, "invalid ticket should be rejected")
+	}
+
+	_, err = verifier.GetChallenge(nym, ticket, minusOne, x2, cs, ys, ws)
+	assert.NotNil(t, err, "first message outside of the group should be rejected")
+
+	challenge, err := verifier.GetChallenge(nym, ticket, x1, x2, cs, ys, ws)
+	assert.Nil(t, err)
+	z, zAlphas, zBetas := prover.GetProofData(challenge)
+	assert.True(t, verifier.Verify(z, zAlphas, zBetas), "valid ticket should be accepted")
+}
+
+func TestPseudonymsysBlacklistCredential(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	s1, s2 := config.LoadPseudonymsysOrgSecrets("org1", "dlog")
+	h1, h2 := config.LoadPseudonymsysOrgPubKeys("org1")
+	orgPubKeys := pseudonymsys.NewOrgPubKeys(h1, h2)
+	org, err := pseudonymsys.NewOrgCredentialIssuer(group, s1, s2)
+	assert.Nil(t, err)
+	verifier := pseudonymsys.NewCredentialBlacklistVerifier(group, orgPubKeys)
+
+	authenticate := func(secret *big.Int, credential *pseudonymsys.Credential) (
+		*pseudonymsys.BlacklistTicket, bool) {
+		nym := pseudonymsys.NewPseudonym(credential.SmallAToGamma, credential.SmallBToGamma)
+		prover := pseudonymsys.NewBlacklistProver(group, secret, nym)
+		ticket, err := prover.GetTicket()
+		assert.Nil(t, err)
+		x1, x2, cs, ys, ws, err := prover.GetProofRandomData(verifier.GetBlacklist())
+		if err != nil {
+			return ticket, false
+		}
+		challenge, err := verifier.GetChallengeForCredential(credential, ticket, x1, x2, cs,
+			ys, ws)
+		if err != nil {
+			return ticket, false
+		}
+		z, zAlphas, zBetas := prover.GetProofData(challenge)
+		return ticket, verifier.Verify(z, zAlphas, zBetas)
+	}
+	issue := func(secret *big.Int) *pseudonymsys.Credential {
+		a := group.Exp(group.G, randomInt(group.Q))
+		return obtainCredential(group, org, orgPubKeys, secret,
+			pseudonymsys.NewPseudonym(a, group.Exp(a, secret)))
+	}
+
+	secret := randomInt(group.Q)
+	credential := issue(secret)
+	ticket, ok := authenticate(secret, credential)
+	assert.Equal(t, true, ok, "Authentication with an issued credential should succeed")
+	verifier.AddToBlacklist(ticket)
+	_, ok = authenticate(secret, issue(secret))
+	assert.Equal(t, false, ok, "Blacklisted user should not authenticate (even with a new credential)")
+
+	// the blacklisted user replaces the nym of its credential with a nym of a fresh master key
+	secret = randomInt(group.Q)
+	a := group.Exp(group.G, randomInt(group.Q))
+	selfMade := pseudonymsys.NewCredential(a, group.Exp(a, secret), credential.AToGamma,
+		credential.BToGamma, credential.T1, credential.T2)
+	_, ok = authenticate(secret, selfMade)
+	assert.Equal(t, false, ok, "Blacklisted user should not authenticate with a self-made nym")
+}
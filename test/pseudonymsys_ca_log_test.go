@@ -0,0 +1,101 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+	"testing"
+)
+
+func TestPseudonymsysCALog(t *testing.T) {
+	d, x, y := config.LoadPseudonymsysCALogKey()
+	log := pseudonymsys.NewCALog(d, x, y)
+
+	certs := make([]*pseudonymsys.CACertificate, 3)
+	for i := range certs {
+		certs[i] = pseudonymsys.NewCACertificate(big.NewInt(int64(i+1)), big.NewInt(2),
+			big.NewInt(3), big.NewInt(4), pseudonymsys.ECDSA)
+		sct, err := log.Submit(certs[i])
+		assert.Nil(t, err)
+		assert.True(t, pseudonymsys.VerifySignedCertificateTimestamp(x, y, certs[i], sct),
+			"signed certificate timestamp should be verified")
+		assert.False(t, pseudonymsys.VerifySignedCertificateTimestamp(x, y, certs[0],
+			pseudonymsys.NewSignedCertificateTimestamp(sct.Timestamp+1, sct.R, sct.S)),
+			"timestamp with changed time should not be verified")
+	}
+
+	entries, err := log.GetEntries(0, len(certs))
+	assert.Nil(t, err)
+	sth, err := log.GetSignedTreeHead()
+	assert.Nil(t, err)
+	assert.Equal(t, len(certs), sth.Size)
+	assert.True(t, pseudonymsys.VerifySignedTreeHead(x, y, sth, entries),
+		"tree head should be verified")
+
+	proof, err := log.GetInclusionProof(1, sth.Size)
+	assert.Nil(t, err)
+	assert.True(t, pseudonymsys.VerifyCALogInclusion(x, y, sth, entries[1], 1, proof),
+		"certificate should be included in the log")
+	assert.False(t, pseudonymsys.VerifyCALogInclusion(x, y, sth, entries[0], 1, proof),
+		"inclusion proof of a different certificate should not be verified")
+
+	oldSTH := sth
+	_, err = log.Submit(pseudonymsys.NewCACertificate(big.NewInt(5), big.NewInt(2),
+		big.NewInt(3), big.NewInt(4), pseudonymsys.ECDSA))
+	assert.Nil(t, err)
+	sth, err = log.GetSignedTreeHead()
+	assert.Nil(t, err)
+	proof, err = log.GetConsistencyProof(oldSTH.Size, sth.Size)
+	assert.Nil(t, err)
+	assert.True(t, pseudonymsys.VerifyCALogConsistency(x, y, oldSTH, sth, proof),
+		"new tree head should extend the old one")
+	forgedSTH := *oldSTH
+	forgedSTH.Root = sth.Root
+	assert.False(t, pseudonymsys.VerifyCALogConsistency(x, y, &forgedSTH, sth, proof),
+		"tree head with changed root should not be verified")
+
+	assert.False(t, pseudonymsys.VerifySignedTreeHead(x, y, sth, entries[1:]),
+		"tree head should not be verified with missing entry")
+	entries[1] = &pseudonymsys.CALogEntry{
+		Certificate: certs[0],
+		Timestamp:   entries[1].Timestamp,
+	}
+	assert.False(t, pseudonymsys.VerifySignedTreeHead(x, y, sth, entries),
+		"tree head should not be verified with changed entry")
+
+	_, err = log.GetEntries(2, sth.Size+1)
+	assert.NotNil(t, err, "entries beyond the log should not be returned")
+}
+
+// TestPseudonymsysCAClientLog requires a running server (it is started in
+// communication_test.go).
+func TestPseudonymsysCAClientLog(t *testing.T) {
+	params := config.LoadPseudonymsysParams()
+	caClient, err := client.NewPseudonymsysCAClient(testGrpcClientConn, params)
+	assert.Nil(t, err)
+	_, x, y := config.LoadPseudonymsysCALogKey()
+	caClient.SetCALogPubKey(x, y)
+
+	// the client checks the signed certificate timestamp of the log
+	_, caCertificate := obtainPseudonymsysCertificate(t, caClient, params)
+	assert.NotNil(t, caCertificate, "Certificate with a valid timestamp should be obtained")
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+	"testing"
+)
+
+func TestPseudonymsysCAStatus(t *testing.T) {
+	x, y := config.LoadPseudonymsysCAPubKey()
+	responder := pseudonymsys.NewCAStatusResponder(config.LoadPseudonymsysCASecret(), x, y)
+
+	cert := pseudonymsys.NewCACertificate(big.NewInt(1), big.NewInt(2), big.NewInt(3),
+		big.NewInt(4), pseudonymsys.ECDSA)
+	other := pseudonymsys.NewCACertificate(big.NewInt(5), big.NewInt(6), big.NewInt(7),
+		big.NewInt(8), pseudonymsys.ECDSA)
+
+	status, err := responder.GetStatus(cert)
+	assert.Nil(t, err)
+	assert.Equal(t, pseudonymsys.Good, status.Status)
+	assert.True(t, pseudonymsys.VerifyCAStatusResponse(x, y, cert, status),
+		"status response should be verified")
+	assert.False(t, pseudonymsys.VerifyCAStatusResponse(x, y, other, status),
+		"status response should not be verified for another certificate")
+
+	responder.Revoke(cert)
+	assert.True(t, responder.IsRevoked(cert))
+	assert.False(t, responder.IsRevoked(other))
+
+	status, err = responder.GetStatus(cert)
+	assert.Nil(t, err)
+	assert.Equal(t, pseudonymsys.Revoked, status.Status)
+	assert.True(t, pseudonymsys.VerifyCAStatusResponse(x, y, cert, status),
+		"status response should be verified")
+
+	forged := pseudonymsys.NewCAStatusResponse(pseudonymsys.Good, status.Timestamp,
+		status.R, status.S)
+	assert.False(t, pseudonymsys.VerifyCAStatusResponse(x, y, cert, forged),
+		"changed status should not be verified")
+}
+
+// TestPseudonymsysCAClientStatus requires a running server (it is started in
+// communication_test.go).
+func TestPseudonymsysCAClientStatus(t *testing.T) {
+	params := config.LoadPseudonymsysParams()
+	caClient, err := client.NewPseudonymsysCAClient(testGrpcClientConn, params)
+	assert.Nil(t, err)
+	_, caCertificate := obtainPseudonymsysCertificate(t, caClient, params)
+
+	caX, caY := config.LoadPseudonymsysCAPubKey()
+	status, err := caClient.GetCertificateStatus(caCertificate)
+	assert.Nil(t, err)
+	assert.Equal(t, pseudonymsys.Good, status.Status)
+	assert.True(t, pseudonymsys.VerifyCAStatusResponse(caX, caY, caCertificate, status),
+		"CA certificate status should be verified")
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+	"testing"
+)
+
+func TestPseudonymsysCASignatureAlgorithms(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	caPubKeys := config.LoadPseudonymsysCAPubKeys()
+	algs := []pseudonymsys.SignatureAlgorithm{
+		pseudonymsys.ECDSA,
+		pseudonymsys.Ed25519,
+		pseudonymsys.Schnorr,
+	}
+
+	userSecret := randomInt(group.Q)
+	b := group.Exp(group.G, userSecret)
+
+	for _, alg := range algs {
+		ca, err := pseudonymsys.NewCAWithSigners(group, config.LoadPseudonymsysCASigners()...)
+		assert.Nil(t, err)
+		negotiated, err := ca.Negotiate([]pseudonymsys.SignatureAlgorithm{alg})
+		assert.Nil(t, err)
+		assert.Equal(t, alg, negotiated)
+
+		prover, err := dlogproofs.NewSchnorrProver(group)
+		assert.Nil(t, err)
+		x, err := prover.GetProofRandomData(userSecret, group.G)
+		assert.Nil(t, err)
+		challenge, err := ca.GetChallenge(group.G, b, x)
+		assert.Nil(t, err)
+		z, _, err := prover.GetProofData(challenge)
+		assert.Nil(t, err)
+		cert, err := ca.Verify(z)
+		assert.Nil(t, err)
+		assert.Equal(t, alg, cert.Algorithm, "certificate should specify the algorithm")
+
+		verified := 0
+		for _, pubKey := range caPubKeys {
+			if cert.Verify(pubKey) {
+				verified++
+				assert.Equal(t, alg, pubKey.Algorithm())
+			}
+		}
+		assert.Equal(t, 1, verified, "certificate should be verified with %v key", alg)
+
+		tampered := pseudonymsys.NewCACertificate(cert.BlindedA, cert.BlindedB,
+			cert.R, new(big.Int).Add(cert.S, big.NewInt(1)), cert.Algorithm)
+		for _, pubKey := range caPubKeys {
+			assert.False(t, tampered.Verify(pubKey), "tampered %v certificate verified", alg)
+		}
+	}
+
+	x, y := config.LoadPseudonymsysCAPubKey()
+	ca, err := pseudonymsys.NewCA(group, config.LoadPseudonymsysCASecret(), x, y)
+	assert.Nil(t, err)
+	_, err = ca.Negotiate([]pseudonymsys.SignatureAlgorithm{pseudonymsys.Ed25519})
+	assert.NotNil(t, err, "CA without Ed25519 key should not agree on Ed25519")
+}
+
+func TestPseudonymsysCACertificateShow(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	rsa, err := signatures.NewRSA(1024)
+	if err != nil {
+		t.Fatalf("error when generating RSA key: %v", err)
+	}
+	rsaPubKey := rsa.GetPubKey()
+	ca, err := pseudonymsys.NewCAWithSigners(group, pseudonymsys.NewRSASigner(rsa))
+	assert.Nil(t, err)
+	_, err = ca.Negotiate([]pseudonymsys.SignatureAlgorithm{pseudonymsys.RSA})
+	assert.Nil(t, err)
+
+	userSecret := randomInt(group.Q)
+	b := group.Exp(group.G, userSecret)
+	prover, err := dlogproofs.NewSchnorrProver(group)
+	assert.Nil(t, err)
+	x, err := prover.GetProofRandomData(userSecret, group.G)
+	assert.Nil(t, err)
+	challenge, err := ca.GetChallenge(group.G, b, x)
+	assert.Nil(t, err)
+	z, _, err := prover.GetProofData(challenge)
+	assert.Nil(t, err)
+	cert, err := ca.Verify(z)
+	assert.Nil(t, err)
+	assert.Equal(t, pseudonymsys.RSA, cert.Algorithm)
+	assert.True(t, cert.Verify(pseudonymsys.NewRSAPubKey(rsaPubKey)), "RSA certificate should verify")
+
+	showProver, err := pseudonymsys.NewCACertificateShowProver(cert, rsaPubKey)
+	assert.Nil(t, err)
+	showVerifier, err := pseudonymsys.NewCACertificateShowVerifier(cert.BlindedA,
+		cert.BlindedB, rsaPubKey)
+	assert.Nil(t, err)
+	proofRandomData, err := showProver.GetProofRandomData()
+	assert.Nil(t, err)
+	challenges, err := showVerifier.GetChallenge(proofRandomData)
+	assert.Nil(t, err)
+	proofData, err := showProver.GetProofData(challenges)
+	assert.Nil(t, err)
+	assert.True(t, showVerifier.Verify(proofData), "CA certificate show should pass")
+
+	otherVerifier, _ := pseudonymsys.NewCACertificateShowVerifier(cert.BlindedA,
+		group.Exp(cert.BlindedB, big.NewInt(2)), rsaPubKey)
+	proofRandomData, _ = showProver.GetProofRandomData()
+	challenges, _ = otherVerifier.GetChallenge(proofRandomData)
+	proofData, _ = showProver.GetProofData(challenges)
+	assert.False(t, otherVerifier.Verify(proofData), "show of other certificate should fail")
+}
+
+// TestPseudonymsysCAClientSignatureAlgorithm requires a running server (it is started in
+// communication_test.go).
+func TestPseudonymsysCAClientSignatureAlgorithm(t *testing.T) {
+	params := config.LoadPseudonymsysParams()
+	caClient, err := client.NewPseudonymsysCAClient(testGrpcClientConn, params)
+	assert.Nil(t, err)
+	caClient.SetSignatureAlgorithms(pseudonymsys.ECDSA)
+
+	_, caCertificate := obtainPseudonymsysCertificate(t, caClient, params)
+	assert.Equal(t, pseudonymsys.ECDSA, caCertificate.Algorithm,
+		"CA should sign with the requested algorithm")
+}
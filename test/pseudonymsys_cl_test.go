@@ -0,0 +1,101 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+	"testing"
+)
+
+func TestPseudonymsysCLCredential(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	nymSecret := randomInt(group.Q)
+	a := group.Exp(group.G, randomInt(group.Q))
+	nym := pseudonymsys.NewPseudonym(a, group.Exp(a, nymSecret))
+
+	cl := signatures.NewCL(2)
+	// block 0 is the user's master secret, block 1 is the attribute assigned by the organization
+	attributes := func(a, b *big.Int) (map[int]*big.Int, error) {
+		if a.Cmp(nym.A) != 0 || b.Cmp(nym.B) != 0 {
+			return nil, errors.New("unknown nym")
+		}
+		return map[int]*big.Int{1: big.NewInt(18)}, nil
+	}
+	masterSecret := randomInt(new(big.Int).Lsh(big.NewInt(1), 159))
+
+	obtainCredential := func(secret *big.Int) (*signatures.CLSignature, []*big.Int, error) {
+		org, err := pseudonymsys.NewOrgCLCredentialIssuer(group, cl, attributes)
+		if err != nil {
+			return nil, nil, err
+		}
+		prover, _ := dlogproofs.NewSchnorrProver(group)
+		x, err := prover.GetProofRandomData(secret, nym.A)
+		if err != nil {
+			return nil, nil, err
+		}
+		challenge, err := org.GetAuthenticationChallenge(nym.A, nym.B, x)
+		if err != nil {
+			return nil, nil, err
+		}
+		z, _, err := prover.GetProofData(challenge)
+		assert.Nil(t, err)
+		known, err := org.VerifyAuthentication(z)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		receiver, err := signatures.NewCLIssueReceiver(cl.GetPubKey(),
+			map[int]*big.Int{0: masterSecret})
+		if err != nil {
+			return nil, nil, err
+		}
+		U, tt, err := receiver.GetProofRandomData()
+		if err != nil {
+			return nil, nil, err
+		}
+		challenge, err = org.GetCommitmentChallenge(U, tt)
+		if err != nil {
+			return nil, nil, err
+		}
+		v, e, s, err := org.IssueCredential(receiver.GetProofData(challenge))
+		if err != nil {
+			return nil, nil, err
+		}
+		_, _, _, err = org.IssueCredential(receiver.GetProofData(challenge))
+		assert.NotNil(t, err, "authentication should be used for a single credential")
+		return receiver.GetSignature(v, e, s, known)
+	}
+
+	signature, m_Ls, err := obtainCredential(nymSecret)
+	assert.Nil(t, err, "CL credential should be issued to the nym")
+	assert.Equal(t, big.NewInt(18), m_Ls[1])
+
+	// the credential is shown with the attribute disclosed and the master secret hidden
+	proved, err := signatures.ProveCLPossession(cl.GetPubKey(), m_Ls, signature, []int{1})
+	assert.Nil(t, err)
+	assert.True(t, proved, "CL credential should be shown")
+
+	_, _, err = obtainCredential(randomInt(group.Q))
+	assert.NotNil(t, err, "CL credential should not be issued without authentication")
+}
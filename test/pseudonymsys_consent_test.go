@@ -0,0 +1,69 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+	"testing"
+)
+
+func TestPseudonymsysConsentReceipt(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	wallet, err := pseudonymsys.NewConsentWallet()
+	if err != nil {
+		t.Errorf("Error when creating consent wallet: %v", err)
+	}
+
+	transcript := []*big.Int{group.G, big.NewInt(13), big.NewInt(17)}
+	disclosed := []string{"credential issued by org1"}
+	receipt, err := wallet.AddReceipt("org2", disclosed, transcript...)
+	if err != nil {
+		t.Errorf("Error when issuing consent receipt: %v", err)
+	}
+
+	assert.Equal(t, 1, len(wallet.GetReceipts()), "receipt not stored in the wallet")
+	assert.Nil(t, pseudonymsys.VerifyConsentReceipt(wallet.GetPubKey(), receipt),
+		"consent receipt verification failed")
+	assert.True(t, receipt.MatchesTranscript(transcript...),
+		"consent receipt does not match the transcript")
+	assert.False(t, receipt.MatchesTranscript(group.G, big.NewInt(13)),
+		"consent receipt matches a different transcript")
+	// 13 and 17 are encoded as bytes 0x0d 0x11, which are also the bytes of 3345
+	assert.False(t, receipt.MatchesTranscript(group.G, big.NewInt(3345)),
+		"consent receipt matches a transcript with concatenated values")
+
+	receipt.TranscriptHash = append([]byte{0}, receipt.TranscriptHash...)
+	assert.NotNil(t, pseudonymsys.VerifyConsentReceipt(wallet.GetPubKey(), receipt),
+		"consent receipt with a leading zero in the transcript hash verified")
+	receipt.TranscriptHash = receipt.TranscriptHash[1:]
+	assert.Nil(t, pseudonymsys.VerifyConsentReceipt(wallet.GetPubKey(), receipt),
+		"consent receipt verification failed")
+
+	receipt2, err := wallet.AddReceipt("org2", []string{"name", "age"}, transcript...)
+	assert.Nil(t, err)
+	receipt2.Disclosed = []string{"name\x00age"}
+	assert.NotNil(t, pseudonymsys.VerifyConsentReceipt(wallet.GetPubKey(), receipt2),
+		"consent receipt with differently split disclosed names verified")
+
+	receipt.Recipient = "org3"
+	assert.NotNil(t, pseudonymsys.VerifyConsentReceipt(wallet.GetPubKey(), receipt),
+		"modified consent receipt verified")
+}
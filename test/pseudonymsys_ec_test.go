@@ -31,14 +31,15 @@ import (
 func TestPseudonymsysEC(t *testing.T) {
 	curveType := dlog.P256
 	ecdlog := dlog.NewECDLog(curveType)
-	caClient, err := client.NewPseudonymsysCAClientEC(testGrpcClientConn, curveType)
+	caClient, err := client.NewPseudonymsysCAClientEC(testGrpcClientConn, client.WithCurve(curveType))
 	if err != nil {
 		t.Errorf("Error when initializing NewPseudonymsysCAClientEC")
 	}
 
 	// usually the endpoint is different from the one used for CA:
-	c1, err := client.NewPseudonymsysClientEC(testGrpcClientConn, curveType)
-	userSecret := c1.GenerateMasterKey()
+	c1, err := client.NewPseudonymsysClientEC(testGrpcClientConn, client.WithCurve(curveType))
+	userSecret, err := c1.GenerateMasterKey()
+	assert.Nil(t, err)
 
 	nymA := types.NewECGroupElement(ecdlog.Curve.Params().Gx, ecdlog.Curve.Params().Gy)
 	nymB1, nymB2 := ecdlog.Exponentiate(nymA.X, nymA.Y, userSecret) // this is user's public key
@@ -67,13 +68,13 @@ func TestPseudonymsysEC(t *testing.T) {
 
 	// register with org2
 	// create a client to communicate with org2
-	caClient1, err := client.NewPseudonymsysCAClientEC(testGrpcClientConn, curveType)
+	caClient1, err := client.NewPseudonymsysCAClientEC(testGrpcClientConn, client.WithCurve(curveType))
 	caCertificate1, err := caClient1.ObtainCertificate(userSecret, masterNym)
 	if err != nil {
 		t.Errorf("Error when registering with CA")
 	}
 
-	c2, err := client.NewPseudonymsysClientEC(testGrpcClientConn, curveType)
+	c2, err := client.NewPseudonymsysClientEC(testGrpcClientConn, client.WithCurve(curveType))
 	nym2, err := c2.GenerateNym(userSecret, caCertificate1)
 	if err != nil {
 		t.Errorf(err.Error())
@@ -0,0 +1,168 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/anoncreds"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPseudonymsysEpochTicket(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	issuer, err := anoncreds.NewIssuer([]string{"person"}, getTestDFParams(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey := issuer.GetPublicKey()
+	limiter, err := pseudonymsys.NewRateLimiter(group,
+		pseudonymsys.NewRateLimitParams(2, 24*time.Hour))
+	assert.Nil(t, err)
+	epoch := limiter.GetEpoch()
+	issue := func(holder *anoncreds.Holder) *anoncreds.Credential {
+		credential, err := anoncreds.IssueCredential(issuer, holder,
+			map[string]*big.Int{"person": big.NewInt(1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return credential
+	}
+
+	useTicket := func(scope string, epoch int64, pubKey *anoncreds.PublicKey,
+		credential *anoncreds.Credential) (*big.Int, error) {
+		prover, err := pseudonymsys.NewEpochTicketProver(group, pubKey, credential)
+		if err != nil {
+			return nil, err
+		}
+		verifier := pseudonymsys.NewEpochTicketVerifier(limiter, issuer.GetPublicKey(), scope)
+		ticket := prover.GetTicket(scope, epoch)
+		v, tt, x := prover.GetProofRandomData()
+		challenge, err := verifier.GetChallenge(epoch, ticket, v, tt, x)
+		if err != nil {
+			return ticket, err
+		}
+		zE, zS, zM := prover.GetProofData(challenge)
+		return ticket, verifier.Verify(zE, zS, zM)
+	}
+
+	holder1, err := anoncreds.NewHolder()
+	assert.Nil(t, err)
+	credential1 := issue(holder1)
+	ticket1, err := useTicket("service1", epoch, pubKey, credential1)
+	assert.Nil(t, err, "Ticket should be accepted")
+	// another credential with the same master secret gives the same ticket
+	ticket2, err := useTicket("service1", epoch, pubKey, issue(holder1))
+	assert.Nil(t, err, "Ticket should be accepted until the limit is reached")
+	assert.Equal(t, ticket1, ticket2, "Tickets of the same epoch should be the same")
+	_, err = useTicket("service1", epoch, pubKey, credential1)
+	assert.NotNil(t, err, "Ticket should not be accepted when the limit is reached")
+
+	holder2, err := anoncreds.NewHolder()
+	assert.Nil(t, err)
+	credential2 := issue(holder2)
+	_, err = useTicket("service1", epoch, pubKey, credential2)
+	assert.Nil(t, err, "Ticket of another user should be accepted")
+	ticket3, err := useTicket("service2", epoch, pubKey, credential1)
+	assert.Nil(t, err, "Ticket for another service should be accepted")
+	assert.NotEqual(t, ticket1, ticket3, "Tickets of different services should differ")
+
+	prover, err := pseudonymsys.NewEpochTicketProver(group, pubKey, credential1)
+	assert.Nil(t, err)
+	assert.NotEqual(t, ticket1, prover.GetTicket("service1", epoch+1),
+		"Tickets of different epochs should differ")
+	_, err = useTicket("service3", epoch-1, pubKey, credential1)
+	assert.NotNil(t, err, "Ticket of an expired epoch should not be accepted")
+
+	// credential of another issuer
+	other, err := anoncreds.NewIssuer([]string{"person"}, getTestDFParams(t))
+	assert.Nil(t, err)
+	credential, err := anoncreds.IssueCredential(other, holder2,
+		map[string]*big.Int{"person": big.NewInt(1)})
+	assert.Nil(t, err)
+	_, err = useTicket("service3", epoch, other.GetPublicKey(), credential)
+	assert.NotNil(t, err, "Ticket with a credential of another issuer should not be accepted")
+
+	// the ticket which is not computed with the master secret of the credential
+	prover, err = pseudonymsys.NewEpochTicketProver(group, pubKey, credential2)
+	assert.Nil(t, err)
+	verifier := pseudonymsys.NewEpochTicketVerifier(limiter, pubKey, "service3")
+	prover.GetTicket("service3", epoch)
+	v, tt, x := prover.GetProofRandomData()
+	challenge, err := verifier.GetChallenge(epoch, ticket1, v, tt, x)
+	assert.Nil(t, err)
+	zE, zS, zM := prover.GetProofData(challenge)
+	assert.NotNil(t, verifier.Verify(zE, zS, zM),
+		"Ticket of another user should not be accepted")
+}
+
+func TestPseudonymsysEpochTicketConcurrent(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	issuer, err := anoncreds.NewIssuer([]string{"person"}, getTestDFParams(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey := issuer.GetPublicKey()
+	holder, err := anoncreds.NewHolder()
+	assert.Nil(t, err)
+	credential, err := anoncreds.IssueCredential(issuer, holder,
+		map[string]*big.Int{"person": big.NewInt(1)})
+	assert.Nil(t, err)
+	limiter, err := pseudonymsys.NewRateLimiter(group,
+		pseudonymsys.NewRateLimitParams(2, 24*time.Hour))
+	assert.Nil(t, err)
+	epoch := limiter.GetEpoch()
+
+	// all the challenges are obtained before any of the proofs is verified
+	n := 6
+	provers := make([]*pseudonymsys.EpochTicketProver, n)
+	verifiers := make([]*pseudonymsys.EpochTicketVerifier, n)
+	challenges := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		provers[i], err = pseudonymsys.NewEpochTicketProver(group, pubKey, credential)
+		assert.Nil(t, err)
+		verifiers[i] = pseudonymsys.NewEpochTicketVerifier(limiter, pubKey, "service")
+		ticket := provers[i].GetTicket("service", epoch)
+		v, tt, x := provers[i].GetProofRandomData()
+		challenges[i], err = verifiers[i].GetChallenge(epoch, ticket, v, tt, x)
+		assert.Nil(t, err)
+	}
+
+	accepted := make([]bool, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			zE, zS, zM := provers[i].GetProofData(challenges[i])
+			accepted[i] = verifiers[i].Verify(zE, zS, zM) == nil
+		}(i)
+	}
+	wg.Wait()
+
+	count := 0
+	for _, ok := range accepted {
+		if ok {
+			count++
+		}
+	}
+	assert.Equal(t, 2, count, "Concurrent uses of the ticket should not exceed the limit")
+}
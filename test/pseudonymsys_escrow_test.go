@@ -0,0 +1,115 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+	"math/big"
+	"testing"
+)
+
+func TestPseudonymsysNymEscrow(t *testing.T) {
+	cspaillier := getTestCSPaillier(t)
+	group := config.LoadGroup("pseudonymsys")
+	userSecret := randomInt(group.Q)
+	a := group.Exp(group.G, randomInt(group.Q))
+	nym := pseudonymsys.NewPseudonym(a, group.Exp(a, userSecret))
+
+	escrow := func(secret *big.Int) (*pseudonymsys.NymEscrow, error) {
+		prover, err := pseudonymsys.NewNymEscrowProver(group, cspaillier.PubKey, nym, secret)
+		if err != nil {
+			return nil, err
+		}
+		randomData, err := prover.GetProofRandomData()
+		if err != nil {
+			return nil, err
+		}
+		org := pseudonymsys.NewOrgNymEscrow(group, cspaillier.PubKey)
+		challenge, err := org.GetChallenge(&pseudonymsys.NymEscrow{
+			Nym:        nym,
+			Encryption: prover.GetEncryption(),
+		}, randomData)
+		if err != nil {
+			return nil, err
+		}
+		return org.Verify(prover.GetProofData(challenge))
+	}
+
+	verified, err := escrow(userSecret)
+	assert.Nil(t, err, "Escrow of the nym's master secret should be accepted")
+	registry := pseudonymsys.NewNymEscrowRegistry()
+	registry.Add(verified)
+
+	auditor := pseudonymsys.NewAuditor(group, cspaillier)
+	identity, err := auditor.RecoverIdentity(registry.Get(nym))
+	assert.Nil(t, err)
+	assert.Equal(t, group.Exp(group.G, userSecret), identity,
+		"Auditor should recover the user's master public key")
+
+	_, err = escrow(randomInt(group.Q))
+	assert.NotNil(t, err, "Escrow of another secret should not be accepted")
+}
+
+func TestGRPC_PseudonymsysNymEscrow(t *testing.T) {
+	cspaillier := getTestCSPaillier(t)
+
+	logger, _ := log.NewStdoutLogger("escrowServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer(logger)
+	assert.Nil(t, err)
+	srv.SetNymEscrowKey(cspaillier.PubKey)
+	address, stop := startTestServer(t, srv)
+	defer stop()
+
+	conn, err := client.GetConnection(address, "testdata/server.pem", false)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	params := config.LoadPseudonymsysParams()
+	group := params.Group
+	caClient, err := client.NewPseudonymsysCAClient(conn, params)
+	assert.Nil(t, err)
+	c, err := client.NewPseudonymsysClient(conn, params)
+	assert.Nil(t, err)
+	userSecret, err := c.GenerateMasterKey()
+	assert.Nil(t, err)
+	masterNym := pseudonymsys.NewPseudonym(group.G, group.Exp(group.G, userSecret))
+	caCertificate, err := caClient.ObtainCertificate(userSecret, masterNym)
+	assert.Nil(t, err)
+	nym, err := c.GenerateNym(userSecret, caCertificate)
+	assert.Nil(t, err)
+
+	assert.Nil(t, c.EscrowNym(nym, userSecret, cspaillier.PubKey), "Escrow should be accepted")
+	escrow := srv.GetNymEscrowRegistry().Get(nym)
+	if escrow == nil {
+		t.Fatal("Escrow should be stored in the registry")
+	}
+	identity, err := pseudonymsys.NewAuditor(group, cspaillier).RecoverIdentity(escrow)
+	assert.Nil(t, err)
+	assert.Equal(t, masterNym.B, identity, "Auditor should recover the user's master public key")
+
+	// the escrow with the default test server is refused as it has no escrow key
+	c, err = client.NewPseudonymsysClient(testGrpcClientConn, params)
+	assert.Nil(t, err)
+	assert.NotNil(t, c.EscrowNym(nym, userSecret, cspaillier.PubKey),
+		"Escrow should be refused by the server without escrow key")
+}
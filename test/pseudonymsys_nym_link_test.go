@@ -0,0 +1,66 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+	"testing"
+)
+
+func TestPseudonymsysNymLink(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	userSecret := randomInt(group.Q)
+
+	generateNym := func(secret *big.Int) *pseudonymsys.Pseudonym {
+		a := group.Exp(group.G, randomInt(group.Q))
+		return pseudonymsys.NewPseudonym(a, group.Exp(a, secret))
+	}
+	linkNyms := func(secret *big.Int, oldNym, newNym *pseudonymsys.Pseudonym) bool {
+		org := pseudonymsys.NewOrgNymLink(group)
+		prover := dlogproofs.NewDLogEqualityProver(group)
+		x1, x2, err := prover.GetProofRandomData(secret, oldNym.A, newNym.A)
+		if err != nil {
+			return false
+		}
+		challenge, err := org.GetChallenge(oldNym, newNym, x1, x2)
+		if err != nil {
+			return false
+		}
+		z, err := prover.GetProofData(challenge)
+		if err != nil {
+			return false
+		}
+		return org.Verify(z)
+	}
+
+	oldNym := generateNym(userSecret)
+	newNym := generateNym(userSecret)
+	assert.Equal(t, true, linkNyms(userSecret, oldNym, newNym),
+		"Nyms of the same master key should be linked")
+
+	otherSecret := randomInt(group.Q)
+	otherNym := generateNym(otherSecret)
+	assert.Equal(t, false, linkNyms(otherSecret, oldNym, otherNym),
+		"Nym of another master key should not be linked")
+	assert.Equal(t, false, linkNyms(userSecret, oldNym, oldNym),
+		"Nym should not be linked to itself")
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+	"testing"
+)
+
+func TestPseudonymsysNymRegistry(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	randomNym := func() *pseudonymsys.Pseudonym {
+		a := group.Exp(group.G, randomInt(group.Q))
+		return pseudonymsys.NewPseudonym(a, group.Exp(a, randomInt(group.Q)))
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("error when generating key: %v", err)
+	}
+	registry := pseudonymsys.NewNymRegistry(0, key.D, key.X, key.Y)
+
+	nym1, nym2, nym3 := randomNym(), randomNym(), randomNym()
+	assert.Nil(t, registry.Register(nym1))
+	assert.Nil(t, registry.Register(nym2))
+	assert.NotNil(t, registry.Register(nym1), "Nym should not be registered twice")
+	_, _, err = registry.Prove(nym1)
+	assert.NotNil(t, err, "Nym should not be included before the end of the epoch")
+
+	_, err = registry.Publish()
+	assert.Nil(t, err)
+	assert.Nil(t, registry.Register(nym3))
+	root, err := registry.Publish()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), root.Epoch)
+	assert.Equal(t, 3, root.Size)
+	assert.True(t, root.Verify(key.X, key.Y), "Root signature should be valid")
+
+	proof, proofRoot, err := registry.Prove(nym1)
+	assert.Nil(t, err)
+	assert.Equal(t, root, proofRoot, "Proof should be for the last root")
+	assert.True(t, proof.Verify(root, nym1), "Inclusion proof should be valid")
+	assert.False(t, proof.Verify(root, nym2), "Inclusion proof should not hold for other nym")
+	_, _, err = registry.Prove(randomNym())
+	assert.NotNil(t, err, "Unregistered nym should have no proof")
+
+	// auditor replays the epochs
+	auditor := pseudonymsys.NewNymRegistryAuditor(key.X, key.Y)
+	root1, nyms1, err := registry.GetEpochNyms(1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(nyms1))
+	root2, nyms2, err := registry.GetEpochNyms(2)
+	assert.Nil(t, err)
+	assert.NotNil(t, auditor.Audit(root2, nyms2), "Epochs should be audited in order")
+	assert.Nil(t, auditor.Audit(root1, nyms1), "First epoch should pass the audit")
+	assert.NotNil(t, auditor.Audit(root2, nil), "Epoch without its nyms should fail the audit")
+	assert.NotNil(t, auditor.Audit(root2, nyms2), "Auditor should fail after a failed audit")
+
+	auditor = pseudonymsys.NewNymRegistryAuditor(key.X, key.Y)
+	assert.Nil(t, auditor.Audit(root1, nyms1))
+	assert.Nil(t, auditor.Audit(root2, nyms2), "Second epoch should pass the audit")
+}
+
+func TestGRPC_PseudonymsysNymRegistry(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("error when generating key: %v", err)
+	}
+	registry := pseudonymsys.NewNymRegistry(0, key.D, key.X, key.Y)
+
+	logger, _ := log.NewStdoutLogger("nymRegistryServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer(logger)
+	assert.Nil(t, err)
+	srv.SetNymRegistry(registry)
+	address, stop := startTestServer(t, srv)
+	defer stop()
+
+	conn, err := client.GetConnection(address, "testdata/server.pem", false)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	params := config.LoadPseudonymsysParams()
+	group := params.Group
+	caClient, err := client.NewPseudonymsysCAClient(conn, params)
+	assert.Nil(t, err)
+	c, err := client.NewPseudonymsysClient(conn, params)
+	assert.Nil(t, err)
+	userSecret, err := c.GenerateMasterKey()
+	assert.Nil(t, err)
+	masterNym := pseudonymsys.NewPseudonym(group.G, group.Exp(group.G, userSecret))
+	caCertificate, err := caClient.ObtainCertificate(userSecret, masterNym)
+	assert.Nil(t, err)
+	nym, err := c.GenerateNym(userSecret, caCertificate)
+	assert.Nil(t, err)
+
+	_, _, err = c.GetNymInclusionProof(nym)
+	assert.NotNil(t, err, "Nym should not be included before the end of the epoch")
+
+	_, err = srv.GetNymRegistry().Publish()
+	assert.Nil(t, err)
+	_, root, err := c.GetNymInclusionProof(nym)
+	assert.Nil(t, err, "Inclusion proof should be obtained")
+	assert.True(t, root.Verify(key.X, key.Y), "Root should be signed by the registry")
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/compiler"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestPseudonymsysParams(t *testing.T) {
+	params := config.LoadPseudonymsysParams()
+	assert.Nil(t, params.Validate(), "params from config should be valid")
+
+	group := params.Group
+	invalid := groups.NewSchnorrGroupFromParams(group.P, big.NewInt(1), group.Q)
+	assert.NotNil(t, pseudonymsys.NewParams(invalid).Validate(), "G=1 should not be accepted")
+
+	invalid = groups.NewSchnorrGroupFromParams(group.P, group.G,
+		new(big.Int).Add(group.Q, big.NewInt(2)))
+	assert.NotNil(t, pseudonymsys.NewParams(invalid).Validate(), "wrong Q should not be accepted")
+
+	assert.NotNil(t, pseudonymsys.NewParams(nil).Validate(), "missing group should not be accepted")
+
+	small, err := groups.NewSchnorrSafePrimeGroup(256)
+	assert.Nil(t, err)
+	assert.Nil(t, small.Validate(), "generated group should be valid")
+	assert.NotNil(t, pseudonymsys.NewParams(small).Validate(), "short group should not be accepted")
+}
+
+func TestPseudonymsysRateLimitParams(t *testing.T) {
+	assert.Nil(t, config.LoadRateLimitParams().Validate(), "params from config should be valid")
+	assert.NotNil(t, pseudonymsys.NewRateLimitParams(0, time.Hour).Validate(),
+		"zero limit should not be accepted")
+	assert.NotNil(t, pseudonymsys.NewRateLimitParams(1, 0).Validate(),
+		"zero period should not be accepted")
+
+	_, err := pseudonymsys.NewRateLimiter(config.LoadGroup("pseudonymsys"),
+		pseudonymsys.NewRateLimitParams(1, 0))
+	assert.NotNil(t, err, "rate limiter with invalid params should not be created")
+}
+
+func TestCompilerParams(t *testing.T) {
+	params := config.LoadCompilerParams()
+	assert.Nil(t, params.Validate(), "params from config should be valid")
+
+	group := params.Group
+	invalid := groups.NewSchnorrGroupFromParams(group.P, big.NewInt(1), group.Q)
+	assert.NotNil(t, compiler.NewParams(invalid).Validate(), "G=1 should not be accepted")
+	assert.NotNil(t, compiler.NewParams(nil).Validate(), "missing group should not be accepted")
+}
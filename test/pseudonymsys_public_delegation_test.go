@@ -0,0 +1,150 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"math/big"
+	"testing"
+)
+
+// obtainCredential executes the credential issuance protocol without gRPC - the user side
+// is the same as in client.PseudonymsysClient.ObtainCredential.
+func obtainCredential(group *groups.SchnorrGroup, org *pseudonymsys.OrgCredentialIssuer,
+	orgPubKeys *pseudonymsys.OrgPubKeys, userSecret *big.Int,
+	nym *pseudonymsys.Pseudonym) *pseudonymsys.Credential {
+	gamma := randomInt(group.Q)
+	equalityVerifier1, err := dlogproofs.NewDLogEqualityBTranscriptVerifier(group, gamma)
+	if err != nil {
+		return nil
+	}
+	equalityVerifier2, err := dlogproofs.NewDLogEqualityBTranscriptVerifier(group, gamma)
+	if err != nil {
+		return nil
+	}
+
+	schnorrProver, err := dlogproofs.NewSchnorrProver(group)
+	if err != nil {
+		return nil
+	}
+	x, err := schnorrProver.GetProofRandomData(userSecret, nym.A)
+	if err != nil {
+		return nil
+	}
+	challenge, err := org.GetAuthenticationChallenge(nym.A, nym.B, x)
+	if err != nil {
+		return nil
+	}
+	z, _, err := schnorrProver.GetProofData(challenge)
+	if err != nil {
+		return nil
+	}
+
+	x11, x12, x21, x22, A, B, err := org.VerifyAuthentication(z)
+	if err != nil {
+		return nil
+	}
+
+	challenge1, err := equalityVerifier1.GetChallenge(group.G, nym.B, orgPubKeys.H2, A, x11, x12)
+	if err != nil {
+		return nil
+	}
+	aA := group.Mul(nym.A, A)
+	challenge2, err := equalityVerifier2.GetChallenge(group.G, aA, orgPubKeys.H1, B, x21, x22)
+	if err != nil {
+		return nil
+	}
+	z1, z2, err := org.GetEqualityProofData(challenge1, challenge2)
+	if err != nil {
+		return nil
+	}
+
+	verified1, transcript1, bToGamma, AToGamma := equalityVerifier1.Verify(z1)
+	verified2, transcript2, _, BToGamma := equalityVerifier2.Verify(z2)
+	if !verified1 || !verified2 {
+		return nil
+	}
+
+	aToGamma := group.Exp(nym.A, gamma)
+	return pseudonymsys.NewCredential(aToGamma, bToGamma, AToGamma, BToGamma,
+		transcript1, transcript2)
+}
+
+// transferCredential executes the credential transfer protocol without gRPC and returns
+// whether the organization accepted the credential.
+func transferCredential(group *groups.SchnorrGroup, orgPubKeys *pseudonymsys.OrgPubKeys,
+	userSecret *big.Int, nym *pseudonymsys.Pseudonym, credential *pseudonymsys.Credential) bool {
+	org := pseudonymsys.NewOrgCredentialVerifier(group, nil, nil)
+	prover := dlogproofs.NewDLogEqualityProver(group)
+	x1, x2, err := prover.GetProofRandomData(userSecret, nym.A, credential.SmallAToGamma)
+	if err != nil {
+		return false
+	}
+	challenge, err := org.GetAuthenticationChallenge(nym.A, nym.B,
+		credential.SmallAToGamma, credential.SmallBToGamma, x1, x2)
+	if err != nil {
+		return false
+	}
+	z, err := prover.GetProofData(challenge)
+	if err != nil {
+		return false
+	}
+	return org.VerifyAuthentication(z, credential, orgPubKeys)
+}
+
+func TestPseudonymsysPublicDelegation(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+
+	root, _ := pseudonymsys.NewPublicDelegationKeys(group)
+	delegator1, _ := pseudonymsys.NewPublicDelegationKeys(group)
+	delegator2, _ := pseudonymsys.NewPublicDelegationKeys(group)
+
+	cert1, err := root.Delegate(delegator1)
+	assert.Nil(t, err, "Delegation should not produce an error")
+	cert2, err := delegator1.Delegate(delegator2)
+	assert.Nil(t, err, "Delegation should not produce an error")
+	chain := []*pseudonymsys.PublicDelegationCertificate{cert1, cert2}
+
+	// a credential issued by the last delegator in the chain
+	userSecret := randomInt(group.Q)
+	gamma := randomInt(group.Q)
+	nymA := group.Exp(group.G, gamma)
+	nym := pseudonymsys.NewPseudonym(nymA, group.Exp(nymA, userSecret))
+	issuer, err := delegator2.GetCredentialIssuer()
+	assert.Nil(t, err)
+	credential := obtainCredential(group, issuer, delegator2.PubKeys, userSecret, nym)
+	assert.NotNil(t, credential, "Credential should be issued")
+
+	orgPubKeys, err := pseudonymsys.VerifyPublicDelegationChain(root.GetSigningPubKey(), chain)
+	assert.Nil(t, err, "Delegation chain should be valid")
+	assert.Equal(t, true, transferCredential(group, orgPubKeys, userSecret, nym, credential),
+		"Credential issued at the end of a valid delegation chain should be accepted")
+
+	// a chain with a missing link is not valid
+	_, err = pseudonymsys.VerifyPublicDelegationChain(root.GetSigningPubKey(), chain[1:])
+	assert.NotNil(t, err, "Delegation chain with a missing link should not be valid")
+
+	// a chain issued by some other root is not valid
+	otherRoot, _ := pseudonymsys.NewPublicDelegationKeys(group)
+	_, err = pseudonymsys.VerifyPublicDelegationChain(otherRoot.GetSigningPubKey(), chain)
+	assert.NotNil(t, err, "Delegation chain from a wrong root should not be valid")
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/anoncreds"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestPseudonymsysRateLimiter(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	limiter, err := pseudonymsys.NewRateLimiter(group,
+		pseudonymsys.NewRateLimitParams(1, 24*time.Hour))
+	assert.Nil(t, err)
+	epoch := limiter.GetEpoch()
+	ticket := group.Exp(pseudonymsys.GetEpochBase(group, "poll1", epoch), randomInt(group.Q))
+
+	assert.Nil(t, limiter.Reserve("poll1", epoch, ticket), "Action should be authorized")
+	assert.NotNil(t, limiter.Reserve("poll1", epoch, ticket),
+		"Action over the limit should not be authorized")
+	assert.Nil(t, limiter.Reserve("poll2", epoch, ticket),
+		"Action in another scope should be authorized")
+	assert.Nil(t, limiter.Reserve("poll1", epoch+1, ticket),
+		"Action in the next epoch should be authorized")
+	assert.NotNil(t, limiter.Reserve("poll3", epoch, ticket),
+		"Action in an expired epoch should not be authorized")
+}
+
+func TestGRPC_PseudonymsysAuthorizeAction(t *testing.T) {
+	issuer, err := anoncreds.NewIssuer([]string{"person"}, getTestDFParams(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey := issuer.GetPublicKey()
+	srv, err := server.NewServer(log.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, stop := startTestServer(t, srv)
+	defer stop()
+	conn, err := client.GetConnection(address, "testdata/server.pem", false)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	holder, err := anoncreds.NewHolder()
+	assert.Nil(t, err)
+	credential, err := anoncreds.IssueCredential(issuer, holder,
+		map[string]*big.Int{"person": big.NewInt(1)})
+	assert.Nil(t, err)
+	c, err := client.NewPseudonymsysClient(conn, config.LoadPseudonymsysParams())
+	assert.Nil(t, err)
+	params := config.LoadRateLimitParams()
+
+	assert.NotNil(t, c.AuthorizeAction("poll1", params, pubKey, credential),
+		"Action should not be authorized when the server has no rate limit issuer")
+	srv.SetRateLimitIssuer(pubKey)
+
+	// Only one action per credential per scope per period should be authorized
+	err = c.AuthorizeAction("poll1", params, pubKey, credential)
+	assert.Nil(t, err, "Action should be authorized")
+	err = c.AuthorizeAction("poll1", params, pubKey, credential)
+	assert.NotNil(t, err, "Action over the limit should not be authorized")
+	err = c.AuthorizeAction("poll2", params, pubKey, credential)
+	assert.Nil(t, err, "Action in another scope should be authorized")
+
+	// the same human with another credential is still limited
+	other, err := anoncreds.IssueCredential(issuer, holder,
+		map[string]*big.Int{"person": big.NewInt(1)})
+	assert.Nil(t, err)
+	err = c.AuthorizeAction("poll2", params, pubKey, other)
+	assert.NotNil(t, err,
+		"Action with another credential of the same human should not be authorized")
+
+	// credential of another issuer
+	otherIssuer, err := anoncreds.NewIssuer([]string{"person"}, getTestDFParams(t))
+	assert.Nil(t, err)
+	other, err = anoncreds.IssueCredential(otherIssuer, holder,
+		map[string]*big.Int{"person": big.NewInt(1)})
+	assert.Nil(t, err)
+	err = c.AuthorizeAction("poll3", params, otherIssuer.GetPublicKey(), other)
+	assert.NotNil(t, err, "Action with a credential of another issuer should not be authorized")
+}
@@ -28,15 +28,17 @@ import (
 
 // TestPseudonymsys requires a running server (it is started in communication_test.go).
 func TestPseudonymsys(t *testing.T) {
-	group := config.LoadGroup("pseudonymsys")
-	caClient, err := client.NewPseudonymsysCAClient(testGrpcClientConn)
+	params := config.LoadPseudonymsysParams()
+	group := params.Group
+	caClient, err := client.NewPseudonymsysCAClient(testGrpcClientConn, params)
 	if err != nil {
 		t.Errorf("Error when initializing NewPseudonymsysCAClient")
 	}
 
 	// usually the endpoint is different from the one used for CA:
-	c1, err := client.NewPseudonymsysClient(testGrpcClientConn)
-	userSecret := c1.GenerateMasterKey()
+	c1, err := client.NewPseudonymsysClient(testGrpcClientConn, params)
+	userSecret, err := c1.GenerateMasterKey()
+	assert.Nil(t, err)
 
 	p := group.Exp(group.G, userSecret) // this is user's public key
 	masterNym := pseudonymsys.NewPseudonym(group.G, p)
@@ -60,13 +62,13 @@ func TestPseudonymsys(t *testing.T) {
 
 	// register with org2
 	// create a client to communicate with org2
-	caClient1, err := client.NewPseudonymsysCAClient(testGrpcClientConn)
+	caClient1, err := client.NewPseudonymsysCAClient(testGrpcClientConn, params)
 	caCertificate1, err := caClient1.ObtainCertificate(userSecret, masterNym)
 	if err != nil {
 		t.Errorf("Error when registering with CA")
 	}
 
-	c2, err := client.NewPseudonymsysClient(testGrpcClientConn)
+	c2, err := client.NewPseudonymsysClient(testGrpcClientConn, params)
 	nym2, err := c2.GenerateNym(userSecret, caCertificate1)
 	if err != nil {
 		t.Errorf(err.Error())
@@ -83,3 +85,18 @@ func TestPseudonymsys(t *testing.T) {
 	assert.Nil(t, sessionKey2, "Authentication should fail, and session key should be nil")
 	assert.NotNil(t, err, "Should produce an error")
 }
+
+// obtainPseudonymsysCertificate generates a master key and registers it with the CA
+// through the given client. It requires a running server.
+func obtainPseudonymsysCertificate(t *testing.T, caClient *client.PseudonymsysCAClient,
+	params *pseudonymsys.Params) (*big.Int, *pseudonymsys.CACertificate) {
+	c, err := client.NewPseudonymsysClient(testGrpcClientConn, params)
+	assert.Nil(t, err)
+	userSecret, err := c.GenerateMasterKey()
+	assert.Nil(t, err)
+	masterNym := pseudonymsys.NewPseudonym(params.Group.G,
+		params.Group.Exp(params.Group.G, userSecret))
+	caCertificate, err := caClient.ObtainCertificate(userSecret, masterNym)
+	assert.Nil(t, err, "Error when registering with CA")
+	return userSecret, caCertificate
+}
@@ -21,7 +21,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/xlab-si/emmy/client"
 	"github.com/xlab-si/emmy/config"
-	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/qrproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/repetition"
 	"github.com/xlab-si/emmy/log"
 	"math/big"
 	"testing"
@@ -32,7 +33,7 @@ func TestQRProof(t *testing.T) {
 	client.SetLogger(log.NewNullLogger())
 
 	group := config.LoadGroup("pseudonymsys")
-	y1 := common.GetRandomInt(group.P)
+	y1 := randomInt(group.P)
 
 	qrClient, err := client.NewQRClient(testGrpcClientConn, group, y1)
 	if err != nil {
@@ -48,6 +49,51 @@ func TestQRProof(t *testing.T) {
 	assert.Equal(t, proved, true, "QR proof does not work correctly")
 }
 
+func TestQRParallelProof(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	y1 := randomInt(group.P)
+
+	proved, err := qrproofs.ProveQRParallel(y1, group, 128)
+	assert.Nil(t, err)
+	assert.True(t, proved, "parallel QR proof does not work correctly")
+
+	// prover which does not know the square root of y
+	y := group.Mul(y1, y1)
+	prover, _ := qrproofs.NewQRParallelProver(group, randomInt(group.P), 80)
+	verifier, _ := qrproofs.NewQRParallelVerifier(y, group, 80)
+	assert.Equal(t, 80, prover.Repetitions())
+	xs, _ := prover.GetProofRandomData()
+	challenge, err := verifier.GetChallenge(xs)
+	assert.Nil(t, err)
+	zs, _ := prover.GetProofData(challenge)
+	assert.False(t, verifier.Verify(zs), "parallel QR proof with wrong secret should fail")
+
+	// verifier requires the number of runs for its soundness
+	verifier, _ = qrproofs.NewQRParallelVerifier(y, group, 128)
+	_, err = verifier.GetChallenge(xs)
+	assert.NotNil(t, err, "verifier should require more runs")
+
+	_, err = qrproofs.NewQRParallelProver(group, y1, 40)
+	assert.NotNil(t, err, "soundness below 80 bits should not be accepted")
+}
+
+func TestRepetition(t *testing.T) {
+	n, err := repetition.Repetitions(128, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 128, n)
+	n, err = repetition.Repetitions(80, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, 51, n)
+	_, err = repetition.Repetitions(80, 1)
+	assert.NotNil(t, err, "challenge space of one challenge gives no soundness")
+
+	// 2 + 1*3 + 2*9 = 23
+	challenges := repetition.Challenges(big.NewInt(23), 3, 4)
+	for i, c := range []int64{2, 1, 2, 0} {
+		assert.Equal(t, c, challenges[i].Int64())
+	}
+}
+
 func TestQNRProof(t *testing.T) {
 	prevLogger := client.GetLogger()
 	client.SetLogger(log.NewNullLogger())
@@ -0,0 +1,221 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/rangeproofs"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+var testDFParams struct {
+	once   sync.Once
+	params *commitments.DamgardFujisakiParams
+	err    error
+}
+
+// getTestDFParams returns Damgard-Fujisaki parameters which are generated only once, as
+// the generation of safe primes is slow.
+func getTestDFParams(t *testing.T) *commitments.DamgardFujisakiParams {
+	testDFParams.once.Do(func() {
+		testDFParams.params, testDFParams.err = commitments.NewDamgardFujisakiParams(256)
+	})
+	if testDFParams.err != nil {
+		t.Fatalf("Error when generating Damgard-Fujisaki parameters: %v", testDFParams.err)
+	}
+	return testDFParams.params
+}
+
+func TestRangeProof(t *testing.T) {
+	group := config.LoadGroup("pedersen")
+	h, err := group.GetRandomElement()
+	assert.Nil(t, err)
+	r := randomInt(group.Q)
+	a, b := big.NewInt(18), big.NewInt(150)
+
+	for _, x := range []int64{18, 42, 128, 150} {
+		proved, err := rangeproofs.ProveRange(group, h, big.NewInt(x), r, a, b)
+		assert.Nil(t, err)
+		assert.True(t, proved, "Range proof does not work correctly")
+	}
+
+	_, err = rangeproofs.ProveRange(group, h, big.NewInt(17), r, a, b)
+	assert.NotNil(t, err, "Prover should not accept the value out of range")
+
+	// prover commits to 17 but proves the range for the commitment to 18
+	x := big.NewInt(17)
+	c := group.Mul(group.Exp(group.G, x), group.Exp(h, r))
+	prover, _ := rangeproofs.NewRangeProver(group, h, big.NewInt(18), r, a, b)
+	verifier := rangeproofs.NewRangeVerifier(group, h, c, a, b)
+	proofRandomData, err := prover.GetProofRandomData()
+	assert.Nil(t, err)
+	err = verifier.SetProofRandomData(proofRandomData)
+	assert.NotNil(t, err, "Bit commitments should not match the commitment")
+}
+
+func TestBitDecomposition(t *testing.T) {
+	group := config.LoadGroup("pedersen")
+	h, err := group.GetRandomElement()
+	assert.Nil(t, err)
+	r := randomInt(group.Q)
+
+	for _, x := range []int64{0, 1, 6, 255} {
+		proved, err := rangeproofs.ProveBitDecomposition(group, h, big.NewInt(x), r, 8)
+		assert.Nil(t, err)
+		assert.True(t, proved, "Bit decomposition proof does not work correctly")
+	}
+	_, err = rangeproofs.ProveBitDecomposition(group, h, big.NewInt(256), r, 8)
+	assert.NotNil(t, err, "Prover should not accept the value with too many bits")
+
+	// the bit commitments open to the bits of x
+	x := big.NewInt(6)
+	c := group.Mul(group.Exp(group.G, x), group.Exp(h, r))
+	prover, err := rangeproofs.NewBitDecompositionProver(group, h, x, r, 3)
+	assert.Nil(t, err)
+	verifier := rangeproofs.NewBitDecompositionVerifier(group, h, c, 3)
+	proofRandomData, err := prover.GetProofRandomData()
+	assert.Nil(t, err)
+	assert.Nil(t, verifier.SetProofRandomData(proofRandomData))
+	challenge, err := verifier.GetChallenge()
+	assert.Nil(t, err)
+	assert.True(t, verifier.Verify(prover.GetProofData(challenge)))
+	bits, rs := prover.GetBits()
+	for i, com := range verifier.GetBitCommitments() {
+		opened := group.Mul(group.Exp(group.G, bits[i]), group.Exp(h, rs[i]))
+		assert.Equal(t, com, opened, "Bit commitment should open to the bit")
+	}
+	assert.Equal(t, []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(1)}, bits)
+
+	// the decomposition of another value is not accepted
+	verifier = rangeproofs.NewBitDecompositionVerifier(group, h, group.Mul(c, group.G), 3)
+	proofRandomData, err = prover.GetProofRandomData()
+	assert.Nil(t, err)
+	assert.NotNil(t, verifier.SetProofRandomData(proofRandomData),
+		"Bit commitments should not match the commitment")
+}
+
+// TestGRPC_RangeProof requires a running server (it is started in communication_test.go).
+func TestGRPC_RangeProof(t *testing.T) {
+	group := config.LoadGroup("pedersen")
+	a, b := big.NewInt(18), big.NewInt(150)
+
+	c, err := client.NewRangeProofClient(testGrpcClientConn, group, big.NewInt(35), a, b)
+	assert.Nil(t, err)
+	proved, err := c.Run()
+	assert.Nil(t, err)
+	assert.True(t, proved, "Range proof should be accepted")
+
+	c, err = client.NewRangeProofClient(testGrpcClientConn, group, big.NewInt(16), a, b)
+	assert.Nil(t, err)
+	_, err = c.Run()
+	assert.NotNil(t, err, "Value out of range should not be proved")
+}
+
+func TestDamgardFujisakiCommitment(t *testing.T) {
+	params := getTestDFParams(t)
+	committer := commitments.NewDamgardFujisakiCommitter(params)
+	receiver := &commitments.DamgardFujisakiReceiver{Params: params}
+
+	for _, x := range []int64{0, 42, -42} {
+		c, err := committer.GetCommitMsg(big.NewInt(x))
+		assert.Nil(t, err)
+		receiver.SetCommitment(c)
+		val, r := committer.GetDecommitMsg()
+		assert.True(t, receiver.CheckDecommitment(r, val), "decommitment failed")
+		assert.False(t, receiver.CheckDecommitment(r, new(big.Int).Add(val, params.N)),
+			"integer commitment should bind the value, not only modulo N")
+	}
+}
+
+func TestSquareProof(t *testing.T) {
+	params := getTestDFParams(t)
+	for _, x := range []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(-5),
+		randomInt(params.N)} {
+		r := randomInt(params.RandomnessBound())
+		proved, err := rangeproofs.ProveSquare(params, x, r)
+		assert.Nil(t, err)
+		assert.True(t, proved, "Square proof does not work correctly")
+	}
+
+	// 7 is not a square, prover which claims that 7 = 3^2 fails
+	r := randomInt(params.RandomnessBound())
+	prover := rangeproofs.NewDamgardFujisakiSquareProver(params, big.NewInt(3), r)
+	verifier := rangeproofs.NewDamgardFujisakiSquareVerifier(params,
+		params.Commit(big.NewInt(7), r))
+	proofRandomData, err := prover.GetProofRandomData()
+	assert.Nil(t, err)
+	assert.Nil(t, verifier.SetProofRandomData(proofRandomData))
+	challenge, err := verifier.GetChallenge()
+	assert.Nil(t, err)
+	assert.False(t, verifier.Verify(prover.GetProofData(challenge)),
+		"proof for a value which is not a square should fail")
+
+	group := config.LoadGroup("pedersen")
+	h, err := group.GetRandomElement()
+	assert.Nil(t, err)
+	x := randomInt(group.Q)
+	r = randomInt(group.Q)
+	c := group.Mul(group.Exp(group.G, new(big.Int).Mul(x, x)), group.Exp(h, r))
+	pProver := rangeproofs.NewPedersenSquareProver(group, h, x, r)
+	pVerifier := rangeproofs.NewPedersenSquareVerifier(group, h, c)
+	proofRandomData, err = pProver.GetProofRandomData()
+	assert.Nil(t, err)
+	assert.Nil(t, pVerifier.SetProofRandomData(proofRandomData))
+	challenge, err = pVerifier.GetChallenge()
+	assert.Nil(t, err)
+	assert.True(t, pVerifier.Verify(pProver.GetProofData(challenge)),
+		"Pedersen square proof does not work correctly")
+}
+
+func TestNonNegativeProof(t *testing.T) {
+	params := getTestDFParams(t)
+	large1, err := common.GetRandomIntOfLength(200)
+	assert.Nil(t, err)
+	large2, err := common.GetRandomIntOfLength(200)
+	assert.Nil(t, err)
+	values := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), big.NewInt(3),
+		big.NewInt(7), big.NewInt(1023), big.NewInt(1024), large1, new(big.Int).Lsh(large2, 6)}
+	for _, x := range values {
+		r := randomInt(params.RandomnessBound())
+		proved, err := rangeproofs.ProveNonNegative(params, x, r)
+		assert.Nil(t, err)
+		assert.True(t, proved, "Non-negativity proof does not work correctly for %v", x)
+	}
+
+	_, err = rangeproofs.NewNonNegativeProver(params, big.NewInt(-1), big.NewInt(1))
+	assert.NotNil(t, err, "prover should refuse a negative value")
+
+	// prover knows the decomposition of 5, but c commits to -5
+	r := randomInt(params.RandomnessBound())
+	prover, err := rangeproofs.NewNonNegativeProver(params, big.NewInt(5), r)
+	assert.Nil(t, err)
+	verifier := rangeproofs.NewNonNegativeVerifier(params, params.Commit(big.NewInt(-5), r))
+	proofRandomData, err := prover.GetProofRandomData()
+	assert.Nil(t, err)
+	assert.Nil(t, verifier.SetProofRandomData(proofRandomData))
+	challenge, err := verifier.GetChallenge()
+	assert.Nil(t, err)
+	assert.False(t, verifier.Verify(prover.GetProofData(challenge)),
+		"proof for a negative value should fail")
+}
@@ -19,11 +19,74 @@ package test
 
 import (
 	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
 	"github.com/xlab-si/emmy/crypto/zkp/primitives/representationproofs"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
 	"testing"
 )
 
 func TestRepresentationProof(t *testing.T) {
-	proved := representationproofs.ProveKnowledgeOfRepresentation()
+	proved, err := representationproofs.ProveKnowledgeOfRepresentation()
+	assert.Nil(t, err)
 	assert.Equal(t, true, proved, "Proof of knowledge of representation does not work correctly")
 }
+
+func TestDLogRepresentation(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+
+	for _, n := range []int{1, 3, 10} {
+		bases := make([]*big.Int, n)
+		secrets := make([]*big.Int, n)
+		y := big.NewInt(1)
+		for i := 0; i < n; i++ {
+			bases[i] = group.Exp(group.G, randomInt(group.Q))
+			secrets[i] = randomInt(group.Q)
+			y = group.Mul(y, group.Exp(bases[i], secrets[i]))
+		}
+
+		proved, err := dlogproofs.ProveRepresentation(group, secrets, bases, y)
+		assert.Nil(t, err)
+		assert.True(t, proved, "representation with %d bases should be proved", n)
+
+		secrets[0] = new(big.Int).Add(secrets[0], big.NewInt(1))
+		proved, err = dlogproofs.ProveRepresentation(group, secrets, bases, y)
+		assert.Nil(t, err)
+		assert.False(t, proved, "wrong representation should not be proved")
+	}
+
+	_, err := dlogproofs.NewRepresentationProver(group, []*big.Int{big.NewInt(1)}, nil, nil)
+	assert.NotNil(t, err, "number of secrets and bases should match")
+}
+
+func TestECRepresentation(t *testing.T) {
+	curve := dlog.P256
+	ecdlog := dlog.NewECDLog(curve)
+	order := ecdlog.OrderOfSubgroup
+
+	for _, n := range []int{1, 3, 10} {
+		bases := make([]*types.ECGroupElement, n)
+		secrets := make([]*big.Int, n)
+		for i := 0; i < n; i++ {
+			bases[i] = types.NewECGroupElement(ecdlog.ExponentiateBaseG(randomInt(order)))
+			secrets[i] = randomInt(order)
+		}
+		yX, yY := ecdlog.Exponentiate(bases[0].X, bases[0].Y, secrets[0])
+		for i := 1; i < n; i++ {
+			tX, tY := ecdlog.Exponentiate(bases[i].X, bases[i].Y, secrets[i])
+			yX, yY = ecdlog.Multiply(yX, yY, tX, tY)
+		}
+		y := types.NewECGroupElement(yX, yY)
+
+		proved, err := dlogproofs.ProveECRepresentation(curve, secrets, bases, y)
+		assert.Nil(t, err)
+		assert.True(t, proved, "EC representation with %d bases should be proved", n)
+
+		secrets[n-1] = new(big.Int).Add(secrets[n-1], big.NewInt(1))
+		proved, err = dlogproofs.ProveECRepresentation(curve, secrets, bases, y)
+		assert.Nil(t, err)
+		assert.False(t, proved, "wrong EC representation should not be proved")
+	}
+}
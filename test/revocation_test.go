@@ -0,0 +1,246 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/revocation"
+	"github.com/xlab-si/emmy/server"
+	"golang.org/x/net/context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRevocationRegistry(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	registry := revocation.NewMemoryRegistry()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := revocation.NewSnapshotSigner(registry, key.D, key.X, key.Y)
+
+	newTicket := func(secret *big.Int) *pseudonymsys.BlacklistTicket {
+		h, err := group.GetRandomElement()
+		assert.Nil(t, err)
+		return pseudonymsys.NewBlacklistTicket(h, group.Exp(h, secret))
+	}
+	secret1 := randomInt(group.Q)
+	secret2 := randomInt(group.Q)
+	ticket1 := newTicket(secret1)
+
+	v, _ := registry.Revoke(ticket1)
+	assert.Equal(t, uint64(1), v, "First revocation should have version 1")
+	v, _ = registry.Revoke(ticket1)
+	assert.Equal(t, uint64(1), v, "Repeated revocation should keep the version")
+	v, _ = registry.Revoke(newTicket(randomInt(group.Q)))
+	assert.Equal(t, uint64(2), v, "Second revocation should have version 2")
+	_, err = signer.SnapshotAt(3)
+	assert.NotNil(t, err, "Snapshot of a future version should fail")
+
+	snapshot, err := signer.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(2), snapshot.Version, "Version of the snapshot is wrong")
+	assert.Equal(t, 2, len(snapshot.Tickets), "Snapshot should contain both tickets")
+	assert.Equal(t, true, snapshot.Verify(key.X, key.Y), "Snapshot signature should verify")
+	assert.Equal(t, true, snapshot.IsFresh(time.Minute), "Snapshot should be fresh")
+	old, _ := signer.SnapshotAt(1)
+	old.Tickets = snapshot.Tickets
+	assert.Equal(t, false, old.Verify(key.X, key.Y), "Modified snapshot should be rejected")
+
+	// the verifier checks non-revocation proofs against the snapshot
+	authenticate := func(secret *big.Int) bool {
+		nymRegistry := newBlacklistNymRegistry(t)
+		verifier := pseudonymsys.NewBlacklistVerifier(group, nymRegistry)
+		for _, ticket := range snapshot.Tickets {
+			verifier.AddToBlacklist(ticket)
+		}
+		a := group.Exp(group.G, randomInt(group.Q))
+		nym := pseudonymsys.NewPseudonym(a, group.Exp(a, secret))
+		if err := nymRegistry.Register(nym); err != nil {
+			return false
+		}
+		prover := pseudonymsys.NewBlacklistProver(group, secret, nym)
+		ticket, err := prover.GetTicket()
+		if err != nil {
+			return false
+		}
+		x1, x2, cs, ys, ws, err := prover.GetProofRandomData(verifier.GetBlacklist())
+		if err != nil {
+			return false
+		}
+		challenge, err := verifier.GetChallenge(nym, ticket, x1, x2, cs, ys, ws)
+		if err != nil {
+			return false
+		}
+		z, zAlphas, zBetas := prover.GetProofData(challenge)
+		return verifier.Verify(z, zAlphas, zBetas)
+	}
+	assert.Equal(t, true, authenticate(secret2), "User who is not revoked should authenticate")
+	assert.Equal(t, false, authenticate(secret1), "Revoked user should not authenticate")
+}
+
+func TestRevocationSnapshot(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	registry := revocation.NewMemoryRegistry()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := revocation.NewSnapshotSigner(registry, key.D, key.X, key.Y)
+	h, err := group.GetRandomElement()
+	assert.Nil(t, err)
+	registry.Revoke(pseudonymsys.NewBlacklistTicket(h, group.Exp(h, big.NewInt(7))))
+
+	dir, err := ioutil.TempDir("", "emmy-revocation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "snapshot")
+
+	snapshot, err := signer.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, snapshot.Store(path), "Storing the snapshot failed")
+
+	loaded, err := revocation.LoadSnapshot(path, key.X, key.Y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, snapshot.Version, loaded.Version, "Version of the loaded snapshot is wrong")
+	assert.Equal(t, snapshot.Tickets, loaded.Tickets, "Tickets of the loaded snapshot are wrong")
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, err = revocation.LoadSnapshot(path, otherKey.X, otherKey.Y)
+	assert.NotNil(t, err, "Snapshot signed by another key should be rejected")
+}
+
+func TestRevocationReplica(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	registry := revocation.NewNotifyingRegistry(revocation.NewMemoryRegistry())
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := revocation.NewSnapshotSigner(registry, key.D, key.X, key.Y)
+	replica := revocation.NewReplica(key.X, key.Y)
+	revoke := func() {
+		h, err := group.GetRandomElement()
+		assert.Nil(t, err)
+		registry.Revoke(pseudonymsys.NewBlacklistTicket(h,
+			group.Exp(h, randomInt(group.Q))))
+	}
+
+	changed := registry.Changed()
+	revoke()
+	select {
+	case <-changed:
+	default:
+		t.Error("Revocation should be notified")
+	}
+	revoke()
+
+	update, err := signer.Update(replica.Version())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(update.Tickets), "Update should contain all tickets")
+	assert.Nil(t, replica.Apply(update), "Update should be applied")
+	assert.Equal(t, uint64(2), replica.Version(), "Replica should be at version 2")
+
+	revoke()
+	update, err = signer.Update(replica.Version())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(update.Tickets), "Update should contain only the new ticket")
+	tampered := *update
+	tampered.Tickets = []*pseudonymsys.BlacklistTicket{pseudonymsys.NewBlacklistTicket(
+		big.NewInt(2), big.NewInt(4))}
+	assert.NotNil(t, replica.Apply(&tampered), "Tampered update should be rejected")
+	assert.Nil(t, replica.Apply(update), "Update should be applied")
+	assert.NotNil(t, replica.Apply(update), "Update should not be applied twice")
+
+	snapshot, _ := signer.Snapshot()
+	assert.Equal(t, snapshot.Tickets, replica.Tickets(), "Replica should match the registry")
+	assert.True(t, replica.Snapshot().Verify(key.X, key.Y), "Replica should be signed")
+}
+
+func TestGRPC_RevocationUpdates(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	registry := revocation.NewNotifyingRegistry(revocation.NewMemoryRegistry())
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoke := func() {
+		h, err := group.GetRandomElement()
+		assert.Nil(t, err)
+		registry.Revoke(pseudonymsys.NewBlacklistTicket(h,
+			group.Exp(h, randomInt(group.Q))))
+	}
+
+	logger, _ := log.NewStdoutLogger("revocationServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer(logger)
+	assert.Nil(t, err)
+	srv.SetRevocationSigner(revocation.NewSnapshotSigner(registry, key.D, key.X, key.Y))
+	address, stop := startTestServer(t, srv)
+	defer stop()
+
+	conn, err := client.GetConnection(address, "testdata/server.pem", false)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	revoke()
+	replica := revocation.NewReplica(key.X, key.Y)
+	c, err := client.NewRevocationClient(conn, replica)
+	assert.Nil(t, err)
+	assert.Nil(t, c.Sync(), "Sync should succeed")
+	assert.Equal(t, uint64(1), replica.Version(), "Replica should be at version 1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	versions := make(chan uint64, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Subscribe(ctx, func(snapshot *revocation.Snapshot) {
+			versions <- snapshot.Version
+		})
+	}()
+	assert.Equal(t, uint64(1), <-versions, "First update should refresh the replica")
+	revoke()
+	revoke()
+	for v := range versions {
+		if v == 3 {
+			break
+		}
+	}
+	cancel()
+	assert.Nil(t, <-done, "Subscription should end when canceled")
+	assert.Equal(t, 3, len(replica.Tickets()), "Replica should contain all tickets")
+}
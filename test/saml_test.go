@@ -0,0 +1,131 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/signatures"
+	"github.com/xlab-si/emmy/saml"
+	"math/big"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type samlAssertion struct {
+	ID         string `xml:"ID,attr"`
+	Issuer     string `xml:"Issuer"`
+	NameID     string `xml:"Subject>NameID"`
+	Audience   string `xml:"Conditions>AudienceRestriction>Audience"`
+	Attributes []struct {
+		Name   string   `xml:"Name,attr"`
+		Values []string `xml:"AttributeValue"`
+	} `xml:"AttributeStatement>Attribute"`
+	DigestValue    string `xml:"Signature>SignedInfo>Reference>DigestValue"`
+	SignatureValue string `xml:"Signature>SignatureValue"`
+}
+
+// verifySAMLSignature checks the enveloped signature of the assertion which is already in
+// the canonical form.
+func verifySAMLSignature(raw []byte, parsed *samlAssertion,
+	key *rsa.PublicKey) bool {
+	withoutSignature := regexp.MustCompile(`<ds:Signature .*</ds:Signature>`).
+		ReplaceAll(raw, nil)
+	digest := sha256.Sum256(withoutSignature)
+	if base64.StdEncoding.EncodeToString(digest[:]) != parsed.DigestValue {
+		return false
+	}
+	signedInfo := regexp.MustCompile(`<ds:SignedInfo>.*</ds:SignedInfo>`).Find(raw)
+	signedInfo = bytes.Replace(signedInfo, []byte("<ds:SignedInfo>"),
+		[]byte(`<ds:SignedInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">`), 1)
+	hashed := sha256.Sum256(signedInfo)
+	signature, _ := base64.StdEncoding.DecodeString(parsed.SignatureValue)
+	return rsa.VerifyPKCS1v15(key, crypto.SHA256, hashed[:], signature) == nil
+}
+
+func TestSAMLBridge(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "emmy SAML bridge"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	certificate, _ := x509.ParseCertificate(der)
+
+	// the holder discloses the affiliation and hides the other attributes
+	names := []string{"urn:oid:2.5.4.3", "urn:oid:1.3.6.1.4.1.5923.1.1.1.1",
+		"urn:oid:1.3.6.1.4.1.5923.1.1.1.6"}
+	committer := commitments.NewMerkleCommitter()
+	root, _ := committer.GetCommitMsg([]*big.Int{big.NewInt(1234), big.NewInt(5678),
+		big.NewInt(42)})
+	cl := signatures.NewMerkleCL()
+	signature, err := cl.SignMerkleRoot(root)
+	assert.Nil(t, err)
+	decommitment, _ := committer.GetDecommitMsg(1)
+	presentation, err := saml.NewMerkleCLPresentation(cl, root, signature,
+		[]*commitments.MerkleDecommitment{decommitment}, names)
+	assert.Nil(t, err)
+
+	bridge := saml.NewBridge("https://bridge.example.org/idp", key, certificate)
+	request := &saml.Request{
+		Audience:     "https://sp.example.org/shibboleth",
+		Recipient:    "https://sp.example.org/Shibboleth.sso/SAML2/POST",
+		InResponseTo: "_req<1>",
+	}
+	raw, err := bridge.Issue(presentation, request)
+	assert.Nil(t, err)
+
+	var parsed samlAssertion
+	assert.Nil(t, xml.Unmarshal(raw, &parsed))
+	assert.Equal(t, "https://bridge.example.org/idp", parsed.Issuer)
+	assert.Equal(t, request.Audience, parsed.Audience)
+	assert.Equal(t, 1, len(parsed.Attributes), "only the disclosed attribute should be included")
+	assert.Equal(t, names[1], parsed.Attributes[0].Name)
+	assert.Equal(t, []string{"5678"}, parsed.Attributes[0].Values)
+	assert.True(t, verifySAMLSignature(raw, &parsed, &key.PublicKey),
+		"signature of the assertion should be valid")
+
+	raw2, _ := bridge.Issue(presentation, request)
+	var parsed2 samlAssertion
+	assert.Nil(t, xml.Unmarshal(raw2, &parsed2))
+	assert.NotEqual(t, parsed.NameID, parsed2.NameID, "assertions should not be linkable")
+
+	tampered := bytes.Replace(raw, []byte("5678"), []byte("5679"), 1)
+	assert.False(t, verifySAMLSignature(tampered, &parsed, &key.PublicKey),
+		"changed assertion should not be accepted")
+
+	decommitment.Attribute = big.NewInt(5679)
+	_, err = saml.NewMerkleCLPresentation(cl, root, signature,
+		[]*commitments.MerkleDecommitment{decommitment}, names)
+	assert.NotNil(t, err, "presentation with a changed attribute should not be accepted")
+}
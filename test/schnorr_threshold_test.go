@@ -0,0 +1,136 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+	"google.golang.org/grpc"
+	"math/big"
+	"testing"
+)
+
+func TestThresholdSchnorr(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	secret := randomInt(group.Q)
+	shares, publicKey, err := dlogproofs.SplitSchnorrSecret(group, secret, 3, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, group.Exp(group.G, secret), publicKey.Y)
+
+	for _, signers := range [][]*dlogproofs.SchnorrKeyShare{
+		shares[:3], shares[2:], {shares[4], shares[0], shares[2]}, shares,
+	} {
+		proved, err := dlogproofs.ProveThresholdDLogKnowledge(signers, publicKey, group)
+		assert.Nil(t, err)
+		assert.True(t, proved, "threshold Schnorr proof should be verified")
+	}
+
+	_, err = dlogproofs.ProveThresholdDLogKnowledge(shares[:2], publicKey, group)
+	assert.NotNil(t, err, "two shares should not be enough")
+
+	// participant with the wrong share is identified by the coordinator
+	forged := []*dlogproofs.SchnorrKeyShare{shares[0], shares[1],
+		{Index: shares[2].Index, Share: randomInt(group.Q)}}
+	_, err = dlogproofs.ProveThresholdDLogKnowledge(forged, publicKey, group)
+	assert.EqualError(t, err, "proof data of participant 3 is not valid")
+
+	_, _, err = dlogproofs.SplitSchnorrSecret(group, secret, 6, 5)
+	assert.NotNil(t, err, "threshold should not exceed the number of shares")
+}
+
+func TestThresholdSchnorrLagrangeCoefficient(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	// lambda_1 for {1, 2} is 2 / (2 - 1) = 2, lambda_2 is 1 / (1 - 2) = -1
+	lambda, err := dlogproofs.ThresholdSchnorrLagrangeCoefficient(group, 1, []int{1, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(2), lambda)
+	lambda, err = dlogproofs.ThresholdSchnorrLagrangeCoefficient(group, 2, []int{1, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, new(big.Int).Sub(group.Q, big.NewInt(1)), lambda)
+
+	_, err = dlogproofs.ThresholdSchnorrLagrangeCoefficient(group, 3, []int{1, 2})
+	assert.NotNil(t, err, "participant needs to be among the signers")
+	_, err = dlogproofs.ThresholdSchnorrLagrangeCoefficient(group, 1, []int{1, 2, 2})
+	assert.NotNil(t, err, "signers need to be distinct")
+}
+
+// startThresholdSchnorrServer starts the server holding the share. It returns the address
+// of the server and a function which stops it.
+func startThresholdSchnorrServer(t *testing.T, share *dlogproofs.SchnorrKeyShare) (string,
+	func()) {
+	srv, err := server.NewServer(log.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.SetThresholdSchnorrShare(share)
+	return startTestServer(t, srv)
+}
+
+func TestGRPC_ThresholdSchnorr(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	secret := randomInt(group.Q)
+	shares, publicKey, err := dlogproofs.SplitSchnorrSecret(group, secret, 3, 4)
+	assert.Nil(t, err)
+
+	// shares 1 and 4 are held by emmy servers, share 2 is held locally
+	participants := []client.ThresholdSchnorrParticipant{
+		dlogproofs.NewThresholdSchnorrParticipant(group, shares[1]),
+	}
+	var conns []*grpc.ClientConn
+	for i := 0; i < 2; i++ {
+		share := shares[3*i]
+		address, stop := startThresholdSchnorrServer(t, share)
+		defer stop()
+		conn, err := client.GetConnection(address, "testdata/server.pem", false)
+		assert.Nil(t, err)
+		defer conn.Close()
+		conns = append(conns, conn)
+		p, err := client.NewThresholdSchnorrShareClient(conn, share.Index)
+		assert.Nil(t, err)
+		participants = append(participants, p)
+	}
+
+	holder, err := client.NewThresholdSchnorrHolder(group, publicKey, participants...)
+	assert.Nil(t, err)
+	b, err := holder.GetPublicKey(group.G)
+	assert.Nil(t, err)
+	verifier, err := dlogproofs.NewSchnorrVerifier(group)
+	assert.Nil(t, err)
+	x, err := holder.GetProofRandomData(group.G)
+	assert.Nil(t, err)
+	verifier.SetProofRandomData(x, group.G, b)
+	challenge, _, err := verifier.GetChallenge()
+	assert.Nil(t, err)
+	z, err := holder.GetProofData(challenge)
+	assert.Nil(t, err)
+	assert.True(t, verifier.Verify(z), "threshold proof should be verified")
+
+	c, err := client.NewSchnorrClientFromSecretHolder(testGrpcClientConn, group, holder)
+	assert.Nil(t, err)
+	assert.Nil(t, c.Run(), "should finish without errors")
+
+	// the server does not hold the share with a different index
+	p, err := client.NewThresholdSchnorrShareClient(conns[0], 2)
+	assert.Nil(t, err)
+	_, err = p.GetProofRandomData([]int{1, 2, 4})
+	assert.NotNil(t, err, "server should refuse to use another share")
+}
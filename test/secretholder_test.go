@@ -0,0 +1,136 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/secretholder"
+	"github.com/xlab-si/emmy/types"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// listenSecretHolder starts the secret holder server on a unix socket in a temporary
+// directory and returns the path of the socket.
+func listenSecretHolder(t *testing.T, server *secretholder.Server) (string, func()) {
+	dir, err := ioutil.TempDir("", "emmy-secretholder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "holder.sock")
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(lis)
+	return path, func() {
+		lis.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestSecretHolderSchnorr(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	secret := randomInt(group.Q)
+	path, stop := listenSecretHolder(t, secretholder.NewSchnorrServer(group, secret))
+	defer stop()
+
+	holder, err := secretholder.DialSchnorr(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer holder.Close()
+
+	b, err := holder.GetPublicKey(group.G)
+	assert.Nil(t, err)
+	assert.Equal(t, group.Exp(group.G, secret), b)
+
+	verifier, err := dlogproofs.NewSchnorrVerifier(group)
+	assert.Nil(t, err)
+	x, err := holder.GetProofRandomData(group.G)
+	assert.Nil(t, err)
+	verifier.SetProofRandomData(x, group.G, b)
+	challenge, _, err := verifier.GetChallenge()
+	assert.Nil(t, err)
+	z, err := holder.GetProofData(challenge)
+	assert.Nil(t, err)
+	assert.True(t, verifier.Verify(z), "proof with remote secret should be verified")
+
+	// the second response to the same proof random data would reveal the secret
+	_, err = holder.GetProofData(new(big.Int).Add(challenge, big.NewInt(1)))
+	assert.NotNil(t, err, "proof random data should be used only once")
+
+	_, err = holder.GetProofRandomData(big.NewInt(0))
+	assert.NotNil(t, err, "base which is not in the group should be refused")
+}
+
+func TestSecretHolderSchnorrEC(t *testing.T) {
+	secret := big.NewInt(345345345334)
+	path, stop := listenSecretHolder(t, secretholder.NewSchnorrECServer(dlog.P256, secret))
+	defer stop()
+
+	holder, err := secretholder.DialSchnorrEC(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer holder.Close()
+
+	dLog := dlog.NewECDLog(dlog.P256)
+	aX, aY := dLog.ExponentiateBaseG(big.NewInt(1))
+	a := types.NewECGroupElement(aX, aY)
+	b, err := holder.GetPublicKey(a)
+	assert.Nil(t, err)
+
+	verifier, _ := dlogproofs.NewSchnorrECVerifier()
+	x, err := holder.GetProofRandomData(a)
+	assert.Nil(t, err)
+	verifier.SetProofRandomData(x, a, b)
+	challenge, _, err := verifier.GetChallenge()
+	assert.Nil(t, err)
+	z, err := holder.GetProofData(challenge)
+	assert.Nil(t, err)
+	assert.True(t, verifier.Verify(z), "proof with remote secret should be verified")
+
+	_, err = holder.GetPublicKey(types.NewECGroupElement(aX, new(big.Int).Add(aY, big.NewInt(1))))
+	assert.NotNil(t, err, "point which is not on the curve should be refused")
+}
+
+func TestGRPC_SecretHolder(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	path, stop := listenSecretHolder(t, secretholder.NewSchnorrServer(group,
+		big.NewInt(345345345334)))
+	defer stop()
+
+	holder, err := secretholder.DialSchnorr(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer holder.Close()
+
+	c, err := client.NewSchnorrClientFromSecretHolder(testGrpcClientConn, group, holder)
+	assert.Nil(t, err)
+	assert.Nil(t, c.Run(), "should finish without errors")
+}
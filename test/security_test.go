@@ -0,0 +1,137 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/dlogproofs"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/stern"
+	"github.com/xlab-si/emmy/crypto/zkp/security"
+	"github.com/xlab-si/emmy/log"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"github.com/xlab-si/emmy/server"
+	"testing"
+)
+
+func TestSecurityParams(t *testing.T) {
+	params := security.Params{Level: 128, StatisticalBitLength: 192}
+	assert.Nil(t, params.Validate())
+	assert.Equal(t, 128, params.ChallengeBits(80))
+	assert.Equal(t, 192, params.StatisticalBits(80))
+	assert.Equal(t, 219, params.Runs(2.0/3, stern.SternRounds))
+	assert.Equal(t, 80, security.Params{}.ChallengeBits(80), "default should be used")
+	assert.Equal(t, stern.SternRounds, security.Params{}.Runs(2.0/3, stern.SternRounds))
+	assert.Equal(t, stern.SternRounds, security.Repetitions(80, 2.0/3))
+
+	assert.NotNil(t, security.Params{Level: 64}.Validate(), "level below 80 bits")
+	assert.NotNil(t, security.Params{ChallengeBitLength: 1024}.Validate(),
+		"challenge bit length above the bound")
+	assert.NotNil(t, security.Params{Repetitions: 2000}.Validate(),
+		"repetitions above the bound")
+	assert.NotNil(t, security.CheckRepetitions(100, 2.0/3), "too few repetitions")
+
+	def, _ := config.LoadSecurityParams()
+	assert.Nil(t, def.Validate())
+}
+
+func TestGPSSecurity(t *testing.T) {
+	params := testGPSParams(t, 1024)
+	secret, publicKey, _ := params.GenerateKey()
+	level := security.Params{Level: 128}
+
+	prover, _ := dlogproofs.NewGPSProver(params, secret)
+	verifier := dlogproofs.NewGPSVerifier(params, publicKey)
+	assert.Nil(t, prover.SetSecurity(level))
+	assert.Nil(t, verifier.SetSecurity(level))
+	x, _ := prover.GetProofRandomData()
+	challenge, err := verifier.GetChallenge(x)
+	assert.Nil(t, err)
+	y, err := prover.GetProofData(challenge)
+	assert.Nil(t, err)
+	assert.True(t, verifier.Verify(y), "GPS identification at 128 bits should pass")
+
+	// prover with the default (80-bit) challenges does not accept longer challenges
+	prover, _ = dlogproofs.NewGPSProver(params, secret)
+	x, _ = prover.GetProofRandomData()
+	challenge, err = verifier.GetChallenge(x)
+	assert.Nil(t, err)
+	for challenge.BitLen() <= dlogproofs.GPSChallengeBitLen {
+		challenge, err = verifier.GetChallenge(x)
+		assert.Nil(t, err)
+	}
+	_, err = prover.GetProofData(challenge)
+	assert.NotNil(t, err, "challenge should be out of range")
+
+	assert.NotNil(t, prover.SetSecurity(security.Params{ChallengeBitLength: 40}))
+}
+
+func TestSternSecurity(t *testing.T) {
+	params, _ := stern.NewParams()
+	secret, publicKey, _ := params.GenerateKey()
+	level := security.Params{Level: 128}
+
+	prover, _ := stern.NewSternProver(params, secret)
+	verifier := stern.NewSternVerifier(params, publicKey)
+	assert.Nil(t, verifier.SetSecurity(level))
+	commitments, _ := prover.GetProofRandomData()
+	_, err := verifier.GetChallenges(commitments)
+	assert.NotNil(t, err, "verifier should require more rounds")
+
+	assert.Nil(t, prover.SetSecurity(level))
+	commitments, _ = prover.GetProofRandomData()
+	assert.Equal(t, 219, len(commitments))
+	challenges, err := verifier.GetChallenges(commitments)
+	assert.Nil(t, err)
+	responses, _ := prover.GetProofData(challenges)
+	assert.True(t, verifier.Verify(responses), "Stern identification at 128 bits should pass")
+
+	assert.NotNil(t, prover.SetSecurity(security.Params{Repetitions: 100}),
+		"repetitions below 80 bits of security should not be accepted")
+}
+
+func TestGRPC_SternSecurity(t *testing.T) {
+	srv, err := server.NewServer(log.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, srv.SetSecurityParams(pb.SchemaType_STERN, security.Params{Level: 128}))
+	assert.NotNil(t, srv.SetSecurityParams(pb.SchemaType_GPS,
+		security.Params{ChallengeBitLength: 1}))
+	address, stop := startTestServer(t, srv)
+	defer stop()
+
+	conn, err := client.GetConnection(address, "testdata/server.pem", false)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	params, _ := stern.NewParams()
+	secret, _, _ := params.GenerateKey()
+	c, err := client.NewSternClient(conn, params, secret)
+	assert.Nil(t, err)
+	_, err = c.Run()
+	assert.NotNil(t, err, "server should require more rounds than the default")
+
+	c, err = client.NewSternClient(conn, params, secret,
+		client.WithSecurityParams(security.Params{Level: 128}))
+	assert.Nil(t, err)
+	success, err := c.Run()
+	assert.Nil(t, err, "should finish without errors")
+	assert.True(t, success, "Stern identification at 128 bits should pass")
+}
@@ -0,0 +1,318 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/log"
+	pb "github.com/xlab-si/emmy/protobuf"
+	"github.com/xlab-si/emmy/server"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"testing"
+	"time"
+)
+
+// TestGRPC_EmbeddedServer registers emmy services with a gRPC server which is
+// created and started by the application.
+func TestGRPC_EmbeddedServer(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("embeddedServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer(logger)
+	assert.Nil(t, err)
+
+	creds, err := credentials.NewServerTLSFromFile("testdata/server.pem", "testdata/server.key")
+	assert.Nil(t, err)
+	grpcServer := grpc.NewServer(grpc.Creds(creds))
+	srv.RegisterServices(grpcServer)
+
+	listener, address, err := listenLocal()
+	assert.Nil(t, err)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := client.GetConnection(address, "testdata/server.pem", false)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	info, err := client.GetServiceInfo(conn)
+	assert.Nil(t, err)
+	assert.NotNil(t, info, "expected non-nil service info")
+
+	assert.Equal(t, 2, len(srv.ServiceDescs()))
+	assert.NotNil(t, srv.Start(0), "server without its own gRPC server should not start")
+}
+
+// TestGRPC_SecondProtocolServer starts another protocol server in the same process (the
+// first one is started in TestMain).
+func TestGRPC_SecondProtocolServer(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("secondServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewProtocolServer("testdata/server.pem", "testdata/server.key", logger)
+	assert.Nil(t, err)
+	listener, address, err := listenLocal()
+	assert.Nil(t, err)
+	go srv.Serve(listener)
+	defer srv.Teardown()
+
+	conn, err := client.GetConnection(address, "testdata/server.pem", false)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	_, err = client.GetServiceInfo(conn)
+	assert.Nil(t, err, "second server should serve requests")
+}
+
+// TestGRPC_RoundTimeout checks that the server aborts the session when the client stalls
+// after the challenge is sent.
+func TestGRPC_RoundTimeout(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("deadlineServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer(logger)
+	assert.Nil(t, err)
+	srv.SetRoundTimeout(pb.SchemaType_SCHNORR, 200*time.Millisecond)
+	srv.SetRoundTimeout(pb.SchemaType_PEDERSEN, 0)
+
+	address, stop := startTestServer(t, srv)
+	defer stop()
+
+	conn, err := client.GetConnection(address, "testdata/server.pem", false)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	group := config.LoadGroup("schnorr")
+	secret := randomInt(group.Q)
+	c, err := client.NewSchnorrClient(conn, group, secret)
+	assert.Nil(t, err)
+	assert.Nil(t, c.Run(), "Client which responds in time should not be aborted")
+
+	stall := func(id int32, msg *pb.Message) (*pb.Message, error) {
+		if msg.GetSchnorrProofData() != nil {
+			time.Sleep(500 * time.Millisecond)
+		}
+		return msg, nil
+	}
+	c, err = client.NewSchnorrClient(conn, group, secret, client.WithSendHook(stall))
+	assert.Nil(t, err)
+	assert.NotNil(t, c.Run(), "Session should be aborted when the client stalls")
+
+	// schemas without a deadline wait for the client
+	slow := func(id int32, msg *pb.Message) (*pb.Message, error) {
+		time.Sleep(300 * time.Millisecond)
+		return msg, nil
+	}
+	pc, err := client.NewPedersenClient(conn, config.LoadGroup("pedersen"),
+		randomInt(group.Q), client.WithSendHook(slow))
+	assert.Nil(t, err)
+	assert.Nil(t, pc.Run(), "Session without a deadline should not be aborted")
+}
+
+func TestAdmissionController(t *testing.T) {
+	ctx := context.Background()
+	c := server.NewAdmissionController(3, 1, 100*time.Millisecond)
+
+	release1, err := c.Admit(ctx, pb.SchemaType_SCHNORR, 2)
+	assert.Nil(t, err, "session within the budget should be admitted")
+	release2, err := c.Admit(ctx, pb.SchemaType_SCHNORR, 1)
+	assert.Nil(t, err, "session within the budget should be admitted")
+
+	// the queued session is admitted when enough budget is released
+	admitted := make(chan error, 1)
+	go func() {
+		release, err := c.Admit(ctx, pb.SchemaType_CSPAILLIER, 2)
+		if err == nil {
+			release()
+		}
+		admitted <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	_, err = c.Admit(ctx, pb.SchemaType_SCHNORR, 1)
+	assert.NotNil(t, err, "session should be rejected when the queue is full")
+
+	release2()
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case <-admitted:
+		t.Errorf("session should not be admitted before enough budget is released")
+	default:
+	}
+	release1()
+	assert.Nil(t, <-admitted, "queued session should be admitted")
+
+	release1, _ = c.Admit(ctx, pb.SchemaType_SCHNORR, 3)
+	_, err = c.Admit(ctx, pb.SchemaType_SCHNORR, 1)
+	assert.NotNil(t, err, "session should be rejected after the queue timeout")
+	release1()
+
+	// a session which costs more than the budget runs alone
+	release1, err = c.Admit(ctx, pb.SchemaType_RANGE_PROOF, 10)
+	assert.Nil(t, err, "costly session should be admitted to idle server")
+	release1()
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	ctx := context.Background()
+	l := server.NewConcurrencyLimiter(0, 1, 1, 100*time.Millisecond)
+
+	releaseA, err := l.Acquire(ctx, pb.SchemaType_SCHNORR, "a")
+	assert.Nil(t, err, "session within the limit should be admitted")
+	releaseB, err := l.Acquire(ctx, pb.SchemaType_SCHNORR, "b")
+	assert.Nil(t, err, "session of another client should be admitted")
+
+	admitted := make(chan error, 1)
+	go func() {
+		release, err := l.Acquire(ctx, pb.SchemaType_SCHNORR, "a")
+		if err == nil {
+			release()
+		}
+		admitted <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	_, err = l.Acquire(ctx, pb.SchemaType_SCHNORR, "a")
+	assert.NotNil(t, err, "session should be rejected when the queue of the client is full")
+	releaseB()
+	select {
+	case <-admitted:
+		t.Errorf("session should not be admitted before the client's session finishes")
+	case <-time.After(20 * time.Millisecond):
+	}
+	releaseA()
+	assert.Nil(t, <-admitted, "queued session should be admitted")
+
+	releaseA, _ = l.Acquire(ctx, pb.SchemaType_SCHNORR, "a")
+	_, err = l.Acquire(ctx, pb.SchemaType_SCHNORR, "a")
+	assert.NotNil(t, err, "session should be rejected after the queue timeout")
+	l.SetClientLimit("a", 2)
+	release, err := l.Acquire(ctx, pb.SchemaType_SCHNORR, "a")
+	assert.Nil(t, err, "session within the limit of the client should be admitted")
+	release()
+	releaseA()
+}
+
+func TestConcurrencyLimiterFairness(t *testing.T) {
+	ctx := context.Background()
+	l := server.NewConcurrencyLimiter(1, 0, 10, time.Second)
+	release, err := l.Acquire(ctx, pb.SchemaType_SCHNORR, "a")
+	assert.Nil(t, err)
+
+	// the chatty client a queues its sessions before b
+	order := make(chan string, 4)
+	acquire := func(client string) {
+		release, err := l.Acquire(ctx, pb.SchemaType_SCHNORR, client)
+		assert.Nil(t, err)
+		order <- client
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}
+	for i := 0; i < 3; i++ {
+		go acquire("a")
+		time.Sleep(10 * time.Millisecond)
+	}
+	go acquire("b")
+	time.Sleep(10 * time.Millisecond)
+	release()
+
+	admitted := []string{<-order, <-order, <-order, <-order}
+	assert.Equal(t, []string{"a", "b", "a", "a"}, admitted,
+		"waiting clients should be admitted in turns")
+}
+
+// TestGRPC_Admission checks that the server rejects the sessions beyond its budget.
+func TestGRPC_Admission(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("admissionServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer(logger)
+	assert.Nil(t, err)
+	srv.SetAdmissionController(server.NewAdmissionController(1, 0, time.Second))
+	srv.SetSessionCost(pb.SchemaType_SCHNORR, 1)
+
+	address, stop := startTestServer(t, srv)
+	defer stop()
+
+	conn, err := client.GetConnection(address, "testdata/server.pem", false)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	group := config.LoadGroup("schnorr")
+	secret := randomInt(group.Q)
+	slow := func(id int32, msg *pb.Message) (*pb.Message, error) {
+		if msg.GetSchnorrProofData() != nil {
+			time.Sleep(300 * time.Millisecond)
+		}
+		return msg, nil
+	}
+	slowClient, err := client.NewSchnorrClient(conn, group, secret, client.WithSendHook(slow))
+	assert.Nil(t, err)
+	done := make(chan error, 1)
+	go func() {
+		done <- slowClient.Run()
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	c, err := client.NewSchnorrClient(conn, group, secret)
+	assert.Nil(t, err)
+	assert.NotNil(t, c.Run(), "Session beyond the budget should be rejected")
+	assert.Nil(t, <-done, "Admitted session should finish")
+
+	c, err = client.NewSchnorrClient(conn, group, secret)
+	assert.Nil(t, err)
+	assert.Nil(t, c.Run(), "Session should be admitted once the budget is released")
+}
+
+// TestGRPC_Concurrency checks that the server limits the concurrent sessions of each tenant.
+func TestGRPC_Concurrency(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("concurrencyServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer(logger)
+	assert.Nil(t, err)
+	srv.SetConcurrencyLimiter(server.NewConcurrencyLimiter(0, 1, 0, time.Second), server.Tenant)
+
+	address, stop := startTestServer(t, srv)
+	defer stop()
+
+	conn, err := client.GetConnection(address, "testdata/server.pem", false)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	group := config.LoadGroup("schnorr")
+	secret := randomInt(group.Q)
+	slow := func(id int32, msg *pb.Message) (*pb.Message, error) {
+		if msg.GetSchnorrProofData() != nil {
+			time.Sleep(300 * time.Millisecond)
+		}
+		return msg, nil
+	}
+	slowClient, err := client.NewSchnorrClient(conn, group, secret, client.WithTenant("a"),
+		client.WithSendHook(slow))
+	assert.Nil(t, err)
+	done := make(chan error, 1)
+	go func() {
+		done <- slowClient.Run()
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	c, err := client.NewSchnorrClient(conn, group, secret, client.WithTenant("a"))
+	assert.Nil(t, err)
+	assert.NotNil(t, c.Run(), "Session beyond the limit of the tenant should be rejected")
+	c, err = client.NewSchnorrClient(conn, group, secret, client.WithTenant("b"))
+	assert.Nil(t, err)
+	assert.Nil(t, c.Run(), "Session of another tenant should be admitted")
+	assert.Nil(t, <-done, "Admitted session should finish")
+
+	c, err = client.NewSchnorrClient(conn, group, secret, client.WithTenant("a"))
+	assert.Nil(t, err)
+	assert.Nil(t, c.Run(), "Session should be admitted once the tenant's session finishes")
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/fiatshamir"
+	"github.com/xlab-si/emmy/crypto/zkp/session"
+	"github.com/xlab-si/emmy/crypto/zkp/sigma"
+	"math/big"
+	"testing"
+)
+
+func TestProofSessions(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	secret := randomInt(group.Q)
+	g2 := group.Exp(group.G, randomInt(group.Q))
+	t1, t2 := group.Exp(group.G, secret), group.Exp(g2, secret)
+	other := group.Exp(group.G, randomInt(group.Q))
+
+	tests := []struct {
+		name     string
+		prover   session.ProofSession
+		verifier session.ProofSession
+		valid    bool
+	}{
+		{"Schnorr",
+			session.NewSchnorrProverSession(group, secret, group.G, t1),
+			session.NewSchnorrVerifierSession(group, group.G, t1), true},
+		{"SchnorrWrongStatement",
+			session.NewSchnorrProverSession(group, secret, group.G, t1),
+			session.NewSchnorrVerifierSession(group, group.G, other), false},
+		{"DLogEquality",
+			session.NewDLogEqualityProverSession(group, secret, group.G, g2, t1, t2),
+			session.NewDLogEqualityVerifierSession(group, group.G, g2, t1, t2), true},
+		{"PartialDLog",
+			session.NewPartialDLogProverSession(group, secret, group.G, t1, g2, other),
+			session.NewPartialDLogVerifierSession(group, group.G, t1, g2, other), true},
+		{"SigmaComposition",
+			session.NewSigmaProverSession(sigma.Or(sigma.NewDLog(group, group.G, other, nil),
+				sigma.NewDLog(group, group.G, t1, secret))),
+			session.NewSigmaVerifierSession(sigma.Or(sigma.NewDLog(group, group.G, other, nil),
+				sigma.NewDLog(group, group.G, t1, nil))), true},
+	}
+	for _, test := range tests {
+		valid, transcript, err := session.Run(test.prover, test.verifier)
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, test.valid, valid, test.name)
+		assert.Len(t, transcript.Messages, 4, test.name)
+
+		_, done, err := test.verifier.Next(nil)
+		assert.True(t, done, test.name)
+		assert.Equal(t, session.ErrFinished, err, test.name)
+	}
+
+	// recorded transcripts can be checked again
+	_, transcript, _ := session.Run(session.NewSchnorrProverSession(group, secret, group.G, t1),
+		session.NewSchnorrVerifierSession(group, group.G, t1))
+	assert.True(t, session.VerifySigmaTranscript(fiatshamir.NewSchnorrVerifier(group, group.G,
+		t1), transcript))
+	assert.False(t, session.VerifySigmaTranscript(fiatshamir.NewSchnorrVerifier(group, group.G,
+		other), transcript))
+
+	// malformed challenge
+	prover := session.NewSchnorrProverSession(group, secret, group.G, t1)
+	prover.Next(nil)
+	_, _, err := prover.Next([]*big.Int{group.Q})
+	assert.Equal(t, session.ErrUnexpectedMsg, err)
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/shuffle"
+	"math/big"
+	"testing"
+)
+
+func getShuffleInput(t *testing.T, elgamal *encryption.ElGamal, n int) ([]*big.Int,
+	[]*encryption.ElGamalCiphertext) {
+	pubKey := elgamal.GetPubKey()
+	messages := make([]*big.Int, n)
+	input := make([]*encryption.ElGamalCiphertext, n)
+	for i := 0; i < n; i++ {
+		m, err := pubKey.Group.GetRandomElement()
+		assert.Nil(t, err)
+		messages[i] = m
+		c, err := pubKey.Encrypt(messages[i])
+		assert.Nil(t, err)
+		input[i] = c
+	}
+	return messages, input
+}
+
+func TestShuffle(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	elgamal, _ := encryption.NewElGamal(group)
+	pubKey := elgamal.GetPubKey()
+	messages, input := getShuffleInput(t, elgamal, 5)
+
+	proved, err := shuffle.ProveShuffle(pubKey, input)
+	assert.Nil(t, err)
+	assert.True(t, proved, "Proof of shuffle does not work correctly")
+
+	// the shuffled ciphertexts decrypt to the same messages
+	output, permutation, _, err := shuffle.Shuffle(pubKey, input)
+	assert.Nil(t, err)
+	for i, j := range permutation {
+		p, err := elgamal.Decrypt(output[i])
+		assert.Nil(t, err)
+		assert.Equal(t, messages[j], p, "Shuffled ciphertext should decrypt to the same message")
+	}
+
+	// a single ciphertext is shuffled too
+	proved, err = shuffle.ProveShuffle(pubKey, input[:1])
+	assert.Nil(t, err)
+	assert.True(t, proved, "Proof of shuffle of a single ciphertext should be accepted")
+}
+
+func runShuffleProof(prover *shuffle.ShuffleProver, verifier *shuffle.ShuffleVerifier) (bool,
+	error) {
+	u, err := prover.GetPermutationCommitment()
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetPermutationCommitment(u); err != nil {
+		return false, err
+	}
+	proofRandomData, err := prover.GetProofRandomData(verifier.GetExponents())
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(proofRandomData); err != nil {
+		return false, err
+	}
+	challenge, err := verifier.GetChallenge()
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+func TestShuffleInvalid(t *testing.T) {
+	group := config.LoadGroup("schnorr")
+	elgamal, _ := encryption.NewElGamal(group)
+	pubKey := elgamal.GetPubKey()
+	_, input := getShuffleInput(t, elgamal, 4)
+	output, permutation, randomness, err := shuffle.Shuffle(pubKey, input)
+	assert.Nil(t, err)
+
+	// one of the output ciphertexts encrypts another message
+	tampered := append([]*encryption.ElGamalCiphertext{}, output...)
+	m, err := group.GetRandomElement()
+	assert.Nil(t, err)
+	other, _ := pubKey.Encrypt(m)
+	tampered[2] = other
+	prover, err := shuffle.NewShuffleProver(pubKey, input, tampered, permutation, randomness)
+	assert.Nil(t, err)
+	verifier, err := shuffle.NewShuffleVerifier(pubKey, input, tampered)
+	assert.Nil(t, err)
+	proved, err := runShuffleProof(prover, verifier)
+	assert.Nil(t, err)
+	assert.False(t, proved, "Proof for a tampered output should not be accepted")
+
+	// one of the input ciphertexts is duplicated instead of being shuffled
+	duplicated := append([]*encryption.ElGamalCiphertext{}, output...)
+	for i, j := range permutation {
+		if j == 0 {
+			duplicated[i] = pubKey.ReEncrypt(input[1], randomInt(group.Q))
+		}
+	}
+	prover, _ = shuffle.NewShuffleProver(pubKey, input, duplicated, permutation, randomness)
+	verifier, _ = shuffle.NewShuffleVerifier(pubKey, input, duplicated)
+	proved, err = runShuffleProof(prover, verifier)
+	assert.Nil(t, err)
+	assert.False(t, proved, "Proof for a duplicated ciphertext should not be accepted")
+
+	_, err = shuffle.NewShuffleProver(pubKey, input, output, []int{0, 1, 1, 2}, randomness)
+	assert.NotNil(t, err, "Not a permutation should not be accepted")
+	_, err = shuffle.NewShuffleVerifier(pubKey, input, output[:3])
+	assert.NotNil(t, err, "Lists of different lengths should not be accepted")
+}
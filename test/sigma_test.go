@@ -0,0 +1,207 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/fiatshamir"
+	"github.com/xlab-si/emmy/crypto/zkp/sigma"
+	"github.com/xlab-si/emmy/types"
+	"math/big"
+	"testing"
+)
+
+func TestSigmaComposition(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	g, h := group.G, group.HashIntoElement(big.NewInt(1))
+	g2 := group.HashIntoElement(big.NewInt(2))
+
+	x1 := randomInt(group.Q) // unknown to the prover
+	b1 := group.Exp(g, x1)
+	v, r := randomInt(group.Q), randomInt(group.Q)
+	c := group.Mul(group.Exp(g, v), group.Exp(h, r)) // commitment to v
+	s := randomInt(group.Q)
+	t1, t2 := group.Exp(g, s), group.Exp(g2, s)
+
+	// knows dlog of b1 OR (knows opening of c AND log_g(t1) = log_g2(t2))
+	statement := func(know bool) sigma.Protocol {
+		var opening []*big.Int
+		var secret *big.Int
+		if know {
+			opening, secret = []*big.Int{v, r}, s
+		}
+		return sigma.Or(
+			sigma.NewDLog(group, g, b1, nil),
+			sigma.And(
+				sigma.NewRepresentation(group, []*big.Int{g, h}, c, opening),
+				sigma.NewDLogEquality(group, g, t1, g2, t2, secret),
+			),
+		)
+	}
+
+	prover := statement(true)
+	assert.True(t, prover.HasWitness())
+	proved, err := sigma.Run(prover)
+	assert.Nil(t, err)
+	assert.True(t, proved, "Composed protocol should be proved")
+	assert.False(t, statement(false).HasWitness())
+	_, err = sigma.Run(statement(false))
+	assert.NotNil(t, err, "Run without the witness should return an error")
+
+	proofRandomData, err := prover.GetProofRandomData()
+	assert.Nil(t, err)
+	challenge := randomInt(prover.ChallengeSpace())
+	proofData, err := prover.GetProofData(challenge)
+	assert.Nil(t, err)
+	verifier := statement(false)
+	assert.True(t, verifier.Verify(proofRandomData, challenge, proofData))
+	wrong := new(big.Int).Xor(challenge, big.NewInt(1))
+	assert.False(t, verifier.Verify(proofRandomData, wrong, proofData),
+		"Proof should not be verified for another challenge")
+
+	// simulated transcripts are accepted too
+	sRandomData, sData, err := verifier.Simulate(challenge)
+	assert.Nil(t, err)
+	assert.True(t, verifier.Verify(sRandomData, challenge, sData))
+
+	// EC and Z_p statements can be mixed, the composition works with Fiat-Shamir
+	ecDLog := dlog.NewECDLog(dlog.P256)
+	a := types.NewECGroupElement(ecDLog.Curve.Params().Gx, ecDLog.Curve.Params().Gy)
+	secret := randomInt(ecDLog.OrderOfSubgroup)
+	b := types.NewECGroupElement(ecDLog.ExponentiateBaseG(secret))
+	mixed := sigma.Or(sigma.NewECDLog(dlog.P256, a, b, secret), sigma.NewDLog(group, g, b1, nil))
+	proof, err := fiatshamir.Prove(mixed, []byte("context"))
+	assert.Nil(t, err)
+	assert.True(t, fiatshamir.Verify(sigma.Or(sigma.NewECDLog(dlog.P256, a, b, nil),
+		sigma.NewDLog(group, g, b1, nil)), proof, []byte("context")))
+}
+
+func TestSigmaPreimage(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	g := group.G
+	sk := randomInt(group.Q)
+	pk := group.Exp(g, sk)
+
+	// knowledge of the plaintext m of ElGamal ciphertext (g^r, pk^r * g^m)
+	phi := sigma.NewSchnorrHomomorphism(group, [][]*big.Int{{g, nil}, {pk, g}})
+	r, m := randomInt(group.Q), randomInt(group.Q)
+	ciphertext := phi.Eval([]*big.Int{r, m})
+
+	prover := sigma.NewPreimage(phi, ciphertext, []*big.Int{r, m})
+	proved, err := sigma.Run(prover)
+	assert.Nil(t, err)
+	assert.True(t, proved, "preimage should be proved")
+
+	verifier := sigma.NewPreimage(phi, ciphertext, nil)
+	proof, err := fiatshamir.Prove(prover, []byte("context"))
+	assert.Nil(t, err)
+	assert.True(t, fiatshamir.Verify(verifier, proof, []byte("context")))
+	other := sigma.NewPreimage(phi, phi.Eval([]*big.Int{r, big.NewInt(1)}), nil)
+	assert.False(t, fiatshamir.Verify(other, proof, []byte("context")),
+		"proof should not be verified for another ciphertext")
+
+	challenge := randomInt(verifier.ChallengeSpace())
+	sRandomData, sData, err := verifier.Simulate(challenge)
+	assert.Nil(t, err)
+	assert.True(t, verifier.Verify(sRandomData, challenge, sData))
+	assert.False(t, verifier.Verify(sRandomData[:1], challenge, sData),
+		"proof random data outside of the codomain should be rejected")
+
+	// preimage proofs compose with the other protocols
+	b := group.Exp(g, randomInt(group.Q))
+	proved, err = sigma.Run(sigma.Or(sigma.NewDLog(group, g, b, nil), prover))
+	assert.Nil(t, err)
+	assert.True(t, proved)
+	proved, err = sigma.Run(sigma.And(sigma.NewDLog(group, g, pk, sk), prover))
+	assert.Nil(t, err)
+	assert.True(t, proved)
+}
+
+func TestSigmaSetMembership(t *testing.T) {
+	group := config.LoadGroup("pedersen")
+	h, err := group.GetRandomElement()
+	assert.Nil(t, err)
+	set := []*big.Int{big.NewInt(40), big.NewInt(49), big.NewInt(276), big.NewInt(250)}
+	x, r := big.NewInt(276), randomInt(group.Q)
+	c := group.Mul(group.Exp(group.G, x), group.Exp(h, r))
+
+	prover := sigma.NewSetMembership(group, h, c, set, x, r)
+	proved, err := sigma.Run(prover)
+	assert.Nil(t, err)
+	assert.True(t, proved, "set membership should be proved")
+	proof, err := fiatshamir.Prove(prover, nil)
+	assert.Nil(t, err)
+	verifier := sigma.NewSetMembership(group, h, c, set, nil, nil)
+	assert.True(t, fiatshamir.Verify(verifier, proof, nil), "proof should be verified")
+
+	other := []*big.Int{big.NewInt(40), big.NewInt(49), big.NewInt(277), big.NewInt(250)}
+	assert.False(t, sigma.NewSetMembership(group, h, c, other, x, r).HasWitness(),
+		"value which is not in the set should not be proved")
+	verifier = sigma.NewSetMembership(group, h, c, other, nil, nil)
+	assert.False(t, fiatshamir.Verify(verifier, proof, nil),
+		"proof should not be verified for another set")
+}
+
+func TestSigmaBackends(t *testing.T) {
+	for _, group := range []groups.PrimeOrderGroup{
+		groups.NewSchnorrBackend(config.LoadGroup("pseudonymsys")),
+		dlog.NewECBackend(dlog.P256),
+	} {
+		g := group.Generator()
+		h := g.Exp(group.Scalar(big.NewInt(7)))
+		v, _ := group.RandomScalar()
+		r, _ := group.RandomScalar()
+		c := g.Exp(v).Mul(h.Exp(r))
+
+		opening := sigma.NewGroupRepresentation(group, []groups.Element{g, h}, c,
+			[]groups.Scalar{v, r})
+		proved, err := sigma.Run(opening)
+		assert.Nil(t, err)
+		assert.True(t, proved, "representation should be proved in %s", group.Name())
+
+		d := g.Exp(r)
+		equality := sigma.NewGroupDLogEquality(group, g, d, h, h.Exp(r), r)
+		proved, err = sigma.Run(equality)
+		assert.Nil(t, err)
+		assert.True(t, proved, "dlog equality should be proved in %s", group.Name())
+
+		proof, err := fiatshamir.Prove(sigma.And(opening, equality), nil)
+		assert.Nil(t, err)
+		wrong := sigma.NewGroupDLogEquality(group, g, d, h, h.Exp(v), nil)
+		assert.False(t, fiatshamir.Verify(sigma.And(opening, wrong), proof, nil),
+			"proof should not be verified for another statement in %s", group.Name())
+
+		_, err = group.Element([]*big.Int{big.NewInt(2), big.NewInt(3)})
+		assert.NotNil(t, err, "invalid element should not be decoded in %s", group.Name())
+		decoded, err := group.Element(c.Ints())
+		assert.Nil(t, err)
+		assert.True(t, decoded.Equals(c))
+		assert.True(t, v.Add(r).Sub(r).Equals(v))
+	}
+
+	// statements with values which are not from the group are never verified
+	group := config.LoadGroup("pseudonymsys")
+	invalid := sigma.NewDLog(group, group.G, big.NewInt(0), nil)
+	assert.False(t, invalid.HasWitness())
+	sRandomData, sData, err := invalid.Simulate(big.NewInt(1))
+	assert.Nil(t, err)
+	assert.False(t, invalid.Verify(sRandomData, big.NewInt(1), sData))
+}
@@ -18,7 +18,8 @@
 package test
 
 import (
-	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/commitments"
 	"github.com/xlab-si/emmy/crypto/signatures"
 	"log"
 	"math/big"
@@ -31,8 +32,8 @@ func TestCL(t *testing.T) {
 	n := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(160)), nil)
 	//n, _ := new(big.Int).SetString("26959946667150639794667015087019630673557916260026308143510066298881", 10)
 
-	m1 := common.GetRandomInt(n)
-	m2 := common.GetRandomInt(n)
+	m1 := randomInt(n)
+	m2 := randomInt(n)
 	var m_Ls []*big.Int
 	m_Ls = append(m_Ls, m1)
 	m_Ls = append(m_Ls, m2)
@@ -47,3 +48,280 @@ func TestCL(t *testing.T) {
 	ok, _ := pubCL.Verify(m_Ls, signature)
 	log.Println(ok)
 }
+
+// TestCLAllBlocks checks that the signature covers all the blocks (not only the last one).
+func TestCLAllBlocks(t *testing.T) {
+	cl := signatures.NewCL(2)
+	n := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(159)), nil)
+	m_Ls := []*big.Int{randomInt(n), randomInt(n)}
+
+	signature, err := cl.Sign(m_Ls)
+	assert.Nil(t, err, "CL signing should not produce an error")
+
+	pubCL := signatures.NewPubCL(cl.GetPubKey())
+	ok, _ := pubCL.Verify(m_Ls, signature)
+	assert.Equal(t, true, ok, "CL signature should be valid")
+	changed := []*big.Int{new(big.Int).Add(m_Ls[0], big.NewInt(1)), m_Ls[1]}
+	ok, _ = pubCL.Verify(changed, signature)
+	assert.Equal(t, false, ok, "CL signature should not be valid when the first block changes")
+}
+
+func TestCLUpdate(t *testing.T) {
+	cl := signatures.NewCL(2)
+	n := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(159)), nil)
+	m_Ls := []*big.Int{randomInt(n), randomInt(n)}
+
+	signature, err := cl.Sign(m_Ls)
+	assert.Nil(t, err, "CL signing should not produce an error")
+
+	// increment the second block (for example a counter) by 1
+	updatedSignature, updated, err := signatures.UpdateCLSignature(cl, m_Ls, signature, 1,
+		big.NewInt(1))
+	assert.Nil(t, err, "CL signature update should not produce an error")
+	assert.Equal(t, m_Ls[0], updated[0], "Blocks which were not updated should not change")
+	assert.Equal(t, new(big.Int).Add(m_Ls[1], big.NewInt(1)), updated[1],
+		"Updated block should be incremented")
+
+	pubCL := signatures.NewPubCL(cl.GetPubKey())
+	ok, _ := pubCL.Verify(updated, updatedSignature)
+	assert.Equal(t, true, ok, "Updated CL signature should be valid for updated blocks")
+	ok, _ = pubCL.Verify(m_Ls, updatedSignature)
+	assert.Equal(t, false, ok, "Updated CL signature should not be valid for old blocks")
+}
+
+// forgeCLSignature returns the signature with e = 1 on m_Ls, which anybody can compute
+// from the public key: v = c * a_1^m_1 * ... * a_L^m_L * b^s.
+func forgeCLSignature(pubKey *signatures.CLPubKey, m_Ls []*big.Int) *signatures.CLSignature {
+	n := pubKey.GetN()
+	s := randomInt(n)
+	v := new(big.Int).Exp(pubKey.GetB(), s, n)
+	v.Mul(v, pubKey.GetC())
+	for i, m := range m_Ls {
+		v.Mul(v, new(big.Int).Exp(pubKey.GetA()[i], m, n))
+		v.Mod(v, n)
+	}
+	return signatures.NewCLSignature(big.NewInt(1), s, v)
+}
+
+func TestCLForgedSignature(t *testing.T) {
+	cl := signatures.NewCL(2)
+	n := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(159)), nil)
+	m_Ls := []*big.Int{randomInt(n), randomInt(n)}
+	forged := forgeCLSignature(cl.GetPubKey(), m_Ls)
+
+	pubCL := signatures.NewPubCL(cl.GetPubKey())
+	ok, err := pubCL.Verify(m_Ls, forged)
+	assert.Nil(t, err)
+	assert.Equal(t, false, ok, "CL signature with e = 1 should not be valid")
+
+	_, _, err = signatures.UpdateCLSignature(cl, m_Ls, forged, 1, big.NewInt(1))
+	assert.NotNil(t, err, "issuer should not update CL signature with e = 1")
+}
+
+func TestCLMerkle(t *testing.T) {
+	attributes := []*big.Int{big.NewInt(1985), big.NewInt(1), big.NewInt(386), big.NewInt(7)}
+	committer := commitments.NewMerkleCommitter()
+	root, err := committer.GetCommitMsg(attributes)
+	assert.Nil(t, err, "Merkle commitment should not produce an error")
+
+	cl := signatures.NewMerkleCL()
+	signature, err := cl.SignMerkleRoot(root)
+	assert.Nil(t, err, "CL signing of Merkle root should not produce an error")
+
+	// reveal only the third attribute
+	decommitment, _ := committer.GetDecommitMsg(2)
+	pubCL := signatures.NewPubCL(cl.GetPubKey())
+	ok, err := pubCL.VerifyMerkleDisclosure(root, signature,
+		[]*commitments.MerkleDecommitment{decommitment})
+	assert.Nil(t, err, "Merkle disclosure verification should not produce an error")
+	assert.Equal(t, true, ok, "Merkle disclosure should be valid")
+
+	decommitment.Index = 3
+	ok, _ = pubCL.VerifyMerkleDisclosure(root, signature,
+		[]*commitments.MerkleDecommitment{decommitment})
+	assert.Equal(t, false, ok, "Merkle disclosure at wrong index should not be valid")
+}
+
+func TestCLAttributeEquality(t *testing.T) {
+	n := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(159)), nil)
+	// for example the hash of national ID contained in credentials from two issuers
+	idHash := randomInt(n)
+
+	cl1 := signatures.NewCL(2)
+	m_Ls1 := []*big.Int{randomInt(n), idHash}
+	signature1, err := cl1.Sign(m_Ls1)
+	assert.Nil(t, err, "CL signing should not produce an error")
+
+	cl2 := signatures.NewCL(3)
+	m_Ls2 := []*big.Int{idHash, randomInt(n), randomInt(n)}
+	signature2, err := cl2.Sign(m_Ls2)
+	assert.Nil(t, err, "CL signing should not produce an error")
+
+	proved, err := signatures.ProveCLAttributeEquality(cl1.GetPubKey(), m_Ls1, signature1, 1,
+		cl2.GetPubKey(), m_Ls2, signature2, 0)
+	assert.Nil(t, err, "CL attribute equality proof should not produce an error")
+	assert.Equal(t, true, proved, "CL attribute equality proof failed")
+
+	_, err = signatures.ProveCLAttributeEquality(cl1.GetPubKey(), m_Ls1, signature1, 0,
+		cl2.GetPubKey(), m_Ls2, signature2, 0)
+	assert.NotNil(t, err, "CL attribute equality proof should fail for different blocks")
+}
+
+func TestCLAttributeEqualityForgedSignature(t *testing.T) {
+	n := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(159)), nil)
+	idHash := randomInt(n)
+
+	cl1 := signatures.NewCL(2)
+	m_Ls1 := []*big.Int{randomInt(n), idHash}
+	signature1, err := cl1.Sign(m_Ls1)
+	assert.Nil(t, err, "CL signing should not produce an error")
+
+	cl2 := signatures.NewCL(2)
+	m_Ls2 := []*big.Int{idHash, randomInt(n)}
+	forged := forgeCLSignature(cl2.GetPubKey(), m_Ls2)
+
+	proved, err := signatures.ProveCLAttributeEquality(cl1.GetPubKey(), m_Ls1, signature1, 1,
+		cl2.GetPubKey(), m_Ls2, forged, 0)
+	assert.Nil(t, err, "CL attribute equality proof should not produce an error")
+	assert.Equal(t, false, proved, "CL attribute equality proof with e = 1 should fail")
+}
+
+func TestCLPossession(t *testing.T) {
+	n := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(159)), nil)
+	cl := signatures.NewCL(3)
+	m_Ls := []*big.Int{randomInt(n), big.NewInt(1990), randomInt(n)}
+	signature, err := cl.Sign(m_Ls)
+	assert.Nil(t, err, "CL signing should not produce an error")
+
+	// disclose only the second block (for example the year of birth)
+	proved, err := signatures.ProveCLPossession(cl.GetPubKey(), m_Ls, signature, []int{1})
+	assert.Nil(t, err, "CL possession proof should not produce an error")
+	assert.Equal(t, true, proved, "CL possession proof failed")
+	proved, err = signatures.ProveCLPossession(cl.GetPubKey(), m_Ls, signature, nil)
+	assert.Nil(t, err, "CL possession proof should not produce an error")
+	assert.Equal(t, true, proved, "CL possession proof without disclosure failed")
+
+	prover, err := signatures.NewCLPossessionProver(cl.GetPubKey(), m_Ls, signature, []int{1})
+	assert.Nil(t, err, "CL possession prover should be created")
+	verifier := signatures.NewCLPossessionVerifier(cl.GetPubKey(),
+		map[int]*big.Int{1: big.NewInt(1991)})
+	v, tt := prover.GetProofRandomData()
+	challenge, err := verifier.GetChallenge(v, tt)
+	assert.Nil(t, err)
+	zE, zS, zM := prover.GetProofData(challenge)
+	assert.Equal(t, 2, len(zM), "Responses should be sent only for hidden blocks")
+	assert.Equal(t, false, verifier.Verify(zE, zS, zM),
+		"CL possession proof with wrong disclosed block should fail")
+
+	_, err = signatures.NewCLPossessionProver(cl.GetPubKey(), m_Ls, signature, []int{3})
+	assert.NotNil(t, err, "CL possession prover should fail for wrong block index")
+}
+
+func TestCLPossessionForgedSignature(t *testing.T) {
+	n := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(159)), nil)
+	cl := signatures.NewCL(3)
+	m_Ls := []*big.Int{randomInt(n), big.NewInt(1990), randomInt(n)}
+	forged := forgeCLSignature(cl.GetPubKey(), m_Ls)
+
+	proved, err := signatures.ProveCLPossession(cl.GetPubKey(), m_Ls, forged, []int{1})
+	assert.Nil(t, err, "CL possession proof should not produce an error")
+	assert.Equal(t, false, proved, "CL possession proof with e = 1 should fail")
+}
+
+func TestCLPossessionSimulation(t *testing.T) {
+	cl := signatures.NewCL(3)
+	disclosed := map[int]*big.Int{1: big.NewInt(1990)}
+	challenge := randomInt(new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(128)), nil))
+	v, tt, zE, zS, zM, err := signatures.SimulateCLPossession(cl.GetPubKey(), disclosed,
+		challenge)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(zM), "Responses should be simulated only for hidden blocks")
+
+	// simulated proof is accepted for the challenge for which it was simulated only
+	verifier := signatures.NewCLPossessionVerifier(cl.GetPubKey(), disclosed)
+	verifier.SetChallenge(v, tt, challenge)
+	assert.Equal(t, true, verifier.Verify(zE, zS, zM), "Simulated proof should be accepted")
+	verifier.SetChallenge(v, tt, new(big.Int).Add(challenge, big.NewInt(1)))
+	assert.Equal(t, false, verifier.Verify(zE, zS, zM),
+		"Simulated proof should fail for another challenge")
+}
+
+func TestCLIssue(t *testing.T) {
+	n := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(159)), nil)
+	cl := signatures.NewCL(3)
+	masterSecret := randomInt(n)
+	hidden := map[int]*big.Int{0: masterSecret}
+	known := map[int]*big.Int{1: big.NewInt(1990), 2: big.NewInt(386)}
+
+	signature, m_Ls, err := signatures.IssueCLSignature(cl, hidden, known)
+	assert.Nil(t, err, "CL issuance should not produce an error")
+	assert.Equal(t, []*big.Int{masterSecret, big.NewInt(1990), big.NewInt(386)}, m_Ls)
+	ok, _ := signatures.NewPubCL(cl.GetPubKey()).Verify(m_Ls, signature)
+	assert.Equal(t, true, ok, "Issued CL signature should be valid")
+
+	// the issuer needs to know all the blocks which are not committed
+	_, _, err = signatures.IssueCLSignature(cl, hidden, map[int]*big.Int{1: big.NewInt(1990)})
+	assert.NotNil(t, err, "CL issuance should fail when a block is missing")
+	_, _, err = signatures.IssueCLSignature(cl, map[int]*big.Int{0: n, 1: big.NewInt(1)},
+		map[int]*big.Int{1: big.NewInt(1990), 2: big.NewInt(386)})
+	assert.NotNil(t, err, "CL issuance should fail when a block is both hidden and known")
+}
+
+func TestXMSS(t *testing.T) {
+	xmss, err := signatures.NewXMSS(2)
+	assert.Nil(t, err)
+	pubKey := xmss.GetPubKey()
+
+	msg := []byte("epoch 42")
+	for i := 0; i < 4; i++ {
+		sig, err := xmss.Sign(msg)
+		assert.Nil(t, err)
+		assert.Equal(t, uint32(i), sig.Index, "each signature should use a new one-time key")
+		assert.True(t, signatures.VerifyXMSS(pubKey, msg, sig), "signature should be verified")
+		assert.False(t, signatures.VerifyXMSS(pubKey, []byte("epoch 43"), sig),
+			"signature of another message should not be verified")
+	}
+	assert.Equal(t, 0, xmss.Remaining())
+	_, err = xmss.Sign(msg)
+	assert.NotNil(t, err, "signing should fail when all one-time keys are used")
+
+	other, _ := signatures.NewXMSS(2)
+	sig, _ := other.Sign(msg)
+	assert.False(t, signatures.VerifyXMSS(pubKey, msg, sig),
+		"signature with another key should not be verified")
+	sig.Index = 1
+	assert.False(t, signatures.VerifyXMSS(other.GetPubKey(), msg, sig),
+		"signature with changed index should not be verified")
+}
+
+func TestRSASignatureKnowledge(t *testing.T) {
+	rsa, err := signatures.NewRSA(1024)
+	if err != nil {
+		t.Fatalf("error when generating RSA key: %v", err)
+	}
+	pubKey := rsa.GetPubKey()
+	msg := []byte("message")
+	signature := rsa.Sign(msg)
+	assert.True(t, pubKey.Verify(msg, signature), "RSA signature should verify")
+	assert.False(t, pubKey.Verify([]byte("other"), signature), "RSA signature verified for other message")
+
+	proved, err := signatures.ProveRSASignatureKnowledge(pubKey, msg, signature)
+	assert.Nil(t, err)
+	assert.True(t, proved, "proof of knowledge of RSA signature should pass")
+
+	_, err = signatures.NewRSASignatureProver(pubKey, []byte("other"), signature)
+	assert.NotNil(t, err, "prover should not accept invalid signature")
+
+	// prover which does not know the signature
+	prover, _ := signatures.NewRSASignatureProver(pubKey, msg, signature)
+	verifier, _ := signatures.NewRSASignatureVerifier(pubKey, []byte("other"))
+	proofRandomData, _ := prover.GetProofRandomData()
+	challenges, err := verifier.GetChallenge(proofRandomData)
+	assert.Nil(t, err)
+	proofData, _ := prover.GetProofData(challenges)
+	assert.False(t, verifier.Verify(proofData), "proof for other message should fail")
+
+	evenKey := &signatures.RSAPubKey{N: pubKey.N, E: big.NewInt(65536)}
+	_, err = signatures.NewRSASignatureVerifier(evenKey, msg)
+	assert.NotNil(t, err, "verifier should not accept exponent which is not prime")
+}
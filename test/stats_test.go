@@ -0,0 +1,70 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/stats"
+	"testing"
+	"time"
+)
+
+func TestUsageStats(t *testing.T) {
+	_, err := stats.NewUsageStats(0, []string{"SCHNORR"})
+	assert.NotNil(t, err, "Epsilon 0 should not be accepted")
+
+	// with huge epsilon the noise is negligible
+	usage, err := stats.NewUsageStats(1e9, []string{"SCHNORR", "PEDERSEN"})
+	assert.Nil(t, err)
+	hour := time.Now().Truncate(time.Hour).Add(-3 * time.Hour)
+	for i := 0; i < 3; i++ {
+		usage.Record("SCHNORR", hour.Add(time.Minute))
+	}
+	usage.Record("PEDERSEN", hour.Add(2*time.Hour))
+
+	report, err := usage.Report(hour.Add(30 * time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(report), "Current hour should not be reported")
+
+	report, err = usage.Report(hour.Add(3 * time.Hour))
+	assert.Nil(t, err)
+	if len(report) != 6 {
+		t.Fatalf("Counts of both schemas for 3 hours expected, got %d", len(report))
+	}
+	assert.Equal(t, "PEDERSEN", report[0].Schema)
+	assert.Equal(t, int64(0), report[0].Count, "Unused schema should be reported")
+	assert.Equal(t, int64(3), report[1].Count)
+	assert.Equal(t, hour.Unix(), report[1].Hour.Unix())
+	assert.Equal(t, int64(0), report[2].Count+report[3].Count, "Hour without sessions")
+	assert.Equal(t, int64(1), report[4].Count)
+
+	usage.Record("SCHNORR", hour.Add(time.Minute))
+	again, err := usage.Report(hour.Add(3 * time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, report, again, "Released counts should not change")
+
+	// with small epsilon the counts are noisy, but the noise is drawn only once
+	usage, err = stats.NewUsageStats(0.01, []string{"SCHNORR"})
+	assert.Nil(t, err)
+	usage.Record("SCHNORR", hour)
+	first, err := usage.Report(hour.Add(time.Hour))
+	assert.Nil(t, err)
+	second, err := usage.Report(hour.Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, first[0].Count, second[0].Count, "Noise should not be drawn again")
+}
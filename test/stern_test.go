@@ -0,0 +1,64 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/stern"
+	"testing"
+)
+
+func TestStern(t *testing.T) {
+	params, err := stern.NewParams()
+	if err != nil {
+		t.Fatalf("error when generating Stern parameters: %v", err)
+	}
+	secret, publicKey, err := params.GenerateKey()
+	if err != nil {
+		t.Fatalf("error when generating Stern key: %v", err)
+	}
+
+	proved, err := stern.ProveStern(params, secret)
+	assert.Nil(t, err)
+	assert.True(t, proved, "Stern identification should pass")
+
+	// prover with a secret which does not match the public key
+	other, _, _ := params.GenerateKey()
+	prover, _ := stern.NewSternProver(params, other)
+	verifier := stern.NewSternVerifier(params, publicKey)
+	commitments, _ := prover.GetProofRandomData()
+	challenges, _ := verifier.GetChallenges(commitments)
+	responses, _ := prover.GetProofData(challenges)
+	assert.False(t, verifier.Verify(responses), "Stern identification with wrong secret should fail")
+
+	_, err = stern.NewSternProver(params, make([]byte, stern.N/8))
+	assert.NotNil(t, err, "prover should not accept secret of wrong weight")
+}
+
+func TestGRPC_Stern(t *testing.T) {
+	params, _ := stern.NewParams()
+	secret, _, _ := params.GenerateKey()
+	c, err := client.NewSternClient(testGrpcClientConn, params, secret)
+	if err != nil {
+		t.Fatalf("error when creating Stern client: %v", err)
+	}
+	success, err := c.Run()
+	assert.Nil(t, err, "should finish without errors")
+	assert.True(t, success, "Stern identification should pass")
+}
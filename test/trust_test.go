@@ -0,0 +1,201 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/trust"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestTrustRegistry(version uint64, validity time.Duration) *trust.Registry {
+	now := time.Now()
+	return &trust.Registry{
+		Version:    version,
+		IssuedAt:   now.Unix(),
+		NextUpdate: now.Add(validity / 2).Unix(),
+		Expires:    now.Add(validity).Unix(),
+		Issuers: []*trust.Issuer{
+			{
+				ID:            "gov",
+				Name:          "Government",
+				Endpoint:      "emmy.gov.example:7007",
+				RevocationURL: "https://emmy.gov.example/revocation",
+				Keys:          []*trust.Key{{ID: "2017", Type: "cl", Value: []byte{1, 2, 3}}},
+				Schemas: []*trust.Schema{
+					{ID: "identity", Attributes: []string{"name", "age"}},
+				},
+			},
+		},
+	}
+}
+
+func TestTrustRegistryBundle(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := trust.NewSigner("anchor", key.D, key.X, key.Y)
+	anchors := trust.Anchors{}
+	anchors.Add("anchor", key.X, key.Y)
+
+	bundle, err := signer.Sign(newTestTrustRegistry(1, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := bundle.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := trust.ParseBundle(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := parsed.Verify(anchors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := registry.Issuer("gov")
+	assert.NotNil(t, issuer, "Issuer is missing from the registry")
+	assert.Equal(t, "emmy.gov.example:7007", issuer.Endpoint, "Endpoint of the issuer is wrong")
+	assert.Equal(t, []byte{1, 2, 3}, issuer.Key("2017").Value, "Key of the issuer is wrong")
+	assert.Equal(t, []*trust.Issuer{issuer}, registry.IssuersOf("identity"),
+		"Issuers of the schema are wrong")
+	assert.Empty(t, registry.IssuersOf("diploma"), "Schema should have no issuers")
+
+	parsed.Registry[len(parsed.Registry)-2] ^= 1
+	_, err = parsed.Verify(anchors)
+	assert.NotNil(t, err, "Modified registry should be rejected")
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherAnchors := trust.Anchors{}
+	otherAnchors.Add("anchor", otherKey.X, otherKey.Y)
+	_, err = bundle.Verify(otherAnchors)
+	assert.NotNil(t, err, "Registry signed by another key should be rejected")
+
+	duplicate := newTestTrustRegistry(2, time.Hour)
+	duplicate.Issuers = append(duplicate.Issuers, duplicate.Issuers[0])
+	_, err = signer.Sign(duplicate)
+	assert.NotNil(t, err, "Registry with duplicate issuers should not be signed")
+}
+
+func TestTrustRegistryStore(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := trust.NewSigner("anchor", key.D, key.X, key.Y)
+	anchors := trust.Anchors{}
+	anchors.Add("anchor", key.X, key.Y)
+
+	var mutex sync.Mutex
+	var published []byte
+	fetches := 0
+	publish := func(registry *trust.Registry) {
+		bundle, err := signer.Sign(registry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := bundle.Marshal()
+		mutex.Lock()
+		published = data
+		mutex.Unlock()
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		fetches++
+		w.Write(published)
+	}))
+	defer srv.Close()
+	fetched := func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return fetches
+	}
+
+	dir, err := ioutil.TempDir("", "emmy-trust")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cache := filepath.Join(dir, "registry.json")
+
+	publish(newTestTrustRegistry(2, time.Hour))
+	store := trust.NewStore(srv.URL, anchors)
+	assert.Nil(t, store.SetCache(cache), "Setting the cache failed")
+	issuer, err := store.Issuer("gov")
+	assert.Nil(t, err, "Issuer should be found in the fetched registry")
+	assert.Equal(t, "gov", issuer.ID, "Wrong issuer returned")
+	_, err = store.Issuer("unknown")
+	assert.NotNil(t, err, "Unknown issuer should not be trusted")
+	assert.Equal(t, 1, fetched(), "Fresh registry should not be fetched again")
+
+	// a new issuer is discovered after the refresh
+	updated := newTestTrustRegistry(3, time.Hour)
+	updated.Issuers = append(updated.Issuers, &trust.Issuer{ID: "university",
+		Endpoint: "emmy.uni.example:7007"})
+	publish(updated)
+	assert.Nil(t, store.Refresh(), "Refreshing the registry failed")
+	_, err = store.Issuer("university")
+	assert.Nil(t, err, "New issuer should be discovered")
+
+	// rollback to an older version and a different registry with the same version
+	publish(newTestTrustRegistry(2, time.Hour))
+	assert.NotNil(t, store.Refresh(), "Older registry should be rejected")
+	publish(newTestTrustRegistry(3, time.Hour))
+	assert.NotNil(t, store.Refresh(), "Different registry with the same version should be rejected")
+	_, err = store.Issuer("university")
+	assert.Nil(t, err, "Registry should be kept after a rejected refresh")
+
+	// the cached registry is available without the trust anchor
+	offline := trust.NewStore("http://127.0.0.1:1/registry", anchors)
+	assert.Nil(t, offline.SetCache(cache), "Loading the cache failed")
+	_, err = offline.Issuer("university")
+	assert.Nil(t, err, "Cached registry should be used while it is not expired")
+
+	// a stale registry is refreshed on each use, an expired one is not accepted
+	due := newTestTrustRegistry(4, time.Hour)
+	due.NextUpdate = due.IssuedAt
+	publish(due)
+	assert.Nil(t, store.Refresh(), "Refreshing the registry failed")
+	mutex.Lock()
+	fetches = 0
+	mutex.Unlock()
+	_, err = store.Registry()
+	assert.Nil(t, err, "Stale registry should be refreshed")
+	_, err = store.Registry()
+	assert.Nil(t, err, "Stale registry should be refreshed")
+	assert.Equal(t, 2, fetched(), "Stale registry should be fetched on each use")
+
+	expired := trust.NewStore(srv.URL, anchors)
+	publish(newTestTrustRegistry(5, 0))
+	assert.NotNil(t, expired.Refresh(), "Expired registry should be rejected")
+	_, err = expired.Registry()
+	assert.NotNil(t, err, "There should be no registry when only the expired one is available")
+}
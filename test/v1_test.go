@@ -0,0 +1,92 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/v1"
+	"testing"
+	"time"
+)
+
+func TestV1Credentials(t *testing.T) {
+	params := config.LoadPseudonymsysParams()
+	id, err := v1.NewIdentity(params)
+	if err != nil {
+		t.Fatalf("error when creating identity: %v", err)
+	}
+
+	cert, err := id.ObtainCertificate(testGrpcClientConn)
+	assert.Nil(t, err, "should obtain CA certificate")
+	nym1, err := id.RegisterNym(testGrpcClientConn, cert)
+	assert.Nil(t, err, "should register nym")
+
+	h1, h2 := config.LoadPseudonymsysOrgPubKeys("org1")
+	credential, err := id.ObtainCredential(testGrpcClientConn, nym1, v1.NewOrgPubKeys(h1, h2))
+	assert.Nil(t, err, "should obtain credential")
+
+	cert, _ = id.ObtainCertificate(testGrpcClientConn)
+	nym2, _ := id.RegisterNym(testGrpcClientConn, cert)
+	key, err := id.ShowCredential(testGrpcClientConn, "org1", nym2, credential)
+	assert.Nil(t, err, "should show credential")
+	assert.NotEmpty(t, key, "should obtain session key")
+
+	other := v1.NewIdentityFromSecret(params, randomInt(params.Group.Q))
+	_, err = other.ShowCredential(testGrpcClientConn, "org1", nym2, credential)
+	assert.NotNil(t, err, "credential should not be shown by another identity")
+}
+
+func TestV1Proofs(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	secret := randomInt(group.Q)
+	y := group.Exp(group.G, secret)
+	other := group.Exp(group.G, randomInt(group.Q))
+
+	proof, err := v1.Prove(v1.Or(v1.DLog(group, group.G, other, nil),
+		v1.DLog(group, group.G, y, secret)), []byte("v1"))
+	assert.Nil(t, err)
+	statement := v1.Or(v1.DLog(group, group.G, other, nil), v1.DLog(group, group.G, y, nil))
+	assert.True(t, v1.Verify(statement, proof, []byte("v1")), "proof should be verified")
+	encoded, _ := proof.Marshal()
+	assert.True(t, v1.VerifyEncoded(statement, encoded, []byte("v1")))
+
+	_, err = v1.Prove(statement, nil)
+	assert.NotNil(t, err, "statement without secrets cannot be proved")
+}
+
+func TestV1Envelope(t *testing.T) {
+	group := config.LoadGroup("pseudonymsys")
+	secret := randomInt(group.Q)
+	y := group.Exp(group.G, secret)
+
+	envelope, err := v1.ProveEnvelope(v1.DLog(group, group.G, y, secret), "verifier",
+		time.Minute, nil, nil)
+	assert.Nil(t, err)
+	policy := &v1.EnvelopePolicy{
+		Audience: "verifier",
+		Nonces:   v1.NewNonceCache(),
+	}
+	statement := v1.DLog(group, group.G, y, nil)
+	assert.Nil(t, v1.VerifyEnvelope(statement, envelope, policy))
+	assert.NotNil(t, v1.VerifyEnvelope(statement, envelope, policy),
+		"envelope should not be replayed")
+
+	_, err = v1.ProveEnvelope(statement, "verifier", time.Minute, nil, nil)
+	assert.NotNil(t, err, "statement without secrets cannot be proved")
+}
@@ -0,0 +1,264 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package trust implements the trust registry - the list of emmy issuers (their keys,
+// credential schemas, endpoints and revocation URLs) which is published by the operator of
+// the ecosystem (the trust anchor) as a signed JSON bundle.
+//
+// Wallets fetch the bundle with Store, check the signature against the keys of the trust
+// anchors which they were configured with and use the registry to discover the issuers
+// which they did not know about before. The bundle is refreshed when its next update is due,
+// an older version than the one already seen is never accepted (rollback) and the issuers
+// are not trusted anymore when the bundle expires and cannot be refreshed.
+package trust
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/dlog"
+	"math/big"
+	"time"
+)
+
+// Registry is the content of the trust registry at the given version. Times are Unix
+// seconds: wallets should refresh the registry after NextUpdate and must not use it
+// after Expires.
+type Registry struct {
+	Version    uint64    `json:"version"`
+	IssuedAt   int64     `json:"issued_at"`
+	NextUpdate int64     `json:"next_update"`
+	Expires    int64     `json:"expires"`
+	Issuers    []*Issuer `json:"issuers"`
+}
+
+// Issuer describes an emmy issuer. Endpoint is the address of its emmy server
+// (host:port) and RevocationURL is where its revocation snapshots are published (if any).
+type Issuer struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name,omitempty"`
+	Endpoint      string    `json:"endpoint"`
+	RevocationURL string    `json:"revocation_url,omitempty"`
+	Keys          []*Key    `json:"keys"`
+	Schemas       []*Schema `json:"schemas,omitempty"`
+}
+
+// Key is a public key of the issuer. Type names the scheme (for example cl, bbs+ or
+// ecdsa-p256) and Value is the encoding of the key defined by the scheme.
+type Key struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Value []byte `json:"value"`
+}
+
+// Schema is a credential schema - the list of attributes of the credentials which
+// the issuer issues under the schema.
+type Schema struct {
+	ID         string   `json:"id"`
+	Attributes []string `json:"attributes"`
+}
+
+// Bundle is the signed trust registry as it is published. Registry is the JSON encoding
+// of the registry exactly as it was signed by the trust anchor with key KeyID.
+type Bundle struct {
+	Registry []byte   `json:"registry"`
+	KeyID    string   `json:"key_id"`
+	R        *big.Int `json:"r"`
+	S        *big.Int `json:"s"`
+}
+
+// Anchors are the ECDSA (P256) public keys of the trust anchors by key ID.
+type Anchors map[string]*ecdsa.PublicKey
+
+// Add adds the public key (x, y) of the trust anchor with the given key ID.
+func (anchors Anchors) Add(keyID string, x, y *big.Int) {
+	anchors[keyID] = &ecdsa.PublicKey{Curve: dlog.GetEllipticCurve(dlog.P256), X: x, Y: y}
+}
+
+// Signer signs the trust registry on behalf of the trust anchor.
+type Signer struct {
+	keyID      string
+	privateKey *ecdsa.PrivateKey
+}
+
+// NewSigner returns a signer which signs the registry with ECDSA (P256) key d, identified
+// by keyID in the bundles.
+func NewSigner(keyID string, d, x, y *big.Int) *Signer {
+	pubKey := ecdsa.PublicKey{Curve: dlog.GetEllipticCurve(dlog.P256), X: x, Y: y}
+	return &Signer{
+		keyID:      keyID,
+		privateKey: &ecdsa.PrivateKey{PublicKey: pubKey, D: d},
+	}
+}
+
+// Sign checks the registry and returns the signed bundle.
+func (signer *Signer) Sign(registry *Registry) (*Bundle, error) {
+	if err := registry.check(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(registry)
+	if err != nil {
+		return nil, err
+	}
+	bundle := &Bundle{
+		Registry: data,
+		KeyID:    signer.keyID,
+	}
+	bundle.R, bundle.S, err = ecdsa.Sign(rand.Reader, signer.privateKey, bundle.hash())
+	if err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// Marshal returns the JSON encoding of the bundle which is published by the trust anchor.
+func (bundle *Bundle) Marshal() ([]byte, error) {
+	return json.Marshal(bundle)
+}
+
+// ParseBundle decodes the bundle from JSON.
+func ParseBundle(data []byte) (*Bundle, error) {
+	bundle := &Bundle{}
+	if err := json.Unmarshal(data, bundle); err != nil {
+		return nil, fmt.Errorf("invalid trust registry bundle: %v", err)
+	}
+	return bundle, nil
+}
+
+// Verify checks that the bundle was signed by one of the trust anchors and returns
+// the registry. It does not check whether the registry is expired.
+func (bundle *Bundle) Verify(anchors Anchors) (*Registry, error) {
+	pubKey, ok := anchors[bundle.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("trust registry signed by unknown key %s", bundle.KeyID)
+	}
+	if bundle.R == nil || bundle.S == nil ||
+		!ecdsa.Verify(pubKey, bundle.hash(), bundle.R, bundle.S) {
+		return nil, fmt.Errorf("invalid signature of the trust registry")
+	}
+
+	registry := &Registry{}
+	if err := json.Unmarshal(bundle.Registry, registry); err != nil {
+		return nil, fmt.Errorf("invalid trust registry: %v", err)
+	}
+	if err := registry.check(); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// hash binds the signature to the encoded registry and to the key which signed it.
+func (bundle *Bundle) hash() []byte {
+	h := sha512.New()
+	h.Write([]byte("emmy/trust"))
+	for _, b := range [][]byte{[]byte(bundle.KeyID), bundle.Registry} {
+		l := make([]byte, 8)
+		binary.BigEndian.PutUint64(l, uint64(len(b)))
+		h.Write(l)
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// IsExpired returns true if the registry must not be used anymore at time t.
+func (registry *Registry) IsExpired(t time.Time) bool {
+	return t.Unix() >= registry.Expires
+}
+
+// IsStale returns true if the registry should be refreshed at time t.
+func (registry *Registry) IsStale(t time.Time) bool {
+	return t.Unix() >= registry.NextUpdate
+}
+
+// Issuer returns the issuer with the given ID or nil if it is not in the registry.
+func (registry *Registry) Issuer(id string) *Issuer {
+	for _, issuer := range registry.Issuers {
+		if issuer.ID == id {
+			return issuer
+		}
+	}
+	return nil
+}
+
+// IssuersOf returns the issuers which issue credentials under the given schema.
+func (registry *Registry) IssuersOf(schemaID string) []*Issuer {
+	var issuers []*Issuer
+	for _, issuer := range registry.Issuers {
+		if issuer.Schema(schemaID) != nil {
+			issuers = append(issuers, issuer)
+		}
+	}
+	return issuers
+}
+
+// Key returns the key of the issuer with the given ID or nil if there is no such key.
+func (issuer *Issuer) Key(id string) *Key {
+	for _, key := range issuer.Keys {
+		if key.ID == id {
+			return key
+		}
+	}
+	return nil
+}
+
+// Schema returns the schema of the issuer with the given ID or nil if there is no
+// such schema.
+func (issuer *Issuer) Schema(id string) *Schema {
+	for _, schema := range issuer.Schemas {
+		if schema.ID == id {
+			return schema
+		}
+	}
+	return nil
+}
+
+// check rejects registries which are ambiguous - without issuer IDs, with duplicate IDs
+// of issuers or their keys and schemas - or whose validity period is inconsistent.
+func (registry *Registry) check() error {
+	if registry.NextUpdate < registry.IssuedAt || registry.Expires < registry.NextUpdate {
+		return fmt.Errorf("trust registry should be issued before its next update and expiry")
+	}
+	issuers := make(map[string]bool)
+	for _, issuer := range registry.Issuers {
+		if issuer == nil || issuer.ID == "" {
+			return fmt.Errorf("trust registry contains an issuer without ID")
+		}
+		if issuers[issuer.ID] {
+			return fmt.Errorf("trust registry contains issuer %s more than once", issuer.ID)
+		}
+		issuers[issuer.ID] = true
+
+		keys := make(map[string]bool)
+		for _, key := range issuer.Keys {
+			if key == nil || keys[key.ID] {
+				return fmt.Errorf("issuer %s has missing or duplicate keys", issuer.ID)
+			}
+			keys[key.ID] = true
+		}
+		schemas := make(map[string]bool)
+		for _, schema := range issuer.Schemas {
+			if schema == nil || schemas[schema.ID] {
+				return fmt.Errorf("issuer %s has missing or duplicate schemas", issuer.ID)
+			}
+			schemas[schema.ID] = true
+		}
+	}
+	return nil
+}
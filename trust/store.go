@@ -0,0 +1,165 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package trust
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/xlab-si/emmy/storage"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// MaxBundleSize is the maximal size (in bytes) of the bundle which is fetched.
+const MaxBundleSize = 4 << 20
+
+// Store keeps the trust registry of a wallet. It fetches the bundle from the URL where
+// the trust anchor publishes it and refreshes it when its next update is due.
+type Store struct {
+	URL     string
+	Anchors Anchors
+	Client  *http.Client
+
+	mutex     sync.Mutex
+	cachePath string
+	registry  *Registry
+	bundle    []byte
+}
+
+// NewStore returns a store which fetches the bundle from the given URL and trusts
+// the bundles signed by the given anchors.
+func NewStore(url string, anchors Anchors) *Store {
+	return &Store{
+		URL:     url,
+		Anchors: anchors,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SetCache sets the file where the last accepted bundle is kept, so that the registry is
+// available (until it expires) when the wallet starts without the connection to the trust
+// anchor. The bundle in the file, if it exists, is loaded.
+func (s *Store) SetCache(path string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.cachePath = path
+	data, err := storage.Load(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return s.accept(data, false)
+}
+
+// Refresh fetches the bundle and accepts it if it is signed by one of the anchors,
+// not expired and not older than the registry which the store already has.
+func (s *Store) Refresh() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.refresh()
+}
+
+// Registry returns the current registry, refreshing it first if it is stale. When the
+// refresh fails, the current registry is returned until it expires.
+func (s *Store) Registry() (*Registry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	if s.registry == nil || s.registry.IsStale(now) {
+		if err := s.refresh(); err != nil && (s.registry == nil || s.registry.IsExpired(now)) {
+			return nil, err
+		}
+	}
+	return s.registry, nil
+}
+
+// Issuer returns the trusted issuer with the given ID.
+func (s *Store) Issuer(id string) (*Issuer, error) {
+	registry, err := s.Registry()
+	if err != nil {
+		return nil, err
+	}
+	issuer := registry.Issuer(id)
+	if issuer == nil {
+		return nil, fmt.Errorf("issuer %s is not in the trust registry", id)
+	}
+	return issuer, nil
+}
+
+func (s *Store) refresh() error {
+	resp, err := s.Client.Get(s.URL)
+	if err != nil {
+		return fmt.Errorf("error fetching trust registry: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error fetching trust registry: %s", resp.Status)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxBundleSize+1))
+	if err != nil {
+		return fmt.Errorf("error fetching trust registry: %v", err)
+	}
+	if len(data) > MaxBundleSize {
+		return fmt.Errorf("trust registry bundle is larger than %d bytes", MaxBundleSize)
+	}
+	return s.accept(data, true)
+}
+
+// accept verifies the bundle and replaces the current registry with it. The same version
+// is accepted only if it is the same registry - the anchor should not sign different
+// registries with the same version.
+func (s *Store) accept(data []byte, cache bool) error {
+	bundle, err := ParseBundle(data)
+	if err != nil {
+		return err
+	}
+	registry, err := bundle.Verify(s.Anchors)
+	if err != nil {
+		return err
+	}
+	if registry.IsExpired(time.Now()) {
+		return fmt.Errorf("trust registry version %d is expired", registry.Version)
+	}
+	if s.registry != nil {
+		if registry.Version < s.registry.Version {
+			return fmt.Errorf("trust registry version %d is older than version %d",
+				registry.Version, s.registry.Version)
+		}
+		if registry.Version == s.registry.Version {
+			current, _ := ParseBundle(s.bundle)
+			if !bytes.Equal(bundle.Registry, current.Registry) {
+				return fmt.Errorf("trust registry version %d was signed with different content",
+					registry.Version)
+			}
+		}
+	}
+
+	s.registry = registry
+	s.bundle = data
+	if cache && s.cachePath != "" {
+		return storage.Store(data, s.cachePath)
+	}
+	return nil
+}
@@ -28,6 +28,10 @@ const (
 	Sigma ProtocolType = iota + 1 // SigmaProtocol
 	ZKP                           //ZeroKnowledgeProof
 	ZKPOK                         //ZeroKnowledgeProofOfKnowledge
+	// DesignatedVerifier is a proof which convinces only the intended verifier: the challenge
+	// is committed with the verifier's public key, thus the verifier could produce
+	// the transcript by itself and the transcript cannot be transferred to anybody else.
+	DesignatedVerifier
 )
 
 type ECGroupElement struct {
@@ -39,6 +43,26 @@ func NewECGroupElement(x, y *big.Int) *ECGroupElement {
 	return &ECGroupElement{X: x, Y: y}
 }
 
+// NewECGroupElementInfinity returns the point at infinity (the identity element). As in
+// crypto/elliptic, it is represented by coordinates (0, 0), which are not on the curve.
+func NewECGroupElementInfinity() *ECGroupElement {
+	return &ECGroupElement{X: big.NewInt(0), Y: big.NewInt(0)}
+}
+
+// IsInfinity returns true if el is the point at infinity.
+func (el *ECGroupElement) IsInfinity() bool {
+	return el.X != nil && el.Y != nil && el.X.Sign() == 0 && el.Y.Sign() == 0
+}
+
+// Equals returns true if el and other are the same point.
+func (el *ECGroupElement) Equals(other *ECGroupElement) bool {
+	if el == nil || other == nil || el.X == nil || el.Y == nil || other.X == nil ||
+		other.Y == nil {
+		return false
+	}
+	return el.X.Cmp(other.X) == 0 && el.Y.Cmp(other.Y) == 0
+}
+
 func ToECGroupElement(el *pb.ECGroupElement) *ECGroupElement {
 	x := ECGroupElement{X: new(big.Int).SetBytes(el.X), Y: new(big.Int).SetBytes(el.Y)}
 	return &x
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"github.com/xlab-si/emmy/client"
+)
+
+// ObtainCredential authenticates with nym to the organization and obtains its credential.
+func (id *Identity) ObtainCredential(conn *Conn, nym *Nym, orgPubKeys *OrgPubKeys,
+	opts ...Option) (*Credential, error) {
+	c, err := client.NewPseudonymsysClient(conn, id.params, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c.ObtainCredential(id.secret, nym, orgPubKeys)
+}
+
+// ShowCredential shows the credential issued by orgName to another organization, where
+// the identity is known under nym. It returns the session key of the authenticated session.
+func (id *Identity) ShowCredential(conn *Conn, orgName string, nym *Nym, credential *Credential,
+	opts ...Option) (string, error) {
+	c, err := client.NewPseudonymsysClient(conn, id.params, opts...)
+	if err != nil {
+		return "", err
+	}
+	key, err := c.TransferCredential(orgName, id.secret, nym, credential)
+	if err != nil {
+		return "", err
+	}
+	return key.GetValue(), nil
+}
@@ -0,0 +1,35 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package v1 is the stable API of emmy. It exposes the entry points which applications
+// need - identities of the pseudonym system (Identity), their credentials, non-interactive
+// proofs (Prove, Verify and the protocols to be proved) and the server - while the packages
+// under crypto, client and server remain free to change as the library evolves.
+//
+// The API follows semantic versioning: within the v1 line exported identifiers are not
+// removed and their behaviour does not change in incompatible ways, new functionality is
+// added in minor versions (see Version). When internal packages are moved or renamed, their
+// old import paths are kept as thin forwarding packages marked as Deprecated for at least
+// one minor version, so code importing them keeps compiling while it migrates to this
+// package.
+//
+// Types which are shared with the internal packages are declared as aliases, thus values
+// can be passed between v1 and the internal packages without conversion.
+package v1
+
+// Version is the semantic version of the API of this package.
+const Version = "1.0.0"
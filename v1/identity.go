@@ -0,0 +1,113 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/zkp/schemes/pseudonymsys"
+	"google.golang.org/grpc"
+	"math/big"
+)
+
+type (
+	// Params are the public parameters of the pseudonym system.
+	Params = pseudonymsys.Params
+	// Nym is a pseudonym under which the identity is known to an organization.
+	Nym = pseudonymsys.Pseudonym
+	// Certificate is issued by CA and is needed to register nyms.
+	Certificate = pseudonymsys.CACertificate
+	// Credential is issued by an organization to the holder of a nym.
+	Credential = pseudonymsys.Credential
+	// OrgPubKeys are the public keys with which an organization issues credentials.
+	OrgPubKeys = pseudonymsys.OrgPubKeys
+	// Conn is the connection to emmy server (CA or organization).
+	Conn = grpc.ClientConn
+	// Option configures the communication with the server (see package client).
+	Option = client.ClientOption
+)
+
+// Connect opens the connection to emmy server at endpoint. CaCert is the path to
+// the certificate of the server's CA, insecure skips the verification of the server's
+// hostname.
+func Connect(endpoint, caCert string, insecure bool) (*Conn, error) {
+	return client.GetConnection(endpoint, caCert, insecure)
+}
+
+// NewOrgPubKeys returns the public keys of an organization.
+func NewOrgPubKeys(h1, h2 *big.Int) *OrgPubKeys {
+	return pseudonymsys.NewOrgPubKeys(h1, h2)
+}
+
+// Identity is a user of the pseudonym system - it holds the master secret key from which
+// all the user's nyms are derived.
+type Identity struct {
+	params *Params
+	secret *big.Int
+}
+
+// NewIdentity generates a new master secret key.
+func NewIdentity(params *Params) (*Identity, error) {
+	if err := params.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pseudonymsys parameters: %v", err)
+	}
+	secret, err := common.GetRandomInt(params.Group.Q)
+	if err != nil {
+		return nil, err
+	}
+	return NewIdentityFromSecret(params, secret), nil
+}
+
+// NewIdentityFromSecret returns the identity with the given (stored) master secret key.
+func NewIdentityFromSecret(params *Params, secret *big.Int) *Identity {
+	return &Identity{
+		params: params,
+		secret: secret,
+	}
+}
+
+// Secret returns the master secret key, which needs to be stored securely.
+func (id *Identity) Secret() *big.Int {
+	return id.secret
+}
+
+// MasterNym returns (g, g^secret) - the nym which is known to CA.
+func (id *Identity) MasterNym() *Nym {
+	group := id.params.Group
+	return pseudonymsys.NewPseudonym(group.G, group.Exp(group.G, id.secret))
+}
+
+// ObtainCertificate proves the knowledge of the master secret key to CA and obtains
+// the certificate. A certificate is to be used for registering only one nym.
+func (id *Identity) ObtainCertificate(conn *Conn, opts ...Option) (*Certificate, error) {
+	c, err := client.NewPseudonymsysCAClient(conn, id.params, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c.ObtainCertificate(id.secret, id.MasterNym())
+}
+
+// RegisterNym generates a new nym and registers it with the organization.
+func (id *Identity) RegisterNym(conn *Conn, cert *Certificate, opts ...Option) (*Nym, error) {
+	c, err := client.NewPseudonymsysClient(conn, id.params, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c.GenerateNym(id.secret, cert)
+}
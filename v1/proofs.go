@@ -0,0 +1,110 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+	"github.com/xlab-si/emmy/crypto/groups"
+	"github.com/xlab-si/emmy/crypto/zkp/primitives/fiatshamir"
+	"github.com/xlab-si/emmy/crypto/zkp/sigma"
+	"math/big"
+	"time"
+)
+
+type (
+	// Group is the Schnorr group in which the proofs are computed.
+	Group = groups.SchnorrGroup
+	// Statement is a composable sigma protocol for a fixed statement - the prover's statements
+	// contain the secrets, the verifier's do not.
+	Statement = sigma.Protocol
+	// Proof is a non-interactive proof of a statement.
+	Proof = fiatshamir.Proof
+	// Envelope is a non-interactive proof together with its issue time, expiry, audience
+	// and nonce.
+	Envelope = fiatshamir.Envelope
+	// EnvelopePolicy contains the checks of the envelope metadata performed by VerifyEnvelope.
+	EnvelopePolicy = fiatshamir.EnvelopePolicy
+	// NonceCache records the nonces of the accepted envelopes to detect replays.
+	NonceCache = fiatshamir.NonceCache
+)
+
+// DLog is the statement that secret is the dlog of y with respect to g.
+func DLog(group *Group, g, y, secret *big.Int) Statement {
+	return sigma.NewDLog(group, g, y, secret)
+}
+
+// Representation is the statement that y = bases[0]^secrets[0] * ... * bases[n-1]^secrets[n-1]
+// (for example the opening of Pedersen commitment).
+func Representation(group *Group, bases []*big.Int, y *big.Int, secrets []*big.Int) Statement {
+	return sigma.NewRepresentation(group, bases, y, secrets)
+}
+
+// DLogEquality is the statement that log_g1(t1) = log_g2(t2) = secret.
+func DLogEquality(group *Group, g1, t1, g2, t2, secret *big.Int) Statement {
+	return sigma.NewDLogEquality(group, g1, t1, g2, t2, secret)
+}
+
+// And is the statement that all the statements hold.
+func And(statements ...Statement) Statement {
+	return sigma.And(statements...)
+}
+
+// Or is the statement that at least one of the statements holds.
+func Or(statements ...Statement) Statement {
+	return sigma.Or(statements...)
+}
+
+// Prove returns the non-interactive proof of the statement, bound to context (for example
+// the verifier's name and a nonce).
+func Prove(statement Statement, context []byte) (*Proof, error) {
+	if !statement.HasWitness() {
+		return nil, fmt.Errorf("secrets for the statement are not known")
+	}
+	return fiatshamir.Prove(statement, context)
+}
+
+// Verify checks the proof of the statement which was produced in the given context.
+func Verify(statement Statement, proof *Proof, context []byte) bool {
+	return fiatshamir.Verify(statement, proof, context)
+}
+
+// VerifyEncoded checks the proof encoded with Proof.Marshal.
+func VerifyEncoded(statement Statement, proof, context []byte) bool {
+	return fiatshamir.VerifyEncoded(statement, proof, context)
+}
+
+// ProveEnvelope returns the proof of the statement in an envelope for the given audience,
+// valid for ttl. When nonce is nil, a random one is generated.
+func ProveEnvelope(statement Statement, audience string, ttl time.Duration, nonce,
+	context []byte) (*Envelope, error) {
+	if !statement.HasWitness() {
+		return nil, fmt.Errorf("secrets for the statement are not known")
+	}
+	return fiatshamir.NewEnvelope(statement, audience, ttl, nonce, context)
+}
+
+// VerifyEnvelope checks the envelope metadata against the policy and the enveloped proof
+// of the statement, it returns nil if the envelope is accepted.
+func VerifyEnvelope(statement Statement, envelope *Envelope, policy *EnvelopePolicy) error {
+	return fiatshamir.VerifyEnvelope(statement, envelope, policy)
+}
+
+// NewNonceCache returns an empty cache of nonces for EnvelopePolicy.
+func NewNonceCache() *NonceCache {
+	return fiatshamir.NewNonceCache()
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+	"google.golang.org/grpc"
+)
+
+// Server is emmy server, which acts as CA and organization of the pseudonym system and
+// as the verifier of the proofs offered by emmy clients. It is configured with the emmy
+// configuration (see package config).
+type Server struct {
+	server *server.Server
+}
+
+// NewServer returns the server with its own gRPC server using TLS with the given certificate
+// and key. It is started with Start.
+func NewServer(certFile, keyFile string, logger log.Logger) (*Server, error) {
+	s, err := server.NewProtocolServer(certFile, keyFile, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{server: s}, nil
+}
+
+// NewEmbeddedServer returns the server which is not bound to any gRPC server - its services
+// are added to the application's gRPC server with Register.
+func NewEmbeddedServer(logger log.Logger) (*Server, error) {
+	s, err := server.NewServer(logger)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{server: s}, nil
+}
+
+// Register adds emmy services to grpcServer.
+func (s *Server) Register(grpcServer *grpc.Server) {
+	s.server.RegisterServices(grpcServer)
+}
+
+// Start serves the connections at port until the server is stopped. It can be called only
+// for the servers created with NewServer.
+func (s *Server) Start(port int) error {
+	return s.server.Start(port)
+}
+
+// Stop gracefully stops the server.
+func (s *Server) Stop() {
+	s.server.Teardown()
+}